@@ -1,16 +1,64 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"text/tabwriter"
 
 	"vsq-oper-manpower/backend/internal/config"
 	"vsq-oper-manpower/backend/internal/repositories/postgres"
 )
 
+const usage = `Usage: migrate [flags] <command> [args]
+
+Commands:
+  status          show applied and pending migrations
+  up              apply all pending migrations (default)
+  down N          roll back the last N migrations
+  redo            roll back and re-apply the latest migration
+  force VERSION   clear the dirty flag after fixing a failed migration by hand
+  create NAME     create a new numbered up/down migration pair
+
+Flags:
+`
+
 func main() {
+	dir := flag.String("dir", "internal/repositories/postgres/migrations", "migrations directory (used by create)")
+	configPath := flag.String("config", "", "YAML config file (default $CONFIG_FILE); environment variables override it")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	command := "up"
+	args := flag.Args()
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	// create only touches the filesystem, no database needed
+	if command == "create" {
+		if len(args) != 1 {
+			log.Fatal("Usage: migrate create NAME")
+		}
+		upPath, downPath, err := postgres.CreateMigrationFiles(*dir, args[0])
+		if err != nil {
+			log.Fatalf("Failed to create migration: %v", err)
+		}
+		log.Printf("Created %s", upPath)
+		log.Printf("Created %s", downPath)
+		return
+	}
+
 	// Load configuration
-	cfg := config.Load()
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 
 	// Initialize database connection
 	db, err := postgres.NewConnection(cfg.Database)
@@ -19,13 +67,91 @@ func main() {
 	}
 	defer db.Close()
 
-	log.Println("Running migrations...")
+	migrator, err := postgres.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
 
-	// Run migrations
-	if err := postgres.RunMigrations(db); err != nil {
-		log.Fatalf("Migration failed: %v", err)
-		os.Exit(1)
+	switch command {
+	case "status":
+		printStatus(migrator)
+
+	case "up":
+		log.Println("Running migrations...")
+		applied, err := migrator.Up()
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		log.Printf("Migrations completed successfully! Applied %d migration(s)", applied)
+
+	case "down":
+		if len(args) != 1 {
+			log.Fatal("Usage: migrate down N")
+		}
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n <= 0 {
+			log.Fatalf("Invalid number of migrations: %s", args[0])
+		}
+		reverted, err := migrator.Down(n)
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+		log.Printf("Rolled back %d migration(s)", reverted)
+
+	case "redo":
+		if err := migrator.Redo(); err != nil {
+			log.Fatalf("Redo failed: %v", err)
+		}
+		log.Println("Redo completed successfully!")
+
+	case "force":
+		if len(args) != 1 {
+			log.Fatal("Usage: migrate force VERSION")
+		}
+		version, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			log.Fatalf("Invalid version: %s", args[0])
+		}
+		if err := migrator.Force(version); err != nil {
+			log.Fatalf("Force failed: %v", err)
+		}
+		log.Printf("Cleared dirty flag on migration %d", version)
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func printStatus(migrator *postgres.Migrator) {
+	statuses, err := migrator.Status()
+	if err != nil {
+		log.Fatalf("Failed to read migration status: %v", err)
 	}
 
-	log.Println("Migrations completed successfully!")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		state := "pending"
+		switch {
+		case s.Dirty:
+			state = "DIRTY"
+		case s.Unknown:
+			state = "unknown"
+		case s.ChecksumMismatch:
+			state = "CHECKSUM MISMATCH"
+		case s.Applied:
+			state = "applied"
+		}
+		appliedAt := ""
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, state, appliedAt)
+	}
+	w.Flush()
+
+	if err := migrator.Verify(); err != nil {
+		fmt.Printf("\n%v\n", err)
+	}
 }
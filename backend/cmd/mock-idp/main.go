@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+
+	"vsq-oper-manpower/backend/pkg/oidc/oidctest"
+)
+
+// mock-idp runs a local OpenID Connect provider for trying SSO without a real IdP.
+// Point the backend at it with:
+//
+//	OIDC_ENABLED=true OIDC_ISSUER_URL=http://localhost:9999 OIDC_CLIENT_ID=vsq OIDC_CLIENT_SECRET=secret
+//
+// MOCK_IDP_CLAIMS sets the logged-in user's claims as JSON, e.g.
+// {"sub":"u1","email":"admin@example.com","email_verified":true,"preferred_username":"sso-admin","groups":["vsq-admins"]}
+func main() {
+	port := getEnv("MOCK_IDP_PORT", "9999")
+	issuer := getEnv("MOCK_IDP_ISSUER", "http://localhost:"+port)
+
+	provider, err := oidctest.NewProvider(issuer, getEnv("MOCK_IDP_CLIENT_ID", "vsq"), getEnv("MOCK_IDP_CLIENT_SECRET", "secret"))
+	if err != nil {
+		log.Fatalf("Failed to create mock IdP: %v", err)
+	}
+
+	if raw := os.Getenv("MOCK_IDP_CLAIMS"); raw != "" {
+		var claims map[string]interface{}
+		if err := json.Unmarshal([]byte(raw), &claims); err != nil {
+			log.Fatalf("Invalid MOCK_IDP_CLAIMS: %v", err)
+		}
+		provider.SetClaims(claims)
+	}
+
+	log.Printf("Mock IdP listening on %s", issuer)
+	if err := http.ListenAndServe(":"+port, provider.Handler()); err != nil {
+		log.Fatalf("Mock IdP stopped: %v", err)
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -1,14 +1,27 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"vsq-oper-manpower/backend/internal/config"
+	"vsq-oper-manpower/backend/internal/domain/events"
 	"vsq-oper-manpower/backend/internal/handlers"
 	"vsq-oper-manpower/backend/internal/middleware"
+	"vsq-oper-manpower/backend/internal/repositories/memory"
 	"vsq-oper-manpower/backend/internal/repositories/postgres"
+	"vsq-oper-manpower/backend/internal/repositories/publishing"
+	"vsq-oper-manpower/backend/pkg/logging"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-contrib/sessions/cookie"
@@ -16,37 +29,100 @@ import (
 )
 
 func main() {
-	// Load configuration
-	cfg := config.Load()
+	configPath := flag.String("config", "", "YAML config file (default $CONFIG_FILE); environment variables override it")
+	printConfig := flag.Bool("print-config", false, "print the resolved configuration with secrets redacted and exit")
+	flag.Parse()
 
-	// Initialize database
-	db, err := postgres.NewConnection(cfg.Database)
+	// Load configuration
+	if *printConfig {
+		os.Exit(printResolvedConfig(*configPath))
+	}
+	cfg, err := config.Load(*configPath)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Fatalf("Invalid configuration: %v", err)
 	}
-	defer db.Close()
 
-	// Run migrations
-	if err := postgres.RunMigrations(db); err != nil {
-		log.Fatalf("Failed to run migrations: %v", err)
+	// Structured logs; log.Printf output goes through the same handler
+	slog.SetDefault(logging.New(os.Stdout, cfg.Logging.Format, cfg.Logging.Level))
+
+	// Initialize repositories; memory storage needs no database and starts
+	// from demo fixtures
+	var db *sql.DB
+	var repos *postgres.Repositories
+	if cfg.Storage == "memory" {
+		repos = newMemoryRepositories()
+	} else {
+		db = connectDatabase(cfg)
+		defer db.Close()
+		repos = postgres.NewRepositories(db)
 	}
 
-	// Initialize repositories
-	repos := postgres.NewRepositories(db)
+	// Writes publish change events so caches evict exactly what changed
+	bus := events.NewBus()
+	repos.Repositories, repos.UnitOfWork = publishing.Wrap(repos.Repositories, repos.UnitOfWork, bus)
 
 	// Initialize handlers
-	h := handlers.NewHandlers(repos, cfg, db)
+	h := handlers.NewHandlers(repos, cfg, db, bus)
+
+	// Background workers run until shutdown cancels workerCtx
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+
+	// Recompute quota summaries queued by writes in the background
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		h.SummaryWorker.Run(workerCtx)
+	}()
+
+	// Run background jobs and their schedules: nightly suggestions, the weekly
+	// report and the doctor roster, which is also brought up to date now
+	if err := h.AllocationJobs.ScheduleDefaults(workerCtx, h.JobRunner); err != nil {
+		log.Printf("Failed to set up job schedules: %v", err)
+	}
+	if err := h.RosterJobs.ScheduleDefaults(workerCtx, h.JobRunner); err != nil {
+		log.Printf("Failed to set up doctor roster schedule: %v", err)
+	}
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		h.JobRunner.Run(workerCtx)
+	}()
 
 	// Setup Gin router
 	if os.Getenv("GIN_MODE") == "release" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	r := gin.Default()
+	r := gin.New()
+	r.Use(gin.Recovery())
 
 	// Request ID middleware (must be first)
 	r.Use(middleware.RequestIDMiddleware())
 
+	// One structured log line and latency sample per request
+	r.Use(middleware.RequestLogger())
+	r.Use(middleware.RequestMetrics(h.Metrics))
+
+	// Request deadline, propagated to repository queries via the request context
+	longTimeout := cfg.Timeouts.LongRequest
+	r.Use(middleware.RequestTimeout(cfg.Timeouts.Request, map[string]time.Duration{
+		"/api/overview/day":                          longTimeout,
+		"/api/overview/monthly":                      longTimeout,
+		"/api/overview/matrix":                       longTimeout,
+		"/api/schedules/monthly":                     longTimeout,
+		"/api/doctors/:id/schedule":                  longTimeout,
+		"/api/staff-requirement-scenarios/calculate": longTimeout,
+		"/api/staff/import":                          longTimeout,
+		"/api/branches/revenue/import":               longTimeout,
+		"/api/doctors/import":                        longTimeout,
+		"/api/doctors/default-schedules/import":      longTimeout,
+		"/api/quotas/import":                         longTimeout,
+		"/api/admin/test-data/generate-schedules":    longTimeout,
+		// Event streams stay open until the client disconnects
+		"/api/events/stream": 0,
+	}))
+
 	// CORS middleware
 	r.Use(middleware.CORS(cfg))
 
@@ -54,7 +130,7 @@ func main() {
 	store := cookie.NewStore([]byte(cfg.SessionSecret))
 	// For development: Use SameSite Lax (works with localhost)
 	// For production: Use SameSite None with Secure true (requires HTTPS)
-	isDevelopment := os.Getenv("ENVIRONMENT") != "production"
+	isDevelopment := !cfg.IsProduction()
 	sameSite := http.SameSiteLaxMode
 	secure := false
 	if !isDevelopment {
@@ -72,15 +148,20 @@ func main() {
 	r.Use(sessions.Sessions("vsq_session", store))
 
 	// Error handler middleware (must be last)
-	r.Use(middleware.ErrorHandlerMiddleware())
+	r.Use(middleware.ErrorHandlerMiddleware(isDevelopment))
 
-	// Health check
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
-	})
+	// Health checks: liveness for restarts, readiness for load balancers
+	r.GET("/health", h.Health.Live)
+	r.GET("/health/live", h.Health.Live)
+	r.GET("/health/ready", h.Health.Ready)
+
+	// Prometheus metrics
+	r.GET("/metrics", middleware.MetricsAuth(cfg.Metrics.Token), gin.WrapH(h.Metrics.Handler()))
 
 	// API routes
 	api := r.Group("/api")
+	// Service accounts authenticate with "Authorization: Bearer <token>" instead of a session
+	api.Use(middleware.APITokenAuth(repos.APIToken, repos.ServiceAccount))
 	{
 		// Version endpoint (public)
 		v1 := api.Group("/v1")
@@ -94,6 +175,10 @@ func main() {
 			auth.POST("/login", h.Auth.Login)
 			auth.POST("/logout", h.Auth.Logout)
 			auth.GET("/me", middleware.RequireAuth(), h.Auth.Me)
+			// OpenID Connect single sign-on
+			auth.GET("/oidc/config", h.OIDC.Config)
+			auth.GET("/oidc/login", h.OIDC.Login)
+			auth.GET("/oidc/callback", h.OIDC.Callback)
 		}
 
 		// Protected routes
@@ -108,6 +193,8 @@ func main() {
 				users.POST("", h.User.Create)
 				users.PUT("/:id", h.User.Update)
 				users.DELETE("/:id", h.User.Delete)
+				// Linking an SSO identity to an existing user needs an admin's approval
+				users.POST("/:id/sso-link", middleware.RequireSession(), h.OIDC.ApproveLink)
 			}
 
 			// Roles
@@ -146,6 +233,11 @@ func main() {
 				branches.DELETE("/:id", middleware.RequireRole("admin"), h.Branch.Delete)
 				branches.GET("/:id/revenue", h.Branch.GetRevenue)
 				branches.POST("/revenue/import", middleware.RequireRole("admin", "area_manager", "district_manager"), h.Branch.ImportRevenue)
+				// Closures and public holidays
+				branches.GET("/closures", h.Branch.GetClosures)
+				branches.POST("/closures", middleware.RequireRole("admin", "area_manager", "district_manager"), h.Branch.CreateClosure)
+				branches.PUT("/closures/:id", middleware.RequireRole("admin", "area_manager", "district_manager"), h.Branch.UpdateClosure)
+				branches.DELETE("/closures/:id", middleware.RequireRole("admin", "area_manager", "district_manager"), h.Branch.DeleteClosure)
 				// Branch configuration endpoints (more specific routes first)
 				branches.GET("/:id/config/constraints", middleware.RequireRole("admin", "area_manager", "district_manager"), h.BranchConfig.GetConstraints)
 				branches.PUT("/:id/config/constraints", middleware.RequireRole("admin", "area_manager", "district_manager"), h.BranchConfig.UpdateConstraints)
@@ -173,6 +265,8 @@ func main() {
 				rotation.POST("/bulk-assign", middleware.RequireRole("area_manager", "admin"), h.Rotation.BulkAssign)
 				rotation.DELETE("/assign/:id", middleware.RequireRole("area_manager", "district_manager", "admin"), h.Rotation.RemoveAssignment)
 				rotation.GET("/eligible-staff/:branchId", middleware.RequireRole("area_manager", "admin"), h.Rotation.GetEligibleStaff)
+				rotation.GET("/doctor-preferences/check", middleware.RequireRole("area_manager", "admin"), h.Rotation.GetDoctorPreferenceChecks)
+				rotation.GET("/pairings", middleware.RequireRole("area_manager", "admin"), h.Rotation.GetPairings)
 				// Schedule management (on/off days)
 				rotation.POST("/schedule", middleware.RequireRole("area_manager", "district_manager", "admin"), h.Rotation.SetSchedule)
 				rotation.GET("/schedule", h.Rotation.GetSchedules)
@@ -234,7 +328,39 @@ func main() {
 			admin := protected.Group("/admin")
 			admin.Use(middleware.RequireRole("admin"))
 			{
-				admin.POST("/test-data/generate-schedules", h.TestData.GenerateSchedules)
+				if cfg.Features.TestData {
+					admin.POST("/test-data/generate-schedules", h.TestData.GenerateSchedules)
+				}
+
+				// Service accounts and API tokens for integrations, managed
+				// from a signed-in session only
+				tokens := admin.Group("/tokens")
+				tokens.Use(middleware.RequireSession())
+				{
+					tokens.GET("", h.APIToken.List)
+					tokens.POST("", h.APIToken.Create)
+					tokens.DELETE("/:id", h.APIToken.Revoke)
+					tokens.GET("/service-accounts", h.APIToken.ListServiceAccounts)
+					tokens.POST("/service-accounts", h.APIToken.CreateServiceAccount)
+					tokens.PUT("/service-accounts/:id", h.APIToken.UpdateServiceAccount)
+					tokens.DELETE("/service-accounts/:id", h.APIToken.DeleteServiceAccount)
+				}
+
+				// Overview cache hit/miss counters
+				admin.GET("/cache/stats", h.Cache.GetStats)
+			}
+
+			// Background jobs: status polling, result downloads and schedules
+			jobs := protected.Group("/jobs")
+			{
+				jobs.GET("", h.Job.List)
+				jobs.POST("", middleware.RequireRole("admin"), h.Job.Create)
+				// Schedules (must be before /:id routes to avoid route conflict)
+				jobs.GET("/schedules", middleware.RequireRole("admin"), h.Job.ListSchedules)
+				jobs.PUT("/schedules/:name", middleware.RequireRole("admin"), h.Job.UpdateSchedule)
+				jobs.GET("/:id", h.Job.Get)
+				jobs.GET("/:id/result", h.Job.GetResult)
+				jobs.POST("/:id/cancel", h.Job.Cancel)
 			}
 
 			// Dashboard
@@ -290,6 +416,34 @@ func main() {
 				doctors.GET("/schedule-overrides", middleware.RequireRole("admin", "area_manager"), h.Doctor.GetScheduleOverrides)
 				doctors.PUT("/schedule-overrides/:id", middleware.RequireRole("admin", "area_manager"), h.Doctor.UpdateScheduleOverride)
 				doctors.DELETE("/schedule-overrides/:id", middleware.RequireRole("admin", "area_manager"), h.Doctor.DeleteScheduleOverride)
+
+				// Doctor expected revenue, attached to calculated assignments
+				doctors.POST("/revenue-profiles", middleware.RequireRole("admin", "area_manager"), h.Doctor.UpsertRevenueProfile)
+				doctors.GET("/revenue-profiles", middleware.RequireRole("admin", "area_manager"), h.Doctor.GetRevenueProfiles)
+				doctors.DELETE("/revenue-profiles/:id", middleware.RequireRole("admin", "area_manager"), h.Doctor.DeleteRevenueProfile)
+				doctors.POST("/revenue-overrides", middleware.RequireRole("admin", "area_manager"), h.Doctor.UpsertRevenueOverride)
+				doctors.GET("/revenue-overrides", middleware.RequireRole("admin", "area_manager"), h.Doctor.GetRevenueOverrides)
+				doctors.DELETE("/revenue-overrides/:id", middleware.RequireRole("admin", "area_manager"), h.Doctor.DeleteRevenueOverride)
+
+				// Doctor leave, with the branches it leaves without a doctor and their substitutes
+				doctors.POST("/leaves", middleware.RequireRole("admin", "area_manager"), h.Doctor.CreateLeave)
+				doctors.GET("/leaves", middleware.RequireRole("admin", "area_manager"), h.Doctor.GetLeaves)
+				doctors.GET("/leaves/:id", middleware.RequireRole("admin", "area_manager"), h.Doctor.GetLeave)
+				doctors.PUT("/leaves/:id/status", middleware.RequireRole("admin"), h.Doctor.ReviewLeave)
+				doctors.DELETE("/leaves/:id", middleware.RequireRole("admin", "area_manager"), h.Doctor.DeleteLeave)
+				doctors.POST("/leaves/:id/substitutes", middleware.RequireRole("admin", "area_manager"), h.Doctor.AssignLeaveSubstitutes)
+				doctors.DELETE("/leaves/:id/substitutes/:substituteId", middleware.RequireRole("admin", "area_manager"), h.Doctor.DeleteLeaveSubstitute)
+
+				// Materialized roster: run history, what each run changed, and on-demand runs
+				doctors.GET("/roster/runs", middleware.RequireRole("admin", "area_manager"), h.Doctor.GetRosterRuns)
+				doctors.GET("/roster/changes", middleware.RequireRole("admin", "area_manager"), h.Doctor.GetRosterChanges)
+				doctors.POST("/roster/materialize", middleware.RequireRole("admin"), h.Doctor.MaterializeRoster)
+
+				// Monthly roster grid: export the calculated month, import deviations as
+				// overrides, and validate coverage, capacity and overrides before publishing
+				doctors.GET("/roster/export", middleware.RequireRole("admin", "area_manager"), h.Doctor.ExportRoster)
+				doctors.POST("/roster/import", middleware.RequireRole("admin", "area_manager"), h.Doctor.ImportRoster)
+				doctors.GET("/roster/validate", middleware.RequireRole("admin", "area_manager"), h.Doctor.ValidateSchedule)
 			}
 
 			// Position quota management
@@ -308,6 +462,16 @@ func main() {
 			{
 				overview.GET("/day", middleware.RequireRole("admin", "area_manager"), h.Overview.GetDayOverview)
 				overview.GET("/monthly", h.Overview.GetMonthlyOverview)
+				overview.GET("/matrix", middleware.RequireRole("admin", "area_manager"), h.Overview.GetMonthlyMatrix)
+				overview.GET("/branch-days", middleware.RequireRole("admin", "area_manager"), h.Overview.GetBranchDayStatuses)
+			}
+
+			// Change events for live UI updates
+			if cfg.Features.EventStream {
+				protected.GET("/events/stream",
+					middleware.RequireRole("admin", "area_manager", "district_manager", "branch_manager"),
+					middleware.RequireBranchAccess(),
+					h.EventStream.Stream)
 			}
 
 			// Allocation Report endpoints (TODO: Implement - Related: FR-RP-04)
@@ -433,12 +597,107 @@ func main() {
 	}
 
 	port := cfg.Port
-	if port == "" {
-		port = "8080"
-	}
 
-	log.Printf("Server starting on port %s", port)
-	if err := r.Run(":" + port); err != nil {
+	srv := &http.Server{
+		Addr:              ":" + port,
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       2 * time.Minute,
+		// No WriteTimeout: event streams and long imports are bounded by RequestTimeout
+	}
+	// Event streams never finish on their own; end them so Shutdown can drain
+	srv.RegisterOnShutdown(h.EventStream.Close)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		log.Printf("Server starting on port %s", port)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+	select {
+	case err := <-serveErr:
 		log.Fatalf("Failed to start server: %v", err)
+	case <-signals.Done():
+	}
+
+	// Stop accepting requests and let in-flight ones (including synchronous
+	// imports) finish while the workers wind down: they stop claiming work and
+	// running jobs get the job shutdown grace before being queued again
+	log.Printf("Shutting down, waiting up to %s", cfg.Server.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	stopWorkers()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("HTTP server did not drain: %v", err)
+	}
+	workersDone := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(workersDone)
+	}()
+	select {
+	case <-workersDone:
+		log.Printf("Server stopped")
+	case <-shutdownCtx.Done():
+		log.Printf("Background workers did not stop within %s", cfg.Server.ShutdownTimeout)
+	}
+}
+
+// connectDatabase opens the database and checks the schema is migrated,
+// refusing to start on a dirty or out-of-date schema
+func connectDatabase(cfg *config.Config) *sql.DB {
+	db, err := postgres.NewConnection(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	migrator, err := postgres.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+	if cfg.Database.AutoMigrate {
+		applied, err := migrator.Up()
+		if err != nil {
+			log.Fatalf("Failed to run migrations: %v", err)
+		}
+		log.Printf("Applied %d migration(s)", applied)
+	}
+	if err := migrator.Verify(); err != nil {
+		log.Fatalf("Database schema check failed: %v", err)
+	}
+	return db
+}
+
+// newMemoryRepositories builds seeded in-memory repositories for demo mode.
+// Data lives only as long as the process.
+func newMemoryRepositories() *postgres.Repositories {
+	mem := memory.NewRepositories()
+	if err := memory.Seed(context.Background(), mem); err != nil {
+		log.Fatalf("Failed to seed memory storage: %v", err)
+	}
+	log.Printf("Using in-memory storage with demo data (login %s / %s)", memory.DemoAdminUsername, memory.DemoAdminPassword)
+	return &postgres.Repositories{Repositories: mem.Repositories, UnitOfWork: mem.UnitOfWork}
+}
+
+// printResolvedConfig writes the configuration as YAML with secrets redacted,
+// then reports whether it is valid; it returns the exit code
+func printResolvedConfig(path string) int {
+	cfg, err := config.Read(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		return 1
+	}
+	out, err := cfg.Redacted().YAML()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to render configuration: %v\n", err)
+		return 1
+	}
+	os.Stdout.Write(out)
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration: %v\n", err)
+		return 1
 	}
+	return 0
 }
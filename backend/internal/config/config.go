@@ -1,80 +1,573 @@
+// Package config loads the server configuration: built-in defaults, then an
+// optional YAML file (CONFIG_FILE or --config), then environment variables,
+// which override both. Validate refuses settings that are unsafe in production.
 package config
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Database      DatabaseConfig
-	Port          string
-	SessionSecret string
-	CORS          CORSConfig
-	MCP           MCPConfig
+	// Environment is "development" (default), "staging" or "production";
+	// production enables secure cookies and strict validation
+	Environment string `yaml:"environment"`
+	// Storage selects the repository backend: "postgres" (default) or
+	// "memory", an in-process store seeded with demo fixtures
+	Storage       string         `yaml:"storage"`
+	Database      DatabaseConfig `yaml:"database"`
+	Port          string         `yaml:"port"`
+	Server        ServerConfig   `yaml:"server"`
+	SessionSecret string         `yaml:"session_secret"`
+	CORS          CORSConfig     `yaml:"cors"`
+	MCP           MCPConfig      `yaml:"mcp"`
+	OIDC          OIDCConfig     `yaml:"oidc"`
+	Timeouts      TimeoutConfig  `yaml:"timeouts"`
+	// ComputeWorkers bounds the concurrent branch/day calculations of one
+	// overview request; 0 uses every CPU
+	ComputeWorkers int                 `yaml:"compute_workers"`
+	Cache          CacheConfig         `yaml:"cache"`
+	SummaryWorker  SummaryWorkerConfig `yaml:"summary_worker"`
+	Jobs           JobsConfig          `yaml:"jobs"`
+	EventStream    EventStreamConfig   `yaml:"event_stream"`
+	Logging        LoggingConfig       `yaml:"logging"`
+	Metrics        MetricsConfig       `yaml:"metrics"`
+	Features       FeaturesConfig      `yaml:"features"`
 }
 
 type DatabaseConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	Name     string
-	SSLMode  string
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"sslmode"`
+	// AutoMigrate applies pending migrations on server start (development only)
+	AutoMigrate bool `yaml:"auto_migrate"`
+	// Connection pool; zero leaves the database/sql default (unlimited, no expiry)
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+}
+
+// ServerConfig controls the HTTP server lifecycle
+type ServerConfig struct {
+	// ShutdownTimeout bounds how long SIGTERM waits for in-flight requests and
+	// background workers before exiting
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+// TimeoutConfig bounds how long a request (and the queries it runs) may take
+type TimeoutConfig struct {
+	Request     time.Duration `yaml:"request"`
+	LongRequest time.Duration `yaml:"long_request"` // monthly overviews, imports and other heavy endpoints
+}
+
+// CacheConfig bounds the in-process overview caches. Entries are evicted by
+// change events as soon as their data changes; TTL is only a safety net.
+type CacheConfig struct {
+	OverviewSize       int           `yaml:"overview_size"`        // Day overviews (one per date and branch selection)
+	QuotaStatusSize    int           `yaml:"quota_status_size"`    // Single-branch quota statuses (one per branch and date)
+	DoctorCalendarSize int           `yaml:"doctor_calendar_size"` // Months of calculated doctor assignments
+	TTL                time.Duration `yaml:"ttl"`
+}
+
+// SummaryWorkerConfig schedules the background recomputation of queued
+// branch quota summaries
+type SummaryWorkerConfig struct {
+	Interval  time.Duration `yaml:"interval"` // How often the queue is polled
+	Debounce  time.Duration `yaml:"debounce"` // How long a branch and date must be unchanged before it is recomputed
+	MaxWait   time.Duration `yaml:"max_wait"` // Recompute anyway once it has been queued this long
+	BatchSize int           `yaml:"batch_size"`
+}
+
+// JobsConfig sizes the background job runner
+type JobsConfig struct {
+	Workers      int           `yaml:"workers"`       // Jobs run concurrently
+	PollInterval time.Duration `yaml:"poll_interval"` // How often idle workers look for queued jobs
+	Retention    time.Duration `yaml:"retention"`     // Finished jobs and their files are deleted after this long
+	// ShutdownGrace is how long running jobs may finish on shutdown before
+	// they are interrupted and queued again
+	ShutdownGrace time.Duration `yaml:"shutdown_grace"`
+	// RosterHorizonDays is how many days ahead, including today, the doctor
+	// roster is materialized; later dates are calculated on every read
+	RosterHorizonDays int `yaml:"roster_horizon_days"`
+}
+
+// EventStreamConfig bounds the log of change events that reconnecting
+// event stream clients replay from
+type EventStreamConfig struct {
+	LogSize   int           `yaml:"log_size"`
+	LogMaxAge time.Duration `yaml:"log_max_age"`
+}
+
+// LoggingConfig selects the structured log output
+type LoggingConfig struct {
+	Format string `yaml:"format"` // "json" (default) or "text"
+	Level  string `yaml:"level"`  // debug, info, warn or error
+}
+
+// MetricsConfig protects the Prometheus endpoint
+type MetricsConfig struct {
+	// Token, when set, must be sent as "Authorization: Bearer <token>" to scrape /metrics
+	Token string `yaml:"token"`
+}
+
+// FeaturesConfig switches optional parts of the API on and off
+type FeaturesConfig struct {
+	// TestData enables the admin endpoints that generate demo schedules; off
+	// by default in production
+	TestData bool `yaml:"test_data"`
+	// EventStream enables server-sent change events; without it the UI polls
+	EventStream bool `yaml:"event_stream"`
 }
 
 type CORSConfig struct {
-	AllowedOrigins []string
+	AllowedOrigins []string `yaml:"allowed_origins"`
 }
 
 type MCPConfig struct {
-	ServerURL string
-	APIKey    string
-	Enabled   bool
-}
-
-func Load() *Config {
-	corsOrigins := getEnv("CORS_ALLOWED_ORIGINS", "http://localhost:4000,http://localhost:3000")
-	origins := []string{}
-	if corsOrigins != "" {
-		// Split comma-separated origins
-		for _, origin := range strings.Split(corsOrigins, ",") {
-			if trimmed := strings.TrimSpace(origin); trimmed != "" {
-				origins = append(origins, trimmed)
-			}
+	ServerURL string        `yaml:"server_url"`
+	APIKey    string        `yaml:"api_key"`
+	Enabled   bool          `yaml:"enabled"`
+	Timeout   time.Duration `yaml:"timeout"` // Bounds each request to the MCP server
+}
+
+// OIDCConfig configures single sign-on through an OpenID Connect identity provider
+type OIDCConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	IssuerURL    string   `yaml:"issuer_url"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	RedirectURL  string   `yaml:"redirect_url"` // Backend callback, e.g. https://vsq.example.com/api/auth/oidc/callback
+	Scopes       []string `yaml:"scopes"`       // Requested in addition to "openid"
+	// GroupsClaim and BranchClaim name the ID token claims holding IdP groups and the branch code
+	GroupsClaim string `yaml:"groups_claim"`
+	BranchClaim string `yaml:"branch_claim"`
+	// RoleMappings maps IdP group names to role names (admin, area_manager, ...)
+	RoleMappings map[string]string `yaml:"role_mappings"`
+	// AutoProvision creates a local user on first login when no matching user exists
+	AutoProvision bool   `yaml:"auto_provision"`
+	DefaultRole   string `yaml:"default_role"` // Role for auto-provisioned users whose groups match no mapping; empty denies login
+	// FrontendURL is where the browser is sent after a successful login
+	FrontendURL string `yaml:"frontend_url"`
+}
+
+// rolePrecedence orders roles from most to least privileged for users in several mapped groups
+var rolePrecedence = []string{"admin", "area_manager", "district_manager", "branch_manager", "viewer"}
+
+// RoleForGroups returns the most privileged role mapped from the given IdP groups
+func (c OIDCConfig) RoleForGroups(groups []string) (string, bool) {
+	matched := make(map[string]bool)
+	for _, group := range groups {
+		if role, ok := c.RoleMappings[group]; ok {
+			matched[role] = true
+		}
+	}
+	for _, role := range rolePrecedence {
+		if matched[role] {
+			return role, true
 		}
 	}
-	// Default origins if none specified
-	if len(origins) == 0 {
-		origins = []string{"http://localhost:4000", "http://localhost:3000"}
+	// Mappings to roles outside the known precedence list still apply
+	for _, group := range groups {
+		if role, ok := c.RoleMappings[group]; ok {
+			return role, true
+		}
 	}
+	return "", false
+}
+
+// Development defaults that must never reach production
+const (
+	defaultSessionSecret = "change-me-in-production"
+	defaultDBPassword    = "vsq_password"
+)
 
+// Default returns the built-in configuration for an environment
+func Default(environment string) *Config {
 	return &Config{
+		Environment: environment,
+		Storage:     "postgres",
 		Database: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     getEnv("DB_PORT", "5432"),
-			User:     getEnv("DB_USER", "vsq_user"),
-			Password: getEnv("DB_PASSWORD", "vsq_password"),
-			Name:     getEnv("DB_NAME", "vsq_manpower"),
-			SSLMode:  getEnv("DB_SSLMODE", "disable"),
+			Host:            "localhost",
+			Port:            "5432",
+			User:            "vsq_user",
+			Password:        defaultDBPassword,
+			Name:            "vsq_manpower",
+			SSLMode:         "disable",
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
 		},
-		Port:          getEnv("PORT", "8080"),
-		SessionSecret: getEnv("SESSION_SECRET", "change-me-in-production"),
+		Port: "8080",
+		Server: ServerConfig{
+			ShutdownTimeout: 30 * time.Second,
+		},
+		SessionSecret: defaultSessionSecret,
 		CORS: CORSConfig{
-			AllowedOrigins: origins,
+			AllowedOrigins: []string{"http://localhost:4000", "http://localhost:3000"},
+		},
+		Timeouts: TimeoutConfig{
+			Request:     30 * time.Second,
+			LongRequest: 2 * time.Minute,
+		},
+		Cache: CacheConfig{
+			OverviewSize:       128,
+			QuotaStatusSize:    4096,
+			DoctorCalendarSize: 24,
+			TTL:                5 * time.Minute,
+		},
+		SummaryWorker: SummaryWorkerConfig{
+			Interval:  2 * time.Second,
+			Debounce:  2 * time.Second,
+			MaxWait:   30 * time.Second,
+			BatchSize: 500,
+		},
+		Jobs: JobsConfig{
+			Workers:           2,
+			PollInterval:      time.Second,
+			Retention:         7 * 24 * time.Hour,
+			ShutdownGrace:     20 * time.Second,
+			RosterHorizonDays: 90,
+		},
+		EventStream: EventStreamConfig{
+			LogSize:   1000,
+			LogMaxAge: 10 * time.Minute,
+		},
+		Logging: LoggingConfig{
+			Format: "json",
+			Level:  "info",
+		},
+		Features: FeaturesConfig{
+			TestData:    environment != "production",
+			EventStream: true,
 		},
 		MCP: MCPConfig{
-			ServerURL: getEnv("MCP_SERVER_URL", ""),
-			APIKey:    getEnv("MCP_API_KEY", ""),
-			Enabled:   getEnv("MCP_ENABLED", "false") == "true",
+			Timeout: 10 * time.Second,
 		},
+		OIDC: OIDCConfig{
+			RedirectURL:  "http://localhost:8080/api/auth/oidc/callback",
+			Scopes:       []string{"profile", "email"},
+			GroupsClaim:  "groups",
+			BranchClaim:  "branch_code",
+			RoleMappings: map[string]string{},
+			FrontendURL:  "http://localhost:4000",
+		},
+	}
+}
+
+// Load reads the configuration (see Read) and validates it
+func Load(path string) (*Config, error) {
+	cfg, err := Read(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Read builds the configuration from the defaults, the YAML file at path
+// (CONFIG_FILE when path is empty; none when both are) and the environment,
+// without validating it
+func Read(path string) (*Config, error) {
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	var file []byte
+	if path != "" {
+		var err error
+		if file, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("read config file: %w", err)
+		}
+	}
+
+	// The environment decides the defaults, so find it before anything else
+	var envOnly struct {
+		Environment string `yaml:"environment"`
+	}
+	if err := yaml.Unmarshal(file, &envOnly); err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	environment := getEnv("ENVIRONMENT", envOnly.Environment)
+	if environment == "" {
+		environment = "development"
+	}
+
+	cfg := Default(environment)
+	decoder := yaml.NewDecoder(bytes.NewReader(file))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	cfg.Environment = environment
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyEnv overrides the configuration with the environment variables that are set
+func (c *Config) applyEnv() error {
+	e := &envReader{}
+	e.str(&c.Storage, "STORAGE")
+	e.str(&c.Database.Host, "DB_HOST")
+	e.str(&c.Database.Port, "DB_PORT")
+	e.str(&c.Database.User, "DB_USER")
+	e.str(&c.Database.Password, "DB_PASSWORD")
+	e.str(&c.Database.Name, "DB_NAME")
+	e.str(&c.Database.SSLMode, "DB_SSLMODE")
+	e.bool(&c.Database.AutoMigrate, "DB_AUTO_MIGRATE")
+	e.int(&c.Database.MaxOpenConns, "DB_MAX_OPEN_CONNS")
+	e.int(&c.Database.MaxIdleConns, "DB_MAX_IDLE_CONNS")
+	e.duration(&c.Database.ConnMaxLifetime, "DB_CONN_MAX_LIFETIME")
+	e.duration(&c.Database.ConnMaxIdleTime, "DB_CONN_MAX_IDLE_TIME")
+	e.str(&c.Port, "PORT")
+	e.duration(&c.Server.ShutdownTimeout, "SHUTDOWN_TIMEOUT")
+	e.str(&c.SessionSecret, "SESSION_SECRET")
+	e.list(&c.CORS.AllowedOrigins, "CORS_ALLOWED_ORIGINS")
+	e.duration(&c.Timeouts.Request, "REQUEST_TIMEOUT")
+	e.duration(&c.Timeouts.LongRequest, "LONG_REQUEST_TIMEOUT")
+	e.int(&c.ComputeWorkers, "COMPUTE_WORKERS")
+	e.int(&c.Cache.OverviewSize, "OVERVIEW_CACHE_SIZE")
+	e.int(&c.Cache.QuotaStatusSize, "QUOTA_STATUS_CACHE_SIZE")
+	e.int(&c.Cache.DoctorCalendarSize, "DOCTOR_CALENDAR_CACHE_SIZE")
+	e.duration(&c.Cache.TTL, "CACHE_TTL")
+	e.duration(&c.SummaryWorker.Interval, "SUMMARY_WORKER_INTERVAL")
+	e.duration(&c.SummaryWorker.Debounce, "SUMMARY_DEBOUNCE")
+	e.duration(&c.SummaryWorker.MaxWait, "SUMMARY_MAX_WAIT")
+	e.int(&c.SummaryWorker.BatchSize, "SUMMARY_BATCH_SIZE")
+	e.int(&c.Jobs.Workers, "JOB_WORKERS")
+	e.duration(&c.Jobs.PollInterval, "JOB_POLL_INTERVAL")
+	e.duration(&c.Jobs.Retention, "JOB_RETENTION")
+	e.duration(&c.Jobs.ShutdownGrace, "JOB_SHUTDOWN_GRACE")
+	e.int(&c.Jobs.RosterHorizonDays, "DOCTOR_ROSTER_HORIZON_DAYS")
+	e.int(&c.EventStream.LogSize, "EVENT_LOG_SIZE")
+	e.duration(&c.EventStream.LogMaxAge, "EVENT_LOG_MAX_AGE")
+	e.str(&c.Logging.Format, "LOG_FORMAT")
+	e.str(&c.Logging.Level, "LOG_LEVEL")
+	e.str(&c.Metrics.Token, "METRICS_TOKEN")
+	e.bool(&c.Features.TestData, "FEATURE_TEST_DATA")
+	e.bool(&c.Features.EventStream, "FEATURE_EVENT_STREAM")
+	e.str(&c.MCP.ServerURL, "MCP_SERVER_URL")
+	e.str(&c.MCP.APIKey, "MCP_API_KEY")
+	e.bool(&c.MCP.Enabled, "MCP_ENABLED")
+	e.duration(&c.MCP.Timeout, "MCP_TIMEOUT")
+	e.bool(&c.OIDC.Enabled, "OIDC_ENABLED")
+	e.str(&c.OIDC.IssuerURL, "OIDC_ISSUER_URL")
+	e.str(&c.OIDC.ClientID, "OIDC_CLIENT_ID")
+	e.str(&c.OIDC.ClientSecret, "OIDC_CLIENT_SECRET")
+	e.str(&c.OIDC.RedirectURL, "OIDC_REDIRECT_URL")
+	e.list(&c.OIDC.Scopes, "OIDC_SCOPES")
+	e.str(&c.OIDC.GroupsClaim, "OIDC_GROUPS_CLAIM")
+	e.str(&c.OIDC.BranchClaim, "OIDC_BRANCH_CLAIM")
+	if value := os.Getenv("OIDC_ROLE_MAPPINGS"); value != "" {
+		c.OIDC.RoleMappings = parseMapping(value)
+	}
+	e.bool(&c.OIDC.AutoProvision, "OIDC_AUTO_PROVISION")
+	e.str(&c.OIDC.DefaultRole, "OIDC_DEFAULT_ROLE")
+	e.str(&c.OIDC.FrontendURL, "OIDC_FRONTEND_URL")
+	return errors.Join(e.errs...)
+}
+
+// IsProduction reports whether the server runs in production
+func (c *Config) IsProduction() bool {
+	return c.Environment == "production"
+}
+
+// Validate checks that the configuration is consistent and, in production,
+// that no development default or insecure setting is left in place
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(oneOf(c.Environment, "development", "staging", "production"),
+		"environment %q must be development, staging or production", c.Environment)
+	check(oneOf(c.Storage, "postgres", "memory"), "storage %q must be postgres or memory", c.Storage)
+	check(c.Port != "", "port is required")
+	check(c.SessionSecret != "", "session_secret is required")
+	check(len(c.CORS.AllowedOrigins) > 0, "cors.allowed_origins needs at least one origin")
+	check(oneOf(c.Logging.Format, "json", "text"), "logging.format %q must be json or text", c.Logging.Format)
+	check(oneOf(strings.ToLower(c.Logging.Level), "debug", "info", "warn", "warning", "error"),
+		"logging.level %q must be debug, info, warn or error", c.Logging.Level)
+
+	if c.Storage == "postgres" {
+		check(c.Database.Host != "" && c.Database.Name != "" && c.Database.User != "",
+			"database host, name and user are required")
+		check(c.Database.MaxOpenConns >= 0 && c.Database.MaxIdleConns >= 0,
+			"database connection limits must not be negative")
+		check(c.Database.MaxOpenConns == 0 || c.Database.MaxIdleConns <= c.Database.MaxOpenConns,
+			"database.max_idle_conns (%d) exceeds max_open_conns (%d)", c.Database.MaxIdleConns, c.Database.MaxOpenConns)
+	}
+
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+	check(c.Timeouts.Request > 0, "timeouts.request must be positive")
+	check(c.Timeouts.LongRequest >= c.Timeouts.Request,
+		"timeouts.long_request (%s) is shorter than timeouts.request (%s)", c.Timeouts.LongRequest, c.Timeouts.Request)
+	check(c.ComputeWorkers >= 0, "compute_workers must not be negative")
+	check(c.Cache.OverviewSize >= 0 && c.Cache.QuotaStatusSize >= 0 && c.Cache.DoctorCalendarSize >= 0, "cache sizes must not be negative")
+	check(c.Cache.TTL >= 0, "cache.ttl must not be negative")
+	check(c.SummaryWorker.Interval > 0 && c.SummaryWorker.BatchSize > 0,
+		"summary_worker.interval and batch_size must be positive")
+	check(c.Jobs.Workers > 0 && c.Jobs.PollInterval > 0, "jobs.workers and poll_interval must be positive")
+	check(c.Jobs.RosterHorizonDays > 0, "jobs.roster_horizon_days must be positive")
+	check(c.Jobs.ShutdownGrace < c.Server.ShutdownTimeout,
+		"jobs.shutdown_grace (%s) must be shorter than server.shutdown_timeout (%s)", c.Jobs.ShutdownGrace, c.Server.ShutdownTimeout)
+	check(c.EventStream.LogSize > 0, "event_stream.log_size must be positive")
+
+	if c.MCP.Enabled {
+		check(c.MCP.ServerURL != "", "mcp.server_url is required when MCP is enabled")
+		check(c.MCP.Timeout > 0, "mcp.timeout must be positive")
+	}
+	if c.OIDC.Enabled {
+		check(c.OIDC.IssuerURL != "" && c.OIDC.ClientID != "" && c.OIDC.RedirectURL != "",
+			"oidc issuer_url, client_id and redirect_url are required when OIDC is enabled")
+	}
+
+	if c.IsProduction() {
+		check(c.SessionSecret != defaultSessionSecret && len(c.SessionSecret) >= 32,
+			"session_secret must be set to a random value of at least 32 characters in production")
+		check(c.Storage == "postgres", "memory storage is not allowed in production")
+		if c.Storage == "postgres" {
+			check(c.Database.Password != "" && c.Database.Password != defaultDBPassword,
+				"database.password must be set in production")
+			check(c.Database.SSLMode != "disable", "database.sslmode must not be disable in production")
+		}
+		check(!c.Database.AutoMigrate, "database.auto_migrate is not allowed in production; run cmd/migrate")
+		check(!c.Features.TestData, "features.test_data is not allowed in production")
+		check(!c.OIDC.Enabled || c.OIDC.ClientSecret != "", "oidc.client_secret must be set in production")
+	}
+	return errors.Join(errs...)
+}
+
+// Redacted returns a copy with secrets masked, safe to print or log
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	for _, secret := range []*string{
+		&redacted.Database.Password,
+		&redacted.SessionSecret,
+		&redacted.Metrics.Token,
+		&redacted.MCP.APIKey,
+		&redacted.OIDC.ClientSecret,
+	} {
+		if *secret != "" {
+			*secret = "REDACTED"
+		}
+	}
+	return &redacted
+}
+
+// YAML renders the configuration in the config file format
+func (c *Config) YAML() ([]byte, error) {
+	return yaml.Marshal(c)
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
+
+// envReader overrides configuration fields from set environment variables,
+// collecting the values it cannot parse
+type envReader struct {
+	errs []error
+}
+
+func (e *envReader) str(field *string, key string) {
+	if value := os.Getenv(key); value != "" {
+		*field = value
 	}
 }
 
+func (e *envReader) list(field *[]string, key string) {
+	if value := os.Getenv(key); value != "" {
+		*field = splitList(value)
+	}
+}
+
+func (e *envReader) bool(field *bool, key string) {
+	if value := os.Getenv(key); value != "" {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			e.errs = append(e.errs, fmt.Errorf("%s: %q is not a boolean", key, value))
+			return
+		}
+		*field = b
+	}
+}
+
+func (e *envReader) int(field *int, key string) {
+	if value := os.Getenv(key); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			e.errs = append(e.errs, fmt.Errorf("%s: %q is not an integer", key, value))
+			return
+		}
+		*field = n
+	}
+}
+
+func (e *envReader) duration(field *time.Duration, key string) {
+	if value := os.Getenv(key); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			e.errs = append(e.errs, fmt.Errorf("%s: %q is not a duration such as 30s or 5m", key, value))
+			return
+		}
+		*field = d
+	}
+}
+
+// splitList splits a comma-separated value, dropping empty entries
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			items = append(items, trimmed)
+		}
+	}
+	return items
+}
+
+// parseMapping parses "key=value,key2=value2" pairs
+func parseMapping(value string) map[string]string {
+	mapping := make(map[string]string)
+	for _, pair := range splitList(value) {
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+		if key != "" && val != "" {
+			mapping[key] = val
+		}
+	}
+	return mapping
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
-
-
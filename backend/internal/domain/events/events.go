@@ -0,0 +1,114 @@
+// Package events is an in-process bus for domain change events. Writes to data
+// the allocation overview depends on publish an Event; caches subscribe and
+// evict exactly the branches and dates it names.
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kind identifies what changed
+type Kind string
+
+const (
+	ScheduleChanged       Kind = "schedule.changed"
+	RotationChanged       Kind = "rotation.changed"
+	QuotaChanged          Kind = "quota.changed"
+	DoctorOverrideChanged Kind = "doctor_override.changed"
+	ConstraintsChanged    Kind = "constraints.changed"
+	// BranchDayChanged follows a change to a branch's doctor-on/off designation
+	// or closures, which decide whether the branch needs staff that day
+	BranchDayChanged Kind = "branch_day.changed"
+	// DoctorRosterChanged follows a materialization run that added, removed or
+	// moved a doctor, naming each branch and date it changed
+	DoctorRosterChanged Kind = "doctor_roster.changed"
+	// QuotaStatusChanged follows a recomputed quota summary. It reports derived
+	// data for clients, so caches need not evict on it.
+	QuotaStatusChanged Kind = "quota_status.changed"
+)
+
+// Event is a change affecting one branch on one date. A nil BranchID or a zero
+// Date widens it to every branch or every date.
+type Event struct {
+	Kind     Kind
+	BranchID uuid.UUID
+	Date     time.Time
+}
+
+// Affects reports whether the event touches branchID on date
+func (e Event) Affects(branchID uuid.UUID, date time.Time) bool {
+	if e.BranchID != uuid.Nil && e.BranchID != branchID {
+		return false
+	}
+	return e.Date.IsZero() || sameDay(e.Date, date)
+}
+
+// AffectsDate reports whether the event touches any branch on date
+func (e Event) AffectsDate(date time.Time) bool {
+	return e.Date.IsZero() || sameDay(e.Date, date)
+}
+
+func sameDay(a, b time.Time) bool {
+	return a.Format("2006-01-02") == b.Format("2006-01-02")
+}
+
+// Handler receives published events. It runs on the publisher's goroutine and
+// should not block.
+type Handler func(ctx context.Context, e Event)
+
+// Bus delivers events to every subscriber in subscription order. A nil *Bus
+// drops events, so publishers need no nil checks.
+type Bus struct {
+	mu        sync.RWMutex
+	handlers  []Handler
+	published map[Kind]uint64
+}
+
+// NewBus creates an empty bus
+func NewBus() *Bus {
+	return &Bus{published: make(map[Kind]uint64)}
+}
+
+// Subscribe registers h for every subsequent event
+func (b *Bus) Subscribe(h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers = append(b.handlers, h)
+}
+
+// Publish delivers events synchronously to every subscriber
+func (b *Bus) Publish(ctx context.Context, events ...Event) {
+	if b == nil || len(events) == 0 {
+		return
+	}
+	b.mu.Lock()
+	for _, e := range events {
+		b.published[e.Kind]++
+	}
+	handlers := b.handlers
+	b.mu.Unlock()
+
+	for _, e := range events {
+		for _, h := range handlers {
+			h(ctx, e)
+		}
+	}
+}
+
+// Published returns how many events of each kind have been published
+func (b *Bus) Published() map[Kind]uint64 {
+	counts := make(map[Kind]uint64)
+	if b == nil {
+		return counts
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for kind, n := range b.published {
+		counts[kind] = n
+	}
+	return counts
+}
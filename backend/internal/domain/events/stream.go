@@ -0,0 +1,180 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Envelope is an event as sent to stream clients, with the ID they resume from
+type Envelope struct {
+	ID    string
+	Event Event
+	At    time.Time
+
+	seq uint64
+}
+
+// Subscription receives the events published after it was opened. Events is
+// closed when the subscriber falls too far behind; the client should reconnect
+// and replay from its last event ID.
+type Subscription struct {
+	Events <-chan Envelope
+	// Since is the ID of the last event published before the subscription
+	// opened, empty if there was none. A client that could not replay resumes
+	// from it.
+	Since string
+
+	ch chan Envelope
+}
+
+// Stream fans events out to long-lived subscribers such as server-sent event
+// connections. It keeps a short log so a reconnecting client can replay what
+// it missed from its Last-Event-ID.
+type Stream struct {
+	mu     sync.Mutex
+	epoch  string // Distinguishes IDs issued before a restart
+	seq    uint64
+	log    []Envelope
+	size   int
+	maxAge time.Duration
+	buffer int
+	subs   map[*Subscription]struct{}
+}
+
+// NewStream creates a stream keeping the last size events, none older than
+// maxAge (0 keeps them until they are pushed out)
+func NewStream(size int, maxAge time.Duration) *Stream {
+	if size < 1 {
+		size = 1
+	}
+	return &Stream{
+		epoch:  strconv.FormatInt(time.Now().UnixNano(), 36),
+		size:   size,
+		maxAge: maxAge,
+		buffer: 64,
+		subs:   make(map[*Subscription]struct{}),
+	}
+}
+
+// WithBuffer sets how many events a subscriber may fall behind before it is dropped
+func (s *Stream) WithBuffer(buffer int) *Stream {
+	s.buffer = buffer
+	return s
+}
+
+// Handle logs an event and delivers it to every subscriber. Subscribe it to
+// the bus; it never blocks on slow subscribers.
+func (s *Stream) Handle(ctx context.Context, e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	env := Envelope{ID: s.id(s.seq), Event: e, At: time.Now(), seq: s.seq}
+	s.log = append(s.log, env)
+	if len(s.log) > s.size {
+		s.log = s.log[len(s.log)-s.size:]
+	}
+	s.prune(env.At)
+
+	for sub := range s.subs {
+		select {
+		case sub.ch <- env:
+		default:
+			// Dropping the subscriber makes the client reconnect and replay
+			delete(s.subs, sub)
+			close(sub.ch)
+		}
+	}
+}
+
+// Subscribe opens a subscription. With a lastEventID it also returns the logged
+// events after it; ok is false when they can no longer be replayed because the
+// ID is unknown, from before a restart or older than the log.
+func (s *Stream) Subscribe(lastEventID string) (sub *Subscription, backlog []Envelope, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := make(chan Envelope, s.buffer)
+	sub = &Subscription{Events: ch, ch: ch}
+	if s.seq > 0 {
+		sub.Since = s.id(s.seq)
+	}
+	s.subs[sub] = struct{}{}
+
+	if lastEventID == "" {
+		return sub, nil, true
+	}
+	seq, ok := s.parseID(lastEventID)
+	if !ok || seq > s.seq {
+		return sub, nil, false
+	}
+	s.prune(time.Now())
+	// The log holds every event after seq only if nothing between was dropped
+	if seq+uint64(len(s.log)) < s.seq {
+		return sub, nil, false
+	}
+	for _, env := range s.log {
+		if env.seq > seq {
+			backlog = append(backlog, env)
+		}
+	}
+	return sub, backlog, true
+}
+
+// Unsubscribe closes a subscription; it is safe to call after the stream dropped it
+func (s *Stream) Unsubscribe(sub *Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subs[sub]; ok {
+		delete(s.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// Close ends every open subscription. The server calls it on shutdown so
+// streaming requests return and their clients reconnect to another instance.
+func (s *Stream) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for sub := range s.subs {
+		delete(s.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// Subscribers returns how many subscriptions are open
+func (s *Stream) Subscribers() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subs)
+}
+
+func (s *Stream) id(seq uint64) string {
+	return fmt.Sprintf("%s-%d", s.epoch, seq)
+}
+
+func (s *Stream) parseID(id string) (uint64, bool) {
+	epoch, seqStr, found := strings.Cut(id, "-")
+	if !found || epoch != s.epoch {
+		return 0, false
+	}
+	seq, err := strconv.ParseUint(seqStr, 10, 64)
+	return seq, err == nil
+}
+
+// prune drops logged events older than maxAge
+func (s *Stream) prune(now time.Time) {
+	if s.maxAge <= 0 {
+		return
+	}
+	cutoff := now.Add(-s.maxAge)
+	i := 0
+	for i < len(s.log) && s.log[i].At.Before(cutoff) {
+		i++
+	}
+	s.log = s.log[i:]
+}
@@ -1,6 +1,7 @@
 package interfaces
 
 import (
+	"context"
 	"time"
 
 	"vsq-oper-manpower/backend/internal/domain/models"
@@ -9,32 +10,47 @@ import (
 )
 
 type UserRepository interface {
-	Create(user *models.User) error
-	GetByID(id uuid.UUID) (*models.User, error)
-	GetByUsername(username string) (*models.User, error)
-	GetByEmail(email string) (*models.User, error)
-	Update(user *models.User) error
-	Delete(id uuid.UUID) error
-	List() ([]*models.User, error)
+	Create(ctx context.Context, user *models.User) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	Update(ctx context.Context, user *models.User) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context) ([]*models.User, error)
+}
+
+type UserIdentityRepository interface {
+	Create(ctx context.Context, identity *models.UserIdentity) error
+	GetByIssuerAndSubject(ctx context.Context, issuer, subject string) (*models.UserIdentity, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.UserIdentity, error)
+	TouchLastLogin(ctx context.Context, id uuid.UUID, loginAt time.Time) error
+	// ApproveLink records an admin's approval to link an SSO identity to the user,
+	// replacing an earlier one
+	ApproveLink(ctx context.Context, approval *models.UserIdentityLinkApproval) error
+	// ConsumeLinkApproval removes the user's approval, reporting whether there was one
+	ConsumeLinkApproval(ctx context.Context, userID uuid.UUID) (bool, error)
 }
 
 type RoleRepository interface {
-	GetByID(id uuid.UUID) (*models.Role, error)
-	GetByName(name string) (*models.Role, error)
-	List() ([]*models.Role, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Role, error)
+	GetByName(ctx context.Context, name string) (*models.Role, error)
+	List(ctx context.Context) ([]*models.Role, error)
 }
 
 type StaffRepository interface {
-	Create(staff *models.Staff) error
-	GetByID(id uuid.UUID) (*models.Staff, error)
-	Update(staff *models.Staff) error
-	Delete(id uuid.UUID) error
-	List(filters StaffFilters) ([]*models.Staff, error)
-	GetByBranchID(branchID uuid.UUID) ([]*models.Staff, error)
-	GetRotationStaff() ([]*models.Staff, error)
+	Create(ctx context.Context, staff *models.Staff) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Staff, error)
+	Update(ctx context.Context, staff *models.Staff) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, filters StaffFilters) ([]*models.Staff, error)
+	GetByBranchID(ctx context.Context, branchID uuid.UUID) ([]*models.Staff, error)
+	// GetByBranchIDs returns branch staff keyed by branch, including staff linked via staff_branches
+	GetByBranchIDs(ctx context.Context, branchIDs []uuid.UUID) (map[uuid.UUID][]*models.Staff, error)
+	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.Staff, error)
+	GetRotationStaff(ctx context.Context) ([]*models.Staff, error)
 	// Zone and Branch management for rotation staff
-	GetBranches(staffID uuid.UUID) ([]*models.Branch, error)
-	BulkUpdateBranches(staffID uuid.UUID, branchIDs []uuid.UUID) error
+	GetBranches(ctx context.Context, staffID uuid.UUID) ([]*models.Branch, error)
+	BulkUpdateBranches(ctx context.Context, staffID uuid.UUID, branchIDs []uuid.UUID) error
 }
 
 type StaffFilters struct {
@@ -45,72 +61,74 @@ type StaffFilters struct {
 }
 
 type PositionRepository interface {
-	Create(position *models.Position) error
-	GetByID(id uuid.UUID) (*models.Position, error)
-	Update(position *models.Position) error
-	Delete(id uuid.UUID) error
-	List() ([]*models.Position, error)
-	HasAssociatedStaff(id uuid.UUID) (bool, error)
+	Create(ctx context.Context, position *models.Position) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Position, error)
+	Update(ctx context.Context, position *models.Position) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context) ([]*models.Position, error)
+	HasAssociatedStaff(ctx context.Context, id uuid.UUID) (bool, error)
 }
 
 type BranchRepository interface {
-	Create(branch *models.Branch) error
-	GetByID(id uuid.UUID) (*models.Branch, error)
-	Update(branch *models.Branch) error
-	Delete(id uuid.UUID) error
-	List() ([]*models.Branch, error)
-	GetByAreaManagerID(areaManagerID uuid.UUID) ([]*models.Branch, error)
+	Create(ctx context.Context, branch *models.Branch) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Branch, error)
+	Update(ctx context.Context, branch *models.Branch) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context) ([]*models.Branch, error)
+	GetByAreaManagerID(ctx context.Context, areaManagerID uuid.UUID) ([]*models.Branch, error)
 }
 
 type EffectiveBranchRepository interface {
-	Create(eb *models.EffectiveBranch) error
-	Update(eb *models.EffectiveBranch) error
-	GetByID(id uuid.UUID) (*models.EffectiveBranch, error)
-	GetByRotationStaffID(rotationStaffID uuid.UUID) ([]*models.EffectiveBranch, error)
-	GetByBranchID(branchID uuid.UUID) ([]*models.EffectiveBranch, error)
-	Delete(id uuid.UUID) error
-	DeleteByRotationStaffID(rotationStaffID uuid.UUID) error
+	Create(ctx context.Context, eb *models.EffectiveBranch) error
+	Update(ctx context.Context, eb *models.EffectiveBranch) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.EffectiveBranch, error)
+	GetByRotationStaffID(ctx context.Context, rotationStaffID uuid.UUID) ([]*models.EffectiveBranch, error)
+	GetByBranchID(ctx context.Context, branchID uuid.UUID) ([]*models.EffectiveBranch, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByRotationStaffID(ctx context.Context, rotationStaffID uuid.UUID) error
 }
 
 type RevenueRepository interface {
-	Create(revenue *models.RevenueData) error
-	GetByBranchID(branchID uuid.UUID, startDate, endDate time.Time) ([]*models.RevenueData, error)
-	GetByDate(date time.Time) ([]*models.RevenueData, error)
-	Update(revenue *models.RevenueData) error
-	BulkCreateOrUpdate(revenues []*models.RevenueData) error
+	Create(ctx context.Context, revenue *models.RevenueData) error
+	GetByBranchID(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) ([]*models.RevenueData, error)
+	GetByDate(ctx context.Context, date time.Time) ([]*models.RevenueData, error)
+	GetByBranchIDsAndDateRange(ctx context.Context, branchIDs []uuid.UUID, startDate, endDate time.Time) ([]*models.RevenueData, error)
+	Update(ctx context.Context, revenue *models.RevenueData) error
+	BulkCreateOrUpdate(ctx context.Context, revenues []*models.RevenueData) error
 }
 
 type ScheduleRepository interface {
-	Create(schedule *models.StaffSchedule) error
-	GetByBranchID(branchID uuid.UUID, startDate, endDate time.Time) ([]*models.StaffSchedule, error)
-	GetByStaffID(staffID uuid.UUID, startDate, endDate time.Time) ([]*models.StaffSchedule, error)
-	GetByStaffIDs(staffIDs []uuid.UUID, startDate, endDate time.Time) (map[uuid.UUID][]*models.StaffSchedule, error)
-	Update(schedule *models.StaffSchedule) error
-	Delete(id uuid.UUID) error
-	DeleteByStaffID(staffID uuid.UUID) error
-	GetMonthlyView(branchID uuid.UUID, year int, month int) ([]*models.StaffSchedule, error)
+	Create(ctx context.Context, schedule *models.StaffSchedule) error
+	GetByBranchID(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) ([]*models.StaffSchedule, error)
+	GetByStaffID(ctx context.Context, staffID uuid.UUID, startDate, endDate time.Time) ([]*models.StaffSchedule, error)
+	GetByStaffIDs(ctx context.Context, staffIDs []uuid.UUID, startDate, endDate time.Time) (map[uuid.UUID][]*models.StaffSchedule, error)
+	Update(ctx context.Context, schedule *models.StaffSchedule) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByStaffID(ctx context.Context, staffID uuid.UUID) error
+	GetMonthlyView(ctx context.Context, branchID uuid.UUID, year int, month int) ([]*models.StaffSchedule, error)
 }
 
 type RotationRepository interface {
-	Create(assignment *models.RotationAssignment) error
-	GetByDate(date time.Time) ([]*models.RotationAssignment, error)
-	GetByBranchID(branchID uuid.UUID, startDate, endDate time.Time) ([]*models.RotationAssignment, error)
-	GetByRotationStaffID(rotationStaffID uuid.UUID, startDate, endDate time.Time) ([]*models.RotationAssignment, error)
-	Delete(id uuid.UUID) error
-	DeleteByRotationStaffID(rotationStaffID uuid.UUID) error
-	GetAssignments(filters RotationFilters) ([]*models.RotationAssignment, error)
+	Create(ctx context.Context, assignment *models.RotationAssignment) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.RotationAssignment, error)
+	GetByDate(ctx context.Context, date time.Time) ([]*models.RotationAssignment, error)
+	GetByBranchID(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) ([]*models.RotationAssignment, error)
+	GetByRotationStaffID(ctx context.Context, rotationStaffID uuid.UUID, startDate, endDate time.Time) ([]*models.RotationAssignment, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByRotationStaffID(ctx context.Context, rotationStaffID uuid.UUID) error
+	GetAssignments(ctx context.Context, filters RotationFilters) ([]*models.RotationAssignment, error)
 }
 
 type RotationStaffScheduleRepository interface {
-	Create(schedule *models.RotationStaffSchedule) error
-	GetByID(id uuid.UUID) (*models.RotationStaffSchedule, error)
-	GetByRotationStaffID(rotationStaffID uuid.UUID, startDate, endDate time.Time) ([]*models.RotationStaffSchedule, error)
-	GetByDate(date time.Time) ([]*models.RotationStaffSchedule, error)
-	GetByDateRange(startDate, endDate time.Time) ([]*models.RotationStaffSchedule, error)
-	GetByRotationStaffIDAndDate(rotationStaffID uuid.UUID, date time.Time) (*models.RotationStaffSchedule, error)
-	Update(schedule *models.RotationStaffSchedule) error
-	Delete(id uuid.UUID) error
-	DeleteByRotationStaffID(rotationStaffID uuid.UUID) error
+	Create(ctx context.Context, schedule *models.RotationStaffSchedule) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.RotationStaffSchedule, error)
+	GetByRotationStaffID(ctx context.Context, rotationStaffID uuid.UUID, startDate, endDate time.Time) ([]*models.RotationStaffSchedule, error)
+	GetByDate(ctx context.Context, date time.Time) ([]*models.RotationStaffSchedule, error)
+	GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.RotationStaffSchedule, error)
+	GetByRotationStaffIDAndDate(ctx context.Context, rotationStaffID uuid.UUID, date time.Time) (*models.RotationStaffSchedule, error)
+	Update(ctx context.Context, schedule *models.RotationStaffSchedule) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByRotationStaffID(ctx context.Context, rotationStaffID uuid.UUID) error
 }
 
 type RotationFilters struct {
@@ -122,57 +140,57 @@ type RotationFilters struct {
 }
 
 type SettingsRepository interface {
-	GetAll() ([]*models.SystemSetting, error)
-	GetByKey(key string) (*models.SystemSetting, error)
-	Update(setting *models.SystemSetting) error
-	Create(setting *models.SystemSetting) error
+	GetAll(ctx context.Context) ([]*models.SystemSetting, error)
+	GetByKey(ctx context.Context, key string) (*models.SystemSetting, error)
+	Update(ctx context.Context, setting *models.SystemSetting) error
+	Create(ctx context.Context, setting *models.SystemSetting) error
 }
 
 type AllocationRuleRepository interface {
-	Create(rule *models.StaffAllocationRule) error
-	GetByPositionID(positionID uuid.UUID) (*models.StaffAllocationRule, error)
-	Update(rule *models.StaffAllocationRule) error
-	List() ([]*models.StaffAllocationRule, error)
+	Create(ctx context.Context, rule *models.StaffAllocationRule) error
+	GetByPositionID(ctx context.Context, positionID uuid.UUID) (*models.StaffAllocationRule, error)
+	Update(ctx context.Context, rule *models.StaffAllocationRule) error
+	List(ctx context.Context) ([]*models.StaffAllocationRule, error)
 }
 
 type AreaOfOperationRepository interface {
-	Create(aoo *models.AreaOfOperation) error
-	GetByID(id uuid.UUID) (*models.AreaOfOperation, error)
-	GetByCode(code string) (*models.AreaOfOperation, error)
-	Update(aoo *models.AreaOfOperation) error
-	Delete(id uuid.UUID) error
-	List(includeInactive bool) ([]*models.AreaOfOperation, error)
+	Create(ctx context.Context, aoo *models.AreaOfOperation) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.AreaOfOperation, error)
+	GetByCode(ctx context.Context, code string) (*models.AreaOfOperation, error)
+	Update(ctx context.Context, aoo *models.AreaOfOperation) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, includeInactive bool) ([]*models.AreaOfOperation, error)
 	// Zone and Branch management
-	AddZone(areaOfOperationID, zoneID uuid.UUID) error
-	RemoveZone(areaOfOperationID, zoneID uuid.UUID) error
-	GetZones(areaOfOperationID uuid.UUID) ([]*models.Zone, error)
-	AddBranch(areaOfOperationID, branchID uuid.UUID) error
-	RemoveBranch(areaOfOperationID, branchID uuid.UUID) error
-	GetBranches(areaOfOperationID uuid.UUID) ([]*models.Branch, error)
-	GetAllBranches(areaOfOperationID uuid.UUID) ([]*models.Branch, error) // Includes branches from zones + individual branches
+	AddZone(ctx context.Context, areaOfOperationID, zoneID uuid.UUID) error
+	RemoveZone(ctx context.Context, areaOfOperationID, zoneID uuid.UUID) error
+	GetZones(ctx context.Context, areaOfOperationID uuid.UUID) ([]*models.Zone, error)
+	AddBranch(ctx context.Context, areaOfOperationID, branchID uuid.UUID) error
+	RemoveBranch(ctx context.Context, areaOfOperationID, branchID uuid.UUID) error
+	GetBranches(ctx context.Context, areaOfOperationID uuid.UUID) ([]*models.Branch, error)
+	GetAllBranches(ctx context.Context, areaOfOperationID uuid.UUID) ([]*models.Branch, error) // Includes branches from zones + individual branches
 }
 
 type ZoneRepository interface {
-	Create(zone *models.Zone) error
-	GetByID(id uuid.UUID) (*models.Zone, error)
-	GetByCode(code string) (*models.Zone, error)
-	Update(zone *models.Zone) error
-	Delete(id uuid.UUID) error
-	List(includeInactive bool) ([]*models.Zone, error)
+	Create(ctx context.Context, zone *models.Zone) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Zone, error)
+	GetByCode(ctx context.Context, code string) (*models.Zone, error)
+	Update(ctx context.Context, zone *models.Zone) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, includeInactive bool) ([]*models.Zone, error)
 	// Branch management
-	AddBranch(zoneID, branchID uuid.UUID) error
-	RemoveBranch(zoneID, branchID uuid.UUID) error
-	GetBranches(zoneID uuid.UUID) ([]*models.Branch, error)
-	BulkUpdateBranches(zoneID uuid.UUID, branchIDs []uuid.UUID) error
+	AddBranch(ctx context.Context, zoneID, branchID uuid.UUID) error
+	RemoveBranch(ctx context.Context, zoneID, branchID uuid.UUID) error
+	GetBranches(ctx context.Context, zoneID uuid.UUID) ([]*models.Branch, error)
+	BulkUpdateBranches(ctx context.Context, zoneID uuid.UUID, branchIDs []uuid.UUID) error
 }
 
 type AllocationCriteriaRepository interface {
-	Create(criteria *models.AllocationCriteria) error
-	GetByID(id uuid.UUID) (*models.AllocationCriteria, error)
-	Update(criteria *models.AllocationCriteria) error
-	Delete(id uuid.UUID) error
-	List(filters AllocationCriteriaFilters) ([]*models.AllocationCriteria, error)
-	GetByPillar(pillar models.CriteriaPillar) ([]*models.AllocationCriteria, error)
+	Create(ctx context.Context, criteria *models.AllocationCriteria) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.AllocationCriteria, error)
+	Update(ctx context.Context, criteria *models.AllocationCriteria) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, filters AllocationCriteriaFilters) ([]*models.AllocationCriteria, error)
+	GetByPillar(ctx context.Context, pillar models.CriteriaPillar) ([]*models.AllocationCriteria, error)
 }
 
 type AllocationCriteriaFilters struct {
@@ -182,13 +200,13 @@ type AllocationCriteriaFilters struct {
 }
 
 type PositionQuotaRepository interface {
-	Create(quota *models.PositionQuota) error
-	GetByID(id uuid.UUID) (*models.PositionQuota, error)
-	GetByBranchID(branchID uuid.UUID) ([]*models.PositionQuota, error)
-	GetByBranchAndPosition(branchID, positionID uuid.UUID) (*models.PositionQuota, error)
-	Update(quota *models.PositionQuota) error
-	Delete(id uuid.UUID) error
-	List(filters PositionQuotaFilters) ([]*models.PositionQuota, error)
+	Create(ctx context.Context, quota *models.PositionQuota) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.PositionQuota, error)
+	GetByBranchID(ctx context.Context, branchID uuid.UUID) ([]*models.PositionQuota, error)
+	GetByBranchAndPosition(ctx context.Context, branchID, positionID uuid.UUID) (*models.PositionQuota, error)
+	Update(ctx context.Context, quota *models.PositionQuota) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, filters PositionQuotaFilters) ([]*models.PositionQuota, error)
 }
 
 type PositionQuotaFilters struct {
@@ -198,237 +216,344 @@ type PositionQuotaFilters struct {
 }
 
 type DoctorRepository interface {
-	Create(doctor *models.Doctor) error
-	GetByID(id uuid.UUID) (*models.Doctor, error)
-	GetByCode(code string) (*models.Doctor, error)
-	Update(doctor *models.Doctor) error
-	Delete(id uuid.UUID) error
-	List() ([]*models.Doctor, error)
+	Create(ctx context.Context, doctor *models.Doctor) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Doctor, error)
+	GetByCode(ctx context.Context, code string) (*models.Doctor, error)
+	Update(ctx context.Context, doctor *models.Doctor) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context) ([]*models.Doctor, error)
 }
 
 type DoctorPreferenceRepository interface {
-	Create(preference *models.DoctorPreference) error
-	GetByID(id uuid.UUID) (*models.DoctorPreference, error)
-	GetByDoctorID(doctorID uuid.UUID) ([]*models.DoctorPreference, error)
-	GetByDoctorAndBranch(doctorID uuid.UUID, branchID uuid.UUID) ([]*models.DoctorPreference, error)
-	GetActiveByDoctorID(doctorID uuid.UUID) ([]*models.DoctorPreference, error)
-	GetRotationStaffRequirements(doctorID uuid.UUID, branchID *uuid.UUID, dayOfWeek *int) ([]*models.DoctorPreference, error)
-	Update(preference *models.DoctorPreference) error
-	Delete(id uuid.UUID) error
-	DeleteByDoctorID(doctorID uuid.UUID) error
-}
-
+	Create(ctx context.Context, preference *models.DoctorPreference) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorPreference, error)
+	GetByDoctorID(ctx context.Context, doctorID uuid.UUID) ([]*models.DoctorPreference, error)
+	GetByDoctorAndBranch(ctx context.Context, doctorID uuid.UUID, branchID uuid.UUID) ([]*models.DoctorPreference, error)
+	GetActiveByDoctorID(ctx context.Context, doctorID uuid.UUID) ([]*models.DoctorPreference, error)
+	GetActiveByRuleType(ctx context.Context, ruleType string) ([]*models.DoctorPreference, error)
+	GetRotationStaffRequirements(ctx context.Context, doctorID uuid.UUID, branchID *uuid.UUID, dayOfWeek *int) ([]*models.DoctorPreference, error)
+	Update(ctx context.Context, preference *models.DoctorPreference) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByDoctorID(ctx context.Context, doctorID uuid.UUID) error
+}
+
+// DoctorAssignmentRepository stores the materialized doctor roster. Reads of
+// dates inside the materialized window come from the roster; other dates are
+// calculated from the doctors' schedules, with the same IDs the roster uses.
 type DoctorAssignmentRepository interface {
-	Create(assignment *models.DoctorAssignment) error
-	GetByID(id uuid.UUID) (*models.DoctorAssignment, error)
-	GetByBranchID(branchID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorAssignment, error)
-	GetByDate(date time.Time) ([]*models.DoctorAssignment, error)
-	GetByDoctorID(doctorID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorAssignment, error)
-	GetMonthlySchedule(doctorID uuid.UUID, year int, month int) ([]*models.DoctorAssignment, error)
-	Update(assignment *models.DoctorAssignment) error
-	Delete(id uuid.UUID) error
-	DeleteByDoctorBranchDate(doctorID uuid.UUID, branchID uuid.UUID, date time.Time) error
-	GetDoctorCountByBranch(branchID uuid.UUID, date time.Time) (int, error)
-	GetDoctorsByBranchAndDate(branchID uuid.UUID, date time.Time) ([]*models.DoctorAssignment, error)
+	Create(ctx context.Context, assignment *models.DoctorAssignment) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorAssignment, error)
+	GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.DoctorAssignment, error)
+	// GetRosterByDateRange returns only the stored roster rows, whatever the window
+	GetRosterByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.DoctorAssignment, error)
+	GetByBranchID(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorAssignment, error)
+	GetByDate(ctx context.Context, date time.Time) ([]*models.DoctorAssignment, error)
+	GetByDoctorID(ctx context.Context, doctorID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorAssignment, error)
+	GetMonthlySchedule(ctx context.Context, doctorID uuid.UUID, year int, month int) ([]*models.DoctorAssignment, error)
+	Update(ctx context.Context, assignment *models.DoctorAssignment) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByDoctorBranchDate(ctx context.Context, doctorID uuid.UUID, branchID uuid.UUID, date time.Time) error
+	GetDoctorCountByBranch(ctx context.Context, branchID uuid.UUID, date time.Time) (int, error)
+	GetDoctorsByBranchAndDate(ctx context.Context, branchID uuid.UUID, date time.Time) ([]*models.DoctorAssignment, error)
 }
 
 type DoctorOnOffDayRepository interface {
-	Create(day *models.DoctorOnOffDay) error
-	GetByID(id uuid.UUID) (*models.DoctorOnOffDay, error)
-	GetByBranchID(branchID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorOnOffDay, error)
-	GetByDate(date time.Time) ([]*models.DoctorOnOffDay, error)
-	Update(day *models.DoctorOnOffDay) error
-	Delete(id uuid.UUID) error
-	GetByBranchAndDate(branchID uuid.UUID, date time.Time) (*models.DoctorOnOffDay, error)
-}
-
+	Create(ctx context.Context, day *models.DoctorOnOffDay) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorOnOffDay, error)
+	GetByBranchID(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorOnOffDay, error)
+	GetByDate(ctx context.Context, date time.Time) ([]*models.DoctorOnOffDay, error)
+	// GetByDateRange returns the designations of every branch from startDate to endDate
+	GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.DoctorOnOffDay, error)
+	Update(ctx context.Context, day *models.DoctorOnOffDay) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	GetByBranchAndDate(ctx context.Context, branchID uuid.UUID, date time.Time) (*models.DoctorOnOffDay, error)
+}
+
+// DoctorDefaultScheduleRepository stores the doctors' weekday schedules. A
+// doctor has at most one every-week schedule per weekday and slot; restricted
+// schedules (see models.ScheduleRecurrence) may be added alongside.
 type DoctorDefaultScheduleRepository interface {
-	Create(schedule *models.DoctorDefaultSchedule) error
-	GetByID(id uuid.UUID) (*models.DoctorDefaultSchedule, error)
-	GetByDoctorID(doctorID uuid.UUID) ([]*models.DoctorDefaultSchedule, error)
-	GetByDoctorAndDayOfWeek(doctorID uuid.UUID, dayOfWeek int) (*models.DoctorDefaultSchedule, error)
-	Update(schedule *models.DoctorDefaultSchedule) error
-	Delete(id uuid.UUID) error
-	DeleteByDoctorID(doctorID uuid.UUID) error
-	Upsert(schedule *models.DoctorDefaultSchedule) error
-}
-
+	Create(ctx context.Context, schedule *models.DoctorDefaultSchedule) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorDefaultSchedule, error)
+	GetByDoctorID(ctx context.Context, doctorID uuid.UUID) ([]*models.DoctorDefaultSchedule, error)
+	// GetByDoctorAndDayOfWeek returns every schedule of the doctor on the weekday
+	GetByDoctorAndDayOfWeek(ctx context.Context, doctorID uuid.UUID, dayOfWeek int) ([]*models.DoctorDefaultSchedule, error)
+	List(ctx context.Context) ([]*models.DoctorDefaultSchedule, error)
+	// Update changes the branch, slot and recurrence of the schedule
+	Update(ctx context.Context, schedule *models.DoctorDefaultSchedule) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByDoctorID(ctx context.Context, doctorID uuid.UUID) error
+	// Upsert replaces the branch of the doctor's every-week schedule for the
+	// weekday and slot, or creates it; restricted schedules are always created
+	Upsert(ctx context.Context, schedule *models.DoctorDefaultSchedule) error
+}
+
+// DoctorWeeklyOffDayRepository stores the doctors' weekday off days, at most
+// one every-week off day per weekday plus any restricted ones
 type DoctorWeeklyOffDayRepository interface {
-	Create(offDay *models.DoctorWeeklyOffDay) error
-	GetByID(id uuid.UUID) (*models.DoctorWeeklyOffDay, error)
-	GetByDoctorID(doctorID uuid.UUID) ([]*models.DoctorWeeklyOffDay, error)
-	GetByDoctorAndDayOfWeek(doctorID uuid.UUID, dayOfWeek int) (*models.DoctorWeeklyOffDay, error)
-	Delete(id uuid.UUID) error
-	DeleteByDoctorID(doctorID uuid.UUID) error
-	DeleteByDoctorAndDayOfWeek(doctorID uuid.UUID, dayOfWeek int) error
+	Create(ctx context.Context, offDay *models.DoctorWeeklyOffDay) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorWeeklyOffDay, error)
+	GetByDoctorID(ctx context.Context, doctorID uuid.UUID) ([]*models.DoctorWeeklyOffDay, error)
+	// GetByDoctorAndDayOfWeek returns every off day of the doctor on the weekday
+	GetByDoctorAndDayOfWeek(ctx context.Context, doctorID uuid.UUID, dayOfWeek int) ([]*models.DoctorWeeklyOffDay, error)
+	List(ctx context.Context) ([]*models.DoctorWeeklyOffDay, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByDoctorID(ctx context.Context, doctorID uuid.UUID) error
+	DeleteByDoctorAndDayOfWeek(ctx context.Context, doctorID uuid.UUID, dayOfWeek int) error
 }
 
 type DoctorScheduleOverrideRepository interface {
-	Create(override *models.DoctorScheduleOverride) error
-	GetByID(id uuid.UUID) (*models.DoctorScheduleOverride, error)
-	GetByDoctorID(doctorID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorScheduleOverride, error)
-	GetByDoctorAndDate(doctorID uuid.UUID, date time.Time) (*models.DoctorScheduleOverride, error)
-	Update(override *models.DoctorScheduleOverride) error
-	Delete(id uuid.UUID) error
-	DeleteByDoctorID(doctorID uuid.UUID) error
+	Create(ctx context.Context, override *models.DoctorScheduleOverride) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorScheduleOverride, error)
+	GetByDoctorID(ctx context.Context, doctorID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorScheduleOverride, error)
+	GetByDoctorAndDate(ctx context.Context, doctorID uuid.UUID, date time.Time) (*models.DoctorScheduleOverride, error)
+	GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.DoctorScheduleOverride, error)
+	Update(ctx context.Context, override *models.DoctorScheduleOverride) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByDoctorID(ctx context.Context, doctorID uuid.UUID) error
+}
+
+type DoctorRevenueProfileRepository interface {
+	// Upsert creates or replaces the profile for the doctor, branch (or none) and weekday
+	Upsert(ctx context.Context, profile *models.DoctorRevenueProfile) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorRevenueProfile, error)
+	GetByDoctorID(ctx context.Context, doctorID uuid.UUID) ([]*models.DoctorRevenueProfile, error)
+	List(ctx context.Context) ([]*models.DoctorRevenueProfile, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type DoctorRevenueOverrideRepository interface {
+	// Upsert creates or replaces the override for the doctor and date
+	Upsert(ctx context.Context, override *models.DoctorRevenueOverride) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorRevenueOverride, error)
+	GetByDoctorID(ctx context.Context, doctorID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorRevenueOverride, error)
+	GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.DoctorRevenueOverride, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// DoctorLeaveRepository stores doctors' leave and the substitutes covering it.
+// Leaves are returned with doctor names; substitutes with doctor names and
+// branch codes.
+type DoctorLeaveRepository interface {
+	Create(ctx context.Context, leave *models.DoctorLeave) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorLeave, error)
+	// List returns leaves ordered by start date, overlapping the filter's dates
+	List(ctx context.Context, filters DoctorLeaveFilters) ([]*models.DoctorLeave, error)
+	// Update changes the dates, reason, status and review of the leave
+	Update(ctx context.Context, leave *models.DoctorLeave) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	CreateSubstitute(ctx context.Context, substitute *models.DoctorLeaveSubstitute) error
+	GetSubstituteByID(ctx context.Context, id uuid.UUID) (*models.DoctorLeaveSubstitute, error)
+	// GetSubstitutes returns the substitutes of a leave ordered by date and branch code
+	GetSubstitutes(ctx context.Context, leaveID uuid.UUID) ([]*models.DoctorLeaveSubstitute, error)
+	DeleteSubstitute(ctx context.Context, id uuid.UUID) error
+}
+
+type DoctorLeaveFilters struct {
+	DoctorID  *uuid.UUID
+	Status    string // any status when empty
+	StartDate *time.Time
+	EndDate   *time.Time
+}
+
+// BranchClosureRepository stores branch closures and public holidays
+type BranchClosureRepository interface {
+	Create(ctx context.Context, closure *models.BranchClosure) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.BranchClosure, error)
+	// List returns closures ordered by start date, overlapping the filter's dates
+	List(ctx context.Context, filters BranchClosureFilters) ([]*models.BranchClosure, error)
+	// Update changes the branch, dates and reason of the closure
+	Update(ctx context.Context, closure *models.BranchClosure) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type BranchClosureFilters struct {
+	BranchID  *uuid.UUID // the branch's own closures and every holiday
+	StartDate *time.Time
+	EndDate   *time.Time
+}
+
+// DoctorRosterRepository records the runs that materialize the doctor roster
+// and the changes they found
+type DoctorRosterRepository interface {
+	CreateRun(ctx context.Context, run *models.DoctorRosterRun) error
+	ListRuns(ctx context.Context, limit int) ([]*models.DoctorRosterRun, error)
+	// Window returns the dates covered by any run, nil before the first run
+	Window(ctx context.Context) (*models.DoctorRosterWindow, error)
+	CreateChange(ctx context.Context, change *models.DoctorRosterChange) error
+	// ListChanges returns changes newest first with doctor names and branch codes
+	ListChanges(ctx context.Context, filters DoctorRosterChangeFilters) ([]*models.DoctorRosterChange, error)
+}
+
+type DoctorRosterChangeFilters struct {
+	RunID     *uuid.UUID
+	DoctorID  *uuid.UUID
+	BranchID  *uuid.UUID // matches the branch moved from or to
+	StartDate *time.Time
+	EndDate   *time.Time
+	Limit     int // 0 for no limit
 }
 
 type BranchWeeklyRevenueRepository interface {
-	Create(revenue *models.BranchWeeklyRevenue) error
-	Update(revenue *models.BranchWeeklyRevenue) error
-	GetByID(id uuid.UUID) (*models.BranchWeeklyRevenue, error)
-	GetByBranchID(branchID uuid.UUID) ([]*models.BranchWeeklyRevenue, error)
-	GetByBranchIDAndDayOfWeek(branchID uuid.UUID, dayOfWeek int) (*models.BranchWeeklyRevenue, error)
-	Delete(id uuid.UUID) error
-	BulkUpsert(revenues []*models.BranchWeeklyRevenue) error
+	Create(ctx context.Context, revenue *models.BranchWeeklyRevenue) error
+	Update(ctx context.Context, revenue *models.BranchWeeklyRevenue) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.BranchWeeklyRevenue, error)
+	GetByBranchID(ctx context.Context, branchID uuid.UUID) ([]*models.BranchWeeklyRevenue, error)
+	GetByBranchIDAndDayOfWeek(ctx context.Context, branchID uuid.UUID, dayOfWeek int) (*models.BranchWeeklyRevenue, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	BulkUpsert(ctx context.Context, revenues []*models.BranchWeeklyRevenue) error
 }
 
 type BranchConstraintsRepository interface {
-	Create(constraint *models.BranchConstraints) error
-	Update(constraint *models.BranchConstraints) error
-	GetByID(id uuid.UUID) (*models.BranchConstraints, error)
-	GetByBranchID(branchID uuid.UUID) ([]*models.BranchConstraints, error)
-	GetByBranchIDAndDayOfWeek(branchID uuid.UUID, dayOfWeek int) (*models.BranchConstraints, error)
-	Delete(id uuid.UUID) error
-	BulkUpsert(constraints []*models.BranchConstraints) error
+	Create(ctx context.Context, constraint *models.BranchConstraints) error
+	Update(ctx context.Context, constraint *models.BranchConstraints) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.BranchConstraints, error)
+	GetByBranchID(ctx context.Context, branchID uuid.UUID) ([]*models.BranchConstraints, error)
+	GetByBranchIDAndDayOfWeek(ctx context.Context, branchID uuid.UUID, dayOfWeek int) (*models.BranchConstraints, error)
+	GetByBranchIDs(ctx context.Context, branchIDs []uuid.UUID) ([]*models.BranchConstraints, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	BulkUpsert(ctx context.Context, constraints []*models.BranchConstraints) error
 	// Load staff group requirements for constraints
-	LoadStaffGroupRequirements(constraints []*models.BranchConstraints) error
+	LoadStaffGroupRequirements(ctx context.Context, constraints []*models.BranchConstraints) error
 	// Bulk upsert with staff group requirements
-	BulkUpsertWithStaffGroups(constraints []*models.BranchConstraints) error
+	BulkUpsertWithStaffGroups(ctx context.Context, constraints []*models.BranchConstraints) error
 }
 
 type BranchConstraintStaffGroupRepository interface {
-	Create(sg *models.BranchConstraintStaffGroup) error
-	GetByConstraintID(constraintID uuid.UUID) ([]*models.BranchConstraintStaffGroup, error)
-	GetByBranchID(branchID uuid.UUID) ([]*models.BranchConstraintStaffGroup, error)
-	Delete(id uuid.UUID) error
-	DeleteByConstraintID(constraintID uuid.UUID) error
-	BulkUpsert(staffGroups []*models.BranchConstraintStaffGroup) error
+	Create(ctx context.Context, sg *models.BranchConstraintStaffGroup) error
+	GetByConstraintID(ctx context.Context, constraintID uuid.UUID) ([]*models.BranchConstraintStaffGroup, error)
+	GetByBranchID(ctx context.Context, branchID uuid.UUID) ([]*models.BranchConstraintStaffGroup, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByConstraintID(ctx context.Context, constraintID uuid.UUID) error
+	BulkUpsert(ctx context.Context, staffGroups []*models.BranchConstraintStaffGroup) error
 }
 
 type BranchTypeConstraintsRepository interface {
-	GetByID(id uuid.UUID) (*models.BranchTypeConstraints, error)
-	GetByBranchTypeID(branchTypeID uuid.UUID) ([]*models.BranchTypeConstraints, error)
-	GetByBranchTypeIDAndDayOfWeek(branchTypeID uuid.UUID, dayOfWeek int) (*models.BranchTypeConstraints, error)
-	Delete(id uuid.UUID) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.BranchTypeConstraints, error)
+	GetByBranchTypeID(ctx context.Context, branchTypeID uuid.UUID) ([]*models.BranchTypeConstraints, error)
+	GetByBranchTypeIDAndDayOfWeek(ctx context.Context, branchTypeID uuid.UUID, dayOfWeek int) (*models.BranchTypeConstraints, error)
+	List(ctx context.Context) ([]*models.BranchTypeConstraints, error)
+	Delete(ctx context.Context, id uuid.UUID) error
 	// Load staff group requirements for constraints
-	LoadStaffGroupRequirements(constraints []*models.BranchTypeConstraints) error
+	LoadStaffGroupRequirements(ctx context.Context, constraints []*models.BranchTypeConstraints) error
 	// Bulk upsert with staff group requirements
-	BulkUpsertWithStaffGroups(constraints []*models.BranchTypeConstraints) error
+	BulkUpsertWithStaffGroups(ctx context.Context, constraints []*models.BranchTypeConstraints) error
 }
 
 type BranchTypeConstraintStaffGroupRepository interface {
-	Create(sg *models.BranchTypeConstraintStaffGroup) error
-	GetByConstraintID(constraintID uuid.UUID) ([]*models.BranchTypeConstraintStaffGroup, error)
-	GetByBranchTypeID(branchTypeID uuid.UUID) ([]*models.BranchTypeConstraintStaffGroup, error)
-	Delete(id uuid.UUID) error
-	DeleteByConstraintID(constraintID uuid.UUID) error
-	BulkUpsert(staffGroups []*models.BranchTypeConstraintStaffGroup) error
+	Create(ctx context.Context, sg *models.BranchTypeConstraintStaffGroup) error
+	GetByConstraintID(ctx context.Context, constraintID uuid.UUID) ([]*models.BranchTypeConstraintStaffGroup, error)
+	GetByBranchTypeID(ctx context.Context, branchTypeID uuid.UUID) ([]*models.BranchTypeConstraintStaffGroup, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByConstraintID(ctx context.Context, constraintID uuid.UUID) error
+	BulkUpsert(ctx context.Context, staffGroups []*models.BranchTypeConstraintStaffGroup) error
 }
 
 type RevenueLevelTierRepository interface {
-	Create(tier *models.RevenueLevelTier) error
-	GetByID(id uuid.UUID) (*models.RevenueLevelTier, error)
-	GetByLevelNumber(levelNumber int) (*models.RevenueLevelTier, error)
-	Update(tier *models.RevenueLevelTier) error
-	Delete(id uuid.UUID) error
-	List() ([]*models.RevenueLevelTier, error)
-	GetTierForRevenue(revenue float64) (*models.RevenueLevelTier, error)
+	Create(ctx context.Context, tier *models.RevenueLevelTier) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.RevenueLevelTier, error)
+	GetByLevelNumber(ctx context.Context, levelNumber int) (*models.RevenueLevelTier, error)
+	Update(ctx context.Context, tier *models.RevenueLevelTier) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context) ([]*models.RevenueLevelTier, error)
+	GetTierForRevenue(ctx context.Context, revenue float64) (*models.RevenueLevelTier, error)
 }
 
 type StaffRequirementScenarioRepository interface {
-	Create(scenario *models.StaffRequirementScenario) error
-	GetByID(id uuid.UUID) (*models.StaffRequirementScenario, error)
-	Update(scenario *models.StaffRequirementScenario) error
-	Delete(id uuid.UUID) error
-	List(includeInactive bool) ([]*models.StaffRequirementScenario, error)
-	GetActiveOrderedByPriority() ([]*models.StaffRequirementScenario, error)
-	GetDefault() (*models.StaffRequirementScenario, error)
+	Create(ctx context.Context, scenario *models.StaffRequirementScenario) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.StaffRequirementScenario, error)
+	Update(ctx context.Context, scenario *models.StaffRequirementScenario) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, includeInactive bool) ([]*models.StaffRequirementScenario, error)
+	GetActiveOrderedByPriority(ctx context.Context) ([]*models.StaffRequirementScenario, error)
+	GetDefault(ctx context.Context) (*models.StaffRequirementScenario, error)
 }
 
 type ScenarioPositionRequirementRepository interface {
-	Create(requirement *models.ScenarioPositionRequirement) error
-	GetByID(id uuid.UUID) (*models.ScenarioPositionRequirement, error)
-	GetByScenarioID(scenarioID uuid.UUID) ([]*models.ScenarioPositionRequirement, error)
-	GetByScenarioAndPosition(scenarioID, positionID uuid.UUID) (*models.ScenarioPositionRequirement, error)
-	Update(requirement *models.ScenarioPositionRequirement) error
-	Delete(id uuid.UUID) error
-	DeleteByScenarioID(scenarioID uuid.UUID) error
-	BulkUpsert(requirements []*models.ScenarioPositionRequirement) error
+	Create(ctx context.Context, requirement *models.ScenarioPositionRequirement) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ScenarioPositionRequirement, error)
+	GetByScenarioID(ctx context.Context, scenarioID uuid.UUID) ([]*models.ScenarioPositionRequirement, error)
+	GetByScenarioAndPosition(ctx context.Context, scenarioID, positionID uuid.UUID) (*models.ScenarioPositionRequirement, error)
+	Update(ctx context.Context, requirement *models.ScenarioPositionRequirement) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByScenarioID(ctx context.Context, scenarioID uuid.UUID) error
+	BulkUpsert(ctx context.Context, requirements []*models.ScenarioPositionRequirement) error
 }
 
 type ScenarioSpecificStaffRequirementRepository interface {
-	Create(requirement *models.ScenarioSpecificStaffRequirement) error
-	GetByID(id uuid.UUID) (*models.ScenarioSpecificStaffRequirement, error)
-	GetByScenarioID(scenarioID uuid.UUID) ([]*models.ScenarioSpecificStaffRequirement, error)
-	Delete(id uuid.UUID) error
-	DeleteByScenarioID(scenarioID uuid.UUID) error
-	BulkUpsert(requirements []*models.ScenarioSpecificStaffRequirement) error
+	Create(ctx context.Context, requirement *models.ScenarioSpecificStaffRequirement) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ScenarioSpecificStaffRequirement, error)
+	GetByScenarioID(ctx context.Context, scenarioID uuid.UUID) ([]*models.ScenarioSpecificStaffRequirement, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByScenarioID(ctx context.Context, scenarioID uuid.UUID) error
+	BulkUpsert(ctx context.Context, requirements []*models.ScenarioSpecificStaffRequirement) error
 }
 
 type ClinicWidePreferenceRepository interface {
-	Create(preference *models.ClinicWidePreference) error
-	GetByID(id uuid.UUID) (*models.ClinicWidePreference, error)
-	Update(preference *models.ClinicWidePreference) error
-	Delete(id uuid.UUID) error
-	List(filters models.ClinicPreferenceFilters) ([]*models.ClinicWidePreference, error)
-	GetByCriteriaTypeAndValue(criteriaType models.ClinicPreferenceCriteriaType, value float64) ([]*models.ClinicWidePreference, error)
+	Create(ctx context.Context, preference *models.ClinicWidePreference) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ClinicWidePreference, error)
+	Update(ctx context.Context, preference *models.ClinicWidePreference) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, filters models.ClinicPreferenceFilters) ([]*models.ClinicWidePreference, error)
+	GetByCriteriaTypeAndValue(ctx context.Context, criteriaType models.ClinicPreferenceCriteriaType, value float64) ([]*models.ClinicWidePreference, error)
 }
 
 type PreferencePositionRequirementRepository interface {
-	Create(requirement *models.PreferencePositionRequirement) error
-	GetByID(id uuid.UUID) (*models.PreferencePositionRequirement, error)
-	GetByPreferenceID(preferenceID uuid.UUID) ([]*models.PreferencePositionRequirement, error)
-	GetByPreferenceAndPosition(preferenceID, positionID uuid.UUID) (*models.PreferencePositionRequirement, error)
-	Update(requirement *models.PreferencePositionRequirement) error
-	Delete(id uuid.UUID) error
-	DeleteByPreferenceID(preferenceID uuid.UUID) error
-	BulkUpsert(requirements []*models.PreferencePositionRequirement) error
+	Create(ctx context.Context, requirement *models.PreferencePositionRequirement) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.PreferencePositionRequirement, error)
+	GetByPreferenceID(ctx context.Context, preferenceID uuid.UUID) ([]*models.PreferencePositionRequirement, error)
+	GetByPreferenceAndPosition(ctx context.Context, preferenceID, positionID uuid.UUID) (*models.PreferencePositionRequirement, error)
+	Update(ctx context.Context, requirement *models.PreferencePositionRequirement) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByPreferenceID(ctx context.Context, preferenceID uuid.UUID) error
+	BulkUpsert(ctx context.Context, requirements []*models.PreferencePositionRequirement) error
 }
 
 type BranchTypeRepository interface {
-	Create(branchType *models.BranchType) error
-	GetByID(id uuid.UUID) (*models.BranchType, error)
-	List() ([]*models.BranchType, error)
-	Update(branchType *models.BranchType) error
-	Delete(id uuid.UUID) error
+	Create(ctx context.Context, branchType *models.BranchType) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.BranchType, error)
+	List(ctx context.Context) ([]*models.BranchType, error)
+	Update(ctx context.Context, branchType *models.BranchType) error
+	Delete(ctx context.Context, id uuid.UUID) error
 }
 
 type StaffGroupRepository interface {
-	Create(staffGroup *models.StaffGroup) error
-	GetByID(id uuid.UUID) (*models.StaffGroup, error)
-	List() ([]*models.StaffGroup, error)
-	GetByPositionID(positionID uuid.UUID) ([]*models.StaffGroup, error)
-	Update(staffGroup *models.StaffGroup) error
-	Delete(id uuid.UUID) error
+	Create(ctx context.Context, staffGroup *models.StaffGroup) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.StaffGroup, error)
+	List(ctx context.Context) ([]*models.StaffGroup, error)
+	GetByPositionID(ctx context.Context, positionID uuid.UUID) ([]*models.StaffGroup, error)
+	Update(ctx context.Context, staffGroup *models.StaffGroup) error
+	Delete(ctx context.Context, id uuid.UUID) error
 }
 
 type StaffGroupPositionRepository interface {
-	Create(sgp *models.StaffGroupPosition) error
-	GetByID(id uuid.UUID) (*models.StaffGroupPosition, error)
-	GetByStaffGroupID(staffGroupID uuid.UUID) ([]*models.StaffGroupPosition, error)
-	GetByPositionID(positionID uuid.UUID) ([]*models.StaffGroupPosition, error)
-	Delete(id uuid.UUID) error
-	DeleteByStaffGroupAndPosition(staffGroupID uuid.UUID, positionID uuid.UUID) error
+	Create(ctx context.Context, sgp *models.StaffGroupPosition) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.StaffGroupPosition, error)
+	GetByStaffGroupID(ctx context.Context, staffGroupID uuid.UUID) ([]*models.StaffGroupPosition, error)
+	GetByPositionID(ctx context.Context, positionID uuid.UUID) ([]*models.StaffGroupPosition, error)
+	List(ctx context.Context) ([]*models.StaffGroupPosition, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByStaffGroupAndPosition(ctx context.Context, staffGroupID uuid.UUID, positionID uuid.UUID) error
 }
 
 type BranchTypeStaffGroupRequirementRepository interface {
-	Create(requirement *models.BranchTypeStaffGroupRequirement) error
-	GetByID(id uuid.UUID) (*models.BranchTypeStaffGroupRequirement, error)
-	GetByBranchTypeID(branchTypeID uuid.UUID) ([]*models.BranchTypeStaffGroupRequirement, error)
-	GetByStaffGroupID(staffGroupID uuid.UUID) ([]*models.BranchTypeStaffGroupRequirement, error)
-	Update(requirement *models.BranchTypeStaffGroupRequirement) error
-	Delete(id uuid.UUID) error
-	BulkUpsert(requirements []*models.BranchTypeStaffGroupRequirement) error
+	Create(ctx context.Context, requirement *models.BranchTypeStaffGroupRequirement) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.BranchTypeStaffGroupRequirement, error)
+	GetByBranchTypeID(ctx context.Context, branchTypeID uuid.UUID) ([]*models.BranchTypeStaffGroupRequirement, error)
+	GetByStaffGroupID(ctx context.Context, staffGroupID uuid.UUID) ([]*models.BranchTypeStaffGroupRequirement, error)
+	List(ctx context.Context) ([]*models.BranchTypeStaffGroupRequirement, error)
+	Update(ctx context.Context, requirement *models.BranchTypeStaffGroupRequirement) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	BulkUpsert(ctx context.Context, requirements []*models.BranchTypeStaffGroupRequirement) error
 }
 
 type SpecificPreferenceRepository interface {
-	Create(preference *models.SpecificPreference) error
-	GetByID(id uuid.UUID) (*models.SpecificPreference, error)
-	List(filters SpecificPreferenceFilters) ([]*models.SpecificPreference, error)
-	GetMatchingPreferences(branchID *uuid.UUID, doctorID *uuid.UUID, dayOfWeek *int) ([]*models.SpecificPreference, error)
-	Update(preference *models.SpecificPreference) error
-	Delete(id uuid.UUID) error
+	Create(ctx context.Context, preference *models.SpecificPreference) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.SpecificPreference, error)
+	List(ctx context.Context, filters SpecificPreferenceFilters) ([]*models.SpecificPreference, error)
+	GetMatchingPreferences(ctx context.Context, branchID *uuid.UUID, doctorID *uuid.UUID, dayOfWeek *int) ([]*models.SpecificPreference, error)
+	Update(ctx context.Context, preference *models.SpecificPreference) error
+	Delete(ctx context.Context, id uuid.UUID) error
 }
 
 type SpecificPreferenceFilters struct {
@@ -439,25 +564,25 @@ type SpecificPreferenceFilters struct {
 }
 
 type RotationStaffBranchPositionRepository interface {
-	Create(mapping *models.RotationStaffBranchPosition) error
-	GetByID(id uuid.UUID) (*models.RotationStaffBranchPosition, error)
-	List() ([]*models.RotationStaffBranchPosition, error)
-	GetByStaffID(rotationStaffID uuid.UUID) ([]*models.RotationStaffBranchPosition, error)
-	GetByPositionID(branchPositionID uuid.UUID) ([]*models.RotationStaffBranchPosition, error)
-	GetByStaffAndPosition(rotationStaffID uuid.UUID, branchPositionID uuid.UUID) (*models.RotationStaffBranchPosition, error)
-	Update(mapping *models.RotationStaffBranchPosition) error
-	Delete(id uuid.UUID) error
-	DeleteByStaffAndPosition(rotationStaffID uuid.UUID, branchPositionID uuid.UUID) error
+	Create(ctx context.Context, mapping *models.RotationStaffBranchPosition) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.RotationStaffBranchPosition, error)
+	List(ctx context.Context) ([]*models.RotationStaffBranchPosition, error)
+	GetByStaffID(ctx context.Context, rotationStaffID uuid.UUID) ([]*models.RotationStaffBranchPosition, error)
+	GetByPositionID(ctx context.Context, branchPositionID uuid.UUID) ([]*models.RotationStaffBranchPosition, error)
+	GetByStaffAndPosition(ctx context.Context, rotationStaffID uuid.UUID, branchPositionID uuid.UUID) (*models.RotationStaffBranchPosition, error)
+	Update(ctx context.Context, mapping *models.RotationStaffBranchPosition) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	DeleteByStaffAndPosition(ctx context.Context, rotationStaffID uuid.UUID, branchPositionID uuid.UUID) error
 }
 
 type AllocationSuggestionRepository interface {
-	Create(suggestion *models.AllocationSuggestion) error
-	GetByID(id uuid.UUID) (*models.AllocationSuggestion, error)
-	Update(suggestion *models.AllocationSuggestion) error
-	Delete(id uuid.UUID) error
-	List(filters AllocationSuggestionFilters) ([]*models.AllocationSuggestion, error)
-	GetByBranchID(branchID uuid.UUID, startDate, endDate time.Time) ([]*models.AllocationSuggestion, error)
-	GetByDateRange(startDate, endDate time.Time) ([]*models.AllocationSuggestion, error)
+	Create(ctx context.Context, suggestion *models.AllocationSuggestion) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.AllocationSuggestion, error)
+	Update(ctx context.Context, suggestion *models.AllocationSuggestion) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, filters AllocationSuggestionFilters) ([]*models.AllocationSuggestion, error)
+	GetByBranchID(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) ([]*models.AllocationSuggestion, error)
+	GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.AllocationSuggestion, error)
 }
 
 type AllocationSuggestionFilters struct {
@@ -470,8 +595,76 @@ type AllocationSuggestionFilters struct {
 }
 
 type BranchQuotaSummaryRepository interface {
-	GetByBranchIDAndDate(branchID uuid.UUID, date time.Time) (*models.BranchQuotaSummary, error)
-	GetByBranchIDsAndDate(branchIDs []uuid.UUID, date time.Time) ([]*models.BranchQuotaSummary, error)
-	Recalculate(branchID uuid.UUID, date time.Time) error
-	RecalculateForDateRange(branchID uuid.UUID, startDate, endDate time.Time) error
+	GetByBranchIDAndDate(ctx context.Context, branchID uuid.UUID, date time.Time) (*models.BranchQuotaSummary, error)
+	GetByBranchIDsAndDate(ctx context.Context, branchIDs []uuid.UUID, date time.Time) ([]*models.BranchQuotaSummary, error)
+	GetByBranchIDsAndDateRange(ctx context.Context, branchIDs []uuid.UUID, startDate, endDate time.Time) ([]*models.BranchQuotaSummary, error)
+	Upsert(ctx context.Context, summary *models.BranchQuotaSummary) error
+	// MarkDirty queues every day of the branch between startDate and endDate for recomputation
+	MarkDirty(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) error
+	// MarkStale queues the existing summaries of branchID on date; uuid.Nil and the zero date match all
+	MarkStale(ctx context.Context, branchID uuid.UUID, date time.Time) error
+	// ListDirty returns up to limit keys untouched for debounce, or first marked at least maxWait ago
+	ListDirty(ctx context.Context, debounce, maxWait time.Duration, limit int) ([]*models.QuotaSummaryDirtyKey, error)
+	GetDirtyByBranchIDsAndDateRange(ctx context.Context, branchIDs []uuid.UUID, startDate, endDate time.Time) ([]*models.QuotaSummaryDirtyKey, error)
+	// ClearDirty removes the keys unless they were marked again after being listed
+	ClearDirty(ctx context.Context, keys []*models.QuotaSummaryDirtyKey) error
+}
+
+type JobRepository interface {
+	// Create queues a job; input is the file it processes, if any
+	Create(ctx context.Context, job *models.Job, input *models.JobFile) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Job, error)
+	List(ctx context.Context, filters JobFilters) ([]*models.Job, error)
+	GetInput(ctx context.Context, id uuid.UUID) (*models.JobFile, error)
+	GetResultFile(ctx context.Context, id uuid.UUID) (*models.JobFile, error)
+	// Claim marks the oldest due queued job of one of types as running by workerID, or returns nil
+	Claim(ctx context.Context, workerID string, types []string) (*models.Job, error)
+	// Heartbeat records progress of a running job and reports whether cancellation was requested
+	Heartbeat(ctx context.Context, id uuid.UUID, progress int, message string) (cancelRequested bool, err error)
+	Complete(ctx context.Context, id uuid.UUID, result []byte, file *models.JobFile) error
+	// Fail records err; a non-nil retryAt queues the job again instead of failing it
+	Fail(ctx context.Context, id uuid.UUID, errMsg string, retryAt *time.Time) error
+	MarkCancelled(ctx context.Context, id uuid.UUID) error
+	// RequestCancel cancels a queued job outright and flags a running one; finished jobs are left alone
+	RequestCancel(ctx context.Context, id uuid.UUID) (*models.Job, error)
+	// RequeueStale queues again running jobs whose heartbeat is older than staleAfter, failing those out of attempts
+	RequeueStale(ctx context.Context, staleAfter time.Duration) (int, error)
+	DeleteFinishedBefore(ctx context.Context, before time.Time) (int, error)
+	// CountByStatus returns how many jobs are in each status
+	CountByStatus(ctx context.Context) (map[models.JobStatus]int, error)
+}
+
+type JobFilters struct {
+	Type      string
+	Status    *models.JobStatus
+	CreatedBy *uuid.UUID
+	Limit     int
+}
+
+type JobScheduleRepository interface {
+	List(ctx context.Context) ([]*models.JobSchedule, error)
+	GetByName(ctx context.Context, name string) (*models.JobSchedule, error)
+	// Ensure creates the schedule, or updates its job and cron keeping Enabled.
+	// NextRunAt is only replaced when the cron expression changes.
+	Ensure(ctx context.Context, schedule *models.JobSchedule) error
+	SetEnabled(ctx context.Context, name string, enabled bool) error
+	// Advance moves a due schedule to nextRunAt, reporting false if another runner advanced it first
+	Advance(ctx context.Context, name string, dueAt, ranAt, nextRunAt time.Time) (bool, error)
+}
+
+type ServiceAccountRepository interface {
+	Create(ctx context.Context, account *models.ServiceAccount) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.ServiceAccount, error)
+	List(ctx context.Context) ([]*models.ServiceAccount, error)
+	Update(ctx context.Context, account *models.ServiceAccount) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type APITokenRepository interface {
+	Create(ctx context.Context, token *models.APIToken) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.APIToken, error)
+	GetByHash(ctx context.Context, tokenHash string) (*models.APIToken, error)
+	List(ctx context.Context, serviceAccountID *uuid.UUID) ([]*models.APIToken, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	TouchLastUsed(ctx context.Context, id uuid.UUID, usedAt time.Time) error
 }
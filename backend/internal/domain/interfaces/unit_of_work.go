@@ -0,0 +1,73 @@
+package interfaces
+
+import "context"
+
+// Repositories is the full set of repositories. A storage backend builds one bound
+// to its connection pool, and a UnitOfWork builds one bound to a transaction.
+type Repositories struct {
+	User                             UserRepository
+	UserIdentity                     UserIdentityRepository
+	Role                             RoleRepository
+	Staff                            StaffRepository
+	Position                         PositionRepository
+	Branch                           BranchRepository
+	EffectiveBranch                  EffectiveBranchRepository
+	Revenue                          RevenueRepository
+	Schedule                         ScheduleRepository
+	Rotation                         RotationRepository
+	RotationStaffSchedule            RotationStaffScheduleRepository
+	Settings                         SettingsRepository
+	AllocationRule                   AllocationRuleRepository
+	AreaOfOperation                  AreaOfOperationRepository
+	Zone                             ZoneRepository
+	AllocationCriteria               AllocationCriteriaRepository
+	PositionQuota                    PositionQuotaRepository
+	Doctor                           DoctorRepository
+	DoctorPreference                 DoctorPreferenceRepository
+	DoctorAssignment                 DoctorAssignmentRepository
+	DoctorOnOffDay                   DoctorOnOffDayRepository
+	DoctorDefaultSchedule            DoctorDefaultScheduleRepository
+	DoctorWeeklyOffDay               DoctorWeeklyOffDayRepository
+	DoctorScheduleOverride           DoctorScheduleOverrideRepository
+	DoctorRevenueProfile             DoctorRevenueProfileRepository
+	DoctorRevenueOverride            DoctorRevenueOverrideRepository
+	DoctorLeave                      DoctorLeaveRepository
+	DoctorRoster                     DoctorRosterRepository
+	BranchWeeklyRevenue              BranchWeeklyRevenueRepository
+	BranchConstraints                BranchConstraintsRepository
+	BranchClosure                    BranchClosureRepository
+	RevenueLevelTier                 RevenueLevelTierRepository
+	StaffRequirementScenario         StaffRequirementScenarioRepository
+	ScenarioPositionRequirement      ScenarioPositionRequirementRepository
+	ScenarioSpecificStaffRequirement ScenarioSpecificStaffRequirementRepository
+	BranchType                       BranchTypeRepository
+	StaffGroup                       StaffGroupRepository
+	StaffGroupPosition               StaffGroupPositionRepository
+	BranchTypeRequirement            BranchTypeStaffGroupRequirementRepository
+	BranchTypeConstraints            BranchTypeConstraintsRepository
+	SpecificPreference               SpecificPreferenceRepository
+	ClinicWidePreference             ClinicWidePreferenceRepository
+	PreferencePositionRequirement    PreferencePositionRequirementRepository
+	RotationStaffBranchPosition      RotationStaffBranchPositionRepository
+	AllocationSuggestion             AllocationSuggestionRepository
+	BranchQuotaSummary               BranchQuotaSummaryRepository
+	ServiceAccount                   ServiceAccountRepository
+	APIToken                         APITokenRepository
+	Job                              JobRepository
+	JobSchedule                      JobScheduleRepository
+}
+
+// UnitOfWork composes several repository calls into one commit or rollback
+type UnitOfWork interface {
+	// Begin starts a transaction whose Repositories() are bound to it
+	Begin(ctx context.Context) (Transaction, error)
+	// Do runs fn in a transaction: returning nil commits, an error or panic rolls back
+	Do(ctx context.Context, fn func(tx *Repositories) error) error
+}
+
+// Transaction is an open unit of work. Rollback after Commit is a no-op, so it can be deferred.
+type Transaction interface {
+	Repositories() *Repositories
+	Commit() error
+	Rollback() error
+}
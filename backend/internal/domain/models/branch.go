@@ -15,10 +15,23 @@ type Branch struct {
 	BranchTypeID  *uuid.UUID  `json:"branch_type_id,omitempty" db:"branch_type_id"`
 	BranchType    *BranchType `json:"branch_type,omitempty"`
 	Priority      int         `json:"priority" db:"priority"`
+	MaxDoctors    *int        `json:"max_doctors,omitempty" db:"max_doctors"` // nil = DefaultMaxDoctorsPerBranch
 	CreatedAt     time.Time   `json:"created_at" db:"created_at"`
 	UpdatedAt     time.Time   `json:"updated_at" db:"updated_at"`
 }
 
+// DefaultMaxDoctorsPerBranch is how many doctors a branch can take on a day
+// unless it sets its own capacity
+const DefaultMaxDoctorsPerBranch = 6
+
+// DoctorCapacity returns how many doctors the branch can take on a day
+func (b *Branch) DoctorCapacity() int {
+	if b.MaxDoctors != nil && *b.MaxDoctors > 0 {
+		return *b.MaxDoctors
+	}
+	return DefaultMaxDoctorsPerBranch
+}
+
 type RevenueData struct {
 	ID       uuid.UUID `json:"id" db:"id"`
 	BranchID uuid.UUID `json:"branch_id" db:"branch_id"`
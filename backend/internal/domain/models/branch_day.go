@@ -0,0 +1,111 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BranchClosure closes a branch from StartDate to EndDate, inclusive, for
+// renovation, an event or similar. Without a BranchID it is a public holiday
+// closing every branch.
+type BranchClosure struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	BranchID   *uuid.UUID `json:"branch_id,omitempty" db:"branch_id"` // nil = every branch
+	BranchCode string     `json:"branch_code,omitempty" db:"branch_code"`
+	StartDate  time.Time  `json:"start_date" db:"start_date"`
+	EndDate    time.Time  `json:"end_date" db:"end_date"`
+	Reason     string     `json:"reason" db:"reason"`
+	CreatedBy  uuid.UUID  `json:"created_by" db:"created_by"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// IsHoliday reports whether the closure applies to every branch
+func (c *BranchClosure) IsHoliday() bool {
+	return c.BranchID == nil
+}
+
+// Closes reports whether the closure closes branchID on date
+func (c *BranchClosure) Closes(branchID uuid.UUID, date time.Time) bool {
+	if c.BranchID != nil && *c.BranchID != branchID {
+		return false
+	}
+	day := civilDate(date)
+	return !day.Before(civilDate(c.StartDate)) && !day.After(civilDate(c.EndDate))
+}
+
+// Branch day states, from the branch's point of view on one date
+const (
+	// BranchDayOpen is an open branch with no doctor rostered and no designation
+	BranchDayOpen = "open"
+	// BranchDayDoctorOn is an open branch with doctors rostered or designated
+	BranchDayDoctorOn = "doctor_on"
+	// BranchDayDoctorOff is an open branch designated as a doctor-off day
+	BranchDayDoctorOff = "doctor_off"
+	// BranchDayClosed is a branch closure or public holiday
+	BranchDayClosed = "closed"
+)
+
+// BranchDayStatus is the operational state of a branch on a date, resolved
+// from the doctor roster, the branch manager's doctor-on/off designation and
+// branch closures. Only doctor-on days need staff allocated. Mismatch
+// describes a designation or closure the roster disagrees with.
+type BranchDayStatus struct {
+	BranchID      uuid.UUID `json:"branch_id"`
+	BranchCode    string    `json:"branch_code,omitempty"`
+	Date          time.Time `json:"date"`
+	State         string    `json:"state"`
+	DoctorCount   int       `json:"doctor_count"`          // doctors on the roster
+	Designation   *bool     `json:"designation,omitempty"` // the DoctorOnOffDay's IsDoctorOn, if designated
+	Holiday       bool      `json:"holiday,omitempty"`
+	ClosureReason string    `json:"closure_reason,omitempty"`
+	Mismatch      string    `json:"mismatch,omitempty"`
+}
+
+// ResolveBranchDayStatus combines the inputs of one branch day into its
+// status. A closure takes precedence over the designation, and the
+// designation over the roster.
+func ResolveBranchDayStatus(branchID uuid.UUID, date time.Time, doctorCount int, designation *DoctorOnOffDay, closure *BranchClosure) *BranchDayStatus {
+	status := &BranchDayStatus{BranchID: branchID, Date: date, DoctorCount: doctorCount}
+	if designation != nil {
+		on := designation.IsDoctorOn
+		status.Designation = &on
+	}
+
+	switch {
+	case closure != nil:
+		status.State = BranchDayClosed
+		status.Holiday = closure.IsHoliday()
+		status.ClosureReason = closure.Reason
+		if doctorCount > 0 {
+			status.Mismatch = fmt.Sprintf("closed but %d doctor(s) rostered", doctorCount)
+		}
+	case designation != nil && !designation.IsDoctorOn:
+		status.State = BranchDayDoctorOff
+		if doctorCount > 0 {
+			status.Mismatch = fmt.Sprintf("designated doctor-off but %d doctor(s) rostered", doctorCount)
+		}
+	case designation != nil:
+		status.State = BranchDayDoctorOn
+		if doctorCount == 0 {
+			status.Mismatch = "designated doctor-on but no doctor rostered"
+		}
+	case doctorCount > 0:
+		status.State = BranchDayDoctorOn
+	default:
+		status.State = BranchDayOpen
+	}
+	return status
+}
+
+// IsOpen reports whether the branch opens at all
+func (s *BranchDayStatus) IsOpen() bool {
+	return s.State != BranchDayClosed
+}
+
+// IsOperational reports whether the branch runs a doctor day and so needs staff allocated
+func (s *BranchDayStatus) IsOperational() bool {
+	return s.State == BranchDayDoctorOn
+}
@@ -39,3 +39,12 @@ type PositionQuotaSummary struct {
 	StillRequired     int       `json:"still_required" db:"still_required"`
 	CalculatedAt      time.Time `json:"calculated_at" db:"calculated_at"`
 }
+
+// QuotaSummaryDirtyKey marks a branch and date whose quota summary must be recomputed.
+// MarkedAt moves with every change while FirstMarkedAt bounds how long debouncing may defer it.
+type QuotaSummaryDirtyKey struct {
+	BranchID      uuid.UUID `json:"branch_id" db:"branch_id"`
+	Date          time.Time `json:"date" db:"date"`
+	FirstMarkedAt time.Time `json:"first_marked_at" db:"first_marked_at"`
+	MarkedAt      time.Time `json:"marked_at" db:"marked_at"`
+}
@@ -60,43 +60,20 @@ type SpecificStaffRequirement struct {
 	PositionID uuid.UUID `json:"position_id"`
 }
 
-// ParseRotationStaffRequirementRule parses the rule_config JSONB field for rotation staff requirement rules
+// ParseRotationStaffRequirementRule strictly decodes and validates the rule_config of a rotation staff requirement rule
 func (dp *DoctorPreference) ParseRotationStaffRequirementRule() (*RotationStaffRequirementRuleConfig, error) {
-	if dp.RuleType != "rotation_staff_requirement" {
+	if dp.RuleType != RuleRotationStaffRequirement {
 		return nil, fmt.Errorf("rule type is not rotation_staff_requirement")
 	}
-
-	config := &RotationStaffRequirementRuleConfig{}
-	// The RuleConfig is already a map[string]interface{}, we need to convert it
-	// This will be handled by the repository layer when unmarshaling JSONB
-	return config, nil
+	rule, err := dp.Rule()
+	if err != nil {
+		return nil, err
+	}
+	return rule.(*RotationStaffRequirementRuleConfig), nil
 }
 
 // ValidateRotationStaffRequirementRule validates the rotation staff requirement rule config
 func ValidateRotationStaffRequirementRule(config map[string]interface{}) error {
-	// Validate days_of_week
-	if days, ok := config["days_of_week"].([]interface{}); ok {
-		for _, day := range days {
-			if dayInt, ok := day.(float64); ok {
-				if dayInt < 0 || dayInt > 6 {
-					return fmt.Errorf("invalid day of week: %v (must be 0-6)", dayInt)
-				}
-			}
-		}
-	}
-
-	// Validate position_requirements
-	if posReqs, ok := config["position_requirements"].([]interface{}); ok {
-		for _, req := range posReqs {
-			if reqMap, ok := req.(map[string]interface{}); ok {
-				if minCount, ok := reqMap["min_count"].(float64); ok {
-					if minCount < 0 {
-						return fmt.Errorf("minimum count cannot be negative")
-					}
-				}
-			}
-		}
-	}
-
-	return nil
+	_, err := DecodePreferenceRule(RuleRotationStaffRequirement, config)
+	return err
 }
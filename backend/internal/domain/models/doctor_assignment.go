@@ -16,7 +16,13 @@ type DoctorAssignment struct {
 	BranchID        uuid.UUID `json:"branch_id" db:"branch_id"`
 	Branch          *Branch   `json:"branch,omitempty"`
 	Date            time.Time `json:"date" db:"date"`
+	Slot            string    `json:"slot,omitempty" db:"slot"`                // "full_day", "morning" or "afternoon"
 	ExpectedRevenue float64   `json:"expected_revenue" db:"expected_revenue"` // Doctor's expected revenue for this branch on this date
+	Source          string    `json:"source,omitempty" db:"source"`           // "default" or "override": what placed the doctor at the branch
+	// Revenue breaks ExpectedRevenue down by type; set when the doctor has a
+	// revenue profile or override for the date
+	Revenue         *DoctorRevenue `json:"revenue,omitempty"`
+	RevenueSource   string         `json:"revenue_source,omitempty"` // "override" or "profile"
 	CreatedBy       uuid.UUID `json:"created_by" db:"created_by"`
 	CreatedAt       time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
@@ -36,6 +42,8 @@ type DoctorOnOffDay struct {
 
 // DoctorDefaultSchedule represents a default branch assignment for a doctor on a specific day of the week
 // DayOfWeek: 0 = Sunday, 1 = Monday, ..., 6 = Saturday
+// A doctor may split a day between branches with a morning and an afternoon
+// schedule, and a recurrence limits a schedule to some of the weeks.
 type DoctorDefaultSchedule struct {
 	ID        uuid.UUID `json:"id" db:"id"`
 	DoctorID  uuid.UUID `json:"doctor_id" db:"doctor_id"`
@@ -43,6 +51,8 @@ type DoctorDefaultSchedule struct {
 	DayOfWeek int       `json:"day_of_week" db:"day_of_week"` // 0-6 (Sunday-Saturday)
 	BranchID  uuid.UUID `json:"branch_id" db:"branch_id"`
 	Branch    *Branch   `json:"branch,omitempty"`
+	Slot      string    `json:"slot" db:"slot"` // "full_day", "morning" or "afternoon"; empty is a full day
+	ScheduleRecurrence
 	CreatedBy uuid.UUID `json:"created_by" db:"created_by"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
@@ -55,6 +65,7 @@ type DoctorWeeklyOffDay struct {
 	DoctorID  uuid.UUID `json:"doctor_id" db:"doctor_id"`
 	Doctor    *Doctor   `json:"doctor,omitempty"`
 	DayOfWeek int       `json:"day_of_week" db:"day_of_week"` // 0-6 (Sunday-Saturday)
+	ScheduleRecurrence
 	CreatedBy uuid.UUID `json:"created_by" db:"created_by"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Doctor leave approval states
+const (
+	DoctorLeavePending  = "pending"
+	DoctorLeaveApproved = "approved"
+	DoctorLeaveRejected = "rejected"
+)
+
+// DoctorLeave is a doctor's vacation or other leave from StartDate to EndDate,
+// inclusive. Only approved leave takes the doctor off their schedule; a
+// schedule override on a date of the leave still takes precedence.
+type DoctorLeave struct {
+	ID          uuid.UUID                `json:"id" db:"id"`
+	DoctorID    uuid.UUID                `json:"doctor_id" db:"doctor_id"`
+	DoctorName  string                   `json:"doctor_name,omitempty" db:"doctor_name"`
+	StartDate   time.Time                `json:"start_date" db:"start_date"`
+	EndDate     time.Time                `json:"end_date" db:"end_date"`
+	Reason      string                   `json:"reason" db:"reason"`
+	Status      string                   `json:"status" db:"status"`                     // "pending", "approved" or "rejected"
+	ReviewedBy  *uuid.UUID               `json:"reviewed_by,omitempty" db:"reviewed_by"` // who approved or rejected it
+	ReviewedAt  *time.Time               `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	Substitutes []*DoctorLeaveSubstitute `json:"substitutes,omitempty"`
+	CreatedBy   uuid.UUID                `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time                `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time                `json:"updated_at" db:"updated_at"`
+}
+
+// Covers reports whether date falls within the leave
+func (l *DoctorLeave) Covers(date time.Time) bool {
+	day := civilDate(date)
+	return !day.Before(civilDate(l.StartDate)) && !day.After(civilDate(l.EndDate))
+}
+
+// Overlaps reports whether the leave shares a date with startDate to endDate
+func (l *DoctorLeave) Overlaps(startDate, endDate time.Time) bool {
+	return !civilDate(l.StartDate).After(civilDate(endDate)) && !civilDate(l.EndDate).Before(civilDate(startDate))
+}
+
+// DoctorLeaveSubstitute is a doctor covering a branch on one date of another
+// doctor's leave. The substitute's working schedule override for the date is
+// what puts them on the roster; this records why it exists.
+type DoctorLeaveSubstitute struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	LeaveID    uuid.UUID `json:"leave_id" db:"leave_id"`
+	Date       time.Time `json:"date" db:"date"`
+	BranchID   uuid.UUID `json:"branch_id" db:"branch_id"`
+	BranchCode string    `json:"branch_code,omitempty" db:"branch_code"`
+	DoctorID   uuid.UUID `json:"doctor_id" db:"doctor_id"` // the substitute
+	DoctorName string    `json:"doctor_name,omitempty" db:"doctor_name"`
+	CreatedBy  uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// DoctorLeaveImpact is a branch and slot a doctor on leave was due to work on
+// a date. A branch left without doctors is closed for rotation purposes until
+// a substitute is assigned.
+type DoctorLeaveImpact struct {
+	Date         time.Time              `json:"date"`
+	BranchID     uuid.UUID              `json:"branch_id"`
+	BranchCode   string                 `json:"branch_code,omitempty"`
+	Slot         string                 `json:"slot"`
+	OtherDoctors int                    `json:"other_doctors"` // doctors still working at the branch that day
+	DoctorLess   bool                   `json:"doctor_less"`
+	Substitute   *DoctorLeaveSubstitute `json:"substitute,omitempty"`
+}
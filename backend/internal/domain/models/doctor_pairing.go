@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Where a doctor-assistant pairing comes from
+const (
+	// PairingSourceSpecificPreference is a staff_name specific preference naming a doctor
+	PairingSourceSpecificPreference = "specific_preference"
+	// PairingSourceDoctorPreference is a specific staff requirement of a rotation_staff_requirement rule
+	PairingSourceDoctorPreference = "doctor_preference"
+)
+
+// Pairing statuses
+const (
+	// PairingMet is a paired staff member already working at the doctor's branch
+	PairingMet = "met"
+	// PairingReserved is a paired rotation staff member held for the doctor's branch
+	PairingReserved = "reserved"
+	// PairingUnmet is a paired staff member who cannot work at the doctor's branch
+	PairingUnmet = "unmet"
+)
+
+// Reasons a pairing is unmet
+const (
+	PairingReasonStaffOnLeave             = "staff_on_leave"
+	PairingReasonStaffOff                 = "staff_off"
+	PairingReasonAlreadyAssigned          = "already_assigned"
+	PairingReasonOutsideEffectiveBranches = "outside_effective_branches"
+	PairingReasonStaffNotFound            = "staff_not_found"
+)
+
+// DoctorPairing says a staff member works alongside a doctor, at whichever
+// branch the doctor's roster puts them on Date. A hard pairing must be met: the
+// staff member is placed there before anything else is allocated and cannot be
+// assigned elsewhere. A soft pairing is met when the branch is staffed anyway.
+type DoctorPairing struct {
+	DoctorID     uuid.UUID `json:"doctor_id"`
+	DoctorName   string    `json:"doctor_name,omitempty"`
+	StaffID      uuid.UUID `json:"staff_id"`
+	StaffName    string    `json:"staff_name,omitempty"`
+	PositionID   uuid.UUID `json:"position_id,omitempty"`
+	BranchID     uuid.UUID `json:"branch_id"`
+	BranchCode   string    `json:"branch_code,omitempty"`
+	Date         time.Time `json:"date"`
+	Hard         bool      `json:"hard"`
+	Source       string    `json:"source"`
+	PreferenceID uuid.UUID `json:"preference_id"`
+	Status       string    `json:"status"`
+	Reason       string    `json:"reason,omitempty"`
+	// The branch the staff member is assigned to instead, for already_assigned
+	AssignedBranchID *uuid.UUID `json:"assigned_branch_id,omitempty"`
+}
+
+// IsUnmet reports whether the paired staff member cannot work at the doctor's branch
+func (p *DoctorPairing) IsUnmet() bool {
+	return p.Status == PairingUnmet
+}
@@ -0,0 +1,293 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Doctor preference rule kinds, the DoctorPreference.RuleType values
+const (
+	RuleRotationStaffRequirement = "rotation_staff_requirement"
+	RulePreferredAssistant       = "preferred_assistant"
+	RuleAvoidStaff               = "avoid_staff"
+	RuleMinPositionCount         = "min_position_count"
+)
+
+// BranchStaffing is what doctor preference rules read about a branch on a date
+type BranchStaffing interface {
+	// PositionCount counts the staff of a position working at the branch, local and rotation
+	PositionCount(positionID uuid.UUID) int
+	// IsWorking reports whether the staff member works at the branch, local or by rotation
+	IsWorking(staffID uuid.UUID) bool
+}
+
+// PreferenceRule is the typed rule_config of one rule kind
+type PreferenceRule interface {
+	// Validate checks the decoded config
+	Validate() error
+	// AppliesOn reports whether the rule applies on date
+	AppliesOn(date time.Time) bool
+	// Evaluate returns each requirement the staffing fails on date, none when the rule is met
+	Evaluate(staffing BranchStaffing, date time.Time) []PreferenceRuleFailure
+}
+
+// PreferenceRuleFailure is one requirement of a doctor preference rule that a
+// branch's staffing does not meet
+type PreferenceRuleFailure struct {
+	PreferenceID uuid.UUID  `json:"preference_id"`
+	DoctorID     uuid.UUID  `json:"doctor_id"`
+	RuleType     string     `json:"rule_type"`
+	Message      string     `json:"message"`
+	PositionID   *uuid.UUID `json:"position_id,omitempty"`
+	StaffID      *uuid.UUID `json:"staff_id,omitempty"`
+	Required     int        `json:"required,omitempty"`
+	Actual       int        `json:"actual,omitempty"`
+}
+
+// preferenceRules is the registry of rule kinds, each making an empty config to decode into
+var preferenceRules = map[string]func() PreferenceRule{
+	RuleRotationStaffRequirement: func() PreferenceRule { return &RotationStaffRequirementRuleConfig{} },
+	RulePreferredAssistant:       func() PreferenceRule { return &PreferredAssistantRuleConfig{} },
+	RuleAvoidStaff:               func() PreferenceRule { return &AvoidStaffRuleConfig{} },
+	RuleMinPositionCount:         func() PreferenceRule { return &MinPositionCountRuleConfig{} },
+}
+
+// PreferenceRuleKinds returns the registered rule kinds in alphabetical order
+func PreferenceRuleKinds() []string {
+	kinds := make([]string, 0, len(preferenceRules))
+	for kind := range preferenceRules {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// IsPreferenceRuleKind reports whether ruleType is a registered rule kind.
+// Other rule types, like the legacy staff_requirement, stay free-form.
+func IsPreferenceRuleKind(ruleType string) bool {
+	_, ok := preferenceRules[ruleType]
+	return ok
+}
+
+// DecodePreferenceRule strictly decodes and validates the config of a rule
+// kind: unknown kinds, unknown fields and mistyped values are errors
+func DecodePreferenceRule(ruleType string, config map[string]interface{}) (PreferenceRule, error) {
+	newRule, ok := preferenceRules[ruleType]
+	if !ok {
+		return nil, fmt.Errorf("unknown rule type %q (must be one of %v)", ruleType, PreferenceRuleKinds())
+	}
+	raw, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s rule config: %w", ruleType, err)
+	}
+	rule := newRule()
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(rule); err != nil {
+		return nil, fmt.Errorf("invalid %s rule config: %w", ruleType, err)
+	}
+	if err := rule.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s rule config: %w", ruleType, err)
+	}
+	return rule, nil
+}
+
+// Rule decodes the preference's rule_config by its rule type
+func (dp *DoctorPreference) Rule() (PreferenceRule, error) {
+	return DecodePreferenceRule(dp.RuleType, dp.RuleConfig)
+}
+
+// Evaluate decodes the preference's rule and returns the requirements it fails
+// at a branch on date, none when the rule is met or does not apply that day
+func (dp *DoctorPreference) Evaluate(staffing BranchStaffing, date time.Time) ([]PreferenceRuleFailure, error) {
+	rule, err := dp.Rule()
+	if err != nil {
+		return nil, err
+	}
+	if !rule.AppliesOn(date) {
+		return nil, nil
+	}
+	failures := rule.Evaluate(staffing, date)
+	for i := range failures {
+		failures[i].PreferenceID = dp.ID
+		failures[i].DoctorID = dp.DoctorID
+		failures[i].RuleType = dp.RuleType
+	}
+	return failures, nil
+}
+
+// validateDaysOfWeek checks days are 0-6 (Sunday-Saturday)
+func validateDaysOfWeek(days []int) error {
+	for _, day := range days {
+		if day < 0 || day > 6 {
+			return fmt.Errorf("invalid day of week: %d (must be 0-6)", day)
+		}
+	}
+	return nil
+}
+
+// onDaysOfWeek reports whether date falls on one of days; no days means every day
+func onDaysOfWeek(days []int, date time.Time) bool {
+	return len(days) == 0 || slices.Contains(days, int(date.Weekday()))
+}
+
+// positionFailure is the failure of a position minimum, if staffing falls short of it
+func positionFailure(staffing BranchStaffing, positionID uuid.UUID, minCount int) []PreferenceRuleFailure {
+	actual := staffing.PositionCount(positionID)
+	if actual >= minCount {
+		return nil
+	}
+	return []PreferenceRuleFailure{{
+		Message:    fmt.Sprintf("needs %d staff of position %s, has %d", minCount, positionID, actual),
+		PositionID: &positionID,
+		Required:   minCount,
+		Actual:     actual,
+	}}
+}
+
+func (c *RotationStaffRequirementRuleConfig) Validate() error {
+	if err := validateDaysOfWeek(c.DaysOfWeek); err != nil {
+		return err
+	}
+	if len(c.PositionRequirements) == 0 && len(c.SpecificStaffRequirements) == 0 {
+		return fmt.Errorf("position_requirements or specific_staff_requirements is required")
+	}
+	for i, req := range c.PositionRequirements {
+		if req.PositionID == uuid.Nil {
+			return fmt.Errorf("position_requirements[%d]: position_id is required", i)
+		}
+		if req.MinCount < 1 {
+			return fmt.Errorf("position_requirements[%d]: min_count must be at least 1", i)
+		}
+	}
+	for i, req := range c.SpecificStaffRequirements {
+		if _, err := time.Parse("2006-01-02", req.Date); err != nil {
+			return fmt.Errorf("specific_staff_requirements[%d]: date must be YYYY-MM-DD", i)
+		}
+		if req.StaffID == uuid.Nil {
+			return fmt.Errorf("specific_staff_requirements[%d]: staff_id is required", i)
+		}
+	}
+	return nil
+}
+
+func (c *RotationStaffRequirementRuleConfig) AppliesOn(date time.Time) bool {
+	return onDaysOfWeek(c.DaysOfWeek, date)
+}
+
+// Evaluate checks each position minimum, and the specific staff required on date
+func (c *RotationStaffRequirementRuleConfig) Evaluate(staffing BranchStaffing, date time.Time) []PreferenceRuleFailure {
+	var failures []PreferenceRuleFailure
+	for _, req := range c.PositionRequirements {
+		failures = append(failures, positionFailure(staffing, req.PositionID, req.MinCount)...)
+	}
+	dateStr := date.Format("2006-01-02")
+	for _, req := range c.SpecificStaffRequirements {
+		if req.Date != dateStr || staffing.IsWorking(req.StaffID) {
+			continue
+		}
+		staffID := req.StaffID
+		failures = append(failures, PreferenceRuleFailure{
+			Message: fmt.Sprintf("needs staff %s on %s", staffID, dateStr),
+			StaffID: &staffID,
+		})
+	}
+	return failures
+}
+
+// PreferredAssistantRuleConfig asks for at least one of the doctor's preferred
+// assistants to work at the branch
+type PreferredAssistantRuleConfig struct {
+	StaffIDs   []uuid.UUID `json:"staff_ids"`
+	DaysOfWeek []int       `json:"days_of_week,omitempty"` // 0-6 (Sunday-Saturday), every day when empty
+}
+
+func (c *PreferredAssistantRuleConfig) Validate() error {
+	if len(c.StaffIDs) == 0 {
+		return fmt.Errorf("staff_ids is required")
+	}
+	if slices.Contains(c.StaffIDs, uuid.Nil) {
+		return fmt.Errorf("staff_ids cannot contain a nil ID")
+	}
+	return validateDaysOfWeek(c.DaysOfWeek)
+}
+
+func (c *PreferredAssistantRuleConfig) AppliesOn(date time.Time) bool {
+	return onDaysOfWeek(c.DaysOfWeek, date)
+}
+
+func (c *PreferredAssistantRuleConfig) Evaluate(staffing BranchStaffing, _ time.Time) []PreferenceRuleFailure {
+	for _, staffID := range c.StaffIDs {
+		if staffing.IsWorking(staffID) {
+			return nil
+		}
+	}
+	return []PreferenceRuleFailure{{Message: "none of the preferred assistants works at the branch"}}
+}
+
+// AvoidStaffRuleConfig asks for none of the listed staff to work at the branch
+type AvoidStaffRuleConfig struct {
+	StaffIDs   []uuid.UUID `json:"staff_ids"`
+	DaysOfWeek []int       `json:"days_of_week,omitempty"` // 0-6 (Sunday-Saturday), every day when empty
+}
+
+func (c *AvoidStaffRuleConfig) Validate() error {
+	if len(c.StaffIDs) == 0 {
+		return fmt.Errorf("staff_ids is required")
+	}
+	if slices.Contains(c.StaffIDs, uuid.Nil) {
+		return fmt.Errorf("staff_ids cannot contain a nil ID")
+	}
+	return validateDaysOfWeek(c.DaysOfWeek)
+}
+
+func (c *AvoidStaffRuleConfig) AppliesOn(date time.Time) bool {
+	return onDaysOfWeek(c.DaysOfWeek, date)
+}
+
+// Evaluate fails once for each avoided staff member working at the branch
+func (c *AvoidStaffRuleConfig) Evaluate(staffing BranchStaffing, _ time.Time) []PreferenceRuleFailure {
+	var failures []PreferenceRuleFailure
+	for _, staffID := range c.StaffIDs {
+		if !staffing.IsWorking(staffID) {
+			continue
+		}
+		staffID := staffID
+		failures = append(failures, PreferenceRuleFailure{
+			Message: fmt.Sprintf("avoided staff %s works at the branch", staffID),
+			StaffID: &staffID,
+		})
+	}
+	return failures
+}
+
+// MinPositionCountRuleConfig asks for a minimum number of staff of one position
+type MinPositionCountRuleConfig struct {
+	PositionID uuid.UUID `json:"position_id"`
+	MinCount   int       `json:"min_count"`
+	DaysOfWeek []int     `json:"days_of_week,omitempty"` // 0-6 (Sunday-Saturday), every day when empty
+}
+
+func (c *MinPositionCountRuleConfig) Validate() error {
+	if c.PositionID == uuid.Nil {
+		return fmt.Errorf("position_id is required")
+	}
+	if c.MinCount < 1 {
+		return fmt.Errorf("min_count must be at least 1")
+	}
+	return validateDaysOfWeek(c.DaysOfWeek)
+}
+
+func (c *MinPositionCountRuleConfig) AppliesOn(date time.Time) bool {
+	return onDaysOfWeek(c.DaysOfWeek, date)
+}
+
+func (c *MinPositionCountRuleConfig) Evaluate(staffing BranchStaffing, _ time.Time) []PreferenceRuleFailure {
+	return positionFailure(staffing, c.PositionID, c.MinCount)
+}
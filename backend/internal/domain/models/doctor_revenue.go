@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DoctorRevenue is the revenue a doctor is expected to bring in on one working
+// day, split by revenue type like branch revenue
+type DoctorRevenue struct {
+	SkinRevenue  float64 `json:"skin_revenue" db:"skin_revenue"`     // Skin revenue (THB)
+	LSHMRevenue  float64 `json:"ls_hm_revenue" db:"ls_hm_revenue"`   // LS HM revenue (THB)
+	VitaminCases int     `json:"vitamin_cases" db:"vitamin_cases"`   // Vitamin cases (count)
+	SlimPenCases int     `json:"slim_pen_cases" db:"slim_pen_cases"` // Slim Pen cases (count)
+}
+
+// Amount is the revenue in THB; vitamin and slim pen cases are counts, not money
+func (r DoctorRevenue) Amount() float64 {
+	return r.SkinRevenue + r.LSHMRevenue
+}
+
+// Add returns the sum of r and other
+func (r DoctorRevenue) Add(other DoctorRevenue) DoctorRevenue {
+	return DoctorRevenue{
+		SkinRevenue:  r.SkinRevenue + other.SkinRevenue,
+		LSHMRevenue:  r.LSHMRevenue + other.LSHMRevenue,
+		VitaminCases: r.VitaminCases + other.VitaminCases,
+		SlimPenCases: r.SlimPenCases + other.SlimPenCases,
+	}
+}
+
+// Half returns the share of r for half a working day. An odd case count
+// leaves the extra case with the morning, so both halves add up to r.
+func (r DoctorRevenue) Half(morning bool) DoctorRevenue {
+	half := DoctorRevenue{
+		SkinRevenue:  r.SkinRevenue / 2,
+		LSHMRevenue:  r.LSHMRevenue / 2,
+		VitaminCases: r.VitaminCases / 2,
+		SlimPenCases: r.SlimPenCases / 2,
+	}
+	if morning {
+		half.VitaminCases += r.VitaminCases % 2
+		half.SlimPenCases += r.SlimPenCases % 2
+	}
+	return half
+}
+
+// DoctorRevenueProfile is a doctor's expected revenue on a day of the week.
+// A profile with a branch applies only there and takes precedence over the
+// doctor's branch-less profile for the same day.
+// DayOfWeek: 0 = Sunday, 1 = Monday, ..., 6 = Saturday
+type DoctorRevenueProfile struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	DoctorID  uuid.UUID  `json:"doctor_id" db:"doctor_id"`
+	BranchID  *uuid.UUID `json:"branch_id,omitempty" db:"branch_id"` // nil applies at every branch
+	DayOfWeek int        `json:"day_of_week" db:"day_of_week"`       // 0-6 (Sunday-Saturday)
+	DoctorRevenue
+	CreatedBy uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DoctorRevenueOverride replaces a doctor's profile revenue on one date,
+// wherever the doctor works that day
+type DoctorRevenueOverride struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	DoctorID uuid.UUID `json:"doctor_id" db:"doctor_id"`
+	Date     time.Time `json:"date" db:"date"`
+	DoctorRevenue
+	CreatedBy uuid.UUID `json:"created_by" db:"created_by"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DoctorRevenueData is a branch's daily revenue made up of the revenue of the
+// doctors working there. ExpectedRevenue holds the THB total.
+func DoctorRevenueData(branchID uuid.UUID, date time.Time, revenue DoctorRevenue) *RevenueData {
+	return &RevenueData{
+		BranchID:        branchID,
+		Date:            date,
+		ExpectedRevenue: revenue.Amount(),
+		SkinRevenue:     revenue.SkinRevenue,
+		LSHMRevenue:     revenue.LSHMRevenue,
+		VitaminCases:    revenue.VitaminCases,
+		SlimPenCases:    revenue.SlimPenCases,
+		RevenueSource:   "doctor",
+	}
+}
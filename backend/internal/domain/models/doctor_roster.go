@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Roster run triggers
+const (
+	RosterTriggerScheduled = "scheduled" // the nightly job rolling the horizon forward
+	RosterTriggerManual    = "manual"    // requested through the API
+	RosterTriggerChange    = "change"    // a doctor's schedule, override or revenue changed
+)
+
+// Roster change types
+const (
+	RosterChangeAdded   = "added"   // the doctor now works on the date
+	RosterChangeRemoved = "removed" // the doctor no longer works on the date
+	RosterChangeMoved   = "moved"   // the doctor works at another branch on the date
+)
+
+// DoctorRosterRun is one materialization of the doctor roster between two
+// dates, with counts of what it changed. Updated counts assignments whose
+// revenue or source changed without the doctor moving.
+type DoctorRosterRun struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	StartDate time.Time `json:"start_date" db:"start_date"`
+	EndDate   time.Time `json:"end_date" db:"end_date"`
+	Trigger   string    `json:"trigger" db:"trigger"` // "scheduled", "manual" or "change"
+	Added     int       `json:"added" db:"added"`
+	Removed   int       `json:"removed" db:"removed"`
+	Moved     int       `json:"moved" db:"moved"`
+	Updated   int       `json:"updated" db:"updated"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// DoctorRosterChange records a doctor starting, stopping or moving branch on a
+// date, e.g. doctor X moved from CTR to PNK on 2026-11-03
+type DoctorRosterChange struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	RunID          uuid.UUID  `json:"run_id" db:"run_id"`
+	DoctorID       uuid.UUID  `json:"doctor_id" db:"doctor_id"`
+	DoctorName     string     `json:"doctor_name,omitempty" db:"doctor_name"`
+	Date           time.Time  `json:"date" db:"date"`
+	ChangeType     string     `json:"change_type" db:"change_type"`                 // "added", "removed" or "moved"
+	FromBranchID   *uuid.UUID `json:"from_branch_id,omitempty" db:"from_branch_id"` // nil when added
+	FromBranchCode string     `json:"from_branch_code,omitempty" db:"from_branch_code"`
+	ToBranchID     *uuid.UUID `json:"to_branch_id,omitempty" db:"to_branch_id"` // nil when removed
+	ToBranchCode   string     `json:"to_branch_code,omitempty" db:"to_branch_code"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// DoctorRosterWindow is the date range the roster has been materialized for
+type DoctorRosterWindow struct {
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+}
+
+// Contains reports whether date falls within the window
+func (w *DoctorRosterWindow) Contains(date time.Time) bool {
+	return w != nil && !date.Before(w.StartDate) && !date.After(w.EndDate)
+}
+
+// Roster import actions
+const (
+	RosterImportSet   = "set"   // an override is created or replaced: the roster deviates from the schedule
+	RosterImportClear = "clear" // an override is removed: the roster is back on the schedule
+)
+
+// DoctorRosterImportChange is a schedule override an imported monthly roster
+// needs: a day where the roster differs from the doctor's default schedule,
+// weekly off days and leave, or an override the roster no longer needs
+type DoctorRosterImportChange struct {
+	DoctorID   uuid.UUID  `json:"doctor_id"`
+	DoctorCode string     `json:"doctor_code,omitempty"`
+	Date       time.Time  `json:"date"`
+	Action     string     `json:"action"`              // "set" or "clear"
+	Type       string     `json:"type,omitempty"`      // "working" or "off", for set
+	BranchID   *uuid.UUID `json:"branch_id,omitempty"` // for a working set
+	BranchCode string     `json:"branch_code,omitempty"`
+	OverrideID *uuid.UUID `json:"override_id,omitempty"` // the existing override replaced or removed
+}
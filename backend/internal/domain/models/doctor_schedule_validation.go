@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Doctor schedule issue types
+const (
+	// ScheduleIssueCoverageGap is a run of open days a branch has no doctor on
+	ScheduleIssueCoverageGap = "coverage_gap"
+	// ScheduleIssueOverCapacity is a day a branch has more doctors than it can take
+	ScheduleIssueOverCapacity = "over_capacity"
+	// ScheduleIssueClosedBranch is a working override at a branch closed that day
+	ScheduleIssueClosedBranch = "override_at_closed_branch"
+	// ScheduleIssueRedundantOverride is an override that says what the default schedule does
+	ScheduleIssueRedundantOverride = "redundant_override"
+)
+
+// Doctor schedule issue severities. Errors stop the schedule being published;
+// warnings are reported only.
+const (
+	ScheduleIssueError   = "error"
+	ScheduleIssueWarning = "warning"
+)
+
+// DoctorScheduleIssue is a problem found in the calculated doctor schedule,
+// e.g. CTR has no doctor from 2026-11-02 to 2026-11-08
+type DoctorScheduleIssue struct {
+	Type       string      `json:"type"`
+	Severity   string      `json:"severity"` // "error" or "warning"
+	BranchID   *uuid.UUID  `json:"branch_id,omitempty"`
+	BranchCode string      `json:"branch_code,omitempty"`
+	DoctorID   *uuid.UUID  `json:"doctor_id,omitempty"`
+	Date       time.Time   `json:"date"`
+	EndDate    *time.Time  `json:"end_date,omitempty"` // last day of a coverage gap
+	Days       int         `json:"days,omitempty"`     // open days without a doctor in a coverage gap
+	Doctors    int         `json:"doctors,omitempty"`  // doctors on the day, for over_capacity
+	Capacity   int         `json:"capacity,omitempty"`
+	Overrides  []uuid.UUID `json:"override_ids,omitempty"`
+	Message    string      `json:"message"`
+}
+
+// DoctorScheduleValidation is the result of validating the doctor schedule
+// from StartDate to EndDate. It can be published when it has no errors.
+type DoctorScheduleValidation struct {
+	StartDate time.Time              `json:"start_date"`
+	EndDate   time.Time              `json:"end_date"`
+	Valid     bool                   `json:"valid"`
+	Errors    int                    `json:"errors"`
+	Warnings  int                    `json:"warnings"`
+	Issues    []*DoctorScheduleIssue `json:"issues"`
+}
+
+// Add records an issue and counts it by severity
+func (v *DoctorScheduleValidation) Add(issue *DoctorScheduleIssue) {
+	v.Issues = append(v.Issues, issue)
+	if issue.Severity == ScheduleIssueError {
+		v.Errors++
+	} else {
+		v.Warnings++
+	}
+	v.Valid = v.Errors == 0
+}
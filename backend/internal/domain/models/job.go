@@ -0,0 +1,70 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// JobStatus is the lifecycle state of a background job
+type JobStatus string
+
+const (
+	JobStatusQueued    JobStatus = "queued"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusSucceeded JobStatus = "succeeded"
+	JobStatusFailed    JobStatus = "failed"
+	JobStatusCancelled JobStatus = "cancelled"
+)
+
+// Job is a unit of background work run by the job runner. Failed attempts are
+// queued again until MaxAttempts is reached.
+type Job struct {
+	ID              uuid.UUID       `json:"id" db:"id"`
+	Type            string          `json:"type" db:"type"`
+	Status          JobStatus       `json:"status" db:"status"`
+	Payload         json.RawMessage `json:"payload,omitempty" db:"payload"`
+	Progress        int             `json:"progress" db:"progress"` // Percent complete, 0-100
+	ProgressMessage string          `json:"progress_message,omitempty" db:"progress_message"`
+	Attempts        int             `json:"attempts" db:"attempts"`
+	MaxAttempts     int             `json:"max_attempts" db:"max_attempts"`
+	Error           string          `json:"error,omitempty" db:"error"`
+	Result          json.RawMessage `json:"result,omitempty" db:"result"`
+	ResultFileName  string          `json:"result_file_name,omitempty" db:"result_file_name"`
+	CancelRequested bool            `json:"cancel_requested" db:"cancel_requested"`
+	ScheduleName    string          `json:"schedule_name,omitempty" db:"schedule_name"` // Cron schedule that enqueued the job
+	RunAt           time.Time       `json:"run_at" db:"run_at"`
+	LockedBy        string          `json:"-" db:"locked_by"`
+	HeartbeatAt     *time.Time      `json:"heartbeat_at,omitempty" db:"heartbeat_at"`
+	StartedAt       *time.Time      `json:"started_at,omitempty" db:"started_at"`
+	FinishedAt      *time.Time      `json:"finished_at,omitempty" db:"finished_at"`
+	CreatedBy       *uuid.UUID      `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// IsFinished reports whether the job has reached a final state
+func (j *Job) IsFinished() bool {
+	return j.Status == JobStatusSucceeded || j.Status == JobStatusFailed || j.Status == JobStatusCancelled
+}
+
+// JobFile is a file attached to a job: the upload it processes or the file it produced
+type JobFile struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Data        []byte `json:"-"`
+}
+
+// JobSchedule enqueues a job of JobType whenever its cron expression fires
+type JobSchedule struct {
+	Name      string          `json:"name" db:"name"`
+	JobType   string          `json:"job_type" db:"job_type"`
+	Cron      string          `json:"cron" db:"cron"`
+	Payload   json.RawMessage `json:"payload,omitempty" db:"payload"`
+	Enabled   bool            `json:"enabled" db:"enabled"`
+	LastRunAt *time.Time      `json:"last_run_at,omitempty" db:"last_run_at"`
+	NextRunAt time.Time       `json:"next_run_at" db:"next_run_at"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at" db:"updated_at"`
+}
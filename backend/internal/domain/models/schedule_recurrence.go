@@ -0,0 +1,117 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Slots of a doctor's working day
+const (
+	DoctorSlotFullDay   = "full_day"
+	DoctorSlotMorning   = "morning"
+	DoctorSlotAfternoon = "afternoon"
+)
+
+// ValidDoctorSlot reports whether slot is a known slot; empty means a full day
+func ValidDoctorSlot(slot string) bool {
+	switch slot {
+	case "", DoctorSlotFullDay, DoctorSlotMorning, DoctorSlotAfternoon:
+		return true
+	}
+	return false
+}
+
+// DoctorSlotOrFullDay returns slot, or DoctorSlotFullDay when it is empty
+func DoctorSlotOrFullDay(slot string) string {
+	if slot == "" {
+		return DoctorSlotFullDay
+	}
+	return slot
+}
+
+// ScheduleRecurrence limits a weekday schedule or off day to some of the weeks
+// its weekday falls in. The zero value recurs every week without end.
+type ScheduleRecurrence struct {
+	// IntervalWeeks repeats the rule every N weeks, counted in Monday-based
+	// weeks from the week of AnchorDate; 0 or 1 is every week
+	IntervalWeeks int        `json:"interval_weeks,omitempty" db:"interval_weeks"`
+	AnchorDate    *time.Time `json:"anchor_date,omitempty" db:"anchor_date"`
+	// WeekOfMonth limits the rule to the nth weekday of the month (1-5) or
+	// the last one (-1); 0 is every week
+	WeekOfMonth int        `json:"week_of_month,omitempty" db:"week_of_month"`
+	ValidFrom   *time.Time `json:"valid_from,omitempty" db:"valid_from"`   // First date the rule applies, inclusive
+	ValidUntil  *time.Time `json:"valid_until,omitempty" db:"valid_until"` // Last date the rule applies, inclusive
+}
+
+// Restricted reports whether the rule skips any week, so it takes precedence
+// over the every-week rules for the same weekday
+func (r ScheduleRecurrence) Restricted() bool {
+	return r.IntervalWeeks > 1 || r.WeekOfMonth != 0 || r.ValidFrom != nil || r.ValidUntil != nil
+}
+
+// Equal reports whether r and other describe the same weeks
+func (r ScheduleRecurrence) Equal(other ScheduleRecurrence) bool {
+	sameDate := func(a, b *time.Time) bool {
+		if a == nil || b == nil {
+			return a == nil && b == nil
+		}
+		return civilDate(*a).Equal(civilDate(*b))
+	}
+	return max(r.IntervalWeeks, 1) == max(other.IntervalWeeks, 1) && r.WeekOfMonth == other.WeekOfMonth &&
+		sameDate(r.AnchorDate, other.AnchorDate) && sameDate(r.ValidFrom, other.ValidFrom) && sameDate(r.ValidUntil, other.ValidUntil)
+}
+
+// Validate checks the recurrence is well formed
+func (r ScheduleRecurrence) Validate() error {
+	if r.IntervalWeeks < 0 {
+		return errors.New("interval_weeks must not be negative")
+	}
+	if r.IntervalWeeks > 1 && r.AnchorDate == nil {
+		return errors.New("anchor_date is required when interval_weeks is more than 1")
+	}
+	if r.IntervalWeeks > 1 && r.WeekOfMonth != 0 {
+		return errors.New("interval_weeks and week_of_month cannot be combined")
+	}
+	if r.WeekOfMonth < -1 || r.WeekOfMonth > 5 {
+		return errors.New("week_of_month must be between 1 and 5, or -1 for the last week")
+	}
+	if r.ValidFrom != nil && r.ValidUntil != nil && civilDate(*r.ValidUntil).Before(civilDate(*r.ValidFrom)) {
+		return errors.New("valid_until must be on or after valid_from")
+	}
+	return nil
+}
+
+// Occurs reports whether the rule applies on date; the caller has already
+// matched date's weekday against the rule's
+func (r ScheduleRecurrence) Occurs(date time.Time) bool {
+	day := civilDate(date)
+	if r.ValidFrom != nil && day.Before(civilDate(*r.ValidFrom)) {
+		return false
+	}
+	if r.ValidUntil != nil && day.After(civilDate(*r.ValidUntil)) {
+		return false
+	}
+	if r.IntervalWeeks > 1 && r.AnchorDate != nil {
+		weeks := int(weekStart(day).Sub(weekStart(civilDate(*r.AnchorDate))).Hours()) / (24 * 7)
+		if ((weeks%r.IntervalWeeks)+r.IntervalWeeks)%r.IntervalWeeks != 0 {
+			return false
+		}
+	}
+	switch {
+	case r.WeekOfMonth == -1:
+		return day.AddDate(0, 0, 7).Month() != day.Month()
+	case r.WeekOfMonth > 0:
+		return (day.Day()-1)/7+1 == r.WeekOfMonth
+	}
+	return true
+}
+
+// civilDate is the calendar date of t as a UTC midnight
+func civilDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// weekStart is the Monday of day's week
+func weekStart(day time.Time) time.Time {
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
@@ -34,6 +34,8 @@ type SpecificPreference struct {
 	// For staff_name type: staff_id is required
 	StaffID     *uuid.UUID             `json:"staff_id,omitempty" db:"staff_id"`
 	Staff       *Staff                 `json:"staff,omitempty"`
+	// For staff_name type with a doctor: a hard pairing must be met, a soft one is preferred
+	IsHard      bool                   `json:"is_hard" db:"is_hard"`
 	
 	IsActive    bool                   `json:"is_active" db:"is_active"`
 	CreatedAt   time.Time              `json:"created_at" db:"created_at"`
@@ -59,6 +61,11 @@ func (sp *SpecificPreference) Validate() error {
 	return nil
 }
 
+// IsPairing reports whether the preference pairs a specific staff member with a doctor
+func (sp *SpecificPreference) IsPairing() bool {
+	return sp.PreferenceType == SpecificPreferenceTypeStaffName && sp.DoctorID != nil && sp.StaffID != nil
+}
+
 // Matches checks if this preference matches the given branch, doctor, and day of week
 func (sp *SpecificPreference) Matches(branchID *uuid.UUID, doctorID *uuid.UUID, dayOfWeek *int) bool {
 	if !sp.IsActive {
@@ -25,5 +25,21 @@ type Role struct {
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
 
+// UserIdentity links a local user to an external OpenID Connect identity (issuer + subject)
+type UserIdentity struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	Issuer      string     `json:"issuer" db:"issuer"`
+	Subject     string     `json:"subject" db:"subject"`
+	Email       string     `json:"email" db:"email"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty" db:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
 
-
+// UserIdentityLinkApproval is an admin's approval for the next SSO login with the
+// user's verified email to link to the existing local user. The first link uses it up.
+type UserIdentityLinkApproval struct {
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	ApprovedBy *uuid.UUID `json:"approved_by,omitempty" db:"approved_by"`
+	ApprovedAt time.Time  `json:"approved_at" db:"approved_at"`
+}
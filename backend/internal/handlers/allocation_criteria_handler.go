@@ -24,7 +24,7 @@ func NewAllocationCriteriaHandler(repos *postgres.Repositories) *AllocationCrite
 // GetCriteriaPriorityOrder returns the current criteria priority order configuration
 func (h *AllocationCriteriaHandler) GetCriteriaPriorityOrder(c *gin.Context) {
 	// Try to get from settings, otherwise return defaults
-	setting, err := h.repos.Settings.GetByKey("allocation_criteria_priority_order")
+	setting, err := h.repos.Settings.GetByKey(c.Request.Context(), "allocation_criteria_priority_order")
 	if err != nil || setting == nil {
 		// Return default priority order
 		defaultPriorityOrder := allocation.DefaultCriteriaPriorityOrder()
@@ -57,7 +57,7 @@ func (h *AllocationCriteriaHandler) GetCriteriaPriorityOrder(c *gin.Context) {
 	}
 
 	// Get doctor preferences setting
-	doctorPrefSetting, _ := h.repos.Settings.GetByKey("allocation_enable_doctor_preferences")
+	doctorPrefSetting, _ := h.repos.Settings.GetByKey(c.Request.Context(), "allocation_enable_doctor_preferences")
 	enableDoctorPrefs := false
 	if doctorPrefSetting != nil && doctorPrefSetting.Value == "true" {
 		enableDoctorPrefs = true
@@ -134,11 +134,11 @@ func (h *AllocationCriteriaHandler) UpdateCriteriaPriorityOrder(c *gin.Context)
 	}
 
 	// Save priority order to settings (upsert logic)
-	existingSetting, _ := h.repos.Settings.GetByKey("allocation_criteria_priority_order")
+	existingSetting, _ := h.repos.Settings.GetByKey(c.Request.Context(), "allocation_criteria_priority_order")
 	if existingSetting != nil {
 		existingSetting.Value = string(priorityOrderJSON)
 		existingSetting.Description = "Allocation criteria priority order for strict lexicographic ranking"
-		err = h.repos.Settings.Update(existingSetting)
+		err = h.repos.Settings.Update(c.Request.Context(), existingSetting)
 	} else {
 		setting := &models.SystemSetting{
 			ID:          uuid.New(),
@@ -146,7 +146,7 @@ func (h *AllocationCriteriaHandler) UpdateCriteriaPriorityOrder(c *gin.Context)
 			Value:       string(priorityOrderJSON),
 			Description: "Allocation criteria priority order for strict lexicographic ranking",
 		}
-		err = h.repos.Settings.Create(setting)
+		err = h.repos.Settings.Create(c.Request.Context(), setting)
 	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -158,11 +158,11 @@ func (h *AllocationCriteriaHandler) UpdateCriteriaPriorityOrder(c *gin.Context)
 	if req.EnableDoctorPreferences {
 		doctorPrefValue = "true"
 	}
-	doctorPrefSetting, _ := h.repos.Settings.GetByKey("allocation_enable_doctor_preferences")
+	doctorPrefSetting, _ := h.repos.Settings.GetByKey(c.Request.Context(), "allocation_enable_doctor_preferences")
 	if doctorPrefSetting != nil {
 		doctorPrefSetting.Value = doctorPrefValue
 		doctorPrefSetting.Description = "Enable doctor preferences in allocation criteria"
-		err = h.repos.Settings.Update(doctorPrefSetting)
+		err = h.repos.Settings.Update(c.Request.Context(), doctorPrefSetting)
 	} else {
 		setting := &models.SystemSetting{
 			ID:          uuid.New(),
@@ -170,7 +170,7 @@ func (h *AllocationCriteriaHandler) UpdateCriteriaPriorityOrder(c *gin.Context)
 			Value:       doctorPrefValue,
 			Description: "Enable doctor preferences in allocation criteria",
 		}
-		err = h.repos.Settings.Create(setting)
+		err = h.repos.Settings.Create(c.Request.Context(), setting)
 	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -195,11 +195,11 @@ func (h *AllocationCriteriaHandler) ResetCriteriaPriorityOrder(c *gin.Context) {
 	}
 
 	// Reset priority order (upsert logic)
-	existingSetting, _ := h.repos.Settings.GetByKey("allocation_criteria_priority_order")
+	existingSetting, _ := h.repos.Settings.GetByKey(c.Request.Context(), "allocation_criteria_priority_order")
 	if existingSetting != nil {
 		existingSetting.Value = string(priorityOrderJSON)
 		existingSetting.Description = "Allocation criteria priority order for strict lexicographic ranking"
-		err = h.repos.Settings.Update(existingSetting)
+		err = h.repos.Settings.Update(c.Request.Context(), existingSetting)
 	} else {
 		setting := &models.SystemSetting{
 			ID:          uuid.New(),
@@ -207,7 +207,7 @@ func (h *AllocationCriteriaHandler) ResetCriteriaPriorityOrder(c *gin.Context) {
 			Value:       string(priorityOrderJSON),
 			Description: "Allocation criteria priority order for strict lexicographic ranking",
 		}
-		err = h.repos.Settings.Create(setting)
+		err = h.repos.Settings.Create(c.Request.Context(), setting)
 	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -215,11 +215,11 @@ func (h *AllocationCriteriaHandler) ResetCriteriaPriorityOrder(c *gin.Context) {
 	}
 
 	// Reset doctor preferences setting
-	doctorPrefSetting, _ := h.repos.Settings.GetByKey("allocation_enable_doctor_preferences")
+	doctorPrefSetting, _ := h.repos.Settings.GetByKey(c.Request.Context(), "allocation_enable_doctor_preferences")
 	if doctorPrefSetting != nil {
 		doctorPrefSetting.Value = "false"
 		doctorPrefSetting.Description = "Enable doctor preferences in allocation criteria"
-		err = h.repos.Settings.Update(doctorPrefSetting)
+		err = h.repos.Settings.Update(c.Request.Context(), doctorPrefSetting)
 	} else {
 		setting := &models.SystemSetting{
 			ID:          uuid.New(),
@@ -227,7 +227,7 @@ func (h *AllocationCriteriaHandler) ResetCriteriaPriorityOrder(c *gin.Context) {
 			Value:       "false",
 			Description: "Enable doctor preferences in allocation criteria",
 		}
-		err = h.repos.Settings.Create(setting)
+		err = h.repos.Settings.Create(c.Request.Context(), setting)
 	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
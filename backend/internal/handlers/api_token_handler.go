@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+	"vsq-oper-manpower/backend/internal/repositories/postgres"
+	"vsq-oper-manpower/backend/pkg/apitoken"
+)
+
+type APITokenHandler struct {
+	repos *postgres.Repositories
+}
+
+func NewAPITokenHandler(repos *postgres.Repositories) *APITokenHandler {
+	return &APITokenHandler{repos: repos}
+}
+
+// ListServiceAccounts returns all service accounts
+func (h *APITokenHandler) ListServiceAccounts(c *gin.Context) {
+	accounts, err := h.repos.ServiceAccount.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"service_accounts": accounts})
+}
+
+// CreateServiceAccount creates a new service account
+func (h *APITokenHandler) CreateServiceAccount(c *gin.Context) {
+	var req models.ServiceAccountCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	roleID, err := uuid.Parse(req.RoleID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role ID"})
+		return
+	}
+	role, err := h.repos.Role.GetByID(c.Request.Context(), roleID)
+	if err != nil || role == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Role not found"})
+		return
+	}
+
+	account := &models.ServiceAccount{
+		ID:          uuid.New(),
+		Name:        req.Name,
+		Description: req.Description,
+		RoleID:      roleID,
+		RoleName:    role.Name,
+		IsActive:    true,
+		CreatedBy:   currentUserID(c),
+	}
+	if req.BranchID != nil && *req.BranchID != "" {
+		branchID, err := uuid.Parse(*req.BranchID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid branch ID"})
+			return
+		}
+		account.BranchID = &branchID
+	}
+
+	if err := h.repos.ServiceAccount.Create(c.Request.Context(), account); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, account)
+}
+
+// UpdateServiceAccount updates a service account; deactivating it disables all of its tokens
+func (h *APITokenHandler) UpdateServiceAccount(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service account ID"})
+		return
+	}
+
+	account, err := h.repos.ServiceAccount.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if account == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service account not found"})
+		return
+	}
+
+	var req models.ServiceAccountUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Name != nil {
+		account.Name = *req.Name
+	}
+	if req.Description != nil {
+		account.Description = *req.Description
+	}
+	if req.RoleID != nil {
+		roleID, err := uuid.Parse(*req.RoleID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role ID"})
+			return
+		}
+		role, err := h.repos.Role.GetByID(c.Request.Context(), roleID)
+		if err != nil || role == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Role not found"})
+			return
+		}
+		account.RoleID = roleID
+		account.RoleName = role.Name
+	}
+	if req.BranchID != nil {
+		if *req.BranchID == "" {
+			account.BranchID = nil
+		} else {
+			branchID, err := uuid.Parse(*req.BranchID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid branch ID"})
+				return
+			}
+			account.BranchID = &branchID
+		}
+	}
+	if req.IsActive != nil {
+		account.IsActive = *req.IsActive
+	}
+
+	if err := h.repos.ServiceAccount.Update(c.Request.Context(), account); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// DeleteServiceAccount deletes a service account together with its tokens
+func (h *APITokenHandler) DeleteServiceAccount(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service account ID"})
+		return
+	}
+
+	// The backing user goes with the account; rows the account wrote still
+	// name it in created_by, and then the account can only be deactivated
+	err = h.repos.UnitOfWork.Do(c.Request.Context(), func(tx *interfaces.Repositories) error {
+		if err := tx.ServiceAccount.Delete(c.Request.Context(), id); err != nil {
+			return err
+		}
+		return tx.User.Delete(c.Request.Context(), id)
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "violates foreign key constraint") {
+			c.JSON(http.StatusConflict, gin.H{"error": "Service account has written data and cannot be deleted; deactivate it instead"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Service account deleted successfully"})
+}
+
+// List returns API tokens, optionally filtered by service_account_id. Hashes are never returned.
+func (h *APITokenHandler) List(c *gin.Context) {
+	var serviceAccountID *uuid.UUID
+	if idStr := c.Query("service_account_id"); idStr != "" {
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service account ID"})
+			return
+		}
+		serviceAccountID = &id
+	}
+
+	tokens, err := h.repos.APIToken.List(c.Request.Context(), serviceAccountID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tokens": tokens})
+}
+
+// Create issues a new token for a service account. The plaintext token is only returned in this response.
+func (h *APITokenHandler) Create(c *gin.Context) {
+	var req models.APITokenCreate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	serviceAccountID, err := uuid.Parse(req.ServiceAccountID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid service account ID"})
+		return
+	}
+	account, err := h.repos.ServiceAccount.GetByID(c.Request.Context(), serviceAccountID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if account == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Service account not found"})
+		return
+	}
+
+	for _, scope := range req.Scopes {
+		if err := apitoken.ValidateScope(scope); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var expiresAt *time.Time
+	if req.ExpiresInDays != nil {
+		if *req.ExpiresInDays <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "expires_in_days must be positive"})
+			return
+		}
+		t := time.Now().AddDate(0, 0, *req.ExpiresInDays)
+		expiresAt = &t
+	}
+
+	plaintext, prefix, hash, err := apitoken.Generate()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	token := &models.APIToken{
+		ID:               uuid.New(),
+		ServiceAccountID: account.ID,
+		Name:             req.Name,
+		TokenPrefix:      prefix,
+		TokenHash:        hash,
+		Scopes:           req.Scopes,
+		ExpiresAt:        expiresAt,
+		CreatedBy:        currentUserID(c),
+	}
+	if err := h.repos.APIToken.Create(c.Request.Context(), token); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"token":     token,
+		"plaintext": plaintext,
+		"message":   "Store this token now; it cannot be retrieved again",
+	})
+}
+
+// Revoke revokes an API token immediately
+func (h *APITokenHandler) Revoke(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token ID"})
+		return
+	}
+
+	token, err := h.repos.APIToken.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if token == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Token not found"})
+		return
+	}
+
+	if err := h.repos.APIToken.Revoke(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Token revoked successfully"})
+}
+
+// currentUserID returns the logged-in user's ID, or nil for service accounts
+func currentUserID(c *gin.Context) *uuid.UUID {
+	if c.GetString("auth_method") != "" {
+		return nil
+	}
+	id, err := uuid.Parse(c.GetString("user_id"))
+	if err != nil {
+		return nil
+	}
+	return &id
+}
@@ -27,7 +27,7 @@ type CreateAreaOfOperationRequest struct {
 func (h *AreaOfOperationHandler) List(c *gin.Context) {
 	includeInactive := c.Query("include_inactive") == "true"
 	
-	areas, err := h.repos.AreaOfOperation.List(includeInactive)
+	areas, err := h.repos.AreaOfOperation.List(c.Request.Context(), includeInactive)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -44,7 +44,7 @@ func (h *AreaOfOperationHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	area, err := h.repos.AreaOfOperation.GetByID(id)
+	area, err := h.repos.AreaOfOperation.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Area of operation not found"})
 		return
@@ -61,7 +61,7 @@ func (h *AreaOfOperationHandler) Create(c *gin.Context) {
 	}
 
 	// Check if code already exists
-	existing, _ := h.repos.AreaOfOperation.GetByCode(req.Code)
+	existing, _ := h.repos.AreaOfOperation.GetByCode(c.Request.Context(), req.Code)
 	if existing != nil {
 		c.JSON(http.StatusConflict, gin.H{"error": "Area of operation with this code already exists"})
 		return
@@ -75,7 +75,7 @@ func (h *AreaOfOperationHandler) Create(c *gin.Context) {
 		IsActive:    req.IsActive,
 	}
 
-	if err := h.repos.AreaOfOperation.Create(area); err != nil {
+	if err := h.repos.AreaOfOperation.Create(c.Request.Context(), area); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -98,7 +98,7 @@ func (h *AreaOfOperationHandler) Update(c *gin.Context) {
 	}
 
 	// Check if code already exists (excluding current record)
-	existing, _ := h.repos.AreaOfOperation.GetByCode(req.Code)
+	existing, _ := h.repos.AreaOfOperation.GetByCode(c.Request.Context(), req.Code)
 	if existing != nil && existing.ID != id {
 		c.JSON(http.StatusConflict, gin.H{"error": "Area of operation with this code already exists"})
 		return
@@ -112,7 +112,7 @@ func (h *AreaOfOperationHandler) Update(c *gin.Context) {
 		IsActive:    req.IsActive,
 	}
 
-	if err := h.repos.AreaOfOperation.Update(area); err != nil {
+	if err := h.repos.AreaOfOperation.Update(c.Request.Context(), area); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -128,7 +128,7 @@ func (h *AreaOfOperationHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.AreaOfOperation.Delete(id); err != nil {
+	if err := h.repos.AreaOfOperation.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -162,7 +162,7 @@ func (h *AreaOfOperationHandler) AddZone(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.AreaOfOperation.AddZone(areaID, zoneID); err != nil {
+	if err := h.repos.AreaOfOperation.AddZone(c.Request.Context(), areaID, zoneID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -185,7 +185,7 @@ func (h *AreaOfOperationHandler) RemoveZone(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.AreaOfOperation.RemoveZone(areaID, zoneID); err != nil {
+	if err := h.repos.AreaOfOperation.RemoveZone(c.Request.Context(), areaID, zoneID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -201,7 +201,7 @@ func (h *AreaOfOperationHandler) GetZones(c *gin.Context) {
 		return
 	}
 
-	zones, err := h.repos.AreaOfOperation.GetZones(areaID)
+	zones, err := h.repos.AreaOfOperation.GetZones(c.Request.Context(), areaID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -226,7 +226,7 @@ func (h *AreaOfOperationHandler) AddBranch(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.AreaOfOperation.AddBranch(areaID, req.BranchID); err != nil {
+	if err := h.repos.AreaOfOperation.AddBranch(c.Request.Context(), areaID, req.BranchID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -249,7 +249,7 @@ func (h *AreaOfOperationHandler) RemoveBranch(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.AreaOfOperation.RemoveBranch(areaID, branchID); err != nil {
+	if err := h.repos.AreaOfOperation.RemoveBranch(c.Request.Context(), areaID, branchID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -265,7 +265,7 @@ func (h *AreaOfOperationHandler) GetBranches(c *gin.Context) {
 		return
 	}
 
-	branches, err := h.repos.AreaOfOperation.GetBranches(areaID)
+	branches, err := h.repos.AreaOfOperation.GetBranches(c.Request.Context(), areaID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -282,7 +282,7 @@ func (h *AreaOfOperationHandler) GetAllBranches(c *gin.Context) {
 		return
 	}
 
-	branches, err := h.repos.AreaOfOperation.GetAllBranches(areaID)
+	branches, err := h.repos.AreaOfOperation.GetAllBranches(c.Request.Context(), areaID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
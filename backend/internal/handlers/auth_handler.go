@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"vsq-oper-manpower/backend/internal/config"
+	"vsq-oper-manpower/backend/internal/domain/models"
 	"vsq-oper-manpower/backend/internal/repositories/postgres"
 )
 
@@ -32,7 +33,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	user, err := h.repos.User.GetByUsername(req.Username)
+	user, err := h.repos.User.GetByUsername(c.Request.Context(), req.Username)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
@@ -48,20 +49,13 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	role, err := h.repos.Role.GetByID(user.RoleID)
+	role, err := h.repos.Role.GetByID(c.Request.Context(), user.RoleID)
 	if err != nil || role == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
-	session := sessions.Default(c)
-	session.Set("user_id", user.ID.String())
-	session.Set("username", user.Username)
-	session.Set("role", role.Name)
-	if user.BranchID != nil {
-		session.Set("branch_id", user.BranchID.String())
-	}
-	if err := session.Save(); err != nil {
+	if err := saveUserSession(c, user, role.Name); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save session"})
 		return
 	}
@@ -75,7 +69,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	if user.BranchID != nil {
 		response["branch_id"] = user.BranchID
 		// Fetch branch details to include branch name and code
-		branch, err := h.repos.Branch.GetByID(*user.BranchID)
+		branch, err := h.repos.Branch.GetByID(c.Request.Context(), *user.BranchID)
 		if err != nil {
 			// Log error but don't fail the request
 			c.Error(err)
@@ -88,6 +82,20 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"user": response})
 }
 
+// saveUserSession stores the authenticated user's identity in the session cookie
+func saveUserSession(c *gin.Context, user *models.User, roleName string) error {
+	session := sessions.Default(c)
+	session.Set("user_id", user.ID.String())
+	session.Set("username", user.Username)
+	session.Set("role", roleName)
+	if user.BranchID != nil {
+		session.Set("branch_id", user.BranchID.String())
+	} else {
+		session.Delete("branch_id")
+	}
+	return session.Save()
+}
+
 func (h *AuthHandler) Logout(c *gin.Context) {
 	session := sessions.Default(c)
 	session.Clear()
@@ -113,13 +121,13 @@ func (h *AuthHandler) Me(c *gin.Context) {
 		return
 	}
 
-	user, err := h.repos.User.GetByID(userID)
+	user, err := h.repos.User.GetByID(c.Request.Context(), userID)
 	if err != nil || user == nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
 
-	role, err := h.repos.Role.GetByID(user.RoleID)
+	role, err := h.repos.Role.GetByID(c.Request.Context(), user.RoleID)
 	if err != nil || role == nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
@@ -134,7 +142,7 @@ func (h *AuthHandler) Me(c *gin.Context) {
 	if user.BranchID != nil {
 		response["branch_id"] = user.BranchID
 		// Fetch branch details to include branch name and code
-		branch, err := h.repos.Branch.GetByID(*user.BranchID)
+		branch, err := h.repos.Branch.GetByID(c.Request.Context(), *user.BranchID)
 		if err != nil {
 			// Log error but don't fail the request
 			c.Error(err)
@@ -148,7 +156,7 @@ func (h *AuthHandler) Me(c *gin.Context) {
 }
 
 func (h *AuthHandler) ListRoles(c *gin.Context) {
-	roles, err := h.repos.Role.List()
+	roles, err := h.repos.Role.List(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -33,21 +34,21 @@ func (h *BranchConfigHandler) GetBranchConfig(c *gin.Context) {
 	}
 
 	// Get quotas
-	quotas, err := h.repos.PositionQuota.GetByBranchID(branchID)
+	quotas, err := h.repos.PositionQuota.GetByBranchID(c.Request.Context(), branchID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Get weekly revenue
-	weeklyRevenue, err := h.repos.BranchWeeklyRevenue.GetByBranchID(branchID)
+	weeklyRevenue, err := h.repos.BranchWeeklyRevenue.GetByBranchID(c.Request.Context(), branchID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Get positions for quota details
-	positions, err := h.repos.Position.List()
+	positions, err := h.repos.Position.List(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -73,7 +74,7 @@ func (h *BranchConfigHandler) GetBranchConfig(c *gin.Context) {
 	}
 
 	// Get constraints with inheritance resolution
-	constraints, err := h.getResolvedConstraints(branchID)
+	constraints, err := h.getResolvedConstraints(c.Request.Context(), branchID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -119,7 +120,7 @@ func (h *BranchConfigHandler) UpdateQuotas(c *gin.Context) {
 	}
 
 	// Get positions to validate position types
-	positions, err := h.repos.Position.List()
+	positions, err := h.repos.Position.List(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -161,7 +162,7 @@ func (h *BranchConfigHandler) UpdateQuotas(c *gin.Context) {
 	// Upsert quotas
 	for _, quotaReq := range req.Quotas {
 		// Check if quota exists
-		existingQuota, err := h.repos.PositionQuota.GetByBranchAndPosition(branchID, quotaReq.PositionID)
+		existingQuota, err := h.repos.PositionQuota.GetByBranchAndPosition(c.Request.Context(), branchID, quotaReq.PositionID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -172,7 +173,7 @@ func (h *BranchConfigHandler) UpdateQuotas(c *gin.Context) {
 			existingQuota.DesignatedQuota = quotaReq.DesignatedQuota
 			existingQuota.MinimumRequired = quotaReq.MinimumRequired
 			existingQuota.IsActive = true
-			if err := h.repos.PositionQuota.Update(existingQuota); err != nil {
+			if err := h.repos.PositionQuota.Update(c.Request.Context(), existingQuota); err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
 			}
@@ -187,7 +188,7 @@ func (h *BranchConfigHandler) UpdateQuotas(c *gin.Context) {
 				IsActive:        true,
 				CreatedBy:       userID,
 			}
-			if err := h.repos.PositionQuota.Create(quota); err != nil {
+			if err := h.repos.PositionQuota.Create(c.Request.Context(), quota); err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
 			}
@@ -269,7 +270,7 @@ func (h *BranchConfigHandler) UpdateWeeklyRevenue(c *gin.Context) {
 	}
 
 	// Bulk upsert
-	if err := h.repos.BranchWeeklyRevenue.BulkUpsert(revenues); err != nil {
+	if err := h.repos.BranchWeeklyRevenue.BulkUpsert(c.Request.Context(), revenues); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -286,14 +287,14 @@ func (h *BranchConfigHandler) GetQuotas(c *gin.Context) {
 		return
 	}
 
-	quotas, err := h.repos.PositionQuota.GetByBranchID(branchID)
+	quotas, err := h.repos.PositionQuota.GetByBranchID(c.Request.Context(), branchID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Get positions for names
-	positions, err := h.repos.Position.List()
+	positions, err := h.repos.Position.List(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -329,7 +330,7 @@ func (h *BranchConfigHandler) GetWeeklyRevenue(c *gin.Context) {
 		return
 	}
 
-	weeklyRevenue, err := h.repos.BranchWeeklyRevenue.GetByBranchID(branchID)
+	weeklyRevenue, err := h.repos.BranchWeeklyRevenue.GetByBranchID(c.Request.Context(), branchID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -400,7 +401,7 @@ func (h *BranchConfigHandler) UpdateConstraints(c *gin.Context) {
 	// #endregion
 
 	// Get branch to check for branch type
-	branch, err := h.repos.Branch.GetByID(branchID)
+	branch, err := h.repos.Branch.GetByID(c.Request.Context(), branchID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -428,7 +429,7 @@ func (h *BranchConfigHandler) UpdateConstraints(c *gin.Context) {
 				return
 			}
 			// Verify staff group exists
-			staffGroup, err := h.repos.StaffGroup.GetByID(sgReq.StaffGroupID)
+			staffGroup, err := h.repos.StaffGroup.GetByID(c.Request.Context(), sgReq.StaffGroupID)
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify staff group: " + err.Error()})
 				return
@@ -444,6 +445,17 @@ func (h *BranchConfigHandler) UpdateConstraints(c *gin.Context) {
 		}
 	}
 
+	// Deletions and upserts are applied in one transaction so a failed upsert
+	// does not leave days deleted without their replacement overrides
+	ctx := c.Request.Context()
+	tx, err := h.repos.UnitOfWork.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+	txRepos := tx.Repositories()
+
 	// Separate constraints to update vs delete
 	constraintsToUpdate := []*models.BranchConstraints{}
 	daysToDelete := []int{}
@@ -476,10 +488,10 @@ func (h *BranchConfigHandler) UpdateConstraints(c *gin.Context) {
 			// Skip creating overridden constraint with empty requirements
 			// This constraint should inherit from branch type (or be deleted if it exists)
 			// Check if there's an existing overridden constraint and delete it
-			existingConstraint, err := h.repos.BranchConstraints.GetByBranchIDAndDayOfWeek(branchID, cons.DayOfWeek)
+			existingConstraint, err := txRepos.BranchConstraints.GetByBranchIDAndDayOfWeek(ctx, branchID, cons.DayOfWeek)
 			if err == nil && existingConstraint != nil && existingConstraint.IsOverridden {
 				// Delete existing overridden constraint so it can inherit from branch type
-				if err := h.repos.BranchConstraints.Delete(existingConstraint.ID); err != nil {
+				if err := txRepos.BranchConstraints.Delete(ctx, existingConstraint.ID); err != nil {
 					// #region agent log
 					if logFile, err2 := os.OpenFile("c:\\Users\\User\\dev_projects\\vsq-oper_manpower\\.cursor\\debug.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err2 == nil {
 						logData, _ := json.Marshal(map[string]interface{}{"location": "branch_config_handler.go:456", "message": "UpdateConstraints: failed to delete empty constraint", "data": map[string]interface{}{"branchId": branchID.String(), "dayOfWeek": cons.DayOfWeek, "error": err.Error()}, "timestamp": fmt.Sprintf("%d", 0), "sessionId": "debug-session", "runId": "run1", "hypothesisId": "D"})
@@ -523,7 +535,7 @@ func (h *BranchConfigHandler) UpdateConstraints(c *gin.Context) {
 		}
 		// #endregion
 		for _, dayOfWeek := range daysToDelete {
-			existingConstraint, err := h.repos.BranchConstraints.GetByBranchIDAndDayOfWeek(branchID, dayOfWeek)
+			existingConstraint, err := txRepos.BranchConstraints.GetByBranchIDAndDayOfWeek(ctx, branchID, dayOfWeek)
 			if err != nil {
 				// #region agent log
 				if logFile, err2 := os.OpenFile("c:\\Users\\User\\dev_projects\\vsq-oper_manpower\\.cursor\\debug.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err2 == nil {
@@ -543,7 +555,7 @@ func (h *BranchConfigHandler) UpdateConstraints(c *gin.Context) {
 					logFile.Close()
 				}
 				// #endregion
-				if err := h.repos.BranchConstraints.Delete(existingConstraint.ID); err != nil {
+				if err := txRepos.BranchConstraints.Delete(ctx, existingConstraint.ID); err != nil {
 					// #region agent log
 					if logFile, err2 := os.OpenFile("c:\\Users\\User\\dev_projects\\vsq-oper_manpower\\.cursor\\debug.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err2 == nil {
 						logData, _ := json.Marshal(map[string]interface{}{"location": "branch_config_handler.go:473", "message": "UpdateConstraints: deletion failed", "data": map[string]interface{}{"branchId": branchID.String(), "dayOfWeek": dayOfWeek, "error": err.Error()}, "timestamp": fmt.Sprintf("%d", 0), "sessionId": "debug-session", "runId": "run1", "hypothesisId": "C"})
@@ -596,7 +608,7 @@ func (h *BranchConfigHandler) UpdateConstraints(c *gin.Context) {
 			logFile.Close()
 		}
 		// #endregion
-		if err := h.repos.BranchConstraints.BulkUpsertWithStaffGroups(constraintsToUpdate); err != nil {
+		if err := txRepos.BranchConstraints.BulkUpsertWithStaffGroups(ctx, constraintsToUpdate); err != nil {
 			// #region agent log
 			if logFile, err2 := os.OpenFile("c:\\Users\\User\\dev_projects\\vsq-oper_manpower\\.cursor\\debug.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err2 == nil {
 				logData, _ := json.Marshal(map[string]interface{}{"location": "branch_config_handler.go:498", "message": "UpdateConstraints: upsert failed", "data": map[string]interface{}{"branchId": branchID.String(), "error": err.Error()}, "timestamp": fmt.Sprintf("%d", 0), "sessionId": "debug-session", "runId": "run1", "hypothesisId": "D"})
@@ -624,6 +636,11 @@ func (h *BranchConfigHandler) UpdateConstraints(c *gin.Context) {
 		// #endregion
 	}
 
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Constraints updated successfully"})
 }
 
@@ -636,7 +653,7 @@ func (h *BranchConfigHandler) GetConstraints(c *gin.Context) {
 		return
 	}
 
-	constraints, err := h.getResolvedConstraints(branchID)
+	constraints, err := h.getResolvedConstraints(c.Request.Context(), branchID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -661,9 +678,9 @@ func (h *BranchConfigHandler) GetConstraints(c *gin.Context) {
 
 // getResolvedConstraints returns constraints for a branch with inheritance resolution
 // Priority: Overridden branch constraints > Branch type constraints > Defaults (all zeros)
-func (h *BranchConfigHandler) getResolvedConstraints(branchID uuid.UUID) ([]*models.BranchConstraints, error) {
+func (h *BranchConfigHandler) getResolvedConstraints(ctx context.Context, branchID uuid.UUID) ([]*models.BranchConstraints, error) {
 	// Get branch to check for branch type
-	branch, err := h.repos.Branch.GetByID(branchID)
+	branch, err := h.repos.Branch.GetByID(ctx, branchID)
 	if err != nil {
 		return nil, err
 	}
@@ -672,12 +689,12 @@ func (h *BranchConfigHandler) getResolvedConstraints(branchID uuid.UUID) ([]*mod
 	}
 
 	// Get branch-specific constraints (overridden ones)
-	branchConstraints, err := h.repos.BranchConstraints.GetByBranchID(branchID)
+	branchConstraints, err := h.repos.BranchConstraints.GetByBranchID(ctx, branchID)
 	if err != nil {
 		return nil, err
 	}
 	// Load staff group requirements for branch constraints
-	if err := h.repos.BranchConstraints.LoadStaffGroupRequirements(branchConstraints); err != nil {
+	if err := h.repos.BranchConstraints.LoadStaffGroupRequirements(ctx, branchConstraints); err != nil {
 		return nil, fmt.Errorf("failed to load staff group requirements: %w", err)
 	}
 
@@ -706,12 +723,12 @@ func (h *BranchConfigHandler) getResolvedConstraints(branchID uuid.UUID) ([]*mod
 	// Get branch type constraints if branch has a branch type
 	var branchTypeConstraints []*models.BranchTypeConstraints
 	if branch.BranchTypeID != nil {
-		branchTypeConstraints, err = h.repos.BranchTypeConstraints.GetByBranchTypeID(*branch.BranchTypeID)
+		branchTypeConstraints, err = h.repos.BranchTypeConstraints.GetByBranchTypeID(ctx, *branch.BranchTypeID)
 		if err != nil {
 			return nil, err
 		}
 		// Load staff group requirements for branch type constraints
-		if err := h.repos.BranchTypeConstraints.LoadStaffGroupRequirements(branchTypeConstraints); err != nil {
+		if err := h.repos.BranchTypeConstraints.LoadStaffGroupRequirements(ctx, branchTypeConstraints); err != nil {
 			return nil, fmt.Errorf("failed to load staff group requirements: %w", err)
 		}
 	}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"time"
@@ -8,14 +9,17 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"vsq-oper-manpower/backend/internal/constants"
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
 	"vsq-oper-manpower/backend/internal/domain/models"
 	"vsq-oper-manpower/backend/internal/repositories/postgres"
+	"vsq-oper-manpower/backend/internal/usecases/jobs"
 	"vsq-oper-manpower/backend/pkg/excel"
 )
 
 type BranchHandler struct {
 	repos         *postgres.Repositories
 	excelImporter *excel.ExcelImporter
+	jobs          *jobs.Runner
 }
 
 func NewBranchHandler(repos *postgres.Repositories) *BranchHandler {
@@ -36,10 +40,11 @@ type CreateBranchRequest struct {
 	AreaManagerID *uuid.UUID `json:"area_manager_id,omitempty"`
 	BranchTypeID  *uuid.UUID `json:"branch_type_id,omitempty"`
 	Priority      int        `json:"priority"`
+	MaxDoctors    *int       `json:"max_doctors,omitempty" binding:"omitempty,min=1"`
 }
 
 func (h *BranchHandler) List(c *gin.Context) {
-	branches, err := h.repos.Branch.List()
+	branches, err := h.repos.Branch.List(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -62,9 +67,10 @@ func (h *BranchHandler) Create(c *gin.Context) {
 		AreaManagerID: req.AreaManagerID,
 		BranchTypeID:  req.BranchTypeID,
 		Priority:      req.Priority,
+		MaxDoctors:    req.MaxDoctors,
 	}
 
-	if err := h.repos.Branch.Create(branch); err != nil {
+	if err := h.repos.Branch.Create(c.Request.Context(), branch); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -81,7 +87,7 @@ func (h *BranchHandler) Update(c *gin.Context) {
 	}
 
 	// Get existing branch to check if it's a standard branch code
-	existingBranch, err := h.repos.Branch.GetByID(id)
+	existingBranch, err := h.repos.Branch.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -110,9 +116,10 @@ func (h *BranchHandler) Update(c *gin.Context) {
 		AreaManagerID: req.AreaManagerID,
 		BranchTypeID:  req.BranchTypeID,
 		Priority:      req.Priority,
+		MaxDoctors:    req.MaxDoctors,
 	}
 
-	if err := h.repos.Branch.Update(branch); err != nil {
+	if err := h.repos.Branch.Update(c.Request.Context(), branch); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -129,7 +136,7 @@ func (h *BranchHandler) Delete(c *gin.Context) {
 	}
 
 	// Get branch to check if it's a standard branch code
-	branch, err := h.repos.Branch.GetByID(id)
+	branch, err := h.repos.Branch.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -145,7 +152,7 @@ func (h *BranchHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.Branch.Delete(id); err != nil {
+	if err := h.repos.Branch.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -182,7 +189,7 @@ func (h *BranchHandler) GetRevenue(c *gin.Context) {
 		startDate = endDate.AddDate(0, 0, -30)
 	}
 
-	revenues, err := h.repos.Revenue.GetByBranchID(branchID, startDate, endDate)
+	revenues, err := h.repos.Revenue.GetByBranchID(c.Request.Context(), branchID, startDate, endDate)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -194,6 +201,7 @@ func (h *BranchHandler) GetRevenue(c *gin.Context) {
 // ImportRevenue handles batch import of expected revenue from Excel file
 // POST /api/branches/revenue/import
 // The import overrides all existing revenue for each day of each branch.
+// With ?async=true the import runs as a background job and the response is the queued job.
 func (h *BranchHandler) ImportRevenue(c *gin.Context) {
 	// Get the uploaded file
 	file, err := c.FormFile("file")
@@ -202,55 +210,225 @@ func (h *BranchHandler) ImportRevenue(c *gin.Context) {
 		return
 	}
 
-	// Open the uploaded file
-	src, err := file.Open()
+	input, err := uploadedJobFile(file)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to open file: %v", err)})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to read file: %v", err)})
 		return
 	}
-	defer src.Close()
 
-	// Read file content
-	fileData := make([]byte, file.Size)
-	_, err = src.Read(fileData)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to read file: %v", err)})
+	if wantsAsync(c) {
+		enqueueJob(c, h.jobs, JobTypeRevenueImport, nil, input)
 		return
 	}
+	c.JSON(h.importRevenue(c.Request.Context(), input.Data))
+}
+
+// registerJobs lets the revenue import run in the background
+func (h *BranchHandler) registerJobs(runner *jobs.Runner) {
+	h.jobs = runner
+	runner.Register(JobTypeRevenueImport, importJob(runner, func(ctx context.Context, job *models.Job, fileData []byte) (int, gin.H) {
+		return h.importRevenue(ctx, fileData)
+	}))
+}
 
+// importRevenue saves the revenue in an Excel file and returns the response status and body
+func (h *BranchHandler) importRevenue(ctx context.Context, fileData []byte) (int, gin.H) {
 	// Import revenue data from Excel
-	revenueList, errors, parseErr := h.excelImporter.ImportBranchRevenue(fileData)
+	revenueList, errors, parseErr := h.excelImporter.ImportBranchRevenue(ctx, fileData)
 	if parseErr != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": parseErr.Error(), "errors": errors})
-		return
+		return http.StatusBadRequest, gin.H{"error": parseErr.Error(), "errors": errors}
 	}
 
 	// If there are validation errors and no valid data, return errors
 	if len(errors) > 0 && len(revenueList) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{
+		return http.StatusBadRequest, gin.H{
 			"error":  "Import failed with validation errors",
 			"errors": errors,
-		})
-		return
+		}
 	}
 
 	// Bulk create or update revenues
 	if len(revenueList) > 0 {
-		if err := h.repos.Revenue.BulkCreateOrUpdate(revenueList); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
+		if err := h.repos.Revenue.BulkCreateOrUpdate(ctx, revenueList); err != nil {
+			return http.StatusInternalServerError, gin.H{
 				"error":  fmt.Sprintf("Failed to import revenue data: %v", err),
 				"errors": errors,
-			})
-			return
+			}
 		}
 	}
 
 	// Return success response
-	c.JSON(http.StatusOK, gin.H{
+	return http.StatusOK, gin.H{
 		"message":     "Revenue data imported successfully",
 		"imported":    len(revenueList),
 		"errors":      errors,
 		"has_errors":  len(errors) > 0,
-	})
+	}
+}
+
+
+// Branch closure operations. A closure closes one branch over a date range;
+// without a branch_id it is a public holiday closing every branch. Closed
+// branch days need no staff, whatever the doctor roster says.
+type BranchClosureRequest struct {
+	BranchID  *uuid.UUID `json:"branch_id"`                     // omit for a public holiday
+	StartDate string     `json:"start_date" binding:"required"` // YYYY-MM-DD format
+	EndDate   string     `json:"end_date" binding:"required"`   // YYYY-MM-DD format, inclusive
+	Reason    string     `json:"reason"`
+}
+
+// maxClosureDays bounds a closure, like a doctor's leave
+const maxClosureDays = 366
+
+func (h *BranchHandler) CreateClosure(c *gin.Context) {
+	closure, ok := h.bindClosure(c)
+	if !ok {
+		return
+	}
+
+	userID, err := uuid.Parse(c.MustGet("user_id").(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	closure.CreatedBy = userID
+
+	if err := h.repos.BranchClosure.Create(c.Request.Context(), closure); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"closure": closure})
 }
 
+// GetClosures lists closures by start date, optionally for one branch (with
+// every holiday) or overlapping start_date to end_date
+func (h *BranchHandler) GetClosures(c *gin.Context) {
+	filters := interfaces.BranchClosureFilters{}
+	if value := c.Query("branch_id"); value != "" {
+		branchID, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid branch_id"})
+			return
+		}
+		filters.BranchID = &branchID
+	}
+	for param, target := range map[string]**time.Time{
+		"start_date": &filters.StartDate,
+		"end_date":   &filters.EndDate,
+	} {
+		if value := c.Query(param); value != "" {
+			date, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + " format"})
+				return
+			}
+			*target = &date
+		}
+	}
+
+	closures, err := h.repos.BranchClosure.List(c.Request.Context(), filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"closures": closures})
+}
+
+func (h *BranchHandler) UpdateClosure(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	existing, err := h.repos.BranchClosure.GetByID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if existing == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Closure not found"})
+		return
+	}
+
+	closure, ok := h.bindClosure(c)
+	if !ok {
+		return
+	}
+	closure.ID = id
+	closure.CreatedBy, closure.CreatedAt = existing.CreatedBy, existing.CreatedAt
+
+	if err := h.repos.BranchClosure.Update(ctx, closure); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"closure": closure})
+}
+
+func (h *BranchHandler) DeleteClosure(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	if err := h.repos.BranchClosure.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Closure deleted successfully"})
+}
+
+// bindClosure reads and validates a closure request, checking its branch
+// exists. It writes a 400 response and returns false when it is invalid.
+func (h *BranchHandler) bindClosure(c *gin.Context) (*models.BranchClosure, bool) {
+	var req BranchClosureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format"})
+		return nil, false
+	}
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format"})
+		return nil, false
+	}
+	if endDate.Before(startDate) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_date must be on or after start_date"})
+		return nil, false
+	}
+	if endDate.After(startDate.AddDate(0, 0, maxClosureDays-1)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Closure cannot be longer than %d days", maxClosureDays)})
+		return nil, false
+	}
+
+	closure := &models.BranchClosure{
+		BranchID:  req.BranchID,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Reason:    req.Reason,
+	}
+	if req.BranchID != nil {
+		branch, err := h.repos.Branch.GetByID(c.Request.Context(), *req.BranchID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		if branch == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Branch not found"})
+			return nil, false
+		}
+		closure.BranchCode = branch.Code
+	}
+	return closure, true
+}
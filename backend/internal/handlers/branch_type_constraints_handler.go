@@ -28,14 +28,14 @@ func (h *BranchTypeConstraintsHandler) GetByBranchTypeID(c *gin.Context) {
 		return
 	}
 
-	constraints, err := h.repos.BranchTypeConstraints.GetByBranchTypeID(branchTypeID)
+	constraints, err := h.repos.BranchTypeConstraints.GetByBranchTypeID(c.Request.Context(), branchTypeID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Load staff group requirements
-	if err := h.repos.BranchTypeConstraints.LoadStaffGroupRequirements(constraints); err != nil {
+	if err := h.repos.BranchTypeConstraints.LoadStaffGroupRequirements(c.Request.Context(), constraints); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load staff group requirements: " + err.Error()})
 		return
 	}
@@ -93,20 +93,20 @@ func (h *BranchTypeConstraintsHandler) UpdateConstraints(c *gin.Context) {
 		constraints[i] = constraint
 	}
 
-	if err := h.repos.BranchTypeConstraints.BulkUpsertWithStaffGroups(constraints); err != nil {
+	if err := h.repos.BranchTypeConstraints.BulkUpsertWithStaffGroups(c.Request.Context(), constraints); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save constraints: " + err.Error()})
 		return
 	}
 
 	// Return updated constraints with staff group requirements loaded
-	updatedConstraints, err := h.repos.BranchTypeConstraints.GetByBranchTypeID(branchTypeID)
+	updatedConstraints, err := h.repos.BranchTypeConstraints.GetByBranchTypeID(c.Request.Context(), branchTypeID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve updated constraints: " + err.Error()})
 		return
 	}
 
 	// Load staff group requirements
-	if err := h.repos.BranchTypeConstraints.LoadStaffGroupRequirements(updatedConstraints); err != nil {
+	if err := h.repos.BranchTypeConstraints.LoadStaffGroupRequirements(c.Request.Context(), updatedConstraints); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load staff group requirements: " + err.Error()})
 		return
 	}
@@ -21,7 +21,7 @@ func NewBranchTypeHandler(repos *postgres.Repositories, db *sql.DB) *BranchTypeH
 }
 
 func (h *BranchTypeHandler) List(c *gin.Context) {
-	branchTypes, err := h.repos.BranchType.List()
+	branchTypes, err := h.repos.BranchType.List(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -38,7 +38,7 @@ func (h *BranchTypeHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	branchType, err := h.repos.BranchType.GetByID(id)
+	branchType, err := h.repos.BranchType.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -71,7 +71,7 @@ func (h *BranchTypeHandler) Create(c *gin.Context) {
 		IsActive:    req.IsActive,
 	}
 
-	if err := h.repos.BranchType.Create(branchType); err != nil {
+	if err := h.repos.BranchType.Create(c.Request.Context(), branchType); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -99,7 +99,7 @@ func (h *BranchTypeHandler) Update(c *gin.Context) {
 		return
 	}
 
-	branchType, err := h.repos.BranchType.GetByID(id)
+	branchType, err := h.repos.BranchType.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -113,7 +113,7 @@ func (h *BranchTypeHandler) Update(c *gin.Context) {
 	branchType.Description = req.Description
 	branchType.IsActive = req.IsActive
 
-	if err := h.repos.BranchType.Update(branchType); err != nil {
+	if err := h.repos.BranchType.Update(c.Request.Context(), branchType); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -129,7 +129,7 @@ func (h *BranchTypeHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.BranchType.Delete(id); err != nil {
+	if err := h.repos.BranchType.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -28,7 +28,7 @@ func (h *BranchTypeRequirementHandler) GetByBranchTypeID(c *gin.Context) {
 		return
 	}
 
-	requirements, err := h.repos.BranchTypeRequirement.GetByBranchTypeID(branchTypeID)
+	requirements, err := h.repos.BranchTypeRequirement.GetByBranchTypeID(c.Request.Context(), branchTypeID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -66,7 +66,7 @@ func (h *BranchTypeRequirementHandler) Create(c *gin.Context) {
 		IsActive:          req.IsActive,
 	}
 
-	if err := h.repos.BranchTypeRequirement.Create(requirement); err != nil {
+	if err := h.repos.BranchTypeRequirement.Create(c.Request.Context(), requirement); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -93,7 +93,7 @@ func (h *BranchTypeRequirementHandler) Update(c *gin.Context) {
 		return
 	}
 
-	requirement, err := h.repos.BranchTypeRequirement.GetByID(id)
+	requirement, err := h.repos.BranchTypeRequirement.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -106,7 +106,7 @@ func (h *BranchTypeRequirementHandler) Update(c *gin.Context) {
 	requirement.MinimumStaffCount = req.MinimumStaffCount
 	requirement.IsActive = req.IsActive
 
-	if err := h.repos.BranchTypeRequirement.Update(requirement); err != nil {
+	if err := h.repos.BranchTypeRequirement.Update(c.Request.Context(), requirement); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -122,7 +122,7 @@ func (h *BranchTypeRequirementHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.BranchTypeRequirement.Delete(id); err != nil {
+	if err := h.repos.BranchTypeRequirement.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -167,13 +167,13 @@ func (h *BranchTypeRequirementHandler) BulkUpsert(c *gin.Context) {
 		}
 	}
 
-	if err := h.repos.BranchTypeRequirement.BulkUpsert(requirements); err != nil {
+	if err := h.repos.BranchTypeRequirement.BulkUpsert(c.Request.Context(), requirements); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Return updated requirements
-	updatedRequirements, err := h.repos.BranchTypeRequirement.GetByBranchTypeID(branchTypeID)
+	updatedRequirements, err := h.repos.BranchTypeRequirement.GetByBranchTypeID(c.Request.Context(), branchTypeID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"vsq-oper-manpower/backend/internal/domain/events"
+	"vsq-oper-manpower/backend/internal/usecases/allocation"
+	"vsq-oper-manpower/backend/internal/usecases/doctor"
+)
+
+// CacheHandler reports on the in-process overview caches
+type CacheHandler struct {
+	overviewGenerator *allocation.OverviewGenerator
+	quotaCalculator   *allocation.QuotaCalculator
+	doctorCalendar    *doctor.DoctorCalendar
+	bus               *events.Bus
+}
+
+func NewCacheHandler(overviewGenerator *allocation.OverviewGenerator, quotaCalculator *allocation.QuotaCalculator, doctorCalendar *doctor.DoctorCalendar, bus *events.Bus) *CacheHandler {
+	return &CacheHandler{
+		overviewGenerator: overviewGenerator,
+		quotaCalculator:   quotaCalculator,
+		doctorCalendar:    doctorCalendar,
+		bus:               bus,
+	}
+}
+
+// GetStats returns size, hit/miss and eviction counters per cache, and how many
+// change events of each kind have been published since startup
+func (h *CacheHandler) GetStats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"caches": gin.H{
+			"day_overview":    h.overviewGenerator.CacheStats(),
+			"quota_status":    h.quotaCalculator.CacheStats(),
+			"doctor_calendar": h.doctorCalendar.CacheStats(),
+		},
+		"events_published": h.bus.Published(),
+	})
+}
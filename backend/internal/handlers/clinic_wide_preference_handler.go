@@ -35,7 +35,7 @@ func (h *ClinicWidePreferenceHandler) List(c *gin.Context) {
 		filters.IsActive = &isActive
 	}
 
-	preferences, err := h.repos.ClinicWidePreference.List(filters)
+	preferences, err := h.repos.ClinicWidePreference.List(c.Request.Context(), filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -43,14 +43,14 @@ func (h *ClinicWidePreferenceHandler) List(c *gin.Context) {
 
 	// Load position requirements for each preference
 	for _, preference := range preferences {
-		requirements, err := h.repos.PreferencePositionRequirement.GetByPreferenceID(preference.ID)
+		requirements, err := h.repos.PreferencePositionRequirement.GetByPreferenceID(c.Request.Context(), preference.ID)
 		if err == nil {
 			preference.PositionRequirements = make([]models.PreferencePositionRequirement, len(requirements))
 			for i, req := range requirements {
 				preference.PositionRequirements[i] = *req
 				// Load position details
 				if req.PositionID != uuid.Nil {
-					position, err := h.repos.Position.GetByID(req.PositionID)
+					position, err := h.repos.Position.GetByID(c.Request.Context(), req.PositionID)
 					if err == nil && position != nil {
 						preference.PositionRequirements[i].Position = position
 					}
@@ -71,7 +71,7 @@ func (h *ClinicWidePreferenceHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	preference, err := h.repos.ClinicWidePreference.GetByID(id)
+	preference, err := h.repos.ClinicWidePreference.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -82,14 +82,14 @@ func (h *ClinicWidePreferenceHandler) GetByID(c *gin.Context) {
 	}
 
 	// Load position requirements
-	requirements, err := h.repos.PreferencePositionRequirement.GetByPreferenceID(preference.ID)
+	requirements, err := h.repos.PreferencePositionRequirement.GetByPreferenceID(c.Request.Context(), preference.ID)
 	if err == nil {
 		preference.PositionRequirements = make([]models.PreferencePositionRequirement, len(requirements))
 		for i, req := range requirements {
 			preference.PositionRequirements[i] = *req
 			// Load position details
 			if req.PositionID != uuid.Nil {
-				position, err := h.repos.Position.GetByID(req.PositionID)
+				position, err := h.repos.Position.GetByID(c.Request.Context(), req.PositionID)
 				if err == nil && position != nil {
 					preference.PositionRequirements[i].Position = position
 				}
@@ -149,7 +149,7 @@ func (h *ClinicWidePreferenceHandler) Create(c *gin.Context) {
 		Description:  req.Description,
 	}
 
-	if err := h.repos.ClinicWidePreference.Create(preference); err != nil {
+	if err := h.repos.ClinicWidePreference.Create(c.Request.Context(), preference); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -167,21 +167,21 @@ func (h *ClinicWidePreferenceHandler) Create(c *gin.Context) {
 				IsActive:       reqReq.IsActive,
 			}
 		}
-		if err := h.repos.PreferencePositionRequirement.BulkUpsert(requirements); err != nil {
+		if err := h.repos.PreferencePositionRequirement.BulkUpsert(c.Request.Context(), requirements); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create position requirements: " + err.Error()})
 			return
 		}
 	}
 
 	// Reload preference with requirements
-	requirements, _ := h.repos.PreferencePositionRequirement.GetByPreferenceID(preference.ID)
+	requirements, _ := h.repos.PreferencePositionRequirement.GetByPreferenceID(c.Request.Context(), preference.ID)
 	if requirements != nil {
 		preference.PositionRequirements = make([]models.PreferencePositionRequirement, len(requirements))
 		for i, req := range requirements {
 			preference.PositionRequirements[i] = *req
 			// Load position details
 			if req.PositionID != uuid.Nil {
-				position, err := h.repos.Position.GetByID(req.PositionID)
+				position, err := h.repos.Position.GetByID(c.Request.Context(), req.PositionID)
 				if err == nil && position != nil {
 					preference.PositionRequirements[i].Position = position
 				}
@@ -207,7 +207,7 @@ func (h *ClinicWidePreferenceHandler) Update(c *gin.Context) {
 		return
 	}
 
-	preference, err := h.repos.ClinicWidePreference.GetByID(id)
+	preference, err := h.repos.ClinicWidePreference.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -285,19 +285,19 @@ func (h *ClinicWidePreferenceHandler) Update(c *gin.Context) {
 		preference.Description = req.Description
 	}
 
-	if err := h.repos.ClinicWidePreference.Update(preference); err != nil {
+	if err := h.repos.ClinicWidePreference.Update(c.Request.Context(), preference); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Reload with requirements
-	requirements, _ := h.repos.PreferencePositionRequirement.GetByPreferenceID(preference.ID)
+	requirements, _ := h.repos.PreferencePositionRequirement.GetByPreferenceID(c.Request.Context(), preference.ID)
 	if requirements != nil {
 		preference.PositionRequirements = make([]models.PreferencePositionRequirement, len(requirements))
 		for i, req := range requirements {
 			preference.PositionRequirements[i] = *req
 			if req.PositionID != uuid.Nil {
-				position, err := h.repos.Position.GetByID(req.PositionID)
+				position, err := h.repos.Position.GetByID(c.Request.Context(), req.PositionID)
 				if err == nil && position != nil {
 					preference.PositionRequirements[i].Position = position
 				}
@@ -317,7 +317,7 @@ func (h *ClinicWidePreferenceHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.ClinicWidePreference.Delete(id); err != nil {
+	if err := h.repos.ClinicWidePreference.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -354,14 +354,14 @@ func (h *ClinicWidePreferenceHandler) AddPositionRequirement(c *gin.Context) {
 		IsActive:       req.IsActive,
 	}
 
-	if err := h.repos.PreferencePositionRequirement.Create(requirement); err != nil {
+	if err := h.repos.PreferencePositionRequirement.Create(c.Request.Context(), requirement); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Load position details
 	if requirement.PositionID != uuid.Nil {
-		position, err := h.repos.Position.GetByID(requirement.PositionID)
+		position, err := h.repos.Position.GetByID(c.Request.Context(), requirement.PositionID)
 		if err == nil && position != nil {
 			requirement.Position = position
 		}
@@ -392,7 +392,7 @@ func (h *ClinicWidePreferenceHandler) UpdatePositionRequirement(c *gin.Context)
 		return
 	}
 
-	requirement, err := h.repos.PreferencePositionRequirement.GetByPreferenceAndPosition(preferenceID, positionID)
+	requirement, err := h.repos.PreferencePositionRequirement.GetByPreferenceAndPosition(c.Request.Context(), preferenceID, positionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -419,14 +419,14 @@ func (h *ClinicWidePreferenceHandler) UpdatePositionRequirement(c *gin.Context)
 		return
 	}
 
-	if err := h.repos.PreferencePositionRequirement.Update(requirement); err != nil {
+	if err := h.repos.PreferencePositionRequirement.Update(c.Request.Context(), requirement); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Load position details
 	if requirement.PositionID != uuid.Nil {
-		position, err := h.repos.Position.GetByID(requirement.PositionID)
+		position, err := h.repos.Position.GetByID(c.Request.Context(), requirement.PositionID)
 		if err == nil && position != nil {
 			requirement.Position = position
 		}
@@ -451,7 +451,7 @@ func (h *ClinicWidePreferenceHandler) DeletePositionRequirement(c *gin.Context)
 		return
 	}
 
-	requirement, err := h.repos.PreferencePositionRequirement.GetByPreferenceAndPosition(preferenceID, positionID)
+	requirement, err := h.repos.PreferencePositionRequirement.GetByPreferenceAndPosition(c.Request.Context(), preferenceID, positionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -461,7 +461,7 @@ func (h *ClinicWidePreferenceHandler) DeletePositionRequirement(c *gin.Context)
 		return
 	}
 
-	if err := h.repos.PreferencePositionRequirement.Delete(requirement.ID); err != nil {
+	if err := h.repos.PreferencePositionRequirement.Delete(c.Request.Context(), requirement.ID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -486,7 +486,7 @@ func (h *ClinicWidePreferenceHandler) GetByCriteriaAndValue(c *gin.Context) {
 		return
 	}
 
-	preferences, err := h.repos.ClinicWidePreference.GetByCriteriaTypeAndValue(criteriaType, value)
+	preferences, err := h.repos.ClinicWidePreference.GetByCriteriaTypeAndValue(c.Request.Context(), criteriaType, value)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -494,13 +494,13 @@ func (h *ClinicWidePreferenceHandler) GetByCriteriaAndValue(c *gin.Context) {
 
 	// Load position requirements for each preference
 	for _, preference := range preferences {
-		requirements, err := h.repos.PreferencePositionRequirement.GetByPreferenceID(preference.ID)
+		requirements, err := h.repos.PreferencePositionRequirement.GetByPreferenceID(c.Request.Context(), preference.ID)
 		if err == nil {
 			preference.PositionRequirements = make([]models.PreferencePositionRequirement, len(requirements))
 			for i, req := range requirements {
 				preference.PositionRequirements[i] = *req
 				if req.PositionID != uuid.Nil {
-					position, err := h.repos.Position.GetByID(req.PositionID)
+					position, err := h.repos.Position.GetByID(c.Request.Context(), req.PositionID)
 					if err == nil && position != nil {
 						preference.PositionRequirements[i].Position = position
 					}
@@ -18,14 +18,14 @@ func NewDashboardHandler(repos *postgres.Repositories) *DashboardHandler {
 
 func (h *DashboardHandler) GetOverview(c *gin.Context) {
 	// Get summary statistics
-	branches, err := h.repos.Branch.List()
+	branches, err := h.repos.Branch.List(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	filters := interfaces.StaffFilters{}
-	staffList, err := h.repos.Staff.List(filters)
+	staffList, err := h.repos.Staff.List(c.Request.Context(), filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -1,26 +1,35 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
 	"vsq-oper-manpower/backend/internal/domain/models"
 	"vsq-oper-manpower/backend/internal/repositories/postgres"
+	"vsq-oper-manpower/backend/internal/usecases/doctor"
+	"vsq-oper-manpower/backend/internal/usecases/jobs"
 	"vsq-oper-manpower/backend/pkg/excel"
 )
 
 type DoctorHandler struct {
 	repos         *postgres.Repositories
+	roster        *doctor.RosterMaterializer
+	jobs          *jobs.Runner
 	excelImporter *excel.ExcelImporter
 }
 
-func NewDoctorHandler(repos *postgres.Repositories) *DoctorHandler {
+func NewDoctorHandler(repos *postgres.Repositories, roster *doctor.RosterMaterializer) *DoctorHandler {
 	return &DoctorHandler{
-		repos: repos,
+		repos:  repos,
+		roster: roster,
 		excelImporter: excel.NewExcelImporter(
 			repos.Position,
 			repos.Branch,
@@ -44,7 +53,7 @@ type UpdateDoctorRequest struct {
 }
 
 func (h *DoctorHandler) List(c *gin.Context) {
-	doctors, err := h.repos.Doctor.List()
+	doctors, err := h.repos.Doctor.List(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -60,7 +69,7 @@ func (h *DoctorHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	doctor, err := h.repos.Doctor.GetByID(id)
+	doctor, err := h.repos.Doctor.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -86,7 +95,7 @@ func (h *DoctorHandler) Create(c *gin.Context) {
 		Preferences: req.Preferences,
 	}
 
-	if err := h.repos.Doctor.Create(doctor); err != nil {
+	if err := h.repos.Doctor.Create(c.Request.Context(), doctor); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -108,7 +117,7 @@ func (h *DoctorHandler) Update(c *gin.Context) {
 		return
 	}
 
-	doctor, err := h.repos.Doctor.GetByID(id)
+	doctor, err := h.repos.Doctor.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -126,7 +135,7 @@ func (h *DoctorHandler) Update(c *gin.Context) {
 	}
 	doctor.Preferences = req.Preferences
 
-	if err := h.repos.Doctor.Update(doctor); err != nil {
+	if err := h.repos.Doctor.Update(c.Request.Context(), doctor); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -142,7 +151,7 @@ func (h *DoctorHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.Doctor.Delete(id); err != nil {
+	if err := h.repos.Doctor.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -150,12 +159,22 @@ func (h *DoctorHandler) Delete(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Doctor deleted successfully"})
 }
 
-// Doctor Schedule operations
+// registerJobs keeps the runner on-demand roster runs are queued on; the
+// roster job itself is registered by jobs.RosterJobs
+func (h *DoctorHandler) registerJobs(runner *jobs.Runner) {
+	h.jobs = runner
+}
+
+// Doctor Schedule operations. Assignments are materialized from the doctors'
+// schedules, so creating or deleting one records a schedule override for the
+// date and refreshes the roster.
 type CreateDoctorAssignmentRequest struct {
-	DoctorID        uuid.UUID `json:"doctor_id" binding:"required"`
-	BranchID        uuid.UUID `json:"branch_id" binding:"required"`
-	Date            string    `json:"date" binding:"required"`
-	ExpectedRevenue float64   `json:"expected_revenue"`
+	DoctorID uuid.UUID `json:"doctor_id" binding:"required"`
+	BranchID uuid.UUID `json:"branch_id" binding:"required"`
+	Date     string    `json:"date" binding:"required"`
+	// ExpectedRevenue, when positive, is recorded as the doctor's skin revenue
+	// for the date; use /doctors/revenue-overrides for the full breakdown
+	ExpectedRevenue float64 `json:"expected_revenue"`
 }
 
 func (h *DoctorHandler) CreateAssignment(c *gin.Context) {
@@ -179,7 +198,7 @@ func (h *DoctorHandler) CreateAssignment(c *gin.Context) {
 	}
 
 	// Validate doctor exists
-	doctor, err := h.repos.Doctor.GetByID(req.DoctorID)
+	doctor, err := h.repos.Doctor.GetByID(c.Request.Context(), req.DoctorID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -189,32 +208,132 @@ func (h *DoctorHandler) CreateAssignment(c *gin.Context) {
 		return
 	}
 
-	// Check maximum doctors per branch (6)
-	count, err := h.repos.DoctorAssignment.GetDoctorCountByBranch(req.BranchID, date)
+	// Check the branch's doctor capacity
+	branch, err := h.repos.Branch.GetByID(c.Request.Context(), req.BranchID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if branch == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Branch not found"})
+		return
+	}
+	count, err := h.repos.DoctorAssignment.GetDoctorCountByBranch(c.Request.Context(), req.BranchID, date)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	if count >= 6 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Maximum 6 doctors can be assigned to a branch per day"})
+	if capacity := branch.DoctorCapacity(); count >= capacity {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Maximum %d doctors can be assigned to this branch per day", capacity)})
 		return
 	}
 
-	assignment := &models.DoctorAssignment{
-		ID:              uuid.New(),
-		DoctorID:        req.DoctorID,
-		BranchID:        req.BranchID,
-		Date:            date,
-		ExpectedRevenue: req.ExpectedRevenue,
-		CreatedBy:       userID,
+	ctx := c.Request.Context()
+	branchID := req.BranchID
+
+	// A closed branch takes no doctors
+	if err := checkBranchOpen(ctx, h.repos.BranchClosure, &branchID, date); err != nil {
+		respondOverrideError(c, err)
+		return
+	}
+	err = h.repos.UnitOfWork.Do(ctx, func(tx *interfaces.Repositories) error {
+		if err := setScheduleOverride(ctx, tx, req.DoctorID, date, "working", &branchID, userID); err != nil {
+			return err
+		}
+		if req.ExpectedRevenue <= 0 {
+			return nil
+		}
+		return tx.DoctorRevenueOverride.Upsert(ctx, &models.DoctorRevenueOverride{
+			DoctorID:      req.DoctorID,
+			Date:          date,
+			DoctorRevenue: models.DoctorRevenue{SkinRevenue: req.ExpectedRevenue},
+			CreatedBy:     userID,
+		})
+	})
+	if err != nil {
+		respondOverrideError(c, err)
+		return
+	}
+	if _, err := h.roster.Refresh(ctx, date, date); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	if err := h.repos.DoctorAssignment.Create(assignment); err != nil {
+	assignments, err := h.repos.DoctorAssignment.GetByDoctorID(ctx, req.DoctorID, date, date)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	if len(assignments) == 0 {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Assignment was not materialized"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"assignment": assignments[0]})
+}
+
+// setScheduleOverride creates or replaces the doctor's schedule override for
+// date. A working override at a branch closed that day is refused with a
+// *branchClosedError.
+func setScheduleOverride(ctx context.Context, tx *interfaces.Repositories, doctorID uuid.UUID, date time.Time, overrideType string, branchID *uuid.UUID, userID uuid.UUID) error {
+	if overrideType == "working" {
+		if err := checkBranchOpen(ctx, tx.BranchClosure, branchID, date); err != nil {
+			return err
+		}
+	}
+	existing, err := tx.DoctorScheduleOverride.GetByDoctorAndDate(ctx, doctorID, date)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		existing.Type = overrideType
+		existing.BranchID = branchID
+		return tx.DoctorScheduleOverride.Update(ctx, existing)
+	}
+	return tx.DoctorScheduleOverride.Create(ctx, &models.DoctorScheduleOverride{
+		DoctorID:  doctorID,
+		Date:      date,
+		Type:      overrideType,
+		BranchID:  branchID,
+		CreatedBy: userID,
+	})
+}
+
+// branchClosedError refuses a doctor at a branch closed on the date
+type branchClosedError struct {
+	closure *models.BranchClosure
+}
+
+func (e *branchClosedError) Error() string {
+	return "Branch is closed on this date"
+}
+
+// checkBranchOpen returns a *branchClosedError when branchID is closed on date
+func checkBranchOpen(ctx context.Context, closureRepo interfaces.BranchClosureRepository, branchID *uuid.UUID, date time.Time) error {
+	if branchID == nil {
+		return nil
+	}
+	closures, err := closureRepo.List(ctx, interfaces.BranchClosureFilters{BranchID: branchID, StartDate: &date, EndDate: &date})
+	if err != nil {
+		return err
+	}
+	for _, closure := range closures {
+		if closure.Closes(*branchID, date) {
+			return &branchClosedError{closure: closure}
+		}
+	}
+	return nil
+}
 
-	c.JSON(http.StatusCreated, gin.H{"assignment": assignment})
+// respondOverrideError responds 400 with the closure for a branchClosedError
+// and 500 for anything else
+func respondOverrideError(c *gin.Context, err error) {
+	var closed *branchClosedError
+	if errors.As(err, &closed) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": closed.Error(), "closure": closed.closure})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 }
 
 func (h *DoctorHandler) GetAssignments(c *gin.Context) {
@@ -252,7 +371,7 @@ func (h *DoctorHandler) GetAssignments(c *gin.Context) {
 			endDate = startDate.AddDate(0, 1, -1)
 		}
 
-		assignments, err = h.repos.DoctorAssignment.GetByBranchID(branchID, startDate, endDate)
+		assignments, err = h.repos.DoctorAssignment.GetByBranchID(c.Request.Context(), branchID, startDate, endDate)
 	} else if doctorIDStr != "" {
 		doctorID, err := uuid.Parse(doctorIDStr)
 		if err != nil {
@@ -279,7 +398,7 @@ func (h *DoctorHandler) GetAssignments(c *gin.Context) {
 			endDate = startDate.AddDate(0, 1, -1)
 		}
 
-		assignments, err = h.repos.DoctorAssignment.GetByDoctorID(doctorID, startDate, endDate)
+		assignments, err = h.repos.DoctorAssignment.GetByDoctorID(c.Request.Context(), doctorID, startDate, endDate)
 	} else {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "branch_id or doctor_id is required"})
 		return
@@ -301,7 +420,33 @@ func (h *DoctorHandler) DeleteAssignment(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.DoctorAssignment.Delete(id); err != nil {
+	userID, err := uuid.Parse(c.MustGet("user_id").(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	// Only materialized assignments have IDs that can be looked up
+	ctx := c.Request.Context()
+	assignment, err := h.repos.DoctorAssignment.GetByID(ctx, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if assignment == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Assignment not found"})
+		return
+	}
+
+	// The doctor is off that day
+	err = h.repos.UnitOfWork.Do(ctx, func(tx *interfaces.Repositories) error {
+		return setScheduleOverride(ctx, tx, assignment.DoctorID, assignment.Date, "off", nil, userID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := h.roster.Refresh(ctx, assignment.Date, assignment.Date); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -309,6 +454,126 @@ func (h *DoctorHandler) DeleteAssignment(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Assignment deleted successfully"})
 }
 
+// GetRosterRuns lists the most recent roster materialization runs
+func (h *DoctorHandler) GetRosterRuns(c *gin.Context) {
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitStr); err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+	}
+
+	runs, err := h.repos.DoctorRoster.ListRuns(c.Request.Context(), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	window, err := h.repos.DoctorRoster.Window(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runs": runs, "window": window})
+}
+
+// GetRosterChanges lists doctors added, removed or moved by roster runs,
+// newest first, optionally for one run, doctor, branch or date range
+func (h *DoctorHandler) GetRosterChanges(c *gin.Context) {
+	filters := interfaces.DoctorRosterChangeFilters{Limit: 200}
+	for param, target := range map[string]**uuid.UUID{
+		"run_id":    &filters.RunID,
+		"doctor_id": &filters.DoctorID,
+		"branch_id": &filters.BranchID,
+	} {
+		if value := c.Query(param); value != "" {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param})
+				return
+			}
+			*target = &id
+		}
+	}
+	for param, target := range map[string]**time.Time{
+		"start_date": &filters.StartDate,
+		"end_date":   &filters.EndDate,
+	} {
+		if value := c.Query(param); value != "" {
+			date, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + " format"})
+				return
+			}
+			*target = &date
+		}
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		filters.Limit = limit
+	}
+
+	changes, err := h.repos.DoctorRoster.ListChanges(c.Request.Context(), filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"changes": changes})
+}
+
+// MaterializeRoster queues a roster run for the horizon, or for start_date to
+// end_date when both are given, and responds 202 with the job. A run for given
+// dates is refused with 409 while the schedule has validation errors, unless
+// force=true.
+func (h *DoctorHandler) MaterializeRoster(c *gin.Context) {
+	var payload jobs.MaterializeRosterPayload
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if (payload.StartDate == "") != (payload.EndDate == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start_date and end_date must be given together"})
+		return
+	}
+
+	// Publishing a chosen range, such as a month, requires a valid schedule
+	// unless forced
+	if payload.StartDate != "" && c.Query("force") != "true" {
+		startDate, err := time.Parse("2006-01-02", payload.StartDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format"})
+			return
+		}
+		endDate, err := time.Parse("2006-01-02", payload.EndDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format"})
+			return
+		}
+		validation, err := h.validateSchedule(c.Request.Context(), startDate, endDate, 0)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !validation.Valid {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":      fmt.Sprintf("Doctor schedule has %d error(s); fix them or pass force=true", validation.Errors),
+				"validation": validation,
+			})
+			return
+		}
+	}
+	enqueueJob(c, h.jobs, jobs.TypeMaterializeRoster, payload, nil)
+}
+
 // DoctorOnOffDay handlers
 type CreateDoctorOnOffDayRequest struct {
 	BranchID   uuid.UUID `json:"branch_id" binding:"required"`
@@ -351,10 +616,10 @@ func (h *DoctorHandler) CreateDoctorOnOffDay(c *gin.Context) {
 	}
 
 	// Check if already exists, update if so
-	existing, err := h.repos.DoctorOnOffDay.GetByBranchAndDate(req.BranchID, date)
+	existing, err := h.repos.DoctorOnOffDay.GetByBranchAndDate(c.Request.Context(), req.BranchID, date)
 	if err == nil && existing != nil {
 		existing.IsDoctorOn = req.IsDoctorOn
-		if err := h.repos.DoctorOnOffDay.Update(existing); err != nil {
+		if err := h.repos.DoctorOnOffDay.Update(c.Request.Context(), existing); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
@@ -370,7 +635,7 @@ func (h *DoctorHandler) CreateDoctorOnOffDay(c *gin.Context) {
 		CreatedBy:   userID,
 	}
 
-	if err := h.repos.DoctorOnOffDay.Create(day); err != nil {
+	if err := h.repos.DoctorOnOffDay.Create(c.Request.Context(), day); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -427,7 +692,7 @@ func (h *DoctorHandler) GetDoctorOnOffDays(c *gin.Context) {
 		endDate = startDate.AddDate(0, 1, -1)
 	}
 
-	days, err := h.repos.DoctorOnOffDay.GetByBranchID(branchID, startDate, endDate)
+	days, err := h.repos.DoctorOnOffDay.GetByBranchID(c.Request.Context(), branchID, startDate, endDate)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -444,7 +709,7 @@ func (h *DoctorHandler) DeleteDoctorOnOffDay(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.DoctorOnOffDay.Delete(id); err != nil {
+	if err := h.repos.DoctorOnOffDay.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -482,7 +747,7 @@ func (h *DoctorHandler) GetMonthlySchedule(c *gin.Context) {
 		month = int(now.Month())
 	}
 
-	assignments, err := h.repos.DoctorAssignment.GetMonthlySchedule(doctorID, year, month)
+	assignments, err := h.repos.DoctorAssignment.GetMonthlySchedule(c.Request.Context(), doctorID, year, month)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -500,6 +765,16 @@ type CreateDoctorPreferenceRequest struct {
 	IsActive   bool                   `json:"is_active"`
 }
 
+// validatePreferenceRule strictly decodes the rule_config of registered rule
+// kinds, so a malformed rule is rejected instead of being skipped at allocation
+func validatePreferenceRule(ruleType string, config map[string]interface{}) error {
+	if !models.IsPreferenceRuleKind(ruleType) {
+		return nil
+	}
+	_, err := models.DecodePreferenceRule(ruleType, config)
+	return err
+}
+
 func (h *DoctorHandler) ListPreferences(c *gin.Context) {
 	doctorIDStr := c.Query("doctor_id")
 	branchIDStr := c.Query("branch_id")
@@ -522,9 +797,9 @@ func (h *DoctorHandler) ListPreferences(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid branch_id"})
 			return
 		}
-		preferences, err = h.repos.DoctorPreference.GetByDoctorAndBranch(doctorID, branchID)
+		preferences, err = h.repos.DoctorPreference.GetByDoctorAndBranch(c.Request.Context(), doctorID, branchID)
 	} else {
-		preferences, err = h.repos.DoctorPreference.GetByDoctorID(doctorID)
+		preferences, err = h.repos.DoctorPreference.GetByDoctorID(c.Request.Context(), doctorID)
 	}
 
 	if err != nil {
@@ -541,6 +816,10 @@ func (h *DoctorHandler) CreatePreference(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := validatePreferenceRule(req.RuleType, req.RuleConfig); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	preference := &models.DoctorPreference{
 		DoctorID:   req.DoctorID,
@@ -550,7 +829,7 @@ func (h *DoctorHandler) CreatePreference(c *gin.Context) {
 		IsActive:   req.IsActive,
 	}
 
-	if err := h.repos.DoctorPreference.Create(preference); err != nil {
+	if err := h.repos.DoctorPreference.Create(c.Request.Context(), preference); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -571,8 +850,12 @@ func (h *DoctorHandler) UpdatePreference(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := validatePreferenceRule(req.RuleType, req.RuleConfig); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	preference, err := h.repos.DoctorPreference.GetByID(id)
+	preference, err := h.repos.DoctorPreference.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -588,7 +871,7 @@ func (h *DoctorHandler) UpdatePreference(c *gin.Context) {
 	preference.RuleConfig = req.RuleConfig
 	preference.IsActive = req.IsActive
 
-	if err := h.repos.DoctorPreference.Update(preference); err != nil {
+	if err := h.repos.DoctorPreference.Update(c.Request.Context(), preference); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -604,7 +887,7 @@ func (h *DoctorHandler) DeletePreference(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.DoctorPreference.Delete(id); err != nil {
+	if err := h.repos.DoctorPreference.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -612,15 +895,58 @@ func (h *DoctorHandler) DeletePreference(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Preference deleted successfully"})
 }
 
-// Doctor Default Schedule operations
+// Doctor Default Schedule operations. An every-week schedule replaces the
+// doctor's every-week schedule for the same weekday and slot; a restricted one
+// (interval_weeks, week_of_month, valid_from or valid_until) is added alongside.
 type CreateDoctorDefaultScheduleRequest struct {
 	DoctorID  uuid.UUID `json:"doctor_id" binding:"required"`
 	DayOfWeek int       `json:"day_of_week" binding:"required,min=0,max=6"` // 0=Sunday, 6=Saturday
 	BranchID  uuid.UUID `json:"branch_id" binding:"required"`
+	Slot      string    `json:"slot"` // "full_day" (default), "morning" or "afternoon"
+	ScheduleRecurrenceRequest
 }
 
+// UpdateDoctorDefaultScheduleRequest changes a schedule's branch. An empty
+// slot keeps the current one; sending any recurrence field replaces the
+// whole recurrence.
 type UpdateDoctorDefaultScheduleRequest struct {
 	BranchID uuid.UUID `json:"branch_id" binding:"required"`
+	Slot     string    `json:"slot"`
+	*ScheduleRecurrenceRequest
+}
+
+// ScheduleRecurrenceRequest is a models.ScheduleRecurrence with its dates as
+// YYYY-MM-DD strings
+type ScheduleRecurrenceRequest struct {
+	IntervalWeeks int    `json:"interval_weeks"`
+	AnchorDate    string `json:"anchor_date"`
+	WeekOfMonth   int    `json:"week_of_month"`
+	ValidFrom     string `json:"valid_from"`
+	ValidUntil    string `json:"valid_until"`
+}
+
+// Recurrence parses and validates the request
+func (r ScheduleRecurrenceRequest) Recurrence() (models.ScheduleRecurrence, error) {
+	recurrence := models.ScheduleRecurrence{IntervalWeeks: r.IntervalWeeks, WeekOfMonth: r.WeekOfMonth}
+	for _, field := range []struct {
+		name  string
+		value string
+		dest  **time.Time
+	}{
+		{"anchor_date", r.AnchorDate, &recurrence.AnchorDate},
+		{"valid_from", r.ValidFrom, &recurrence.ValidFrom},
+		{"valid_until", r.ValidUntil, &recurrence.ValidUntil},
+	} {
+		if field.value == "" {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", field.value)
+		if err != nil {
+			return recurrence, fmt.Errorf("invalid %s format", field.name)
+		}
+		*field.dest = &date
+	}
+	return recurrence, recurrence.Validate()
 }
 
 func (h *DoctorHandler) CreateDefaultSchedule(c *gin.Context) {
@@ -637,8 +963,18 @@ func (h *DoctorHandler) CreateDefaultSchedule(c *gin.Context) {
 		return
 	}
 
+	if !models.ValidDoctorSlot(req.Slot) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "slot must be full_day, morning or afternoon"})
+		return
+	}
+	recurrence, err := req.Recurrence()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Validate doctor exists
-	doctor, err := h.repos.Doctor.GetByID(req.DoctorID)
+	doctor, err := h.repos.Doctor.GetByID(c.Request.Context(), req.DoctorID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -649,13 +985,36 @@ func (h *DoctorHandler) CreateDefaultSchedule(c *gin.Context) {
 	}
 
 	schedule := &models.DoctorDefaultSchedule{
-		DoctorID:  req.DoctorID,
-		DayOfWeek: req.DayOfWeek,
-		BranchID:  req.BranchID,
-		CreatedBy: userID,
-	}
-
-	if err := h.repos.DoctorDefaultSchedule.Upsert(schedule); err != nil {
+		DoctorID:           req.DoctorID,
+		DayOfWeek:          req.DayOfWeek,
+		BranchID:           req.BranchID,
+		Slot:               req.Slot,
+		ScheduleRecurrence: recurrence,
+		CreatedBy:          userID,
+	}
+
+	ctx := c.Request.Context()
+	err = h.repos.UnitOfWork.Do(ctx, func(tx *interfaces.Repositories) error {
+		if !schedule.Restricted() {
+			// A full day replaces both halves of the day and a half replaces the full day
+			existing, err := tx.DoctorDefaultSchedule.GetByDoctorAndDayOfWeek(ctx, schedule.DoctorID, schedule.DayOfWeek)
+			if err != nil {
+				return err
+			}
+			slot := models.DoctorSlotOrFullDay(schedule.Slot)
+			for _, other := range existing {
+				if other.Restricted() || other.Slot == slot ||
+					(other.Slot != models.DoctorSlotFullDay && slot != models.DoctorSlotFullDay) {
+					continue
+				}
+				if err := tx.DoctorDefaultSchedule.Delete(ctx, other.ID); err != nil {
+					return err
+				}
+			}
+		}
+		return tx.DoctorDefaultSchedule.Upsert(ctx, schedule)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -676,7 +1035,7 @@ func (h *DoctorHandler) GetDefaultSchedules(c *gin.Context) {
 		return
 	}
 
-	schedules, err := h.repos.DoctorDefaultSchedule.GetByDoctorID(doctorID)
+	schedules, err := h.repos.DoctorDefaultSchedule.GetByDoctorID(c.Request.Context(), doctorID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -699,7 +1058,7 @@ func (h *DoctorHandler) UpdateDefaultSchedule(c *gin.Context) {
 		return
 	}
 
-	schedule, err := h.repos.DoctorDefaultSchedule.GetByID(id)
+	schedule, err := h.repos.DoctorDefaultSchedule.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -710,7 +1069,20 @@ func (h *DoctorHandler) UpdateDefaultSchedule(c *gin.Context) {
 	}
 
 	schedule.BranchID = req.BranchID
-	if err := h.repos.DoctorDefaultSchedule.Update(schedule); err != nil {
+	if req.Slot != "" {
+		if !models.ValidDoctorSlot(req.Slot) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "slot must be full_day, morning or afternoon"})
+			return
+		}
+		schedule.Slot = req.Slot
+	}
+	if req.ScheduleRecurrenceRequest != nil {
+		if schedule.ScheduleRecurrence, err = req.Recurrence(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if err := h.repos.DoctorDefaultSchedule.Update(c.Request.Context(), schedule); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -726,7 +1098,7 @@ func (h *DoctorHandler) DeleteDefaultSchedule(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.DoctorDefaultSchedule.Delete(id); err != nil {
+	if err := h.repos.DoctorDefaultSchedule.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -734,10 +1106,12 @@ func (h *DoctorHandler) DeleteDefaultSchedule(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Default schedule deleted successfully"})
 }
 
-// Doctor Weekly Off Day operations
+// Doctor Weekly Off Day operations. Like schedules, an off day may recur
+// every N weeks, on the nth weekday of the month or between two dates.
 type CreateDoctorWeeklyOffDayRequest struct {
 	DoctorID  uuid.UUID `json:"doctor_id" binding:"required"`
 	DayOfWeek int       `json:"day_of_week" binding:"required,min=0,max=6"` // 0=Sunday, 6=Saturday
+	ScheduleRecurrenceRequest
 }
 
 func (h *DoctorHandler) CreateWeeklyOffDay(c *gin.Context) {
@@ -755,7 +1129,7 @@ func (h *DoctorHandler) CreateWeeklyOffDay(c *gin.Context) {
 	}
 
 	// Validate doctor exists
-	doctor, err := h.repos.Doctor.GetByID(req.DoctorID)
+	doctor, err := h.repos.Doctor.GetByID(c.Request.Context(), req.DoctorID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -765,24 +1139,33 @@ func (h *DoctorHandler) CreateWeeklyOffDay(c *gin.Context) {
 		return
 	}
 
+	recurrence, err := req.Recurrence()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Check if already exists
-	existing, err := h.repos.DoctorWeeklyOffDay.GetByDoctorAndDayOfWeek(req.DoctorID, req.DayOfWeek)
-	if err != nil && err.Error() != "sql: no rows in result set" {
+	existing, err := h.repos.DoctorWeeklyOffDay.GetByDoctorAndDayOfWeek(c.Request.Context(), req.DoctorID, req.DayOfWeek)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	if existing != nil {
-		c.JSON(http.StatusOK, gin.H{"off_day": existing})
-		return
+	for _, offDay := range existing {
+		if offDay.ScheduleRecurrence.Equal(recurrence) {
+			c.JSON(http.StatusOK, gin.H{"off_day": offDay})
+			return
+		}
 	}
 
 	offDay := &models.DoctorWeeklyOffDay{
-		DoctorID:  req.DoctorID,
-		DayOfWeek: req.DayOfWeek,
-		CreatedBy: userID,
+		DoctorID:           req.DoctorID,
+		DayOfWeek:          req.DayOfWeek,
+		ScheduleRecurrence: recurrence,
+		CreatedBy:          userID,
 	}
 
-	if err := h.repos.DoctorWeeklyOffDay.Create(offDay); err != nil {
+	if err := h.repos.DoctorWeeklyOffDay.Create(c.Request.Context(), offDay); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -803,7 +1186,7 @@ func (h *DoctorHandler) GetWeeklyOffDays(c *gin.Context) {
 		return
 	}
 
-	offDays, err := h.repos.DoctorWeeklyOffDay.GetByDoctorID(doctorID)
+	offDays, err := h.repos.DoctorWeeklyOffDay.GetByDoctorID(c.Request.Context(), doctorID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -820,7 +1203,7 @@ func (h *DoctorHandler) DeleteWeeklyOffDay(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.DoctorWeeklyOffDay.Delete(id); err != nil {
+	if err := h.repos.DoctorWeeklyOffDay.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -869,7 +1252,7 @@ func (h *DoctorHandler) CreateScheduleOverride(c *gin.Context) {
 	}
 
 	// Validate doctor exists
-	doctor, err := h.repos.Doctor.GetByID(req.DoctorID)
+	doctor, err := h.repos.Doctor.GetByID(c.Request.Context(), req.DoctorID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -879,8 +1262,16 @@ func (h *DoctorHandler) CreateScheduleOverride(c *gin.Context) {
 		return
 	}
 
+	// A closed branch takes no doctors
+	if req.Type == "working" {
+		if err := checkBranchOpen(c.Request.Context(), h.repos.BranchClosure, req.BranchID, date); err != nil {
+			respondOverrideError(c, err)
+			return
+		}
+	}
+
 	// Check if override already exists for this date
-	existing, err := h.repos.DoctorScheduleOverride.GetByDoctorAndDate(req.DoctorID, date)
+	existing, err := h.repos.DoctorScheduleOverride.GetByDoctorAndDate(c.Request.Context(), req.DoctorID, date)
 	if err != nil && err.Error() != "sql: no rows in result set" {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -889,7 +1280,7 @@ func (h *DoctorHandler) CreateScheduleOverride(c *gin.Context) {
 		// Update existing override
 		existing.Type = req.Type
 		existing.BranchID = req.BranchID
-		if err := h.repos.DoctorScheduleOverride.Update(existing); err != nil {
+		if err := h.repos.DoctorScheduleOverride.Update(c.Request.Context(), existing); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
@@ -905,7 +1296,7 @@ func (h *DoctorHandler) CreateScheduleOverride(c *gin.Context) {
 		CreatedBy: userID,
 	}
 
-	if err := h.repos.DoctorScheduleOverride.Create(override); err != nil {
+	if err := h.repos.DoctorScheduleOverride.Create(c.Request.Context(), override); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -948,7 +1339,7 @@ func (h *DoctorHandler) GetScheduleOverrides(c *gin.Context) {
 		endDate = startDate.AddDate(0, 1, -1)
 	}
 
-	overrides, err := h.repos.DoctorScheduleOverride.GetByDoctorID(doctorID, startDate, endDate)
+	overrides, err := h.repos.DoctorScheduleOverride.GetByDoctorID(c.Request.Context(), doctorID, startDate, endDate)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -971,7 +1362,7 @@ func (h *DoctorHandler) UpdateScheduleOverride(c *gin.Context) {
 		return
 	}
 
-	override, err := h.repos.DoctorScheduleOverride.GetByID(id)
+	override, err := h.repos.DoctorScheduleOverride.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -993,9 +1384,17 @@ func (h *DoctorHandler) UpdateScheduleOverride(c *gin.Context) {
 		return
 	}
 
+	// A closed branch takes no doctors
+	if req.Type == "working" {
+		if err := checkBranchOpen(c.Request.Context(), h.repos.BranchClosure, req.BranchID, override.Date); err != nil {
+			respondOverrideError(c, err)
+			return
+		}
+	}
+
 	override.Type = req.Type
 	override.BranchID = req.BranchID
-	if err := h.repos.DoctorScheduleOverride.Update(override); err != nil {
+	if err := h.repos.DoctorScheduleOverride.Update(c.Request.Context(), override); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -1011,7 +1410,7 @@ func (h *DoctorHandler) DeleteScheduleOverride(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.DoctorScheduleOverride.Delete(id); err != nil {
+	if err := h.repos.DoctorScheduleOverride.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -1019,65 +1418,769 @@ func (h *DoctorHandler) DeleteScheduleOverride(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Schedule override deleted successfully"})
 }
 
-// Import doctors from Excel
-func (h *DoctorHandler) Import(c *gin.Context) {
-	// Get the uploaded file
-	file, err := c.FormFile("file")
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+// Doctor Revenue Profile operations
+type UpsertDoctorRevenueProfileRequest struct {
+	DoctorID     uuid.UUID  `json:"doctor_id" binding:"required"`
+	BranchID     *uuid.UUID `json:"branch_id"` // Omit for the profile used at every branch
+	DayOfWeek    int        `json:"day_of_week" binding:"min=0,max=6"`
+	SkinRevenue  float64    `json:"skin_revenue" binding:"min=0"`
+	LSHMRevenue  float64    `json:"ls_hm_revenue" binding:"min=0"`
+	VitaminCases int        `json:"vitamin_cases" binding:"min=0"`
+	SlimPenCases int        `json:"slim_pen_cases" binding:"min=0"`
+}
+
+// UpsertRevenueProfile creates or replaces the doctor's expected revenue for a
+// weekday, optionally at one branch
+func (h *DoctorHandler) UpsertRevenueProfile(c *gin.Context) {
+	var req UpsertDoctorRevenueProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Open the uploaded file
-	src, err := file.Open()
+	userID, err := uuid.Parse(c.MustGet("user_id").(string))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open uploaded file"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
 		return
 	}
-	defer src.Close()
 
-	// Read file data
-	fileData := make([]byte, file.Size)
-	_, err = src.Read(fileData)
-	if err != nil && err != io.EOF {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file data"})
+	doctor, err := h.repos.Doctor.GetByID(c.Request.Context(), req.DoctorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-
-	// Import doctors from Excel
-	doctorList, parseErr := h.excelImporter.ImportDoctors(fileData)
-	if parseErr != nil {
-		// If no valid records were parsed, return error immediately
-		if len(doctorList) == 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": parseErr.Error()})
+	if doctor == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Doctor not found"})
+		return
+	}
+	if req.BranchID != nil {
+		branch, err := h.repos.Branch.GetByID(c.Request.Context(), *req.BranchID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if branch == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Branch not found"})
 			return
 		}
-		// If there are valid records but also parsing errors, continue to save the valid ones
-		// The parsing errors will be included as warnings in the response
 	}
 
-	// Save imported doctors to database
-	var savedDoctors []*models.Doctor
-	var saveErrors []string
-	for _, doctor := range doctorList {
-		// Check if doctor with same code already exists (if code is provided)
-		if doctor.Code != "" {
-			existing, err := h.repos.Doctor.GetByCode(doctor.Code)
-			if err == nil && existing != nil {
-				saveErrors = append(saveErrors, fmt.Sprintf("Doctor code '%s' already exists: %s", doctor.Code, doctor.Name))
-				continue
-			}
-		}
+	profile := &models.DoctorRevenueProfile{
+		DoctorID:  req.DoctorID,
+		BranchID:  req.BranchID,
+		DayOfWeek: req.DayOfWeek,
+		DoctorRevenue: models.DoctorRevenue{
+			SkinRevenue:  req.SkinRevenue,
+			LSHMRevenue:  req.LSHMRevenue,
+			VitaminCases: req.VitaminCases,
+			SlimPenCases: req.SlimPenCases,
+		},
+		CreatedBy: userID,
+	}
+	if err := h.repos.DoctorRevenueProfile.Upsert(c.Request.Context(), profile); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-		if err := h.repos.Doctor.Create(doctor); err != nil {
-			saveErrors = append(saveErrors, fmt.Sprintf("Failed to save %s: %v", doctor.Name, err))
-			continue
-		}
-		savedDoctors = append(savedDoctors, doctor)
+	c.JSON(http.StatusOK, gin.H{"profile": profile})
+}
+
+func (h *DoctorHandler) GetRevenueProfiles(c *gin.Context) {
+	doctorIDStr := c.Query("doctor_id")
+	if doctorIDStr == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "doctor_id is required"})
+		return
 	}
 
-	// If no records were saved at all, return error
-	if len(savedDoctors) == 0 {
+	doctorID, err := uuid.Parse(doctorIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor_id"})
+		return
+	}
+
+	profiles, err := h.repos.DoctorRevenueProfile.GetByDoctorID(c.Request.Context(), doctorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"profiles": profiles})
+}
+
+func (h *DoctorHandler) DeleteRevenueProfile(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	if err := h.repos.DoctorRevenueProfile.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Revenue profile deleted successfully"})
+}
+
+// Doctor Revenue Override operations
+type UpsertDoctorRevenueOverrideRequest struct {
+	DoctorID     uuid.UUID `json:"doctor_id" binding:"required"`
+	Date         string    `json:"date" binding:"required"` // YYYY-MM-DD format
+	SkinRevenue  float64   `json:"skin_revenue" binding:"min=0"`
+	LSHMRevenue  float64   `json:"ls_hm_revenue" binding:"min=0"`
+	VitaminCases int       `json:"vitamin_cases" binding:"min=0"`
+	SlimPenCases int       `json:"slim_pen_cases" binding:"min=0"`
+}
+
+// UpsertRevenueOverride creates or replaces the doctor's expected revenue on
+// one date, taking precedence over their profiles
+func (h *DoctorHandler) UpsertRevenueOverride(c *gin.Context) {
+	var req UpsertDoctorRevenueOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+		return
+	}
+
+	userID, err := uuid.Parse(c.MustGet("user_id").(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	doctor, err := h.repos.Doctor.GetByID(c.Request.Context(), req.DoctorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if doctor == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Doctor not found"})
+		return
+	}
+
+	override := &models.DoctorRevenueOverride{
+		DoctorID: req.DoctorID,
+		Date:     date,
+		DoctorRevenue: models.DoctorRevenue{
+			SkinRevenue:  req.SkinRevenue,
+			LSHMRevenue:  req.LSHMRevenue,
+			VitaminCases: req.VitaminCases,
+			SlimPenCases: req.SlimPenCases,
+		},
+		CreatedBy: userID,
+	}
+	if err := h.repos.DoctorRevenueOverride.Upsert(c.Request.Context(), override); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"override": override})
+}
+
+func (h *DoctorHandler) GetRevenueOverrides(c *gin.Context) {
+	doctorIDStr := c.Query("doctor_id")
+	startDateStr := c.Query("start_date")
+	endDateStr := c.Query("end_date")
+
+	if doctorIDStr == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "doctor_id is required"})
+		return
+	}
+
+	doctorID, err := uuid.Parse(doctorIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor_id"})
+		return
+	}
+
+	var startDate, endDate time.Time
+	if startDateStr != "" && endDateStr != "" {
+		startDate, err = time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format"})
+			return
+		}
+		endDate, err = time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format"})
+			return
+		}
+	} else {
+		// Default to current month
+		now := time.Now()
+		startDate = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		endDate = startDate.AddDate(0, 1, -1)
+	}
+
+	overrides, err := h.repos.DoctorRevenueOverride.GetByDoctorID(c.Request.Context(), doctorID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"overrides": overrides})
+}
+
+func (h *DoctorHandler) DeleteRevenueOverride(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	if err := h.repos.DoctorRevenueOverride.Delete(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Revenue override deleted successfully"})
+}
+
+// Doctor Leave operations. Leave covers a date range; approved leave takes the
+// doctor off their schedule. Creating, reading or reviewing a leave responds
+// with its impact: every branch and date the doctor was due to work, and
+// whether the branch is left without a doctor and so closed for rotation.
+type CreateDoctorLeaveRequest struct {
+	DoctorID  uuid.UUID `json:"doctor_id" binding:"required"`
+	StartDate string    `json:"start_date" binding:"required"` // YYYY-MM-DD format
+	EndDate   string    `json:"end_date" binding:"required"`   // YYYY-MM-DD format, inclusive
+	Reason    string    `json:"reason"`
+}
+
+type ReviewDoctorLeaveRequest struct {
+	Status string `json:"status" binding:"required,oneof=approved rejected"`
+}
+
+// maxLeaveDays bounds a leave so its impact stays cheap to calculate
+const maxLeaveDays = 366
+
+func (h *DoctorHandler) CreateLeave(c *gin.Context) {
+	var req CreateDoctorLeaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format"})
+		return
+	}
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format"})
+		return
+	}
+	if endDate.Before(startDate) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_date must be on or after start_date"})
+		return
+	}
+	if endDate.After(startDate.AddDate(0, 0, maxLeaveDays-1)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Leave cannot be longer than %d days", maxLeaveDays)})
+		return
+	}
+
+	userID, err := uuid.Parse(c.MustGet("user_id").(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	onLeave, err := h.repos.Doctor.GetByID(ctx, req.DoctorID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if onLeave == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Doctor not found"})
+		return
+	}
+
+	leave := &models.DoctorLeave{
+		DoctorID:  req.DoctorID,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Reason:    req.Reason,
+		Status:    models.DoctorLeavePending,
+		CreatedBy: userID,
+	}
+	overlapping, err := h.overlappingLeave(ctx, leave)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if overlapping != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Doctor already has leave on these dates", "leave": overlapping})
+		return
+	}
+
+	if err := h.repos.DoctorLeave.Create(ctx, leave); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	leave.DoctorName = onLeave.Name
+
+	impact, err := h.leaveImpact(ctx, leave)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"leave": leave, "impact": impact})
+}
+
+// GetLeaves lists leave by start date, optionally for one doctor or status,
+// or overlapping start_date to end_date
+func (h *DoctorHandler) GetLeaves(c *gin.Context) {
+	filters := interfaces.DoctorLeaveFilters{Status: c.Query("status")}
+	switch filters.Status {
+	case "", models.DoctorLeavePending, models.DoctorLeaveApproved, models.DoctorLeaveRejected:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status"})
+		return
+	}
+	if value := c.Query("doctor_id"); value != "" {
+		doctorID, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid doctor_id"})
+			return
+		}
+		filters.DoctorID = &doctorID
+	}
+	for param, target := range map[string]**time.Time{
+		"start_date": &filters.StartDate,
+		"end_date":   &filters.EndDate,
+	} {
+		if value := c.Query(param); value != "" {
+			date, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + " format"})
+				return
+			}
+			*target = &date
+		}
+	}
+
+	leaves, err := h.repos.DoctorLeave.List(c.Request.Context(), filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"leaves": leaves})
+}
+
+// GetLeave returns a leave with its substitutes and impact
+func (h *DoctorHandler) GetLeave(c *gin.Context) {
+	leave, ok := h.findLeave(c)
+	if !ok {
+		return
+	}
+
+	impact, err := h.leaveImpact(c.Request.Context(), leave)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"leave": leave, "impact": impact})
+}
+
+// ReviewLeave approves or rejects a leave. Rejecting it removes its
+// substitutes, since the doctor works as scheduled.
+func (h *DoctorHandler) ReviewLeave(c *gin.Context) {
+	leave, ok := h.findLeave(c)
+	if !ok {
+		return
+	}
+
+	var req ReviewDoctorLeaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := uuid.Parse(c.MustGet("user_id").(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if req.Status == models.DoctorLeaveApproved {
+		overlapping, err := h.overlappingLeave(ctx, leave)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if overlapping != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "Doctor already has leave on these dates", "leave": overlapping})
+			return
+		}
+	}
+
+	now := time.Now()
+	leave.Status = req.Status
+	leave.ReviewedBy = &userID
+	leave.ReviewedAt = &now
+	err = h.repos.UnitOfWork.Do(ctx, func(tx *interfaces.Repositories) error {
+		if req.Status == models.DoctorLeaveRejected {
+			if err := removeLeaveSubstitutes(ctx, tx, leave.Substitutes); err != nil {
+				return err
+			}
+			leave.Substitutes = nil
+		}
+		return tx.DoctorLeave.Update(ctx, leave)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	impact, err := h.leaveImpact(ctx, leave)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"leave": leave, "impact": impact})
+}
+
+// DeleteLeave deletes a leave and its substitutes
+func (h *DoctorHandler) DeleteLeave(c *gin.Context) {
+	leave, ok := h.findLeave(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	err := h.repos.UnitOfWork.Do(ctx, func(tx *interfaces.Repositories) error {
+		if err := removeLeaveSubstitutes(ctx, tx, leave.Substitutes); err != nil {
+			return err
+		}
+		return tx.DoctorLeave.Delete(ctx, leave.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Leave deleted successfully"})
+}
+
+type AssignDoctorLeaveSubstitutesRequest struct {
+	Substitutes []DoctorLeaveSubstituteRequest `json:"substitutes" binding:"required,min=1,dive"`
+}
+
+type DoctorLeaveSubstituteRequest struct {
+	Date     string    `json:"date" binding:"required"` // YYYY-MM-DD format
+	BranchID uuid.UUID `json:"branch_id" binding:"required"`
+	DoctorID uuid.UUID `json:"doctor_id" binding:"required"` // The substitute
+}
+
+// AssignLeaveSubstitutes puts a substitute doctor at a branch on dates of the
+// leave, through a working schedule override for each. A substitute already
+// assigned to the branch and date is replaced.
+func (h *DoctorHandler) AssignLeaveSubstitutes(c *gin.Context) {
+	leave, ok := h.findLeave(c)
+	if !ok {
+		return
+	}
+	if leave.Status == models.DoctorLeaveRejected {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Leave was rejected"})
+		return
+	}
+
+	var req AssignDoctorLeaveSubstitutesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := uuid.Parse(c.MustGet("user_id").(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	substitutes := make([]*models.DoctorLeaveSubstitute, 0, len(req.Substitutes))
+	for _, item := range req.Substitutes {
+		date, err := time.Parse("2006-01-02", item.Date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
+			return
+		}
+		if !leave.Covers(date) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s is not a date of the leave", item.Date)})
+			return
+		}
+		if item.DoctorID == leave.DoctorID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A doctor cannot substitute for their own leave"})
+			return
+		}
+		substitute, err := h.repos.Doctor.GetByID(ctx, item.DoctorID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if substitute == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Substitute doctor not found"})
+			return
+		}
+		branch, err := h.repos.Branch.GetByID(ctx, item.BranchID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if branch == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Branch not found"})
+			return
+		}
+		away, err := h.repos.DoctorLeave.List(ctx, interfaces.DoctorLeaveFilters{
+			DoctorID: &item.DoctorID, Status: models.DoctorLeaveApproved, StartDate: &date, EndDate: &date,
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if len(away) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s is on leave on %s", substitute.Name, item.Date)})
+			return
+		}
+		substitutes = append(substitutes, &models.DoctorLeaveSubstitute{
+			LeaveID:   leave.ID,
+			Date:      date,
+			BranchID:  item.BranchID,
+			DoctorID:  item.DoctorID,
+			CreatedBy: userID,
+		})
+	}
+
+	err = h.repos.UnitOfWork.Do(ctx, func(tx *interfaces.Repositories) error {
+		for _, substitute := range substitutes {
+			for _, existing := range leave.Substitutes {
+				if existing.Date.Equal(substitute.Date) && existing.BranchID == substitute.BranchID {
+					if err := removeLeaveSubstitutes(ctx, tx, []*models.DoctorLeaveSubstitute{existing}); err != nil {
+						return err
+					}
+				}
+			}
+			branchID := substitute.BranchID
+			if err := setScheduleOverride(ctx, tx, substitute.DoctorID, substitute.Date, "working", &branchID, userID); err != nil {
+				return err
+			}
+			if err := tx.DoctorLeave.CreateSubstitute(ctx, substitute); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		respondOverrideError(c, err)
+		return
+	}
+
+	if leave.Substitutes, err = h.repos.DoctorLeave.GetSubstitutes(ctx, leave.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	impact, err := h.leaveImpact(ctx, leave)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"leave": leave, "impact": impact})
+}
+
+// DeleteLeaveSubstitute removes a substitute and the override that put them
+// on the roster
+func (h *DoctorHandler) DeleteLeaveSubstitute(c *gin.Context) {
+	leave, ok := h.findLeave(c)
+	if !ok {
+		return
+	}
+	substituteID, err := uuid.Parse(c.Param("substituteId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid substitute ID"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	substitute, err := h.repos.DoctorLeave.GetSubstituteByID(ctx, substituteID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if substitute == nil || substitute.LeaveID != leave.ID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Substitute not found"})
+		return
+	}
+
+	err = h.repos.UnitOfWork.Do(ctx, func(tx *interfaces.Repositories) error {
+		return removeLeaveSubstitutes(ctx, tx, []*models.DoctorLeaveSubstitute{substitute})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Substitute removed successfully"})
+}
+
+// findLeave loads the leave named by the :id parameter with its substitutes,
+// responding with an error when it cannot
+func (h *DoctorHandler) findLeave(c *gin.Context) (*models.DoctorLeave, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return nil, false
+	}
+	leave, err := h.repos.DoctorLeave.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if leave == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Leave not found"})
+		return nil, false
+	}
+	if leave.Substitutes, err = h.repos.DoctorLeave.GetSubstitutes(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return leave, true
+}
+
+// overlappingLeave returns another pending or approved leave of the doctor
+// sharing a date with leave, or nil
+func (h *DoctorHandler) overlappingLeave(ctx context.Context, leave *models.DoctorLeave) (*models.DoctorLeave, error) {
+	leaves, err := h.repos.DoctorLeave.List(ctx, interfaces.DoctorLeaveFilters{
+		DoctorID: &leave.DoctorID, StartDate: &leave.StartDate, EndDate: &leave.EndDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range leaves {
+		if other.ID != leave.ID && other.Status != models.DoctorLeaveRejected {
+			return other, nil
+		}
+	}
+	return nil, nil
+}
+
+// leaveImpact calculates where leave takes its doctor away from, with branch
+// codes and the substitutes assigned to each branch and date
+func (h *DoctorHandler) leaveImpact(ctx context.Context, leave *models.DoctorLeave) ([]*models.DoctorLeaveImpact, error) {
+	impact, err := h.scheduleCalculator().LeaveImpact(ctx, leave)
+	if err != nil {
+		return nil, err
+	}
+	branches, err := h.repos.Branch.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	codes := make(map[uuid.UUID]string, len(branches))
+	for _, branch := range branches {
+		codes[branch.ID] = branch.Code
+	}
+	for _, item := range impact {
+		item.BranchCode = codes[item.BranchID]
+		for _, substitute := range leave.Substitutes {
+			if substitute.BranchID == item.BranchID && substitute.Date.Format("2006-01-02") == item.Date.Format("2006-01-02") {
+				item.Substitute = substitute
+			}
+		}
+	}
+	return impact, nil
+}
+
+// removeLeaveSubstitutes deletes substitutes along with the working overrides
+// that put them on the roster, leaving any override changed since alone
+func removeLeaveSubstitutes(ctx context.Context, tx *interfaces.Repositories, substitutes []*models.DoctorLeaveSubstitute) error {
+	for _, substitute := range substitutes {
+		override, err := tx.DoctorScheduleOverride.GetByDoctorAndDate(ctx, substitute.DoctorID, substitute.Date)
+		if err != nil {
+			return err
+		}
+		if override != nil && override.Type == "working" && override.BranchID != nil && *override.BranchID == substitute.BranchID {
+			if err := tx.DoctorScheduleOverride.Delete(ctx, override.ID); err != nil {
+				return err
+			}
+		}
+		if err := tx.DoctorLeave.DeleteSubstitute(ctx, substitute.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Import doctors from Excel
+func (h *DoctorHandler) Import(c *gin.Context) {
+	// Get the uploaded file
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+		return
+	}
+
+	// Open the uploaded file
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open uploaded file"})
+		return
+	}
+	defer src.Close()
+
+	// Read file data
+	fileData := make([]byte, file.Size)
+	_, err = src.Read(fileData)
+	if err != nil && err != io.EOF {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file data"})
+		return
+	}
+
+	// Import doctors from Excel
+	doctorList, parseErr := h.excelImporter.ImportDoctors(c.Request.Context(), fileData)
+	if parseErr != nil {
+		// If no valid records were parsed, return error immediately
+		if len(doctorList) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": parseErr.Error()})
+			return
+		}
+		// If there are valid records but also parsing errors, continue to save the valid ones
+		// The parsing errors will be included as warnings in the response
+	}
+
+	// Save imported doctors to database
+	var savedDoctors []*models.Doctor
+	var saveErrors []string
+	for _, doctor := range doctorList {
+		// Check if doctor with same code already exists (if code is provided)
+		if doctor.Code != "" {
+			existing, err := h.repos.Doctor.GetByCode(c.Request.Context(), doctor.Code)
+			if err == nil && existing != nil {
+				saveErrors = append(saveErrors, fmt.Sprintf("Doctor code '%s' already exists: %s", doctor.Code, doctor.Name))
+				continue
+			}
+		}
+
+		if err := h.repos.Doctor.Create(c.Request.Context(), doctor); err != nil {
+			saveErrors = append(saveErrors, fmt.Sprintf("Failed to save %s: %v", doctor.Name, err))
+			continue
+		}
+		savedDoctors = append(savedDoctors, doctor)
+	}
+
+	// If no records were saved at all, return error
+	if len(savedDoctors) == 0 {
 		errorMsg := "Failed to save any doctor records"
 		if parseErr != nil {
 			errorMsg = fmt.Sprintf("%s. Parse errors: %v", errorMsg, parseErr)
@@ -1149,7 +2252,7 @@ func (h *DoctorHandler) ImportDefaultSchedules(c *gin.Context) {
 	}
 
 	// Import default schedules from Excel
-	result, parseErr := h.excelImporter.ImportDefaultSchedules(fileData)
+	result, parseErr := h.excelImporter.ImportDefaultSchedules(c.Request.Context(), fileData)
 	if parseErr != nil {
 		// If no valid records were parsed, return error immediately
 		if result == nil || (len(result.Schedules) == 0 && len(result.OffDays) == 0) {
@@ -1168,17 +2271,29 @@ func (h *DoctorHandler) ImportDefaultSchedules(c *gin.Context) {
 		return
 	}
 
-	// First, delete schedules for off days
+	// First, delete schedules for off days. The sheet holds every-week
+	// schedules only, so restricted ones entered in the app are kept.
 	var deletedOffDays int
 	var deleteErrors []string
 	for _, offDay := range result.OffDays {
-		// Find existing schedule for this doctor and day
-		existing, err := h.repos.DoctorDefaultSchedule.GetByDoctorAndDayOfWeek(offDay.DoctorID, offDay.DayOfWeek)
-		if err == nil && existing != nil {
-			if err := h.repos.DoctorDefaultSchedule.Delete(existing.ID); err != nil {
+		// Find existing schedules for this doctor and day
+		existing, err := h.repos.DoctorDefaultSchedule.GetByDoctorAndDayOfWeek(c.Request.Context(), offDay.DoctorID, offDay.DayOfWeek)
+		if err != nil {
+			deleteErrors = append(deleteErrors, fmt.Sprintf("Failed to get schedules for doctor %s, day %d: %v", offDay.DoctorID, offDay.DayOfWeek, err))
+			continue
+		}
+		deleted := false
+		for _, schedule := range existing {
+			if schedule.Restricted() {
+				continue
+			}
+			if err := h.repos.DoctorDefaultSchedule.Delete(c.Request.Context(), schedule.ID); err != nil {
 				deleteErrors = append(deleteErrors, fmt.Sprintf("Failed to delete schedule for doctor %s, day %d: %v", offDay.DoctorID, offDay.DayOfWeek, err))
 				continue
 			}
+			deleted = true
+		}
+		if deleted {
 			deletedOffDays++
 		}
 	}
@@ -1192,7 +2307,7 @@ func (h *DoctorHandler) ImportDefaultSchedules(c *gin.Context) {
 
 		// Use Upsert to handle duplicates (last imported entry wins)
 		// Upsert will update existing schedule if doctor_id + day_of_week combination exists
-		if err := h.repos.DoctorDefaultSchedule.Upsert(schedule); err != nil {
+		if err := h.repos.DoctorDefaultSchedule.Upsert(c.Request.Context(), schedule); err != nil {
 			saveErrors = append(saveErrors, fmt.Sprintf("Failed to save schedule for doctor %s, day %d: %v", schedule.DoctorID, schedule.DayOfWeek, err))
 			continue
 		}
@@ -1249,4 +2364,293 @@ func (h *DoctorHandler) ImportDefaultSchedules(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, response)
-}
\ No newline at end of file
+}
+// scheduleCalculator calculates the doctors' schedules from default schedules,
+// weekly off days, overrides and approved leave
+func (h *DoctorHandler) scheduleCalculator() *doctor.DoctorScheduleCalculator {
+	return doctor.NewDoctorScheduleCalculator(
+		h.repos.DoctorDefaultSchedule,
+		h.repos.DoctorWeeklyOffDay,
+		h.repos.DoctorScheduleOverride,
+		h.repos.Doctor,
+	).WithLeaves(h.repos.DoctorLeave)
+}
+
+// ImportRoster imports a monthly doctor roster grid from Excel. Only the days
+// the roster has differently from the doctors' schedules become schedule
+// overrides, and overrides the roster no longer needs are removed.
+func (h *DoctorHandler) ImportRoster(c *gin.Context) {
+	// Get the uploaded file
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+		return
+	}
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open uploaded file"})
+		return
+	}
+	defer src.Close()
+	fileData, err := io.ReadAll(src)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file data"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	result, parseErr := h.excelImporter.ImportDoctorRoster(ctx, fileData)
+	if parseErr != nil && (result == nil || len(result.Cells) == 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": parseErr.Error()})
+		return
+	}
+
+	userID, err := uuid.Parse(c.MustGet("user_id").(string))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	days := make([]*doctor.RosterDay, len(result.Cells))
+	cells := make(map[*doctor.RosterDay]*excel.DoctorRosterCell, len(result.Cells))
+	codes := make(map[uuid.UUID]string)
+	for i, cell := range result.Cells {
+		days[i] = &doctor.RosterDay{DoctorID: cell.DoctorID, Date: cell.Date, Off: cell.Off, BranchIDs: cell.BranchIDs}
+		cells[days[i]] = cell
+		codes[cell.DoctorID] = cell.DoctorCode
+	}
+	changes, unmatched, err := h.scheduleCalculator().DiffRoster(ctx, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var warnings []string
+	for _, day := range unmatched {
+		warnings = append(warnings, fmt.Sprintf("Row %d: %s: a day split between branches cannot be overridden; set it in the default schedule",
+			cells[day].Row, day.Date.Format("2006-01-02")))
+	}
+
+	branches, err := h.repos.Branch.List(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	branchCodes := make(map[uuid.UUID]string, len(branches))
+	for _, branch := range branches {
+		branchCodes[branch.ID] = branch.Code
+	}
+
+	// A working day at a closed branch is not imported
+	open, refused := changes[:0], 0
+	for _, change := range changes {
+		change.DoctorCode = codes[change.DoctorID]
+		if change.BranchID != nil {
+			change.BranchCode = branchCodes[*change.BranchID]
+		}
+		if change.Action == models.RosterImportSet && change.Type == "working" {
+			err := checkBranchOpen(ctx, h.repos.BranchClosure, change.BranchID, change.Date)
+			var closed *branchClosedError
+			if errors.As(err, &closed) {
+				warnings = append(warnings, fmt.Sprintf("%s: %s: %s is closed", change.DoctorCode, change.Date.Format("2006-01-02"), change.BranchCode))
+				refused++
+				continue
+			}
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		open = append(open, change)
+	}
+	changes = open
+
+	set, cleared := 0, 0
+	err = h.repos.UnitOfWork.Do(ctx, func(tx *interfaces.Repositories) error {
+		set, cleared = 0, 0
+		for _, change := range changes {
+			if change.Action == models.RosterImportClear {
+				if err := tx.DoctorScheduleOverride.Delete(ctx, *change.OverrideID); err != nil {
+					return err
+				}
+				cleared++
+				continue
+			}
+			if err := setScheduleOverride(ctx, tx, change.DoctorID, change.Date, change.Type, change.BranchID, userID); err != nil {
+				return err
+			}
+			set++
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if len(changes) > 0 {
+		if _, err := h.roster.Refresh(ctx, result.StartDate, result.EndDate); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	response := gin.H{
+		"message":           fmt.Sprintf("Imported roster from %s to %s: %d override(s) set, %d cleared", result.StartDate.Format("2006-01-02"), result.EndDate.Format("2006-01-02"), set, cleared),
+		"start_date":        result.StartDate.Format("2006-01-02"),
+		"end_date":          result.EndDate.Format("2006-01-02"),
+		"days":              len(result.Cells),
+		"overrides_set":     set,
+		"overrides_cleared": cleared,
+		"unchanged":         len(result.Cells) - len(changes) - len(unmatched) - refused,
+		"changes":           changes,
+	}
+	if parseErr != nil {
+		response["parse_warnings"] = parseErr.Error()
+	}
+	if len(warnings) > 0 {
+		response["import_warnings"] = warnings
+	}
+	// The roster as imported, checked ahead of publishing
+	if validation, err := h.validateSchedule(ctx, result.StartDate, result.EndDate, 0); err == nil {
+		response["validation"] = validation
+	}
+
+	// Return partial content if any cell could not be imported
+	if parseErr != nil || len(warnings) > 0 {
+		c.JSON(http.StatusPartialContent, response)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// ExportRoster downloads the calculated doctor roster for a month as an Excel
+// grid, doctors by dates, in the layout ImportRoster reads back
+func (h *DoctorHandler) ExportRoster(c *gin.Context) {
+	startDate, endDate, ok := queryMonth(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	assignments, err := h.scheduleCalculator().CalculateDetailedAssignmentsForDateRange(ctx, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	doctors, err := h.repos.Doctor.List(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	branches, err := h.repos.Branch.List(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	branchCodes := make(map[uuid.UUID]string, len(branches))
+	for _, branch := range branches {
+		branchCodes[branch.ID] = branch.Code
+	}
+
+	roster := &excel.DoctorRoster{Title: fmt.Sprintf("Doctor roster, %s", startDate.Format("January 2006"))}
+	for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 1) {
+		roster.Dates = append(roster.Dates, date)
+	}
+	rows := make(map[uuid.UUID]*excel.DoctorRosterRow, len(doctors))
+	for _, d := range doctors {
+		rows[d.ID] = &excel.DoctorRosterRow{DoctorCode: d.Code, DoctorName: d.Name, Branches: make([][]string, len(roster.Dates))}
+	}
+	// Assignments come ordered by date, doctor and slot
+	for _, assignment := range assignments {
+		row, ok := rows[assignment.DoctorID]
+		if !ok {
+			continue
+		}
+		day := assignment.Date.Day() - 1
+		row.Branches[day] = append(row.Branches[day], branchCodes[assignment.BranchID])
+	}
+	for _, d := range doctors {
+		roster.Rows = append(roster.Rows, *rows[d.ID])
+	}
+
+	data, err := excel.WriteDoctorRoster(roster)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("doctor-roster-%s.xlsx", startDate.Format("2006-01"))))
+	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", data)
+}
+
+// queryMonth returns the first and last dates of the year and month query
+// parameters, the current month without them. It responds 400 and returns
+// false when they are invalid.
+func queryMonth(c *gin.Context) (time.Time, time.Time, bool) {
+	now := time.Now()
+	year, month := now.Year(), int(now.Month())
+	if yearStr, monthStr := c.Query("year"), c.Query("month"); yearStr != "" || monthStr != "" {
+		var err error
+		if year, err = strconv.Atoi(yearStr); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+			return time.Time{}, time.Time{}, false
+		}
+		if month, err = strconv.Atoi(monthStr); err != nil || month < 1 || month > 12 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month"})
+			return time.Time{}, time.Time{}, false
+		}
+	}
+	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	return startDate, startDate.AddDate(0, 1, -1), true
+}
+
+// validateSchedule checks the calculated doctor schedule from startDate to
+// endDate for coverage gaps, over-capacity days and conflicting overrides
+func (h *DoctorHandler) validateSchedule(ctx context.Context, startDate, endDate time.Time, gapDays int) (*models.DoctorScheduleValidation, error) {
+	return doctor.NewScheduleValidator(
+		h.scheduleCalculator(),
+		h.repos.Branch,
+		h.repos.BranchClosure,
+		h.repos.DoctorOnOffDay,
+	).Validate(ctx, startDate, endDate, gapDays)
+}
+
+// ValidateSchedule validates the doctor schedule for start_date to end_date,
+// or the year and month given (the current month by default). gap_days sets
+// how many doctorless open days in a row make a coverage gap (default 7).
+func (h *DoctorHandler) ValidateSchedule(c *gin.Context) {
+	var startDate, endDate time.Time
+	if startDateStr, endDateStr := c.Query("start_date"), c.Query("end_date"); startDateStr != "" || endDateStr != "" {
+		var err error
+		if startDate, err = time.Parse("2006-01-02", startDateStr); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format"})
+			return
+		}
+		if endDate, err = time.Parse("2006-01-02", endDateStr); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format"})
+			return
+		}
+		if endDate.Before(startDate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "end_date must be after start_date"})
+			return
+		}
+	} else {
+		var ok bool
+		if startDate, endDate, ok = queryMonth(c); !ok {
+			return
+		}
+	}
+	gapDays := 0
+	if gapDaysStr := c.Query("gap_days"); gapDaysStr != "" {
+		var err error
+		if gapDays, err = strconv.Atoi(gapDaysStr); err != nil || gapDays <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid gap_days"})
+			return
+		}
+	}
+
+	validation, err := h.validateSchedule(c.Request.Context(), startDate, endDate, gapDays)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"validation": validation})
+}
@@ -41,7 +41,7 @@ func (h *EffectiveBranchHandler) GetByRotationStaffID(c *gin.Context) {
 	}
 
 	// Verify the staff member exists and is rotation staff
-	staff, err := h.repos.Staff.GetByID(rotationStaffID)
+	staff, err := h.repos.Staff.GetByID(c.Request.Context(), rotationStaffID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Rotation staff not found"})
 		return
@@ -52,7 +52,7 @@ func (h *EffectiveBranchHandler) GetByRotationStaffID(c *gin.Context) {
 		return
 	}
 
-	effectiveBranches, err := h.repos.EffectiveBranch.GetByRotationStaffID(rotationStaffID)
+	effectiveBranches, err := h.repos.EffectiveBranch.GetByRotationStaffID(c.Request.Context(), rotationStaffID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -73,7 +73,7 @@ func (h *EffectiveBranchHandler) GetByRotationStaffID(c *gin.Context) {
 
 	response := make([]EffectiveBranchResponse, 0)
 	for _, eb := range effectiveBranches {
-		branch, err := h.repos.Branch.GetByID(eb.BranchID)
+		branch, err := h.repos.Branch.GetByID(c.Request.Context(), eb.BranchID)
 		if err != nil {
 			continue // Skip if branch not found
 		}
@@ -103,7 +103,7 @@ func (h *EffectiveBranchHandler) Create(c *gin.Context) {
 	}
 
 	// Verify the staff member exists and is rotation staff
-	staff, err := h.repos.Staff.GetByID(req.RotationStaffID)
+	staff, err := h.repos.Staff.GetByID(c.Request.Context(), req.RotationStaffID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Rotation staff not found"})
 		return
@@ -115,14 +115,14 @@ func (h *EffectiveBranchHandler) Create(c *gin.Context) {
 	}
 
 	// Verify branch exists
-	_, err = h.repos.Branch.GetByID(req.BranchID)
+	_, err = h.repos.Branch.GetByID(c.Request.Context(), req.BranchID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Branch not found"})
 		return
 	}
 
 	// Check if this effective branch already exists
-	existingEBs, err := h.repos.EffectiveBranch.GetByRotationStaffID(req.RotationStaffID)
+	existingEBs, err := h.repos.EffectiveBranch.GetByRotationStaffID(c.Request.Context(), req.RotationStaffID)
 	if err == nil {
 		for _, eb := range existingEBs {
 			if eb.BranchID == req.BranchID {
@@ -142,13 +142,13 @@ func (h *EffectiveBranchHandler) Create(c *gin.Context) {
 		TravelCost:            req.TravelCost,
 	}
 
-	if err := h.repos.EffectiveBranch.Create(effectiveBranch); err != nil {
+	if err := h.repos.EffectiveBranch.Create(c.Request.Context(), effectiveBranch); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Load branch details
-	branch, _ := h.repos.Branch.GetByID(req.BranchID)
+	branch, _ := h.repos.Branch.GetByID(c.Request.Context(), req.BranchID)
 	effectiveBranch.Branch = branch
 
 	c.JSON(http.StatusCreated, gin.H{"effective_branch": effectiveBranch})
@@ -178,14 +178,14 @@ func (h *EffectiveBranchHandler) Update(c *gin.Context) {
 	}
 
 	// Get existing effective branch
-	existingEB, err := h.repos.EffectiveBranch.GetByID(id)
+	existingEB, err := h.repos.EffectiveBranch.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Effective branch not found"})
 		return
 	}
 
 	// Verify branch exists
-	_, err = h.repos.Branch.GetByID(req.BranchID)
+	_, err = h.repos.Branch.GetByID(c.Request.Context(), req.BranchID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Branch not found"})
 		return
@@ -198,13 +198,13 @@ func (h *EffectiveBranchHandler) Update(c *gin.Context) {
 	existingEB.TransitCount = req.TransitCount
 	existingEB.TravelCost = req.TravelCost
 
-	if err := h.repos.EffectiveBranch.Update(existingEB); err != nil {
+	if err := h.repos.EffectiveBranch.Update(c.Request.Context(), existingEB); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Load branch details
-	branch, _ := h.repos.Branch.GetByID(req.BranchID)
+	branch, _ := h.repos.Branch.GetByID(c.Request.Context(), req.BranchID)
 	existingEB.Branch = branch
 
 	c.JSON(http.StatusOK, gin.H{"effective_branch": existingEB})
@@ -219,7 +219,7 @@ func (h *EffectiveBranchHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.EffectiveBranch.Delete(id); err != nil {
+	if err := h.repos.EffectiveBranch.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -248,7 +248,7 @@ func (h *EffectiveBranchHandler) BulkUpdate(c *gin.Context) {
 	}
 
 	// Verify the staff member exists and is rotation staff
-	staff, err := h.repos.Staff.GetByID(req.RotationStaffID)
+	staff, err := h.repos.Staff.GetByID(c.Request.Context(), req.RotationStaffID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Rotation staff not found"})
 		return
@@ -261,7 +261,7 @@ func (h *EffectiveBranchHandler) BulkUpdate(c *gin.Context) {
 
 	// Verify all branches exist
 	for _, eb := range req.EffectiveBranches {
-		_, err := h.repos.Branch.GetByID(eb.BranchID)
+		_, err := h.repos.Branch.GetByID(c.Request.Context(), eb.BranchID)
 		if err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Branch not found: " + eb.BranchID.String()})
 			return
@@ -269,7 +269,7 @@ func (h *EffectiveBranchHandler) BulkUpdate(c *gin.Context) {
 	}
 
 	// Delete all existing effective branches for this rotation staff
-	if err := h.repos.EffectiveBranch.DeleteByRotationStaffID(req.RotationStaffID); err != nil {
+	if err := h.repos.EffectiveBranch.DeleteByRotationStaffID(c.Request.Context(), req.RotationStaffID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete existing effective branches: " + err.Error()})
 		return
 	}
@@ -287,13 +287,13 @@ func (h *EffectiveBranchHandler) BulkUpdate(c *gin.Context) {
 			TravelCost:            eb.TravelCost,
 		}
 
-		if err := h.repos.EffectiveBranch.Create(effectiveBranch); err != nil {
+		if err := h.repos.EffectiveBranch.Create(c.Request.Context(), effectiveBranch); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create effective branch: " + err.Error()})
 			return
 		}
 
 		// Load branch details
-		branch, _ := h.repos.Branch.GetByID(eb.BranchID)
+		branch, _ := h.repos.Branch.GetByID(c.Request.Context(), eb.BranchID)
 		effectiveBranch.Branch = branch
 		created = append(created, effectiveBranch)
 	}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"vsq-oper-manpower/backend/internal/domain/events"
+	"vsq-oper-manpower/backend/internal/middleware"
+	"vsq-oper-manpower/backend/internal/repositories/postgres"
+)
+
+// EventStreamHandler streams change events to the UI over server-sent events,
+// so pages refresh when assignments, schedules or quota statuses change
+// instead of polling the overview
+type EventStreamHandler struct {
+	repos     *postgres.Repositories
+	stream    *events.Stream
+	heartbeat time.Duration
+}
+
+func NewEventStreamHandler(repos *postgres.Repositories, stream *events.Stream) *EventStreamHandler {
+	return &EventStreamHandler{
+		repos:     repos,
+		stream:    stream,
+		heartbeat: 20 * time.Second,
+	}
+}
+
+// Close ends every open stream; the server calls it on shutdown
+func (h *EventStreamHandler) Close() {
+	h.stream.Close()
+}
+
+// streamEvent is the data of one server-sent event
+type streamEvent struct {
+	ID       string     `json:"id"`
+	Kind     string     `json:"kind"`
+	BranchID *uuid.UUID `json:"branch_id,omitempty"` // Absent when every branch is affected
+	Date     string     `json:"date,omitempty"`      // YYYY-MM-DD; absent when every date is affected
+	At       time.Time  `json:"at"`
+}
+
+// Stream sends change events for the caller's branches until the client
+// disconnects. A reconnecting client sends Last-Event-ID (or ?last_event_id=)
+// and receives what it missed; when that is no longer possible it receives a
+// "reset" event and should refetch its data.
+// GET /api/events/stream?branch_ids=
+func (h *EventStreamHandler) Stream(c *gin.Context) {
+	scope, ok := h.branchScope(c)
+	if !ok {
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	sub, backlog, replayed := h.stream.Subscribe(lastEventID)
+	defer h.stream.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Stop nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	send := func(w io.Writer, env events.Envelope) error {
+		if !inScope(scope, env.Event.BranchID) {
+			return nil
+		}
+		data := streamEvent{ID: env.ID, Kind: string(env.Event.Kind), At: env.At}
+		if env.Event.BranchID != uuid.Nil {
+			branchID := env.Event.BranchID
+			data.BranchID = &branchID
+		}
+		if !env.Event.Date.IsZero() {
+			data.Date = env.Event.Date.Format("2006-01-02")
+		}
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		return sse.Encode(w, sse.Event{Id: env.ID, Event: data.Kind, Data: string(payload)})
+	}
+
+	if !replayed {
+		if err := sse.Encode(c.Writer, sse.Event{Id: sub.Since, Event: "reset", Data: "{}"}); err != nil {
+			return
+		}
+	}
+	for _, env := range backlog {
+		if err := send(c.Writer, env); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+	ctx := c.Request.Context()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case env, open := <-sub.Events:
+			// A closed subscription fell behind; the client reconnects and replays
+			return open && send(w, env) == nil
+		case <-ticker.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		}
+	})
+}
+
+// branchScope returns the branches the caller may receive events for, nil
+// meaning every branch, narrowed by the optional branch_ids query
+func (h *EventStreamHandler) branchScope(c *gin.Context) (map[uuid.UUID]bool, bool) {
+	var scope map[uuid.UUID]bool
+	switch c.GetString("role") {
+	case "branch_manager":
+		branchID, ok := middleware.GetUserBranchID(c)
+		if !ok {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Branch manager must be assigned to a branch"})
+			return nil, false
+		}
+		scope = map[uuid.UUID]bool{*branchID: true}
+	case "area_manager":
+		userID, err := uuid.Parse(c.GetString("user_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return nil, false
+		}
+		branches, err := h.repos.Branch.GetByAreaManagerID(c.Request.Context(), userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		// Area managers without assigned branches oversee every branch, as in the overview
+		if len(branches) > 0 {
+			scope = make(map[uuid.UUID]bool, len(branches))
+			for _, branch := range branches {
+				scope[branch.ID] = true
+			}
+		}
+	}
+
+	requested := parseBranchIDs(c.Query("branch_ids"))
+	if len(requested) == 0 {
+		return scope, true
+	}
+	narrowed := make(map[uuid.UUID]bool, len(requested))
+	for _, branchID := range requested {
+		if !inScope(scope, branchID) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Access denied to branch " + branchID.String()})
+			return nil, false
+		}
+		narrowed[branchID] = true
+	}
+	return narrowed, true
+}
+
+// inScope reports whether an event for branchID reaches a client scoped to
+// scope; events for every branch reach everyone
+func inScope(scope map[uuid.UUID]bool, branchID uuid.UUID) bool {
+	return scope == nil || branchID == uuid.Nil || scope[branchID]
+}
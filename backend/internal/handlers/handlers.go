@@ -2,9 +2,15 @@ package handlers
 
 import (
 	"database/sql"
+	"log"
 	"vsq-oper-manpower/backend/internal/config"
+	"vsq-oper-manpower/backend/internal/domain/events"
 	"vsq-oper-manpower/backend/internal/repositories/postgres"
 	"vsq-oper-manpower/backend/internal/usecases/allocation"
+	"vsq-oper-manpower/backend/internal/usecases/doctor"
+	"vsq-oper-manpower/backend/internal/usecases/jobs"
+	"vsq-oper-manpower/backend/pkg/lru"
+	"vsq-oper-manpower/backend/pkg/metrics"
 )
 
 type Handlers struct {
@@ -37,9 +43,23 @@ type Handlers struct {
 	ClinicWidePreference        *ClinicWidePreferenceHandler
 	TestData                    *TestDataHandler
 	RotationStaffBranchPosition *RotationStaffBranchPositionHandler
+	APIToken                    *APITokenHandler
+	OIDC                        *OIDCHandler
+	Cache                       *CacheHandler
+	Job                         *JobHandler
+	EventStream                 *EventStreamHandler
+	Health                      *HealthHandler
+	// SummaryWorker recomputes queued quota summaries; the server runs it in the background
+	SummaryWorker *allocation.SummaryWorker
+	// JobRunner runs background jobs; AllocationJobs and RosterJobs own their default schedules
+	JobRunner      *jobs.Runner
+	AllocationJobs *jobs.AllocationJobs
+	RosterJobs     *jobs.RosterJobs
+	// Metrics is served on /metrics; request metrics register on it too
+	Metrics *metrics.Registry
 }
 
-func NewHandlers(repos *postgres.Repositories, cfg *config.Config, db *sql.DB) *Handlers {
+func NewHandlers(repos *postgres.Repositories, cfg *config.Config, db *sql.DB, bus *events.Bus) *Handlers {
 	// Initialize use cases
 	// Create a wrapper that implements the interfaces needed by use cases
 	reposWrapper := &allocation.RepositoriesWrapper{
@@ -61,36 +81,114 @@ func NewHandlers(repos *postgres.Repositories, cfg *config.Config, db *sql.DB) *
 		DoctorPreference:            repos.DoctorPreference,
 		DoctorAssignment:            repos.DoctorAssignment,
 		DoctorOnOffDay:              repos.DoctorOnOffDay,
+		DoctorDefaultSchedule:       repos.DoctorDefaultSchedule,
+		DoctorWeeklyOffDay:          repos.DoctorWeeklyOffDay,
+		DoctorScheduleOverride:      repos.DoctorScheduleOverride,
+		DoctorRevenueProfile:        repos.DoctorRevenueProfile,
+		DoctorRevenueOverride:       repos.DoctorRevenueOverride,
+		DoctorLeave:                 repos.DoctorLeave,
+		BranchClosure:               repos.BranchClosure,
 		BranchType:                  repos.BranchType,
 		StaffGroup:                  repos.StaffGroup,
 		StaffGroupPosition:          repos.StaffGroupPosition,
 		BranchTypeRequirement:       repos.BranchTypeRequirement,
 		BranchTypeConstraints:       repos.BranchTypeConstraints,
 		BranchConstraints:           repos.BranchConstraints,
+		RotationStaffSchedule:       repos.RotationStaffSchedule,
+		SpecificPreference:          repos.SpecificPreference,
 		RotationStaffBranchPosition: repos.RotationStaffBranchPosition,
 		AllocationSuggestion:        repos.AllocationSuggestion,
 		BranchQuotaSummary:          repos.BranchQuotaSummary,
+		UnitOfWork:                  repos.UnitOfWork,
 	}
 
-	quotaCalculator := allocation.NewQuotaCalculator(reposWrapper)
-	overviewGenerator := allocation.NewOverviewGenerator(reposWrapper, quotaCalculator)
-	multiCriteriaFilter := allocation.NewMultiCriteriaFilter(reposWrapper)
+	// Doctor assignments outside the materialized roster are calculated a month
+	// at a time and kept until a doctor schedule write drops the month
+	doctorCalendar := doctor.NewDoctorCalendar(
+		doctor.NewDoctorScheduleCalculator(
+			repos.DoctorDefaultSchedule,
+			repos.DoctorWeeklyOffDay,
+			repos.DoctorScheduleOverride,
+			repos.Doctor,
+		).WithRevenue(repos.DoctorRevenueProfile, repos.DoctorRevenueOverride).
+			WithLeaves(repos.DoctorLeave),
+		cfg.Cache.DoctorCalendarSize, cfg.Cache.TTL)
+	bus.Subscribe(doctorCalendar.HandleEvent)
+	if r, ok := repos.DoctorAssignment.(interface{ UseCalendar(*doctor.DoctorCalendar) }); ok {
+		r.UseCalendar(doctorCalendar)
+	}
+
+	quotaCalculator := allocation.NewQuotaCalculator(reposWrapper).
+		WithWorkers(cfg.ComputeWorkers).
+		WithStatusCache(cfg.Cache.QuotaStatusSize, cfg.Cache.TTL)
+	overviewGenerator := allocation.NewOverviewGenerator(reposWrapper, quotaCalculator).
+		WithCache(cfg.Cache.OverviewSize, cfg.Cache.TTL)
+	// Evict cached overviews and statuses as soon as the data behind them changes
+	bus.Subscribe(overviewGenerator.HandleEvent)
+	bus.Subscribe(quotaCalculator.HandleEvent)
+	summaryWorker := allocation.NewSummaryWorker(reposWrapper, quotaCalculator).
+		WithSchedule(cfg.SummaryWorker.Interval, cfg.SummaryWorker.Debounce, cfg.SummaryWorker.MaxWait).
+		WithBatchSize(cfg.SummaryWorker.BatchSize).
+		WithEvents(bus)
+	bus.Subscribe(summaryWorker.HandleEvent)
+	// Forward every change to connected clients
+	eventStream := events.NewStream(cfg.EventStream.LogSize, cfg.EventStream.LogMaxAge)
+	bus.Subscribe(eventStream.Handle)
+	registry := metrics.NewRegistry()
+	multiCriteriaFilter := allocation.NewMultiCriteriaFilter(reposWrapper).
+		WithDurationMetric(registry.NewHistogram("allocation_ranking_duration_seconds",
+			"Time to rank rotation staff suggestions for a day", nil))
+
+	// Background jobs: imports and generation that outgrow a request, plus scheduled work
+	jobRunner := jobs.NewRunner(repos.Job, repos.JobSchedule).
+		WithWorkers(cfg.Jobs.Workers).
+		WithPollInterval(cfg.Jobs.PollInterval).
+		WithRetention(cfg.Jobs.Retention).
+		WithShutdownGrace(cfg.Jobs.ShutdownGrace)
+	suggestionEngine := allocation.NewSuggestionEngine(reposWrapper, multiCriteriaFilter, quotaCalculator)
+	allocationJobs := jobs.NewAllocationJobs(reposWrapper, suggestionEngine, quotaCalculator)
+	allocationJobs.Register(jobRunner)
+
+	// Doctor assignments are read from a materialized roster that a nightly job
+	// rolls forward and doctor schedule changes refresh
+	rosterMaterializer := doctor.NewRosterMaterializer(repos.UnitOfWork, cfg.Jobs.RosterHorizonDays).
+		WithEvents(bus)
+	bus.Subscribe(rosterMaterializer.HandleEvent)
+	rosterJobs := jobs.NewRosterJobs(rosterMaterializer)
+	rosterJobs.Register(jobRunner)
+
+	// Readiness compares the schema with this build's migrations
+	var migrator *postgres.Migrator
+	if db != nil {
+		registerDBMetrics(registry, db)
+		var err error
+		if migrator, err = postgres.NewMigrator(db); err != nil {
+			log.Printf("Readiness will not check migrations: %v", err)
+		}
+	}
+	registerCacheMetrics(registry, map[string]func() lru.Stats{
+		"day_overview":    overviewGenerator.CacheStats,
+		"quota_status":    quotaCalculator.CacheStats,
+		"doctor_calendar": doctorCalendar.CacheStats,
+	})
+	registerJobMetrics(registry, repos.Job)
+	registerEventMetrics(registry, bus, eventStream)
 
-	return &Handlers{
+	h := &Handlers{
 		Auth:                        NewAuthHandler(repos, cfg),
 		User:                        NewUserHandler(repos),
 		Staff:                       NewStaffHandler(repos),
 		Position:                    NewPositionHandler(repos, db),
 		Branch:                      NewBranchHandler(repos),
 		Schedule:                    NewScheduleHandler(repos),
-		Rotation:                    NewRotationHandler(repos, cfg, multiCriteriaFilter),
+		Rotation:                    NewRotationHandler(repos, cfg, multiCriteriaFilter, allocation.NewPairingResolver(reposWrapper)),
 		EffectiveBranch:             NewEffectiveBranchHandler(repos),
 		AreaOfOperation:             NewAreaOfOperationHandler(repos),
 		Zone:                        NewZoneHandler(repos),
 		Settings:                    NewSettingsHandler(repos),
 		Dashboard:                   NewDashboardHandler(repos),
 		Version:                     NewVersionHandler(),
-		Doctor:                      NewDoctorHandler(repos),
+		Doctor:                      NewDoctorHandler(repos, rosterMaterializer),
 		Quota:                       NewQuotaHandler(repos, quotaCalculator),
 		Overview:                    NewOverviewHandler(repos, overviewGenerator),
 		AllocationCriteria:          NewAllocationCriteriaHandler(repos),
@@ -106,5 +204,22 @@ func NewHandlers(repos *postgres.Repositories, cfg *config.Config, db *sql.DB) *
 		ClinicWidePreference:        NewClinicWidePreferenceHandler(repos),
 		TestData:                    NewTestDataHandler(repos),
 		RotationStaffBranchPosition: NewRotationStaffBranchPositionHandler(repos, db),
+		APIToken:                    NewAPITokenHandler(repos),
+		OIDC:                        NewOIDCHandler(repos, cfg),
+		Cache:                       NewCacheHandler(overviewGenerator, quotaCalculator, doctorCalendar, bus),
+		Job:                         NewJobHandler(repos, jobRunner),
+		EventStream:                 NewEventStreamHandler(repos, eventStream),
+		Health:                      NewHealthHandler(db, migrator, jobRunner),
+		SummaryWorker:               summaryWorker,
+		JobRunner:                   jobRunner,
+		AllocationJobs:              allocationJobs,
+		RosterJobs:                  rosterJobs,
+		Metrics:                     registry,
 	}
+	h.Staff.registerJobs(jobRunner)
+	h.Quota.registerJobs(jobRunner)
+	h.Branch.registerJobs(jobRunner)
+	h.TestData.registerJobs(jobRunner)
+	h.Doctor.registerJobs(jobRunner)
+	return h
 }
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"vsq-oper-manpower/backend/internal/repositories/postgres"
+	"vsq-oper-manpower/backend/internal/usecases/jobs"
+)
+
+// HealthHandler answers liveness and readiness probes
+type HealthHandler struct {
+	db       *sql.DB            // nil with memory storage
+	migrator *postgres.Migrator // nil with memory storage
+	runner   *jobs.Runner
+	timeout  time.Duration // Bounds each readiness check
+}
+
+func NewHealthHandler(db *sql.DB, migrator *postgres.Migrator, runner *jobs.Runner) *HealthHandler {
+	return &HealthHandler{
+		db:       db,
+		migrator: migrator,
+		runner:   runner,
+		timeout:  2 * time.Second,
+	}
+}
+
+// healthCheck is the outcome of one readiness check
+type healthCheck struct {
+	Status    string  `json:"status"` // "ok" or "fail"
+	Error     string  `json:"error,omitempty"`
+	LatencyMS float64 `json:"latency_ms"`
+	Version   int64   `json:"version,omitempty"` // Schema version, for the migrations check
+}
+
+// Live reports that the process is up and serving; it checks no dependencies
+// so a database outage does not get the server restarted
+// GET /health/live
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Ready reports whether the server can do its work: the database answers, its
+// schema is exactly this build's and the job workers are polling. It responds
+// 503 when any check fails so load balancers stop routing to the instance.
+// GET /health/ready
+func (h *HealthHandler) Ready(c *gin.Context) {
+	checks := map[string]*healthCheck{}
+	if h.db != nil {
+		checks["database"] = h.check(c.Request.Context(), func(ctx context.Context, check *healthCheck) error {
+			return h.db.PingContext(ctx)
+		})
+	}
+	if h.migrator != nil {
+		checks["migrations"] = h.check(c.Request.Context(), func(ctx context.Context, check *healthCheck) error {
+			if err := h.migrator.VerifyContext(ctx); err != nil {
+				return err
+			}
+			if migrations := h.migrator.Migrations(); len(migrations) > 0 {
+				check.Version = migrations[len(migrations)-1].Version
+			}
+			return nil
+		})
+	}
+	checks["jobs"] = h.check(c.Request.Context(), func(ctx context.Context, check *healthCheck) error {
+		return h.runner.Health()
+	})
+
+	status, code := "ok", http.StatusOK
+	for _, check := range checks {
+		if check.Status != "ok" {
+			status, code = "unavailable", http.StatusServiceUnavailable
+		}
+	}
+	c.JSON(code, gin.H{"status": status, "checks": checks})
+}
+
+func (h *HealthHandler) check(ctx context.Context, run func(ctx context.Context, check *healthCheck) error) *healthCheck {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	check := &healthCheck{Status: "ok"}
+	start := time.Now()
+	if err := run(ctx, check); err != nil {
+		check.Status = "fail"
+		check.Error = err.Error()
+	}
+	check.LatencyMS = float64(time.Since(start).Microseconds()) / 1000
+	return check
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+	"vsq-oper-manpower/backend/internal/repositories/postgres"
+	"vsq-oper-manpower/backend/internal/usecases/jobs"
+)
+
+// Job types for requests that can run in the background with ?async=true
+const (
+	JobTypeStaffImport           = "staff.import"
+	JobTypeQuotaImport           = "quota.import"
+	JobTypeRevenueImport         = "revenue.import"
+	JobTypeGenerateTestSchedules = "test_data.generate_schedules"
+)
+
+// JobHandler lets clients poll background jobs, download their results and
+// lets admins queue jobs and manage recurring schedules
+type JobHandler struct {
+	repos  *postgres.Repositories
+	runner *jobs.Runner
+}
+
+func NewJobHandler(repos *postgres.Repositories, runner *jobs.Runner) *JobHandler {
+	return &JobHandler{
+		repos:  repos,
+		runner: runner,
+	}
+}
+
+// List returns the caller's jobs, newest first; admins see every job
+// GET /api/jobs?type=&status=&limit=
+func (h *JobHandler) List(c *gin.Context) {
+	filters := interfaces.JobFilters{Type: c.Query("type"), Limit: 50}
+	if status := c.Query("status"); status != "" {
+		jobStatus := models.JobStatus(status)
+		filters.Status = &jobStatus
+	}
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > 500 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 500"})
+			return
+		}
+		filters.Limit = limit
+	}
+	if c.GetString("role") != "admin" {
+		userID, err := uuid.Parse(c.GetString("user_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		filters.CreatedBy = &userID
+	}
+
+	jobList, err := h.repos.Job.List(c.Request.Context(), filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"jobs": jobList})
+}
+
+// Get returns one job with its progress and result
+// GET /api/jobs/:id
+func (h *JobHandler) Get(c *gin.Context) {
+	job, ok := h.loadJob(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"job": job})
+}
+
+// GetResult downloads the file a finished job produced, or returns its JSON result
+// GET /api/jobs/:id/result
+func (h *JobHandler) GetResult(c *gin.Context) {
+	job, ok := h.loadJob(c)
+	if !ok {
+		return
+	}
+	if job.Status != models.JobStatusSucceeded {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Job is %s", job.Status), "job": job})
+		return
+	}
+
+	if job.ResultFileName != "" {
+		file, err := h.repos.Job.GetResultFile(c.Request.Context(), job.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if file != nil {
+			c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.Name))
+			c.Data(http.StatusOK, file.ContentType, file.Data)
+			return
+		}
+	}
+	if len(job.Result) == 0 {
+		c.JSON(http.StatusOK, gin.H{"result": nil})
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", job.Result)
+}
+
+// Cancel cancels a queued job or asks a running one to stop
+// POST /api/jobs/:id/cancel
+func (h *JobHandler) Cancel(c *gin.Context) {
+	job, ok := h.loadJob(c)
+	if !ok {
+		return
+	}
+	if job.IsFinished() {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Job already %s", job.Status), "job": job})
+		return
+	}
+	job, err := h.runner.Cancel(c.Request.Context(), job.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"job": job})
+}
+
+type CreateJobRequest struct {
+	Type    string          `json:"type" binding:"required"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Create queues a job of any registered type (admin only)
+// POST /api/jobs
+func (h *JobHandler) Create(c *gin.Context) {
+	var req CreateJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var payload interface{}
+	if len(req.Payload) > 0 {
+		payload = req.Payload
+	}
+	enqueueJob(c, h.runner, req.Type, payload, nil)
+}
+
+// ListSchedules returns the recurring job schedules (admin only)
+// GET /api/jobs/schedules
+func (h *JobHandler) ListSchedules(c *gin.Context) {
+	schedules, err := h.repos.JobSchedule.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"schedules": schedules})
+}
+
+type UpdateJobScheduleRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// UpdateSchedule pauses or resumes a recurring job (admin only)
+// PUT /api/jobs/schedules/:name
+func (h *JobHandler) UpdateSchedule(c *gin.Context) {
+	var req UpdateJobScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	name := c.Param("name")
+	if err := h.repos.JobSchedule.SetEnabled(c.Request.Context(), name, *req.Enabled); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Schedule not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	schedule, err := h.repos.JobSchedule.GetByName(c.Request.Context(), name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"schedule": schedule})
+}
+
+// loadJob loads the job named in the path, responding 404 unless it exists and
+// the caller queued it or is an admin
+func (h *JobHandler) loadJob(c *gin.Context) (*models.Job, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid job ID"})
+		return nil, false
+	}
+	job, err := h.repos.Job.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if job == nil || (c.GetString("role") != "admin" &&
+		(job.CreatedBy == nil || job.CreatedBy.String() != c.GetString("user_id"))) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return nil, false
+	}
+	return job, true
+}
+
+// wantsAsync reports whether the client asked to run the request as a background job
+func wantsAsync(c *gin.Context) bool {
+	return c.Query("async") == "true"
+}
+
+// enqueueJob queues a job for the caller and responds 202 with it; clients
+// poll GET /api/jobs/:id for progress
+func enqueueJob(c *gin.Context, runner *jobs.Runner, jobType string, payload interface{}, input *models.JobFile) {
+	opts := jobs.EnqueueOptions{Payload: payload, Input: input}
+	if userID, err := uuid.Parse(c.GetString("user_id")); err == nil {
+		opts.CreatedBy = &userID
+	}
+	job, err := runner.Enqueue(c.Request.Context(), jobType, opts)
+	if err != nil {
+		if errors.Is(err, jobs.ErrUnknownJobType) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"job": job})
+}
+
+// uploadedJobFile reads an uploaded form file so it can be stored with a job
+func uploadedJobFile(file *multipart.FileHeader) (*models.JobFile, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+	data, err := io.ReadAll(src)
+	if err != nil {
+		return nil, err
+	}
+	return &models.JobFile{Name: file.Filename, ContentType: file.Header.Get("Content-Type"), Data: data}, nil
+}
+
+// responseJob adapts work that answers like an HTTP handler, with a status and
+// a body, into a job handler
+func responseJob(run func(ctx context.Context, job *models.Job) (int, gin.H)) jobs.Handler {
+	return func(ctx context.Context, job *models.Job, progress *jobs.Progress) (*jobs.Result, error) {
+		return responseResult(run(ctx, job))
+	}
+}
+
+// importJob adapts an Excel import into a job handler reading the uploaded file stored with the job
+func importJob(runner *jobs.Runner, run func(ctx context.Context, job *models.Job, fileData []byte) (int, gin.H)) jobs.Handler {
+	return func(ctx context.Context, job *models.Job, progress *jobs.Progress) (*jobs.Result, error) {
+		input, err := runner.Input(ctx, job)
+		if err != nil {
+			return nil, err
+		}
+		progress.Report(0, fmt.Sprintf("Importing %s", input.Name))
+		return responseResult(run(ctx, job, input.Data))
+	}
+}
+
+// responseResult turns a response body into the job result. Client errors
+// fail the job without retrying.
+func responseResult(status int, body gin.H) (*jobs.Result, error) {
+	if status < http.StatusBadRequest {
+		return &jobs.Result{Data: body}, nil
+	}
+	message := fmt.Sprintf("request failed with status %d", status)
+	if errMsg, ok := body["error"].(string); ok {
+		message = errMsg
+	}
+	if status >= http.StatusInternalServerError {
+		return nil, errors.New(message)
+	}
+	return nil, jobs.Permanent(errors.New(message))
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+
+	"vsq-oper-manpower/backend/internal/domain/events"
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+	"vsq-oper-manpower/backend/pkg/lru"
+	"vsq-oper-manpower/backend/pkg/metrics"
+)
+
+// registerDBMetrics exposes the connection pool statistics of db
+func registerDBMetrics(registry *metrics.Registry, db *sql.DB) {
+	registry.NewGaugeFunc("db_connections", "Open database connections by state", []string{"state"},
+		func(_ context.Context, emit metrics.Emit) {
+			stats := db.Stats()
+			emit(float64(stats.InUse), "in_use")
+			emit(float64(stats.Idle), "idle")
+		})
+	registry.NewGaugeFunc("db_max_open_connections", "Maximum open database connections; 0 is unlimited", nil,
+		func(_ context.Context, emit metrics.Emit) {
+			emit(float64(db.Stats().MaxOpenConnections))
+		})
+	registry.NewCounterFunc("db_wait_total", "Connections waited for because the pool was exhausted", nil,
+		func(_ context.Context, emit metrics.Emit) {
+			emit(float64(db.Stats().WaitCount))
+		})
+	registry.NewCounterFunc("db_wait_seconds_total", "Time spent waiting for a pooled connection", nil,
+		func(_ context.Context, emit metrics.Emit) {
+			emit(db.Stats().WaitDuration.Seconds())
+		})
+}
+
+// registerCacheMetrics exposes the counters of the named in-process caches;
+// hit rate is rate(cache_requests_total{result="hit"}) over all requests
+func registerCacheMetrics(registry *metrics.Registry, caches map[string]func() lru.Stats) {
+	each := func(emit func(name string, stats lru.Stats)) {
+		for name, stats := range caches {
+			emit(name, stats())
+		}
+	}
+	registry.NewGaugeFunc("cache_entries", "Entries held by each cache", []string{"cache"},
+		func(_ context.Context, emit metrics.Emit) {
+			each(func(name string, stats lru.Stats) {
+				emit(float64(stats.Size), name)
+			})
+		})
+	registry.NewCounterFunc("cache_requests_total", "Cache lookups by result", []string{"cache", "result"},
+		func(_ context.Context, emit metrics.Emit) {
+			each(func(name string, stats lru.Stats) {
+				emit(float64(stats.Hits), name, "hit")
+				emit(float64(stats.Misses), name, "miss")
+			})
+		})
+	registry.NewCounterFunc("cache_removals_total", "Cache entries removed by reason", []string{"cache", "reason"},
+		func(_ context.Context, emit metrics.Emit) {
+			each(func(name string, stats lru.Stats) {
+				emit(float64(stats.Evictions), name, "evicted")
+				emit(float64(stats.Expirations), name, "expired")
+				emit(float64(stats.Invalidations), name, "invalidated")
+			})
+		})
+}
+
+// registerJobMetrics exposes how many background jobs are in each status; the
+// queue depth is jobs{status="queued"}
+func registerJobMetrics(registry *metrics.Registry, jobs interfaces.JobRepository) {
+	statuses := []models.JobStatus{
+		models.JobStatusQueued, models.JobStatusRunning, models.JobStatusSucceeded,
+		models.JobStatusFailed, models.JobStatusCancelled,
+	}
+	registry.NewGaugeFunc("jobs", "Background jobs by status", []string{"status"},
+		func(ctx context.Context, emit metrics.Emit) {
+			counts, err := jobs.CountByStatus(ctx)
+			if err != nil {
+				slog.WarnContext(ctx, "Failed to count jobs for metrics", "error", err)
+				return
+			}
+			for _, status := range statuses {
+				emit(float64(counts[status]), string(status))
+			}
+		})
+}
+
+// registerEventMetrics exposes published change events and live stream clients
+func registerEventMetrics(registry *metrics.Registry, bus *events.Bus, stream *events.Stream) {
+	registry.NewCounterFunc("events_published_total", "Change events published by kind", []string{"kind"},
+		func(_ context.Context, emit metrics.Emit) {
+			for kind, n := range bus.Published() {
+				emit(float64(n), string(kind))
+			}
+		})
+	registry.NewGaugeFunc("event_stream_clients", "Clients connected to the change event stream", nil,
+		func(_ context.Context, emit metrics.Emit) {
+			emit(float64(stream.Subscribers()))
+		})
+}
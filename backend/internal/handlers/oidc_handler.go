@@ -0,0 +1,375 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"vsq-oper-manpower/backend/internal/config"
+	"vsq-oper-manpower/backend/internal/domain/models"
+	"vsq-oper-manpower/backend/internal/repositories/postgres"
+	"vsq-oper-manpower/backend/pkg/oidc"
+)
+
+// OIDCHandler implements single sign-on via OpenID Connect (authorization code + PKCE)
+// alongside the password login in AuthHandler
+type OIDCHandler struct {
+	repos  *postgres.Repositories
+	cfg    config.OIDCConfig
+	client *oidc.Client
+}
+
+func NewOIDCHandler(repos *postgres.Repositories, cfg *config.Config) *OIDCHandler {
+	return &OIDCHandler{repos: repos, cfg: cfg.OIDC, client: oidc.NewClient(oidc.Options{
+		Enabled:      cfg.OIDC.Enabled,
+		IssuerURL:    cfg.OIDC.IssuerURL,
+		ClientID:     cfg.OIDC.ClientID,
+		ClientSecret: cfg.OIDC.ClientSecret,
+		RedirectURL:  cfg.OIDC.RedirectURL,
+		Scopes:       cfg.OIDC.Scopes,
+		GroupsClaim:  cfg.OIDC.GroupsClaim,
+		BranchClaim:  cfg.OIDC.BranchClaim,
+	})}
+}
+
+// Config tells the frontend whether to show the SSO button
+func (h *OIDCHandler) Config(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"enabled": h.client.Enabled()})
+}
+
+// Login starts the authorization code flow and redirects the browser to the IdP.
+// An optional "redirect" query parameter (a frontend path) is honoured after login.
+func (h *OIDCHandler) Login(c *gin.Context) {
+	if !h.client.Enabled() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not enabled"})
+		return
+	}
+
+	state, err := oidc.NewState()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return
+	}
+	nonce, err := oidc.NewState()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start login"})
+		return
+	}
+	verifier := oidc.NewVerifier()
+
+	authURL, err := h.client.AuthCodeURL(c.Request.Context(), state, nonce, verifier)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "OIDC: failed to build authorization URL", "error", err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Identity provider is unavailable"})
+		return
+	}
+
+	session := sessions.Default(c)
+	session.Set("oidc_state", state)
+	session.Set("oidc_nonce", nonce)
+	session.Set("oidc_verifier", verifier)
+	session.Set("oidc_redirect", safeRedirectPath(c.Query("redirect")))
+	if err := session.Save(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save session"})
+		return
+	}
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// Callback completes the flow: verifies state, exchanges the code, maps claims to a local user and starts a session
+func (h *OIDCHandler) Callback(c *gin.Context) {
+	session := sessions.Default(c)
+	expectedState, _ := session.Get("oidc_state").(string)
+	nonce, _ := session.Get("oidc_nonce").(string)
+	verifier, _ := session.Get("oidc_verifier").(string)
+	redirectPath, _ := session.Get("oidc_redirect").(string)
+	session.Delete("oidc_state")
+	session.Delete("oidc_nonce")
+	session.Delete("oidc_verifier")
+	session.Delete("oidc_redirect")
+
+	if idpErr := c.Query("error"); idpErr != "" {
+		h.failLogin(c, "idp_error", fmt.Errorf("identity provider returned %s: %s", idpErr, c.Query("error_description")))
+		return
+	}
+	if expectedState == "" || c.Query("state") != expectedState {
+		h.failLogin(c, "invalid_state", fmt.Errorf("state mismatch"))
+		return
+	}
+
+	identity, err := h.client.Exchange(c.Request.Context(), c.Query("code"), verifier, nonce)
+	if err != nil {
+		h.failLogin(c, "exchange_failed", err)
+		return
+	}
+
+	user, role, err := h.resolveUser(c.Request.Context(), identity)
+	if err != nil {
+		h.failLogin(c, "user_not_allowed", err)
+		return
+	}
+
+	if err := saveUserSession(c, user, role.Name); err != nil {
+		h.failLogin(c, "session_failed", err)
+		return
+	}
+
+	slog.InfoContext(c.Request.Context(), "OIDC: user logged in",
+		"username", user.Username, "issuer", identity.Issuer, "subject", identity.Subject, "role", role.Name)
+	c.Redirect(http.StatusFound, strings.TrimRight(h.cfg.FrontendURL, "/")+redirectPath)
+}
+
+// resolveUser finds the local user for an external identity, linking by verified email on first
+// login when an admin approved it or auto-provisioning when enabled, then applies the role and
+// branch mapped from claims
+func (h *OIDCHandler) resolveUser(ctx context.Context, identity *oidc.Identity) (*models.User, *models.Role, error) {
+	mappedRole, hasMappedRole := h.cfg.RoleForGroups(identity.Groups)
+
+	var user *models.User
+	link, err := h.repos.UserIdentity.GetByIssuerAndSubject(ctx, identity.Issuer, identity.Subject)
+	if err != nil {
+		return nil, nil, err
+	}
+	if link != nil {
+		if user, err = h.repos.User.GetByID(ctx, link.UserID); err != nil {
+			return nil, nil, err
+		}
+	}
+	if user == nil && identity.Email != "" && identity.EmailVerified {
+		if user, err = h.approvedUser(ctx, identity.Email); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if user == nil {
+		if !h.cfg.AutoProvision {
+			return nil, nil, fmt.Errorf("no local user for subject %s and auto-provisioning is disabled", identity.Subject)
+		}
+		roleName := mappedRole
+		if !hasMappedRole {
+			roleName = h.cfg.DefaultRole
+		}
+		if roleName == "" {
+			return nil, nil, fmt.Errorf("groups %v map to no role and no default role is configured", identity.Groups)
+		}
+		if user, err = h.provisionUser(ctx, identity, roleName); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Keep role and branch in sync with the IdP when it provides them
+	changed := false
+	if hasMappedRole {
+		role, err := h.repos.Role.GetByName(ctx, mappedRole)
+		if err != nil {
+			return nil, nil, err
+		}
+		if role == nil {
+			return nil, nil, fmt.Errorf("mapped role %q does not exist", mappedRole)
+		}
+		if user.RoleID != role.ID {
+			user.RoleID = role.ID
+			changed = true
+		}
+	}
+	if identity.BranchCode != "" {
+		branch, err := h.findBranchByCode(ctx, identity.BranchCode)
+		if err != nil {
+			return nil, nil, err
+		}
+		if branch != nil && (user.BranchID == nil || *user.BranchID != branch.ID) {
+			user.BranchID = &branch.ID
+			changed = true
+		}
+	}
+	if changed {
+		if err := h.repos.User.Update(ctx, user); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	now := time.Now()
+	if link == nil {
+		link = &models.UserIdentity{
+			UserID:      user.ID,
+			Issuer:      identity.Issuer,
+			Subject:     identity.Subject,
+			Email:       identity.Email,
+			LastLoginAt: &now,
+		}
+		if err := h.repos.UserIdentity.Create(ctx, link); err != nil {
+			return nil, nil, err
+		}
+	} else if err := h.repos.UserIdentity.TouchLastLogin(ctx, link.ID, now); err != nil {
+		return nil, nil, err
+	}
+
+	role, err := h.repos.Role.GetByID(ctx, user.RoleID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if role == nil {
+		return nil, nil, fmt.Errorf("role %s not found", user.RoleID)
+	}
+	return user, role, nil
+}
+
+// approvedUser returns the local user with email when an admin approved linking it to an SSO
+// identity, using the approval up. Every local user has a password (SSO-provisioned ones a
+// random one) or is a service account's backing user, so none is linked on email alone.
+func (h *OIDCHandler) approvedUser(ctx context.Context, email string) (*models.User, error) {
+	user, err := h.repos.User.GetByEmail(ctx, email)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	approved, err := h.repos.UserIdentity.ConsumeLinkApproval(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !approved {
+		return nil, fmt.Errorf("local user %s has the email %s but linking it needs an admin's approval", user.Username, email)
+	}
+	return user, nil
+}
+
+// ApproveLink lets the next SSO login with the user's verified email link to the user.
+// Service accounts' backing users sign in with API tokens only.
+func (h *OIDCHandler) ApproveLink(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		return
+	}
+
+	user, err := h.repos.User.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if user == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	account, err := h.repos.ServiceAccount.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if account != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Service accounts cannot sign in through single sign-on"})
+		return
+	}
+
+	approval := &models.UserIdentityLinkApproval{UserID: id, ApprovedBy: currentUserID(c)}
+	if err := h.repos.UserIdentity.ApproveLink(c.Request.Context(), approval); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"approval": approval})
+}
+
+// provisionUser creates a local user for an SSO identity. The random password hash
+// means the account can only sign in through the IdP until an admin sets a password.
+func (h *OIDCHandler) provisionUser(ctx context.Context, identity *oidc.Identity, roleName string) (*models.User, error) {
+	role, err := h.repos.Role.GetByName(ctx, roleName)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, fmt.Errorf("role %q does not exist", roleName)
+	}
+
+	username, err := h.uniqueUsername(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(hex.EncodeToString(secret)), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	// An unverified email is not the caller's to claim
+	email := identity.Email
+	if email == "" || !identity.EmailVerified {
+		email = username + "@sso.local"
+	}
+
+	user := &models.User{
+		ID:           uuid.New(),
+		Username:     username,
+		Email:        email,
+		PasswordHash: string(passwordHash),
+		RoleID:       role.ID,
+	}
+	if err := h.repos.User.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (h *OIDCHandler) uniqueUsername(ctx context.Context, identity *oidc.Identity) (string, error) {
+	base := identity.Username
+	if base == "" && identity.Email != "" {
+		base = strings.SplitN(identity.Email, "@", 2)[0]
+	}
+	if base == "" {
+		base = "sso-" + identity.Subject
+	}
+
+	candidate := base
+	for i := 2; i < 100; i++ {
+		existing, err := h.repos.User.GetByUsername(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s%d", base, i)
+	}
+	return "", fmt.Errorf("could not find a free username for %s", base)
+}
+
+func (h *OIDCHandler) findBranchByCode(ctx context.Context, code string) (*models.Branch, error) {
+	branches, err := h.repos.Branch.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, branch := range branches {
+		if strings.EqualFold(branch.Code, code) {
+			return branch, nil
+		}
+	}
+	return nil, nil
+}
+
+// failLogin logs the cause and sends the browser back to the frontend login page with an error code
+func (h *OIDCHandler) failLogin(c *gin.Context, code string, err error) {
+	slog.WarnContext(c.Request.Context(), "OIDC: login failed", "code", code, "error", err)
+	_ = sessions.Default(c).Save()
+	c.Redirect(http.StatusFound, strings.TrimRight(h.cfg.FrontendURL, "/")+"/login?sso_error="+url.QueryEscape(code))
+}
+
+// safeRedirectPath only allows local paths so the login flow cannot be used as an open redirect
+func safeRedirectPath(path string) string {
+	if !strings.HasPrefix(path, "/") || strings.HasPrefix(path, "//") || strings.Contains(path, "\\") {
+		return "/"
+	}
+	return path
+}
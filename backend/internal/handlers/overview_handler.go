@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -40,36 +41,9 @@ func (h *OverviewHandler) GetDayOverview(c *gin.Context) {
 	}
 
 	// Parse branch IDs if provided
-	var branchIDs []uuid.UUID
-	branchIDsStr := c.Query("branch_ids")
-	if branchIDsStr != "" {
-		// Split by comma and parse each UUID
-		parts := []string{}
-		current := ""
-		for _, char := range branchIDsStr {
-			if char == ',' {
-				if current != "" {
-					parts = append(parts, current)
-					current = ""
-				}
-			} else if char != ' ' {
-				current += string(char)
-			}
-		}
-		if current != "" {
-			parts = append(parts, current)
-		}
-		
-		branchIDs = make([]uuid.UUID, 0, len(parts))
-		for _, part := range parts {
-			id, err := uuid.Parse(part)
-			if err == nil {
-				branchIDs = append(branchIDs, id)
-			}
-		}
-	}
+	branchIDs := parseBranchIDs(c.Query("branch_ids"))
 
-	overview, err := h.overviewGenerator.GenerateDayOverview(date, branchIDs)
+	overview, err := h.overviewGenerator.GenerateDayOverview(c.Request.Context(), date, branchIDs)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -92,25 +66,9 @@ func (h *OverviewHandler) GetMonthlyOverview(c *gin.Context) {
 		return
 	}
 
-	yearStr := c.Query("year")
-	monthStr := c.Query("month")
-
-	var year, month int
-	if yearStr == "" || monthStr == "" {
-		now := time.Now()
-		year = now.Year()
-		month = int(now.Month())
-	} else {
-		year, err = strconv.Atoi(yearStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
-			return
-		}
-		month, err = strconv.Atoi(monthStr)
-		if err != nil || month < 1 || month > 12 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month"})
-			return
-		}
+	year, month, ok := parseYearMonth(c)
+	if !ok {
+		return
 	}
 
 	// For branch managers, enforce their branch
@@ -127,7 +85,7 @@ func (h *OverviewHandler) GetMonthlyOverview(c *gin.Context) {
 		}
 	}
 
-	overview, err := h.overviewGenerator.GenerateMonthlyOverview(branchID, year, month)
+	overview, err := h.overviewGenerator.GenerateMonthlyOverview(c.Request.Context(), branchID, year, month)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -135,3 +93,102 @@ func (h *OverviewHandler) GetMonthlyOverview(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"overview": overview})
 }
+
+// GetMonthlyMatrix returns the monthly overview of several branches at once (branches x days)
+// Query params:
+//   - year, month: defaults to the current month
+//   - branch_ids: comma-separated list of branch UUIDs (optional, defaults to all branches)
+func (h *OverviewHandler) GetMonthlyMatrix(c *gin.Context) {
+	year, month, ok := parseYearMonth(c)
+	if !ok {
+		return
+	}
+
+	matrix, err := h.overviewGenerator.GenerateMonthlyMatrix(c.Request.Context(), year, month, parseBranchIDs(c.Query("branch_ids")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"matrix": matrix})
+}
+
+// maxBranchDayRange bounds the days resolved by GetBranchDayStatuses
+const maxBranchDayRange = 92
+
+// GetBranchDayStatuses returns whether each branch is closed, or open with or
+// without doctors, on each day, with any mismatch between its doctor-on/off
+// designation and the roster
+// Query params:
+//   - start_date, end_date: YYYY-MM-DD, inclusive (end_date defaults to start_date)
+//   - branch_ids: comma-separated list of branch UUIDs (optional, defaults to all branches)
+//   - mismatches: "true" to return only the days with a mismatch
+func (h *OverviewHandler) GetBranchDayStatuses(c *gin.Context) {
+	startDate, err := time.Parse("2006-01-02", c.Query("start_date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start_date is required in YYYY-MM-DD format"})
+		return
+	}
+	endDate := startDate
+	if value := c.Query("end_date"); value != "" {
+		if endDate, err = time.Parse("2006-01-02", value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format"})
+			return
+		}
+	}
+	if endDate.Before(startDate) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_date must be on or after start_date"})
+		return
+	}
+	if endDate.After(startDate.AddDate(0, 0, maxBranchDayRange-1)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Date range cannot be longer than " + strconv.Itoa(maxBranchDayRange) + " days"})
+		return
+	}
+
+	statuses, err := h.overviewGenerator.GenerateBranchDayStatuses(c.Request.Context(), startDate, endDate,
+		parseBranchIDs(c.Query("branch_ids")), c.Query("mismatches") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"statuses": statuses})
+}
+
+// parseBranchIDs parses a comma-separated list of branch UUIDs, skipping invalid entries
+func parseBranchIDs(value string) []uuid.UUID {
+	var branchIDs []uuid.UUID
+	for _, part := range strings.Split(value, ",") {
+		part = strings.ReplaceAll(part, " ", "")
+		if part == "" {
+			continue
+		}
+		if id, err := uuid.Parse(part); err == nil {
+			branchIDs = append(branchIDs, id)
+		}
+	}
+	return branchIDs
+}
+
+// parseYearMonth reads the year and month query params, defaulting to the current month.
+// It writes a 400 response and returns false when they are invalid.
+func parseYearMonth(c *gin.Context) (int, int, bool) {
+	yearStr := c.Query("year")
+	monthStr := c.Query("month")
+	if yearStr == "" || monthStr == "" {
+		now := time.Now()
+		return now.Year(), int(now.Month()), true
+	}
+
+	year, err := strconv.Atoi(yearStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year"})
+		return 0, 0, false
+	}
+	month, err := strconv.Atoi(monthStr)
+	if err != nil || month < 1 || month > 12 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month"})
+		return 0, 0, false
+	}
+	return year, month, true
+}
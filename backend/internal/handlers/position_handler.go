@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"net/http"
@@ -22,7 +23,7 @@ func NewPositionHandler(repos *postgres.Repositories, db *sql.DB) *PositionHandl
 }
 
 func (h *PositionHandler) List(c *gin.Context) {
-	positions, err := h.repos.Position.List()
+	positions, err := h.repos.Position.List(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -39,7 +40,7 @@ func (h *PositionHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	position, err := h.repos.Position.GetByID(id)
+	position, err := h.repos.Position.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -70,7 +71,7 @@ func (h *PositionHandler) Update(c *gin.Context) {
 	}
 
 	// Check if position exists
-	existingPosition, err := h.repos.Position.GetByID(id)
+	existingPosition, err := h.repos.Position.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -97,7 +98,7 @@ func (h *PositionHandler) Update(c *gin.Context) {
 		CreatedAt:         existingPosition.CreatedAt,
 	}
 
-	if err := h.repos.Position.Update(position); err != nil {
+	if err := h.repos.Position.Update(c.Request.Context(), position); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -114,7 +115,7 @@ func (h *PositionHandler) Delete(c *gin.Context) {
 	}
 
 	// Check if position exists
-	existingPosition, err := h.repos.Position.GetByID(id)
+	existingPosition, err := h.repos.Position.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -125,7 +126,7 @@ func (h *PositionHandler) Delete(c *gin.Context) {
 	}
 
 	// Automatically delete all associated position quotas
-	quotas, err := h.repos.PositionQuota.List(interfaces.PositionQuotaFilters{
+	quotas, err := h.repos.PositionQuota.List(c.Request.Context(), interfaces.PositionQuotaFilters{
 		PositionID: &id,
 	})
 	if err != nil {
@@ -133,7 +134,7 @@ func (h *PositionHandler) Delete(c *gin.Context) {
 		return
 	}
 	for _, quota := range quotas {
-		if err := h.repos.PositionQuota.Delete(quota.ID); err != nil {
+		if err := h.repos.PositionQuota.Delete(c.Request.Context(), quota.ID); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to delete position quota: %v", err)})
 			return
 		}
@@ -141,25 +142,7 @@ func (h *PositionHandler) Delete(c *gin.Context) {
 
 	// Check if position has any other associations (staff, rules, suggestions, or scenario requirements)
 	// Note: We exclude quotas from this check since we've already deleted them
-	staffCountQuery := `SELECT COUNT(*) FROM staff WHERE position_id = $1`
-	var staffCount int
-	err = h.db.QueryRow(staffCountQuery, id).Scan(&staffCount)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	ruleCountQuery := `SELECT COUNT(*) FROM staff_allocation_rules WHERE position_id = $1`
-	var ruleCount int
-	err = h.db.QueryRow(ruleCountQuery, id).Scan(&ruleCount)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	scenarioCountQuery := `SELECT COUNT(*) FROM scenario_position_requirements WHERE position_id = $1`
-	var scenarioCount int
-	err = h.db.QueryRow(scenarioCountQuery, id).Scan(&scenarioCount)
+	staffCount, ruleCount, scenarioCount, err := h.countAssociations(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -174,7 +157,7 @@ func (h *PositionHandler) Delete(c *gin.Context) {
 	}
 
 	// Delete the position
-	if err := h.repos.Position.Delete(id); err != nil {
+	if err := h.repos.Position.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -210,7 +193,7 @@ func (h *PositionHandler) GetAssociations(c *gin.Context) {
 	}
 
 	// Check if position exists
-	existingPosition, err := h.repos.Position.GetByID(id)
+	existingPosition, err := h.repos.Position.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -223,7 +206,7 @@ func (h *PositionHandler) GetAssociations(c *gin.Context) {
 	associations := &PositionAssociations{}
 
 	// Get all quotas for this position
-	allQuotas, err := h.repos.PositionQuota.List(interfaces.PositionQuotaFilters{
+	allQuotas, err := h.repos.PositionQuota.List(c.Request.Context(), interfaces.PositionQuotaFilters{
 		PositionID: &id,
 	})
 	if err != nil {
@@ -235,7 +218,7 @@ func (h *PositionHandler) GetAssociations(c *gin.Context) {
 	// Get quota details with branch names
 	if associations.QuotaCount > 0 {
 		for _, quota := range allQuotas {
-			branch, err := h.repos.Branch.GetByID(quota.BranchID)
+			branch, err := h.repos.Branch.GetByID(c.Request.Context(), quota.BranchID)
 			if err != nil {
 				// Continue even if branch lookup fails
 				continue
@@ -254,33 +237,62 @@ func (h *PositionHandler) GetAssociations(c *gin.Context) {
 		}
 	}
 
-	// Get staff count
-	staffCountQuery := `SELECT COUNT(*) FROM staff WHERE position_id = $1`
-	err = h.db.QueryRow(staffCountQuery, id).Scan(&associations.StaffCount)
+	// Get staff, allocation rule and scenario requirement counts
+	associations.StaffCount, associations.AllocationRuleCount, associations.ScenarioRequirementCount, err =
+		h.countAssociations(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Get allocation rule count
-	ruleCountQuery := `SELECT COUNT(*) FROM staff_allocation_rules WHERE position_id = $1`
-	err = h.db.QueryRow(ruleCountQuery, id).Scan(&associations.AllocationRuleCount)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	associations.TotalCount = associations.StaffCount + associations.QuotaCount + 
+		associations.AllocationRuleCount + associations.ScenarioRequirementCount
+
+	c.JSON(http.StatusOK, gin.H{"associations": associations})
+}
+
+
+// countAssociations counts the staff, allocation rules and scenario requirements
+// that reference a position. Without a database connection (STORAGE=memory) it
+// counts through the repositories instead.
+func (h *PositionHandler) countAssociations(ctx context.Context, id uuid.UUID) (staffCount, ruleCount, scenarioCount int, err error) {
+	if h.db != nil {
+		if err = h.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM staff WHERE position_id = $1`, id).Scan(&staffCount); err != nil {
+			return
+		}
+		if err = h.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM staff_allocation_rules WHERE position_id = $1`, id).Scan(&ruleCount); err != nil {
+			return
+		}
+		err = h.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM scenario_position_requirements WHERE position_id = $1`, id).Scan(&scenarioCount)
 		return
 	}
 
-	// Get scenario requirement count
-	scenarioCountQuery := `SELECT COUNT(*) FROM scenario_position_requirements WHERE position_id = $1`
-	err = h.db.QueryRow(scenarioCountQuery, id).Scan(&associations.ScenarioRequirementCount)
+	staffList, err := h.repos.Staff.List(ctx, interfaces.StaffFilters{PositionID: &id})
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	staffCount = len(staffList)
 
-	associations.TotalCount = associations.StaffCount + associations.QuotaCount + 
-		associations.AllocationRuleCount + associations.ScenarioRequirementCount
+	rule, err := h.repos.AllocationRule.GetByPositionID(ctx, id)
+	if err != nil {
+		return
+	}
+	if rule != nil {
+		ruleCount = 1
+	}
 
-	c.JSON(http.StatusOK, gin.H{"associations": associations})
+	scenarios, err := h.repos.StaffRequirementScenario.List(ctx, true)
+	if err != nil {
+		return
+	}
+	for _, scenario := range scenarios {
+		requirement, err := h.repos.ScenarioPositionRequirement.GetByScenarioAndPosition(ctx, scenario.ID, id)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		if requirement != nil {
+			scenarioCount++
+		}
+	}
+	return staffCount, ruleCount, scenarioCount, nil
 }
-
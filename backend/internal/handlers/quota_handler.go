@@ -1,8 +1,8 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
-	"io"
 	"net/http"
 	"time"
 
@@ -12,6 +12,7 @@ import (
 	"vsq-oper-manpower/backend/internal/domain/models"
 	"vsq-oper-manpower/backend/internal/repositories/postgres"
 	"vsq-oper-manpower/backend/internal/usecases/allocation"
+	"vsq-oper-manpower/backend/internal/usecases/jobs"
 	"vsq-oper-manpower/backend/pkg/excel"
 )
 
@@ -19,6 +20,7 @@ type QuotaHandler struct {
 	repos           *postgres.Repositories
 	quotaCalculator *allocation.QuotaCalculator
 	excelImporter   *excel.ExcelImporter
+	jobs            *jobs.Runner
 }
 
 func NewQuotaHandler(repos *postgres.Repositories, quotaCalculator *allocation.QuotaCalculator) *QuotaHandler {
@@ -65,7 +67,7 @@ func (h *QuotaHandler) CreateQuota(c *gin.Context) {
 		CreatedBy:       userID,
 	}
 
-	if err := h.repos.PositionQuota.Create(quota); err != nil {
+	if err := h.repos.PositionQuota.Create(c.Request.Context(), quota); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -86,7 +88,7 @@ func (h *QuotaHandler) GetQuotas(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid branch_id"})
 			return
 		}
-		quotas, err = h.repos.PositionQuota.GetByBranchID(branchID)
+		quotas, err = h.repos.PositionQuota.GetByBranchID(c.Request.Context(), branchID)
 	} else {
 		filters := interfaces.PositionQuotaFilters{}
 		if positionIDStr != "" {
@@ -97,7 +99,7 @@ func (h *QuotaHandler) GetQuotas(c *gin.Context) {
 		}
 		isActive := true
 		filters.IsActive = &isActive
-		quotas, err = h.repos.PositionQuota.List(filters)
+		quotas, err = h.repos.PositionQuota.List(c.Request.Context(), filters)
 	}
 
 	if err != nil {
@@ -127,7 +129,7 @@ func (h *QuotaHandler) UpdateQuota(c *gin.Context) {
 		return
 	}
 
-	quota, err := h.repos.PositionQuota.GetByID(id)
+	quota, err := h.repos.PositionQuota.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -147,7 +149,7 @@ func (h *QuotaHandler) UpdateQuota(c *gin.Context) {
 		quota.IsActive = *req.IsActive
 	}
 
-	if err := h.repos.PositionQuota.Update(quota); err != nil {
+	if err := h.repos.PositionQuota.Update(c.Request.Context(), quota); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -163,7 +165,7 @@ func (h *QuotaHandler) DeleteQuota(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.PositionQuota.Delete(id); err != nil {
+	if err := h.repos.PositionQuota.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -199,7 +201,7 @@ func (h *QuotaHandler) GetBranchQuotaStatus(c *gin.Context) {
 		return
 	}
 
-	status, err := h.quotaCalculator.CalculateBranchQuotaStatus(branchID, date)
+	status, err := h.quotaCalculator.CalculateBranchQuotaStatus(c.Request.Context(), branchID, date)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -208,6 +210,8 @@ func (h *QuotaHandler) GetBranchQuotaStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": status})
 }
 
+// Import creates or updates position quotas from an Excel file. With
+// ?async=true the import runs as a background job and the response is the queued job.
 func (h *QuotaHandler) Import(c *gin.Context) {
 	// Get the uploaded file
 	file, err := c.FormFile("file")
@@ -216,16 +220,7 @@ func (h *QuotaHandler) Import(c *gin.Context) {
 		return
 	}
 
-	// Open the uploaded file
-	src, err := file.Open()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open file"})
-		return
-	}
-	defer src.Close()
-
-	// Read file content
-	fileData, err := io.ReadAll(src)
+	input, err := uploadedJobFile(file)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
 		return
@@ -239,13 +234,32 @@ func (h *QuotaHandler) Import(c *gin.Context) {
 		return
 	}
 
+	if wantsAsync(c) {
+		enqueueJob(c, h.jobs, JobTypeQuotaImport, nil, input)
+		return
+	}
+	c.JSON(h.importQuotas(c.Request.Context(), input.Data, userID))
+}
+
+// registerJobs lets the quota import run in the background
+func (h *QuotaHandler) registerJobs(runner *jobs.Runner) {
+	h.jobs = runner
+	runner.Register(JobTypeQuotaImport, importJob(runner, func(ctx context.Context, job *models.Job, fileData []byte) (int, gin.H) {
+		if job.CreatedBy == nil {
+			return http.StatusBadRequest, gin.H{"error": "Quota import job has no creator"}
+		}
+		return h.importQuotas(ctx, fileData, *job.CreatedBy)
+	}))
+}
+
+// importQuotas saves the position quotas in an Excel file and returns the response status and body
+func (h *QuotaHandler) importQuotas(ctx context.Context, fileData []byte, userID uuid.UUID) (int, gin.H) {
 	// Import position quotas from Excel
-	result, importErr := h.excelImporter.ImportPositionQuotas(fileData, userID)
+	result, importErr := h.excelImporter.ImportPositionQuotas(ctx, fileData, userID)
 	if importErr != nil {
 		// If no records were imported, return error immediately
 		if result == nil || (result.Created == 0 && result.Updated == 0) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": importErr.Error()})
-			return
+			return http.StatusBadRequest, gin.H{"error": importErr.Error()}
 		}
 		// If there are valid records but also import errors, continue with partial success
 	}
@@ -265,10 +279,9 @@ func (h *QuotaHandler) Import(c *gin.Context) {
 
 	// If there were import errors but we still have a result, return partial success
 	if importErr != nil && (result.Created > 0 || result.Updated > 0) {
-		c.JSON(http.StatusOK, response)
-		return
+		return http.StatusOK, response
 	}
 
 	// Success
-	c.JSON(http.StatusOK, response)
+	return http.StatusOK, response
 }
@@ -19,7 +19,7 @@ func NewRevenueLevelTierHandler(repos *postgres.Repositories) *RevenueLevelTierH
 
 // List returns all revenue level tiers
 func (h *RevenueLevelTierHandler) List(c *gin.Context) {
-	tiers, err := h.repos.RevenueLevelTier.List()
+	tiers, err := h.repos.RevenueLevelTier.List(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -36,7 +36,7 @@ func (h *RevenueLevelTierHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	tier, err := h.repos.RevenueLevelTier.GetByID(id)
+	tier, err := h.repos.RevenueLevelTier.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -67,7 +67,7 @@ func (h *RevenueLevelTierHandler) Create(c *gin.Context) {
 		Description: req.Description,
 	}
 
-	if err := h.repos.RevenueLevelTier.Create(tier); err != nil {
+	if err := h.repos.RevenueLevelTier.Create(c.Request.Context(), tier); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -84,7 +84,7 @@ func (h *RevenueLevelTierHandler) Update(c *gin.Context) {
 		return
 	}
 
-	tier, err := h.repos.RevenueLevelTier.GetByID(id)
+	tier, err := h.repos.RevenueLevelTier.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -119,7 +119,7 @@ func (h *RevenueLevelTierHandler) Update(c *gin.Context) {
 		tier.Description = req.Description
 	}
 
-	if err := h.repos.RevenueLevelTier.Update(tier); err != nil {
+	if err := h.repos.RevenueLevelTier.Update(c.Request.Context(), tier); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -136,7 +136,7 @@ func (h *RevenueLevelTierHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.RevenueLevelTier.Delete(id); err != nil {
+	if err := h.repos.RevenueLevelTier.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -154,7 +154,7 @@ func (h *RevenueLevelTierHandler) GetTierForRevenue(c *gin.Context) {
 		return
 	}
 
-	tier, err := h.repos.RevenueLevelTier.GetTierForRevenue(req.Revenue)
+	tier, err := h.repos.RevenueLevelTier.GetTierForRevenue(c.Request.Context(), req.Revenue)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -20,13 +20,15 @@ type RotationHandler struct {
 	repos               *postgres.Repositories
 	cfg                 *config.Config
 	multiCriteriaFilter *allocation.MultiCriteriaFilter
+	pairingResolver     *allocation.PairingResolver
 }
 
-func NewRotationHandler(repos *postgres.Repositories, cfg *config.Config, multiCriteriaFilter *allocation.MultiCriteriaFilter) *RotationHandler {
+func NewRotationHandler(repos *postgres.Repositories, cfg *config.Config, multiCriteriaFilter *allocation.MultiCriteriaFilter, pairingResolver *allocation.PairingResolver) *RotationHandler {
 	return &RotationHandler{
 		repos:               repos,
 		cfg:                 cfg,
 		multiCriteriaFilter: multiCriteriaFilter,
+		pairingResolver:     pairingResolver,
 	}
 }
 
@@ -80,7 +82,7 @@ func (h *RotationHandler) GetAssignments(c *gin.Context) {
 		filters.CoverageArea = &coverageArea
 	}
 
-	assignments, err := h.repos.Rotation.GetAssignments(filters)
+	assignments, err := h.repos.Rotation.GetAssignments(c.Request.Context(), filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -108,7 +110,7 @@ func (h *RotationHandler) Assign(c *gin.Context) {
 	}
 
 	// Check schedule status - cannot assign branch if day is set to "off"
-	schedule, err := h.repos.RotationStaffSchedule.GetByRotationStaffIDAndDate(req.RotationStaffID, date)
+	schedule, err := h.repos.RotationStaffSchedule.GetByRotationStaffIDAndDate(c.Request.Context(), req.RotationStaffID, date)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to check schedule status: %v", err)})
 		return
@@ -118,6 +120,17 @@ func (h *RotationHandler) Assign(c *gin.Context) {
 		return
 	}
 
+	// A hard pairing keeps the staff member at their doctor's branch
+	pairing, err := h.pairingResolver.PairingFor(c.Request.Context(), req.RotationStaffID, req.BranchID, date)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to check doctor pairings: %v", err)})
+		return
+	}
+	if pairing != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": pairingConflict(pairing), "pairing": pairing})
+		return
+	}
+
 	userIDStr := c.MustGet("user_id").(string)
 	userID, err := uuid.Parse(userIDStr)
 	if err != nil {
@@ -136,7 +149,7 @@ func (h *RotationHandler) Assign(c *gin.Context) {
 		AssignedBy:      userID,
 	}
 
-	if err := h.repos.Rotation.Create(assignment); err != nil {
+	if err := h.repos.Rotation.Create(c.Request.Context(), assignment); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -152,7 +165,7 @@ func (h *RotationHandler) RemoveAssignment(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.Rotation.Delete(id); err != nil {
+	if err := h.repos.Rotation.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -160,6 +173,11 @@ func (h *RotationHandler) RemoveAssignment(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Assignment removed successfully"})
 }
 
+// pairingConflict explains why a hard-paired staff member cannot be assigned elsewhere
+func pairingConflict(pairing *models.DoctorPairing) string {
+	return fmt.Sprintf("staff is hard-paired with %s at branch %s on this date", pairing.DoctorName, pairing.BranchCode)
+}
+
 type SuggestionsRequest struct {
 	BranchID  string `json:"branch_id"`
 	StartDate string `json:"start_date"`
@@ -185,7 +203,7 @@ func (h *RotationHandler) GetEligibleStaff(c *gin.Context) {
 	}
 
 	// Get effective branches for this branch (rotation staff eligible for this branch)
-	effectiveBranches, err := h.repos.EffectiveBranch.GetByBranchID(branchID)
+	effectiveBranches, err := h.repos.EffectiveBranch.GetByBranchID(c.Request.Context(), branchID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -198,7 +216,7 @@ func (h *RotationHandler) GetEligibleStaff(c *gin.Context) {
 	}
 
 	// Get all rotation staff
-	allRotationStaff, err := h.repos.Staff.GetRotationStaff()
+	allRotationStaff, err := h.repos.Staff.GetRotationStaff(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -223,6 +241,54 @@ func (h *RotationHandler) GetEligibleStaff(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"eligible_staff": eligibleStaff})
 }
 
+// GetPairings lists the doctor-assistant pairings of the doctors rostered on a
+// date, with whether each paired staff member is met, reserved or unmet
+func (h *RotationHandler) GetPairings(c *gin.Context) {
+	date, err := time.Parse("2006-01-02", c.Query("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date is required in YYYY-MM-DD format"})
+		return
+	}
+
+	pairings, err := h.pairingResolver.Resolve(c.Request.Context(), parseBranchIDs(c.Query("branch_ids")), date)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"pairings": pairings})
+}
+
+// GetDoctorPreferenceChecks reports, for each doctor rostered at the branches
+// on a date, which of their preference rules the current staffing fails
+func (h *RotationHandler) GetDoctorPreferenceChecks(c *gin.Context) {
+	date, err := time.Parse("2006-01-02", c.Query("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date is required in YYYY-MM-DD format"})
+		return
+	}
+
+	branchIDs := parseBranchIDs(c.Query("branch_ids"))
+	if len(branchIDs) == 0 {
+		branches, err := h.repos.Branch.List(c.Request.Context())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		for _, branch := range branches {
+			branchIDs = append(branchIDs, branch.ID)
+		}
+	}
+
+	checks, err := h.multiCriteriaFilter.CheckDoctorPreferences(c.Request.Context(), branchIDs, date)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"checks": checks})
+}
+
 type BulkAssignRequest struct {
 	Assignments []struct {
 		RotationStaffID uuid.UUID `json:"rotation_staff_id" binding:"required"`
@@ -264,7 +330,7 @@ func (h *RotationHandler) BulkAssign(c *gin.Context) {
 			}
 
 			// Check schedule status - cannot assign branch if day is set to "off"
-			schedule, err := h.repos.RotationStaffSchedule.GetByRotationStaffIDAndDate(assignment.RotationStaffID, date)
+			schedule, err := h.repos.RotationStaffSchedule.GetByRotationStaffIDAndDate(c.Request.Context(), assignment.RotationStaffID, date)
 			if err != nil {
 				errors = append(errors, fmt.Sprintf("Failed to check schedule status for %s on %s: %v", assignment.RotationStaffID, dateStr, err))
 				continue
@@ -273,6 +339,15 @@ func (h *RotationHandler) BulkAssign(c *gin.Context) {
 				errors = append(errors, fmt.Sprintf("Cannot assign branch for %s on %s: schedule status is 'off'", assignment.RotationStaffID, dateStr))
 				continue
 			}
+			pairing, err := h.pairingResolver.PairingFor(c.Request.Context(), assignment.RotationStaffID, req.BranchID, date)
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("Failed to check doctor pairings for %s on %s: %v", assignment.RotationStaffID, dateStr, err))
+				continue
+			}
+			if pairing != nil {
+				errors = append(errors, fmt.Sprintf("Cannot assign %s on %s: %s", assignment.RotationStaffID, dateStr, pairingConflict(pairing)))
+				continue
+			}
 
 			assignmentModel := &models.RotationAssignment{
 				ID:              uuid.New(),
@@ -283,7 +358,7 @@ func (h *RotationHandler) BulkAssign(c *gin.Context) {
 				AssignedBy:      userID,
 			}
 
-			if err := h.repos.Rotation.Create(assignmentModel); err != nil {
+			if err := h.repos.Rotation.Create(c.Request.Context(), assignmentModel); err != nil {
 				// Check if it's a duplicate key error (already assigned) - ignore it
 				errStr := err.Error()
 				if !strings.Contains(errStr, "duplicate key") && !strings.Contains(errStr, "UNIQUE constraint") {
@@ -357,7 +432,7 @@ func (h *RotationHandler) SetSchedule(c *gin.Context) {
 		CreatedBy:       userID,
 	}
 
-	if err := h.repos.RotationStaffSchedule.Create(schedule); err != nil {
+	if err := h.repos.RotationStaffSchedule.Create(c.Request.Context(), schedule); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -381,7 +456,7 @@ func (h *RotationHandler) GetSchedules(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format"})
 			return
 		}
-		schedules, err = h.repos.RotationStaffSchedule.GetByDate(date)
+		schedules, err = h.repos.RotationStaffSchedule.GetByDate(c.Request.Context(), date)
 	} else if rotationStaffIDStr != "" && startDateStr != "" && endDateStr != "" {
 		// Get schedules for a specific rotation staff within a date range
 		rotationStaffID, err := uuid.Parse(rotationStaffIDStr)
@@ -399,7 +474,7 @@ func (h *RotationHandler) GetSchedules(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format"})
 			return
 		}
-		schedules, err = h.repos.RotationStaffSchedule.GetByRotationStaffID(rotationStaffID, startDate, endDate)
+		schedules, err = h.repos.RotationStaffSchedule.GetByRotationStaffID(c.Request.Context(), rotationStaffID, startDate, endDate)
 	} else if startDateStr != "" && endDateStr != "" {
 		// Get schedules for all rotation staff within a date range
 		startDate, err := time.Parse("2006-01-02", startDateStr)
@@ -412,7 +487,7 @@ func (h *RotationHandler) GetSchedules(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format"})
 			return
 		}
-		schedules, err = h.repos.RotationStaffSchedule.GetByDateRange(startDate, endDate)
+		schedules, err = h.repos.RotationStaffSchedule.GetByDateRange(c.Request.Context(), startDate, endDate)
 	} else {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Either 'date' or ('start_date', 'end_date') or ('rotation_staff_id', 'start_date', 'end_date') must be provided"})
 		return
@@ -461,7 +536,7 @@ func (h *RotationHandler) UpdateSchedule(c *gin.Context) {
 		return
 	}
 
-	schedule, err := h.repos.RotationStaffSchedule.GetByID(id)
+	schedule, err := h.repos.RotationStaffSchedule.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -472,7 +547,7 @@ func (h *RotationHandler) UpdateSchedule(c *gin.Context) {
 	}
 
 	schedule.ScheduleStatus = req.ScheduleStatus
-	if err := h.repos.RotationStaffSchedule.Update(schedule); err != nil {
+	if err := h.repos.RotationStaffSchedule.Update(c.Request.Context(), schedule); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -488,7 +563,7 @@ func (h *RotationHandler) DeleteSchedule(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.RotationStaffSchedule.Delete(id); err != nil {
+	if err := h.repos.RotationStaffSchedule.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
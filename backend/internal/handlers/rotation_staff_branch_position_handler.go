@@ -34,17 +34,17 @@ func (h *RotationStaffBranchPositionHandler) List(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid staff_id"})
 			return
 		}
-		mappings, err = h.repos.RotationStaffBranchPosition.GetByStaffID(staffID)
+		mappings, err = h.repos.RotationStaffBranchPosition.GetByStaffID(c.Request.Context(), staffID)
 	} else if positionIDStr != "" {
 		positionID, parseErr := uuid.Parse(positionIDStr)
 		if parseErr != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid position_id"})
 			return
 		}
-		mappings, err = h.repos.RotationStaffBranchPosition.GetByPositionID(positionID)
+		mappings, err = h.repos.RotationStaffBranchPosition.GetByPositionID(c.Request.Context(), positionID)
 	} else {
 		// If no filter, return all mappings
-		mappings, err = h.repos.RotationStaffBranchPosition.List()
+		mappings, err = h.repos.RotationStaffBranchPosition.List(c.Request.Context())
 	}
 
 	if err != nil {
@@ -55,17 +55,17 @@ func (h *RotationStaffBranchPositionHandler) List(c *gin.Context) {
 	// Load related data
 	for _, mapping := range mappings {
 		// Load staff
-		if staff, err := h.repos.Staff.GetByID(mapping.RotationStaffID); err == nil && staff != nil {
+		if staff, err := h.repos.Staff.GetByID(c.Request.Context(), mapping.RotationStaffID); err == nil && staff != nil {
 			mapping.RotationStaff = staff
 			// Load staff position
 			if staff.PositionID != uuid.Nil {
-				if position, err := h.repos.Position.GetByID(staff.PositionID); err == nil && position != nil {
+				if position, err := h.repos.Position.GetByID(c.Request.Context(), staff.PositionID); err == nil && position != nil {
 					staff.Position = position
 				}
 			}
 		}
 		// Load branch position
-		if position, err := h.repos.Position.GetByID(mapping.BranchPositionID); err == nil && position != nil {
+		if position, err := h.repos.Position.GetByID(c.Request.Context(), mapping.BranchPositionID); err == nil && position != nil {
 			mapping.BranchPosition = position
 		}
 	}
@@ -81,7 +81,7 @@ func (h *RotationStaffBranchPositionHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	mapping, err := h.repos.RotationStaffBranchPosition.GetByID(id)
+	mapping, err := h.repos.RotationStaffBranchPosition.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -93,15 +93,15 @@ func (h *RotationStaffBranchPositionHandler) GetByID(c *gin.Context) {
 	}
 
 	// Load related data
-	if staff, err := h.repos.Staff.GetByID(mapping.RotationStaffID); err == nil && staff != nil {
+	if staff, err := h.repos.Staff.GetByID(c.Request.Context(), mapping.RotationStaffID); err == nil && staff != nil {
 		mapping.RotationStaff = staff
 		if staff.PositionID != uuid.Nil {
-			if position, err := h.repos.Position.GetByID(staff.PositionID); err == nil && position != nil {
+			if position, err := h.repos.Position.GetByID(c.Request.Context(), staff.PositionID); err == nil && position != nil {
 				staff.Position = position
 			}
 		}
 	}
-	if position, err := h.repos.Position.GetByID(mapping.BranchPositionID); err == nil && position != nil {
+	if position, err := h.repos.Position.GetByID(c.Request.Context(), mapping.BranchPositionID); err == nil && position != nil {
 		mapping.BranchPosition = position
 	}
 
@@ -130,7 +130,7 @@ func (h *RotationStaffBranchPositionHandler) Create(c *gin.Context) {
 	}
 
 	// Verify staff is rotation staff
-	staff, err := h.repos.Staff.GetByID(req.RotationStaffID)
+	staff, err := h.repos.Staff.GetByID(c.Request.Context(), req.RotationStaffID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -145,7 +145,7 @@ func (h *RotationStaffBranchPositionHandler) Create(c *gin.Context) {
 	}
 
 	// Verify position is branch position
-	position, err := h.repos.Position.GetByID(req.BranchPositionID)
+	position, err := h.repos.Position.GetByID(c.Request.Context(), req.BranchPositionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -160,7 +160,7 @@ func (h *RotationStaffBranchPositionHandler) Create(c *gin.Context) {
 	}
 
 	// Check if mapping already exists
-	existing, err := h.repos.RotationStaffBranchPosition.GetByStaffAndPosition(req.RotationStaffID, req.BranchPositionID)
+	existing, err := h.repos.RotationStaffBranchPosition.GetByStaffAndPosition(c.Request.Context(), req.RotationStaffID, req.BranchPositionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -178,7 +178,7 @@ func (h *RotationStaffBranchPositionHandler) Create(c *gin.Context) {
 		Notes:             req.Notes,
 	}
 
-	if err := h.repos.RotationStaffBranchPosition.Create(mapping); err != nil {
+	if err := h.repos.RotationStaffBranchPosition.Create(c.Request.Context(), mapping); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -212,7 +212,7 @@ func (h *RotationStaffBranchPositionHandler) Update(c *gin.Context) {
 		return
 	}
 
-	mapping, err := h.repos.RotationStaffBranchPosition.GetByID(id)
+	mapping, err := h.repos.RotationStaffBranchPosition.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -226,7 +226,7 @@ func (h *RotationStaffBranchPositionHandler) Update(c *gin.Context) {
 	mapping.IsActive = req.IsActive
 	mapping.Notes = req.Notes
 
-	if err := h.repos.RotationStaffBranchPosition.Update(mapping); err != nil {
+	if err := h.repos.RotationStaffBranchPosition.Update(c.Request.Context(), mapping); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -242,7 +242,7 @@ func (h *RotationStaffBranchPositionHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.RotationStaffBranchPosition.Delete(id); err != nil {
+	if err := h.repos.RotationStaffBranchPosition.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
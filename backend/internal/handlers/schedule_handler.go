@@ -70,7 +70,7 @@ func (h *ScheduleHandler) GetBranchSchedule(c *gin.Context) {
 		endDate = startDate.AddDate(0, 0, 30)
 	}
 
-	schedules, err := h.repos.Schedule.GetByBranchID(branchID, startDate, endDate)
+	schedules, err := h.repos.Schedule.GetByBranchID(c.Request.Context(), branchID, startDate, endDate)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -132,7 +132,7 @@ func (h *ScheduleHandler) Create(c *gin.Context) {
 		}
 	}
 
-	if err := h.repos.Schedule.Create(schedule); err != nil {
+	if err := h.repos.Schedule.Create(c.Request.Context(), schedule); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -182,7 +182,7 @@ func (h *ScheduleHandler) GetMonthlyView(c *gin.Context) {
 		return
 	}
 
-	schedules, err := h.repos.Schedule.GetMonthlyView(branchID, year, month)
+	schedules, err := h.repos.Schedule.GetMonthlyView(c.Request.Context(), branchID, year, month)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -22,7 +22,7 @@ type UpdateSettingRequest struct {
 }
 
 func (h *SettingsHandler) GetAll(c *gin.Context) {
-	settings, err := h.repos.Settings.GetAll()
+	settings, err := h.repos.Settings.GetAll(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -44,7 +44,7 @@ func (h *SettingsHandler) Update(c *gin.Context) {
 		return
 	}
 
-	setting, err := h.repos.Settings.GetByKey(key)
+	setting, err := h.repos.Settings.GetByKey(c.Request.Context(), key)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -57,7 +57,7 @@ func (h *SettingsHandler) Update(c *gin.Context) {
 			Value:       req.Value,
 			Description: req.Description,
 		}
-		if err := h.repos.Settings.Create(setting); err != nil {
+		if err := h.repos.Settings.Create(c.Request.Context(), setting); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
@@ -67,7 +67,7 @@ func (h *SettingsHandler) Update(c *gin.Context) {
 		if req.Description != "" {
 			setting.Description = req.Description
 		}
-		if err := h.repos.Settings.Update(setting); err != nil {
+		if err := h.repos.Settings.Update(c.Request.Context(), setting); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
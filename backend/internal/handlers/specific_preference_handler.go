@@ -30,6 +30,7 @@ type CreateSpecificPreferenceRequest struct {
 	PositionID      *string `json:"position_id,omitempty"`     // Required for position_count
 	StaffCount      *int    `json:"staff_count,omitempty"`     // Required for position_count
 	StaffID         *string `json:"staff_id,omitempty"`        // Required for staff_name
+	IsHard          bool    `json:"is_hard"`                   // staff_name with a doctor: a hard pairing
 	IsActive        bool    `json:"is_active"`
 }
 
@@ -41,6 +42,7 @@ type UpdateSpecificPreferenceRequest struct {
 	PositionID      *string `json:"position_id,omitempty"`
 	StaffCount      *int    `json:"staff_count,omitempty"`
 	StaffID         *string `json:"staff_id,omitempty"`
+	IsHard          *bool   `json:"is_hard,omitempty"`
 	IsActive        *bool   `json:"is_active,omitempty"`
 }
 
@@ -71,7 +73,7 @@ func (h *SpecificPreferenceHandler) List(c *gin.Context) {
 		filters.IsActive = &isActive
 	}
 
-	preferences, err := h.repos.SpecificPreference.List(filters)
+	preferences, err := h.repos.SpecificPreference.List(c.Request.Context(), filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -80,19 +82,19 @@ func (h *SpecificPreferenceHandler) List(c *gin.Context) {
 	// Load related entities
 	for _, pref := range preferences {
 		if pref.BranchID != nil {
-			branch, _ := h.repos.Branch.GetByID(*pref.BranchID)
+			branch, _ := h.repos.Branch.GetByID(c.Request.Context(), *pref.BranchID)
 			pref.Branch = branch
 		}
 		if pref.DoctorID != nil {
-			doctor, _ := h.repos.Doctor.GetByID(*pref.DoctorID)
+			doctor, _ := h.repos.Doctor.GetByID(c.Request.Context(), *pref.DoctorID)
 			pref.Doctor = doctor
 		}
 		if pref.PositionID != nil {
-			position, _ := h.repos.Position.GetByID(*pref.PositionID)
+			position, _ := h.repos.Position.GetByID(c.Request.Context(), *pref.PositionID)
 			pref.Position = position
 		}
 		if pref.StaffID != nil {
-			staff, _ := h.repos.Staff.GetByID(*pref.StaffID)
+			staff, _ := h.repos.Staff.GetByID(c.Request.Context(), *pref.StaffID)
 			pref.Staff = staff
 		}
 	}
@@ -108,7 +110,7 @@ func (h *SpecificPreferenceHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	preference, err := h.repos.SpecificPreference.GetByID(id)
+	preference, err := h.repos.SpecificPreference.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -120,19 +122,19 @@ func (h *SpecificPreferenceHandler) GetByID(c *gin.Context) {
 
 	// Load related entities
 	if preference.BranchID != nil {
-		branch, _ := h.repos.Branch.GetByID(*preference.BranchID)
+		branch, _ := h.repos.Branch.GetByID(c.Request.Context(), *preference.BranchID)
 		preference.Branch = branch
 	}
 	if preference.DoctorID != nil {
-		doctor, _ := h.repos.Doctor.GetByID(*preference.DoctorID)
+		doctor, _ := h.repos.Doctor.GetByID(c.Request.Context(), *preference.DoctorID)
 		preference.Doctor = doctor
 	}
 	if preference.PositionID != nil {
-		position, _ := h.repos.Position.GetByID(*preference.PositionID)
+		position, _ := h.repos.Position.GetByID(c.Request.Context(), *preference.PositionID)
 		preference.Position = position
 	}
 	if preference.StaffID != nil {
-		staff, _ := h.repos.Staff.GetByID(*preference.StaffID)
+		staff, _ := h.repos.Staff.GetByID(c.Request.Context(), *preference.StaffID)
 		preference.Staff = staff
 	}
 
@@ -148,6 +150,7 @@ func (h *SpecificPreferenceHandler) Create(c *gin.Context) {
 
 	preference := &models.SpecificPreference{
 		PreferenceType: models.SpecificPreferenceType(req.PreferenceType),
+		IsHard:          req.IsHard,
 		IsActive:        req.IsActive,
 	}
 
@@ -214,7 +217,7 @@ func (h *SpecificPreferenceHandler) Create(c *gin.Context) {
 		preference.StaffID = &staffID
 	}
 
-	if err := h.repos.SpecificPreference.Create(preference); err != nil {
+	if err := h.repos.SpecificPreference.Create(c.Request.Context(), preference); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -231,7 +234,7 @@ func (h *SpecificPreferenceHandler) Update(c *gin.Context) {
 	}
 
 	// Get existing preference
-	preference, err := h.repos.SpecificPreference.GetByID(id)
+	preference, err := h.repos.SpecificPreference.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -316,11 +319,15 @@ func (h *SpecificPreferenceHandler) Update(c *gin.Context) {
 		}
 	}
 
+	if req.IsHard != nil {
+		preference.IsHard = *req.IsHard
+	}
+
 	if req.IsActive != nil {
 		preference.IsActive = *req.IsActive
 	}
 
-	if err := h.repos.SpecificPreference.Update(preference); err != nil {
+	if err := h.repos.SpecificPreference.Update(c.Request.Context(), preference); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -336,7 +343,7 @@ func (h *SpecificPreferenceHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.SpecificPreference.Delete(id); err != nil {
+	if err := h.repos.SpecificPreference.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -21,7 +21,7 @@ func NewStaffGroupHandler(repos *postgres.Repositories, db *sql.DB) *StaffGroupH
 }
 
 func (h *StaffGroupHandler) List(c *gin.Context) {
-	staffGroups, err := h.repos.StaffGroup.List()
+	staffGroups, err := h.repos.StaffGroup.List(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -29,7 +29,7 @@ func (h *StaffGroupHandler) List(c *gin.Context) {
 
 	// Load positions for each staff group
 	for _, group := range staffGroups {
-		positions, err := h.repos.StaffGroupPosition.GetByStaffGroupID(group.ID)
+		positions, err := h.repos.StaffGroupPosition.GetByStaffGroupID(c.Request.Context(), group.ID)
 		if err == nil {
 			group.Positions = positions
 		}
@@ -46,7 +46,7 @@ func (h *StaffGroupHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	staffGroup, err := h.repos.StaffGroup.GetByID(id)
+	staffGroup, err := h.repos.StaffGroup.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -58,7 +58,7 @@ func (h *StaffGroupHandler) GetByID(c *gin.Context) {
 	}
 
 	// Load positions
-	positions, err := h.repos.StaffGroupPosition.GetByStaffGroupID(id)
+	positions, err := h.repos.StaffGroupPosition.GetByStaffGroupID(c.Request.Context(), id)
 	if err == nil {
 		staffGroup.Positions = positions
 	}
@@ -85,7 +85,7 @@ func (h *StaffGroupHandler) Create(c *gin.Context) {
 		IsActive:    req.IsActive,
 	}
 
-	if err := h.repos.StaffGroup.Create(staffGroup); err != nil {
+	if err := h.repos.StaffGroup.Create(c.Request.Context(), staffGroup); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -113,7 +113,7 @@ func (h *StaffGroupHandler) Update(c *gin.Context) {
 		return
 	}
 
-	staffGroup, err := h.repos.StaffGroup.GetByID(id)
+	staffGroup, err := h.repos.StaffGroup.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -127,7 +127,7 @@ func (h *StaffGroupHandler) Update(c *gin.Context) {
 	staffGroup.Description = req.Description
 	staffGroup.IsActive = req.IsActive
 
-	if err := h.repos.StaffGroup.Update(staffGroup); err != nil {
+	if err := h.repos.StaffGroup.Update(c.Request.Context(), staffGroup); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -143,7 +143,7 @@ func (h *StaffGroupHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.StaffGroup.Delete(id); err != nil {
+	if err := h.repos.StaffGroup.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -174,7 +174,7 @@ func (h *StaffGroupHandler) AddPosition(c *gin.Context) {
 		PositionID:   req.PositionID,
 	}
 
-	if err := h.repos.StaffGroupPosition.Create(sgp); err != nil {
+	if err := h.repos.StaffGroupPosition.Create(c.Request.Context(), sgp); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -198,7 +198,7 @@ func (h *StaffGroupHandler) RemovePosition(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.StaffGroupPosition.DeleteByStaffGroupAndPosition(staffGroupID, positionID); err != nil {
+	if err := h.repos.StaffGroupPosition.DeleteByStaffGroupAndPosition(c.Request.Context(), staffGroupID, positionID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
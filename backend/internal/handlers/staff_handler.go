@@ -1,8 +1,8 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
-	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -10,12 +10,14 @@ import (
 	"vsq-oper-manpower/backend/internal/domain/interfaces"
 	"vsq-oper-manpower/backend/internal/domain/models"
 	"vsq-oper-manpower/backend/internal/repositories/postgres"
+	"vsq-oper-manpower/backend/internal/usecases/jobs"
 	"vsq-oper-manpower/backend/pkg/excel"
 )
 
 type StaffHandler struct {
 	repos         *postgres.Repositories
 	excelImporter *excel.ExcelImporter
+	jobs          *jobs.Runner
 }
 
 func NewStaffHandler(repos *postgres.Repositories) *StaffHandler {
@@ -91,7 +93,7 @@ func (h *StaffHandler) List(c *gin.Context) {
 		}
 	}
 
-	staffList, err := h.repos.Staff.List(filters)
+	staffList, err := h.repos.Staff.List(c.Request.Context(), filters)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -152,14 +154,14 @@ func (h *StaffHandler) Create(c *gin.Context) {
 		SkillLevel:        skillLevel,
 	}
 
-	if err := h.repos.Staff.Create(staff); err != nil {
+	if err := h.repos.Staff.Create(c.Request.Context(), staff); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	// Save individual branches if this is rotation staff
 	if models.StaffType(req.StaffType) == models.StaffTypeRotation && len(req.BranchIDs) > 0 {
-		if err := h.repos.Staff.BulkUpdateBranches(staff.ID, req.BranchIDs); err != nil {
+		if err := h.repos.Staff.BulkUpdateBranches(c.Request.Context(), staff.ID, req.BranchIDs); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to save branches: %v", err)})
 			return
 		}
@@ -177,7 +179,7 @@ func (h *StaffHandler) Update(c *gin.Context) {
 	}
 
 	// Check if staff exists and get current data
-	existingStaff, err := h.repos.Staff.GetByID(id)
+	existingStaff, err := h.repos.Staff.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Staff not found"})
 		return
@@ -263,7 +265,7 @@ func (h *StaffHandler) Update(c *gin.Context) {
 		staff.SkillLevel = 5 // Default to 5 if existing is also 0
 	}
 
-	if err := h.repos.Staff.Update(staff); err != nil {
+	if err := h.repos.Staff.Update(c.Request.Context(), staff); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -272,7 +274,7 @@ func (h *StaffHandler) Update(c *gin.Context) {
 	// If branch_ids is provided (even if empty array), update branches
 	// If branch_ids is nil/not provided, don't update branches
 	if staff.StaffType == models.StaffTypeRotation && req.BranchIDs != nil {
-		if err := h.repos.Staff.BulkUpdateBranches(id, req.BranchIDs); err != nil {
+		if err := h.repos.Staff.BulkUpdateBranches(c.Request.Context(), id, req.BranchIDs); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to update branches: %v", err)})
 			return
 		}
@@ -290,7 +292,7 @@ func (h *StaffHandler) Delete(c *gin.Context) {
 	}
 
 	// Check if staff exists and get current data
-	existingStaff, err := h.repos.Staff.GetByID(id)
+	existingStaff, err := h.repos.Staff.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Staff not found"})
 		return
@@ -317,9 +319,19 @@ func (h *StaffHandler) Delete(c *gin.Context) {
 		}
 	}
 
-	// Delete related records before deleting staff
+	// Delete related records before deleting staff, all in one transaction so a
+	// failure part-way does not leave a staff member without their schedules
+	ctx := c.Request.Context()
+	tx, err := h.repos.UnitOfWork.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to delete staff: %v", err)})
+		return
+	}
+	defer tx.Rollback()
+	txRepos := tx.Repositories()
+
 	// 1. Delete all schedules for this staff
-	if err := h.repos.Schedule.DeleteByStaffID(id); err != nil {
+	if err := txRepos.Schedule.DeleteByStaffID(ctx, id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to delete staff schedules: %v", err)})
 		return
 	}
@@ -327,19 +339,24 @@ func (h *StaffHandler) Delete(c *gin.Context) {
 	// 2. If rotation staff, delete rotation assignments and effective branches
 	if existingStaff.StaffType == models.StaffTypeRotation {
 		// Delete rotation assignments
-		if err := h.repos.Rotation.DeleteByRotationStaffID(id); err != nil {
+		if err := txRepos.Rotation.DeleteByRotationStaffID(ctx, id); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to delete rotation assignments: %v", err)})
 			return
 		}
 		// Delete effective branches
-		if err := h.repos.EffectiveBranch.DeleteByRotationStaffID(id); err != nil {
+		if err := txRepos.EffectiveBranch.DeleteByRotationStaffID(ctx, id); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to delete effective branches: %v", err)})
 			return
 		}
 	}
 
 	// 3. Finally, delete the staff member
-	if err := h.repos.Staff.Delete(id); err != nil {
+	if err := txRepos.Staff.Delete(ctx, id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to delete staff: %v", err)})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to delete staff: %v", err)})
 		return
 	}
@@ -347,6 +364,8 @@ func (h *StaffHandler) Delete(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Staff deleted successfully"})
 }
 
+// Import imports staff from an Excel file. With ?async=true the import runs as
+// a background job and the response is the queued job.
 func (h *StaffHandler) Import(c *gin.Context) {
 	// Get the uploaded file
 	file, err := c.FormFile("file")
@@ -355,28 +374,35 @@ func (h *StaffHandler) Import(c *gin.Context) {
 		return
 	}
 
-	// Open the uploaded file
-	src, err := file.Open()
+	input, err := uploadedJobFile(file)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open file"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
 		return
 	}
-	defer src.Close()
 
-	// Read file content
-	fileData, err := io.ReadAll(src)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
+	if wantsAsync(c) {
+		enqueueJob(c, h.jobs, JobTypeStaffImport, nil, input)
 		return
 	}
+	c.JSON(h.importStaff(c.Request.Context(), input.Data))
+}
+
+// registerJobs lets the staff import run in the background
+func (h *StaffHandler) registerJobs(runner *jobs.Runner) {
+	h.jobs = runner
+	runner.Register(JobTypeStaffImport, importJob(runner, func(ctx context.Context, job *models.Job, fileData []byte) (int, gin.H) {
+		return h.importStaff(ctx, fileData)
+	}))
+}
 
+// importStaff saves the staff in an Excel file and returns the response status and body
+func (h *StaffHandler) importStaff(ctx context.Context, fileData []byte) (int, gin.H) {
 	// Import staff from Excel
-	staffList, parseErr := h.excelImporter.ImportStaff(fileData)
+	staffList, parseErr := h.excelImporter.ImportStaff(ctx, fileData)
 	if parseErr != nil {
 		// If no valid records were parsed, return error immediately
 		if len(staffList) == 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": parseErr.Error()})
-			return
+			return http.StatusBadRequest, gin.H{"error": parseErr.Error()}
 		}
 		// If there are valid records but also parsing errors, continue to save the valid ones
 		// The parsing errors will be included as warnings in the response
@@ -386,7 +412,7 @@ func (h *StaffHandler) Import(c *gin.Context) {
 	var savedStaff []*models.Staff
 	var saveErrors []string
 	for _, staff := range staffList {
-		if err := h.repos.Staff.Create(staff); err != nil {
+		if err := h.repos.Staff.Create(ctx, staff); err != nil {
 			saveErrors = append(saveErrors, fmt.Sprintf("Failed to save %s: %v", staff.Name, err))
 			continue
 		}
@@ -405,11 +431,10 @@ func (h *StaffHandler) Import(c *gin.Context) {
 				errorMsg = fmt.Sprintf("%s (and %d more)", errorMsg, len(saveErrors)-1)
 			}
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
+		return http.StatusInternalServerError, gin.H{
 			"error":   errorMsg,
 			"details": saveErrors,
-		})
-		return
+		}
 	}
 
 	// Build response with actual saved count
@@ -433,10 +458,9 @@ func (h *StaffHandler) Import(c *gin.Context) {
 
 	// Return partial content if there were any errors (parsing or saving)
 	if parseErr != nil || len(saveErrors) > 0 {
-		c.JSON(http.StatusPartialContent, response)
-		return
+		return http.StatusPartialContent, response
 	}
 
-	c.JSON(http.StatusOK, response)
+	return http.StatusOK, response
 }
 
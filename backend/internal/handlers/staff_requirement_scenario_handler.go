@@ -23,7 +23,7 @@ func NewStaffRequirementScenarioHandler(repos *postgres.Repositories) *StaffRequ
 // List returns all staff requirement scenarios
 func (h *StaffRequirementScenarioHandler) List(c *gin.Context) {
 	includeInactive := c.Query("include_inactive") == "true"
-	scenarios, err := h.repos.StaffRequirementScenario.List(includeInactive)
+	scenarios, err := h.repos.StaffRequirementScenario.List(c.Request.Context(), includeInactive)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -31,7 +31,7 @@ func (h *StaffRequirementScenarioHandler) List(c *gin.Context) {
 
 	// Load position requirements and specific staff requirements for each scenario
 	for _, scenario := range scenarios {
-		requirements, err := h.repos.ScenarioPositionRequirement.GetByScenarioID(scenario.ID)
+		requirements, err := h.repos.ScenarioPositionRequirement.GetByScenarioID(c.Request.Context(), scenario.ID)
 		if err == nil {
 			// Convert []*models.ScenarioPositionRequirement to []models.ScenarioPositionRequirement
 			scenario.PositionRequirements = make([]models.ScenarioPositionRequirement, len(requirements))
@@ -39,7 +39,7 @@ func (h *StaffRequirementScenarioHandler) List(c *gin.Context) {
 				scenario.PositionRequirements[i] = *req
 			}
 		}
-		specificStaffReqs, err := h.repos.ScenarioSpecificStaffRequirement.GetByScenarioID(scenario.ID)
+		specificStaffReqs, err := h.repos.ScenarioSpecificStaffRequirement.GetByScenarioID(c.Request.Context(), scenario.ID)
 		if err == nil {
 			scenario.SpecificStaffRequirements = make([]models.ScenarioSpecificStaffRequirement, len(specificStaffReqs))
 			for i, req := range specificStaffReqs {
@@ -60,7 +60,7 @@ func (h *StaffRequirementScenarioHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	scenario, err := h.repos.StaffRequirementScenario.GetByID(id)
+	scenario, err := h.repos.StaffRequirementScenario.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -71,7 +71,7 @@ func (h *StaffRequirementScenarioHandler) GetByID(c *gin.Context) {
 	}
 
 	// Load position requirements
-	requirements, err := h.repos.ScenarioPositionRequirement.GetByScenarioID(scenario.ID)
+	requirements, err := h.repos.ScenarioPositionRequirement.GetByScenarioID(c.Request.Context(), scenario.ID)
 	if err == nil {
 		// Convert []*models.ScenarioPositionRequirement to []models.ScenarioPositionRequirement
 		scenario.PositionRequirements = make([]models.ScenarioPositionRequirement, len(requirements))
@@ -81,7 +81,7 @@ func (h *StaffRequirementScenarioHandler) GetByID(c *gin.Context) {
 	}
 
 	// Load specific staff requirements
-	specificStaffReqs, err := h.repos.ScenarioSpecificStaffRequirement.GetByScenarioID(scenario.ID)
+	specificStaffReqs, err := h.repos.ScenarioSpecificStaffRequirement.GetByScenarioID(c.Request.Context(), scenario.ID)
 	if err == nil {
 		scenario.SpecificStaffRequirements = make([]models.ScenarioSpecificStaffRequirement, len(specificStaffReqs))
 		for i, req := range specificStaffReqs {
@@ -91,7 +91,7 @@ func (h *StaffRequirementScenarioHandler) GetByID(c *gin.Context) {
 
 	// Load revenue tier if present
 	if scenario.RevenueLevelTierID != nil {
-		tier, err := h.repos.RevenueLevelTier.GetByID(*scenario.RevenueLevelTierID)
+		tier, err := h.repos.RevenueLevelTier.GetByID(c.Request.Context(), *scenario.RevenueLevelTierID)
 		if err == nil && tier != nil {
 			scenario.RevenueLevelTier = tier
 		}
@@ -99,7 +99,7 @@ func (h *StaffRequirementScenarioHandler) GetByID(c *gin.Context) {
 
 	// Load doctor if present
 	if scenario.DoctorID != nil {
-		doctor, err := h.repos.Doctor.GetByID(*scenario.DoctorID)
+		doctor, err := h.repos.Doctor.GetByID(c.Request.Context(), *scenario.DoctorID)
 		if err == nil && doctor != nil {
 			scenario.Doctor = doctor
 		}
@@ -107,7 +107,7 @@ func (h *StaffRequirementScenarioHandler) GetByID(c *gin.Context) {
 
 	// Load branch if present
 	if scenario.BranchID != nil {
-		branch, err := h.repos.Branch.GetByID(*scenario.BranchID)
+		branch, err := h.repos.Branch.GetByID(c.Request.Context(), *scenario.BranchID)
 		if err == nil && branch != nil {
 			scenario.Branch = branch
 		}
@@ -143,7 +143,18 @@ func (h *StaffRequirementScenarioHandler) Create(c *gin.Context) {
 		Priority:               req.Priority,
 	}
 
-	if err := h.repos.StaffRequirementScenario.Create(scenario); err != nil {
+	// The scenario and its requirements are created together so a failed
+	// requirement insert does not leave an empty scenario behind
+	ctx := c.Request.Context()
+	tx, err := h.repos.UnitOfWork.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer tx.Rollback()
+	txRepos := tx.Repositories()
+
+	if err := txRepos.StaffRequirementScenario.Create(ctx, scenario); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -161,7 +172,7 @@ func (h *StaffRequirementScenarioHandler) Create(c *gin.Context) {
 				OverrideBase:   reqReq.OverrideBase,
 			}
 		}
-		if err := h.repos.ScenarioPositionRequirement.BulkUpsert(requirements); err != nil {
+		if err := txRepos.ScenarioPositionRequirement.BulkUpsert(ctx, requirements); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create position requirements: " + err.Error()})
 			return
 		}
@@ -177,14 +188,19 @@ func (h *StaffRequirementScenarioHandler) Create(c *gin.Context) {
 				StaffID:    reqReq.StaffID,
 			}
 		}
-		if err := h.repos.ScenarioSpecificStaffRequirement.BulkUpsert(specificStaffReqs); err != nil {
+		if err := txRepos.ScenarioSpecificStaffRequirement.BulkUpsert(ctx, specificStaffReqs); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create specific staff requirements: " + err.Error()})
 			return
 		}
 	}
 
+	if err := tx.Commit(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Reload scenario with requirements
-	requirements, _ := h.repos.ScenarioPositionRequirement.GetByScenarioID(scenario.ID)
+	requirements, _ := h.repos.ScenarioPositionRequirement.GetByScenarioID(c.Request.Context(), scenario.ID)
 	if requirements != nil {
 		// Convert []*models.ScenarioPositionRequirement to []models.ScenarioPositionRequirement
 		scenario.PositionRequirements = make([]models.ScenarioPositionRequirement, len(requirements))
@@ -192,7 +208,7 @@ func (h *StaffRequirementScenarioHandler) Create(c *gin.Context) {
 			scenario.PositionRequirements[i] = *req
 		}
 	}
-	specificStaffReqs, _ := h.repos.ScenarioSpecificStaffRequirement.GetByScenarioID(scenario.ID)
+	specificStaffReqs, _ := h.repos.ScenarioSpecificStaffRequirement.GetByScenarioID(c.Request.Context(), scenario.ID)
 	if specificStaffReqs != nil {
 		scenario.SpecificStaffRequirements = make([]models.ScenarioSpecificStaffRequirement, len(specificStaffReqs))
 		for i, req := range specificStaffReqs {
@@ -212,7 +228,7 @@ func (h *StaffRequirementScenarioHandler) Update(c *gin.Context) {
 		return
 	}
 
-	scenario, err := h.repos.StaffRequirementScenario.GetByID(id)
+	scenario, err := h.repos.StaffRequirementScenario.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -273,7 +289,7 @@ func (h *StaffRequirementScenarioHandler) Update(c *gin.Context) {
 		scenario.Priority = *req.Priority
 	}
 
-	if err := h.repos.StaffRequirementScenario.Update(scenario); err != nil {
+	if err := h.repos.StaffRequirementScenario.Update(c.Request.Context(), scenario); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -290,7 +306,7 @@ func (h *StaffRequirementScenarioHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.StaffRequirementScenario.Delete(id); err != nil {
+	if err := h.repos.StaffRequirementScenario.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -316,7 +332,7 @@ func (h *StaffRequirementScenarioHandler) UpdatePositionRequirements(c *gin.Cont
 	}
 
 	// Delete existing requirements
-	if err := h.repos.ScenarioPositionRequirement.DeleteByScenarioID(scenarioID); err != nil {
+	if err := h.repos.ScenarioPositionRequirement.DeleteByScenarioID(c.Request.Context(), scenarioID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete existing requirements: " + err.Error()})
 		return
 	}
@@ -334,7 +350,7 @@ func (h *StaffRequirementScenarioHandler) UpdatePositionRequirements(c *gin.Cont
 				OverrideBase:   reqReq.OverrideBase,
 			}
 		}
-		if err := h.repos.ScenarioPositionRequirement.BulkUpsert(requirements); err != nil {
+		if err := h.repos.ScenarioPositionRequirement.BulkUpsert(c.Request.Context(), requirements); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create requirements: " + err.Error()})
 			return
 		}
@@ -361,7 +377,7 @@ func (h *StaffRequirementScenarioHandler) UpdateSpecificStaffRequirements(c *gin
 	}
 
 	// Delete existing requirements
-	if err := h.repos.ScenarioSpecificStaffRequirement.DeleteByScenarioID(scenarioID); err != nil {
+	if err := h.repos.ScenarioSpecificStaffRequirement.DeleteByScenarioID(c.Request.Context(), scenarioID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete existing requirements: " + err.Error()})
 		return
 	}
@@ -376,7 +392,7 @@ func (h *StaffRequirementScenarioHandler) UpdateSpecificStaffRequirements(c *gin
 				StaffID:    reqReq.StaffID,
 			}
 		}
-		if err := h.repos.ScenarioSpecificStaffRequirement.BulkUpsert(requirements); err != nil {
+		if err := h.repos.ScenarioSpecificStaffRequirement.BulkUpsert(c.Request.Context(), requirements); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create requirements: " + err.Error()})
 			return
 		}
@@ -420,7 +436,7 @@ func (h *StaffRequirementScenarioHandler) CalculateRequirements(c *gin.Context)
 
 	// Get base quotas if not provided
 	if req.BasePreferred == 0 && req.BaseMinimum == 0 {
-		quota, err := h.repos.PositionQuota.GetByBranchAndPosition(branchID, positionID)
+		quota, err := h.repos.PositionQuota.GetByBranchAndPosition(c.Request.Context(), branchID, positionID)
 		if err == nil && quota != nil {
 			req.BasePreferred = quota.DesignatedQuota
 			req.BaseMinimum = quota.MinimumRequired
@@ -440,7 +456,7 @@ func (h *StaffRequirementScenarioHandler) CalculateRequirements(c *gin.Context)
 	}
 
 	calculator := scenario.NewScenarioCalculator(reposWrapper)
-	result, err := calculator.CalculateStaffRequirements(branchID, date, positionID, req.BasePreferred, req.BaseMinimum)
+	result, err := calculator.CalculateStaffRequirements(c.Request.Context(), branchID, date, positionID, req.BasePreferred, req.BaseMinimum)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -484,7 +500,7 @@ func (h *StaffRequirementScenarioHandler) GetMatchingScenarios(c *gin.Context) {
 	}
 
 	calculator := scenario.NewScenarioCalculator(reposWrapper)
-	matches, err := calculator.GetMatchingScenarios(branchID, date)
+	matches, err := calculator.GetMatchingScenarios(c.Request.Context(), branchID, date)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
+	"vsq-oper-manpower/backend/internal/domain/models"
 	"vsq-oper-manpower/backend/internal/repositories/postgres"
+	"vsq-oper-manpower/backend/internal/usecases/jobs"
 	"vsq-oper-manpower/backend/internal/usecases/test_data"
 
 	"github.com/gin-gonic/gin"
@@ -15,6 +19,7 @@ import (
 type TestDataHandler struct {
 	repos     *postgres.Repositories
 	generator *test_data.ScheduleGenerator
+	jobs      *jobs.Runner
 }
 
 func NewTestDataHandler(repos *postgres.Repositories) *TestDataHandler {
@@ -32,7 +37,8 @@ type GenerateScheduleRequest struct {
 	BranchIDs         []string                `json:"branch_ids,omitempty"` // Optional: filter by specific branch IDs. If empty, generates for all branches.
 }
 
-// GenerateSchedules generates schedules for all branch staff
+// GenerateSchedules generates schedules for all branch staff. With ?async=true
+// the generation runs as a background job and the response is the queued job.
 func (h *TestDataHandler) GenerateSchedules(c *gin.Context) {
 	var req GenerateScheduleRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -40,64 +46,101 @@ func (h *TestDataHandler) GenerateSchedules(c *gin.Context) {
 		return
 	}
 
+	// Get user ID from context
+	userIDStr := c.MustGet("user_id").(string)
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	generateReq, err := req.toGenerateRequest(userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if wantsAsync(c) {
+		enqueueJob(c, h.jobs, JobTypeGenerateTestSchedules, req, nil)
+		return
+	}
+	c.JSON(h.generateSchedules(c.Request.Context(), generateReq))
+}
+
+// registerJobs lets schedule generation run in the background
+func (h *TestDataHandler) registerJobs(runner *jobs.Runner) {
+	h.jobs = runner
+	runner.Register(JobTypeGenerateTestSchedules, responseJob(func(ctx context.Context, job *models.Job) (int, gin.H) {
+		var req GenerateScheduleRequest
+		if err := jobs.DecodePayload(job, &req); err != nil {
+			return http.StatusBadRequest, gin.H{"error": err.Error()}
+		}
+		if job.CreatedBy == nil {
+			return http.StatusBadRequest, gin.H{"error": "Schedule generation job has no creator"}
+		}
+		generateReq, err := req.toGenerateRequest(*job.CreatedBy)
+		if err != nil {
+			return http.StatusBadRequest, gin.H{"error": err.Error()}
+		}
+		return h.generateSchedules(ctx, generateReq)
+	}))
+}
+
+func (h *TestDataHandler) generateSchedules(ctx context.Context, generateReq test_data.GenerateSchedulesRequest) (int, gin.H) {
+	result, err := h.generator.GenerateSchedules(ctx, generateReq)
+	if err != nil {
+		return http.StatusInternalServerError, gin.H{"error": err.Error()}
+	}
+
+	return http.StatusOK, gin.H{
+		"message": "Schedules generated successfully",
+		"result":  result,
+	}
+}
+
+// toGenerateRequest validates the request and converts it for the generator
+func (req *GenerateScheduleRequest) toGenerateRequest(userID uuid.UUID) (test_data.GenerateSchedulesRequest, error) {
+	var generateReq test_data.GenerateSchedulesRequest
+
 	// Parse dates
 	startDate, err := time.Parse("2006-01-02", req.StartDate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format. Use YYYY-MM-DD"})
-		return
+		return generateReq, errors.New("Invalid start_date format. Use YYYY-MM-DD")
 	}
 
 	endDate, err := time.Parse("2006-01-02", req.EndDate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format. Use YYYY-MM-DD"})
-		return
+		return generateReq, errors.New("Invalid end_date format. Use YYYY-MM-DD")
 	}
 
 	if endDate.Before(startDate) {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "end_date must be after start_date"})
-		return
+		return generateReq, errors.New("end_date must be after start_date")
 	}
 
 	// Validate rules
 	if req.Rules.MinWorkingDaysPerWeek < 0 || req.Rules.MinWorkingDaysPerWeek > 7 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "min_working_days_per_week must be between 0 and 7"})
-		return
+		return generateReq, errors.New("min_working_days_per_week must be between 0 and 7")
 	}
 	if req.Rules.MaxWorkingDaysPerWeek < 0 || req.Rules.MaxWorkingDaysPerWeek > 7 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "max_working_days_per_week must be between 0 and 7"})
-		return
+		return generateReq, errors.New("max_working_days_per_week must be between 0 and 7")
 	}
 	if req.Rules.MinWorkingDaysPerWeek > req.Rules.MaxWorkingDaysPerWeek {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "min_working_days_per_week must be <= max_working_days_per_week"})
-		return
+		return generateReq, errors.New("min_working_days_per_week must be <= max_working_days_per_week")
 	}
 	if req.Rules.LeaveProbability < 0 || req.Rules.LeaveProbability > 1 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "leave_probability must be between 0.0 and 1.0"})
-		return
+		return generateReq, errors.New("leave_probability must be between 0.0 and 1.0")
 	}
 	if req.Rules.WeekendWorkingRatio < 0 || req.Rules.WeekendWorkingRatio > 1 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "weekend_working_ratio must be between 0.0 and 1.0"})
-		return
+		return generateReq, errors.New("weekend_working_ratio must be between 0.0 and 1.0")
 	}
 	if req.Rules.ConsecutiveLeaveMax < 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "consecutive_leave_max must be >= 0"})
-		return
+		return generateReq, errors.New("consecutive_leave_max must be >= 0")
 	}
 	if req.Rules.MinOffDaysPerMonth < 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "min_off_days_per_month must be >= 0"})
-		return
+		return generateReq, errors.New("min_off_days_per_month must be >= 0")
 	}
 	if req.Rules.MaxOffDaysPerMonth > 0 && req.Rules.MaxOffDaysPerMonth < req.Rules.MinOffDaysPerMonth {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "max_off_days_per_month must be >= min_off_days_per_month"})
-		return
-	}
-
-	// Get user ID from context
-	userIDStr := c.MustGet("user_id").(string)
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
-		return
+		return generateReq, errors.New("max_off_days_per_month must be >= min_off_days_per_month")
 	}
 
 	// Parse branch IDs if provided
@@ -107,31 +150,18 @@ func (h *TestDataHandler) GenerateSchedules(c *gin.Context) {
 		for _, branchIDStr := range req.BranchIDs {
 			branchID, err := uuid.Parse(branchIDStr)
 			if err != nil {
-				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid branch_id format: %s", branchIDStr)})
-				return
+				return generateReq, fmt.Errorf("Invalid branch_id format: %s", branchIDStr)
 			}
 			branchIDs = append(branchIDs, branchID)
 		}
 	}
 
-	// Generate schedules
-	generateReq := test_data.GenerateSchedulesRequest{
+	return test_data.GenerateSchedulesRequest{
 		StartDate:         startDate,
 		EndDate:           endDate,
 		Rules:             req.Rules,
 		OverwriteExisting: req.OverwriteExisting,
 		CreatedBy:         userID,
 		BranchIDs:         branchIDs, // nil or empty means all branches
-	}
-
-	result, err := h.generator.GenerateSchedules(generateReq)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Schedules generated successfully",
-		"result":  result,
-	})
+	}, nil
 }
@@ -33,7 +33,7 @@ type UpdateUserRequest struct {
 }
 
 func (h *UserHandler) List(c *gin.Context) {
-	users, err := h.repos.User.List()
+	users, err := h.repos.User.List(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -42,7 +42,7 @@ func (h *UserHandler) List(c *gin.Context) {
 	// Get roles for each user
 	usersWithRoles := make([]gin.H, 0, len(users))
 	for _, user := range users {
-		role, err := h.repos.Role.GetByID(user.RoleID)
+		role, err := h.repos.Role.GetByID(c.Request.Context(), user.RoleID)
 		if err != nil {
 			continue
 		}
@@ -75,14 +75,14 @@ func (h *UserHandler) Create(c *gin.Context) {
 		return
 	}
 
-	role, err := h.repos.Role.GetByID(roleID)
+	role, err := h.repos.Role.GetByID(c.Request.Context(), roleID)
 	if err != nil || role == nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Role not found"})
 		return
 	}
 
 	// Check if username already exists
-	existingUser, err := h.repos.User.GetByUsername(req.Username)
+	existingUser, err := h.repos.User.GetByUsername(c.Request.Context(), req.Username)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
@@ -93,7 +93,7 @@ func (h *UserHandler) Create(c *gin.Context) {
 	}
 
 	// Check if email already exists
-	existingEmail, err := h.repos.User.GetByEmail(req.Email)
+	existingEmail, err := h.repos.User.GetByEmail(c.Request.Context(), req.Email)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
@@ -118,12 +118,12 @@ func (h *UserHandler) Create(c *gin.Context) {
 		RoleID:       roleID,
 	}
 
-	if err := h.repos.User.Create(user); err != nil {
+	if err := h.repos.User.Create(c.Request.Context(), user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	role, _ = h.repos.Role.GetByID(user.RoleID)
+	role, _ = h.repos.Role.GetByID(c.Request.Context(), user.RoleID)
 	c.JSON(http.StatusCreated, gin.H{
 		"user": gin.H{
 			"id":        user.ID,
@@ -152,7 +152,7 @@ func (h *UserHandler) Update(c *gin.Context) {
 	}
 
 	// Get existing user
-	user, err := h.repos.User.GetByID(id)
+	user, err := h.repos.User.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -165,7 +165,7 @@ func (h *UserHandler) Update(c *gin.Context) {
 	// Update fields
 	if req.Username != "" {
 		// Check if username is already taken by another user
-		existingUser, err := h.repos.User.GetByUsername(req.Username)
+		existingUser, err := h.repos.User.GetByUsername(c.Request.Context(), req.Username)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 			return
@@ -179,7 +179,7 @@ func (h *UserHandler) Update(c *gin.Context) {
 
 	if req.Email != "" {
 		// Check if email is already taken by another user
-		existingEmail, err := h.repos.User.GetByEmail(req.Email)
+		existingEmail, err := h.repos.User.GetByEmail(c.Request.Context(), req.Email)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 			return
@@ -207,7 +207,7 @@ func (h *UserHandler) Update(c *gin.Context) {
 			return
 		}
 
-		role, err := h.repos.Role.GetByID(roleID)
+		role, err := h.repos.Role.GetByID(c.Request.Context(), roleID)
 		if err != nil || role == nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Role not found"})
 			return
@@ -215,12 +215,12 @@ func (h *UserHandler) Update(c *gin.Context) {
 		user.RoleID = roleID
 	}
 
-	if err := h.repos.User.Update(user); err != nil {
+	if err := h.repos.User.Update(c.Request.Context(), user); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	role, _ := h.repos.Role.GetByID(user.RoleID)
+	role, _ := h.repos.Role.GetByID(c.Request.Context(), user.RoleID)
 	c.JSON(http.StatusOK, gin.H{
 		"user": gin.H{
 			"id":        user.ID,
@@ -243,7 +243,7 @@ func (h *UserHandler) Delete(c *gin.Context) {
 	}
 
 	// Check if user exists
-	user, err := h.repos.User.GetByID(id)
+	user, err := h.repos.User.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -253,7 +253,7 @@ func (h *UserHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.User.Delete(id); err != nil {
+	if err := h.repos.User.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -31,7 +31,7 @@ type UpdateZoneBranchesRequest struct {
 func (h *ZoneHandler) List(c *gin.Context) {
 	includeInactive := c.Query("include_inactive") == "true"
 	
-	zones, err := h.repos.Zone.List(includeInactive)
+	zones, err := h.repos.Zone.List(c.Request.Context(), includeInactive)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -48,14 +48,14 @@ func (h *ZoneHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	zone, err := h.repos.Zone.GetByID(id)
+	zone, err := h.repos.Zone.GetByID(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Zone not found"})
 		return
 	}
 
 	// Load branches for this zone
-	branches, err := h.repos.Zone.GetBranches(id)
+	branches, err := h.repos.Zone.GetBranches(c.Request.Context(), id)
 	if err == nil {
 		zone.Branches = branches
 	}
@@ -71,7 +71,7 @@ func (h *ZoneHandler) Create(c *gin.Context) {
 	}
 
 	// Check if code already exists
-	existing, _ := h.repos.Zone.GetByCode(req.Code)
+	existing, _ := h.repos.Zone.GetByCode(c.Request.Context(), req.Code)
 	if existing != nil {
 		c.JSON(http.StatusConflict, gin.H{"error": "Zone with this code already exists"})
 		return
@@ -85,7 +85,7 @@ func (h *ZoneHandler) Create(c *gin.Context) {
 		IsActive:    req.IsActive,
 	}
 
-	if err := h.repos.Zone.Create(zone); err != nil {
+	if err := h.repos.Zone.Create(c.Request.Context(), zone); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -108,7 +108,7 @@ func (h *ZoneHandler) Update(c *gin.Context) {
 	}
 
 	// Check if code already exists (excluding current record)
-	existing, _ := h.repos.Zone.GetByCode(req.Code)
+	existing, _ := h.repos.Zone.GetByCode(c.Request.Context(), req.Code)
 	if existing != nil && existing.ID != id {
 		c.JSON(http.StatusConflict, gin.H{"error": "Zone with this code already exists"})
 		return
@@ -122,7 +122,7 @@ func (h *ZoneHandler) Update(c *gin.Context) {
 		IsActive:    req.IsActive,
 	}
 
-	if err := h.repos.Zone.Update(zone); err != nil {
+	if err := h.repos.Zone.Update(c.Request.Context(), zone); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -138,7 +138,7 @@ func (h *ZoneHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.Zone.Delete(id); err != nil {
+	if err := h.repos.Zone.Delete(c.Request.Context(), id); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -154,7 +154,7 @@ func (h *ZoneHandler) GetBranches(c *gin.Context) {
 		return
 	}
 
-	branches, err := h.repos.Zone.GetBranches(id)
+	branches, err := h.repos.Zone.GetBranches(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -177,7 +177,7 @@ func (h *ZoneHandler) UpdateBranches(c *gin.Context) {
 		return
 	}
 
-	if err := h.repos.Zone.BulkUpdateBranches(id, req.BranchIDs); err != nil {
+	if err := h.repos.Zone.BulkUpdateBranches(c.Request.Context(), id, req.BranchIDs); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
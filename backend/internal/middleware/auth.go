@@ -3,12 +3,20 @@ package middleware
 import (
 	"net/http"
 
+	"vsq-oper-manpower/backend/pkg/logging"
+
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 )
 
 func RequireAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Bearer tokens were already validated by APITokenAuth
+		if isTokenAuthenticated(c) {
+			c.Next()
+			return
+		}
+
 		session := sessions.Default(c)
 		userID := session.Get("user_id")
 		
@@ -27,6 +35,9 @@ func RequireAuth() gin.HandlerFunc {
 		}
 
 		c.Set("user_id", userIDStr)
+		role, _ := session.Get("role").(string)
+		c.Set("role", role)
+		c.Request = c.Request.WithContext(logging.WithFields(c.Request.Context(), "user_id", userIDStr, "role", role))
 		
 		// Set branch_id if available (for branch managers)
 		branchID := session.Get("branch_id")
@@ -42,9 +53,13 @@ func RequireAuth() gin.HandlerFunc {
 
 func RequireRole(allowedRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		session := sessions.Default(c)
-		role := session.Get("role")
-		
+		var role interface{}
+		if isTokenAuthenticated(c) {
+			role = c.GetString("role")
+		} else {
+			role = sessions.Default(c).Get("role")
+		}
+
 		if role == nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 			c.Abort()
@@ -65,3 +80,16 @@ func RequireRole(allowedRoles ...string) gin.HandlerFunc {
 	}
 }
 
+// RequireSession refuses requests authenticated by API token. The routes that
+// manage service accounts and tokens use it, so a leaked token cannot mint
+// wider tokens or new admin accounts.
+func RequireSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if isTokenAuthenticated(c) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API tokens cannot manage service accounts or tokens"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -13,6 +13,9 @@ func RequireBranchAccess() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		session := sessions.Default(c)
 		role := session.Get("role")
+		if isTokenAuthenticated(c) {
+			role = c.GetString("role")
+		}
 		
 		// Only enforce for branch managers
 		if role != "branch_manager" {
@@ -21,6 +24,12 @@ func RequireBranchAccess() gin.HandlerFunc {
 		}
 
 		branchID := session.Get("branch_id")
+		if isTokenAuthenticated(c) {
+			branchID = nil
+			if id := c.GetString("branch_id"); id != "" {
+				branchID = id
+			}
+		}
 		if branchID == nil {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Branch manager must be assigned to a branch"})
 			c.Abort()
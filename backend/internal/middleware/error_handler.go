@@ -2,14 +2,12 @@ package middleware
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
-	"os"
-	"time"
 
 	"vsq-oper-manpower/backend/internal/errors"
+	"vsq-oper-manpower/backend/pkg/logging"
 
-	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -23,14 +21,15 @@ func RequestIDMiddleware() gin.HandlerFunc {
 		}
 		c.Set("request_id", requestID)
 		c.Writer.Header().Set("X-Request-ID", requestID)
+		// Every line logged with the request context carries the ID
+		c.Request = c.Request.WithContext(logging.WithFields(c.Request.Context(), "request_id", requestID))
 		c.Next()
 	}
 }
 
-// ErrorHandlerMiddleware handles errors and returns structured error responses
-func ErrorHandlerMiddleware() gin.HandlerFunc {
-	isDevelopment := os.Getenv("ENVIRONMENT") != "production"
-
+// ErrorHandlerMiddleware handles errors and returns structured error responses;
+// in development they include the underlying error
+func ErrorHandlerMiddleware(isDevelopment bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Next()
 
@@ -45,15 +44,6 @@ func ErrorHandlerMiddleware() gin.HandlerFunc {
 				requestIDStr = id
 			}
 
-			// Get user ID from session if available
-			userID := ""
-			session := sessions.Default(c)
-			if uid := session.Get("user_id"); uid != nil {
-				if uidStr, ok := uid.(string); ok {
-					userID = uidStr
-				}
-			}
-
 			// Determine error response
 			var appErr *errors.AppError
 			var statusCode int
@@ -73,7 +63,7 @@ func ErrorHandlerMiddleware() gin.HandlerFunc {
 			}
 
 			// Log error with context
-			logError(c, err.Err, statusCode, requestIDStr, userID, isDevelopment)
+			logError(c, err.Err, statusCode, isDevelopment)
 
 			// Build error response
 			errorResponse := gin.H{
@@ -100,20 +90,21 @@ func ErrorHandlerMiddleware() gin.HandlerFunc {
 	}
 }
 
-// logError logs error information with context
-func logError(c *gin.Context, err error, statusCode int, requestID, userID string, isDevelopment bool) {
-	timestamp := time.Now().Format(time.RFC3339)
-	method := c.Request.Method
-	path := c.Request.URL.Path
-
-	log.Printf("[ERROR] [%s] %s %s | Status: %d | RequestID: %s | UserID: %s | Error: %v",
-		timestamp, method, path, statusCode, requestID, userID, err)
+// logError logs error information; the request context adds the request ID
+// and, once authenticated, the user and role
+func logError(c *gin.Context, err error, statusCode int, isDevelopment bool) {
+	attrs := []any{
+		"method", c.Request.Method,
+		"path", c.Request.URL.Path,
+		"status", statusCode,
+		"error", err.Error(),
+	}
 
 	// In development, log more details
 	if isDevelopment {
-		log.Printf("[ERROR-DEBUG] Request Body: %s", getRequestBody(c))
-		log.Printf("[ERROR-DEBUG] Query Params: %s", c.Request.URL.RawQuery)
+		attrs = append(attrs, "body", getRequestBody(c), "query", c.Request.URL.RawQuery)
 	}
+	slog.ErrorContext(c.Request.Context(), "Request failed", attrs...)
 }
 
 // getRequestBody safely extracts request body for logging
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestTimeout attaches a deadline to the request context. Handlers pass
+// c.Request.Context() down to the repositories, so queries still running when the
+// deadline passes (or the client disconnects) are cancelled by the database driver.
+// overrides maps route patterns (c.FullPath()) to their own timeout; a zero or
+// negative timeout disables the deadline for that route.
+func RequestTimeout(timeout time.Duration, overrides map[string]time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		d := timeout
+		if override, ok := overrides[c.FullPath()]; ok {
+			d = override
+		}
+		if d <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			slog.WarnContext(ctx, "Request timed out", "method", c.Request.Method, "path", c.Request.URL.Path, "timeout", d.String())
+			if !c.Writer.Written() {
+				c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Request timed out"})
+			}
+		}
+	}
+}
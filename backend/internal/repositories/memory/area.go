@@ -0,0 +1,384 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// linkedBranches returns the branches linked to owner in links, ordered by name.
+// Like the postgres joins, branch_type_id is not selected.
+func linkedBranches(t *tables, links map[link]time.Time, owner uuid.UUID) []*models.Branch {
+	branches := collect(t.branches,
+		func(b *models.Branch) bool {
+			_, ok := links[link{owner, b.ID}]
+			return ok
+		},
+		func(a, b *models.Branch) bool { return a.Name < b.Name })
+	for _, branch := range branches {
+		branch.BranchTypeID = nil
+	}
+	return branches
+}
+
+// AreaOfOperationRepository implementation
+type areaOfOperationRepository struct {
+	store *Store
+}
+
+func checkAreaCode(t *tables, aoo *models.AreaOfOperation) error {
+	for _, a := range t.areasOfOperation {
+		if a.ID != aoo.ID && a.Code == aoo.Code {
+			return uniqueViolation("areas_of_operation_code_key")
+		}
+	}
+	return nil
+}
+
+func (r *areaOfOperationRepository) Create(ctx context.Context, aoo *models.AreaOfOperation) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.areasOfOperation[aoo.ID]; ok {
+			return uniqueViolation("areas_of_operation_pkey")
+		}
+		if err := checkAreaCode(t, aoo); err != nil {
+			return err
+		}
+		now := time.Now()
+		aoo.CreatedAt, aoo.UpdatedAt = now, now
+		row := *aoo
+		row.Zones, row.Branches = nil, nil
+		t.areasOfOperation[row.ID] = row
+		return nil
+	})
+}
+
+func (r *areaOfOperationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.AreaOfOperation, error) {
+	var aoo *models.AreaOfOperation
+	err := r.store.read(ctx, func(t *tables) error {
+		if aoo = get(t.areasOfOperation, id); aoo == nil {
+			return errNoRows
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return aoo, nil
+}
+
+func (r *areaOfOperationRepository) GetByCode(ctx context.Context, code string) (*models.AreaOfOperation, error) {
+	var aoo *models.AreaOfOperation
+	err := r.store.read(ctx, func(t *tables) error {
+		if aoo = find(t.areasOfOperation, func(a *models.AreaOfOperation) bool { return a.Code == code }); aoo == nil {
+			return errNoRows
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return aoo, nil
+}
+
+func (r *areaOfOperationRepository) Update(ctx context.Context, aoo *models.AreaOfOperation) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.areasOfOperation[aoo.ID]
+		if !ok {
+			return errNoRows
+		}
+		if err := checkAreaCode(t, aoo); err != nil {
+			return err
+		}
+		row.Name = aoo.Name
+		row.Code = aoo.Code
+		row.Description = aoo.Description
+		row.IsActive = aoo.IsActive
+		row.UpdatedAt = time.Now()
+		t.areasOfOperation[row.ID] = row
+		aoo.UpdatedAt = row.UpdatedAt
+		return nil
+	})
+}
+
+func (r *areaOfOperationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if find(t.staff, func(s *models.Staff) bool { return sameUUID(s.AreaOfOperationID, &id) }) != nil {
+			return foreignKeyViolation("areas_of_operation", "staff", "area_of_operation_id")
+		}
+		delete(t.areasOfOperation, id)
+		deleteLinks(t.aooZones, func(l link) bool { return l.left == id })
+		deleteLinks(t.aooBranches, func(l link) bool { return l.left == id })
+		return nil
+	})
+}
+
+func (r *areaOfOperationRepository) List(ctx context.Context, includeInactive bool) ([]*models.AreaOfOperation, error) {
+	var areas []*models.AreaOfOperation
+	err := r.store.read(ctx, func(t *tables) error {
+		areas = collect(t.areasOfOperation,
+			func(a *models.AreaOfOperation) bool { return includeInactive || a.IsActive },
+			func(a, b *models.AreaOfOperation) bool { return a.Name < b.Name })
+		return nil
+	})
+	return areas, err
+}
+
+func (r *areaOfOperationRepository) AddZone(ctx context.Context, areaOfOperationID, zoneID uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.areasOfOperation[areaOfOperationID]; !ok {
+			return foreignKeyViolation("area_of_operation_zones", "area_of_operation_zones", "area_of_operation_id")
+		}
+		if _, ok := t.zones[zoneID]; !ok {
+			return foreignKeyViolation("area_of_operation_zones", "area_of_operation_zones", "zone_id")
+		}
+		l := link{areaOfOperationID, zoneID}
+		if _, ok := t.aooZones[l]; !ok {
+			t.aooZones[l] = time.Now()
+		}
+		return nil
+	})
+}
+
+func (r *areaOfOperationRepository) RemoveZone(ctx context.Context, areaOfOperationID, zoneID uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.aooZones, link{areaOfOperationID, zoneID})
+		return nil
+	})
+}
+
+func (r *areaOfOperationRepository) GetZones(ctx context.Context, areaOfOperationID uuid.UUID) ([]*models.Zone, error) {
+	var zones []*models.Zone
+	err := r.store.read(ctx, func(t *tables) error {
+		zones = collect(t.zones,
+			func(z *models.Zone) bool {
+				_, ok := t.aooZones[link{areaOfOperationID, z.ID}]
+				return ok
+			},
+			func(a, b *models.Zone) bool { return a.Name < b.Name })
+		return nil
+	})
+	return zones, err
+}
+
+func (r *areaOfOperationRepository) AddBranch(ctx context.Context, areaOfOperationID, branchID uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.areasOfOperation[areaOfOperationID]; !ok {
+			return foreignKeyViolation("area_of_operation_branches", "area_of_operation_branches", "area_of_operation_id")
+		}
+		if _, ok := t.branches[branchID]; !ok {
+			return foreignKeyViolation("area_of_operation_branches", "area_of_operation_branches", "branch_id")
+		}
+		l := link{areaOfOperationID, branchID}
+		if _, ok := t.aooBranches[l]; !ok {
+			t.aooBranches[l] = time.Now()
+		}
+		return nil
+	})
+}
+
+func (r *areaOfOperationRepository) RemoveBranch(ctx context.Context, areaOfOperationID, branchID uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.aooBranches, link{areaOfOperationID, branchID})
+		return nil
+	})
+}
+
+func (r *areaOfOperationRepository) GetBranches(ctx context.Context, areaOfOperationID uuid.UUID) ([]*models.Branch, error) {
+	var branches []*models.Branch
+	err := r.store.read(ctx, func(t *tables) error {
+		branches = linkedBranches(t, t.aooBranches, areaOfOperationID)
+		return nil
+	})
+	return branches, err
+}
+
+func (r *areaOfOperationRepository) GetAllBranches(ctx context.Context, areaOfOperationID uuid.UUID) ([]*models.Branch, error) {
+	var branches []*models.Branch
+	err := r.store.read(ctx, func(t *tables) error {
+		branches = collect(t.branches,
+			func(b *models.Branch) bool {
+				if _, ok := t.aooBranches[link{areaOfOperationID, b.ID}]; ok {
+					return true
+				}
+				for l := range t.aooZones {
+					if l.left != areaOfOperationID {
+						continue
+					}
+					if _, ok := t.zoneBranches[link{l.right, b.ID}]; ok {
+						return true
+					}
+				}
+				return false
+			},
+			func(a, b *models.Branch) bool { return a.Name < b.Name })
+		for _, branch := range branches {
+			branch.BranchTypeID = nil
+		}
+		return nil
+	})
+	return branches, err
+}
+
+// ZoneRepository implementation
+type zoneRepository struct {
+	store *Store
+}
+
+func checkZoneCode(t *tables, zone *models.Zone) error {
+	for _, z := range t.zones {
+		if z.ID != zone.ID && z.Code == zone.Code {
+			return uniqueViolation("zones_code_key")
+		}
+	}
+	return nil
+}
+
+func (r *zoneRepository) Create(ctx context.Context, zone *models.Zone) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.zones[zone.ID]; ok {
+			return uniqueViolation("zones_pkey")
+		}
+		if err := checkZoneCode(t, zone); err != nil {
+			return err
+		}
+		now := time.Now()
+		zone.CreatedAt, zone.UpdatedAt = now, now
+		row := *zone
+		row.Branches = nil
+		t.zones[row.ID] = row
+		return nil
+	})
+}
+
+func (r *zoneRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Zone, error) {
+	var zone *models.Zone
+	err := r.store.read(ctx, func(t *tables) error {
+		if zone = get(t.zones, id); zone == nil {
+			return errNoRows
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return zone, nil
+}
+
+func (r *zoneRepository) GetByCode(ctx context.Context, code string) (*models.Zone, error) {
+	var zone *models.Zone
+	err := r.store.read(ctx, func(t *tables) error {
+		if zone = find(t.zones, func(z *models.Zone) bool { return z.Code == code }); zone == nil {
+			return errNoRows
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return zone, nil
+}
+
+func (r *zoneRepository) Update(ctx context.Context, zone *models.Zone) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.zones[zone.ID]
+		if !ok {
+			return errNoRows
+		}
+		if err := checkZoneCode(t, zone); err != nil {
+			return err
+		}
+		row.Name = zone.Name
+		row.Code = zone.Code
+		row.Description = zone.Description
+		row.IsActive = zone.IsActive
+		row.UpdatedAt = time.Now()
+		t.zones[row.ID] = row
+		zone.UpdatedAt = row.UpdatedAt
+		return nil
+	})
+}
+
+func (r *zoneRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if find(t.staff, func(s *models.Staff) bool { return sameUUID(s.ZoneID, &id) }) != nil {
+			return foreignKeyViolation("zones", "staff", "zone_id")
+		}
+		delete(t.zones, id)
+		deleteLinks(t.zoneBranches, func(l link) bool { return l.left == id })
+		deleteLinks(t.aooZones, func(l link) bool { return l.right == id })
+		return nil
+	})
+}
+
+func (r *zoneRepository) List(ctx context.Context, includeInactive bool) ([]*models.Zone, error) {
+	var zones []*models.Zone
+	err := r.store.read(ctx, func(t *tables) error {
+		zones = collect(t.zones,
+			func(z *models.Zone) bool { return includeInactive || z.IsActive },
+			func(a, b *models.Zone) bool { return a.Name < b.Name })
+		return nil
+	})
+	return zones, err
+}
+
+func (r *zoneRepository) AddBranch(ctx context.Context, zoneID, branchID uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.zones[zoneID]; !ok {
+			return foreignKeyViolation("zone_branches", "zone_branches", "zone_id")
+		}
+		if _, ok := t.branches[branchID]; !ok {
+			return foreignKeyViolation("zone_branches", "zone_branches", "branch_id")
+		}
+		l := link{zoneID, branchID}
+		if _, ok := t.zoneBranches[l]; !ok {
+			t.zoneBranches[l] = time.Now()
+		}
+		return nil
+	})
+}
+
+func (r *zoneRepository) RemoveBranch(ctx context.Context, zoneID, branchID uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.zoneBranches, link{zoneID, branchID})
+		return nil
+	})
+}
+
+func (r *zoneRepository) GetBranches(ctx context.Context, zoneID uuid.UUID) ([]*models.Branch, error) {
+	var branches []*models.Branch
+	err := r.store.read(ctx, func(t *tables) error {
+		branches = linkedBranches(t, t.zoneBranches, zoneID)
+		return nil
+	})
+	return branches, err
+}
+
+func (r *zoneRepository) BulkUpdateBranches(ctx context.Context, zoneID uuid.UUID, branchIDs []uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		for i, branchID := range branchIDs {
+			if _, ok := t.branches[branchID]; !ok {
+				return foreignKeyViolation("zone_branches", "zone_branches", "branch_id")
+			}
+			for _, prev := range branchIDs[:i] {
+				if prev == branchID {
+					return uniqueViolation("zone_branches_zone_id_branch_id_key")
+				}
+			}
+		}
+		deleteLinks(t.zoneBranches, func(l link) bool { return l.left == zoneID })
+		now := time.Now()
+		for _, branchID := range branchIDs {
+			t.zoneBranches[link{zoneID, branchID}] = now
+		}
+		return nil
+	})
+}
+
+var (
+	_ interfaces.AreaOfOperationRepository = (*areaOfOperationRepository)(nil)
+	_ interfaces.ZoneRepository            = (*zoneRepository)(nil)
+)
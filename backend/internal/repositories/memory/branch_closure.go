@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+)
+
+// BranchClosureRepository implementation
+type branchClosureRepository struct {
+	store *Store
+}
+
+// storeClosure detaches a closure for storage like a table row
+func storeClosure(closure *models.BranchClosure) models.BranchClosure {
+	row := *closure
+	row.BranchCode = ""
+	row.BranchID = copyUUID(closure.BranchID)
+	row.StartDate, row.EndDate = day(closure.StartDate), day(closure.EndDate)
+	return row
+}
+
+// withBranchCode fills the joined branch code and detaches the nullable branch
+func withBranchCode(t *tables, closure *models.BranchClosure) *models.BranchClosure {
+	closure.BranchID = copyUUID(closure.BranchID)
+	if closure.BranchID != nil {
+		closure.BranchCode = t.branches[*closure.BranchID].Code
+	}
+	return closure
+}
+
+func (r *branchClosureRepository) Create(ctx context.Context, closure *models.BranchClosure) error {
+	if closure.ID == uuid.Nil {
+		closure.ID = uuid.New()
+	}
+	return r.store.write(ctx, func(t *tables) error {
+		if closure.BranchID != nil {
+			if _, ok := t.branches[*closure.BranchID]; !ok {
+				return foreignKeyViolation("branches", "branch_closures", "branch_id")
+			}
+		}
+		now := time.Now()
+		closure.CreatedAt, closure.UpdatedAt = now, now
+		t.branchClosures[closure.ID] = storeClosure(closure)
+		return nil
+	})
+}
+
+func (r *branchClosureRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BranchClosure, error) {
+	var closure *models.BranchClosure
+	err := r.store.read(ctx, func(t *tables) error {
+		if closure = get(t.branchClosures, id); closure != nil {
+			withBranchCode(t, closure)
+		}
+		return nil
+	})
+	return closure, err
+}
+
+func (r *branchClosureRepository) List(ctx context.Context, filters interfaces.BranchClosureFilters) ([]*models.BranchClosure, error) {
+	closures := []*models.BranchClosure{}
+	err := r.store.read(ctx, func(t *tables) error {
+		rows := collect(t.branchClosures, func(c *models.BranchClosure) bool {
+			if filters.BranchID != nil && c.BranchID != nil && *c.BranchID != *filters.BranchID {
+				return false
+			}
+			if filters.StartDate != nil && c.EndDate.Before(day(*filters.StartDate)) {
+				return false
+			}
+			return filters.EndDate == nil || !c.StartDate.After(day(*filters.EndDate))
+		}, func(a, b *models.BranchClosure) bool {
+			if !a.StartDate.Equal(b.StartDate) {
+				return a.StartDate.Before(b.StartDate)
+			}
+			// Holidays sort first, like NULLS FIRST
+			if a.BranchID == nil || b.BranchID == nil {
+				return a.BranchID == nil && b.BranchID != nil
+			}
+			return t.branches[*a.BranchID].Code < t.branches[*b.BranchID].Code
+		})
+		for _, c := range rows {
+			closures = append(closures, withBranchCode(t, c))
+		}
+		return nil
+	})
+	return closures, err
+}
+
+func (r *branchClosureRepository) Update(ctx context.Context, closure *models.BranchClosure) error {
+	return r.store.write(ctx, func(t *tables) error {
+		existing, ok := t.branchClosures[closure.ID]
+		if !ok {
+			return errNoRows
+		}
+		if closure.BranchID != nil {
+			if _, ok := t.branches[*closure.BranchID]; !ok {
+				return foreignKeyViolation("branches", "branch_closures", "branch_id")
+			}
+		}
+		closure.UpdatedAt = time.Now()
+		row := storeClosure(closure)
+		row.CreatedBy, row.CreatedAt = existing.CreatedBy, existing.CreatedAt
+		t.branchClosures[closure.ID] = row
+		return nil
+	})
+}
+
+func (r *branchClosureRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.branchClosures, id)
+		return nil
+	})
+}
+
+var _ interfaces.BranchClosureRepository = (*branchClosureRepository)(nil)
@@ -0,0 +1,677 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// BranchWeeklyRevenueRepository implementation
+type branchWeeklyRevenueRepository struct {
+	store *Store
+}
+
+func findWeeklyRevenue(t *tables, branchID uuid.UUID, dayOfWeek int) *models.BranchWeeklyRevenue {
+	return find(t.branchWeeklyRevenue, func(w *models.BranchWeeklyRevenue) bool {
+		return w.BranchID == branchID && w.DayOfWeek == dayOfWeek
+	})
+}
+
+func (r *branchWeeklyRevenueRepository) Create(ctx context.Context, revenue *models.BranchWeeklyRevenue) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.branchWeeklyRevenue[revenue.ID]; ok {
+			return uniqueViolation("branch_weekly_revenue_pkey")
+		}
+		if _, ok := t.branches[revenue.BranchID]; !ok {
+			return foreignKeyViolation("branch_weekly_revenue", "branch_weekly_revenue", "branch_id")
+		}
+		if findWeeklyRevenue(t, revenue.BranchID, revenue.DayOfWeek) != nil {
+			return uniqueViolation("branch_weekly_revenue_branch_id_day_of_week_key")
+		}
+		now := time.Now()
+		revenue.CreatedAt, revenue.UpdatedAt = now, now
+		row := *revenue
+		row.Branch = nil
+		t.branchWeeklyRevenue[row.ID] = row
+		return nil
+	})
+}
+
+func (r *branchWeeklyRevenueRepository) Update(ctx context.Context, revenue *models.BranchWeeklyRevenue) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.branchWeeklyRevenue[revenue.ID]
+		if !ok {
+			return errNoRows
+		}
+		if other := findWeeklyRevenue(t, row.BranchID, revenue.DayOfWeek); other != nil && other.ID != row.ID {
+			return uniqueViolation("branch_weekly_revenue_branch_id_day_of_week_key")
+		}
+		row.DayOfWeek = revenue.DayOfWeek
+		row.ExpectedRevenue = revenue.ExpectedRevenue
+		row.SkinRevenue = revenue.SkinRevenue
+		row.LSHMRevenue = revenue.LSHMRevenue
+		row.VitaminCases = revenue.VitaminCases
+		row.SlimPenCases = revenue.SlimPenCases
+		row.UpdatedAt = time.Now()
+		t.branchWeeklyRevenue[row.ID] = row
+		revenue.UpdatedAt = row.UpdatedAt
+		return nil
+	})
+}
+
+func (r *branchWeeklyRevenueRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BranchWeeklyRevenue, error) {
+	var revenue *models.BranchWeeklyRevenue
+	err := r.store.read(ctx, func(t *tables) error {
+		revenue = get(t.branchWeeklyRevenue, id)
+		return nil
+	})
+	return revenue, err
+}
+
+func (r *branchWeeklyRevenueRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID) ([]*models.BranchWeeklyRevenue, error) {
+	var revenues []*models.BranchWeeklyRevenue
+	err := r.store.read(ctx, func(t *tables) error {
+		revenues = collect(t.branchWeeklyRevenue,
+			func(w *models.BranchWeeklyRevenue) bool { return w.BranchID == branchID },
+			func(a, b *models.BranchWeeklyRevenue) bool { return a.DayOfWeek < b.DayOfWeek })
+		return nil
+	})
+	return nonNil(revenues), err
+}
+
+func (r *branchWeeklyRevenueRepository) GetByBranchIDAndDayOfWeek(ctx context.Context, branchID uuid.UUID, dayOfWeek int) (*models.BranchWeeklyRevenue, error) {
+	var revenue *models.BranchWeeklyRevenue
+	err := r.store.read(ctx, func(t *tables) error {
+		revenue = findWeeklyRevenue(t, branchID, dayOfWeek)
+		return nil
+	})
+	return revenue, err
+}
+
+func (r *branchWeeklyRevenueRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.branchWeeklyRevenue, id)
+		return nil
+	})
+}
+
+func (r *branchWeeklyRevenueRepository) BulkUpsert(ctx context.Context, revenues []*models.BranchWeeklyRevenue) error {
+	return r.store.write(ctx, func(t *tables) error {
+		// Check every row before writing any, so a failure leaves nothing
+		// behind like the postgres transaction
+		for _, revenue := range revenues {
+			if _, ok := t.branches[revenue.BranchID]; !ok {
+				return foreignKeyViolation("branch_weekly_revenue", "branch_weekly_revenue", "branch_id")
+			}
+		}
+
+		now := time.Now()
+		for _, revenue := range revenues {
+			if revenue.ID == uuid.Nil {
+				revenue.ID = uuid.New()
+			}
+			if existing := findWeeklyRevenue(t, revenue.BranchID, revenue.DayOfWeek); existing != nil {
+				existing.ExpectedRevenue = revenue.ExpectedRevenue
+				existing.SkinRevenue = revenue.SkinRevenue
+				existing.LSHMRevenue = revenue.LSHMRevenue
+				existing.VitaminCases = revenue.VitaminCases
+				existing.SlimPenCases = revenue.SlimPenCases
+				existing.UpdatedAt = now
+				t.branchWeeklyRevenue[existing.ID] = *existing
+				revenue.CreatedAt, revenue.UpdatedAt = existing.CreatedAt, existing.UpdatedAt
+				continue
+			}
+			revenue.CreatedAt, revenue.UpdatedAt = now, now
+			row := *revenue
+			row.Branch = nil
+			t.branchWeeklyRevenue[row.ID] = row
+		}
+		return nil
+	})
+}
+
+// BranchConstraintsRepository implementation
+type branchConstraintsRepository struct {
+	store *Store
+}
+
+func findBranchConstraint(t *tables, branchID uuid.UUID, dayOfWeek int) *models.BranchConstraints {
+	return find(t.branchConstraints, func(c *models.BranchConstraints) bool {
+		return c.BranchID == branchID && c.DayOfWeek == dayOfWeek
+	})
+}
+
+// branchConstraintRow strips the loaded relations and detaches the nullable
+// branch type from the caller
+func branchConstraintRow(c *models.BranchConstraints) models.BranchConstraints {
+	row := *c
+	row.Branch = nil
+	row.StaffGroupRequirements = nil
+	row.InheritedFromBranchTypeID = copyUUID(c.InheritedFromBranchTypeID)
+	return row
+}
+
+// upsertBranchConstraint writes c on conflict (branch_id, day_of_week) and
+// returns the stored row
+func upsertBranchConstraint(t *tables, c *models.BranchConstraints, now time.Time) models.BranchConstraints {
+	row := branchConstraintRow(c)
+	if existing := findBranchConstraint(t, c.BranchID, c.DayOfWeek); existing != nil {
+		row.ID = existing.ID
+		row.CreatedAt = existing.CreatedAt
+	} else {
+		row.CreatedAt = now
+	}
+	row.UpdatedAt = now
+	t.branchConstraints[row.ID] = row
+	return row
+}
+
+func checkBranchConstraint(t *tables, c *models.BranchConstraints) error {
+	if _, ok := t.branches[c.BranchID]; !ok {
+		return foreignKeyViolation("branch_constraints", "branch_constraints", "branch_id")
+	}
+	if c.InheritedFromBranchTypeID != nil {
+		if _, ok := t.branchTypes[*c.InheritedFromBranchTypeID]; !ok {
+			return foreignKeyViolation("branch_constraints", "branch_constraints", "inherited_from_branch_type_id")
+		}
+	}
+	return nil
+}
+
+func (r *branchConstraintsRepository) Create(ctx context.Context, constraint *models.BranchConstraints) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.branchConstraints[constraint.ID]; ok {
+			return uniqueViolation("branch_constraints_pkey")
+		}
+		if err := checkBranchConstraint(t, constraint); err != nil {
+			return err
+		}
+		if findBranchConstraint(t, constraint.BranchID, constraint.DayOfWeek) != nil {
+			return uniqueViolation("branch_constraints_branch_id_day_of_week_key")
+		}
+		now := time.Now()
+		constraint.CreatedAt, constraint.UpdatedAt = now, now
+		t.branchConstraints[constraint.ID] = branchConstraintRow(constraint)
+		return nil
+	})
+}
+
+func (r *branchConstraintsRepository) Update(ctx context.Context, constraint *models.BranchConstraints) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.branchConstraints[constraint.ID]
+		if !ok {
+			return errNoRows
+		}
+		if constraint.InheritedFromBranchTypeID != nil {
+			if _, ok := t.branchTypes[*constraint.InheritedFromBranchTypeID]; !ok {
+				return foreignKeyViolation("branch_constraints", "branch_constraints", "inherited_from_branch_type_id")
+			}
+		}
+		row.MinFrontStaff = constraint.MinFrontStaff
+		row.MinManagers = constraint.MinManagers
+		row.MinDoctorAssistant = constraint.MinDoctorAssistant
+		row.MinTotalStaff = constraint.MinTotalStaff
+		row.InheritedFromBranchTypeID = copyUUID(constraint.InheritedFromBranchTypeID)
+		row.IsOverridden = constraint.IsOverridden
+		row.UpdatedAt = time.Now()
+		t.branchConstraints[row.ID] = row
+		constraint.UpdatedAt = row.UpdatedAt
+		return nil
+	})
+}
+
+func (r *branchConstraintsRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BranchConstraints, error) {
+	var constraint *models.BranchConstraints
+	err := r.store.read(ctx, func(t *tables) error {
+		if constraint = get(t.branchConstraints, id); constraint != nil {
+			constraint.InheritedFromBranchTypeID = copyUUID(constraint.InheritedFromBranchTypeID)
+		}
+		return nil
+	})
+	return constraint, err
+}
+
+func (r *branchConstraintsRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID) ([]*models.BranchConstraints, error) {
+	var constraints []*models.BranchConstraints
+	err := r.store.read(ctx, func(t *tables) error {
+		constraints = collect(t.branchConstraints,
+			func(c *models.BranchConstraints) bool { return c.BranchID == branchID },
+			func(a, b *models.BranchConstraints) bool { return a.DayOfWeek < b.DayOfWeek })
+		for _, c := range constraints {
+			c.InheritedFromBranchTypeID = copyUUID(c.InheritedFromBranchTypeID)
+		}
+		return nil
+	})
+	return constraints, err
+}
+
+func (r *branchConstraintsRepository) GetByBranchIDs(ctx context.Context, branchIDs []uuid.UUID) ([]*models.BranchConstraints, error) {
+	wanted := make(map[uuid.UUID]bool, len(branchIDs))
+	for _, id := range branchIDs {
+		wanted[id] = true
+	}
+	var constraints []*models.BranchConstraints
+	err := r.store.read(ctx, func(t *tables) error {
+		constraints = collect(t.branchConstraints,
+			func(c *models.BranchConstraints) bool { return wanted[c.BranchID] },
+			func(a, b *models.BranchConstraints) bool {
+				if a.BranchID != b.BranchID {
+					return uuidLess(a.BranchID, b.BranchID)
+				}
+				return a.DayOfWeek < b.DayOfWeek
+			})
+		for _, c := range constraints {
+			c.InheritedFromBranchTypeID = copyUUID(c.InheritedFromBranchTypeID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nonNil(constraints), nil
+}
+
+func (r *branchConstraintsRepository) GetByBranchIDAndDayOfWeek(ctx context.Context, branchID uuid.UUID, dayOfWeek int) (*models.BranchConstraints, error) {
+	var constraint *models.BranchConstraints
+	err := r.store.read(ctx, func(t *tables) error {
+		if constraint = findBranchConstraint(t, branchID, dayOfWeek); constraint != nil {
+			constraint.InheritedFromBranchTypeID = copyUUID(constraint.InheritedFromBranchTypeID)
+		}
+		return nil
+	})
+	return constraint, err
+}
+
+func (r *branchConstraintsRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.branchConstraints, id)
+		deleteWhere(t.constraintStaffGroups, func(sg *models.BranchConstraintStaffGroup) bool {
+			return sg.BranchConstraintID == id
+		})
+		return nil
+	})
+}
+
+func (r *branchConstraintsRepository) BulkUpsert(ctx context.Context, constraints []*models.BranchConstraints) error {
+	return r.store.write(ctx, func(t *tables) error {
+		for _, constraint := range constraints {
+			if err := checkBranchConstraint(t, constraint); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		for _, constraint := range constraints {
+			if constraint.ID == uuid.Nil {
+				constraint.ID = uuid.New()
+			}
+			row := upsertBranchConstraint(t, constraint, now)
+			constraint.CreatedAt, constraint.UpdatedAt = row.CreatedAt, row.UpdatedAt
+		}
+		return nil
+	})
+}
+
+func (r *branchConstraintsRepository) LoadStaffGroupRequirements(ctx context.Context, constraints []*models.BranchConstraints) error {
+	if len(constraints) == 0 {
+		return nil
+	}
+
+	constraintMap := make(map[uuid.UUID]*models.BranchConstraints)
+	for _, c := range constraints {
+		if c.ID != uuid.Nil {
+			constraintMap[c.ID] = c
+		}
+	}
+	if len(constraintMap) == 0 {
+		return nil
+	}
+
+	return r.store.read(ctx, func(t *tables) error {
+		groups := collect(t.constraintStaffGroups,
+			func(sg *models.BranchConstraintStaffGroup) bool { return constraintMap[sg.BranchConstraintID] != nil },
+			func(a, b *models.BranchConstraintStaffGroup) bool {
+				if a.BranchConstraintID != b.BranchConstraintID {
+					return uuidLess(a.BranchConstraintID, b.BranchConstraintID)
+				}
+				return uuidLess(a.StaffGroupID, b.StaffGroupID)
+			})
+		for _, sg := range groups {
+			constraint := constraintMap[sg.BranchConstraintID]
+			if constraint.StaffGroupRequirements == nil {
+				constraint.StaffGroupRequirements = make([]*models.BranchConstraintStaffGroup, 0)
+			}
+			constraint.StaffGroupRequirements = append(constraint.StaffGroupRequirements, sg)
+		}
+		return nil
+	})
+}
+
+func (r *branchConstraintsRepository) BulkUpsertWithStaffGroups(ctx context.Context, constraints []*models.BranchConstraints) error {
+	return r.store.write(ctx, func(t *tables) error {
+		for _, constraint := range constraints {
+			if err := checkBranchConstraint(t, constraint); err != nil {
+				return err
+			}
+			for i, sg := range constraint.StaffGroupRequirements {
+				if _, ok := t.staffGroups[sg.StaffGroupID]; !ok {
+					return foreignKeyViolation("branch_constraint_staff_groups", "branch_constraint_staff_groups", "staff_group_id")
+				}
+				for _, prev := range constraint.StaffGroupRequirements[:i] {
+					if prev.StaffGroupID == sg.StaffGroupID {
+						return uniqueViolation("branch_constraint_staff_groups_branch_constraint_id_staff_group_id_key")
+					}
+				}
+			}
+		}
+
+		now := time.Now()
+		for _, constraint := range constraints {
+			if constraint.ID == uuid.Nil {
+				constraint.ID = uuid.New()
+			}
+			// The deprecated minimums are always written as 0
+			constraint.MinFrontStaff, constraint.MinManagers = 0, 0
+			constraint.MinDoctorAssistant, constraint.MinTotalStaff = 0, 0
+			row := upsertBranchConstraint(t, constraint, now)
+			constraint.ID, constraint.CreatedAt, constraint.UpdatedAt = row.ID, row.CreatedAt, row.UpdatedAt
+
+			deleteWhere(t.constraintStaffGroups, func(sg *models.BranchConstraintStaffGroup) bool {
+				return sg.BranchConstraintID == constraint.ID
+			})
+			for _, sg := range constraint.StaffGroupRequirements {
+				if sg.ID == uuid.Nil {
+					sg.ID = uuid.New()
+				}
+				sg.BranchConstraintID = constraint.ID
+				sg.CreatedAt, sg.UpdatedAt = now, now
+				sgRow := *sg
+				sgRow.StaffGroup = nil
+				t.constraintStaffGroups[sgRow.ID] = sgRow
+			}
+		}
+		return nil
+	})
+}
+
+// BranchTypeConstraintsRepository implementation
+type branchTypeConstraintsRepository struct {
+	store *Store
+}
+
+func findBranchTypeConstraint(t *tables, branchTypeID uuid.UUID, dayOfWeek int) *models.BranchTypeConstraints {
+	return find(t.branchTypeConstraints, func(c *models.BranchTypeConstraints) bool {
+		return c.BranchTypeID == branchTypeID && c.DayOfWeek == dayOfWeek
+	})
+}
+
+func (r *branchTypeConstraintsRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BranchTypeConstraints, error) {
+	var constraint *models.BranchTypeConstraints
+	err := r.store.read(ctx, func(t *tables) error {
+		constraint = get(t.branchTypeConstraints, id)
+		return nil
+	})
+	return constraint, err
+}
+
+func (r *branchTypeConstraintsRepository) GetByBranchTypeID(ctx context.Context, branchTypeID uuid.UUID) ([]*models.BranchTypeConstraints, error) {
+	var constraints []*models.BranchTypeConstraints
+	err := r.store.read(ctx, func(t *tables) error {
+		constraints = collect(t.branchTypeConstraints,
+			func(c *models.BranchTypeConstraints) bool { return c.BranchTypeID == branchTypeID },
+			func(a, b *models.BranchTypeConstraints) bool { return a.DayOfWeek < b.DayOfWeek })
+		return nil
+	})
+	return constraints, err
+}
+
+func (r *branchTypeConstraintsRepository) List(ctx context.Context) ([]*models.BranchTypeConstraints, error) {
+	var constraints []*models.BranchTypeConstraints
+	err := r.store.read(ctx, func(t *tables) error {
+		constraints = collect(t.branchTypeConstraints, nil,
+			func(a, b *models.BranchTypeConstraints) bool {
+				if a.BranchTypeID != b.BranchTypeID {
+					return uuidLess(a.BranchTypeID, b.BranchTypeID)
+				}
+				return a.DayOfWeek < b.DayOfWeek
+			})
+		return nil
+	})
+	return constraints, err
+}
+
+func (r *branchTypeConstraintsRepository) GetByBranchTypeIDAndDayOfWeek(ctx context.Context, branchTypeID uuid.UUID, dayOfWeek int) (*models.BranchTypeConstraints, error) {
+	var constraint *models.BranchTypeConstraints
+	err := r.store.read(ctx, func(t *tables) error {
+		constraint = findBranchTypeConstraint(t, branchTypeID, dayOfWeek)
+		return nil
+	})
+	return constraint, err
+}
+
+func (r *branchTypeConstraintsRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.branchTypeConstraints, id)
+		deleteWhere(t.typeConstraintGroups, func(sg *models.BranchTypeConstraintStaffGroup) bool {
+			return sg.BranchTypeConstraintID == id
+		})
+		return nil
+	})
+}
+
+func (r *branchTypeConstraintsRepository) LoadStaffGroupRequirements(ctx context.Context, constraints []*models.BranchTypeConstraints) error {
+	if len(constraints) == 0 {
+		return nil
+	}
+
+	constraintMap := make(map[uuid.UUID]*models.BranchTypeConstraints)
+	for _, c := range constraints {
+		if c.ID != uuid.Nil {
+			constraintMap[c.ID] = c
+		}
+	}
+	if len(constraintMap) == 0 {
+		return nil
+	}
+
+	return r.store.read(ctx, func(t *tables) error {
+		groups := collect(t.typeConstraintGroups,
+			func(sg *models.BranchTypeConstraintStaffGroup) bool {
+				return constraintMap[sg.BranchTypeConstraintID] != nil
+			},
+			func(a, b *models.BranchTypeConstraintStaffGroup) bool {
+				if a.BranchTypeConstraintID != b.BranchTypeConstraintID {
+					return uuidLess(a.BranchTypeConstraintID, b.BranchTypeConstraintID)
+				}
+				return uuidLess(a.StaffGroupID, b.StaffGroupID)
+			})
+		for _, sg := range groups {
+			constraint := constraintMap[sg.BranchTypeConstraintID]
+			if constraint.StaffGroupRequirements == nil {
+				constraint.StaffGroupRequirements = make([]*models.BranchTypeConstraintStaffGroup, 0)
+			}
+			constraint.StaffGroupRequirements = append(constraint.StaffGroupRequirements, sg)
+		}
+		return nil
+	})
+}
+
+func (r *branchTypeConstraintsRepository) BulkUpsertWithStaffGroups(ctx context.Context, constraints []*models.BranchTypeConstraints) error {
+	return r.store.write(ctx, func(t *tables) error {
+		for _, constraint := range constraints {
+			if _, ok := t.branchTypes[constraint.BranchTypeID]; !ok {
+				return foreignKeyViolation("branch_type_constraints", "branch_type_constraints", "branch_type_id")
+			}
+			for i, sg := range constraint.StaffGroupRequirements {
+				if _, ok := t.staffGroups[sg.StaffGroupID]; !ok {
+					return foreignKeyViolation("branch_type_constraint_staff_groups", "branch_type_constraint_staff_groups", "staff_group_id")
+				}
+				for _, prev := range constraint.StaffGroupRequirements[:i] {
+					if prev.StaffGroupID == sg.StaffGroupID {
+						return uniqueViolation("branch_type_constraint_staff_groups_branch_type_constraint_id_staff_group_id_key")
+					}
+				}
+			}
+		}
+
+		now := time.Now()
+		for _, constraint := range constraints {
+			if constraint.ID == uuid.Nil {
+				constraint.ID = uuid.New()
+			}
+			row := models.BranchTypeConstraints{
+				ID:           constraint.ID,
+				BranchTypeID: constraint.BranchTypeID,
+				DayOfWeek:    constraint.DayOfWeek,
+				CreatedAt:    now,
+			}
+			if existing := findBranchTypeConstraint(t, constraint.BranchTypeID, constraint.DayOfWeek); existing != nil {
+				row.ID, row.CreatedAt = existing.ID, existing.CreatedAt
+			}
+			row.UpdatedAt = now
+			t.branchTypeConstraints[row.ID] = row
+			constraint.ID, constraint.CreatedAt, constraint.UpdatedAt = row.ID, row.CreatedAt, row.UpdatedAt
+
+			deleteWhere(t.typeConstraintGroups, func(sg *models.BranchTypeConstraintStaffGroup) bool {
+				return sg.BranchTypeConstraintID == constraint.ID
+			})
+			for _, sg := range constraint.StaffGroupRequirements {
+				if sg.ID == uuid.Nil {
+					sg.ID = uuid.New()
+				}
+				sg.BranchTypeConstraintID = constraint.ID
+				sg.CreatedAt, sg.UpdatedAt = now, now
+				sgRow := *sg
+				sgRow.StaffGroup = nil
+				t.typeConstraintGroups[sgRow.ID] = sgRow
+			}
+		}
+		return nil
+	})
+}
+
+// RevenueLevelTierRepository implementation
+type revenueLevelTierRepository struct {
+	store *Store
+}
+
+func tierRow(tier *models.RevenueLevelTier) models.RevenueLevelTier {
+	row := *tier
+	row.MaxRevenue = copyPtr(tier.MaxRevenue)
+	row.ColorCode = copyPtr(tier.ColorCode)
+	row.Description = copyPtr(tier.Description)
+	return row
+}
+
+func (r *revenueLevelTierRepository) Create(ctx context.Context, tier *models.RevenueLevelTier) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.revenueLevelTiers[tier.ID]; ok {
+			return uniqueViolation("revenue_level_tiers_pkey")
+		}
+		if find(t.revenueLevelTiers, func(l *models.RevenueLevelTier) bool { return l.LevelNumber == tier.LevelNumber }) != nil {
+			return uniqueViolation("revenue_level_tiers_level_number_key")
+		}
+		now := time.Now()
+		tier.CreatedAt, tier.UpdatedAt = now, now
+		t.revenueLevelTiers[tier.ID] = tierRow(tier)
+		return nil
+	})
+}
+
+func (r *revenueLevelTierRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.RevenueLevelTier, error) {
+	var tier *models.RevenueLevelTier
+	err := r.store.read(ctx, func(t *tables) error {
+		if row, ok := t.revenueLevelTiers[id]; ok {
+			row = tierRow(&row)
+			tier = &row
+		}
+		return nil
+	})
+	return tier, err
+}
+
+func (r *revenueLevelTierRepository) GetByLevelNumber(ctx context.Context, levelNumber int) (*models.RevenueLevelTier, error) {
+	var tier *models.RevenueLevelTier
+	err := r.store.read(ctx, func(t *tables) error {
+		if found := find(t.revenueLevelTiers, func(l *models.RevenueLevelTier) bool { return l.LevelNumber == levelNumber }); found != nil {
+			row := tierRow(found)
+			tier = &row
+		}
+		return nil
+	})
+	return tier, err
+}
+
+func (r *revenueLevelTierRepository) Update(ctx context.Context, tier *models.RevenueLevelTier) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.revenueLevelTiers[tier.ID]
+		if !ok {
+			return errNoRows
+		}
+		row.LevelName = tier.LevelName
+		row.MinRevenue = tier.MinRevenue
+		row.MaxRevenue = copyPtr(tier.MaxRevenue)
+		row.DisplayOrder = tier.DisplayOrder
+		row.ColorCode = copyPtr(tier.ColorCode)
+		row.Description = copyPtr(tier.Description)
+		row.UpdatedAt = time.Now()
+		t.revenueLevelTiers[row.ID] = row
+		tier.UpdatedAt = row.UpdatedAt
+		return nil
+	})
+}
+
+func (r *revenueLevelTierRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if find(t.scenarios, func(s *models.StaffRequirementScenario) bool { return sameUUID(s.RevenueLevelTierID, &id) }) != nil {
+			return foreignKeyViolation("revenue_level_tiers", "staff_requirement_scenarios", "revenue_level_tier_id")
+		}
+		delete(t.revenueLevelTiers, id)
+		return nil
+	})
+}
+
+func (r *revenueLevelTierRepository) List(ctx context.Context) ([]*models.RevenueLevelTier, error) {
+	var tiers []*models.RevenueLevelTier
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, tier := range collect(t.revenueLevelTiers, nil, func(a, b *models.RevenueLevelTier) bool {
+			if a.DisplayOrder != b.DisplayOrder {
+				return a.DisplayOrder < b.DisplayOrder
+			}
+			return a.LevelNumber < b.LevelNumber
+		}) {
+			row := tierRow(tier)
+			tiers = append(tiers, &row)
+		}
+		return nil
+	})
+	return tiers, err
+}
+
+func (r *revenueLevelTierRepository) GetTierForRevenue(ctx context.Context, revenue float64) (*models.RevenueLevelTier, error) {
+	var tier *models.RevenueLevelTier
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, l := range t.revenueLevelTiers {
+			if revenue < l.MinRevenue || (l.MaxRevenue != nil && revenue >= *l.MaxRevenue) {
+				continue
+			}
+			if tier == nil || l.LevelNumber > tier.LevelNumber {
+				row := tierRow(&l)
+				tier = &row
+			}
+		}
+		return nil
+	})
+	return tier, err
+}
+
+var (
+	_ interfaces.BranchWeeklyRevenueRepository   = (*branchWeeklyRevenueRepository)(nil)
+	_ interfaces.BranchConstraintsRepository     = (*branchConstraintsRepository)(nil)
+	_ interfaces.BranchTypeConstraintsRepository = (*branchTypeConstraintsRepository)(nil)
+	_ interfaces.RevenueLevelTierRepository      = (*revenueLevelTierRepository)(nil)
+)
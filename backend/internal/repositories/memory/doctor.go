@@ -0,0 +1,1236 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+	"vsq-oper-manpower/backend/internal/usecases/doctor"
+
+	"github.com/google/uuid"
+)
+
+// DoctorRepository implementation
+type doctorRepository struct {
+	store *Store
+}
+
+// checkDoctorCode enforces the unique code; an empty code is stored as NULL
+// and never conflicts
+func checkDoctorCode(t *tables, d *models.Doctor) error {
+	if d.Code == "" {
+		return nil
+	}
+	for _, other := range t.doctors {
+		if other.ID != d.ID && other.Code == d.Code {
+			return uniqueViolation("doctors_code_key")
+		}
+	}
+	return nil
+}
+
+func (r *doctorRepository) Create(ctx context.Context, d *models.Doctor) error {
+	d.ID = uuid.New()
+	d.CreatedAt = time.Now()
+	d.UpdatedAt = time.Now()
+	return r.store.write(ctx, func(t *tables) error {
+		if err := checkDoctorCode(t, d); err != nil {
+			return err
+		}
+		t.doctors[d.ID] = *d
+		return nil
+	})
+}
+
+func (r *doctorRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Doctor, error) {
+	var d *models.Doctor
+	err := r.store.read(ctx, func(t *tables) error {
+		d = get(t.doctors, id)
+		return nil
+	})
+	return d, err
+}
+
+func (r *doctorRepository) GetByCode(ctx context.Context, code string) (*models.Doctor, error) {
+	var d *models.Doctor
+	err := r.store.read(ctx, func(t *tables) error {
+		if code != "" {
+			d = find(t.doctors, func(d *models.Doctor) bool { return d.Code == code })
+		}
+		return nil
+	})
+	return d, err
+}
+
+func (r *doctorRepository) Update(ctx context.Context, d *models.Doctor) error {
+	d.UpdatedAt = time.Now()
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.doctors[d.ID]
+		if !ok {
+			return nil
+		}
+		if err := checkDoctorCode(t, d); err != nil {
+			return err
+		}
+		row.Name = d.Name
+		row.Code = d.Code
+		row.Preferences = d.Preferences
+		row.UpdatedAt = d.UpdatedAt
+		t.doctors[row.ID] = row
+		return nil
+	})
+}
+
+func (r *doctorRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.doctors, id)
+		deleteWhere(t.doctorPreferences, func(p *models.DoctorPreference) bool { return p.DoctorID == id })
+		deleteWhere(t.doctorAssignments, func(a *models.DoctorAssignment) bool { return a.DoctorID == id })
+		deleteWhere(t.doctorDefaultSchedules, func(s *models.DoctorDefaultSchedule) bool { return s.DoctorID == id })
+		deleteWhere(t.doctorWeeklyOffDays, func(o *models.DoctorWeeklyOffDay) bool { return o.DoctorID == id })
+		deleteWhere(t.doctorOverrides, func(o *models.DoctorScheduleOverride) bool { return o.DoctorID == id })
+		deleteWhere(t.doctorRevenueProfiles, func(p *models.DoctorRevenueProfile) bool { return p.DoctorID == id })
+		deleteWhere(t.doctorRevenueOverrides, func(o *models.DoctorRevenueOverride) bool { return o.DoctorID == id })
+		for leaveID, l := range t.doctorLeaves {
+			if l.DoctorID == id {
+				delete(t.doctorLeaves, leaveID)
+				deleteWhere(t.doctorLeaveSubstitutes, func(s *models.DoctorLeaveSubstitute) bool { return s.LeaveID == leaveID })
+			}
+		}
+		deleteWhere(t.doctorLeaveSubstitutes, func(s *models.DoctorLeaveSubstitute) bool { return s.DoctorID == id })
+		deleteWhere(t.doctorRosterChanges, func(c *models.DoctorRosterChange) bool { return c.DoctorID == id })
+		deleteWhere(t.specificPreferences, func(p *models.SpecificPreference) bool { return sameUUID(p.DoctorID, &id) })
+		for sid, s := range t.scenarios {
+			if sameUUID(s.DoctorID, &id) {
+				s.DoctorID = nil
+				t.scenarios[sid] = s
+			}
+		}
+		return nil
+	})
+}
+
+func (r *doctorRepository) List(ctx context.Context) ([]*models.Doctor, error) {
+	var doctors []*models.Doctor
+	err := r.store.read(ctx, func(t *tables) error {
+		doctors = collect(t.doctors, nil, func(a, b *models.Doctor) bool { return a.Name < b.Name })
+		return nil
+	})
+	return doctors, err
+}
+
+// DoctorPreferenceRepository implementation
+type doctorPreferenceRepository struct {
+	store *Store
+}
+
+// storePreference round-trips the rule config through JSON, so stored rows
+// never alias the caller's map and numbers come back as float64 like JSONB
+func storePreference(p *models.DoctorPreference) (models.DoctorPreference, error) {
+	row := *p
+	row.Doctor, row.Branch = nil, nil
+	row.BranchID = copyUUID(p.BranchID)
+	raw, err := json.Marshal(p.RuleConfig)
+	if err != nil {
+		return row, fmt.Errorf("failed to marshal rule_config: %w", err)
+	}
+	row.RuleConfig = nil
+	if err := json.Unmarshal(raw, &row.RuleConfig); err != nil {
+		return row, fmt.Errorf("failed to unmarshal rule_config: %w", err)
+	}
+	return row, nil
+}
+
+// loadPreference detaches the rule config of a row read from the store
+func loadPreference(p *models.DoctorPreference) *models.DoctorPreference {
+	row, err := storePreference(p)
+	if err != nil {
+		return p
+	}
+	return &row
+}
+
+func (r *doctorPreferenceRepository) list(ctx context.Context, keep func(p *models.DoctorPreference) bool) ([]*models.DoctorPreference, error) {
+	var preferences []*models.DoctorPreference
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, p := range collect(t.doctorPreferences, keep, func(a, b *models.DoctorPreference) bool {
+			return a.CreatedAt.After(b.CreatedAt)
+		}) {
+			preferences = append(preferences, loadPreference(p))
+		}
+		return nil
+	})
+	return preferences, err
+}
+
+func (r *doctorPreferenceRepository) Create(ctx context.Context, preference *models.DoctorPreference) error {
+	preference.ID = uuid.New()
+	preference.CreatedAt = time.Now()
+	preference.UpdatedAt = time.Now()
+
+	row, err := storePreference(preference)
+	if err != nil {
+		return err
+	}
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.doctors[row.DoctorID]; !ok {
+			return foreignKeyViolation("doctor_preferences", "doctor_preferences", "doctor_id")
+		}
+		t.doctorPreferences[row.ID] = row
+		return nil
+	})
+}
+
+func (r *doctorPreferenceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorPreference, error) {
+	var preference *models.DoctorPreference
+	err := r.store.read(ctx, func(t *tables) error {
+		if p := get(t.doctorPreferences, id); p != nil {
+			preference = loadPreference(p)
+		}
+		return nil
+	})
+	return preference, err
+}
+
+func (r *doctorPreferenceRepository) GetByDoctorID(ctx context.Context, doctorID uuid.UUID) ([]*models.DoctorPreference, error) {
+	return r.list(ctx, func(p *models.DoctorPreference) bool { return p.DoctorID == doctorID })
+}
+
+func (r *doctorPreferenceRepository) GetByDoctorAndBranch(ctx context.Context, doctorID uuid.UUID, branchID uuid.UUID) ([]*models.DoctorPreference, error) {
+	return r.list(ctx, func(p *models.DoctorPreference) bool {
+		return p.DoctorID == doctorID && (p.BranchID == nil || *p.BranchID == branchID)
+	})
+}
+
+func (r *doctorPreferenceRepository) GetActiveByDoctorID(ctx context.Context, doctorID uuid.UUID) ([]*models.DoctorPreference, error) {
+	return r.list(ctx, func(p *models.DoctorPreference) bool { return p.DoctorID == doctorID && p.IsActive })
+}
+
+func (r *doctorPreferenceRepository) GetActiveByRuleType(ctx context.Context, ruleType string) ([]*models.DoctorPreference, error) {
+	return r.list(ctx, func(p *models.DoctorPreference) bool { return p.RuleType == ruleType && p.IsActive })
+}
+
+func (r *doctorPreferenceRepository) GetRotationStaffRequirements(ctx context.Context, doctorID uuid.UUID, branchID *uuid.UUID, dayOfWeek *int) ([]*models.DoctorPreference, error) {
+	return r.list(ctx, func(p *models.DoctorPreference) bool {
+		if p.DoctorID != doctorID || p.RuleType != "rotation_staff_requirement" || !p.IsActive {
+			return false
+		}
+		if branchID != nil && p.BranchID != nil && *p.BranchID != *branchID {
+			return false
+		}
+		// Filter by day of week if specified
+		if dayOfWeek != nil {
+			if daysOfWeek, ok := p.RuleConfig["days_of_week"].([]interface{}); ok {
+				for _, day := range daysOfWeek {
+					if dayInt, ok := day.(float64); ok && int(dayInt) == *dayOfWeek {
+						return true
+					}
+				}
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func (r *doctorPreferenceRepository) Update(ctx context.Context, preference *models.DoctorPreference) error {
+	preference.UpdatedAt = time.Now()
+
+	row, err := storePreference(preference)
+	if err != nil {
+		return err
+	}
+	return r.store.write(ctx, func(t *tables) error {
+		existing, ok := t.doctorPreferences[row.ID]
+		if !ok {
+			return nil
+		}
+		row.CreatedAt = existing.CreatedAt
+		t.doctorPreferences[row.ID] = row
+		return nil
+	})
+}
+
+func (r *doctorPreferenceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.doctorPreferences, id)
+		return nil
+	})
+}
+
+func (r *doctorPreferenceRepository) DeleteByDoctorID(ctx context.Context, doctorID uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		deleteWhere(t.doctorPreferences, func(p *models.DoctorPreference) bool { return p.DoctorID == doctorID })
+		return nil
+	})
+}
+
+// DoctorAssignmentRepository implementation. Like the postgres repository,
+// dates inside the roster window are read from the materialized roster and
+// other dates are calculated from the doctor schedules.
+type doctorAssignmentRepository struct {
+	store               *Store
+	defaultScheduleRepo interfaces.DoctorDefaultScheduleRepository
+	weeklyOffDayRepo    interfaces.DoctorWeeklyOffDayRepository
+	overrideRepo        interfaces.DoctorScheduleOverrideRepository
+	doctorRepo          interfaces.DoctorRepository
+	revenueProfileRepo  interfaces.DoctorRevenueProfileRepository
+	revenueOverrideRepo interfaces.DoctorRevenueOverrideRepository
+	leaveRepo           interfaces.DoctorLeaveRepository
+	rosterRepo          interfaces.DoctorRosterRepository
+	calendar            *doctor.DoctorCalendar // nil calculates every read afresh
+}
+
+func newDoctorAssignmentRepository(
+	store *Store,
+	defaultScheduleRepo interfaces.DoctorDefaultScheduleRepository,
+	weeklyOffDayRepo interfaces.DoctorWeeklyOffDayRepository,
+	overrideRepo interfaces.DoctorScheduleOverrideRepository,
+	doctorRepo interfaces.DoctorRepository,
+	revenueProfileRepo interfaces.DoctorRevenueProfileRepository,
+	revenueOverrideRepo interfaces.DoctorRevenueOverrideRepository,
+	leaveRepo interfaces.DoctorLeaveRepository,
+	rosterRepo interfaces.DoctorRosterRepository,
+) interfaces.DoctorAssignmentRepository {
+	return &doctorAssignmentRepository{
+		store:               store,
+		defaultScheduleRepo: defaultScheduleRepo,
+		weeklyOffDayRepo:    weeklyOffDayRepo,
+		overrideRepo:        overrideRepo,
+		doctorRepo:          doctorRepo,
+		revenueProfileRepo:  revenueProfileRepo,
+		revenueOverrideRepo: revenueOverrideRepo,
+		leaveRepo:           leaveRepo,
+		rosterRepo:          rosterRepo,
+	}
+}
+
+func (r *doctorAssignmentRepository) calculator() *doctor.DoctorScheduleCalculator {
+	return doctor.NewDoctorScheduleCalculator(
+		r.defaultScheduleRepo,
+		r.weeklyOffDayRepo,
+		r.overrideRepo,
+		r.doctorRepo,
+	).WithRevenue(r.revenueProfileRepo, r.revenueOverrideRepo).
+		WithLeaves(r.leaveRepo)
+}
+
+// UseCalendar serves calculated assignments from calendar, as the postgres
+// repository does
+func (r *doctorAssignmentRepository) UseCalendar(calendar *doctor.DoctorCalendar) {
+	r.calendar = calendar
+}
+
+// calculated returns the assignments calculated from the doctors' schedules
+func (r *doctorAssignmentRepository) calculated(ctx context.Context, startDate, endDate time.Time, filter doctor.RosterFilter) ([]*models.DoctorAssignment, error) {
+	if r.calendar != nil {
+		return r.calendar.Assignments(ctx, startDate, endDate, filter)
+	}
+	return r.calculator().Assignments(ctx, startDate, endDate, filter)
+}
+
+// storeAssignment detaches an assignment for storage like a table row
+func storeAssignment(assignment *models.DoctorAssignment) models.DoctorAssignment {
+	if assignment.Source == "" {
+		assignment.Source = "default"
+	}
+	assignment.Slot = models.DoctorSlotOrFullDay(assignment.Slot)
+	row := *assignment
+	row.Doctor, row.Branch = nil, nil
+	row.DoctorName, row.DoctorCode = "", ""
+	row.Date = day(assignment.Date)
+	if assignment.Revenue != nil {
+		revenue := *assignment.Revenue
+		row.Revenue = &revenue
+	}
+	return row
+}
+
+// loadAssignment joins the doctor's name and code onto a stored row
+func loadAssignment(t *tables, assignment *models.DoctorAssignment) *models.DoctorAssignment {
+	if d, ok := t.doctors[assignment.DoctorID]; ok {
+		assignment.DoctorName = d.Name
+		assignment.DoctorCode = d.Code
+	}
+	if assignment.Revenue != nil {
+		revenue := *assignment.Revenue
+		assignment.Revenue = &revenue
+	}
+	return assignment
+}
+
+func (r *doctorAssignmentRepository) Create(ctx context.Context, assignment *models.DoctorAssignment) error {
+	if assignment.ID == uuid.Nil {
+		assignment.ID = uuid.New()
+	}
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.doctorAssignments[assignment.ID]; ok {
+			return uniqueViolation("doctor_assignments_pkey")
+		}
+		if _, ok := t.doctors[assignment.DoctorID]; !ok {
+			return foreignKeyViolation("doctor_assignments", "doctor_assignments", "doctor_id")
+		}
+		if _, ok := t.branches[assignment.BranchID]; !ok {
+			return foreignKeyViolation("doctor_assignments", "doctor_assignments", "branch_id")
+		}
+		date := day(assignment.Date)
+		slot := models.DoctorSlotOrFullDay(assignment.Slot)
+		if find(t.doctorAssignments, func(a *models.DoctorAssignment) bool {
+			return a.DoctorID == assignment.DoctorID && a.Date.Equal(date) && a.Slot == slot
+		}) != nil {
+			return uniqueViolation("doctor_assignments_doctor_id_date_slot_key")
+		}
+		now := time.Now()
+		assignment.CreatedAt, assignment.UpdatedAt = now, now
+		row := storeAssignment(assignment)
+		t.doctorAssignments[row.ID] = row
+		return nil
+	})
+}
+
+func (r *doctorAssignmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorAssignment, error) {
+	var assignment *models.DoctorAssignment
+	err := r.store.read(ctx, func(t *tables) error {
+		if assignment = get(t.doctorAssignments, id); assignment != nil {
+			loadAssignment(t, assignment)
+		}
+		return nil
+	})
+	return assignment, err
+}
+
+// stored reads the roster rows between startDate and endDate that filter
+// matches, ordered like the postgres repository by date, doctor name and slot
+func (r *doctorAssignmentRepository) stored(ctx context.Context, startDate, endDate time.Time, filter doctor.RosterFilter) ([]*models.DoctorAssignment, error) {
+	assignments := []*models.DoctorAssignment{}
+	err := r.store.read(ctx, func(t *tables) error {
+		rows := collect(t.doctorAssignments, func(a *models.DoctorAssignment) bool {
+			_, ok := t.doctors[a.DoctorID]
+			return ok && inRange(a.Date, startDate, endDate) && filter.Matches(a.DoctorID, a.BranchID)
+		}, func(a, b *models.DoctorAssignment) bool {
+			if !a.Date.Equal(b.Date) {
+				return a.Date.Before(b.Date)
+			}
+			if nameA, nameB := t.doctors[a.DoctorID].Name, t.doctors[b.DoctorID].Name; nameA != nameB {
+				return nameA < nameB
+			}
+			return a.Slot > b.Slot
+		})
+		for _, row := range rows {
+			assignments = append(assignments, loadAssignment(t, row))
+		}
+		return nil
+	})
+	return assignments, err
+}
+
+// list reads the dates inside the roster window from the roster and
+// calculates the rest
+func (r *doctorAssignmentRepository) list(ctx context.Context, startDate, endDate time.Time, filter doctor.RosterFilter) ([]*models.DoctorAssignment, error) {
+	window, err := r.rosterRepo.Window(ctx)
+	if err != nil {
+		return nil, err
+	}
+	assignments := []*models.DoctorAssignment{}
+	for _, segment := range doctor.SplitRosterRange(window, startDate, endDate) {
+		var part []*models.DoctorAssignment
+		if segment.Materialized {
+			part, err = r.stored(ctx, segment.StartDate, segment.EndDate, filter)
+		} else {
+			part, err = r.calculated(ctx, segment.StartDate, segment.EndDate, filter)
+		}
+		if err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, part...)
+	}
+	return assignments, nil
+}
+
+func (r *doctorAssignmentRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorAssignment, error) {
+	return r.list(ctx, startDate, endDate, doctor.RosterFilter{BranchID: &branchID})
+}
+
+func (r *doctorAssignmentRepository) GetByDate(ctx context.Context, date time.Time) ([]*models.DoctorAssignment, error) {
+	return r.list(ctx, date, date, doctor.RosterFilter{})
+}
+
+func (r *doctorAssignmentRepository) GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.DoctorAssignment, error) {
+	return r.list(ctx, startDate, endDate, doctor.RosterFilter{})
+}
+
+func (r *doctorAssignmentRepository) GetRosterByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.DoctorAssignment, error) {
+	return r.stored(ctx, startDate, endDate, doctor.RosterFilter{})
+}
+
+func (r *doctorAssignmentRepository) GetByDoctorID(ctx context.Context, doctorID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorAssignment, error) {
+	return r.list(ctx, startDate, endDate, doctor.RosterFilter{DoctorID: &doctorID})
+}
+
+func (r *doctorAssignmentRepository) Update(ctx context.Context, assignment *models.DoctorAssignment) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.doctorAssignments[assignment.ID]
+		if !ok {
+			return errNoRows
+		}
+		if _, ok := t.branches[assignment.BranchID]; !ok {
+			return foreignKeyViolation("doctor_assignments", "doctor_assignments", "branch_id")
+		}
+		updated := storeAssignment(assignment)
+		row.BranchID = updated.BranchID
+		row.ExpectedRevenue = updated.ExpectedRevenue
+		row.Source = updated.Source
+		row.Revenue = updated.Revenue
+		row.RevenueSource = updated.RevenueSource
+		row.UpdatedAt = time.Now()
+		t.doctorAssignments[row.ID] = row
+		assignment.UpdatedAt = row.UpdatedAt
+		return nil
+	})
+}
+
+func (r *doctorAssignmentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.doctorAssignments, id)
+		return nil
+	})
+}
+
+func (r *doctorAssignmentRepository) GetDoctorCountByBranch(ctx context.Context, branchID uuid.UUID, date time.Time) (int, error) {
+	assignments, err := r.GetDoctorsByBranchAndDate(ctx, branchID, date)
+	if err != nil {
+		return 0, err
+	}
+	return doctor.CountDoctors(assignments), nil
+}
+
+func (r *doctorAssignmentRepository) GetMonthlySchedule(ctx context.Context, doctorID uuid.UUID, year int, month int) ([]*models.DoctorAssignment, error) {
+	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	endDate := startDate.AddDate(0, 1, -1)
+	return r.GetByDoctorID(ctx, doctorID, startDate, endDate)
+}
+
+func (r *doctorAssignmentRepository) DeleteByDoctorBranchDate(ctx context.Context, doctorID uuid.UUID, branchID uuid.UUID, date time.Time) error {
+	return r.store.write(ctx, func(t *tables) error {
+		date := day(date)
+		deleteWhere(t.doctorAssignments, func(a *models.DoctorAssignment) bool {
+			return a.DoctorID == doctorID && a.BranchID == branchID && a.Date.Equal(date)
+		})
+		return nil
+	})
+}
+
+func (r *doctorAssignmentRepository) GetDoctorsByBranchAndDate(ctx context.Context, branchID uuid.UUID, date time.Time) ([]*models.DoctorAssignment, error) {
+	return r.list(ctx, date, date, doctor.RosterFilter{BranchID: &branchID})
+}
+
+// DoctorOnOffDayRepository implementation
+type doctorOnOffDayRepository struct {
+	store *Store
+}
+
+func (r *doctorOnOffDayRepository) Create(ctx context.Context, onOff *models.DoctorOnOffDay) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.doctorOnOffDays[onOff.ID]; ok {
+			return uniqueViolation("doctor_on_off_days_pkey")
+		}
+		if _, ok := t.branches[onOff.BranchID]; !ok {
+			return foreignKeyViolation("doctor_on_off_days", "doctor_on_off_days", "branch_id")
+		}
+		date := day(onOff.Date)
+		if find(t.doctorOnOffDays, func(d *models.DoctorOnOffDay) bool {
+			return d.BranchID == onOff.BranchID && d.Date.Equal(date)
+		}) != nil {
+			return uniqueViolation("doctor_on_off_days_branch_id_date_key")
+		}
+		now := time.Now()
+		onOff.CreatedAt, onOff.UpdatedAt = now, now
+		row := *onOff
+		row.Branch = nil
+		row.Date = date
+		t.doctorOnOffDays[row.ID] = row
+		return nil
+	})
+}
+
+func (r *doctorOnOffDayRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorOnOffDay, error) {
+	var onOff *models.DoctorOnOffDay
+	err := r.store.read(ctx, func(t *tables) error {
+		onOff = get(t.doctorOnOffDays, id)
+		return nil
+	})
+	return onOff, err
+}
+
+func (r *doctorOnOffDayRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorOnOffDay, error) {
+	var days []*models.DoctorOnOffDay
+	err := r.store.read(ctx, func(t *tables) error {
+		days = collect(t.doctorOnOffDays,
+			func(d *models.DoctorOnOffDay) bool {
+				return d.BranchID == branchID && inRange(d.Date, startDate, endDate)
+			},
+			func(a, b *models.DoctorOnOffDay) bool { return a.Date.Before(b.Date) })
+		return nil
+	})
+	return days, err
+}
+
+func (r *doctorOnOffDayRepository) GetByDate(ctx context.Context, date time.Time) ([]*models.DoctorOnOffDay, error) {
+	var days []*models.DoctorOnOffDay
+	err := r.store.read(ctx, func(t *tables) error {
+		date := day(date)
+		days = collect(t.doctorOnOffDays,
+			func(d *models.DoctorOnOffDay) bool { return d.Date.Equal(date) },
+			func(a, b *models.DoctorOnOffDay) bool { return uuidLess(a.BranchID, b.BranchID) })
+		return nil
+	})
+	return days, err
+}
+
+func (r *doctorOnOffDayRepository) GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.DoctorOnOffDay, error) {
+	var days []*models.DoctorOnOffDay
+	err := r.store.read(ctx, func(t *tables) error {
+		days = collect(t.doctorOnOffDays,
+			func(d *models.DoctorOnOffDay) bool { return inRange(d.Date, startDate, endDate) },
+			func(a, b *models.DoctorOnOffDay) bool {
+				if !a.Date.Equal(b.Date) {
+					return a.Date.Before(b.Date)
+				}
+				return uuidLess(a.BranchID, b.BranchID)
+			})
+		return nil
+	})
+	return days, err
+}
+
+func (r *doctorOnOffDayRepository) Update(ctx context.Context, onOff *models.DoctorOnOffDay) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.doctorOnOffDays[onOff.ID]
+		if !ok {
+			return errNoRows
+		}
+		row.IsDoctorOn = onOff.IsDoctorOn
+		row.UpdatedAt = time.Now()
+		t.doctorOnOffDays[row.ID] = row
+		onOff.UpdatedAt = row.UpdatedAt
+		return nil
+	})
+}
+
+func (r *doctorOnOffDayRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.doctorOnOffDays, id)
+		return nil
+	})
+}
+
+func (r *doctorOnOffDayRepository) GetByBranchAndDate(ctx context.Context, branchID uuid.UUID, date time.Time) (*models.DoctorOnOffDay, error) {
+	var onOff *models.DoctorOnOffDay
+	err := r.store.read(ctx, func(t *tables) error {
+		date := day(date)
+		onOff = find(t.doctorOnOffDays, func(d *models.DoctorOnOffDay) bool {
+			return d.BranchID == branchID && d.Date.Equal(date)
+		})
+		return nil
+	})
+	return onOff, err
+}
+
+// DoctorDefaultScheduleRepository implementation
+type doctorDefaultScheduleRepository struct {
+	store *Store
+}
+
+// findWeeklySchedule finds the doctor's every-week schedule for the weekday and slot
+func findWeeklySchedule(t *tables, doctorID uuid.UUID, dayOfWeek int, slot string) *models.DoctorDefaultSchedule {
+	return find(t.doctorDefaultSchedules, func(s *models.DoctorDefaultSchedule) bool {
+		return s.DoctorID == doctorID && s.DayOfWeek == dayOfWeek && s.Slot == slot && !s.Restricted()
+	})
+}
+
+// storeRecurrence detaches the recurrence dates from the caller's pointers,
+// truncated the way DATE columns store them
+func storeRecurrence(r models.ScheduleRecurrence) models.ScheduleRecurrence {
+	for _, date := range []**time.Time{&r.AnchorDate, &r.ValidFrom, &r.ValidUntil} {
+		if *date != nil {
+			d := day(**date)
+			*date = &d
+		}
+	}
+	return r
+}
+
+func scheduleLess(a, b *models.DoctorDefaultSchedule) bool {
+	if a.DayOfWeek != b.DayOfWeek {
+		return a.DayOfWeek < b.DayOfWeek
+	}
+	if a.Slot != b.Slot {
+		return a.Slot < b.Slot
+	}
+	return a.CreatedAt.Before(b.CreatedAt)
+}
+
+func checkDoctorSchedule(t *tables, table string, doctorID uuid.UUID, branchID *uuid.UUID) error {
+	if _, ok := t.doctors[doctorID]; !ok {
+		return foreignKeyViolation(table, table, "doctor_id")
+	}
+	if branchID != nil {
+		if _, ok := t.branches[*branchID]; !ok {
+			return foreignKeyViolation(table, table, "branch_id")
+		}
+	}
+	return nil
+}
+
+func (r *doctorDefaultScheduleRepository) Create(ctx context.Context, schedule *models.DoctorDefaultSchedule) error {
+	if schedule.ID == uuid.Nil {
+		schedule.ID = uuid.New()
+	}
+	schedule.Slot = models.DoctorSlotOrFullDay(schedule.Slot)
+	schedule.CreatedAt = time.Now()
+	schedule.UpdatedAt = time.Now()
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.doctorDefaultSchedules[schedule.ID]; ok {
+			return uniqueViolation("doctor_default_schedules_pkey")
+		}
+		if err := checkDoctorSchedule(t, "doctor_default_schedules", schedule.DoctorID, &schedule.BranchID); err != nil {
+			return err
+		}
+		if !schedule.Restricted() && findWeeklySchedule(t, schedule.DoctorID, schedule.DayOfWeek, schedule.Slot) != nil {
+			return uniqueViolation("doctor_default_schedules_weekly_key")
+		}
+		row := *schedule
+		row.Doctor, row.Branch = nil, nil
+		row.ScheduleRecurrence = storeRecurrence(row.ScheduleRecurrence)
+		t.doctorDefaultSchedules[row.ID] = row
+		return nil
+	})
+}
+
+func (r *doctorDefaultScheduleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorDefaultSchedule, error) {
+	var schedule *models.DoctorDefaultSchedule
+	err := r.store.read(ctx, func(t *tables) error {
+		schedule = get(t.doctorDefaultSchedules, id)
+		return nil
+	})
+	return schedule, err
+}
+
+func (r *doctorDefaultScheduleRepository) GetByDoctorID(ctx context.Context, doctorID uuid.UUID) ([]*models.DoctorDefaultSchedule, error) {
+	var schedules []*models.DoctorDefaultSchedule
+	err := r.store.read(ctx, func(t *tables) error {
+		schedules = collect(t.doctorDefaultSchedules,
+			func(s *models.DoctorDefaultSchedule) bool { return s.DoctorID == doctorID },
+			scheduleLess)
+		return nil
+	})
+	return schedules, err
+}
+
+func (r *doctorDefaultScheduleRepository) List(ctx context.Context) ([]*models.DoctorDefaultSchedule, error) {
+	var schedules []*models.DoctorDefaultSchedule
+	err := r.store.read(ctx, func(t *tables) error {
+		schedules = collect(t.doctorDefaultSchedules, nil,
+			func(a, b *models.DoctorDefaultSchedule) bool {
+				if a.DoctorID != b.DoctorID {
+					return uuidLess(a.DoctorID, b.DoctorID)
+				}
+				return scheduleLess(a, b)
+			})
+		return nil
+	})
+	return schedules, err
+}
+
+func (r *doctorDefaultScheduleRepository) GetByDoctorAndDayOfWeek(ctx context.Context, doctorID uuid.UUID, dayOfWeek int) ([]*models.DoctorDefaultSchedule, error) {
+	var schedules []*models.DoctorDefaultSchedule
+	err := r.store.read(ctx, func(t *tables) error {
+		schedules = collect(t.doctorDefaultSchedules,
+			func(s *models.DoctorDefaultSchedule) bool { return s.DoctorID == doctorID && s.DayOfWeek == dayOfWeek },
+			scheduleLess)
+		return nil
+	})
+	return schedules, err
+}
+
+func (r *doctorDefaultScheduleRepository) Update(ctx context.Context, schedule *models.DoctorDefaultSchedule) error {
+	schedule.Slot = models.DoctorSlotOrFullDay(schedule.Slot)
+	schedule.UpdatedAt = time.Now()
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.doctorDefaultSchedules[schedule.ID]
+		if !ok {
+			return errNoRows
+		}
+		if _, ok := t.branches[schedule.BranchID]; !ok {
+			return foreignKeyViolation("doctor_default_schedules", "doctor_default_schedules", "branch_id")
+		}
+		if !schedule.Restricted() {
+			if other := findWeeklySchedule(t, row.DoctorID, row.DayOfWeek, schedule.Slot); other != nil && other.ID != row.ID {
+				return uniqueViolation("doctor_default_schedules_weekly_key")
+			}
+		}
+		row.BranchID = schedule.BranchID
+		row.Slot = schedule.Slot
+		row.ScheduleRecurrence = storeRecurrence(schedule.ScheduleRecurrence)
+		row.UpdatedAt = schedule.UpdatedAt
+		t.doctorDefaultSchedules[row.ID] = row
+		return nil
+	})
+}
+
+func (r *doctorDefaultScheduleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.doctorDefaultSchedules, id)
+		return nil
+	})
+}
+
+func (r *doctorDefaultScheduleRepository) DeleteByDoctorID(ctx context.Context, doctorID uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		deleteWhere(t.doctorDefaultSchedules, func(s *models.DoctorDefaultSchedule) bool { return s.DoctorID == doctorID })
+		return nil
+	})
+}
+
+func (r *doctorDefaultScheduleRepository) Upsert(ctx context.Context, schedule *models.DoctorDefaultSchedule) error {
+	if schedule.Restricted() {
+		return r.Create(ctx, schedule)
+	}
+	if schedule.ID == uuid.Nil {
+		schedule.ID = uuid.New()
+	}
+	schedule.Slot = models.DoctorSlotOrFullDay(schedule.Slot)
+	schedule.CreatedAt = time.Now()
+	schedule.UpdatedAt = time.Now()
+	return r.store.write(ctx, func(t *tables) error {
+		if err := checkDoctorSchedule(t, "doctor_default_schedules", schedule.DoctorID, &schedule.BranchID); err != nil {
+			return err
+		}
+		if existing := findWeeklySchedule(t, schedule.DoctorID, schedule.DayOfWeek, schedule.Slot); existing != nil {
+			existing.BranchID = schedule.BranchID
+			existing.IntervalWeeks = schedule.IntervalWeeks
+			existing.UpdatedAt = schedule.UpdatedAt
+			t.doctorDefaultSchedules[existing.ID] = *existing
+			schedule.ID = existing.ID
+			schedule.CreatedAt = existing.CreatedAt
+			return nil
+		}
+		if _, ok := t.doctorDefaultSchedules[schedule.ID]; ok {
+			return uniqueViolation("doctor_default_schedules_pkey")
+		}
+		row := *schedule
+		row.Doctor, row.Branch = nil, nil
+		row.ScheduleRecurrence = storeRecurrence(row.ScheduleRecurrence)
+		t.doctorDefaultSchedules[row.ID] = row
+		return nil
+	})
+}
+
+// DoctorWeeklyOffDayRepository implementation
+type doctorWeeklyOffDayRepository struct {
+	store *Store
+}
+
+func offDayLess(a, b *models.DoctorWeeklyOffDay) bool {
+	if a.DayOfWeek != b.DayOfWeek {
+		return a.DayOfWeek < b.DayOfWeek
+	}
+	return a.CreatedAt.Before(b.CreatedAt)
+}
+
+func (r *doctorWeeklyOffDayRepository) Create(ctx context.Context, offDay *models.DoctorWeeklyOffDay) error {
+	if offDay.ID == uuid.Nil {
+		offDay.ID = uuid.New()
+	}
+	offDay.CreatedAt = time.Now()
+	offDay.UpdatedAt = time.Now()
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.doctorWeeklyOffDays[offDay.ID]; ok {
+			return uniqueViolation("doctor_weekly_off_days_pkey")
+		}
+		if err := checkDoctorSchedule(t, "doctor_weekly_off_days", offDay.DoctorID, nil); err != nil {
+			return err
+		}
+		if !offDay.Restricted() && find(t.doctorWeeklyOffDays, func(o *models.DoctorWeeklyOffDay) bool {
+			return o.DoctorID == offDay.DoctorID && o.DayOfWeek == offDay.DayOfWeek && !o.Restricted()
+		}) != nil {
+			return uniqueViolation("doctor_weekly_off_days_weekly_key")
+		}
+		row := *offDay
+		row.Doctor = nil
+		row.ScheduleRecurrence = storeRecurrence(row.ScheduleRecurrence)
+		t.doctorWeeklyOffDays[row.ID] = row
+		return nil
+	})
+}
+
+func (r *doctorWeeklyOffDayRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorWeeklyOffDay, error) {
+	var offDay *models.DoctorWeeklyOffDay
+	err := r.store.read(ctx, func(t *tables) error {
+		offDay = get(t.doctorWeeklyOffDays, id)
+		return nil
+	})
+	return offDay, err
+}
+
+func (r *doctorWeeklyOffDayRepository) GetByDoctorID(ctx context.Context, doctorID uuid.UUID) ([]*models.DoctorWeeklyOffDay, error) {
+	var offDays []*models.DoctorWeeklyOffDay
+	err := r.store.read(ctx, func(t *tables) error {
+		offDays = collect(t.doctorWeeklyOffDays,
+			func(o *models.DoctorWeeklyOffDay) bool { return o.DoctorID == doctorID },
+			offDayLess)
+		return nil
+	})
+	return offDays, err
+}
+
+func (r *doctorWeeklyOffDayRepository) List(ctx context.Context) ([]*models.DoctorWeeklyOffDay, error) {
+	var offDays []*models.DoctorWeeklyOffDay
+	err := r.store.read(ctx, func(t *tables) error {
+		offDays = collect(t.doctorWeeklyOffDays, nil,
+			func(a, b *models.DoctorWeeklyOffDay) bool {
+				if a.DoctorID != b.DoctorID {
+					return uuidLess(a.DoctorID, b.DoctorID)
+				}
+				return offDayLess(a, b)
+			})
+		return nil
+	})
+	return offDays, err
+}
+
+func (r *doctorWeeklyOffDayRepository) GetByDoctorAndDayOfWeek(ctx context.Context, doctorID uuid.UUID, dayOfWeek int) ([]*models.DoctorWeeklyOffDay, error) {
+	var offDays []*models.DoctorWeeklyOffDay
+	err := r.store.read(ctx, func(t *tables) error {
+		offDays = collect(t.doctorWeeklyOffDays,
+			func(o *models.DoctorWeeklyOffDay) bool { return o.DoctorID == doctorID && o.DayOfWeek == dayOfWeek },
+			offDayLess)
+		return nil
+	})
+	return offDays, err
+}
+
+func (r *doctorWeeklyOffDayRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.doctorWeeklyOffDays, id)
+		return nil
+	})
+}
+
+func (r *doctorWeeklyOffDayRepository) DeleteByDoctorID(ctx context.Context, doctorID uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		deleteWhere(t.doctorWeeklyOffDays, func(o *models.DoctorWeeklyOffDay) bool { return o.DoctorID == doctorID })
+		return nil
+	})
+}
+
+func (r *doctorWeeklyOffDayRepository) DeleteByDoctorAndDayOfWeek(ctx context.Context, doctorID uuid.UUID, dayOfWeek int) error {
+	return r.store.write(ctx, func(t *tables) error {
+		deleteWhere(t.doctorWeeklyOffDays, func(o *models.DoctorWeeklyOffDay) bool {
+			return o.DoctorID == doctorID && o.DayOfWeek == dayOfWeek
+		})
+		return nil
+	})
+}
+
+// DoctorScheduleOverrideRepository implementation
+type doctorScheduleOverrideRepository struct {
+	store *Store
+}
+
+func (r *doctorScheduleOverrideRepository) Create(ctx context.Context, override *models.DoctorScheduleOverride) error {
+	if override.ID == uuid.Nil {
+		override.ID = uuid.New()
+	}
+	override.CreatedAt = time.Now()
+	override.UpdatedAt = time.Now()
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.doctorOverrides[override.ID]; ok {
+			return uniqueViolation("doctor_schedule_overrides_pkey")
+		}
+		if err := checkDoctorSchedule(t, "doctor_schedule_overrides", override.DoctorID, override.BranchID); err != nil {
+			return err
+		}
+		date := day(override.Date)
+		if find(t.doctorOverrides, func(o *models.DoctorScheduleOverride) bool {
+			return o.DoctorID == override.DoctorID && o.Date.Equal(date)
+		}) != nil {
+			return uniqueViolation("doctor_schedule_overrides_doctor_id_date_key")
+		}
+		row := *override
+		row.Doctor, row.Branch = nil, nil
+		row.BranchID = copyUUID(override.BranchID)
+		row.Date = date
+		t.doctorOverrides[row.ID] = row
+		return nil
+	})
+}
+
+func (r *doctorScheduleOverrideRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorScheduleOverride, error) {
+	var override *models.DoctorScheduleOverride
+	err := r.store.read(ctx, func(t *tables) error {
+		if override = get(t.doctorOverrides, id); override != nil {
+			override.BranchID = copyUUID(override.BranchID)
+		}
+		return nil
+	})
+	return override, err
+}
+
+func (r *doctorScheduleOverrideRepository) GetByDoctorID(ctx context.Context, doctorID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorScheduleOverride, error) {
+	var overrides []*models.DoctorScheduleOverride
+	err := r.store.read(ctx, func(t *tables) error {
+		overrides = collect(t.doctorOverrides,
+			func(o *models.DoctorScheduleOverride) bool {
+				return o.DoctorID == doctorID && inRange(o.Date, startDate, endDate)
+			},
+			func(a, b *models.DoctorScheduleOverride) bool { return a.Date.Before(b.Date) })
+		for _, o := range overrides {
+			o.BranchID = copyUUID(o.BranchID)
+		}
+		return nil
+	})
+	return overrides, err
+}
+
+func (r *doctorScheduleOverrideRepository) GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.DoctorScheduleOverride, error) {
+	var overrides []*models.DoctorScheduleOverride
+	err := r.store.read(ctx, func(t *tables) error {
+		overrides = collect(t.doctorOverrides,
+			func(o *models.DoctorScheduleOverride) bool { return inRange(o.Date, startDate, endDate) },
+			func(a, b *models.DoctorScheduleOverride) bool {
+				if !a.Date.Equal(b.Date) {
+					return a.Date.Before(b.Date)
+				}
+				return uuidLess(a.DoctorID, b.DoctorID)
+			})
+		for _, o := range overrides {
+			o.BranchID = copyUUID(o.BranchID)
+		}
+		return nil
+	})
+	return overrides, err
+}
+
+func (r *doctorScheduleOverrideRepository) GetByDoctorAndDate(ctx context.Context, doctorID uuid.UUID, date time.Time) (*models.DoctorScheduleOverride, error) {
+	var override *models.DoctorScheduleOverride
+	err := r.store.read(ctx, func(t *tables) error {
+		date := day(date)
+		override = find(t.doctorOverrides, func(o *models.DoctorScheduleOverride) bool {
+			return o.DoctorID == doctorID && o.Date.Equal(date)
+		})
+		if override != nil {
+			override.BranchID = copyUUID(override.BranchID)
+		}
+		return nil
+	})
+	return override, err
+}
+
+func (r *doctorScheduleOverrideRepository) Update(ctx context.Context, override *models.DoctorScheduleOverride) error {
+	override.UpdatedAt = time.Now()
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.doctorOverrides[override.ID]
+		if !ok {
+			return errNoRows
+		}
+		if err := checkDoctorSchedule(t, "doctor_schedule_overrides", row.DoctorID, override.BranchID); err != nil {
+			return err
+		}
+		row.Type = override.Type
+		row.BranchID = copyUUID(override.BranchID)
+		row.UpdatedAt = override.UpdatedAt
+		t.doctorOverrides[row.ID] = row
+		return nil
+	})
+}
+
+func (r *doctorScheduleOverrideRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.doctorOverrides, id)
+		return nil
+	})
+}
+
+func (r *doctorScheduleOverrideRepository) DeleteByDoctorID(ctx context.Context, doctorID uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		deleteWhere(t.doctorOverrides, func(o *models.DoctorScheduleOverride) bool { return o.DoctorID == doctorID })
+		return nil
+	})
+}
+
+// DoctorRevenueProfileRepository implementation
+type doctorRevenueProfileRepository struct {
+	store *Store
+}
+
+func (r *doctorRevenueProfileRepository) Upsert(ctx context.Context, profile *models.DoctorRevenueProfile) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.doctors[profile.DoctorID]; !ok {
+			return foreignKeyViolation("doctor_revenue_profiles", "doctor_revenue_profiles", "doctor_id")
+		}
+		if profile.BranchID != nil {
+			if _, ok := t.branches[*profile.BranchID]; !ok {
+				return foreignKeyViolation("doctor_revenue_profiles", "doctor_revenue_profiles", "branch_id")
+			}
+		}
+		now := time.Now()
+		row := *profile
+		row.BranchID = copyUUID(profile.BranchID)
+		row.UpdatedAt = now
+		if existing := find(t.doctorRevenueProfiles, func(p *models.DoctorRevenueProfile) bool {
+			return p.DoctorID == profile.DoctorID && p.DayOfWeek == profile.DayOfWeek && sameUUID(p.BranchID, profile.BranchID)
+		}); existing != nil {
+			row.ID, row.CreatedBy, row.CreatedAt = existing.ID, existing.CreatedBy, existing.CreatedAt
+		} else {
+			if row.ID == uuid.Nil {
+				row.ID = uuid.New()
+			}
+			row.CreatedAt = now
+		}
+		t.doctorRevenueProfiles[row.ID] = row
+		profile.ID, profile.CreatedBy, profile.CreatedAt, profile.UpdatedAt = row.ID, row.CreatedBy, row.CreatedAt, row.UpdatedAt
+		return nil
+	})
+}
+
+func (r *doctorRevenueProfileRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorRevenueProfile, error) {
+	var profile *models.DoctorRevenueProfile
+	err := r.store.read(ctx, func(t *tables) error {
+		if profile = get(t.doctorRevenueProfiles, id); profile != nil {
+			profile.BranchID = copyUUID(profile.BranchID)
+		}
+		return nil
+	})
+	return profile, err
+}
+
+func (r *doctorRevenueProfileRepository) GetByDoctorID(ctx context.Context, doctorID uuid.UUID) ([]*models.DoctorRevenueProfile, error) {
+	return r.collect(ctx, func(p *models.DoctorRevenueProfile) bool { return p.DoctorID == doctorID })
+}
+
+func (r *doctorRevenueProfileRepository) List(ctx context.Context) ([]*models.DoctorRevenueProfile, error) {
+	return r.collect(ctx, func(*models.DoctorRevenueProfile) bool { return true })
+}
+
+// collect returns the matching profiles ordered like the postgres repository:
+// by doctor, day of week and then branch with the any-branch profile first
+func (r *doctorRevenueProfileRepository) collect(ctx context.Context, match func(*models.DoctorRevenueProfile) bool) ([]*models.DoctorRevenueProfile, error) {
+	var profiles []*models.DoctorRevenueProfile
+	err := r.store.read(ctx, func(t *tables) error {
+		profiles = collect(t.doctorRevenueProfiles, match, func(a, b *models.DoctorRevenueProfile) bool {
+			if a.DoctorID != b.DoctorID {
+				return uuidLess(a.DoctorID, b.DoctorID)
+			}
+			if a.DayOfWeek != b.DayOfWeek {
+				return a.DayOfWeek < b.DayOfWeek
+			}
+			if a.BranchID == nil || b.BranchID == nil {
+				return a.BranchID == nil && b.BranchID != nil
+			}
+			return uuidLess(*a.BranchID, *b.BranchID)
+		})
+		for _, p := range profiles {
+			p.BranchID = copyUUID(p.BranchID)
+		}
+		return nil
+	})
+	return profiles, err
+}
+
+func (r *doctorRevenueProfileRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.doctorRevenueProfiles, id)
+		return nil
+	})
+}
+
+// DoctorRevenueOverrideRepository implementation
+type doctorRevenueOverrideRepository struct {
+	store *Store
+}
+
+func (r *doctorRevenueOverrideRepository) Upsert(ctx context.Context, override *models.DoctorRevenueOverride) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.doctors[override.DoctorID]; !ok {
+			return foreignKeyViolation("doctor_revenue_overrides", "doctor_revenue_overrides", "doctor_id")
+		}
+		now := time.Now()
+		row := *override
+		row.Date = day(override.Date)
+		row.UpdatedAt = now
+		if existing := find(t.doctorRevenueOverrides, func(o *models.DoctorRevenueOverride) bool {
+			return o.DoctorID == override.DoctorID && o.Date.Equal(row.Date)
+		}); existing != nil {
+			row.ID, row.CreatedBy, row.CreatedAt = existing.ID, existing.CreatedBy, existing.CreatedAt
+		} else {
+			if row.ID == uuid.Nil {
+				row.ID = uuid.New()
+			}
+			row.CreatedAt = now
+		}
+		t.doctorRevenueOverrides[row.ID] = row
+		override.ID, override.CreatedBy, override.CreatedAt, override.UpdatedAt = row.ID, row.CreatedBy, row.CreatedAt, row.UpdatedAt
+		return nil
+	})
+}
+
+func (r *doctorRevenueOverrideRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorRevenueOverride, error) {
+	var override *models.DoctorRevenueOverride
+	err := r.store.read(ctx, func(t *tables) error {
+		override = get(t.doctorRevenueOverrides, id)
+		return nil
+	})
+	return override, err
+}
+
+func (r *doctorRevenueOverrideRepository) GetByDoctorID(ctx context.Context, doctorID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorRevenueOverride, error) {
+	var overrides []*models.DoctorRevenueOverride
+	err := r.store.read(ctx, func(t *tables) error {
+		overrides = collect(t.doctorRevenueOverrides,
+			func(o *models.DoctorRevenueOverride) bool {
+				return o.DoctorID == doctorID && inRange(o.Date, startDate, endDate)
+			},
+			func(a, b *models.DoctorRevenueOverride) bool { return a.Date.Before(b.Date) })
+		return nil
+	})
+	return overrides, err
+}
+
+func (r *doctorRevenueOverrideRepository) GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.DoctorRevenueOverride, error) {
+	var overrides []*models.DoctorRevenueOverride
+	err := r.store.read(ctx, func(t *tables) error {
+		overrides = collect(t.doctorRevenueOverrides,
+			func(o *models.DoctorRevenueOverride) bool { return inRange(o.Date, startDate, endDate) },
+			func(a, b *models.DoctorRevenueOverride) bool {
+				if !a.Date.Equal(b.Date) {
+					return a.Date.Before(b.Date)
+				}
+				return uuidLess(a.DoctorID, b.DoctorID)
+			})
+		return nil
+	})
+	return overrides, err
+}
+
+func (r *doctorRevenueOverrideRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.doctorRevenueOverrides, id)
+		return nil
+	})
+}
+
+var (
+	_ interfaces.DoctorRepository                 = (*doctorRepository)(nil)
+	_ interfaces.DoctorPreferenceRepository       = (*doctorPreferenceRepository)(nil)
+	_ interfaces.DoctorAssignmentRepository       = (*doctorAssignmentRepository)(nil)
+	_ interfaces.DoctorOnOffDayRepository         = (*doctorOnOffDayRepository)(nil)
+	_ interfaces.DoctorDefaultScheduleRepository  = (*doctorDefaultScheduleRepository)(nil)
+	_ interfaces.DoctorWeeklyOffDayRepository     = (*doctorWeeklyOffDayRepository)(nil)
+	_ interfaces.DoctorScheduleOverrideRepository = (*doctorScheduleOverrideRepository)(nil)
+	_ interfaces.DoctorRevenueProfileRepository   = (*doctorRevenueProfileRepository)(nil)
+	_ interfaces.DoctorRevenueOverrideRepository  = (*doctorRevenueOverrideRepository)(nil)
+)
@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+)
+
+// DoctorLeaveRepository implementation
+type doctorLeaveRepository struct {
+	store *Store
+}
+
+// storeLeave detaches a leave for storage like a table row
+func storeLeave(leave *models.DoctorLeave) models.DoctorLeave {
+	row := *leave
+	row.DoctorName, row.Substitutes = "", nil
+	row.StartDate, row.EndDate = day(leave.StartDate), day(leave.EndDate)
+	row.ReviewedBy, row.ReviewedAt = copyUUID(leave.ReviewedBy), copyPtr(leave.ReviewedAt)
+	return row
+}
+
+func (r *doctorLeaveRepository) Create(ctx context.Context, leave *models.DoctorLeave) error {
+	if leave.ID == uuid.Nil {
+		leave.ID = uuid.New()
+	}
+	if leave.Status == "" {
+		leave.Status = models.DoctorLeavePending
+	}
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.doctors[leave.DoctorID]; !ok {
+			return foreignKeyViolation("doctors", "doctor_leaves", "doctor_id")
+		}
+		now := time.Now()
+		leave.CreatedAt, leave.UpdatedAt = now, now
+		t.doctorLeaves[leave.ID] = storeLeave(leave)
+		return nil
+	})
+}
+
+func (r *doctorLeaveRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorLeave, error) {
+	var leave *models.DoctorLeave
+	err := r.store.read(ctx, func(t *tables) error {
+		if leave = get(t.doctorLeaves, id); leave != nil {
+			leave.DoctorName = t.doctors[leave.DoctorID].Name
+			leave.ReviewedBy, leave.ReviewedAt = copyUUID(leave.ReviewedBy), copyPtr(leave.ReviewedAt)
+		}
+		return nil
+	})
+	return leave, err
+}
+
+func (r *doctorLeaveRepository) List(ctx context.Context, filters interfaces.DoctorLeaveFilters) ([]*models.DoctorLeave, error) {
+	leaves := []*models.DoctorLeave{}
+	err := r.store.read(ctx, func(t *tables) error {
+		rows := collect(t.doctorLeaves, func(l *models.DoctorLeave) bool {
+			if filters.DoctorID != nil && l.DoctorID != *filters.DoctorID {
+				return false
+			}
+			if filters.Status != "" && l.Status != filters.Status {
+				return false
+			}
+			if filters.StartDate != nil && l.EndDate.Before(day(*filters.StartDate)) {
+				return false
+			}
+			return filters.EndDate == nil || !l.StartDate.After(day(*filters.EndDate))
+		}, func(a, b *models.DoctorLeave) bool {
+			if !a.StartDate.Equal(b.StartDate) {
+				return a.StartDate.Before(b.StartDate)
+			}
+			return t.doctors[a.DoctorID].Name < t.doctors[b.DoctorID].Name
+		})
+		for _, l := range rows {
+			l.DoctorName = t.doctors[l.DoctorID].Name
+			l.ReviewedBy, l.ReviewedAt = copyUUID(l.ReviewedBy), copyPtr(l.ReviewedAt)
+			leaves = append(leaves, l)
+		}
+		return nil
+	})
+	return leaves, err
+}
+
+func (r *doctorLeaveRepository) Update(ctx context.Context, leave *models.DoctorLeave) error {
+	return r.store.write(ctx, func(t *tables) error {
+		existing, ok := t.doctorLeaves[leave.ID]
+		if !ok {
+			return errNoRows
+		}
+		leave.UpdatedAt = time.Now()
+		row := storeLeave(leave)
+		row.DoctorID, row.CreatedBy, row.CreatedAt = existing.DoctorID, existing.CreatedBy, existing.CreatedAt
+		t.doctorLeaves[leave.ID] = row
+		return nil
+	})
+}
+
+func (r *doctorLeaveRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.doctorLeaves, id)
+		deleteWhere(t.doctorLeaveSubstitutes, func(s *models.DoctorLeaveSubstitute) bool { return s.LeaveID == id })
+		return nil
+	})
+}
+
+func (r *doctorLeaveRepository) CreateSubstitute(ctx context.Context, substitute *models.DoctorLeaveSubstitute) error {
+	if substitute.ID == uuid.Nil {
+		substitute.ID = uuid.New()
+	}
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.doctorLeaves[substitute.LeaveID]; !ok {
+			return foreignKeyViolation("doctor_leaves", "doctor_leave_substitutes", "leave_id")
+		}
+		if _, ok := t.branches[substitute.BranchID]; !ok {
+			return foreignKeyViolation("branches", "doctor_leave_substitutes", "branch_id")
+		}
+		if _, ok := t.doctors[substitute.DoctorID]; !ok {
+			return foreignKeyViolation("doctors", "doctor_leave_substitutes", "doctor_id")
+		}
+		date := day(substitute.Date)
+		if find(t.doctorLeaveSubstitutes, func(s *models.DoctorLeaveSubstitute) bool {
+			return s.LeaveID == substitute.LeaveID && s.Date.Equal(date) && s.BranchID == substitute.BranchID
+		}) != nil {
+			return uniqueViolation("doctor_leave_substitutes_leave_id_date_branch_id_key")
+		}
+		substitute.CreatedAt = time.Now()
+		row := *substitute
+		row.Date = date
+		row.BranchCode, row.DoctorName = "", ""
+		t.doctorLeaveSubstitutes[row.ID] = row
+		return nil
+	})
+}
+
+func (r *doctorLeaveRepository) GetSubstituteByID(ctx context.Context, id uuid.UUID) (*models.DoctorLeaveSubstitute, error) {
+	var substitute *models.DoctorLeaveSubstitute
+	err := r.store.read(ctx, func(t *tables) error {
+		if substitute = get(t.doctorLeaveSubstitutes, id); substitute != nil {
+			substitute.BranchCode = t.branches[substitute.BranchID].Code
+			substitute.DoctorName = t.doctors[substitute.DoctorID].Name
+		}
+		return nil
+	})
+	return substitute, err
+}
+
+func (r *doctorLeaveRepository) GetSubstitutes(ctx context.Context, leaveID uuid.UUID) ([]*models.DoctorLeaveSubstitute, error) {
+	substitutes := []*models.DoctorLeaveSubstitute{}
+	err := r.store.read(ctx, func(t *tables) error {
+		rows := collect(t.doctorLeaveSubstitutes,
+			func(s *models.DoctorLeaveSubstitute) bool { return s.LeaveID == leaveID },
+			func(a, b *models.DoctorLeaveSubstitute) bool {
+				if !a.Date.Equal(b.Date) {
+					return a.Date.Before(b.Date)
+				}
+				return t.branches[a.BranchID].Code < t.branches[b.BranchID].Code
+			})
+		for _, s := range rows {
+			s.BranchCode = t.branches[s.BranchID].Code
+			s.DoctorName = t.doctors[s.DoctorID].Name
+			substitutes = append(substitutes, s)
+		}
+		return nil
+	})
+	return substitutes, err
+}
+
+func (r *doctorLeaveRepository) DeleteSubstitute(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.doctorLeaveSubstitutes, id)
+		return nil
+	})
+}
+
+var _ interfaces.DoctorLeaveRepository = (*doctorLeaveRepository)(nil)
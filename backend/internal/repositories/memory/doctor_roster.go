@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// DoctorRosterRepository implementation
+type doctorRosterRepository struct {
+	store *Store
+}
+
+func (r *doctorRosterRepository) CreateRun(ctx context.Context, run *models.DoctorRosterRun) error {
+	if run.ID == uuid.Nil {
+		run.ID = uuid.New()
+	}
+	run.CreatedAt = time.Now()
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.doctorRosterRuns[run.ID]; ok {
+			return uniqueViolation("doctor_roster_runs_pkey")
+		}
+		row := *run
+		row.StartDate, row.EndDate = day(run.StartDate), day(run.EndDate)
+		t.doctorRosterRuns[row.ID] = row
+		return nil
+	})
+}
+
+func (r *doctorRosterRepository) ListRuns(ctx context.Context, limit int) ([]*models.DoctorRosterRun, error) {
+	runs := []*models.DoctorRosterRun{}
+	err := r.store.read(ctx, func(t *tables) error {
+		runs = append(runs, collect(t.doctorRosterRuns, nil, func(a, b *models.DoctorRosterRun) bool {
+			return a.CreatedAt.After(b.CreatedAt)
+		})...)
+		return nil
+	})
+	if limit > 0 && len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, err
+}
+
+func (r *doctorRosterRepository) Window(ctx context.Context) (*models.DoctorRosterWindow, error) {
+	var window *models.DoctorRosterWindow
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, run := range t.doctorRosterRuns {
+			if window == nil {
+				window = &models.DoctorRosterWindow{StartDate: run.StartDate, EndDate: run.EndDate}
+				continue
+			}
+			if run.StartDate.Before(window.StartDate) {
+				window.StartDate = run.StartDate
+			}
+			if run.EndDate.After(window.EndDate) {
+				window.EndDate = run.EndDate
+			}
+		}
+		return nil
+	})
+	return window, err
+}
+
+func (r *doctorRosterRepository) CreateChange(ctx context.Context, change *models.DoctorRosterChange) error {
+	if change.ID == uuid.Nil {
+		change.ID = uuid.New()
+	}
+	change.CreatedAt = time.Now()
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.doctorRosterRuns[change.RunID]; !ok {
+			return foreignKeyViolation("doctor_roster_changes", "doctor_roster_changes", "run_id")
+		}
+		if _, ok := t.doctors[change.DoctorID]; !ok {
+			return foreignKeyViolation("doctor_roster_changes", "doctor_roster_changes", "doctor_id")
+		}
+		row := *change
+		row.DoctorName, row.FromBranchCode, row.ToBranchCode = "", "", ""
+		row.Date = day(change.Date)
+		row.FromBranchID, row.ToBranchID = copyUUID(change.FromBranchID), copyUUID(change.ToBranchID)
+		t.doctorRosterChanges[row.ID] = row
+		return nil
+	})
+}
+
+func (r *doctorRosterRepository) ListChanges(ctx context.Context, filters interfaces.DoctorRosterChangeFilters) ([]*models.DoctorRosterChange, error) {
+	changes := []*models.DoctorRosterChange{}
+	err := r.store.read(ctx, func(t *tables) error {
+		rows := collect(t.doctorRosterChanges, func(c *models.DoctorRosterChange) bool {
+			if filters.RunID != nil && c.RunID != *filters.RunID {
+				return false
+			}
+			if filters.DoctorID != nil && c.DoctorID != *filters.DoctorID {
+				return false
+			}
+			if filters.BranchID != nil && !sameUUID(c.FromBranchID, filters.BranchID) && !sameUUID(c.ToBranchID, filters.BranchID) {
+				return false
+			}
+			if filters.StartDate != nil && c.Date.Before(day(*filters.StartDate)) {
+				return false
+			}
+			return filters.EndDate == nil || !c.Date.After(day(*filters.EndDate))
+		}, func(a, b *models.DoctorRosterChange) bool {
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.After(b.CreatedAt)
+			}
+			if !a.Date.Equal(b.Date) {
+				return a.Date.Before(b.Date)
+			}
+			return t.doctors[a.DoctorID].Name < t.doctors[b.DoctorID].Name
+		})
+		for _, c := range rows {
+			c.DoctorName = t.doctors[c.DoctorID].Name
+			if c.FromBranchID != nil {
+				c.FromBranchCode = t.branches[*c.FromBranchID].Code
+			}
+			if c.ToBranchID != nil {
+				c.ToBranchCode = t.branches[*c.ToBranchID].Code
+			}
+			changes = append(changes, c)
+		}
+		return nil
+	})
+	if filters.Limit > 0 && len(changes) > filters.Limit {
+		changes = changes[:filters.Limit]
+	}
+	return changes, err
+}
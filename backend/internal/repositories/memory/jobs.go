@@ -0,0 +1,411 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// jobRow is a row of the jobs table, including the files the postgres
+// repository keeps in bytea columns
+type jobRow struct {
+	job        models.Job
+	input      *models.JobFile
+	resultFile *models.JobFile
+}
+
+// copyJob detaches the slices and pointers of a stored job
+func copyJob(job models.Job) *models.Job {
+	job.Payload = append([]byte(nil), job.Payload...)
+	job.Result = append([]byte(nil), job.Result...)
+	job.HeartbeatAt = copyPtr(job.HeartbeatAt)
+	job.StartedAt = copyPtr(job.StartedAt)
+	job.FinishedAt = copyPtr(job.FinishedAt)
+	job.CreatedBy = copyUUID(job.CreatedBy)
+	return &job
+}
+
+func copyJobFile(file *models.JobFile) *models.JobFile {
+	if file == nil {
+		return nil
+	}
+	return &models.JobFile{Name: file.Name, ContentType: file.ContentType, Data: append([]byte(nil), file.Data...)}
+}
+
+// JobRepository implementation
+type jobRepository struct {
+	store *Store
+}
+
+func (r *jobRepository) Create(ctx context.Context, job *models.Job, input *models.JobFile) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if job.ID == uuid.Nil {
+			job.ID = uuid.New()
+		}
+		if _, ok := t.jobs[job.ID]; ok {
+			return uniqueViolation("jobs_pkey")
+		}
+		now := time.Now()
+		if job.Status == "" {
+			job.Status = models.JobStatusQueued
+		}
+		if job.RunAt.IsZero() {
+			job.RunAt = now
+		}
+		job.CreatedAt, job.UpdatedAt = now, now
+		t.jobs[job.ID] = jobRow{job: *copyJob(*job), input: copyJobFile(input)}
+		return nil
+	})
+}
+
+func (r *jobRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Job, error) {
+	var job *models.Job
+	err := r.store.read(ctx, func(t *tables) error {
+		if row, ok := t.jobs[id]; ok {
+			job = copyJob(row.job)
+		}
+		return nil
+	})
+	return job, err
+}
+
+func (r *jobRepository) List(ctx context.Context, filters interfaces.JobFilters) ([]*models.Job, error) {
+	jobs := []*models.Job{}
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, row := range t.jobs {
+			job := row.job
+			if filters.Type != "" && job.Type != filters.Type {
+				continue
+			}
+			if filters.Status != nil && job.Status != *filters.Status {
+				continue
+			}
+			if filters.CreatedBy != nil && !sameUUID(job.CreatedBy, filters.CreatedBy) {
+				continue
+			}
+			jobs = append(jobs, copyJob(job))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		if !jobs[i].CreatedAt.Equal(jobs[j].CreatedAt) {
+			return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+		}
+		return uuidLess(jobs[i].ID, jobs[j].ID)
+	})
+	if filters.Limit > 0 && len(jobs) > filters.Limit {
+		jobs = jobs[:filters.Limit]
+	}
+	return jobs, nil
+}
+
+func (r *jobRepository) GetInput(ctx context.Context, id uuid.UUID) (*models.JobFile, error) {
+	var file *models.JobFile
+	err := r.store.read(ctx, func(t *tables) error {
+		file = copyJobFile(t.jobs[id].input)
+		return nil
+	})
+	return file, err
+}
+
+func (r *jobRepository) GetResultFile(ctx context.Context, id uuid.UUID) (*models.JobFile, error) {
+	var file *models.JobFile
+	err := r.store.read(ctx, func(t *tables) error {
+		file = copyJobFile(t.jobs[id].resultFile)
+		return nil
+	})
+	return file, err
+}
+
+func (r *jobRepository) Claim(ctx context.Context, workerID string, types []string) (*models.Job, error) {
+	wanted := make(map[string]bool, len(types))
+	for _, jobType := range types {
+		wanted[jobType] = true
+	}
+	var job *models.Job
+	err := r.store.write(ctx, func(t *tables) error {
+		now := time.Now()
+		var next *jobRow
+		for id := range t.jobs {
+			row := t.jobs[id]
+			if row.job.Status != models.JobStatusQueued || row.job.RunAt.After(now) || !wanted[row.job.Type] {
+				continue
+			}
+			if next == nil || row.job.RunAt.Before(next.job.RunAt) ||
+				(row.job.RunAt.Equal(next.job.RunAt) && row.job.CreatedAt.Before(next.job.CreatedAt)) {
+				next = &row
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		row := *next
+		row.job.Status = models.JobStatusRunning
+		row.job.Attempts++
+		row.job.LockedBy = workerID
+		row.job.HeartbeatAt = &now
+		if row.job.StartedAt == nil {
+			row.job.StartedAt = &now
+		}
+		row.job.UpdatedAt = now
+		t.jobs[row.job.ID] = row
+		job = copyJob(row.job)
+		return nil
+	})
+	return job, err
+}
+
+// updateJob applies fn to a copy of the job row and stores it, reporting whether the job exists
+func updateJob(t *tables, id uuid.UUID, fn func(row *jobRow, now time.Time)) bool {
+	row, ok := t.jobs[id]
+	if !ok {
+		return false
+	}
+	now := time.Now()
+	fn(&row, now)
+	row.job.UpdatedAt = now
+	t.jobs[id] = row
+	return true
+}
+
+func (r *jobRepository) Heartbeat(ctx context.Context, id uuid.UUID, progress int, message string) (bool, error) {
+	cancelRequested := true
+	err := r.store.write(ctx, func(t *tables) error {
+		if row, ok := t.jobs[id]; !ok || row.job.Status != models.JobStatusRunning {
+			// No longer running here: treat it as cancelled so the work stops
+			return nil
+		}
+		updateJob(t, id, func(row *jobRow, now time.Time) {
+			row.job.Progress = progress
+			row.job.ProgressMessage = message
+			row.job.HeartbeatAt = &now
+			cancelRequested = row.job.CancelRequested
+		})
+		return nil
+	})
+	return cancelRequested, err
+}
+
+func (r *jobRepository) Complete(ctx context.Context, id uuid.UUID, result []byte, file *models.JobFile) error {
+	return r.store.write(ctx, func(t *tables) error {
+		updateJob(t, id, func(row *jobRow, now time.Time) {
+			row.job.Status = models.JobStatusSucceeded
+			row.job.Progress = 100
+			row.job.Error = ""
+			row.job.Result = append([]byte(nil), result...)
+			row.resultFile = copyJobFile(file)
+			row.job.ResultFileName = ""
+			if file != nil {
+				row.job.ResultFileName = file.Name
+			}
+			row.job.LockedBy = ""
+			row.job.FinishedAt = &now
+		})
+		return nil
+	})
+}
+
+func (r *jobRepository) Fail(ctx context.Context, id uuid.UUID, errMsg string, retryAt *time.Time) error {
+	return r.store.write(ctx, func(t *tables) error {
+		updateJob(t, id, func(row *jobRow, now time.Time) {
+			row.job.Error = errMsg
+			row.job.LockedBy = ""
+			if retryAt != nil {
+				row.job.Status = models.JobStatusQueued
+				row.job.RunAt = *retryAt
+				row.job.HeartbeatAt = nil
+				return
+			}
+			row.job.Status = models.JobStatusFailed
+			row.job.FinishedAt = &now
+		})
+		return nil
+	})
+}
+
+func (r *jobRepository) MarkCancelled(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		updateJob(t, id, func(row *jobRow, now time.Time) {
+			row.job.Status = models.JobStatusCancelled
+			row.job.LockedBy = ""
+			row.job.FinishedAt = &now
+		})
+		return nil
+	})
+}
+
+func (r *jobRepository) RequestCancel(ctx context.Context, id uuid.UUID) (*models.Job, error) {
+	var job *models.Job
+	err := r.store.write(ctx, func(t *tables) error {
+		row, ok := t.jobs[id]
+		if !ok {
+			return nil
+		}
+		if row.job.Status == models.JobStatusQueued || row.job.Status == models.JobStatusRunning {
+			updateJob(t, id, func(row *jobRow, now time.Time) {
+				row.job.CancelRequested = true
+				if row.job.Status == models.JobStatusQueued {
+					row.job.Status = models.JobStatusCancelled
+					row.job.FinishedAt = &now
+				}
+			})
+		}
+		job = copyJob(t.jobs[id].job)
+		return nil
+	})
+	return job, err
+}
+
+func (r *jobRepository) RequeueStale(ctx context.Context, staleAfter time.Duration) (int, error) {
+	requeued := 0
+	err := r.store.write(ctx, func(t *tables) error {
+		cutoff := time.Now().Add(-staleAfter)
+		for id, row := range t.jobs {
+			if row.job.Status != models.JobStatusRunning || row.job.HeartbeatAt == nil || !row.job.HeartbeatAt.Before(cutoff) {
+				continue
+			}
+			updateJob(t, id, func(row *jobRow, now time.Time) {
+				row.job.Error = "worker stopped responding"
+				row.job.LockedBy = ""
+				row.job.HeartbeatAt = nil
+				if row.job.Attempts >= row.job.MaxAttempts {
+					row.job.Status = models.JobStatusFailed
+					row.job.FinishedAt = &now
+				} else {
+					row.job.Status = models.JobStatusQueued
+					row.job.FinishedAt = nil
+				}
+			})
+			requeued++
+		}
+		return nil
+	})
+	return requeued, err
+}
+
+func (r *jobRepository) DeleteFinishedBefore(ctx context.Context, before time.Time) (int, error) {
+	deleted := 0
+	err := r.store.write(ctx, func(t *tables) error {
+		for id, row := range t.jobs {
+			if row.job.IsFinished() && row.job.FinishedAt != nil && row.job.FinishedAt.Before(before) {
+				delete(t.jobs, id)
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+func (r *jobRepository) CountByStatus(ctx context.Context) (map[models.JobStatus]int, error) {
+	counts := make(map[models.JobStatus]int)
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, row := range t.jobs {
+			counts[row.job.Status]++
+		}
+		return nil
+	})
+	return counts, err
+}
+
+// JobScheduleRepository implementation
+type jobScheduleRepository struct {
+	store *Store
+}
+
+func copyJobSchedule(s models.JobSchedule) *models.JobSchedule {
+	s.Payload = append([]byte(nil), s.Payload...)
+	s.LastRunAt = copyPtr(s.LastRunAt)
+	return &s
+}
+
+func (r *jobScheduleRepository) List(ctx context.Context) ([]*models.JobSchedule, error) {
+	schedules := []*models.JobSchedule{}
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, s := range t.jobSchedules {
+			schedules = append(schedules, copyJobSchedule(s))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(schedules, func(i, j int) bool { return schedules[i].Name < schedules[j].Name })
+	return schedules, nil
+}
+
+func (r *jobScheduleRepository) GetByName(ctx context.Context, name string) (*models.JobSchedule, error) {
+	var schedule *models.JobSchedule
+	err := r.store.read(ctx, func(t *tables) error {
+		if s, ok := t.jobSchedules[name]; ok {
+			schedule = copyJobSchedule(s)
+		}
+		return nil
+	})
+	return schedule, err
+}
+
+func (r *jobScheduleRepository) Ensure(ctx context.Context, schedule *models.JobSchedule) error {
+	return r.store.write(ctx, func(t *tables) error {
+		now := time.Now()
+		row, exists := t.jobSchedules[schedule.Name]
+		if !exists {
+			row = *copyJobSchedule(*schedule)
+			row.CreatedAt = now
+		} else {
+			if row.Cron != schedule.Cron {
+				row.NextRunAt = schedule.NextRunAt
+			}
+			row.JobType = schedule.JobType
+			row.Cron = schedule.Cron
+			row.Payload = append([]byte(nil), schedule.Payload...)
+		}
+		row.UpdatedAt = now
+		t.jobSchedules[row.Name] = row
+		*schedule = *copyJobSchedule(row)
+		return nil
+	})
+}
+
+func (r *jobScheduleRepository) SetEnabled(ctx context.Context, name string, enabled bool) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.jobSchedules[name]
+		if !ok {
+			return errNoRows
+		}
+		row.Enabled = enabled
+		row.UpdatedAt = time.Now()
+		t.jobSchedules[name] = row
+		return nil
+	})
+}
+
+func (r *jobScheduleRepository) Advance(ctx context.Context, name string, dueAt, ranAt, nextRunAt time.Time) (bool, error) {
+	advanced := false
+	err := r.store.write(ctx, func(t *tables) error {
+		row, ok := t.jobSchedules[name]
+		if !ok || !row.NextRunAt.Equal(dueAt) {
+			return nil
+		}
+		row.LastRunAt = &ranAt
+		row.NextRunAt = nextRunAt
+		row.UpdatedAt = time.Now()
+		t.jobSchedules[name] = row
+		advanced = true
+		return nil
+	})
+	return advanced, err
+}
+
+var (
+	_ interfaces.JobRepository         = (*jobRepository)(nil)
+	_ interfaces.JobScheduleRepository = (*jobScheduleRepository)(nil)
+)
@@ -0,0 +1,360 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// quotaTriggerDays is how far ahead a position quota change marks the branch
+// summaries dirty, matching mark_quota_summary_dirty_on_quota_change
+const quotaTriggerDays = 30
+
+// summaryKey identifies a row of quota_summary_dirty
+type summaryKey struct {
+	branchID uuid.UUID
+	date     string
+}
+
+func newSummaryKey(branchID uuid.UUID, date time.Time) summaryKey {
+	return summaryKey{branchID: branchID, date: day(date).Format("2006-01-02")}
+}
+
+// markDirty mirrors the quota summary triggers: the branch and date is queued
+// for the summary worker, keeping the time it was first marked
+func markDirty(t *tables, branchID uuid.UUID, date time.Time) {
+	now := time.Now()
+	key := newSummaryKey(branchID, date)
+	if existing, ok := t.quotaSummaryDirty[key]; ok {
+		existing.MarkedAt = now
+		t.quotaSummaryDirty[key] = existing
+		return
+	}
+	t.quotaSummaryDirty[key] = models.QuotaSummaryDirtyKey{
+		BranchID:      branchID,
+		Date:          day(date),
+		FirstMarkedAt: now,
+		MarkedAt:      now,
+	}
+}
+
+// markDirtyWindow is the position quota trigger: every day from today through
+// quotaTriggerDays ahead
+func markDirtyWindow(t *tables, branchID uuid.UUID) {
+	start := today()
+	for i := 0; i <= quotaTriggerDays; i++ {
+		markDirty(t, branchID, start.AddDate(0, 0, i))
+	}
+}
+
+func findQuotaSummary(t *tables, branchID uuid.UUID, date time.Time) *models.BranchQuotaSummary {
+	date = day(date)
+	return find(t.branchQuotaSummaries, func(s *models.BranchQuotaSummary) bool {
+		return s.BranchID == branchID && s.Date.Equal(date)
+	})
+}
+
+// copySummary detaches the missing-staff slices from the stored row
+func copySummary(s *models.BranchQuotaSummary) *models.BranchQuotaSummary {
+	s.Group1MissingStaff = append([]string{}, s.Group1MissingStaff...)
+	s.Group2MissingStaff = append([]string{}, s.Group2MissingStaff...)
+	s.Group3MissingStaff = append([]string{}, s.Group3MissingStaff...)
+	return s
+}
+
+// PositionQuotaRepository implementation
+type positionQuotaRepository struct {
+	store *Store
+}
+
+func (r *positionQuotaRepository) Create(ctx context.Context, quota *models.PositionQuota) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.positionQuotas[quota.ID]; ok {
+			return uniqueViolation("position_quotas_pkey")
+		}
+		if _, ok := t.branches[quota.BranchID]; !ok {
+			return foreignKeyViolation("position_quotas", "position_quotas", "branch_id")
+		}
+		if _, ok := t.positions[quota.PositionID]; !ok {
+			return foreignKeyViolation("position_quotas", "position_quotas", "position_id")
+		}
+		// Usecase tests leave created_by unset; a set one must name a user
+		if quota.CreatedBy != uuid.Nil {
+			if _, ok := t.users[quota.CreatedBy]; !ok {
+				return foreignKeyViolation("position_quotas", "position_quotas", "created_by")
+			}
+		}
+		if find(t.positionQuotas, func(q *models.PositionQuota) bool {
+			return q.BranchID == quota.BranchID && q.PositionID == quota.PositionID
+		}) != nil {
+			return uniqueViolation("position_quotas_branch_id_position_id_key")
+		}
+		now := time.Now()
+		quota.CreatedAt, quota.UpdatedAt = now, now
+		row := *quota
+		row.Branch, row.Position = nil, nil
+		t.positionQuotas[row.ID] = row
+		markDirtyWindow(t, row.BranchID)
+		return nil
+	})
+}
+
+func (r *positionQuotaRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PositionQuota, error) {
+	var quota *models.PositionQuota
+	err := r.store.read(ctx, func(t *tables) error {
+		quota = get(t.positionQuotas, id)
+		return nil
+	})
+	return quota, err
+}
+
+func (r *positionQuotaRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID) ([]*models.PositionQuota, error) {
+	var quotas []*models.PositionQuota
+	err := r.store.read(ctx, func(t *tables) error {
+		quotas = collect(t.positionQuotas,
+			func(q *models.PositionQuota) bool { return q.BranchID == branchID },
+			func(a, b *models.PositionQuota) bool { return uuidLess(a.PositionID, b.PositionID) })
+		return nil
+	})
+	return quotas, err
+}
+
+func (r *positionQuotaRepository) GetByBranchAndPosition(ctx context.Context, branchID, positionID uuid.UUID) (*models.PositionQuota, error) {
+	var quota *models.PositionQuota
+	err := r.store.read(ctx, func(t *tables) error {
+		quota = find(t.positionQuotas, func(q *models.PositionQuota) bool {
+			return q.BranchID == branchID && q.PositionID == positionID
+		})
+		return nil
+	})
+	return quota, err
+}
+
+func (r *positionQuotaRepository) Update(ctx context.Context, quota *models.PositionQuota) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.positionQuotas[quota.ID]
+		if !ok {
+			return errNoRows
+		}
+		row.DesignatedQuota = quota.DesignatedQuota
+		row.MinimumRequired = quota.MinimumRequired
+		row.IsActive = quota.IsActive
+		row.UpdatedAt = time.Now()
+		t.positionQuotas[row.ID] = row
+		quota.UpdatedAt = row.UpdatedAt
+		markDirtyWindow(t, row.BranchID)
+		return nil
+	})
+}
+
+func (r *positionQuotaRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.positionQuotas[id]
+		if !ok {
+			return nil
+		}
+		delete(t.positionQuotas, id)
+		markDirtyWindow(t, row.BranchID)
+		return nil
+	})
+}
+
+func (r *positionQuotaRepository) List(ctx context.Context, filters interfaces.PositionQuotaFilters) ([]*models.PositionQuota, error) {
+	var quotas []*models.PositionQuota
+	err := r.store.read(ctx, func(t *tables) error {
+		quotas = collect(t.positionQuotas,
+			func(q *models.PositionQuota) bool {
+				if filters.BranchID != nil && q.BranchID != *filters.BranchID {
+					return false
+				}
+				if filters.PositionID != nil && q.PositionID != *filters.PositionID {
+					return false
+				}
+				if filters.IsActive != nil && q.IsActive != *filters.IsActive {
+					return false
+				}
+				return true
+			},
+			func(a, b *models.PositionQuota) bool {
+				if a.BranchID != b.BranchID {
+					return uuidLess(a.BranchID, b.BranchID)
+				}
+				return uuidLess(a.PositionID, b.PositionID)
+			})
+		return nil
+	})
+	return quotas, err
+}
+
+// BranchQuotaSummaryRepository implementation
+type branchQuotaSummaryRepository struct {
+	store *Store
+}
+
+func (r *branchQuotaSummaryRepository) GetByBranchIDAndDate(ctx context.Context, branchID uuid.UUID, date time.Time) (*models.BranchQuotaSummary, error) {
+	var summary *models.BranchQuotaSummary
+	err := r.store.read(ctx, func(t *tables) error {
+		if summary = findQuotaSummary(t, branchID, date); summary != nil {
+			copySummary(summary)
+		}
+		return nil
+	})
+	return summary, err
+}
+
+func (r *branchQuotaSummaryRepository) GetByBranchIDsAndDate(ctx context.Context, branchIDs []uuid.UUID, date time.Time) ([]*models.BranchQuotaSummary, error) {
+	return r.GetByBranchIDsAndDateRange(ctx, branchIDs, date, date)
+}
+
+func (r *branchQuotaSummaryRepository) GetByBranchIDsAndDateRange(ctx context.Context, branchIDs []uuid.UUID, startDate, endDate time.Time) ([]*models.BranchQuotaSummary, error) {
+	summaries := []*models.BranchQuotaSummary{}
+	if len(branchIDs) == 0 {
+		return summaries, nil
+	}
+
+	wanted := make(map[uuid.UUID]bool, len(branchIDs))
+	for _, id := range branchIDs {
+		wanted[id] = true
+	}
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, s := range collect(t.branchQuotaSummaries,
+			func(s *models.BranchQuotaSummary) bool {
+				return wanted[s.BranchID] && inRange(s.Date, startDate, endDate)
+			},
+			func(a, b *models.BranchQuotaSummary) bool {
+				if a.BranchID != b.BranchID {
+					return uuidLess(a.BranchID, b.BranchID)
+				}
+				return a.Date.Before(b.Date)
+			}) {
+			summaries = append(summaries, copySummary(s))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+func (r *branchQuotaSummaryRepository) Upsert(ctx context.Context, summary *models.BranchQuotaSummary) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.branches[summary.BranchID]; !ok {
+			return foreignKeyViolation("branch_quota_daily_summary", "branch_quota_daily_summary", "branch_id")
+		}
+		now := time.Now()
+		row := *summary
+		row.Date = day(summary.Date)
+		row.Group1MissingStaff = append([]string{}, summary.Group1MissingStaff...)
+		row.Group2MissingStaff = append([]string{}, summary.Group2MissingStaff...)
+		row.Group3MissingStaff = append([]string{}, summary.Group3MissingStaff...)
+		row.UpdatedAt = now
+		if existing := findQuotaSummary(t, summary.BranchID, summary.Date); existing != nil {
+			// ON CONFLICT keeps the row and the time of its first calculation
+			row.ID = existing.ID
+			row.CalculatedAt = existing.CalculatedAt
+		} else {
+			row.ID = uuid.New()
+			row.CalculatedAt = now
+		}
+		t.branchQuotaSummaries[row.ID] = row
+		summary.ID, summary.CalculatedAt, summary.UpdatedAt = row.ID, row.CalculatedAt, row.UpdatedAt
+		return nil
+	})
+}
+
+func (r *branchQuotaSummaryRepository) MarkDirty(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.branches[branchID]; !ok {
+			return foreignKeyViolation("quota_summary_dirty", "quota_summary_dirty", "branch_id")
+		}
+		for d := day(startDate); !d.After(day(endDate)); d = d.AddDate(0, 0, 1) {
+			markDirty(t, branchID, d)
+		}
+		return nil
+	})
+}
+
+func (r *branchQuotaSummaryRepository) MarkStale(ctx context.Context, branchID uuid.UUID, date time.Time) error {
+	return r.store.write(ctx, func(t *tables) error {
+		for _, s := range t.branchQuotaSummaries {
+			if (branchID == uuid.Nil || s.BranchID == branchID) && (date.IsZero() || day(s.Date).Equal(day(date))) {
+				markDirty(t, s.BranchID, s.Date)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *branchQuotaSummaryRepository) ListDirty(ctx context.Context, debounce, maxWait time.Duration, limit int) ([]*models.QuotaSummaryDirtyKey, error) {
+	now := time.Now()
+	return r.listDirty(ctx, func(k *models.QuotaSummaryDirtyKey) bool {
+		return !k.MarkedAt.After(now.Add(-debounce)) || !k.FirstMarkedAt.After(now.Add(-maxWait))
+	}, func(a, b *models.QuotaSummaryDirtyKey) bool {
+		if !a.FirstMarkedAt.Equal(b.FirstMarkedAt) {
+			return a.FirstMarkedAt.Before(b.FirstMarkedAt)
+		}
+		return dirtyKeyLess(a, b)
+	}, limit)
+}
+
+func (r *branchQuotaSummaryRepository) GetDirtyByBranchIDsAndDateRange(ctx context.Context, branchIDs []uuid.UUID, startDate, endDate time.Time) ([]*models.QuotaSummaryDirtyKey, error) {
+	wanted := make(map[uuid.UUID]bool, len(branchIDs))
+	for _, id := range branchIDs {
+		wanted[id] = true
+	}
+	return r.listDirty(ctx, func(k *models.QuotaSummaryDirtyKey) bool {
+		return wanted[k.BranchID] && inRange(k.Date, startDate, endDate)
+	}, dirtyKeyLess, 0)
+}
+
+// listDirty returns copies of the matching queue rows in order, at most limit when positive
+func (r *branchQuotaSummaryRepository) listDirty(ctx context.Context, keep func(*models.QuotaSummaryDirtyKey) bool, less func(a, b *models.QuotaSummaryDirtyKey) bool, limit int) ([]*models.QuotaSummaryDirtyKey, error) {
+	keys := []*models.QuotaSummaryDirtyKey{}
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, k := range t.quotaSummaryDirty {
+			if keep(&k) {
+				row := k
+				keys = append(keys, &row)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+	if limit > 0 && len(keys) > limit {
+		keys = keys[:limit]
+	}
+	return keys, nil
+}
+
+func (r *branchQuotaSummaryRepository) ClearDirty(ctx context.Context, keys []*models.QuotaSummaryDirtyKey) error {
+	return r.store.write(ctx, func(t *tables) error {
+		for _, k := range keys {
+			key := newSummaryKey(k.BranchID, k.Date)
+			if existing, ok := t.quotaSummaryDirty[key]; ok && existing.MarkedAt.Equal(k.MarkedAt) {
+				delete(t.quotaSummaryDirty, key)
+			}
+		}
+		return nil
+	})
+}
+
+func dirtyKeyLess(a, b *models.QuotaSummaryDirtyKey) bool {
+	if a.BranchID != b.BranchID {
+		return uuidLess(a.BranchID, b.BranchID)
+	}
+	return a.Date.Before(b.Date)
+}
+
+var (
+	_ interfaces.PositionQuotaRepository      = (*positionQuotaRepository)(nil)
+	_ interfaces.BranchQuotaSummaryRepository = (*branchQuotaSummaryRepository)(nil)
+)
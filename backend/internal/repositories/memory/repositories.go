@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+)
+
+// Repositories is the set of in-memory repositories over one store, plus the
+// unit of work for composing them into a single transaction
+type Repositories struct {
+	interfaces.Repositories
+	UnitOfWork interfaces.UnitOfWork
+	Store      *Store
+}
+
+// NewRepositories creates repositories over a new, empty store
+func NewRepositories() *Repositories {
+	store := NewStore()
+	return &Repositories{
+		Repositories: *newRepositorySet(store),
+		UnitOfWork:   NewUnitOfWork(store),
+		Store:        store,
+	}
+}
+
+// newRepositorySet builds every repository on store, which is either the root
+// store or a transaction snapshot
+func newRepositorySet(store *Store) *interfaces.Repositories {
+	repos := &interfaces.Repositories{
+		User:                             &userRepository{store: store},
+		UserIdentity:                     &userIdentityRepository{store: store},
+		Role:                             &roleRepository{store: store},
+		Staff:                            &staffRepository{store: store},
+		Position:                         &positionRepository{store: store},
+		Branch:                           &branchRepository{store: store},
+		EffectiveBranch:                  &effectiveBranchRepository{store: store},
+		Revenue:                          &revenueRepository{store: store},
+		Schedule:                         &scheduleRepository{store: store},
+		Rotation:                         &rotationRepository{store: store},
+		RotationStaffSchedule:            &rotationStaffScheduleRepository{store: store},
+		Settings:                         &settingsRepository{store: store},
+		AllocationRule:                   &allocationRuleRepository{store: store},
+		AreaOfOperation:                  &areaOfOperationRepository{store: store},
+		Zone:                             &zoneRepository{store: store},
+		AllocationCriteria:               &allocationCriteriaRepository{store: store},
+		PositionQuota:                    &positionQuotaRepository{store: store},
+		Doctor:                           &doctorRepository{store: store},
+		DoctorPreference:                 &doctorPreferenceRepository{store: store},
+		DoctorOnOffDay:                   &doctorOnOffDayRepository{store: store},
+		DoctorDefaultSchedule:            &doctorDefaultScheduleRepository{store: store},
+		DoctorWeeklyOffDay:               &doctorWeeklyOffDayRepository{store: store},
+		DoctorScheduleOverride:           &doctorScheduleOverrideRepository{store: store},
+		DoctorRevenueProfile:             &doctorRevenueProfileRepository{store: store},
+		DoctorRevenueOverride:            &doctorRevenueOverrideRepository{store: store},
+		DoctorLeave:                      &doctorLeaveRepository{store: store},
+		DoctorRoster:                     &doctorRosterRepository{store: store},
+		BranchWeeklyRevenue:              &branchWeeklyRevenueRepository{store: store},
+		BranchConstraints:                &branchConstraintsRepository{store: store},
+		BranchClosure:                    &branchClosureRepository{store: store},
+		RevenueLevelTier:                 &revenueLevelTierRepository{store: store},
+		StaffRequirementScenario:         &staffRequirementScenarioRepository{store: store},
+		ScenarioPositionRequirement:      &scenarioPositionRequirementRepository{store: store},
+		ScenarioSpecificStaffRequirement: &scenarioSpecificStaffRequirementRepository{store: store},
+		BranchType:                       &branchTypeRepository{store: store},
+		StaffGroup:                       &staffGroupRepository{store: store},
+		StaffGroupPosition:               &staffGroupPositionRepository{store: store},
+		BranchTypeRequirement:            &branchTypeRequirementRepository{store: store},
+		BranchTypeConstraints:            &branchTypeConstraintsRepository{store: store},
+		SpecificPreference:               &specificPreferenceRepository{store: store},
+		ClinicWidePreference:             &clinicWidePreferenceRepository{store: store},
+		PreferencePositionRequirement:    &preferencePositionRequirementRepository{store: store},
+		RotationStaffBranchPosition:      &rotationStaffBranchPositionRepository{store: store},
+		AllocationSuggestion:             &allocationSuggestionRepository{store: store},
+		BranchQuotaSummary:               &branchQuotaSummaryRepository{store: store},
+		ServiceAccount:                   &serviceAccountRepository{store: store},
+		APIToken:                         &apiTokenRepository{store: store},
+		Job:                              &jobRepository{store: store},
+		JobSchedule:                      &jobScheduleRepository{store: store},
+	}
+
+	// DoctorAssignment derives assignments from the schedule repositories, so
+	// create it after them
+	repos.DoctorAssignment = newDoctorAssignmentRepository(
+		store,
+		repos.DoctorDefaultSchedule,
+		repos.DoctorWeeklyOffDay,
+		repos.DoctorScheduleOverride,
+		repos.Doctor,
+		repos.DoctorRevenueProfile,
+		repos.DoctorRevenueOverride,
+		repos.DoctorLeave,
+		repos.DoctorRoster,
+	)
+
+	return repos
+}
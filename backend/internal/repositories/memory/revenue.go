@@ -0,0 +1,186 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// RevenueRepository implementation
+type revenueRepository struct {
+	store *Store
+}
+
+func findRevenue(t *tables, branchID uuid.UUID, date time.Time) *models.RevenueData {
+	date = day(date)
+	return find(t.revenue, func(rv *models.RevenueData) bool {
+		return rv.BranchID == branchID && rv.Date.Equal(date)
+	})
+}
+
+func (r *revenueRepository) Create(ctx context.Context, revenue *models.RevenueData) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.revenue[revenue.ID]; ok {
+			return uniqueViolation("revenue_data_pkey")
+		}
+		if _, ok := t.branches[revenue.BranchID]; !ok {
+			return foreignKeyViolation("revenue_data", "revenue_data", "branch_id")
+		}
+		if findRevenue(t, revenue.BranchID, revenue.Date) != nil {
+			return uniqueViolation("revenue_data_branch_id_date_key")
+		}
+		now := time.Now()
+		revenue.CreatedAt, revenue.UpdatedAt = now, now
+		row := *revenue
+		row.Branch = nil
+		row.Date = day(row.Date)
+		row.ActualRevenue = copyPtr(row.ActualRevenue)
+		if row.RevenueSource == "" {
+			row.RevenueSource = "branch"
+		}
+		t.revenue[row.ID] = row
+		return nil
+	})
+}
+
+func (r *revenueRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) ([]*models.RevenueData, error) {
+	var revenues []*models.RevenueData
+	err := r.store.read(ctx, func(t *tables) error {
+		revenues = collect(t.revenue,
+			func(rv *models.RevenueData) bool {
+				return rv.BranchID == branchID && inRange(rv.Date, startDate, endDate)
+			},
+			func(a, b *models.RevenueData) bool { return a.Date.Before(b.Date) })
+		return nil
+	})
+	return revenues, err
+}
+
+func (r *revenueRepository) GetByDate(ctx context.Context, date time.Time) ([]*models.RevenueData, error) {
+	var revenues []*models.RevenueData
+	err := r.store.read(ctx, func(t *tables) error {
+		date := day(date)
+		revenues = collect(t.revenue,
+			func(rv *models.RevenueData) bool { return rv.Date.Equal(date) },
+			func(a, b *models.RevenueData) bool { return uuidLess(a.BranchID, b.BranchID) })
+		return nil
+	})
+	return revenues, err
+}
+
+func (r *revenueRepository) GetByBranchIDsAndDateRange(ctx context.Context, branchIDs []uuid.UUID, startDate, endDate time.Time) ([]*models.RevenueData, error) {
+	revenues := []*models.RevenueData{}
+	if len(branchIDs) == 0 {
+		return revenues, nil
+	}
+
+	wanted := make(map[uuid.UUID]bool, len(branchIDs))
+	for _, id := range branchIDs {
+		wanted[id] = true
+	}
+	err := r.store.read(ctx, func(t *tables) error {
+		revenues = collect(t.revenue,
+			func(rv *models.RevenueData) bool {
+				return wanted[rv.BranchID] && inRange(rv.Date, startDate, endDate)
+			},
+			func(a, b *models.RevenueData) bool {
+				if a.BranchID != b.BranchID {
+					return uuidLess(a.BranchID, b.BranchID)
+				}
+				return a.Date.Before(b.Date)
+			})
+		return nil
+	})
+	return revenues, err
+}
+
+func (r *revenueRepository) Update(ctx context.Context, revenue *models.RevenueData) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.revenue[revenue.ID]
+		if !ok {
+			return nil
+		}
+		row.ExpectedRevenue = revenue.ExpectedRevenue
+		row.SkinRevenue = revenue.SkinRevenue
+		row.LSHMRevenue = revenue.LSHMRevenue
+		row.VitaminCases = revenue.VitaminCases
+		row.SlimPenCases = revenue.SlimPenCases
+		row.ActualRevenue = copyPtr(revenue.ActualRevenue)
+		row.RevenueSource = revenue.RevenueSource
+		if row.RevenueSource == "" {
+			row.RevenueSource = "branch"
+		}
+		row.UpdatedAt = time.Now()
+		t.revenue[row.ID] = row
+		return nil
+	})
+}
+
+func (r *revenueRepository) BulkCreateOrUpdate(ctx context.Context, revenues []*models.RevenueData) error {
+	if len(revenues) == 0 {
+		return nil
+	}
+
+	// The whole batch is applied under one write lock, so a failure part way
+	// leaves nothing behind, like the postgres transaction
+	return r.store.write(ctx, func(t *tables) error {
+		staged := &tables{branches: t.branches, revenue: maps.Clone(t.revenue)}
+		for _, revenue := range revenues {
+			source := revenue.RevenueSource
+			if source == "" {
+				source = "excel" // Default for imports
+			}
+			if revenue.ID == uuid.Nil {
+				revenue.ID = uuid.New()
+			}
+			dateOnly := day(revenue.Date)
+
+			if _, ok := staged.branches[revenue.BranchID]; !ok {
+				return fmt.Errorf("failed to insert/update revenue for branch %s on date %s: %w",
+					revenue.BranchID, dateOnly.Format("2006-01-02"),
+					foreignKeyViolation("revenue_data", "revenue_data", "branch_id"))
+			}
+
+			now := time.Now()
+			if existing := findRevenue(staged, revenue.BranchID, dateOnly); existing != nil {
+				existing.ExpectedRevenue = revenue.ExpectedRevenue
+				existing.SkinRevenue = revenue.SkinRevenue
+				existing.LSHMRevenue = revenue.LSHMRevenue
+				existing.VitaminCases = revenue.VitaminCases
+				existing.SlimPenCases = revenue.SlimPenCases
+				existing.RevenueSource = source
+				existing.UpdatedAt = now
+				staged.revenue[existing.ID] = *existing
+				continue
+			}
+			if _, ok := staged.revenue[revenue.ID]; ok {
+				return fmt.Errorf("failed to insert/update revenue for branch %s on date %s: %w",
+					revenue.BranchID, dateOnly.Format("2006-01-02"), uniqueViolation("revenue_data_pkey"))
+			}
+			staged.revenue[revenue.ID] = models.RevenueData{
+				ID:              revenue.ID,
+				BranchID:        revenue.BranchID,
+				Date:            dateOnly,
+				ExpectedRevenue: revenue.ExpectedRevenue,
+				SkinRevenue:     revenue.SkinRevenue,
+				LSHMRevenue:     revenue.LSHMRevenue,
+				VitaminCases:    revenue.VitaminCases,
+				SlimPenCases:    revenue.SlimPenCases,
+				ActualRevenue:   copyPtr(revenue.ActualRevenue),
+				RevenueSource:   source,
+				CreatedAt:       now,
+				UpdatedAt:       now,
+			}
+		}
+		t.revenue = staged.revenue
+		return nil
+	})
+}
+
+var _ interfaces.RevenueRepository = (*revenueRepository)(nil)
@@ -0,0 +1,793 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// StaffRequirementScenarioRepository implementation
+type staffRequirementScenarioRepository struct {
+	store *Store
+}
+
+// scenarioRow detaches the nullable columns of a scenario and drops the
+// relations that are loaded separately
+func scenarioRow(s *models.StaffRequirementScenario) models.StaffRequirementScenario {
+	row := *s
+	row.Doctor, row.Branch, row.RevenueLevelTier = nil, nil, nil
+	row.PositionRequirements, row.SpecificStaffRequirements = nil, nil
+	row.Description = copyPtr(s.Description)
+	row.DoctorID = copyUUID(s.DoctorID)
+	row.BranchID = copyUUID(s.BranchID)
+	row.RevenueLevelTierID = copyUUID(s.RevenueLevelTierID)
+	row.MinRevenue = copyPtr(s.MinRevenue)
+	row.MaxRevenue = copyPtr(s.MaxRevenue)
+	row.DoctorCount = copyPtr(s.DoctorCount)
+	row.MinDoctorCount = copyPtr(s.MinDoctorCount)
+	row.DayOfWeek = copyPtr(s.DayOfWeek)
+	return row
+}
+
+func checkScenario(t *tables, s *models.StaffRequirementScenario) error {
+	if s.DoctorID != nil {
+		if _, ok := t.doctors[*s.DoctorID]; !ok {
+			return foreignKeyViolation("staff_requirement_scenarios", "staff_requirement_scenarios", "doctor_id")
+		}
+	}
+	if s.BranchID != nil {
+		if _, ok := t.branches[*s.BranchID]; !ok {
+			return foreignKeyViolation("staff_requirement_scenarios", "staff_requirement_scenarios", "branch_id")
+		}
+	}
+	if s.RevenueLevelTierID != nil {
+		if _, ok := t.revenueLevelTiers[*s.RevenueLevelTierID]; !ok {
+			return foreignKeyViolation("staff_requirement_scenarios", "staff_requirement_scenarios", "revenue_level_tier_id")
+		}
+	}
+	return nil
+}
+
+func (r *staffRequirementScenarioRepository) list(ctx context.Context, includeInactive bool) ([]*models.StaffRequirementScenario, error) {
+	scenarios := []*models.StaffRequirementScenario{}
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, s := range collect(t.scenarios,
+			func(s *models.StaffRequirementScenario) bool { return includeInactive || s.IsActive },
+			func(a, b *models.StaffRequirementScenario) bool {
+				if a.Priority != b.Priority {
+					return a.Priority > b.Priority
+				}
+				return a.ScenarioName < b.ScenarioName
+			}) {
+			row := scenarioRow(s)
+			scenarios = append(scenarios, &row)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return scenarios, nil
+}
+
+func (r *staffRequirementScenarioRepository) Create(ctx context.Context, scenario *models.StaffRequirementScenario) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.scenarios[scenario.ID]; ok {
+			return uniqueViolation("staff_requirement_scenarios_pkey")
+		}
+		if err := checkScenario(t, scenario); err != nil {
+			return err
+		}
+		now := time.Now()
+		scenario.CreatedAt, scenario.UpdatedAt = now, now
+		t.scenarios[scenario.ID] = scenarioRow(scenario)
+		return nil
+	})
+}
+
+func (r *staffRequirementScenarioRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.StaffRequirementScenario, error) {
+	var scenario *models.StaffRequirementScenario
+	err := r.store.read(ctx, func(t *tables) error {
+		if s, ok := t.scenarios[id]; ok {
+			row := scenarioRow(&s)
+			scenario = &row
+		}
+		return nil
+	})
+	return scenario, err
+}
+
+func (r *staffRequirementScenarioRepository) Update(ctx context.Context, scenario *models.StaffRequirementScenario) error {
+	return r.store.write(ctx, func(t *tables) error {
+		existing, ok := t.scenarios[scenario.ID]
+		if !ok {
+			return errNoRows
+		}
+		if err := checkScenario(t, scenario); err != nil {
+			return err
+		}
+		row := scenarioRow(scenario)
+		row.CreatedAt = existing.CreatedAt
+		row.UpdatedAt = time.Now()
+		t.scenarios[row.ID] = row
+		scenario.UpdatedAt = row.UpdatedAt
+		return nil
+	})
+}
+
+func (r *staffRequirementScenarioRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.scenarios, id)
+		deleteWhere(t.scenarioPositions, func(p *models.ScenarioPositionRequirement) bool { return p.ScenarioID == id })
+		deleteWhere(t.scenarioSpecificStaff, func(s *models.ScenarioSpecificStaffRequirement) bool { return s.ScenarioID == id })
+		return nil
+	})
+}
+
+func (r *staffRequirementScenarioRepository) List(ctx context.Context, includeInactive bool) ([]*models.StaffRequirementScenario, error) {
+	return r.list(ctx, includeInactive)
+}
+
+func (r *staffRequirementScenarioRepository) GetActiveOrderedByPriority(ctx context.Context) ([]*models.StaffRequirementScenario, error) {
+	return r.list(ctx, false)
+}
+
+func (r *staffRequirementScenarioRepository) GetDefault(ctx context.Context) (*models.StaffRequirementScenario, error) {
+	var scenario *models.StaffRequirementScenario
+	err := r.store.read(ctx, func(t *tables) error {
+		if s := find(t.scenarios, func(s *models.StaffRequirementScenario) bool { return s.IsDefault }); s != nil {
+			row := scenarioRow(s)
+			scenario = &row
+		}
+		return nil
+	})
+	return scenario, err
+}
+
+// ScenarioPositionRequirementRepository implementation
+type scenarioPositionRequirementRepository struct {
+	store *Store
+}
+
+func findScenarioPosition(t *tables, scenarioID, positionID uuid.UUID) *models.ScenarioPositionRequirement {
+	return find(t.scenarioPositions, func(p *models.ScenarioPositionRequirement) bool {
+		return p.ScenarioID == scenarioID && p.PositionID == positionID
+	})
+}
+
+func checkScenarioPosition(t *tables, requirement *models.ScenarioPositionRequirement) error {
+	if _, ok := t.scenarios[requirement.ScenarioID]; !ok {
+		return foreignKeyViolation("scenario_position_requirements", "scenario_position_requirements", "scenario_id")
+	}
+	if _, ok := t.positions[requirement.PositionID]; !ok {
+		return foreignKeyViolation("scenario_position_requirements", "scenario_position_requirements", "position_id")
+	}
+	return nil
+}
+
+func (r *scenarioPositionRequirementRepository) Create(ctx context.Context, requirement *models.ScenarioPositionRequirement) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.scenarioPositions[requirement.ID]; ok {
+			return uniqueViolation("scenario_position_requirements_pkey")
+		}
+		if err := checkScenarioPosition(t, requirement); err != nil {
+			return err
+		}
+		if findScenarioPosition(t, requirement.ScenarioID, requirement.PositionID) != nil {
+			return uniqueViolation("scenario_position_requirements_scenario_id_position_id_key")
+		}
+		now := time.Now()
+		requirement.CreatedAt, requirement.UpdatedAt = now, now
+		row := *requirement
+		row.Position = nil
+		t.scenarioPositions[row.ID] = row
+		return nil
+	})
+}
+
+func (r *scenarioPositionRequirementRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ScenarioPositionRequirement, error) {
+	var requirement *models.ScenarioPositionRequirement
+	err := r.store.read(ctx, func(t *tables) error {
+		requirement = get(t.scenarioPositions, id)
+		return nil
+	})
+	return requirement, err
+}
+
+func (r *scenarioPositionRequirementRepository) GetByScenarioID(ctx context.Context, scenarioID uuid.UUID) ([]*models.ScenarioPositionRequirement, error) {
+	var requirements []*models.ScenarioPositionRequirement
+	err := r.store.read(ctx, func(t *tables) error {
+		requirements = collect(t.scenarioPositions,
+			func(p *models.ScenarioPositionRequirement) bool { return p.ScenarioID == scenarioID },
+			func(a, b *models.ScenarioPositionRequirement) bool { return uuidLess(a.PositionID, b.PositionID) })
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nonNil(requirements), nil
+}
+
+func (r *scenarioPositionRequirementRepository) GetByScenarioAndPosition(ctx context.Context, scenarioID, positionID uuid.UUID) (*models.ScenarioPositionRequirement, error) {
+	var requirement *models.ScenarioPositionRequirement
+	err := r.store.read(ctx, func(t *tables) error {
+		requirement = findScenarioPosition(t, scenarioID, positionID)
+		return nil
+	})
+	return requirement, err
+}
+
+func (r *scenarioPositionRequirementRepository) Update(ctx context.Context, requirement *models.ScenarioPositionRequirement) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.scenarioPositions[requirement.ID]
+		if !ok {
+			return errNoRows
+		}
+		row.PreferredStaff = requirement.PreferredStaff
+		row.MinimumStaff = requirement.MinimumStaff
+		row.OverrideBase = requirement.OverrideBase
+		row.UpdatedAt = time.Now()
+		t.scenarioPositions[row.ID] = row
+		requirement.UpdatedAt = row.UpdatedAt
+		return nil
+	})
+}
+
+func (r *scenarioPositionRequirementRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.scenarioPositions, id)
+		return nil
+	})
+}
+
+func (r *scenarioPositionRequirementRepository) DeleteByScenarioID(ctx context.Context, scenarioID uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		deleteWhere(t.scenarioPositions, func(p *models.ScenarioPositionRequirement) bool { return p.ScenarioID == scenarioID })
+		return nil
+	})
+}
+
+func (r *scenarioPositionRequirementRepository) BulkUpsert(ctx context.Context, requirements []*models.ScenarioPositionRequirement) error {
+	return r.store.write(ctx, func(t *tables) error {
+		for _, requirement := range requirements {
+			if err := checkScenarioPosition(t, requirement); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		for _, requirement := range requirements {
+			if requirement.ID == uuid.Nil {
+				requirement.ID = uuid.New()
+			}
+			if existing := findScenarioPosition(t, requirement.ScenarioID, requirement.PositionID); existing != nil {
+				existing.PreferredStaff = requirement.PreferredStaff
+				existing.MinimumStaff = requirement.MinimumStaff
+				existing.OverrideBase = requirement.OverrideBase
+				existing.UpdatedAt = now
+				t.scenarioPositions[existing.ID] = *existing
+				requirement.CreatedAt, requirement.UpdatedAt = existing.CreatedAt, existing.UpdatedAt
+				continue
+			}
+			requirement.CreatedAt, requirement.UpdatedAt = now, now
+			row := *requirement
+			row.Position = nil
+			t.scenarioPositions[row.ID] = row
+		}
+		return nil
+	})
+}
+
+// ScenarioSpecificStaffRequirementRepository implementation
+type scenarioSpecificStaffRequirementRepository struct {
+	store *Store
+}
+
+func findScenarioStaff(t *tables, scenarioID, staffID uuid.UUID) *models.ScenarioSpecificStaffRequirement {
+	return find(t.scenarioSpecificStaff, func(s *models.ScenarioSpecificStaffRequirement) bool {
+		return s.ScenarioID == scenarioID && s.StaffID == staffID
+	})
+}
+
+func checkScenarioStaff(t *tables, requirement *models.ScenarioSpecificStaffRequirement) error {
+	if _, ok := t.scenarios[requirement.ScenarioID]; !ok {
+		return foreignKeyViolation("scenario_specific_staff_requirements", "scenario_specific_staff_requirements", "scenario_id")
+	}
+	if _, ok := t.staff[requirement.StaffID]; !ok {
+		return foreignKeyViolation("scenario_specific_staff_requirements", "scenario_specific_staff_requirements", "staff_id")
+	}
+	return nil
+}
+
+func (r *scenarioSpecificStaffRequirementRepository) Create(ctx context.Context, requirement *models.ScenarioSpecificStaffRequirement) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.scenarioSpecificStaff[requirement.ID]; ok {
+			return uniqueViolation("scenario_specific_staff_requirements_pkey")
+		}
+		if err := checkScenarioStaff(t, requirement); err != nil {
+			return err
+		}
+		if findScenarioStaff(t, requirement.ScenarioID, requirement.StaffID) != nil {
+			return uniqueViolation("scenario_specific_staff_requirements_scenario_id_staff_id_key")
+		}
+		now := time.Now()
+		requirement.CreatedAt, requirement.UpdatedAt = now, now
+		row := *requirement
+		row.Staff = nil
+		t.scenarioSpecificStaff[row.ID] = row
+		return nil
+	})
+}
+
+func (r *scenarioSpecificStaffRequirementRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ScenarioSpecificStaffRequirement, error) {
+	var requirement *models.ScenarioSpecificStaffRequirement
+	err := r.store.read(ctx, func(t *tables) error {
+		requirement = get(t.scenarioSpecificStaff, id)
+		return nil
+	})
+	return requirement, err
+}
+
+func (r *scenarioSpecificStaffRequirementRepository) GetByScenarioID(ctx context.Context, scenarioID uuid.UUID) ([]*models.ScenarioSpecificStaffRequirement, error) {
+	var requirements []*models.ScenarioSpecificStaffRequirement
+	err := r.store.read(ctx, func(t *tables) error {
+		requirements = collect(t.scenarioSpecificStaff,
+			func(s *models.ScenarioSpecificStaffRequirement) bool { return s.ScenarioID == scenarioID },
+			func(a, b *models.ScenarioSpecificStaffRequirement) bool { return uuidLess(a.StaffID, b.StaffID) })
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nonNil(requirements), nil
+}
+
+func (r *scenarioSpecificStaffRequirementRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.scenarioSpecificStaff, id)
+		return nil
+	})
+}
+
+func (r *scenarioSpecificStaffRequirementRepository) DeleteByScenarioID(ctx context.Context, scenarioID uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		deleteWhere(t.scenarioSpecificStaff, func(s *models.ScenarioSpecificStaffRequirement) bool { return s.ScenarioID == scenarioID })
+		return nil
+	})
+}
+
+func (r *scenarioSpecificStaffRequirementRepository) BulkUpsert(ctx context.Context, requirements []*models.ScenarioSpecificStaffRequirement) error {
+	return r.store.write(ctx, func(t *tables) error {
+		for _, requirement := range requirements {
+			if err := checkScenarioStaff(t, requirement); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		for _, requirement := range requirements {
+			if requirement.ID == uuid.Nil {
+				requirement.ID = uuid.New()
+			}
+			if existing := findScenarioStaff(t, requirement.ScenarioID, requirement.StaffID); existing != nil {
+				existing.UpdatedAt = now
+				t.scenarioSpecificStaff[existing.ID] = *existing
+				requirement.CreatedAt, requirement.UpdatedAt = existing.CreatedAt, existing.UpdatedAt
+				continue
+			}
+			requirement.CreatedAt, requirement.UpdatedAt = now, now
+			row := *requirement
+			row.Staff = nil
+			t.scenarioSpecificStaff[row.ID] = row
+		}
+		return nil
+	})
+}
+
+// ClinicWidePreferenceRepository implementation
+type clinicWidePreferenceRepository struct {
+	store *Store
+}
+
+func clinicPreferenceRow(p *models.ClinicWidePreference) models.ClinicWidePreference {
+	row := *p
+	row.PositionRequirements = nil
+	row.MaxValue = copyPtr(p.MaxValue)
+	row.Description = copyPtr(p.Description)
+	return row
+}
+
+func (r *clinicWidePreferenceRepository) collect(ctx context.Context, keep func(p *models.ClinicWidePreference) bool, less func(a, b *models.ClinicWidePreference) bool) ([]*models.ClinicWidePreference, error) {
+	preferences := []*models.ClinicWidePreference{}
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, p := range collect(t.clinicPreferences, keep, less) {
+			row := clinicPreferenceRow(p)
+			preferences = append(preferences, &row)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+func (r *clinicWidePreferenceRepository) Create(ctx context.Context, preference *models.ClinicWidePreference) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.clinicPreferences[preference.ID]; ok {
+			return uniqueViolation("clinic_wide_preferences_pkey")
+		}
+		now := time.Now()
+		preference.CreatedAt, preference.UpdatedAt = now, now
+		t.clinicPreferences[preference.ID] = clinicPreferenceRow(preference)
+		return nil
+	})
+}
+
+func (r *clinicWidePreferenceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ClinicWidePreference, error) {
+	var preference *models.ClinicWidePreference
+	err := r.store.read(ctx, func(t *tables) error {
+		if p, ok := t.clinicPreferences[id]; ok {
+			row := clinicPreferenceRow(&p)
+			preference = &row
+		}
+		return nil
+	})
+	return preference, err
+}
+
+func (r *clinicWidePreferenceRepository) Update(ctx context.Context, preference *models.ClinicWidePreference) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.clinicPreferences[preference.ID]
+		if !ok {
+			return errNoRows
+		}
+		row.CriteriaName = preference.CriteriaName
+		row.MinValue = preference.MinValue
+		row.MaxValue = copyPtr(preference.MaxValue)
+		row.IsActive = preference.IsActive
+		row.DisplayOrder = preference.DisplayOrder
+		row.Description = copyPtr(preference.Description)
+		row.UpdatedAt = time.Now()
+		t.clinicPreferences[row.ID] = row
+		preference.UpdatedAt = row.UpdatedAt
+		return nil
+	})
+}
+
+func (r *clinicWidePreferenceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.clinicPreferences, id)
+		deleteWhere(t.preferencePositions, func(p *models.PreferencePositionRequirement) bool { return p.PreferenceID == id })
+		return nil
+	})
+}
+
+func (r *clinicWidePreferenceRepository) List(ctx context.Context, filters models.ClinicPreferenceFilters) ([]*models.ClinicWidePreference, error) {
+	return r.collect(ctx,
+		func(p *models.ClinicWidePreference) bool {
+			if filters.CriteriaType != nil && p.CriteriaType != *filters.CriteriaType {
+				return false
+			}
+			if filters.IsActive != nil && p.IsActive != *filters.IsActive {
+				return false
+			}
+			return true
+		},
+		func(a, b *models.ClinicWidePreference) bool {
+			if a.CriteriaType != b.CriteriaType {
+				return a.CriteriaType < b.CriteriaType
+			}
+			if a.DisplayOrder != b.DisplayOrder {
+				return a.DisplayOrder < b.DisplayOrder
+			}
+			return a.MinValue < b.MinValue
+		})
+}
+
+func (r *clinicWidePreferenceRepository) GetByCriteriaTypeAndValue(ctx context.Context, criteriaType models.ClinicPreferenceCriteriaType, value float64) ([]*models.ClinicWidePreference, error) {
+	return r.collect(ctx,
+		func(p *models.ClinicWidePreference) bool {
+			return p.CriteriaType == criteriaType && p.IsActive &&
+				p.MinValue <= value && (p.MaxValue == nil || *p.MaxValue >= value)
+		},
+		func(a, b *models.ClinicWidePreference) bool {
+			if a.DisplayOrder != b.DisplayOrder {
+				return a.DisplayOrder < b.DisplayOrder
+			}
+			return a.MinValue > b.MinValue
+		})
+}
+
+// PreferencePositionRequirementRepository implementation
+type preferencePositionRequirementRepository struct {
+	store *Store
+}
+
+func findPreferencePosition(t *tables, preferenceID, positionID uuid.UUID) *models.PreferencePositionRequirement {
+	return find(t.preferencePositions, func(p *models.PreferencePositionRequirement) bool {
+		return p.PreferenceID == preferenceID && p.PositionID == positionID
+	})
+}
+
+func checkPreferencePosition(t *tables, requirement *models.PreferencePositionRequirement) error {
+	if _, ok := t.clinicPreferences[requirement.PreferenceID]; !ok {
+		return foreignKeyViolation("clinic_preference_position_requirements", "clinic_preference_position_requirements", "preference_id")
+	}
+	if _, ok := t.positions[requirement.PositionID]; !ok {
+		return foreignKeyViolation("clinic_preference_position_requirements", "clinic_preference_position_requirements", "position_id")
+	}
+	return nil
+}
+
+func preferencePositionRow(p *models.PreferencePositionRequirement) models.PreferencePositionRequirement {
+	row := *p
+	row.Preference, row.Position = nil, nil
+	return row
+}
+
+func (r *preferencePositionRequirementRepository) Create(ctx context.Context, requirement *models.PreferencePositionRequirement) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.preferencePositions[requirement.ID]; ok {
+			return uniqueViolation("clinic_preference_position_requirements_pkey")
+		}
+		if err := checkPreferencePosition(t, requirement); err != nil {
+			return err
+		}
+		if findPreferencePosition(t, requirement.PreferenceID, requirement.PositionID) != nil {
+			return uniqueViolation("clinic_preference_position_requirements_preference_id_position_id_key")
+		}
+		now := time.Now()
+		requirement.CreatedAt, requirement.UpdatedAt = now, now
+		t.preferencePositions[requirement.ID] = preferencePositionRow(requirement)
+		return nil
+	})
+}
+
+func (r *preferencePositionRequirementRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PreferencePositionRequirement, error) {
+	var requirement *models.PreferencePositionRequirement
+	err := r.store.read(ctx, func(t *tables) error {
+		requirement = get(t.preferencePositions, id)
+		return nil
+	})
+	return requirement, err
+}
+
+func (r *preferencePositionRequirementRepository) GetByPreferenceID(ctx context.Context, preferenceID uuid.UUID) ([]*models.PreferencePositionRequirement, error) {
+	var requirements []*models.PreferencePositionRequirement
+	err := r.store.read(ctx, func(t *tables) error {
+		requirements = collect(t.preferencePositions,
+			func(p *models.PreferencePositionRequirement) bool { return p.PreferenceID == preferenceID },
+			func(a, b *models.PreferencePositionRequirement) bool { return uuidLess(a.PositionID, b.PositionID) })
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nonNil(requirements), nil
+}
+
+func (r *preferencePositionRequirementRepository) GetByPreferenceAndPosition(ctx context.Context, preferenceID, positionID uuid.UUID) (*models.PreferencePositionRequirement, error) {
+	var requirement *models.PreferencePositionRequirement
+	err := r.store.read(ctx, func(t *tables) error {
+		requirement = findPreferencePosition(t, preferenceID, positionID)
+		return nil
+	})
+	return requirement, err
+}
+
+func (r *preferencePositionRequirementRepository) Update(ctx context.Context, requirement *models.PreferencePositionRequirement) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.preferencePositions[requirement.ID]
+		if !ok {
+			return errNoRows
+		}
+		row.MinimumStaff = requirement.MinimumStaff
+		row.PreferredStaff = requirement.PreferredStaff
+		row.IsActive = requirement.IsActive
+		row.UpdatedAt = time.Now()
+		t.preferencePositions[row.ID] = row
+		requirement.UpdatedAt = row.UpdatedAt
+		return nil
+	})
+}
+
+func (r *preferencePositionRequirementRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.preferencePositions, id)
+		return nil
+	})
+}
+
+func (r *preferencePositionRequirementRepository) DeleteByPreferenceID(ctx context.Context, preferenceID uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		deleteWhere(t.preferencePositions, func(p *models.PreferencePositionRequirement) bool { return p.PreferenceID == preferenceID })
+		return nil
+	})
+}
+
+func (r *preferencePositionRequirementRepository) BulkUpsert(ctx context.Context, requirements []*models.PreferencePositionRequirement) error {
+	if len(requirements) == 0 {
+		return nil
+	}
+
+	return r.store.write(ctx, func(t *tables) error {
+		for _, req := range requirements {
+			if err := checkPreferencePosition(t, req); err != nil {
+				return fmt.Errorf("failed to upsert requirement: %w", err)
+			}
+		}
+
+		now := time.Now()
+		for _, req := range requirements {
+			if existing := findPreferencePosition(t, req.PreferenceID, req.PositionID); existing != nil {
+				existing.MinimumStaff = req.MinimumStaff
+				existing.PreferredStaff = req.PreferredStaff
+				existing.IsActive = req.IsActive
+				existing.UpdatedAt = now
+				t.preferencePositions[existing.ID] = *existing
+				continue
+			}
+			row := preferencePositionRow(req)
+			row.CreatedAt, row.UpdatedAt = now, now
+			t.preferencePositions[row.ID] = row
+		}
+		return nil
+	})
+}
+
+// SpecificPreferenceRepository implementation
+type specificPreferenceRepository struct {
+	store *Store
+}
+
+func specificPreferenceRow(p *models.SpecificPreference) models.SpecificPreference {
+	row := *p
+	row.Branch, row.Doctor, row.Position, row.Staff = nil, nil, nil, nil
+	row.BranchID = copyUUID(p.BranchID)
+	row.DoctorID = copyUUID(p.DoctorID)
+	row.DayOfWeek = copyPtr(p.DayOfWeek)
+	row.PositionID = copyUUID(p.PositionID)
+	row.StaffCount = copyPtr(p.StaffCount)
+	row.StaffID = copyUUID(p.StaffID)
+	return row
+}
+
+func checkSpecificPreference(t *tables, p *models.SpecificPreference) error {
+	refs := []struct {
+		column string
+		id     *uuid.UUID
+		exists func(uuid.UUID) bool
+	}{
+		{"branch_id", p.BranchID, func(id uuid.UUID) bool { _, ok := t.branches[id]; return ok }},
+		{"doctor_id", p.DoctorID, func(id uuid.UUID) bool { _, ok := t.doctors[id]; return ok }},
+		{"position_id", p.PositionID, func(id uuid.UUID) bool { _, ok := t.positions[id]; return ok }},
+		{"staff_id", p.StaffID, func(id uuid.UUID) bool { _, ok := t.staff[id]; return ok }},
+	}
+	for _, ref := range refs {
+		if ref.id != nil && !ref.exists(*ref.id) {
+			return foreignKeyViolation("specific_preferences", "specific_preferences", ref.column)
+		}
+	}
+	return nil
+}
+
+func (r *specificPreferenceRepository) Create(ctx context.Context, preference *models.SpecificPreference) error {
+	preference.ID = uuid.New()
+	preference.CreatedAt = time.Now()
+	preference.UpdatedAt = time.Now()
+
+	if err := preference.Validate(); err != nil {
+		return err
+	}
+
+	return r.store.write(ctx, func(t *tables) error {
+		if err := checkSpecificPreference(t, preference); err != nil {
+			return err
+		}
+		t.specificPreferences[preference.ID] = specificPreferenceRow(preference)
+		return nil
+	})
+}
+
+func (r *specificPreferenceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SpecificPreference, error) {
+	var preference *models.SpecificPreference
+	err := r.store.read(ctx, func(t *tables) error {
+		if p, ok := t.specificPreferences[id]; ok {
+			row := specificPreferenceRow(&p)
+			preference = &row
+		}
+		return nil
+	})
+	return preference, err
+}
+
+func (r *specificPreferenceRepository) List(ctx context.Context, filters interfaces.SpecificPreferenceFilters) ([]*models.SpecificPreference, error) {
+	var preferences []*models.SpecificPreference
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, p := range collect(t.specificPreferences,
+			func(p *models.SpecificPreference) bool {
+				// NULL columns match any filter value
+				if filters.BranchID != nil && p.BranchID != nil && *p.BranchID != *filters.BranchID {
+					return false
+				}
+				if filters.DoctorID != nil && p.DoctorID != nil && *p.DoctorID != *filters.DoctorID {
+					return false
+				}
+				if filters.DayOfWeek != nil && p.DayOfWeek != nil && *p.DayOfWeek != *filters.DayOfWeek {
+					return false
+				}
+				if filters.IsActive != nil && p.IsActive != *filters.IsActive {
+					return false
+				}
+				return true
+			},
+			func(a, b *models.SpecificPreference) bool { return a.CreatedAt.After(b.CreatedAt) }) {
+			row := specificPreferenceRow(p)
+			preferences = append(preferences, &row)
+		}
+		return nil
+	})
+	return preferences, err
+}
+
+func (r *specificPreferenceRepository) GetMatchingPreferences(ctx context.Context, branchID *uuid.UUID, doctorID *uuid.UUID, dayOfWeek *int) ([]*models.SpecificPreference, error) {
+	filters := interfaces.SpecificPreferenceFilters{
+		IsActive: &[]bool{true}[0],
+	}
+	allPreferences, err := r.List(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	var matching []*models.SpecificPreference
+	for _, pref := range allPreferences {
+		if pref.Matches(branchID, doctorID, dayOfWeek) {
+			matching = append(matching, pref)
+		}
+	}
+	return matching, nil
+}
+
+func (r *specificPreferenceRepository) Update(ctx context.Context, preference *models.SpecificPreference) error {
+	preference.UpdatedAt = time.Now()
+
+	if err := preference.Validate(); err != nil {
+		return err
+	}
+
+	return r.store.write(ctx, func(t *tables) error {
+		existing, ok := t.specificPreferences[preference.ID]
+		if !ok {
+			return nil
+		}
+		if err := checkSpecificPreference(t, preference); err != nil {
+			return err
+		}
+		row := specificPreferenceRow(preference)
+		row.CreatedAt = existing.CreatedAt
+		t.specificPreferences[row.ID] = row
+		return nil
+	})
+}
+
+func (r *specificPreferenceRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.specificPreferences, id)
+		return nil
+	})
+}
+
+var (
+	_ interfaces.StaffRequirementScenarioRepository         = (*staffRequirementScenarioRepository)(nil)
+	_ interfaces.ScenarioPositionRequirementRepository      = (*scenarioPositionRequirementRepository)(nil)
+	_ interfaces.ScenarioSpecificStaffRequirementRepository = (*scenarioSpecificStaffRequirementRepository)(nil)
+	_ interfaces.ClinicWidePreferenceRepository             = (*clinicWidePreferenceRepository)(nil)
+	_ interfaces.PreferencePositionRequirementRepository    = (*preferencePositionRequirementRepository)(nil)
+	_ interfaces.SpecificPreferenceRepository               = (*specificPreferenceRepository)(nil)
+)
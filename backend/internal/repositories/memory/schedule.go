@@ -0,0 +1,441 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// ScheduleRepository implementation. Every write queues the branch quota summary
+// of the affected branch and date, like the schedule_change_quota_update trigger.
+type scheduleRepository struct {
+	store *Store
+}
+
+// normalizeScheduleStatus fills schedule_status from the legacy is_working_day
+// flag and keeps the flag in sync, as the postgres repository does
+func normalizeScheduleStatus(schedule *models.StaffSchedule) {
+	if schedule.ScheduleStatus == "" {
+		if schedule.IsWorkingDay {
+			schedule.ScheduleStatus = models.ScheduleStatusWorking
+		} else {
+			schedule.ScheduleStatus = models.ScheduleStatusOff
+		}
+	}
+	schedule.IsWorkingDay = (schedule.ScheduleStatus == models.ScheduleStatusWorking)
+}
+
+func (r *scheduleRepository) Create(ctx context.Context, schedule *models.StaffSchedule) error {
+	normalizeScheduleStatus(schedule)
+	return r.store.write(ctx, func(t *tables) error {
+		date := day(schedule.Date)
+		existing := find(t.schedules, func(s *models.StaffSchedule) bool {
+			return s.StaffID == schedule.StaffID && s.BranchID == schedule.BranchID && s.Date.Equal(date)
+		})
+		if existing != nil {
+			existing.ScheduleStatus = schedule.ScheduleStatus
+			existing.IsWorkingDay = schedule.IsWorkingDay
+			t.schedules[existing.ID] = *existing
+			schedule.ID = existing.ID
+			schedule.CreatedAt = existing.CreatedAt
+		} else {
+			if _, ok := t.schedules[schedule.ID]; ok {
+				return uniqueViolation("staff_schedules_pkey")
+			}
+			schedule.CreatedAt = time.Now()
+			row := *schedule
+			row.Staff, row.Branch = nil, nil
+			row.Date = date
+			t.schedules[row.ID] = row
+		}
+		markDirty(t, schedule.BranchID, date)
+		return nil
+	})
+}
+
+func scheduleOrder(a, b *models.StaffSchedule) bool {
+	if !a.Date.Equal(b.Date) {
+		return a.Date.Before(b.Date)
+	}
+	return uuidLess(a.StaffID, b.StaffID)
+}
+
+func (r *scheduleRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) ([]*models.StaffSchedule, error) {
+	var schedules []*models.StaffSchedule
+	err := r.store.read(ctx, func(t *tables) error {
+		schedules = collect(t.schedules,
+			func(s *models.StaffSchedule) bool {
+				return s.BranchID == branchID && inRange(s.Date, startDate, endDate)
+			},
+			scheduleOrder)
+		return nil
+	})
+	return schedules, err
+}
+
+func (r *scheduleRepository) GetByStaffID(ctx context.Context, staffID uuid.UUID, startDate, endDate time.Time) ([]*models.StaffSchedule, error) {
+	var schedules []*models.StaffSchedule
+	err := r.store.read(ctx, func(t *tables) error {
+		schedules = collect(t.schedules,
+			func(s *models.StaffSchedule) bool { return s.StaffID == staffID && inRange(s.Date, startDate, endDate) },
+			func(a, b *models.StaffSchedule) bool { return a.Date.Before(b.Date) })
+		return nil
+	})
+	return schedules, err
+}
+
+func (r *scheduleRepository) GetByStaffIDs(ctx context.Context, staffIDs []uuid.UUID, startDate, endDate time.Time) (map[uuid.UUID][]*models.StaffSchedule, error) {
+	result := make(map[uuid.UUID][]*models.StaffSchedule)
+	if len(staffIDs) == 0 {
+		return result, nil
+	}
+	wanted := make(map[uuid.UUID]bool, len(staffIDs))
+	for _, id := range staffIDs {
+		wanted[id] = true
+	}
+	err := r.store.read(ctx, func(t *tables) error {
+		schedules := collect(t.schedules,
+			func(s *models.StaffSchedule) bool { return wanted[s.StaffID] && inRange(s.Date, startDate, endDate) },
+			func(a, b *models.StaffSchedule) bool { return a.Date.Before(b.Date) })
+		for _, schedule := range schedules {
+			result[schedule.StaffID] = append(result[schedule.StaffID], schedule)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *scheduleRepository) Update(ctx context.Context, schedule *models.StaffSchedule) error {
+	normalizeScheduleStatus(schedule)
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.schedules[schedule.ID]
+		if !ok {
+			return nil
+		}
+		row.ScheduleStatus = schedule.ScheduleStatus
+		row.IsWorkingDay = schedule.IsWorkingDay
+		t.schedules[row.ID] = row
+		markDirty(t, row.BranchID, row.Date)
+		return nil
+	})
+}
+
+func (r *scheduleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		deleteSchedules(t, func(s *models.StaffSchedule) bool { return s.ID == id })
+		return nil
+	})
+}
+
+func (r *scheduleRepository) DeleteByStaffID(ctx context.Context, staffID uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		deleteSchedules(t, func(s *models.StaffSchedule) bool { return s.StaffID == staffID })
+		return nil
+	})
+}
+
+// deleteSchedules removes matching schedules and queues each affected branch
+// and date once
+func deleteSchedules(t *tables, drop func(*models.StaffSchedule) bool) {
+	affected := map[branchDate]bool{}
+	for id, s := range t.schedules {
+		s := s
+		if drop(&s) {
+			delete(t.schedules, id)
+			affected[branchDate{s.BranchID, s.Date}] = true
+		}
+	}
+	for bd := range affected {
+		markDirty(t, bd.branchID, bd.date)
+	}
+}
+
+type branchDate struct {
+	branchID uuid.UUID
+	date     time.Time
+}
+
+func (r *scheduleRepository) GetMonthlyView(ctx context.Context, branchID uuid.UUID, year int, month int) ([]*models.StaffSchedule, error) {
+	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	endDate := startDate.AddDate(0, 1, -1)
+	return r.GetByBranchID(ctx, branchID, startDate, endDate)
+}
+
+// RotationRepository implementation. Inserts and deletes queue the branch quota
+// summary for recomputation, like the rotation_change_quota_update trigger.
+type rotationRepository struct {
+	store *Store
+}
+
+func (r *rotationRepository) Create(ctx context.Context, assignment *models.RotationAssignment) error {
+	return r.store.write(ctx, func(t *tables) error {
+		date := day(assignment.Date)
+		if _, ok := t.rotations[assignment.ID]; ok {
+			return uniqueViolation("rotation_assignments_pkey")
+		}
+		for _, a := range t.rotations {
+			if a.RotationStaffID == assignment.RotationStaffID && a.BranchID == assignment.BranchID && a.Date.Equal(date) {
+				return uniqueViolation("rotation_assignments_rotation_staff_id_branch_id_date_key")
+			}
+		}
+		assignment.CreatedAt = time.Now()
+		row := *assignment
+		row.RotationStaff, row.Branch = nil, nil
+		row.Date = date
+		// is_adhoc and adhoc_reason are not persisted by the postgres repository
+		row.IsAdhoc, row.AdhocReason = false, ""
+		t.rotations[row.ID] = row
+		markDirty(t, row.BranchID, row.Date)
+		return nil
+	})
+}
+
+func (r *rotationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.RotationAssignment, error) {
+	var assignment *models.RotationAssignment
+	err := r.store.read(ctx, func(t *tables) error {
+		assignment = get(t.rotations, id)
+		return nil
+	})
+	return assignment, err
+}
+
+func (r *rotationRepository) GetByDate(ctx context.Context, date time.Time) ([]*models.RotationAssignment, error) {
+	var assignments []*models.RotationAssignment
+	err := r.store.read(ctx, func(t *tables) error {
+		d := day(date)
+		assignments = collect(t.rotations,
+			func(a *models.RotationAssignment) bool { return a.Date.Equal(d) },
+			func(a, b *models.RotationAssignment) bool {
+				if a.BranchID != b.BranchID {
+					return uuidLess(a.BranchID, b.BranchID)
+				}
+				return uuidLess(a.RotationStaffID, b.RotationStaffID)
+			})
+		return nil
+	})
+	return assignments, err
+}
+
+func rotationByDate(a, b *models.RotationAssignment) bool {
+	return a.Date.Before(b.Date)
+}
+
+func (r *rotationRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) ([]*models.RotationAssignment, error) {
+	var assignments []*models.RotationAssignment
+	err := r.store.read(ctx, func(t *tables) error {
+		assignments = collect(t.rotations,
+			func(a *models.RotationAssignment) bool {
+				return a.BranchID == branchID && inRange(a.Date, startDate, endDate)
+			},
+			rotationByDate)
+		return nil
+	})
+	return assignments, err
+}
+
+func (r *rotationRepository) GetByRotationStaffID(ctx context.Context, rotationStaffID uuid.UUID, startDate, endDate time.Time) ([]*models.RotationAssignment, error) {
+	var assignments []*models.RotationAssignment
+	err := r.store.read(ctx, func(t *tables) error {
+		assignments = collect(t.rotations,
+			func(a *models.RotationAssignment) bool {
+				return a.RotationStaffID == rotationStaffID && inRange(a.Date, startDate, endDate)
+			},
+			rotationByDate)
+		return nil
+	})
+	return assignments, err
+}
+
+func (r *rotationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		deleteRotations(t, func(a *models.RotationAssignment) bool { return a.ID == id })
+		return nil
+	})
+}
+
+func (r *rotationRepository) DeleteByRotationStaffID(ctx context.Context, rotationStaffID uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		deleteRotations(t, func(a *models.RotationAssignment) bool { return a.RotationStaffID == rotationStaffID })
+		return nil
+	})
+}
+
+func deleteRotations(t *tables, drop func(*models.RotationAssignment) bool) {
+	affected := map[branchDate]bool{}
+	for id, a := range t.rotations {
+		a := a
+		if drop(&a) {
+			delete(t.rotations, id)
+			affected[branchDate{a.BranchID, a.Date}] = true
+		}
+	}
+	for bd := range affected {
+		markDirty(t, bd.branchID, bd.date)
+	}
+}
+
+func (r *rotationRepository) GetAssignments(ctx context.Context, filters interfaces.RotationFilters) ([]*models.RotationAssignment, error) {
+	var assignments []*models.RotationAssignment
+	err := r.store.read(ctx, func(t *tables) error {
+		assignments = collect(t.rotations,
+			func(a *models.RotationAssignment) bool {
+				if filters.BranchID != nil && a.BranchID != *filters.BranchID {
+					return false
+				}
+				if filters.RotationStaffID != nil && a.RotationStaffID != *filters.RotationStaffID {
+					return false
+				}
+				if filters.StartDate != nil && a.Date.Before(day(*filters.StartDate)) {
+					return false
+				}
+				if filters.EndDate != nil && a.Date.After(day(*filters.EndDate)) {
+					return false
+				}
+				return true
+			},
+			func(a, b *models.RotationAssignment) bool {
+				if !a.Date.Equal(b.Date) {
+					return a.Date.Before(b.Date)
+				}
+				return uuidLess(a.BranchID, b.BranchID)
+			})
+		return nil
+	})
+	return assignments, err
+}
+
+// RotationStaffScheduleRepository implementation
+type rotationStaffScheduleRepository struct {
+	store *Store
+}
+
+func (r *rotationStaffScheduleRepository) Create(ctx context.Context, schedule *models.RotationStaffSchedule) error {
+	return r.store.write(ctx, func(t *tables) error {
+		date := day(schedule.Date)
+		now := time.Now()
+		existing := find(t.rotationStaffSchedules, func(s *models.RotationStaffSchedule) bool {
+			return s.RotationStaffID == schedule.RotationStaffID && s.Date.Equal(date)
+		})
+		if existing != nil {
+			existing.ScheduleStatus = schedule.ScheduleStatus
+			existing.UpdatedAt = now
+			t.rotationStaffSchedules[existing.ID] = *existing
+			schedule.CreatedAt, schedule.UpdatedAt = existing.CreatedAt, existing.UpdatedAt
+			return nil
+		}
+		if _, ok := t.rotationStaffSchedules[schedule.ID]; ok {
+			return uniqueViolation("rotation_staff_schedules_pkey")
+		}
+		schedule.CreatedAt, schedule.UpdatedAt = now, now
+		row := *schedule
+		row.RotationStaff = nil
+		row.Date = date
+		t.rotationStaffSchedules[row.ID] = row
+		return nil
+	})
+}
+
+func (r *rotationStaffScheduleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.RotationStaffSchedule, error) {
+	var schedule *models.RotationStaffSchedule
+	err := r.store.read(ctx, func(t *tables) error {
+		schedule = get(t.rotationStaffSchedules, id)
+		return nil
+	})
+	return schedule, err
+}
+
+func (r *rotationStaffScheduleRepository) GetByRotationStaffID(ctx context.Context, rotationStaffID uuid.UUID, startDate, endDate time.Time) ([]*models.RotationStaffSchedule, error) {
+	var schedules []*models.RotationStaffSchedule
+	err := r.store.read(ctx, func(t *tables) error {
+		schedules = collect(t.rotationStaffSchedules,
+			func(s *models.RotationStaffSchedule) bool {
+				return s.RotationStaffID == rotationStaffID && inRange(s.Date, startDate, endDate)
+			},
+			func(a, b *models.RotationStaffSchedule) bool { return a.Date.Before(b.Date) })
+		return nil
+	})
+	return schedules, err
+}
+
+func (r *rotationStaffScheduleRepository) GetByDate(ctx context.Context, date time.Time) ([]*models.RotationStaffSchedule, error) {
+	var schedules []*models.RotationStaffSchedule
+	err := r.store.read(ctx, func(t *tables) error {
+		d := day(date)
+		schedules = collect(t.rotationStaffSchedules,
+			func(s *models.RotationStaffSchedule) bool { return s.Date.Equal(d) },
+			func(a, b *models.RotationStaffSchedule) bool { return uuidLess(a.RotationStaffID, b.RotationStaffID) })
+		return nil
+	})
+	return schedules, err
+}
+
+func (r *rotationStaffScheduleRepository) GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.RotationStaffSchedule, error) {
+	var schedules []*models.RotationStaffSchedule
+	err := r.store.read(ctx, func(t *tables) error {
+		schedules = collect(t.rotationStaffSchedules,
+			func(s *models.RotationStaffSchedule) bool { return inRange(s.Date, startDate, endDate) },
+			func(a, b *models.RotationStaffSchedule) bool {
+				if a.RotationStaffID != b.RotationStaffID {
+					return uuidLess(a.RotationStaffID, b.RotationStaffID)
+				}
+				return a.Date.Before(b.Date)
+			})
+		return nil
+	})
+	return schedules, err
+}
+
+func (r *rotationStaffScheduleRepository) GetByRotationStaffIDAndDate(ctx context.Context, rotationStaffID uuid.UUID, date time.Time) (*models.RotationStaffSchedule, error) {
+	var schedule *models.RotationStaffSchedule
+	err := r.store.read(ctx, func(t *tables) error {
+		d := day(date)
+		schedule = find(t.rotationStaffSchedules, func(s *models.RotationStaffSchedule) bool {
+			return s.RotationStaffID == rotationStaffID && s.Date.Equal(d)
+		})
+		return nil
+	})
+	return schedule, err
+}
+
+func (r *rotationStaffScheduleRepository) Update(ctx context.Context, schedule *models.RotationStaffSchedule) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.rotationStaffSchedules[schedule.ID]
+		if !ok {
+			return errNoRows
+		}
+		row.ScheduleStatus = schedule.ScheduleStatus
+		row.UpdatedAt = time.Now()
+		t.rotationStaffSchedules[row.ID] = row
+		schedule.UpdatedAt = row.UpdatedAt
+		return nil
+	})
+}
+
+func (r *rotationStaffScheduleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.rotationStaffSchedules, id)
+		return nil
+	})
+}
+
+func (r *rotationStaffScheduleRepository) DeleteByRotationStaffID(ctx context.Context, rotationStaffID uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		deleteWhere(t.rotationStaffSchedules, func(s *models.RotationStaffSchedule) bool {
+			return s.RotationStaffID == rotationStaffID
+		})
+		return nil
+	})
+}
+
+var (
+	_ interfaces.ScheduleRepository              = (*scheduleRepository)(nil)
+	_ interfaces.RotationRepository              = (*rotationRepository)(nil)
+	_ interfaces.RotationStaffScheduleRepository = (*rotationStaffScheduleRepository)(nil)
+)
@@ -0,0 +1,384 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DemoAdminUsername and DemoAdminPassword are the login Seed creates. They are
+// for demo mode only and must never be used against a real database.
+const (
+	DemoAdminUsername = "admin"
+	DemoAdminPassword = "admin123"
+)
+
+// Reference IDs shared with the baseline migration, so fixtures written against
+// a migrated database also work against a seeded store
+var (
+	adminRoleID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+	branchManagerPositionID   = uuid.MustParse("10000000-0000-0000-0000-000000000008")
+	doctorAssistantPositionID = uuid.MustParse("10000000-0000-0000-0000-000000000010")
+	receptionistPositionID    = uuid.MustParse("10000000-0000-0000-0000-000000000013")
+	nursePositionID           = uuid.MustParse("10000000-0000-0000-0000-000000000015")
+	rotationAssistantID       = uuid.MustParse("10000000-0000-0000-0000-000000000026")
+	rotationFrontID           = uuid.MustParse("10000000-0000-0000-0000-000000000027")
+
+	highRevenueTierID = uuid.MustParse("30000000-0000-0000-0000-000000000004")
+
+	standardBranchBaseID = uuid.MustParse("20000000-0000-0000-0000-000000000000")
+)
+
+var seedRoles = []string{"admin", "area_manager", "district_manager", "branch_manager", "viewer"}
+
+var seedPositions = []models.Position{
+	{ID: branchManagerPositionID, Name: "ผู้จัดการสาขา", MinStaffPerBranch: 1, DisplayOrder: 1, PositionType: models.PositionTypeBranch, ManpowerType: models.ManpowerTypeOther},
+	{ID: uuid.MustParse("10000000-0000-0000-0000-000000000009"), Name: "รองผู้จัดการสาขา", DisplayOrder: 2, PositionType: models.PositionTypeBranch, ManpowerType: models.ManpowerTypeOther},
+	{ID: doctorAssistantPositionID, Name: "ผู้ช่วยแพทย์", MinStaffPerBranch: 2, DisplayOrder: 20, PositionType: models.PositionTypeBranch, ManpowerType: models.ManpowerTypeDoctor},
+	{ID: uuid.MustParse("10000000-0000-0000-0000-000000000011"), Name: "ผู้ประสานงานคลินิก (Clinic Coordination Officer)", MinStaffPerBranch: 1, DisplayOrder: 50, PositionType: models.PositionTypeBranch, ManpowerType: models.ManpowerTypeOther},
+	{ID: uuid.MustParse("10000000-0000-0000-0000-000000000012"), Name: "ผู้ช่วย Laser Specialist", MinStaffPerBranch: 1, DisplayOrder: 20, PositionType: models.PositionTypeBranch, ManpowerType: models.ManpowerTypeDoctor},
+	{ID: receptionistPositionID, Name: "พนักงานต้อนรับ (Laser Receptionist)", MinStaffPerBranch: 1, DisplayOrder: 40, PositionType: models.PositionTypeBranch, ManpowerType: models.ManpowerTypeFront},
+	{ID: uuid.MustParse("10000000-0000-0000-0000-000000000014"), Name: "แม่บ้านประจำสาขา", MinStaffPerBranch: 1, DisplayOrder: 60, PositionType: models.PositionTypeBranch, ManpowerType: models.ManpowerTypeCleaning},
+	{ID: nursePositionID, Name: "พยาบาล", MinStaffPerBranch: 2, DisplayOrder: 30, PositionType: models.PositionTypeBranch, ManpowerType: models.ManpowerTypeDoctor},
+	{ID: uuid.MustParse("10000000-0000-0000-0000-000000000016"), Name: "ผู้ช่วยผู้จัดการสาขา", DisplayOrder: 2, PositionType: models.PositionTypeBranch, ManpowerType: models.ManpowerTypeOther},
+	{ID: uuid.MustParse("10000000-0000-0000-0000-000000000017"), Name: "ผู้ช่วยแพทย์ Pico Laser", MinStaffPerBranch: 1, DisplayOrder: 20, PositionType: models.PositionTypeBranch, ManpowerType: models.ManpowerTypeDoctor},
+	{ID: uuid.MustParse("10000000-0000-0000-0000-000000000018"), Name: "รองผู้จัดการสาขาและล่าม", DisplayOrder: 2, PositionType: models.PositionTypeBranch, ManpowerType: models.ManpowerTypeOther},
+	{ID: uuid.MustParse("10000000-0000-0000-0000-000000000019"), Name: "Front+ล่ามวนสาขา", MinStaffPerBranch: 1, DisplayOrder: 40, PositionType: models.PositionTypeRotation, ManpowerType: models.ManpowerTypeFront},
+	{ID: uuid.MustParse("10000000-0000-0000-0000-000000000020"), Name: "ผู้ช่วยแพทย์ Pico", MinStaffPerBranch: 1, DisplayOrder: 20, PositionType: models.PositionTypeBranch, ManpowerType: models.ManpowerTypeDoctor},
+	{ID: uuid.MustParse("10000000-0000-0000-0000-000000000021"), Name: "พนักงานต้อนรับ (Pico Laser Receptionist)", MinStaffPerBranch: 1, DisplayOrder: 40, PositionType: models.PositionTypeBranch, ManpowerType: models.ManpowerTypeFront},
+	{ID: uuid.MustParse("10000000-0000-0000-0000-000000000022"), Name: "ผู้จัดการเขต", DisplayOrder: 10, PositionType: models.PositionTypeRotation, ManpowerType: models.ManpowerTypeOther},
+	{ID: uuid.MustParse("10000000-0000-0000-0000-000000000023"), Name: "ผู้จัดการแผนกและกำกับพัฒนาระเบียบสาขา", DisplayOrder: 10, PositionType: models.PositionTypeRotation, ManpowerType: models.ManpowerTypeOther},
+	{ID: uuid.MustParse("10000000-0000-0000-0000-000000000024"), Name: "หัวหน้าผู้ช่วยแพทย์", MinStaffPerBranch: 1, DisplayOrder: 15, PositionType: models.PositionTypeRotation, ManpowerType: models.ManpowerTypeDoctor},
+	{ID: uuid.MustParse("10000000-0000-0000-0000-000000000025"), Name: "ผู้ช่วยพิเศษ", DisplayOrder: 20, PositionType: models.PositionTypeRotation, ManpowerType: models.ManpowerTypeOther},
+	{ID: rotationAssistantID, Name: "ผู้ช่วยแพทย์วนสาขา", MinStaffPerBranch: 1, DisplayOrder: 20, PositionType: models.PositionTypeRotation, ManpowerType: models.ManpowerTypeDoctor},
+	{ID: rotationFrontID, Name: "ฟร้อนท์วนสาขา", MinStaffPerBranch: 1, DisplayOrder: 40, PositionType: models.PositionTypeRotation, ManpowerType: models.ManpowerTypeFront},
+}
+
+type seedTier struct {
+	name        string
+	min, max    float64
+	color       string
+	description string
+}
+
+var seedTiers = []seedTier{
+	{"Very Low", 0, 100000, "#CCCCCC", "Low revenue days"},
+	{"Low", 100000, 200000, "#99CCFF", "Below average revenue"},
+	{"Medium", 200000, 300000, "#66FF99", "Average revenue days"},
+	{"High", 300000, 400000, "#FFCC66", "Above average revenue"},
+	{"Very High", 400000, 500000, "#FF9966", "High revenue days"},
+	{"Extremely High", 500000, 600000, "#FF6666", "Very high revenue days"},
+	{"Peak", 600000, 0, "#FF0000", "Peak revenue days"},
+}
+
+// seedBranches are the demo branches; each gets a branch manager, two doctor
+// assistants and a receptionist, and one doctor working Monday to Saturday
+var seedBranches = []struct {
+	code         string
+	doctor       string
+	skinRevenue  float64
+	weekendBoost float64
+}{
+	{"CPN", "Dr. Anan", 250000, 150000},
+	{"CTR", "Dr. Busaba", 180000, 80000},
+	{"PNK", "Dr. Chai", 120000, 50000},
+}
+
+// seedScheduleDays is how many days of staff schedules Seed creates, starting today
+const seedScheduleDays = 14
+
+// Seed fills repos with the reference data the baseline migration inserts
+// (roles, positions, revenue tiers) and a small demo data set: an admin
+// login, three standard branches with staff, schedules, quotas, doctors, a
+// staff group requirement and revenue scenarios. It is meant for a fresh
+// store; seeding twice fails on unique constraints.
+func Seed(ctx context.Context, repos *Repositories) error {
+	now := time.Now()
+	err := repos.Store.write(ctx, func(t *tables) error {
+		for i, name := range seedRoles {
+			id := uuid.MustParse(fmt.Sprintf("00000000-0000-0000-0000-%012d", i+1))
+			t.roles[id] = models.Role{ID: id, Name: name, CreatedAt: now}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("seed roles: %w", err)
+	}
+
+	for _, p := range seedPositions {
+		position := p
+		if err := repos.Position.Create(ctx, &position); err != nil {
+			return fmt.Errorf("seed position %s: %w", p.ID, err)
+		}
+	}
+
+	for i, st := range seedTiers {
+		tier := &models.RevenueLevelTier{
+			ID:           uuid.MustParse(fmt.Sprintf("30000000-0000-0000-0000-%012d", i+1)),
+			LevelNumber:  i + 1,
+			LevelName:    st.name,
+			MinRevenue:   st.min,
+			DisplayOrder: i + 1,
+			ColorCode:    &st.color,
+			Description:  &st.description,
+		}
+		if st.max > 0 {
+			tier.MaxRevenue = &st.max
+		}
+		if err := repos.RevenueLevelTier.Create(ctx, tier); err != nil {
+			return fmt.Errorf("seed revenue tier %d: %w", tier.LevelNumber, err)
+		}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(DemoAdminPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("hash demo password: %w", err)
+	}
+	admin := &models.User{
+		ID:           uuid.New(),
+		Username:     DemoAdminUsername,
+		Email:        "admin@example.com",
+		PasswordHash: string(hash),
+		RoleID:       adminRoleID,
+	}
+	if err := repos.User.Create(ctx, admin); err != nil {
+		return fmt.Errorf("seed admin user: %w", err)
+	}
+
+	branchType := &models.BranchType{
+		Name:        "Standard",
+		Description: "Standard clinic branch",
+		IsActive:    true,
+	}
+	if err := repos.BranchType.Create(ctx, branchType); err != nil {
+		return fmt.Errorf("seed branch type: %w", err)
+	}
+
+	assistants := &models.StaffGroup{
+		Name:        "Doctor Assistants",
+		Description: "Branch and rotation doctor assistants",
+		IsActive:    true,
+	}
+	if err := repos.StaffGroup.Create(ctx, assistants); err != nil {
+		return fmt.Errorf("seed staff group: %w", err)
+	}
+	for _, positionID := range []uuid.UUID{doctorAssistantPositionID, rotationAssistantID} {
+		sgp := &models.StaffGroupPosition{StaffGroupID: assistants.ID, PositionID: positionID}
+		if err := repos.StaffGroupPosition.Create(ctx, sgp); err != nil {
+			return fmt.Errorf("seed staff group position: %w", err)
+		}
+	}
+	for dow := 1; dow <= 6; dow++ {
+		requirement := &models.BranchTypeStaffGroupRequirement{
+			BranchTypeID:      branchType.ID,
+			StaffGroupID:      assistants.ID,
+			DayOfWeek:         dow,
+			MinimumStaffCount: 2,
+			IsActive:          true,
+		}
+		if err := repos.BranchTypeRequirement.Create(ctx, requirement); err != nil {
+			return fmt.Errorf("seed branch type requirement: %w", err)
+		}
+	}
+
+	start := today()
+	for _, sb := range seedBranches {
+		if err := seedBranch(ctx, repos, admin.ID, branchType.ID, sb.code, sb.doctor, sb.skinRevenue, sb.weekendBoost, start); err != nil {
+			return fmt.Errorf("seed branch %s: %w", sb.code, err)
+		}
+	}
+
+	for i, r := range []struct {
+		nickname   string
+		positionID uuid.UUID
+	}{
+		{"Rot-DA1", rotationAssistantID},
+		{"Rot-DA2", rotationAssistantID},
+		{"Rot-FR1", rotationFrontID},
+	} {
+		staff := &models.Staff{
+			ID:         uuid.New(),
+			Nickname:   r.nickname,
+			Name:       fmt.Sprintf("Rotation Staff %d", i+1),
+			StaffType:  models.StaffTypeRotation,
+			PositionID: r.positionID,
+			SkillLevel: 7,
+		}
+		if err := repos.Staff.Create(ctx, staff); err != nil {
+			return fmt.Errorf("seed rotation staff: %w", err)
+		}
+	}
+
+	return seedScenarios(ctx, repos)
+}
+
+func seedBranch(ctx context.Context, repos *Repositories, createdBy, branchTypeID uuid.UUID,
+	code, doctorName string, skinRevenue, weekendBoost float64, start time.Time) error {
+	branch := &models.Branch{
+		ID:           uuid.NewSHA1(standardBranchBaseID, []byte(code)),
+		Name:         code,
+		Code:         code,
+		BranchTypeID: &branchTypeID,
+	}
+	if err := repos.Branch.Create(ctx, branch); err != nil {
+		return err
+	}
+
+	roster := []struct {
+		nickname   string
+		positionID uuid.UUID
+	}{
+		{"BM", branchManagerPositionID},
+		{"DA1", doctorAssistantPositionID},
+		{"DA2", doctorAssistantPositionID},
+		{"FR1", receptionistPositionID},
+	}
+	for i, r := range roster {
+		staff := &models.Staff{
+			ID:         uuid.New(),
+			Nickname:   code + "-" + r.nickname,
+			Name:       fmt.Sprintf("%s Staff %d", code, i+1),
+			StaffType:  models.StaffTypeBranch,
+			PositionID: r.positionID,
+			BranchID:   &branch.ID,
+			SkillLevel: 5,
+		}
+		if err := repos.Staff.Create(ctx, staff); err != nil {
+			return err
+		}
+
+		// Everyone is off on Sunday and takes one more weekday off, staggered
+		// so each branch is short-handed on a different day
+		offDay := time.Weekday(1 + i%6)
+		for d := 0; d < seedScheduleDays; d++ {
+			date := start.AddDate(0, 0, d)
+			status := models.ScheduleStatusWorking
+			if date.Weekday() == time.Sunday || date.Weekday() == offDay {
+				status = models.ScheduleStatusOff
+			}
+			schedule := &models.StaffSchedule{
+				ID:             uuid.New(),
+				StaffID:        staff.ID,
+				BranchID:       branch.ID,
+				Date:           date,
+				ScheduleStatus: status,
+				IsWorkingDay:   status == models.ScheduleStatusWorking,
+				CreatedBy:      createdBy,
+			}
+			if err := repos.Schedule.Create(ctx, schedule); err != nil {
+				return err
+			}
+		}
+	}
+
+	doctor := &models.Doctor{Name: doctorName, Code: code}
+	if err := repos.Doctor.Create(ctx, doctor); err != nil {
+		return err
+	}
+	for dow := 1; dow <= 6; dow++ {
+		schedule := &models.DoctorDefaultSchedule{
+			DoctorID:  doctor.ID,
+			DayOfWeek: dow,
+			BranchID:  branch.ID,
+			CreatedBy: createdBy,
+		}
+		if err := repos.DoctorDefaultSchedule.Create(ctx, schedule); err != nil {
+			return err
+		}
+	}
+
+	for _, q := range []struct {
+		positionID          uuid.UUID
+		designated, minimum int
+	}{
+		{branchManagerPositionID, 1, 1},
+		{doctorAssistantPositionID, 3, 2},
+		{receptionistPositionID, 2, 1},
+	} {
+		quota := &models.PositionQuota{
+			ID:              uuid.New(),
+			BranchID:        branch.ID,
+			PositionID:      q.positionID,
+			DesignatedQuota: q.designated,
+			MinimumRequired: q.minimum,
+			IsActive:        true,
+			CreatedBy:       createdBy,
+		}
+		if err := repos.PositionQuota.Create(ctx, quota); err != nil {
+			return err
+		}
+	}
+
+	revenues := make([]*models.BranchWeeklyRevenue, 0, 7)
+	for dow := 0; dow < 7; dow++ {
+		revenue := skinRevenue
+		if dow == int(time.Saturday) || dow == int(time.Sunday) {
+			revenue += weekendBoost
+		}
+		revenues = append(revenues, &models.BranchWeeklyRevenue{
+			BranchID:     branch.ID,
+			DayOfWeek:    dow,
+			SkinRevenue:  revenue,
+			VitaminCases: 20,
+			SlimPenCases: 10,
+		})
+	}
+	return repos.BranchWeeklyRevenue.BulkUpsert(ctx, revenues)
+}
+
+// seedScenarios adds a default scenario and a high-revenue scenario that asks
+// for one more doctor assistant and receptionist on top of the quota
+func seedScenarios(ctx context.Context, repos *Repositories) error {
+	defaultDescription := "Base quotas apply"
+	highDescription := "High revenue days need an extra doctor assistant and receptionist"
+	scenarios := []struct {
+		scenario     *models.StaffRequirementScenario
+		requirements []models.ScenarioPositionRequirement
+	}{
+		{
+			scenario: &models.StaffRequirementScenario{
+				ID:           uuid.New(),
+				ScenarioName: "Default",
+				Description:  &defaultDescription,
+				IsDefault:    true,
+				IsActive:     true,
+			},
+		},
+		{
+			scenario: &models.StaffRequirementScenario{
+				ID:                  uuid.New(),
+				ScenarioName:        "High Revenue",
+				Description:         &highDescription,
+				RevenueLevelTierID:  &highRevenueTierID,
+				UseDayOfWeekRevenue: true,
+				IsActive:            true,
+				Priority:            10,
+			},
+			requirements: []models.ScenarioPositionRequirement{
+				{PositionID: doctorAssistantPositionID, PreferredStaff: 1, MinimumStaff: 1},
+				{PositionID: receptionistPositionID, PreferredStaff: 1, MinimumStaff: 0},
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		if err := repos.StaffRequirementScenario.Create(ctx, s.scenario); err != nil {
+			return fmt.Errorf("seed scenario %s: %w", s.scenario.ScenarioName, err)
+		}
+		for _, r := range s.requirements {
+			requirement := r
+			requirement.ID = uuid.New()
+			requirement.ScenarioID = s.scenario.ID
+			if err := repos.ScenarioPositionRequirement.Create(ctx, &requirement); err != nil {
+				return fmt.Errorf("seed scenario %s requirement: %w", s.scenario.ScenarioName, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// SettingsRepository implementation
+type settingsRepository struct {
+	store *Store
+}
+
+func (r *settingsRepository) GetAll(ctx context.Context) ([]*models.SystemSetting, error) {
+	var settings []*models.SystemSetting
+	err := r.store.read(ctx, func(t *tables) error {
+		settings = collect(t.settings, nil, func(a, b *models.SystemSetting) bool { return a.Key < b.Key })
+		return nil
+	})
+	return settings, err
+}
+
+func (r *settingsRepository) GetByKey(ctx context.Context, key string) (*models.SystemSetting, error) {
+	var setting *models.SystemSetting
+	err := r.store.read(ctx, func(t *tables) error {
+		setting = find(t.settings, func(s *models.SystemSetting) bool { return s.Key == key })
+		return nil
+	})
+	return setting, err
+}
+
+func (r *settingsRepository) Update(ctx context.Context, setting *models.SystemSetting) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if row := find(t.settings, func(s *models.SystemSetting) bool { return s.Key == setting.Key }); row != nil {
+			row.Value = setting.Value
+			row.Description = setting.Description
+			row.UpdatedAt = time.Now()
+			t.settings[row.ID] = *row
+		}
+		return nil
+	})
+}
+
+func (r *settingsRepository) Create(ctx context.Context, setting *models.SystemSetting) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.settings[setting.ID]; ok {
+			return uniqueViolation("system_settings_pkey")
+		}
+		if find(t.settings, func(s *models.SystemSetting) bool { return s.Key == setting.Key }) != nil {
+			return uniqueViolation("system_settings_key_key")
+		}
+		setting.UpdatedAt = time.Now()
+		t.settings[setting.ID] = *setting
+		return nil
+	})
+}
+
+// AllocationRuleRepository implementation
+type allocationRuleRepository struct {
+	store *Store
+}
+
+func (r *allocationRuleRepository) Create(ctx context.Context, rule *models.StaffAllocationRule) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.allocationRules[rule.ID]; ok {
+			return uniqueViolation("staff_allocation_rules_pkey")
+		}
+		if find(t.allocationRules, func(a *models.StaffAllocationRule) bool { return a.PositionID == rule.PositionID }) != nil {
+			return uniqueViolation("staff_allocation_rules_position_id_key")
+		}
+		now := time.Now()
+		rule.CreatedAt, rule.UpdatedAt = now, now
+		row := *rule
+		row.Position = nil
+		t.allocationRules[row.ID] = row
+		return nil
+	})
+}
+
+func (r *allocationRuleRepository) GetByPositionID(ctx context.Context, positionID uuid.UUID) (*models.StaffAllocationRule, error) {
+	var rule *models.StaffAllocationRule
+	err := r.store.read(ctx, func(t *tables) error {
+		rule = find(t.allocationRules, func(a *models.StaffAllocationRule) bool { return a.PositionID == positionID })
+		return nil
+	})
+	return rule, err
+}
+
+func (r *allocationRuleRepository) Update(ctx context.Context, rule *models.StaffAllocationRule) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.allocationRules[rule.ID]
+		if !ok {
+			return nil
+		}
+		row.MinStaff = rule.MinStaff
+		row.RevenueThreshold = rule.RevenueThreshold
+		row.StaffCountFormula = rule.StaffCountFormula
+		row.UpdatedAt = time.Now()
+		t.allocationRules[row.ID] = row
+		return nil
+	})
+}
+
+func (r *allocationRuleRepository) List(ctx context.Context) ([]*models.StaffAllocationRule, error) {
+	var rules []*models.StaffAllocationRule
+	err := r.store.read(ctx, func(t *tables) error {
+		rules = collect(t.allocationRules, nil, func(a, b *models.StaffAllocationRule) bool {
+			return uuidLess(a.PositionID, b.PositionID)
+		})
+		return nil
+	})
+	return rules, err
+}
+
+// AllocationCriteriaRepository implementation
+type allocationCriteriaRepository struct {
+	store *Store
+}
+
+func (r *allocationCriteriaRepository) Create(ctx context.Context, criteria *models.AllocationCriteria) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.allocationCriteria[criteria.ID]; ok {
+			return uniqueViolation("allocation_criteria_pkey")
+		}
+		now := time.Now()
+		criteria.CreatedAt, criteria.UpdatedAt = now, now
+		t.allocationCriteria[criteria.ID] = *criteria
+		return nil
+	})
+}
+
+func (r *allocationCriteriaRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.AllocationCriteria, error) {
+	var criteria *models.AllocationCriteria
+	err := r.store.read(ctx, func(t *tables) error {
+		criteria = get(t.allocationCriteria, id)
+		return nil
+	})
+	return criteria, err
+}
+
+func (r *allocationCriteriaRepository) Update(ctx context.Context, criteria *models.AllocationCriteria) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.allocationCriteria[criteria.ID]
+		if !ok {
+			return errNoRows
+		}
+		row.Pillar = criteria.Pillar
+		row.Type = criteria.Type
+		row.Weight = criteria.Weight
+		row.IsActive = criteria.IsActive
+		row.Description = criteria.Description
+		row.Config = criteria.Config
+		row.UpdatedAt = time.Now()
+		t.allocationCriteria[row.ID] = row
+		criteria.UpdatedAt = row.UpdatedAt
+		return nil
+	})
+}
+
+func (r *allocationCriteriaRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.allocationCriteria, id)
+		return nil
+	})
+}
+
+func (r *allocationCriteriaRepository) List(ctx context.Context, filters interfaces.AllocationCriteriaFilters) ([]*models.AllocationCriteria, error) {
+	var criteriaList []*models.AllocationCriteria
+	err := r.store.read(ctx, func(t *tables) error {
+		criteriaList = collect(t.allocationCriteria,
+			func(c *models.AllocationCriteria) bool {
+				if filters.Pillar != nil && c.Pillar != *filters.Pillar {
+					return false
+				}
+				if filters.Type != nil && c.Type != *filters.Type {
+					return false
+				}
+				if filters.IsActive != nil && c.IsActive != *filters.IsActive {
+					return false
+				}
+				return true
+			},
+			func(a, b *models.AllocationCriteria) bool {
+				if a.Pillar != b.Pillar {
+					return a.Pillar < b.Pillar
+				}
+				return a.Type < b.Type
+			})
+		return nil
+	})
+	return criteriaList, err
+}
+
+func (r *allocationCriteriaRepository) GetByPillar(ctx context.Context, pillar models.CriteriaPillar) ([]*models.AllocationCriteria, error) {
+	filters := interfaces.AllocationCriteriaFilters{Pillar: &pillar, IsActive: &[]bool{true}[0]}
+	return r.List(ctx, filters)
+}
+
+var (
+	_ interfaces.SettingsRepository           = (*settingsRepository)(nil)
+	_ interfaces.AllocationRuleRepository     = (*allocationRuleRepository)(nil)
+	_ interfaces.AllocationCriteriaRepository = (*allocationCriteriaRepository)(nil)
+)
@@ -0,0 +1,691 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// StaffRepository implementation
+type staffRepository struct {
+	store *Store
+}
+
+func (r *staffRepository) Create(ctx context.Context, staff *models.Staff) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.staff[staff.ID]; ok {
+			return uniqueViolation("staff_pkey")
+		}
+		now := time.Now()
+		staff.CreatedAt, staff.UpdatedAt = now, now
+		t.staff[staff.ID] = staffRow(staff)
+		return nil
+	})
+}
+
+// staffRow strips relations and detaches nullable columns before storing
+func staffRow(staff *models.Staff) models.Staff {
+	row := *staff
+	row.Position, row.Branch, row.AreaOfOperation, row.Zone, row.Branches = nil, nil, nil, nil, nil
+	row.BranchID = copyUUID(staff.BranchID)
+	row.AreaOfOperationID = copyUUID(staff.AreaOfOperationID)
+	row.ZoneID = copyUUID(staff.ZoneID)
+	return row
+}
+
+// withStaffBranches loads the individual branches of rotation staff, as the
+// postgres repository does on every read
+func withStaffBranches(t *tables, staff *models.Staff) *models.Staff {
+	if staff != nil && staff.StaffType == models.StaffTypeRotation {
+		staff.Branches = staffBranches(t, staff.ID)
+	}
+	return staff
+}
+
+func staffBranches(t *tables, staffID uuid.UUID) []*models.Branch {
+	branches := collect(t.branches,
+		func(b *models.Branch) bool {
+			_, ok := t.staffBranches[link{staffID, b.ID}]
+			return ok
+		},
+		func(a, b *models.Branch) bool { return a.Name < b.Name })
+	for _, branch := range branches {
+		// GetBranches does not select branch_type_id
+		branch.BranchTypeID = nil
+	}
+	return branches
+}
+
+func (r *staffRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Staff, error) {
+	var staff *models.Staff
+	err := r.store.read(ctx, func(t *tables) error {
+		staff = withStaffBranches(t, get(t.staff, id))
+		return nil
+	})
+	return staff, err
+}
+
+func (r *staffRepository) Update(ctx context.Context, staff *models.Staff) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.staff[staff.ID]
+		if !ok {
+			return nil
+		}
+		updated := staffRow(staff)
+		updated.CreatedAt = row.CreatedAt
+		updated.UpdatedAt = time.Now()
+		t.staff[staff.ID] = updated
+		return nil
+	})
+}
+
+func (r *staffRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.staff[id]; !ok {
+			return nil
+		}
+		if find(t.effectiveBranches, func(eb *models.EffectiveBranch) bool { return eb.RotationStaffID == id }) != nil {
+			return foreignKeyViolation("staff", "effective_branches", "rotation_staff_id")
+		}
+		if find(t.schedules, func(s *models.StaffSchedule) bool { return s.StaffID == id }) != nil {
+			return foreignKeyViolation("staff", "staff_schedules", "staff_id")
+		}
+		if find(t.rotations, func(a *models.RotationAssignment) bool { return a.RotationStaffID == id }) != nil {
+			return foreignKeyViolation("staff", "rotation_assignments", "rotation_staff_id")
+		}
+		if find(t.rotationStaffSchedules, func(s *models.RotationStaffSchedule) bool { return s.RotationStaffID == id }) != nil {
+			return foreignKeyViolation("staff", "rotation_staff_schedules", "rotation_staff_id")
+		}
+		delete(t.staff, id)
+		deleteLinks(t.staffBranches, func(l link) bool { return l.left == id })
+		deleteWhere(t.scenarioSpecificStaff, func(s *models.ScenarioSpecificStaffRequirement) bool { return s.StaffID == id })
+		deleteWhere(t.specificPreferences, func(p *models.SpecificPreference) bool { return sameUUID(p.StaffID, &id) })
+		deleteWhere(t.rotationBranchPositions, func(m *models.RotationStaffBranchPosition) bool { return m.RotationStaffID == id })
+		return nil
+	})
+}
+
+func (r *staffRepository) GetBranches(ctx context.Context, staffID uuid.UUID) ([]*models.Branch, error) {
+	var branches []*models.Branch
+	err := r.store.read(ctx, func(t *tables) error {
+		branches = staffBranches(t, staffID)
+		return nil
+	})
+	return branches, err
+}
+
+func (r *staffRepository) BulkUpdateBranches(ctx context.Context, staffID uuid.UUID, branchIDs []uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		for i, branchID := range branchIDs {
+			if _, ok := t.branches[branchID]; !ok {
+				return foreignKeyViolation("staff_branches", "staff_branches", "branch_id")
+			}
+			for _, prev := range branchIDs[:i] {
+				if prev == branchID {
+					return uniqueViolation("staff_branches_staff_id_branch_id_key")
+				}
+			}
+		}
+		deleteLinks(t.staffBranches, func(l link) bool { return l.left == staffID })
+		now := time.Now()
+		for _, branchID := range branchIDs {
+			t.staffBranches[link{staffID, branchID}] = now
+		}
+		return nil
+	})
+}
+
+func (r *staffRepository) List(ctx context.Context, filters interfaces.StaffFilters) ([]*models.Staff, error) {
+	var staffList []*models.Staff
+	err := r.store.read(ctx, func(t *tables) error {
+		staffList = listStaff(t, filters)
+		return nil
+	})
+	return staffList, err
+}
+
+// staffLess orders staff by position display order, then name
+func staffLess(t *tables) func(a, b *models.Staff) bool {
+	displayOrder := func(s *models.Staff) int {
+		if p, ok := t.positions[s.PositionID]; ok {
+			return p.DisplayOrder
+		}
+		return 999
+	}
+	return func(a, b *models.Staff) bool {
+		if da, db := displayOrder(a), displayOrder(b); da != db {
+			return da < db
+		}
+		return a.Name < b.Name
+	}
+}
+
+func listStaff(t *tables, filters interfaces.StaffFilters) []*models.Staff {
+	staffList := collect(t.staff,
+		func(s *models.Staff) bool {
+			if filters.StaffType != nil && s.StaffType != *filters.StaffType {
+				return false
+			}
+			if filters.BranchID != nil && !sameUUID(s.BranchID, filters.BranchID) {
+				if _, ok := t.staffBranches[link{s.ID, *filters.BranchID}]; !ok {
+					return false
+				}
+			}
+			if filters.PositionID != nil && s.PositionID != *filters.PositionID {
+				return false
+			}
+			if filters.AreaOfOperationID != nil && !sameUUID(s.AreaOfOperationID, filters.AreaOfOperationID) {
+				return false
+			}
+			return true
+		},
+		staffLess(t))
+	for _, staff := range staffList {
+		withStaffBranches(t, staff)
+	}
+	return staffList
+}
+
+func (r *staffRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID) ([]*models.Staff, error) {
+	filters := interfaces.StaffFilters{BranchID: &branchID}
+	return r.List(ctx, filters)
+}
+
+func (r *staffRepository) GetByBranchIDs(ctx context.Context, branchIDs []uuid.UUID) (map[uuid.UUID][]*models.Staff, error) {
+	wanted := make(map[uuid.UUID]bool, len(branchIDs))
+	for _, id := range branchIDs {
+		wanted[id] = true
+	}
+	result := make(map[uuid.UUID][]*models.Staff)
+	err := r.store.read(ctx, func(t *tables) error {
+		linked := make(map[uuid.UUID][]uuid.UUID)
+		for l := range t.staffBranches {
+			linked[l.left] = append(linked[l.left], l.right)
+		}
+		// Like the UNION in postgres, staff appear once per branch and
+		// without Branches populated
+		for _, staff := range collect(t.staff, nil, staffLess(t)) {
+			seen := make(map[uuid.UUID]bool)
+			add := func(branchID uuid.UUID) {
+				if wanted[branchID] && !seen[branchID] {
+					seen[branchID] = true
+					row := *staff
+					result[branchID] = append(result[branchID], &row)
+				}
+			}
+			if staff.BranchID != nil {
+				add(*staff.BranchID)
+			}
+			for _, branchID := range linked[staff.ID] {
+				add(branchID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *staffRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.Staff, error) {
+	wanted := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	staffList := []*models.Staff{}
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, staff := range listStaff(t, interfaces.StaffFilters{}) {
+			if wanted[staff.ID] {
+				staffList = append(staffList, staff)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return staffList, nil
+}
+
+func (r *staffRepository) GetRotationStaff(ctx context.Context) ([]*models.Staff, error) {
+	rotationType := models.StaffTypeRotation
+	filters := interfaces.StaffFilters{StaffType: &rotationType}
+	return r.List(ctx, filters)
+}
+
+// PositionRepository implementation
+type positionRepository struct {
+	store *Store
+}
+
+func (r *positionRepository) Create(ctx context.Context, position *models.Position) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.positions[position.ID]; ok {
+			return uniqueViolation("positions_pkey")
+		}
+		if err := checkPositionCode(t, position); err != nil {
+			return err
+		}
+		position.CreatedAt = time.Now()
+		row := *position
+		row.PositionCode = copyPtr(position.PositionCode)
+		row.BranchStaffCount, row.RotationStaffCount = nil, nil
+		t.positions[row.ID] = row
+		return nil
+	})
+}
+
+func checkPositionCode(t *tables, position *models.Position) error {
+	if position.PositionCode == nil {
+		return nil
+	}
+	for _, p := range t.positions {
+		if p.ID != position.ID && p.PositionCode != nil && *p.PositionCode == *position.PositionCode {
+			return uniqueViolation("positions_position_code_key")
+		}
+	}
+	return nil
+}
+
+func (r *positionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Position, error) {
+	var position *models.Position
+	err := r.store.read(ctx, func(t *tables) error {
+		position = get(t.positions, id)
+		return nil
+	})
+	return position, err
+}
+
+func (r *positionRepository) Update(ctx context.Context, position *models.Position) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.positions[position.ID]
+		if !ok {
+			return nil
+		}
+		if err := checkPositionCode(t, position); err != nil {
+			return err
+		}
+		row.Name = position.Name
+		row.PositionCode = copyPtr(position.PositionCode)
+		row.DisplayOrder = position.DisplayOrder
+		row.PositionType = position.PositionType
+		row.ManpowerType = position.ManpowerType
+		t.positions[row.ID] = row
+		return nil
+	})
+}
+
+func (r *positionRepository) HasAssociatedStaff(ctx context.Context, id uuid.UUID) (bool, error) {
+	var associated bool
+	err := r.store.read(ctx, func(t *tables) error {
+		associated = find(t.staff, func(s *models.Staff) bool { return s.PositionID == id }) != nil ||
+			find(t.positionQuotas, func(q *models.PositionQuota) bool { return q.PositionID == id }) != nil ||
+			find(t.allocationRules, func(a *models.StaffAllocationRule) bool { return a.PositionID == id }) != nil ||
+			find(t.scenarioPositions, func(s *models.ScenarioPositionRequirement) bool { return s.PositionID == id }) != nil
+		return nil
+	})
+	return associated, err
+}
+
+func (r *positionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.positions[id]; !ok {
+			return nil
+		}
+		if find(t.staff, func(s *models.Staff) bool { return s.PositionID == id }) != nil {
+			return foreignKeyViolation("positions", "staff", "position_id")
+		}
+		if find(t.allocationRules, func(a *models.StaffAllocationRule) bool { return a.PositionID == id }) != nil {
+			return foreignKeyViolation("positions", "staff_allocation_rules", "position_id")
+		}
+		if find(t.positionQuotas, func(q *models.PositionQuota) bool { return q.PositionID == id }) != nil {
+			return foreignKeyViolation("positions", "position_quotas", "position_id")
+		}
+		if find(t.scenarioPositions, func(s *models.ScenarioPositionRequirement) bool { return s.PositionID == id }) != nil {
+			return foreignKeyViolation("positions", "scenario_position_requirements", "position_id")
+		}
+		delete(t.positions, id)
+		deleteWhere(t.staffGroupPositions, func(s *models.StaffGroupPosition) bool { return s.PositionID == id })
+		deleteWhere(t.preferencePositions, func(p *models.PreferencePositionRequirement) bool { return p.PositionID == id })
+		deleteWhere(t.specificPreferences, func(p *models.SpecificPreference) bool { return sameUUID(p.PositionID, &id) })
+		deleteWhere(t.rotationBranchPositions, func(m *models.RotationStaffBranchPosition) bool { return m.BranchPositionID == id })
+		deleteWhere(t.positionQuotaSummaries, func(s *models.PositionQuotaSummary) bool { return s.PositionID == id })
+		return nil
+	})
+}
+
+func (r *positionRepository) List(ctx context.Context) ([]*models.Position, error) {
+	var positions []*models.Position
+	err := r.store.read(ctx, func(t *tables) error {
+		positions = collect(t.positions, nil, func(a, b *models.Position) bool {
+			if a.DisplayOrder != b.DisplayOrder {
+				return a.DisplayOrder < b.DisplayOrder
+			}
+			return a.Name < b.Name
+		})
+		for _, position := range positions {
+			var branchStaffCount, rotationStaffCount int
+			for _, s := range t.staff {
+				if s.PositionID != position.ID {
+					continue
+				}
+				switch s.StaffType {
+				case models.StaffTypeBranch:
+					branchStaffCount++
+				case models.StaffTypeRotation:
+					rotationStaffCount++
+				}
+			}
+			position.BranchStaffCount = &branchStaffCount
+			position.RotationStaffCount = &rotationStaffCount
+		}
+		return nil
+	})
+	return positions, err
+}
+
+// BranchRepository implementation
+type branchRepository struct {
+	store *Store
+}
+
+func (r *branchRepository) Create(ctx context.Context, branch *models.Branch) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.branches[branch.ID]; ok {
+			return uniqueViolation("branches_pkey")
+		}
+		if err := checkBranchCode(t, branch); err != nil {
+			return err
+		}
+		now := time.Now()
+		branch.CreatedAt, branch.UpdatedAt = now, now
+		t.branches[branch.ID] = branchRow(branch)
+		return nil
+	})
+}
+
+func branchRow(branch *models.Branch) models.Branch {
+	row := *branch
+	row.AreaManager, row.BranchType = nil, nil
+	row.AreaManagerID = copyUUID(branch.AreaManagerID)
+	row.BranchTypeID = copyUUID(branch.BranchTypeID)
+	row.MaxDoctors = copyPtr(branch.MaxDoctors)
+	return row
+}
+
+func checkBranchCode(t *tables, branch *models.Branch) error {
+	for _, b := range t.branches {
+		if b.ID != branch.ID && b.Code == branch.Code {
+			return uniqueViolation("branches_code_key")
+		}
+	}
+	return nil
+}
+
+func (r *branchRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Branch, error) {
+	var branch *models.Branch
+	err := r.store.read(ctx, func(t *tables) error {
+		branch = get(t.branches, id)
+		return nil
+	})
+	return branch, err
+}
+
+func (r *branchRepository) Update(ctx context.Context, branch *models.Branch) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.branches[branch.ID]
+		if !ok {
+			return nil
+		}
+		if err := checkBranchCode(t, branch); err != nil {
+			return err
+		}
+		row.Name = branch.Name
+		row.Code = branch.Code
+		row.AreaManagerID = copyUUID(branch.AreaManagerID)
+		row.BranchTypeID = copyUUID(branch.BranchTypeID)
+		row.Priority = branch.Priority
+		row.MaxDoctors = copyPtr(branch.MaxDoctors)
+		row.UpdatedAt = time.Now()
+		t.branches[row.ID] = row
+		return nil
+	})
+}
+
+func (r *branchRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.branches[id]; !ok {
+			return nil
+		}
+		restricted := []struct {
+			table, column string
+			used          bool
+		}{
+			{"users", "branch_id", find(t.users, func(u *models.User) bool { return sameUUID(u.BranchID, &id) }) != nil},
+			{"staff", "branch_id", find(t.staff, func(s *models.Staff) bool { return sameUUID(s.BranchID, &id) }) != nil},
+			{"effective_branches", "branch_id", find(t.effectiveBranches, func(eb *models.EffectiveBranch) bool { return eb.BranchID == id }) != nil},
+			{"revenue_data", "branch_id", find(t.revenue, func(rv *models.RevenueData) bool { return rv.BranchID == id }) != nil},
+			{"staff_schedules", "branch_id", find(t.schedules, func(s *models.StaffSchedule) bool { return s.BranchID == id }) != nil},
+			{"rotation_assignments", "branch_id", find(t.rotations, func(a *models.RotationAssignment) bool { return a.BranchID == id }) != nil},
+			{"position_quotas", "branch_id", find(t.positionQuotas, func(q *models.PositionQuota) bool { return q.BranchID == id }) != nil},
+			{"doctor_assignments", "branch_id", find(t.doctorAssignments, func(a *models.DoctorAssignment) bool { return a.BranchID == id }) != nil},
+			{"doctor_on_off_days", "branch_id", find(t.doctorOnOffDays, func(d *models.DoctorOnOffDay) bool { return d.BranchID == id }) != nil},
+			{"doctor_default_schedules", "branch_id", find(t.doctorDefaultSchedules, func(d *models.DoctorDefaultSchedule) bool { return d.BranchID == id }) != nil},
+			{"doctor_schedule_overrides", "branch_id", find(t.doctorOverrides, func(o *models.DoctorScheduleOverride) bool { return sameUUID(o.BranchID, &id) }) != nil},
+		}
+		for _, ref := range restricted {
+			if ref.used {
+				return foreignKeyViolation("branches", ref.table, ref.column)
+			}
+		}
+
+		delete(t.branches, id)
+		deleteLinks(t.zoneBranches, func(l link) bool { return l.right == id })
+		deleteLinks(t.aooBranches, func(l link) bool { return l.right == id })
+		deleteLinks(t.staffBranches, func(l link) bool { return l.right == id })
+		deleteWhere(t.doctorPreferences, func(p *models.DoctorPreference) bool { return sameUUID(p.BranchID, &id) })
+		deleteWhere(t.branchWeeklyRevenue, func(w *models.BranchWeeklyRevenue) bool { return w.BranchID == id })
+		deleteWhere(t.doctorRevenueProfiles, func(p *models.DoctorRevenueProfile) bool { return sameUUID(p.BranchID, &id) })
+		deleteWhere(t.doctorLeaveSubstitutes, func(s *models.DoctorLeaveSubstitute) bool { return s.BranchID == id })
+		deleteWhere(t.branchClosures, func(c *models.BranchClosure) bool { return sameUUID(c.BranchID, &id) })
+		for changeID, c := range t.doctorRosterChanges {
+			if sameUUID(c.FromBranchID, &id) || sameUUID(c.ToBranchID, &id) {
+				if sameUUID(c.FromBranchID, &id) {
+					c.FromBranchID = nil
+				}
+				if sameUUID(c.ToBranchID, &id) {
+					c.ToBranchID = nil
+				}
+				t.doctorRosterChanges[changeID] = c
+			}
+		}
+		for constraintID, c := range t.branchConstraints {
+			if c.BranchID == id {
+				delete(t.branchConstraints, constraintID)
+				deleteWhere(t.constraintStaffGroups, func(sg *models.BranchConstraintStaffGroup) bool {
+					return sg.BranchConstraintID == constraintID
+				})
+			}
+		}
+		deleteWhere(t.specificPreferences, func(p *models.SpecificPreference) bool { return sameUUID(p.BranchID, &id) })
+		deleteWhere(t.branchQuotaSummaries, func(s *models.BranchQuotaSummary) bool { return s.BranchID == id })
+		deleteWhere(t.positionQuotaSummaries, func(s *models.PositionQuotaSummary) bool { return s.BranchID == id })
+		for key := range t.quotaSummaryDirty {
+			if key.branchID == id {
+				delete(t.quotaSummaryDirty, key)
+			}
+		}
+		for scenarioID, s := range t.scenarios {
+			if sameUUID(s.BranchID, &id) {
+				s.BranchID = nil
+				t.scenarios[scenarioID] = s
+			}
+		}
+		for accountID, a := range t.serviceAccounts {
+			if sameUUID(a.BranchID, &id) {
+				a.BranchID = nil
+				t.serviceAccounts[accountID] = a
+			}
+		}
+		return nil
+	})
+}
+
+func (r *branchRepository) List(ctx context.Context) ([]*models.Branch, error) {
+	var branches []*models.Branch
+	err := r.store.read(ctx, func(t *tables) error {
+		branches = collect(t.branches, nil, func(a, b *models.Branch) bool { return a.Code < b.Code })
+		for _, branch := range branches {
+			if branch.BranchTypeID != nil {
+				branch.BranchType = get(t.branchTypes, *branch.BranchTypeID)
+			}
+		}
+		return nil
+	})
+	return branches, err
+}
+
+func (r *branchRepository) GetByAreaManagerID(ctx context.Context, areaManagerID uuid.UUID) ([]*models.Branch, error) {
+	var branches []*models.Branch
+	err := r.store.read(ctx, func(t *tables) error {
+		branches = collect(t.branches,
+			func(b *models.Branch) bool { return sameUUID(b.AreaManagerID, &areaManagerID) },
+			func(a, b *models.Branch) bool { return a.Code < b.Code })
+		return nil
+	})
+	return branches, err
+}
+
+// EffectiveBranchRepository implementation
+type effectiveBranchRepository struct {
+	store *Store
+}
+
+// withTravelDefaults applies the defaults the postgres repository writes when
+// the travel fields are left empty
+func withTravelDefaults(eb *models.EffectiveBranch) models.EffectiveBranch {
+	row := *eb
+	row.Branch = nil
+	commuteDuration, transitCount, travelCost := 300, 10, 1000.0
+	if eb.CommuteDurationMinutes != nil {
+		commuteDuration = *eb.CommuteDurationMinutes
+	}
+	if eb.TransitCount != nil {
+		transitCount = *eb.TransitCount
+	}
+	if eb.TravelCost != nil {
+		travelCost = *eb.TravelCost
+	}
+	row.CommuteDurationMinutes = &commuteDuration
+	row.TransitCount = &transitCount
+	row.TravelCost = &travelCost
+	return row
+}
+
+func checkEffectiveBranch(t *tables, eb *models.EffectiveBranch) error {
+	for _, existing := range t.effectiveBranches {
+		if existing.ID != eb.ID && existing.RotationStaffID == eb.RotationStaffID && existing.BranchID == eb.BranchID {
+			return uniqueViolation("effective_branches_rotation_staff_id_branch_id_key")
+		}
+	}
+	return nil
+}
+
+func (r *effectiveBranchRepository) Create(ctx context.Context, eb *models.EffectiveBranch) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.effectiveBranches[eb.ID]; ok {
+			return uniqueViolation("effective_branches_pkey")
+		}
+		if err := checkEffectiveBranch(t, eb); err != nil {
+			return err
+		}
+		eb.CreatedAt = time.Now()
+		t.effectiveBranches[eb.ID] = withTravelDefaults(eb)
+		return nil
+	})
+}
+
+func (r *effectiveBranchRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.EffectiveBranch, error) {
+	var eb *models.EffectiveBranch
+	err := r.store.read(ctx, func(t *tables) error {
+		if eb = get(t.effectiveBranches, id); eb == nil {
+			return errNoRows
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return eb, nil
+}
+
+func effectiveBranchOrder(a, b *models.EffectiveBranch) bool {
+	if a.Level != b.Level {
+		return a.Level < b.Level
+	}
+	return a.CreatedAt.Before(b.CreatedAt)
+}
+
+func (r *effectiveBranchRepository) GetByRotationStaffID(ctx context.Context, rotationStaffID uuid.UUID) ([]*models.EffectiveBranch, error) {
+	var ebs []*models.EffectiveBranch
+	err := r.store.read(ctx, func(t *tables) error {
+		ebs = collect(t.effectiveBranches,
+			func(eb *models.EffectiveBranch) bool { return eb.RotationStaffID == rotationStaffID },
+			effectiveBranchOrder)
+		return nil
+	})
+	return ebs, err
+}
+
+func (r *effectiveBranchRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID) ([]*models.EffectiveBranch, error) {
+	var ebs []*models.EffectiveBranch
+	err := r.store.read(ctx, func(t *tables) error {
+		ebs = collect(t.effectiveBranches,
+			func(eb *models.EffectiveBranch) bool { return eb.BranchID == branchID },
+			effectiveBranchOrder)
+		return nil
+	})
+	return ebs, err
+}
+
+func (r *effectiveBranchRepository) Update(ctx context.Context, eb *models.EffectiveBranch) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.effectiveBranches[eb.ID]
+		if !ok {
+			return nil
+		}
+		if err := checkEffectiveBranch(t, eb); err != nil {
+			return err
+		}
+		updated := withTravelDefaults(eb)
+		updated.CreatedAt = row.CreatedAt
+		t.effectiveBranches[eb.ID] = updated
+		return nil
+	})
+}
+
+func (r *effectiveBranchRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.effectiveBranches, id)
+		return nil
+	})
+}
+
+func (r *effectiveBranchRepository) DeleteByRotationStaffID(ctx context.Context, rotationStaffID uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		deleteWhere(t.effectiveBranches, func(eb *models.EffectiveBranch) bool { return eb.RotationStaffID == rotationStaffID })
+		return nil
+	})
+}
+
+var (
+	_ interfaces.StaffRepository           = (*staffRepository)(nil)
+	_ interfaces.PositionRepository        = (*positionRepository)(nil)
+	_ interfaces.BranchRepository          = (*branchRepository)(nil)
+	_ interfaces.EffectiveBranchRepository = (*effectiveBranchRepository)(nil)
+)
@@ -0,0 +1,612 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// BranchTypeRepository implementation
+type branchTypeRepository struct {
+	store *Store
+}
+
+func (r *branchTypeRepository) Create(ctx context.Context, branchType *models.BranchType) error {
+	branchType.ID = uuid.New()
+	branchType.CreatedAt = time.Now()
+	branchType.UpdatedAt = time.Now()
+
+	return r.store.write(ctx, func(t *tables) error {
+		if find(t.branchTypes, func(bt *models.BranchType) bool { return bt.Name == branchType.Name }) != nil {
+			return uniqueViolation("branch_types_name_key")
+		}
+		t.branchTypes[branchType.ID] = *branchType
+		return nil
+	})
+}
+
+func (r *branchTypeRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BranchType, error) {
+	var branchType *models.BranchType
+	err := r.store.read(ctx, func(t *tables) error {
+		branchType = get(t.branchTypes, id)
+		return nil
+	})
+	return branchType, err
+}
+
+func (r *branchTypeRepository) List(ctx context.Context) ([]*models.BranchType, error) {
+	var branchTypes []*models.BranchType
+	err := r.store.read(ctx, func(t *tables) error {
+		branchTypes = collect(t.branchTypes, nil, func(a, b *models.BranchType) bool { return a.Name < b.Name })
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nonNil(branchTypes), nil
+}
+
+func (r *branchTypeRepository) Update(ctx context.Context, branchType *models.BranchType) error {
+	branchType.UpdatedAt = time.Now()
+
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.branchTypes[branchType.ID]
+		if !ok {
+			return nil
+		}
+		if find(t.branchTypes, func(bt *models.BranchType) bool {
+			return bt.Name == branchType.Name && bt.ID != branchType.ID
+		}) != nil {
+			return uniqueViolation("branch_types_name_key")
+		}
+		row.Name = branchType.Name
+		row.Description = branchType.Description
+		row.IsActive = branchType.IsActive
+		row.UpdatedAt = branchType.UpdatedAt
+		t.branchTypes[row.ID] = row
+		return nil
+	})
+}
+
+func (r *branchTypeRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.branchTypes[id]; !ok {
+			return nil
+		}
+		if find(t.branches, func(b *models.Branch) bool { return sameUUID(b.BranchTypeID, &id) }) != nil {
+			return foreignKeyViolation("branch_types", "branches", "branch_type_id")
+		}
+		delete(t.branchTypes, id)
+		deleteWhere(t.branchTypeRequirements, func(req *models.BranchTypeStaffGroupRequirement) bool { return req.BranchTypeID == id })
+		for constraintID, c := range t.branchTypeConstraints {
+			if c.BranchTypeID == id {
+				delete(t.branchTypeConstraints, constraintID)
+				deleteWhere(t.typeConstraintGroups, func(sg *models.BranchTypeConstraintStaffGroup) bool {
+					return sg.BranchTypeConstraintID == constraintID
+				})
+			}
+		}
+		for constraintID, c := range t.branchConstraints {
+			if sameUUID(c.InheritedFromBranchTypeID, &id) {
+				c.InheritedFromBranchTypeID = nil
+				t.branchConstraints[constraintID] = c
+			}
+		}
+		return nil
+	})
+}
+
+// StaffGroupRepository implementation
+type staffGroupRepository struct {
+	store *Store
+}
+
+func (r *staffGroupRepository) Create(ctx context.Context, staffGroup *models.StaffGroup) error {
+	staffGroup.ID = uuid.New()
+	staffGroup.CreatedAt = time.Now()
+	staffGroup.UpdatedAt = time.Now()
+
+	return r.store.write(ctx, func(t *tables) error {
+		if find(t.staffGroups, func(sg *models.StaffGroup) bool { return sg.Name == staffGroup.Name }) != nil {
+			return uniqueViolation("staff_groups_name_key")
+		}
+		row := *staffGroup
+		row.Positions = nil
+		t.staffGroups[row.ID] = row
+		return nil
+	})
+}
+
+func (r *staffGroupRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.StaffGroup, error) {
+	var staffGroup *models.StaffGroup
+	err := r.store.read(ctx, func(t *tables) error {
+		staffGroup = get(t.staffGroups, id)
+		return nil
+	})
+	return staffGroup, err
+}
+
+func (r *staffGroupRepository) List(ctx context.Context) ([]*models.StaffGroup, error) {
+	var staffGroups []*models.StaffGroup
+	err := r.store.read(ctx, func(t *tables) error {
+		staffGroups = collect(t.staffGroups, nil, func(a, b *models.StaffGroup) bool { return a.Name < b.Name })
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nonNil(staffGroups), nil
+}
+
+func (r *staffGroupRepository) GetByPositionID(ctx context.Context, positionID uuid.UUID) ([]*models.StaffGroup, error) {
+	var staffGroups []*models.StaffGroup
+	err := r.store.read(ctx, func(t *tables) error {
+		member := map[uuid.UUID]bool{}
+		for _, sgp := range t.staffGroupPositions {
+			if sgp.PositionID == positionID {
+				member[sgp.StaffGroupID] = true
+			}
+		}
+		staffGroups = collect(t.staffGroups,
+			func(sg *models.StaffGroup) bool { return member[sg.ID] && sg.IsActive },
+			func(a, b *models.StaffGroup) bool { return a.Name < b.Name })
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nonNil(staffGroups), nil
+}
+
+func (r *staffGroupRepository) Update(ctx context.Context, staffGroup *models.StaffGroup) error {
+	staffGroup.UpdatedAt = time.Now()
+
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.staffGroups[staffGroup.ID]
+		if !ok {
+			return nil
+		}
+		if find(t.staffGroups, func(sg *models.StaffGroup) bool {
+			return sg.Name == staffGroup.Name && sg.ID != staffGroup.ID
+		}) != nil {
+			return uniqueViolation("staff_groups_name_key")
+		}
+		row.Name = staffGroup.Name
+		row.Description = staffGroup.Description
+		row.IsActive = staffGroup.IsActive
+		row.UpdatedAt = staffGroup.UpdatedAt
+		t.staffGroups[row.ID] = row
+		return nil
+	})
+}
+
+func (r *staffGroupRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.staffGroups, id)
+		deleteWhere(t.staffGroupPositions, func(sgp *models.StaffGroupPosition) bool { return sgp.StaffGroupID == id })
+		deleteWhere(t.branchTypeRequirements, func(req *models.BranchTypeStaffGroupRequirement) bool { return req.StaffGroupID == id })
+		deleteWhere(t.constraintStaffGroups, func(sg *models.BranchConstraintStaffGroup) bool { return sg.StaffGroupID == id })
+		deleteWhere(t.typeConstraintGroups, func(sg *models.BranchTypeConstraintStaffGroup) bool { return sg.StaffGroupID == id })
+		return nil
+	})
+}
+
+// StaffGroupPositionRepository implementation
+type staffGroupPositionRepository struct {
+	store *Store
+}
+
+func (r *staffGroupPositionRepository) Create(ctx context.Context, sgp *models.StaffGroupPosition) error {
+	sgp.ID = uuid.New()
+	sgp.CreatedAt = time.Now()
+
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.staffGroups[sgp.StaffGroupID]; !ok {
+			return foreignKeyViolation("staff_group_positions", "staff_group_positions", "staff_group_id")
+		}
+		if _, ok := t.positions[sgp.PositionID]; !ok {
+			return foreignKeyViolation("staff_group_positions", "staff_group_positions", "position_id")
+		}
+		if find(t.staffGroupPositions, func(s *models.StaffGroupPosition) bool {
+			return s.StaffGroupID == sgp.StaffGroupID && s.PositionID == sgp.PositionID
+		}) != nil {
+			return uniqueViolation("staff_group_positions_staff_group_id_position_id_key")
+		}
+		row := *sgp
+		row.StaffGroup, row.Position = nil, nil
+		t.staffGroupPositions[row.ID] = row
+		return nil
+	})
+}
+
+func (r *staffGroupPositionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.StaffGroupPosition, error) {
+	var sgp *models.StaffGroupPosition
+	err := r.store.read(ctx, func(t *tables) error {
+		sgp = get(t.staffGroupPositions, id)
+		return nil
+	})
+	return sgp, err
+}
+
+func (r *staffGroupPositionRepository) GetByStaffGroupID(ctx context.Context, staffGroupID uuid.UUID) ([]*models.StaffGroupPosition, error) {
+	var positions []*models.StaffGroupPosition
+	err := r.store.read(ctx, func(t *tables) error {
+		positions = collect(t.staffGroupPositions,
+			func(s *models.StaffGroupPosition) bool { return s.StaffGroupID == staffGroupID },
+			func(a, b *models.StaffGroupPosition) bool { return uuidLess(a.PositionID, b.PositionID) })
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nonNil(positions), nil
+}
+
+func (r *staffGroupPositionRepository) List(ctx context.Context) ([]*models.StaffGroupPosition, error) {
+	var positions []*models.StaffGroupPosition
+	err := r.store.read(ctx, func(t *tables) error {
+		positions = collect(t.staffGroupPositions, nil,
+			func(a, b *models.StaffGroupPosition) bool {
+				if a.StaffGroupID != b.StaffGroupID {
+					return uuidLess(a.StaffGroupID, b.StaffGroupID)
+				}
+				return uuidLess(a.PositionID, b.PositionID)
+			})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nonNil(positions), nil
+}
+
+func (r *staffGroupPositionRepository) GetByPositionID(ctx context.Context, positionID uuid.UUID) ([]*models.StaffGroupPosition, error) {
+	var positions []*models.StaffGroupPosition
+	err := r.store.read(ctx, func(t *tables) error {
+		positions = collect(t.staffGroupPositions,
+			func(s *models.StaffGroupPosition) bool { return s.PositionID == positionID },
+			func(a, b *models.StaffGroupPosition) bool { return uuidLess(a.StaffGroupID, b.StaffGroupID) })
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nonNil(positions), nil
+}
+
+func (r *staffGroupPositionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.staffGroupPositions, id)
+		return nil
+	})
+}
+
+func (r *staffGroupPositionRepository) DeleteByStaffGroupAndPosition(ctx context.Context, staffGroupID uuid.UUID, positionID uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		deleteWhere(t.staffGroupPositions, func(s *models.StaffGroupPosition) bool {
+			return s.StaffGroupID == staffGroupID && s.PositionID == positionID
+		})
+		return nil
+	})
+}
+
+// BranchTypeStaffGroupRequirementRepository implementation
+type branchTypeRequirementRepository struct {
+	store *Store
+}
+
+func findBranchTypeRequirement(t *tables, req *models.BranchTypeStaffGroupRequirement) *models.BranchTypeStaffGroupRequirement {
+	return find(t.branchTypeRequirements, func(r *models.BranchTypeStaffGroupRequirement) bool {
+		return r.BranchTypeID == req.BranchTypeID && r.StaffGroupID == req.StaffGroupID && r.DayOfWeek == req.DayOfWeek
+	})
+}
+
+func checkBranchTypeRequirement(t *tables, req *models.BranchTypeStaffGroupRequirement) error {
+	if _, ok := t.branchTypes[req.BranchTypeID]; !ok {
+		return foreignKeyViolation("branch_type_staff_group_requirements", "branch_type_staff_group_requirements", "branch_type_id")
+	}
+	if _, ok := t.staffGroups[req.StaffGroupID]; !ok {
+		return foreignKeyViolation("branch_type_staff_group_requirements", "branch_type_staff_group_requirements", "staff_group_id")
+	}
+	return nil
+}
+
+func branchTypeRequirementRow(req *models.BranchTypeStaffGroupRequirement) models.BranchTypeStaffGroupRequirement {
+	row := *req
+	row.BranchType, row.StaffGroup = nil, nil
+	return row
+}
+
+func (r *branchTypeRequirementRepository) Create(ctx context.Context, requirement *models.BranchTypeStaffGroupRequirement) error {
+	requirement.ID = uuid.New()
+	requirement.CreatedAt = time.Now()
+	requirement.UpdatedAt = time.Now()
+
+	return r.store.write(ctx, func(t *tables) error {
+		if err := checkBranchTypeRequirement(t, requirement); err != nil {
+			return err
+		}
+		if findBranchTypeRequirement(t, requirement) != nil {
+			return uniqueViolation("branch_type_staff_group_requirements_branch_type_id_staff_group_id_day_of_week_key")
+		}
+		t.branchTypeRequirements[requirement.ID] = branchTypeRequirementRow(requirement)
+		return nil
+	})
+}
+
+func (r *branchTypeRequirementRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BranchTypeStaffGroupRequirement, error) {
+	var requirement *models.BranchTypeStaffGroupRequirement
+	err := r.store.read(ctx, func(t *tables) error {
+		requirement = get(t.branchTypeRequirements, id)
+		return nil
+	})
+	return requirement, err
+}
+
+func (r *branchTypeRequirementRepository) GetByBranchTypeID(ctx context.Context, branchTypeID uuid.UUID) ([]*models.BranchTypeStaffGroupRequirement, error) {
+	var requirements []*models.BranchTypeStaffGroupRequirement
+	err := r.store.read(ctx, func(t *tables) error {
+		requirements = collect(t.branchTypeRequirements,
+			func(req *models.BranchTypeStaffGroupRequirement) bool { return req.BranchTypeID == branchTypeID },
+			func(a, b *models.BranchTypeStaffGroupRequirement) bool {
+				if a.DayOfWeek != b.DayOfWeek {
+					return a.DayOfWeek < b.DayOfWeek
+				}
+				return uuidLess(a.StaffGroupID, b.StaffGroupID)
+			})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nonNil(requirements), nil
+}
+
+func (r *branchTypeRequirementRepository) GetByStaffGroupID(ctx context.Context, staffGroupID uuid.UUID) ([]*models.BranchTypeStaffGroupRequirement, error) {
+	var requirements []*models.BranchTypeStaffGroupRequirement
+	err := r.store.read(ctx, func(t *tables) error {
+		requirements = collect(t.branchTypeRequirements,
+			func(req *models.BranchTypeStaffGroupRequirement) bool { return req.StaffGroupID == staffGroupID },
+			func(a, b *models.BranchTypeStaffGroupRequirement) bool {
+				if a.BranchTypeID != b.BranchTypeID {
+					return uuidLess(a.BranchTypeID, b.BranchTypeID)
+				}
+				return a.DayOfWeek < b.DayOfWeek
+			})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nonNil(requirements), nil
+}
+
+func (r *branchTypeRequirementRepository) List(ctx context.Context) ([]*models.BranchTypeStaffGroupRequirement, error) {
+	var requirements []*models.BranchTypeStaffGroupRequirement
+	err := r.store.read(ctx, func(t *tables) error {
+		requirements = collect(t.branchTypeRequirements, nil,
+			func(a, b *models.BranchTypeStaffGroupRequirement) bool {
+				if a.BranchTypeID != b.BranchTypeID {
+					return uuidLess(a.BranchTypeID, b.BranchTypeID)
+				}
+				if a.DayOfWeek != b.DayOfWeek {
+					return a.DayOfWeek < b.DayOfWeek
+				}
+				return uuidLess(a.StaffGroupID, b.StaffGroupID)
+			})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nonNil(requirements), nil
+}
+
+func (r *branchTypeRequirementRepository) Update(ctx context.Context, requirement *models.BranchTypeStaffGroupRequirement) error {
+	requirement.UpdatedAt = time.Now()
+
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.branchTypeRequirements[requirement.ID]
+		if !ok {
+			return errNoRows
+		}
+		row.MinimumStaffCount = requirement.MinimumStaffCount
+		row.IsActive = requirement.IsActive
+		row.UpdatedAt = requirement.UpdatedAt
+		t.branchTypeRequirements[row.ID] = row
+		return nil
+	})
+}
+
+func (r *branchTypeRequirementRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.branchTypeRequirements, id)
+		return nil
+	})
+}
+
+func (r *branchTypeRequirementRepository) BulkUpsert(ctx context.Context, requirements []*models.BranchTypeStaffGroupRequirement) error {
+	return r.store.write(ctx, func(t *tables) error {
+		for _, requirement := range requirements {
+			if err := checkBranchTypeRequirement(t, requirement); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		for _, requirement := range requirements {
+			if requirement.ID == uuid.Nil {
+				requirement.ID = uuid.New()
+			}
+			if existing := findBranchTypeRequirement(t, requirement); existing != nil {
+				existing.MinimumStaffCount = requirement.MinimumStaffCount
+				existing.IsActive = requirement.IsActive
+				existing.UpdatedAt = now
+				t.branchTypeRequirements[existing.ID] = *existing
+				requirement.CreatedAt, requirement.UpdatedAt = existing.CreatedAt, existing.UpdatedAt
+				continue
+			}
+			requirement.CreatedAt, requirement.UpdatedAt = now, now
+			t.branchTypeRequirements[requirement.ID] = branchTypeRequirementRow(requirement)
+		}
+		return nil
+	})
+}
+
+// RotationStaffBranchPositionRepository implementation
+type rotationStaffBranchPositionRepository struct {
+	store *Store
+}
+
+func rotationBranchPositionRow(m *models.RotationStaffBranchPosition) models.RotationStaffBranchPosition {
+	row := *m
+	row.RotationStaff, row.BranchPosition = nil, nil
+	return row
+}
+
+func (r *rotationStaffBranchPositionRepository) Create(ctx context.Context, mapping *models.RotationStaffBranchPosition) error {
+	mapping.ID = uuid.New()
+	mapping.CreatedAt = time.Now()
+	mapping.UpdatedAt = time.Now()
+
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.staff[mapping.RotationStaffID]; !ok {
+			return foreignKeyViolation("rotation_staff_branch_positions", "rotation_staff_branch_positions", "rotation_staff_id")
+		}
+		if _, ok := t.positions[mapping.BranchPositionID]; !ok {
+			return foreignKeyViolation("rotation_staff_branch_positions", "rotation_staff_branch_positions", "branch_position_id")
+		}
+		if find(t.rotationBranchPositions, func(m *models.RotationStaffBranchPosition) bool {
+			return m.RotationStaffID == mapping.RotationStaffID && m.BranchPositionID == mapping.BranchPositionID
+		}) != nil {
+			return uniqueViolation("rotation_staff_branch_positions_rotation_staff_id_branch_position_id_key")
+		}
+		t.rotationBranchPositions[mapping.ID] = rotationBranchPositionRow(mapping)
+		return nil
+	})
+}
+
+func (r *rotationStaffBranchPositionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.RotationStaffBranchPosition, error) {
+	var mapping *models.RotationStaffBranchPosition
+	err := r.store.read(ctx, func(t *tables) error {
+		mapping = get(t.rotationBranchPositions, id)
+		return nil
+	})
+	return mapping, err
+}
+
+func (r *rotationStaffBranchPositionRepository) List(ctx context.Context) ([]*models.RotationStaffBranchPosition, error) {
+	var mappings []*models.RotationStaffBranchPosition
+	err := r.store.read(ctx, func(t *tables) error {
+		mappings = collect(t.rotationBranchPositions, nil, func(a, b *models.RotationStaffBranchPosition) bool {
+			if a.RotationStaffID != b.RotationStaffID {
+				return uuidLess(a.RotationStaffID, b.RotationStaffID)
+			}
+			if a.SubstitutionLevel != b.SubstitutionLevel {
+				return a.SubstitutionLevel < b.SubstitutionLevel
+			}
+			return uuidLess(a.BranchPositionID, b.BranchPositionID)
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nonNil(mappings), nil
+}
+
+func (r *rotationStaffBranchPositionRepository) GetByStaffID(ctx context.Context, rotationStaffID uuid.UUID) ([]*models.RotationStaffBranchPosition, error) {
+	var mappings []*models.RotationStaffBranchPosition
+	err := r.store.read(ctx, func(t *tables) error {
+		mappings = collect(t.rotationBranchPositions,
+			func(m *models.RotationStaffBranchPosition) bool { return m.RotationStaffID == rotationStaffID },
+			func(a, b *models.RotationStaffBranchPosition) bool {
+				if a.SubstitutionLevel != b.SubstitutionLevel {
+					return a.SubstitutionLevel < b.SubstitutionLevel
+				}
+				return uuidLess(a.BranchPositionID, b.BranchPositionID)
+			})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nonNil(mappings), nil
+}
+
+func (r *rotationStaffBranchPositionRepository) GetByPositionID(ctx context.Context, branchPositionID uuid.UUID) ([]*models.RotationStaffBranchPosition, error) {
+	var mappings []*models.RotationStaffBranchPosition
+	err := r.store.read(ctx, func(t *tables) error {
+		mappings = collect(t.rotationBranchPositions,
+			func(m *models.RotationStaffBranchPosition) bool {
+				return m.BranchPositionID == branchPositionID && m.IsActive
+			},
+			func(a, b *models.RotationStaffBranchPosition) bool {
+				if a.SubstitutionLevel != b.SubstitutionLevel {
+					return a.SubstitutionLevel < b.SubstitutionLevel
+				}
+				return uuidLess(a.RotationStaffID, b.RotationStaffID)
+			})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nonNil(mappings), nil
+}
+
+func (r *rotationStaffBranchPositionRepository) GetByStaffAndPosition(ctx context.Context, rotationStaffID uuid.UUID, branchPositionID uuid.UUID) (*models.RotationStaffBranchPosition, error) {
+	var mapping *models.RotationStaffBranchPosition
+	err := r.store.read(ctx, func(t *tables) error {
+		mapping = find(t.rotationBranchPositions, func(m *models.RotationStaffBranchPosition) bool {
+			return m.RotationStaffID == rotationStaffID && m.BranchPositionID == branchPositionID && m.IsActive
+		})
+		return nil
+	})
+	return mapping, err
+}
+
+func (r *rotationStaffBranchPositionRepository) Update(ctx context.Context, mapping *models.RotationStaffBranchPosition) error {
+	mapping.UpdatedAt = time.Now()
+
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.rotationBranchPositions[mapping.ID]
+		if !ok {
+			return nil
+		}
+		row.SubstitutionLevel = mapping.SubstitutionLevel
+		row.IsActive = mapping.IsActive
+		row.Notes = mapping.Notes
+		row.UpdatedAt = mapping.UpdatedAt
+		t.rotationBranchPositions[row.ID] = row
+		return nil
+	})
+}
+
+func (r *rotationStaffBranchPositionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.rotationBranchPositions, id)
+		return nil
+	})
+}
+
+func (r *rotationStaffBranchPositionRepository) DeleteByStaffAndPosition(ctx context.Context, rotationStaffID uuid.UUID, branchPositionID uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		deleteWhere(t.rotationBranchPositions, func(m *models.RotationStaffBranchPosition) bool {
+			return m.RotationStaffID == rotationStaffID && m.BranchPositionID == branchPositionID
+		})
+		return nil
+	})
+}
+
+var (
+	_ interfaces.BranchTypeRepository                      = (*branchTypeRepository)(nil)
+	_ interfaces.StaffGroupRepository                      = (*staffGroupRepository)(nil)
+	_ interfaces.StaffGroupPositionRepository              = (*staffGroupPositionRepository)(nil)
+	_ interfaces.BranchTypeStaffGroupRequirementRepository = (*branchTypeRequirementRepository)(nil)
+	_ interfaces.RotationStaffBranchPositionRepository     = (*rotationStaffBranchPositionRepository)(nil)
+)
@@ -0,0 +1,396 @@
+// Package memory implements every repository in internal/domain/interfaces on
+// in-process maps. It mirrors the postgres repositories (ordering, upserts,
+// not-found behaviour, cascades and the quota summary triggers) closely enough
+// to run the usecases in tests and to boot the server with STORAGE=memory.
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"sort"
+	"sync"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// Store holds all tables. Rows are stored by value and copied on the way in and
+// out, so callers never share memory with the store.
+type Store struct {
+	// writeMu serialises writers. An open transaction holds it until it commits
+	// or rolls back, the way row locks block concurrent writers in Postgres.
+	writeMu sync.Mutex
+	mu      sync.RWMutex
+	data    *tables
+	// done is set once a transaction store has committed or rolled back
+	done bool
+}
+
+// NewStore creates an empty store
+func NewStore() *Store {
+	return &Store{data: newTables()}
+}
+
+type link struct {
+	left, right uuid.UUID
+}
+
+type tables struct {
+	users                   map[uuid.UUID]models.User
+	userIdentities          map[uuid.UUID]models.UserIdentity
+	linkApprovals           map[uuid.UUID]models.UserIdentityLinkApproval // keyed by user_id
+	roles                   map[uuid.UUID]models.Role
+	staff                   map[uuid.UUID]models.Staff
+	staffBranches           map[link]time.Time // staff_id, branch_id
+	positions               map[uuid.UUID]models.Position
+	branches                map[uuid.UUID]models.Branch
+	effectiveBranches       map[uuid.UUID]models.EffectiveBranch
+	revenue                 map[uuid.UUID]models.RevenueData
+	schedules               map[uuid.UUID]models.StaffSchedule
+	rotations               map[uuid.UUID]models.RotationAssignment
+	rotationStaffSchedules  map[uuid.UUID]models.RotationStaffSchedule
+	settings                map[uuid.UUID]models.SystemSetting
+	allocationRules         map[uuid.UUID]models.StaffAllocationRule
+	areasOfOperation        map[uuid.UUID]models.AreaOfOperation
+	zones                   map[uuid.UUID]models.Zone
+	zoneBranches            map[link]time.Time // zone_id, branch_id
+	aooZones                map[link]time.Time // area_of_operation_id, zone_id
+	aooBranches             map[link]time.Time // area_of_operation_id, branch_id
+	allocationCriteria      map[uuid.UUID]models.AllocationCriteria
+	positionQuotas          map[uuid.UUID]models.PositionQuota
+	doctors                 map[uuid.UUID]models.Doctor
+	doctorPreferences       map[uuid.UUID]models.DoctorPreference
+	doctorAssignments       map[uuid.UUID]models.DoctorAssignment
+	doctorOnOffDays         map[uuid.UUID]models.DoctorOnOffDay
+	doctorDefaultSchedules  map[uuid.UUID]models.DoctorDefaultSchedule
+	doctorWeeklyOffDays     map[uuid.UUID]models.DoctorWeeklyOffDay
+	doctorOverrides         map[uuid.UUID]models.DoctorScheduleOverride
+	doctorRevenueProfiles   map[uuid.UUID]models.DoctorRevenueProfile
+	doctorRevenueOverrides  map[uuid.UUID]models.DoctorRevenueOverride
+	doctorLeaves            map[uuid.UUID]models.DoctorLeave
+	doctorLeaveSubstitutes  map[uuid.UUID]models.DoctorLeaveSubstitute
+	doctorRosterRuns        map[uuid.UUID]models.DoctorRosterRun
+	doctorRosterChanges     map[uuid.UUID]models.DoctorRosterChange
+	branchWeeklyRevenue     map[uuid.UUID]models.BranchWeeklyRevenue
+	branchConstraints       map[uuid.UUID]models.BranchConstraints
+	branchClosures          map[uuid.UUID]models.BranchClosure
+	constraintStaffGroups   map[uuid.UUID]models.BranchConstraintStaffGroup
+	branchTypeConstraints   map[uuid.UUID]models.BranchTypeConstraints
+	typeConstraintGroups    map[uuid.UUID]models.BranchTypeConstraintStaffGroup
+	revenueLevelTiers       map[uuid.UUID]models.RevenueLevelTier
+	scenarios               map[uuid.UUID]models.StaffRequirementScenario
+	scenarioPositions       map[uuid.UUID]models.ScenarioPositionRequirement
+	scenarioSpecificStaff   map[uuid.UUID]models.ScenarioSpecificStaffRequirement
+	clinicPreferences       map[uuid.UUID]models.ClinicWidePreference
+	preferencePositions     map[uuid.UUID]models.PreferencePositionRequirement
+	branchTypes             map[uuid.UUID]models.BranchType
+	staffGroups             map[uuid.UUID]models.StaffGroup
+	staffGroupPositions     map[uuid.UUID]models.StaffGroupPosition
+	branchTypeRequirements  map[uuid.UUID]models.BranchTypeStaffGroupRequirement
+	specificPreferences     map[uuid.UUID]models.SpecificPreference
+	rotationBranchPositions map[uuid.UUID]models.RotationStaffBranchPosition
+	allocationSuggestions   map[uuid.UUID]models.AllocationSuggestion
+	branchQuotaSummaries    map[uuid.UUID]models.BranchQuotaSummary
+	positionQuotaSummaries  map[uuid.UUID]models.PositionQuotaSummary // position_quota_daily_summary
+	quotaSummaryDirty       map[summaryKey]models.QuotaSummaryDirtyKey
+	serviceAccounts         map[uuid.UUID]models.ServiceAccount
+	apiTokens               map[uuid.UUID]models.APIToken
+	jobs                    map[uuid.UUID]jobRow
+	jobSchedules            map[string]models.JobSchedule
+}
+
+func newTables() *tables {
+	return &tables{
+		users:                   map[uuid.UUID]models.User{},
+		userIdentities:          map[uuid.UUID]models.UserIdentity{},
+		linkApprovals:           map[uuid.UUID]models.UserIdentityLinkApproval{},
+		roles:                   map[uuid.UUID]models.Role{},
+		staff:                   map[uuid.UUID]models.Staff{},
+		staffBranches:           map[link]time.Time{},
+		positions:               map[uuid.UUID]models.Position{},
+		branches:                map[uuid.UUID]models.Branch{},
+		effectiveBranches:       map[uuid.UUID]models.EffectiveBranch{},
+		revenue:                 map[uuid.UUID]models.RevenueData{},
+		schedules:               map[uuid.UUID]models.StaffSchedule{},
+		rotations:               map[uuid.UUID]models.RotationAssignment{},
+		rotationStaffSchedules:  map[uuid.UUID]models.RotationStaffSchedule{},
+		settings:                map[uuid.UUID]models.SystemSetting{},
+		allocationRules:         map[uuid.UUID]models.StaffAllocationRule{},
+		areasOfOperation:        map[uuid.UUID]models.AreaOfOperation{},
+		zones:                   map[uuid.UUID]models.Zone{},
+		zoneBranches:            map[link]time.Time{},
+		aooZones:                map[link]time.Time{},
+		aooBranches:             map[link]time.Time{},
+		allocationCriteria:      map[uuid.UUID]models.AllocationCriteria{},
+		positionQuotas:          map[uuid.UUID]models.PositionQuota{},
+		doctors:                 map[uuid.UUID]models.Doctor{},
+		doctorPreferences:       map[uuid.UUID]models.DoctorPreference{},
+		doctorAssignments:       map[uuid.UUID]models.DoctorAssignment{},
+		doctorOnOffDays:         map[uuid.UUID]models.DoctorOnOffDay{},
+		doctorDefaultSchedules:  map[uuid.UUID]models.DoctorDefaultSchedule{},
+		doctorWeeklyOffDays:     map[uuid.UUID]models.DoctorWeeklyOffDay{},
+		doctorOverrides:         map[uuid.UUID]models.DoctorScheduleOverride{},
+		doctorRevenueProfiles:   map[uuid.UUID]models.DoctorRevenueProfile{},
+		doctorRevenueOverrides:  map[uuid.UUID]models.DoctorRevenueOverride{},
+		doctorLeaves:            map[uuid.UUID]models.DoctorLeave{},
+		doctorLeaveSubstitutes:  map[uuid.UUID]models.DoctorLeaveSubstitute{},
+		doctorRosterRuns:        map[uuid.UUID]models.DoctorRosterRun{},
+		doctorRosterChanges:     map[uuid.UUID]models.DoctorRosterChange{},
+		branchWeeklyRevenue:     map[uuid.UUID]models.BranchWeeklyRevenue{},
+		branchConstraints:       map[uuid.UUID]models.BranchConstraints{},
+		branchClosures:          map[uuid.UUID]models.BranchClosure{},
+		constraintStaffGroups:   map[uuid.UUID]models.BranchConstraintStaffGroup{},
+		branchTypeConstraints:   map[uuid.UUID]models.BranchTypeConstraints{},
+		typeConstraintGroups:    map[uuid.UUID]models.BranchTypeConstraintStaffGroup{},
+		revenueLevelTiers:       map[uuid.UUID]models.RevenueLevelTier{},
+		scenarios:               map[uuid.UUID]models.StaffRequirementScenario{},
+		scenarioPositions:       map[uuid.UUID]models.ScenarioPositionRequirement{},
+		scenarioSpecificStaff:   map[uuid.UUID]models.ScenarioSpecificStaffRequirement{},
+		clinicPreferences:       map[uuid.UUID]models.ClinicWidePreference{},
+		preferencePositions:     map[uuid.UUID]models.PreferencePositionRequirement{},
+		branchTypes:             map[uuid.UUID]models.BranchType{},
+		staffGroups:             map[uuid.UUID]models.StaffGroup{},
+		staffGroupPositions:     map[uuid.UUID]models.StaffGroupPosition{},
+		branchTypeRequirements:  map[uuid.UUID]models.BranchTypeStaffGroupRequirement{},
+		specificPreferences:     map[uuid.UUID]models.SpecificPreference{},
+		rotationBranchPositions: map[uuid.UUID]models.RotationStaffBranchPosition{},
+		allocationSuggestions:   map[uuid.UUID]models.AllocationSuggestion{},
+		branchQuotaSummaries:    map[uuid.UUID]models.BranchQuotaSummary{},
+		positionQuotaSummaries:  map[uuid.UUID]models.PositionQuotaSummary{},
+		quotaSummaryDirty:       map[summaryKey]models.QuotaSummaryDirtyKey{},
+		serviceAccounts:         map[uuid.UUID]models.ServiceAccount{},
+		apiTokens:               map[uuid.UUID]models.APIToken{},
+		jobs:                    map[uuid.UUID]jobRow{},
+		jobSchedules:            map[string]models.JobSchedule{},
+	}
+}
+
+// clone copies every table. Rows are values and are replaced rather than
+// mutated in place, so a shallow copy of each map is enough.
+func (t *tables) clone() *tables {
+	return &tables{
+		users:                   maps.Clone(t.users),
+		userIdentities:          maps.Clone(t.userIdentities),
+		linkApprovals:           maps.Clone(t.linkApprovals),
+		roles:                   maps.Clone(t.roles),
+		staff:                   maps.Clone(t.staff),
+		staffBranches:           maps.Clone(t.staffBranches),
+		positions:               maps.Clone(t.positions),
+		branches:                maps.Clone(t.branches),
+		effectiveBranches:       maps.Clone(t.effectiveBranches),
+		revenue:                 maps.Clone(t.revenue),
+		schedules:               maps.Clone(t.schedules),
+		rotations:               maps.Clone(t.rotations),
+		rotationStaffSchedules:  maps.Clone(t.rotationStaffSchedules),
+		settings:                maps.Clone(t.settings),
+		allocationRules:         maps.Clone(t.allocationRules),
+		areasOfOperation:        maps.Clone(t.areasOfOperation),
+		zones:                   maps.Clone(t.zones),
+		zoneBranches:            maps.Clone(t.zoneBranches),
+		aooZones:                maps.Clone(t.aooZones),
+		aooBranches:             maps.Clone(t.aooBranches),
+		allocationCriteria:      maps.Clone(t.allocationCriteria),
+		positionQuotas:          maps.Clone(t.positionQuotas),
+		doctors:                 maps.Clone(t.doctors),
+		doctorPreferences:       maps.Clone(t.doctorPreferences),
+		doctorAssignments:       maps.Clone(t.doctorAssignments),
+		doctorOnOffDays:         maps.Clone(t.doctorOnOffDays),
+		doctorDefaultSchedules:  maps.Clone(t.doctorDefaultSchedules),
+		doctorWeeklyOffDays:     maps.Clone(t.doctorWeeklyOffDays),
+		doctorOverrides:         maps.Clone(t.doctorOverrides),
+		doctorRevenueProfiles:   maps.Clone(t.doctorRevenueProfiles),
+		doctorRevenueOverrides:  maps.Clone(t.doctorRevenueOverrides),
+		doctorLeaves:            maps.Clone(t.doctorLeaves),
+		doctorLeaveSubstitutes:  maps.Clone(t.doctorLeaveSubstitutes),
+		doctorRosterRuns:        maps.Clone(t.doctorRosterRuns),
+		doctorRosterChanges:     maps.Clone(t.doctorRosterChanges),
+		branchWeeklyRevenue:     maps.Clone(t.branchWeeklyRevenue),
+		branchConstraints:       maps.Clone(t.branchConstraints),
+		branchClosures:          maps.Clone(t.branchClosures),
+		constraintStaffGroups:   maps.Clone(t.constraintStaffGroups),
+		branchTypeConstraints:   maps.Clone(t.branchTypeConstraints),
+		typeConstraintGroups:    maps.Clone(t.typeConstraintGroups),
+		revenueLevelTiers:       maps.Clone(t.revenueLevelTiers),
+		scenarios:               maps.Clone(t.scenarios),
+		scenarioPositions:       maps.Clone(t.scenarioPositions),
+		scenarioSpecificStaff:   maps.Clone(t.scenarioSpecificStaff),
+		clinicPreferences:       maps.Clone(t.clinicPreferences),
+		preferencePositions:     maps.Clone(t.preferencePositions),
+		branchTypes:             maps.Clone(t.branchTypes),
+		staffGroups:             maps.Clone(t.staffGroups),
+		staffGroupPositions:     maps.Clone(t.staffGroupPositions),
+		branchTypeRequirements:  maps.Clone(t.branchTypeRequirements),
+		specificPreferences:     maps.Clone(t.specificPreferences),
+		rotationBranchPositions: maps.Clone(t.rotationBranchPositions),
+		allocationSuggestions:   maps.Clone(t.allocationSuggestions),
+		branchQuotaSummaries:    maps.Clone(t.branchQuotaSummaries),
+		positionQuotaSummaries:  maps.Clone(t.positionQuotaSummaries),
+		quotaSummaryDirty:       maps.Clone(t.quotaSummaryDirty),
+		serviceAccounts:         maps.Clone(t.serviceAccounts),
+		apiTokens:               maps.Clone(t.apiTokens),
+		jobs:                    maps.Clone(t.jobs),
+		jobSchedules:            maps.Clone(t.jobSchedules),
+	}
+}
+
+// read runs fn against the committed tables
+func (s *Store) read(ctx context.Context, fn func(t *tables) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.done {
+		return sql.ErrTxDone
+	}
+	return fn(s.data)
+}
+
+// write runs fn with exclusive access. fn must leave the tables consistent even
+// when it returns an error, because there is no statement-level rollback.
+func (s *Store) write(ctx context.Context, fn func(t *tables) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return sql.ErrTxDone
+	}
+	return fn(s.data)
+}
+
+// errNoRows is returned where the postgres repository surfaces sql.ErrNoRows
+var errNoRows = sql.ErrNoRows
+
+// uniqueViolation matches the text of a Postgres unique violation, which some
+// handlers inspect to treat duplicates as no-ops
+func uniqueViolation(constraint string) error {
+	return fmt.Errorf("duplicate key value violates unique constraint %q", constraint)
+}
+
+// foreignKeyViolation matches the text of a Postgres restrict violation, for
+// deletes of rows that other tables still reference without ON DELETE CASCADE
+func foreignKeyViolation(table, referencing, column string) error {
+	return fmt.Errorf("update or delete on table %q violates foreign key constraint %q on table %q",
+		table, referencing+"_"+column+"_fkey", referencing)
+}
+
+// day truncates t to its calendar date, the way a Postgres DATE column stores it
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// inRange reports whether date falls within [start, end], compared as dates
+func inRange(date, start, end time.Time) bool {
+	d := day(date)
+	return !d.Before(day(start)) && !d.After(day(end))
+}
+
+// today is CURRENT_DATE
+func today() time.Time {
+	return day(time.Now())
+}
+
+// collect returns pointers to copies of the rows matching keep, sorted by less.
+// Ties fall back to the ID so results are stable between calls.
+func collect[T any](rows map[uuid.UUID]T, keep func(*T) bool, less func(a, b *T) bool) []*T {
+	var out []*T
+	for _, row := range rows {
+		row := row
+		if keep == nil || keep(&row) {
+			out = append(out, &row)
+		}
+	}
+	if less != nil {
+		sort.SliceStable(out, func(i, j int) bool { return less(out[i], out[j]) })
+	}
+	return out
+}
+
+// nonNil turns a nil result into an empty slice, for repositories whose
+// postgres implementation initialises the slice before scanning
+func nonNil[T any](rows []*T) []*T {
+	if rows == nil {
+		return []*T{}
+	}
+	return rows
+}
+
+// find returns a copy of the first row matching keep
+func find[T any](rows map[uuid.UUID]T, keep func(*T) bool) *T {
+	for _, row := range rows {
+		row := row
+		if keep(&row) {
+			return &row
+		}
+	}
+	return nil
+}
+
+// get returns a copy of the row with id, or nil
+func get[T any](rows map[uuid.UUID]T, id uuid.UUID) *T {
+	row, ok := rows[id]
+	if !ok {
+		return nil
+	}
+	return &row
+}
+
+// deleteWhere removes every row matching drop
+func deleteWhere[T any](rows map[uuid.UUID]T, drop func(*T) bool) {
+	for id, row := range rows {
+		row := row
+		if drop(&row) {
+			delete(rows, id)
+		}
+	}
+}
+
+// deleteLinks removes every link row matching drop
+func deleteLinks(rows map[link]time.Time, drop func(l link) bool) {
+	for l := range rows {
+		if drop(l) {
+			delete(rows, l)
+		}
+	}
+}
+
+func uuidLess(a, b uuid.UUID) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+func sameUUID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// copyUUID detaches a nullable column from the caller's pointer
+func copyUUID(id *uuid.UUID) *uuid.UUID {
+	if id == nil {
+		return nil
+	}
+	v := *id
+	return &v
+}
+
+func copyPtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// AllocationSuggestionRepository implementation
+type allocationSuggestionRepository struct {
+	store *Store
+}
+
+func suggestionRow(s *models.AllocationSuggestion) models.AllocationSuggestion {
+	row := *s
+	row.RotationStaff, row.Branch, row.Position = nil, nil, nil
+	row.Date = day(s.Date)
+	row.ReviewedBy = copyUUID(s.ReviewedBy)
+	row.ReviewedAt = copyPtr(s.ReviewedAt)
+	return row
+}
+
+func (r *allocationSuggestionRepository) Create(ctx context.Context, suggestion *models.AllocationSuggestion) error {
+	suggestion.ID = uuid.New()
+	now := time.Now()
+	suggestion.CreatedAt = now
+	suggestion.UpdatedAt = now
+
+	return r.store.write(ctx, func(t *tables) error {
+		t.allocationSuggestions[suggestion.ID] = suggestionRow(suggestion)
+		return nil
+	})
+}
+
+func (r *allocationSuggestionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.AllocationSuggestion, error) {
+	var suggestion *models.AllocationSuggestion
+	err := r.store.read(ctx, func(t *tables) error {
+		if s, ok := t.allocationSuggestions[id]; ok {
+			row := suggestionRow(&s)
+			suggestion = &row
+		}
+		return nil
+	})
+	return suggestion, err
+}
+
+func (r *allocationSuggestionRepository) Update(ctx context.Context, suggestion *models.AllocationSuggestion) error {
+	suggestion.UpdatedAt = time.Now()
+
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.allocationSuggestions[suggestion.ID]
+		if !ok {
+			return nil
+		}
+		row.Status = suggestion.Status
+		row.Confidence = suggestion.Confidence
+		row.Reason = suggestion.Reason
+		row.CriteriaUsed = suggestion.CriteriaUsed
+		row.ReviewedBy = copyUUID(suggestion.ReviewedBy)
+		row.ReviewedAt = copyPtr(suggestion.ReviewedAt)
+		row.UpdatedAt = suggestion.UpdatedAt
+		t.allocationSuggestions[row.ID] = row
+		return nil
+	})
+}
+
+func (r *allocationSuggestionRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.allocationSuggestions, id)
+		return nil
+	})
+}
+
+func (r *allocationSuggestionRepository) List(ctx context.Context, filters interfaces.AllocationSuggestionFilters) ([]*models.AllocationSuggestion, error) {
+	suggestions := []*models.AllocationSuggestion{}
+	err := r.store.read(ctx, func(t *tables) error {
+		for _, s := range collect(t.allocationSuggestions,
+			func(s *models.AllocationSuggestion) bool {
+				if filters.BranchID != nil && s.BranchID != *filters.BranchID {
+					return false
+				}
+				if filters.RotationStaffID != nil && s.RotationStaffID != *filters.RotationStaffID {
+					return false
+				}
+				if filters.PositionID != nil && s.PositionID != *filters.PositionID {
+					return false
+				}
+				if filters.Status != nil && s.Status != *filters.Status {
+					return false
+				}
+				if filters.StartDate != nil && s.Date.Before(day(*filters.StartDate)) {
+					return false
+				}
+				if filters.EndDate != nil && s.Date.After(day(*filters.EndDate)) {
+					return false
+				}
+				return true
+			},
+			func(a, b *models.AllocationSuggestion) bool {
+				if !a.Date.Equal(b.Date) {
+					return a.Date.After(b.Date)
+				}
+				return a.Confidence > b.Confidence
+			}) {
+			row := suggestionRow(s)
+			suggestions = append(suggestions, &row)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+func (r *allocationSuggestionRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) ([]*models.AllocationSuggestion, error) {
+	filters := interfaces.AllocationSuggestionFilters{
+		BranchID:  &branchID,
+		StartDate: &startDate,
+		EndDate:   &endDate,
+	}
+	return r.List(ctx, filters)
+}
+
+func (r *allocationSuggestionRepository) GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.AllocationSuggestion, error) {
+	filters := interfaces.AllocationSuggestionFilters{
+		StartDate: &startDate,
+		EndDate:   &endDate,
+	}
+	return r.List(ctx, filters)
+}
+
+var _ interfaces.AllocationSuggestionRepository = (*allocationSuggestionRepository)(nil)
@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+)
+
+// txManager hands out repositories bound to a snapshot of the store. The
+// snapshot replaces the committed tables on Commit and is dropped on Rollback.
+//
+// An open transaction holds the store's write lock, so writes through the root
+// repositories block until it finishes, much like Postgres row locks. Calling a
+// root repository write from inside the transaction's own callback deadlocks;
+// use the repositories the transaction hands out instead.
+type txManager struct {
+	store *Store
+}
+
+// NewUnitOfWork creates a unit of work backed by store snapshots
+func NewUnitOfWork(store *Store) interfaces.UnitOfWork {
+	return &txManager{store: store}
+}
+
+func (m *txManager) Begin(ctx context.Context) (interfaces.Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	m.store.writeMu.Lock()
+	m.store.mu.RLock()
+	snapshot := &Store{data: m.store.data.clone()}
+	m.store.mu.RUnlock()
+	return &transaction{parent: m.store, snapshot: snapshot, repos: newRepositorySet(snapshot)}, nil
+}
+
+func (m *txManager) Do(ctx context.Context, fn func(tx *interfaces.Repositories) error) (err error) {
+	t, err := m.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			t.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(t.Repositories()); err != nil {
+		t.Rollback()
+		return err
+	}
+	return t.Commit()
+}
+
+type transaction struct {
+	parent   *Store
+	snapshot *Store
+	repos    *interfaces.Repositories
+}
+
+func (t *transaction) Repositories() *interfaces.Repositories {
+	return t.repos
+}
+
+func (t *transaction) Commit() error {
+	data, ok := t.finish()
+	if !ok {
+		return sql.ErrTxDone
+	}
+	t.parent.mu.Lock()
+	t.parent.data = data
+	t.parent.mu.Unlock()
+	t.parent.writeMu.Unlock()
+	return nil
+}
+
+// Rollback is safe to defer: after Commit it does nothing
+func (t *transaction) Rollback() error {
+	if _, ok := t.finish(); ok {
+		t.parent.writeMu.Unlock()
+	}
+	return nil
+}
+
+// finish closes the snapshot and returns its tables, or false if the
+// transaction was already committed or rolled back
+func (t *transaction) finish() (*tables, bool) {
+	// Wait for any statement still running on the snapshot
+	t.snapshot.writeMu.Lock()
+	defer t.snapshot.writeMu.Unlock()
+	t.snapshot.mu.Lock()
+	defer t.snapshot.mu.Unlock()
+	if t.snapshot.done {
+		return nil, false
+	}
+	t.snapshot.done = true
+	return t.snapshot.data, true
+}
@@ -0,0 +1,415 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// UserRepository implementation
+type userRepository struct {
+	store *Store
+}
+
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
+	return r.store.write(ctx, func(t *tables) error {
+		for _, u := range t.users {
+			if u.Username == user.Username {
+				return uniqueViolation("users_username_key")
+			}
+			if u.Email == user.Email {
+				return uniqueViolation("users_email_key")
+			}
+		}
+		now := time.Now()
+		user.CreatedAt, user.UpdatedAt = now, now
+		row := *user
+		row.Role, row.Branch = nil, nil
+		row.BranchID = copyUUID(user.BranchID)
+		t.users[row.ID] = row
+		return nil
+	})
+}
+
+func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	var user *models.User
+	err := r.store.read(ctx, func(t *tables) error {
+		user = get(t.users, id)
+		return nil
+	})
+	return user, err
+}
+
+func (r *userRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	var user *models.User
+	err := r.store.read(ctx, func(t *tables) error {
+		user = find(t.users, func(u *models.User) bool { return u.Username == username })
+		return nil
+	})
+	return user, err
+}
+
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user *models.User
+	err := r.store.read(ctx, func(t *tables) error {
+		user = find(t.users, func(u *models.User) bool { return u.Email == email })
+		return nil
+	})
+	return user, err
+}
+
+func (r *userRepository) Update(ctx context.Context, user *models.User) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.users[user.ID]
+		if !ok {
+			return nil
+		}
+		row.Username = user.Username
+		row.Email = user.Email
+		row.PasswordHash = user.PasswordHash
+		row.RoleID = user.RoleID
+		row.BranchID = copyUUID(user.BranchID)
+		row.UpdatedAt = time.Now()
+		t.users[row.ID] = row
+		return nil
+	})
+}
+
+func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.serviceAccounts[id]; ok {
+			return foreignKeyViolation("users", "service_accounts", "id")
+		}
+		if find(t.positionQuotas, func(q *models.PositionQuota) bool { return q.CreatedBy == id }) != nil {
+			return foreignKeyViolation("users", "position_quotas", "created_by")
+		}
+		delete(t.users, id)
+		deleteWhere(t.userIdentities, func(i *models.UserIdentity) bool { return i.UserID == id })
+		delete(t.linkApprovals, id)
+		return nil
+	})
+}
+
+func (r *userRepository) List(ctx context.Context) ([]*models.User, error) {
+	var users []*models.User
+	err := r.store.read(ctx, func(t *tables) error {
+		// Service accounts' backing users are managed with their accounts
+		users = collect(t.users, func(u *models.User) bool {
+			_, ok := t.serviceAccounts[u.ID]
+			return !ok
+		}, func(a, b *models.User) bool {
+			return a.CreatedAt.After(b.CreatedAt)
+		})
+		return nil
+	})
+	return users, err
+}
+
+// UserIdentityRepository implementation
+type userIdentityRepository struct {
+	store *Store
+}
+
+func (r *userIdentityRepository) Create(ctx context.Context, identity *models.UserIdentity) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if identity.ID == uuid.Nil {
+			identity.ID = uuid.New()
+		}
+		for _, i := range t.userIdentities {
+			if i.Issuer == identity.Issuer && i.Subject == identity.Subject {
+				return uniqueViolation("user_identities_issuer_subject_key")
+			}
+		}
+		identity.CreatedAt = time.Now()
+		row := *identity
+		row.LastLoginAt = copyPtr(identity.LastLoginAt)
+		t.userIdentities[row.ID] = row
+		return nil
+	})
+}
+
+func (r *userIdentityRepository) GetByIssuerAndSubject(ctx context.Context, issuer, subject string) (*models.UserIdentity, error) {
+	var identity *models.UserIdentity
+	err := r.store.read(ctx, func(t *tables) error {
+		identity = find(t.userIdentities, func(i *models.UserIdentity) bool {
+			return i.Issuer == issuer && i.Subject == subject
+		})
+		return nil
+	})
+	return identity, err
+}
+
+func (r *userIdentityRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.UserIdentity, error) {
+	var identities []*models.UserIdentity
+	err := r.store.read(ctx, func(t *tables) error {
+		identities = collect(t.userIdentities,
+			func(i *models.UserIdentity) bool { return i.UserID == userID },
+			func(a, b *models.UserIdentity) bool { return a.CreatedAt.Before(b.CreatedAt) })
+		return nil
+	})
+	return identities, err
+}
+
+func (r *userIdentityRepository) TouchLastLogin(ctx context.Context, id uuid.UUID, loginAt time.Time) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if row, ok := t.userIdentities[id]; ok {
+			row.LastLoginAt = &loginAt
+			t.userIdentities[id] = row
+		}
+		return nil
+	})
+}
+
+func (r *userIdentityRepository) ApproveLink(ctx context.Context, approval *models.UserIdentityLinkApproval) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if _, ok := t.users[approval.UserID]; !ok {
+			return foreignKeyViolation("user_identity_link_approvals", "user_identity_link_approvals", "user_id")
+		}
+		approval.ApprovedAt = time.Now()
+		row := *approval
+		row.ApprovedBy = copyUUID(approval.ApprovedBy)
+		t.linkApprovals[row.UserID] = row
+		return nil
+	})
+}
+
+func (r *userIdentityRepository) ConsumeLinkApproval(ctx context.Context, userID uuid.UUID) (bool, error) {
+	var found bool
+	err := r.store.write(ctx, func(t *tables) error {
+		_, found = t.linkApprovals[userID]
+		delete(t.linkApprovals, userID)
+		return nil
+	})
+	return found, err
+}
+
+// RoleRepository implementation
+type roleRepository struct {
+	store *Store
+}
+
+func (r *roleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Role, error) {
+	var role *models.Role
+	err := r.store.read(ctx, func(t *tables) error {
+		role = get(t.roles, id)
+		return nil
+	})
+	return role, err
+}
+
+func (r *roleRepository) GetByName(ctx context.Context, name string) (*models.Role, error) {
+	var role *models.Role
+	err := r.store.read(ctx, func(t *tables) error {
+		role = find(t.roles, func(ro *models.Role) bool { return ro.Name == name })
+		return nil
+	})
+	return role, err
+}
+
+func (r *roleRepository) List(ctx context.Context) ([]*models.Role, error) {
+	var roles []*models.Role
+	err := r.store.read(ctx, func(t *tables) error {
+		roles = collect(t.roles, nil, func(a, b *models.Role) bool { return a.Name < b.Name })
+		return nil
+	})
+	return roles, err
+}
+
+// ServiceAccountRepository implementation
+type serviceAccountRepository struct {
+	store *Store
+}
+
+func (r *serviceAccountRepository) Create(ctx context.Context, account *models.ServiceAccount) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if account.ID == uuid.Nil {
+			account.ID = uuid.New()
+		}
+		now := time.Now()
+		user := *account.BackingUser()
+		if _, ok := t.users[user.ID]; ok {
+			return uniqueViolation("users_pkey")
+		}
+		for _, u := range t.users {
+			if u.Username == user.Username {
+				return uniqueViolation("users_username_key")
+			}
+			if u.Email == user.Email {
+				return uniqueViolation("users_email_key")
+			}
+		}
+		user.BranchID = copyUUID(account.BranchID)
+		user.CreatedAt, user.UpdatedAt = now, now
+		t.users[user.ID] = user
+
+		account.CreatedAt, account.UpdatedAt = now, now
+		row := *account
+		row.RoleName = ""
+		row.BranchID = copyUUID(account.BranchID)
+		row.CreatedBy = copyUUID(account.CreatedBy)
+		t.serviceAccounts[row.ID] = row
+		return nil
+	})
+}
+
+// withRoleName fills the joined role name the way the postgres query does
+func withRoleName(t *tables, account *models.ServiceAccount) *models.ServiceAccount {
+	if role, ok := t.roles[account.RoleID]; ok {
+		account.RoleName = role.Name
+	}
+	return account
+}
+
+func (r *serviceAccountRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ServiceAccount, error) {
+	var account *models.ServiceAccount
+	err := r.store.read(ctx, func(t *tables) error {
+		if account = get(t.serviceAccounts, id); account != nil {
+			withRoleName(t, account)
+		}
+		return nil
+	})
+	return account, err
+}
+
+func (r *serviceAccountRepository) List(ctx context.Context) ([]*models.ServiceAccount, error) {
+	var accounts []*models.ServiceAccount
+	err := r.store.read(ctx, func(t *tables) error {
+		accounts = collect(t.serviceAccounts, nil, func(a, b *models.ServiceAccount) bool { return a.Name < b.Name })
+		for _, account := range accounts {
+			withRoleName(t, account)
+		}
+		return nil
+	})
+	return accounts, err
+}
+
+func (r *serviceAccountRepository) Update(ctx context.Context, account *models.ServiceAccount) error {
+	return r.store.write(ctx, func(t *tables) error {
+		row, ok := t.serviceAccounts[account.ID]
+		if !ok {
+			return errNoRows
+		}
+		row.Name = account.Name
+		row.Description = account.Description
+		row.RoleID = account.RoleID
+		row.BranchID = copyUUID(account.BranchID)
+		row.IsActive = account.IsActive
+		row.UpdatedAt = time.Now()
+		t.serviceAccounts[row.ID] = row
+		if user, ok := t.users[row.ID]; ok {
+			user.RoleID, user.BranchID, user.UpdatedAt = row.RoleID, copyUUID(row.BranchID), row.UpdatedAt
+			t.users[user.ID] = user
+		}
+		account.UpdatedAt = row.UpdatedAt
+		return nil
+	})
+}
+
+func (r *serviceAccountRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		delete(t.serviceAccounts, id)
+		deleteWhere(t.apiTokens, func(tok *models.APIToken) bool { return tok.ServiceAccountID == id })
+		return nil
+	})
+}
+
+// APITokenRepository implementation
+type apiTokenRepository struct {
+	store *Store
+}
+
+func (r *apiTokenRepository) Create(ctx context.Context, token *models.APIToken) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if token.ID == uuid.Nil {
+			token.ID = uuid.New()
+		}
+		for _, existing := range t.apiTokens {
+			if existing.TokenHash == token.TokenHash {
+				return uniqueViolation("api_tokens_token_hash_key")
+			}
+		}
+		token.CreatedAt = time.Now()
+		row := *token
+		row.Scopes = slices.Clone(token.Scopes)
+		row.ExpiresAt = copyPtr(token.ExpiresAt)
+		row.CreatedBy = copyUUID(token.CreatedBy)
+		t.apiTokens[row.ID] = row
+		return nil
+	})
+}
+
+// copyToken detaches the scopes slice from the stored row
+func copyToken(token *models.APIToken) *models.APIToken {
+	if token != nil {
+		token.Scopes = slices.Clone(token.Scopes)
+	}
+	return token
+}
+
+func (r *apiTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.APIToken, error) {
+	var token *models.APIToken
+	err := r.store.read(ctx, func(t *tables) error {
+		token = copyToken(get(t.apiTokens, id))
+		return nil
+	})
+	return token, err
+}
+
+func (r *apiTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.APIToken, error) {
+	var token *models.APIToken
+	err := r.store.read(ctx, func(t *tables) error {
+		token = copyToken(find(t.apiTokens, func(tok *models.APIToken) bool { return tok.TokenHash == tokenHash }))
+		return nil
+	})
+	return token, err
+}
+
+func (r *apiTokenRepository) List(ctx context.Context, serviceAccountID *uuid.UUID) ([]*models.APIToken, error) {
+	var tokens []*models.APIToken
+	err := r.store.read(ctx, func(t *tables) error {
+		tokens = collect(t.apiTokens,
+			func(tok *models.APIToken) bool {
+				return serviceAccountID == nil || tok.ServiceAccountID == *serviceAccountID
+			},
+			func(a, b *models.APIToken) bool { return a.CreatedAt.After(b.CreatedAt) })
+		for _, token := range tokens {
+			copyToken(token)
+		}
+		return nil
+	})
+	return tokens, err
+}
+
+func (r *apiTokenRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if row, ok := t.apiTokens[id]; ok && row.RevokedAt == nil {
+			now := time.Now()
+			row.RevokedAt = &now
+			t.apiTokens[id] = row
+		}
+		return nil
+	})
+}
+
+func (r *apiTokenRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	return r.store.write(ctx, func(t *tables) error {
+		if row, ok := t.apiTokens[id]; ok {
+			row.LastUsedAt = &usedAt
+			t.apiTokens[id] = row
+		}
+		return nil
+	})
+}
+
+var (
+	_ interfaces.UserRepository           = (*userRepository)(nil)
+	_ interfaces.UserIdentityRepository   = (*userIdentityRepository)(nil)
+	_ interfaces.RoleRepository           = (*roleRepository)(nil)
+	_ interfaces.ServiceAccountRepository = (*serviceAccountRepository)(nil)
+	_ interfaces.APITokenRepository       = (*apiTokenRepository)(nil)
+)
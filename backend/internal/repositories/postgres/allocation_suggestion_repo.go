@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -12,14 +13,14 @@ import (
 )
 
 type allocationSuggestionRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewAllocationSuggestionRepository(db *sql.DB) interfaces.AllocationSuggestionRepository {
+func NewAllocationSuggestionRepository(db DBTX) interfaces.AllocationSuggestionRepository {
 	return &allocationSuggestionRepository{db: db}
 }
 
-func (r *allocationSuggestionRepository) Create(suggestion *models.AllocationSuggestion) error {
+func (r *allocationSuggestionRepository) Create(ctx context.Context, suggestion *models.AllocationSuggestion) error {
 	suggestion.ID = uuid.New()
 	now := time.Now()
 	suggestion.CreatedAt = now
@@ -39,7 +40,7 @@ func (r *allocationSuggestionRepository) Create(suggestion *models.AllocationSug
 		reviewedAt = sql.NullTime{Time: *suggestion.ReviewedAt, Valid: true}
 	}
 
-	return r.db.QueryRow(query,
+	return r.db.QueryRowContext(ctx, query,
 		suggestion.ID,
 		suggestion.RotationStaffID,
 		suggestion.BranchID,
@@ -56,7 +57,7 @@ func (r *allocationSuggestionRepository) Create(suggestion *models.AllocationSug
 	).Scan(&suggestion.CreatedAt, &suggestion.UpdatedAt)
 }
 
-func (r *allocationSuggestionRepository) GetByID(id uuid.UUID) (*models.AllocationSuggestion, error) {
+func (r *allocationSuggestionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.AllocationSuggestion, error) {
 	suggestion := &models.AllocationSuggestion{}
 	query := `SELECT id, rotation_staff_id, branch_id, date, position_id, status, confidence, reason, criteria_used, reviewed_by, reviewed_at, created_at, updated_at
 	          FROM allocation_suggestions WHERE id = $1`
@@ -64,7 +65,7 @@ func (r *allocationSuggestionRepository) GetByID(id uuid.UUID) (*models.Allocati
 	var reviewedBy sql.NullString
 	var reviewedAt sql.NullTime
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&suggestion.ID,
 		&suggestion.RotationStaffID,
 		&suggestion.BranchID,
@@ -100,7 +101,7 @@ func (r *allocationSuggestionRepository) GetByID(id uuid.UUID) (*models.Allocati
 	return suggestion, nil
 }
 
-func (r *allocationSuggestionRepository) Update(suggestion *models.AllocationSuggestion) error {
+func (r *allocationSuggestionRepository) Update(ctx context.Context, suggestion *models.AllocationSuggestion) error {
 	suggestion.UpdatedAt = time.Now()
 
 	query := `UPDATE allocation_suggestions 
@@ -117,7 +118,7 @@ func (r *allocationSuggestionRepository) Update(suggestion *models.AllocationSug
 		reviewedAt = sql.NullTime{Time: *suggestion.ReviewedAt, Valid: true}
 	}
 
-	_, err := r.db.Exec(query,
+	_, err := r.db.ExecContext(ctx, query,
 		suggestion.Status,
 		suggestion.Confidence,
 		suggestion.Reason,
@@ -130,13 +131,13 @@ func (r *allocationSuggestionRepository) Update(suggestion *models.AllocationSug
 	return err
 }
 
-func (r *allocationSuggestionRepository) Delete(id uuid.UUID) error {
+func (r *allocationSuggestionRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM allocation_suggestions WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *allocationSuggestionRepository) List(filters interfaces.AllocationSuggestionFilters) ([]*models.AllocationSuggestion, error) {
+func (r *allocationSuggestionRepository) List(ctx context.Context, filters interfaces.AllocationSuggestionFilters) ([]*models.AllocationSuggestion, error) {
 	query := `SELECT id, rotation_staff_id, branch_id, date, position_id, status, confidence, reason, criteria_used, reviewed_by, reviewed_at, created_at, updated_at
 	          FROM allocation_suggestions WHERE 1=1`
 	args := []interface{}{}
@@ -180,7 +181,7 @@ func (r *allocationSuggestionRepository) List(filters interfaces.AllocationSugge
 
 	query += " ORDER BY date DESC, confidence DESC"
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -227,19 +228,19 @@ func (r *allocationSuggestionRepository) List(filters interfaces.AllocationSugge
 	return suggestions, rows.Err()
 }
 
-func (r *allocationSuggestionRepository) GetByBranchID(branchID uuid.UUID, startDate, endDate time.Time) ([]*models.AllocationSuggestion, error) {
+func (r *allocationSuggestionRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) ([]*models.AllocationSuggestion, error) {
 	filters := interfaces.AllocationSuggestionFilters{
 		BranchID:  &branchID,
 		StartDate: &startDate,
 		EndDate:   &endDate,
 	}
-	return r.List(filters)
+	return r.List(ctx, filters)
 }
 
-func (r *allocationSuggestionRepository) GetByDateRange(startDate, endDate time.Time) ([]*models.AllocationSuggestion, error) {
+func (r *allocationSuggestionRepository) GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.AllocationSuggestion, error) {
 	filters := interfaces.AllocationSuggestionFilters{
 		StartDate: &startDate,
 		EndDate:   &endDate,
 	}
-	return r.List(filters)
+	return r.List(ctx, filters)
 }
@@ -0,0 +1,211 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type serviceAccountRepository struct {
+	db DBTX
+}
+
+func NewServiceAccountRepository(db DBTX) interfaces.ServiceAccountRepository {
+	return &serviceAccountRepository{db: db}
+}
+
+func (r *serviceAccountRepository) Create(ctx context.Context, account *models.ServiceAccount) error {
+	if account.ID == uuid.Nil {
+		account.ID = uuid.New()
+	}
+	user := account.BackingUser()
+	query := `WITH backing_user AS (
+	              INSERT INTO users (id, username, email, password_hash, role_id, branch_id)
+	              VALUES ($1, $8, $9, $10, $4, $5)
+	          )
+	          INSERT INTO service_accounts (id, name, description, role_id, branch_id, is_active, created_by)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING created_at, updated_at`
+	return r.db.QueryRowContext(ctx, query, account.ID, account.Name, account.Description, account.RoleID,
+		account.BranchID, account.IsActive, account.CreatedBy, user.Username, user.Email, user.PasswordHash).
+		Scan(&account.CreatedAt, &account.UpdatedAt)
+}
+
+const serviceAccountColumns = `sa.id, sa.name, COALESCE(sa.description, ''), sa.role_id, r.name, sa.branch_id,
+	sa.is_active, sa.created_by, sa.created_at, sa.updated_at`
+
+func scanServiceAccount(scanner interface{ Scan(...interface{}) error }) (*models.ServiceAccount, error) {
+	account := &models.ServiceAccount{}
+	var branchID, createdBy uuid.NullUUID
+	if err := scanner.Scan(
+		&account.ID, &account.Name, &account.Description, &account.RoleID, &account.RoleName,
+		&branchID, &account.IsActive, &createdBy, &account.CreatedAt, &account.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if branchID.Valid {
+		account.BranchID = &branchID.UUID
+	}
+	if createdBy.Valid {
+		account.CreatedBy = &createdBy.UUID
+	}
+	return account, nil
+}
+
+func (r *serviceAccountRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ServiceAccount, error) {
+	query := `SELECT ` + serviceAccountColumns + `
+	          FROM service_accounts sa JOIN roles r ON r.id = sa.role_id
+	          WHERE sa.id = $1`
+	account, err := scanServiceAccount(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return account, err
+}
+
+func (r *serviceAccountRepository) List(ctx context.Context) ([]*models.ServiceAccount, error) {
+	query := `SELECT ` + serviceAccountColumns + `
+	          FROM service_accounts sa JOIN roles r ON r.id = sa.role_id
+	          ORDER BY sa.name`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []*models.ServiceAccount
+	for rows.Next() {
+		account, err := scanServiceAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, rows.Err()
+}
+
+func (r *serviceAccountRepository) Update(ctx context.Context, account *models.ServiceAccount) error {
+	// The backing user follows the account's role and branch
+	query := `WITH backing_user AS (
+	              UPDATE users SET role_id = $3, branch_id = $4, updated_at = CURRENT_TIMESTAMP WHERE id = $6
+	          )
+	          UPDATE service_accounts SET name = $1, description = $2, role_id = $3, branch_id = $4,
+	          is_active = $5, updated_at = CURRENT_TIMESTAMP WHERE id = $6 RETURNING updated_at`
+	return r.db.QueryRowContext(ctx, query, account.Name, account.Description, account.RoleID, account.BranchID,
+		account.IsActive, account.ID).Scan(&account.UpdatedAt)
+}
+
+// Delete removes the account and its tokens; the backing user is removed separately
+func (r *serviceAccountRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM service_accounts WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+type apiTokenRepository struct {
+	db DBTX
+}
+
+func NewAPITokenRepository(db DBTX) interfaces.APITokenRepository {
+	return &apiTokenRepository{db: db}
+}
+
+func (r *apiTokenRepository) Create(ctx context.Context, token *models.APIToken) error {
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	query := `INSERT INTO api_tokens (id, service_account_id, name, token_prefix, token_hash, scopes, expires_at, created_by)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING created_at`
+	return r.db.QueryRowContext(ctx, query, token.ID, token.ServiceAccountID, token.Name, token.TokenPrefix,
+		token.TokenHash, pq.Array(token.Scopes), token.ExpiresAt, token.CreatedBy).
+		Scan(&token.CreatedAt)
+}
+
+const apiTokenColumns = `id, service_account_id, name, token_prefix, token_hash, scopes,
+	expires_at, last_used_at, revoked_at, created_by, created_at`
+
+func scanAPIToken(scanner interface{ Scan(...interface{}) error }) (*models.APIToken, error) {
+	token := &models.APIToken{}
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	var createdBy uuid.NullUUID
+	if err := scanner.Scan(
+		&token.ID, &token.ServiceAccountID, &token.Name, &token.TokenPrefix, &token.TokenHash,
+		pq.Array(&token.Scopes), &expiresAt, &lastUsedAt, &revokedAt, &createdBy, &token.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		token.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	if createdBy.Valid {
+		token.CreatedBy = &createdBy.UUID
+	}
+	return token, nil
+}
+
+func (r *apiTokenRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE id = $1`
+	token, err := scanAPIToken(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return token, err
+}
+
+func (r *apiTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens WHERE token_hash = $1`
+	token, err := scanAPIToken(r.db.QueryRowContext(ctx, query, tokenHash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return token, err
+}
+
+func (r *apiTokenRepository) List(ctx context.Context, serviceAccountID *uuid.UUID) ([]*models.APIToken, error) {
+	query := `SELECT ` + apiTokenColumns + ` FROM api_tokens`
+	args := []interface{}{}
+	if serviceAccountID != nil {
+		query += ` WHERE service_account_id = $1`
+		args = append(args, *serviceAccountID)
+	}
+	query += ` ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []*models.APIToken
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+func (r *apiTokenRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE api_tokens SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND revoked_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+func (r *apiTokenRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	query := `UPDATE api_tokens SET last_used_at = $1 WHERE id = $2`
+	_, err := r.db.ExecContext(ctx, query, usedAt, id)
+	return err
+}
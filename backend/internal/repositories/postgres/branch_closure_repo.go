@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// BranchClosureRepository implementation
+type branchClosureRepository struct {
+	db DBTX
+}
+
+func NewBranchClosureRepository(db DBTX) interfaces.BranchClosureRepository {
+	return &branchClosureRepository{db: db}
+}
+
+const branchClosureColumns = `c.id, c.branch_id, COALESCE(b.code, ''), c.start_date, c.end_date, c.reason,
+	          c.created_by, c.created_at, c.updated_at`
+
+func scanBranchClosure(scanner interface{ Scan(...interface{}) error }) (*models.BranchClosure, error) {
+	closure := &models.BranchClosure{}
+	var branchID, createdBy uuid.NullUUID
+	if err := scanner.Scan(
+		&closure.ID, &branchID, &closure.BranchCode, &closure.StartDate, &closure.EndDate, &closure.Reason,
+		&createdBy, &closure.CreatedAt, &closure.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if branchID.Valid {
+		closure.BranchID = &branchID.UUID
+	}
+	closure.CreatedBy = createdBy.UUID
+	return closure, nil
+}
+
+func (r *branchClosureRepository) Create(ctx context.Context, closure *models.BranchClosure) error {
+	if closure.ID == uuid.Nil {
+		closure.ID = uuid.New()
+	}
+	query := `INSERT INTO branch_closures (id, branch_id, start_date, end_date, reason, created_by, created_at, updated_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+	          RETURNING created_at, updated_at`
+	return r.db.QueryRowContext(ctx, query, closure.ID, closure.BranchID, closure.StartDate, closure.EndDate,
+		closure.Reason, closure.CreatedBy, time.Now()).
+		Scan(&closure.CreatedAt, &closure.UpdatedAt)
+}
+
+func (r *branchClosureRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BranchClosure, error) {
+	query := `SELECT ` + branchClosureColumns + ` FROM branch_closures c
+	          LEFT JOIN branches b ON c.branch_id = b.id WHERE c.id = $1`
+	closure, err := scanBranchClosure(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return closure, err
+}
+
+func (r *branchClosureRepository) List(ctx context.Context, filters interfaces.BranchClosureFilters) ([]*models.BranchClosure, error) {
+	query := `SELECT ` + branchClosureColumns + ` FROM branch_closures c
+	          LEFT JOIN branches b ON c.branch_id = b.id
+	          WHERE 1=1`
+	var args []interface{}
+	if filters.BranchID != nil {
+		args = append(args, *filters.BranchID)
+		query += fmt.Sprintf(" AND (c.branch_id = $%d OR c.branch_id IS NULL)", len(args))
+	}
+	if filters.StartDate != nil {
+		args = append(args, *filters.StartDate)
+		query += fmt.Sprintf(" AND c.end_date >= $%d", len(args))
+	}
+	if filters.EndDate != nil {
+		args = append(args, *filters.EndDate)
+		query += fmt.Sprintf(" AND c.start_date <= $%d", len(args))
+	}
+	query += ` ORDER BY c.start_date, b.code NULLS FIRST, c.id`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	closures := []*models.BranchClosure{}
+	for rows.Next() {
+		closure, err := scanBranchClosure(rows)
+		if err != nil {
+			return nil, err
+		}
+		closures = append(closures, closure)
+	}
+	return closures, rows.Err()
+}
+
+func (r *branchClosureRepository) Update(ctx context.Context, closure *models.BranchClosure) error {
+	query := `UPDATE branch_closures SET branch_id = $2, start_date = $3, end_date = $4, reason = $5, updated_at = $6
+	          WHERE id = $1 RETURNING updated_at`
+	return r.db.QueryRowContext(ctx, query, closure.ID, closure.BranchID, closure.StartDate, closure.EndDate,
+		closure.Reason, time.Now()).Scan(&closure.UpdatedAt)
+}
+
+func (r *branchClosureRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM branch_closures WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
@@ -1,7 +1,7 @@
 package postgres
 
 import (
-	"database/sql"
+	"context"
 
 	"vsq-oper-manpower/backend/internal/domain/interfaces"
 	"vsq-oper-manpower/backend/internal/domain/models"
@@ -10,26 +10,26 @@ import (
 )
 
 type branchConstraintStaffGroupRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewBranchConstraintStaffGroupRepository(db *sql.DB) interfaces.BranchConstraintStaffGroupRepository {
+func NewBranchConstraintStaffGroupRepository(db DBTX) interfaces.BranchConstraintStaffGroupRepository {
 	return &branchConstraintStaffGroupRepository{db: db}
 }
 
-func (r *branchConstraintStaffGroupRepository) Create(sg *models.BranchConstraintStaffGroup) error {
+func (r *branchConstraintStaffGroupRepository) Create(ctx context.Context, sg *models.BranchConstraintStaffGroup) error {
 	query := `INSERT INTO branch_constraint_staff_groups (id, branch_constraint_id, staff_group_id, minimum_count) 
 	          VALUES ($1, $2, $3, $4) RETURNING created_at, updated_at`
-	return r.db.QueryRow(query, sg.ID, sg.BranchConstraintID, sg.StaffGroupID, sg.MinimumCount).
+	return r.db.QueryRowContext(ctx, query, sg.ID, sg.BranchConstraintID, sg.StaffGroupID, sg.MinimumCount).
 		Scan(&sg.CreatedAt, &sg.UpdatedAt)
 }
 
-func (r *branchConstraintStaffGroupRepository) GetByConstraintID(constraintID uuid.UUID) ([]*models.BranchConstraintStaffGroup, error) {
+func (r *branchConstraintStaffGroupRepository) GetByConstraintID(ctx context.Context, constraintID uuid.UUID) ([]*models.BranchConstraintStaffGroup, error) {
 	query := `SELECT id, branch_constraint_id, staff_group_id, minimum_count, created_at, updated_at 
 	          FROM branch_constraint_staff_groups 
 	          WHERE branch_constraint_id = $1 
 	          ORDER BY staff_group_id`
-	rows, err := r.db.Query(query, constraintID)
+	rows, err := r.db.QueryContext(ctx, query, constraintID)
 	if err != nil {
 		return nil, err
 	}
@@ -49,13 +49,13 @@ func (r *branchConstraintStaffGroupRepository) GetByConstraintID(constraintID uu
 	return staffGroups, rows.Err()
 }
 
-func (r *branchConstraintStaffGroupRepository) GetByBranchID(branchID uuid.UUID) ([]*models.BranchConstraintStaffGroup, error) {
+func (r *branchConstraintStaffGroupRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID) ([]*models.BranchConstraintStaffGroup, error) {
 	query := `SELECT sg.id, sg.branch_constraint_id, sg.staff_group_id, sg.minimum_count, sg.created_at, sg.updated_at 
 	          FROM branch_constraint_staff_groups sg
 	          INNER JOIN branch_constraints c ON sg.branch_constraint_id = c.id
 	          WHERE c.branch_id = $1 
 	          ORDER BY c.day_of_week, sg.staff_group_id`
-	rows, err := r.db.Query(query, branchID)
+	rows, err := r.db.QueryContext(ctx, query, branchID)
 	if err != nil {
 		return nil, err
 	}
@@ -75,24 +75,24 @@ func (r *branchConstraintStaffGroupRepository) GetByBranchID(branchID uuid.UUID)
 	return staffGroups, rows.Err()
 }
 
-func (r *branchConstraintStaffGroupRepository) Delete(id uuid.UUID) error {
+func (r *branchConstraintStaffGroupRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM branch_constraint_staff_groups WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *branchConstraintStaffGroupRepository) DeleteByConstraintID(constraintID uuid.UUID) error {
+func (r *branchConstraintStaffGroupRepository) DeleteByConstraintID(ctx context.Context, constraintID uuid.UUID) error {
 	query := `DELETE FROM branch_constraint_staff_groups WHERE branch_constraint_id = $1`
-	_, err := r.db.Exec(query, constraintID)
+	_, err := r.db.ExecContext(ctx, query, constraintID)
 	return err
 }
 
-func (r *branchConstraintStaffGroupRepository) BulkUpsert(staffGroups []*models.BranchConstraintStaffGroup) error {
+func (r *branchConstraintStaffGroupRepository) BulkUpsert(ctx context.Context, staffGroups []*models.BranchConstraintStaffGroup) error {
 	if len(staffGroups) == 0 {
 		return nil
 	}
 
-	tx, err := r.db.Begin()
+	tx, err := beginTx(ctx, r.db)
 	if err != nil {
 		return err
 	}
@@ -108,7 +108,7 @@ func (r *branchConstraintStaffGroupRepository) BulkUpsert(staffGroups []*models.
 		if sg.ID == uuid.Nil {
 			sg.ID = uuid.New()
 		}
-		err := tx.QueryRow(query, sg.ID, sg.BranchConstraintID, sg.StaffGroupID, sg.MinimumCount).
+		err := tx.QueryRowContext(ctx, query, sg.ID, sg.BranchConstraintID, sg.StaffGroupID, sg.MinimumCount).
 			Scan(&sg.CreatedAt, &sg.UpdatedAt)
 		if err != nil {
 			return err
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 
 	"vsq-oper-manpower/backend/internal/domain/interfaces"
@@ -11,39 +12,39 @@ import (
 )
 
 type branchConstraintsRepository struct {
-	db             *sql.DB
+	db             DBTX
 	staffGroupRepo interfaces.BranchConstraintStaffGroupRepository
 }
 
-func NewBranchConstraintsRepository(db *sql.DB) interfaces.BranchConstraintsRepository {
+func NewBranchConstraintsRepository(db DBTX) interfaces.BranchConstraintsRepository {
 	return &branchConstraintsRepository{
 		db:             db,
 		staffGroupRepo: NewBranchConstraintStaffGroupRepository(db),
 	}
 }
 
-func (r *branchConstraintsRepository) Create(constraint *models.BranchConstraints) error {
+func (r *branchConstraintsRepository) Create(ctx context.Context, constraint *models.BranchConstraints) error {
 	query := `INSERT INTO branch_constraints (id, branch_id, day_of_week, min_front_staff, min_managers, min_doctor_assistant, min_total_staff, inherited_from_branch_type_id, is_overridden) 
 	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING created_at, updated_at`
-	return r.db.QueryRow(query, constraint.ID, constraint.BranchID, constraint.DayOfWeek,
+	return r.db.QueryRowContext(ctx, query, constraint.ID, constraint.BranchID, constraint.DayOfWeek,
 		constraint.MinFrontStaff, constraint.MinManagers, constraint.MinDoctorAssistant, constraint.MinTotalStaff,
 		constraint.InheritedFromBranchTypeID, constraint.IsOverridden).
 		Scan(&constraint.CreatedAt, &constraint.UpdatedAt)
 }
 
-func (r *branchConstraintsRepository) Update(constraint *models.BranchConstraints) error {
+func (r *branchConstraintsRepository) Update(ctx context.Context, constraint *models.BranchConstraints) error {
 	query := `UPDATE branch_constraints SET min_front_staff = $1, min_managers = $2, min_doctor_assistant = $3, min_total_staff = $4, inherited_from_branch_type_id = $5, is_overridden = $6, updated_at = CURRENT_TIMESTAMP 
 	          WHERE id = $7 RETURNING updated_at`
-	return r.db.QueryRow(query, constraint.MinFrontStaff, constraint.MinManagers, constraint.MinDoctorAssistant, constraint.MinTotalStaff,
+	return r.db.QueryRowContext(ctx, query, constraint.MinFrontStaff, constraint.MinManagers, constraint.MinDoctorAssistant, constraint.MinTotalStaff,
 		constraint.InheritedFromBranchTypeID, constraint.IsOverridden, constraint.ID).
 		Scan(&constraint.UpdatedAt)
 }
 
-func (r *branchConstraintsRepository) GetByID(id uuid.UUID) (*models.BranchConstraints, error) {
+func (r *branchConstraintsRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BranchConstraints, error) {
 	constraint := &models.BranchConstraints{}
 	query := `SELECT id, branch_id, day_of_week, min_front_staff, min_managers, min_doctor_assistant, min_total_staff, inherited_from_branch_type_id, is_overridden, created_at, updated_at 
 	          FROM branch_constraints WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&constraint.ID, &constraint.BranchID, &constraint.DayOfWeek,
 		&constraint.MinFrontStaff, &constraint.MinManagers, &constraint.MinDoctorAssistant, &constraint.MinTotalStaff,
 		&constraint.InheritedFromBranchTypeID, &constraint.IsOverridden,
@@ -55,10 +56,10 @@ func (r *branchConstraintsRepository) GetByID(id uuid.UUID) (*models.BranchConst
 	return constraint, err
 }
 
-func (r *branchConstraintsRepository) GetByBranchID(branchID uuid.UUID) ([]*models.BranchConstraints, error) {
+func (r *branchConstraintsRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID) ([]*models.BranchConstraints, error) {
 	query := `SELECT id, branch_id, day_of_week, min_front_staff, min_managers, min_doctor_assistant, min_total_staff, inherited_from_branch_type_id, is_overridden, created_at, updated_at 
 	          FROM branch_constraints WHERE branch_id = $1 ORDER BY day_of_week`
-	rows, err := r.db.Query(query, branchID)
+	rows, err := r.db.QueryContext(ctx, query, branchID)
 	if err != nil {
 		return nil, err
 	}
@@ -80,11 +81,40 @@ func (r *branchConstraintsRepository) GetByBranchID(branchID uuid.UUID) ([]*mode
 	return constraints, rows.Err()
 }
 
-func (r *branchConstraintsRepository) GetByBranchIDAndDayOfWeek(branchID uuid.UUID, dayOfWeek int) (*models.BranchConstraints, error) {
+func (r *branchConstraintsRepository) GetByBranchIDs(ctx context.Context, branchIDs []uuid.UUID) ([]*models.BranchConstraints, error) {
+	if len(branchIDs) == 0 {
+		return []*models.BranchConstraints{}, nil
+	}
+
+	query := `SELECT id, branch_id, day_of_week, min_front_staff, min_managers, min_doctor_assistant, min_total_staff, inherited_from_branch_type_id, is_overridden, created_at, updated_at 
+	          FROM branch_constraints WHERE branch_id = ANY($1) ORDER BY branch_id, day_of_week`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(branchIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var constraints []*models.BranchConstraints
+	for rows.Next() {
+		constraint := &models.BranchConstraints{}
+		if err := rows.Scan(
+			&constraint.ID, &constraint.BranchID, &constraint.DayOfWeek,
+			&constraint.MinFrontStaff, &constraint.MinManagers, &constraint.MinDoctorAssistant, &constraint.MinTotalStaff,
+			&constraint.InheritedFromBranchTypeID, &constraint.IsOverridden,
+			&constraint.CreatedAt, &constraint.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		constraints = append(constraints, constraint)
+	}
+	return constraints, rows.Err()
+}
+
+func (r *branchConstraintsRepository) GetByBranchIDAndDayOfWeek(ctx context.Context, branchID uuid.UUID, dayOfWeek int) (*models.BranchConstraints, error) {
 	constraint := &models.BranchConstraints{}
 	query := `SELECT id, branch_id, day_of_week, min_front_staff, min_managers, min_doctor_assistant, min_total_staff, inherited_from_branch_type_id, is_overridden, created_at, updated_at 
 	          FROM branch_constraints WHERE branch_id = $1 AND day_of_week = $2`
-	err := r.db.QueryRow(query, branchID, dayOfWeek).Scan(
+	err := r.db.QueryRowContext(ctx, query, branchID, dayOfWeek).Scan(
 		&constraint.ID, &constraint.BranchID, &constraint.DayOfWeek,
 		&constraint.MinFrontStaff, &constraint.MinManagers, &constraint.MinDoctorAssistant, &constraint.MinTotalStaff,
 		&constraint.InheritedFromBranchTypeID, &constraint.IsOverridden,
@@ -96,14 +126,14 @@ func (r *branchConstraintsRepository) GetByBranchIDAndDayOfWeek(branchID uuid.UU
 	return constraint, err
 }
 
-func (r *branchConstraintsRepository) Delete(id uuid.UUID) error {
+func (r *branchConstraintsRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM branch_constraints WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *branchConstraintsRepository) BulkUpsert(constraints []*models.BranchConstraints) error {
-	tx, err := r.db.Begin()
+func (r *branchConstraintsRepository) BulkUpsert(ctx context.Context, constraints []*models.BranchConstraints) error {
+	tx, err := beginTx(ctx, r.db)
 	if err != nil {
 		return err
 	}
@@ -119,7 +149,7 @@ func (r *branchConstraintsRepository) BulkUpsert(constraints []*models.BranchCon
 		if constraint.ID == uuid.Nil {
 			constraint.ID = uuid.New()
 		}
-		err := tx.QueryRow(query, constraint.ID, constraint.BranchID, constraint.DayOfWeek,
+		err := tx.QueryRowContext(ctx, query, constraint.ID, constraint.BranchID, constraint.DayOfWeek,
 			constraint.MinFrontStaff, constraint.MinManagers, constraint.MinDoctorAssistant, constraint.MinTotalStaff,
 			constraint.InheritedFromBranchTypeID, constraint.IsOverridden).
 			Scan(&constraint.CreatedAt, &constraint.UpdatedAt)
@@ -131,7 +161,7 @@ func (r *branchConstraintsRepository) BulkUpsert(constraints []*models.BranchCon
 	return tx.Commit()
 }
 
-func (r *branchConstraintsRepository) LoadStaffGroupRequirements(constraints []*models.BranchConstraints) error {
+func (r *branchConstraintsRepository) LoadStaffGroupRequirements(ctx context.Context, constraints []*models.BranchConstraints) error {
 	if len(constraints) == 0 {
 		return nil
 	}
@@ -155,7 +185,7 @@ func (r *branchConstraintsRepository) LoadStaffGroupRequirements(constraints []*
 	          FROM branch_constraint_staff_groups 
 	          WHERE branch_constraint_id = ANY($1) 
 	          ORDER BY branch_constraint_id, staff_group_id`
-	rows, err := r.db.Query(query, pq.Array(constraintIDs))
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(constraintIDs))
 	if err != nil {
 		return err
 	}
@@ -181,8 +211,8 @@ func (r *branchConstraintsRepository) LoadStaffGroupRequirements(constraints []*
 	return rows.Err()
 }
 
-func (r *branchConstraintsRepository) BulkUpsertWithStaffGroups(constraints []*models.BranchConstraints) error {
-	tx, err := r.db.Begin()
+func (r *branchConstraintsRepository) BulkUpsertWithStaffGroups(ctx context.Context, constraints []*models.BranchConstraints) error {
+	tx, err := beginTx(ctx, r.db)
 	if err != nil {
 		return err
 	}
@@ -199,7 +229,7 @@ func (r *branchConstraintsRepository) BulkUpsertWithStaffGroups(constraints []*m
 		if constraint.ID == uuid.Nil {
 			constraint.ID = uuid.New()
 		}
-		err := tx.QueryRow(constraintQuery, constraint.ID, constraint.BranchID, constraint.DayOfWeek,
+		err := tx.QueryRowContext(ctx, constraintQuery, constraint.ID, constraint.BranchID, constraint.DayOfWeek,
 			0, 0, 0, 0, // Deprecated fields set to 0
 			constraint.InheritedFromBranchTypeID, constraint.IsOverridden).
 			Scan(&constraint.ID, &constraint.CreatedAt, &constraint.UpdatedAt)
@@ -209,7 +239,7 @@ func (r *branchConstraintsRepository) BulkUpsertWithStaffGroups(constraints []*m
 
 		// Delete existing staff group requirements for this constraint
 		deleteQuery := `DELETE FROM branch_constraint_staff_groups WHERE branch_constraint_id = $1`
-		if _, err := tx.Exec(deleteQuery, constraint.ID); err != nil {
+		if _, err := tx.ExecContext(ctx, deleteQuery, constraint.ID); err != nil {
 			return err
 		}
 
@@ -223,7 +253,7 @@ func (r *branchConstraintsRepository) BulkUpsertWithStaffGroups(constraints []*m
 					sg.ID = uuid.New()
 				}
 				sg.BranchConstraintID = constraint.ID
-				err := tx.QueryRow(staffGroupQuery, sg.ID, sg.BranchConstraintID, sg.StaffGroupID, sg.MinimumCount).
+				err := tx.QueryRowContext(ctx, staffGroupQuery, sg.ID, sg.BranchConstraintID, sg.StaffGroupID, sg.MinimumCount).
 					Scan(&sg.CreatedAt, &sg.UpdatedAt)
 				if err != nil {
 					return err
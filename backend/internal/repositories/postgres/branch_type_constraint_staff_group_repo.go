@@ -1,7 +1,7 @@
 package postgres
 
 import (
-	"database/sql"
+	"context"
 
 	"vsq-oper-manpower/backend/internal/domain/interfaces"
 	"vsq-oper-manpower/backend/internal/domain/models"
@@ -10,26 +10,26 @@ import (
 )
 
 type branchTypeConstraintStaffGroupRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewBranchTypeConstraintStaffGroupRepository(db *sql.DB) interfaces.BranchTypeConstraintStaffGroupRepository {
+func NewBranchTypeConstraintStaffGroupRepository(db DBTX) interfaces.BranchTypeConstraintStaffGroupRepository {
 	return &branchTypeConstraintStaffGroupRepository{db: db}
 }
 
-func (r *branchTypeConstraintStaffGroupRepository) Create(sg *models.BranchTypeConstraintStaffGroup) error {
+func (r *branchTypeConstraintStaffGroupRepository) Create(ctx context.Context, sg *models.BranchTypeConstraintStaffGroup) error {
 	query := `INSERT INTO branch_type_constraint_staff_groups (id, branch_type_constraint_id, staff_group_id, minimum_count) 
 	          VALUES ($1, $2, $3, $4) RETURNING created_at, updated_at`
-	return r.db.QueryRow(query, sg.ID, sg.BranchTypeConstraintID, sg.StaffGroupID, sg.MinimumCount).
+	return r.db.QueryRowContext(ctx, query, sg.ID, sg.BranchTypeConstraintID, sg.StaffGroupID, sg.MinimumCount).
 		Scan(&sg.CreatedAt, &sg.UpdatedAt)
 }
 
-func (r *branchTypeConstraintStaffGroupRepository) GetByConstraintID(constraintID uuid.UUID) ([]*models.BranchTypeConstraintStaffGroup, error) {
+func (r *branchTypeConstraintStaffGroupRepository) GetByConstraintID(ctx context.Context, constraintID uuid.UUID) ([]*models.BranchTypeConstraintStaffGroup, error) {
 	query := `SELECT id, branch_type_constraint_id, staff_group_id, minimum_count, created_at, updated_at 
 	          FROM branch_type_constraint_staff_groups 
 	          WHERE branch_type_constraint_id = $1 
 	          ORDER BY staff_group_id`
-	rows, err := r.db.Query(query, constraintID)
+	rows, err := r.db.QueryContext(ctx, query, constraintID)
 	if err != nil {
 		return nil, err
 	}
@@ -49,13 +49,13 @@ func (r *branchTypeConstraintStaffGroupRepository) GetByConstraintID(constraintI
 	return staffGroups, rows.Err()
 }
 
-func (r *branchTypeConstraintStaffGroupRepository) GetByBranchTypeID(branchTypeID uuid.UUID) ([]*models.BranchTypeConstraintStaffGroup, error) {
+func (r *branchTypeConstraintStaffGroupRepository) GetByBranchTypeID(ctx context.Context, branchTypeID uuid.UUID) ([]*models.BranchTypeConstraintStaffGroup, error) {
 	query := `SELECT sg.id, sg.branch_type_constraint_id, sg.staff_group_id, sg.minimum_count, sg.created_at, sg.updated_at 
 	          FROM branch_type_constraint_staff_groups sg
 	          INNER JOIN branch_type_constraints c ON sg.branch_type_constraint_id = c.id
 	          WHERE c.branch_type_id = $1 
 	          ORDER BY c.day_of_week, sg.staff_group_id`
-	rows, err := r.db.Query(query, branchTypeID)
+	rows, err := r.db.QueryContext(ctx, query, branchTypeID)
 	if err != nil {
 		return nil, err
 	}
@@ -75,24 +75,24 @@ func (r *branchTypeConstraintStaffGroupRepository) GetByBranchTypeID(branchTypeI
 	return staffGroups, rows.Err()
 }
 
-func (r *branchTypeConstraintStaffGroupRepository) Delete(id uuid.UUID) error {
+func (r *branchTypeConstraintStaffGroupRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM branch_type_constraint_staff_groups WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *branchTypeConstraintStaffGroupRepository) DeleteByConstraintID(constraintID uuid.UUID) error {
+func (r *branchTypeConstraintStaffGroupRepository) DeleteByConstraintID(ctx context.Context, constraintID uuid.UUID) error {
 	query := `DELETE FROM branch_type_constraint_staff_groups WHERE branch_type_constraint_id = $1`
-	_, err := r.db.Exec(query, constraintID)
+	_, err := r.db.ExecContext(ctx, query, constraintID)
 	return err
 }
 
-func (r *branchTypeConstraintStaffGroupRepository) BulkUpsert(staffGroups []*models.BranchTypeConstraintStaffGroup) error {
+func (r *branchTypeConstraintStaffGroupRepository) BulkUpsert(ctx context.Context, staffGroups []*models.BranchTypeConstraintStaffGroup) error {
 	if len(staffGroups) == 0 {
 		return nil
 	}
 
-	tx, err := r.db.Begin()
+	tx, err := beginTx(ctx, r.db)
 	if err != nil {
 		return err
 	}
@@ -108,7 +108,7 @@ func (r *branchTypeConstraintStaffGroupRepository) BulkUpsert(staffGroups []*mod
 		if sg.ID == uuid.Nil {
 			sg.ID = uuid.New()
 		}
-		err := tx.QueryRow(query, sg.ID, sg.BranchTypeConstraintID, sg.StaffGroupID, sg.MinimumCount).
+		err := tx.QueryRowContext(ctx, query, sg.ID, sg.BranchTypeConstraintID, sg.StaffGroupID, sg.MinimumCount).
 			Scan(&sg.CreatedAt, &sg.UpdatedAt)
 		if err != nil {
 			return err
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 
 	"vsq-oper-manpower/backend/internal/domain/interfaces"
@@ -11,22 +12,22 @@ import (
 )
 
 type branchTypeConstraintsRepository struct {
-	db             *sql.DB
+	db             DBTX
 	staffGroupRepo interfaces.BranchTypeConstraintStaffGroupRepository
 }
 
-func NewBranchTypeConstraintsRepository(db *sql.DB) interfaces.BranchTypeConstraintsRepository {
+func NewBranchTypeConstraintsRepository(db DBTX) interfaces.BranchTypeConstraintsRepository {
 	return &branchTypeConstraintsRepository{
 		db:             db,
 		staffGroupRepo: NewBranchTypeConstraintStaffGroupRepository(db),
 	}
 }
 
-func (r *branchTypeConstraintsRepository) GetByID(id uuid.UUID) (*models.BranchTypeConstraints, error) {
+func (r *branchTypeConstraintsRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BranchTypeConstraints, error) {
 	constraint := &models.BranchTypeConstraints{}
 	query := `SELECT id, branch_type_id, day_of_week, created_at, updated_at 
 	          FROM branch_type_constraints WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&constraint.ID, &constraint.BranchTypeID, &constraint.DayOfWeek,
 		&constraint.CreatedAt, &constraint.UpdatedAt,
 	)
@@ -36,10 +37,10 @@ func (r *branchTypeConstraintsRepository) GetByID(id uuid.UUID) (*models.BranchT
 	return constraint, err
 }
 
-func (r *branchTypeConstraintsRepository) GetByBranchTypeID(branchTypeID uuid.UUID) ([]*models.BranchTypeConstraints, error) {
+func (r *branchTypeConstraintsRepository) GetByBranchTypeID(ctx context.Context, branchTypeID uuid.UUID) ([]*models.BranchTypeConstraints, error) {
 	query := `SELECT id, branch_type_id, day_of_week, created_at, updated_at 
 	          FROM branch_type_constraints WHERE branch_type_id = $1 ORDER BY day_of_week`
-	rows, err := r.db.Query(query, branchTypeID)
+	rows, err := r.db.QueryContext(ctx, query, branchTypeID)
 	if err != nil {
 		return nil, err
 	}
@@ -59,11 +60,34 @@ func (r *branchTypeConstraintsRepository) GetByBranchTypeID(branchTypeID uuid.UU
 	return constraints, rows.Err()
 }
 
-func (r *branchTypeConstraintsRepository) GetByBranchTypeIDAndDayOfWeek(branchTypeID uuid.UUID, dayOfWeek int) (*models.BranchTypeConstraints, error) {
+func (r *branchTypeConstraintsRepository) List(ctx context.Context) ([]*models.BranchTypeConstraints, error) {
+	query := `SELECT id, branch_type_id, day_of_week, created_at, updated_at 
+	          FROM branch_type_constraints ORDER BY branch_type_id, day_of_week`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var constraints []*models.BranchTypeConstraints
+	for rows.Next() {
+		constraint := &models.BranchTypeConstraints{}
+		if err := rows.Scan(
+			&constraint.ID, &constraint.BranchTypeID, &constraint.DayOfWeek,
+			&constraint.CreatedAt, &constraint.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		constraints = append(constraints, constraint)
+	}
+	return constraints, rows.Err()
+}
+
+func (r *branchTypeConstraintsRepository) GetByBranchTypeIDAndDayOfWeek(ctx context.Context, branchTypeID uuid.UUID, dayOfWeek int) (*models.BranchTypeConstraints, error) {
 	constraint := &models.BranchTypeConstraints{}
 	query := `SELECT id, branch_type_id, day_of_week, created_at, updated_at 
 	          FROM branch_type_constraints WHERE branch_type_id = $1 AND day_of_week = $2`
-	err := r.db.QueryRow(query, branchTypeID, dayOfWeek).Scan(
+	err := r.db.QueryRowContext(ctx, query, branchTypeID, dayOfWeek).Scan(
 		&constraint.ID, &constraint.BranchTypeID, &constraint.DayOfWeek,
 		&constraint.CreatedAt, &constraint.UpdatedAt,
 	)
@@ -73,13 +97,13 @@ func (r *branchTypeConstraintsRepository) GetByBranchTypeIDAndDayOfWeek(branchTy
 	return constraint, err
 }
 
-func (r *branchTypeConstraintsRepository) Delete(id uuid.UUID) error {
+func (r *branchTypeConstraintsRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM branch_type_constraints WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *branchTypeConstraintsRepository) LoadStaffGroupRequirements(constraints []*models.BranchTypeConstraints) error {
+func (r *branchTypeConstraintsRepository) LoadStaffGroupRequirements(ctx context.Context, constraints []*models.BranchTypeConstraints) error {
 	if len(constraints) == 0 {
 		return nil
 	}
@@ -103,7 +127,7 @@ func (r *branchTypeConstraintsRepository) LoadStaffGroupRequirements(constraints
 	          FROM branch_type_constraint_staff_groups 
 	          WHERE branch_type_constraint_id = ANY($1) 
 	          ORDER BY branch_type_constraint_id, staff_group_id`
-	rows, err := r.db.Query(query, pq.Array(constraintIDs))
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(constraintIDs))
 	if err != nil {
 		return err
 	}
@@ -129,8 +153,8 @@ func (r *branchTypeConstraintsRepository) LoadStaffGroupRequirements(constraints
 	return rows.Err()
 }
 
-func (r *branchTypeConstraintsRepository) BulkUpsertWithStaffGroups(constraints []*models.BranchTypeConstraints) error {
-	tx, err := r.db.Begin()
+func (r *branchTypeConstraintsRepository) BulkUpsertWithStaffGroups(ctx context.Context, constraints []*models.BranchTypeConstraints) error {
+	tx, err := beginTx(ctx, r.db)
 	if err != nil {
 		return err
 	}
@@ -147,7 +171,7 @@ func (r *branchTypeConstraintsRepository) BulkUpsertWithStaffGroups(constraints
 		if constraint.ID == uuid.Nil {
 			constraint.ID = uuid.New()
 		}
-		err := tx.QueryRow(constraintQuery, constraint.ID, constraint.BranchTypeID, constraint.DayOfWeek).
+		err := tx.QueryRowContext(ctx, constraintQuery, constraint.ID, constraint.BranchTypeID, constraint.DayOfWeek).
 			Scan(&constraint.ID, &constraint.CreatedAt, &constraint.UpdatedAt)
 		if err != nil {
 			return err
@@ -155,7 +179,7 @@ func (r *branchTypeConstraintsRepository) BulkUpsertWithStaffGroups(constraints
 
 		// Delete existing staff group requirements for this constraint
 		deleteQuery := `DELETE FROM branch_type_constraint_staff_groups WHERE branch_type_constraint_id = $1`
-		if _, err := tx.Exec(deleteQuery, constraint.ID); err != nil {
+		if _, err := tx.ExecContext(ctx, deleteQuery, constraint.ID); err != nil {
 			return err
 		}
 
@@ -169,7 +193,7 @@ func (r *branchTypeConstraintsRepository) BulkUpsertWithStaffGroups(constraints
 					sg.ID = uuid.New()
 				}
 				sg.BranchTypeConstraintID = constraint.ID
-				err := tx.QueryRow(staffGroupQuery, sg.ID, sg.BranchTypeConstraintID, sg.StaffGroupID, sg.MinimumCount).
+				err := tx.QueryRowContext(ctx, staffGroupQuery, sg.ID, sg.BranchTypeConstraintID, sg.StaffGroupID, sg.MinimumCount).
 					Scan(&sg.CreatedAt, &sg.UpdatedAt)
 				if err != nil {
 					return err
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
@@ -11,14 +12,14 @@ import (
 )
 
 type branchTypeRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewBranchTypeRepository(db *sql.DB) interfaces.BranchTypeRepository {
+func NewBranchTypeRepository(db DBTX) interfaces.BranchTypeRepository {
 	return &branchTypeRepository{db: db}
 }
 
-func (r *branchTypeRepository) Create(branchType *models.BranchType) error {
+func (r *branchTypeRepository) Create(ctx context.Context, branchType *models.BranchType) error {
 	branchType.ID = uuid.New()
 	branchType.CreatedAt = time.Now()
 	branchType.UpdatedAt = time.Now()
@@ -31,18 +32,18 @@ func (r *branchTypeRepository) Create(branchType *models.BranchType) error {
 		description = sql.NullString{String: branchType.Description, Valid: true}
 	}
 
-	return r.db.QueryRow(query, branchType.ID, branchType.Name, description, branchType.IsActive, branchType.CreatedAt, branchType.UpdatedAt).
+	return r.db.QueryRowContext(ctx, query, branchType.ID, branchType.Name, description, branchType.IsActive, branchType.CreatedAt, branchType.UpdatedAt).
 		Scan(&branchType.CreatedAt, &branchType.UpdatedAt)
 }
 
-func (r *branchTypeRepository) GetByID(id uuid.UUID) (*models.BranchType, error) {
+func (r *branchTypeRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BranchType, error) {
 	branchType := &models.BranchType{}
 	query := `SELECT id, name, description, is_active, created_at, updated_at
 	          FROM branch_types WHERE id = $1`
 
 	var description sql.NullString
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&branchType.ID, &branchType.Name, &description, &branchType.IsActive, &branchType.CreatedAt, &branchType.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
@@ -59,11 +60,11 @@ func (r *branchTypeRepository) GetByID(id uuid.UUID) (*models.BranchType, error)
 	return branchType, nil
 }
 
-func (r *branchTypeRepository) List() ([]*models.BranchType, error) {
+func (r *branchTypeRepository) List(ctx context.Context) ([]*models.BranchType, error) {
 	query := `SELECT id, name, description, is_active, created_at, updated_at
 	          FROM branch_types ORDER BY name`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -90,7 +91,7 @@ func (r *branchTypeRepository) List() ([]*models.BranchType, error) {
 	return branchTypes, rows.Err()
 }
 
-func (r *branchTypeRepository) Update(branchType *models.BranchType) error {
+func (r *branchTypeRepository) Update(ctx context.Context, branchType *models.BranchType) error {
 	branchType.UpdatedAt = time.Now()
 
 	query := `UPDATE branch_types SET name = $1, description = $2, is_active = $3, updated_at = $4 WHERE id = $5`
@@ -100,12 +101,12 @@ func (r *branchTypeRepository) Update(branchType *models.BranchType) error {
 		description = sql.NullString{String: branchType.Description, Valid: true}
 	}
 
-	_, err := r.db.Exec(query, branchType.Name, description, branchType.IsActive, branchType.UpdatedAt, branchType.ID)
+	_, err := r.db.ExecContext(ctx, query, branchType.Name, description, branchType.IsActive, branchType.UpdatedAt, branchType.ID)
 	return err
 }
 
-func (r *branchTypeRepository) Delete(id uuid.UUID) error {
+func (r *branchTypeRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM branch_types WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
@@ -11,14 +12,14 @@ import (
 )
 
 type branchTypeStaffGroupRequirementRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewBranchTypeStaffGroupRequirementRepository(db *sql.DB) interfaces.BranchTypeStaffGroupRequirementRepository {
+func NewBranchTypeStaffGroupRequirementRepository(db DBTX) interfaces.BranchTypeStaffGroupRequirementRepository {
 	return &branchTypeStaffGroupRequirementRepository{db: db}
 }
 
-func (r *branchTypeStaffGroupRequirementRepository) Create(requirement *models.BranchTypeStaffGroupRequirement) error {
+func (r *branchTypeStaffGroupRequirementRepository) Create(ctx context.Context, requirement *models.BranchTypeStaffGroupRequirement) error {
 	requirement.ID = uuid.New()
 	requirement.CreatedAt = time.Now()
 	requirement.UpdatedAt = time.Now()
@@ -27,18 +28,18 @@ func (r *branchTypeStaffGroupRequirementRepository) Create(requirement *models.B
 	          (id, branch_type_id, staff_group_id, day_of_week, minimum_staff_count, is_active, created_at, updated_at)
 	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING created_at, updated_at`
 
-	return r.db.QueryRow(query,
+	return r.db.QueryRowContext(ctx, query,
 		requirement.ID, requirement.BranchTypeID, requirement.StaffGroupID, requirement.DayOfWeek,
 		requirement.MinimumStaffCount, requirement.IsActive, requirement.CreatedAt, requirement.UpdatedAt,
 	).Scan(&requirement.CreatedAt, &requirement.UpdatedAt)
 }
 
-func (r *branchTypeStaffGroupRequirementRepository) GetByID(id uuid.UUID) (*models.BranchTypeStaffGroupRequirement, error) {
+func (r *branchTypeStaffGroupRequirementRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BranchTypeStaffGroupRequirement, error) {
 	requirement := &models.BranchTypeStaffGroupRequirement{}
 	query := `SELECT id, branch_type_id, staff_group_id, day_of_week, minimum_staff_count, is_active, created_at, updated_at
 	          FROM branch_type_staff_group_requirements WHERE id = $1`
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&requirement.ID, &requirement.BranchTypeID, &requirement.StaffGroupID, &requirement.DayOfWeek,
 		&requirement.MinimumStaffCount, &requirement.IsActive, &requirement.CreatedAt, &requirement.UpdatedAt,
 	)
@@ -52,11 +53,11 @@ func (r *branchTypeStaffGroupRequirementRepository) GetByID(id uuid.UUID) (*mode
 	return requirement, nil
 }
 
-func (r *branchTypeStaffGroupRequirementRepository) GetByBranchTypeID(branchTypeID uuid.UUID) ([]*models.BranchTypeStaffGroupRequirement, error) {
+func (r *branchTypeStaffGroupRequirementRepository) GetByBranchTypeID(ctx context.Context, branchTypeID uuid.UUID) ([]*models.BranchTypeStaffGroupRequirement, error) {
 	query := `SELECT id, branch_type_id, staff_group_id, day_of_week, minimum_staff_count, is_active, created_at, updated_at
 	          FROM branch_type_staff_group_requirements WHERE branch_type_id = $1 ORDER BY day_of_week, staff_group_id`
 
-	rows, err := r.db.Query(query, branchTypeID)
+	rows, err := r.db.QueryContext(ctx, query, branchTypeID)
 	if err != nil {
 		return nil, err
 	}
@@ -77,11 +78,36 @@ func (r *branchTypeStaffGroupRequirementRepository) GetByBranchTypeID(branchType
 	return requirements, rows.Err()
 }
 
-func (r *branchTypeStaffGroupRequirementRepository) GetByStaffGroupID(staffGroupID uuid.UUID) ([]*models.BranchTypeStaffGroupRequirement, error) {
+func (r *branchTypeStaffGroupRequirementRepository) List(ctx context.Context) ([]*models.BranchTypeStaffGroupRequirement, error) {
+	query := `SELECT id, branch_type_id, staff_group_id, day_of_week, minimum_staff_count, is_active, created_at, updated_at
+	          FROM branch_type_staff_group_requirements ORDER BY branch_type_id, day_of_week, staff_group_id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requirements := []*models.BranchTypeStaffGroupRequirement{}
+	for rows.Next() {
+		requirement := &models.BranchTypeStaffGroupRequirement{}
+		if err := rows.Scan(
+			&requirement.ID, &requirement.BranchTypeID, &requirement.StaffGroupID, &requirement.DayOfWeek,
+			&requirement.MinimumStaffCount, &requirement.IsActive, &requirement.CreatedAt, &requirement.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		requirements = append(requirements, requirement)
+	}
+
+	return requirements, rows.Err()
+}
+
+func (r *branchTypeStaffGroupRequirementRepository) GetByStaffGroupID(ctx context.Context, staffGroupID uuid.UUID) ([]*models.BranchTypeStaffGroupRequirement, error) {
 	query := `SELECT id, branch_type_id, staff_group_id, day_of_week, minimum_staff_count, is_active, created_at, updated_at
 	          FROM branch_type_staff_group_requirements WHERE staff_group_id = $1 ORDER BY branch_type_id, day_of_week`
 
-	rows, err := r.db.Query(query, staffGroupID)
+	rows, err := r.db.QueryContext(ctx, query, staffGroupID)
 	if err != nil {
 		return nil, err
 	}
@@ -102,26 +128,26 @@ func (r *branchTypeStaffGroupRequirementRepository) GetByStaffGroupID(staffGroup
 	return requirements, rows.Err()
 }
 
-func (r *branchTypeStaffGroupRequirementRepository) Update(requirement *models.BranchTypeStaffGroupRequirement) error {
+func (r *branchTypeStaffGroupRequirementRepository) Update(ctx context.Context, requirement *models.BranchTypeStaffGroupRequirement) error {
 	requirement.UpdatedAt = time.Now()
 
 	query := `UPDATE branch_type_staff_group_requirements
 	          SET minimum_staff_count = $1, is_active = $2, updated_at = $3
 	          WHERE id = $4 RETURNING updated_at`
 
-	return r.db.QueryRow(query,
+	return r.db.QueryRowContext(ctx, query,
 		requirement.MinimumStaffCount, requirement.IsActive, requirement.UpdatedAt, requirement.ID,
 	).Scan(&requirement.UpdatedAt)
 }
 
-func (r *branchTypeStaffGroupRequirementRepository) Delete(id uuid.UUID) error {
+func (r *branchTypeStaffGroupRequirementRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM branch_type_staff_group_requirements WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *branchTypeStaffGroupRequirementRepository) BulkUpsert(requirements []*models.BranchTypeStaffGroupRequirement) error {
-	tx, err := r.db.Begin()
+func (r *branchTypeStaffGroupRequirementRepository) BulkUpsert(ctx context.Context, requirements []*models.BranchTypeStaffGroupRequirement) error {
+	tx, err := beginTx(ctx, r.db)
 	if err != nil {
 		return err
 	}
@@ -137,7 +163,7 @@ func (r *branchTypeStaffGroupRequirementRepository) BulkUpsert(requirements []*m
 		if requirement.ID == uuid.Nil {
 			requirement.ID = uuid.New()
 		}
-		err := tx.QueryRow(query, requirement.ID, requirement.BranchTypeID, requirement.StaffGroupID, requirement.DayOfWeek,
+		err := tx.QueryRowContext(ctx, query, requirement.ID, requirement.BranchTypeID, requirement.StaffGroupID, requirement.DayOfWeek,
 			requirement.MinimumStaffCount, requirement.IsActive).
 			Scan(&requirement.CreatedAt, &requirement.UpdatedAt)
 		if err != nil {
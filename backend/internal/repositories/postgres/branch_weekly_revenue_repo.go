@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/google/uuid"
@@ -10,35 +11,35 @@ import (
 
 // BranchWeeklyRevenueRepository implementation
 type branchWeeklyRevenueRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewBranchWeeklyRevenueRepository(db *sql.DB) interfaces.BranchWeeklyRevenueRepository {
+func NewBranchWeeklyRevenueRepository(db DBTX) interfaces.BranchWeeklyRevenueRepository {
 	return &branchWeeklyRevenueRepository{db: db}
 }
 
-func (r *branchWeeklyRevenueRepository) Create(revenue *models.BranchWeeklyRevenue) error {
+func (r *branchWeeklyRevenueRepository) Create(ctx context.Context, revenue *models.BranchWeeklyRevenue) error {
 	query := `INSERT INTO branch_weekly_revenue (id, branch_id, day_of_week, expected_revenue, skin_revenue, ls_hm_revenue, vitamin_cases, slim_pen_cases) 
 	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING created_at, updated_at`
-	return r.db.QueryRow(query, revenue.ID, revenue.BranchID, revenue.DayOfWeek, revenue.ExpectedRevenue,
+	return r.db.QueryRowContext(ctx, query, revenue.ID, revenue.BranchID, revenue.DayOfWeek, revenue.ExpectedRevenue,
 		revenue.SkinRevenue, revenue.LSHMRevenue, revenue.VitaminCases, revenue.SlimPenCases).
 		Scan(&revenue.CreatedAt, &revenue.UpdatedAt)
 }
 
-func (r *branchWeeklyRevenueRepository) Update(revenue *models.BranchWeeklyRevenue) error {
+func (r *branchWeeklyRevenueRepository) Update(ctx context.Context, revenue *models.BranchWeeklyRevenue) error {
 	query := `UPDATE branch_weekly_revenue 
 	          SET day_of_week = $2, expected_revenue = $3, skin_revenue = $4, ls_hm_revenue = $5, vitamin_cases = $6, slim_pen_cases = $7, updated_at = CURRENT_TIMESTAMP 
 	          WHERE id = $1 RETURNING updated_at`
-	return r.db.QueryRow(query, revenue.ID, revenue.DayOfWeek, revenue.ExpectedRevenue,
+	return r.db.QueryRowContext(ctx, query, revenue.ID, revenue.DayOfWeek, revenue.ExpectedRevenue,
 		revenue.SkinRevenue, revenue.LSHMRevenue, revenue.VitaminCases, revenue.SlimPenCases).
 		Scan(&revenue.UpdatedAt)
 }
 
-func (r *branchWeeklyRevenueRepository) GetByID(id uuid.UUID) (*models.BranchWeeklyRevenue, error) {
+func (r *branchWeeklyRevenueRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BranchWeeklyRevenue, error) {
 	revenue := &models.BranchWeeklyRevenue{}
 	query := `SELECT id, branch_id, day_of_week, expected_revenue, skin_revenue, ls_hm_revenue, vitamin_cases, slim_pen_cases, created_at, updated_at 
 	          FROM branch_weekly_revenue WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&revenue.ID, &revenue.BranchID, &revenue.DayOfWeek, &revenue.ExpectedRevenue,
 		&revenue.SkinRevenue, &revenue.LSHMRevenue, &revenue.VitaminCases, &revenue.SlimPenCases,
 		&revenue.CreatedAt, &revenue.UpdatedAt,
@@ -52,10 +53,10 @@ func (r *branchWeeklyRevenueRepository) GetByID(id uuid.UUID) (*models.BranchWee
 	return revenue, nil
 }
 
-func (r *branchWeeklyRevenueRepository) GetByBranchID(branchID uuid.UUID) ([]*models.BranchWeeklyRevenue, error) {
+func (r *branchWeeklyRevenueRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID) ([]*models.BranchWeeklyRevenue, error) {
 	query := `SELECT id, branch_id, day_of_week, expected_revenue, skin_revenue, ls_hm_revenue, vitamin_cases, slim_pen_cases, created_at, updated_at 
 	          FROM branch_weekly_revenue WHERE branch_id = $1 ORDER BY day_of_week`
-	rows, err := r.db.Query(query, branchID)
+	rows, err := r.db.QueryContext(ctx, query, branchID)
 	if err != nil {
 		return nil, err
 	}
@@ -76,11 +77,11 @@ func (r *branchWeeklyRevenueRepository) GetByBranchID(branchID uuid.UUID) ([]*mo
 	return revenues, rows.Err()
 }
 
-func (r *branchWeeklyRevenueRepository) GetByBranchIDAndDayOfWeek(branchID uuid.UUID, dayOfWeek int) (*models.BranchWeeklyRevenue, error) {
+func (r *branchWeeklyRevenueRepository) GetByBranchIDAndDayOfWeek(ctx context.Context, branchID uuid.UUID, dayOfWeek int) (*models.BranchWeeklyRevenue, error) {
 	revenue := &models.BranchWeeklyRevenue{}
 	query := `SELECT id, branch_id, day_of_week, expected_revenue, skin_revenue, ls_hm_revenue, vitamin_cases, slim_pen_cases, created_at, updated_at 
 	          FROM branch_weekly_revenue WHERE branch_id = $1 AND day_of_week = $2`
-	err := r.db.QueryRow(query, branchID, dayOfWeek).Scan(
+	err := r.db.QueryRowContext(ctx, query, branchID, dayOfWeek).Scan(
 		&revenue.ID, &revenue.BranchID, &revenue.DayOfWeek, &revenue.ExpectedRevenue,
 		&revenue.SkinRevenue, &revenue.LSHMRevenue, &revenue.VitaminCases, &revenue.SlimPenCases,
 		&revenue.CreatedAt, &revenue.UpdatedAt,
@@ -94,14 +95,14 @@ func (r *branchWeeklyRevenueRepository) GetByBranchIDAndDayOfWeek(branchID uuid.
 	return revenue, nil
 }
 
-func (r *branchWeeklyRevenueRepository) Delete(id uuid.UUID) error {
+func (r *branchWeeklyRevenueRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM branch_weekly_revenue WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *branchWeeklyRevenueRepository) BulkUpsert(revenues []*models.BranchWeeklyRevenue) error {
-	tx, err := r.db.Begin()
+func (r *branchWeeklyRevenueRepository) BulkUpsert(ctx context.Context, revenues []*models.BranchWeeklyRevenue) error {
+	tx, err := beginTx(ctx, r.db)
 	if err != nil {
 		return err
 	}
@@ -117,7 +118,7 @@ func (r *branchWeeklyRevenueRepository) BulkUpsert(revenues []*models.BranchWeek
 		if revenue.ID == uuid.Nil {
 			revenue.ID = uuid.New()
 		}
-		err := tx.QueryRow(query, revenue.ID, revenue.BranchID, revenue.DayOfWeek, revenue.ExpectedRevenue,
+		err := tx.QueryRowContext(ctx, query, revenue.ID, revenue.BranchID, revenue.DayOfWeek, revenue.ExpectedRevenue,
 			revenue.SkinRevenue, revenue.LSHMRevenue, revenue.VitaminCases, revenue.SlimPenCases).
 			Scan(&revenue.CreatedAt, &revenue.UpdatedAt)
 		if err != nil {
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -10,19 +11,20 @@ import (
 )
 
 type clinicWidePreferenceRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewClinicWidePreferenceRepository(db *sql.DB) interfaces.ClinicWidePreferenceRepository {
+func NewClinicWidePreferenceRepository(db DBTX) interfaces.ClinicWidePreferenceRepository {
 	return &clinicWidePreferenceRepository{db: db}
 }
 
-func (r *clinicWidePreferenceRepository) Create(preference *models.ClinicWidePreference) error {
+func (r *clinicWidePreferenceRepository) Create(ctx context.Context, preference *models.ClinicWidePreference) error {
 	query := `INSERT INTO clinic_wide_preferences 
 	          (id, criteria_type, criteria_name, min_value, max_value, is_active, display_order, description)
 	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	          RETURNING created_at, updated_at`
-	return r.db.QueryRow(
+	return r.db.QueryRowContext(
+		ctx,
 		query,
 		preference.ID, preference.CriteriaType, preference.CriteriaName,
 		preference.MinValue, preference.MaxValue, preference.IsActive,
@@ -30,12 +32,12 @@ func (r *clinicWidePreferenceRepository) Create(preference *models.ClinicWidePre
 	).Scan(&preference.CreatedAt, &preference.UpdatedAt)
 }
 
-func (r *clinicWidePreferenceRepository) GetByID(id uuid.UUID) (*models.ClinicWidePreference, error) {
+func (r *clinicWidePreferenceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ClinicWidePreference, error) {
 	preference := &models.ClinicWidePreference{}
 	query := `SELECT id, criteria_type, criteria_name, min_value, max_value, is_active, 
 	          display_order, description, created_at, updated_at
 	          FROM clinic_wide_preferences WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&preference.ID, &preference.CriteriaType, &preference.CriteriaName,
 		&preference.MinValue, &preference.MaxValue, &preference.IsActive,
 		&preference.DisplayOrder, &preference.Description,
@@ -50,12 +52,13 @@ func (r *clinicWidePreferenceRepository) GetByID(id uuid.UUID) (*models.ClinicWi
 	return preference, nil
 }
 
-func (r *clinicWidePreferenceRepository) Update(preference *models.ClinicWidePreference) error {
+func (r *clinicWidePreferenceRepository) Update(ctx context.Context, preference *models.ClinicWidePreference) error {
 	query := `UPDATE clinic_wide_preferences
 	          SET criteria_name = $1, min_value = $2, max_value = $3, is_active = $4, 
 	              display_order = $5, description = $6, updated_at = CURRENT_TIMESTAMP
 	          WHERE id = $7 RETURNING updated_at`
-	return r.db.QueryRow(
+	return r.db.QueryRowContext(
+		ctx,
 		query,
 		preference.CriteriaName, preference.MinValue, preference.MaxValue,
 		preference.IsActive, preference.DisplayOrder, preference.Description,
@@ -63,13 +66,13 @@ func (r *clinicWidePreferenceRepository) Update(preference *models.ClinicWidePre
 	).Scan(&preference.UpdatedAt)
 }
 
-func (r *clinicWidePreferenceRepository) Delete(id uuid.UUID) error {
+func (r *clinicWidePreferenceRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM clinic_wide_preferences WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *clinicWidePreferenceRepository) List(filters models.ClinicPreferenceFilters) ([]*models.ClinicWidePreference, error) {
+func (r *clinicWidePreferenceRepository) List(ctx context.Context, filters models.ClinicPreferenceFilters) ([]*models.ClinicWidePreference, error) {
 	query := `SELECT id, criteria_type, criteria_name, min_value, max_value, is_active, 
 	          display_order, description, created_at, updated_at
 	          FROM clinic_wide_preferences WHERE 1=1`
@@ -90,7 +93,7 @@ func (r *clinicWidePreferenceRepository) List(filters models.ClinicPreferenceFil
 
 	query += " ORDER BY criteria_type, display_order, min_value"
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -113,7 +116,7 @@ func (r *clinicWidePreferenceRepository) List(filters models.ClinicPreferenceFil
 	return preferences, rows.Err()
 }
 
-func (r *clinicWidePreferenceRepository) GetByCriteriaTypeAndValue(criteriaType models.ClinicPreferenceCriteriaType, value float64) ([]*models.ClinicWidePreference, error) {
+func (r *clinicWidePreferenceRepository) GetByCriteriaTypeAndValue(ctx context.Context, criteriaType models.ClinicPreferenceCriteriaType, value float64) ([]*models.ClinicWidePreference, error) {
 	query := `SELECT id, criteria_type, criteria_name, min_value, max_value, is_active, 
 	          display_order, description, created_at, updated_at
 	          FROM clinic_wide_preferences 
@@ -121,7 +124,7 @@ func (r *clinicWidePreferenceRepository) GetByCriteriaTypeAndValue(criteriaType
 	          AND min_value <= $2 AND (max_value IS NULL OR max_value >= $2)
 	          ORDER BY display_order, min_value DESC`
 	
-	rows, err := r.db.Query(query, criteriaType, value)
+	rows, err := r.db.QueryContext(ctx, query, criteriaType, value)
 	if err != nil {
 		return nil, err
 	}
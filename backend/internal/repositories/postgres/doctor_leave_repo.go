@@ -0,0 +1,191 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// DoctorLeaveRepository implementation
+type doctorLeaveRepository struct {
+	db DBTX
+}
+
+func NewDoctorLeaveRepository(db DBTX) interfaces.DoctorLeaveRepository {
+	return &doctorLeaveRepository{db: db}
+}
+
+const doctorLeaveColumns = `l.id, l.doctor_id, COALESCE(d.name, ''), l.start_date, l.end_date, l.reason, l.status,
+	          l.reviewed_by, l.reviewed_at, l.created_by, l.created_at, l.updated_at`
+
+func scanDoctorLeave(scanner interface{ Scan(...interface{}) error }) (*models.DoctorLeave, error) {
+	leave := &models.DoctorLeave{}
+	var reviewedBy, createdBy uuid.NullUUID
+	var reviewedAt sql.NullTime
+	if err := scanner.Scan(
+		&leave.ID, &leave.DoctorID, &leave.DoctorName, &leave.StartDate, &leave.EndDate, &leave.Reason, &leave.Status,
+		&reviewedBy, &reviewedAt, &createdBy, &leave.CreatedAt, &leave.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if reviewedBy.Valid {
+		leave.ReviewedBy = &reviewedBy.UUID
+	}
+	if reviewedAt.Valid {
+		leave.ReviewedAt = &reviewedAt.Time
+	}
+	leave.CreatedBy = createdBy.UUID
+	return leave, nil
+}
+
+func (r *doctorLeaveRepository) Create(ctx context.Context, leave *models.DoctorLeave) error {
+	if leave.ID == uuid.Nil {
+		leave.ID = uuid.New()
+	}
+	if leave.Status == "" {
+		leave.Status = models.DoctorLeavePending
+	}
+	now := time.Now()
+	query := `INSERT INTO doctor_leaves (id, doctor_id, start_date, end_date, reason, status, reviewed_by, reviewed_at,
+	          created_by, created_at, updated_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)
+	          RETURNING created_at, updated_at`
+	return r.db.QueryRowContext(ctx, query, leave.ID, leave.DoctorID, leave.StartDate, leave.EndDate, leave.Reason,
+		leave.Status, leave.ReviewedBy, leave.ReviewedAt, leave.CreatedBy, now).
+		Scan(&leave.CreatedAt, &leave.UpdatedAt)
+}
+
+func (r *doctorLeaveRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorLeave, error) {
+	query := `SELECT ` + doctorLeaveColumns + ` FROM doctor_leaves l
+	          LEFT JOIN doctors d ON l.doctor_id = d.id WHERE l.id = $1`
+	leave, err := scanDoctorLeave(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return leave, err
+}
+
+func (r *doctorLeaveRepository) List(ctx context.Context, filters interfaces.DoctorLeaveFilters) ([]*models.DoctorLeave, error) {
+	query := `SELECT ` + doctorLeaveColumns + ` FROM doctor_leaves l
+	          LEFT JOIN doctors d ON l.doctor_id = d.id
+	          WHERE 1=1`
+	var args []interface{}
+	if filters.DoctorID != nil {
+		args = append(args, *filters.DoctorID)
+		query += fmt.Sprintf(" AND l.doctor_id = $%d", len(args))
+	}
+	if filters.Status != "" {
+		args = append(args, filters.Status)
+		query += fmt.Sprintf(" AND l.status = $%d", len(args))
+	}
+	if filters.StartDate != nil {
+		args = append(args, *filters.StartDate)
+		query += fmt.Sprintf(" AND l.end_date >= $%d", len(args))
+	}
+	if filters.EndDate != nil {
+		args = append(args, *filters.EndDate)
+		query += fmt.Sprintf(" AND l.start_date <= $%d", len(args))
+	}
+	query += ` ORDER BY l.start_date, d.name, l.id`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	leaves := []*models.DoctorLeave{}
+	for rows.Next() {
+		leave, err := scanDoctorLeave(rows)
+		if err != nil {
+			return nil, err
+		}
+		leaves = append(leaves, leave)
+	}
+	return leaves, rows.Err()
+}
+
+func (r *doctorLeaveRepository) Update(ctx context.Context, leave *models.DoctorLeave) error {
+	query := `UPDATE doctor_leaves SET start_date = $2, end_date = $3, reason = $4, status = $5,
+	          reviewed_by = $6, reviewed_at = $7, updated_at = $8
+	          WHERE id = $1 RETURNING updated_at`
+	return r.db.QueryRowContext(ctx, query, leave.ID, leave.StartDate, leave.EndDate, leave.Reason, leave.Status,
+		leave.ReviewedBy, leave.ReviewedAt, time.Now()).Scan(&leave.UpdatedAt)
+}
+
+func (r *doctorLeaveRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM doctor_leaves WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+const doctorLeaveSubstituteColumns = `s.id, s.leave_id, s.date, s.branch_id, COALESCE(b.code, ''), s.doctor_id, COALESCE(d.name, ''),
+	          s.created_by, s.created_at`
+
+const doctorLeaveSubstituteJoins = ` FROM doctor_leave_substitutes s
+	          LEFT JOIN branches b ON s.branch_id = b.id
+	          LEFT JOIN doctors d ON s.doctor_id = d.id`
+
+func scanDoctorLeaveSubstitute(scanner interface{ Scan(...interface{}) error }) (*models.DoctorLeaveSubstitute, error) {
+	substitute := &models.DoctorLeaveSubstitute{}
+	var createdBy uuid.NullUUID
+	if err := scanner.Scan(
+		&substitute.ID, &substitute.LeaveID, &substitute.Date, &substitute.BranchID, &substitute.BranchCode,
+		&substitute.DoctorID, &substitute.DoctorName, &createdBy, &substitute.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	substitute.CreatedBy = createdBy.UUID
+	return substitute, nil
+}
+
+func (r *doctorLeaveRepository) CreateSubstitute(ctx context.Context, substitute *models.DoctorLeaveSubstitute) error {
+	if substitute.ID == uuid.Nil {
+		substitute.ID = uuid.New()
+	}
+	query := `INSERT INTO doctor_leave_substitutes (id, leave_id, date, branch_id, doctor_id, created_by, created_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING created_at`
+	return r.db.QueryRowContext(ctx, query, substitute.ID, substitute.LeaveID, substitute.Date, substitute.BranchID,
+		substitute.DoctorID, substitute.CreatedBy, time.Now()).Scan(&substitute.CreatedAt)
+}
+
+func (r *doctorLeaveRepository) GetSubstituteByID(ctx context.Context, id uuid.UUID) (*models.DoctorLeaveSubstitute, error) {
+	query := `SELECT ` + doctorLeaveSubstituteColumns + doctorLeaveSubstituteJoins + ` WHERE s.id = $1`
+	substitute, err := scanDoctorLeaveSubstitute(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return substitute, err
+}
+
+func (r *doctorLeaveRepository) GetSubstitutes(ctx context.Context, leaveID uuid.UUID) ([]*models.DoctorLeaveSubstitute, error) {
+	query := `SELECT ` + doctorLeaveSubstituteColumns + doctorLeaveSubstituteJoins + `
+	          WHERE s.leave_id = $1 ORDER BY s.date, b.code`
+	rows, err := r.db.QueryContext(ctx, query, leaveID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	substitutes := []*models.DoctorLeaveSubstitute{}
+	for rows.Next() {
+		substitute, err := scanDoctorLeaveSubstitute(rows)
+		if err != nil {
+			return nil, err
+		}
+		substitutes = append(substitutes, substitute)
+	}
+	return substitutes, rows.Err()
+}
+
+func (r *doctorLeaveRepository) DeleteSubstitute(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM doctor_leave_substitutes WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -13,14 +14,14 @@ import (
 )
 
 type doctorPreferenceRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewDoctorPreferenceRepository(db *sql.DB) interfaces.DoctorPreferenceRepository {
+func NewDoctorPreferenceRepository(db DBTX) interfaces.DoctorPreferenceRepository {
 	return &doctorPreferenceRepository{db: db}
 }
 
-func (r *doctorPreferenceRepository) Create(preference *models.DoctorPreference) error {
+func (r *doctorPreferenceRepository) Create(ctx context.Context, preference *models.DoctorPreference) error {
 	preference.ID = uuid.New()
 	preference.CreatedAt = time.Now()
 	preference.UpdatedAt = time.Now()
@@ -38,11 +39,11 @@ func (r *doctorPreferenceRepository) Create(preference *models.DoctorPreference)
 		branchID = *preference.BranchID
 	}
 
-	return r.db.QueryRow(query, preference.ID, preference.DoctorID, branchID, preference.RuleType, ruleConfigJSON, preference.IsActive, preference.CreatedAt, preference.UpdatedAt).
+	return r.db.QueryRowContext(ctx, query, preference.ID, preference.DoctorID, branchID, preference.RuleType, ruleConfigJSON, preference.IsActive, preference.CreatedAt, preference.UpdatedAt).
 		Scan(&preference.CreatedAt, &preference.UpdatedAt)
 }
 
-func (r *doctorPreferenceRepository) GetByID(id uuid.UUID) (*models.DoctorPreference, error) {
+func (r *doctorPreferenceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorPreference, error) {
 	preference := &models.DoctorPreference{}
 	query := `SELECT id, doctor_id, branch_id, rule_type, rule_config, is_active, created_at, updated_at
 	          FROM doctor_preferences WHERE id = $1`
@@ -50,7 +51,7 @@ func (r *doctorPreferenceRepository) GetByID(id uuid.UUID) (*models.DoctorPrefer
 	var branchID sql.NullString
 	var ruleConfigJSON []byte
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&preference.ID, &preference.DoctorID, &branchID, &preference.RuleType, &ruleConfigJSON, &preference.IsActive, &preference.CreatedAt, &preference.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
@@ -74,11 +75,11 @@ func (r *doctorPreferenceRepository) GetByID(id uuid.UUID) (*models.DoctorPrefer
 	return preference, nil
 }
 
-func (r *doctorPreferenceRepository) GetByDoctorID(doctorID uuid.UUID) ([]*models.DoctorPreference, error) {
+func (r *doctorPreferenceRepository) GetByDoctorID(ctx context.Context, doctorID uuid.UUID) ([]*models.DoctorPreference, error) {
 	query := `SELECT id, doctor_id, branch_id, rule_type, rule_config, is_active, created_at, updated_at
 	          FROM doctor_preferences WHERE doctor_id = $1 ORDER BY created_at DESC`
 
-	rows, err := r.db.Query(query, doctorID)
+	rows, err := r.db.QueryContext(ctx, query, doctorID)
 	if err != nil {
 		return nil, err
 	}
@@ -114,11 +115,11 @@ func (r *doctorPreferenceRepository) GetByDoctorID(doctorID uuid.UUID) ([]*model
 	return preferences, rows.Err()
 }
 
-func (r *doctorPreferenceRepository) GetByDoctorAndBranch(doctorID uuid.UUID, branchID uuid.UUID) ([]*models.DoctorPreference, error) {
+func (r *doctorPreferenceRepository) GetByDoctorAndBranch(ctx context.Context, doctorID uuid.UUID, branchID uuid.UUID) ([]*models.DoctorPreference, error) {
 	query := `SELECT id, doctor_id, branch_id, rule_type, rule_config, is_active, created_at, updated_at
 	          FROM doctor_preferences WHERE doctor_id = $1 AND (branch_id = $2 OR branch_id IS NULL) ORDER BY created_at DESC`
 
-	rows, err := r.db.Query(query, doctorID, branchID)
+	rows, err := r.db.QueryContext(ctx, query, doctorID, branchID)
 	if err != nil {
 		return nil, err
 	}
@@ -154,11 +155,11 @@ func (r *doctorPreferenceRepository) GetByDoctorAndBranch(doctorID uuid.UUID, br
 	return preferences, rows.Err()
 }
 
-func (r *doctorPreferenceRepository) GetActiveByDoctorID(doctorID uuid.UUID) ([]*models.DoctorPreference, error) {
+func (r *doctorPreferenceRepository) GetActiveByDoctorID(ctx context.Context, doctorID uuid.UUID) ([]*models.DoctorPreference, error) {
 	query := `SELECT id, doctor_id, branch_id, rule_type, rule_config, is_active, created_at, updated_at
 	          FROM doctor_preferences WHERE doctor_id = $1 AND is_active = true ORDER BY created_at DESC`
 
-	rows, err := r.db.Query(query, doctorID)
+	rows, err := r.db.QueryContext(ctx, query, doctorID)
 	if err != nil {
 		return nil, err
 	}
@@ -194,7 +195,47 @@ func (r *doctorPreferenceRepository) GetActiveByDoctorID(doctorID uuid.UUID) ([]
 	return preferences, rows.Err()
 }
 
-func (r *doctorPreferenceRepository) GetRotationStaffRequirements(doctorID uuid.UUID, branchID *uuid.UUID, dayOfWeek *int) ([]*models.DoctorPreference, error) {
+func (r *doctorPreferenceRepository) GetActiveByRuleType(ctx context.Context, ruleType string) ([]*models.DoctorPreference, error) {
+	query := `SELECT id, doctor_id, branch_id, rule_type, rule_config, is_active, created_at, updated_at
+	          FROM doctor_preferences WHERE rule_type = $1 AND is_active = true ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, ruleType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var preferences []*models.DoctorPreference
+	for rows.Next() {
+		preference := &models.DoctorPreference{}
+		var branchID sql.NullString
+		var ruleConfigJSON []byte
+
+		err := rows.Scan(
+			&preference.ID, &preference.DoctorID, &branchID, &preference.RuleType, &ruleConfigJSON, &preference.IsActive, &preference.CreatedAt, &preference.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if branchID.Valid {
+			bID, _ := uuid.Parse(branchID.String)
+			preference.BranchID = &bID
+		}
+
+		if len(ruleConfigJSON) > 0 {
+			if err := json.Unmarshal(ruleConfigJSON, &preference.RuleConfig); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal rule_config: %w", err)
+			}
+		}
+
+		preferences = append(preferences, preference)
+	}
+
+	return preferences, rows.Err()
+}
+
+func (r *doctorPreferenceRepository) GetRotationStaffRequirements(ctx context.Context, doctorID uuid.UUID, branchID *uuid.UUID, dayOfWeek *int) ([]*models.DoctorPreference, error) {
 	query := `SELECT id, doctor_id, branch_id, rule_type, rule_config, is_active, created_at, updated_at
 	          FROM doctor_preferences 
 	          WHERE doctor_id = $1 AND rule_type = 'rotation_staff_requirement' AND is_active = true`
@@ -210,7 +251,7 @@ func (r *doctorPreferenceRepository) GetRotationStaffRequirements(doctorID uuid.
 
 	query += " ORDER BY created_at DESC"
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -262,7 +303,7 @@ func (r *doctorPreferenceRepository) GetRotationStaffRequirements(doctorID uuid.
 	return preferences, rows.Err()
 }
 
-func (r *doctorPreferenceRepository) Update(preference *models.DoctorPreference) error {
+func (r *doctorPreferenceRepository) Update(ctx context.Context, preference *models.DoctorPreference) error {
 	preference.UpdatedAt = time.Now()
 
 	ruleConfigJSON, err := json.Marshal(preference.RuleConfig)
@@ -278,18 +319,18 @@ func (r *doctorPreferenceRepository) Update(preference *models.DoctorPreference)
 		branchID = *preference.BranchID
 	}
 
-	_, err = r.db.Exec(query, preference.DoctorID, branchID, preference.RuleType, ruleConfigJSON, preference.IsActive, preference.UpdatedAt, preference.ID)
+	_, err = r.db.ExecContext(ctx, query, preference.DoctorID, branchID, preference.RuleType, ruleConfigJSON, preference.IsActive, preference.UpdatedAt, preference.ID)
 	return err
 }
 
-func (r *doctorPreferenceRepository) Delete(id uuid.UUID) error {
+func (r *doctorPreferenceRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM doctor_preferences WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *doctorPreferenceRepository) DeleteByDoctorID(doctorID uuid.UUID) error {
+func (r *doctorPreferenceRepository) DeleteByDoctorID(ctx context.Context, doctorID uuid.UUID) error {
 	query := `DELETE FROM doctor_preferences WHERE doctor_id = $1`
-	_, err := r.db.Exec(query, doctorID)
+	_, err := r.db.ExecContext(ctx, query, doctorID)
 	return err
 }
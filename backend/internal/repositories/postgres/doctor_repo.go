@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
@@ -10,14 +11,14 @@ import (
 )
 
 type doctorRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewDoctorRepository(db *sql.DB) interfaces.DoctorRepository {
+func NewDoctorRepository(db DBTX) interfaces.DoctorRepository {
 	return &doctorRepository{db: db}
 }
 
-func (r *doctorRepository) Create(doctor *models.Doctor) error {
+func (r *doctorRepository) Create(ctx context.Context, doctor *models.Doctor) error {
 	doctor.ID = uuid.New()
 	doctor.CreatedAt = time.Now()
 	doctor.UpdatedAt = time.Now()
@@ -34,18 +35,18 @@ func (r *doctorRepository) Create(doctor *models.Doctor) error {
 		preferences = sql.NullString{String: doctor.Preferences, Valid: true}
 	}
 
-	return r.db.QueryRow(query, doctor.ID, doctor.Name, code, preferences, doctor.CreatedAt, doctor.UpdatedAt).
+	return r.db.QueryRowContext(ctx, query, doctor.ID, doctor.Name, code, preferences, doctor.CreatedAt, doctor.UpdatedAt).
 		Scan(&doctor.CreatedAt, &doctor.UpdatedAt)
 }
 
-func (r *doctorRepository) GetByID(id uuid.UUID) (*models.Doctor, error) {
+func (r *doctorRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Doctor, error) {
 	doctor := &models.Doctor{}
 	query := `SELECT id, name, code, preferences, created_at, updated_at
 	          FROM doctors WHERE id = $1`
 	
 	var code, preferences sql.NullString
 	
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&doctor.ID, &doctor.Name, &code, &preferences, &doctor.CreatedAt, &doctor.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
@@ -65,14 +66,14 @@ func (r *doctorRepository) GetByID(id uuid.UUID) (*models.Doctor, error) {
 	return doctor, nil
 }
 
-func (r *doctorRepository) GetByCode(code string) (*models.Doctor, error) {
+func (r *doctorRepository) GetByCode(ctx context.Context, code string) (*models.Doctor, error) {
 	doctor := &models.Doctor{}
 	query := `SELECT id, name, code, preferences, created_at, updated_at
 	          FROM doctors WHERE code = $1`
 	
 	var codeVal, preferences sql.NullString
 	
-	err := r.db.QueryRow(query, code).Scan(
+	err := r.db.QueryRowContext(ctx, query, code).Scan(
 		&doctor.ID, &doctor.Name, &codeVal, &preferences, &doctor.CreatedAt, &doctor.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
@@ -92,7 +93,7 @@ func (r *doctorRepository) GetByCode(code string) (*models.Doctor, error) {
 	return doctor, nil
 }
 
-func (r *doctorRepository) Update(doctor *models.Doctor) error {
+func (r *doctorRepository) Update(ctx context.Context, doctor *models.Doctor) error {
 	doctor.UpdatedAt = time.Now()
 
 	query := `UPDATE doctors SET name = $1, code = $2, preferences = $3, updated_at = $4 WHERE id = $5`
@@ -106,21 +107,21 @@ func (r *doctorRepository) Update(doctor *models.Doctor) error {
 		preferences = sql.NullString{String: doctor.Preferences, Valid: true}
 	}
 
-	_, err := r.db.Exec(query, doctor.Name, code, preferences, doctor.UpdatedAt, doctor.ID)
+	_, err := r.db.ExecContext(ctx, query, doctor.Name, code, preferences, doctor.UpdatedAt, doctor.ID)
 	return err
 }
 
-func (r *doctorRepository) Delete(id uuid.UUID) error {
+func (r *doctorRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM doctors WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *doctorRepository) List() ([]*models.Doctor, error) {
+func (r *doctorRepository) List(ctx context.Context) ([]*models.Doctor, error) {
 	query := `SELECT id, name, code, preferences, created_at, updated_at
 	          FROM doctors ORDER BY name`
 	
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,186 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// DoctorRevenueProfileRepository implementation
+type doctorRevenueProfileRepository struct {
+	db DBTX
+}
+
+func NewDoctorRevenueProfileRepository(db DBTX) interfaces.DoctorRevenueProfileRepository {
+	return &doctorRevenueProfileRepository{db: db}
+}
+
+const doctorRevenueProfileColumns = `id, doctor_id, branch_id, day_of_week, skin_revenue, ls_hm_revenue,
+	          vitamin_cases, slim_pen_cases, created_by, created_at, updated_at`
+
+func scanDoctorRevenueProfile(scanner interface{ Scan(...interface{}) error }) (*models.DoctorRevenueProfile, error) {
+	profile := &models.DoctorRevenueProfile{}
+	var branchID uuid.NullUUID
+	if err := scanner.Scan(
+		&profile.ID, &profile.DoctorID, &branchID, &profile.DayOfWeek, &profile.SkinRevenue, &profile.LSHMRevenue,
+		&profile.VitaminCases, &profile.SlimPenCases, &profile.CreatedBy, &profile.CreatedAt, &profile.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if branchID.Valid {
+		profile.BranchID = &branchID.UUID
+	}
+	return profile, nil
+}
+
+func (r *doctorRevenueProfileRepository) Upsert(ctx context.Context, profile *models.DoctorRevenueProfile) error {
+	if profile.ID == uuid.Nil {
+		profile.ID = uuid.New()
+	}
+	now := time.Now()
+	query := `INSERT INTO doctor_revenue_profiles (id, doctor_id, branch_id, day_of_week, skin_revenue, ls_hm_revenue,
+	          vitamin_cases, slim_pen_cases, created_by, created_at, updated_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)
+	          ON CONFLICT (doctor_id, COALESCE(branch_id, '00000000-0000-0000-0000-000000000000'::uuid), day_of_week)
+	          DO UPDATE SET skin_revenue = EXCLUDED.skin_revenue, ls_hm_revenue = EXCLUDED.ls_hm_revenue,
+	          vitamin_cases = EXCLUDED.vitamin_cases, slim_pen_cases = EXCLUDED.slim_pen_cases, updated_at = EXCLUDED.updated_at
+	          RETURNING id, created_by, created_at, updated_at`
+	return r.db.QueryRowContext(ctx, query, profile.ID, profile.DoctorID, profile.BranchID, profile.DayOfWeek,
+		profile.SkinRevenue, profile.LSHMRevenue, profile.VitaminCases, profile.SlimPenCases, profile.CreatedBy, now).
+		Scan(&profile.ID, &profile.CreatedBy, &profile.CreatedAt, &profile.UpdatedAt)
+}
+
+func (r *doctorRevenueProfileRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorRevenueProfile, error) {
+	query := `SELECT ` + doctorRevenueProfileColumns + ` FROM doctor_revenue_profiles WHERE id = $1`
+	profile, err := scanDoctorRevenueProfile(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return profile, err
+}
+
+func (r *doctorRevenueProfileRepository) GetByDoctorID(ctx context.Context, doctorID uuid.UUID) ([]*models.DoctorRevenueProfile, error) {
+	query := `SELECT ` + doctorRevenueProfileColumns + ` FROM doctor_revenue_profiles
+	          WHERE doctor_id = $1 ORDER BY day_of_week, branch_id NULLS FIRST`
+	return r.query(ctx, query, doctorID)
+}
+
+func (r *doctorRevenueProfileRepository) List(ctx context.Context) ([]*models.DoctorRevenueProfile, error) {
+	query := `SELECT ` + doctorRevenueProfileColumns + ` FROM doctor_revenue_profiles
+	          ORDER BY doctor_id, day_of_week, branch_id NULLS FIRST`
+	return r.query(ctx, query)
+}
+
+func (r *doctorRevenueProfileRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.DoctorRevenueProfile, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []*models.DoctorRevenueProfile
+	for rows.Next() {
+		profile, err := scanDoctorRevenueProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, rows.Err()
+}
+
+func (r *doctorRevenueProfileRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM doctor_revenue_profiles WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// DoctorRevenueOverrideRepository implementation
+type doctorRevenueOverrideRepository struct {
+	db DBTX
+}
+
+func NewDoctorRevenueOverrideRepository(db DBTX) interfaces.DoctorRevenueOverrideRepository {
+	return &doctorRevenueOverrideRepository{db: db}
+}
+
+const doctorRevenueOverrideColumns = `id, doctor_id, date, skin_revenue, ls_hm_revenue,
+	          vitamin_cases, slim_pen_cases, created_by, created_at, updated_at`
+
+func scanDoctorRevenueOverride(scanner interface{ Scan(...interface{}) error }) (*models.DoctorRevenueOverride, error) {
+	override := &models.DoctorRevenueOverride{}
+	if err := scanner.Scan(
+		&override.ID, &override.DoctorID, &override.Date, &override.SkinRevenue, &override.LSHMRevenue,
+		&override.VitaminCases, &override.SlimPenCases, &override.CreatedBy, &override.CreatedAt, &override.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return override, nil
+}
+
+func (r *doctorRevenueOverrideRepository) Upsert(ctx context.Context, override *models.DoctorRevenueOverride) error {
+	if override.ID == uuid.Nil {
+		override.ID = uuid.New()
+	}
+	now := time.Now()
+	query := `INSERT INTO doctor_revenue_overrides (id, doctor_id, date, skin_revenue, ls_hm_revenue,
+	          vitamin_cases, slim_pen_cases, created_by, created_at, updated_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+	          ON CONFLICT (doctor_id, date)
+	          DO UPDATE SET skin_revenue = EXCLUDED.skin_revenue, ls_hm_revenue = EXCLUDED.ls_hm_revenue,
+	          vitamin_cases = EXCLUDED.vitamin_cases, slim_pen_cases = EXCLUDED.slim_pen_cases, updated_at = EXCLUDED.updated_at
+	          RETURNING id, created_by, created_at, updated_at`
+	return r.db.QueryRowContext(ctx, query, override.ID, override.DoctorID, override.Date,
+		override.SkinRevenue, override.LSHMRevenue, override.VitaminCases, override.SlimPenCases, override.CreatedBy, now).
+		Scan(&override.ID, &override.CreatedBy, &override.CreatedAt, &override.UpdatedAt)
+}
+
+func (r *doctorRevenueOverrideRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorRevenueOverride, error) {
+	query := `SELECT ` + doctorRevenueOverrideColumns + ` FROM doctor_revenue_overrides WHERE id = $1`
+	override, err := scanDoctorRevenueOverride(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return override, err
+}
+
+func (r *doctorRevenueOverrideRepository) GetByDoctorID(ctx context.Context, doctorID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorRevenueOverride, error) {
+	query := `SELECT ` + doctorRevenueOverrideColumns + ` FROM doctor_revenue_overrides
+	          WHERE doctor_id = $1 AND date >= $2 AND date <= $3 ORDER BY date`
+	return r.query(ctx, query, doctorID, startDate, endDate)
+}
+
+func (r *doctorRevenueOverrideRepository) GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.DoctorRevenueOverride, error) {
+	query := `SELECT ` + doctorRevenueOverrideColumns + ` FROM doctor_revenue_overrides
+	          WHERE date >= $1 AND date <= $2 ORDER BY date, doctor_id`
+	return r.query(ctx, query, startDate, endDate)
+}
+
+func (r *doctorRevenueOverrideRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.DoctorRevenueOverride, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var overrides []*models.DoctorRevenueOverride
+	for rows.Next() {
+		override, err := scanDoctorRevenueOverride(rows)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, override)
+	}
+	return overrides, rows.Err()
+}
+
+func (r *doctorRevenueOverrideRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM doctor_revenue_overrides WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// DoctorRosterRepository implementation
+type doctorRosterRepository struct {
+	db DBTX
+}
+
+func NewDoctorRosterRepository(db DBTX) interfaces.DoctorRosterRepository {
+	return &doctorRosterRepository{db: db}
+}
+
+func (r *doctorRosterRepository) CreateRun(ctx context.Context, run *models.DoctorRosterRun) error {
+	if run.ID == uuid.Nil {
+		run.ID = uuid.New()
+	}
+	query := `INSERT INTO doctor_roster_runs (id, start_date, end_date, trigger, added, removed, moved, updated)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING created_at`
+	return r.db.QueryRowContext(ctx, query, run.ID, run.StartDate, run.EndDate, run.Trigger,
+		run.Added, run.Removed, run.Moved, run.Updated).Scan(&run.CreatedAt)
+}
+
+func (r *doctorRosterRepository) ListRuns(ctx context.Context, limit int) ([]*models.DoctorRosterRun, error) {
+	query := `SELECT id, start_date, end_date, trigger, added, removed, moved, updated, created_at
+	          FROM doctor_roster_runs ORDER BY created_at DESC`
+	var args []interface{}
+	if limit > 0 {
+		query += ` LIMIT $1`
+		args = append(args, limit)
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []*models.DoctorRosterRun{}
+	for rows.Next() {
+		run := &models.DoctorRosterRun{}
+		if err := rows.Scan(
+			&run.ID, &run.StartDate, &run.EndDate, &run.Trigger,
+			&run.Added, &run.Removed, &run.Moved, &run.Updated, &run.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+func (r *doctorRosterRepository) Window(ctx context.Context) (*models.DoctorRosterWindow, error) {
+	query := `SELECT MIN(start_date), MAX(end_date) FROM doctor_roster_runs`
+	var start, end sql.NullTime
+	if err := r.db.QueryRowContext(ctx, query).Scan(&start, &end); err != nil {
+		return nil, err
+	}
+	if !start.Valid || !end.Valid {
+		return nil, nil
+	}
+	return &models.DoctorRosterWindow{StartDate: start.Time, EndDate: end.Time}, nil
+}
+
+func (r *doctorRosterRepository) CreateChange(ctx context.Context, change *models.DoctorRosterChange) error {
+	if change.ID == uuid.Nil {
+		change.ID = uuid.New()
+	}
+	query := `INSERT INTO doctor_roster_changes (id, run_id, doctor_id, date, change_type, from_branch_id, to_branch_id)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING created_at`
+	return r.db.QueryRowContext(ctx, query, change.ID, change.RunID, change.DoctorID, change.Date,
+		change.ChangeType, change.FromBranchID, change.ToBranchID).Scan(&change.CreatedAt)
+}
+
+func (r *doctorRosterRepository) ListChanges(ctx context.Context, filters interfaces.DoctorRosterChangeFilters) ([]*models.DoctorRosterChange, error) {
+	query := `SELECT c.id, c.run_id, c.doctor_id, COALESCE(d.name, ''), c.date, c.change_type,
+	          c.from_branch_id, COALESCE(fb.code, ''), c.to_branch_id, COALESCE(tb.code, ''), c.created_at
+	          FROM doctor_roster_changes c
+	          LEFT JOIN doctors d ON c.doctor_id = d.id
+	          LEFT JOIN branches fb ON c.from_branch_id = fb.id
+	          LEFT JOIN branches tb ON c.to_branch_id = tb.id
+	          WHERE 1=1`
+	var args []interface{}
+	if filters.RunID != nil {
+		args = append(args, *filters.RunID)
+		query += fmt.Sprintf(" AND c.run_id = $%d", len(args))
+	}
+	if filters.DoctorID != nil {
+		args = append(args, *filters.DoctorID)
+		query += fmt.Sprintf(" AND c.doctor_id = $%d", len(args))
+	}
+	if filters.BranchID != nil {
+		args = append(args, *filters.BranchID)
+		query += fmt.Sprintf(" AND (c.from_branch_id = $%d OR c.to_branch_id = $%d)", len(args), len(args))
+	}
+	if filters.StartDate != nil {
+		args = append(args, *filters.StartDate)
+		query += fmt.Sprintf(" AND c.date >= $%d", len(args))
+	}
+	if filters.EndDate != nil {
+		args = append(args, *filters.EndDate)
+		query += fmt.Sprintf(" AND c.date <= $%d", len(args))
+	}
+	query += ` ORDER BY c.created_at DESC, c.date, d.name`
+	if filters.Limit > 0 {
+		args = append(args, filters.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []*models.DoctorRosterChange{}
+	for rows.Next() {
+		change := &models.DoctorRosterChange{}
+		var fromBranchID, toBranchID uuid.NullUUID
+		if err := rows.Scan(
+			&change.ID, &change.RunID, &change.DoctorID, &change.DoctorName, &change.Date, &change.ChangeType,
+			&fromBranchID, &change.FromBranchCode, &toBranchID, &change.ToBranchCode, &change.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if fromBranchID.Valid {
+			change.FromBranchID = &fromBranchID.UUID
+		}
+		if toBranchID.Valid {
+			change.ToBranchID = &toBranchID.UUID
+		}
+		changes = append(changes, change)
+	}
+	return changes, rows.Err()
+}
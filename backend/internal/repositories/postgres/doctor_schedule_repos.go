@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
@@ -9,44 +10,44 @@ import (
 	"vsq-oper-manpower/backend/internal/domain/models"
 )
 
+// recurrenceColumns are the models.ScheduleRecurrence columns of the schedule tables
+const recurrenceColumns = `interval_weeks, anchor_date, week_of_month, valid_from, valid_until`
+
+// weeklyRule is the predicate of the every-week rows, matching the partial unique indexes
+const weeklyRule = `interval_weeks <= 1 AND week_of_month = 0 AND valid_from IS NULL AND valid_until IS NULL`
+
+func recurrenceArgs(r models.ScheduleRecurrence) []interface{} {
+	return []interface{}{r.IntervalWeeks, r.AnchorDate, r.WeekOfMonth, r.ValidFrom, r.ValidUntil}
+}
+
+func recurrenceDest(r *models.ScheduleRecurrence) []interface{} {
+	return []interface{}{&r.IntervalWeeks, &r.AnchorDate, &r.WeekOfMonth, &r.ValidFrom, &r.ValidUntil}
+}
+
 // DoctorDefaultScheduleRepository implementation
 type doctorDefaultScheduleRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewDoctorDefaultScheduleRepository(db *sql.DB) interfaces.DoctorDefaultScheduleRepository {
+func NewDoctorDefaultScheduleRepository(db DBTX) interfaces.DoctorDefaultScheduleRepository {
 	return &doctorDefaultScheduleRepository{db: db}
 }
 
-func (r *doctorDefaultScheduleRepository) Create(schedule *models.DoctorDefaultSchedule) error {
-	if schedule.ID == uuid.Nil {
-		schedule.ID = uuid.New()
-	}
-	schedule.CreatedAt = time.Now()
-	schedule.UpdatedAt = time.Now()
-	query := `INSERT INTO doctor_default_schedules (id, doctor_id, day_of_week, branch_id, created_by, created_at, updated_at) 
-	          VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING created_at, updated_at`
-	return r.db.QueryRow(query, schedule.ID, schedule.DoctorID, schedule.DayOfWeek, schedule.BranchID, schedule.CreatedBy, schedule.CreatedAt, schedule.UpdatedAt).
-		Scan(&schedule.CreatedAt, &schedule.UpdatedAt)
-}
+const doctorDefaultScheduleColumns = `id, doctor_id, day_of_week, branch_id, slot, ` + recurrenceColumns + `, created_by, created_at, updated_at`
 
-func (r *doctorDefaultScheduleRepository) GetByID(id uuid.UUID) (*models.DoctorDefaultSchedule, error) {
-	query := `SELECT id, doctor_id, day_of_week, branch_id, created_by, created_at, updated_at 
-	          FROM doctor_default_schedules WHERE id = $1`
+func scanDoctorDefaultSchedule(row interface{ Scan(...interface{}) error }) (*models.DoctorDefaultSchedule, error) {
 	schedule := &models.DoctorDefaultSchedule{}
-	err := r.db.QueryRow(query, id).Scan(
-		&schedule.ID, &schedule.DoctorID, &schedule.DayOfWeek, &schedule.BranchID, &schedule.CreatedBy, &schedule.CreatedAt, &schedule.UpdatedAt,
-	)
-	if err == sql.ErrNoRows {
-		return nil, nil
+	dest := []interface{}{&schedule.ID, &schedule.DoctorID, &schedule.DayOfWeek, &schedule.BranchID, &schedule.Slot}
+	dest = append(dest, recurrenceDest(&schedule.ScheduleRecurrence)...)
+	dest = append(dest, &schedule.CreatedBy, &schedule.CreatedAt, &schedule.UpdatedAt)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
 	}
-	return schedule, err
+	return schedule, nil
 }
 
-func (r *doctorDefaultScheduleRepository) GetByDoctorID(doctorID uuid.UUID) ([]*models.DoctorDefaultSchedule, error) {
-	query := `SELECT id, doctor_id, day_of_week, branch_id, created_by, created_at, updated_at 
-	          FROM doctor_default_schedules WHERE doctor_id = $1 ORDER BY day_of_week`
-	rows, err := r.db.Query(query, doctorID)
+func (r *doctorDefaultScheduleRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.DoctorDefaultSchedule, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -54,10 +55,8 @@ func (r *doctorDefaultScheduleRepository) GetByDoctorID(doctorID uuid.UUID) ([]*
 
 	var schedules []*models.DoctorDefaultSchedule
 	for rows.Next() {
-		schedule := &models.DoctorDefaultSchedule{}
-		if err := rows.Scan(
-			&schedule.ID, &schedule.DoctorID, &schedule.DayOfWeek, &schedule.BranchID, &schedule.CreatedBy, &schedule.CreatedAt, &schedule.UpdatedAt,
-		); err != nil {
+		schedule, err := scanDoctorDefaultSchedule(rows)
+		if err != nil {
 			return nil, err
 		}
 		schedules = append(schedules, schedule)
@@ -65,92 +64,117 @@ func (r *doctorDefaultScheduleRepository) GetByDoctorID(doctorID uuid.UUID) ([]*
 	return schedules, rows.Err()
 }
 
-func (r *doctorDefaultScheduleRepository) GetByDoctorAndDayOfWeek(doctorID uuid.UUID, dayOfWeek int) (*models.DoctorDefaultSchedule, error) {
-	query := `SELECT id, doctor_id, day_of_week, branch_id, created_by, created_at, updated_at 
-	          FROM doctor_default_schedules WHERE doctor_id = $1 AND day_of_week = $2`
-	schedule := &models.DoctorDefaultSchedule{}
-	err := r.db.QueryRow(query, doctorID, dayOfWeek).Scan(
-		&schedule.ID, &schedule.DoctorID, &schedule.DayOfWeek, &schedule.BranchID, &schedule.CreatedBy, &schedule.CreatedAt, &schedule.UpdatedAt,
-	)
+func (r *doctorDefaultScheduleRepository) Create(ctx context.Context, schedule *models.DoctorDefaultSchedule) error {
+	if schedule.ID == uuid.Nil {
+		schedule.ID = uuid.New()
+	}
+	schedule.Slot = models.DoctorSlotOrFullDay(schedule.Slot)
+	schedule.CreatedAt = time.Now()
+	schedule.UpdatedAt = time.Now()
+	query := `INSERT INTO doctor_default_schedules (id, doctor_id, day_of_week, branch_id, slot, ` + recurrenceColumns + `, created_by, created_at, updated_at) 
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING created_at, updated_at`
+	args := []interface{}{schedule.ID, schedule.DoctorID, schedule.DayOfWeek, schedule.BranchID, schedule.Slot}
+	args = append(args, recurrenceArgs(schedule.ScheduleRecurrence)...)
+	args = append(args, schedule.CreatedBy, schedule.CreatedAt, schedule.UpdatedAt)
+	return r.db.QueryRowContext(ctx, query, args...).Scan(&schedule.CreatedAt, &schedule.UpdatedAt)
+}
+
+func (r *doctorDefaultScheduleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorDefaultSchedule, error) {
+	query := `SELECT ` + doctorDefaultScheduleColumns + ` FROM doctor_default_schedules WHERE id = $1`
+	schedule, err := scanDoctorDefaultSchedule(r.db.QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	return schedule, err
 }
 
-func (r *doctorDefaultScheduleRepository) Update(schedule *models.DoctorDefaultSchedule) error {
+func (r *doctorDefaultScheduleRepository) GetByDoctorID(ctx context.Context, doctorID uuid.UUID) ([]*models.DoctorDefaultSchedule, error) {
+	query := `SELECT ` + doctorDefaultScheduleColumns + ` 
+	          FROM doctor_default_schedules WHERE doctor_id = $1 ORDER BY day_of_week, slot, created_at, id`
+	return r.query(ctx, query, doctorID)
+}
+
+func (r *doctorDefaultScheduleRepository) List(ctx context.Context) ([]*models.DoctorDefaultSchedule, error) {
+	query := `SELECT ` + doctorDefaultScheduleColumns + ` 
+	          FROM doctor_default_schedules ORDER BY doctor_id, day_of_week, slot, created_at, id`
+	return r.query(ctx, query)
+}
+
+func (r *doctorDefaultScheduleRepository) GetByDoctorAndDayOfWeek(ctx context.Context, doctorID uuid.UUID, dayOfWeek int) ([]*models.DoctorDefaultSchedule, error) {
+	query := `SELECT ` + doctorDefaultScheduleColumns + ` 
+	          FROM doctor_default_schedules WHERE doctor_id = $1 AND day_of_week = $2 ORDER BY slot, created_at, id`
+	return r.query(ctx, query, doctorID, dayOfWeek)
+}
+
+func (r *doctorDefaultScheduleRepository) Update(ctx context.Context, schedule *models.DoctorDefaultSchedule) error {
+	schedule.Slot = models.DoctorSlotOrFullDay(schedule.Slot)
 	schedule.UpdatedAt = time.Now()
 	query := `UPDATE doctor_default_schedules 
-	          SET branch_id = $1, updated_at = $2 
-	          WHERE id = $3 RETURNING updated_at`
-	return r.db.QueryRow(query, schedule.BranchID, schedule.UpdatedAt, schedule.ID).Scan(&schedule.UpdatedAt)
+	          SET branch_id = $1, slot = $2, interval_weeks = $3, anchor_date = $4, week_of_month = $5,
+	              valid_from = $6, valid_until = $7, updated_at = $8 
+	          WHERE id = $9 RETURNING updated_at`
+	args := []interface{}{schedule.BranchID, schedule.Slot}
+	args = append(args, recurrenceArgs(schedule.ScheduleRecurrence)...)
+	args = append(args, schedule.UpdatedAt, schedule.ID)
+	return r.db.QueryRowContext(ctx, query, args...).Scan(&schedule.UpdatedAt)
 }
 
-func (r *doctorDefaultScheduleRepository) Delete(id uuid.UUID) error {
+func (r *doctorDefaultScheduleRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM doctor_default_schedules WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *doctorDefaultScheduleRepository) DeleteByDoctorID(doctorID uuid.UUID) error {
+func (r *doctorDefaultScheduleRepository) DeleteByDoctorID(ctx context.Context, doctorID uuid.UUID) error {
 	query := `DELETE FROM doctor_default_schedules WHERE doctor_id = $1`
-	_, err := r.db.Exec(query, doctorID)
+	_, err := r.db.ExecContext(ctx, query, doctorID)
 	return err
 }
 
-func (r *doctorDefaultScheduleRepository) Upsert(schedule *models.DoctorDefaultSchedule) error {
+func (r *doctorDefaultScheduleRepository) Upsert(ctx context.Context, schedule *models.DoctorDefaultSchedule) error {
+	if schedule.Restricted() {
+		return r.Create(ctx, schedule)
+	}
 	if schedule.ID == uuid.Nil {
 		schedule.ID = uuid.New()
 	}
+	schedule.Slot = models.DoctorSlotOrFullDay(schedule.Slot)
 	schedule.CreatedAt = time.Now()
 	schedule.UpdatedAt = time.Now()
-	query := `INSERT INTO doctor_default_schedules (id, doctor_id, day_of_week, branch_id, created_by, created_at, updated_at) 
-	          VALUES ($1, $2, $3, $4, $5, $6, $7)
-	          ON CONFLICT (doctor_id, day_of_week) 
-	          DO UPDATE SET branch_id = EXCLUDED.branch_id, updated_at = EXCLUDED.updated_at
-	          RETURNING created_at, updated_at`
-	return r.db.QueryRow(query, schedule.ID, schedule.DoctorID, schedule.DayOfWeek, schedule.BranchID, schedule.CreatedBy, schedule.CreatedAt, schedule.UpdatedAt).
-		Scan(&schedule.CreatedAt, &schedule.UpdatedAt)
+	query := `INSERT INTO doctor_default_schedules (id, doctor_id, day_of_week, branch_id, slot, interval_weeks, created_by, created_at, updated_at) 
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	          ON CONFLICT (doctor_id, day_of_week, slot) WHERE ` + weeklyRule + ` 
+	          DO UPDATE SET branch_id = EXCLUDED.branch_id, interval_weeks = EXCLUDED.interval_weeks, updated_at = EXCLUDED.updated_at
+	          RETURNING id, created_at, updated_at`
+	return r.db.QueryRowContext(ctx, query, schedule.ID, schedule.DoctorID, schedule.DayOfWeek, schedule.BranchID, schedule.Slot,
+		schedule.IntervalWeeks, schedule.CreatedBy, schedule.CreatedAt, schedule.UpdatedAt).
+		Scan(&schedule.ID, &schedule.CreatedAt, &schedule.UpdatedAt)
 }
 
 // DoctorWeeklyOffDayRepository implementation
 type doctorWeeklyOffDayRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewDoctorWeeklyOffDayRepository(db *sql.DB) interfaces.DoctorWeeklyOffDayRepository {
+func NewDoctorWeeklyOffDayRepository(db DBTX) interfaces.DoctorWeeklyOffDayRepository {
 	return &doctorWeeklyOffDayRepository{db: db}
 }
 
-func (r *doctorWeeklyOffDayRepository) Create(offDay *models.DoctorWeeklyOffDay) error {
-	if offDay.ID == uuid.Nil {
-		offDay.ID = uuid.New()
-	}
-	offDay.CreatedAt = time.Now()
-	offDay.UpdatedAt = time.Now()
-	query := `INSERT INTO doctor_weekly_off_days (id, doctor_id, day_of_week, created_by, created_at, updated_at) 
-	          VALUES ($1, $2, $3, $4, $5, $6) RETURNING created_at, updated_at`
-	return r.db.QueryRow(query, offDay.ID, offDay.DoctorID, offDay.DayOfWeek, offDay.CreatedBy, offDay.CreatedAt, offDay.UpdatedAt).
-		Scan(&offDay.CreatedAt, &offDay.UpdatedAt)
-}
+const doctorWeeklyOffDayColumns = `id, doctor_id, day_of_week, ` + recurrenceColumns + `, created_by, created_at, updated_at`
 
-func (r *doctorWeeklyOffDayRepository) GetByID(id uuid.UUID) (*models.DoctorWeeklyOffDay, error) {
-	query := `SELECT id, doctor_id, day_of_week, created_by, created_at, updated_at 
-	          FROM doctor_weekly_off_days WHERE id = $1`
+func scanDoctorWeeklyOffDay(row interface{ Scan(...interface{}) error }) (*models.DoctorWeeklyOffDay, error) {
 	offDay := &models.DoctorWeeklyOffDay{}
-	err := r.db.QueryRow(query, id).Scan(
-		&offDay.ID, &offDay.DoctorID, &offDay.DayOfWeek, &offDay.CreatedBy, &offDay.CreatedAt, &offDay.UpdatedAt,
-	)
-	if err == sql.ErrNoRows {
-		return nil, nil
+	dest := []interface{}{&offDay.ID, &offDay.DoctorID, &offDay.DayOfWeek}
+	dest = append(dest, recurrenceDest(&offDay.ScheduleRecurrence)...)
+	dest = append(dest, &offDay.CreatedBy, &offDay.CreatedAt, &offDay.UpdatedAt)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
 	}
-	return offDay, err
+	return offDay, nil
 }
 
-func (r *doctorWeeklyOffDayRepository) GetByDoctorID(doctorID uuid.UUID) ([]*models.DoctorWeeklyOffDay, error) {
-	query := `SELECT id, doctor_id, day_of_week, created_by, created_at, updated_at 
-	          FROM doctor_weekly_off_days WHERE doctor_id = $1 ORDER BY day_of_week`
-	rows, err := r.db.Query(query, doctorID)
+func (r *doctorWeeklyOffDayRepository) query(ctx context.Context, query string, args ...interface{}) ([]*models.DoctorWeeklyOffDay, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -158,10 +182,8 @@ func (r *doctorWeeklyOffDayRepository) GetByDoctorID(doctorID uuid.UUID) ([]*mod
 
 	var offDays []*models.DoctorWeeklyOffDay
 	for rows.Next() {
-		offDay := &models.DoctorWeeklyOffDay{}
-		if err := rows.Scan(
-			&offDay.ID, &offDay.DoctorID, &offDay.DayOfWeek, &offDay.CreatedBy, &offDay.CreatedAt, &offDay.UpdatedAt,
-		); err != nil {
+		offDay, err := scanDoctorWeeklyOffDay(rows)
+		if err != nil {
 			return nil, err
 		}
 		offDays = append(offDays, offDay)
@@ -169,47 +191,75 @@ func (r *doctorWeeklyOffDayRepository) GetByDoctorID(doctorID uuid.UUID) ([]*mod
 	return offDays, rows.Err()
 }
 
-func (r *doctorWeeklyOffDayRepository) GetByDoctorAndDayOfWeek(doctorID uuid.UUID, dayOfWeek int) (*models.DoctorWeeklyOffDay, error) {
-	query := `SELECT id, doctor_id, day_of_week, created_by, created_at, updated_at 
-	          FROM doctor_weekly_off_days WHERE doctor_id = $1 AND day_of_week = $2`
-	offDay := &models.DoctorWeeklyOffDay{}
-	err := r.db.QueryRow(query, doctorID, dayOfWeek).Scan(
-		&offDay.ID, &offDay.DoctorID, &offDay.DayOfWeek, &offDay.CreatedBy, &offDay.CreatedAt, &offDay.UpdatedAt,
-	)
+func (r *doctorWeeklyOffDayRepository) Create(ctx context.Context, offDay *models.DoctorWeeklyOffDay) error {
+	if offDay.ID == uuid.Nil {
+		offDay.ID = uuid.New()
+	}
+	offDay.CreatedAt = time.Now()
+	offDay.UpdatedAt = time.Now()
+	query := `INSERT INTO doctor_weekly_off_days (id, doctor_id, day_of_week, ` + recurrenceColumns + `, created_by, created_at, updated_at) 
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING created_at, updated_at`
+	args := []interface{}{offDay.ID, offDay.DoctorID, offDay.DayOfWeek}
+	args = append(args, recurrenceArgs(offDay.ScheduleRecurrence)...)
+	args = append(args, offDay.CreatedBy, offDay.CreatedAt, offDay.UpdatedAt)
+	return r.db.QueryRowContext(ctx, query, args...).Scan(&offDay.CreatedAt, &offDay.UpdatedAt)
+}
+
+func (r *doctorWeeklyOffDayRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorWeeklyOffDay, error) {
+	query := `SELECT ` + doctorWeeklyOffDayColumns + ` FROM doctor_weekly_off_days WHERE id = $1`
+	offDay, err := scanDoctorWeeklyOffDay(r.db.QueryRowContext(ctx, query, id))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	return offDay, err
 }
 
-func (r *doctorWeeklyOffDayRepository) Delete(id uuid.UUID) error {
+func (r *doctorWeeklyOffDayRepository) GetByDoctorID(ctx context.Context, doctorID uuid.UUID) ([]*models.DoctorWeeklyOffDay, error) {
+	query := `SELECT ` + doctorWeeklyOffDayColumns + ` 
+	          FROM doctor_weekly_off_days WHERE doctor_id = $1 ORDER BY day_of_week, created_at, id`
+	return r.query(ctx, query, doctorID)
+}
+
+func (r *doctorWeeklyOffDayRepository) List(ctx context.Context) ([]*models.DoctorWeeklyOffDay, error) {
+	query := `SELECT ` + doctorWeeklyOffDayColumns + ` 
+	          FROM doctor_weekly_off_days ORDER BY doctor_id, day_of_week, created_at, id`
+	return r.query(ctx, query)
+}
+
+func (r *doctorWeeklyOffDayRepository) GetByDoctorAndDayOfWeek(ctx context.Context, doctorID uuid.UUID, dayOfWeek int) ([]*models.DoctorWeeklyOffDay, error) {
+	query := `SELECT ` + doctorWeeklyOffDayColumns + ` 
+	          FROM doctor_weekly_off_days WHERE doctor_id = $1 AND day_of_week = $2 ORDER BY created_at, id`
+	return r.query(ctx, query, doctorID, dayOfWeek)
+}
+
+func (r *doctorWeeklyOffDayRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM doctor_weekly_off_days WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *doctorWeeklyOffDayRepository) DeleteByDoctorID(doctorID uuid.UUID) error {
+func (r *doctorWeeklyOffDayRepository) DeleteByDoctorID(ctx context.Context, doctorID uuid.UUID) error {
 	query := `DELETE FROM doctor_weekly_off_days WHERE doctor_id = $1`
-	_, err := r.db.Exec(query, doctorID)
+	_, err := r.db.ExecContext(ctx, query, doctorID)
 	return err
 }
 
-func (r *doctorWeeklyOffDayRepository) DeleteByDoctorAndDayOfWeek(doctorID uuid.UUID, dayOfWeek int) error {
+func (r *doctorWeeklyOffDayRepository) DeleteByDoctorAndDayOfWeek(ctx context.Context, doctorID uuid.UUID, dayOfWeek int) error {
 	query := `DELETE FROM doctor_weekly_off_days WHERE doctor_id = $1 AND day_of_week = $2`
-	_, err := r.db.Exec(query, doctorID, dayOfWeek)
+	_, err := r.db.ExecContext(ctx, query, doctorID, dayOfWeek)
 	return err
 }
 
 // DoctorScheduleOverrideRepository implementation
 type doctorScheduleOverrideRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewDoctorScheduleOverrideRepository(db *sql.DB) interfaces.DoctorScheduleOverrideRepository {
+func NewDoctorScheduleOverrideRepository(db DBTX) interfaces.DoctorScheduleOverrideRepository {
 	return &doctorScheduleOverrideRepository{db: db}
 }
 
-func (r *doctorScheduleOverrideRepository) Create(override *models.DoctorScheduleOverride) error {
+func (r *doctorScheduleOverrideRepository) Create(ctx context.Context, override *models.DoctorScheduleOverride) error {
 	if override.ID == uuid.Nil {
 		override.ID = uuid.New()
 	}
@@ -217,16 +267,16 @@ func (r *doctorScheduleOverrideRepository) Create(override *models.DoctorSchedul
 	override.UpdatedAt = time.Now()
 	query := `INSERT INTO doctor_schedule_overrides (id, doctor_id, date, type, branch_id, created_by, created_at, updated_at) 
 	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING created_at, updated_at`
-	return r.db.QueryRow(query, override.ID, override.DoctorID, override.Date, override.Type, override.BranchID, override.CreatedBy, override.CreatedAt, override.UpdatedAt).
+	return r.db.QueryRowContext(ctx, query, override.ID, override.DoctorID, override.Date, override.Type, override.BranchID, override.CreatedBy, override.CreatedAt, override.UpdatedAt).
 		Scan(&override.CreatedAt, &override.UpdatedAt)
 }
 
-func (r *doctorScheduleOverrideRepository) GetByID(id uuid.UUID) (*models.DoctorScheduleOverride, error) {
+func (r *doctorScheduleOverrideRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorScheduleOverride, error) {
 	query := `SELECT id, doctor_id, date, type, branch_id, created_by, created_at, updated_at 
 	          FROM doctor_schedule_overrides WHERE id = $1`
 	override := &models.DoctorScheduleOverride{}
 	var branchID sql.NullString
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&override.ID, &override.DoctorID, &override.Date, &override.Type, &branchID, &override.CreatedBy, &override.CreatedAt, &override.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
@@ -242,10 +292,37 @@ func (r *doctorScheduleOverrideRepository) GetByID(id uuid.UUID) (*models.Doctor
 	return override, nil
 }
 
-func (r *doctorScheduleOverrideRepository) GetByDoctorID(doctorID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorScheduleOverride, error) {
+func (r *doctorScheduleOverrideRepository) GetByDoctorID(ctx context.Context, doctorID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorScheduleOverride, error) {
 	query := `SELECT id, doctor_id, date, type, branch_id, created_by, created_at, updated_at 
 	          FROM doctor_schedule_overrides WHERE doctor_id = $1 AND date >= $2 AND date <= $3 ORDER BY date`
-	rows, err := r.db.Query(query, doctorID, startDate, endDate)
+	rows, err := r.db.QueryContext(ctx, query, doctorID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var overrides []*models.DoctorScheduleOverride
+	for rows.Next() {
+		override := &models.DoctorScheduleOverride{}
+		var branchID sql.NullString
+		if err := rows.Scan(
+			&override.ID, &override.DoctorID, &override.Date, &override.Type, &branchID, &override.CreatedBy, &override.CreatedAt, &override.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if branchID.Valid {
+			bID, _ := uuid.Parse(branchID.String)
+			override.BranchID = &bID
+		}
+		overrides = append(overrides, override)
+	}
+	return overrides, rows.Err()
+}
+
+func (r *doctorScheduleOverrideRepository) GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.DoctorScheduleOverride, error) {
+	query := `SELECT id, doctor_id, date, type, branch_id, created_by, created_at, updated_at 
+	          FROM doctor_schedule_overrides WHERE date >= $1 AND date <= $2 ORDER BY date, doctor_id`
+	rows, err := r.db.QueryContext(ctx, query, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -269,12 +346,12 @@ func (r *doctorScheduleOverrideRepository) GetByDoctorID(doctorID uuid.UUID, sta
 	return overrides, rows.Err()
 }
 
-func (r *doctorScheduleOverrideRepository) GetByDoctorAndDate(doctorID uuid.UUID, date time.Time) (*models.DoctorScheduleOverride, error) {
+func (r *doctorScheduleOverrideRepository) GetByDoctorAndDate(ctx context.Context, doctorID uuid.UUID, date time.Time) (*models.DoctorScheduleOverride, error) {
 	query := `SELECT id, doctor_id, date, type, branch_id, created_by, created_at, updated_at 
 	          FROM doctor_schedule_overrides WHERE doctor_id = $1 AND date = $2`
 	override := &models.DoctorScheduleOverride{}
 	var branchID sql.NullString
-	err := r.db.QueryRow(query, doctorID, date).Scan(
+	err := r.db.QueryRowContext(ctx, query, doctorID, date).Scan(
 		&override.ID, &override.DoctorID, &override.Date, &override.Type, &branchID, &override.CreatedBy, &override.CreatedAt, &override.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
@@ -290,22 +367,22 @@ func (r *doctorScheduleOverrideRepository) GetByDoctorAndDate(doctorID uuid.UUID
 	return override, nil
 }
 
-func (r *doctorScheduleOverrideRepository) Update(override *models.DoctorScheduleOverride) error {
+func (r *doctorScheduleOverrideRepository) Update(ctx context.Context, override *models.DoctorScheduleOverride) error {
 	override.UpdatedAt = time.Now()
 	query := `UPDATE doctor_schedule_overrides 
 	          SET type = $1, branch_id = $2, updated_at = $3 
 	          WHERE id = $4 RETURNING updated_at`
-	return r.db.QueryRow(query, override.Type, override.BranchID, override.UpdatedAt, override.ID).Scan(&override.UpdatedAt)
+	return r.db.QueryRowContext(ctx, query, override.Type, override.BranchID, override.UpdatedAt, override.ID).Scan(&override.UpdatedAt)
 }
 
-func (r *doctorScheduleOverrideRepository) Delete(id uuid.UUID) error {
+func (r *doctorScheduleOverrideRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM doctor_schedule_overrides WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *doctorScheduleOverrideRepository) DeleteByDoctorID(doctorID uuid.UUID) error {
+func (r *doctorScheduleOverrideRepository) DeleteByDoctorID(ctx context.Context, doctorID uuid.UUID) error {
 	query := `DELETE FROM doctor_schedule_overrides WHERE doctor_id = $1`
-	_, err := r.db.Exec(query, doctorID)
+	_, err := r.db.ExecContext(ctx, query, doctorID)
 	return err
 }
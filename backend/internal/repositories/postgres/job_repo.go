@@ -0,0 +1,398 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type jobRepository struct {
+	db DBTX
+}
+
+func NewJobRepository(db DBTX) interfaces.JobRepository {
+	return &jobRepository{db: db}
+}
+
+func (r *jobRepository) Create(ctx context.Context, job *models.Job, input *models.JobFile) error {
+	if job.ID == uuid.Nil {
+		job.ID = uuid.New()
+	}
+	if job.Status == "" {
+		job.Status = models.JobStatusQueued
+	}
+	if job.RunAt.IsZero() {
+		job.RunAt = time.Now()
+	}
+	var inputName, inputType sql.NullString
+	var inputData []byte
+	if input != nil {
+		inputName = sql.NullString{String: input.Name, Valid: true}
+		inputType = sql.NullString{String: input.ContentType, Valid: true}
+		inputData = input.Data
+	}
+	query := `INSERT INTO jobs (id, type, status, payload, input_name, input_content_type, input_data,
+	          max_attempts, schedule_name, run_at, created_by)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING created_at, updated_at`
+	return r.db.QueryRowContext(ctx, query, job.ID, job.Type, job.Status, nullJSON(job.Payload),
+		inputName, inputType, inputData, job.MaxAttempts, nullString(job.ScheduleName), job.RunAt, job.CreatedBy).
+		Scan(&job.CreatedAt, &job.UpdatedAt)
+}
+
+const jobColumns = `id, type, status, payload, progress, progress_message, attempts, max_attempts, error,
+	result, COALESCE(result_file_name, ''), cancel_requested, COALESCE(schedule_name, ''), run_at,
+	COALESCE(locked_by, ''), heartbeat_at, started_at, finished_at, created_by, created_at, updated_at`
+
+func scanJob(scanner interface{ Scan(...interface{}) error }) (*models.Job, error) {
+	job := &models.Job{}
+	var payload, result []byte
+	var heartbeatAt, startedAt, finishedAt sql.NullTime
+	var createdBy uuid.NullUUID
+	if err := scanner.Scan(
+		&job.ID, &job.Type, &job.Status, &payload, &job.Progress, &job.ProgressMessage,
+		&job.Attempts, &job.MaxAttempts, &job.Error, &result, &job.ResultFileName,
+		&job.CancelRequested, &job.ScheduleName, &job.RunAt, &job.LockedBy,
+		&heartbeatAt, &startedAt, &finishedAt, &createdBy, &job.CreatedAt, &job.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	job.Payload = payload
+	job.Result = result
+	if heartbeatAt.Valid {
+		job.HeartbeatAt = &heartbeatAt.Time
+	}
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	if createdBy.Valid {
+		job.CreatedBy = &createdBy.UUID
+	}
+	return job, nil
+}
+
+func (r *jobRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE id = $1`
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return job, err
+}
+
+func (r *jobRepository) List(ctx context.Context, filters interfaces.JobFilters) ([]*models.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM jobs`
+	var conditions []string
+	var args []interface{}
+	if filters.Type != "" {
+		args = append(args, filters.Type)
+		conditions = append(conditions, fmt.Sprintf("type = $%d", len(args)))
+	}
+	if filters.Status != nil {
+		args = append(args, *filters.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if filters.CreatedBy != nil {
+		args = append(args, *filters.CreatedBy)
+		conditions = append(conditions, fmt.Sprintf("created_by = $%d", len(args)))
+	}
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC, id"
+	if filters.Limit > 0 {
+		args = append(args, filters.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []*models.Job{}
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+func (r *jobRepository) GetInput(ctx context.Context, id uuid.UUID) (*models.JobFile, error) {
+	query := `SELECT input_name, input_content_type, input_data FROM jobs WHERE id = $1 AND input_data IS NOT NULL`
+	return r.getFile(ctx, query, id)
+}
+
+func (r *jobRepository) GetResultFile(ctx context.Context, id uuid.UUID) (*models.JobFile, error) {
+	query := `SELECT result_file_name, result_content_type, result_data FROM jobs WHERE id = $1 AND result_data IS NOT NULL`
+	return r.getFile(ctx, query, id)
+}
+
+func (r *jobRepository) getFile(ctx context.Context, query string, id uuid.UUID) (*models.JobFile, error) {
+	file := &models.JobFile{}
+	var name, contentType sql.NullString
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&name, &contentType, &file.Data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	file.Name, file.ContentType = name.String, contentType.String
+	return file, nil
+}
+
+func (r *jobRepository) Claim(ctx context.Context, workerID string, types []string) (*models.Job, error) {
+	query := `UPDATE jobs SET status = 'running', attempts = attempts + 1, locked_by = $1,
+	          heartbeat_at = CURRENT_TIMESTAMP, started_at = COALESCE(started_at, CURRENT_TIMESTAMP),
+	          updated_at = CURRENT_TIMESTAMP
+	          WHERE id = (
+	              SELECT id FROM jobs
+	              WHERE status = 'queued' AND run_at <= CURRENT_TIMESTAMP AND type = ANY($2)
+	              ORDER BY run_at, created_at
+	              FOR UPDATE SKIP LOCKED
+	              LIMIT 1
+	          )
+	          RETURNING ` + jobColumns
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, workerID, pq.Array(types)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return job, err
+}
+
+func (r *jobRepository) Heartbeat(ctx context.Context, id uuid.UUID, progress int, message string) (bool, error) {
+	query := `UPDATE jobs SET progress = $2, progress_message = $3, heartbeat_at = CURRENT_TIMESTAMP,
+	          updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $1 AND status = 'running'
+	          RETURNING cancel_requested`
+	var cancelRequested bool
+	err := r.db.QueryRowContext(ctx, query, id, progress, message).Scan(&cancelRequested)
+	if err == sql.ErrNoRows {
+		// No longer running here: treat it as cancelled so the work stops
+		return true, nil
+	}
+	return cancelRequested, err
+}
+
+func (r *jobRepository) Complete(ctx context.Context, id uuid.UUID, result []byte, file *models.JobFile) error {
+	var fileName, contentType sql.NullString
+	var data []byte
+	if file != nil {
+		fileName = sql.NullString{String: file.Name, Valid: true}
+		contentType = sql.NullString{String: file.ContentType, Valid: true}
+		data = file.Data
+	}
+	query := `UPDATE jobs SET status = 'succeeded', progress = 100, error = '', result = $2,
+	          result_file_name = $3, result_content_type = $4, result_data = $5,
+	          locked_by = NULL, finished_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id, nullJSON(result), fileName, contentType, data)
+	return err
+}
+
+func (r *jobRepository) Fail(ctx context.Context, id uuid.UUID, errMsg string, retryAt *time.Time) error {
+	if retryAt != nil {
+		query := `UPDATE jobs SET status = 'queued', error = $2, run_at = $3, locked_by = NULL,
+		          heartbeat_at = NULL, updated_at = CURRENT_TIMESTAMP
+		          WHERE id = $1`
+		_, err := r.db.ExecContext(ctx, query, id, errMsg, *retryAt)
+		return err
+	}
+	query := `UPDATE jobs SET status = 'failed', error = $2, locked_by = NULL,
+	          finished_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id, errMsg)
+	return err
+}
+
+func (r *jobRepository) MarkCancelled(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE jobs SET status = 'cancelled', locked_by = NULL,
+	          finished_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+func (r *jobRepository) RequestCancel(ctx context.Context, id uuid.UUID) (*models.Job, error) {
+	query := `UPDATE jobs SET cancel_requested = true,
+	          status = CASE WHEN status = 'queued' THEN 'cancelled' ELSE status END,
+	          finished_at = CASE WHEN status = 'queued' THEN CURRENT_TIMESTAMP ELSE finished_at END,
+	          updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $1 AND status IN ('queued', 'running')
+	          RETURNING ` + jobColumns
+	job, err := scanJob(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return r.GetByID(ctx, id)
+	}
+	return job, err
+}
+
+func (r *jobRepository) RequeueStale(ctx context.Context, staleAfter time.Duration) (int, error) {
+	query := `UPDATE jobs SET
+	          status = CASE WHEN attempts >= max_attempts THEN 'failed' ELSE 'queued' END,
+	          finished_at = CASE WHEN attempts >= max_attempts THEN CURRENT_TIMESTAMP ELSE NULL END,
+	          error = 'worker stopped responding', locked_by = NULL, heartbeat_at = NULL,
+	          updated_at = CURRENT_TIMESTAMP
+	          WHERE status = 'running' AND heartbeat_at < CURRENT_TIMESTAMP - make_interval(secs => $1)`
+	result, err := r.db.ExecContext(ctx, query, staleAfter.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+func (r *jobRepository) DeleteFinishedBefore(ctx context.Context, before time.Time) (int, error) {
+	query := `DELETE FROM jobs WHERE status IN ('succeeded', 'failed', 'cancelled') AND finished_at < $1`
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, err
+	}
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+func (r *jobRepository) CountByStatus(ctx context.Context) (map[models.JobStatus]int, error) {
+	query := `SELECT status, COUNT(*) FROM jobs GROUP BY status`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[models.JobStatus]int)
+	for rows.Next() {
+		var status models.JobStatus
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, err
+		}
+		counts[status] = n
+	}
+	return counts, rows.Err()
+}
+
+type jobScheduleRepository struct {
+	db DBTX
+}
+
+func NewJobScheduleRepository(db DBTX) interfaces.JobScheduleRepository {
+	return &jobScheduleRepository{db: db}
+}
+
+const jobScheduleColumns = `name, job_type, cron, payload, enabled, last_run_at, next_run_at, created_at, updated_at`
+
+func scanJobSchedule(scanner interface{ Scan(...interface{}) error }) (*models.JobSchedule, error) {
+	schedule := &models.JobSchedule{}
+	var payload []byte
+	var lastRunAt sql.NullTime
+	if err := scanner.Scan(
+		&schedule.Name, &schedule.JobType, &schedule.Cron, &payload, &schedule.Enabled,
+		&lastRunAt, &schedule.NextRunAt, &schedule.CreatedAt, &schedule.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	schedule.Payload = payload
+	if lastRunAt.Valid {
+		schedule.LastRunAt = &lastRunAt.Time
+	}
+	return schedule, nil
+}
+
+func (r *jobScheduleRepository) List(ctx context.Context) ([]*models.JobSchedule, error) {
+	query := `SELECT ` + jobScheduleColumns + ` FROM job_schedules ORDER BY name`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []*models.JobSchedule{}
+	for rows.Next() {
+		schedule, err := scanJobSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, rows.Err()
+}
+
+func (r *jobScheduleRepository) GetByName(ctx context.Context, name string) (*models.JobSchedule, error) {
+	query := `SELECT ` + jobScheduleColumns + ` FROM job_schedules WHERE name = $1`
+	schedule, err := scanJobSchedule(r.db.QueryRowContext(ctx, query, name))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return schedule, err
+}
+
+func (r *jobScheduleRepository) Ensure(ctx context.Context, schedule *models.JobSchedule) error {
+	query := `INSERT INTO job_schedules (name, job_type, cron, payload, enabled, next_run_at)
+	          VALUES ($1, $2, $3, $4, $5, $6)
+	          ON CONFLICT (name) DO UPDATE SET
+	          job_type = EXCLUDED.job_type,
+	          payload = EXCLUDED.payload,
+	          next_run_at = CASE WHEN job_schedules.cron <> EXCLUDED.cron
+	              THEN EXCLUDED.next_run_at ELSE job_schedules.next_run_at END,
+	          cron = EXCLUDED.cron,
+	          updated_at = CURRENT_TIMESTAMP
+	          RETURNING ` + jobScheduleColumns
+	saved, err := scanJobSchedule(r.db.QueryRowContext(ctx, query, schedule.Name, schedule.JobType,
+		schedule.Cron, nullJSON(schedule.Payload), schedule.Enabled, schedule.NextRunAt))
+	if err != nil {
+		return err
+	}
+	*schedule = *saved
+	return nil
+}
+
+func (r *jobScheduleRepository) SetEnabled(ctx context.Context, name string, enabled bool) error {
+	query := `UPDATE job_schedules SET enabled = $2, updated_at = CURRENT_TIMESTAMP WHERE name = $1`
+	result, err := r.db.ExecContext(ctx, query, name, enabled)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *jobScheduleRepository) Advance(ctx context.Context, name string, dueAt, ranAt, nextRunAt time.Time) (bool, error) {
+	query := `UPDATE job_schedules SET last_run_at = $3, next_run_at = $4, updated_at = CURRENT_TIMESTAMP
+	          WHERE name = $1 AND next_run_at = $2`
+	result, err := r.db.ExecContext(ctx, query, name, dueAt, ranAt, nextRunAt)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n == 1, err
+}
+
+// nullJSON stores an empty JSON document as NULL
+func nullJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// linkBranchManagersToBranches links existing branch managers to their branches
+// based on username pattern (e.g., "bkk01mgr" -> branch code "BKK01")
+func linkBranchManagersToBranches(tx *sql.Tx) error {
+	// Get branch manager role ID
+	var branchManagerRoleID string
+	err := tx.QueryRow("SELECT id FROM roles WHERE name = 'branch_manager'").Scan(&branchManagerRoleID)
+	if err == sql.ErrNoRows {
+		// Nothing to link without the role
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load branch manager role: %w", err)
+	}
+
+	// Get all branch managers without branch_id set
+	rows, err := tx.Query(`
+		SELECT id, username 
+		FROM users 
+		WHERE role_id = $1 AND (branch_id IS NULL OR branch_id = '00000000-0000-0000-0000-000000000000'::uuid)
+	`, branchManagerRoleID)
+	if err != nil {
+		return fmt.Errorf("failed to query branch managers: %w", err)
+	}
+
+	// Collect first: the transaction's connection can't run other statements while rows are open
+	type manager struct {
+		userID   string
+		username string
+	}
+	var managers []manager
+	for rows.Next() {
+		var m manager
+		if err := rows.Scan(&m.userID, &m.username); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan branch manager: %w", err)
+		}
+		managers = append(managers, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, m := range managers {
+		// Extract branch code from username
+		// Pattern: {branchcode}mgr or {branchcode}amgr
+		branchCode := ""
+		if strings.HasSuffix(strings.ToLower(m.username), "amgr") {
+			branchCode = strings.ToUpper(m.username[:len(m.username)-4])
+		} else if strings.HasSuffix(strings.ToLower(m.username), "mgr") {
+			branchCode = strings.ToUpper(m.username[:len(m.username)-3])
+		} else {
+			// Skip if username doesn't match expected pattern
+			continue
+		}
+
+		// Update user's branch_id when a branch with that code exists
+		if _, err := tx.Exec(`
+			UPDATE users SET branch_id = b.id
+			FROM branches b
+			WHERE users.id = $1 AND b.code = $2
+		`, m.userID, branchCode); err != nil {
+			return fmt.Errorf("failed to link branch manager %s: %w", m.username, err)
+		}
+	}
+
+	return nil
+}
@@ -37,7 +37,7 @@ var EnglishPositionIDs = []string{
 }
 
 // MigrateRemoveEnglishPositions migrates data from English positions to Thai positions and removes English positions
-func MigrateRemoveEnglishPositions(db *sql.DB) error {
+func MigrateRemoveEnglishPositions(tx *sql.Tx) error {
 	// Check if there's anything to migrate first - check if any English positions exist
 	var hasEnglishPositions int
 	checkQuery := `SELECT COUNT(*) FROM positions WHERE id IN (`
@@ -48,10 +48,8 @@ func MigrateRemoveEnglishPositions(db *sql.DB) error {
 		checkQuery += "'" + posID + "'"
 	}
 	checkQuery += ")"
-	err := db.QueryRow(checkQuery).Scan(&hasEnglishPositions)
-	if err != nil {
-		// If query fails, continue anyway (might be first run or table doesn't exist yet)
-		hasEnglishPositions = 1
+	if err := tx.QueryRow(checkQuery).Scan(&hasEnglishPositions); err != nil {
+		return fmt.Errorf("failed to check English positions: %w", err)
 	}
 
 	// If no English positions exist, migration is already complete - skip silently
@@ -59,12 +57,6 @@ func MigrateRemoveEnglishPositions(db *sql.DB) error {
 		return nil
 	}
 
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
-	}
-	defer tx.Rollback()
-
 	// Step 1: Update staff.position_id
 	fmt.Println("Updating staff records...")
 	totalStaffUpdated := 0
@@ -259,10 +251,6 @@ func MigrateRemoveEnglishPositions(db *sql.DB) error {
 		fmt.Println("  No positions to delete")
 	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	fmt.Println("Migration completed successfully!")
 	return nil
 }
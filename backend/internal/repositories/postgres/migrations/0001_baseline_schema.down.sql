@@ -0,0 +1,61 @@
+-- Drops the whole baseline schema. All application data is lost.
+
+DROP MATERIALIZED VIEW IF EXISTS branch_quota_status_cache;
+
+DROP FUNCTION IF EXISTS refresh_quota_cache_simple CASCADE;
+DROP FUNCTION IF EXISTS refresh_branch_quota_cache CASCADE;
+DROP FUNCTION IF EXISTS update_quota_summary_on_quota_change CASCADE;
+DROP FUNCTION IF EXISTS update_quota_summary_on_rotation_change CASCADE;
+DROP FUNCTION IF EXISTS update_quota_summary_on_schedule_change CASCADE;
+DROP FUNCTION IF EXISTS recalculate_branch_quota_summary CASCADE;
+DROP FUNCTION IF EXISTS scenario_matches CASCADE;
+DROP FUNCTION IF EXISTS get_revenue_level_tier CASCADE;
+
+DROP TABLE IF EXISTS position_quota_daily_summary CASCADE;
+DROP TABLE IF EXISTS branch_quota_daily_summary CASCADE;
+DROP TABLE IF EXISTS rotation_staff_branch_positions CASCADE;
+DROP TABLE IF EXISTS specific_preferences CASCADE;
+DROP TABLE IF EXISTS clinic_preference_position_requirements CASCADE;
+DROP TABLE IF EXISTS clinic_wide_preferences CASCADE;
+DROP TABLE IF EXISTS branch_constraint_staff_groups CASCADE;
+DROP TABLE IF EXISTS branch_type_constraint_staff_groups CASCADE;
+DROP TABLE IF EXISTS branch_type_constraints CASCADE;
+DROP TABLE IF EXISTS branch_type_staff_group_requirements CASCADE;
+DROP TABLE IF EXISTS staff_group_positions CASCADE;
+DROP TABLE IF EXISTS staff_groups CASCADE;
+DROP TABLE IF EXISTS branch_types CASCADE;
+DROP TABLE IF EXISTS scenario_specific_staff_requirements CASCADE;
+DROP TABLE IF EXISTS scenario_position_requirements CASCADE;
+DROP TABLE IF EXISTS staff_requirement_scenarios CASCADE;
+DROP TABLE IF EXISTS revenue_level_tiers CASCADE;
+DROP TABLE IF EXISTS branch_constraints CASCADE;
+DROP TABLE IF EXISTS branch_weekly_revenue CASCADE;
+DROP TABLE IF EXISTS doctor_schedule_overrides CASCADE;
+DROP TABLE IF EXISTS doctor_weekly_off_days CASCADE;
+DROP TABLE IF EXISTS doctor_default_schedules CASCADE;
+DROP TABLE IF EXISTS doctor_on_off_days CASCADE;
+DROP TABLE IF EXISTS doctor_assignments CASCADE;
+DROP TABLE IF EXISTS doctor_preferences CASCADE;
+DROP TABLE IF EXISTS doctors CASCADE;
+DROP TABLE IF EXISTS position_quotas CASCADE;
+DROP TABLE IF EXISTS allocation_criteria CASCADE;
+DROP TABLE IF EXISTS staff_allocation_rules CASCADE;
+DROP TABLE IF EXISTS system_settings CASCADE;
+DROP TABLE IF EXISTS rotation_staff_schedules CASCADE;
+DROP TABLE IF EXISTS rotation_assignments CASCADE;
+DROP TABLE IF EXISTS staff_schedules CASCADE;
+DROP TABLE IF EXISTS revenue_data CASCADE;
+DROP TABLE IF EXISTS effective_branches CASCADE;
+DROP TABLE IF EXISTS staff_branches CASCADE;
+DROP TABLE IF EXISTS staff CASCADE;
+DROP TABLE IF EXISTS area_of_operation_branches CASCADE;
+DROP TABLE IF EXISTS area_of_operation_zones CASCADE;
+DROP TABLE IF EXISTS zone_branches CASCADE;
+DROP TABLE IF EXISTS zones CASCADE;
+DROP TABLE IF EXISTS areas_of_operation CASCADE;
+DROP TABLE IF EXISTS positions CASCADE;
+DROP TABLE IF EXISTS users CASCADE;
+DROP TABLE IF EXISTS branches CASCADE;
+DROP TABLE IF EXISTS roles CASCADE;
+
+DROP TYPE IF EXISTS clinic_preference_criteria_type;
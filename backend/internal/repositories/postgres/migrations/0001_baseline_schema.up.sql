@@ -0,0 +1,1460 @@
+-- Baseline schema: everything RunMigrations created before versioned migrations.
+-- Statements are idempotent so this applies cleanly to databases created by the old migrator.
+
+-- createRolesTable
+CREATE TABLE IF NOT EXISTS roles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(50) UNIQUE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- createBranchesTable
+CREATE TABLE IF NOT EXISTS branches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    code VARCHAR(50) UNIQUE NOT NULL,
+    address TEXT,
+    area_manager_id UUID,  -- Foreign key added later after users table exists
+    expected_revenue DECIMAL(15,2) DEFAULT 0,
+    priority INTEGER DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- createUsersTable
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    username VARCHAR(100) UNIQUE NOT NULL,
+    email VARCHAR(255) UNIQUE NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    role_id UUID NOT NULL REFERENCES roles(id),
+    branch_id UUID REFERENCES branches(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- createPositionsTable
+CREATE TABLE IF NOT EXISTS positions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL,
+    position_code VARCHAR(20) UNIQUE,
+    min_staff_per_branch INTEGER DEFAULT 1,
+    revenue_multiplier DECIMAL(10,4) DEFAULT 0,
+    display_order INTEGER DEFAULT 999,
+    position_type VARCHAR(20) NOT NULL DEFAULT 'branch' CHECK (position_type IN ('branch', 'rotation')),
+    manpower_type VARCHAR(50) NOT NULL DEFAULT 'อื่นๆ' CHECK (manpower_type IN ('พนักงานฟร้อนท์', 'ผู้ช่วยแพทย์', 'อื่นๆ', 'ทำความสะอาด')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- createAreasOfOperationTable
+CREATE TABLE IF NOT EXISTS areas_of_operation (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    description TEXT,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- createZonesTable
+CREATE TABLE IF NOT EXISTS zones (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    description TEXT,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- createZoneBranchesTable
+CREATE TABLE IF NOT EXISTS zone_branches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    zone_id UUID NOT NULL REFERENCES zones(id) ON DELETE CASCADE,
+    branch_id UUID NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(zone_id, branch_id)
+);
+
+-- createAreaOfOperationZonesTable
+CREATE TABLE IF NOT EXISTS area_of_operation_zones (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    area_of_operation_id UUID NOT NULL REFERENCES areas_of_operation(id) ON DELETE CASCADE,
+    zone_id UUID NOT NULL REFERENCES zones(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(area_of_operation_id, zone_id)
+);
+
+-- createAreaOfOperationBranchesTable
+CREATE TABLE IF NOT EXISTS area_of_operation_branches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    area_of_operation_id UUID NOT NULL REFERENCES areas_of_operation(id) ON DELETE CASCADE,
+    branch_id UUID NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(area_of_operation_id, branch_id)
+);
+
+-- createStaffTable
+CREATE TABLE IF NOT EXISTS staff (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    nickname VARCHAR(100),
+    name VARCHAR(255) NOT NULL,
+    staff_type VARCHAR(20) NOT NULL CHECK (staff_type IN ('branch', 'rotation')),
+    position_id UUID NOT NULL REFERENCES positions(id),
+    branch_id UUID REFERENCES branches(id),
+    coverage_area VARCHAR(255),
+    area_of_operation_id UUID REFERENCES areas_of_operation(id),
+    zone_id UUID REFERENCES zones(id),
+    skill_level INTEGER DEFAULT 5 CHECK (skill_level >= 0 AND skill_level <= 10),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- createStaffBranchesTable
+CREATE TABLE IF NOT EXISTS staff_branches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    staff_id UUID NOT NULL REFERENCES staff(id) ON DELETE CASCADE,
+    branch_id UUID NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(staff_id, branch_id)
+);
+
+-- createEffectiveBranchesTable
+CREATE TABLE IF NOT EXISTS effective_branches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    rotation_staff_id UUID NOT NULL REFERENCES staff(id),
+    branch_id UUID NOT NULL REFERENCES branches(id),
+    level INTEGER NOT NULL CHECK (level IN (1, 2)),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(rotation_staff_id, branch_id)
+);
+
+-- createRevenueDataTable
+CREATE TABLE IF NOT EXISTS revenue_data (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id UUID NOT NULL REFERENCES branches(id),
+    date DATE NOT NULL,
+    expected_revenue DECIMAL(15,2) NOT NULL,
+    actual_revenue DECIMAL(15,2),
+    revenue_source VARCHAR(20) DEFAULT 'branch' CHECK (revenue_source IN ('branch', 'doctor', 'excel')),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(branch_id, date)
+);
+
+-- createStaffSchedulesTable
+CREATE TABLE IF NOT EXISTS staff_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    staff_id UUID NOT NULL REFERENCES staff(id),
+    branch_id UUID NOT NULL REFERENCES branches(id),
+    date DATE NOT NULL,
+    schedule_status VARCHAR(20) NOT NULL DEFAULT 'off' CHECK (schedule_status IN ('working', 'off', 'leave', 'sick_leave')),
+    is_working_day BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(staff_id, branch_id, date)
+);
+
+-- createRotationAssignmentsTable
+CREATE TABLE IF NOT EXISTS rotation_assignments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    rotation_staff_id UUID NOT NULL REFERENCES staff(id),
+    branch_id UUID NOT NULL REFERENCES branches(id),
+    date DATE NOT NULL,
+    assignment_level INTEGER NOT NULL CHECK (assignment_level IN (1, 2)),
+    assigned_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(rotation_staff_id, branch_id, date)
+);
+
+-- createRotationStaffSchedulesTable
+CREATE TABLE IF NOT EXISTS rotation_staff_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    rotation_staff_id UUID NOT NULL REFERENCES staff(id),
+    date DATE NOT NULL,
+    schedule_status VARCHAR(20) NOT NULL DEFAULT 'off' CHECK (schedule_status IN ('working', 'off', 'leave', 'sick_leave')),
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(rotation_staff_id, date)
+);
+CREATE INDEX IF NOT EXISTS idx_rotation_staff_schedules_rotation_staff_id ON rotation_staff_schedules(rotation_staff_id);
+CREATE INDEX IF NOT EXISTS idx_rotation_staff_schedules_date ON rotation_staff_schedules(date);
+CREATE INDEX IF NOT EXISTS idx_rotation_staff_schedules_rotation_staff_date ON rotation_staff_schedules(rotation_staff_id, date);
+
+-- createSystemSettingsTable
+CREATE TABLE IF NOT EXISTS system_settings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    key VARCHAR(100) UNIQUE NOT NULL,
+    value TEXT NOT NULL,
+    description TEXT,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- createStaffAllocationRulesTable
+CREATE TABLE IF NOT EXISTS staff_allocation_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    position_id UUID NOT NULL REFERENCES positions(id),
+    min_staff INTEGER NOT NULL DEFAULT 1,
+    revenue_threshold DECIMAL(15,2) DEFAULT 0,
+    staff_count_formula TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(position_id)
+);
+
+-- createAllocationCriteriaTable
+CREATE TABLE IF NOT EXISTS allocation_criteria (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pillar VARCHAR(50) NOT NULL CHECK (pillar IN ('clinic_wide', 'doctor_specific', 'branch_specific')),
+    type VARCHAR(50) NOT NULL CHECK (type IN ('bookings', 'revenue', 'min_staff_position', 'min_staff_branch', 'doctor_count')),
+    weight DECIMAL(5,4) NOT NULL DEFAULT 0.0 CHECK (weight >= 0.0 AND weight <= 1.0),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    description TEXT,
+    config TEXT, -- JSON config for criteria-specific settings
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- createPositionQuotasTable
+CREATE TABLE IF NOT EXISTS position_quotas (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id UUID NOT NULL REFERENCES branches(id),
+    position_id UUID NOT NULL REFERENCES positions(id),
+    designated_quota INTEGER NOT NULL DEFAULT 0 CHECK (designated_quota >= 0),
+    minimum_required INTEGER NOT NULL DEFAULT 0 CHECK (minimum_required >= 0),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(branch_id, position_id)
+);
+
+-- createDoctorsTable
+CREATE TABLE IF NOT EXISTS doctors (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    code VARCHAR(100) UNIQUE,
+    specialization VARCHAR(255),
+    contact_info TEXT,
+    preferences JSONB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- createDoctorPreferencesTable
+CREATE TABLE IF NOT EXISTS doctor_preferences (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    doctor_id UUID NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+    branch_id UUID REFERENCES branches(id) ON DELETE CASCADE,
+    rule_type VARCHAR(100) NOT NULL,
+    rule_config JSONB NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_doctor_preferences_doctor_id ON doctor_preferences(doctor_id);
+CREATE INDEX IF NOT EXISTS idx_doctor_preferences_branch_id ON doctor_preferences(branch_id);
+
+-- createDoctorAssignmentsTable
+CREATE TABLE IF NOT EXISTS doctor_assignments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    doctor_id UUID NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+    branch_id UUID NOT NULL REFERENCES branches(id),
+    date DATE NOT NULL,
+    expected_revenue DECIMAL(15,2) NOT NULL DEFAULT 0,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(doctor_id, branch_id, date)
+);
+CREATE INDEX IF NOT EXISTS idx_doctor_assignments_doctor_id ON doctor_assignments(doctor_id);
+CREATE INDEX IF NOT EXISTS idx_doctor_assignments_branch_id ON doctor_assignments(branch_id);
+CREATE INDEX IF NOT EXISTS idx_doctor_assignments_date ON doctor_assignments(date);
+
+-- createDoctorOnOffDaysTable
+CREATE TABLE IF NOT EXISTS doctor_on_off_days (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id UUID NOT NULL REFERENCES branches(id),
+    date DATE NOT NULL,
+    is_doctor_on BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(branch_id, date)
+);
+
+-- createDoctorDefaultSchedulesTable
+CREATE TABLE IF NOT EXISTS doctor_default_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    doctor_id UUID NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+    day_of_week INTEGER NOT NULL CHECK (day_of_week >= 0 AND day_of_week <= 6),
+    branch_id UUID NOT NULL REFERENCES branches(id),
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(doctor_id, day_of_week)
+);
+CREATE INDEX IF NOT EXISTS idx_doctor_default_schedules_doctor_id ON doctor_default_schedules(doctor_id);
+CREATE INDEX IF NOT EXISTS idx_doctor_default_schedules_branch_id ON doctor_default_schedules(branch_id);
+CREATE INDEX IF NOT EXISTS idx_doctor_default_schedules_day_of_week ON doctor_default_schedules(day_of_week);
+
+-- createDoctorWeeklyOffDaysTable
+CREATE TABLE IF NOT EXISTS doctor_weekly_off_days (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    doctor_id UUID NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+    day_of_week INTEGER NOT NULL CHECK (day_of_week >= 0 AND day_of_week <= 6),
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(doctor_id, day_of_week)
+);
+CREATE INDEX IF NOT EXISTS idx_doctor_weekly_off_days_doctor_id ON doctor_weekly_off_days(doctor_id);
+CREATE INDEX IF NOT EXISTS idx_doctor_weekly_off_days_day_of_week ON doctor_weekly_off_days(day_of_week);
+
+-- createDoctorScheduleOverridesTable
+CREATE TABLE IF NOT EXISTS doctor_schedule_overrides (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    doctor_id UUID NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('working', 'off')),
+    branch_id UUID REFERENCES branches(id),
+    created_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(doctor_id, date),
+    CONSTRAINT check_working_branch CHECK (
+        (type = 'working' AND branch_id IS NOT NULL) OR
+        (type = 'off' AND branch_id IS NULL)
+    )
+);
+CREATE INDEX IF NOT EXISTS idx_doctor_schedule_overrides_doctor_id ON doctor_schedule_overrides(doctor_id);
+CREATE INDEX IF NOT EXISTS idx_doctor_schedule_overrides_date ON doctor_schedule_overrides(date);
+CREATE INDEX IF NOT EXISTS idx_doctor_schedule_overrides_branch_id ON doctor_schedule_overrides(branch_id);
+
+-- createBranchWeeklyRevenueTable
+CREATE TABLE IF NOT EXISTS branch_weekly_revenue (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id UUID NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    day_of_week INTEGER NOT NULL CHECK (day_of_week >= 0 AND day_of_week <= 6),
+    expected_revenue DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (expected_revenue >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(branch_id, day_of_week)
+);
+
+-- createBranchConstraintsTable
+CREATE TABLE IF NOT EXISTS branch_constraints (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id UUID NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    day_of_week INTEGER NOT NULL CHECK (day_of_week >= 0 AND day_of_week <= 6),
+    min_front_staff INTEGER NOT NULL DEFAULT 0 CHECK (min_front_staff >= 0),
+    min_managers INTEGER NOT NULL DEFAULT 0 CHECK (min_managers >= 0),
+    min_doctor_assistant INTEGER NOT NULL DEFAULT 0 CHECK (min_doctor_assistant >= 0),
+    min_total_staff INTEGER NOT NULL DEFAULT 0 CHECK (min_total_staff >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(branch_id, day_of_week)
+);
+
+-- createRevenueLevelTiersTable
+CREATE TABLE IF NOT EXISTS revenue_level_tiers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    level_number INTEGER NOT NULL UNIQUE CHECK (level_number >= 1 AND level_number <= 10),
+    level_name VARCHAR(50) NOT NULL,
+    min_revenue DECIMAL(15,2) NOT NULL CHECK (min_revenue >= 0),
+    max_revenue DECIMAL(15,2),
+    display_order INTEGER NOT NULL DEFAULT 0,
+    color_code VARCHAR(20),
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_revenue_level_tiers_level ON revenue_level_tiers(level_number);
+CREATE INDEX IF NOT EXISTS idx_revenue_level_tiers_range ON revenue_level_tiers(min_revenue, max_revenue);
+
+-- createStaffRequirementScenariosTable
+CREATE TABLE IF NOT EXISTS staff_requirement_scenarios (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scenario_name VARCHAR(100) NOT NULL,
+    description TEXT,
+    revenue_level_tier_id UUID REFERENCES revenue_level_tiers(id),
+    min_revenue DECIMAL(15,2),
+    max_revenue DECIMAL(15,2),
+    use_day_of_week_revenue BOOLEAN NOT NULL DEFAULT true,
+    use_specific_date_revenue BOOLEAN NOT NULL DEFAULT false,
+    doctor_count INTEGER,
+    min_doctor_count INTEGER,
+    day_of_week INTEGER CHECK (day_of_week >= 0 AND day_of_week <= 6),
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    priority INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_staff_requirement_scenarios_priority ON staff_requirement_scenarios(priority DESC, is_active);
+CREATE INDEX IF NOT EXISTS idx_staff_requirement_scenarios_tier ON staff_requirement_scenarios(revenue_level_tier_id);
+CREATE INDEX IF NOT EXISTS idx_staff_requirement_scenarios_day ON staff_requirement_scenarios(day_of_week);
+
+-- createScenarioPositionRequirementsTable
+CREATE TABLE IF NOT EXISTS scenario_position_requirements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scenario_id UUID NOT NULL REFERENCES staff_requirement_scenarios(id) ON DELETE CASCADE,
+    position_id UUID NOT NULL REFERENCES positions(id),
+    preferred_staff INTEGER NOT NULL DEFAULT 0 CHECK (preferred_staff >= 0),
+    minimum_staff INTEGER NOT NULL DEFAULT 0 CHECK (minimum_staff >= 0),
+    override_base BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(scenario_id, position_id)
+);
+CREATE INDEX IF NOT EXISTS idx_scenario_position_requirements_scenario ON scenario_position_requirements(scenario_id);
+CREATE INDEX IF NOT EXISTS idx_scenario_position_requirements_position ON scenario_position_requirements(position_id);
+
+-- addDoctorAndBranchToStaffRequirementScenarios
+DO $$ 
+BEGIN
+	-- Add doctor_id column if it doesn't exist
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'staff_requirement_scenarios' AND column_name = 'doctor_id'
+	) THEN
+		ALTER TABLE staff_requirement_scenarios ADD COLUMN doctor_id UUID REFERENCES doctors(id) ON DELETE SET NULL;
+		CREATE INDEX IF NOT EXISTS idx_staff_requirement_scenarios_doctor ON staff_requirement_scenarios(doctor_id);
+	END IF;
+
+	-- Add branch_id column if it doesn't exist
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'staff_requirement_scenarios' AND column_name = 'branch_id'
+	) THEN
+		ALTER TABLE staff_requirement_scenarios ADD COLUMN branch_id UUID REFERENCES branches(id) ON DELETE SET NULL;
+		CREATE INDEX IF NOT EXISTS idx_staff_requirement_scenarios_branch ON staff_requirement_scenarios(branch_id);
+	END IF;
+END $$;
+
+-- createScenarioSpecificStaffRequirementsTable
+CREATE TABLE IF NOT EXISTS scenario_specific_staff_requirements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scenario_id UUID NOT NULL REFERENCES staff_requirement_scenarios(id) ON DELETE CASCADE,
+    staff_id UUID NOT NULL REFERENCES staff(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(scenario_id, staff_id)
+);
+CREATE INDEX IF NOT EXISTS idx_scenario_specific_staff_requirements_scenario ON scenario_specific_staff_requirements(scenario_id);
+CREATE INDEX IF NOT EXISTS idx_scenario_specific_staff_requirements_staff ON scenario_specific_staff_requirements(staff_id);
+
+-- insertDefaultRoles
+INSERT INTO roles (id, name) VALUES
+    ('00000000-0000-0000-0000-000000000001', 'admin'),
+    ('00000000-0000-0000-0000-000000000002', 'area_manager'),
+    ('00000000-0000-0000-0000-000000000003', 'district_manager'),
+    ('00000000-0000-0000-0000-000000000004', 'branch_manager'),
+    ('00000000-0000-0000-0000-000000000005', 'viewer')
+ON CONFLICT (id) DO NOTHING;
+
+-- insertDefaultPositions
+INSERT INTO positions (id, name, min_staff_per_branch, revenue_multiplier, display_order, position_type, manpower_type) VALUES
+    -- Thai positions only (English positions removed)
+    ('10000000-0000-0000-0000-000000000008', 'ผู้จัดการสาขา', 1, 0, 1, 'branch', 'อื่นๆ'),
+    ('10000000-0000-0000-0000-000000000009', 'รองผู้จัดการสาขา', 0, 0.5, 2, 'branch', 'อื่นๆ'),
+    ('10000000-0000-0000-0000-000000000010', 'ผู้ช่วยแพทย์', 2, 1.2, 20, 'branch', 'ผู้ช่วยแพทย์'),
+    ('10000000-0000-0000-0000-000000000011', 'ผู้ประสานงานคลินิก (Clinic Coordination Officer)', 1, 0.8, 50, 'branch', 'อื่นๆ'),
+    ('10000000-0000-0000-0000-000000000012', 'ผู้ช่วย Laser Specialist', 1, 1.0, 20, 'branch', 'ผู้ช่วยแพทย์'),
+    ('10000000-0000-0000-0000-000000000013', 'พนักงานต้อนรับ (Laser Receptionist)', 1, 0.5, 40, 'branch', 'พนักงานฟร้อนท์'),
+    ('10000000-0000-0000-0000-000000000014', 'แม่บ้านประจำสาขา', 1, 0.3, 60, 'branch', 'ทำความสะอาด'),
+    ('10000000-0000-0000-0000-000000000015', 'พยาบาล', 2, 1.0, 30, 'branch', 'ผู้ช่วยแพทย์'),
+    ('10000000-0000-0000-0000-000000000016', 'ผู้ช่วยผู้จัดการสาขา', 0, 0.5, 2, 'branch', 'อื่นๆ'),
+    ('10000000-0000-0000-0000-000000000017', 'ผู้ช่วยแพทย์ Pico Laser', 1, 1.2, 20, 'branch', 'ผู้ช่วยแพทย์'),
+    ('10000000-0000-0000-0000-000000000018', 'รองผู้จัดการสาขาและล่าม', 0, 0.6, 2, 'branch', 'อื่นๆ'),
+    ('10000000-0000-0000-0000-000000000019', 'Front+ล่ามวนสาขา', 1, 0.7, 40, 'rotation', 'พนักงานฟร้อนท์'),
+    ('10000000-0000-0000-0000-000000000020', 'ผู้ช่วยแพทย์ Pico', 1, 1.2, 20, 'branch', 'ผู้ช่วยแพทย์'),
+    ('10000000-0000-0000-0000-000000000021', 'พนักงานต้อนรับ (Pico Laser Receptionist)', 1, 0.5, 40, 'branch', 'พนักงานฟร้อนท์'),
+    ('10000000-0000-0000-0000-000000000022', 'ผู้จัดการเขต', 0, 0, 10, 'rotation', 'อื่นๆ'),
+    ('10000000-0000-0000-0000-000000000023', 'ผู้จัดการแผนกและกำกับพัฒนาระเบียบสาขา', 0, 0, 10, 'rotation', 'อื่นๆ'),
+    ('10000000-0000-0000-0000-000000000024', 'หัวหน้าผู้ช่วยแพทย์', 1, 1.5, 15, 'rotation', 'ผู้ช่วยแพทย์'),
+    ('10000000-0000-0000-0000-000000000025', 'ผู้ช่วยพิเศษ', 0, 0.8, 20, 'rotation', 'อื่นๆ'),
+    ('10000000-0000-0000-0000-000000000026', 'ผู้ช่วยแพทย์วนสาขา', 1, 1.2, 20, 'rotation', 'ผู้ช่วยแพทย์'),
+    ('10000000-0000-0000-0000-000000000027', 'ฟร้อนท์วนสาขา', 1, 0.5, 40, 'rotation', 'พนักงานฟร้อนท์')
+ON CONFLICT (id) DO NOTHING;
+
+-- insertDefaultRevenueLevelTiers
+INSERT INTO revenue_level_tiers (id, level_number, level_name, min_revenue, max_revenue, display_order, color_code, description) VALUES
+    ('30000000-0000-0000-0000-000000000001', 1, 'Very Low', 0, 100000, 1, '#CCCCCC', 'Low revenue days'),
+    ('30000000-0000-0000-0000-000000000002', 2, 'Low', 100000, 200000, 2, '#99CCFF', 'Below average revenue'),
+    ('30000000-0000-0000-0000-000000000003', 3, 'Medium', 200000, 300000, 3, '#66FF99', 'Average revenue days'),
+    ('30000000-0000-0000-0000-000000000004', 4, 'High', 300000, 400000, 4, '#FFCC66', 'Above average revenue'),
+    ('30000000-0000-0000-0000-000000000005', 5, 'Very High', 400000, 500000, 5, '#FF9966', 'High revenue days'),
+    ('30000000-0000-0000-0000-000000000006', 6, 'Extremely High', 500000, 600000, 6, '#FF6666', 'Very high revenue days'),
+    ('30000000-0000-0000-0000-000000000007', 7, 'Peak', 600000, NULL, 7, '#FF0000', 'Peak revenue days')
+ON CONFLICT (level_number) DO NOTHING;
+
+-- dropCheckRevenueCriteriaConstraint
+DO $$ 
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM information_schema.table_constraints 
+        WHERE constraint_name = 'check_revenue_criteria' 
+        AND table_name = 'staff_requirement_scenarios'
+    ) THEN
+        ALTER TABLE staff_requirement_scenarios DROP CONSTRAINT check_revenue_criteria;
+    END IF;
+END $$;
+
+-- createGetRevenueLevelTierFunction
+-- Helper function to get revenue level tier for a given revenue amount
+CREATE OR REPLACE FUNCTION get_revenue_level_tier(revenue_amount DECIMAL(15,2))
+RETURNS UUID AS $$
+DECLARE
+    tier_id UUID;
+BEGIN
+    SELECT id INTO tier_id
+    FROM revenue_level_tiers
+    WHERE revenue_amount >= min_revenue
+      AND (max_revenue IS NULL OR revenue_amount < max_revenue)
+    ORDER BY level_number DESC
+    LIMIT 1;
+    
+    RETURN tier_id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- createScenarioMatchesFunction
+-- Helper function to check if scenario matches given conditions
+CREATE OR REPLACE FUNCTION scenario_matches(
+    p_scenario_id UUID,
+    p_day_of_week_revenue DECIMAL(15,2),
+    p_specific_date_revenue DECIMAL(15,2),
+    p_doctor_count INTEGER,
+    p_day_of_week INTEGER
+)
+RETURNS BOOLEAN AS $$
+DECLARE
+    v_scenario RECORD;
+    v_revenue_to_check DECIMAL(15,2);
+    v_revenue_tier_id UUID;
+BEGIN
+    -- Get scenario
+    SELECT * INTO v_scenario
+    FROM staff_requirement_scenarios
+    WHERE id = p_scenario_id AND is_active = true;
+    
+    IF NOT FOUND THEN
+        RETURN false;
+    END IF;
+    
+    -- Check day of week filter
+    IF v_scenario.day_of_week IS NOT NULL AND v_scenario.day_of_week != p_day_of_week THEN
+        RETURN false;
+    END IF;
+    
+    -- Determine which revenue to use
+    IF v_scenario.use_specific_date_revenue AND p_specific_date_revenue IS NOT NULL THEN
+        v_revenue_to_check := p_specific_date_revenue;
+    ELSIF v_scenario.use_day_of_week_revenue THEN
+        v_revenue_to_check := p_day_of_week_revenue;
+    ELSE
+        v_revenue_to_check := COALESCE(p_specific_date_revenue, p_day_of_week_revenue);
+    END IF;
+    
+    -- Check revenue tier match
+    IF v_scenario.revenue_level_tier_id IS NOT NULL THEN
+        v_revenue_tier_id := get_revenue_level_tier(v_revenue_to_check);
+        IF v_revenue_tier_id IS NULL OR v_revenue_tier_id != v_scenario.revenue_level_tier_id THEN
+            RETURN false;
+        END IF;
+    END IF;
+    
+    -- Check direct revenue range
+    IF v_scenario.min_revenue IS NOT NULL THEN
+        IF v_revenue_to_check < v_scenario.min_revenue THEN
+            RETURN false;
+        END IF;
+    END IF;
+    IF v_scenario.max_revenue IS NOT NULL THEN
+        IF v_revenue_to_check >= v_scenario.max_revenue THEN
+            RETURN false;
+        END IF;
+    END IF;
+    
+    -- Check doctor count
+    IF v_scenario.doctor_count IS NOT NULL THEN
+        IF p_doctor_count != v_scenario.doctor_count THEN
+            RETURN false;
+        END IF;
+    END IF;
+    IF v_scenario.min_doctor_count IS NOT NULL THEN
+        IF p_doctor_count < v_scenario.min_doctor_count THEN
+            RETURN false;
+        END IF;
+    END IF;
+    
+    RETURN true;
+END;
+$$ LANGUAGE plpgsql;
+
+-- createBranchTypesTable
+CREATE TABLE IF NOT EXISTS branch_types (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_branch_types_name ON branch_types(name);
+CREATE INDEX IF NOT EXISTS idx_branch_types_is_active ON branch_types(is_active);
+
+-- createStaffGroupsTable
+CREATE TABLE IF NOT EXISTS staff_groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL UNIQUE,
+    description TEXT,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_staff_groups_name ON staff_groups(name);
+CREATE INDEX IF NOT EXISTS idx_staff_groups_is_active ON staff_groups(is_active);
+
+-- createStaffGroupPositionsTable
+CREATE TABLE IF NOT EXISTS staff_group_positions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    staff_group_id UUID NOT NULL REFERENCES staff_groups(id) ON DELETE CASCADE,
+    position_id UUID NOT NULL REFERENCES positions(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(staff_group_id, position_id)
+);
+CREATE INDEX IF NOT EXISTS idx_staff_group_positions_group ON staff_group_positions(staff_group_id);
+CREATE INDEX IF NOT EXISTS idx_staff_group_positions_position ON staff_group_positions(position_id);
+
+-- createBranchTypeStaffGroupRequirementsTable
+DO $$ 
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.tables 
+        WHERE table_name = 'branch_type_staff_group_requirements'
+    ) THEN
+        CREATE TABLE branch_type_staff_group_requirements (
+            id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+            branch_type_id UUID NOT NULL REFERENCES branch_types(id) ON DELETE CASCADE,
+            staff_group_id UUID NOT NULL REFERENCES staff_groups(id) ON DELETE CASCADE,
+            day_of_week INTEGER NOT NULL DEFAULT 0 CHECK (day_of_week >= 0 AND day_of_week <= 6),
+            minimum_staff_count INTEGER NOT NULL CHECK (minimum_staff_count >= 0),
+            is_active BOOLEAN DEFAULT true,
+            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+            UNIQUE(branch_type_id, staff_group_id, day_of_week)
+        );
+        CREATE INDEX idx_branch_type_requirements_type ON branch_type_staff_group_requirements(branch_type_id);
+        CREATE INDEX idx_branch_type_requirements_group ON branch_type_staff_group_requirements(staff_group_id);
+        CREATE INDEX idx_branch_type_requirements_day ON branch_type_staff_group_requirements(day_of_week);
+    END IF;
+END $$;
+
+-- addDayOfWeekToBranchTypeStaffGroupRequirementsTable
+DO $$ 
+DECLARE
+    constraint_name_var TEXT;
+BEGIN
+    -- Only proceed if table exists
+    IF EXISTS (
+        SELECT 1 FROM information_schema.tables 
+        WHERE table_name = 'branch_type_staff_group_requirements'
+    ) THEN
+        -- Add day_of_week column if it doesn't exist
+        IF NOT EXISTS (
+            SELECT 1 FROM information_schema.columns 
+            WHERE table_name = 'branch_type_staff_group_requirements' 
+            AND column_name = 'day_of_week'
+        ) THEN
+            -- Add the column with default value (without NOT NULL first, then set it)
+            ALTER TABLE branch_type_staff_group_requirements 
+            ADD COLUMN day_of_week INTEGER DEFAULT 0;
+            
+            -- Update existing rows to have day_of_week = 0
+            UPDATE branch_type_staff_group_requirements SET day_of_week = 0 WHERE day_of_week IS NULL;
+            
+            -- Now make it NOT NULL
+            ALTER TABLE branch_type_staff_group_requirements 
+            ALTER COLUMN day_of_week SET NOT NULL;
+            
+            -- Add check constraint if it doesn't exist
+            IF NOT EXISTS (
+                SELECT 1 FROM information_schema.table_constraints 
+                WHERE constraint_name = 'branch_type_staff_group_requirements_day_of_week_check'
+                AND table_name = 'branch_type_staff_group_requirements'
+            ) THEN
+                ALTER TABLE branch_type_staff_group_requirements 
+                ADD CONSTRAINT branch_type_staff_group_requirements_day_of_week_check 
+                CHECK (day_of_week >= 0 AND day_of_week <= 6);
+            END IF;
+            
+            -- Find and drop the old unique constraint on (branch_type_id, staff_group_id) only
+            SELECT tc.constraint_name INTO constraint_name_var
+            FROM information_schema.table_constraints tc
+            WHERE tc.table_name = 'branch_type_staff_group_requirements'
+            AND tc.constraint_type = 'UNIQUE'
+            AND (
+                SELECT COUNT(DISTINCT ccu.column_name)
+                FROM information_schema.constraint_column_usage ccu
+                WHERE ccu.constraint_name = tc.constraint_name
+                AND ccu.table_name = 'branch_type_staff_group_requirements'
+                AND ccu.column_name IN ('branch_type_id', 'staff_group_id')
+            ) = 2
+            AND (
+                SELECT COUNT(DISTINCT ccu.column_name)
+                FROM information_schema.constraint_column_usage ccu
+                WHERE ccu.constraint_name = tc.constraint_name
+                AND ccu.table_name = 'branch_type_staff_group_requirements'
+            ) = 2
+            LIMIT 1;
+            
+            IF constraint_name_var IS NOT NULL THEN
+                EXECUTE 'ALTER TABLE branch_type_staff_group_requirements DROP CONSTRAINT ' || quote_ident(constraint_name_var);
+            END IF;
+            
+            -- Add new unique constraint with day_of_week if it doesn't exist
+            IF NOT EXISTS (
+                SELECT 1 FROM information_schema.table_constraints 
+                WHERE constraint_name = 'branch_type_staff_group_requirements_branch_type_id_staff_group_id_day_of_week_key'
+                AND table_name = 'branch_type_staff_group_requirements'
+            ) THEN
+                ALTER TABLE branch_type_staff_group_requirements 
+                ADD CONSTRAINT branch_type_staff_group_requirements_branch_type_id_staff_group_id_day_of_week_key 
+                UNIQUE(branch_type_id, staff_group_id, day_of_week);
+            END IF;
+            
+            -- Add index for day_of_week if it doesn't exist
+            CREATE INDEX IF NOT EXISTS idx_branch_type_requirements_day ON branch_type_staff_group_requirements(day_of_week);
+        END IF;
+    END IF;
+END $$;
+
+-- addBranchTypeToBranchesTable
+DO $$ 
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns 
+        WHERE table_name = 'branches' 
+        AND column_name = 'branch_type_id'
+    ) THEN
+        ALTER TABLE branches ADD COLUMN branch_type_id UUID REFERENCES branch_types(id);
+        CREATE INDEX IF NOT EXISTS idx_branches_branch_type_id ON branches(branch_type_id);
+    END IF;
+END $$;
+
+-- createBranchTypeConstraintsTable
+CREATE TABLE IF NOT EXISTS branch_type_constraints (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_type_id UUID NOT NULL REFERENCES branch_types(id) ON DELETE CASCADE,
+    day_of_week INTEGER NOT NULL CHECK (day_of_week >= 0 AND day_of_week <= 6),
+    min_front_staff INTEGER NOT NULL DEFAULT 0 CHECK (min_front_staff >= 0),
+    min_managers INTEGER NOT NULL DEFAULT 0 CHECK (min_managers >= 0),
+    min_doctor_assistant INTEGER NOT NULL DEFAULT 0 CHECK (min_doctor_assistant >= 0),
+    min_total_staff INTEGER NOT NULL DEFAULT 0 CHECK (min_total_staff >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(branch_type_id, day_of_week)
+);
+CREATE INDEX IF NOT EXISTS idx_branch_type_constraints_type ON branch_type_constraints(branch_type_id);
+CREATE INDEX IF NOT EXISTS idx_branch_type_constraints_day ON branch_type_constraints(day_of_week);
+
+-- addInheritanceFieldsToBranchConstraintsTable
+DO $$ 
+BEGIN
+    -- Add inherited_from_branch_type_id column if it doesn't exist
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns 
+        WHERE table_name = 'branch_constraints' 
+        AND column_name = 'inherited_from_branch_type_id'
+    ) THEN
+        ALTER TABLE branch_constraints 
+        ADD COLUMN inherited_from_branch_type_id UUID REFERENCES branch_types(id) ON DELETE SET NULL;
+        CREATE INDEX IF NOT EXISTS idx_branch_constraints_inherited_from ON branch_constraints(inherited_from_branch_type_id);
+    END IF;
+
+    -- Add is_overridden column if it doesn't exist
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.columns 
+        WHERE table_name = 'branch_constraints' 
+        AND column_name = 'is_overridden'
+    ) THEN
+        ALTER TABLE branch_constraints 
+        ADD COLUMN is_overridden BOOLEAN NOT NULL DEFAULT false;
+        CREATE INDEX IF NOT EXISTS idx_branch_constraints_is_overridden ON branch_constraints(is_overridden);
+    END IF;
+END $$;
+
+-- createBranchTypeConstraintStaffGroupsTable
+CREATE TABLE IF NOT EXISTS branch_type_constraint_staff_groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_type_constraint_id UUID NOT NULL REFERENCES branch_type_constraints(id) ON DELETE CASCADE,
+    staff_group_id UUID NOT NULL REFERENCES staff_groups(id) ON DELETE CASCADE,
+    minimum_count INTEGER NOT NULL DEFAULT 0 CHECK (minimum_count >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(branch_type_constraint_id, staff_group_id)
+);
+CREATE INDEX IF NOT EXISTS idx_branch_type_constraint_staff_groups_constraint ON branch_type_constraint_staff_groups(branch_type_constraint_id);
+CREATE INDEX IF NOT EXISTS idx_branch_type_constraint_staff_groups_staff_group ON branch_type_constraint_staff_groups(staff_group_id);
+CREATE INDEX IF NOT EXISTS idx_branch_type_constraint_staff_groups_composite ON branch_type_constraint_staff_groups(branch_type_constraint_id, staff_group_id);
+
+-- createBranchConstraintStaffGroupsTable
+CREATE TABLE IF NOT EXISTS branch_constraint_staff_groups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_constraint_id UUID NOT NULL REFERENCES branch_constraints(id) ON DELETE CASCADE,
+    staff_group_id UUID NOT NULL REFERENCES staff_groups(id) ON DELETE CASCADE,
+    minimum_count INTEGER NOT NULL DEFAULT 0 CHECK (minimum_count >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(branch_constraint_id, staff_group_id)
+);
+CREATE INDEX IF NOT EXISTS idx_branch_constraint_staff_groups_constraint ON branch_constraint_staff_groups(branch_constraint_id);
+CREATE INDEX IF NOT EXISTS idx_branch_constraint_staff_groups_staff_group ON branch_constraint_staff_groups(staff_group_id);
+CREATE INDEX IF NOT EXISTS idx_branch_constraint_staff_groups_composite ON branch_constraint_staff_groups(branch_constraint_id, staff_group_id);
+
+-- dropDeprecatedColumnsFromBranchTypeConstraints
+DO $$ 
+BEGIN
+    -- Drop deprecated columns from branch_type_constraints if they exist
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns 
+        WHERE table_name = 'branch_type_constraints' 
+        AND column_name = 'min_front_staff'
+    ) THEN
+        ALTER TABLE branch_type_constraints DROP COLUMN min_front_staff;
+    END IF;
+
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns 
+        WHERE table_name = 'branch_type_constraints' 
+        AND column_name = 'min_managers'
+    ) THEN
+        ALTER TABLE branch_type_constraints DROP COLUMN min_managers;
+    END IF;
+
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns 
+        WHERE table_name = 'branch_type_constraints' 
+        AND column_name = 'min_doctor_assistant'
+    ) THEN
+        ALTER TABLE branch_type_constraints DROP COLUMN min_doctor_assistant;
+    END IF;
+
+    IF EXISTS (
+        SELECT 1 FROM information_schema.columns 
+        WHERE table_name = 'branch_type_constraints' 
+        AND column_name = 'min_total_staff'
+    ) THEN
+        ALTER TABLE branch_type_constraints DROP COLUMN min_total_staff;
+    END IF;
+END $$;
+
+-- createClinicWidePreferencesTable
+-- Create enum type for criteria types
+DO $$ 
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'clinic_preference_criteria_type') THEN
+        CREATE TYPE clinic_preference_criteria_type AS ENUM (
+            'skin_revenue',
+            'laser_yag_revenue',
+            'iv_cases',
+            'slim_pen_cases',
+            'doctor_count'
+        );
+    END IF;
+END $$;
+
+CREATE TABLE IF NOT EXISTS clinic_wide_preferences (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    criteria_type clinic_preference_criteria_type NOT NULL,
+    criteria_name VARCHAR(100) NOT NULL,
+    min_value DECIMAL(15,2) NOT NULL CHECK (min_value >= 0),
+    max_value DECIMAL(15,2), -- NULL means no upper limit
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    display_order INTEGER NOT NULL DEFAULT 0,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    
+    -- Ensure max_value >= min_value if both are set (allows equality for doctor_count)
+    CONSTRAINT check_value_range CHECK (max_value IS NULL OR max_value >= min_value)
+);
+
+CREATE INDEX IF NOT EXISTS idx_clinic_preferences_type ON clinic_wide_preferences(criteria_type, is_active);
+CREATE INDEX IF NOT EXISTS idx_clinic_preferences_range ON clinic_wide_preferences(criteria_type, min_value, max_value);
+CREATE INDEX IF NOT EXISTS idx_clinic_preferences_display_order ON clinic_wide_preferences(criteria_type, display_order);
+
+-- updateClinicWidePreferencesConstraint
+DO $$ 
+BEGIN
+    -- Drop the old constraint if it exists
+    IF EXISTS (
+        SELECT 1 FROM information_schema.table_constraints 
+        WHERE constraint_name = 'check_value_range' 
+        AND table_name = 'clinic_wide_preferences'
+    ) THEN
+        ALTER TABLE clinic_wide_preferences DROP CONSTRAINT check_value_range;
+    END IF;
+    
+    -- Add the new constraint that allows equality (for doctor_count)
+    IF NOT EXISTS (
+        SELECT 1 FROM information_schema.table_constraints 
+        WHERE constraint_name = 'check_value_range' 
+        AND table_name = 'clinic_wide_preferences'
+    ) THEN
+        ALTER TABLE clinic_wide_preferences 
+        ADD CONSTRAINT check_value_range 
+        CHECK (max_value IS NULL OR max_value >= min_value);
+    END IF;
+END $$;
+
+-- createClinicPreferencePositionRequirementsTable
+CREATE TABLE IF NOT EXISTS clinic_preference_position_requirements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    preference_id UUID NOT NULL REFERENCES clinic_wide_preferences(id) ON DELETE CASCADE,
+    position_id UUID NOT NULL REFERENCES positions(id) ON DELETE CASCADE,
+    minimum_staff INTEGER NOT NULL CHECK (minimum_staff >= 0),
+    preferred_staff INTEGER NOT NULL CHECK (preferred_staff >= minimum_staff),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    
+    -- One requirement per position per preference
+    CONSTRAINT unique_position_per_preference UNIQUE (preference_id, position_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_preference_position_req ON clinic_preference_position_requirements(preference_id, position_id);
+CREATE INDEX IF NOT EXISTS idx_preference_position_req_position ON clinic_preference_position_requirements(position_id);
+CREATE INDEX IF NOT EXISTS idx_preference_position_req_active ON clinic_preference_position_requirements(preference_id, is_active);
+
+-- createSpecificPreferencesTable
+CREATE TABLE IF NOT EXISTS specific_preferences (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id UUID REFERENCES branches(id) ON DELETE CASCADE,
+    doctor_id UUID REFERENCES doctors(id) ON DELETE CASCADE,
+    day_of_week INTEGER CHECK (day_of_week >= 0 AND day_of_week <= 6),
+    preference_type VARCHAR(50) NOT NULL CHECK (preference_type IN ('position_count', 'staff_name')),
+    position_id UUID REFERENCES positions(id) ON DELETE CASCADE,
+    staff_count INTEGER CHECK (staff_count >= 1),
+    staff_id UUID REFERENCES staff(id) ON DELETE CASCADE,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    -- Ensure position_count has position_id and staff_count
+    CONSTRAINT chk_position_count CHECK (
+        (preference_type = 'position_count' AND position_id IS NOT NULL AND staff_count IS NOT NULL AND staff_id IS NULL) OR
+        (preference_type != 'position_count')
+    ),
+    -- Ensure staff_name has staff_id
+    CONSTRAINT chk_staff_name CHECK (
+        (preference_type = 'staff_name' AND staff_id IS NOT NULL AND position_id IS NULL AND staff_count IS NULL) OR
+        (preference_type != 'staff_name')
+    )
+);
+CREATE INDEX IF NOT EXISTS idx_specific_preferences_branch_id ON specific_preferences(branch_id);
+CREATE INDEX IF NOT EXISTS idx_specific_preferences_doctor_id ON specific_preferences(doctor_id);
+CREATE INDEX IF NOT EXISTS idx_specific_preferences_day_of_week ON specific_preferences(day_of_week);
+CREATE INDEX IF NOT EXISTS idx_specific_preferences_is_active ON specific_preferences(is_active);
+CREATE INDEX IF NOT EXISTS idx_specific_preferences_composite ON specific_preferences(branch_id, doctor_id, day_of_week, is_active);
+
+-- createRotationStaffBranchPositionsTable
+CREATE TABLE IF NOT EXISTS rotation_staff_branch_positions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    rotation_staff_id UUID NOT NULL REFERENCES staff(id) ON DELETE CASCADE,
+    branch_position_id UUID NOT NULL REFERENCES positions(id) ON DELETE CASCADE,
+    substitution_level INTEGER NOT NULL DEFAULT 2 CHECK (substitution_level BETWEEN 1 AND 3),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(rotation_staff_id, branch_position_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rotation_staff_branch_positions_staff ON rotation_staff_branch_positions(rotation_staff_id) WHERE is_active = true;
+CREATE INDEX IF NOT EXISTS idx_rotation_staff_branch_positions_position ON rotation_staff_branch_positions(branch_position_id) WHERE is_active = true;
+CREATE INDEX IF NOT EXISTS idx_rotation_staff_branch_positions_composite ON rotation_staff_branch_positions(rotation_staff_id, branch_position_id) WHERE is_active = true;
+
+-- addPerformanceIndexesStaffSchedules
+-- Indexes for staff_schedules table (CRITICAL - most queried table)
+-- Composite index for staff + date queries (most common query pattern)
+CREATE INDEX IF NOT EXISTS idx_staff_schedules_staff_date 
+ON staff_schedules(staff_id, date DESC);
+
+-- Composite index for branch + date + status queries
+CREATE INDEX IF NOT EXISTS idx_staff_schedules_branch_date_status 
+ON staff_schedules(branch_id, date, schedule_status) 
+WHERE schedule_status = 'working';
+
+-- Index for date queries (used for date range filtering)
+CREATE INDEX IF NOT EXISTS idx_staff_schedules_date 
+ON staff_schedules(date DESC);
+
+-- Composite index for date + status filtering
+CREATE INDEX IF NOT EXISTS idx_staff_schedules_date_status 
+ON staff_schedules(date DESC, schedule_status);
+
+-- addPerformanceIndexesPositionQuotas
+-- Indexes for position_quotas table
+-- Composite index for branch + active status queries
+CREATE INDEX IF NOT EXISTS idx_position_quotas_branch_active 
+ON position_quotas(branch_id, is_active) 
+WHERE is_active = true;
+
+-- Index for position lookups
+CREATE INDEX IF NOT EXISTS idx_position_quotas_position_active 
+ON position_quotas(position_id, is_active) 
+WHERE is_active = true;
+
+-- addPerformanceIndexesRotationAssignments
+-- Indexes for rotation_assignments table
+-- Composite index for branch + date queries
+CREATE INDEX IF NOT EXISTS idx_rotation_assignments_branch_date 
+ON rotation_assignments(branch_id, date DESC);
+
+-- Composite index for staff + date queries
+CREATE INDEX IF NOT EXISTS idx_rotation_assignments_staff_date 
+ON rotation_assignments(rotation_staff_id, date DESC);
+
+-- Index for date queries
+CREATE INDEX IF NOT EXISTS idx_rotation_assignments_date 
+ON rotation_assignments(date DESC);
+
+-- addPerformanceIndexesBranchConstraints
+-- Indexes for branch_constraints table
+-- Composite index for branch + day_of_week lookups
+CREATE INDEX IF NOT EXISTS idx_branch_constraints_branch_day 
+ON branch_constraints(branch_id, day_of_week);
+
+-- Index for day_of_week queries
+CREATE INDEX IF NOT EXISTS idx_branch_constraints_day 
+ON branch_constraints(day_of_week);
+
+-- addPerformanceIndexesStaff
+-- Indexes for staff table
+-- Composite index for branch + position + type queries
+CREATE INDEX IF NOT EXISTS idx_staff_branch_position_type 
+ON staff(branch_id, position_id, staff_type) 
+WHERE staff_type = 'branch';
+
+-- Index for rotation staff queries
+CREATE INDEX IF NOT EXISTS idx_staff_type_position 
+ON staff(staff_type, position_id) 
+WHERE staff_type = 'rotation';
+
+-- Index for branch staff lookups
+CREATE INDEX IF NOT EXISTS idx_staff_branch_type 
+ON staff(branch_id, staff_type) 
+WHERE staff_type = 'branch';
+
+-- addPerformanceIndexesDoctorAssignments
+-- Indexes for doctor_assignments table
+-- Composite index for branch + date queries
+CREATE INDEX IF NOT EXISTS idx_doctor_assignments_branch_date 
+ON doctor_assignments(branch_id, date DESC);
+
+-- Composite index for doctor + date queries
+CREATE INDEX IF NOT EXISTS idx_doctor_assignments_doctor_date 
+ON doctor_assignments(doctor_id, date DESC);
+
+-- Index for date queries  
+CREATE INDEX IF NOT EXISTS idx_doctor_assignments_date 
+ON doctor_assignments(date DESC);
+
+-- createBranchQuotaDailySummaryTable
+-- Branch quota daily summary table
+CREATE TABLE IF NOT EXISTS branch_quota_daily_summary (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id UUID NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    
+    -- Aggregated counts
+    total_designated INTEGER NOT NULL DEFAULT 0,
+    total_available INTEGER NOT NULL DEFAULT 0,
+    total_assigned INTEGER NOT NULL DEFAULT 0,
+    total_required INTEGER NOT NULL DEFAULT 0,
+    
+    -- Group scores
+    group1_score INTEGER NOT NULL DEFAULT 0,
+    group2_score INTEGER NOT NULL DEFAULT 0,
+    group3_score INTEGER NOT NULL DEFAULT 0,
+    
+    -- Missing staff (JSONB for flexibility)
+    group1_missing_staff JSONB DEFAULT '[]'::jsonb,
+    group2_missing_staff JSONB DEFAULT '[]'::jsonb,
+    group3_missing_staff JSONB DEFAULT '[]'::jsonb,
+    
+    -- Metadata
+    calculated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    
+    UNIQUE(branch_id, date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quota_summary_branch_date 
+ON branch_quota_daily_summary(branch_id, date DESC);
+
+CREATE INDEX IF NOT EXISTS idx_quota_summary_date 
+ON branch_quota_daily_summary(date DESC);
+
+CREATE INDEX IF NOT EXISTS idx_quota_summary_branch 
+ON branch_quota_daily_summary(branch_id);
+
+-- createPositionQuotaDailySummaryTable
+-- Position quota daily summary table
+CREATE TABLE IF NOT EXISTS position_quota_daily_summary (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id UUID NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    position_id UUID NOT NULL REFERENCES positions(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    
+    designated_quota INTEGER NOT NULL DEFAULT 0,
+    minimum_required INTEGER NOT NULL DEFAULT 0,
+    available_local INTEGER NOT NULL DEFAULT 0,
+    assigned_rotation INTEGER NOT NULL DEFAULT 0,
+    total_assigned INTEGER NOT NULL DEFAULT 0,
+    still_required INTEGER NOT NULL DEFAULT 0,
+    
+    calculated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    
+    UNIQUE(branch_id, position_id, date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_position_summary_branch_date 
+ON position_quota_daily_summary(branch_id, date DESC);
+
+CREATE INDEX IF NOT EXISTS idx_position_summary_position_date 
+ON position_quota_daily_summary(position_id, date DESC);
+
+CREATE INDEX IF NOT EXISTS idx_position_summary_date 
+ON position_quota_daily_summary(date DESC);
+
+-- createRecalculateBranchQuotaSummaryFunction
+-- Function to recalculate branch quota summary
+CREATE OR REPLACE FUNCTION recalculate_branch_quota_summary(
+    p_branch_id UUID,
+    p_date DATE
+)
+RETURNS void AS $$
+DECLARE
+    v_total_designated INTEGER := 0;
+    v_total_available INTEGER := 0;
+    v_total_assigned INTEGER := 0;
+    v_total_required INTEGER := 0;
+    v_group1_score INTEGER := 0;
+    v_group2_score INTEGER := 0;
+    v_group3_score INTEGER := 0;
+    v_group1_missing JSONB := '[]'::jsonb;
+    v_group2_missing JSONB := '[]'::jsonb;
+    v_group3_missing JSONB := '[]'::jsonb;
+    v_position_record RECORD;
+    v_staff_record RECORD;
+    v_rotation_count INTEGER;
+    v_branch_count INTEGER;
+    v_shortage INTEGER;
+    v_excess INTEGER;
+    v_total_assigned_pos INTEGER;
+    v_still_required_pos INTEGER;
+    v_excess_pos INTEGER;
+BEGIN
+    -- Calculate position-level summaries
+    FOR v_position_record IN
+        SELECT 
+            pq.position_id,
+            pq.designated_quota,
+            pq.minimum_required,
+            COUNT(DISTINCT CASE 
+                WHEN s.staff_type = 'branch' 
+                AND ss.schedule_status = 'working' 
+                THEN s.id 
+            END) AS available_local,
+            COUNT(DISTINCT ra.rotation_staff_id) AS assigned_rotation
+        FROM position_quotas pq
+        LEFT JOIN staff s ON s.branch_id = pq.branch_id 
+            AND s.position_id = pq.position_id 
+            AND s.staff_type = 'branch'
+        LEFT JOIN staff_schedules ss ON ss.staff_id = s.id 
+            AND ss.date = p_date
+        LEFT JOIN rotation_assignments ra ON ra.branch_id = pq.branch_id 
+            AND ra.date = p_date
+        LEFT JOIN staff rs ON rs.id = ra.rotation_staff_id 
+            AND rs.position_id = pq.position_id
+        WHERE pq.branch_id = p_branch_id 
+            AND pq.is_active = true
+        GROUP BY pq.position_id, pq.designated_quota, pq.minimum_required
+    LOOP
+        v_total_assigned_pos := v_position_record.available_local + v_position_record.assigned_rotation;
+        v_still_required_pos := GREATEST(0, v_position_record.minimum_required - v_total_assigned_pos);
+        
+        -- Update position summary
+        INSERT INTO position_quota_daily_summary (
+            branch_id, position_id, date,
+            designated_quota, minimum_required,
+            available_local, assigned_rotation, total_assigned, still_required,
+            calculated_at
+        ) VALUES (
+            p_branch_id, v_position_record.position_id, p_date,
+            v_position_record.designated_quota, v_position_record.minimum_required,
+            v_position_record.available_local, v_position_record.assigned_rotation,
+            v_total_assigned_pos, v_still_required_pos,
+            CURRENT_TIMESTAMP
+        )
+        ON CONFLICT (branch_id, position_id, date) 
+        DO UPDATE SET
+            designated_quota = EXCLUDED.designated_quota,
+            minimum_required = EXCLUDED.minimum_required,
+            available_local = EXCLUDED.available_local,
+            assigned_rotation = EXCLUDED.assigned_rotation,
+            total_assigned = EXCLUDED.total_assigned,
+            still_required = EXCLUDED.still_required,
+            calculated_at = CURRENT_TIMESTAMP;
+        
+        -- Accumulate totals
+        v_total_designated := v_total_designated + v_position_record.designated_quota;
+        v_total_available := v_total_available + v_position_record.available_local;
+        v_total_assigned := v_total_assigned + v_total_assigned_pos;
+        v_total_required := v_total_required + v_still_required_pos;
+        
+        -- Group 2 score (Position Quota - Minimum Shortage)
+        IF v_still_required_pos > 0 THEN
+            v_group2_score := v_group2_score - v_still_required_pos;
+        END IF;
+        
+        -- Group 3 score (Position Quota - Preferred Excess)
+        v_excess_pos := v_total_assigned_pos - v_position_record.designated_quota;
+        IF v_excess_pos > 0 THEN
+            v_group3_score := v_group3_score + v_excess_pos;
+        END IF;
+    END LOOP;
+    
+    -- Calculate Group 1 score (Daily Staff Constraints - Minimum Shortage)
+    -- This is simplified - full calculation would require staff group logic
+    -- For now, we'll calculate it based on missing staff from schedules
+    SELECT 
+        COALESCE(SUM(CASE 
+            WHEN ss.schedule_status != 'working' THEN 1 
+            ELSE 0 
+        END), 0) INTO v_group1_score
+    FROM staff s
+    LEFT JOIN staff_schedules ss ON ss.staff_id = s.id AND ss.date = p_date
+    WHERE s.branch_id = p_branch_id AND s.staff_type = 'branch';
+    
+    -- Update branch summary
+    INSERT INTO branch_quota_daily_summary (
+        branch_id, date,
+        total_designated, total_available, total_assigned, total_required,
+        group1_score, group2_score, group3_score,
+        group1_missing_staff, group2_missing_staff, group3_missing_staff,
+        calculated_at, updated_at
+    ) VALUES (
+        p_branch_id, p_date,
+        v_total_designated, v_total_available, v_total_assigned, v_total_required,
+        v_group1_score, v_group2_score, v_group3_score,
+        v_group1_missing, v_group2_missing, v_group3_missing,
+        CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+    )
+    ON CONFLICT (branch_id, date) 
+    DO UPDATE SET
+        total_designated = EXCLUDED.total_designated,
+        total_available = EXCLUDED.total_available,
+        total_assigned = EXCLUDED.total_assigned,
+        total_required = EXCLUDED.total_required,
+        group1_score = EXCLUDED.group1_score,
+        group2_score = EXCLUDED.group2_score,
+        group3_score = EXCLUDED.group3_score,
+        group1_missing_staff = EXCLUDED.group1_missing_staff,
+        group2_missing_staff = EXCLUDED.group2_missing_staff,
+        group3_missing_staff = EXCLUDED.group3_missing_staff,
+        updated_at = CURRENT_TIMESTAMP;
+END;
+$$ LANGUAGE plpgsql;
+
+-- createQuotaSummaryTriggers
+-- Triggers to automatically update summaries when data changes
+-- Trigger function for schedule changes
+CREATE OR REPLACE FUNCTION update_quota_summary_on_schedule_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM recalculate_branch_quota_summary(
+        COALESCE(NEW.branch_id, OLD.branch_id),
+        COALESCE(NEW.date, OLD.date)
+    );
+    RETURN COALESCE(NEW, OLD);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Trigger for staff_schedules changes
+DROP TRIGGER IF EXISTS schedule_change_quota_update ON staff_schedules;
+CREATE TRIGGER schedule_change_quota_update
+AFTER INSERT OR UPDATE OR DELETE ON staff_schedules
+FOR EACH ROW
+EXECUTE FUNCTION update_quota_summary_on_schedule_change();
+
+-- Trigger function for rotation assignment changes
+CREATE OR REPLACE FUNCTION update_quota_summary_on_rotation_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM recalculate_branch_quota_summary(
+        COALESCE(NEW.branch_id, OLD.branch_id),
+        COALESCE(NEW.date, OLD.date)
+    );
+    RETURN COALESCE(NEW, OLD);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Trigger for rotation_assignments changes
+DROP TRIGGER IF EXISTS rotation_change_quota_update ON rotation_assignments;
+CREATE TRIGGER rotation_change_quota_update
+AFTER INSERT OR UPDATE OR DELETE ON rotation_assignments
+FOR EACH ROW
+EXECUTE FUNCTION update_quota_summary_on_rotation_change();
+
+-- Trigger function for position quota changes
+CREATE OR REPLACE FUNCTION update_quota_summary_on_quota_change()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_date DATE;
+BEGIN
+    -- Recalculate for next 30 days
+    FOR v_date IN 
+        SELECT generate_series(CURRENT_DATE, CURRENT_DATE + INTERVAL '30 days', INTERVAL '1 day')::DATE
+    LOOP
+        PERFORM recalculate_branch_quota_summary(
+            COALESCE(NEW.branch_id, OLD.branch_id),
+            v_date
+        );
+    END LOOP;
+    RETURN COALESCE(NEW, OLD);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Trigger for position_quotas changes
+DROP TRIGGER IF EXISTS quota_change_summary_update ON position_quotas;
+CREATE TRIGGER quota_change_summary_update
+AFTER INSERT OR UPDATE OR DELETE ON position_quotas
+FOR EACH ROW
+EXECUTE FUNCTION update_quota_summary_on_quota_change();
+
+-- populateInitialQuotaSummaries
+-- Populate initial quota summaries for next 30 days for all branches
+DO $$
+DECLARE
+    v_branch RECORD;
+    v_date DATE;
+BEGIN
+    -- Populate summaries for next 30 days for all active branches
+    FOR v_branch IN SELECT id FROM branches
+    LOOP
+        FOR v_date IN 
+            SELECT generate_series(CURRENT_DATE, CURRENT_DATE + INTERVAL '30 days', INTERVAL '1 day')::DATE
+        LOOP
+            -- Only calculate if summary doesn't exist
+            IF NOT EXISTS (
+                SELECT 1 FROM branch_quota_daily_summary 
+                WHERE branch_id = v_branch.id AND date = v_date
+            ) THEN
+                PERFORM recalculate_branch_quota_summary(v_branch.id, v_date);
+            END IF;
+        END LOOP;
+    END LOOP;
+END $$;
+
+-- createBranchQuotaStatusMaterializedView
+-- Materialized view for branch quota status (30-day rolling window)
+-- Drop existing view if it exists
+DROP MATERIALIZED VIEW IF EXISTS branch_quota_status_cache CASCADE;
+
+-- Create materialized view with 30-day rolling window
+CREATE MATERIALIZED VIEW branch_quota_status_cache AS
+SELECT 
+    bqs.branch_id,
+    bqs.date,
+    bqs.total_designated,
+    bqs.total_available,
+    bqs.total_assigned,
+    bqs.total_required,
+    bqs.group1_score,
+    bqs.group2_score,
+    bqs.group3_score,
+    bqs.group1_missing_staff,
+    bqs.group2_missing_staff,
+    bqs.group3_missing_staff,
+    bqs.calculated_at,
+    bqs.updated_at,
+    b.name AS branch_name,
+    b.code AS branch_code
+FROM branch_quota_daily_summary bqs
+INNER JOIN branches b ON b.id = bqs.branch_id
+WHERE bqs.date >= CURRENT_DATE - INTERVAL '30 days'
+    AND bqs.date <= CURRENT_DATE + INTERVAL '30 days';
+
+-- Create indexes on materialized view for fast queries
+CREATE INDEX idx_quota_cache_branch_date 
+ON branch_quota_status_cache(branch_id, date DESC);
+
+CREATE INDEX idx_quota_cache_date 
+ON branch_quota_status_cache(date DESC);
+
+CREATE INDEX idx_quota_cache_branch 
+ON branch_quota_status_cache(branch_id);
+
+-- Create unique index for fast lookups
+CREATE UNIQUE INDEX idx_quota_cache_branch_date_unique 
+ON branch_quota_status_cache(branch_id, date);
+
+-- createRefreshQuotaCacheFunction
+-- Function to refresh materialized view (can be called via cron)
+CREATE OR REPLACE FUNCTION refresh_branch_quota_cache()
+RETURNS void AS $$
+DECLARE
+    v_branch RECORD;
+    v_date DATE;
+BEGIN
+    -- Refresh the materialized view
+    -- This will rebuild it with current data from branch_quota_daily_summary
+    REFRESH MATERIALIZED VIEW CONCURRENTLY branch_quota_status_cache;
+    
+    -- Also ensure summaries exist for the next 30 days
+    FOR v_branch IN SELECT id FROM branches
+    LOOP
+        FOR v_date IN 
+            SELECT generate_series(CURRENT_DATE, CURRENT_DATE + INTERVAL '30 days', INTERVAL '1 day')::DATE
+        LOOP
+            -- Only calculate if summary doesn't exist
+            IF NOT EXISTS (
+                SELECT 1 FROM branch_quota_daily_summary 
+                WHERE branch_id = v_branch.id AND date = v_date
+            ) THEN
+                PERFORM recalculate_branch_quota_summary(v_branch.id, v_date);
+            END IF;
+        END LOOP;
+    END LOOP;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Create a simpler refresh function that just refreshes the view
+CREATE OR REPLACE FUNCTION refresh_quota_cache_simple()
+RETURNS void AS $$
+BEGIN
+    REFRESH MATERIALIZED VIEW CONCURRENTLY branch_quota_status_cache;
+END;
+$$ LANGUAGE plpgsql;
+
+-- addBranchesAreaManagerForeignKey
+-- Foreign key from branches to users (circular dependency resolution)
+DO $$ 
+BEGIN
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.table_constraints 
+		WHERE constraint_name = 'branches_area_manager_id_fkey' 
+		AND table_name = 'branches'
+	) THEN
+		ALTER TABLE branches 
+		ADD CONSTRAINT branches_area_manager_id_fkey 
+		FOREIGN KEY (area_manager_id) REFERENCES users(id);
+	END IF;
+END $$;
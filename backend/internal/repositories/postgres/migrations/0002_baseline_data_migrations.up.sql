@@ -0,0 +1,324 @@
+-- Baseline data migrations: column additions and data fixes formerly run by runDataMigrations on every start.
+
+-- Add nickname column to staff table if it doesn't exist
+DO $$ 
+BEGIN
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'staff' AND column_name = 'nickname'
+	) THEN
+		ALTER TABLE staff ADD COLUMN nickname VARCHAR(100);
+	END IF;
+END $$;
+
+-- Add skill_level column to staff table if it doesn't exist
+DO $$ 
+BEGIN
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'staff' AND column_name = 'skill_level'
+	) THEN
+		ALTER TABLE staff ADD COLUMN skill_level INTEGER DEFAULT 5 CHECK (skill_level >= 0 AND skill_level <= 10);
+	END IF;
+END $$;
+
+-- Add area_of_operation_id column to staff table if it doesn't exist
+DO $$ 
+BEGIN
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'staff' AND column_name = 'area_of_operation_id'
+	) THEN
+		ALTER TABLE staff ADD COLUMN area_of_operation_id UUID REFERENCES areas_of_operation(id);
+	END IF;
+END $$;
+
+-- Add zone_id column to staff table if it doesn't exist
+DO $$ 
+BEGIN
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'staff' AND column_name = 'zone_id'
+	) THEN
+		ALTER TABLE staff ADD COLUMN zone_id UUID REFERENCES zones(id);
+	END IF;
+END $$;
+
+-- Add position_type column to positions table if it doesn't exist
+DO $$ 
+BEGIN
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'positions' AND column_name = 'position_type'
+	) THEN
+		ALTER TABLE positions ADD COLUMN position_type VARCHAR(20) NOT NULL DEFAULT 'branch' CHECK (position_type IN ('branch', 'rotation'));
+	END IF;
+END $$;
+
+-- Update existing rotation positions based on name patterns and specific position IDs
+UPDATE positions 
+SET position_type = 'rotation' 
+WHERE (
+	name LIKE '%วนสาขา%' 
+	OR name LIKE '%Rotation%' 
+	OR name ILIKE '%rotation%'
+	OR id IN (
+		'10000000-0000-0000-0000-000000000019', -- Front+ล่ามวนสาขา
+		'10000000-0000-0000-0000-000000000022', -- ผู้จัดการเขต
+		'10000000-0000-0000-0000-000000000023', -- ผู้จัดการแผนกและกำกับพัฒนาระเบียบสาขา
+		'10000000-0000-0000-0000-000000000024', -- หัวหน้าผู้ช่วยแพทย์
+		'10000000-0000-0000-0000-000000000025', -- ผู้ช่วยพิเศษ
+		'10000000-0000-0000-0000-000000000026', -- ผู้ช่วยแพทย์วนสาขา
+		'10000000-0000-0000-0000-000000000027'  -- ฟร้อนท์วนสาขา
+	)
+)
+AND position_type = 'branch';
+
+-- Add manpower_type column to positions table if it doesn't exist
+DO $$ 
+BEGIN
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'positions' AND column_name = 'manpower_type'
+	) THEN
+		ALTER TABLE positions ADD COLUMN manpower_type VARCHAR(50) NOT NULL DEFAULT 'อื่นๆ' CHECK (manpower_type IN ('พนักงานฟร้อนท์', 'ผู้ช่วยแพทย์', 'อื่นๆ', 'ทำความสะอาด'));
+	END IF;
+END $$;
+
+-- Update existing positions with appropriate manpower_type based on name patterns
+UPDATE positions 
+SET manpower_type = CASE
+	WHEN name LIKE '%ฟร้อนท์%' OR name LIKE '%Front%' OR name LIKE '%ต้อนรับ%' OR name LIKE '%Receptionist%' THEN 'พนักงานฟร้อนท์'
+	WHEN name LIKE '%ผู้ช่วยแพทย์%' OR name LIKE '%Doctor Assistant%' OR name LIKE '%พยาบาล%' OR name LIKE '%Nurse%' OR name LIKE '%Physiotherapist%' THEN 'ผู้ช่วยแพทย์'
+	WHEN name LIKE '%แม่บ้าน%' OR name LIKE '%Housekeeper%' OR name LIKE '%ทำความสะอาด%' THEN 'ทำความสะอาด'
+	ELSE 'อื่นๆ'
+END
+WHERE manpower_type = 'อื่นๆ';
+
+-- Add schedule_status column to staff_schedules table if it doesn't exist
+DO $$ 
+BEGIN
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'staff_schedules' AND column_name = 'schedule_status'
+	) THEN
+		ALTER TABLE staff_schedules ADD COLUMN schedule_status VARCHAR(20) DEFAULT 'off' CHECK (schedule_status IN ('working', 'off', 'leave', 'sick_leave'));
+		-- Migrate existing data: convert is_working_day to schedule_status
+		UPDATE staff_schedules SET schedule_status = CASE 
+			WHEN is_working_day = true THEN 'working' 
+			ELSE 'off' 
+		END;
+	END IF;
+END $$;
+
+-- Add is_adhoc and adhoc_reason columns to rotation_assignments table if they don't exist
+DO $$ 
+BEGIN
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'rotation_assignments' AND column_name = 'is_adhoc'
+	) THEN
+		ALTER TABLE rotation_assignments ADD COLUMN is_adhoc BOOLEAN DEFAULT false;
+	END IF;
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'rotation_assignments' AND column_name = 'adhoc_reason'
+	) THEN
+		ALTER TABLE rotation_assignments ADD COLUMN adhoc_reason TEXT;
+	END IF;
+END $$;
+
+-- Add travel parameters columns to effective_branches table if they don't exist
+DO $$ 
+BEGIN
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'effective_branches' AND column_name = 'commute_duration_minutes'
+	) THEN
+		ALTER TABLE effective_branches ADD COLUMN commute_duration_minutes INTEGER DEFAULT 300;
+	END IF;
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'effective_branches' AND column_name = 'transit_count'
+	) THEN
+		ALTER TABLE effective_branches ADD COLUMN transit_count INTEGER DEFAULT 10;
+	END IF;
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'effective_branches' AND column_name = 'travel_cost'
+	) THEN
+		ALTER TABLE effective_branches ADD COLUMN travel_cost DECIMAL(10,2) DEFAULT 1000.00;
+	END IF;
+END $$;
+
+-- Update CHECK constraint to include 'sick_leave' if it doesn't already include it
+DO $$ 
+BEGIN
+	-- Drop existing constraint if it exists and doesn't include 'sick_leave'
+	IF EXISTS (
+		SELECT 1 FROM information_schema.check_constraints 
+		WHERE constraint_name LIKE 'staff_schedules_schedule_status_check%'
+		AND constraint_schema = 'public'
+	) THEN
+		-- Check if constraint needs updating (doesn't include 'sick_leave')
+		IF NOT EXISTS (
+			SELECT 1 FROM information_schema.check_constraints 
+			WHERE constraint_name LIKE 'staff_schedules_schedule_status_check%'
+			AND constraint_schema = 'public'
+			AND check_clause LIKE '%sick_leave%'
+		) THEN
+			ALTER TABLE staff_schedules DROP CONSTRAINT IF EXISTS staff_schedules_schedule_status_check;
+			ALTER TABLE staff_schedules ADD CONSTRAINT staff_schedules_schedule_status_check 
+				CHECK (schedule_status IN ('working', 'off', 'leave', 'sick_leave'));
+		END IF;
+	END IF;
+END $$;
+
+-- Add position_code column to positions table if it doesn't exist
+DO $$ 
+BEGIN
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'positions' AND column_name = 'position_code'
+	) THEN
+		ALTER TABLE positions ADD COLUMN position_code VARCHAR(20) UNIQUE;
+	END IF;
+END $$;
+
+-- Add display_order column to positions table if it doesn't exist
+DO $$ 
+BEGIN
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'positions' AND column_name = 'display_order'
+	) THEN
+		ALTER TABLE positions ADD COLUMN display_order INTEGER DEFAULT 999;
+		-- Set default display_order values for existing positions
+		-- Branch Manager gets 1 (highest priority)
+		UPDATE positions SET display_order = 1 WHERE name = 'Branch Manager' OR name = 'ผู้จัดการสาขา';
+		-- Assistant Branch Manager gets 2
+		UPDATE positions SET display_order = 2 WHERE name = 'Assistant Branch Manager' OR name = 'รองผู้จัดการสาขา' OR name = 'ผู้ช่วยผู้จัดการสาขา';
+		-- Other positions get incremental values starting from 10
+		UPDATE positions SET display_order = 10 WHERE display_order = 999 AND (name LIKE '%Manager%' OR name LIKE '%ผู้จัดการ%');
+		UPDATE positions SET display_order = 20 WHERE display_order = 999 AND (name LIKE '%Doctor%' OR name LIKE '%แพทย์%');
+		UPDATE positions SET display_order = 30 WHERE display_order = 999 AND (name LIKE '%Nurse%' OR name LIKE '%พยาบาล%');
+		UPDATE positions SET display_order = 40 WHERE display_order = 999 AND (name LIKE '%Receptionist%' OR name LIKE '%ต้อนรับ%');
+		UPDATE positions SET display_order = 50 WHERE display_order = 999 AND (name LIKE '%Coordinator%' OR name LIKE '%ประสานงาน%');
+		-- Set remaining positions to 100+
+		UPDATE positions SET display_order = 100 + ROW_NUMBER() OVER (ORDER BY name) WHERE display_order = 999;
+	END IF;
+END $$;
+
+-- Drop address and expected_revenue columns from branches table if they exist
+DO $$ 
+BEGIN
+	IF EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'branches' AND column_name = 'address'
+	) THEN
+		ALTER TABLE branches DROP COLUMN address;
+	END IF;
+
+	IF EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'branches' AND column_name = 'expected_revenue'
+	) THEN
+		ALTER TABLE branches DROP COLUMN expected_revenue;
+	END IF;
+END $$;
+
+-- Add min_doctor_assistant column to branch_constraints table if it doesn't exist
+DO $$ 
+BEGIN
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'branch_constraints' AND column_name = 'min_doctor_assistant'
+	) THEN
+		ALTER TABLE branch_constraints ADD COLUMN min_doctor_assistant INTEGER NOT NULL DEFAULT 0 CHECK (min_doctor_assistant >= 0);
+	END IF;
+END $$;
+
+-- Add revenue type columns to revenue_data and branch_weekly_revenue tables
+-- addRevenueTypeColumns adds 4 new columns to revenue_data and branch_weekly_revenue tables
+DO $$ 
+BEGIN
+	-- Add columns to revenue_data table if they don't exist
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'revenue_data' AND column_name = 'skin_revenue'
+	) THEN
+		ALTER TABLE revenue_data ADD COLUMN skin_revenue DECIMAL(15,2) DEFAULT 0;
+	END IF;
+
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'revenue_data' AND column_name = 'ls_hm_revenue'
+	) THEN
+		ALTER TABLE revenue_data ADD COLUMN ls_hm_revenue DECIMAL(15,2) DEFAULT 0;
+	END IF;
+
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'revenue_data' AND column_name = 'vitamin_cases'
+	) THEN
+		ALTER TABLE revenue_data ADD COLUMN vitamin_cases INTEGER DEFAULT 0;
+	END IF;
+
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'revenue_data' AND column_name = 'slim_pen_cases'
+	) THEN
+		ALTER TABLE revenue_data ADD COLUMN slim_pen_cases INTEGER DEFAULT 0;
+	END IF;
+
+	-- Add columns to branch_weekly_revenue table if they don't exist
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'branch_weekly_revenue' AND column_name = 'skin_revenue'
+	) THEN
+		ALTER TABLE branch_weekly_revenue ADD COLUMN skin_revenue DECIMAL(15,2) DEFAULT 0;
+	END IF;
+
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'branch_weekly_revenue' AND column_name = 'ls_hm_revenue'
+	) THEN
+		ALTER TABLE branch_weekly_revenue ADD COLUMN ls_hm_revenue DECIMAL(15,2) DEFAULT 0;
+	END IF;
+
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'branch_weekly_revenue' AND column_name = 'vitamin_cases'
+	) THEN
+		ALTER TABLE branch_weekly_revenue ADD COLUMN vitamin_cases INTEGER DEFAULT 0;
+	END IF;
+
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'branch_weekly_revenue' AND column_name = 'slim_pen_cases'
+	) THEN
+		ALTER TABLE branch_weekly_revenue ADD COLUMN slim_pen_cases INTEGER DEFAULT 0;
+	END IF;
+END $$;
+
+-- Add revenue_source column to revenue_data table if it doesn't exist
+DO $$ 
+BEGIN
+	IF NOT EXISTS (
+		SELECT 1 FROM information_schema.columns 
+		WHERE table_name = 'revenue_data' AND column_name = 'revenue_source'
+	) THEN
+		ALTER TABLE revenue_data ADD COLUMN revenue_source VARCHAR(20) DEFAULT 'branch' CHECK (revenue_source IN ('branch', 'doctor', 'excel'));
+	END IF;
+END $$;
+
+-- Migrate existing expected_revenue to skin_revenue
+-- migrateRevenueDataToSkinRevenue migrates existing expected_revenue to skin_revenue
+UPDATE revenue_data 
+SET skin_revenue = expected_revenue 
+WHERE expected_revenue > 0 AND (skin_revenue = 0 OR skin_revenue IS NULL);
+
+-- migrateBranchWeeklyRevenueToSkinRevenue
+UPDATE branch_weekly_revenue 
+SET skin_revenue = expected_revenue 
+WHERE expected_revenue > 0 AND (skin_revenue = 0 OR skin_revenue IS NULL);
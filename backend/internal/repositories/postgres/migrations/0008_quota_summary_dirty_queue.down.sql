@@ -0,0 +1,241 @@
+DROP TRIGGER IF EXISTS schedule_change_quota_update ON staff_schedules;
+DROP TRIGGER IF EXISTS rotation_change_quota_update ON rotation_assignments;
+DROP TRIGGER IF EXISTS quota_change_summary_update ON position_quotas;
+DROP FUNCTION IF EXISTS mark_quota_summary_dirty_on_row_change();
+DROP FUNCTION IF EXISTS mark_quota_summary_dirty_on_quota_change();
+DROP FUNCTION IF EXISTS mark_quota_summary_dirty(UUID, DATE, DATE);
+DROP TABLE IF EXISTS quota_summary_dirty;
+
+-- createRecalculateBranchQuotaSummaryFunction
+-- Function to recalculate branch quota summary
+CREATE OR REPLACE FUNCTION recalculate_branch_quota_summary(
+    p_branch_id UUID,
+    p_date DATE
+)
+RETURNS void AS $$
+DECLARE
+    v_total_designated INTEGER := 0;
+    v_total_available INTEGER := 0;
+    v_total_assigned INTEGER := 0;
+    v_total_required INTEGER := 0;
+    v_group1_score INTEGER := 0;
+    v_group2_score INTEGER := 0;
+    v_group3_score INTEGER := 0;
+    v_group1_missing JSONB := '[]'::jsonb;
+    v_group2_missing JSONB := '[]'::jsonb;
+    v_group3_missing JSONB := '[]'::jsonb;
+    v_position_record RECORD;
+    v_staff_record RECORD;
+    v_rotation_count INTEGER;
+    v_branch_count INTEGER;
+    v_shortage INTEGER;
+    v_excess INTEGER;
+    v_total_assigned_pos INTEGER;
+    v_still_required_pos INTEGER;
+    v_excess_pos INTEGER;
+BEGIN
+    -- Calculate position-level summaries
+    FOR v_position_record IN
+        SELECT 
+            pq.position_id,
+            pq.designated_quota,
+            pq.minimum_required,
+            COUNT(DISTINCT CASE 
+                WHEN s.staff_type = 'branch' 
+                AND ss.schedule_status = 'working' 
+                THEN s.id 
+            END) AS available_local,
+            COUNT(DISTINCT ra.rotation_staff_id) AS assigned_rotation
+        FROM position_quotas pq
+        LEFT JOIN staff s ON s.branch_id = pq.branch_id 
+            AND s.position_id = pq.position_id 
+            AND s.staff_type = 'branch'
+        LEFT JOIN staff_schedules ss ON ss.staff_id = s.id 
+            AND ss.date = p_date
+        LEFT JOIN rotation_assignments ra ON ra.branch_id = pq.branch_id 
+            AND ra.date = p_date
+        LEFT JOIN staff rs ON rs.id = ra.rotation_staff_id 
+            AND rs.position_id = pq.position_id
+        WHERE pq.branch_id = p_branch_id 
+            AND pq.is_active = true
+        GROUP BY pq.position_id, pq.designated_quota, pq.minimum_required
+    LOOP
+        v_total_assigned_pos := v_position_record.available_local + v_position_record.assigned_rotation;
+        v_still_required_pos := GREATEST(0, v_position_record.minimum_required - v_total_assigned_pos);
+        
+        -- Update position summary
+        INSERT INTO position_quota_daily_summary (
+            branch_id, position_id, date,
+            designated_quota, minimum_required,
+            available_local, assigned_rotation, total_assigned, still_required,
+            calculated_at
+        ) VALUES (
+            p_branch_id, v_position_record.position_id, p_date,
+            v_position_record.designated_quota, v_position_record.minimum_required,
+            v_position_record.available_local, v_position_record.assigned_rotation,
+            v_total_assigned_pos, v_still_required_pos,
+            CURRENT_TIMESTAMP
+        )
+        ON CONFLICT (branch_id, position_id, date) 
+        DO UPDATE SET
+            designated_quota = EXCLUDED.designated_quota,
+            minimum_required = EXCLUDED.minimum_required,
+            available_local = EXCLUDED.available_local,
+            assigned_rotation = EXCLUDED.assigned_rotation,
+            total_assigned = EXCLUDED.total_assigned,
+            still_required = EXCLUDED.still_required,
+            calculated_at = CURRENT_TIMESTAMP;
+        
+        -- Accumulate totals
+        v_total_designated := v_total_designated + v_position_record.designated_quota;
+        v_total_available := v_total_available + v_position_record.available_local;
+        v_total_assigned := v_total_assigned + v_total_assigned_pos;
+        v_total_required := v_total_required + v_still_required_pos;
+        
+        -- Group 2 score (Position Quota - Minimum Shortage)
+        IF v_still_required_pos > 0 THEN
+            v_group2_score := v_group2_score - v_still_required_pos;
+        END IF;
+        
+        -- Group 3 score (Position Quota - Preferred Excess)
+        v_excess_pos := v_total_assigned_pos - v_position_record.designated_quota;
+        IF v_excess_pos > 0 THEN
+            v_group3_score := v_group3_score + v_excess_pos;
+        END IF;
+    END LOOP;
+    
+    -- Calculate Group 1 score (Daily Staff Constraints - Minimum Shortage)
+    -- This is simplified - full calculation would require staff group logic
+    -- For now, we'll calculate it based on missing staff from schedules
+    SELECT 
+        COALESCE(SUM(CASE 
+            WHEN ss.schedule_status != 'working' THEN 1 
+            ELSE 0 
+        END), 0) INTO v_group1_score
+    FROM staff s
+    LEFT JOIN staff_schedules ss ON ss.staff_id = s.id AND ss.date = p_date
+    WHERE s.branch_id = p_branch_id AND s.staff_type = 'branch';
+    
+    -- Update branch summary
+    INSERT INTO branch_quota_daily_summary (
+        branch_id, date,
+        total_designated, total_available, total_assigned, total_required,
+        group1_score, group2_score, group3_score,
+        group1_missing_staff, group2_missing_staff, group3_missing_staff,
+        calculated_at, updated_at
+    ) VALUES (
+        p_branch_id, p_date,
+        v_total_designated, v_total_available, v_total_assigned, v_total_required,
+        v_group1_score, v_group2_score, v_group3_score,
+        v_group1_missing, v_group2_missing, v_group3_missing,
+        CURRENT_TIMESTAMP, CURRENT_TIMESTAMP
+    )
+    ON CONFLICT (branch_id, date) 
+    DO UPDATE SET
+        total_designated = EXCLUDED.total_designated,
+        total_available = EXCLUDED.total_available,
+        total_assigned = EXCLUDED.total_assigned,
+        total_required = EXCLUDED.total_required,
+        group1_score = EXCLUDED.group1_score,
+        group2_score = EXCLUDED.group2_score,
+        group3_score = EXCLUDED.group3_score,
+        group1_missing_staff = EXCLUDED.group1_missing_staff,
+        group2_missing_staff = EXCLUDED.group2_missing_staff,
+        group3_missing_staff = EXCLUDED.group3_missing_staff,
+        updated_at = CURRENT_TIMESTAMP;
+END;
+$$ LANGUAGE plpgsql;
+
+-- createQuotaSummaryTriggers
+-- Triggers to automatically update summaries when data changes
+-- Trigger function for schedule changes
+CREATE OR REPLACE FUNCTION update_quota_summary_on_schedule_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM recalculate_branch_quota_summary(
+        COALESCE(NEW.branch_id, OLD.branch_id),
+        COALESCE(NEW.date, OLD.date)
+    );
+    RETURN COALESCE(NEW, OLD);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Trigger for staff_schedules changes
+DROP TRIGGER IF EXISTS schedule_change_quota_update ON staff_schedules;
+CREATE TRIGGER schedule_change_quota_update
+AFTER INSERT OR UPDATE OR DELETE ON staff_schedules
+FOR EACH ROW
+EXECUTE FUNCTION update_quota_summary_on_schedule_change();
+
+-- Trigger function for rotation assignment changes
+CREATE OR REPLACE FUNCTION update_quota_summary_on_rotation_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM recalculate_branch_quota_summary(
+        COALESCE(NEW.branch_id, OLD.branch_id),
+        COALESCE(NEW.date, OLD.date)
+    );
+    RETURN COALESCE(NEW, OLD);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Trigger for rotation_assignments changes
+DROP TRIGGER IF EXISTS rotation_change_quota_update ON rotation_assignments;
+CREATE TRIGGER rotation_change_quota_update
+AFTER INSERT OR UPDATE OR DELETE ON rotation_assignments
+FOR EACH ROW
+EXECUTE FUNCTION update_quota_summary_on_rotation_change();
+
+-- Trigger function for position quota changes
+CREATE OR REPLACE FUNCTION update_quota_summary_on_quota_change()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_date DATE;
+BEGIN
+    -- Recalculate for next 30 days
+    FOR v_date IN 
+        SELECT generate_series(CURRENT_DATE, CURRENT_DATE + INTERVAL '30 days', INTERVAL '1 day')::DATE
+    LOOP
+        PERFORM recalculate_branch_quota_summary(
+            COALESCE(NEW.branch_id, OLD.branch_id),
+            v_date
+        );
+    END LOOP;
+    RETURN COALESCE(NEW, OLD);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Trigger for position_quotas changes
+DROP TRIGGER IF EXISTS quota_change_summary_update ON position_quotas;
+CREATE TRIGGER quota_change_summary_update
+AFTER INSERT OR UPDATE OR DELETE ON position_quotas
+FOR EACH ROW
+EXECUTE FUNCTION update_quota_summary_on_quota_change();
+
+CREATE OR REPLACE FUNCTION refresh_branch_quota_cache()
+RETURNS void AS $$
+DECLARE
+    v_branch RECORD;
+    v_date DATE;
+BEGIN
+    -- Refresh the materialized view
+    -- This will rebuild it with current data from branch_quota_daily_summary
+    REFRESH MATERIALIZED VIEW CONCURRENTLY branch_quota_status_cache;
+    
+    -- Also ensure summaries exist for the next 30 days
+    FOR v_branch IN SELECT id FROM branches
+    LOOP
+        FOR v_date IN 
+            SELECT generate_series(CURRENT_DATE, CURRENT_DATE + INTERVAL '30 days', INTERVAL '1 day')::DATE
+        LOOP
+            -- Only calculate if summary doesn't exist
+            IF NOT EXISTS (
+                SELECT 1 FROM branch_quota_daily_summary 
+                WHERE branch_id = v_branch.id AND date = v_date
+            ) THEN
+                PERFORM recalculate_branch_quota_summary(v_branch.id, v_date);
+            END IF;
+        END LOOP;
+    END LOOP;
+END;
+$$ LANGUAGE plpgsql;
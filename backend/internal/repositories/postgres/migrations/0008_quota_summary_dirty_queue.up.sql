@@ -0,0 +1,109 @@
+-- Quota summaries are recomputed in Go by the summary worker. Writes no longer
+-- recalculate them inline; triggers only queue the affected branch and date.
+CREATE TABLE IF NOT EXISTS quota_summary_dirty (
+    branch_id UUID NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    first_marked_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    marked_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (branch_id, date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quota_summary_dirty_marked_at
+ON quota_summary_dirty(marked_at);
+
+-- Queue every day of a branch between p_start and p_end. Marking again only
+-- moves marked_at, so the worker waits for writes to settle. Branches deleted
+-- in the same statement (cascading deletes) are skipped.
+CREATE OR REPLACE FUNCTION mark_quota_summary_dirty(
+    p_branch_id UUID,
+    p_start DATE,
+    p_end DATE
+)
+RETURNS void AS $$
+BEGIN
+    INSERT INTO quota_summary_dirty (branch_id, date)
+    SELECT b.id, d::DATE
+    FROM branches b
+    CROSS JOIN generate_series(p_start, p_end, INTERVAL '1 day') d
+    WHERE b.id = p_branch_id
+    ON CONFLICT (branch_id, date)
+    DO UPDATE SET marked_at = CURRENT_TIMESTAMP;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Schedules and rotation assignments queue the old and the new branch and date
+CREATE OR REPLACE FUNCTION mark_quota_summary_dirty_on_row_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM mark_quota_summary_dirty(OLD.branch_id, OLD.date, OLD.date);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM mark_quota_summary_dirty(NEW.branch_id, NEW.date, NEW.date);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Position quotas queue the next 30 days of the branch
+CREATE OR REPLACE FUNCTION mark_quota_summary_dirty_on_quota_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM mark_quota_summary_dirty(OLD.branch_id, CURRENT_DATE, CURRENT_DATE + 30);
+    END IF;
+    IF TG_OP = 'INSERT' OR (TG_OP = 'UPDATE' AND NEW.branch_id <> OLD.branch_id) THEN
+        PERFORM mark_quota_summary_dirty(NEW.branch_id, CURRENT_DATE, CURRENT_DATE + 30);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS schedule_change_quota_update ON staff_schedules;
+CREATE TRIGGER schedule_change_quota_update
+AFTER INSERT OR UPDATE OR DELETE ON staff_schedules
+FOR EACH ROW
+EXECUTE FUNCTION mark_quota_summary_dirty_on_row_change();
+
+DROP TRIGGER IF EXISTS rotation_change_quota_update ON rotation_assignments;
+CREATE TRIGGER rotation_change_quota_update
+AFTER INSERT OR UPDATE OR DELETE ON rotation_assignments
+FOR EACH ROW
+EXECUTE FUNCTION mark_quota_summary_dirty_on_row_change();
+
+DROP TRIGGER IF EXISTS quota_change_summary_update ON position_quotas;
+CREATE TRIGGER quota_change_summary_update
+AFTER INSERT OR UPDATE OR DELETE ON position_quotas
+FOR EACH ROW
+EXECUTE FUNCTION mark_quota_summary_dirty_on_quota_change();
+
+-- The cron refresh queues missing summaries instead of calculating them
+CREATE OR REPLACE FUNCTION refresh_branch_quota_cache()
+RETURNS void AS $$
+BEGIN
+    REFRESH MATERIALIZED VIEW CONCURRENTLY branch_quota_status_cache;
+
+    INSERT INTO quota_summary_dirty (branch_id, date)
+    SELECT b.id, d::DATE
+    FROM branches b
+    CROSS JOIN generate_series(CURRENT_DATE, CURRENT_DATE + 30, INTERVAL '1 day') d
+    WHERE NOT EXISTS (
+        SELECT 1 FROM branch_quota_daily_summary
+        WHERE branch_id = b.id AND date = d::DATE
+    )
+    ON CONFLICT (branch_id, date) DO NOTHING;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS update_quota_summary_on_schedule_change();
+DROP FUNCTION IF EXISTS update_quota_summary_on_rotation_change();
+DROP FUNCTION IF EXISTS update_quota_summary_on_quota_change();
+DROP FUNCTION IF EXISTS recalculate_branch_quota_summary(UUID, DATE);
+
+-- Stored Group 1 and Group 2 scores came from the simplified SQL calculation:
+-- recompute every existing summary, and fill the next 30 days
+INSERT INTO quota_summary_dirty (branch_id, date)
+SELECT branch_id, date FROM branch_quota_daily_summary
+ON CONFLICT (branch_id, date) DO NOTHING;
+
+SELECT mark_quota_summary_dirty(id, CURRENT_DATE, CURRENT_DATE + 30) FROM branches;
@@ -0,0 +1,51 @@
+-- Job times are TIMESTAMPTZ: workers compare run_at and heartbeats set from Go
+-- against CURRENT_TIMESTAMP, which must not depend on the session time zone
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    type VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued'
+        CHECK (status IN ('queued', 'running', 'succeeded', 'failed', 'cancelled')),
+    payload JSONB,
+    input_name VARCHAR(255),
+    input_content_type VARCHAR(255),
+    input_data BYTEA,
+    progress INTEGER NOT NULL DEFAULT 0,
+    progress_message TEXT NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 3,
+    error TEXT NOT NULL DEFAULT '',
+    result JSONB,
+    result_file_name VARCHAR(255),
+    result_content_type VARCHAR(255),
+    result_data BYTEA,
+    cancel_requested BOOLEAN NOT NULL DEFAULT false,
+    schedule_name VARCHAR(100),
+    run_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_by VARCHAR(255),
+    heartbeat_at TIMESTAMPTZ,
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    -- A user or, for jobs queued with an API token, a service account
+    created_by UUID,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Workers claim the oldest due job with FOR UPDATE SKIP LOCKED
+CREATE INDEX IF NOT EXISTS idx_jobs_queued ON jobs(run_at) WHERE status = 'queued';
+CREATE INDEX IF NOT EXISTS idx_jobs_running ON jobs(heartbeat_at) WHERE status = 'running';
+CREATE INDEX IF NOT EXISTS idx_jobs_created_by ON jobs(created_by, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_jobs_finished_at ON jobs(finished_at);
+
+CREATE TABLE IF NOT EXISTS job_schedules (
+    name VARCHAR(100) PRIMARY KEY,
+    job_type VARCHAR(100) NOT NULL,
+    cron VARCHAR(100) NOT NULL,
+    payload JSONB,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    last_run_at TIMESTAMPTZ,
+    next_run_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS doctor_revenue_overrides;
+DROP TABLE IF EXISTS doctor_revenue_profiles;
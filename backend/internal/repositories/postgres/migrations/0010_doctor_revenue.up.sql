@@ -0,0 +1,39 @@
+-- Expected revenue a doctor brings to the branch they work at, by weekday with
+-- optional per-date overrides. Calculated doctor assignments carry it and
+-- branches without entered revenue sum it into their daily revenue.
+
+CREATE TABLE IF NOT EXISTS doctor_revenue_profiles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    doctor_id UUID NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+    -- NULL applies at every branch; a branch-specific profile takes precedence
+    branch_id UUID REFERENCES branches(id) ON DELETE CASCADE,
+    day_of_week INTEGER NOT NULL CHECK (day_of_week >= 0 AND day_of_week <= 6),
+    skin_revenue DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (skin_revenue >= 0),
+    ls_hm_revenue DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (ls_hm_revenue >= 0),
+    vitamin_cases INTEGER NOT NULL DEFAULT 0 CHECK (vitamin_cases >= 0),
+    slim_pen_cases INTEGER NOT NULL DEFAULT 0 CHECK (slim_pen_cases >= 0),
+    created_by UUID,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One profile per doctor, branch (or none) and weekday; NULL branches compare equal
+CREATE UNIQUE INDEX IF NOT EXISTS idx_doctor_revenue_profiles_unique ON doctor_revenue_profiles(
+    doctor_id, COALESCE(branch_id, '00000000-0000-0000-0000-000000000000'::uuid), day_of_week
+);
+CREATE INDEX IF NOT EXISTS idx_doctor_revenue_profiles_branch_id ON doctor_revenue_profiles(branch_id);
+
+CREATE TABLE IF NOT EXISTS doctor_revenue_overrides (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    doctor_id UUID NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    skin_revenue DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (skin_revenue >= 0),
+    ls_hm_revenue DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (ls_hm_revenue >= 0),
+    vitamin_cases INTEGER NOT NULL DEFAULT 0 CHECK (vitamin_cases >= 0),
+    slim_pen_cases INTEGER NOT NULL DEFAULT 0 CHECK (slim_pen_cases >= 0),
+    created_by UUID,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(doctor_id, date)
+);
+CREATE INDEX IF NOT EXISTS idx_doctor_revenue_overrides_date ON doctor_revenue_overrides(date);
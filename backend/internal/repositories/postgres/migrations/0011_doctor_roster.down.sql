@@ -0,0 +1,18 @@
+DROP TABLE IF EXISTS doctor_roster_changes;
+DROP TABLE IF EXISTS doctor_roster_runs;
+
+-- Only rows the job wrote are removed; hand-entered rows and the overrides made
+-- from them stay
+DELETE FROM doctor_assignments WHERE created_by IS NULL;
+ALTER TABLE doctor_assignments
+    DROP COLUMN IF EXISTS source,
+    DROP COLUMN IF EXISTS skin_revenue,
+    DROP COLUMN IF EXISTS ls_hm_revenue,
+    DROP COLUMN IF EXISTS vitamin_cases,
+    DROP COLUMN IF EXISTS slim_pen_cases,
+    DROP COLUMN IF EXISTS revenue_source;
+ALTER TABLE doctor_assignments ALTER COLUMN created_by SET NOT NULL;
+ALTER TABLE doctor_assignments DROP CONSTRAINT IF EXISTS doctor_assignments_doctor_id_date_key;
+ALTER TABLE doctor_assignments ADD CONSTRAINT doctor_assignments_doctor_id_branch_id_date_key UNIQUE (doctor_id, branch_id, date);
+INSERT INTO doctor_assignments SELECT * FROM doctor_assignments_unrostered ON CONFLICT DO NOTHING;
+DROP TABLE IF EXISTS doctor_assignments_unrostered;
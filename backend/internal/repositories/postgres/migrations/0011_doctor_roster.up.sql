@@ -0,0 +1,71 @@
+-- Materialized doctor roster. A job writes the assignments calculated from the
+-- doctors' default schedules, weekly off days and overrides for a rolling
+-- horizon into doctor_assignments, so each keeps a stable ID, and records how
+-- every run changed the roster.
+
+-- Rows entered by hand before assignments were calculated become working
+-- overrides, so the roster the job calculates keeps them. An override already
+-- set for the doctor and date wins, and where a doctor was entered at several
+-- branches on one date the latest entry does. Rows that disagree with their
+-- override move to doctor_assignments_unrostered for review.
+INSERT INTO doctor_schedule_overrides (doctor_id, date, type, branch_id, created_by, created_at, updated_at)
+SELECT DISTINCT ON (doctor_id, date) doctor_id, date, 'working', branch_id, created_by, created_at, updated_at
+FROM doctor_assignments
+ORDER BY doctor_id, date, updated_at DESC NULLS LAST, created_at DESC NULLS LAST, id
+ON CONFLICT (doctor_id, date) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS doctor_assignments_unrostered (LIKE doctor_assignments INCLUDING DEFAULTS);
+WITH moved AS (
+    DELETE FROM doctor_assignments a
+    WHERE NOT EXISTS (
+        SELECT 1 FROM doctor_schedule_overrides o
+        WHERE o.doctor_id = a.doctor_id AND o.date = a.date AND o.type = 'working' AND o.branch_id = a.branch_id
+    )
+    RETURNING a.*
+)
+INSERT INTO doctor_assignments_unrostered SELECT * FROM moved;
+
+ALTER TABLE doctor_assignments DROP CONSTRAINT IF EXISTS doctor_assignments_doctor_id_branch_id_date_key;
+ALTER TABLE doctor_assignments ADD CONSTRAINT doctor_assignments_doctor_id_date_key UNIQUE (doctor_id, date);
+-- Rows are written by the materialization job, not a user
+ALTER TABLE doctor_assignments ALTER COLUMN created_by DROP NOT NULL;
+ALTER TABLE doctor_assignments
+    ADD COLUMN IF NOT EXISTS source VARCHAR(20) NOT NULL DEFAULT 'default' CHECK (source IN ('default', 'override')),
+    -- The revenue breakdown is NULL when the doctor has no revenue profile or override for the day
+    ADD COLUMN IF NOT EXISTS skin_revenue DECIMAL(15,2),
+    ADD COLUMN IF NOT EXISTS ls_hm_revenue DECIMAL(15,2),
+    ADD COLUMN IF NOT EXISTS vitamin_cases INTEGER,
+    ADD COLUMN IF NOT EXISTS slim_pen_cases INTEGER,
+    ADD COLUMN IF NOT EXISTS revenue_source VARCHAR(20);
+-- Every row left matches its override
+UPDATE doctor_assignments SET source = 'override';
+
+-- The dates a run materialized. Reads within the union of all runs come from
+-- doctor_assignments; dates outside it are calculated on the fly.
+CREATE TABLE IF NOT EXISTS doctor_roster_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL CHECK (end_date >= start_date),
+    trigger VARCHAR(20) NOT NULL CHECK (trigger IN ('scheduled', 'manual', 'change')),
+    added INTEGER NOT NULL DEFAULT 0,
+    removed INTEGER NOT NULL DEFAULT 0,
+    moved INTEGER NOT NULL DEFAULT 0,
+    updated INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_doctor_roster_runs_created_at ON doctor_roster_runs(created_at);
+
+-- A doctor starting, stopping or moving branch on a date, as found by a run
+CREATE TABLE IF NOT EXISTS doctor_roster_changes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    run_id UUID NOT NULL REFERENCES doctor_roster_runs(id) ON DELETE CASCADE,
+    doctor_id UUID NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    change_type VARCHAR(20) NOT NULL CHECK (change_type IN ('added', 'removed', 'moved')),
+    from_branch_id UUID REFERENCES branches(id) ON DELETE SET NULL,
+    to_branch_id UUID REFERENCES branches(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_doctor_roster_changes_run_id ON doctor_roster_changes(run_id);
+CREATE INDEX IF NOT EXISTS idx_doctor_roster_changes_doctor_date ON doctor_roster_changes(doctor_id, date);
+CREATE INDEX IF NOT EXISTS idx_doctor_roster_changes_date ON doctor_roster_changes(date);
@@ -0,0 +1,33 @@
+-- Only every-week, full-day schedules fit the old tables; the next roster run
+-- rewrites the assignments
+DELETE FROM doctor_assignments WHERE slot <> 'full_day';
+ALTER TABLE doctor_assignments DROP CONSTRAINT IF EXISTS doctor_assignments_doctor_id_date_slot_key;
+ALTER TABLE doctor_assignments DROP COLUMN IF EXISTS slot;
+ALTER TABLE doctor_assignments ADD CONSTRAINT doctor_assignments_doctor_id_date_key UNIQUE (doctor_id, date);
+
+DELETE FROM doctor_weekly_off_days
+WHERE interval_weeks > 1 OR week_of_month <> 0 OR valid_from IS NOT NULL OR valid_until IS NOT NULL;
+DROP INDEX IF EXISTS doctor_weekly_off_days_weekly_key;
+ALTER TABLE doctor_weekly_off_days
+    DROP CONSTRAINT IF EXISTS doctor_weekly_off_days_anchor_check,
+    DROP CONSTRAINT IF EXISTS doctor_weekly_off_days_validity_check,
+    DROP COLUMN IF EXISTS interval_weeks,
+    DROP COLUMN IF EXISTS anchor_date,
+    DROP COLUMN IF EXISTS week_of_month,
+    DROP COLUMN IF EXISTS valid_from,
+    DROP COLUMN IF EXISTS valid_until;
+ALTER TABLE doctor_weekly_off_days ADD CONSTRAINT doctor_weekly_off_days_doctor_id_day_of_week_key UNIQUE (doctor_id, day_of_week);
+
+DELETE FROM doctor_default_schedules
+WHERE slot <> 'full_day' OR interval_weeks > 1 OR week_of_month <> 0 OR valid_from IS NOT NULL OR valid_until IS NOT NULL;
+DROP INDEX IF EXISTS doctor_default_schedules_weekly_key;
+ALTER TABLE doctor_default_schedules
+    DROP CONSTRAINT IF EXISTS doctor_default_schedules_anchor_check,
+    DROP CONSTRAINT IF EXISTS doctor_default_schedules_validity_check,
+    DROP COLUMN IF EXISTS slot,
+    DROP COLUMN IF EXISTS interval_weeks,
+    DROP COLUMN IF EXISTS anchor_date,
+    DROP COLUMN IF EXISTS week_of_month,
+    DROP COLUMN IF EXISTS valid_from,
+    DROP COLUMN IF EXISTS valid_until;
+ALTER TABLE doctor_default_schedules ADD CONSTRAINT doctor_default_schedules_doctor_id_day_of_week_key UNIQUE (doctor_id, day_of_week);
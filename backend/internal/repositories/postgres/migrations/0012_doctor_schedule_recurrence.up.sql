@@ -0,0 +1,36 @@
+-- Recurring doctor schedules. Default schedules and weekly off days may repeat
+-- every N weeks from an anchor date, fall on the nth weekday of the month or
+-- apply only between two dates, and a doctor may split a day between branches
+-- with a morning and an afternoon schedule.
+
+ALTER TABLE doctor_default_schedules DROP CONSTRAINT IF EXISTS doctor_default_schedules_doctor_id_day_of_week_key;
+ALTER TABLE doctor_default_schedules
+    ADD COLUMN IF NOT EXISTS slot VARCHAR(20) NOT NULL DEFAULT 'full_day' CHECK (slot IN ('full_day', 'morning', 'afternoon')),
+    ADD COLUMN IF NOT EXISTS interval_weeks INTEGER NOT NULL DEFAULT 0 CHECK (interval_weeks >= 0),
+    ADD COLUMN IF NOT EXISTS anchor_date DATE,
+    ADD COLUMN IF NOT EXISTS week_of_month INTEGER NOT NULL DEFAULT 0 CHECK (week_of_month BETWEEN -1 AND 5),
+    ADD COLUMN IF NOT EXISTS valid_from DATE,
+    ADD COLUMN IF NOT EXISTS valid_until DATE,
+    ADD CONSTRAINT doctor_default_schedules_anchor_check CHECK (interval_weeks <= 1 OR anchor_date IS NOT NULL),
+    ADD CONSTRAINT doctor_default_schedules_validity_check CHECK (valid_from IS NULL OR valid_until IS NULL OR valid_until >= valid_from);
+-- One every-week schedule per weekday and slot; restricted ones may be added alongside
+CREATE UNIQUE INDEX IF NOT EXISTS doctor_default_schedules_weekly_key ON doctor_default_schedules(doctor_id, day_of_week, slot)
+    WHERE interval_weeks <= 1 AND week_of_month = 0 AND valid_from IS NULL AND valid_until IS NULL;
+
+ALTER TABLE doctor_weekly_off_days DROP CONSTRAINT IF EXISTS doctor_weekly_off_days_doctor_id_day_of_week_key;
+ALTER TABLE doctor_weekly_off_days
+    ADD COLUMN IF NOT EXISTS interval_weeks INTEGER NOT NULL DEFAULT 0 CHECK (interval_weeks >= 0),
+    ADD COLUMN IF NOT EXISTS anchor_date DATE,
+    ADD COLUMN IF NOT EXISTS week_of_month INTEGER NOT NULL DEFAULT 0 CHECK (week_of_month BETWEEN -1 AND 5),
+    ADD COLUMN IF NOT EXISTS valid_from DATE,
+    ADD COLUMN IF NOT EXISTS valid_until DATE,
+    ADD CONSTRAINT doctor_weekly_off_days_anchor_check CHECK (interval_weeks <= 1 OR anchor_date IS NOT NULL),
+    ADD CONSTRAINT doctor_weekly_off_days_validity_check CHECK (valid_from IS NULL OR valid_until IS NULL OR valid_until >= valid_from);
+CREATE UNIQUE INDEX IF NOT EXISTS doctor_weekly_off_days_weekly_key ON doctor_weekly_off_days(doctor_id, day_of_week)
+    WHERE interval_weeks <= 1 AND week_of_month = 0 AND valid_from IS NULL AND valid_until IS NULL;
+
+-- A doctor splitting a day has one roster row per slot
+ALTER TABLE doctor_assignments DROP CONSTRAINT IF EXISTS doctor_assignments_doctor_id_date_key;
+ALTER TABLE doctor_assignments
+    ADD COLUMN IF NOT EXISTS slot VARCHAR(20) NOT NULL DEFAULT 'full_day' CHECK (slot IN ('full_day', 'morning', 'afternoon'));
+ALTER TABLE doctor_assignments ADD CONSTRAINT doctor_assignments_doctor_id_date_slot_key UNIQUE (doctor_id, date, slot);
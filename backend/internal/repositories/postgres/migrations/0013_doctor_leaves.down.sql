@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS doctor_leave_substitutes;
+DROP TABLE IF EXISTS doctor_leaves;
@@ -0,0 +1,33 @@
+-- Doctor leave: a vacation or other absence over a date range, replacing one
+-- "off" schedule override per day. Approved leave takes the doctor off their
+-- schedule; substitutes record which doctor covers a branch on a leave date.
+
+CREATE TABLE IF NOT EXISTS doctor_leaves (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    doctor_id UUID NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL CHECK (end_date >= start_date),
+    reason TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    reviewed_by UUID,
+    reviewed_at TIMESTAMP,
+    created_by UUID,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_doctor_leaves_doctor_id ON doctor_leaves(doctor_id, start_date);
+CREATE INDEX IF NOT EXISTS idx_doctor_leaves_dates ON doctor_leaves(start_date, end_date);
+
+-- The substitute's working schedule override puts them on the roster; the row
+-- ties it to the leave it covers
+CREATE TABLE IF NOT EXISTS doctor_leave_substitutes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    leave_id UUID NOT NULL REFERENCES doctor_leaves(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    branch_id UUID NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+    doctor_id UUID NOT NULL REFERENCES doctors(id) ON DELETE CASCADE,
+    created_by UUID,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(leave_id, date, branch_id)
+);
+CREATE INDEX IF NOT EXISTS idx_doctor_leave_substitutes_doctor_id ON doctor_leave_substitutes(doctor_id, date);
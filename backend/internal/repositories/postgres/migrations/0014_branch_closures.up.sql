@@ -0,0 +1,16 @@
+-- Branch closures: a branch closed over a date range, or a public holiday
+-- closing every branch when branch_id is NULL. Closed branch days need no
+-- staff, whatever the doctor roster says.
+
+CREATE TABLE IF NOT EXISTS branch_closures (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    branch_id UUID REFERENCES branches(id) ON DELETE CASCADE,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL CHECK (end_date >= start_date),
+    reason TEXT NOT NULL DEFAULT '',
+    created_by UUID,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_branch_closures_dates ON branch_closures(start_date, end_date);
+CREATE INDEX IF NOT EXISTS idx_branch_closures_branch_id ON branch_closures(branch_id, start_date);
@@ -0,0 +1 @@
+ALTER TABLE specific_preferences DROP COLUMN IF EXISTS is_hard;
@@ -0,0 +1,5 @@
+-- Doctor-assistant pairings: a staff_name specific preference naming a doctor
+-- pairs the staff member with that doctor. A hard pairing must be met, and the
+-- staff member cannot be assigned away from the doctor's branch.
+
+ALTER TABLE specific_preferences ADD COLUMN IF NOT EXISTS is_hard BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1 @@
+ALTER TABLE branches DROP COLUMN IF EXISTS max_doctors;
@@ -0,0 +1,4 @@
+-- Doctor capacity per branch: how many doctors a branch can take on a day.
+-- NULL keeps the default of 6.
+
+ALTER TABLE branches ADD COLUMN IF NOT EXISTS max_doctors INTEGER CHECK (max_doctors > 0);
@@ -0,0 +1,8 @@
+-- Admin approvals for linking an SSO identity to an existing local user. The
+-- first login with the user's verified email links and uses the approval up.
+
+CREATE TABLE IF NOT EXISTS user_identity_link_approvals (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    approved_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    approved_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,558 @@
+package postgres
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// noTransactionDirective marks a SQL migration that must run outside a transaction
+// (e.g. CREATE INDEX CONCURRENTLY). Such migrations are tracked with the dirty flag.
+const noTransactionDirective = "-- migrate:no-transaction"
+
+// migrationLockID is the advisory lock key held while migrations run
+const migrationLockID = 727_202_801
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+const createSchemaMigrationsTable = `
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    dirty BOOLEAN NOT NULL DEFAULT false,
+    execution_ms INTEGER NOT NULL DEFAULT 0,
+    applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+`
+
+// Migration is a single numbered schema change. It is either SQL (Up/Down)
+// or Go code (UpFunc/DownFunc) running inside the migration transaction.
+type Migration struct {
+	Version       int64
+	Name          string
+	Checksum      string
+	Up            string
+	Down          string
+	UpFunc        func(tx *sql.Tx) error
+	DownFunc      func(tx *sql.Tx) error
+	NoTransaction bool
+}
+
+// Reversible reports whether the migration can be rolled back
+func (m Migration) Reversible() bool {
+	return hasStatements(m.Down) || m.DownFunc != nil
+}
+
+// MigrationStatus describes a known or applied migration against the database
+type MigrationStatus struct {
+	Version          int64      `json:"version"`
+	Name             string     `json:"name"`
+	Applied          bool       `json:"applied"`
+	AppliedAt        *time.Time `json:"applied_at,omitempty"`
+	ExecutionMS      int        `json:"execution_ms"`
+	Dirty            bool       `json:"dirty"`
+	ChecksumMismatch bool       `json:"checksum_mismatch"`
+	// Unknown is set for versions recorded in the database but not shipped with this build
+	Unknown bool `json:"unknown"`
+}
+
+type appliedMigration struct {
+	version     int64
+	name        string
+	checksum    string
+	dirty       bool
+	executionMS int
+	appliedAt   time.Time
+}
+
+// Migrator applies and rolls back numbered migrations tracked in schema_migrations
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator creates a migrator for the embedded SQL migrations and the Go migrations
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := LoadMigrations(migrationFiles, "migrations", goMigrations)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// LoadMigrations reads NNNN_name.up.sql / NNNN_name.down.sql files from dir and
+// merges them with Go migrations, sorted by version
+func LoadMigrations(fsys fs.FS, dir string, goMigs []Migration) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d used by both %q and %q", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(content)
+			m.Checksum = checksum(content)
+			m.NoTransaction = hasNoTransactionDirective(m.Up)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	for _, goMig := range goMigs {
+		if existing, ok := byVersion[goMig.Version]; ok {
+			return nil, fmt.Errorf("migration version %d used by both %q and Go migration %q", goMig.Version, existing.Name, goMig.Name)
+		}
+		m := goMig
+		if m.Checksum == "" {
+			m.Checksum = checksum([]byte("go:" + m.Name))
+		}
+		byVersion[m.Version] = &m
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" && m.UpFunc == nil {
+			return nil, fmt.Errorf("migration %d (%s) has no up migration", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrations returns the known migrations in version order
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Status reports every known migration plus any applied version this build does not know about
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	return m.StatusContext(context.Background())
+}
+
+// StatusContext is Status bounded by ctx
+func (m *Migrator) StatusContext(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	known := make(map[int64]bool, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = true
+		status := MigrationStatus{Version: mig.Version, Name: mig.Name}
+		if a, ok := applied[mig.Version]; ok {
+			appliedAt := a.appliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+			status.ExecutionMS = a.executionMS
+			status.Dirty = a.dirty
+			status.ChecksumMismatch = a.checksum != mig.Checksum
+		}
+		statuses = append(statuses, status)
+	}
+	for version, a := range applied {
+		if known[version] {
+			continue
+		}
+		appliedAt := a.appliedAt
+		statuses = append(statuses, MigrationStatus{
+			Version:     version,
+			Name:        a.name,
+			Applied:     true,
+			AppliedAt:   &appliedAt,
+			ExecutionMS: a.executionMS,
+			Dirty:       a.dirty,
+			Unknown:     true,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// Verify returns an error unless the database schema is clean and exactly up to date.
+// The server calls this on startup and refuses to serve an out-of-date schema.
+func (m *Migrator) Verify() error {
+	return m.VerifyContext(context.Background())
+}
+
+// VerifyContext is Verify bounded by ctx; readiness checks call it on every probe
+func (m *Migrator) VerifyContext(ctx context.Context) error {
+	statuses, err := m.StatusContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, s := range statuses {
+		switch {
+		case s.Dirty:
+			return fmt.Errorf("schema is dirty at migration %d (%s): fix the database manually, then run `migrate force %d`", s.Version, s.Name, s.Version)
+		case s.Unknown:
+			return fmt.Errorf("database has migration %d (%s) that this build does not know about: deploy a newer build", s.Version, s.Name)
+		case s.ChecksumMismatch:
+			return fmt.Errorf("migration %d (%s) was changed after it was applied: checksum mismatch", s.Version, s.Name)
+		case !s.Applied:
+			pending = append(pending, fmt.Sprintf("%d_%s", s.Version, s.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("schema is out of date, %d pending migration(s): %s: run `migrate up`", len(pending), strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// Up applies all pending migrations in order and returns how many were applied
+func (m *Migrator) Up() (int, error) {
+	count := 0
+	err := m.withLock(func(conn *sql.Conn) error {
+		applied, err := m.applied(context.Background(), conn)
+		if err != nil {
+			return err
+		}
+		if err := checkClean(applied, m.migrations); err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if err := m.apply(conn, mig); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Down rolls back the last n applied migrations and returns how many were rolled back
+func (m *Migrator) Down(n int) (int, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("number of migrations to roll back must be positive")
+	}
+
+	count := 0
+	err := m.withLock(func(conn *sql.Conn) error {
+		applied, err := m.applied(context.Background(), conn)
+		if err != nil {
+			return err
+		}
+		if err := checkClean(applied, m.migrations); err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && count < n; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if err := m.revert(conn, mig); err != nil {
+				return err
+			}
+			count++
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Redo rolls back the latest applied migration and applies it again
+func (m *Migrator) Redo() error {
+	return m.withLock(func(conn *sql.Conn) error {
+		applied, err := m.applied(context.Background(), conn)
+		if err != nil {
+			return err
+		}
+		if err := checkClean(applied, m.migrations); err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if err := m.revert(conn, mig); err != nil {
+				return err
+			}
+			return m.apply(conn, mig)
+		}
+		return fmt.Errorf("no applied migrations to redo")
+	})
+}
+
+// Force clears the dirty flag of a migration after the database was repaired by hand
+func (m *Migrator) Force(version int64) error {
+	return m.withLock(func(conn *sql.Conn) error {
+		result, err := conn.ExecContext(context.Background(),
+			`UPDATE schema_migrations SET dirty = false WHERE version = $1`, version)
+		if err != nil {
+			return fmt.Errorf("failed to clear dirty flag: %w", err)
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return fmt.Errorf("migration %d is not recorded as applied", version)
+		}
+		return nil
+	})
+}
+
+func (m *Migrator) apply(conn *sql.Conn, mig Migration) error {
+	ctx := context.Background()
+	start := time.Now()
+	log.Printf("[MIGRATE] Applying %d_%s", mig.Version, mig.Name)
+
+	if mig.NoTransaction {
+		if _, err := conn.ExecContext(ctx,
+			`INSERT INTO schema_migrations (version, name, checksum, dirty) VALUES ($1, $2, $3, true)`,
+			mig.Version, mig.Name, mig.Checksum); err != nil {
+			return fmt.Errorf("failed to record migration %d: %w", mig.Version, err)
+		}
+		if _, err := conn.ExecContext(ctx, mig.Up); err != nil {
+			return fmt.Errorf("migration %d (%s) failed, schema left dirty: %w", mig.Version, mig.Name, err)
+		}
+		if _, err := conn.ExecContext(ctx,
+			`UPDATE schema_migrations SET dirty = false, execution_ms = $2, applied_at = CURRENT_TIMESTAMP WHERE version = $1`,
+			mig.Version, time.Since(start).Milliseconds()); err != nil {
+			return fmt.Errorf("failed to record migration %d: %w", mig.Version, err)
+		}
+		return nil
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if mig.UpFunc != nil {
+		err = mig.UpFunc(tx)
+	} else {
+		_, err = tx.Exec(mig.Up)
+	}
+	if err != nil {
+		return fmt.Errorf("migration %d (%s) failed: %w", mig.Version, mig.Name, err)
+	}
+
+	if _, err := tx.Exec(
+		`INSERT INTO schema_migrations (version, name, checksum, execution_ms) VALUES ($1, $2, $3, $4)`,
+		mig.Version, mig.Name, mig.Checksum, time.Since(start).Milliseconds()); err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", mig.Version, err)
+	}
+	return tx.Commit()
+}
+
+func (m *Migrator) revert(conn *sql.Conn, mig Migration) error {
+	if !mig.Reversible() {
+		return fmt.Errorf("migration %d (%s) is irreversible", mig.Version, mig.Name)
+	}
+
+	ctx := context.Background()
+	log.Printf("[MIGRATE] Rolling back %d_%s", mig.Version, mig.Name)
+
+	if mig.NoTransaction {
+		if _, err := conn.ExecContext(ctx, `UPDATE schema_migrations SET dirty = true WHERE version = $1`, mig.Version); err != nil {
+			return fmt.Errorf("failed to mark migration %d dirty: %w", mig.Version, err)
+		}
+		if _, err := conn.ExecContext(ctx, mig.Down); err != nil {
+			return fmt.Errorf("rollback of %d (%s) failed, schema left dirty: %w", mig.Version, mig.Name, err)
+		}
+		if _, err := conn.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version); err != nil {
+			return fmt.Errorf("failed to remove migration record %d: %w", mig.Version, err)
+		}
+		return nil
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if mig.DownFunc != nil {
+		err = mig.DownFunc(tx)
+	} else {
+		_, err = tx.Exec(mig.Down)
+	}
+	if err != nil {
+		return fmt.Errorf("rollback of %d (%s) failed: %w", mig.Version, mig.Name, err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM schema_migrations WHERE version = $1`, mig.Version); err != nil {
+		return fmt.Errorf("failed to remove migration record %d: %w", mig.Version, err)
+	}
+	return tx.Commit()
+}
+
+// withLock runs fn on a single connection holding the migration advisory lock,
+// so concurrent migrate runs (e.g. several replicas starting) cannot interleave
+func (m *Migrator) withLock(fn func(conn *sql.Conn) error) error {
+	ctx := context.Background()
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, migrationLockID)
+
+	if _, err := conn.ExecContext(ctx, createSchemaMigrationsTable); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return fn(conn)
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// applied loads schema_migrations; a missing table means nothing has been applied yet
+func (m *Migrator) applied(ctx context.Context, q queryer) (map[int64]appliedMigration, error) {
+	var exists bool
+	if err := q.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to check schema_migrations: %w", err)
+	}
+	applied := make(map[int64]appliedMigration)
+	if !exists {
+		return applied, nil
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT version, name, checksum, dirty, execution_ms, applied_at
+		FROM schema_migrations
+		ORDER BY version
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a appliedMigration
+		if err := rows.Scan(&a.version, &a.name, &a.checksum, &a.dirty, &a.executionMS, &a.appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations: %w", err)
+		}
+		applied[a.version] = a
+	}
+	return applied, rows.Err()
+}
+
+// checkClean refuses to migrate from a dirty state or over an edited migration
+func checkClean(applied map[int64]appliedMigration, migrations []Migration) error {
+	for _, a := range applied {
+		if a.dirty {
+			return fmt.Errorf("schema is dirty at migration %d (%s): fix the database manually, then run `migrate force %d`", a.version, a.name, a.version)
+		}
+	}
+	for _, mig := range migrations {
+		if a, ok := applied[mig.Version]; ok && a.checksum != mig.Checksum {
+			return fmt.Errorf("migration %d (%s) was changed after it was applied: checksum mismatch", mig.Version, mig.Name)
+		}
+	}
+	return nil
+}
+
+// CreateMigrationFiles writes an empty up/down pair numbered after the highest
+// existing version (taking Go migrations into account) and returns their paths
+func CreateMigrationFiles(dir, name string) (string, string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = regexp.MustCompile(`[^a-z0-9]+`).ReplaceAllString(name, "_")
+	name = strings.Trim(name, "_")
+	if name == "" {
+		return "", "", fmt.Errorf("migration name is required")
+	}
+
+	migrations, err := LoadMigrations(os.DirFS(dir), ".", goMigrations)
+	if err != nil {
+		return "", "", err
+	}
+	var next int64 = 1
+	if len(migrations) > 0 {
+		next = migrations[len(migrations)-1].Version + 1
+	}
+
+	base := fmt.Sprintf("%04d_%s", next, name)
+	upPath := filepath.Join(dir, base+".up.sql")
+	downPath := filepath.Join(dir, base+".down.sql")
+	if err := os.WriteFile(upPath, []byte(fmt.Sprintf("-- %s\n", name)), 0o644); err != nil {
+		return "", "", fmt.Errorf("failed to write %s: %w", upPath, err)
+	}
+	if err := os.WriteFile(downPath, []byte(fmt.Sprintf("-- Revert %s\n", name)), 0o644); err != nil {
+		return "", "", fmt.Errorf("failed to write %s: %w", downPath, err)
+	}
+	return upPath, downPath, nil
+}
+
+// hasStatements reports whether SQL contains anything besides comments and blank lines
+func hasStatements(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			return true
+		}
+	}
+	return false
+}
+
+func hasNoTransactionDirective(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "--") {
+			return false
+		}
+		if line == noTransactionDirective {
+			return true
+		}
+	}
+	return false
+}
+
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
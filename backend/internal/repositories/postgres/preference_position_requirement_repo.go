@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 
@@ -10,31 +11,32 @@ import (
 )
 
 type preferencePositionRequirementRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewPreferencePositionRequirementRepository(db *sql.DB) interfaces.PreferencePositionRequirementRepository {
+func NewPreferencePositionRequirementRepository(db DBTX) interfaces.PreferencePositionRequirementRepository {
 	return &preferencePositionRequirementRepository{db: db}
 }
 
-func (r *preferencePositionRequirementRepository) Create(requirement *models.PreferencePositionRequirement) error {
+func (r *preferencePositionRequirementRepository) Create(ctx context.Context, requirement *models.PreferencePositionRequirement) error {
 	query := `INSERT INTO clinic_preference_position_requirements 
 	          (id, preference_id, position_id, minimum_staff, preferred_staff, is_active)
 	          VALUES ($1, $2, $3, $4, $5, $6)
 	          RETURNING created_at, updated_at`
-	return r.db.QueryRow(
+	return r.db.QueryRowContext(
+		ctx,
 		query,
 		requirement.ID, requirement.PreferenceID, requirement.PositionID,
 		requirement.MinimumStaff, requirement.PreferredStaff, requirement.IsActive,
 	).Scan(&requirement.CreatedAt, &requirement.UpdatedAt)
 }
 
-func (r *preferencePositionRequirementRepository) GetByID(id uuid.UUID) (*models.PreferencePositionRequirement, error) {
+func (r *preferencePositionRequirementRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PreferencePositionRequirement, error) {
 	requirement := &models.PreferencePositionRequirement{}
 	query := `SELECT id, preference_id, position_id, minimum_staff, preferred_staff, is_active, 
 	          created_at, updated_at
 	          FROM clinic_preference_position_requirements WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&requirement.ID, &requirement.PreferenceID, &requirement.PositionID,
 		&requirement.MinimumStaff, &requirement.PreferredStaff, &requirement.IsActive,
 		&requirement.CreatedAt, &requirement.UpdatedAt,
@@ -48,14 +50,14 @@ func (r *preferencePositionRequirementRepository) GetByID(id uuid.UUID) (*models
 	return requirement, nil
 }
 
-func (r *preferencePositionRequirementRepository) GetByPreferenceID(preferenceID uuid.UUID) ([]*models.PreferencePositionRequirement, error) {
+func (r *preferencePositionRequirementRepository) GetByPreferenceID(ctx context.Context, preferenceID uuid.UUID) ([]*models.PreferencePositionRequirement, error) {
 	query := `SELECT id, preference_id, position_id, minimum_staff, preferred_staff, is_active, 
 	          created_at, updated_at
 	          FROM clinic_preference_position_requirements 
 	          WHERE preference_id = $1
 	          ORDER BY position_id`
 	
-	rows, err := r.db.Query(query, preferenceID)
+	rows, err := r.db.QueryContext(ctx, query, preferenceID)
 	if err != nil {
 		return nil, err
 	}
@@ -77,13 +79,13 @@ func (r *preferencePositionRequirementRepository) GetByPreferenceID(preferenceID
 	return requirements, rows.Err()
 }
 
-func (r *preferencePositionRequirementRepository) GetByPreferenceAndPosition(preferenceID, positionID uuid.UUID) (*models.PreferencePositionRequirement, error) {
+func (r *preferencePositionRequirementRepository) GetByPreferenceAndPosition(ctx context.Context, preferenceID, positionID uuid.UUID) (*models.PreferencePositionRequirement, error) {
 	requirement := &models.PreferencePositionRequirement{}
 	query := `SELECT id, preference_id, position_id, minimum_staff, preferred_staff, is_active, 
 	          created_at, updated_at
 	          FROM clinic_preference_position_requirements 
 	          WHERE preference_id = $1 AND position_id = $2`
-	err := r.db.QueryRow(query, preferenceID, positionID).Scan(
+	err := r.db.QueryRowContext(ctx, query, preferenceID, positionID).Scan(
 		&requirement.ID, &requirement.PreferenceID, &requirement.PositionID,
 		&requirement.MinimumStaff, &requirement.PreferredStaff, &requirement.IsActive,
 		&requirement.CreatedAt, &requirement.UpdatedAt,
@@ -97,41 +99,42 @@ func (r *preferencePositionRequirementRepository) GetByPreferenceAndPosition(pre
 	return requirement, nil
 }
 
-func (r *preferencePositionRequirementRepository) Update(requirement *models.PreferencePositionRequirement) error {
+func (r *preferencePositionRequirementRepository) Update(ctx context.Context, requirement *models.PreferencePositionRequirement) error {
 	query := `UPDATE clinic_preference_position_requirements
 	          SET minimum_staff = $1, preferred_staff = $2, is_active = $3, updated_at = CURRENT_TIMESTAMP
 	          WHERE id = $4 RETURNING updated_at`
-	return r.db.QueryRow(
+	return r.db.QueryRowContext(
+		ctx,
 		query,
 		requirement.MinimumStaff, requirement.PreferredStaff, requirement.IsActive,
 		requirement.ID,
 	).Scan(&requirement.UpdatedAt)
 }
 
-func (r *preferencePositionRequirementRepository) Delete(id uuid.UUID) error {
+func (r *preferencePositionRequirementRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM clinic_preference_position_requirements WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *preferencePositionRequirementRepository) DeleteByPreferenceID(preferenceID uuid.UUID) error {
+func (r *preferencePositionRequirementRepository) DeleteByPreferenceID(ctx context.Context, preferenceID uuid.UUID) error {
 	query := `DELETE FROM clinic_preference_position_requirements WHERE preference_id = $1`
-	_, err := r.db.Exec(query, preferenceID)
+	_, err := r.db.ExecContext(ctx, query, preferenceID)
 	return err
 }
 
-func (r *preferencePositionRequirementRepository) BulkUpsert(requirements []*models.PreferencePositionRequirement) error {
+func (r *preferencePositionRequirementRepository) BulkUpsert(ctx context.Context, requirements []*models.PreferencePositionRequirement) error {
 	if len(requirements) == 0 {
 		return nil
 	}
 
-	tx, err := r.db.Begin()
+	tx, err := beginTx(ctx, r.db)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	stmt, err := tx.Prepare(`
+	stmt, err := tx.PrepareContext(ctx, `
 		INSERT INTO clinic_preference_position_requirements 
 		(id, preference_id, position_id, minimum_staff, preferred_staff, is_active)
 		VALUES ($1, $2, $3, $4, $5, $6)
@@ -148,7 +151,8 @@ func (r *preferencePositionRequirementRepository) BulkUpsert(requirements []*mod
 	defer stmt.Close()
 
 	for _, req := range requirements {
-		_, err := stmt.Exec(
+		_, err := stmt.ExecContext(
+			ctx,
 			req.ID, req.PreferenceID, req.PositionID,
 			req.MinimumStaff, req.PreferredStaff, req.IsActive,
 		)
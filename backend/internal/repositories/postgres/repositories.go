@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -15,52 +16,25 @@ import (
 	"github.com/lib/pq"
 )
 
+// Repositories is the set of postgres repositories bound to the connection pool,
+// plus the unit of work for composing them into a single transaction
 type Repositories struct {
-	User                             interfaces.UserRepository
-	Role                             interfaces.RoleRepository
-	Staff                            interfaces.StaffRepository
-	Position                         interfaces.PositionRepository
-	Branch                           interfaces.BranchRepository
-	EffectiveBranch                  interfaces.EffectiveBranchRepository
-	Revenue                          interfaces.RevenueRepository
-	Schedule                         interfaces.ScheduleRepository
-	Rotation                         interfaces.RotationRepository
-	RotationStaffSchedule            interfaces.RotationStaffScheduleRepository
-	Settings                         interfaces.SettingsRepository
-	AllocationRule                   interfaces.AllocationRuleRepository
-	AreaOfOperation                  interfaces.AreaOfOperationRepository
-	Zone                             interfaces.ZoneRepository
-	AllocationCriteria               interfaces.AllocationCriteriaRepository
-	PositionQuota                    interfaces.PositionQuotaRepository
-	Doctor                           interfaces.DoctorRepository
-	DoctorPreference                 interfaces.DoctorPreferenceRepository
-	DoctorAssignment                 interfaces.DoctorAssignmentRepository
-	DoctorOnOffDay                   interfaces.DoctorOnOffDayRepository
-	DoctorDefaultSchedule            interfaces.DoctorDefaultScheduleRepository
-	DoctorWeeklyOffDay               interfaces.DoctorWeeklyOffDayRepository
-	DoctorScheduleOverride           interfaces.DoctorScheduleOverrideRepository
-	BranchWeeklyRevenue              interfaces.BranchWeeklyRevenueRepository
-	BranchConstraints                interfaces.BranchConstraintsRepository
-	RevenueLevelTier                 interfaces.RevenueLevelTierRepository
-	StaffRequirementScenario         interfaces.StaffRequirementScenarioRepository
-	ScenarioPositionRequirement      interfaces.ScenarioPositionRequirementRepository
-	ScenarioSpecificStaffRequirement interfaces.ScenarioSpecificStaffRequirementRepository
-	BranchType                       interfaces.BranchTypeRepository
-	StaffGroup                       interfaces.StaffGroupRepository
-	StaffGroupPosition               interfaces.StaffGroupPositionRepository
-	BranchTypeRequirement            interfaces.BranchTypeStaffGroupRequirementRepository
-	BranchTypeConstraints            interfaces.BranchTypeConstraintsRepository
-	SpecificPreference               interfaces.SpecificPreferenceRepository
-	ClinicWidePreference             interfaces.ClinicWidePreferenceRepository
-	PreferencePositionRequirement    interfaces.PreferencePositionRequirementRepository
-	RotationStaffBranchPosition      interfaces.RotationStaffBranchPositionRepository
-	AllocationSuggestion             interfaces.AllocationSuggestionRepository
-	BranchQuotaSummary               interfaces.BranchQuotaSummaryRepository
+	interfaces.Repositories
+	UnitOfWork interfaces.UnitOfWork
 }
 
 func NewRepositories(db *sql.DB) *Repositories {
-	repos := &Repositories{
+	return &Repositories{
+		Repositories: *newRepositorySet(db),
+		UnitOfWork:   NewUnitOfWork(db),
+	}
+}
+
+// newRepositorySet builds every repository on db, which is either the pool or a transaction
+func newRepositorySet(db DBTX) *interfaces.Repositories {
+	repos := &interfaces.Repositories{
 		User:                             NewUserRepository(db),
+		UserIdentity:                     NewUserIdentityRepository(db),
 		Role:                             NewRoleRepository(db),
 		Staff:                            NewStaffRepository(db),
 		Position:                         NewPositionRepository(db),
@@ -82,8 +56,13 @@ func NewRepositories(db *sql.DB) *Repositories {
 		DoctorDefaultSchedule:            NewDoctorDefaultScheduleRepository(db),
 		DoctorWeeklyOffDay:               NewDoctorWeeklyOffDayRepository(db),
 		DoctorScheduleOverride:           NewDoctorScheduleOverrideRepository(db),
+		DoctorRevenueProfile:             NewDoctorRevenueProfileRepository(db),
+		DoctorRevenueOverride:            NewDoctorRevenueOverrideRepository(db),
+		DoctorLeave:                      NewDoctorLeaveRepository(db),
+		DoctorRoster:                     NewDoctorRosterRepository(db),
 		BranchWeeklyRevenue:              NewBranchWeeklyRevenueRepository(db),
 		BranchConstraints:                NewBranchConstraintsRepository(db),
+		BranchClosure:                    NewBranchClosureRepository(db),
 		RevenueLevelTier:                 NewRevenueLevelTierRepository(db),
 		StaffRequirementScenario:         NewStaffRequirementScenarioRepository(db),
 		ScenarioPositionRequirement:      NewScenarioPositionRequirementRepository(db),
@@ -99,6 +78,10 @@ func NewRepositories(db *sql.DB) *Repositories {
 		RotationStaffBranchPosition:      NewRotationStaffBranchPositionRepository(db),
 		AllocationSuggestion:             NewAllocationSuggestionRepository(db),
 		BranchQuotaSummary:               NewBranchQuotaSummaryRepository(db),
+		ServiceAccount:                   NewServiceAccountRepository(db),
+		APIToken:                         NewAPITokenRepository(db),
+		Job:                              NewJobRepository(db),
+		JobSchedule:                      NewJobScheduleRepository(db),
 	}
 
 	// DoctorAssignment needs schedule repositories, so create it after them
@@ -108,6 +91,10 @@ func NewRepositories(db *sql.DB) *Repositories {
 		repos.DoctorWeeklyOffDay,
 		repos.DoctorScheduleOverride,
 		repos.Doctor,
+		repos.DoctorRevenueProfile,
+		repos.DoctorRevenueOverride,
+		repos.DoctorLeave,
+		repos.DoctorRoster,
 	)
 
 	return repos
@@ -115,26 +102,26 @@ func NewRepositories(db *sql.DB) *Repositories {
 
 // UserRepository implementation
 type userRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewUserRepository(db *sql.DB) interfaces.UserRepository {
+func NewUserRepository(db DBTX) interfaces.UserRepository {
 	return &userRepository{db: db}
 }
 
-func (r *userRepository) Create(user *models.User) error {
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
 	query := `INSERT INTO users (id, username, email, password_hash, role_id, branch_id) 
 	          VALUES ($1, $2, $3, $4, $5, $6) RETURNING created_at, updated_at`
-	return r.db.QueryRow(query, user.ID, user.Username, user.Email, user.PasswordHash, user.RoleID, user.BranchID).
+	return r.db.QueryRowContext(ctx, query, user.ID, user.Username, user.Email, user.PasswordHash, user.RoleID, user.BranchID).
 		Scan(&user.CreatedAt, &user.UpdatedAt)
 }
 
-func (r *userRepository) GetByID(id uuid.UUID) (*models.User, error) {
+func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
 	user := &models.User{}
 	query := `SELECT id, username, email, password_hash, role_id, branch_id, created_at, updated_at 
 	          FROM users WHERE id = $1`
 	var branchID sql.NullString
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&user.ID, &user.Username, &user.Email, &user.PasswordHash,
 		&user.RoleID, &branchID, &user.CreatedAt, &user.UpdatedAt,
 	)
@@ -151,12 +138,12 @@ func (r *userRepository) GetByID(id uuid.UUID) (*models.User, error) {
 	return user, nil
 }
 
-func (r *userRepository) GetByUsername(username string) (*models.User, error) {
+func (r *userRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
 	user := &models.User{}
 	query := `SELECT id, username, email, password_hash, role_id, branch_id, created_at, updated_at 
 	          FROM users WHERE username = $1`
 	var branchID sql.NullString
-	err := r.db.QueryRow(query, username).Scan(
+	err := r.db.QueryRowContext(ctx, query, username).Scan(
 		&user.ID, &user.Username, &user.Email, &user.PasswordHash,
 		&user.RoleID, &branchID, &user.CreatedAt, &user.UpdatedAt,
 	)
@@ -173,37 +160,47 @@ func (r *userRepository) GetByUsername(username string) (*models.User, error) {
 	return user, nil
 }
 
-func (r *userRepository) GetByEmail(email string) (*models.User, error) {
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	user := &models.User{}
-	query := `SELECT id, username, email, password_hash, role_id, created_at, updated_at 
+	query := `SELECT id, username, email, password_hash, role_id, branch_id, created_at, updated_at 
 	          FROM users WHERE email = $1`
-	err := r.db.QueryRow(query, email).Scan(
+	var branchID sql.NullString
+	err := r.db.QueryRowContext(ctx, query, email).Scan(
 		&user.ID, &user.Username, &user.Email, &user.PasswordHash,
-		&user.RoleID, &user.CreatedAt, &user.UpdatedAt,
+		&user.RoleID, &branchID, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
-	return user, err
+	if err != nil {
+		return nil, err
+	}
+	if branchID.Valid {
+		bID, _ := uuid.Parse(branchID.String)
+		user.BranchID = &bID
+	}
+	return user, nil
 }
 
-func (r *userRepository) Update(user *models.User) error {
+func (r *userRepository) Update(ctx context.Context, user *models.User) error {
 	query := `UPDATE users SET username = $1, email = $2, password_hash = $3, 
 	          role_id = $4, branch_id = $5, updated_at = CURRENT_TIMESTAMP WHERE id = $6`
-	_, err := r.db.Exec(query, user.Username, user.Email, user.PasswordHash, user.RoleID, user.BranchID, user.ID)
+	_, err := r.db.ExecContext(ctx, query, user.Username, user.Email, user.PasswordHash, user.RoleID, user.BranchID, user.ID)
 	return err
 }
 
-func (r *userRepository) Delete(id uuid.UUID) error {
+func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM users WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *userRepository) List() ([]*models.User, error) {
+func (r *userRepository) List(ctx context.Context) ([]*models.User, error) {
+	// Service accounts' backing users are managed with their accounts
 	query := `SELECT id, username, email, password_hash, role_id, branch_id, created_at, updated_at 
-	          FROM users ORDER BY created_at DESC`
-	rows, err := r.db.Query(query)
+	          FROM users u WHERE NOT EXISTS (SELECT 1 FROM service_accounts sa WHERE sa.id = u.id)
+	          ORDER BY created_at DESC`
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -230,36 +227,36 @@ func (r *userRepository) List() ([]*models.User, error) {
 
 // RoleRepository implementation
 type roleRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewRoleRepository(db *sql.DB) interfaces.RoleRepository {
+func NewRoleRepository(db DBTX) interfaces.RoleRepository {
 	return &roleRepository{db: db}
 }
 
-func (r *roleRepository) GetByID(id uuid.UUID) (*models.Role, error) {
+func (r *roleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Role, error) {
 	role := &models.Role{}
 	query := `SELECT id, name, created_at FROM roles WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(&role.ID, &role.Name, &role.CreatedAt)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&role.ID, &role.Name, &role.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	return role, err
 }
 
-func (r *roleRepository) GetByName(name string) (*models.Role, error) {
+func (r *roleRepository) GetByName(ctx context.Context, name string) (*models.Role, error) {
 	role := &models.Role{}
 	query := `SELECT id, name, created_at FROM roles WHERE name = $1`
-	err := r.db.QueryRow(query, name).Scan(&role.ID, &role.Name, &role.CreatedAt)
+	err := r.db.QueryRowContext(ctx, query, name).Scan(&role.ID, &role.Name, &role.CreatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	return role, err
 }
 
-func (r *roleRepository) List() ([]*models.Role, error) {
+func (r *roleRepository) List(ctx context.Context) ([]*models.Role, error) {
 	query := `SELECT id, name, created_at FROM roles ORDER BY name`
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -278,21 +275,21 @@ func (r *roleRepository) List() ([]*models.Role, error) {
 
 // StaffRepository implementation
 type staffRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewStaffRepository(db *sql.DB) interfaces.StaffRepository {
+func NewStaffRepository(db DBTX) interfaces.StaffRepository {
 	return &staffRepository{db: db}
 }
 
-func (r *staffRepository) Create(staff *models.Staff) error {
+func (r *staffRepository) Create(ctx context.Context, staff *models.Staff) error {
 	query := `INSERT INTO staff (id, nickname, name, staff_type, position_id, branch_id, coverage_area, area_of_operation_id, zone_id, skill_level) 
 	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING created_at, updated_at`
-	return r.db.QueryRow(query, staff.ID, staff.Nickname, staff.Name, staff.StaffType, staff.PositionID,
+	return r.db.QueryRowContext(ctx, query, staff.ID, staff.Nickname, staff.Name, staff.StaffType, staff.PositionID,
 		staff.BranchID, staff.CoverageArea, staff.AreaOfOperationID, staff.ZoneID, staff.SkillLevel).Scan(&staff.CreatedAt, &staff.UpdatedAt)
 }
 
-func (r *staffRepository) GetByID(id uuid.UUID) (*models.Staff, error) {
+func (r *staffRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Staff, error) {
 	staff := &models.Staff{}
 	query := `SELECT id, nickname, name, staff_type, position_id, branch_id, coverage_area, area_of_operation_id, zone_id, skill_level, created_at, updated_at 
 	          FROM staff WHERE id = $1`
@@ -300,7 +297,7 @@ func (r *staffRepository) GetByID(id uuid.UUID) (*models.Staff, error) {
 	var areaOfOpID sql.NullString
 	var zoneID sql.NullString
 	var nickname sql.NullString
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&staff.ID, &nickname, &staff.Name, &staff.StaffType, &staff.PositionID,
 		&branchID, &staff.CoverageArea, &areaOfOpID, &zoneID, &staff.SkillLevel, &staff.CreatedAt, &staff.UpdatedAt,
 	)
@@ -328,7 +325,7 @@ func (r *staffRepository) GetByID(id uuid.UUID) (*models.Staff, error) {
 
 	// Load branches if this is rotation staff
 	if staff.StaffType == models.StaffTypeRotation {
-		branches, err := r.GetBranches(id)
+		branches, err := r.GetBranches(ctx, id)
 		if err == nil {
 			staff.Branches = branches
 		}
@@ -337,28 +334,28 @@ func (r *staffRepository) GetByID(id uuid.UUID) (*models.Staff, error) {
 	return staff, nil
 }
 
-func (r *staffRepository) Update(staff *models.Staff) error {
+func (r *staffRepository) Update(ctx context.Context, staff *models.Staff) error {
 	query := `UPDATE staff SET nickname = $1, name = $2, staff_type = $3, position_id = $4, 
 	          branch_id = $5, coverage_area = $6, area_of_operation_id = $7, zone_id = $8, skill_level = $9, updated_at = CURRENT_TIMESTAMP WHERE id = $10`
-	_, err := r.db.Exec(query, staff.Nickname, staff.Name, staff.StaffType, staff.PositionID,
+	_, err := r.db.ExecContext(ctx, query, staff.Nickname, staff.Name, staff.StaffType, staff.PositionID,
 		staff.BranchID, staff.CoverageArea, staff.AreaOfOperationID, staff.ZoneID, staff.SkillLevel, staff.ID)
 	return err
 }
 
-func (r *staffRepository) Delete(id uuid.UUID) error {
+func (r *staffRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM staff WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *staffRepository) GetBranches(staffID uuid.UUID) ([]*models.Branch, error) {
+func (r *staffRepository) GetBranches(ctx context.Context, staffID uuid.UUID) ([]*models.Branch, error) {
 	query := `SELECT b.id, b.name, b.code, b.area_manager_id, b.priority, b.created_at, b.updated_at
 	          FROM branches b
 	          INNER JOIN staff_branches sb ON b.id = sb.branch_id
 	          WHERE sb.staff_id = $1
 	          ORDER BY b.name`
 
-	rows, err := r.db.Query(query, staffID)
+	rows, err := r.db.QueryContext(ctx, query, staffID)
 	if err != nil {
 		return nil, err
 	}
@@ -385,8 +382,8 @@ func (r *staffRepository) GetBranches(staffID uuid.UUID) ([]*models.Branch, erro
 	return branches, rows.Err()
 }
 
-func (r *staffRepository) BulkUpdateBranches(staffID uuid.UUID, branchIDs []uuid.UUID) error {
-	tx, err := r.db.Begin()
+func (r *staffRepository) BulkUpdateBranches(ctx context.Context, staffID uuid.UUID, branchIDs []uuid.UUID) error {
+	tx, err := beginTx(ctx, r.db)
 	if err != nil {
 		return err
 	}
@@ -394,7 +391,7 @@ func (r *staffRepository) BulkUpdateBranches(staffID uuid.UUID, branchIDs []uuid
 
 	// Delete all existing branches for this staff
 	deleteQuery := `DELETE FROM staff_branches WHERE staff_id = $1`
-	if _, err := tx.Exec(deleteQuery, staffID); err != nil {
+	if _, err := tx.ExecContext(ctx, deleteQuery, staffID); err != nil {
 		return err
 	}
 
@@ -402,7 +399,7 @@ func (r *staffRepository) BulkUpdateBranches(staffID uuid.UUID, branchIDs []uuid
 	insertQuery := `INSERT INTO staff_branches (id, staff_id, branch_id) 
 	                VALUES (gen_random_uuid(), $1, $2)`
 	for _, branchID := range branchIDs {
-		if _, err := tx.Exec(insertQuery, staffID, branchID); err != nil {
+		if _, err := tx.ExecContext(ctx, insertQuery, staffID, branchID); err != nil {
 			return err
 		}
 	}
@@ -410,7 +407,7 @@ func (r *staffRepository) BulkUpdateBranches(staffID uuid.UUID, branchIDs []uuid
 	return tx.Commit()
 }
 
-func (r *staffRepository) List(filters interfaces.StaffFilters) ([]*models.Staff, error) {
+func (r *staffRepository) List(ctx context.Context, filters interfaces.StaffFilters) ([]*models.Staff, error) {
 	query := `SELECT s.id, s.nickname, s.name, s.staff_type, s.position_id, s.branch_id, s.coverage_area, s.area_of_operation_id, s.zone_id, s.skill_level, s.created_at, s.updated_at 
 	          FROM staff s
 	          LEFT JOIN positions p ON s.position_id = p.id
@@ -445,7 +442,7 @@ func (r *staffRepository) List(filters interfaces.StaffFilters) ([]*models.Staff
 
 	query += ` ORDER BY COALESCE(p.display_order, 999), s.name`
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -482,7 +479,7 @@ func (r *staffRepository) List(filters interfaces.StaffFilters) ([]*models.Staff
 
 		// Load branches if this is rotation staff
 		if staff.StaffType == models.StaffTypeRotation {
-			branches, err := r.GetBranches(staff.ID)
+			branches, err := r.GetBranches(ctx, staff.ID)
 			if err == nil {
 				staff.Branches = branches
 			}
@@ -493,39 +490,133 @@ func (r *staffRepository) List(filters interfaces.StaffFilters) ([]*models.Staff
 	return staffList, rows.Err()
 }
 
-func (r *staffRepository) GetByBranchID(branchID uuid.UUID) ([]*models.Staff, error) {
+func (r *staffRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID) ([]*models.Staff, error) {
 	filters := interfaces.StaffFilters{BranchID: &branchID}
-	return r.List(filters)
+	return r.List(ctx, filters)
+}
+
+// scanStaffRow scans the staff columns selected by GetByBranchIDs and GetByIDs,
+// after any leading destinations
+func scanStaffRow(rows *sql.Rows, leading ...interface{}) (*models.Staff, error) {
+	staff := &models.Staff{}
+	var branchID sql.NullString
+	var areaOfOpID sql.NullString
+	var zoneID sql.NullString
+	var nickname sql.NullString
+	dest := append(leading,
+		&staff.ID, &nickname, &staff.Name, &staff.StaffType, &staff.PositionID,
+		&branchID, &staff.CoverageArea, &areaOfOpID, &zoneID, &staff.SkillLevel, &staff.CreatedAt, &staff.UpdatedAt,
+	)
+	if err := rows.Scan(dest...); err != nil {
+		return nil, err
+	}
+	if nickname.Valid {
+		staff.Nickname = nickname.String
+	}
+	if branchID.Valid {
+		bID, _ := uuid.Parse(branchID.String)
+		staff.BranchID = &bID
+	}
+	if areaOfOpID.Valid {
+		aooID, _ := uuid.Parse(areaOfOpID.String)
+		staff.AreaOfOperationID = &aooID
+	}
+	if zoneID.Valid {
+		zID, _ := uuid.Parse(zoneID.String)
+		staff.ZoneID = &zID
+	}
+	return staff, nil
+}
+
+// GetByBranchIDs loads the staff of several branches in one query. Unlike List it
+// does not populate Branches for rotation staff.
+func (r *staffRepository) GetByBranchIDs(ctx context.Context, branchIDs []uuid.UUID) (map[uuid.UUID][]*models.Staff, error) {
+	result := make(map[uuid.UUID][]*models.Staff)
+	if len(branchIDs) == 0 {
+		return result, nil
+	}
+
+	query := `SELECT m.branch_id, s.id, s.nickname, s.name, s.staff_type, s.position_id, s.branch_id, s.coverage_area, s.area_of_operation_id, s.zone_id, s.skill_level, s.created_at, s.updated_at 
+	          FROM (
+	              SELECT id AS staff_id, branch_id FROM staff WHERE branch_id = ANY($1)
+	              UNION
+	              SELECT staff_id, branch_id FROM staff_branches WHERE branch_id = ANY($1)
+	          ) m
+	          JOIN staff s ON s.id = m.staff_id
+	          LEFT JOIN positions p ON s.position_id = p.id
+	          ORDER BY m.branch_id, COALESCE(p.display_order, 999), s.name`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(branchIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var memberOf uuid.UUID
+		staff, err := scanStaffRow(rows, &memberOf)
+		if err != nil {
+			return nil, err
+		}
+		result[memberOf] = append(result[memberOf], staff)
+	}
+	return result, rows.Err()
+}
+
+func (r *staffRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.Staff, error) {
+	if len(ids) == 0 {
+		return []*models.Staff{}, nil
+	}
+
+	query := `SELECT s.id, s.nickname, s.name, s.staff_type, s.position_id, s.branch_id, s.coverage_area, s.area_of_operation_id, s.zone_id, s.skill_level, s.created_at, s.updated_at 
+	          FROM staff s
+	          LEFT JOIN positions p ON s.position_id = p.id
+	          WHERE s.id = ANY($1)
+	          ORDER BY COALESCE(p.display_order, 999), s.name`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	staffList := []*models.Staff{}
+	for rows.Next() {
+		staff, err := scanStaffRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		staffList = append(staffList, staff)
+	}
+	return staffList, rows.Err()
 }
 
-func (r *staffRepository) GetRotationStaff() ([]*models.Staff, error) {
+func (r *staffRepository) GetRotationStaff(ctx context.Context) ([]*models.Staff, error) {
 	rotationType := models.StaffTypeRotation
 	filters := interfaces.StaffFilters{StaffType: &rotationType}
-	return r.List(filters)
+	return r.List(ctx, filters)
 }
 
 // PositionRepository implementation
 type positionRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewPositionRepository(db *sql.DB) interfaces.PositionRepository {
+func NewPositionRepository(db DBTX) interfaces.PositionRepository {
 	return &positionRepository{db: db}
 }
 
-func (r *positionRepository) Create(position *models.Position) error {
+func (r *positionRepository) Create(ctx context.Context, position *models.Position) error {
 	query := `INSERT INTO positions (id, name, position_code, min_staff_per_branch, display_order, position_type, manpower_type) 
 	          VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING created_at`
-	return r.db.QueryRow(query, position.ID, position.Name, position.PositionCode, position.MinStaffPerBranch,
+	return r.db.QueryRowContext(ctx, query, position.ID, position.Name, position.PositionCode, position.MinStaffPerBranch,
 		position.DisplayOrder, position.PositionType, position.ManpowerType).Scan(&position.CreatedAt)
 }
 
-func (r *positionRepository) GetByID(id uuid.UUID) (*models.Position, error) {
+func (r *positionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Position, error) {
 	position := &models.Position{}
 	query := `SELECT id, name, position_code, min_staff_per_branch, display_order, position_type, manpower_type, created_at 
 	          FROM positions WHERE id = $1`
 	var positionCode sql.NullString
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&position.ID, &position.Name, &positionCode, &position.MinStaffPerBranch,
 		&position.DisplayOrder, &position.PositionType, &position.ManpowerType, &position.CreatedAt,
 	)
@@ -541,13 +632,13 @@ func (r *positionRepository) GetByID(id uuid.UUID) (*models.Position, error) {
 	return position, nil
 }
 
-func (r *positionRepository) Update(position *models.Position) error {
+func (r *positionRepository) Update(ctx context.Context, position *models.Position) error {
 	query := `UPDATE positions SET name = $1, position_code = $2, display_order = $3, position_type = $4, manpower_type = $5 WHERE id = $6`
-	_, err := r.db.Exec(query, position.Name, position.PositionCode, position.DisplayOrder, position.PositionType, position.ManpowerType, position.ID)
+	_, err := r.db.ExecContext(ctx, query, position.Name, position.PositionCode, position.DisplayOrder, position.PositionType, position.ManpowerType, position.ID)
 	return err
 }
 
-func (r *positionRepository) HasAssociatedStaff(id uuid.UUID) (bool, error) {
+func (r *positionRepository) HasAssociatedStaff(ctx context.Context, id uuid.UUID) (bool, error) {
 	// Check all tables that reference positions via foreign keys:
 	// 1. staff (position_id)
 	// 2. position_quotas (position_id)
@@ -562,20 +653,20 @@ func (r *positionRepository) HasAssociatedStaff(id uuid.UUID) (bool, error) {
 			(SELECT COUNT(*) FROM scenario_position_requirements WHERE position_id = $1) as total_count
 	`
 	var count int
-	err := r.db.QueryRow(query, id).Scan(&count)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&count)
 	if err != nil {
 		return false, err
 	}
 	return count > 0, nil
 }
 
-func (r *positionRepository) Delete(id uuid.UUID) error {
+func (r *positionRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM positions WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *positionRepository) List() ([]*models.Position, error) {
+func (r *positionRepository) List(ctx context.Context) ([]*models.Position, error) {
 	query := `SELECT p.id, p.name, p.position_code, p.min_staff_per_branch, p.display_order, p.position_type, p.manpower_type, p.created_at,
 	          COALESCE(COUNT(CASE WHEN s.staff_type = 'branch' THEN 1 END), 0) as branch_staff_count,
 	          COALESCE(COUNT(CASE WHEN s.staff_type = 'rotation' THEN 1 END), 0) as rotation_staff_count
@@ -583,7 +674,7 @@ func (r *positionRepository) List() ([]*models.Position, error) {
 	          LEFT JOIN staff s ON p.id = s.position_id
 	          GROUP BY p.id, p.name, p.position_code, p.min_staff_per_branch, p.display_order, p.position_type, p.manpower_type, p.created_at
 	          ORDER BY p.display_order, p.name`
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -613,30 +704,31 @@ func (r *positionRepository) List() ([]*models.Position, error) {
 
 // BranchRepository implementation
 type branchRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewBranchRepository(db *sql.DB) interfaces.BranchRepository {
+func NewBranchRepository(db DBTX) interfaces.BranchRepository {
 	return &branchRepository{db: db}
 }
 
-func (r *branchRepository) Create(branch *models.Branch) error {
-	query := `INSERT INTO branches (id, name, code, area_manager_id, branch_type_id, priority) 
-	          VALUES ($1, $2, $3, $4, $5, $6) RETURNING created_at, updated_at`
-	return r.db.QueryRow(query, branch.ID, branch.Name, branch.Code,
-		branch.AreaManagerID, branch.BranchTypeID, branch.Priority).
+func (r *branchRepository) Create(ctx context.Context, branch *models.Branch) error {
+	query := `INSERT INTO branches (id, name, code, area_manager_id, branch_type_id, priority, max_doctors) 
+	          VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING created_at, updated_at`
+	return r.db.QueryRowContext(ctx, query, branch.ID, branch.Name, branch.Code,
+		branch.AreaManagerID, branch.BranchTypeID, branch.Priority, branch.MaxDoctors).
 		Scan(&branch.CreatedAt, &branch.UpdatedAt)
 }
 
-func (r *branchRepository) GetByID(id uuid.UUID) (*models.Branch, error) {
+func (r *branchRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Branch, error) {
 	branch := &models.Branch{}
-	query := `SELECT id, name, code, area_manager_id, branch_type_id, priority, created_at, updated_at 
+	query := `SELECT id, name, code, area_manager_id, branch_type_id, priority, max_doctors, created_at, updated_at 
 	          FROM branches WHERE id = $1`
 	var areaManagerID sql.NullString
+	var maxDoctors sql.NullInt64
 	var branchTypeID sql.NullString
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&branch.ID, &branch.Name, &branch.Code,
-		&areaManagerID, &branchTypeID, &branch.Priority,
+		&areaManagerID, &branchTypeID, &branch.Priority, &maxDoctors,
 		&branch.CreatedAt, &branch.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
@@ -649,6 +741,10 @@ func (r *branchRepository) GetByID(id uuid.UUID) (*models.Branch, error) {
 		amID, _ := uuid.Parse(areaManagerID.String)
 		branch.AreaManagerID = &amID
 	}
+	if maxDoctors.Valid {
+		capacity := int(maxDoctors.Int64)
+		branch.MaxDoctors = &capacity
+	}
 	if branchTypeID.Valid {
 		btID, _ := uuid.Parse(branchTypeID.String)
 		branch.BranchTypeID = &btID
@@ -656,29 +752,29 @@ func (r *branchRepository) GetByID(id uuid.UUID) (*models.Branch, error) {
 	return branch, nil
 }
 
-func (r *branchRepository) Update(branch *models.Branch) error {
+func (r *branchRepository) Update(ctx context.Context, branch *models.Branch) error {
 	query := `UPDATE branches SET name = $1, code = $2, area_manager_id = $3, branch_type_id = $4,
-	          priority = $5, updated_at = CURRENT_TIMESTAMP WHERE id = $6`
-	_, err := r.db.Exec(query, branch.Name, branch.Code,
-		branch.AreaManagerID, branch.BranchTypeID, branch.Priority, branch.ID)
+	          priority = $5, max_doctors = $6, updated_at = CURRENT_TIMESTAMP WHERE id = $7`
+	_, err := r.db.ExecContext(ctx, query, branch.Name, branch.Code,
+		branch.AreaManagerID, branch.BranchTypeID, branch.Priority, branch.MaxDoctors, branch.ID)
 	return err
 }
 
-func (r *branchRepository) Delete(id uuid.UUID) error {
+func (r *branchRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM branches WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *branchRepository) List() ([]*models.Branch, error) {
+func (r *branchRepository) List(ctx context.Context) ([]*models.Branch, error) {
 	query := `SELECT 
-	          b.id, b.name, b.code, b.area_manager_id, b.branch_type_id, b.priority, b.created_at, b.updated_at,
+	          b.id, b.name, b.code, b.area_manager_id, b.branch_type_id, b.priority, b.max_doctors, b.created_at, b.updated_at,
 	          bt.id as branch_type_id_full, bt.name as branch_type_name, bt.description as branch_type_description, 
 	          bt.is_active as branch_type_is_active, bt.created_at as branch_type_created_at, bt.updated_at as branch_type_updated_at
 	          FROM branches b
 	          LEFT JOIN branch_types bt ON b.branch_type_id = bt.id
 	          ORDER BY b.code`
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -688,6 +784,7 @@ func (r *branchRepository) List() ([]*models.Branch, error) {
 	for rows.Next() {
 		branch := &models.Branch{}
 		var areaManagerID sql.NullString
+		var maxDoctors sql.NullInt64
 		var branchTypeID sql.NullString
 		var branchTypeIDFull sql.NullString
 		var branchTypeName sql.NullString
@@ -697,7 +794,7 @@ func (r *branchRepository) List() ([]*models.Branch, error) {
 		var branchTypeUpdatedAt sql.NullTime
 		if err := rows.Scan(
 			&branch.ID, &branch.Name, &branch.Code,
-			&areaManagerID, &branchTypeID, &branch.Priority,
+			&areaManagerID, &branchTypeID, &branch.Priority, &maxDoctors,
 			&branch.CreatedAt, &branch.UpdatedAt,
 			&branchTypeIDFull, &branchTypeName, &branchTypeDescription,
 			&branchTypeIsActive, &branchTypeCreatedAt, &branchTypeUpdatedAt,
@@ -708,6 +805,10 @@ func (r *branchRepository) List() ([]*models.Branch, error) {
 			amID, _ := uuid.Parse(areaManagerID.String)
 			branch.AreaManagerID = &amID
 		}
+		if maxDoctors.Valid {
+			capacity := int(maxDoctors.Int64)
+			branch.MaxDoctors = &capacity
+		}
 		if branchTypeID.Valid {
 			btID, _ := uuid.Parse(branchTypeID.String)
 			branch.BranchTypeID = &btID
@@ -733,10 +834,10 @@ func (r *branchRepository) List() ([]*models.Branch, error) {
 	return branches, rows.Err()
 }
 
-func (r *branchRepository) GetByAreaManagerID(areaManagerID uuid.UUID) ([]*models.Branch, error) {
-	query := `SELECT id, name, code, area_manager_id, branch_type_id, priority, created_at, updated_at 
+func (r *branchRepository) GetByAreaManagerID(ctx context.Context, areaManagerID uuid.UUID) ([]*models.Branch, error) {
+	query := `SELECT id, name, code, area_manager_id, branch_type_id, priority, max_doctors, created_at, updated_at 
 	          FROM branches WHERE area_manager_id = $1 ORDER BY code`
-	rows, err := r.db.Query(query, areaManagerID)
+	rows, err := r.db.QueryContext(ctx, query, areaManagerID)
 	if err != nil {
 		return nil, err
 	}
@@ -746,10 +847,11 @@ func (r *branchRepository) GetByAreaManagerID(areaManagerID uuid.UUID) ([]*model
 	for rows.Next() {
 		branch := &models.Branch{}
 		var areaManagerID sql.NullString
+		var maxDoctors sql.NullInt64
 		var branchTypeID sql.NullString
 		if err := rows.Scan(
 			&branch.ID, &branch.Name, &branch.Code,
-			&areaManagerID, &branchTypeID, &branch.Priority,
+			&areaManagerID, &branchTypeID, &branch.Priority, &maxDoctors,
 			&branch.CreatedAt, &branch.UpdatedAt,
 		); err != nil {
 			return nil, err
@@ -758,6 +860,10 @@ func (r *branchRepository) GetByAreaManagerID(areaManagerID uuid.UUID) ([]*model
 			amID, _ := uuid.Parse(areaManagerID.String)
 			branch.AreaManagerID = &amID
 		}
+		if maxDoctors.Valid {
+			capacity := int(maxDoctors.Int64)
+			branch.MaxDoctors = &capacity
+		}
 		if branchTypeID.Valid {
 			btID, _ := uuid.Parse(branchTypeID.String)
 			branch.BranchTypeID = &btID
@@ -769,14 +875,14 @@ func (r *branchRepository) GetByAreaManagerID(areaManagerID uuid.UUID) ([]*model
 
 // EffectiveBranchRepository implementation
 type effectiveBranchRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewEffectiveBranchRepository(db *sql.DB) interfaces.EffectiveBranchRepository {
+func NewEffectiveBranchRepository(db DBTX) interfaces.EffectiveBranchRepository {
 	return &effectiveBranchRepository{db: db}
 }
 
-func (r *effectiveBranchRepository) Create(eb *models.EffectiveBranch) error {
+func (r *effectiveBranchRepository) Create(ctx context.Context, eb *models.EffectiveBranch) error {
 	// Set defaults if not provided
 	commuteDuration := 300
 	if eb.CommuteDurationMinutes != nil {
@@ -793,17 +899,17 @@ func (r *effectiveBranchRepository) Create(eb *models.EffectiveBranch) error {
 
 	query := `INSERT INTO effective_branches (id, rotation_staff_id, branch_id, level, commute_duration_minutes, transit_count, travel_cost) 
 	          VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING created_at`
-	return r.db.QueryRow(query, eb.ID, eb.RotationStaffID, eb.BranchID, eb.Level, commuteDuration, transitCount, travelCost).
+	return r.db.QueryRowContext(ctx, query, eb.ID, eb.RotationStaffID, eb.BranchID, eb.Level, commuteDuration, transitCount, travelCost).
 		Scan(&eb.CreatedAt)
 }
 
-func (r *effectiveBranchRepository) GetByID(id uuid.UUID) (*models.EffectiveBranch, error) {
+func (r *effectiveBranchRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.EffectiveBranch, error) {
 	query := `SELECT id, rotation_staff_id, branch_id, level, commute_duration_minutes, transit_count, travel_cost, created_at 
 	          FROM effective_branches WHERE id = $1`
 	eb := &models.EffectiveBranch{}
 	var commuteDuration, transitCount sql.NullInt64
 	var travelCost sql.NullFloat64
-	err := r.db.QueryRow(query, id).Scan(&eb.ID, &eb.RotationStaffID, &eb.BranchID, &eb.Level, &commuteDuration, &transitCount, &travelCost, &eb.CreatedAt)
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&eb.ID, &eb.RotationStaffID, &eb.BranchID, &eb.Level, &commuteDuration, &transitCount, &travelCost, &eb.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -822,10 +928,10 @@ func (r *effectiveBranchRepository) GetByID(id uuid.UUID) (*models.EffectiveBran
 	return eb, nil
 }
 
-func (r *effectiveBranchRepository) GetByRotationStaffID(rotationStaffID uuid.UUID) ([]*models.EffectiveBranch, error) {
+func (r *effectiveBranchRepository) GetByRotationStaffID(ctx context.Context, rotationStaffID uuid.UUID) ([]*models.EffectiveBranch, error) {
 	query := `SELECT id, rotation_staff_id, branch_id, level, commute_duration_minutes, transit_count, travel_cost, created_at 
 	          FROM effective_branches WHERE rotation_staff_id = $1 ORDER BY level, created_at`
-	rows, err := r.db.Query(query, rotationStaffID)
+	rows, err := r.db.QueryContext(ctx, query, rotationStaffID)
 	if err != nil {
 		return nil, err
 	}
@@ -856,10 +962,10 @@ func (r *effectiveBranchRepository) GetByRotationStaffID(rotationStaffID uuid.UU
 	return ebs, rows.Err()
 }
 
-func (r *effectiveBranchRepository) GetByBranchID(branchID uuid.UUID) ([]*models.EffectiveBranch, error) {
+func (r *effectiveBranchRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID) ([]*models.EffectiveBranch, error) {
 	query := `SELECT id, rotation_staff_id, branch_id, level, commute_duration_minutes, transit_count, travel_cost, created_at 
 	          FROM effective_branches WHERE branch_id = $1 ORDER BY level, created_at`
-	rows, err := r.db.Query(query, branchID)
+	rows, err := r.db.QueryContext(ctx, query, branchID)
 	if err != nil {
 		return nil, err
 	}
@@ -890,7 +996,7 @@ func (r *effectiveBranchRepository) GetByBranchID(branchID uuid.UUID) ([]*models
 	return ebs, rows.Err()
 }
 
-func (r *effectiveBranchRepository) Update(eb *models.EffectiveBranch) error {
+func (r *effectiveBranchRepository) Update(ctx context.Context, eb *models.EffectiveBranch) error {
 	// Set defaults if not provided
 	commuteDuration := 300
 	if eb.CommuteDurationMinutes != nil {
@@ -909,48 +1015,48 @@ func (r *effectiveBranchRepository) Update(eb *models.EffectiveBranch) error {
 	          SET rotation_staff_id = $2, branch_id = $3, level = $4, 
 	              commute_duration_minutes = $5, transit_count = $6, travel_cost = $7
 	          WHERE id = $1`
-	_, err := r.db.Exec(query, eb.ID, eb.RotationStaffID, eb.BranchID, eb.Level, commuteDuration, transitCount, travelCost)
+	_, err := r.db.ExecContext(ctx, query, eb.ID, eb.RotationStaffID, eb.BranchID, eb.Level, commuteDuration, transitCount, travelCost)
 	return err
 }
 
-func (r *effectiveBranchRepository) Delete(id uuid.UUID) error {
+func (r *effectiveBranchRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM effective_branches WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *effectiveBranchRepository) DeleteByRotationStaffID(rotationStaffID uuid.UUID) error {
+func (r *effectiveBranchRepository) DeleteByRotationStaffID(ctx context.Context, rotationStaffID uuid.UUID) error {
 	query := `DELETE FROM effective_branches WHERE rotation_staff_id = $1`
-	_, err := r.db.Exec(query, rotationStaffID)
+	_, err := r.db.ExecContext(ctx, query, rotationStaffID)
 	return err
 }
 
 // RevenueRepository implementation
 type revenueRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewRevenueRepository(db *sql.DB) interfaces.RevenueRepository {
+func NewRevenueRepository(db DBTX) interfaces.RevenueRepository {
 	return &revenueRepository{db: db}
 }
 
-func (r *revenueRepository) Create(revenue *models.RevenueData) error {
+func (r *revenueRepository) Create(ctx context.Context, revenue *models.RevenueData) error {
 	revenueSource := revenue.RevenueSource
 	if revenueSource == "" {
 		revenueSource = "branch" // Default
 	}
 	query := `INSERT INTO revenue_data (id, branch_id, date, expected_revenue, skin_revenue, ls_hm_revenue, vitamin_cases, slim_pen_cases, actual_revenue, revenue_source) 
 	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING created_at, updated_at`
-	return r.db.QueryRow(query, revenue.ID, revenue.BranchID, revenue.Date,
+	return r.db.QueryRowContext(ctx, query, revenue.ID, revenue.BranchID, revenue.Date,
 		revenue.ExpectedRevenue, revenue.SkinRevenue, revenue.LSHMRevenue, revenue.VitaminCases, revenue.SlimPenCases,
 		revenue.ActualRevenue, revenueSource).
 		Scan(&revenue.CreatedAt, &revenue.UpdatedAt)
 }
 
-func (r *revenueRepository) GetByBranchID(branchID uuid.UUID, startDate, endDate time.Time) ([]*models.RevenueData, error) {
+func (r *revenueRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) ([]*models.RevenueData, error) {
 	query := `SELECT id, branch_id, date, expected_revenue, skin_revenue, ls_hm_revenue, vitamin_cases, slim_pen_cases, actual_revenue, COALESCE(revenue_source, 'branch') as revenue_source, created_at, updated_at 
 	          FROM revenue_data WHERE branch_id = $1 AND date >= $2 AND date <= $3 ORDER BY date`
-	rows, err := r.db.Query(query, branchID, startDate, endDate)
+	rows, err := r.db.QueryContext(ctx, query, branchID, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -982,10 +1088,10 @@ func (r *revenueRepository) GetByBranchID(branchID uuid.UUID, startDate, endDate
 	return revenues, rows.Err()
 }
 
-func (r *revenueRepository) GetByDate(date time.Time) ([]*models.RevenueData, error) {
+func (r *revenueRepository) GetByDate(ctx context.Context, date time.Time) ([]*models.RevenueData, error) {
 	query := `SELECT id, branch_id, date, expected_revenue, skin_revenue, ls_hm_revenue, vitamin_cases, slim_pen_cases, actual_revenue, COALESCE(revenue_source, 'branch') as revenue_source, created_at, updated_at 
 	          FROM revenue_data WHERE date = $1 ORDER BY branch_id`
-	rows, err := r.db.Query(query, date)
+	rows, err := r.db.QueryContext(ctx, query, date)
 	if err != nil {
 		return nil, err
 	}
@@ -1017,24 +1123,63 @@ func (r *revenueRepository) GetByDate(date time.Time) ([]*models.RevenueData, er
 	return revenues, rows.Err()
 }
 
-func (r *revenueRepository) Update(revenue *models.RevenueData) error {
+func (r *revenueRepository) GetByBranchIDsAndDateRange(ctx context.Context, branchIDs []uuid.UUID, startDate, endDate time.Time) ([]*models.RevenueData, error) {
+	if len(branchIDs) == 0 {
+		return []*models.RevenueData{}, nil
+	}
+
+	query := `SELECT id, branch_id, date, expected_revenue, skin_revenue, ls_hm_revenue, vitamin_cases, slim_pen_cases, actual_revenue, COALESCE(revenue_source, 'branch') as revenue_source, created_at, updated_at
+	          FROM revenue_data WHERE branch_id = ANY($1) AND date >= $2 AND date <= $3 ORDER BY branch_id, date`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(branchIDs), startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revenues := []*models.RevenueData{}
+	for rows.Next() {
+		revenue := &models.RevenueData{}
+		var actualRevenue sql.NullFloat64
+		var revenueSource sql.NullString
+		if err := rows.Scan(
+			&revenue.ID, &revenue.BranchID, &revenue.Date,
+			&revenue.ExpectedRevenue, &revenue.SkinRevenue, &revenue.LSHMRevenue, &revenue.VitaminCases, &revenue.SlimPenCases,
+			&actualRevenue, &revenueSource,
+			&revenue.CreatedAt, &revenue.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if actualRevenue.Valid {
+			revenue.ActualRevenue = &actualRevenue.Float64
+		}
+		if revenueSource.Valid {
+			revenue.RevenueSource = revenueSource.String
+		} else {
+			revenue.RevenueSource = "branch" // Default
+		}
+		revenues = append(revenues, revenue)
+	}
+	return revenues, rows.Err()
+}
+
+func (r *revenueRepository) Update(ctx context.Context, revenue *models.RevenueData) error {
 	revenueSource := revenue.RevenueSource
 	if revenueSource == "" {
 		revenueSource = "branch" // Default
 	}
 	query := `UPDATE revenue_data SET expected_revenue = $1, skin_revenue = $2, ls_hm_revenue = $3, vitamin_cases = $4, slim_pen_cases = $5, actual_revenue = $6, revenue_source = $7,
 	          updated_at = CURRENT_TIMESTAMP WHERE id = $8`
-	_, err := r.db.Exec(query, revenue.ExpectedRevenue, revenue.SkinRevenue, revenue.LSHMRevenue, revenue.VitaminCases, revenue.SlimPenCases,
+	_, err := r.db.ExecContext(ctx, query, revenue.ExpectedRevenue, revenue.SkinRevenue, revenue.LSHMRevenue, revenue.VitaminCases, revenue.SlimPenCases,
 		revenue.ActualRevenue, revenueSource, revenue.ID)
 	return err
 }
 
-func (r *revenueRepository) BulkCreateOrUpdate(revenues []*models.RevenueData) error {
+func (r *revenueRepository) BulkCreateOrUpdate(ctx context.Context, revenues []*models.RevenueData) error {
 	if len(revenues) == 0 {
 		return nil
 	}
 
-	tx, err := r.db.Begin()
+	tx, err := beginTx(ctx, r.db)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
@@ -1053,7 +1198,7 @@ func (r *revenueRepository) BulkCreateOrUpdate(revenues []*models.RevenueData) e
 	              revenue_source = EXCLUDED.revenue_source,
 	              updated_at = CURRENT_TIMESTAMP`
 
-	stmt, err := tx.Prepare(query)
+	stmt, err := tx.PrepareContext(ctx, query)
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %w", err)
 	}
@@ -1072,7 +1217,8 @@ func (r *revenueRepository) BulkCreateOrUpdate(revenues []*models.RevenueData) e
 		// Normalize date to ensure it's date-only (no time component)
 		dateOnly := time.Date(revenue.Date.Year(), revenue.Date.Month(), revenue.Date.Day(), 0, 0, 0, 0, time.UTC)
 
-		_, err := stmt.Exec(
+		_, err := stmt.ExecContext(
+			ctx,
 			revenue.ID,
 			revenue.BranchID,
 			dateOnly,
@@ -1099,14 +1245,14 @@ func (r *revenueRepository) BulkCreateOrUpdate(revenues []*models.RevenueData) e
 
 // ScheduleRepository implementation
 type scheduleRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewScheduleRepository(db *sql.DB) interfaces.ScheduleRepository {
+func NewScheduleRepository(db DBTX) interfaces.ScheduleRepository {
 	return &scheduleRepository{db: db}
 }
 
-func (r *scheduleRepository) Create(schedule *models.StaffSchedule) error {
+func (r *scheduleRepository) Create(ctx context.Context, schedule *models.StaffSchedule) error {
 	// Set default schedule_status if not provided
 	if schedule.ScheduleStatus == "" {
 		if schedule.IsWorkingDay {
@@ -1128,7 +1274,7 @@ func (r *scheduleRepository) Create(schedule *models.StaffSchedule) error {
 	            is_working_day = EXCLUDED.is_working_day
 	          RETURNING id, created_at`
 	var returnedID uuid.UUID
-	err := r.db.QueryRow(query, schedule.ID, schedule.StaffID, schedule.BranchID,
+	err := r.db.QueryRowContext(ctx, query, schedule.ID, schedule.StaffID, schedule.BranchID,
 		schedule.Date, schedule.ScheduleStatus, schedule.IsWorkingDay, schedule.CreatedBy).
 		Scan(&returnedID, &schedule.CreatedAt)
 	if err != nil {
@@ -1139,10 +1285,10 @@ func (r *scheduleRepository) Create(schedule *models.StaffSchedule) error {
 	return nil
 }
 
-func (r *scheduleRepository) GetByBranchID(branchID uuid.UUID, startDate, endDate time.Time) ([]*models.StaffSchedule, error) {
+func (r *scheduleRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) ([]*models.StaffSchedule, error) {
 	query := `SELECT id, staff_id, branch_id, date, schedule_status, is_working_day, created_by, created_at 
 	          FROM staff_schedules WHERE branch_id = $1 AND date >= $2 AND date <= $3 ORDER BY date, staff_id`
-	rows, err := r.db.Query(query, branchID, startDate, endDate)
+	rows, err := r.db.QueryContext(ctx, query, branchID, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -1173,10 +1319,10 @@ func (r *scheduleRepository) GetByBranchID(branchID uuid.UUID, startDate, endDat
 	return schedules, rows.Err()
 }
 
-func (r *scheduleRepository) GetByStaffID(staffID uuid.UUID, startDate, endDate time.Time) ([]*models.StaffSchedule, error) {
+func (r *scheduleRepository) GetByStaffID(ctx context.Context, staffID uuid.UUID, startDate, endDate time.Time) ([]*models.StaffSchedule, error) {
 	query := `SELECT id, staff_id, branch_id, date, schedule_status, is_working_day, created_by, created_at 
 	          FROM staff_schedules WHERE staff_id = $1 AND date >= $2 AND date <= $3 ORDER BY date`
-	rows, err := r.db.Query(query, staffID, startDate, endDate)
+	rows, err := r.db.QueryContext(ctx, query, staffID, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -1207,7 +1353,7 @@ func (r *scheduleRepository) GetByStaffID(staffID uuid.UUID, startDate, endDate
 	return schedules, rows.Err()
 }
 
-func (r *scheduleRepository) GetByStaffIDs(staffIDs []uuid.UUID, startDate, endDate time.Time) (map[uuid.UUID][]*models.StaffSchedule, error) {
+func (r *scheduleRepository) GetByStaffIDs(ctx context.Context, staffIDs []uuid.UUID, startDate, endDate time.Time) (map[uuid.UUID][]*models.StaffSchedule, error) {
 	if len(staffIDs) == 0 {
 		return make(map[uuid.UUID][]*models.StaffSchedule), nil
 	}
@@ -1216,7 +1362,7 @@ func (r *scheduleRepository) GetByStaffIDs(staffIDs []uuid.UUID, startDate, endD
 	query := `SELECT id, staff_id, branch_id, date, schedule_status, is_working_day, created_by, created_at 
 	          FROM staff_schedules WHERE staff_id = ANY($1) AND date >= $2 AND date <= $3 ORDER BY staff_id, date`
 	
-	rows, err := r.db.Query(query, pq.Array(staffIDs), startDate, endDate)
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(staffIDs), startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -1247,7 +1393,7 @@ func (r *scheduleRepository) GetByStaffIDs(staffIDs []uuid.UUID, startDate, endD
 	return result, rows.Err()
 }
 
-func (r *scheduleRepository) Update(schedule *models.StaffSchedule) error {
+func (r *scheduleRepository) Update(ctx context.Context, schedule *models.StaffSchedule) error {
 	// Set default schedule_status if not provided
 	if schedule.ScheduleStatus == "" {
 		if schedule.IsWorkingDay {
@@ -1260,49 +1406,66 @@ func (r *scheduleRepository) Update(schedule *models.StaffSchedule) error {
 	schedule.IsWorkingDay = (schedule.ScheduleStatus == models.ScheduleStatusWorking)
 
 	query := `UPDATE staff_schedules SET schedule_status = $1, is_working_day = $2 WHERE id = $3`
-	_, err := r.db.Exec(query, schedule.ScheduleStatus, schedule.IsWorkingDay, schedule.ID)
+	_, err := r.db.ExecContext(ctx, query, schedule.ScheduleStatus, schedule.IsWorkingDay, schedule.ID)
 	return err
 }
 
-func (r *scheduleRepository) Delete(id uuid.UUID) error {
+func (r *scheduleRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM staff_schedules WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *scheduleRepository) DeleteByStaffID(staffID uuid.UUID) error {
+func (r *scheduleRepository) DeleteByStaffID(ctx context.Context, staffID uuid.UUID) error {
 	query := `DELETE FROM staff_schedules WHERE staff_id = $1`
-	_, err := r.db.Exec(query, staffID)
+	_, err := r.db.ExecContext(ctx, query, staffID)
 	return err
 }
 
-func (r *scheduleRepository) GetMonthlyView(branchID uuid.UUID, year int, month int) ([]*models.StaffSchedule, error) {
+func (r *scheduleRepository) GetMonthlyView(ctx context.Context, branchID uuid.UUID, year int, month int) ([]*models.StaffSchedule, error) {
 	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
 	endDate := startDate.AddDate(0, 1, -1)
-	return r.GetByBranchID(branchID, startDate, endDate)
+	return r.GetByBranchID(ctx, branchID, startDate, endDate)
 }
 
 // RotationRepository implementation
 type rotationRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewRotationRepository(db *sql.DB) interfaces.RotationRepository {
+func NewRotationRepository(db DBTX) interfaces.RotationRepository {
 	return &rotationRepository{db: db}
 }
 
-func (r *rotationRepository) Create(assignment *models.RotationAssignment) error {
+func (r *rotationRepository) Create(ctx context.Context, assignment *models.RotationAssignment) error {
 	query := `INSERT INTO rotation_assignments (id, rotation_staff_id, branch_id, date, assignment_level, assigned_by) 
 	          VALUES ($1, $2, $3, $4, $5, $6) RETURNING created_at`
-	return r.db.QueryRow(query, assignment.ID, assignment.RotationStaffID, assignment.BranchID,
+	return r.db.QueryRowContext(ctx, query, assignment.ID, assignment.RotationStaffID, assignment.BranchID,
 		assignment.Date, assignment.AssignmentLevel, assignment.AssignedBy).
 		Scan(&assignment.CreatedAt)
 }
 
-func (r *rotationRepository) GetByDate(date time.Time) ([]*models.RotationAssignment, error) {
+func (r *rotationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.RotationAssignment, error) {
+	query := `SELECT id, rotation_staff_id, branch_id, date, assignment_level, assigned_by, created_at 
+	          FROM rotation_assignments WHERE id = $1`
+	assignment := &models.RotationAssignment{}
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&assignment.ID, &assignment.RotationStaffID, &assignment.BranchID, &assignment.Date,
+		&assignment.AssignmentLevel, &assignment.AssignedBy, &assignment.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
+
+func (r *rotationRepository) GetByDate(ctx context.Context, date time.Time) ([]*models.RotationAssignment, error) {
 	query := `SELECT id, rotation_staff_id, branch_id, date, assignment_level, assigned_by, created_at 
 	          FROM rotation_assignments WHERE date = $1 ORDER BY branch_id, rotation_staff_id`
-	rows, err := r.db.Query(query, date)
+	rows, err := r.db.QueryContext(ctx, query, date)
 	if err != nil {
 		return nil, err
 	}
@@ -1322,10 +1485,10 @@ func (r *rotationRepository) GetByDate(date time.Time) ([]*models.RotationAssign
 	return assignments, rows.Err()
 }
 
-func (r *rotationRepository) GetByBranchID(branchID uuid.UUID, startDate, endDate time.Time) ([]*models.RotationAssignment, error) {
+func (r *rotationRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) ([]*models.RotationAssignment, error) {
 	query := `SELECT id, rotation_staff_id, branch_id, date, assignment_level, assigned_by, created_at 
 	          FROM rotation_assignments WHERE branch_id = $1 AND date >= $2 AND date <= $3 ORDER BY date`
-	rows, err := r.db.Query(query, branchID, startDate, endDate)
+	rows, err := r.db.QueryContext(ctx, query, branchID, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -1345,10 +1508,10 @@ func (r *rotationRepository) GetByBranchID(branchID uuid.UUID, startDate, endDat
 	return assignments, rows.Err()
 }
 
-func (r *rotationRepository) GetByRotationStaffID(rotationStaffID uuid.UUID, startDate, endDate time.Time) ([]*models.RotationAssignment, error) {
+func (r *rotationRepository) GetByRotationStaffID(ctx context.Context, rotationStaffID uuid.UUID, startDate, endDate time.Time) ([]*models.RotationAssignment, error) {
 	query := `SELECT id, rotation_staff_id, branch_id, date, assignment_level, assigned_by, created_at 
 	          FROM rotation_assignments WHERE rotation_staff_id = $1 AND date >= $2 AND date <= $3 ORDER BY date`
-	rows, err := r.db.Query(query, rotationStaffID, startDate, endDate)
+	rows, err := r.db.QueryContext(ctx, query, rotationStaffID, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -1368,19 +1531,19 @@ func (r *rotationRepository) GetByRotationStaffID(rotationStaffID uuid.UUID, sta
 	return assignments, rows.Err()
 }
 
-func (r *rotationRepository) Delete(id uuid.UUID) error {
+func (r *rotationRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM rotation_assignments WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *rotationRepository) DeleteByRotationStaffID(rotationStaffID uuid.UUID) error {
+func (r *rotationRepository) DeleteByRotationStaffID(ctx context.Context, rotationStaffID uuid.UUID) error {
 	query := `DELETE FROM rotation_assignments WHERE rotation_staff_id = $1`
-	_, err := r.db.Exec(query, rotationStaffID)
+	_, err := r.db.ExecContext(ctx, query, rotationStaffID)
 	return err
 }
 
-func (r *rotationRepository) GetAssignments(filters interfaces.RotationFilters) ([]*models.RotationAssignment, error) {
+func (r *rotationRepository) GetAssignments(ctx context.Context, filters interfaces.RotationFilters) ([]*models.RotationAssignment, error) {
 	query := `SELECT id, rotation_staff_id, branch_id, date, assignment_level, assigned_by, created_at 
 	          FROM rotation_assignments WHERE 1=1`
 	args := []interface{}{}
@@ -1409,7 +1572,7 @@ func (r *rotationRepository) GetAssignments(filters interfaces.RotationFilters)
 
 	query += ` ORDER BY date, branch_id`
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -1431,28 +1594,28 @@ func (r *rotationRepository) GetAssignments(filters interfaces.RotationFilters)
 
 // RotationStaffScheduleRepository implementation
 type rotationStaffScheduleRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewRotationStaffScheduleRepository(db *sql.DB) interfaces.RotationStaffScheduleRepository {
+func NewRotationStaffScheduleRepository(db DBTX) interfaces.RotationStaffScheduleRepository {
 	return &rotationStaffScheduleRepository{db: db}
 }
 
-func (r *rotationStaffScheduleRepository) Create(schedule *models.RotationStaffSchedule) error {
+func (r *rotationStaffScheduleRepository) Create(ctx context.Context, schedule *models.RotationStaffSchedule) error {
 	query := `INSERT INTO rotation_staff_schedules (id, rotation_staff_id, date, schedule_status, created_by) 
 	          VALUES ($1, $2, $3, $4, $5) 
 	          ON CONFLICT (rotation_staff_id, date) 
 	          DO UPDATE SET schedule_status = EXCLUDED.schedule_status, updated_at = CURRENT_TIMESTAMP
 	          RETURNING created_at, updated_at`
-	return r.db.QueryRow(query, schedule.ID, schedule.RotationStaffID, schedule.Date, schedule.ScheduleStatus, schedule.CreatedBy).
+	return r.db.QueryRowContext(ctx, query, schedule.ID, schedule.RotationStaffID, schedule.Date, schedule.ScheduleStatus, schedule.CreatedBy).
 		Scan(&schedule.CreatedAt, &schedule.UpdatedAt)
 }
 
-func (r *rotationStaffScheduleRepository) GetByID(id uuid.UUID) (*models.RotationStaffSchedule, error) {
+func (r *rotationStaffScheduleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.RotationStaffSchedule, error) {
 	query := `SELECT id, rotation_staff_id, date, schedule_status, created_by, created_at, updated_at 
 	          FROM rotation_staff_schedules WHERE id = $1`
 	schedule := &models.RotationStaffSchedule{}
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&schedule.ID, &schedule.RotationStaffID, &schedule.Date, &schedule.ScheduleStatus,
 		&schedule.CreatedBy, &schedule.CreatedAt, &schedule.UpdatedAt,
 	)
@@ -1465,12 +1628,12 @@ func (r *rotationStaffScheduleRepository) GetByID(id uuid.UUID) (*models.Rotatio
 	return schedule, nil
 }
 
-func (r *rotationStaffScheduleRepository) GetByRotationStaffID(rotationStaffID uuid.UUID, startDate, endDate time.Time) ([]*models.RotationStaffSchedule, error) {
+func (r *rotationStaffScheduleRepository) GetByRotationStaffID(ctx context.Context, rotationStaffID uuid.UUID, startDate, endDate time.Time) ([]*models.RotationStaffSchedule, error) {
 	query := `SELECT id, rotation_staff_id, date, schedule_status, created_by, created_at, updated_at 
 	          FROM rotation_staff_schedules 
 	          WHERE rotation_staff_id = $1 AND date >= $2 AND date <= $3 
 	          ORDER BY date`
-	rows, err := r.db.Query(query, rotationStaffID, startDate, endDate)
+	rows, err := r.db.QueryContext(ctx, query, rotationStaffID, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -1490,10 +1653,10 @@ func (r *rotationStaffScheduleRepository) GetByRotationStaffID(rotationStaffID u
 	return schedules, rows.Err()
 }
 
-func (r *rotationStaffScheduleRepository) GetByDate(date time.Time) ([]*models.RotationStaffSchedule, error) {
+func (r *rotationStaffScheduleRepository) GetByDate(ctx context.Context, date time.Time) ([]*models.RotationStaffSchedule, error) {
 	query := `SELECT id, rotation_staff_id, date, schedule_status, created_by, created_at, updated_at 
 	          FROM rotation_staff_schedules WHERE date = $1 ORDER BY rotation_staff_id`
-	rows, err := r.db.Query(query, date)
+	rows, err := r.db.QueryContext(ctx, query, date)
 	if err != nil {
 		return nil, err
 	}
@@ -1513,12 +1676,12 @@ func (r *rotationStaffScheduleRepository) GetByDate(date time.Time) ([]*models.R
 	return schedules, rows.Err()
 }
 
-func (r *rotationStaffScheduleRepository) GetByDateRange(startDate, endDate time.Time) ([]*models.RotationStaffSchedule, error) {
+func (r *rotationStaffScheduleRepository) GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.RotationStaffSchedule, error) {
 	query := `SELECT id, rotation_staff_id, date, schedule_status, created_by, created_at, updated_at 
 	          FROM rotation_staff_schedules 
 	          WHERE date >= $1 AND date <= $2 
 	          ORDER BY rotation_staff_id, date`
-	rows, err := r.db.Query(query, startDate, endDate)
+	rows, err := r.db.QueryContext(ctx, query, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -1538,11 +1701,11 @@ func (r *rotationStaffScheduleRepository) GetByDateRange(startDate, endDate time
 	return schedules, rows.Err()
 }
 
-func (r *rotationStaffScheduleRepository) GetByRotationStaffIDAndDate(rotationStaffID uuid.UUID, date time.Time) (*models.RotationStaffSchedule, error) {
+func (r *rotationStaffScheduleRepository) GetByRotationStaffIDAndDate(ctx context.Context, rotationStaffID uuid.UUID, date time.Time) (*models.RotationStaffSchedule, error) {
 	query := `SELECT id, rotation_staff_id, date, schedule_status, created_by, created_at, updated_at 
 	          FROM rotation_staff_schedules WHERE rotation_staff_id = $1 AND date = $2`
 	schedule := &models.RotationStaffSchedule{}
-	err := r.db.QueryRow(query, rotationStaffID, date).Scan(
+	err := r.db.QueryRowContext(ctx, query, rotationStaffID, date).Scan(
 		&schedule.ID, &schedule.RotationStaffID, &schedule.Date, &schedule.ScheduleStatus,
 		&schedule.CreatedBy, &schedule.CreatedAt, &schedule.UpdatedAt,
 	)
@@ -1555,38 +1718,38 @@ func (r *rotationStaffScheduleRepository) GetByRotationStaffIDAndDate(rotationSt
 	return schedule, nil
 }
 
-func (r *rotationStaffScheduleRepository) Update(schedule *models.RotationStaffSchedule) error {
+func (r *rotationStaffScheduleRepository) Update(ctx context.Context, schedule *models.RotationStaffSchedule) error {
 	query := `UPDATE rotation_staff_schedules 
 	          SET schedule_status = $1, updated_at = CURRENT_TIMESTAMP 
 	          WHERE id = $2 
 	          RETURNING updated_at`
-	return r.db.QueryRow(query, schedule.ScheduleStatus, schedule.ID).Scan(&schedule.UpdatedAt)
+	return r.db.QueryRowContext(ctx, query, schedule.ScheduleStatus, schedule.ID).Scan(&schedule.UpdatedAt)
 }
 
-func (r *rotationStaffScheduleRepository) Delete(id uuid.UUID) error {
+func (r *rotationStaffScheduleRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM rotation_staff_schedules WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *rotationStaffScheduleRepository) DeleteByRotationStaffID(rotationStaffID uuid.UUID) error {
+func (r *rotationStaffScheduleRepository) DeleteByRotationStaffID(ctx context.Context, rotationStaffID uuid.UUID) error {
 	query := `DELETE FROM rotation_staff_schedules WHERE rotation_staff_id = $1`
-	_, err := r.db.Exec(query, rotationStaffID)
+	_, err := r.db.ExecContext(ctx, query, rotationStaffID)
 	return err
 }
 
 // SettingsRepository implementation
 type settingsRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewSettingsRepository(db *sql.DB) interfaces.SettingsRepository {
+func NewSettingsRepository(db DBTX) interfaces.SettingsRepository {
 	return &settingsRepository{db: db}
 }
 
-func (r *settingsRepository) GetAll() ([]*models.SystemSetting, error) {
+func (r *settingsRepository) GetAll(ctx context.Context) ([]*models.SystemSetting, error) {
 	query := `SELECT id, key, value, description, updated_at FROM system_settings ORDER BY key`
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -1603,49 +1766,49 @@ func (r *settingsRepository) GetAll() ([]*models.SystemSetting, error) {
 	return settings, rows.Err()
 }
 
-func (r *settingsRepository) GetByKey(key string) (*models.SystemSetting, error) {
+func (r *settingsRepository) GetByKey(ctx context.Context, key string) (*models.SystemSetting, error) {
 	setting := &models.SystemSetting{}
 	query := `SELECT id, key, value, description, updated_at FROM system_settings WHERE key = $1`
-	err := r.db.QueryRow(query, key).Scan(&setting.ID, &setting.Key, &setting.Value, &setting.Description, &setting.UpdatedAt)
+	err := r.db.QueryRowContext(ctx, query, key).Scan(&setting.ID, &setting.Key, &setting.Value, &setting.Description, &setting.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	return setting, err
 }
 
-func (r *settingsRepository) Update(setting *models.SystemSetting) error {
+func (r *settingsRepository) Update(ctx context.Context, setting *models.SystemSetting) error {
 	query := `UPDATE system_settings SET value = $1, description = $2, updated_at = CURRENT_TIMESTAMP WHERE key = $3`
-	_, err := r.db.Exec(query, setting.Value, setting.Description, setting.Key)
+	_, err := r.db.ExecContext(ctx, query, setting.Value, setting.Description, setting.Key)
 	return err
 }
 
-func (r *settingsRepository) Create(setting *models.SystemSetting) error {
+func (r *settingsRepository) Create(ctx context.Context, setting *models.SystemSetting) error {
 	query := `INSERT INTO system_settings (id, key, value, description) VALUES ($1, $2, $3, $4) RETURNING updated_at`
-	return r.db.QueryRow(query, setting.ID, setting.Key, setting.Value, setting.Description).Scan(&setting.UpdatedAt)
+	return r.db.QueryRowContext(ctx, query, setting.ID, setting.Key, setting.Value, setting.Description).Scan(&setting.UpdatedAt)
 }
 
 // AllocationRuleRepository implementation
 type allocationRuleRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewAllocationRuleRepository(db *sql.DB) interfaces.AllocationRuleRepository {
+func NewAllocationRuleRepository(db DBTX) interfaces.AllocationRuleRepository {
 	return &allocationRuleRepository{db: db}
 }
 
-func (r *allocationRuleRepository) Create(rule *models.StaffAllocationRule) error {
+func (r *allocationRuleRepository) Create(ctx context.Context, rule *models.StaffAllocationRule) error {
 	query := `INSERT INTO staff_allocation_rules (id, position_id, min_staff, revenue_threshold, staff_count_formula) 
 	          VALUES ($1, $2, $3, $4, $5) RETURNING created_at, updated_at`
-	return r.db.QueryRow(query, rule.ID, rule.PositionID, rule.MinStaff,
+	return r.db.QueryRowContext(ctx, query, rule.ID, rule.PositionID, rule.MinStaff,
 		rule.RevenueThreshold, rule.StaffCountFormula).
 		Scan(&rule.CreatedAt, &rule.UpdatedAt)
 }
 
-func (r *allocationRuleRepository) GetByPositionID(positionID uuid.UUID) (*models.StaffAllocationRule, error) {
+func (r *allocationRuleRepository) GetByPositionID(ctx context.Context, positionID uuid.UUID) (*models.StaffAllocationRule, error) {
 	rule := &models.StaffAllocationRule{}
 	query := `SELECT id, position_id, min_staff, revenue_threshold, staff_count_formula, created_at, updated_at 
 	          FROM staff_allocation_rules WHERE position_id = $1`
-	err := r.db.QueryRow(query, positionID).Scan(
+	err := r.db.QueryRowContext(ctx, query, positionID).Scan(
 		&rule.ID, &rule.PositionID, &rule.MinStaff, &rule.RevenueThreshold,
 		&rule.StaffCountFormula, &rule.CreatedAt, &rule.UpdatedAt,
 	)
@@ -1655,17 +1818,17 @@ func (r *allocationRuleRepository) GetByPositionID(positionID uuid.UUID) (*model
 	return rule, err
 }
 
-func (r *allocationRuleRepository) Update(rule *models.StaffAllocationRule) error {
+func (r *allocationRuleRepository) Update(ctx context.Context, rule *models.StaffAllocationRule) error {
 	query := `UPDATE staff_allocation_rules SET min_staff = $1, revenue_threshold = $2, 
 	          staff_count_formula = $3, updated_at = CURRENT_TIMESTAMP WHERE id = $4`
-	_, err := r.db.Exec(query, rule.MinStaff, rule.RevenueThreshold, rule.StaffCountFormula, rule.ID)
+	_, err := r.db.ExecContext(ctx, query, rule.MinStaff, rule.RevenueThreshold, rule.StaffCountFormula, rule.ID)
 	return err
 }
 
-func (r *allocationRuleRepository) List() ([]*models.StaffAllocationRule, error) {
+func (r *allocationRuleRepository) List(ctx context.Context) ([]*models.StaffAllocationRule, error) {
 	query := `SELECT id, position_id, min_staff, revenue_threshold, staff_count_formula, created_at, updated_at 
 	          FROM staff_allocation_rules ORDER BY position_id`
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -1687,25 +1850,25 @@ func (r *allocationRuleRepository) List() ([]*models.StaffAllocationRule, error)
 
 // AreaOfOperationRepository implementation
 type areaOfOperationRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewAreaOfOperationRepository(db *sql.DB) interfaces.AreaOfOperationRepository {
+func NewAreaOfOperationRepository(db DBTX) interfaces.AreaOfOperationRepository {
 	return &areaOfOperationRepository{db: db}
 }
 
-func (r *areaOfOperationRepository) Create(aoo *models.AreaOfOperation) error {
+func (r *areaOfOperationRepository) Create(ctx context.Context, aoo *models.AreaOfOperation) error {
 	query := `INSERT INTO areas_of_operation (id, name, code, description, is_active) 
 	          VALUES ($1, $2, $3, $4, $5) RETURNING created_at, updated_at`
-	return r.db.QueryRow(query, aoo.ID, aoo.Name, aoo.Code, aoo.Description, aoo.IsActive).
+	return r.db.QueryRowContext(ctx, query, aoo.ID, aoo.Name, aoo.Code, aoo.Description, aoo.IsActive).
 		Scan(&aoo.CreatedAt, &aoo.UpdatedAt)
 }
 
-func (r *areaOfOperationRepository) GetByID(id uuid.UUID) (*models.AreaOfOperation, error) {
+func (r *areaOfOperationRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.AreaOfOperation, error) {
 	query := `SELECT id, name, code, description, is_active, created_at, updated_at 
 	          FROM areas_of_operation WHERE id = $1`
 	aoo := &models.AreaOfOperation{}
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&aoo.ID, &aoo.Name, &aoo.Code, &aoo.Description, &aoo.IsActive,
 		&aoo.CreatedAt, &aoo.UpdatedAt,
 	)
@@ -1715,11 +1878,11 @@ func (r *areaOfOperationRepository) GetByID(id uuid.UUID) (*models.AreaOfOperati
 	return aoo, nil
 }
 
-func (r *areaOfOperationRepository) GetByCode(code string) (*models.AreaOfOperation, error) {
+func (r *areaOfOperationRepository) GetByCode(ctx context.Context, code string) (*models.AreaOfOperation, error) {
 	query := `SELECT id, name, code, description, is_active, created_at, updated_at 
 	          FROM areas_of_operation WHERE code = $1`
 	aoo := &models.AreaOfOperation{}
-	err := r.db.QueryRow(query, code).Scan(
+	err := r.db.QueryRowContext(ctx, query, code).Scan(
 		&aoo.ID, &aoo.Name, &aoo.Code, &aoo.Description, &aoo.IsActive,
 		&aoo.CreatedAt, &aoo.UpdatedAt,
 	)
@@ -1729,21 +1892,21 @@ func (r *areaOfOperationRepository) GetByCode(code string) (*models.AreaOfOperat
 	return aoo, nil
 }
 
-func (r *areaOfOperationRepository) Update(aoo *models.AreaOfOperation) error {
+func (r *areaOfOperationRepository) Update(ctx context.Context, aoo *models.AreaOfOperation) error {
 	query := `UPDATE areas_of_operation 
 	          SET name = $1, code = $2, description = $3, is_active = $4, updated_at = CURRENT_TIMESTAMP 
 	          WHERE id = $5 RETURNING updated_at`
-	return r.db.QueryRow(query, aoo.Name, aoo.Code, aoo.Description, aoo.IsActive, aoo.ID).
+	return r.db.QueryRowContext(ctx, query, aoo.Name, aoo.Code, aoo.Description, aoo.IsActive, aoo.ID).
 		Scan(&aoo.UpdatedAt)
 }
 
-func (r *areaOfOperationRepository) Delete(id uuid.UUID) error {
+func (r *areaOfOperationRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM areas_of_operation WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *areaOfOperationRepository) List(includeInactive bool) ([]*models.AreaOfOperation, error) {
+func (r *areaOfOperationRepository) List(ctx context.Context, includeInactive bool) ([]*models.AreaOfOperation, error) {
 	var query string
 	if includeInactive {
 		query = `SELECT id, name, code, description, is_active, created_at, updated_at 
@@ -1753,7 +1916,7 @@ func (r *areaOfOperationRepository) List(includeInactive bool) ([]*models.AreaOf
 		         FROM areas_of_operation WHERE is_active = true ORDER BY name`
 	}
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -1773,28 +1936,28 @@ func (r *areaOfOperationRepository) List(includeInactive bool) ([]*models.AreaOf
 	return areas, rows.Err()
 }
 
-func (r *areaOfOperationRepository) AddZone(areaOfOperationID, zoneID uuid.UUID) error {
+func (r *areaOfOperationRepository) AddZone(ctx context.Context, areaOfOperationID, zoneID uuid.UUID) error {
 	query := `INSERT INTO area_of_operation_zones (id, area_of_operation_id, zone_id) 
 	          VALUES (gen_random_uuid(), $1, $2) 
 	          ON CONFLICT (area_of_operation_id, zone_id) DO NOTHING`
-	_, err := r.db.Exec(query, areaOfOperationID, zoneID)
+	_, err := r.db.ExecContext(ctx, query, areaOfOperationID, zoneID)
 	return err
 }
 
-func (r *areaOfOperationRepository) RemoveZone(areaOfOperationID, zoneID uuid.UUID) error {
+func (r *areaOfOperationRepository) RemoveZone(ctx context.Context, areaOfOperationID, zoneID uuid.UUID) error {
 	query := `DELETE FROM area_of_operation_zones WHERE area_of_operation_id = $1 AND zone_id = $2`
-	_, err := r.db.Exec(query, areaOfOperationID, zoneID)
+	_, err := r.db.ExecContext(ctx, query, areaOfOperationID, zoneID)
 	return err
 }
 
-func (r *areaOfOperationRepository) GetZones(areaOfOperationID uuid.UUID) ([]*models.Zone, error) {
+func (r *areaOfOperationRepository) GetZones(ctx context.Context, areaOfOperationID uuid.UUID) ([]*models.Zone, error) {
 	query := `SELECT z.id, z.name, z.code, z.description, z.is_active, z.created_at, z.updated_at
 	          FROM zones z
 	          INNER JOIN area_of_operation_zones aoz ON z.id = aoz.zone_id
 	          WHERE aoz.area_of_operation_id = $1
 	          ORDER BY z.name`
 
-	rows, err := r.db.Query(query, areaOfOperationID)
+	rows, err := r.db.QueryContext(ctx, query, areaOfOperationID)
 	if err != nil {
 		return nil, err
 	}
@@ -1814,21 +1977,21 @@ func (r *areaOfOperationRepository) GetZones(areaOfOperationID uuid.UUID) ([]*mo
 	return zones, rows.Err()
 }
 
-func (r *areaOfOperationRepository) AddBranch(areaOfOperationID, branchID uuid.UUID) error {
+func (r *areaOfOperationRepository) AddBranch(ctx context.Context, areaOfOperationID, branchID uuid.UUID) error {
 	query := `INSERT INTO area_of_operation_branches (id, area_of_operation_id, branch_id) 
 	          VALUES (gen_random_uuid(), $1, $2) 
 	          ON CONFLICT (area_of_operation_id, branch_id) DO NOTHING`
-	_, err := r.db.Exec(query, areaOfOperationID, branchID)
+	_, err := r.db.ExecContext(ctx, query, areaOfOperationID, branchID)
 	return err
 }
 
-func (r *areaOfOperationRepository) RemoveBranch(areaOfOperationID, branchID uuid.UUID) error {
+func (r *areaOfOperationRepository) RemoveBranch(ctx context.Context, areaOfOperationID, branchID uuid.UUID) error {
 	query := `DELETE FROM area_of_operation_branches WHERE area_of_operation_id = $1 AND branch_id = $2`
-	_, err := r.db.Exec(query, areaOfOperationID, branchID)
+	_, err := r.db.ExecContext(ctx, query, areaOfOperationID, branchID)
 	return err
 }
 
-func (r *areaOfOperationRepository) GetBranches(areaOfOperationID uuid.UUID) ([]*models.Branch, error) {
+func (r *areaOfOperationRepository) GetBranches(ctx context.Context, areaOfOperationID uuid.UUID) ([]*models.Branch, error) {
 	// Get individual branches (not from zones)
 	query := `SELECT b.id, b.name, b.code, b.area_manager_id, b.priority, b.created_at, b.updated_at
 	          FROM branches b
@@ -1836,7 +1999,7 @@ func (r *areaOfOperationRepository) GetBranches(areaOfOperationID uuid.UUID) ([]
 	          WHERE aob.area_of_operation_id = $1
 	          ORDER BY b.name`
 
-	rows, err := r.db.Query(query, areaOfOperationID)
+	rows, err := r.db.QueryContext(ctx, query, areaOfOperationID)
 	if err != nil {
 		return nil, err
 	}
@@ -1863,7 +2026,7 @@ func (r *areaOfOperationRepository) GetBranches(areaOfOperationID uuid.UUID) ([]
 	return branches, rows.Err()
 }
 
-func (r *areaOfOperationRepository) GetAllBranches(areaOfOperationID uuid.UUID) ([]*models.Branch, error) {
+func (r *areaOfOperationRepository) GetAllBranches(ctx context.Context, areaOfOperationID uuid.UUID) ([]*models.Branch, error) {
 	// Get all branches: from zones + individual branches
 	// Using UNION to combine branches from zones and individual branches
 	query := `
@@ -1883,7 +2046,7 @@ func (r *areaOfOperationRepository) GetAllBranches(areaOfOperationID uuid.UUID)
 		)
 		ORDER BY b.name`
 
-	rows, err := r.db.Query(query, areaOfOperationID)
+	rows, err := r.db.QueryContext(ctx, query, areaOfOperationID)
 	if err != nil {
 		return nil, err
 	}
@@ -1912,25 +2075,25 @@ func (r *areaOfOperationRepository) GetAllBranches(areaOfOperationID uuid.UUID)
 
 // ZoneRepository implementation
 type zoneRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewZoneRepository(db *sql.DB) interfaces.ZoneRepository {
+func NewZoneRepository(db DBTX) interfaces.ZoneRepository {
 	return &zoneRepository{db: db}
 }
 
-func (r *zoneRepository) Create(zone *models.Zone) error {
+func (r *zoneRepository) Create(ctx context.Context, zone *models.Zone) error {
 	query := `INSERT INTO zones (id, name, code, description, is_active) 
 	          VALUES ($1, $2, $3, $4, $5) RETURNING created_at, updated_at`
-	return r.db.QueryRow(query, zone.ID, zone.Name, zone.Code, zone.Description, zone.IsActive).
+	return r.db.QueryRowContext(ctx, query, zone.ID, zone.Name, zone.Code, zone.Description, zone.IsActive).
 		Scan(&zone.CreatedAt, &zone.UpdatedAt)
 }
 
-func (r *zoneRepository) GetByID(id uuid.UUID) (*models.Zone, error) {
+func (r *zoneRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Zone, error) {
 	query := `SELECT id, name, code, description, is_active, created_at, updated_at 
 	          FROM zones WHERE id = $1`
 	zone := &models.Zone{}
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&zone.ID, &zone.Name, &zone.Code, &zone.Description, &zone.IsActive,
 		&zone.CreatedAt, &zone.UpdatedAt,
 	)
@@ -1940,11 +2103,11 @@ func (r *zoneRepository) GetByID(id uuid.UUID) (*models.Zone, error) {
 	return zone, nil
 }
 
-func (r *zoneRepository) GetByCode(code string) (*models.Zone, error) {
+func (r *zoneRepository) GetByCode(ctx context.Context, code string) (*models.Zone, error) {
 	query := `SELECT id, name, code, description, is_active, created_at, updated_at 
 	          FROM zones WHERE code = $1`
 	zone := &models.Zone{}
-	err := r.db.QueryRow(query, code).Scan(
+	err := r.db.QueryRowContext(ctx, query, code).Scan(
 		&zone.ID, &zone.Name, &zone.Code, &zone.Description, &zone.IsActive,
 		&zone.CreatedAt, &zone.UpdatedAt,
 	)
@@ -1954,21 +2117,21 @@ func (r *zoneRepository) GetByCode(code string) (*models.Zone, error) {
 	return zone, nil
 }
 
-func (r *zoneRepository) Update(zone *models.Zone) error {
+func (r *zoneRepository) Update(ctx context.Context, zone *models.Zone) error {
 	query := `UPDATE zones 
 	          SET name = $1, code = $2, description = $3, is_active = $4, updated_at = CURRENT_TIMESTAMP 
 	          WHERE id = $5 RETURNING updated_at`
-	return r.db.QueryRow(query, zone.Name, zone.Code, zone.Description, zone.IsActive, zone.ID).
+	return r.db.QueryRowContext(ctx, query, zone.Name, zone.Code, zone.Description, zone.IsActive, zone.ID).
 		Scan(&zone.UpdatedAt)
 }
 
-func (r *zoneRepository) Delete(id uuid.UUID) error {
+func (r *zoneRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM zones WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *zoneRepository) List(includeInactive bool) ([]*models.Zone, error) {
+func (r *zoneRepository) List(ctx context.Context, includeInactive bool) ([]*models.Zone, error) {
 	var query string
 	if includeInactive {
 		query = `SELECT id, name, code, description, is_active, created_at, updated_at 
@@ -1978,7 +2141,7 @@ func (r *zoneRepository) List(includeInactive bool) ([]*models.Zone, error) {
 		         FROM zones WHERE is_active = true ORDER BY name`
 	}
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -1998,28 +2161,28 @@ func (r *zoneRepository) List(includeInactive bool) ([]*models.Zone, error) {
 	return zones, rows.Err()
 }
 
-func (r *zoneRepository) AddBranch(zoneID, branchID uuid.UUID) error {
+func (r *zoneRepository) AddBranch(ctx context.Context, zoneID, branchID uuid.UUID) error {
 	query := `INSERT INTO zone_branches (id, zone_id, branch_id) 
 	          VALUES (gen_random_uuid(), $1, $2) 
 	          ON CONFLICT (zone_id, branch_id) DO NOTHING`
-	_, err := r.db.Exec(query, zoneID, branchID)
+	_, err := r.db.ExecContext(ctx, query, zoneID, branchID)
 	return err
 }
 
-func (r *zoneRepository) RemoveBranch(zoneID, branchID uuid.UUID) error {
+func (r *zoneRepository) RemoveBranch(ctx context.Context, zoneID, branchID uuid.UUID) error {
 	query := `DELETE FROM zone_branches WHERE zone_id = $1 AND branch_id = $2`
-	_, err := r.db.Exec(query, zoneID, branchID)
+	_, err := r.db.ExecContext(ctx, query, zoneID, branchID)
 	return err
 }
 
-func (r *zoneRepository) GetBranches(zoneID uuid.UUID) ([]*models.Branch, error) {
+func (r *zoneRepository) GetBranches(ctx context.Context, zoneID uuid.UUID) ([]*models.Branch, error) {
 	query := `SELECT b.id, b.name, b.code, b.area_manager_id, b.priority, b.created_at, b.updated_at
 	          FROM branches b
 	          INNER JOIN zone_branches zb ON b.id = zb.branch_id
 	          WHERE zb.zone_id = $1
 	          ORDER BY b.name`
 
-	rows, err := r.db.Query(query, zoneID)
+	rows, err := r.db.QueryContext(ctx, query, zoneID)
 	if err != nil {
 		return nil, err
 	}
@@ -2046,8 +2209,8 @@ func (r *zoneRepository) GetBranches(zoneID uuid.UUID) ([]*models.Branch, error)
 	return branches, rows.Err()
 }
 
-func (r *zoneRepository) BulkUpdateBranches(zoneID uuid.UUID, branchIDs []uuid.UUID) error {
-	tx, err := r.db.Begin()
+func (r *zoneRepository) BulkUpdateBranches(ctx context.Context, zoneID uuid.UUID, branchIDs []uuid.UUID) error {
+	tx, err := beginTx(ctx, r.db)
 	if err != nil {
 		return err
 	}
@@ -2055,7 +2218,7 @@ func (r *zoneRepository) BulkUpdateBranches(zoneID uuid.UUID, branchIDs []uuid.U
 
 	// Delete all existing branches for this zone
 	deleteQuery := `DELETE FROM zone_branches WHERE zone_id = $1`
-	if _, err := tx.Exec(deleteQuery, zoneID); err != nil {
+	if _, err := tx.ExecContext(ctx, deleteQuery, zoneID); err != nil {
 		return err
 	}
 
@@ -2063,7 +2226,7 @@ func (r *zoneRepository) BulkUpdateBranches(zoneID uuid.UUID, branchIDs []uuid.U
 	insertQuery := `INSERT INTO zone_branches (id, zone_id, branch_id) 
 	                VALUES (gen_random_uuid(), $1, $2)`
 	for _, branchID := range branchIDs {
-		if _, err := tx.Exec(insertQuery, zoneID, branchID); err != nil {
+		if _, err := tx.ExecContext(ctx, insertQuery, zoneID, branchID); err != nil {
 			return err
 		}
 	}
@@ -2073,25 +2236,25 @@ func (r *zoneRepository) BulkUpdateBranches(zoneID uuid.UUID, branchIDs []uuid.U
 
 // AllocationCriteriaRepository implementation
 type allocationCriteriaRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewAllocationCriteriaRepository(db *sql.DB) interfaces.AllocationCriteriaRepository {
+func NewAllocationCriteriaRepository(db DBTX) interfaces.AllocationCriteriaRepository {
 	return &allocationCriteriaRepository{db: db}
 }
 
-func (r *allocationCriteriaRepository) Create(criteria *models.AllocationCriteria) error {
+func (r *allocationCriteriaRepository) Create(ctx context.Context, criteria *models.AllocationCriteria) error {
 	query := `INSERT INTO allocation_criteria (id, pillar, type, weight, is_active, description, config, created_by) 
 	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING created_at, updated_at`
-	return r.db.QueryRow(query, criteria.ID, criteria.Pillar, criteria.Type, criteria.Weight, criteria.IsActive,
+	return r.db.QueryRowContext(ctx, query, criteria.ID, criteria.Pillar, criteria.Type, criteria.Weight, criteria.IsActive,
 		criteria.Description, criteria.Config, criteria.CreatedBy).Scan(&criteria.CreatedAt, &criteria.UpdatedAt)
 }
 
-func (r *allocationCriteriaRepository) GetByID(id uuid.UUID) (*models.AllocationCriteria, error) {
+func (r *allocationCriteriaRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.AllocationCriteria, error) {
 	query := `SELECT id, pillar, type, weight, is_active, description, config, created_by, created_at, updated_at 
 	          FROM allocation_criteria WHERE id = $1`
 	criteria := &models.AllocationCriteria{}
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&criteria.ID, &criteria.Pillar, &criteria.Type, &criteria.Weight, &criteria.IsActive,
 		&criteria.Description, &criteria.Config, &criteria.CreatedBy, &criteria.CreatedAt, &criteria.UpdatedAt,
 	)
@@ -2101,21 +2264,21 @@ func (r *allocationCriteriaRepository) GetByID(id uuid.UUID) (*models.Allocation
 	return criteria, err
 }
 
-func (r *allocationCriteriaRepository) Update(criteria *models.AllocationCriteria) error {
+func (r *allocationCriteriaRepository) Update(ctx context.Context, criteria *models.AllocationCriteria) error {
 	query := `UPDATE allocation_criteria 
 	          SET pillar = $1, type = $2, weight = $3, is_active = $4, description = $5, config = $6, updated_at = CURRENT_TIMESTAMP 
 	          WHERE id = $7 RETURNING updated_at`
-	return r.db.QueryRow(query, criteria.Pillar, criteria.Type, criteria.Weight, criteria.IsActive,
+	return r.db.QueryRowContext(ctx, query, criteria.Pillar, criteria.Type, criteria.Weight, criteria.IsActive,
 		criteria.Description, criteria.Config, criteria.ID).Scan(&criteria.UpdatedAt)
 }
 
-func (r *allocationCriteriaRepository) Delete(id uuid.UUID) error {
+func (r *allocationCriteriaRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM allocation_criteria WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *allocationCriteriaRepository) List(filters interfaces.AllocationCriteriaFilters) ([]*models.AllocationCriteria, error) {
+func (r *allocationCriteriaRepository) List(ctx context.Context, filters interfaces.AllocationCriteriaFilters) ([]*models.AllocationCriteria, error) {
 	query := `SELECT id, pillar, type, weight, is_active, description, config, created_by, created_at, updated_at 
 	          FROM allocation_criteria WHERE 1=1`
 	args := []interface{}{}
@@ -2139,7 +2302,7 @@ func (r *allocationCriteriaRepository) List(filters interfaces.AllocationCriteri
 
 	query += " ORDER BY pillar, type"
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -2159,32 +2322,32 @@ func (r *allocationCriteriaRepository) List(filters interfaces.AllocationCriteri
 	return criteriaList, rows.Err()
 }
 
-func (r *allocationCriteriaRepository) GetByPillar(pillar models.CriteriaPillar) ([]*models.AllocationCriteria, error) {
+func (r *allocationCriteriaRepository) GetByPillar(ctx context.Context, pillar models.CriteriaPillar) ([]*models.AllocationCriteria, error) {
 	filters := interfaces.AllocationCriteriaFilters{Pillar: &pillar, IsActive: &[]bool{true}[0]}
-	return r.List(filters)
+	return r.List(ctx, filters)
 }
 
 // PositionQuotaRepository implementation
 type positionQuotaRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewPositionQuotaRepository(db *sql.DB) interfaces.PositionQuotaRepository {
+func NewPositionQuotaRepository(db DBTX) interfaces.PositionQuotaRepository {
 	return &positionQuotaRepository{db: db}
 }
 
-func (r *positionQuotaRepository) Create(quota *models.PositionQuota) error {
+func (r *positionQuotaRepository) Create(ctx context.Context, quota *models.PositionQuota) error {
 	query := `INSERT INTO position_quotas (id, branch_id, position_id, designated_quota, minimum_required, is_active, created_by) 
 	          VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING created_at, updated_at`
-	return r.db.QueryRow(query, quota.ID, quota.BranchID, quota.PositionID, quota.DesignatedQuota,
+	return r.db.QueryRowContext(ctx, query, quota.ID, quota.BranchID, quota.PositionID, quota.DesignatedQuota,
 		quota.MinimumRequired, quota.IsActive, quota.CreatedBy).Scan(&quota.CreatedAt, &quota.UpdatedAt)
 }
 
-func (r *positionQuotaRepository) GetByID(id uuid.UUID) (*models.PositionQuota, error) {
+func (r *positionQuotaRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.PositionQuota, error) {
 	query := `SELECT id, branch_id, position_id, designated_quota, minimum_required, is_active, created_by, created_at, updated_at 
 	          FROM position_quotas WHERE id = $1`
 	quota := &models.PositionQuota{}
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&quota.ID, &quota.BranchID, &quota.PositionID, &quota.DesignatedQuota,
 		&quota.MinimumRequired, &quota.IsActive, &quota.CreatedBy, &quota.CreatedAt, &quota.UpdatedAt,
 	)
@@ -2194,10 +2357,10 @@ func (r *positionQuotaRepository) GetByID(id uuid.UUID) (*models.PositionQuota,
 	return quota, err
 }
 
-func (r *positionQuotaRepository) GetByBranchID(branchID uuid.UUID) ([]*models.PositionQuota, error) {
+func (r *positionQuotaRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID) ([]*models.PositionQuota, error) {
 	query := `SELECT id, branch_id, position_id, designated_quota, minimum_required, is_active, created_by, created_at, updated_at 
 	          FROM position_quotas WHERE branch_id = $1 ORDER BY position_id`
-	rows, err := r.db.Query(query, branchID)
+	rows, err := r.db.QueryContext(ctx, query, branchID)
 	if err != nil {
 		return nil, err
 	}
@@ -2217,11 +2380,11 @@ func (r *positionQuotaRepository) GetByBranchID(branchID uuid.UUID) ([]*models.P
 	return quotas, rows.Err()
 }
 
-func (r *positionQuotaRepository) GetByBranchAndPosition(branchID, positionID uuid.UUID) (*models.PositionQuota, error) {
+func (r *positionQuotaRepository) GetByBranchAndPosition(ctx context.Context, branchID, positionID uuid.UUID) (*models.PositionQuota, error) {
 	query := `SELECT id, branch_id, position_id, designated_quota, minimum_required, is_active, created_by, created_at, updated_at 
 	          FROM position_quotas WHERE branch_id = $1 AND position_id = $2`
 	quota := &models.PositionQuota{}
-	err := r.db.QueryRow(query, branchID, positionID).Scan(
+	err := r.db.QueryRowContext(ctx, query, branchID, positionID).Scan(
 		&quota.ID, &quota.BranchID, &quota.PositionID, &quota.DesignatedQuota,
 		&quota.MinimumRequired, &quota.IsActive, &quota.CreatedBy, &quota.CreatedAt, &quota.UpdatedAt,
 	)
@@ -2231,20 +2394,20 @@ func (r *positionQuotaRepository) GetByBranchAndPosition(branchID, positionID uu
 	return quota, err
 }
 
-func (r *positionQuotaRepository) Update(quota *models.PositionQuota) error {
+func (r *positionQuotaRepository) Update(ctx context.Context, quota *models.PositionQuota) error {
 	query := `UPDATE position_quotas 
 	          SET designated_quota = $1, minimum_required = $2, is_active = $3, updated_at = CURRENT_TIMESTAMP 
 	          WHERE id = $4 RETURNING updated_at`
-	return r.db.QueryRow(query, quota.DesignatedQuota, quota.MinimumRequired, quota.IsActive, quota.ID).Scan(&quota.UpdatedAt)
+	return r.db.QueryRowContext(ctx, query, quota.DesignatedQuota, quota.MinimumRequired, quota.IsActive, quota.ID).Scan(&quota.UpdatedAt)
 }
 
-func (r *positionQuotaRepository) Delete(id uuid.UUID) error {
+func (r *positionQuotaRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM position_quotas WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *positionQuotaRepository) List(filters interfaces.PositionQuotaFilters) ([]*models.PositionQuota, error) {
+func (r *positionQuotaRepository) List(ctx context.Context, filters interfaces.PositionQuotaFilters) ([]*models.PositionQuota, error) {
 	query := `SELECT id, branch_id, position_id, designated_quota, minimum_required, is_active, created_by, created_at, updated_at 
 	          FROM position_quotas WHERE 1=1`
 	args := []interface{}{}
@@ -2268,7 +2431,7 @@ func (r *positionQuotaRepository) List(filters interfaces.PositionQuotaFilters)
 
 	query += " ORDER BY branch_id, position_id"
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -2288,21 +2451,32 @@ func (r *positionQuotaRepository) List(filters interfaces.PositionQuotaFilters)
 	return quotas, rows.Err()
 }
 
-// DoctorAssignmentRepository implementation
+// DoctorAssignmentRepository implementation. Dates inside the roster window
+// are read from doctor_assignments, which the roster materializer maintains;
+// other dates are calculated from the doctor schedules.
 type doctorAssignmentRepository struct {
-	db                  *sql.DB
+	db                  DBTX
 	defaultScheduleRepo interfaces.DoctorDefaultScheduleRepository
 	weeklyOffDayRepo    interfaces.DoctorWeeklyOffDayRepository
 	overrideRepo        interfaces.DoctorScheduleOverrideRepository
 	doctorRepo          interfaces.DoctorRepository
+	revenueProfileRepo  interfaces.DoctorRevenueProfileRepository
+	revenueOverrideRepo interfaces.DoctorRevenueOverrideRepository
+	leaveRepo           interfaces.DoctorLeaveRepository
+	rosterRepo          interfaces.DoctorRosterRepository
+	calendar            *doctor.DoctorCalendar // nil calculates every read afresh
 }
 
 func NewDoctorAssignmentRepository(
-	db *sql.DB,
+	db DBTX,
 	defaultScheduleRepo interfaces.DoctorDefaultScheduleRepository,
 	weeklyOffDayRepo interfaces.DoctorWeeklyOffDayRepository,
 	overrideRepo interfaces.DoctorScheduleOverrideRepository,
 	doctorRepo interfaces.DoctorRepository,
+	revenueProfileRepo interfaces.DoctorRevenueProfileRepository,
+	revenueOverrideRepo interfaces.DoctorRevenueOverrideRepository,
+	leaveRepo interfaces.DoctorLeaveRepository,
+	rosterRepo interfaces.DoctorRosterRepository,
 ) interfaces.DoctorAssignmentRepository {
 	return &doctorAssignmentRepository{
 		db:                  db,
@@ -2310,272 +2484,256 @@ func NewDoctorAssignmentRepository(
 		weeklyOffDayRepo:    weeklyOffDayRepo,
 		overrideRepo:        overrideRepo,
 		doctorRepo:          doctorRepo,
+		revenueProfileRepo:  revenueProfileRepo,
+		revenueOverrideRepo: revenueOverrideRepo,
+		leaveRepo:           leaveRepo,
+		rosterRepo:          rosterRepo,
 	}
 }
 
-func (r *doctorAssignmentRepository) Create(assignment *models.DoctorAssignment) error {
-	if assignment.ID == uuid.Nil {
-		assignment.ID = uuid.New()
+// calculator resolves assignments, with revenue, from the doctors' schedules
+func (r *doctorAssignmentRepository) calculator() *doctor.DoctorScheduleCalculator {
+	return doctor.NewDoctorScheduleCalculator(
+		r.defaultScheduleRepo,
+		r.weeklyOffDayRepo,
+		r.overrideRepo,
+		r.doctorRepo,
+	).WithRevenue(r.revenueProfileRepo, r.revenueOverrideRepo).
+		WithLeaves(r.leaveRepo)
+}
+
+// UseCalendar serves calculated assignments from calendar, which must be
+// invalidated on every doctor schedule write. Only repositories outside a
+// transaction should use one; a transaction must see its own writes.
+func (r *doctorAssignmentRepository) UseCalendar(calendar *doctor.DoctorCalendar) {
+	r.calendar = calendar
+}
+
+// calculated returns the assignments calculated from the doctors' schedules
+func (r *doctorAssignmentRepository) calculated(ctx context.Context, startDate, endDate time.Time, filter doctor.RosterFilter) ([]*models.DoctorAssignment, error) {
+	if r.calendar != nil {
+		return r.calendar.Assignments(ctx, startDate, endDate, filter)
 	}
-	query := `INSERT INTO doctor_assignments (id, doctor_id, branch_id, date, expected_revenue, created_by) 
-	          VALUES ($1, $2, $3, $4, $5, $6) RETURNING created_at, updated_at`
-	return r.db.QueryRow(query, assignment.ID, assignment.DoctorID,
-		assignment.BranchID, assignment.Date, assignment.ExpectedRevenue, assignment.CreatedBy).
-		Scan(&assignment.CreatedAt, &assignment.UpdatedAt)
+	return r.calculator().Assignments(ctx, startDate, endDate, filter)
 }
 
-func (r *doctorAssignmentRepository) GetByID(id uuid.UUID) (*models.DoctorAssignment, error) {
-	query := `SELECT da.id, da.doctor_id, COALESCE(d.name, '') as doctor_name, COALESCE(d.code, '') as doctor_code, 
-	          da.branch_id, da.date, da.expected_revenue, da.created_by, da.created_at, da.updated_at 
-	          FROM doctor_assignments da
-	          LEFT JOIN doctors d ON da.doctor_id = d.id
-	          WHERE da.id = $1`
+const doctorAssignmentColumns = `da.id, da.doctor_id, COALESCE(d.name, '') as doctor_name, COALESCE(d.code, '') as doctor_code,
+	          da.branch_id, da.date, da.slot, da.expected_revenue, da.source, da.skin_revenue, da.ls_hm_revenue,
+	          da.vitamin_cases, da.slim_pen_cases, COALESCE(da.revenue_source, ''), da.created_by, da.created_at, da.updated_at`
+
+func scanDoctorAssignment(scanner interface{ Scan(...interface{}) error }) (*models.DoctorAssignment, error) {
 	assignment := &models.DoctorAssignment{}
-	var doctorName, doctorCode sql.NullString
-	err := r.db.QueryRow(query, id).Scan(
-		&assignment.ID, &assignment.DoctorID, &doctorName, &doctorCode,
-		&assignment.BranchID, &assignment.Date, &assignment.ExpectedRevenue, &assignment.CreatedBy,
-		&assignment.CreatedAt, &assignment.UpdatedAt,
-	)
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
-	if err != nil {
+	var skinRevenue, lsHMRevenue sql.NullFloat64
+	var vitaminCases, slimPenCases sql.NullInt64
+	var createdBy uuid.NullUUID
+	if err := scanner.Scan(
+		&assignment.ID, &assignment.DoctorID, &assignment.DoctorName, &assignment.DoctorCode,
+		&assignment.BranchID, &assignment.Date, &assignment.Slot, &assignment.ExpectedRevenue, &assignment.Source,
+		&skinRevenue, &lsHMRevenue, &vitaminCases, &slimPenCases, &assignment.RevenueSource,
+		&createdBy, &assignment.CreatedAt, &assignment.UpdatedAt,
+	); err != nil {
 		return nil, err
 	}
-	if doctorName.Valid {
-		assignment.DoctorName = doctorName.String
-	}
-	if doctorCode.Valid {
-		assignment.DoctorCode = doctorCode.String
+	if skinRevenue.Valid {
+		assignment.Revenue = &models.DoctorRevenue{
+			SkinRevenue:  skinRevenue.Float64,
+			LSHMRevenue:  lsHMRevenue.Float64,
+			VitaminCases: int(vitaminCases.Int64),
+			SlimPenCases: int(slimPenCases.Int64),
+		}
 	}
+	assignment.CreatedBy = createdBy.UUID
 	return assignment, nil
 }
 
-func (r *doctorAssignmentRepository) GetByBranchID(branchID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorAssignment, error) {
-	// Calculate from schedules instead of querying explicit assignments
-	calculator := doctor.NewDoctorScheduleCalculator(
-		r.defaultScheduleRepo,
-		r.weeklyOffDayRepo,
-		r.overrideRepo,
-		r.doctorRepo,
-	)
-
-	assignments := []*models.DoctorAssignment{}
-	currentDate := startDate
-	for !currentDate.After(endDate) {
-		doctorIDs, err := calculator.GetDoctorsByBranchAndDate(branchID, currentDate)
-		if err != nil {
-			return nil, err
-		}
-
-		for _, doctorID := range doctorIDs {
-			doctor, err := r.doctorRepo.GetByID(doctorID)
-			if err != nil {
-				continue
-			}
-			if doctor == nil {
-				continue
-			}
+// doctorAssignmentRevenue returns the revenue columns of an assignment, NULL
+// when it has no revenue breakdown
+func doctorAssignmentRevenue(assignment *models.DoctorAssignment) (skin, lsHM sql.NullFloat64, vitamin, slimPen sql.NullInt64) {
+	if assignment.Revenue == nil {
+		return
+	}
+	return sql.NullFloat64{Float64: assignment.Revenue.SkinRevenue, Valid: true},
+		sql.NullFloat64{Float64: assignment.Revenue.LSHMRevenue, Valid: true},
+		sql.NullInt64{Int64: int64(assignment.Revenue.VitaminCases), Valid: true},
+		sql.NullInt64{Int64: int64(assignment.Revenue.SlimPenCases), Valid: true}
+}
 
-			assignment := &models.DoctorAssignment{
-				ID:              uuid.New(),
-				DoctorID:        doctorID,
-				DoctorName:      doctor.Name,
-				DoctorCode:      doctor.Code,
-				BranchID:        branchID,
-				Date:            currentDate,
-				ExpectedRevenue: 0,
-			}
-			assignments = append(assignments, assignment)
-		}
+func doctorAssignmentSource(assignment *models.DoctorAssignment) string {
+	if assignment.Source == "" {
+		return "default"
+	}
+	return assignment.Source
+}
 
-		currentDate = currentDate.AddDate(0, 0, 1)
+func (r *doctorAssignmentRepository) Create(ctx context.Context, assignment *models.DoctorAssignment) error {
+	if assignment.ID == uuid.Nil {
+		assignment.ID = uuid.New()
 	}
+	assignment.Source = doctorAssignmentSource(assignment)
+	assignment.Slot = models.DoctorSlotOrFullDay(assignment.Slot)
+	skin, lsHM, vitamin, slimPen := doctorAssignmentRevenue(assignment)
+	createdBy := uuid.NullUUID{UUID: assignment.CreatedBy, Valid: assignment.CreatedBy != uuid.Nil}
+	query := `INSERT INTO doctor_assignments (id, doctor_id, branch_id, date, slot, expected_revenue, source,
+	          skin_revenue, ls_hm_revenue, vitamin_cases, slim_pen_cases, revenue_source, created_by)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, NULLIF($12, ''), $13) RETURNING created_at, updated_at`
+	return r.db.QueryRowContext(ctx, query, assignment.ID, assignment.DoctorID,
+		assignment.BranchID, assignment.Date, assignment.Slot, assignment.ExpectedRevenue, assignment.Source,
+		skin, lsHM, vitamin, slimPen, assignment.RevenueSource, createdBy).
+		Scan(&assignment.CreatedAt, &assignment.UpdatedAt)
+}
 
-	return assignments, nil
+func (r *doctorAssignmentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorAssignment, error) {
+	query := `SELECT ` + doctorAssignmentColumns + `
+	          FROM doctor_assignments da
+	          LEFT JOIN doctors d ON da.doctor_id = d.id
+	          WHERE da.id = $1`
+	assignment, err := scanDoctorAssignment(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return assignment, err
 }
 
-func (r *doctorAssignmentRepository) GetByDate(date time.Time) ([]*models.DoctorAssignment, error) {
-	// Calculate from schedules instead of querying explicit assignments
-	calculator := doctor.NewDoctorScheduleCalculator(
-		r.defaultScheduleRepo,
-		r.weeklyOffDayRepo,
-		r.overrideRepo,
-		r.doctorRepo,
-	)
+// stored reads the roster rows between startDate and endDate that filter matches
+func (r *doctorAssignmentRepository) stored(ctx context.Context, startDate, endDate time.Time, filter doctor.RosterFilter) ([]*models.DoctorAssignment, error) {
+	query := `SELECT ` + doctorAssignmentColumns + `
+	          FROM doctor_assignments da
+	          JOIN doctors d ON da.doctor_id = d.id
+	          WHERE da.date >= $1 AND da.date <= $2`
+	args := []interface{}{startDate, endDate}
+	if filter.DoctorID != nil {
+		args = append(args, *filter.DoctorID)
+		query += fmt.Sprintf(" AND da.doctor_id = $%d", len(args))
+	}
+	if filter.BranchID != nil {
+		args = append(args, *filter.BranchID)
+		query += fmt.Sprintf(" AND da.branch_id = $%d", len(args))
+	}
+	query += ` ORDER BY da.date, d.name, da.slot DESC`
 
-	// Get all doctors and calculate their assignments for this date
-	doctors, err := r.doctorRepo.List()
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	assignments := []*models.DoctorAssignment{}
-	for _, doctor := range doctors {
-		calculated, err := calculator.CalculateAssignmentForDate(doctor.ID, date)
+	for rows.Next() {
+		assignment, err := scanDoctorAssignment(rows)
 		if err != nil {
-			continue
-		}
-		if calculated == nil {
-			continue // Doctor is off
-		}
-
-		assignment := &models.DoctorAssignment{
-			ID:              uuid.New(),
-			DoctorID:        calculated.DoctorID,
-			DoctorName:      doctor.Name,
-			DoctorCode:      doctor.Code,
-			BranchID:        calculated.BranchID,
-			Date:            date,
-			ExpectedRevenue: 0,
+			return nil, err
 		}
 		assignments = append(assignments, assignment)
 	}
-
-	return assignments, nil
+	return assignments, rows.Err()
 }
 
-func (r *doctorAssignmentRepository) GetByDoctorID(doctorID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorAssignment, error) {
-	// Calculate from schedules instead of querying explicit assignments
-	calculator := doctor.NewDoctorScheduleCalculator(
-		r.defaultScheduleRepo,
-		r.weeklyOffDayRepo,
-		r.overrideRepo,
-		r.doctorRepo,
-	)
-
-	doctor, err := r.doctorRepo.GetByID(doctorID)
+// list reads the dates inside the roster window from the roster and
+// calculates the rest
+func (r *doctorAssignmentRepository) list(ctx context.Context, startDate, endDate time.Time, filter doctor.RosterFilter) ([]*models.DoctorAssignment, error) {
+	window, err := r.rosterRepo.Window(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if doctor == nil {
-		return []*models.DoctorAssignment{}, nil
-	}
-
 	assignments := []*models.DoctorAssignment{}
-	currentDate := startDate
-	for !currentDate.After(endDate) {
-		calculated, err := calculator.CalculateAssignmentForDate(doctorID, currentDate)
+	for _, segment := range doctor.SplitRosterRange(window, startDate, endDate) {
+		var part []*models.DoctorAssignment
+		if segment.Materialized {
+			part, err = r.stored(ctx, segment.StartDate, segment.EndDate, filter)
+		} else {
+			part, err = r.calculated(ctx, segment.StartDate, segment.EndDate, filter)
+		}
 		if err != nil {
 			return nil, err
 		}
-		if calculated != nil {
-			assignment := &models.DoctorAssignment{
-				ID:              uuid.New(),
-				DoctorID:        doctorID,
-				DoctorName:      doctor.Name,
-				DoctorCode:      doctor.Code,
-				BranchID:        calculated.BranchID,
-				Date:            currentDate,
-				ExpectedRevenue: 0,
-			}
-			assignments = append(assignments, assignment)
-		}
-		currentDate = currentDate.AddDate(0, 0, 1)
+		assignments = append(assignments, part...)
 	}
-
 	return assignments, nil
 }
 
-func (r *doctorAssignmentRepository) Update(assignment *models.DoctorAssignment) error {
-	query := `UPDATE doctor_assignments 
-	          SET expected_revenue = $1, updated_at = CURRENT_TIMESTAMP 
-	          WHERE id = $2 RETURNING updated_at`
-	return r.db.QueryRow(query, assignment.ExpectedRevenue, assignment.ID).
+func (r *doctorAssignmentRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorAssignment, error) {
+	return r.list(ctx, startDate, endDate, doctor.RosterFilter{BranchID: &branchID})
+}
+
+func (r *doctorAssignmentRepository) GetByDate(ctx context.Context, date time.Time) ([]*models.DoctorAssignment, error) {
+	return r.list(ctx, date, date, doctor.RosterFilter{})
+}
+
+func (r *doctorAssignmentRepository) GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.DoctorAssignment, error) {
+	return r.list(ctx, startDate, endDate, doctor.RosterFilter{})
+}
+
+func (r *doctorAssignmentRepository) GetRosterByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.DoctorAssignment, error) {
+	return r.stored(ctx, startDate, endDate, doctor.RosterFilter{})
+}
+
+func (r *doctorAssignmentRepository) GetByDoctorID(ctx context.Context, doctorID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorAssignment, error) {
+	return r.list(ctx, startDate, endDate, doctor.RosterFilter{DoctorID: &doctorID})
+}
+
+func (r *doctorAssignmentRepository) Update(ctx context.Context, assignment *models.DoctorAssignment) error {
+	assignment.Source = doctorAssignmentSource(assignment)
+	skin, lsHM, vitamin, slimPen := doctorAssignmentRevenue(assignment)
+	query := `UPDATE doctor_assignments
+	          SET branch_id = $1, expected_revenue = $2, source = $3, skin_revenue = $4, ls_hm_revenue = $5,
+	          vitamin_cases = $6, slim_pen_cases = $7, revenue_source = NULLIF($8, ''), updated_at = CURRENT_TIMESTAMP
+	          WHERE id = $9 RETURNING updated_at`
+	return r.db.QueryRowContext(ctx, query, assignment.BranchID, assignment.ExpectedRevenue, assignment.Source,
+		skin, lsHM, vitamin, slimPen, assignment.RevenueSource, assignment.ID).
 		Scan(&assignment.UpdatedAt)
 }
 
-func (r *doctorAssignmentRepository) Delete(id uuid.UUID) error {
+func (r *doctorAssignmentRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM doctor_assignments WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *doctorAssignmentRepository) GetDoctorCountByBranch(branchID uuid.UUID, date time.Time) (int, error) {
-	// Calculate from schedules instead of querying explicit assignments
-	calculator := doctor.NewDoctorScheduleCalculator(
-		r.defaultScheduleRepo,
-		r.weeklyOffDayRepo,
-		r.overrideRepo,
-		r.doctorRepo,
-	)
-	return calculator.GetDoctorCountByBranch(branchID, date)
+func (r *doctorAssignmentRepository) GetDoctorCountByBranch(ctx context.Context, branchID uuid.UUID, date time.Time) (int, error) {
+	assignments, err := r.GetDoctorsByBranchAndDate(ctx, branchID, date)
+	if err != nil {
+		return 0, err
+	}
+	return doctor.CountDoctors(assignments), nil
 }
 
-func (r *doctorAssignmentRepository) GetMonthlySchedule(doctorID uuid.UUID, year int, month int) ([]*models.DoctorAssignment, error) {
+func (r *doctorAssignmentRepository) GetMonthlySchedule(ctx context.Context, doctorID uuid.UUID, year int, month int) ([]*models.DoctorAssignment, error) {
 	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
 	endDate := startDate.AddDate(0, 1, -1)
-	return r.GetByDoctorID(doctorID, startDate, endDate)
+	return r.GetByDoctorID(ctx, doctorID, startDate, endDate)
 }
 
-func (r *doctorAssignmentRepository) DeleteByDoctorBranchDate(doctorID uuid.UUID, branchID uuid.UUID, date time.Time) error {
+func (r *doctorAssignmentRepository) DeleteByDoctorBranchDate(ctx context.Context, doctorID uuid.UUID, branchID uuid.UUID, date time.Time) error {
 	query := `DELETE FROM doctor_assignments WHERE doctor_id = $1 AND branch_id = $2 AND date = $3`
-	_, err := r.db.Exec(query, doctorID, branchID, date)
+	_, err := r.db.ExecContext(ctx, query, doctorID, branchID, date)
 	return err
 }
 
-func (r *doctorAssignmentRepository) GetDoctorsByBranchAndDate(branchID uuid.UUID, date time.Time) ([]*models.DoctorAssignment, error) {
-	// Calculate from schedules instead of querying explicit assignments
-	calculator := doctor.NewDoctorScheduleCalculator(
-		r.defaultScheduleRepo,
-		r.weeklyOffDayRepo,
-		r.overrideRepo,
-		r.doctorRepo,
-	)
-
-	doctorIDs, err := calculator.GetDoctorsByBranchAndDate(branchID, date)
-	if err != nil {
-		return nil, err
-	}
-
-	// Convert to DoctorAssignment models
-	assignments := []*models.DoctorAssignment{}
-	for _, doctorID := range doctorIDs {
-		doctor, err := r.doctorRepo.GetByID(doctorID)
-		if err != nil {
-			continue // Skip if doctor not found
-		}
-		if doctor == nil {
-			continue
-		}
-
-		assignment := &models.DoctorAssignment{
-			ID:              uuid.New(), // Generate ID for calculated assignment
-			DoctorID:        doctorID,
-			DoctorName:      doctor.Name,
-			DoctorCode:      doctor.Code,
-			BranchID:        branchID,
-			Date:            date,
-			ExpectedRevenue: 0, // Can be set separately if needed
-		}
-		assignments = append(assignments, assignment)
-	}
-
-	return assignments, nil
+func (r *doctorAssignmentRepository) GetDoctorsByBranchAndDate(ctx context.Context, branchID uuid.UUID, date time.Time) ([]*models.DoctorAssignment, error) {
+	return r.list(ctx, date, date, doctor.RosterFilter{BranchID: &branchID})
 }
 
 // DoctorOnOffDayRepository implementation
 type doctorOnOffDayRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewDoctorOnOffDayRepository(db *sql.DB) interfaces.DoctorOnOffDayRepository {
+func NewDoctorOnOffDayRepository(db DBTX) interfaces.DoctorOnOffDayRepository {
 	return &doctorOnOffDayRepository{db: db}
 }
 
-func (r *doctorOnOffDayRepository) Create(day *models.DoctorOnOffDay) error {
+func (r *doctorOnOffDayRepository) Create(ctx context.Context, day *models.DoctorOnOffDay) error {
 	query := `INSERT INTO doctor_on_off_days (id, branch_id, date, is_doctor_on, created_by) 
 	          VALUES ($1, $2, $3, $4, $5) RETURNING created_at, updated_at`
-	return r.db.QueryRow(query, day.ID, day.BranchID, day.Date, day.IsDoctorOn, day.CreatedBy).
+	return r.db.QueryRowContext(ctx, query, day.ID, day.BranchID, day.Date, day.IsDoctorOn, day.CreatedBy).
 		Scan(&day.CreatedAt, &day.UpdatedAt)
 }
 
-func (r *doctorOnOffDayRepository) GetByID(id uuid.UUID) (*models.DoctorOnOffDay, error) {
+func (r *doctorOnOffDayRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DoctorOnOffDay, error) {
 	query := `SELECT id, branch_id, date, is_doctor_on, created_by, created_at, updated_at 
 	          FROM doctor_on_off_days WHERE id = $1`
 	day := &models.DoctorOnOffDay{}
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&day.ID, &day.BranchID, &day.Date, &day.IsDoctorOn, &day.CreatedBy, &day.CreatedAt, &day.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
@@ -2584,10 +2742,10 @@ func (r *doctorOnOffDayRepository) GetByID(id uuid.UUID) (*models.DoctorOnOffDay
 	return day, err
 }
 
-func (r *doctorOnOffDayRepository) GetByBranchID(branchID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorOnOffDay, error) {
+func (r *doctorOnOffDayRepository) GetByBranchID(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorOnOffDay, error) {
 	query := `SELECT id, branch_id, date, is_doctor_on, created_by, created_at, updated_at 
 	          FROM doctor_on_off_days WHERE branch_id = $1 AND date >= $2 AND date <= $3 ORDER BY date`
-	rows, err := r.db.Query(query, branchID, startDate, endDate)
+	rows, err := r.db.QueryContext(ctx, query, branchID, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -2606,10 +2764,32 @@ func (r *doctorOnOffDayRepository) GetByBranchID(branchID uuid.UUID, startDate,
 	return days, rows.Err()
 }
 
-func (r *doctorOnOffDayRepository) GetByDate(date time.Time) ([]*models.DoctorOnOffDay, error) {
+func (r *doctorOnOffDayRepository) GetByDate(ctx context.Context, date time.Time) ([]*models.DoctorOnOffDay, error) {
 	query := `SELECT id, branch_id, date, is_doctor_on, created_by, created_at, updated_at 
 	          FROM doctor_on_off_days WHERE date = $1 ORDER BY branch_id`
-	rows, err := r.db.Query(query, date)
+	rows, err := r.db.QueryContext(ctx, query, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []*models.DoctorOnOffDay
+	for rows.Next() {
+		day := &models.DoctorOnOffDay{}
+		if err := rows.Scan(
+			&day.ID, &day.BranchID, &day.Date, &day.IsDoctorOn, &day.CreatedBy, &day.CreatedAt, &day.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+	return days, rows.Err()
+}
+
+func (r *doctorOnOffDayRepository) GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.DoctorOnOffDay, error) {
+	query := `SELECT id, branch_id, date, is_doctor_on, created_by, created_at, updated_at
+	          FROM doctor_on_off_days WHERE date >= $1 AND date <= $2 ORDER BY date, branch_id`
+	rows, err := r.db.QueryContext(ctx, query, startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -2628,24 +2808,24 @@ func (r *doctorOnOffDayRepository) GetByDate(date time.Time) ([]*models.DoctorOn
 	return days, rows.Err()
 }
 
-func (r *doctorOnOffDayRepository) Update(day *models.DoctorOnOffDay) error {
+func (r *doctorOnOffDayRepository) Update(ctx context.Context, day *models.DoctorOnOffDay) error {
 	query := `UPDATE doctor_on_off_days 
 	          SET is_doctor_on = $1, updated_at = CURRENT_TIMESTAMP 
 	          WHERE id = $2 RETURNING updated_at`
-	return r.db.QueryRow(query, day.IsDoctorOn, day.ID).Scan(&day.UpdatedAt)
+	return r.db.QueryRowContext(ctx, query, day.IsDoctorOn, day.ID).Scan(&day.UpdatedAt)
 }
 
-func (r *doctorOnOffDayRepository) Delete(id uuid.UUID) error {
+func (r *doctorOnOffDayRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM doctor_on_off_days WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *doctorOnOffDayRepository) GetByBranchAndDate(branchID uuid.UUID, date time.Time) (*models.DoctorOnOffDay, error) {
+func (r *doctorOnOffDayRepository) GetByBranchAndDate(ctx context.Context, branchID uuid.UUID, date time.Time) (*models.DoctorOnOffDay, error) {
 	query := `SELECT id, branch_id, date, is_doctor_on, created_by, created_at, updated_at 
 	          FROM doctor_on_off_days WHERE branch_id = $1 AND date = $2`
 	day := &models.DoctorOnOffDay{}
-	err := r.db.QueryRow(query, branchID, date).Scan(
+	err := r.db.QueryRowContext(ctx, query, branchID, date).Scan(
 		&day.ID, &day.BranchID, &day.Date, &day.IsDoctorOn, &day.CreatedBy, &day.CreatedAt, &day.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
@@ -2656,14 +2836,14 @@ func (r *doctorOnOffDayRepository) GetByBranchAndDate(branchID uuid.UUID, date t
 
 // SpecificPreferenceRepository implementation
 type specificPreferenceRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewSpecificPreferenceRepository(db *sql.DB) interfaces.SpecificPreferenceRepository {
+func NewSpecificPreferenceRepository(db DBTX) interfaces.SpecificPreferenceRepository {
 	return &specificPreferenceRepository{db: db}
 }
 
-func (r *specificPreferenceRepository) Create(preference *models.SpecificPreference) error {
+func (r *specificPreferenceRepository) Create(ctx context.Context, preference *models.SpecificPreference) error {
 	preference.ID = uuid.New()
 	preference.CreatedAt = time.Now()
 	preference.UpdatedAt = time.Now()
@@ -2672,8 +2852,8 @@ func (r *specificPreferenceRepository) Create(preference *models.SpecificPrefere
 		return err
 	}
 
-	query := `INSERT INTO specific_preferences (id, branch_id, doctor_id, day_of_week, preference_type, position_id, staff_count, staff_id, is_active, created_at, updated_at)
-	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING created_at, updated_at`
+	query := `INSERT INTO specific_preferences (id, branch_id, doctor_id, day_of_week, preference_type, position_id, staff_count, staff_id, is_hard, is_active, created_at, updated_at)
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING created_at, updated_at`
 
 	var branchID, doctorID, positionID, staffID interface{}
 	var dayOfWeek interface{}
@@ -2698,23 +2878,23 @@ func (r *specificPreferenceRepository) Create(preference *models.SpecificPrefere
 		staffCount = *preference.StaffCount
 	}
 
-	return r.db.QueryRow(query, preference.ID, branchID, doctorID, dayOfWeek, preference.PreferenceType,
-		positionID, staffCount, staffID, preference.IsActive, preference.CreatedAt, preference.UpdatedAt).
+	return r.db.QueryRowContext(ctx, query, preference.ID, branchID, doctorID, dayOfWeek, preference.PreferenceType,
+		positionID, staffCount, staffID, preference.IsHard, preference.IsActive, preference.CreatedAt, preference.UpdatedAt).
 		Scan(&preference.CreatedAt, &preference.UpdatedAt)
 }
 
-func (r *specificPreferenceRepository) GetByID(id uuid.UUID) (*models.SpecificPreference, error) {
+func (r *specificPreferenceRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.SpecificPreference, error) {
 	preference := &models.SpecificPreference{}
-	query := `SELECT id, branch_id, doctor_id, day_of_week, preference_type, position_id, staff_count, staff_id, is_active, created_at, updated_at
+	query := `SELECT id, branch_id, doctor_id, day_of_week, preference_type, position_id, staff_count, staff_id, is_hard, is_active, created_at, updated_at
 	          FROM specific_preferences WHERE id = $1`
 
 	var branchID, doctorID, positionID, staffID sql.NullString
 	var dayOfWeek sql.NullInt64
 	var staffCount sql.NullInt64
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&preference.ID, &branchID, &doctorID, &dayOfWeek, &preference.PreferenceType,
-		&positionID, &staffCount, &staffID, &preference.IsActive, &preference.CreatedAt, &preference.UpdatedAt,
+		&positionID, &staffCount, &staffID, &preference.IsHard, &preference.IsActive, &preference.CreatedAt, &preference.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -2751,8 +2931,8 @@ func (r *specificPreferenceRepository) GetByID(id uuid.UUID) (*models.SpecificPr
 	return preference, nil
 }
 
-func (r *specificPreferenceRepository) List(filters interfaces.SpecificPreferenceFilters) ([]*models.SpecificPreference, error) {
-	query := `SELECT id, branch_id, doctor_id, day_of_week, preference_type, position_id, staff_count, staff_id, is_active, created_at, updated_at
+func (r *specificPreferenceRepository) List(ctx context.Context, filters interfaces.SpecificPreferenceFilters) ([]*models.SpecificPreference, error) {
+	query := `SELECT id, branch_id, doctor_id, day_of_week, preference_type, position_id, staff_count, staff_id, is_hard, is_active, created_at, updated_at
 	          FROM specific_preferences WHERE 1=1`
 	args := []interface{}{}
 	argPos := 1
@@ -2780,7 +2960,7 @@ func (r *specificPreferenceRepository) List(filters interfaces.SpecificPreferenc
 
 	query += " ORDER BY created_at DESC"
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -2795,7 +2975,7 @@ func (r *specificPreferenceRepository) List(filters interfaces.SpecificPreferenc
 
 		err := rows.Scan(
 			&preference.ID, &branchID, &doctorID, &dayOfWeek, &preference.PreferenceType,
-			&positionID, &staffCount, &staffID, &preference.IsActive, &preference.CreatedAt, &preference.UpdatedAt,
+			&positionID, &staffCount, &staffID, &preference.IsHard, &preference.IsActive, &preference.CreatedAt, &preference.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -2832,12 +3012,12 @@ func (r *specificPreferenceRepository) List(filters interfaces.SpecificPreferenc
 	return preferences, rows.Err()
 }
 
-func (r *specificPreferenceRepository) GetMatchingPreferences(branchID *uuid.UUID, doctorID *uuid.UUID, dayOfWeek *int) ([]*models.SpecificPreference, error) {
+func (r *specificPreferenceRepository) GetMatchingPreferences(ctx context.Context, branchID *uuid.UUID, doctorID *uuid.UUID, dayOfWeek *int) ([]*models.SpecificPreference, error) {
 	// Get all active preferences
 	filters := interfaces.SpecificPreferenceFilters{
 		IsActive: &[]bool{true}[0],
 	}
-	allPreferences, err := r.List(filters)
+	allPreferences, err := r.List(ctx, filters)
 	if err != nil {
 		return nil, err
 	}
@@ -2853,7 +3033,7 @@ func (r *specificPreferenceRepository) GetMatchingPreferences(branchID *uuid.UUI
 	return matching, nil
 }
 
-func (r *specificPreferenceRepository) Update(preference *models.SpecificPreference) error {
+func (r *specificPreferenceRepository) Update(ctx context.Context, preference *models.SpecificPreference) error {
 	preference.UpdatedAt = time.Now()
 
 	if err := preference.Validate(); err != nil {
@@ -2861,7 +3041,7 @@ func (r *specificPreferenceRepository) Update(preference *models.SpecificPrefere
 	}
 
 	query := `UPDATE specific_preferences SET branch_id = $1, doctor_id = $2, day_of_week = $3, preference_type = $4,
-	          position_id = $5, staff_count = $6, staff_id = $7, is_active = $8, updated_at = $9 WHERE id = $10`
+	          position_id = $5, staff_count = $6, staff_id = $7, is_hard = $8, is_active = $9, updated_at = $10 WHERE id = $11`
 
 	var branchID, doctorID, positionID, staffID interface{}
 	var dayOfWeek interface{}
@@ -2886,27 +3066,27 @@ func (r *specificPreferenceRepository) Update(preference *models.SpecificPrefere
 		staffCount = *preference.StaffCount
 	}
 
-	_, err := r.db.Exec(query, branchID, doctorID, dayOfWeek, preference.PreferenceType,
-		positionID, staffCount, staffID, preference.IsActive, preference.UpdatedAt, preference.ID)
+	_, err := r.db.ExecContext(ctx, query, branchID, doctorID, dayOfWeek, preference.PreferenceType,
+		positionID, staffCount, staffID, preference.IsHard, preference.IsActive, preference.UpdatedAt, preference.ID)
 	return err
 }
 
-func (r *specificPreferenceRepository) Delete(id uuid.UUID) error {
+func (r *specificPreferenceRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM specific_preferences WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
 // BranchQuotaSummaryRepository implementation
 type branchQuotaSummaryRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewBranchQuotaSummaryRepository(db *sql.DB) interfaces.BranchQuotaSummaryRepository {
+func NewBranchQuotaSummaryRepository(db DBTX) interfaces.BranchQuotaSummaryRepository {
 	return &branchQuotaSummaryRepository{db: db}
 }
 
-func (r *branchQuotaSummaryRepository) GetByBranchIDAndDate(branchID uuid.UUID, date time.Time) (*models.BranchQuotaSummary, error) {
+func (r *branchQuotaSummaryRepository) GetByBranchIDAndDate(ctx context.Context, branchID uuid.UUID, date time.Time) (*models.BranchQuotaSummary, error) {
 	query := `SELECT id, branch_id, date, total_designated, total_available, total_assigned, total_required,
 	         group1_score, group2_score, group3_score, group1_missing_staff, group2_missing_staff, group3_missing_staff,
 	         calculated_at, updated_at
@@ -2916,7 +3096,7 @@ func (r *branchQuotaSummaryRepository) GetByBranchIDAndDate(branchID uuid.UUID,
 	summary := &models.BranchQuotaSummary{}
 	var group1Missing, group2Missing, group3Missing []byte
 	
-	err := r.db.QueryRow(query, branchID, date).Scan(
+	err := r.db.QueryRowContext(ctx, query, branchID, date).Scan(
 		&summary.ID, &summary.BranchID, &summary.Date,
 		&summary.TotalDesignated, &summary.TotalAvailable, &summary.TotalAssigned, &summary.TotalRequired,
 		&summary.Group1Score, &summary.Group2Score, &summary.Group3Score,
@@ -2951,7 +3131,11 @@ func (r *branchQuotaSummaryRepository) GetByBranchIDAndDate(branchID uuid.UUID,
 	return summary, nil
 }
 
-func (r *branchQuotaSummaryRepository) GetByBranchIDsAndDate(branchIDs []uuid.UUID, date time.Time) ([]*models.BranchQuotaSummary, error) {
+func (r *branchQuotaSummaryRepository) GetByBranchIDsAndDate(ctx context.Context, branchIDs []uuid.UUID, date time.Time) ([]*models.BranchQuotaSummary, error) {
+	return r.GetByBranchIDsAndDateRange(ctx, branchIDs, date, date)
+}
+
+func (r *branchQuotaSummaryRepository) GetByBranchIDsAndDateRange(ctx context.Context, branchIDs []uuid.UUID, startDate, endDate time.Time) ([]*models.BranchQuotaSummary, error) {
 	if len(branchIDs) == 0 {
 		return []*models.BranchQuotaSummary{}, nil
 	}
@@ -2960,10 +3144,10 @@ func (r *branchQuotaSummaryRepository) GetByBranchIDsAndDate(branchIDs []uuid.UU
 	         group1_score, group2_score, group3_score, group1_missing_staff, group2_missing_staff, group3_missing_staff,
 	         calculated_at, updated_at
 	         FROM branch_quota_daily_summary
-	         WHERE branch_id = ANY($1) AND date = $2
-	         ORDER BY branch_id`
+	         WHERE branch_id = ANY($1) AND date >= $2 AND date <= $3
+	         ORDER BY branch_id, date`
 	
-	rows, err := r.db.Query(query, pq.Array(branchIDs), date)
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(branchIDs), startDate, endDate)
 	if err != nil {
 		return nil, err
 	}
@@ -3001,14 +3185,121 @@ func (r *branchQuotaSummaryRepository) GetByBranchIDsAndDate(branchIDs []uuid.UU
 	return summaries, rows.Err()
 }
 
-func (r *branchQuotaSummaryRepository) Recalculate(branchID uuid.UUID, date time.Time) error {
-	query := `SELECT recalculate_branch_quota_summary($1, $2)`
-	_, err := r.db.Exec(query, branchID, date)
+func (r *branchQuotaSummaryRepository) Upsert(ctx context.Context, summary *models.BranchQuotaSummary) error {
+	group1Missing, err := json.Marshal(nonNilStrings(summary.Group1MissingStaff))
+	if err != nil {
+		return err
+	}
+	group2Missing, err := json.Marshal(nonNilStrings(summary.Group2MissingStaff))
+	if err != nil {
+		return err
+	}
+	group3Missing, err := json.Marshal(nonNilStrings(summary.Group3MissingStaff))
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO branch_quota_daily_summary (
+	         branch_id, date, total_designated, total_available, total_assigned, total_required,
+	         group1_score, group2_score, group3_score, group1_missing_staff, group2_missing_staff, group3_missing_staff,
+	         calculated_at, updated_at)
+	         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	         ON CONFLICT (branch_id, date) DO UPDATE SET
+	         total_designated = EXCLUDED.total_designated,
+	         total_available = EXCLUDED.total_available,
+	         total_assigned = EXCLUDED.total_assigned,
+	         total_required = EXCLUDED.total_required,
+	         group1_score = EXCLUDED.group1_score,
+	         group2_score = EXCLUDED.group2_score,
+	         group3_score = EXCLUDED.group3_score,
+	         group1_missing_staff = EXCLUDED.group1_missing_staff,
+	         group2_missing_staff = EXCLUDED.group2_missing_staff,
+	         group3_missing_staff = EXCLUDED.group3_missing_staff,
+	         updated_at = CURRENT_TIMESTAMP
+	         RETURNING id, calculated_at, updated_at`
+	return r.db.QueryRowContext(ctx, query,
+		summary.BranchID, summary.Date,
+		summary.TotalDesignated, summary.TotalAvailable, summary.TotalAssigned, summary.TotalRequired,
+		summary.Group1Score, summary.Group2Score, summary.Group3Score,
+		group1Missing, group2Missing, group3Missing,
+	).Scan(&summary.ID, &summary.CalculatedAt, &summary.UpdatedAt)
+}
+
+func (r *branchQuotaSummaryRepository) MarkDirty(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) error {
+	query := `SELECT mark_quota_summary_dirty($1, $2::date, $3::date)`
+	_, err := r.db.ExecContext(ctx, query, branchID, startDate, endDate)
 	return err
 }
 
-func (r *branchQuotaSummaryRepository) RecalculateForDateRange(branchID uuid.UUID, startDate, endDate time.Time) error {
-	query := `SELECT recalculate_branch_quota_summary($1, generate_series($2::date, $3::date, '1 day'::interval)::date)`
-	_, err := r.db.Exec(query, branchID, startDate, endDate)
+func (r *branchQuotaSummaryRepository) MarkStale(ctx context.Context, branchID uuid.UUID, date time.Time) error {
+	var branchFilter, dateFilter interface{}
+	if branchID != uuid.Nil {
+		branchFilter = branchID
+	}
+	if !date.IsZero() {
+		dateFilter = date
+	}
+	query := `INSERT INTO quota_summary_dirty (branch_id, date)
+	         SELECT branch_id, date FROM branch_quota_daily_summary
+	         WHERE ($1::uuid IS NULL OR branch_id = $1) AND ($2::date IS NULL OR date = $2)
+	         ON CONFLICT (branch_id, date) DO UPDATE SET marked_at = CURRENT_TIMESTAMP`
+	_, err := r.db.ExecContext(ctx, query, branchFilter, dateFilter)
 	return err
 }
+
+func (r *branchQuotaSummaryRepository) ListDirty(ctx context.Context, debounce, maxWait time.Duration, limit int) ([]*models.QuotaSummaryDirtyKey, error) {
+	query := `SELECT branch_id, date, first_marked_at, marked_at
+	         FROM quota_summary_dirty
+	         WHERE marked_at <= CURRENT_TIMESTAMP - make_interval(secs => $1)
+	            OR first_marked_at <= CURRENT_TIMESTAMP - make_interval(secs => $2)
+	         ORDER BY first_marked_at, branch_id, date
+	         LIMIT $3`
+	return r.queryDirty(ctx, query, debounce.Seconds(), maxWait.Seconds(), limit)
+}
+
+func (r *branchQuotaSummaryRepository) GetDirtyByBranchIDsAndDateRange(ctx context.Context, branchIDs []uuid.UUID, startDate, endDate time.Time) ([]*models.QuotaSummaryDirtyKey, error) {
+	if len(branchIDs) == 0 {
+		return []*models.QuotaSummaryDirtyKey{}, nil
+	}
+	query := `SELECT branch_id, date, first_marked_at, marked_at
+	         FROM quota_summary_dirty
+	         WHERE branch_id = ANY($1) AND date >= $2 AND date <= $3
+	         ORDER BY branch_id, date`
+	return r.queryDirty(ctx, query, pq.Array(branchIDs), startDate, endDate)
+}
+
+func (r *branchQuotaSummaryRepository) queryDirty(ctx context.Context, query string, args ...interface{}) ([]*models.QuotaSummaryDirtyKey, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*models.QuotaSummaryDirtyKey{}
+	for rows.Next() {
+		key := &models.QuotaSummaryDirtyKey{}
+		if err := rows.Scan(&key.BranchID, &key.Date, &key.FirstMarkedAt, &key.MarkedAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (r *branchQuotaSummaryRepository) ClearDirty(ctx context.Context, keys []*models.QuotaSummaryDirtyKey) error {
+	query := `DELETE FROM quota_summary_dirty WHERE branch_id = $1 AND date = $2 AND marked_at = $3`
+	for _, key := range keys {
+		if _, err := r.db.ExecContext(ctx, query, key.BranchID, key.Date, key.MarkedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nonNilStrings keeps empty missing-staff lists stored as [] rather than null
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
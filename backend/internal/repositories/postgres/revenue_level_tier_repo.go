@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/google/uuid"
@@ -9,28 +10,29 @@ import (
 )
 
 type revenueLevelTierRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewRevenueLevelTierRepository(db *sql.DB) interfaces.RevenueLevelTierRepository {
+func NewRevenueLevelTierRepository(db DBTX) interfaces.RevenueLevelTierRepository {
 	return &revenueLevelTierRepository{db: db}
 }
 
-func (r *revenueLevelTierRepository) Create(tier *models.RevenueLevelTier) error {
+func (r *revenueLevelTierRepository) Create(ctx context.Context, tier *models.RevenueLevelTier) error {
 	query := `INSERT INTO revenue_level_tiers (id, level_number, level_name, min_revenue, max_revenue, display_order, color_code, description)
 	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING created_at, updated_at`
-	return r.db.QueryRow(
+	return r.db.QueryRowContext(
+		ctx,
 		query,
 		tier.ID, tier.LevelNumber, tier.LevelName, tier.MinRevenue, tier.MaxRevenue,
 		tier.DisplayOrder, tier.ColorCode, tier.Description,
 	).Scan(&tier.CreatedAt, &tier.UpdatedAt)
 }
 
-func (r *revenueLevelTierRepository) GetByID(id uuid.UUID) (*models.RevenueLevelTier, error) {
+func (r *revenueLevelTierRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.RevenueLevelTier, error) {
 	tier := &models.RevenueLevelTier{}
 	query := `SELECT id, level_number, level_name, min_revenue, max_revenue, display_order, color_code, description, created_at, updated_at
 	          FROM revenue_level_tiers WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&tier.ID, &tier.LevelNumber, &tier.LevelName, &tier.MinRevenue, &tier.MaxRevenue,
 		&tier.DisplayOrder, &tier.ColorCode, &tier.Description, &tier.CreatedAt, &tier.UpdatedAt,
 	)
@@ -43,11 +45,11 @@ func (r *revenueLevelTierRepository) GetByID(id uuid.UUID) (*models.RevenueLevel
 	return tier, nil
 }
 
-func (r *revenueLevelTierRepository) GetByLevelNumber(levelNumber int) (*models.RevenueLevelTier, error) {
+func (r *revenueLevelTierRepository) GetByLevelNumber(ctx context.Context, levelNumber int) (*models.RevenueLevelTier, error) {
 	tier := &models.RevenueLevelTier{}
 	query := `SELECT id, level_number, level_name, min_revenue, max_revenue, display_order, color_code, description, created_at, updated_at
 	          FROM revenue_level_tiers WHERE level_number = $1`
-	err := r.db.QueryRow(query, levelNumber).Scan(
+	err := r.db.QueryRowContext(ctx, query, levelNumber).Scan(
 		&tier.ID, &tier.LevelNumber, &tier.LevelName, &tier.MinRevenue, &tier.MaxRevenue,
 		&tier.DisplayOrder, &tier.ColorCode, &tier.Description, &tier.CreatedAt, &tier.UpdatedAt,
 	)
@@ -60,26 +62,27 @@ func (r *revenueLevelTierRepository) GetByLevelNumber(levelNumber int) (*models.
 	return tier, nil
 }
 
-func (r *revenueLevelTierRepository) Update(tier *models.RevenueLevelTier) error {
+func (r *revenueLevelTierRepository) Update(ctx context.Context, tier *models.RevenueLevelTier) error {
 	query := `UPDATE revenue_level_tiers
 	          SET level_name = $1, min_revenue = $2, max_revenue = $3, display_order = $4, color_code = $5, description = $6, updated_at = CURRENT_TIMESTAMP
 	          WHERE id = $7 RETURNING updated_at`
-	return r.db.QueryRow(
+	return r.db.QueryRowContext(
+		ctx,
 		query,
 		tier.LevelName, tier.MinRevenue, tier.MaxRevenue, tier.DisplayOrder, tier.ColorCode, tier.Description, tier.ID,
 	).Scan(&tier.UpdatedAt)
 }
 
-func (r *revenueLevelTierRepository) Delete(id uuid.UUID) error {
+func (r *revenueLevelTierRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM revenue_level_tiers WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *revenueLevelTierRepository) List() ([]*models.RevenueLevelTier, error) {
+func (r *revenueLevelTierRepository) List(ctx context.Context) ([]*models.RevenueLevelTier, error) {
 	query := `SELECT id, level_number, level_name, min_revenue, max_revenue, display_order, color_code, description, created_at, updated_at
 	          FROM revenue_level_tiers ORDER BY display_order, level_number`
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -99,7 +102,7 @@ func (r *revenueLevelTierRepository) List() ([]*models.RevenueLevelTier, error)
 	return tiers, rows.Err()
 }
 
-func (r *revenueLevelTierRepository) GetTierForRevenue(revenue float64) (*models.RevenueLevelTier, error) {
+func (r *revenueLevelTierRepository) GetTierForRevenue(ctx context.Context, revenue float64) (*models.RevenueLevelTier, error) {
 	tier := &models.RevenueLevelTier{}
 	query := `SELECT id, level_number, level_name, min_revenue, max_revenue, display_order, color_code, description, created_at, updated_at
 	          FROM revenue_level_tiers
@@ -107,7 +110,7 @@ func (r *revenueLevelTierRepository) GetTierForRevenue(revenue float64) (*models
 	            AND (max_revenue IS NULL OR revenue < max_revenue)
 	          ORDER BY level_number DESC
 	          LIMIT 1`
-	err := r.db.QueryRow(query, revenue).Scan(
+	err := r.db.QueryRowContext(ctx, query, revenue).Scan(
 		&tier.ID, &tier.LevelNumber, &tier.LevelName, &tier.MinRevenue, &tier.MaxRevenue,
 		&tier.DisplayOrder, &tier.ColorCode, &tier.Description, &tier.CreatedAt, &tier.UpdatedAt,
 	)
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
@@ -11,14 +12,14 @@ import (
 )
 
 type rotationStaffBranchPositionRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewRotationStaffBranchPositionRepository(db *sql.DB) interfaces.RotationStaffBranchPositionRepository {
+func NewRotationStaffBranchPositionRepository(db DBTX) interfaces.RotationStaffBranchPositionRepository {
 	return &rotationStaffBranchPositionRepository{db: db}
 }
 
-func (r *rotationStaffBranchPositionRepository) Create(mapping *models.RotationStaffBranchPosition) error {
+func (r *rotationStaffBranchPositionRepository) Create(ctx context.Context, mapping *models.RotationStaffBranchPosition) error {
 	mapping.ID = uuid.New()
 	mapping.CreatedAt = time.Now()
 	mapping.UpdatedAt = time.Now()
@@ -32,7 +33,7 @@ func (r *rotationStaffBranchPositionRepository) Create(mapping *models.RotationS
 		notes = sql.NullString{String: mapping.Notes, Valid: true}
 	}
 
-	return r.db.QueryRow(query,
+	return r.db.QueryRowContext(ctx, query,
 		mapping.ID,
 		mapping.RotationStaffID,
 		mapping.BranchPositionID,
@@ -44,14 +45,14 @@ func (r *rotationStaffBranchPositionRepository) Create(mapping *models.RotationS
 	).Scan(&mapping.CreatedAt, &mapping.UpdatedAt)
 }
 
-func (r *rotationStaffBranchPositionRepository) GetByID(id uuid.UUID) (*models.RotationStaffBranchPosition, error) {
+func (r *rotationStaffBranchPositionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.RotationStaffBranchPosition, error) {
 	mapping := &models.RotationStaffBranchPosition{}
 	query := `SELECT id, rotation_staff_id, branch_position_id, substitution_level, is_active, notes, created_at, updated_at
 	          FROM rotation_staff_branch_positions WHERE id = $1`
 
 	var notes sql.NullString
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&mapping.ID,
 		&mapping.RotationStaffID,
 		&mapping.BranchPositionID,
@@ -75,12 +76,12 @@ func (r *rotationStaffBranchPositionRepository) GetByID(id uuid.UUID) (*models.R
 	return mapping, nil
 }
 
-func (r *rotationStaffBranchPositionRepository) List() ([]*models.RotationStaffBranchPosition, error) {
+func (r *rotationStaffBranchPositionRepository) List(ctx context.Context) ([]*models.RotationStaffBranchPosition, error) {
 	query := `SELECT id, rotation_staff_id, branch_position_id, substitution_level, is_active, notes, created_at, updated_at
 	          FROM rotation_staff_branch_positions 
 	          ORDER BY rotation_staff_id, substitution_level, branch_position_id`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -114,13 +115,13 @@ func (r *rotationStaffBranchPositionRepository) List() ([]*models.RotationStaffB
 	return mappings, rows.Err()
 }
 
-func (r *rotationStaffBranchPositionRepository) GetByStaffID(rotationStaffID uuid.UUID) ([]*models.RotationStaffBranchPosition, error) {
+func (r *rotationStaffBranchPositionRepository) GetByStaffID(ctx context.Context, rotationStaffID uuid.UUID) ([]*models.RotationStaffBranchPosition, error) {
 	query := `SELECT id, rotation_staff_id, branch_position_id, substitution_level, is_active, notes, created_at, updated_at
 	          FROM rotation_staff_branch_positions 
 	          WHERE rotation_staff_id = $1 
 	          ORDER BY substitution_level, branch_position_id`
 
-	rows, err := r.db.Query(query, rotationStaffID)
+	rows, err := r.db.QueryContext(ctx, query, rotationStaffID)
 	if err != nil {
 		return nil, err
 	}
@@ -154,13 +155,13 @@ func (r *rotationStaffBranchPositionRepository) GetByStaffID(rotationStaffID uui
 	return mappings, rows.Err()
 }
 
-func (r *rotationStaffBranchPositionRepository) GetByPositionID(branchPositionID uuid.UUID) ([]*models.RotationStaffBranchPosition, error) {
+func (r *rotationStaffBranchPositionRepository) GetByPositionID(ctx context.Context, branchPositionID uuid.UUID) ([]*models.RotationStaffBranchPosition, error) {
 	query := `SELECT id, rotation_staff_id, branch_position_id, substitution_level, is_active, notes, created_at, updated_at
 	          FROM rotation_staff_branch_positions 
 	          WHERE branch_position_id = $1 AND is_active = true
 	          ORDER BY substitution_level, rotation_staff_id`
 
-	rows, err := r.db.Query(query, branchPositionID)
+	rows, err := r.db.QueryContext(ctx, query, branchPositionID)
 	if err != nil {
 		return nil, err
 	}
@@ -194,7 +195,7 @@ func (r *rotationStaffBranchPositionRepository) GetByPositionID(branchPositionID
 	return mappings, rows.Err()
 }
 
-func (r *rotationStaffBranchPositionRepository) GetByStaffAndPosition(rotationStaffID uuid.UUID, branchPositionID uuid.UUID) (*models.RotationStaffBranchPosition, error) {
+func (r *rotationStaffBranchPositionRepository) GetByStaffAndPosition(ctx context.Context, rotationStaffID uuid.UUID, branchPositionID uuid.UUID) (*models.RotationStaffBranchPosition, error) {
 	mapping := &models.RotationStaffBranchPosition{}
 	query := `SELECT id, rotation_staff_id, branch_position_id, substitution_level, is_active, notes, created_at, updated_at
 	          FROM rotation_staff_branch_positions 
@@ -202,7 +203,7 @@ func (r *rotationStaffBranchPositionRepository) GetByStaffAndPosition(rotationSt
 
 	var notes sql.NullString
 
-	err := r.db.QueryRow(query, rotationStaffID, branchPositionID).Scan(
+	err := r.db.QueryRowContext(ctx, query, rotationStaffID, branchPositionID).Scan(
 		&mapping.ID,
 		&mapping.RotationStaffID,
 		&mapping.BranchPositionID,
@@ -226,7 +227,7 @@ func (r *rotationStaffBranchPositionRepository) GetByStaffAndPosition(rotationSt
 	return mapping, nil
 }
 
-func (r *rotationStaffBranchPositionRepository) Update(mapping *models.RotationStaffBranchPosition) error {
+func (r *rotationStaffBranchPositionRepository) Update(ctx context.Context, mapping *models.RotationStaffBranchPosition) error {
 	mapping.UpdatedAt = time.Now()
 
 	query := `UPDATE rotation_staff_branch_positions 
@@ -238,7 +239,7 @@ func (r *rotationStaffBranchPositionRepository) Update(mapping *models.RotationS
 		notes = sql.NullString{String: mapping.Notes, Valid: true}
 	}
 
-	_, err := r.db.Exec(query,
+	_, err := r.db.ExecContext(ctx, query,
 		mapping.SubstitutionLevel,
 		mapping.IsActive,
 		notes,
@@ -248,15 +249,15 @@ func (r *rotationStaffBranchPositionRepository) Update(mapping *models.RotationS
 	return err
 }
 
-func (r *rotationStaffBranchPositionRepository) Delete(id uuid.UUID) error {
+func (r *rotationStaffBranchPositionRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM rotation_staff_branch_positions WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *rotationStaffBranchPositionRepository) DeleteByStaffAndPosition(rotationStaffID uuid.UUID, branchPositionID uuid.UUID) error {
+func (r *rotationStaffBranchPositionRepository) DeleteByStaffAndPosition(ctx context.Context, rotationStaffID uuid.UUID, branchPositionID uuid.UUID) error {
 	query := `DELETE FROM rotation_staff_branch_positions 
 	          WHERE rotation_staff_id = $1 AND branch_position_id = $2`
-	_, err := r.db.Exec(query, rotationStaffID, branchPositionID)
+	_, err := r.db.ExecContext(ctx, query, rotationStaffID, branchPositionID)
 	return err
 }
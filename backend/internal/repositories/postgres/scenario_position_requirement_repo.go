@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/google/uuid"
@@ -9,29 +10,30 @@ import (
 )
 
 type scenarioPositionRequirementRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewScenarioPositionRequirementRepository(db *sql.DB) interfaces.ScenarioPositionRequirementRepository {
+func NewScenarioPositionRequirementRepository(db DBTX) interfaces.ScenarioPositionRequirementRepository {
 	return &scenarioPositionRequirementRepository{db: db}
 }
 
-func (r *scenarioPositionRequirementRepository) Create(requirement *models.ScenarioPositionRequirement) error {
+func (r *scenarioPositionRequirementRepository) Create(ctx context.Context, requirement *models.ScenarioPositionRequirement) error {
 	query := `INSERT INTO scenario_position_requirements 
 	          (id, scenario_id, position_id, preferred_staff, minimum_staff, override_base)
 	          VALUES ($1, $2, $3, $4, $5, $6) RETURNING created_at, updated_at`
-	return r.db.QueryRow(
+	return r.db.QueryRowContext(
+		ctx,
 		query,
 		requirement.ID, requirement.ScenarioID, requirement.PositionID,
 		requirement.PreferredStaff, requirement.MinimumStaff, requirement.OverrideBase,
 	).Scan(&requirement.CreatedAt, &requirement.UpdatedAt)
 }
 
-func (r *scenarioPositionRequirementRepository) GetByID(id uuid.UUID) (*models.ScenarioPositionRequirement, error) {
+func (r *scenarioPositionRequirementRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ScenarioPositionRequirement, error) {
 	requirement := &models.ScenarioPositionRequirement{}
 	query := `SELECT id, scenario_id, position_id, preferred_staff, minimum_staff, override_base, created_at, updated_at
 	          FROM scenario_position_requirements WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&requirement.ID, &requirement.ScenarioID, &requirement.PositionID,
 		&requirement.PreferredStaff, &requirement.MinimumStaff, &requirement.OverrideBase,
 		&requirement.CreatedAt, &requirement.UpdatedAt,
@@ -45,10 +47,10 @@ func (r *scenarioPositionRequirementRepository) GetByID(id uuid.UUID) (*models.S
 	return requirement, nil
 }
 
-func (r *scenarioPositionRequirementRepository) GetByScenarioID(scenarioID uuid.UUID) ([]*models.ScenarioPositionRequirement, error) {
+func (r *scenarioPositionRequirementRepository) GetByScenarioID(ctx context.Context, scenarioID uuid.UUID) ([]*models.ScenarioPositionRequirement, error) {
 	query := `SELECT id, scenario_id, position_id, preferred_staff, minimum_staff, override_base, created_at, updated_at
 	          FROM scenario_position_requirements WHERE scenario_id = $1 ORDER BY position_id`
-	rows, err := r.db.Query(query, scenarioID)
+	rows, err := r.db.QueryContext(ctx, query, scenarioID)
 	if err != nil {
 		return nil, err
 	}
@@ -69,11 +71,11 @@ func (r *scenarioPositionRequirementRepository) GetByScenarioID(scenarioID uuid.
 	return requirements, rows.Err()
 }
 
-func (r *scenarioPositionRequirementRepository) GetByScenarioAndPosition(scenarioID, positionID uuid.UUID) (*models.ScenarioPositionRequirement, error) {
+func (r *scenarioPositionRequirementRepository) GetByScenarioAndPosition(ctx context.Context, scenarioID, positionID uuid.UUID) (*models.ScenarioPositionRequirement, error) {
 	requirement := &models.ScenarioPositionRequirement{}
 	query := `SELECT id, scenario_id, position_id, preferred_staff, minimum_staff, override_base, created_at, updated_at
 	          FROM scenario_position_requirements WHERE scenario_id = $1 AND position_id = $2`
-	err := r.db.QueryRow(query, scenarioID, positionID).Scan(
+	err := r.db.QueryRowContext(ctx, query, scenarioID, positionID).Scan(
 		&requirement.ID, &requirement.ScenarioID, &requirement.PositionID,
 		&requirement.PreferredStaff, &requirement.MinimumStaff, &requirement.OverrideBase,
 		&requirement.CreatedAt, &requirement.UpdatedAt,
@@ -87,30 +89,31 @@ func (r *scenarioPositionRequirementRepository) GetByScenarioAndPosition(scenari
 	return requirement, nil
 }
 
-func (r *scenarioPositionRequirementRepository) Update(requirement *models.ScenarioPositionRequirement) error {
+func (r *scenarioPositionRequirementRepository) Update(ctx context.Context, requirement *models.ScenarioPositionRequirement) error {
 	query := `UPDATE scenario_position_requirements
 	          SET preferred_staff = $1, minimum_staff = $2, override_base = $3, updated_at = CURRENT_TIMESTAMP
 	          WHERE id = $4 RETURNING updated_at`
-	return r.db.QueryRow(
+	return r.db.QueryRowContext(
+		ctx,
 		query,
 		requirement.PreferredStaff, requirement.MinimumStaff, requirement.OverrideBase, requirement.ID,
 	).Scan(&requirement.UpdatedAt)
 }
 
-func (r *scenarioPositionRequirementRepository) Delete(id uuid.UUID) error {
+func (r *scenarioPositionRequirementRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM scenario_position_requirements WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *scenarioPositionRequirementRepository) DeleteByScenarioID(scenarioID uuid.UUID) error {
+func (r *scenarioPositionRequirementRepository) DeleteByScenarioID(ctx context.Context, scenarioID uuid.UUID) error {
 	query := `DELETE FROM scenario_position_requirements WHERE scenario_id = $1`
-	_, err := r.db.Exec(query, scenarioID)
+	_, err := r.db.ExecContext(ctx, query, scenarioID)
 	return err
 }
 
-func (r *scenarioPositionRequirementRepository) BulkUpsert(requirements []*models.ScenarioPositionRequirement) error {
-	tx, err := r.db.Begin()
+func (r *scenarioPositionRequirementRepository) BulkUpsert(ctx context.Context, requirements []*models.ScenarioPositionRequirement) error {
+	tx, err := beginTx(ctx, r.db)
 	if err != nil {
 		return err
 	}
@@ -130,7 +133,8 @@ func (r *scenarioPositionRequirementRepository) BulkUpsert(requirements []*model
 		if requirement.ID == uuid.Nil {
 			requirement.ID = uuid.New()
 		}
-		err := tx.QueryRow(
+		err := tx.QueryRowContext(
+			ctx,
 			query,
 			requirement.ID, requirement.ScenarioID, requirement.PositionID,
 			requirement.PreferredStaff, requirement.MinimumStaff, requirement.OverrideBase,
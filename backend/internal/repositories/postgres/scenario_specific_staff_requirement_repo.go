@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 
 	"vsq-oper-manpower/backend/internal/domain/interfaces"
@@ -10,28 +11,29 @@ import (
 )
 
 type scenarioSpecificStaffRequirementRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewScenarioSpecificStaffRequirementRepository(db *sql.DB) interfaces.ScenarioSpecificStaffRequirementRepository {
+func NewScenarioSpecificStaffRequirementRepository(db DBTX) interfaces.ScenarioSpecificStaffRequirementRepository {
 	return &scenarioSpecificStaffRequirementRepository{db: db}
 }
 
-func (r *scenarioSpecificStaffRequirementRepository) Create(requirement *models.ScenarioSpecificStaffRequirement) error {
+func (r *scenarioSpecificStaffRequirementRepository) Create(ctx context.Context, requirement *models.ScenarioSpecificStaffRequirement) error {
 	query := `INSERT INTO scenario_specific_staff_requirements 
 	          (id, scenario_id, staff_id)
 	          VALUES ($1, $2, $3) RETURNING created_at, updated_at`
-	return r.db.QueryRow(
+	return r.db.QueryRowContext(
+		ctx,
 		query,
 		requirement.ID, requirement.ScenarioID, requirement.StaffID,
 	).Scan(&requirement.CreatedAt, &requirement.UpdatedAt)
 }
 
-func (r *scenarioSpecificStaffRequirementRepository) GetByID(id uuid.UUID) (*models.ScenarioSpecificStaffRequirement, error) {
+func (r *scenarioSpecificStaffRequirementRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.ScenarioSpecificStaffRequirement, error) {
 	requirement := &models.ScenarioSpecificStaffRequirement{}
 	query := `SELECT id, scenario_id, staff_id, created_at, updated_at
 	          FROM scenario_specific_staff_requirements WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&requirement.ID, &requirement.ScenarioID, &requirement.StaffID,
 		&requirement.CreatedAt, &requirement.UpdatedAt,
 	)
@@ -44,10 +46,10 @@ func (r *scenarioSpecificStaffRequirementRepository) GetByID(id uuid.UUID) (*mod
 	return requirement, nil
 }
 
-func (r *scenarioSpecificStaffRequirementRepository) GetByScenarioID(scenarioID uuid.UUID) ([]*models.ScenarioSpecificStaffRequirement, error) {
+func (r *scenarioSpecificStaffRequirementRepository) GetByScenarioID(ctx context.Context, scenarioID uuid.UUID) ([]*models.ScenarioSpecificStaffRequirement, error) {
 	query := `SELECT id, scenario_id, staff_id, created_at, updated_at
 	          FROM scenario_specific_staff_requirements WHERE scenario_id = $1 ORDER BY staff_id`
-	rows, err := r.db.Query(query, scenarioID)
+	rows, err := r.db.QueryContext(ctx, query, scenarioID)
 	if err != nil {
 		return nil, err
 	}
@@ -67,20 +69,20 @@ func (r *scenarioSpecificStaffRequirementRepository) GetByScenarioID(scenarioID
 	return requirements, rows.Err()
 }
 
-func (r *scenarioSpecificStaffRequirementRepository) Delete(id uuid.UUID) error {
+func (r *scenarioSpecificStaffRequirementRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM scenario_specific_staff_requirements WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *scenarioSpecificStaffRequirementRepository) DeleteByScenarioID(scenarioID uuid.UUID) error {
+func (r *scenarioSpecificStaffRequirementRepository) DeleteByScenarioID(ctx context.Context, scenarioID uuid.UUID) error {
 	query := `DELETE FROM scenario_specific_staff_requirements WHERE scenario_id = $1`
-	_, err := r.db.Exec(query, scenarioID)
+	_, err := r.db.ExecContext(ctx, query, scenarioID)
 	return err
 }
 
-func (r *scenarioSpecificStaffRequirementRepository) BulkUpsert(requirements []*models.ScenarioSpecificStaffRequirement) error {
-	tx, err := r.db.Begin()
+func (r *scenarioSpecificStaffRequirementRepository) BulkUpsert(ctx context.Context, requirements []*models.ScenarioSpecificStaffRequirement) error {
+	tx, err := beginTx(ctx, r.db)
 	if err != nil {
 		return err
 	}
@@ -97,7 +99,8 @@ func (r *scenarioSpecificStaffRequirementRepository) BulkUpsert(requirements []*
 		if requirement.ID == uuid.Nil {
 			requirement.ID = uuid.New()
 		}
-		err := tx.QueryRow(
+		err := tx.QueryRowContext(
+			ctx,
 			query,
 			requirement.ID, requirement.ScenarioID, requirement.StaffID,
 		).Scan(&requirement.CreatedAt, &requirement.UpdatedAt)
@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
@@ -11,30 +12,30 @@ import (
 )
 
 type staffGroupPositionRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewStaffGroupPositionRepository(db *sql.DB) interfaces.StaffGroupPositionRepository {
+func NewStaffGroupPositionRepository(db DBTX) interfaces.StaffGroupPositionRepository {
 	return &staffGroupPositionRepository{db: db}
 }
 
-func (r *staffGroupPositionRepository) Create(sgp *models.StaffGroupPosition) error {
+func (r *staffGroupPositionRepository) Create(ctx context.Context, sgp *models.StaffGroupPosition) error {
 	sgp.ID = uuid.New()
 	sgp.CreatedAt = time.Now()
 
 	query := `INSERT INTO staff_group_positions (id, staff_group_id, position_id, created_at)
 	          VALUES ($1, $2, $3, $4) RETURNING created_at`
 
-	return r.db.QueryRow(query, sgp.ID, sgp.StaffGroupID, sgp.PositionID, sgp.CreatedAt).
+	return r.db.QueryRowContext(ctx, query, sgp.ID, sgp.StaffGroupID, sgp.PositionID, sgp.CreatedAt).
 		Scan(&sgp.CreatedAt)
 }
 
-func (r *staffGroupPositionRepository) GetByID(id uuid.UUID) (*models.StaffGroupPosition, error) {
+func (r *staffGroupPositionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.StaffGroupPosition, error) {
 	sgp := &models.StaffGroupPosition{}
 	query := `SELECT id, staff_group_id, position_id, created_at
 	          FROM staff_group_positions WHERE id = $1`
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&sgp.ID, &sgp.StaffGroupID, &sgp.PositionID, &sgp.CreatedAt,
 	)
 	if err == sql.ErrNoRows {
@@ -47,11 +48,11 @@ func (r *staffGroupPositionRepository) GetByID(id uuid.UUID) (*models.StaffGroup
 	return sgp, nil
 }
 
-func (r *staffGroupPositionRepository) GetByStaffGroupID(staffGroupID uuid.UUID) ([]*models.StaffGroupPosition, error) {
+func (r *staffGroupPositionRepository) GetByStaffGroupID(ctx context.Context, staffGroupID uuid.UUID) ([]*models.StaffGroupPosition, error) {
 	query := `SELECT id, staff_group_id, position_id, created_at
 	          FROM staff_group_positions WHERE staff_group_id = $1 ORDER BY position_id`
 
-	rows, err := r.db.Query(query, staffGroupID)
+	rows, err := r.db.QueryContext(ctx, query, staffGroupID)
 	if err != nil {
 		return nil, err
 	}
@@ -71,11 +72,11 @@ func (r *staffGroupPositionRepository) GetByStaffGroupID(staffGroupID uuid.UUID)
 	return positions, rows.Err()
 }
 
-func (r *staffGroupPositionRepository) GetByPositionID(positionID uuid.UUID) ([]*models.StaffGroupPosition, error) {
+func (r *staffGroupPositionRepository) GetByPositionID(ctx context.Context, positionID uuid.UUID) ([]*models.StaffGroupPosition, error) {
 	query := `SELECT id, staff_group_id, position_id, created_at
 	          FROM staff_group_positions WHERE position_id = $1 ORDER BY staff_group_id`
 
-	rows, err := r.db.Query(query, positionID)
+	rows, err := r.db.QueryContext(ctx, query, positionID)
 	if err != nil {
 		return nil, err
 	}
@@ -95,14 +96,38 @@ func (r *staffGroupPositionRepository) GetByPositionID(positionID uuid.UUID) ([]
 	return positions, rows.Err()
 }
 
-func (r *staffGroupPositionRepository) Delete(id uuid.UUID) error {
+func (r *staffGroupPositionRepository) List(ctx context.Context) ([]*models.StaffGroupPosition, error) {
+	query := `SELECT id, staff_group_id, position_id, created_at
+	          FROM staff_group_positions ORDER BY staff_group_id, position_id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	positions := []*models.StaffGroupPosition{}
+	for rows.Next() {
+		sgp := &models.StaffGroupPosition{}
+		if err := rows.Scan(
+			&sgp.ID, &sgp.StaffGroupID, &sgp.PositionID, &sgp.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		positions = append(positions, sgp)
+	}
+
+	return positions, rows.Err()
+}
+
+func (r *staffGroupPositionRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM staff_group_positions WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *staffGroupPositionRepository) DeleteByStaffGroupAndPosition(staffGroupID uuid.UUID, positionID uuid.UUID) error {
+func (r *staffGroupPositionRepository) DeleteByStaffGroupAndPosition(ctx context.Context, staffGroupID uuid.UUID, positionID uuid.UUID) error {
 	query := `DELETE FROM staff_group_positions WHERE staff_group_id = $1 AND position_id = $2`
-	_, err := r.db.Exec(query, staffGroupID, positionID)
+	_, err := r.db.ExecContext(ctx, query, staffGroupID, positionID)
 	return err
 }
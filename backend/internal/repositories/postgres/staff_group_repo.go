@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
@@ -11,14 +12,14 @@ import (
 )
 
 type staffGroupRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewStaffGroupRepository(db *sql.DB) interfaces.StaffGroupRepository {
+func NewStaffGroupRepository(db DBTX) interfaces.StaffGroupRepository {
 	return &staffGroupRepository{db: db}
 }
 
-func (r *staffGroupRepository) Create(staffGroup *models.StaffGroup) error {
+func (r *staffGroupRepository) Create(ctx context.Context, staffGroup *models.StaffGroup) error {
 	staffGroup.ID = uuid.New()
 	staffGroup.CreatedAt = time.Now()
 	staffGroup.UpdatedAt = time.Now()
@@ -31,18 +32,18 @@ func (r *staffGroupRepository) Create(staffGroup *models.StaffGroup) error {
 		description = sql.NullString{String: staffGroup.Description, Valid: true}
 	}
 
-	return r.db.QueryRow(query, staffGroup.ID, staffGroup.Name, description, staffGroup.IsActive, staffGroup.CreatedAt, staffGroup.UpdatedAt).
+	return r.db.QueryRowContext(ctx, query, staffGroup.ID, staffGroup.Name, description, staffGroup.IsActive, staffGroup.CreatedAt, staffGroup.UpdatedAt).
 		Scan(&staffGroup.CreatedAt, &staffGroup.UpdatedAt)
 }
 
-func (r *staffGroupRepository) GetByID(id uuid.UUID) (*models.StaffGroup, error) {
+func (r *staffGroupRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.StaffGroup, error) {
 	staffGroup := &models.StaffGroup{}
 	query := `SELECT id, name, description, is_active, created_at, updated_at
 	          FROM staff_groups WHERE id = $1`
 
 	var description sql.NullString
 
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&staffGroup.ID, &staffGroup.Name, &description, &staffGroup.IsActive, &staffGroup.CreatedAt, &staffGroup.UpdatedAt,
 	)
 	if err == sql.ErrNoRows {
@@ -59,11 +60,11 @@ func (r *staffGroupRepository) GetByID(id uuid.UUID) (*models.StaffGroup, error)
 	return staffGroup, nil
 }
 
-func (r *staffGroupRepository) List() ([]*models.StaffGroup, error) {
+func (r *staffGroupRepository) List(ctx context.Context) ([]*models.StaffGroup, error) {
 	query := `SELECT id, name, description, is_active, created_at, updated_at
 	          FROM staff_groups ORDER BY name`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -90,14 +91,14 @@ func (r *staffGroupRepository) List() ([]*models.StaffGroup, error) {
 	return staffGroups, rows.Err()
 }
 
-func (r *staffGroupRepository) GetByPositionID(positionID uuid.UUID) ([]*models.StaffGroup, error) {
+func (r *staffGroupRepository) GetByPositionID(ctx context.Context, positionID uuid.UUID) ([]*models.StaffGroup, error) {
 	query := `SELECT DISTINCT sg.id, sg.name, sg.description, sg.is_active, sg.created_at, sg.updated_at
 	          FROM staff_groups sg
 	          INNER JOIN staff_group_positions sgp ON sg.id = sgp.staff_group_id
 	          WHERE sgp.position_id = $1 AND sg.is_active = true
 	          ORDER BY sg.name`
 
-	rows, err := r.db.Query(query, positionID)
+	rows, err := r.db.QueryContext(ctx, query, positionID)
 	if err != nil {
 		return nil, err
 	}
@@ -124,7 +125,7 @@ func (r *staffGroupRepository) GetByPositionID(positionID uuid.UUID) ([]*models.
 	return staffGroups, rows.Err()
 }
 
-func (r *staffGroupRepository) Update(staffGroup *models.StaffGroup) error {
+func (r *staffGroupRepository) Update(ctx context.Context, staffGroup *models.StaffGroup) error {
 	staffGroup.UpdatedAt = time.Now()
 
 	query := `UPDATE staff_groups SET name = $1, description = $2, is_active = $3, updated_at = $4 WHERE id = $5`
@@ -134,12 +135,12 @@ func (r *staffGroupRepository) Update(staffGroup *models.StaffGroup) error {
 		description = sql.NullString{String: staffGroup.Description, Valid: true}
 	}
 
-	_, err := r.db.Exec(query, staffGroup.Name, description, staffGroup.IsActive, staffGroup.UpdatedAt, staffGroup.ID)
+	_, err := r.db.ExecContext(ctx, query, staffGroup.Name, description, staffGroup.IsActive, staffGroup.UpdatedAt, staffGroup.ID)
 	return err
 }
 
-func (r *staffGroupRepository) Delete(id uuid.UUID) error {
+func (r *staffGroupRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM staff_groups WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
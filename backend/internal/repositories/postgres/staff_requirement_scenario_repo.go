@@ -1,6 +1,7 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 
 	"vsq-oper-manpower/backend/internal/domain/interfaces"
@@ -10,21 +11,22 @@ import (
 )
 
 type staffRequirementScenarioRepository struct {
-	db *sql.DB
+	db DBTX
 }
 
-func NewStaffRequirementScenarioRepository(db *sql.DB) interfaces.StaffRequirementScenarioRepository {
+func NewStaffRequirementScenarioRepository(db DBTX) interfaces.StaffRequirementScenarioRepository {
 	return &staffRequirementScenarioRepository{db: db}
 }
 
-func (r *staffRequirementScenarioRepository) Create(scenario *models.StaffRequirementScenario) error {
+func (r *staffRequirementScenarioRepository) Create(ctx context.Context, scenario *models.StaffRequirementScenario) error {
 	query := `INSERT INTO staff_requirement_scenarios 
 	          (id, scenario_name, description, doctor_id, branch_id, revenue_level_tier_id, min_revenue, max_revenue, 
 	           use_day_of_week_revenue, use_specific_date_revenue, doctor_count, min_doctor_count, 
 	           day_of_week, is_default, is_active, priority)
 	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 	          RETURNING created_at, updated_at`
-	return r.db.QueryRow(
+	return r.db.QueryRowContext(
+		ctx,
 		query,
 		scenario.ID, scenario.ScenarioName, scenario.Description, scenario.DoctorID, scenario.BranchID,
 		scenario.RevenueLevelTierID, scenario.MinRevenue, scenario.MaxRevenue, scenario.UseDayOfWeekRevenue,
@@ -33,13 +35,13 @@ func (r *staffRequirementScenarioRepository) Create(scenario *models.StaffRequir
 	).Scan(&scenario.CreatedAt, &scenario.UpdatedAt)
 }
 
-func (r *staffRequirementScenarioRepository) GetByID(id uuid.UUID) (*models.StaffRequirementScenario, error) {
+func (r *staffRequirementScenarioRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.StaffRequirementScenario, error) {
 	scenario := &models.StaffRequirementScenario{}
 	query := `SELECT id, scenario_name, description, doctor_id, branch_id, revenue_level_tier_id, min_revenue, max_revenue,
 	          use_day_of_week_revenue, use_specific_date_revenue, doctor_count, min_doctor_count,
 	          day_of_week, is_default, is_active, priority, created_at, updated_at
 	          FROM staff_requirement_scenarios WHERE id = $1`
-	err := r.db.QueryRow(query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&scenario.ID, &scenario.ScenarioName, &scenario.Description, &scenario.DoctorID, &scenario.BranchID,
 		&scenario.RevenueLevelTierID, &scenario.MinRevenue, &scenario.MaxRevenue, &scenario.UseDayOfWeekRevenue,
 		&scenario.UseSpecificDateRevenue, &scenario.DoctorCount, &scenario.MinDoctorCount,
@@ -55,13 +57,14 @@ func (r *staffRequirementScenarioRepository) GetByID(id uuid.UUID) (*models.Staf
 	return scenario, nil
 }
 
-func (r *staffRequirementScenarioRepository) Update(scenario *models.StaffRequirementScenario) error {
+func (r *staffRequirementScenarioRepository) Update(ctx context.Context, scenario *models.StaffRequirementScenario) error {
 	query := `UPDATE staff_requirement_scenarios
 	          SET scenario_name = $1, description = $2, doctor_id = $3, branch_id = $4, revenue_level_tier_id = $5, min_revenue = $6, max_revenue = $7,
 	              use_day_of_week_revenue = $8, use_specific_date_revenue = $9, doctor_count = $10, min_doctor_count = $11,
 	              day_of_week = $12, is_default = $13, is_active = $14, priority = $15, updated_at = CURRENT_TIMESTAMP
 	          WHERE id = $16 RETURNING updated_at`
-	return r.db.QueryRow(
+	return r.db.QueryRowContext(
+		ctx,
 		query,
 		scenario.ScenarioName, scenario.Description, scenario.DoctorID, scenario.BranchID, scenario.RevenueLevelTierID,
 		scenario.MinRevenue, scenario.MaxRevenue, scenario.UseDayOfWeekRevenue,
@@ -70,13 +73,13 @@ func (r *staffRequirementScenarioRepository) Update(scenario *models.StaffRequir
 	).Scan(&scenario.UpdatedAt)
 }
 
-func (r *staffRequirementScenarioRepository) Delete(id uuid.UUID) error {
+func (r *staffRequirementScenarioRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM staff_requirement_scenarios WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *staffRequirementScenarioRepository) List(includeInactive bool) ([]*models.StaffRequirementScenario, error) {
+func (r *staffRequirementScenarioRepository) List(ctx context.Context, includeInactive bool) ([]*models.StaffRequirementScenario, error) {
 	query := `SELECT id, scenario_name, description, doctor_id, branch_id, revenue_level_tier_id, min_revenue, max_revenue,
 	          use_day_of_week_revenue, use_specific_date_revenue, doctor_count, min_doctor_count,
 	          day_of_week, is_default, is_active, priority, created_at, updated_at
@@ -86,7 +89,7 @@ func (r *staffRequirementScenarioRepository) List(includeInactive bool) ([]*mode
 	}
 	query += " ORDER BY priority DESC, scenario_name"
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -109,14 +112,14 @@ func (r *staffRequirementScenarioRepository) List(includeInactive bool) ([]*mode
 	return scenarios, rows.Err()
 }
 
-func (r *staffRequirementScenarioRepository) GetActiveOrderedByPriority() ([]*models.StaffRequirementScenario, error) {
+func (r *staffRequirementScenarioRepository) GetActiveOrderedByPriority(ctx context.Context) ([]*models.StaffRequirementScenario, error) {
 	query := `SELECT id, scenario_name, description, doctor_id, branch_id, revenue_level_tier_id, min_revenue, max_revenue,
 	          use_day_of_week_revenue, use_specific_date_revenue, doctor_count, min_doctor_count,
 	          day_of_week, is_default, is_active, priority, created_at, updated_at
 	          FROM staff_requirement_scenarios
 	          WHERE is_active = true
 	          ORDER BY priority DESC, scenario_name`
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -139,13 +142,13 @@ func (r *staffRequirementScenarioRepository) GetActiveOrderedByPriority() ([]*mo
 	return scenarios, rows.Err()
 }
 
-func (r *staffRequirementScenarioRepository) GetDefault() (*models.StaffRequirementScenario, error) {
+func (r *staffRequirementScenarioRepository) GetDefault(ctx context.Context) (*models.StaffRequirementScenario, error) {
 	scenario := &models.StaffRequirementScenario{}
 	query := `SELECT id, scenario_name, description, doctor_id, branch_id, revenue_level_tier_id, min_revenue, max_revenue,
 	          use_day_of_week_revenue, use_specific_date_revenue, doctor_count, min_doctor_count,
 	          day_of_week, is_default, is_active, priority, created_at, updated_at
 	          FROM staff_requirement_scenarios WHERE is_default = true LIMIT 1`
-	err := r.db.QueryRow(query).Scan(
+	err := r.db.QueryRowContext(ctx, query).Scan(
 		&scenario.ID, &scenario.ScenarioName, &scenario.Description, &scenario.DoctorID, &scenario.BranchID,
 		&scenario.RevenueLevelTierID, &scenario.MinRevenue, &scenario.MaxRevenue, &scenario.UseDayOfWeekRevenue,
 		&scenario.UseSpecificDateRevenue, &scenario.DoctorCount, &scenario.MinDoctorCount,
@@ -0,0 +1,114 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+)
+
+// DBTX is the part of *sql.DB and *sql.Tx the repositories use, so the same
+// repository code runs against the pool or inside a unit of work.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// repoTx is a transaction started by a repository method. When the repository is
+// already bound to a unit of work it joins that transaction instead: Commit and
+// Rollback become no-ops and the unit of work decides the outcome.
+type repoTx struct {
+	*sql.Tx
+	owned bool
+}
+
+func (t *repoTx) Commit() error {
+	if !t.owned {
+		return nil
+	}
+	return t.Tx.Commit()
+}
+
+func (t *repoTx) Rollback() error {
+	if !t.owned {
+		return nil
+	}
+	return t.Tx.Rollback()
+}
+
+// beginTx starts a transaction on db, or joins the caller's transaction
+func beginTx(ctx context.Context, db DBTX) (*repoTx, error) {
+	switch conn := db.(type) {
+	case *sql.Tx:
+		return &repoTx{Tx: conn}, nil
+	case *sql.DB:
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &repoTx{Tx: tx, owned: true}, nil
+	default:
+		return nil, fmt.Errorf("cannot begin transaction on %T", db)
+	}
+}
+
+// txManager hands out repositories bound to a single database transaction
+type txManager struct {
+	db *sql.DB
+}
+
+// NewUnitOfWork creates a unit of work backed by database transactions
+func NewUnitOfWork(db *sql.DB) interfaces.UnitOfWork {
+	return &txManager{db: db}
+}
+
+func (m *txManager) Begin(ctx context.Context) (interfaces.Transaction, error) {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	return &transaction{tx: tx, repos: newRepositorySet(tx)}, nil
+}
+
+func (m *txManager) Do(ctx context.Context, fn func(tx *interfaces.Repositories) error) (err error) {
+	t, err := m.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			t.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(t.Repositories()); err != nil {
+		t.Rollback()
+		return err
+	}
+	return t.Commit()
+}
+
+type transaction struct {
+	tx    *sql.Tx
+	repos *interfaces.Repositories
+}
+
+func (t *transaction) Repositories() *interfaces.Repositories {
+	return t.repos
+}
+
+func (t *transaction) Commit() error {
+	return t.tx.Commit()
+}
+
+// Rollback is safe to defer: after Commit it does nothing
+func (t *transaction) Rollback() error {
+	if err := t.tx.Rollback(); err != nil && err != sql.ErrTxDone {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+type userIdentityRepository struct {
+	db DBTX
+}
+
+func NewUserIdentityRepository(db DBTX) interfaces.UserIdentityRepository {
+	return &userIdentityRepository{db: db}
+}
+
+func (r *userIdentityRepository) Create(ctx context.Context, identity *models.UserIdentity) error {
+	if identity.ID == uuid.Nil {
+		identity.ID = uuid.New()
+	}
+	query := `INSERT INTO user_identities (id, user_id, issuer, subject, email, last_login_at)
+	          VALUES ($1, $2, $3, $4, $5, $6) RETURNING created_at`
+	return r.db.QueryRowContext(ctx, query, identity.ID, identity.UserID, identity.Issuer, identity.Subject,
+		identity.Email, identity.LastLoginAt).Scan(&identity.CreatedAt)
+}
+
+func scanUserIdentity(scanner interface{ Scan(...interface{}) error }) (*models.UserIdentity, error) {
+	identity := &models.UserIdentity{}
+	var lastLoginAt sql.NullTime
+	if err := scanner.Scan(
+		&identity.ID, &identity.UserID, &identity.Issuer, &identity.Subject,
+		&identity.Email, &lastLoginAt, &identity.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if lastLoginAt.Valid {
+		identity.LastLoginAt = &lastLoginAt.Time
+	}
+	return identity, nil
+}
+
+func (r *userIdentityRepository) GetByIssuerAndSubject(ctx context.Context, issuer, subject string) (*models.UserIdentity, error) {
+	query := `SELECT id, user_id, issuer, subject, COALESCE(email, ''), last_login_at, created_at
+	          FROM user_identities WHERE issuer = $1 AND subject = $2`
+	identity, err := scanUserIdentity(r.db.QueryRowContext(ctx, query, issuer, subject))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return identity, err
+}
+
+func (r *userIdentityRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.UserIdentity, error) {
+	query := `SELECT id, user_id, issuer, subject, COALESCE(email, ''), last_login_at, created_at
+	          FROM user_identities WHERE user_id = $1 ORDER BY created_at`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var identities []*models.UserIdentity
+	for rows.Next() {
+		identity, err := scanUserIdentity(rows)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+	return identities, rows.Err()
+}
+
+func (r *userIdentityRepository) ApproveLink(ctx context.Context, approval *models.UserIdentityLinkApproval) error {
+	query := `INSERT INTO user_identity_link_approvals (user_id, approved_by) VALUES ($1, $2)
+	          ON CONFLICT (user_id) DO UPDATE SET approved_by = EXCLUDED.approved_by, approved_at = CURRENT_TIMESTAMP
+	          RETURNING approved_at`
+	return r.db.QueryRowContext(ctx, query, approval.UserID, approval.ApprovedBy).Scan(&approval.ApprovedAt)
+}
+
+func (r *userIdentityRepository) ConsumeLinkApproval(ctx context.Context, userID uuid.UUID) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM user_identity_link_approvals WHERE user_id = $1`, userID)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := result.RowsAffected()
+	return deleted > 0, err
+}
+
+func (r *userIdentityRepository) TouchLastLogin(ctx context.Context, id uuid.UUID, loginAt time.Time) error {
+	query := `UPDATE user_identities SET last_login_at = $1 WHERE id = $2`
+	_, err := r.db.ExecContext(ctx, query, loginAt, id)
+	return err
+}
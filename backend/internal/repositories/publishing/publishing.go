@@ -0,0 +1,116 @@
+// Package publishing decorates repositories so that successful writes to data the
+// allocation overview depends on publish domain events. It wraps any storage
+// backend; reads and all other repositories pass straight through.
+package publishing
+
+import (
+	"context"
+	"sync"
+
+	"vsq-oper-manpower/backend/internal/domain/events"
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+)
+
+// publishFunc publishes straight to the bus, or buffers inside a transaction
+type publishFunc func(ctx context.Context, evs ...events.Event)
+
+// Wrap returns repos and uow with the schedule, rotation, quota, doctor schedule,
+// branch day and constraint repositories publishing to bus. Writes made through
+// the unit of work publish only once it commits, and not at all if it rolls back.
+func Wrap(repos interfaces.Repositories, uow interfaces.UnitOfWork, bus *events.Bus) (interfaces.Repositories, interfaces.UnitOfWork) {
+	return decorate(repos, bus.Publish), &unitOfWork{UnitOfWork: uow, bus: bus}
+}
+
+func decorate(repos interfaces.Repositories, publish publishFunc) interfaces.Repositories {
+	repos.Schedule = &scheduleRepository{ScheduleRepository: repos.Schedule, publish: publish}
+	repos.Rotation = &rotationRepository{RotationRepository: repos.Rotation, publish: publish}
+	repos.PositionQuota = &positionQuotaRepository{PositionQuotaRepository: repos.PositionQuota, publish: publish}
+	repos.DoctorDefaultSchedule = &doctorDefaultScheduleRepository{DoctorDefaultScheduleRepository: repos.DoctorDefaultSchedule, publish: publish}
+	repos.DoctorWeeklyOffDay = &doctorWeeklyOffDayRepository{DoctorWeeklyOffDayRepository: repos.DoctorWeeklyOffDay, publish: publish}
+	repos.DoctorScheduleOverride = &doctorScheduleOverrideRepository{DoctorScheduleOverrideRepository: repos.DoctorScheduleOverride, publish: publish}
+	repos.DoctorRevenueProfile = &doctorRevenueProfileRepository{DoctorRevenueProfileRepository: repos.DoctorRevenueProfile, publish: publish}
+	repos.DoctorRevenueOverride = &doctorRevenueOverrideRepository{DoctorRevenueOverrideRepository: repos.DoctorRevenueOverride, publish: publish}
+	repos.DoctorLeave = &doctorLeaveRepository{DoctorLeaveRepository: repos.DoctorLeave, publish: publish}
+	repos.DoctorOnOffDay = &doctorOnOffDayRepository{DoctorOnOffDayRepository: repos.DoctorOnOffDay, publish: publish}
+	repos.BranchConstraints = &branchConstraintsRepository{BranchConstraintsRepository: repos.BranchConstraints, publish: publish}
+	repos.BranchClosure = &branchClosureRepository{BranchClosureRepository: repos.BranchClosure, publish: publish}
+	repos.BranchTypeConstraints = &branchTypeConstraintsRepository{BranchTypeConstraintsRepository: repos.BranchTypeConstraints, publish: publish}
+	return repos
+}
+
+type unitOfWork struct {
+	interfaces.UnitOfWork
+	bus *events.Bus
+}
+
+func (u *unitOfWork) Begin(ctx context.Context) (interfaces.Transaction, error) {
+	tx, err := u.UnitOfWork.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t := &transaction{Transaction: tx, ctx: ctx, bus: u.bus}
+	t.repos = decorate(*tx.Repositories(), t.buffer)
+	return t, nil
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(tx *interfaces.Repositories) error) error {
+	var pending pendingEvents
+	err := u.UnitOfWork.Do(ctx, func(tx *interfaces.Repositories) error {
+		repos := decorate(*tx, pending.add)
+		return fn(&repos)
+	})
+	if err != nil {
+		return err
+	}
+	u.bus.Publish(ctx, pending.take()...)
+	return nil
+}
+
+// pendingEvents collects the events of an open transaction
+type pendingEvents struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (p *pendingEvents) add(_ context.Context, evs ...events.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, evs...)
+}
+
+func (p *pendingEvents) take() []events.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	evs := p.events
+	p.events = nil
+	return evs
+}
+
+type transaction struct {
+	interfaces.Transaction
+	ctx     context.Context
+	bus     *events.Bus
+	repos   interfaces.Repositories
+	pending pendingEvents
+}
+
+func (t *transaction) buffer(ctx context.Context, evs ...events.Event) {
+	t.pending.add(ctx, evs...)
+}
+
+func (t *transaction) Repositories() *interfaces.Repositories {
+	return &t.repos
+}
+
+func (t *transaction) Commit() error {
+	if err := t.Transaction.Commit(); err != nil {
+		return err
+	}
+	t.bus.Publish(t.ctx, t.pending.take()...)
+	return nil
+}
+
+func (t *transaction) Rollback() error {
+	t.pending.take()
+	return t.Transaction.Rollback()
+}
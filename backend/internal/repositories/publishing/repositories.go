@@ -0,0 +1,573 @@
+package publishing
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"vsq-oper-manpower/backend/internal/domain/events"
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+)
+
+type scheduleRepository struct {
+	interfaces.ScheduleRepository
+	publish publishFunc
+}
+
+func (r *scheduleRepository) Create(ctx context.Context, schedule *models.StaffSchedule) error {
+	if err := r.ScheduleRepository.Create(ctx, schedule); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.ScheduleChanged, BranchID: schedule.BranchID, Date: schedule.Date})
+	return nil
+}
+
+func (r *scheduleRepository) Update(ctx context.Context, schedule *models.StaffSchedule) error {
+	if err := r.ScheduleRepository.Update(ctx, schedule); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.ScheduleChanged, BranchID: schedule.BranchID, Date: schedule.Date})
+	return nil
+}
+
+// Delete publishes for every branch and date: schedules cannot be looked up by ID
+func (r *scheduleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := r.ScheduleRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.ScheduleChanged})
+	return nil
+}
+
+// DeleteByStaffID publishes for every branch and date, since staff may work at several branches
+func (r *scheduleRepository) DeleteByStaffID(ctx context.Context, staffID uuid.UUID) error {
+	if err := r.ScheduleRepository.DeleteByStaffID(ctx, staffID); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.ScheduleChanged})
+	return nil
+}
+
+type rotationRepository struct {
+	interfaces.RotationRepository
+	publish publishFunc
+}
+
+func rotationEvents(assignments ...*models.RotationAssignment) []events.Event {
+	evs := make([]events.Event, 0, len(assignments))
+	for _, a := range assignments {
+		evs = append(evs, events.Event{Kind: events.RotationChanged, BranchID: a.BranchID, Date: a.Date})
+	}
+	return evs
+}
+
+func (r *rotationRepository) Create(ctx context.Context, assignment *models.RotationAssignment) error {
+	if err := r.RotationRepository.Create(ctx, assignment); err != nil {
+		return err
+	}
+	r.publish(ctx, rotationEvents(assignment)...)
+	return nil
+}
+
+func (r *rotationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	assignment, err := r.RotationRepository.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := r.RotationRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	if assignment != nil {
+		r.publish(ctx, rotationEvents(assignment)...)
+	}
+	return nil
+}
+
+func (r *rotationRepository) DeleteByRotationStaffID(ctx context.Context, rotationStaffID uuid.UUID) error {
+	assignments, err := r.RotationRepository.GetAssignments(ctx, interfaces.RotationFilters{RotationStaffID: &rotationStaffID})
+	if err != nil {
+		return err
+	}
+	if err := r.RotationRepository.DeleteByRotationStaffID(ctx, rotationStaffID); err != nil {
+		return err
+	}
+	r.publish(ctx, rotationEvents(assignments...)...)
+	return nil
+}
+
+// Quotas apply to every date of their branch
+type positionQuotaRepository struct {
+	interfaces.PositionQuotaRepository
+	publish publishFunc
+}
+
+func (r *positionQuotaRepository) Create(ctx context.Context, quota *models.PositionQuota) error {
+	if err := r.PositionQuotaRepository.Create(ctx, quota); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.QuotaChanged, BranchID: quota.BranchID})
+	return nil
+}
+
+func (r *positionQuotaRepository) Update(ctx context.Context, quota *models.PositionQuota) error {
+	if err := r.PositionQuotaRepository.Update(ctx, quota); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.QuotaChanged, BranchID: quota.BranchID})
+	return nil
+}
+
+func (r *positionQuotaRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	quota, err := r.PositionQuotaRepository.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := r.PositionQuotaRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	if quota != nil {
+		r.publish(ctx, events.Event{Kind: events.QuotaChanged, BranchID: quota.BranchID})
+	}
+	return nil
+}
+
+// Default schedules and weekly off days are per weekday; they move doctors
+// between branches on every matching date, so they are published like an
+// override of every date
+type doctorDefaultScheduleRepository struct {
+	interfaces.DoctorDefaultScheduleRepository
+	publish publishFunc
+}
+
+func (r *doctorDefaultScheduleRepository) Create(ctx context.Context, schedule *models.DoctorDefaultSchedule) error {
+	if err := r.DoctorDefaultScheduleRepository.Create(ctx, schedule); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.DoctorOverrideChanged})
+	return nil
+}
+
+func (r *doctorDefaultScheduleRepository) Update(ctx context.Context, schedule *models.DoctorDefaultSchedule) error {
+	if err := r.DoctorDefaultScheduleRepository.Update(ctx, schedule); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.DoctorOverrideChanged})
+	return nil
+}
+
+func (r *doctorDefaultScheduleRepository) Upsert(ctx context.Context, schedule *models.DoctorDefaultSchedule) error {
+	if err := r.DoctorDefaultScheduleRepository.Upsert(ctx, schedule); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.DoctorOverrideChanged})
+	return nil
+}
+
+func (r *doctorDefaultScheduleRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := r.DoctorDefaultScheduleRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.DoctorOverrideChanged})
+	return nil
+}
+
+func (r *doctorDefaultScheduleRepository) DeleteByDoctorID(ctx context.Context, doctorID uuid.UUID) error {
+	if err := r.DoctorDefaultScheduleRepository.DeleteByDoctorID(ctx, doctorID); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.DoctorOverrideChanged})
+	return nil
+}
+
+type doctorWeeklyOffDayRepository struct {
+	interfaces.DoctorWeeklyOffDayRepository
+	publish publishFunc
+}
+
+func (r *doctorWeeklyOffDayRepository) Create(ctx context.Context, offDay *models.DoctorWeeklyOffDay) error {
+	if err := r.DoctorWeeklyOffDayRepository.Create(ctx, offDay); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.DoctorOverrideChanged})
+	return nil
+}
+
+func (r *doctorWeeklyOffDayRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := r.DoctorWeeklyOffDayRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.DoctorOverrideChanged})
+	return nil
+}
+
+func (r *doctorWeeklyOffDayRepository) DeleteByDoctorID(ctx context.Context, doctorID uuid.UUID) error {
+	if err := r.DoctorWeeklyOffDayRepository.DeleteByDoctorID(ctx, doctorID); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.DoctorOverrideChanged})
+	return nil
+}
+
+func (r *doctorWeeklyOffDayRepository) DeleteByDoctorAndDayOfWeek(ctx context.Context, doctorID uuid.UUID, dayOfWeek int) error {
+	if err := r.DoctorWeeklyOffDayRepository.DeleteByDoctorAndDayOfWeek(ctx, doctorID, dayOfWeek); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.DoctorOverrideChanged})
+	return nil
+}
+
+// A doctor override moves the doctor away from their default branch as well as
+// to the override branch, so it affects every branch on its date
+type doctorScheduleOverrideRepository struct {
+	interfaces.DoctorScheduleOverrideRepository
+	publish publishFunc
+}
+
+func (r *doctorScheduleOverrideRepository) Create(ctx context.Context, override *models.DoctorScheduleOverride) error {
+	if err := r.DoctorScheduleOverrideRepository.Create(ctx, override); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.DoctorOverrideChanged, Date: override.Date})
+	return nil
+}
+
+func (r *doctorScheduleOverrideRepository) Update(ctx context.Context, override *models.DoctorScheduleOverride) error {
+	if err := r.DoctorScheduleOverrideRepository.Update(ctx, override); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.DoctorOverrideChanged, Date: override.Date})
+	return nil
+}
+
+func (r *doctorScheduleOverrideRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	override, err := r.DoctorScheduleOverrideRepository.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := r.DoctorScheduleOverrideRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	if override != nil {
+		r.publish(ctx, events.Event{Kind: events.DoctorOverrideChanged, Date: override.Date})
+	}
+	return nil
+}
+
+func (r *doctorScheduleOverrideRepository) DeleteByDoctorID(ctx context.Context, doctorID uuid.UUID) error {
+	if err := r.DoctorScheduleOverrideRepository.DeleteByDoctorID(ctx, doctorID); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.DoctorOverrideChanged})
+	return nil
+}
+
+// Doctor revenue feeds the daily revenue of whichever branch the doctor works
+// at, so it is published like a doctor override. Profiles are per weekday and
+// affect every date.
+type doctorRevenueProfileRepository struct {
+	interfaces.DoctorRevenueProfileRepository
+	publish publishFunc
+}
+
+func (r *doctorRevenueProfileRepository) Upsert(ctx context.Context, profile *models.DoctorRevenueProfile) error {
+	if err := r.DoctorRevenueProfileRepository.Upsert(ctx, profile); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.DoctorOverrideChanged})
+	return nil
+}
+
+func (r *doctorRevenueProfileRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := r.DoctorRevenueProfileRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.DoctorOverrideChanged})
+	return nil
+}
+
+type doctorRevenueOverrideRepository struct {
+	interfaces.DoctorRevenueOverrideRepository
+	publish publishFunc
+}
+
+func (r *doctorRevenueOverrideRepository) Upsert(ctx context.Context, override *models.DoctorRevenueOverride) error {
+	if err := r.DoctorRevenueOverrideRepository.Upsert(ctx, override); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.DoctorOverrideChanged, Date: override.Date})
+	return nil
+}
+
+func (r *doctorRevenueOverrideRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	override, err := r.DoctorRevenueOverrideRepository.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := r.DoctorRevenueOverrideRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	if override != nil {
+		r.publish(ctx, events.Event{Kind: events.DoctorOverrideChanged, Date: override.Date})
+	}
+	return nil
+}
+
+// Doctor leave takes the doctor off every date it covers, like an off override
+// on each. Substitutes are put on the roster by their own overrides.
+type doctorLeaveRepository struct {
+	interfaces.DoctorLeaveRepository
+	publish publishFunc
+}
+
+func leaveEvents(leaves ...*models.DoctorLeave) []events.Event {
+	seen := make(map[string]bool)
+	evs := []events.Event{}
+	for _, l := range leaves {
+		for date := l.StartDate; !date.After(l.EndDate); date = date.AddDate(0, 0, 1) {
+			if key := date.Format("2006-01-02"); !seen[key] {
+				seen[key] = true
+				evs = append(evs, events.Event{Kind: events.DoctorOverrideChanged, Date: date})
+			}
+		}
+	}
+	return evs
+}
+
+// Create publishes only approved leave; pending leave does not change the roster
+func (r *doctorLeaveRepository) Create(ctx context.Context, leave *models.DoctorLeave) error {
+	if err := r.DoctorLeaveRepository.Create(ctx, leave); err != nil {
+		return err
+	}
+	if leave.Status == models.DoctorLeaveApproved {
+		r.publish(ctx, leaveEvents(leave)...)
+	}
+	return nil
+}
+
+// Update publishes the dates the leave covered before as well as after
+func (r *doctorLeaveRepository) Update(ctx context.Context, leave *models.DoctorLeave) error {
+	previous, err := r.DoctorLeaveRepository.GetByID(ctx, leave.ID)
+	if err != nil {
+		return err
+	}
+	if err := r.DoctorLeaveRepository.Update(ctx, leave); err != nil {
+		return err
+	}
+	if previous != nil {
+		r.publish(ctx, leaveEvents(previous, leave)...)
+	} else {
+		r.publish(ctx, leaveEvents(leave)...)
+	}
+	return nil
+}
+
+func (r *doctorLeaveRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	leave, err := r.DoctorLeaveRepository.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := r.DoctorLeaveRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	if leave != nil && leave.Status == models.DoctorLeaveApproved {
+		r.publish(ctx, leaveEvents(leave)...)
+	}
+	return nil
+}
+
+// A doctor-on/off designation decides whether its branch needs staff that day
+type doctorOnOffDayRepository struct {
+	interfaces.DoctorOnOffDayRepository
+	publish publishFunc
+}
+
+func onOffDayEvent(day *models.DoctorOnOffDay) events.Event {
+	return events.Event{Kind: events.BranchDayChanged, BranchID: day.BranchID, Date: day.Date}
+}
+
+func (r *doctorOnOffDayRepository) Create(ctx context.Context, day *models.DoctorOnOffDay) error {
+	if err := r.DoctorOnOffDayRepository.Create(ctx, day); err != nil {
+		return err
+	}
+	r.publish(ctx, onOffDayEvent(day))
+	return nil
+}
+
+// Update publishes the stored branch and date, which the update cannot change
+func (r *doctorOnOffDayRepository) Update(ctx context.Context, day *models.DoctorOnOffDay) error {
+	existing, err := r.DoctorOnOffDayRepository.GetByID(ctx, day.ID)
+	if err != nil {
+		return err
+	}
+	if err := r.DoctorOnOffDayRepository.Update(ctx, day); err != nil {
+		return err
+	}
+	if existing != nil {
+		r.publish(ctx, onOffDayEvent(existing))
+	}
+	return nil
+}
+
+func (r *doctorOnOffDayRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	existing, err := r.DoctorOnOffDayRepository.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := r.DoctorOnOffDayRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	if existing != nil {
+		r.publish(ctx, onOffDayEvent(existing))
+	}
+	return nil
+}
+
+// A closure affects every date it covers, at its branch or, for a holiday,
+// at every branch
+type branchClosureRepository struct {
+	interfaces.BranchClosureRepository
+	publish publishFunc
+}
+
+func closureEvents(closures ...*models.BranchClosure) []events.Event {
+	seen := make(map[string]bool)
+	evs := []events.Event{}
+	for _, c := range closures {
+		branchID := uuid.Nil
+		if c.BranchID != nil {
+			branchID = *c.BranchID
+		}
+		for date := c.StartDate; !date.After(c.EndDate); date = date.AddDate(0, 0, 1) {
+			if key := branchID.String() + date.Format("2006-01-02"); !seen[key] {
+				seen[key] = true
+				evs = append(evs, events.Event{Kind: events.BranchDayChanged, BranchID: branchID, Date: date})
+			}
+		}
+	}
+	return evs
+}
+
+func (r *branchClosureRepository) Create(ctx context.Context, closure *models.BranchClosure) error {
+	if err := r.BranchClosureRepository.Create(ctx, closure); err != nil {
+		return err
+	}
+	r.publish(ctx, closureEvents(closure)...)
+	return nil
+}
+
+// Update publishes the branch and dates the closure covered before as well as after
+func (r *branchClosureRepository) Update(ctx context.Context, closure *models.BranchClosure) error {
+	previous, err := r.BranchClosureRepository.GetByID(ctx, closure.ID)
+	if err != nil {
+		return err
+	}
+	if err := r.BranchClosureRepository.Update(ctx, closure); err != nil {
+		return err
+	}
+	if previous != nil {
+		r.publish(ctx, closureEvents(previous, closure)...)
+	} else {
+		r.publish(ctx, closureEvents(closure)...)
+	}
+	return nil
+}
+
+func (r *branchClosureRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	closure, err := r.BranchClosureRepository.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := r.BranchClosureRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	if closure != nil {
+		r.publish(ctx, closureEvents(closure)...)
+	}
+	return nil
+}
+
+// Constraints are per weekday; they are evicted for every date of their branch
+type branchConstraintsRepository struct {
+	interfaces.BranchConstraintsRepository
+	publish publishFunc
+}
+
+func constraintEvents(constraints ...*models.BranchConstraints) []events.Event {
+	seen := make(map[uuid.UUID]bool)
+	evs := []events.Event{}
+	for _, c := range constraints {
+		if !seen[c.BranchID] {
+			seen[c.BranchID] = true
+			evs = append(evs, events.Event{Kind: events.ConstraintsChanged, BranchID: c.BranchID})
+		}
+	}
+	return evs
+}
+
+func (r *branchConstraintsRepository) Create(ctx context.Context, constraint *models.BranchConstraints) error {
+	if err := r.BranchConstraintsRepository.Create(ctx, constraint); err != nil {
+		return err
+	}
+	r.publish(ctx, constraintEvents(constraint)...)
+	return nil
+}
+
+func (r *branchConstraintsRepository) Update(ctx context.Context, constraint *models.BranchConstraints) error {
+	if err := r.BranchConstraintsRepository.Update(ctx, constraint); err != nil {
+		return err
+	}
+	r.publish(ctx, constraintEvents(constraint)...)
+	return nil
+}
+
+func (r *branchConstraintsRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	constraint, err := r.BranchConstraintsRepository.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := r.BranchConstraintsRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	if constraint != nil {
+		r.publish(ctx, constraintEvents(constraint)...)
+	}
+	return nil
+}
+
+func (r *branchConstraintsRepository) BulkUpsert(ctx context.Context, constraints []*models.BranchConstraints) error {
+	if err := r.BranchConstraintsRepository.BulkUpsert(ctx, constraints); err != nil {
+		return err
+	}
+	r.publish(ctx, constraintEvents(constraints...)...)
+	return nil
+}
+
+func (r *branchConstraintsRepository) BulkUpsertWithStaffGroups(ctx context.Context, constraints []*models.BranchConstraints) error {
+	if err := r.BranchConstraintsRepository.BulkUpsertWithStaffGroups(ctx, constraints); err != nil {
+		return err
+	}
+	r.publish(ctx, constraintEvents(constraints...)...)
+	return nil
+}
+
+// Branch type constraints are the fallback of every branch of that type
+type branchTypeConstraintsRepository struct {
+	interfaces.BranchTypeConstraintsRepository
+	publish publishFunc
+}
+
+func (r *branchTypeConstraintsRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := r.BranchTypeConstraintsRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.ConstraintsChanged})
+	return nil
+}
+
+func (r *branchTypeConstraintsRepository) BulkUpsertWithStaffGroups(ctx context.Context, constraints []*models.BranchTypeConstraints) error {
+	if err := r.BranchTypeConstraintsRepository.BulkUpsertWithStaffGroups(ctx, constraints); err != nil {
+		return err
+	}
+	r.publish(ctx, events.Event{Kind: events.ConstraintsChanged})
+	return nil
+}
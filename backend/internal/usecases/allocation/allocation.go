@@ -1,6 +1,7 @@
 package allocation
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/google/uuid"
@@ -16,20 +17,21 @@ func NewAllocationEngine(repos *RepositoriesWrapper) *AllocationEngine {
 
 // CalculateRequiredStaff calculates required staff count based on revenue and rules
 func (e *AllocationEngine) CalculateRequiredStaff(
+	ctx context.Context,
 	branchID uuid.UUID,
 	date string,
 	positionID uuid.UUID,
 	expectedRevenue float64,
 ) (int, error) {
 	// Get allocation rule for position
-	rule, err := e.repos.AllocationRule.GetByPositionID(positionID)
+	rule, err := e.repos.AllocationRule.GetByPositionID(ctx, positionID)
 	if err != nil {
 		return 0, err
 	}
 
 	if rule == nil {
 		// Use default minimum
-		position, err := e.repos.Position.GetByID(positionID)
+		position, err := e.repos.Position.GetByID(ctx, positionID)
 		if err != nil {
 			return 0, err
 		}
@@ -48,12 +50,13 @@ func (e *AllocationEngine) CalculateRequiredStaff(
 
 // CheckAvailability checks if rotation staff is available for assignment
 func (e *AllocationEngine) CheckAvailability(
+	ctx context.Context,
 	rotationStaffID uuid.UUID,
 	branchID uuid.UUID,
 	date string,
 ) (bool, error) {
 	// Check if rotation staff has effective branch assignment
-	effectiveBranches, err := e.repos.EffectiveBranch.GetByRotationStaffID(rotationStaffID)
+	effectiveBranches, err := e.repos.EffectiveBranch.GetByRotationStaffID(ctx, rotationStaffID)
 	if err != nil {
 		return false, err
 	}
@@ -1,6 +1,7 @@
 package allocation
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -31,15 +32,25 @@ type RepositoriesWrapper struct {
 	DoctorPreference            interfaces.DoctorPreferenceRepository
 	DoctorAssignment            interfaces.DoctorAssignmentRepository
 	DoctorOnOffDay              interfaces.DoctorOnOffDayRepository
+	DoctorDefaultSchedule       interfaces.DoctorDefaultScheduleRepository
+	DoctorWeeklyOffDay          interfaces.DoctorWeeklyOffDayRepository
+	DoctorScheduleOverride      interfaces.DoctorScheduleOverrideRepository
+	DoctorRevenueProfile        interfaces.DoctorRevenueProfileRepository
+	DoctorRevenueOverride       interfaces.DoctorRevenueOverrideRepository
+	DoctorLeave                 interfaces.DoctorLeaveRepository
+	BranchClosure               interfaces.BranchClosureRepository
 	BranchType                  interfaces.BranchTypeRepository
 	StaffGroup                  interfaces.StaffGroupRepository
 	StaffGroupPosition          interfaces.StaffGroupPositionRepository
 	BranchTypeRequirement       interfaces.BranchTypeStaffGroupRequirementRepository
 	BranchTypeConstraints       interfaces.BranchTypeConstraintsRepository
 	BranchConstraints           interfaces.BranchConstraintsRepository
+	RotationStaffSchedule       interfaces.RotationStaffScheduleRepository
+	SpecificPreference          interfaces.SpecificPreferenceRepository
 	RotationStaffBranchPosition interfaces.RotationStaffBranchPositionRepository
 	AllocationSuggestion        interfaces.AllocationSuggestionRepository
 	BranchQuotaSummary          interfaces.BranchQuotaSummaryRepository
+	UnitOfWork                  interfaces.UnitOfWork
 }
 
 // CriteriaEngine evaluates allocation criteria across the three pillars
@@ -79,10 +90,10 @@ type AllocationScore struct {
 }
 
 // EvaluateCriteria evaluates allocation criteria for a branch on a specific date
-func (e *CriteriaEngine) EvaluateCriteria(branchID uuid.UUID, date time.Time) (*AllocationScore, error) {
+func (e *CriteriaEngine) EvaluateCriteria(ctx context.Context, branchID uuid.UUID, date time.Time) (*AllocationScore, error) {
 	// Get all active criteria
 	filters := interfaces.AllocationCriteriaFilters{IsActive: &[]bool{true}[0]}
-	allCriteria, err := e.repos.AllocationCriteria.List(filters)
+	allCriteria, err := e.repos.AllocationCriteria.List(ctx, filters)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get criteria: %w", err)
 	}
@@ -103,18 +114,23 @@ func (e *CriteriaEngine) EvaluateCriteria(branchID uuid.UUID, date time.Time) (*
 		}
 	}
 
+	dc, err := LoadDayContext(ctx, e.repos, []uuid.UUID{branchID}, date, date)
+	if err != nil {
+		return nil, err
+	}
+
 	// Evaluate each pillar
-	clinicWideScore, err := e.evaluatePillar(models.PillarClinicWide, clinicWideCriteria, branchID, date)
+	clinicWideScore, err := e.evaluatePillar(ctx, dc, models.PillarClinicWide, clinicWideCriteria, branchID, date)
 	if err != nil {
 		return nil, fmt.Errorf("failed to evaluate clinic-wide criteria: %w", err)
 	}
 
-	doctorSpecificScore, err := e.evaluatePillar(models.PillarDoctorSpecific, doctorSpecificCriteria, branchID, date)
+	doctorSpecificScore, err := e.evaluatePillar(ctx, dc, models.PillarDoctorSpecific, doctorSpecificCriteria, branchID, date)
 	if err != nil {
 		return nil, fmt.Errorf("failed to evaluate doctor-specific criteria: %w", err)
 	}
 
-	branchSpecificScore, err := e.evaluatePillar(models.PillarBranchSpecific, branchSpecificCriteria, branchID, date)
+	branchSpecificScore, err := e.evaluatePillar(ctx, dc, models.PillarBranchSpecific, branchSpecificCriteria, branchID, date)
 	if err != nil {
 		return nil, fmt.Errorf("failed to evaluate branch-specific criteria: %w", err)
 	}
@@ -132,13 +148,13 @@ func (e *CriteriaEngine) EvaluateCriteria(branchID uuid.UUID, date time.Time) (*
 }
 
 // evaluatePillar evaluates criteria for a specific pillar
-func (e *CriteriaEngine) evaluatePillar(pillar models.CriteriaPillar, criteriaList []*models.AllocationCriteria, branchID uuid.UUID, date time.Time) (PillarScore, error) {
+func (e *CriteriaEngine) evaluatePillar(ctx context.Context, dc *DayContext, pillar models.CriteriaPillar, criteriaList []*models.AllocationCriteria, branchID uuid.UUID, date time.Time) (PillarScore, error) {
 	scores := []CriteriaScore{}
 	totalWeight := 0.0
 	weightedSum := 0.0
 
 	for _, criteria := range criteriaList {
-		score, err := e.evaluateCriterion(criteria, branchID, date)
+		score, err := e.evaluateCriterion(ctx, dc, criteria, branchID, date)
 		if err != nil {
 			return PillarScore{}, fmt.Errorf("failed to evaluate criterion %s: %w", criteria.ID, err)
 		}
@@ -172,18 +188,18 @@ func (e *CriteriaEngine) evaluatePillar(pillar models.CriteriaPillar, criteriaLi
 }
 
 // evaluateCriterion evaluates a single criterion
-func (e *CriteriaEngine) evaluateCriterion(criteria *models.AllocationCriteria, branchID uuid.UUID, date time.Time) (float64, error) {
+func (e *CriteriaEngine) evaluateCriterion(ctx context.Context, dc *DayContext, criteria *models.AllocationCriteria, branchID uuid.UUID, date time.Time) (float64, error) {
 	switch criteria.Type {
 	case models.CriteriaTypeBookings:
 		return e.evaluateBookings(criteria, branchID, date)
 	case models.CriteriaTypeRevenue:
-		return e.evaluateRevenue(criteria, branchID, date)
+		return e.evaluateRevenue(dc, criteria, branchID, date)
 	case models.CriteriaTypeMinStaffPosition:
-		return e.evaluateMinStaffPosition(criteria, branchID, date)
+		return e.evaluateMinStaffPosition(dc, criteria, branchID, date)
 	case models.CriteriaTypeMinStaffBranch:
-		return e.evaluateMinStaffBranch(criteria, branchID, date)
+		return e.evaluateMinStaffBranch(dc, criteria, branchID, date)
 	case models.CriteriaTypeDoctorCount:
-		return e.evaluateDoctorCount(criteria, branchID, date)
+		return e.evaluateDoctorCount(dc, criteria, branchID, date)
 	default:
 		return 0.0, fmt.Errorf("unknown criteria type: %s", criteria.Type)
 	}
@@ -197,45 +213,13 @@ func (e *CriteriaEngine) evaluateBookings(criteria *models.AllocationCriteria, b
 }
 
 // evaluateRevenue evaluates revenue criterion
-func (e *CriteriaEngine) evaluateRevenue(criteria *models.AllocationCriteria, branchID uuid.UUID, date time.Time) (float64, error) {
+func (e *CriteriaEngine) evaluateRevenue(dc *DayContext, criteria *models.AllocationCriteria, branchID uuid.UUID, date time.Time) (float64, error) {
 	var revenue float64
 
-	// Get revenue data for the branch on the date
-	revenueData, err := e.repos.Revenue.GetByBranchID(branchID, date, date)
-	if err != nil {
-		return 0.0, fmt.Errorf("failed to get revenue data: %w", err)
-	}
-
-	if len(revenueData) > 0 {
-		revenueRecord := revenueData[0]
-
-		// Check revenue source
-		if revenueRecord.RevenueSource == "doctor" {
-			// Calculate revenue from doctor assignments
-			doctorAssignments, err := e.repos.DoctorAssignment.GetDoctorsByBranchAndDate(branchID, date)
-			if err != nil {
-				return 0.0, fmt.Errorf("failed to get doctor assignments: %w", err)
-			}
-
-			revenue = 0.0
-			for _, assignment := range doctorAssignments {
-				revenue += assignment.ExpectedRevenue
-			}
-		} else {
-			// Use branch configuration revenue
-			revenue = revenueRecord.ExpectedRevenue
-		}
-	} else {
-		// No revenue data - try to calculate from doctors if any are assigned
-		doctorAssignments, err := e.repos.DoctorAssignment.GetDoctorsByBranchAndDate(branchID, date)
-		if err == nil && len(doctorAssignments) > 0 {
-			revenue = 0.0
-			for _, assignment := range doctorAssignments {
-				revenue += assignment.ExpectedRevenue
-			}
-		} else {
-			return 0.0, nil // No revenue data = 0 score
-		}
+	// Use branch configuration revenue, or the summed revenue of the doctors
+	// working there when the branch has none of its own
+	if revenueRecord := dc.Revenue(branchID, date); revenueRecord != nil {
+		revenue = revenueRecord.ExpectedRevenue
 	}
 
 	// Normalize revenue (0-1 scale)
@@ -249,14 +233,31 @@ func (e *CriteriaEngine) evaluateRevenue(criteria *models.AllocationCriteria, br
 	return revenue / maxRevenue, nil
 }
 
-// evaluateMinStaffPosition evaluates minimum staff per position criterion
-func (e *CriteriaEngine) evaluateMinStaffPosition(criteria *models.AllocationCriteria, branchID uuid.UUID, date time.Time) (float64, error) {
-	// Get position quota for the branch
-	quotas, err := e.repos.PositionQuota.GetByBranchID(branchID)
-	if err != nil {
-		return 0.0, fmt.Errorf("failed to get position quotas: %w", err)
+// workingStaffCount counts branch staff whose schedule on date is working, plus
+// rotation staff assigned to the branch, optionally restricted to one position
+func workingStaffCount(dc *DayContext, branchID uuid.UUID, positionID *uuid.UUID, date time.Time) int {
+	schedulesMap := dc.SchedulesOn(branchID, date)
+
+	count := 0
+	for _, staff := range dc.BranchStaff(branchID) {
+		if positionID != nil && staff.PositionID != *positionID {
+			continue
+		}
+		if schedules := schedulesMap[staff.ID]; len(schedules) > 0 && schedules[0].ScheduleStatus == models.ScheduleStatusWorking {
+			count++
+		}
 	}
 
+	if positionID == nil {
+		return count + len(dc.RotationsOn(branchID, date))
+	}
+	return count + dc.RotationCount(branchID, *positionID, date)
+}
+
+// evaluateMinStaffPosition evaluates minimum staff per position criterion
+func (e *CriteriaEngine) evaluateMinStaffPosition(dc *DayContext, criteria *models.AllocationCriteria, branchID uuid.UUID, date time.Time) (float64, error) {
+	// Get position quota for the branch
+	quotas := dc.Quotas(branchID)
 	if len(quotas) == 0 {
 		return 0.0, nil // No quotas = 0 score
 	}
@@ -272,34 +273,7 @@ func (e *CriteriaEngine) evaluateMinStaffPosition(criteria *models.AllocationCri
 
 		// Get actual staff count for this position on this date
 		// Count branch staff + rotation staff assigned
-		branchStaff, err := e.repos.Staff.GetByBranchID(branchID)
-		if err != nil {
-			continue
-		}
-
-		actualCount := 0
-		for _, staff := range branchStaff {
-			if staff.PositionID == quota.PositionID {
-				// Check if staff is working on this date
-				schedules, err := e.repos.Schedule.GetByStaffID(staff.ID, date, date)
-				if err == nil && len(schedules) > 0 {
-					if schedules[0].ScheduleStatus == models.ScheduleStatusWorking {
-						actualCount++
-					}
-				}
-			}
-		}
-
-		// Count rotation staff assigned
-		rotationAssignments, err := e.repos.Rotation.GetByBranchID(branchID, date, date)
-		if err == nil {
-			for _, assignment := range rotationAssignments {
-				staff, err := e.repos.Staff.GetByID(assignment.RotationStaffID)
-				if err == nil && staff != nil && staff.PositionID == quota.PositionID {
-					actualCount++
-				}
-			}
-		}
+		actualCount := workingStaffCount(dc, branchID, &quota.PositionID, date)
 
 		// Calculate fulfillment rate (0-1)
 		minRequired := quota.MinimumRequired
@@ -323,13 +297,9 @@ func (e *CriteriaEngine) evaluateMinStaffPosition(criteria *models.AllocationCri
 }
 
 // evaluateMinStaffBranch evaluates minimum staff per branch criterion
-func (e *CriteriaEngine) evaluateMinStaffBranch(criteria *models.AllocationCriteria, branchID uuid.UUID, date time.Time) (float64, error) {
+func (e *CriteriaEngine) evaluateMinStaffBranch(dc *DayContext, criteria *models.AllocationCriteria, branchID uuid.UUID, date time.Time) (float64, error) {
 	// Get all quotas for the branch
-	quotas, err := e.repos.PositionQuota.GetByBranchID(branchID)
-	if err != nil {
-		return 0.0, fmt.Errorf("failed to get position quotas: %w", err)
-	}
-
+	quotas := dc.Quotas(branchID)
 	if len(quotas) == 0 {
 		return 0.0, nil
 	}
@@ -347,26 +317,7 @@ func (e *CriteriaEngine) evaluateMinStaffBranch(criteria *models.AllocationCrite
 	}
 
 	// Get actual staff count (branch + rotation)
-	branchStaff, err := e.repos.Staff.GetByBranchID(branchID)
-	if err != nil {
-		return 0.0, fmt.Errorf("failed to get branch staff: %w", err)
-	}
-
-	actualCount := 0
-	for _, staff := range branchStaff {
-		schedules, err := e.repos.Schedule.GetByStaffID(staff.ID, date, date)
-		if err == nil && len(schedules) > 0 {
-			if schedules[0].ScheduleStatus == models.ScheduleStatusWorking {
-				actualCount++
-			}
-		}
-	}
-
-	// Count rotation staff
-	rotationAssignments, err := e.repos.Rotation.GetByBranchID(branchID, date, date)
-	if err == nil {
-		actualCount += len(rotationAssignments)
-	}
+	actualCount := workingStaffCount(dc, branchID, nil, date)
 
 	// Calculate fulfillment rate
 	fulfillment := float64(actualCount) / float64(totalMinRequired)
@@ -378,12 +329,12 @@ func (e *CriteriaEngine) evaluateMinStaffBranch(criteria *models.AllocationCrite
 }
 
 // evaluateDoctorCount evaluates doctor count criterion
-func (e *CriteriaEngine) evaluateDoctorCount(criteria *models.AllocationCriteria, branchID uuid.UUID, date time.Time) (float64, error) {
-	// Get doctor count for the branch on the date
-	doctorCount, err := e.repos.DoctorAssignment.GetDoctorCountByBranch(branchID, date)
-	if err != nil {
-		return 0.0, fmt.Errorf("failed to get doctor count: %w", err)
+func (e *CriteriaEngine) evaluateDoctorCount(dc *DayContext, criteria *models.AllocationCriteria, branchID uuid.UUID, date time.Time) (float64, error) {
+	// Closed and doctor-off days count no doctors, whatever the roster says
+	if !dc.Status(branchID, date).IsOperational() {
+		return 0.0, nil
 	}
+	doctorCount := dc.DoctorCount(branchID, date)
 
 	// Normalize doctor count (0-1 scale)
 	// Maximum doctors per branch per day is 6
@@ -395,14 +346,10 @@ func (e *CriteriaEngine) evaluateDoctorCount(criteria *models.AllocationCriteria
 }
 
 // evaluateDoctorSpecificStaff evaluates doctor-specific staff requirement criterion
-func (e *CriteriaEngine) evaluateDoctorSpecificStaff(criteria *models.AllocationCriteria, branchID uuid.UUID, date time.Time) (float64, error) {
+func (e *CriteriaEngine) evaluateDoctorSpecificStaff(ctx context.Context, dc *DayContext, criteria *models.AllocationCriteria, branchID uuid.UUID, date time.Time) (float64, error) {
 	// Get doctors assigned to this branch on this date
-	doctorAssignments, err := e.repos.DoctorAssignment.GetDoctorsByBranchAndDate(branchID, date)
-	if err != nil {
-		return 0.0, fmt.Errorf("failed to get doctor assignments: %w", err)
-	}
-
-	if len(doctorAssignments) == 0 {
+	doctors := dc.DoctorsOn(branchID, date)
+	if len(doctors) == 0 {
 		return 0.0, nil // No doctors = no doctor-specific requirements
 	}
 
@@ -410,9 +357,9 @@ func (e *CriteriaEngine) evaluateDoctorSpecificStaff(criteria *models.Allocation
 	totalFulfillment := 0.0
 	requirementCount := 0
 
-	for _, assignment := range doctorAssignments {
+	for _, doctor := range doctors {
 		// Get doctor preferences (branch-specific or global)
-		preferences, err := e.repos.DoctorPreference.GetActiveByDoctorID(assignment.DoctorID)
+		preferences, err := e.repos.DoctorPreference.GetActiveByDoctorID(ctx, doctor.ID)
 		if err != nil {
 			continue
 		}
@@ -459,32 +406,8 @@ func (e *CriteriaEngine) evaluateDoctorSpecificStaff(criteria *models.Allocation
 					minCount = float64(minCountInt)
 				}
 
-				// Count actual staff for this position
-				actualCount := 0
-				branchStaff, err := e.repos.Staff.GetByBranchID(branchID)
-				if err == nil {
-					for _, staff := range branchStaff {
-						if staff.PositionID == positionID {
-							schedules, err := e.repos.Schedule.GetByStaffID(staff.ID, date, date)
-							if err == nil && len(schedules) > 0 {
-								if schedules[0].ScheduleStatus == models.ScheduleStatusWorking {
-									actualCount++
-								}
-							}
-						}
-					}
-				}
-
-				// Count rotation staff
-				rotationAssignments, err := e.repos.Rotation.GetByBranchID(branchID, date, date)
-				if err == nil {
-					for _, rotAssignment := range rotationAssignments {
-						staff, err := e.repos.Staff.GetByID(rotAssignment.RotationStaffID)
-						if err == nil && staff != nil && staff.PositionID == positionID {
-							actualCount++
-						}
-					}
-				}
+				// Count actual staff for this position (branch + rotation)
+				actualCount := workingStaffCount(dc, branchID, &positionID, date)
 
 				// Calculate fulfillment rate
 				if minCount > 0 {
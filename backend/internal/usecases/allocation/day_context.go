@@ -0,0 +1,597 @@
+package allocation
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+	"vsq-oper-manpower/backend/internal/usecases/doctor"
+)
+
+// DayContext holds everything the allocation calculators read for a set of branches
+// over a date range. It is loaded up front by LoadDayContext with a fixed number of
+// set-based queries, so the cost of a day overview no longer grows with the number
+// of branches, positions or staff.
+type DayContext struct {
+	StartDate time.Time
+	EndDate   time.Time
+	BranchIDs []uuid.UUID
+
+	branches    map[uuid.UUID]*models.Branch
+	positions   []*models.Position
+	positionMap map[uuid.UUID]*models.Position
+	staffGroups map[uuid.UUID]*models.StaffGroup
+
+	quotas      map[uuid.UUID][]*models.PositionQuota // branchID -> quotas (ordered by position)
+	branchStaff map[uuid.UUID][]*models.Staff         // branchID -> staff, including staff_branches links
+	schedules   map[uuid.UUID]map[string][]*models.StaffSchedule
+	rotations   map[uuid.UUID]map[string][]*models.RotationAssignment
+	staffByID   map[uuid.UUID]*models.Staff // rotation staff referenced by assignments
+
+	doctors       map[uuid.UUID]*models.Doctor
+	doctorsByDate map[uuid.UUID]map[string][]uuid.UUID            // branchID -> date -> doctorIDs
+	doctorRevenue map[uuid.UUID]map[string]*models.DoctorRevenue  // branchID -> date -> sum of the doctors' revenue
+	designations  map[uuid.UUID]map[string]*models.DoctorOnOffDay // branchID -> date -> doctor-on/off designation
+	closures      []*models.BranchClosure
+
+	branchConstraints map[uuid.UUID]map[int]*models.BranchConstraints     // branchID -> day of week
+	typeConstraints   map[uuid.UUID]map[int]*models.BranchTypeConstraints // branchTypeID -> day of week
+	groupPositions    map[uuid.UUID][]uuid.UUID                           // staffGroupID -> positionIDs
+	positionGroups    map[uuid.UUID][]uuid.UUID                           // positionID -> staffGroupIDs
+	typeRequirements  map[uuid.UUID][]*models.BranchTypeStaffGroupRequirement
+
+	summaries map[uuid.UUID]map[string]*models.BranchQuotaSummary
+	dirty     map[uuid.UUID]map[string]bool // summaries queued for recomputation
+	revenue   map[uuid.UUID]map[string]*models.RevenueData
+}
+
+// dateKey is the map key used for per-day lookups, independent of time zone and clock
+func dateKey(date time.Time) string {
+	return date.Format("2006-01-02")
+}
+
+// LoadDayContext loads the allocation inputs for branchIDs between startDate and endDate
+func LoadDayContext(ctx context.Context, repos *RepositoriesWrapper, branchIDs []uuid.UUID, startDate, endDate time.Time) (*DayContext, error) {
+	dc := &DayContext{
+		StartDate:         startDate,
+		EndDate:           endDate,
+		BranchIDs:         branchIDs,
+		branches:          make(map[uuid.UUID]*models.Branch),
+		positionMap:       make(map[uuid.UUID]*models.Position),
+		staffGroups:       make(map[uuid.UUID]*models.StaffGroup),
+		quotas:            make(map[uuid.UUID][]*models.PositionQuota),
+		schedules:         make(map[uuid.UUID]map[string][]*models.StaffSchedule),
+		rotations:         make(map[uuid.UUID]map[string][]*models.RotationAssignment),
+		staffByID:         make(map[uuid.UUID]*models.Staff),
+		doctors:           make(map[uuid.UUID]*models.Doctor),
+		doctorsByDate:     make(map[uuid.UUID]map[string][]uuid.UUID),
+		doctorRevenue:     make(map[uuid.UUID]map[string]*models.DoctorRevenue),
+		designations:      make(map[uuid.UUID]map[string]*models.DoctorOnOffDay),
+		branchConstraints: make(map[uuid.UUID]map[int]*models.BranchConstraints),
+		typeConstraints:   make(map[uuid.UUID]map[int]*models.BranchTypeConstraints),
+		groupPositions:    make(map[uuid.UUID][]uuid.UUID),
+		positionGroups:    make(map[uuid.UUID][]uuid.UUID),
+		typeRequirements:  make(map[uuid.UUID][]*models.BranchTypeStaffGroupRequirement),
+		summaries:         make(map[uuid.UUID]map[string]*models.BranchQuotaSummary),
+		dirty:             make(map[uuid.UUID]map[string]bool),
+		revenue:           make(map[uuid.UUID]map[string]*models.RevenueData),
+	}
+	wanted := make(map[uuid.UUID]bool, len(branchIDs))
+	for _, id := range branchIDs {
+		wanted[id] = true
+	}
+
+	branches, err := repos.Branch.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branches: %w", err)
+	}
+	for _, branch := range branches {
+		if wanted[branch.ID] {
+			dc.branches[branch.ID] = branch
+		}
+	}
+
+	if dc.positions, err = repos.Position.List(ctx); err != nil {
+		return nil, fmt.Errorf("failed to get positions: %w", err)
+	}
+	for _, pos := range dc.positions {
+		dc.positionMap[pos.ID] = pos
+	}
+
+	quotas, err := repos.PositionQuota.List(ctx, interfaces.PositionQuotaFilters{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get position quotas: %w", err)
+	}
+	for _, quota := range quotas {
+		if wanted[quota.BranchID] {
+			dc.quotas[quota.BranchID] = append(dc.quotas[quota.BranchID], quota)
+		}
+	}
+
+	if err := dc.loadStaff(ctx, repos, startDate, endDate); err != nil {
+		return nil, err
+	}
+	if err := dc.loadDoctors(ctx, repos, startDate, endDate); err != nil {
+		return nil, err
+	}
+	if err := dc.loadBranchDays(ctx, repos, startDate, endDate); err != nil {
+		return nil, err
+	}
+	if err := dc.loadConstraints(ctx, repos); err != nil {
+		return nil, err
+	}
+
+	if repos.BranchQuotaSummary != nil {
+		summaries, err := repos.BranchQuotaSummary.GetByBranchIDsAndDateRange(ctx, branchIDs, startDate, endDate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get quota summaries: %w", err)
+		}
+		for _, summary := range summaries {
+			if dc.summaries[summary.BranchID] == nil {
+				dc.summaries[summary.BranchID] = make(map[string]*models.BranchQuotaSummary)
+			}
+			dc.summaries[summary.BranchID][dateKey(summary.Date)] = summary
+		}
+
+		dirty, err := repos.BranchQuotaSummary.GetDirtyByBranchIDsAndDateRange(ctx, branchIDs, startDate, endDate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get dirty quota summaries: %w", err)
+		}
+		for _, key := range dirty {
+			if dc.dirty[key.BranchID] == nil {
+				dc.dirty[key.BranchID] = make(map[string]bool)
+			}
+			dc.dirty[key.BranchID][dateKey(key.Date)] = true
+		}
+	}
+
+	revenues, err := repos.Revenue.GetByBranchIDsAndDateRange(ctx, branchIDs, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revenue: %w", err)
+	}
+	for _, rd := range revenues {
+		if dc.revenue[rd.BranchID] == nil {
+			dc.revenue[rd.BranchID] = make(map[string]*models.RevenueData)
+		}
+		if _, seen := dc.revenue[rd.BranchID][dateKey(rd.Date)]; !seen {
+			dc.revenue[rd.BranchID][dateKey(rd.Date)] = rd
+		}
+	}
+
+	return dc, nil
+}
+
+// LoadBranchDayStatuses resolves the status of branchIDs on each day from
+// startDate to endDate, ordered by date and then as branchIDs. Only the doctor
+// roster, designations and closures are loaded.
+func LoadBranchDayStatuses(ctx context.Context, repos *RepositoriesWrapper, branchIDs []uuid.UUID, startDate, endDate time.Time) ([]*models.BranchDayStatus, error) {
+	dc, err := loadRosterContext(ctx, repos, branchIDs, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []*models.BranchDayStatus{}
+	for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 1) {
+		for _, branchID := range branchIDs {
+			if dc.branches[branchID] != nil {
+				statuses = append(statuses, dc.Status(branchID, date))
+			}
+		}
+	}
+	return statuses, nil
+}
+
+// loadRosterContext loads a day context holding only the branches, the doctor
+// roster, designations and closures, enough for DoctorsOn and Status
+func loadRosterContext(ctx context.Context, repos *RepositoriesWrapper, branchIDs []uuid.UUID, startDate, endDate time.Time) (*DayContext, error) {
+	dc := &DayContext{
+		StartDate:     startDate,
+		EndDate:       endDate,
+		BranchIDs:     branchIDs,
+		branches:      make(map[uuid.UUID]*models.Branch),
+		doctors:       make(map[uuid.UUID]*models.Doctor),
+		doctorsByDate: make(map[uuid.UUID]map[string][]uuid.UUID),
+		doctorRevenue: make(map[uuid.UUID]map[string]*models.DoctorRevenue),
+		designations:  make(map[uuid.UUID]map[string]*models.DoctorOnOffDay),
+	}
+	branches, err := repos.Branch.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branches: %w", err)
+	}
+	for _, branch := range branches {
+		if slices.Contains(branchIDs, branch.ID) {
+			dc.branches[branch.ID] = branch
+		}
+	}
+	if err := dc.loadDoctors(ctx, repos, startDate, endDate); err != nil {
+		return nil, err
+	}
+	if err := dc.loadBranchDays(ctx, repos, startDate, endDate); err != nil {
+		return nil, err
+	}
+	return dc, nil
+}
+
+// loadStaff loads branch staff with their schedules, plus rotation assignments and the rotation staff they reference
+func (dc *DayContext) loadStaff(ctx context.Context, repos *RepositoriesWrapper, startDate, endDate time.Time) error {
+	var err error
+	if dc.branchStaff, err = repos.Staff.GetByBranchIDs(ctx, dc.BranchIDs); err != nil {
+		return fmt.Errorf("failed to get branch staff: %w", err)
+	}
+
+	seen := make(map[uuid.UUID]bool)
+	staffIDs := []uuid.UUID{}
+	for _, staffList := range dc.branchStaff {
+		for _, staff := range staffList {
+			if !seen[staff.ID] {
+				seen[staff.ID] = true
+				staffIDs = append(staffIDs, staff.ID)
+			}
+		}
+	}
+	schedulesMap, err := repos.Schedule.GetByStaffIDs(ctx, staffIDs, startDate, endDate)
+	if err != nil {
+		return fmt.Errorf("failed to get schedules: %w", err)
+	}
+	for staffID, schedules := range schedulesMap {
+		byDate := make(map[string][]*models.StaffSchedule)
+		for _, schedule := range schedules {
+			byDate[dateKey(schedule.Date)] = append(byDate[dateKey(schedule.Date)], schedule)
+		}
+		dc.schedules[staffID] = byDate
+	}
+
+	assignments, err := repos.Rotation.GetAssignments(ctx, interfaces.RotationFilters{StartDate: &startDate, EndDate: &endDate})
+	if err != nil {
+		return fmt.Errorf("failed to get rotation assignments: %w", err)
+	}
+	rotationStaffIDs := []uuid.UUID{}
+	seen = make(map[uuid.UUID]bool)
+	for _, assignment := range assignments {
+		if _, ok := dc.branches[assignment.BranchID]; !ok {
+			continue
+		}
+		if dc.rotations[assignment.BranchID] == nil {
+			dc.rotations[assignment.BranchID] = make(map[string][]*models.RotationAssignment)
+		}
+		key := dateKey(assignment.Date)
+		dc.rotations[assignment.BranchID][key] = append(dc.rotations[assignment.BranchID][key], assignment)
+		if !seen[assignment.RotationStaffID] {
+			seen[assignment.RotationStaffID] = true
+			rotationStaffIDs = append(rotationStaffIDs, assignment.RotationStaffID)
+		}
+	}
+	rotationStaff, err := repos.Staff.GetByIDs(ctx, rotationStaffIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get rotation staff: %w", err)
+	}
+	for _, staff := range rotationStaff {
+		dc.staffByID[staff.ID] = staff
+	}
+	return nil
+}
+
+// loadDoctors resolves which doctors work at each branch on each day of the range
+func (dc *DayContext) loadDoctors(ctx context.Context, repos *RepositoriesWrapper, startDate, endDate time.Time) error {
+	if repos.Doctor == nil {
+		return nil
+	}
+	doctors, err := repos.Doctor.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get doctors: %w", err)
+	}
+	for _, d := range doctors {
+		dc.doctors[d.ID] = d
+	}
+
+	// The assignment repository serves the materialized roster with stable IDs;
+	// without it, calculate from the schedule repositories
+	if repos.DoctorAssignment != nil {
+		assignments, err := repos.DoctorAssignment.GetByDateRange(ctx, startDate, endDate)
+		if err != nil {
+			return fmt.Errorf("failed to get doctor assignments: %w", err)
+		}
+		for _, assignment := range assignments {
+			dc.addDoctor(assignment.BranchID, assignment.Date, assignment.DoctorID, assignment.Revenue)
+		}
+		return nil
+	}
+	if repos.DoctorDefaultSchedule == nil || repos.DoctorWeeklyOffDay == nil || repos.DoctorScheduleOverride == nil {
+		return nil
+	}
+
+	calculator := doctor.NewDoctorScheduleCalculator(
+		repos.DoctorDefaultSchedule,
+		repos.DoctorWeeklyOffDay,
+		repos.DoctorScheduleOverride,
+		repos.Doctor,
+	).WithRevenue(repos.DoctorRevenueProfile, repos.DoctorRevenueOverride).
+		WithLeaves(repos.DoctorLeave)
+	assignments, err := calculator.CalculateDetailedAssignmentsForDateRange(ctx, startDate, endDate)
+	if err != nil {
+		return fmt.Errorf("failed to calculate doctor assignments: %w", err)
+	}
+	for _, assignment := range assignments {
+		dc.addDoctor(assignment.BranchID, assignment.Date, assignment.DoctorID, assignment.Revenue)
+	}
+	return nil
+}
+
+func (dc *DayContext) addDoctor(branchID uuid.UUID, date time.Time, doctorID uuid.UUID, revenue *models.DoctorRevenue) {
+	if _, ok := dc.branches[branchID]; !ok {
+		return
+	}
+	if dc.doctorsByDate[branchID] == nil {
+		dc.doctorsByDate[branchID] = make(map[string][]uuid.UUID)
+	}
+	key := dateKey(date)
+	// A doctor working both halves of the day at the branch is listed once
+	if !slices.Contains(dc.doctorsByDate[branchID][key], doctorID) {
+		dc.doctorsByDate[branchID][key] = append(dc.doctorsByDate[branchID][key], doctorID)
+	}
+
+	if revenue == nil {
+		return
+	}
+	if dc.doctorRevenue[branchID] == nil {
+		dc.doctorRevenue[branchID] = make(map[string]*models.DoctorRevenue)
+	}
+	sum := *revenue
+	if existing := dc.doctorRevenue[branchID][key]; existing != nil {
+		sum = existing.Add(*revenue)
+	}
+	dc.doctorRevenue[branchID][key] = &sum
+}
+
+// loadBranchDays loads the doctor-on/off designations and closures that, with
+// the doctor roster, resolve each branch day's status
+func (dc *DayContext) loadBranchDays(ctx context.Context, repos *RepositoriesWrapper, startDate, endDate time.Time) error {
+	if repos.DoctorOnOffDay != nil {
+		days, err := repos.DoctorOnOffDay.GetByDateRange(ctx, startDate, endDate)
+		if err != nil {
+			return fmt.Errorf("failed to get doctor on/off days: %w", err)
+		}
+		for _, d := range days {
+			if _, ok := dc.branches[d.BranchID]; !ok {
+				continue
+			}
+			if dc.designations[d.BranchID] == nil {
+				dc.designations[d.BranchID] = make(map[string]*models.DoctorOnOffDay)
+			}
+			dc.designations[d.BranchID][dateKey(d.Date)] = d
+		}
+	}
+	if repos.BranchClosure != nil {
+		closures, err := repos.BranchClosure.List(ctx, interfaces.BranchClosureFilters{StartDate: &startDate, EndDate: &endDate})
+		if err != nil {
+			return fmt.Errorf("failed to get branch closures: %w", err)
+		}
+		dc.closures = closures
+	}
+	return nil
+}
+
+// loadConstraints loads branch and branch type daily constraints with their staff group requirements
+func (dc *DayContext) loadConstraints(ctx context.Context, repos *RepositoriesWrapper) error {
+	constraints, err := repos.BranchConstraints.GetByBranchIDs(ctx, dc.BranchIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get branch constraints: %w", err)
+	}
+	if err := repos.BranchConstraints.LoadStaffGroupRequirements(ctx, constraints); err != nil {
+		return fmt.Errorf("failed to load branch constraint staff groups: %w", err)
+	}
+	for _, c := range constraints {
+		if dc.branchConstraints[c.BranchID] == nil {
+			dc.branchConstraints[c.BranchID] = make(map[int]*models.BranchConstraints)
+		}
+		dc.branchConstraints[c.BranchID][c.DayOfWeek] = c
+	}
+
+	typeConstraints, err := repos.BranchTypeConstraints.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get branch type constraints: %w", err)
+	}
+	if err := repos.BranchTypeConstraints.LoadStaffGroupRequirements(ctx, typeConstraints); err != nil {
+		return fmt.Errorf("failed to load branch type constraint staff groups: %w", err)
+	}
+	for _, c := range typeConstraints {
+		if dc.typeConstraints[c.BranchTypeID] == nil {
+			dc.typeConstraints[c.BranchTypeID] = make(map[int]*models.BranchTypeConstraints)
+		}
+		if _, exists := dc.typeConstraints[c.BranchTypeID][c.DayOfWeek]; !exists {
+			dc.typeConstraints[c.BranchTypeID][c.DayOfWeek] = c
+		}
+	}
+
+	staffGroups, err := repos.StaffGroup.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get staff groups: %w", err)
+	}
+	for _, sg := range staffGroups {
+		dc.staffGroups[sg.ID] = sg
+	}
+
+	groupPositions, err := repos.StaffGroupPosition.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get staff group positions: %w", err)
+	}
+	for _, sgp := range groupPositions {
+		dc.groupPositions[sgp.StaffGroupID] = append(dc.groupPositions[sgp.StaffGroupID], sgp.PositionID)
+		dc.positionGroups[sgp.PositionID] = append(dc.positionGroups[sgp.PositionID], sgp.StaffGroupID)
+	}
+
+	requirements, err := repos.BranchTypeRequirement.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get branch type requirements: %w", err)
+	}
+	for _, req := range requirements {
+		dc.typeRequirements[req.BranchTypeID] = append(dc.typeRequirements[req.BranchTypeID], req)
+	}
+	return nil
+}
+
+// Branch returns the branch, or nil if it was not requested or does not exist
+func (dc *DayContext) Branch(branchID uuid.UUID) *models.Branch {
+	return dc.branches[branchID]
+}
+
+// Positions returns all positions
+func (dc *DayContext) Positions() []*models.Position {
+	return dc.positions
+}
+
+// PositionMap returns all positions keyed by ID
+func (dc *DayContext) PositionMap() map[uuid.UUID]*models.Position {
+	return dc.positionMap
+}
+
+// StaffGroup returns the staff group with the given ID, if any
+func (dc *DayContext) StaffGroup(staffGroupID uuid.UUID) *models.StaffGroup {
+	return dc.staffGroups[staffGroupID]
+}
+
+// Quotas returns the branch's position quotas
+func (dc *DayContext) Quotas(branchID uuid.UUID) []*models.PositionQuota {
+	return dc.quotas[branchID]
+}
+
+// BranchStaff returns the branch's own staff
+func (dc *DayContext) BranchStaff(branchID uuid.UUID) []*models.Staff {
+	return dc.branchStaff[branchID]
+}
+
+// SchedulesOn returns each branch staff member's schedules for date, in the shape of Schedule.GetByStaffIDs
+func (dc *DayContext) SchedulesOn(branchID uuid.UUID, date time.Time) map[uuid.UUID][]*models.StaffSchedule {
+	key := dateKey(date)
+	result := make(map[uuid.UUID][]*models.StaffSchedule)
+	for _, staff := range dc.branchStaff[branchID] {
+		if schedules := dc.schedules[staff.ID][key]; len(schedules) > 0 {
+			result[staff.ID] = schedules
+		}
+	}
+	return result
+}
+
+// RotationsOn returns the rotation assignments to the branch on date
+func (dc *DayContext) RotationsOn(branchID uuid.UUID, date time.Time) []*models.RotationAssignment {
+	return dc.rotations[branchID][dateKey(date)]
+}
+
+// RotationCount counts the rotation staff assigned to the branch on date for a position
+func (dc *DayContext) RotationCount(branchID, positionID uuid.UUID, date time.Time) int {
+	count := 0
+	for _, assignment := range dc.RotationsOn(branchID, date) {
+		if staff := dc.staffByID[assignment.RotationStaffID]; staff != nil && staff.PositionID == positionID {
+			count++
+		}
+	}
+	return count
+}
+
+// DoctorsOn returns the doctors working at the branch on date
+func (dc *DayContext) DoctorsOn(branchID uuid.UUID, date time.Time) []*models.Doctor {
+	doctorIDs := dc.doctorsByDate[branchID][dateKey(date)]
+	doctors := make([]*models.Doctor, 0, len(doctorIDs))
+	for _, id := range doctorIDs {
+		if d := dc.doctors[id]; d != nil {
+			doctors = append(doctors, d)
+		}
+	}
+	return doctors
+}
+
+// DoctorCount returns the number of doctors working at the branch on date
+func (dc *DayContext) DoctorCount(branchID uuid.UUID, date time.Time) int {
+	return len(dc.doctorsByDate[branchID][dateKey(date)])
+}
+
+// Status resolves whether the branch is closed, or open with or without
+// doctors, on date. Allocation only staffs operational (doctor-on) days.
+func (dc *DayContext) Status(branchID uuid.UUID, date time.Time) *models.BranchDayStatus {
+	var closure *models.BranchClosure
+	for _, c := range dc.closures {
+		// A branch's own closure explains more than a holiday does
+		if c.Closes(branchID, date) && (closure == nil || closure.IsHoliday()) {
+			closure = c
+		}
+	}
+	status := models.ResolveBranchDayStatus(branchID, date, dc.DoctorCount(branchID, date),
+		dc.designations[branchID][dateKey(date)], closure)
+	if branch := dc.branches[branchID]; branch != nil {
+		status.BranchCode = branch.Code
+	}
+	return status
+}
+
+// Summary returns the pre-computed quota summary for the branch on date, if any.
+// Summaries queued for recomputation are stale and are not returned.
+func (dc *DayContext) Summary(branchID uuid.UUID, date time.Time) *models.BranchQuotaSummary {
+	if dc.IsDirty(branchID, date) {
+		return nil
+	}
+	return dc.summaries[branchID][dateKey(date)]
+}
+
+// IsDirty reports whether the summary of the branch on date is queued for recomputation
+func (dc *DayContext) IsDirty(branchID uuid.UUID, date time.Time) bool {
+	return dc.dirty[branchID][dateKey(date)]
+}
+
+// Revenue returns the branch's revenue data for date, if any. Without entered
+// branch revenue, or when it is itself doctor-sourced, the expected revenue of
+// the doctors working there that day is used.
+func (dc *DayContext) Revenue(branchID uuid.UUID, date time.Time) *models.RevenueData {
+	rd := dc.revenue[branchID][dateKey(date)]
+	if rd != nil && rd.RevenueSource != "doctor" {
+		return rd
+	}
+	sum := dc.doctorRevenue[branchID][dateKey(date)]
+	if sum == nil {
+		return rd
+	}
+	return models.DoctorRevenueData(branchID, date, *sum)
+}
+
+// StaffGroupPositions returns the positions that belong to a staff group
+func (dc *DayContext) StaffGroupPositions(staffGroupID uuid.UUID) []uuid.UUID {
+	return dc.groupPositions[staffGroupID]
+}
+
+// PositionStaffGroups returns the staff groups a position belongs to
+func (dc *DayContext) PositionStaffGroups(positionID uuid.UUID) []uuid.UUID {
+	return dc.positionGroups[positionID]
+}
+
+// BranchTypeRequirements returns the staff group requirements of a branch type
+func (dc *DayContext) BranchTypeRequirements(branchTypeID uuid.UUID) []*models.BranchTypeStaffGroupRequirement {
+	return dc.typeRequirements[branchTypeID]
+}
+
+// StaffGroupRequirements returns the daily staff group minimums for the branch on date:
+// the branch's own constraint for that weekday, falling back to its branch type's
+func (dc *DayContext) StaffGroupRequirements(branchID uuid.UUID, date time.Time) []*models.BranchConstraintStaffGroup {
+	dayOfWeek := int(date.Weekday())
+
+	var requirements []*models.BranchConstraintStaffGroup
+	if constraint := dc.branchConstraints[branchID][dayOfWeek]; constraint != nil {
+		requirements = constraint.StaffGroupRequirements
+	}
+
+	branch := dc.branches[branchID]
+	if len(requirements) == 0 && branch != nil && branch.BranchTypeID != nil {
+		if typeConstraint := dc.typeConstraints[*branch.BranchTypeID][dayOfWeek]; typeConstraint != nil {
+			requirements = make([]*models.BranchConstraintStaffGroup, 0, len(typeConstraint.StaffGroupRequirements))
+			for _, btReq := range typeConstraint.StaffGroupRequirements {
+				requirements = append(requirements, &models.BranchConstraintStaffGroup{
+					StaffGroupID: btReq.StaffGroupID,
+					MinimumCount: btReq.MinimumCount,
+				})
+			}
+		}
+	}
+	return requirements
+}
@@ -1,19 +1,22 @@
 package allocation
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"sort"
 	"time"
 
 	"vsq-oper-manpower/backend/internal/domain/models"
+	"vsq-oper-manpower/backend/pkg/metrics"
 
 	"github.com/google/uuid"
 )
 
 // MultiCriteriaFilter implements the 5-criteria-group filtering system for rotation staff allocation
 type MultiCriteriaFilter struct {
-	repos *RepositoriesWrapper
+	repos    *RepositoriesWrapper
+	duration *metrics.HistogramVec // Time spent in GenerateRankedSuggestions; nil disables
 }
 
 // NewMultiCriteriaFilter creates a new multi-criteria filter
@@ -21,6 +24,13 @@ func NewMultiCriteriaFilter(repos *RepositoriesWrapper) *MultiCriteriaFilter {
 	return &MultiCriteriaFilter{repos: repos}
 }
 
+// WithDurationMetric records how long each GenerateRankedSuggestions call
+// takes in duration, which must have no labels
+func (f *MultiCriteriaFilter) WithDurationMetric(duration *metrics.HistogramVec) *MultiCriteriaFilter {
+	f.duration = duration
+	return f
+}
+
 // CriteriaPriorityOrder represents the priority order of criteria (strict lexicographic ordering)
 // Criteria are evaluated in order: PriorityOrder[0] is highest priority, PriorityOrder[len-1] is lowest
 type CriteriaPriorityOrder struct {
@@ -123,11 +133,19 @@ type StaffGroupScore struct {
 // GenerateRankedSuggestions generates ranked suggestions for rotation staff allocation
 // based on strict priority ordering (lexicographic sorting)
 func (f *MultiCriteriaFilter) GenerateRankedSuggestions(
+	ctx context.Context,
 	branchIDs []uuid.UUID,
 	date time.Time,
 	priorityOrder CriteriaPriorityOrder,
 	enableDoctorPreferences bool,
 ) ([]*AllocationSuggestion, error) {
+	defer f.duration.ObserveSince(time.Now())
+
+	// Load everything the criteria read for these branches in one batch
+	dc, err := LoadDayContext(ctx, f.repos, branchIDs, date, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load day context: %w", err)
+	}
 
 	// Step 1: Apply zeroth criteria (doctor preferences) - optional filter
 	var filteredBranchIDs []uuid.UUID
@@ -141,8 +159,7 @@ func (f *MultiCriteriaFilter) GenerateRankedSuggestions(
 			}
 		}
 		if hasZeroth {
-			var err error
-			filteredBranchIDs, err = f.applyZerothCriteria(branchIDs, date)
+			filteredBranchIDs, err = f.applyZerothCriteria(ctx, dc, branchIDs, date)
 			if err != nil {
 				return nil, fmt.Errorf("failed to apply zeroth criteria: %w", err)
 			}
@@ -157,63 +174,34 @@ func (f *MultiCriteriaFilter) GenerateRankedSuggestions(
 	suggestions := []*AllocationSuggestion{}
 
 	for _, branchID := range filteredBranchIDs {
-		// Get branch info
-		branch, err := f.repos.Branch.GetByID(branchID)
-		if err != nil {
-			continue
-		}
-		if branch == nil {
-			continue
-		}
-
-		// Check if branch is operational (has at least one doctor)
-		doctorCount, err := f.repos.DoctorAssignment.GetDoctorCountByBranch(branchID, date)
-		if err != nil {
-			continue
-		}
-		if doctorCount == 0 {
-			continue // Skip closed branches
+		// Stop explicitly once the request is cancelled
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
 
-		// Get position quotas for this branch
-		quotas, err := f.repos.PositionQuota.GetByBranchID(branchID)
-		if err != nil {
+		// Get branch info
+		branch := dc.Branch(branchID)
+		if branch == nil {
 			continue
 		}
 
-		// Get all positions (only branch-type positions can have quotas)
-		positions, err := f.repos.Position.List()
-		if err != nil {
-			continue
+		// Check if branch is operational (a doctor-on day that is not closed)
+		if !dc.Status(branchID, date).IsOperational() {
+			continue // Skip closed and doctor-off branches
 		}
 
-	// Calculate Group 1 and Group 2 scores once per branch (they apply to all positions)
-	group1Score, group1Breakdown, err := f.calculateGroup1Score(branchID, date)
-	if err != nil {
-		group1Score = 0
-		group1Breakdown = []StaffGroupScore{}
-	}
-
-	group2Score, group2Breakdown, err := f.calculateGroup2Score(branchID, date)
-	if err != nil {
-		group2Score = 0
-		group2Breakdown = []PositionQuotaScore{}
-	}
+		// Calculate Group 1 and Group 2 scores once per branch (they apply to all positions)
+		group1Score, group1Breakdown := f.calculateGroup1Score(dc, branchID, date)
+		group2Score, group2Breakdown := f.calculateGroup2Score(dc, branchID, date)
 
 		// Evaluate for each position that has a quota
-		for _, quota := range quotas {
+		for _, quota := range dc.Quotas(branchID) {
 			if !quota.IsActive {
 				continue
 			}
 
 			// Find position details
-			var position *models.Position
-			for _, p := range positions {
-				if p.ID == quota.PositionID {
-					position = p
-					break
-				}
-			}
+			position := dc.PositionMap()[quota.PositionID]
 			if position == nil {
 				continue
 			}
@@ -224,10 +212,7 @@ func (f *MultiCriteriaFilter) GenerateRankedSuggestions(
 			}
 
 			// Calculate current staff count for this position
-			currentStaffCount, err := f.calculateCurrentStaffCount(branchID, quota.PositionID, date)
-			if err != nil {
-				continue
-			}
+			currentStaffCount := f.calculateCurrentStaffCount(dc, branchID, quota.PositionID, date)
 
 			// Calculate shortage
 			preferredShortage := quota.DesignatedQuota - currentStaffCount
@@ -258,11 +243,11 @@ func (f *MultiCriteriaFilter) GenerateRankedSuggestions(
 
 			// Legacy criteria evaluation (kept for backward compatibility)
 			criteriaBreakdown := CriteriaBreakdown{}
-			firstScore, _ := f.evaluateFirstCriteria(branchID, date)
+			firstScore := f.evaluateFirstCriteria(dc, branchID, date)
 			criteriaBreakdown.FirstCriteriaScore = firstScore
 			criteriaBreakdown.SecondCriteriaScore = f.evaluateSecondCriteria(preferredShortage, quota.DesignatedQuota)
 			criteriaBreakdown.ThirdCriteriaScore = f.evaluateThirdCriteria(minimumShortage, quota.MinimumRequired)
-			fourthScore, _ := f.evaluateFourthCriteria(branchID, quota.PositionID, date)
+			fourthScore := f.evaluateFourthCriteria(dc, branchID, quota.PositionID, date)
 			criteriaBreakdown.FourthCriteriaScore = fourthScore
 
 			var zerothScore float64
@@ -275,7 +260,7 @@ func (f *MultiCriteriaFilter) GenerateRankedSuggestions(
 					}
 				}
 				if hasZeroth {
-					zerothScore, _ = f.evaluateZerothCriteria(branchID, quota.PositionID, date)
+					zerothScore, _ = f.evaluateZerothCriteria(ctx, dc, branchID, quota.PositionID, date)
 					criteriaBreakdown.ZerothCriteriaScore = zerothScore
 				}
 			}
@@ -349,42 +334,67 @@ func (f *MultiCriteriaFilter) GenerateRankedSuggestions(
 	return suggestions, nil
 }
 
+// DoctorPreferenceCheck is the outcome of one doctor's preferences at a branch on a date
+type DoctorPreferenceCheck struct {
+	BranchID   uuid.UUID                      `json:"branch_id"`
+	BranchCode string                         `json:"branch_code,omitempty"`
+	DoctorID   uuid.UUID                      `json:"doctor_id"`
+	DoctorName string                         `json:"doctor_name,omitempty"`
+	Met        bool                           `json:"met"`
+	Failures   []models.PreferenceRuleFailure `json:"failures"`
+}
+
+// CheckDoctorPreferences evaluates the preferences of every doctor rostered at
+// the branches on date and reports exactly which rules fail
+func (f *MultiCriteriaFilter) CheckDoctorPreferences(ctx context.Context, branchIDs []uuid.UUID, date time.Time) ([]*DoctorPreferenceCheck, error) {
+	dc, err := LoadDayContext(ctx, f.repos, branchIDs, date, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load day context: %w", err)
+	}
+
+	checks := []*DoctorPreferenceCheck{}
+	for _, branchID := range branchIDs {
+		for _, doctor := range dc.DoctorsOn(branchID, date) {
+			failures, err := f.checkDoctorPreferenceMet(ctx, dc, doctor.ID, branchID, date)
+			if err != nil {
+				return nil, err
+			}
+			check := &DoctorPreferenceCheck{
+				BranchID:   branchID,
+				DoctorID:   doctor.ID,
+				DoctorName: doctor.Name,
+				Met:        len(failures) == 0,
+				Failures:   failures,
+			}
+			if branch := dc.Branch(branchID); branch != nil {
+				check.BranchCode = branch.Code
+			}
+			checks = append(checks, check)
+		}
+	}
+	return checks, nil
+}
+
 // applyZerothCriteria filters branches based on doctor preferences
 // Returns only branches that meet doctor preference requirements
-func (f *MultiCriteriaFilter) applyZerothCriteria(branchIDs []uuid.UUID, date time.Time) ([]uuid.UUID, error) {
-	dayOfWeek := int(date.Weekday())
+func (f *MultiCriteriaFilter) applyZerothCriteria(ctx context.Context, dc *DayContext, branchIDs []uuid.UUID, date time.Time) ([]uuid.UUID, error) {
 	filteredBranches := []uuid.UUID{}
 
 	for _, branchID := range branchIDs {
-		// Get doctors assigned to this branch on this date
-		doctorAssignments, err := f.repos.DoctorAssignment.GetDoctorsByBranchAndDate(branchID, date)
-		if err != nil {
-			continue
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
 
 		meetsRequirements := true
 
-		// Check each doctor's preferences
-		for _, assignment := range doctorAssignments {
-			// Get rotation staff requirement preferences for this doctor
-			preferences, err := f.repos.DoctorPreference.GetRotationStaffRequirements(
-				assignment.DoctorID,
-				&branchID,
-				&dayOfWeek,
-			)
+		// Check the preferences of each doctor assigned to this branch on this date
+		for _, doctor := range dc.DoctorsOn(branchID, date) {
+			failures, err := f.checkDoctorPreferenceMet(ctx, dc, doctor.ID, branchID, date)
 			if err != nil {
-				continue
-			}
-
-			// Check if preferences are met
-			for _, preference := range preferences {
-				if !f.checkDoctorPreferenceMet(preference, branchID, date) {
-					meetsRequirements = false
-					break
-				}
+				return nil, err
 			}
-
-			if !meetsRequirements {
+			if len(failures) > 0 {
+				meetsRequirements = false
 				break
 			}
 		}
@@ -397,101 +407,97 @@ func (f *MultiCriteriaFilter) applyZerothCriteria(branchIDs []uuid.UUID, date ti
 	return filteredBranches, nil
 }
 
-// checkDoctorPreferenceMet checks if a doctor preference requirement is met
+// doctorRules returns the doctor's active preferences of registered rule kinds
+// that apply at the branch, global or branch-specific
+func (f *MultiCriteriaFilter) doctorRules(ctx context.Context, doctorID, branchID uuid.UUID) ([]*models.DoctorPreference, error) {
+	preferences, err := f.repos.DoctorPreference.GetActiveByDoctorID(ctx, doctorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get doctor preferences: %w", err)
+	}
+	rules := []*models.DoctorPreference{}
+	for _, preference := range preferences {
+		if preference.BranchID != nil && *preference.BranchID != branchID {
+			continue
+		}
+		if !models.IsPreferenceRuleKind(preference.RuleType) {
+			continue // Legacy rule types are scored by the criteria engine
+		}
+		rules = append(rules, preference)
+	}
+	return rules, nil
+}
+
+// checkDoctorPreferenceMet evaluates a doctor's preference rules at the branch
+// on date and returns each requirement that is not met. A rule whose config
+// no longer decodes fails rather than being skipped.
 func (f *MultiCriteriaFilter) checkDoctorPreferenceMet(
-	preference *models.DoctorPreference,
+	ctx context.Context,
+	dc *DayContext,
+	doctorID uuid.UUID,
 	branchID uuid.UUID,
 	date time.Time,
-) bool {
-	// Check position requirements
-	if positionReqs, ok := preference.RuleConfig["position_requirements"].([]interface{}); ok {
-		for _, req := range positionReqs {
-			if reqMap, ok := req.(map[string]interface{}); ok {
-				positionIDStr, ok := reqMap["position_id"].(string)
-				if !ok {
-					continue
-				}
-				positionID, err := uuid.Parse(positionIDStr)
-				if err != nil {
-					continue
-				}
-				minCount, ok := reqMap["min_count"].(float64)
-				if !ok {
-					continue
-				}
-
-				// Check current staff count
-				currentCount, err := f.calculateCurrentStaffCount(branchID, positionID, date)
-				if err != nil {
-					return false
-				}
+) ([]models.PreferenceRuleFailure, error) {
+	preferences, err := f.doctorRules(ctx, doctorID, branchID)
+	if err != nil {
+		return nil, err
+	}
 
-				if currentCount < int(minCount) {
-					return false // Requirement not met
-				}
-			}
+	staffing := &dayStaffing{filter: f, dc: dc, branchID: branchID, date: date}
+	failures := []models.PreferenceRuleFailure{}
+	for _, preference := range preferences {
+		ruleFailures, err := preference.Evaluate(staffing, date)
+		if err != nil {
+			failures = append(failures, models.PreferenceRuleFailure{
+				PreferenceID: preference.ID,
+				DoctorID:     preference.DoctorID,
+				RuleType:     preference.RuleType,
+				Message:      err.Error(),
+			})
+			continue
 		}
+		failures = append(failures, ruleFailures...)
 	}
+	return failures, nil
+}
 
-	// Check specific staff requirements for this date
-	if specificReqs, ok := preference.RuleConfig["specific_staff_requirements"].([]interface{}); ok {
-		dateStr := date.Format("2006-01-02")
-		for _, req := range specificReqs {
-			if reqMap, ok := req.(map[string]interface{}); ok {
-				reqDate, ok := reqMap["date"].(string)
-				if !ok || reqDate != dateStr {
-					continue
-				}
-
-				// Check if specific staff is assigned
-				staffIDStr, ok := reqMap["staff_id"].(string)
-				if !ok {
-					continue
-				}
-				staffID, err := uuid.Parse(staffIDStr)
-				if err != nil {
-					continue
-				}
-
-				// Check if this staff is assigned to the branch on this date
-				assignments, err := f.repos.Rotation.GetByBranchID(branchID, date, date)
-				if err != nil {
-					return false
-				}
+// dayStaffing reads a branch's staffing on a date from the day context for
+// doctor preference rules
+type dayStaffing struct {
+	filter   *MultiCriteriaFilter
+	dc       *DayContext
+	branchID uuid.UUID
+	date     time.Time
+}
 
-				found := false
-				for _, assignment := range assignments {
-					if assignment.RotationStaffID == staffID {
-						found = true
-						break
-					}
-				}
+func (s *dayStaffing) PositionCount(positionID uuid.UUID) int {
+	return s.filter.calculateCurrentStaffCount(s.dc, s.branchID, positionID, s.date)
+}
 
-				if !found {
-					return false // Specific staff requirement not met
-				}
-			}
+func (s *dayStaffing) IsWorking(staffID uuid.UUID) bool {
+	for _, schedule := range s.dc.SchedulesOn(s.branchID, s.date)[staffID] {
+		if schedule.ScheduleStatus == models.ScheduleStatusWorking {
+			return true
 		}
 	}
-
-	return true
+	for _, assignment := range s.dc.RotationsOn(s.branchID, s.date) {
+		if assignment.RotationStaffID == staffID {
+			return true
+		}
+	}
+	return false
 }
 
 // evaluateZerothCriteria evaluates doctor preferences criteria
 func (f *MultiCriteriaFilter) evaluateZerothCriteria(
+	ctx context.Context,
+	dc *DayContext,
 	branchID uuid.UUID,
 	positionID uuid.UUID,
 	date time.Time,
 ) (float64, error) {
-	dayOfWeek := int(date.Weekday())
-
 	// Get doctors assigned to this branch on this date
-	doctorAssignments, err := f.repos.DoctorAssignment.GetDoctorsByBranchAndDate(branchID, date)
-	if err != nil {
-		return 0.0, err
-	}
-
-	if len(doctorAssignments) == 0 {
+	doctors := dc.DoctorsOn(branchID, date)
+	if len(doctors) == 0 {
 		return 0.0, nil // No doctors, no preference score
 	}
 
@@ -499,54 +505,44 @@ func (f *MultiCriteriaFilter) evaluateZerothCriteria(
 	count := 0
 
 	// Check each doctor's preferences
-	for _, assignment := range doctorAssignments {
-		preferences, err := f.repos.DoctorPreference.GetRotationStaffRequirements(
-			assignment.DoctorID,
-			&branchID,
-			&dayOfWeek,
-		)
+	for _, doctor := range doctors {
+		preferences, err := f.doctorRules(ctx, doctor.ID, branchID)
 		if err != nil {
-			continue
+			return 0.0, err
 		}
 
 		for _, preference := range preferences {
-			// Check if this position is required by doctor preference
-			if positionReqs, ok := preference.RuleConfig["position_requirements"].([]interface{}); ok {
-				for _, req := range positionReqs {
-					if reqMap, ok := req.(map[string]interface{}); ok {
-						reqPositionIDStr, ok := reqMap["position_id"].(string)
-						if !ok {
-							continue
-						}
-						reqPositionID, err := uuid.Parse(reqPositionIDStr)
-						if err != nil {
-							continue
-						}
-
-						if reqPositionID == positionID {
-							// This position is required by doctor preference
-							// Score based on how well it's met
-							minCount, ok := reqMap["min_count"].(float64)
-							if !ok {
-								continue
-							}
-
-							currentCount, err := f.calculateCurrentStaffCount(branchID, positionID, date)
-							if err != nil {
-								continue
-							}
-
-							// Score: 1.0 if requirement met, decreasing if not met
-							if currentCount >= int(minCount) {
-								totalScore += 1.0
-							} else {
-								// Partial score based on fulfillment ratio
-								totalScore += float64(currentCount) / minCount
-							}
-							count++
-						}
+			rule, err := preference.Rule()
+			if err != nil || !rule.AppliesOn(date) {
+				continue
+			}
+
+			// Collect the minimums this rule sets for the position
+			var minCounts []int
+			switch r := rule.(type) {
+			case *models.RotationStaffRequirementRuleConfig:
+				for _, req := range r.PositionRequirements {
+					if req.PositionID == positionID {
+						minCounts = append(minCounts, req.MinCount)
 					}
 				}
+			case *models.MinPositionCountRuleConfig:
+				if r.PositionID == positionID {
+					minCounts = append(minCounts, r.MinCount)
+				}
+			}
+
+			for _, minCount := range minCounts {
+				currentCount := f.calculateCurrentStaffCount(dc, branchID, positionID, date)
+
+				// Score: 1.0 if requirement met, decreasing if not met
+				if currentCount >= minCount {
+					totalScore += 1.0
+				} else {
+					// Partial score based on fulfillment ratio
+					totalScore += float64(currentCount) / float64(minCount)
+				}
+				count++
 			}
 		}
 	}
@@ -559,29 +555,19 @@ func (f *MultiCriteriaFilter) evaluateZerothCriteria(
 }
 
 // evaluateFirstCriteria evaluates branch-level variables (universal across branches)
-func (f *MultiCriteriaFilter) evaluateFirstCriteria(branchID uuid.UUID, date time.Time) (float64, error) {
-	// Get revenue data for this branch and date
-	revenueData, err := f.repos.Revenue.GetByBranchID(branchID, date, date)
-	if err != nil {
-		return 0.0, err
-	}
-
+func (f *MultiCriteriaFilter) evaluateFirstCriteria(dc *DayContext, branchID uuid.UUID, date time.Time) float64 {
 	var skinRevenue, laserYagRevenue float64
 	var vitaminCases, slimPenCases int
 
-	if len(revenueData) > 0 {
-		rd := revenueData[0]
+	// Revenue data for this branch and date
+	if rd := dc.Revenue(branchID, date); rd != nil {
 		skinRevenue = rd.SkinRevenue
 		laserYagRevenue = rd.LSHMRevenue
 		vitaminCases = rd.VitaminCases
 		slimPenCases = rd.SlimPenCases
 	}
 
-	// Get doctor count
-	doctorCount, err := f.repos.DoctorAssignment.GetDoctorCountByBranch(branchID, date)
-	if err != nil {
-		return 0.0, err
-	}
+	doctorCount := dc.DoctorCount(branchID, date)
 
 	// Normalize each variable to 0-1 scale
 	// We need to get max values for normalization - for now, use reasonable defaults
@@ -600,7 +586,7 @@ func (f *MultiCriteriaFilter) evaluateFirstCriteria(branchID uuid.UUID, date tim
 	// Combine scores with equal weights
 	combinedScore := (skinScore + laserScore + vitaminScore + slimPenScore + doctorScore) / 5.0
 
-	return combinedScore, nil
+	return combinedScore
 }
 
 // evaluateSecondCriteria evaluates preferred staff shortage
@@ -639,46 +625,33 @@ func (f *MultiCriteriaFilter) evaluateThirdCriteria(shortage int, minimum int) f
 
 // evaluateFourthCriteria evaluates branch type staff group requirements
 func (f *MultiCriteriaFilter) evaluateFourthCriteria(
+	dc *DayContext,
 	branchID uuid.UUID,
 	positionID uuid.UUID,
 	date time.Time,
-) (float64, error) {
-	// Get branch
-	branch, err := f.repos.Branch.GetByID(branchID)
-	if err != nil {
-		return 0.0, err
-	}
+) float64 {
+	branch := dc.Branch(branchID)
 	if branch == nil || branch.BranchTypeID == nil {
-		return 0.5, nil // Neutral score if no branch type assigned
+		return 0.5 // Neutral score if no branch type assigned
 	}
 
 	// Get branch type requirements
-	requirements, err := f.repos.BranchTypeRequirement.GetByBranchTypeID(*branch.BranchTypeID)
-	if err != nil {
-		return 0.5, nil
-	}
-
-	// Find which staff group this position belongs to
-	staffGroupPositions, err := f.repos.StaffGroupPosition.GetByPositionID(positionID)
-	if err != nil {
-		return 0.5, nil
-	}
+	requirements := dc.BranchTypeRequirements(*branch.BranchTypeID)
 
-	if len(staffGroupPositions) == 0 {
-		return 0.5, nil // Position not in any staff group
+	// Find which staff groups this position belongs to
+	staffGroupIDs := dc.PositionStaffGroups(positionID)
+	if len(staffGroupIDs) == 0 {
+		return 0.5 // Position not in any staff group
 	}
 
 	// Check each staff group this position belongs to
 	maxScore := 0.0
-	for _, sgp := range staffGroupPositions {
+	for _, staffGroupID := range staffGroupIDs {
 		// Find requirement for this staff group
 		for _, req := range requirements {
-			if req.StaffGroupID == sgp.StaffGroupID && req.IsActive {
+			if req.StaffGroupID == staffGroupID && req.IsActive {
 				// Calculate current staff count for this staff group
-				currentCount, err := f.calculateStaffGroupCount(branchID, sgp.StaffGroupID, date)
-				if err != nil {
-					continue
-				}
+				currentCount := f.calculateStaffGroupCount(dc, branchID, staffGroupID, date)
 
 				// Score based on shortage
 				if currentCount < req.MinimumStaffCount {
@@ -698,98 +671,24 @@ func (f *MultiCriteriaFilter) evaluateFourthCriteria(
 	}
 
 	if maxScore == 0.0 {
-		return 0.5, nil // Neutral score if requirements are met
+		return 0.5 // Neutral score if requirements are met
 	}
 
-	return maxScore, nil
+	return maxScore
 }
 
 // calculateGroup1Score calculates Group 1 score: Daily Staff Constraints - Minimum Shortage (negative points)
 func (f *MultiCriteriaFilter) calculateGroup1Score(
+	dc *DayContext,
 	branchID uuid.UUID,
 	date time.Time,
-) (int, []StaffGroupScore, error) {
-	dayOfWeek := int(date.Weekday())
-
-	// Get branch to find branch type
-	branch, err := f.repos.Branch.GetByID(branchID)
-	if err != nil || branch == nil {
-		return 0, nil, err
-	}
-
-	// Get branch constraints for this day
-	constraint, err := f.repos.BranchConstraints.GetByBranchIDAndDayOfWeek(branchID, dayOfWeek)
-	if err != nil {
-		return 0, nil, err
-	}
-
-	var staffGroupRequirements []*models.BranchConstraintStaffGroup
-
-	// If branch-specific constraint exists, use it
-	if constraint != nil {
-		// Load staff group requirements from branch constraint
-		if err := f.repos.BranchConstraints.LoadStaffGroupRequirements([]*models.BranchConstraints{constraint}); err != nil {
-			return 0, nil, err
-		}
-		staffGroupRequirements = constraint.StaffGroupRequirements
-	}
-	
-	// If no branch-specific constraints or they're empty, fallback to branch type constraints
-	if len(staffGroupRequirements) == 0 && branch.BranchTypeID != nil {
-		branchTypeConstraints, err := f.repos.BranchTypeConstraints.GetByBranchTypeID(*branch.BranchTypeID)
-		if err != nil {
-			return 0, nil, err
-		}
-
-		// Find constraint for this day
-		var branchTypeConstraint *models.BranchTypeConstraints
-		for _, bt := range branchTypeConstraints {
-			if bt.DayOfWeek == dayOfWeek {
-				branchTypeConstraint = bt
-				break
-			}
-		}
-
-		if branchTypeConstraint != nil {
-			// Load staff group requirements from branch type constraint
-			if err := f.repos.BranchTypeConstraints.LoadStaffGroupRequirements([]*models.BranchTypeConstraints{branchTypeConstraint}); err != nil {
-				return 0, nil, err
-			}
-
-			// Convert BranchTypeConstraintStaffGroup to BranchConstraintStaffGroup
-			staffGroupRequirements = make([]*models.BranchConstraintStaffGroup, 0, len(branchTypeConstraint.StaffGroupRequirements))
-			for _, btReq := range branchTypeConstraint.StaffGroupRequirements {
-				staffGroupRequirements = append(staffGroupRequirements, &models.BranchConstraintStaffGroup{
-					StaffGroupID:  btReq.StaffGroupID,
-					MinimumCount:  btReq.MinimumCount,
-				})
-			}
-		}
-	}
-
-	// If no constraints found (neither branch-specific nor branch type), return 0
-	if len(staffGroupRequirements) == 0 {
-		return 0, nil, nil
-	}
-
-	// Get all staff groups for name lookup
-	staffGroups, err := f.repos.StaffGroup.List()
-	if err != nil {
-		return 0, nil, err
-	}
-	staffGroupMap := make(map[uuid.UUID]*models.StaffGroup)
-	for _, sg := range staffGroups {
-		staffGroupMap[sg.ID] = sg
-	}
-
+) (int, []StaffGroupScore) {
 	totalScore := 0
 	breakdown := []StaffGroupScore{}
 
-	for _, req := range staffGroupRequirements {
-		actualCount, err := f.calculateStaffGroupCount(branchID, req.StaffGroupID, date)
-		if err != nil {
-			continue
-		}
+	// Branch constraints for this day, falling back to branch type constraints
+	for _, req := range dc.StaffGroupRequirements(branchID, date) {
+		actualCount := f.calculateStaffGroupCount(dc, branchID, req.StaffGroupID, date)
 
 		shortage := req.MinimumCount - actualCount
 		if shortage > 0 {
@@ -797,7 +696,7 @@ func (f *MultiCriteriaFilter) calculateGroup1Score(
 			totalScore += points
 
 			staffGroupName := ""
-			if sg, ok := staffGroupMap[req.StaffGroupID]; ok {
+			if sg := dc.StaffGroup(req.StaffGroupID); sg != nil {
 				staffGroupName = sg.Name
 			}
 
@@ -812,41 +711,28 @@ func (f *MultiCriteriaFilter) calculateGroup1Score(
 		}
 	}
 
-	return totalScore, breakdown, nil
+	return totalScore, breakdown
 }
 
 // calculateGroup2Score calculates Group 2 score: Position Quota - Minimum Shortage (negative points)
 func (f *MultiCriteriaFilter) calculateGroup2Score(
+	dc *DayContext,
 	branchID uuid.UUID,
 	date time.Time,
-) (int, []PositionQuotaScore, error) {
-	quotas, err := f.repos.PositionQuota.GetByBranchID(branchID)
-	if err != nil {
-		return 0, nil, err
-	}
+) (int, []PositionQuotaScore) {
 
 	totalScore := 0
 	breakdown := []PositionQuotaScore{}
 
-	// Get all positions for name lookup
-	positions, err := f.repos.Position.List()
-	if err != nil {
-		return 0, nil, err
-	}
-	positionMap := make(map[uuid.UUID]*models.Position)
-	for _, p := range positions {
-		positionMap[p.ID] = p
-	}
+	// Positions for name lookup
+	positionMap := dc.PositionMap()
 
-	for _, quota := range quotas {
+	for _, quota := range dc.Quotas(branchID) {
 		if !quota.IsActive {
 			continue
 		}
 
-		currentCount, err := f.calculateCurrentStaffCount(branchID, quota.PositionID, date)
-		if err != nil {
-			continue
-		}
+		currentCount := f.calculateCurrentStaffCount(dc, branchID, quota.PositionID, date)
 
 		shortage := quota.MinimumRequired - currentCount
 		if shortage > 0 {
@@ -869,42 +755,29 @@ func (f *MultiCriteriaFilter) calculateGroup2Score(
 		}
 	}
 
-	return totalScore, breakdown, nil
+	return totalScore, breakdown
 }
 
 // calculateGroup3Score calculates Group 3 score: Position Quota - Preferred Excess (positive points)
 // Tracks positions with actual staff number greater than preferred number (informational only)
 func (f *MultiCriteriaFilter) calculateGroup3Score(
+	dc *DayContext,
 	branchID uuid.UUID,
 	date time.Time,
-) (int, []PositionQuotaScore, error) {
-	quotas, err := f.repos.PositionQuota.GetByBranchID(branchID)
-	if err != nil {
-		return 0, nil, err
-	}
+) (int, []PositionQuotaScore) {
 
-	// Get all positions for name lookup
-	positions, err := f.repos.Position.List()
-	if err != nil {
-		return 0, nil, err
-	}
-	positionMap := make(map[uuid.UUID]*models.Position)
-	for _, p := range positions {
-		positionMap[p.ID] = p
-	}
+	// Positions for name lookup
+	positionMap := dc.PositionMap()
 
 	totalScore := 0
 	breakdown := []PositionQuotaScore{}
 
-	for _, quota := range quotas {
+	for _, quota := range dc.Quotas(branchID) {
 		if !quota.IsActive {
 			continue
 		}
 
-		currentCount, err := f.calculateCurrentStaffCount(branchID, quota.PositionID, date)
-		if err != nil {
-			continue
-		}
+		currentCount := f.calculateCurrentStaffCount(dc, branchID, quota.PositionID, date)
 
 		// Only count positions with actual staff number greater than preferred number
 		excess := currentCount - quota.DesignatedQuota
@@ -928,30 +801,23 @@ func (f *MultiCriteriaFilter) calculateGroup3Score(
 		}
 	}
 
-	return totalScore, breakdown, nil
+	return totalScore, breakdown
 }
 
 // calculateCurrentStaffCount calculates current staff count (branch + rotation) for a position
 func (f *MultiCriteriaFilter) calculateCurrentStaffCount(
+	dc *DayContext,
 	branchID uuid.UUID,
 	positionID uuid.UUID,
 	date time.Time,
-) (int, error) {
-	// Get branch staff for this position
-	branchStaff, err := f.repos.Staff.GetByBranchID(branchID)
-	if err != nil {
-		return 0, err
-	}
+) int {
+	schedulesMap := dc.SchedulesOn(branchID, date)
 
 	branchCount := 0
-	for _, staff := range branchStaff {
+	for _, staff := range dc.BranchStaff(branchID) {
 		if staff.PositionID == positionID {
 			// Check if staff is working on this date
-			schedules, err := f.repos.Schedule.GetByStaffID(staff.ID, date, date)
-			if err != nil {
-				continue
-			}
-			for _, schedule := range schedules {
+			for _, schedule := range schedulesMap[staff.ID] {
 				if schedule.ScheduleStatus == models.ScheduleStatusWorking {
 					branchCount++
 					break
@@ -960,49 +826,22 @@ func (f *MultiCriteriaFilter) calculateCurrentStaffCount(
 		}
 	}
 
-	// Get rotation assignments for this position
-	rotationAssignments, err := f.repos.Rotation.GetByBranchID(branchID, date, date)
-	if err != nil {
-		return branchCount, nil
-	}
-
-	rotationCount := 0
-	for _, assignment := range rotationAssignments {
-		// Get rotation staff
-		staff, err := f.repos.Staff.GetByID(assignment.RotationStaffID)
-		if err != nil {
-			continue
-		}
-		if staff != nil && staff.PositionID == positionID {
-			rotationCount++
-		}
-	}
-
-	return branchCount + rotationCount, nil
+	return branchCount + dc.RotationCount(branchID, positionID, date)
 }
 
 // calculateStaffGroupCount calculates current staff count for a staff group
 func (f *MultiCriteriaFilter) calculateStaffGroupCount(
+	dc *DayContext,
 	branchID uuid.UUID,
 	staffGroupID uuid.UUID,
 	date time.Time,
-) (int, error) {
-	// Get all positions in this staff group
-	positions, err := f.repos.StaffGroupPosition.GetByStaffGroupID(staffGroupID)
-	if err != nil {
-		return 0, err
-	}
-
+) int {
 	totalCount := 0
-	for _, sgp := range positions {
-		count, err := f.calculateCurrentStaffCount(branchID, sgp.PositionID, date)
-		if err != nil {
-			continue
-		}
-		totalCount += count
+	for _, positionID := range dc.StaffGroupPositions(staffGroupID) {
+		totalCount += f.calculateCurrentStaffCount(dc, branchID, positionID, date)
 	}
 
-	return totalCount, nil
+	return totalCount
 }
 
 // generateReason generates a human-readable reason for the suggestion
@@ -1,25 +1,28 @@
 package allocation
 
 import (
+	"context"
 	"fmt"
-	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"vsq-oper-manpower/backend/internal/domain/events"
+	"vsq-oper-manpower/backend/internal/domain/models"
+	"vsq-oper-manpower/backend/pkg/lru"
 )
 
-// cacheEntry represents a cached overview entry
-type cacheEntry struct {
-	data      *DayOverview
-	expiresAt time.Time
-}
+// Default overview cache bounds, see WithCache
+const (
+	defaultOverviewCacheSize = 128
+	defaultOverviewCacheTTL  = 5 * time.Minute
+)
 
 // OverviewGenerator generates overview data for Area Managers
 type OverviewGenerator struct {
-	repos            *RepositoriesWrapper
+	repos           *RepositoriesWrapper
 	quotaCalculator *QuotaCalculator
-	cache            sync.Map // map[string]*cacheEntry, key format: "date:branchIDs"
-	cacheTTL         time.Duration
+	pairingResolver *PairingResolver
+	cache           *lru.Cache[string, *DayOverview] // key format: "overview:date:branchIDs"
 }
 
 // NewOverviewGenerator creates a new overview generator
@@ -27,8 +30,42 @@ func NewOverviewGenerator(repos *RepositoriesWrapper, quotaCalculator *QuotaCalc
 	return &OverviewGenerator{
 		repos:           repos,
 		quotaCalculator: quotaCalculator,
-		cacheTTL:        5 * time.Minute, // Cache for 5 minutes
+		pairingResolver: NewPairingResolver(repos),
+		cache:           lru.New[string, *DayOverview](defaultOverviewCacheSize, defaultOverviewCacheTTL),
+	}
+}
+
+// WithCache replaces the day overview cache with one holding at most size overviews for ttl.
+// The TTL is only a safety net: HandleEvent evicts overviews as soon as their data changes.
+func (g *OverviewGenerator) WithCache(size int, ttl time.Duration) *OverviewGenerator {
+	g.cache = lru.New[string, *DayOverview](size, ttl)
+	return g
+}
+
+// HandleEvent evicts the cached day overviews that include the changed branch and date
+func (g *OverviewGenerator) HandleEvent(ctx context.Context, e events.Event) {
+	if e.Kind == events.QuotaStatusChanged {
+		return
 	}
+	g.cache.RemoveFunc(func(_ string, overview *DayOverview) bool {
+		if !e.AffectsDate(overview.Date) {
+			return false
+		}
+		if e.BranchID == uuid.Nil {
+			return true
+		}
+		for _, status := range overview.BranchStatuses {
+			if e.Affects(status.BranchID, overview.Date) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// CacheStats returns the day overview cache counters
+func (g *OverviewGenerator) CacheStats() lru.Stats {
+	return g.cache.Stats()
 }
 
 // generateCacheKey generates a cache key from date and branch IDs
@@ -51,6 +88,10 @@ type DayOverview struct {
 	BranchStatuses  []*BranchQuotaStatus `json:"branch_statuses"`
 	TotalBranches   int       `json:"total_branches"`
 	BranchesWithShortage int  `json:"branches_with_shortage"`
+	// Branches whose doctor-on/off designation or closure disagrees with the roster
+	Mismatches      int       `json:"mismatches"`
+	// Doctor-assistant pairings that cannot be met, with the reason
+	UnmetPairings   []*models.DoctorPairing `json:"unmet_pairings"`
 }
 
 // MonthlyOverview represents overview data for a single branch across a month
@@ -66,42 +107,48 @@ type MonthlyOverview struct {
 
 // GenerateDayOverview generates overview for specified branches on a specific day
 // If branchIDs is nil or empty, calculates for all branches
-func (g *OverviewGenerator) GenerateDayOverview(date time.Time, branchIDs []uuid.UUID) (*DayOverview, error) {
+func (g *OverviewGenerator) GenerateDayOverview(ctx context.Context, date time.Time, branchIDs []uuid.UUID) (*DayOverview, error) {
 	// Check cache first
 	cacheKey := g.generateCacheKey(date, branchIDs)
-	if cached, found := g.cache.Load(cacheKey); found {
-		entry := cached.(*cacheEntry)
-		if time.Now().Before(entry.expiresAt) {
-			return entry.data, nil
-		}
-		// Cache expired, remove it
-		g.cache.Delete(cacheKey)
+	if cached, found := g.cache.Get(cacheKey); found {
+		return cached, nil
 	}
 
 	// If no branch IDs provided, get all branches
 	if len(branchIDs) == 0 {
-		branches, err := g.repos.Branch.List()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get branches: %w", err)
-		}
-		branchIDs = make([]uuid.UUID, 0, len(branches))
-		for _, branch := range branches {
-			branchIDs = append(branchIDs, branch.ID)
+		var err error
+		if branchIDs, err = g.allBranchIDs(ctx); err != nil {
+			return nil, err
 		}
 	}
 
 	// Calculate quota status for specified branches
-	branchStatuses, err := g.quotaCalculator.CalculateBranchesQuotaStatus(branchIDs, date)
+	branchStatuses, err := g.quotaCalculator.CalculateBranchesQuotaStatus(ctx, branchIDs, date)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate quota statuses: %w", err)
 	}
 
-	// Count branches with shortage
+	// Count branches with shortage and with a designation mismatch
 	branchesWithShortage := 0
+	mismatches := 0
 	for _, status := range branchStatuses {
 		if status.TotalRequired > 0 {
 			branchesWithShortage++
 		}
+		if status.DayStatus != nil && status.DayStatus.Mismatch != "" {
+			mismatches++
+		}
+	}
+
+	pairings, err := g.pairingResolver.Resolve(ctx, branchIDs, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve doctor pairings: %w", err)
+	}
+	unmetPairings := []*models.DoctorPairing{}
+	for _, pairing := range pairings {
+		if pairing.IsUnmet() {
+			unmetPairings = append(unmetPairings, pairing)
+		}
 	}
 
 	overview := &DayOverview{
@@ -109,34 +156,25 @@ func (g *OverviewGenerator) GenerateDayOverview(date time.Time, branchIDs []uuid
 		BranchStatuses:    branchStatuses,
 		TotalBranches:     len(branchStatuses),
 		BranchesWithShortage: branchesWithShortage,
+		Mismatches:        mismatches,
+		UnmetPairings:     unmetPairings,
 	}
 
 	// Cache the result
-	g.cache.Store(cacheKey, &cacheEntry{
-		data:      overview,
-		expiresAt: time.Now().Add(g.cacheTTL),
-	})
+	g.cache.Put(cacheKey, overview)
 
 	return overview, nil
 }
 
 // InvalidateCache invalidates the cache for a specific date
 func (g *OverviewGenerator) InvalidateCache(date time.Time) {
-	dateStr := date.Format("2006-01-02")
-	// Remove all cache entries for this date
-	g.cache.Range(func(key, value interface{}) bool {
-		keyStr := key.(string)
-		if len(keyStr) > len(dateStr) && keyStr[:len(dateStr)+9] == fmt.Sprintf("overview:%s:", dateStr) {
-			g.cache.Delete(key)
-		}
-		return true
-	})
+	g.HandleEvent(context.Background(), events.Event{Date: date})
 }
 
 // GenerateMonthlyOverview generates overview for a single branch across a month
-func (g *OverviewGenerator) GenerateMonthlyOverview(branchID uuid.UUID, year int, month int) (*MonthlyOverview, error) {
+func (g *OverviewGenerator) GenerateMonthlyOverview(ctx context.Context, branchID uuid.UUID, year int, month int) (*MonthlyOverview, error) {
 	// Get branch info
-	branch, err := g.repos.Branch.GetByID(branchID)
+	branch, err := g.repos.Branch.GetByID(ctx, branchID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get branch: %w", err)
 	}
@@ -145,12 +183,115 @@ func (g *OverviewGenerator) GenerateMonthlyOverview(branchID uuid.UUID, year int
 	}
 
 	// Calculate quota status for the month
-	dayStatuses, err := g.quotaCalculator.CalculateMonthlyQuotaStatus(branchID, year, month)
+	dayStatuses, err := g.quotaCalculator.CalculateMonthlyQuotaStatus(ctx, branchID, year, month)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate monthly quota status: %w", err)
+	}
+
+	return &MonthlyOverview{
+		BranchID:          branchID,
+		BranchName:        branch.Name,
+		BranchCode:        branch.Code,
+		Year:              year,
+		Month:             month,
+		DayStatuses:       dayStatuses,
+		AverageFulfillment: averageFulfillment(dayStatuses),
+	}, nil
+}
+
+// MonthlyMatrix holds the monthly overview of several branches, one row per branch
+type MonthlyMatrix struct {
+	Year     int                `json:"year"`
+	Month    int                `json:"month"`
+	Dates    []string           `json:"dates"` // Column headers, YYYY-MM-DD
+	Branches []*MonthlyOverview `json:"branches"`
+}
+
+// GenerateMonthlyMatrix generates the monthly overview of the specified branches in one pass
+// If branchIDs is nil or empty, calculates for all branches
+func (g *OverviewGenerator) GenerateMonthlyMatrix(ctx context.Context, year int, month int, branchIDs []uuid.UUID) (*MonthlyMatrix, error) {
+	if len(branchIDs) == 0 {
+		var err error
+		if branchIDs, err = g.allBranchIDs(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := g.quotaCalculator.CalculateMonthlyMatrix(ctx, branchIDs, year, month)
 	if err != nil {
 		return nil, fmt.Errorf("failed to calculate monthly quota status: %w", err)
 	}
 
-	// Calculate average fulfillment
+	dates := MonthDates(year, month)
+	matrix := &MonthlyMatrix{
+		Year:     year,
+		Month:    month,
+		Dates:    make([]string, 0, len(dates)),
+		Branches: make([]*MonthlyOverview, 0, len(rows)),
+	}
+	for _, date := range dates {
+		matrix.Dates = append(matrix.Dates, date.Format("2006-01-02"))
+	}
+	for i, dayStatuses := range rows {
+		row := &MonthlyOverview{
+			BranchID:           branchIDs[i],
+			Year:               year,
+			Month:              month,
+			DayStatuses:        dayStatuses,
+			AverageFulfillment: averageFulfillment(dayStatuses),
+		}
+		if len(dayStatuses) > 0 {
+			row.BranchName = dayStatuses[0].BranchName
+			row.BranchCode = dayStatuses[0].BranchCode
+		}
+		matrix.Branches = append(matrix.Branches, row)
+	}
+
+	return matrix, nil
+}
+
+// GenerateBranchDayStatuses resolves the status of the specified branches on
+// each day from startDate to endDate, keeping only mismatches if asked.
+// If branchIDs is nil or empty, resolves for all branches
+func (g *OverviewGenerator) GenerateBranchDayStatuses(ctx context.Context, startDate, endDate time.Time, branchIDs []uuid.UUID, mismatchesOnly bool) ([]*models.BranchDayStatus, error) {
+	if len(branchIDs) == 0 {
+		var err error
+		if branchIDs, err = g.allBranchIDs(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	statuses, err := LoadBranchDayStatuses(ctx, g.repos, branchIDs, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve branch day statuses: %w", err)
+	}
+	if !mismatchesOnly {
+		return statuses, nil
+	}
+	mismatches := []*models.BranchDayStatus{}
+	for _, status := range statuses {
+		if status.Mismatch != "" {
+			mismatches = append(mismatches, status)
+		}
+	}
+	return mismatches, nil
+}
+
+// allBranchIDs returns the IDs of every branch
+func (g *OverviewGenerator) allBranchIDs(ctx context.Context) ([]uuid.UUID, error) {
+	branches, err := g.repos.Branch.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branches: %w", err)
+	}
+	branchIDs := make([]uuid.UUID, 0, len(branches))
+	for _, branch := range branches {
+		branchIDs = append(branchIDs, branch.ID)
+	}
+	return branchIDs, nil
+}
+
+// averageFulfillment averages assigned/designated, capped at 1, over the days with a quota
+func averageFulfillment(dayStatuses []*BranchQuotaStatus) float64 {
 	totalFulfillment := 0.0
 	daysWithQuota := 0
 	for _, status := range dayStatuses {
@@ -164,18 +305,8 @@ func (g *OverviewGenerator) GenerateMonthlyOverview(branchID uuid.UUID, year int
 		}
 	}
 
-	averageFulfillment := 0.0
-	if daysWithQuota > 0 {
-		averageFulfillment = totalFulfillment / float64(daysWithQuota)
+	if daysWithQuota == 0 {
+		return 0.0
 	}
-
-	return &MonthlyOverview{
-		BranchID:          branchID,
-		BranchName:        branch.Name,
-		BranchCode:        branch.Code,
-		Year:              year,
-		Month:             month,
-		DayStatuses:       dayStatuses,
-		AverageFulfillment: averageFulfillment,
-	}, nil
+	return totalFulfillment / float64(daysWithQuota)
 }
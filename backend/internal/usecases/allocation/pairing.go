@@ -0,0 +1,271 @@
+package allocation
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+
+	"github.com/google/uuid"
+)
+
+// PairingResolver resolves doctor-assistant pairings against the doctor
+// roster: each paired staff member follows the doctor to whichever branch the
+// roster puts them on, and is held for that branch if they are free to go
+type PairingResolver struct {
+	repos *RepositoriesWrapper
+}
+
+// NewPairingResolver creates a new pairing resolver
+func NewPairingResolver(repos *RepositoriesWrapper) *PairingResolver {
+	return &PairingResolver{repos: repos}
+}
+
+// Resolve returns the pairings of the doctors rostered at the branches on
+// date, hard pairings first, each with whether the paired staff member is met,
+// reserved or unmet and why. Closed and doctor-off branches have no pairings.
+// If branchIDs is nil or empty, resolves for all branches.
+func (r *PairingResolver) Resolve(ctx context.Context, branchIDs []uuid.UUID, date time.Time) ([]*models.DoctorPairing, error) {
+	if len(branchIDs) == 0 {
+		branches, err := r.repos.Branch.List(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get branches: %w", err)
+		}
+		for _, branch := range branches {
+			branchIDs = append(branchIDs, branch.ID)
+		}
+	}
+
+	dc, err := loadRosterContext(ctx, r.repos, branchIDs, date, date)
+	if err != nil {
+		return nil, err
+	}
+	pairings, err := r.collect(ctx, dc, branchIDs, date)
+	if err != nil || len(pairings) == 0 {
+		return pairings, err
+	}
+	if err := r.check(ctx, pairings, date); err != nil {
+		return nil, err
+	}
+	return pairings, nil
+}
+
+// PairingFor returns the hard pairing holding the staff member at a branch
+// other than branchID on date, or nil if they are free to be assigned there
+func (r *PairingResolver) PairingFor(ctx context.Context, staffID, branchID uuid.UUID, date time.Time) (*models.DoctorPairing, error) {
+	pairings, err := r.Resolve(ctx, nil, date)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pairings {
+		if p.StaffID == staffID && p.Hard && p.Status == models.PairingReserved && p.BranchID != branchID {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+// collect lists the pairings of each doctor rostered at an operational branch,
+// from staff_name specific preferences and from the specific staff
+// requirements of rotation_staff_requirement rules, which are hard
+func (r *PairingResolver) collect(ctx context.Context, dc *DayContext, branchIDs []uuid.UUID, date time.Time) ([]*models.DoctorPairing, error) {
+	active := true
+	specific, err := r.repos.SpecificPreference.List(ctx, interfaces.SpecificPreferenceFilters{IsActive: &active})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get specific preferences: %w", err)
+	}
+	rules, err := r.repos.DoctorPreference.GetActiveByRuleType(ctx, models.RuleRotationStaffRequirement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get doctor preferences: %w", err)
+	}
+	rulesByDoctor := make(map[uuid.UUID][]*models.DoctorPreference)
+	for _, rule := range rules {
+		rulesByDoctor[rule.DoctorID] = append(rulesByDoctor[rule.DoctorID], rule)
+	}
+
+	dayOfWeek := int(date.Weekday())
+	dateStr := date.Format("2006-01-02")
+	pairings := []*models.DoctorPairing{}
+	seen := make(map[[3]uuid.UUID]*models.DoctorPairing) // branch, doctor, staff
+	add := func(branchID uuid.UUID, doctor *models.Doctor, staffID uuid.UUID, hard bool, source string, preferenceID uuid.UUID) {
+		key := [3]uuid.UUID{branchID, doctor.ID, staffID}
+		if p, ok := seen[key]; ok {
+			p.Hard = p.Hard || hard
+			return
+		}
+		p := &models.DoctorPairing{
+			DoctorID:     doctor.ID,
+			DoctorName:   doctor.Name,
+			StaffID:      staffID,
+			BranchID:     branchID,
+			BranchCode:   dc.Branch(branchID).Code,
+			Date:         date,
+			Hard:         hard,
+			Source:       source,
+			PreferenceID: preferenceID,
+		}
+		seen[key] = p
+		pairings = append(pairings, p)
+	}
+
+	for _, branchID := range branchIDs {
+		if dc.Branch(branchID) == nil || !dc.Status(branchID, date).IsOperational() {
+			continue
+		}
+		for _, doctor := range dc.DoctorsOn(branchID, date) {
+			for _, sp := range specific {
+				if sp.IsPairing() && sp.Matches(&branchID, &doctor.ID, &dayOfWeek) {
+					add(branchID, doctor, *sp.StaffID, sp.IsHard, models.PairingSourceSpecificPreference, sp.ID)
+				}
+			}
+			for _, preference := range rulesByDoctor[doctor.ID] {
+				if preference.BranchID != nil && *preference.BranchID != branchID {
+					continue
+				}
+				config, err := preference.ParseRotationStaffRequirementRule()
+				if err != nil || !config.AppliesOn(date) {
+					continue // Malformed rules are reported by CheckDoctorPreferences
+				}
+				for _, req := range config.SpecificStaffRequirements {
+					if req.Date == dateStr {
+						add(branchID, doctor, req.StaffID, true, models.PairingSourceDoctorPreference, preference.ID)
+					}
+				}
+			}
+		}
+	}
+
+	// Hard pairings claim their staff first
+	sort.SliceStable(pairings, func(i, j int) bool {
+		return pairings[i].Hard && !pairings[j].Hard
+	})
+	return pairings, nil
+}
+
+// check sets the status of each pairing in order. A staff member already
+// assigned, on leave or off, or outside the branch's effective branches is
+// unmet; a free rotation staff member is reserved for the first pairing
+// naming them, and unmet as already assigned for the others.
+func (r *PairingResolver) check(ctx context.Context, pairings []*models.DoctorPairing, date time.Time) error {
+	assignments, err := r.repos.Rotation.GetByDate(ctx, date)
+	if err != nil {
+		return fmt.Errorf("failed to get rotation assignments: %w", err)
+	}
+	assignedTo := make(map[uuid.UUID][]uuid.UUID) // staffID -> branchIDs
+	for _, a := range assignments {
+		assignedTo[a.RotationStaffID] = append(assignedTo[a.RotationStaffID], a.BranchID)
+	}
+	rotationSchedules, err := r.repos.RotationStaffSchedule.GetByDate(ctx, date)
+	if err != nil {
+		return fmt.Errorf("failed to get rotation staff schedules: %w", err)
+	}
+	rotationStatus := make(map[uuid.UUID]models.ScheduleStatus)
+	for _, s := range rotationSchedules {
+		rotationStatus[s.RotationStaffID] = s.ScheduleStatus
+	}
+
+	staffByID := make(map[uuid.UUID]*models.Staff)
+	eligible := make(map[uuid.UUID]map[uuid.UUID]bool) // branchID -> rotation staff IDs
+	reserved := make(map[uuid.UUID]uuid.UUID)          // staffID -> branchID
+	for _, p := range pairings {
+		staff, ok := staffByID[p.StaffID]
+		if !ok {
+			if staff, err = r.repos.Staff.GetByID(ctx, p.StaffID); err != nil {
+				return fmt.Errorf("failed to get staff: %w", err)
+			}
+			staffByID[p.StaffID] = staff
+		}
+		if staff == nil {
+			p.Status, p.Reason = models.PairingUnmet, models.PairingReasonStaffNotFound
+			continue
+		}
+		p.StaffName = staff.Nickname
+		p.PositionID = staff.PositionID
+
+		if staff.StaffType != models.StaffTypeRotation {
+			status, err := r.branchStaffStatus(ctx, staff.ID, date)
+			if err != nil {
+				return err
+			}
+			switch {
+			case isLeave(status):
+				p.Status, p.Reason = models.PairingUnmet, models.PairingReasonStaffOnLeave
+			case staff.BranchID == nil || *staff.BranchID != p.BranchID:
+				p.Status, p.Reason = models.PairingUnmet, models.PairingReasonOutsideEffectiveBranches
+			case status != models.ScheduleStatusWorking:
+				p.Status, p.Reason = models.PairingUnmet, models.PairingReasonStaffOff
+			default:
+				p.Status = models.PairingMet
+			}
+			continue
+		}
+
+		if branches := assignedTo[staff.ID]; len(branches) > 0 {
+			for _, branchID := range branches {
+				if branchID == p.BranchID {
+					p.Status = models.PairingMet
+				}
+			}
+			if p.Status == "" {
+				p.Status, p.Reason = models.PairingUnmet, models.PairingReasonAlreadyAssigned
+				p.AssignedBranchID = &branches[0]
+			}
+			continue
+		}
+		if status := rotationStatus[staff.ID]; isLeave(status) {
+			p.Status, p.Reason = models.PairingUnmet, models.PairingReasonStaffOnLeave
+			continue
+		} else if status == models.ScheduleStatusOff {
+			p.Status, p.Reason = models.PairingUnmet, models.PairingReasonStaffOff
+			continue
+		}
+		if branchID, ok := reserved[staff.ID]; ok && branchID != p.BranchID {
+			p.Status, p.Reason = models.PairingUnmet, models.PairingReasonAlreadyAssigned
+			p.AssignedBranchID = &branchID
+			continue
+		}
+		if eligible[p.BranchID] == nil {
+			effectiveBranches, err := r.repos.EffectiveBranch.GetByBranchID(ctx, p.BranchID)
+			if err != nil {
+				return fmt.Errorf("failed to get effective branches: %w", err)
+			}
+			eligible[p.BranchID] = make(map[uuid.UUID]bool, len(effectiveBranches))
+			for _, eb := range effectiveBranches {
+				eligible[p.BranchID][eb.RotationStaffID] = true
+			}
+		}
+		if !eligible[p.BranchID][staff.ID] {
+			p.Status, p.Reason = models.PairingUnmet, models.PairingReasonOutsideEffectiveBranches
+			continue
+		}
+		p.Status = models.PairingReserved
+		reserved[staff.ID] = p.BranchID
+	}
+	return nil
+}
+
+// branchStaffStatus returns a branch staff member's schedule status on date, empty if unscheduled
+func (r *PairingResolver) branchStaffStatus(ctx context.Context, staffID uuid.UUID, date time.Time) (models.ScheduleStatus, error) {
+	schedules, err := r.repos.Schedule.GetByStaffID(ctx, staffID, date, date)
+	if err != nil {
+		return "", fmt.Errorf("failed to get staff schedules: %w", err)
+	}
+	var status models.ScheduleStatus
+	for _, s := range schedules {
+		if isLeave(s.ScheduleStatus) {
+			return s.ScheduleStatus, nil
+		}
+		if status != models.ScheduleStatusWorking {
+			status = s.ScheduleStatus
+		}
+	}
+	return status, nil
+}
+
+// isLeave reports whether a schedule status is leave or sick leave
+func isLeave(status models.ScheduleStatus) bool {
+	return status == models.ScheduleStatusLeave || status == models.ScheduleStatusSickLeave
+}
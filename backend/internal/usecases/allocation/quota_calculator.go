@@ -1,16 +1,29 @@
 package allocation
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"vsq-oper-manpower/backend/internal/domain/events"
 	"vsq-oper-manpower/backend/internal/domain/models"
+	"vsq-oper-manpower/backend/pkg/lru"
+	"vsq-oper-manpower/backend/pkg/workerpool"
 )
 
 // QuotaCalculator calculates quota fulfillment and requirements
 type QuotaCalculator struct {
-	repos *RepositoriesWrapper
+	repos   *RepositoriesWrapper
+	workers int // Concurrent branch/day calculations; 0 uses every CPU
+	// statusCache holds single-branch statuses; nil disables caching
+	statusCache *lru.Cache[statusKey, *BranchQuotaStatus]
+}
+
+// statusKey identifies a branch on a day in the status cache
+type statusKey struct {
+	branchID uuid.UUID
+	date     string
 }
 
 // NewQuotaCalculator creates a new quota calculator
@@ -18,16 +31,50 @@ func NewQuotaCalculator(repos *RepositoriesWrapper) *QuotaCalculator {
 	return &QuotaCalculator{repos: repos}
 }
 
+// WithWorkers bounds how many branch/day statuses are calculated concurrently
+func (c *QuotaCalculator) WithWorkers(workers int) *QuotaCalculator {
+	c.workers = workers
+	return c
+}
+
+// WithStatusCache caches up to size single-branch statuses for ttl. Subscribe
+// HandleEvent to the event bus so changes evict them.
+func (c *QuotaCalculator) WithStatusCache(size int, ttl time.Duration) *QuotaCalculator {
+	c.statusCache = lru.New[statusKey, *BranchQuotaStatus](size, ttl)
+	return c
+}
+
+// HandleEvent evicts the cached statuses of the changed branch and date
+func (c *QuotaCalculator) HandleEvent(ctx context.Context, e events.Event) {
+	if e.Kind == events.QuotaStatusChanged {
+		return
+	}
+	if c.statusCache == nil {
+		return
+	}
+	c.statusCache.RemoveFunc(func(_ statusKey, status *BranchQuotaStatus) bool {
+		return e.Affects(status.BranchID, status.Date)
+	})
+}
+
+// CacheStats returns the status cache counters, all zero when caching is disabled
+func (c *QuotaCalculator) CacheStats() lru.Stats {
+	if c.statusCache == nil {
+		return lru.Stats{}
+	}
+	return c.statusCache.Stats()
+}
+
 // PositionQuotaStatus represents the quota status for a position
 type PositionQuotaStatus struct {
-	PositionID      uuid.UUID `json:"position_id"`
-	PositionName    string    `json:"position_name"`
-	DesignatedQuota int       `json:"designated_quota"`
-	MinimumRequired int       `json:"minimum_required"`
-	AvailableLocal  int       `json:"available_local"`  // Local branch staff available
+	PositionID       uuid.UUID `json:"position_id"`
+	PositionName     string    `json:"position_name"`
+	DesignatedQuota  int       `json:"designated_quota"`
+	MinimumRequired  int       `json:"minimum_required"`
+	AvailableLocal   int       `json:"available_local"`   // Local branch staff available
 	AssignedRotation int       `json:"assigned_rotation"` // Rotation staff assigned
-	TotalAssigned    int       `json:"total_assigned"`   // Total staff (local + rotation)
-	StillRequired    int       `json:"still_required"`   // Staff still needed
+	TotalAssigned    int       `json:"total_assigned"`    // Total staff (local + rotation)
+	StillRequired    int       `json:"still_required"`    // Staff still needed
 }
 
 // DoctorInfo represents basic doctor information
@@ -39,227 +86,195 @@ type DoctorInfo struct {
 
 // BranchQuotaStatus represents the quota status for a branch on a specific date
 type BranchQuotaStatus struct {
-	BranchID        uuid.UUID           `json:"branch_id"`
-	BranchName      string              `json:"branch_name"`
-	BranchCode      string              `json:"branch_code"`
-	Date            time.Time           `json:"date"`
+	BranchID         uuid.UUID             `json:"branch_id"`
+	BranchName       string                `json:"branch_name"`
+	BranchCode       string                `json:"branch_code"`
+	Date             time.Time             `json:"date"`
 	PositionStatuses []PositionQuotaStatus `json:"position_statuses"`
-	TotalDesignated int                 `json:"total_designated"`
-	TotalAvailable  int                 `json:"total_available"`
-	TotalAssigned   int                 `json:"total_assigned"`
-	TotalRequired   int                 `json:"total_required"`
+	TotalDesignated  int                   `json:"total_designated"`
+	TotalAvailable   int                   `json:"total_available"`
+	TotalAssigned    int                   `json:"total_assigned"`
+	TotalRequired    int                   `json:"total_required"`
 	// Doctors assigned to this branch on this date
-	Doctors         []DoctorInfo        `json:"doctors"`
+	Doctors []DoctorInfo `json:"doctors"`
+	// Whether the branch is closed, or open with or without doctors, and any
+	// disagreement between its designation and the roster
+	DayStatus *models.BranchDayStatus `json:"day_status"`
 	// Scoring group points and missing staff
-	Group1Score     int                 `json:"group1_score"`     // Daily Staff Constraints - Minimum Shortage
-	Group2Score     int                 `json:"group2_score"`     // Position Quota - Minimum Shortage
-	Group3Score     int                 `json:"group3_score"`     // Position Quota - Preferred Excess
-	Group1MissingStaff []string          `json:"group1_missing_staff"` // Staff nicknames who don't work (Group 1)
-	Group2MissingStaff []string          `json:"group2_missing_staff"` // Staff nicknames who don't work (Group 2)
-	Group3MissingStaff []string          `json:"group3_missing_staff"` // Staff nicknames who don't work (Group 3)
+	Group1Score        int      `json:"group1_score"`         // Daily Staff Constraints - Minimum Shortage
+	Group2Score        int      `json:"group2_score"`         // Position Quota - Minimum Shortage
+	Group3Score        int      `json:"group3_score"`         // Position Quota - Preferred Excess
+	Group1MissingStaff []string `json:"group1_missing_staff"` // Staff nicknames who don't work (Group 1)
+	Group2MissingStaff []string `json:"group2_missing_staff"` // Staff nicknames who don't work (Group 2)
+	Group3MissingStaff []string `json:"group3_missing_staff"` // Staff nicknames who don't work (Group 3)
 }
 
 // CalculateBranchQuotaStatus calculates quota status for a branch on a specific date
-// First tries to use pre-computed summary table, falls back to calculation if not available
-func (c *QuotaCalculator) CalculateBranchQuotaStatus(branchID uuid.UUID, date time.Time) (*BranchQuotaStatus, error) {
-	// Try to get from summary table first (fast path)
-	// If BranchQuotaSummary repository is not available, skip cache and calculate directly
-	if c.repos.BranchQuotaSummary == nil {
-		return c.calculateBranchQuotaStatus(branchID, date)
-	}
-	
-	summary, err := c.repos.BranchQuotaSummary.GetByBranchIDAndDate(branchID, date)
-	if err == nil && summary != nil {
-		// Get branch info for name/code
-		branch, err := c.repos.Branch.GetByID(branchID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get branch: %w", err)
-		}
-		if branch == nil {
-			return nil, fmt.Errorf("branch not found")
-		}
-		
-		// Get position summaries
-		positionStatuses, err := c.getPositionStatusesFromSummary(branchID, date)
-		if err != nil {
-			// Fallback to calculation if position summaries fail
-			return c.calculateBranchQuotaStatus(branchID, date)
-		}
-		
-		// Get doctors for this branch and date
-		doctors, err := c.getDoctorsForBranch(branchID, date)
-		if err != nil {
-			// Log error but don't fail - doctors are optional for display
-			// Note: This could hide real issues, but we want to continue even if doctor fetch fails
-			doctors = []DoctorInfo{}
-		}
-		
-		// Recalculate Group 1 score using correct logic (summary table calculation is incorrect)
-		// We need branch staff and schedules for missing staff tracking
-		branchStaff, err := c.repos.Staff.GetByBranchID(branchID)
-		if err != nil {
-			// Fallback to calculation if we can't get branch staff
-			return c.calculateBranchQuotaStatus(branchID, date)
-		}
-		
-		staffIDs := make([]uuid.UUID, 0, len(branchStaff))
-		for _, staff := range branchStaff {
-			staffIDs = append(staffIDs, staff.ID)
-		}
-		schedulesMap, err := c.repos.Schedule.GetByStaffIDs(staffIDs, date, date)
-		if err != nil {
-			// Fallback to calculation if we can't get schedules
-			return c.calculateBranchQuotaStatus(branchID, date)
-		}
-		
-		rotationAssignments, err := c.repos.Rotation.GetByBranchID(branchID, date, date)
-		if err != nil {
-			// Use empty list if we can't get rotation assignments
-			rotationAssignments = []*models.RotationAssignment{}
-		}
-		
-		// Recalculate Group 1 and Group 2 with correct logic (summary table calculations are incorrect)
-		group1Score, group1Missing := c.calculateGroup1ScoreAndMissingStaff(branchID, date, branchStaff, rotationAssignments, schedulesMap, positionStatuses)
-		
-		// Get quotas and position map for Group 2 calculation
-		quotas, err := c.repos.PositionQuota.GetByBranchID(branchID)
-		if err != nil {
-			// Fallback to calculation if we can't get quotas
-			return c.calculateBranchQuotaStatus(branchID, date)
-		}
-		
-		positions, err := c.repos.Position.List()
-		if err != nil {
-			// Fallback to calculation if we can't get positions
-			return c.calculateBranchQuotaStatus(branchID, date)
-		}
-		
-		positionMap := make(map[uuid.UUID]*models.Position)
-		for _, pos := range positions {
-			positionMap[pos.ID] = pos
+// Uses the pre-computed summary totals when available, falls back to calculation otherwise
+func (c *QuotaCalculator) CalculateBranchQuotaStatus(ctx context.Context, branchID uuid.UUID, date time.Time) (*BranchQuotaStatus, error) {
+	key := statusKey{branchID: branchID, date: dateKey(date)}
+	if c.statusCache != nil {
+		if status, found := c.statusCache.Get(key); found {
+			return status, nil
 		}
-		
-		group2Score, group2Missing := c.calculateGroup2ScoreAndMissingStaff(branchID, date, quotas, branchStaff, rotationAssignments, positionMap, schedulesMap)
-		
-		return &BranchQuotaStatus{
-			BranchID:         branchID,
-			BranchName:       branch.Name,
-			BranchCode:       branch.Code,
-			Date:             date,
-			PositionStatuses: positionStatuses,
-			TotalDesignated:  summary.TotalDesignated,
-			TotalAvailable:   summary.TotalAvailable,
-			TotalAssigned:    summary.TotalAssigned,
-			TotalRequired:    summary.TotalRequired,
-			Doctors:          doctors,
-			Group1Score:      group1Score, // Use recalculated value
-			Group2Score:      group2Score, // Use recalculated value
-			Group3Score:      summary.Group3Score,
-			Group1MissingStaff: group1Missing, // Use recalculated value
-			Group2MissingStaff: group2Missing, // Use recalculated value
-			Group3MissingStaff: summary.Group3MissingStaff,
-		}, nil
 	}
-	
-	// Fallback to calculation if summary not available
-	return c.calculateBranchQuotaStatus(branchID, date)
-}
 
-// calculateBranchQuotaStatus performs the actual calculation (original implementation)
-func (c *QuotaCalculator) calculateBranchQuotaStatus(branchID uuid.UUID, date time.Time) (*BranchQuotaStatus, error) {
-	// Get branch info
-	branch, err := c.repos.Branch.GetByID(branchID)
+	dc, err := LoadDayContext(ctx, c.repos, []uuid.UUID{branchID}, date, date)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get branch: %w", err)
-	}
-	if branch == nil {
-		return nil, fmt.Errorf("branch not found")
+		return nil, err
 	}
-
-	// Check if branch is operational (has at least one doctor)
-	// Branch operational status is determined by doctor assignments
-	doctorCount, err := c.repos.DoctorAssignment.GetDoctorCountByBranch(branchID, date)
+	status, err := c.CalculateBranchQuotaStatusFromContext(ctx, dc, branchID, date)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get doctor count: %w", err)
+		return nil, err
 	}
+	if c.statusCache != nil {
+		c.statusCache.Put(key, status)
+	}
+	return status, nil
+}
 
-	// Get doctors for this branch and date (always fetch, even if count is 0)
-	doctors, err := c.getDoctorsForBranch(branchID, date)
-	if err != nil {
-		// Log error but don't fail - doctors are optional for display
-		// Note: This could hide real issues, but we want to continue even if doctor fetch fails
-		doctors = []DoctorInfo{}
+// CalculateBranchQuotaStatusFromContext calculates quota status for a branch on a date already covered by dc
+func (c *QuotaCalculator) CalculateBranchQuotaStatusFromContext(ctx context.Context, dc *DayContext, branchID uuid.UUID, date time.Time) (*BranchQuotaStatus, error) {
+	branch := dc.Branch(branchID)
+	if branch == nil {
+		return nil, fmt.Errorf("branch not found")
 	}
 
-	// If branch has no doctors, it's closed - return empty status (no rotation staff needed)
-	if doctorCount == 0 {
+	// Fast path: totals and scores come from the summary table, which the
+	// summary worker keeps current with this same calculation
+	dayStatus := dc.Status(branchID, date)
+	if summary := dc.Summary(branchID, date); summary != nil && dayStatus.IsOperational() {
 		return &BranchQuotaStatus{
-			BranchID:         branchID,
-			BranchName:       branch.Name,
-			BranchCode:       branch.Code,
-			Date:             date,
-			PositionStatuses: []PositionQuotaStatus{},
-			TotalDesignated:  0,
-			TotalAvailable:   0,
-			TotalAssigned:    0,
-			TotalRequired:    0,
-			Doctors:          doctors, // Empty list when no doctors
-			Group1Score:      0,
-			Group2Score:      0,
-			Group3Score:      0,
-			Group1MissingStaff: []string{},
-			Group2MissingStaff: []string{},
-			Group3MissingStaff: []string{},
+			BranchID:           branchID,
+			BranchName:         branch.Name,
+			BranchCode:         branch.Code,
+			Date:               date,
+			PositionStatuses:   c.calculatePositionStatuses(dc, branchID, date),
+			TotalDesignated:    summary.TotalDesignated,
+			TotalAvailable:     summary.TotalAvailable,
+			TotalAssigned:      summary.TotalAssigned,
+			TotalRequired:      summary.TotalRequired,
+			Doctors:            c.doctorsForBranch(dc, branchID, date),
+			DayStatus:          dayStatus,
+			Group1Score:        summary.Group1Score,
+			Group2Score:        summary.Group2Score,
+			Group3Score:        summary.Group3Score,
+			Group1MissingStaff: summary.Group1MissingStaff,
+			Group2MissingStaff: summary.Group2MissingStaff,
+			Group3MissingStaff: summary.Group3MissingStaff,
 		}, nil
 	}
 
-	// Get position quotas for the branch
-	quotas, err := c.repos.PositionQuota.GetByBranchID(branchID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get position quotas: %w", err)
-	}
+	status := c.calculateBranchQuotaStatus(dc, branch, date)
 
-	// Get branch staff
-	branchStaff, err := c.repos.Staff.GetByBranchID(branchID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get branch staff: %w", err)
+	// Queue the missing summary for the summary worker (async, don't block on error).
+	// Detached from ctx so the write survives the request finishing
+	if c.repos.BranchQuotaSummary != nil && dc.Summary(branchID, date) == nil && !dc.IsDirty(branchID, date) {
+		bgCtx := context.WithoutCancel(ctx)
+		go func() {
+			_ = c.repos.BranchQuotaSummary.MarkDirty(bgCtx, branchID, date, date)
+		}()
 	}
 
-	// Get rotation assignments for the date
-	rotationAssignments, err := c.repos.Rotation.GetByBranchID(branchID, date, date)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get rotation assignments: %w", err)
-	}
+	return status, nil
+}
 
-	// Get all positions
-	positions, err := c.repos.Position.List()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get positions: %w", err)
+// SummaryFromContext calculates the summary row of a branch on a date covered by dc,
+// ignoring any summary dc already holds
+func (c *QuotaCalculator) SummaryFromContext(dc *DayContext, branchID uuid.UUID, date time.Time) (*models.BranchQuotaSummary, error) {
+	branch := dc.Branch(branchID)
+	if branch == nil {
+		return nil, fmt.Errorf("branch not found")
 	}
+	status := c.calculateBranchQuotaStatus(dc, branch, date)
+	return &models.BranchQuotaSummary{
+		BranchID:           branchID,
+		Date:               date,
+		TotalDesignated:    status.TotalDesignated,
+		TotalAvailable:     status.TotalAvailable,
+		TotalAssigned:      status.TotalAssigned,
+		TotalRequired:      status.TotalRequired,
+		Group1Score:        status.Group1Score,
+		Group2Score:        status.Group2Score,
+		Group3Score:        status.Group3Score,
+		Group1MissingStaff: status.Group1MissingStaff,
+		Group2MissingStaff: status.Group2MissingStaff,
+		Group3MissingStaff: status.Group3MissingStaff,
+	}, nil
+}
 
-	positionMap := make(map[uuid.UUID]*models.Position)
-	for _, pos := range positions {
-		positionMap[pos.ID] = pos
-	}
+// calculateBranchQuotaStatus performs the full calculation from the inputs in dc
+func (c *QuotaCalculator) calculateBranchQuotaStatus(dc *DayContext, branch *models.Branch, date time.Time) *BranchQuotaStatus {
+	branchID := branch.ID
+	doctors := c.doctorsForBranch(dc, branchID, date)
+	dayStatus := dc.Status(branchID, date)
 
-	// Batch fetch all schedules for branch staff at once
-	staffIDs := make([]uuid.UUID, 0, len(branchStaff))
-	for _, staff := range branchStaff {
-		staffIDs = append(staffIDs, staff.ID)
-	}
-	schedulesMap, err := c.repos.Schedule.GetByStaffIDs(staffIDs, date, date)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get schedules: %w", err)
+	// Closed and doctor-off days need no staff - return empty status (no rotation staff needed)
+	if !dayStatus.IsOperational() {
+		return &BranchQuotaStatus{
+			BranchID:           branchID,
+			BranchName:         branch.Name,
+			BranchCode:         branch.Code,
+			Date:               date,
+			PositionStatuses:   []PositionQuotaStatus{},
+			Doctors:            doctors,
+			DayStatus:          dayStatus,
+			Group1MissingStaff: []string{},
+			Group2MissingStaff: []string{},
+			Group3MissingStaff: []string{},
+		}
 	}
 
-	// Calculate status for each position
-	positionStatuses := []PositionQuotaStatus{}
+	positionStatuses := c.calculatePositionStatuses(dc, branchID, date)
 	totalDesignated := 0
 	totalAvailable := 0
 	totalAssigned := 0
 	totalRequired := 0
+	for _, status := range positionStatuses {
+		totalDesignated += status.DesignatedQuota
+		totalAvailable += status.AvailableLocal
+		totalAssigned += status.TotalAssigned
+		totalRequired += status.StillRequired
+	}
+
+	// Calculate scoring groups and missing staff
+	// Group 1: Daily Staff Constraints - Minimum Shortage
+	// Use positionStatuses to ensure consistency with what's displayed in the UI
+	group1Score, group1Missing := c.calculateGroup1ScoreAndMissingStaff(dc, branchID, date, positionStatuses)
+	// Group 2: Position Quota - Minimum Shortage
+	group2Score, group2Missing := c.calculateGroup2ScoreAndMissingStaff(dc, branchID, date)
+	// Group 3: Position Quota - Preferred Excess
+	group3Score, group3Missing := c.calculateGroup3ScoreAndMissingStaff(dc, branchID, date)
+
+	return &BranchQuotaStatus{
+		BranchID:           branchID,
+		BranchName:         branch.Name,
+		BranchCode:         branch.Code,
+		Date:               date,
+		PositionStatuses:   positionStatuses,
+		TotalDesignated:    totalDesignated,
+		TotalAvailable:     totalAvailable,
+		TotalAssigned:      totalAssigned,
+		TotalRequired:      totalRequired,
+		Doctors:            doctors,
+		DayStatus:          dayStatus,
+		Group1Score:        group1Score,
+		Group2Score:        group2Score,
+		Group3Score:        group3Score,
+		Group1MissingStaff: group1Missing,
+		Group2MissingStaff: group2Missing,
+		Group3MissingStaff: group3Missing,
+	}
+}
+
+// calculatePositionStatuses calculates the status of each active quota of the branch on date
+func (c *QuotaCalculator) calculatePositionStatuses(dc *DayContext, branchID uuid.UUID, date time.Time) []PositionQuotaStatus {
+	positionMap := dc.PositionMap()
+	branchStaff := dc.BranchStaff(branchID)
+	schedulesMap := dc.SchedulesOn(branchID, date)
 
-	for _, quota := range quotas {
+	positionStatuses := []PositionQuotaStatus{}
+	for _, quota := range dc.Quotas(branchID) {
 		if !quota.IsActive {
 			continue
 		}
@@ -273,24 +288,15 @@ func (c *QuotaCalculator) calculateBranchQuotaStatus(branchID uuid.UUID, date ti
 		availableLocal := 0
 		for _, staff := range branchStaff {
 			if staff.PositionID == quota.PositionID {
-				// Check if staff is working on this date using batch-fetched schedules
 				schedules := schedulesMap[staff.ID]
-				if len(schedules) > 0 {
-					if schedules[0].ScheduleStatus == models.ScheduleStatusWorking {
-						availableLocal++
-					}
+				if len(schedules) > 0 && schedules[0].ScheduleStatus == models.ScheduleStatusWorking {
+					availableLocal++
 				}
 			}
 		}
 
 		// Count rotation staff assigned for this position
-		assignedRotation := 0
-		for _, assignment := range rotationAssignments {
-			staff, err := c.repos.Staff.GetByID(assignment.RotationStaffID)
-			if err == nil && staff != nil && staff.PositionID == quota.PositionID {
-				assignedRotation++
-			}
-		}
+		assignedRotation := dc.RotationCount(branchID, quota.PositionID, date)
 
 		totalAssignedForPosition := availableLocal + assignedRotation
 		stillRequired := quota.MinimumRequired - totalAssignedForPosition
@@ -299,278 +305,123 @@ func (c *QuotaCalculator) calculateBranchQuotaStatus(branchID uuid.UUID, date ti
 		}
 
 		positionStatuses = append(positionStatuses, PositionQuotaStatus{
-			PositionID:      quota.PositionID,
-			PositionName:    position.Name,
-			DesignatedQuota: quota.DesignatedQuota,
-			MinimumRequired: quota.MinimumRequired,
-			AvailableLocal:  availableLocal,
+			PositionID:       quota.PositionID,
+			PositionName:     position.Name,
+			DesignatedQuota:  quota.DesignatedQuota,
+			MinimumRequired:  quota.MinimumRequired,
+			AvailableLocal:   availableLocal,
 			AssignedRotation: assignedRotation,
 			TotalAssigned:    totalAssignedForPosition,
 			StillRequired:    stillRequired,
 		})
-
-		totalDesignated += quota.DesignatedQuota
-		totalAvailable += availableLocal
-		totalAssigned += totalAssignedForPosition
-		totalRequired += stillRequired
 	}
 
-	// Calculate scoring groups and missing staff
-	// Group 1: Daily Staff Constraints - Minimum Shortage
-	// Use positionStatuses to ensure consistency with what's displayed in the UI
-	group1Score, group1Missing := c.calculateGroup1ScoreAndMissingStaff(branchID, date, branchStaff, rotationAssignments, schedulesMap, positionStatuses)
-	// Group 2: Position Quota - Minimum Shortage
-	group2Score, group2Missing := c.calculateGroup2ScoreAndMissingStaff(branchID, date, quotas, branchStaff, rotationAssignments, positionMap, schedulesMap)
-	// Group 3: Position Quota - Preferred Excess
-	group3Score, group3Missing := c.calculateGroup3ScoreAndMissingStaff(branchID, date, quotas, branchStaff, rotationAssignments, positionMap, schedulesMap)
-
-	result := &BranchQuotaStatus{
-		BranchID:         branchID,
-		BranchName:       branch.Name,
-		BranchCode:       branch.Code,
-		Date:             date,
-		PositionStatuses: positionStatuses,
-		TotalDesignated:  totalDesignated,
-		TotalAvailable:   totalAvailable,
-		TotalAssigned:    totalAssigned,
-		TotalRequired:    totalRequired,
-		Doctors:          doctors,
-		Group1Score:      group1Score,
-		Group2Score:      group2Score,
-		Group3Score:      group3Score,
-		Group1MissingStaff: group1Missing,
-		Group2MissingStaff: group2Missing,
-		Group3MissingStaff: group3Missing,
-	}
-	
-	// Save to summary table for future use (async, don't block on error)
-	if c.repos.BranchQuotaSummary != nil {
-		go func() {
-			_ = c.repos.BranchQuotaSummary.Recalculate(branchID, date)
-		}()
-	}
-	
-	return result, nil
+	return positionStatuses
 }
 
-// getPositionStatusesFromSummary is a helper to get position statuses (simplified - still calculates)
-// In future, this could use position_quota_daily_summary table
-func (c *QuotaCalculator) getPositionStatusesFromSummary(branchID uuid.UUID, date time.Time) ([]PositionQuotaStatus, error) {
-	// For now, we still calculate position statuses
-	// In Phase 3, we can optimize this to use position_quota_daily_summary table
-	quotas, err := c.repos.PositionQuota.GetByBranchID(branchID)
+// CalculateBranchesQuotaStatus calculates quota status for multiple branches on a specific date
+// Statuses are returned in the order of branchIDs
+func (c *QuotaCalculator) CalculateBranchesQuotaStatus(ctx context.Context, branchIDs []uuid.UUID, date time.Time) ([]*BranchQuotaStatus, error) {
+	dc, err := LoadDayContext(ctx, c.repos, branchIDs, date, date)
 	if err != nil {
 		return nil, err
 	}
-	
-	positions, err := c.repos.Position.List()
+
+	statuses, err := workerpool.Map(ctx, c.workers, len(branchIDs), func(ctx context.Context, i int) (*BranchQuotaStatus, error) {
+		status, err := c.CalculateBranchQuotaStatusFromContext(ctx, dc, branchIDs[i], date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate quota status for branch %s: %w", branchIDs[i], err)
+		}
+		return status, nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	
-	positionMap := make(map[uuid.UUID]*models.Position)
-	for _, pos := range positions {
-		positionMap[pos.ID] = pos
-	}
-	
-	branchStaff, err := c.repos.Staff.GetByBranchID(branchID)
+	return statuses, nil
+}
+
+// CalculateMonthlyQuotaStatus calculates quota status for a branch across a month
+func (c *QuotaCalculator) CalculateMonthlyQuotaStatus(ctx context.Context, branchID uuid.UUID, year int, month int) ([]*BranchQuotaStatus, error) {
+	matrix, err := c.CalculateMonthlyMatrix(ctx, []uuid.UUID{branchID}, year, month)
 	if err != nil {
 		return nil, err
 	}
-	
-	staffIDs := make([]uuid.UUID, 0, len(branchStaff))
-	for _, staff := range branchStaff {
-		staffIDs = append(staffIDs, staff.ID)
-	}
-	schedulesMap, err := c.repos.Schedule.GetByStaffIDs(staffIDs, date, date)
-	if err != nil {
-		return nil, err
+	return matrix[0], nil
+}
+
+// CalculateMonthlyMatrix calculates quota status for every branch on every day of a month.
+// Rows follow the order of branchIDs and each row holds one status per day.
+func (c *QuotaCalculator) CalculateMonthlyMatrix(ctx context.Context, branchIDs []uuid.UUID, year int, month int) ([][]*BranchQuotaStatus, error) {
+	return c.CalculateMatrix(ctx, branchIDs, MonthDates(year, month))
+}
+
+// CalculateMatrix calculates quota status for every branch on each of the given
+// consecutive dates. Rows follow the order of branchIDs and each row holds one status per date.
+func (c *QuotaCalculator) CalculateMatrix(ctx context.Context, branchIDs []uuid.UUID, dates []time.Time) ([][]*BranchQuotaStatus, error) {
+	if len(dates) == 0 {
+		return make([][]*BranchQuotaStatus, len(branchIDs)), nil
 	}
-	
-	rotationAssignments, err := c.repos.Rotation.GetByBranchID(branchID, date, date)
+	dc, err := LoadDayContext(ctx, c.repos, branchIDs, dates[0], dates[len(dates)-1])
 	if err != nil {
 		return nil, err
 	}
-	
-	positionStatuses := []PositionQuotaStatus{}
-	for _, quota := range quotas {
-		if !quota.IsActive {
-			continue
-		}
-		
-		position := positionMap[quota.PositionID]
-		if position == nil {
-			continue
-		}
-		
-		availableLocal := 0
-		for _, staff := range branchStaff {
-			if staff.PositionID == quota.PositionID {
-				schedules := schedulesMap[staff.ID]
-				if len(schedules) > 0 && schedules[0].ScheduleStatus == models.ScheduleStatusWorking {
-					availableLocal++
-				}
-			}
-		}
-		
-		assignedRotation := 0
-		for _, assignment := range rotationAssignments {
-			staff, err := c.repos.Staff.GetByID(assignment.RotationStaffID)
-			if err == nil && staff != nil && staff.PositionID == quota.PositionID {
-				assignedRotation++
-			}
-		}
-		
-		totalAssignedForPosition := availableLocal + assignedRotation
-		stillRequired := quota.MinimumRequired - totalAssignedForPosition
-		if stillRequired < 0 {
-			stillRequired = 0
-		}
-		
-		positionStatuses = append(positionStatuses, PositionQuotaStatus{
-			PositionID:      quota.PositionID,
-			PositionName:    position.Name,
-			DesignatedQuota: quota.DesignatedQuota,
-			MinimumRequired: quota.MinimumRequired,
-			AvailableLocal:  availableLocal,
-			AssignedRotation: assignedRotation,
-			TotalAssigned:    totalAssignedForPosition,
-			StillRequired:    stillRequired,
-		})
-	}
-	
-	return positionStatuses, nil
-}
-
-// CalculateBranchesQuotaStatus calculates quota status for multiple branches on a specific date
-func (c *QuotaCalculator) CalculateBranchesQuotaStatus(branchIDs []uuid.UUID, date time.Time) ([]*BranchQuotaStatus, error) {
-	statuses := []*BranchQuotaStatus{}
 
-	for _, branchID := range branchIDs {
-		status, err := c.CalculateBranchQuotaStatus(branchID, date)
+	cells, err := workerpool.Map(ctx, c.workers, len(branchIDs)*len(dates), func(ctx context.Context, i int) (*BranchQuotaStatus, error) {
+		branchID, date := branchIDs[i/len(dates)], dates[i%len(dates)]
+		status, err := c.CalculateBranchQuotaStatusFromContext(ctx, dc, branchID, date)
 		if err != nil {
-			return nil, fmt.Errorf("failed to calculate quota status for branch %s: %w", branchID, err)
+			return nil, fmt.Errorf("failed to calculate quota status for branch %s on %s: %w", branchID, date.Format("2006-01-02"), err)
 		}
-		statuses = append(statuses, status)
+		return status, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return statuses, nil
+	matrix := make([][]*BranchQuotaStatus, len(branchIDs))
+	for row := range matrix {
+		matrix[row] = cells[row*len(dates) : (row+1)*len(dates)]
+	}
+	return matrix, nil
 }
 
-// CalculateMonthlyQuotaStatus calculates quota status for a branch across a month
-func (c *QuotaCalculator) CalculateMonthlyQuotaStatus(branchID uuid.UUID, year int, month int) ([]*BranchQuotaStatus, error) {
-	// Get all dates in the month
+// MonthDates returns every day of the month, in UTC
+func MonthDates(year int, month int) []time.Time {
 	startDate := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
 	endDate := startDate.AddDate(0, 1, -1)
 
-	statuses := []*BranchQuotaStatus{}
-	currentDate := startDate
-
-	for !currentDate.After(endDate) {
-		status, err := c.CalculateBranchQuotaStatus(branchID, currentDate)
-		if err != nil {
-			return nil, fmt.Errorf("failed to calculate quota status for date %s: %w", currentDate.Format("2006-01-02"), err)
-		}
-		statuses = append(statuses, status)
-		currentDate = currentDate.AddDate(0, 0, 1)
+	dates := make([]time.Time, 0, endDate.Day())
+	for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 1) {
+		dates = append(dates, date)
 	}
-
-	return statuses, nil
+	return dates
 }
 
 // calculateGroup1ScoreAndMissingStaff calculates Group 1 score (Daily Staff Constraints - Minimum Shortage) and returns missing staff nicknames
 // Uses positionStatuses to ensure consistency with UI display
 func (c *QuotaCalculator) calculateGroup1ScoreAndMissingStaff(
+	dc *DayContext,
 	branchID uuid.UUID,
 	date time.Time,
-	branchStaff []*models.Staff,
-	rotationAssignments []*models.RotationAssignment,
-	schedulesMap map[uuid.UUID][]*models.StaffSchedule,
 	positionStatuses []PositionQuotaStatus,
 ) (int, []string) {
-	dayOfWeek := int(date.Weekday())
-
-	// Get branch to find branch type
-	branch, err := c.repos.Branch.GetByID(branchID)
-	if err != nil || branch == nil {
-		return 0, []string{}
-	}
-
-	// Get branch constraints for this day
-	constraint, err := c.repos.BranchConstraints.GetByBranchIDAndDayOfWeek(branchID, dayOfWeek)
-	if err != nil {
-		return 0, []string{}
-	}
-
-	var staffGroupRequirements []*models.BranchConstraintStaffGroup
-
-	// If branch-specific constraint exists, use it
-	if constraint != nil {
-		// Load staff group requirements from branch constraint
-		if err := c.repos.BranchConstraints.LoadStaffGroupRequirements([]*models.BranchConstraints{constraint}); err != nil {
-			return 0, []string{}
-		}
-		staffGroupRequirements = constraint.StaffGroupRequirements
-	}
-	
-	// If no branch-specific constraints or they're empty, fallback to branch type constraints
-	if len(staffGroupRequirements) == 0 && branch.BranchTypeID != nil {
-		branchTypeConstraints, err := c.repos.BranchTypeConstraints.GetByBranchTypeID(*branch.BranchTypeID)
-		if err != nil {
-			return 0, []string{}
-		}
-
-		// Find constraint for this day
-		var branchTypeConstraint *models.BranchTypeConstraints
-		for _, bt := range branchTypeConstraints {
-			if bt.DayOfWeek == dayOfWeek {
-				branchTypeConstraint = bt
-				break
-			}
-		}
-
-		if branchTypeConstraint != nil {
-			// Load staff group requirements from branch type constraint
-			if err := c.repos.BranchTypeConstraints.LoadStaffGroupRequirements([]*models.BranchTypeConstraints{branchTypeConstraint}); err != nil {
-				return 0, []string{}
-			}
-
-			// Convert BranchTypeConstraintStaffGroup to BranchConstraintStaffGroup
-			staffGroupRequirements = make([]*models.BranchConstraintStaffGroup, 0, len(branchTypeConstraint.StaffGroupRequirements))
-			for _, btReq := range branchTypeConstraint.StaffGroupRequirements {
-				staffGroupRequirements = append(staffGroupRequirements, &models.BranchConstraintStaffGroup{
-					StaffGroupID:  btReq.StaffGroupID,
-					MinimumCount:  btReq.MinimumCount,
-				})
-			}
-		}
-	}
+	// Branch constraints for this day, falling back to branch type constraints
+	staffGroupRequirements := dc.StaffGroupRequirements(branchID, date)
 
 	// If no constraints found (neither branch-specific nor branch type), return 0
 	if len(staffGroupRequirements) == 0 {
 		return 0, []string{}
 	}
 
-	// Get all staff groups and their positions
-	staffGroupPositionsMap := make(map[uuid.UUID][]uuid.UUID) // staffGroupID -> []positionID
-	for _, req := range staffGroupRequirements {
-		positions, err := c.repos.StaffGroupPosition.GetByStaffGroupID(req.StaffGroupID)
-		if err != nil {
-			continue
-		}
-		positionIDs := make([]uuid.UUID, 0, len(positions))
-		for _, sgp := range positions {
-			positionIDs = append(positionIDs, sgp.PositionID)
-		}
-		staffGroupPositionsMap[req.StaffGroupID] = positionIDs
-	}
+	branchStaff := dc.BranchStaff(branchID)
+	schedulesMap := dc.SchedulesOn(branchID, date)
 
 	totalScore := 0
 	missingStaffSet := make(map[string]bool)
 
 	for _, req := range staffGroupRequirements {
-		positionIDs := staffGroupPositionsMap[req.StaffGroupID]
-		
+		positionIDs := dc.StaffGroupPositions(req.StaffGroupID)
+
 		// Calculate actual count by summing total_assigned from positionStatuses for all positions in this staff group
 		// This ensures consistency with what's displayed in the UI modal
 		actualCount := 0
@@ -582,21 +433,14 @@ func (c *QuotaCalculator) calculateGroup1ScoreAndMissingStaff(
 				}
 			}
 		}
-		
+
 		// Also track missing staff (branch staff who don't work)
 		for _, staff := range branchStaff {
 			for _, positionID := range positionIDs {
 				if staff.PositionID == positionID {
 					schedules := schedulesMap[staff.ID]
-					if len(schedules) > 0 {
-						if schedules[0].ScheduleStatus != models.ScheduleStatusWorking {
-							// Staff doesn't work - add to missing staff
-							if staff.Nickname != "" {
-								missingStaffSet[staff.Nickname] = true
-							}
-						}
-					} else {
-						// No schedule found - assume not working
+					// No schedule found - assume not working
+					if len(schedules) == 0 || schedules[0].ScheduleStatus != models.ScheduleStatusWorking {
 						if staff.Nickname != "" {
 							missingStaffSet[staff.Nickname] = true
 						}
@@ -621,58 +465,40 @@ func (c *QuotaCalculator) calculateGroup1ScoreAndMissingStaff(
 	return totalScore, missingStaff
 }
 
+// countPositionStaff counts working branch staff plus assigned rotation staff for a quota,
+// recording branch staff who don't work in missingStaffSet
+func countPositionStaff(dc *DayContext, branchID uuid.UUID, date time.Time, quota *models.PositionQuota, missingStaffSet map[string]bool) int {
+	schedulesMap := dc.SchedulesOn(branchID, date)
+
+	branchCount := 0
+	for _, staff := range dc.BranchStaff(branchID) {
+		if staff.PositionID != quota.PositionID {
+			continue
+		}
+		schedules := schedulesMap[staff.ID]
+		if len(schedules) > 0 && schedules[0].ScheduleStatus == models.ScheduleStatusWorking {
+			branchCount++
+		} else if staff.Nickname != "" {
+			// Staff doesn't work (off, leave, sick_leave, or no schedule) - add to missing staff
+			missingStaffSet[staff.Nickname] = true
+		}
+	}
+
+	// Total current count includes both branch and rotation staff
+	return branchCount + dc.RotationCount(branchID, quota.PositionID, date)
+}
+
 // calculateGroup2ScoreAndMissingStaff calculates Group 2 score (Position Quota - Minimum Shortage) and returns missing staff nicknames
-func (c *QuotaCalculator) calculateGroup2ScoreAndMissingStaff(
-	branchID uuid.UUID,
-	date time.Time,
-	quotas []*models.PositionQuota,
-	branchStaff []*models.Staff,
-	rotationAssignments []*models.RotationAssignment,
-	positionMap map[uuid.UUID]*models.Position,
-	schedulesMap map[uuid.UUID][]*models.StaffSchedule,
-) (int, []string) {
+func (c *QuotaCalculator) calculateGroup2ScoreAndMissingStaff(dc *DayContext, branchID uuid.UUID, date time.Time) (int, []string) {
 	totalScore := 0
 	missingStaffSet := make(map[string]bool)
 
-	for _, quota := range quotas {
+	for _, quota := range dc.Quotas(branchID) {
 		if !quota.IsActive {
 			continue
 		}
 
-		// Count branch staff for this position
-		branchCount := 0
-		for _, staff := range branchStaff {
-			if staff.PositionID == quota.PositionID {
-				schedules := schedulesMap[staff.ID]
-				if len(schedules) > 0 {
-					if schedules[0].ScheduleStatus == models.ScheduleStatusWorking {
-						branchCount++
-					} else {
-						// Staff doesn't work (off, leave, sick_leave) - add to missing staff
-						if staff.Nickname != "" {
-							missingStaffSet[staff.Nickname] = true
-						}
-					}
-				} else {
-					// No schedule found - assume not working
-					if staff.Nickname != "" {
-						missingStaffSet[staff.Nickname] = true
-					}
-				}
-			}
-		}
-
-		// Count rotation staff assigned for this position
-		rotationCount := 0
-		for _, assignment := range rotationAssignments {
-			staff, err := c.repos.Staff.GetByID(assignment.RotationStaffID)
-			if err == nil && staff != nil && staff.PositionID == quota.PositionID {
-				rotationCount++
-			}
-		}
-
-		// Total current count includes both branch and rotation staff
-		currentCount := branchCount + rotationCount
+		currentCount := countPositionStaff(dc, branchID, date, quota, missingStaffSet)
 
 		shortage := quota.MinimumRequired - currentCount
 		if shortage > 0 {
@@ -690,59 +516,18 @@ func (c *QuotaCalculator) calculateGroup2ScoreAndMissingStaff(
 }
 
 // calculateGroup3ScoreAndMissingStaff calculates Group 3 score (Position Quota - Preferred Excess) and returns missing staff nicknames
-// Note: For Group 3, missing staff would be those who ARE working but exceed preferred quota, 
+// Note: For Group 3, missing staff would be those who ARE working but exceed preferred quota,
 // but since we're looking for staff who DON'T work, this will typically be empty
-func (c *QuotaCalculator) calculateGroup3ScoreAndMissingStaff(
-	branchID uuid.UUID,
-	date time.Time,
-	quotas []*models.PositionQuota,
-	branchStaff []*models.Staff,
-	rotationAssignments []*models.RotationAssignment,
-	positionMap map[uuid.UUID]*models.Position,
-	schedulesMap map[uuid.UUID][]*models.StaffSchedule,
-) (int, []string) {
+func (c *QuotaCalculator) calculateGroup3ScoreAndMissingStaff(dc *DayContext, branchID uuid.UUID, date time.Time) (int, []string) {
 	totalScore := 0
 	missingStaffSet := make(map[string]bool)
 
-	for _, quota := range quotas {
+	for _, quota := range dc.Quotas(branchID) {
 		if !quota.IsActive {
 			continue
 		}
 
-		// Count branch staff for this position
-		branchCount := 0
-		for _, staff := range branchStaff {
-			if staff.PositionID == quota.PositionID {
-				schedules := schedulesMap[staff.ID]
-				if len(schedules) > 0 {
-					if schedules[0].ScheduleStatus == models.ScheduleStatusWorking {
-						branchCount++
-					} else {
-						// Staff doesn't work - add to missing staff
-						if staff.Nickname != "" {
-							missingStaffSet[staff.Nickname] = true
-						}
-					}
-				} else {
-					// No schedule found - assume not working
-					if staff.Nickname != "" {
-						missingStaffSet[staff.Nickname] = true
-					}
-				}
-			}
-		}
-
-		// Count rotation staff assigned for this position
-		rotationCount := 0
-		for _, assignment := range rotationAssignments {
-			staff, err := c.repos.Staff.GetByID(assignment.RotationStaffID)
-			if err == nil && staff != nil && staff.PositionID == quota.PositionID {
-				rotationCount++
-			}
-		}
-
-		// Total current count includes both branch and rotation staff
-		currentCount := branchCount + rotationCount
+		currentCount := countPositionStaff(dc, branchID, date, quota, missingStaffSet)
 
 		// Only count positions with actual staff number greater than preferred number
 		excess := currentCount - quota.DesignatedQuota
@@ -760,42 +545,16 @@ func (c *QuotaCalculator) calculateGroup3ScoreAndMissingStaff(
 	return totalScore, missingStaff
 }
 
-// getDoctorsForBranch retrieves doctors assigned to a branch on a specific date
-func (c *QuotaCalculator) getDoctorsForBranch(branchID uuid.UUID, date time.Time) ([]DoctorInfo, error) {
-	// Check if DoctorAssignment repository is available
-	if c.repos.DoctorAssignment == nil {
-		return []DoctorInfo{}, nil // Return empty list if repository not available
-	}
-
-	doctorAssignments, err := c.repos.DoctorAssignment.GetDoctorsByBranchAndDate(branchID, date)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get doctors: %w", err)
-	}
-
-	doctors := make([]DoctorInfo, 0, len(doctorAssignments))
-	for _, assignment := range doctorAssignments {
-		// Use DoctorName and DoctorCode from assignment, which should be populated
-		doctorName := assignment.DoctorName
-		doctorCode := assignment.DoctorCode
-		
-		// Fallback: if name/code are empty, try to get from doctor repo
-		if doctorName == "" || doctorCode == "" {
-			if assignment.Doctor != nil {
-				if doctorName == "" {
-					doctorName = assignment.Doctor.Name
-				}
-				if doctorCode == "" {
-					doctorCode = assignment.Doctor.Code
-				}
-			}
-		}
-		
+// doctorsForBranch lists the doctors working at a branch on a specific date
+func (c *QuotaCalculator) doctorsForBranch(dc *DayContext, branchID uuid.UUID, date time.Time) []DoctorInfo {
+	assigned := dc.DoctorsOn(branchID, date)
+	doctors := make([]DoctorInfo, 0, len(assigned))
+	for _, d := range assigned {
 		doctors = append(doctors, DoctorInfo{
-			ID:   assignment.DoctorID,
-			Name: doctorName,
-			Code: doctorCode,
+			ID:   d.ID,
+			Name: d.Name,
+			Code: d.Code,
 		})
 	}
-
-	return doctors, nil
+	return doctors
 }
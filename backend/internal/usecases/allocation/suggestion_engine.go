@@ -1,10 +1,12 @@
 package allocation
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"time"
 
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
 	"vsq-oper-manpower/backend/internal/domain/models"
 
 	"github.com/google/uuid"
@@ -15,6 +17,7 @@ type SuggestionEngine struct {
 	repos               *RepositoriesWrapper
 	multiCriteriaFilter *MultiCriteriaFilter
 	quotaCalculator     *QuotaCalculator
+	pairingResolver     *PairingResolver
 }
 
 // NewSuggestionEngine creates a new suggestion engine
@@ -23,45 +26,33 @@ func NewSuggestionEngine(repos *RepositoriesWrapper, multiCriteriaFilter *MultiC
 		repos:               repos,
 		multiCriteriaFilter: multiCriteriaFilter,
 		quotaCalculator:     quotaCalculator,
+		pairingResolver:     NewPairingResolver(repos),
 	}
 }
 
 // GenerateSuggestions generates allocation suggestions for branches in a date range
-func (e *SuggestionEngine) GenerateSuggestions(branchIDs []uuid.UUID, startDate, endDate time.Time) ([]*models.AllocationSuggestion, error) {
+func (e *SuggestionEngine) GenerateSuggestions(ctx context.Context, branchIDs []uuid.UUID, startDate, endDate time.Time) ([]*models.AllocationSuggestion, error) {
 	suggestions := []*models.AllocationSuggestion{}
 	currentDate := startDate
 
 	for !currentDate.After(endDate) {
-		// Generate suggestions for each branch on this date
-		for _, branchID := range branchIDs {
-			branchSuggestions, err := e.generateSuggestionsForBranch(branchID, currentDate)
-			if err != nil {
-				return nil, fmt.Errorf("failed to generate suggestions for branch %s on %s: %w", branchID, currentDate.Format("2006-01-02"), err)
-			}
-			suggestions = append(suggestions, branchSuggestions...)
+		// Generate suggestions for all branches on this date at once
+		dateSuggestions, err := e.generateSuggestionsForDate(ctx, branchIDs, currentDate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate suggestions on %s: %w", currentDate.Format("2006-01-02"), err)
 		}
+		suggestions = append(suggestions, dateSuggestions...)
 		currentDate = currentDate.AddDate(0, 0, 1)
 	}
 
 	return suggestions, nil
 }
 
-// generateSuggestionsForBranch generates suggestions for a specific branch on a specific date
-func (e *SuggestionEngine) generateSuggestionsForBranch(branchID uuid.UUID, date time.Time) ([]*models.AllocationSuggestion, error) {
-	// Check if branch is operational (has at least one doctor)
-	// Branch operational status is determined by doctor assignments
-	doctorCount, err := e.repos.DoctorAssignment.GetDoctorCountByBranch(branchID, date)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get doctor count: %w", err)
-	}
-
-	// Skip branches with no doctors (closed branches don't need rotation staff)
-	if doctorCount == 0 {
-		return []*models.AllocationSuggestion{}, nil
-	}
-
+// generateSuggestionsForDate generates suggestions for the given branches on a specific date
+// Closed and doctor-off branches are skipped by the multi-criteria filter
+func (e *SuggestionEngine) generateSuggestionsForDate(ctx context.Context, branchIDs []uuid.UUID, date time.Time) ([]*models.AllocationSuggestion, error) {
 	// Get criteria priority order from settings
-	priorityOrder, enableDoctorPrefs, err := e.getCriteriaPriorityOrder()
+	priorityOrder, enableDoctorPrefs, err := e.getCriteriaPriorityOrder(ctx)
 	if err != nil {
 		// Use defaults if settings not found
 		priorityOrder = DefaultCriteriaPriorityOrder()
@@ -70,7 +61,8 @@ func (e *SuggestionEngine) generateSuggestionsForBranch(branchID uuid.UUID, date
 
 	// Use MultiCriteriaFilter to generate ranked suggestions
 	multiSuggestions, err := e.multiCriteriaFilter.GenerateRankedSuggestions(
-		[]uuid.UUID{branchID},
+		ctx,
+		branchIDs,
 		date,
 		priorityOrder,
 		enableDoctorPrefs,
@@ -79,17 +71,69 @@ func (e *SuggestionEngine) generateSuggestionsForBranch(branchID uuid.UUID, date
 		return nil, fmt.Errorf("failed to generate ranked suggestions: %w", err)
 	}
 
-	// Convert MultiCriteriaFilter suggestions to models.AllocationSuggestion
+	// Doctor-assistant pairings come first: hard pairings place their staff at
+	// the doctor's branch outright, soft ones are preferred when it is staffed
+	pairings, err := e.pairingResolver.Resolve(ctx, branchIDs, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve doctor pairings: %w", err)
+	}
+
 	suggestions := []*models.AllocationSuggestion{}
+	taken := make(map[uuid.UUID]bool)                           // staff suggested or held for a pairing
+	placed := make(map[[2]uuid.UUID]bool)                       // branch and position filled by a hard pairing
+	softPairings := make(map[uuid.UUID][]*models.DoctorPairing) // branchID -> reserved soft pairings
+	for _, p := range pairings {
+		if p.Status != models.PairingReserved {
+			continue
+		}
+		taken[p.StaffID] = true
+		if !p.Hard {
+			softPairings[p.BranchID] = append(softPairings[p.BranchID], p)
+			continue
+		}
+		pairingJSON, _ := json.Marshal(p)
+		suggestions = append(suggestions, &models.AllocationSuggestion{
+			ID:              uuid.New(),
+			RotationStaffID: p.StaffID,
+			BranchID:        p.BranchID,
+			Date:            date,
+			PositionID:      p.PositionID,
+			Status:          models.SuggestionStatusPending,
+			Confidence:      1.0,
+			Reason:          fmt.Sprintf("Hard pairing with %s", p.DoctorName),
+			CriteriaUsed:    string(pairingJSON),
+		})
+		placed[[2]uuid.UUID{p.BranchID, p.PositionID}] = true
+	}
+
+	// Convert MultiCriteriaFilter suggestions to models.AllocationSuggestion
 	for _, ms := range multiSuggestions {
+		if placed[[2]uuid.UUID{ms.BranchID, ms.PositionID}] {
+			continue // Already staffed by a hard pairing
+		}
+
 		// Find eligible rotation staff for this position
-		eligibleStaff, err := e.findEligibleRotationStaff(ms.BranchID, ms.PositionID, date)
+		eligibleStaff, err := e.findEligibleRotationStaff(ctx, ms.BranchID, ms.PositionID, date)
 		if err != nil || len(eligibleStaff) == 0 {
 			continue // Skip if no eligible staff
 		}
 
-		// Use the first eligible staff member
-		staff := eligibleStaff[0]
+		// Prefer staff softly paired with a doctor at this branch, then the first free eligible staff member
+		staff, reason := pickPairedStaff(eligibleStaff, softPairings[ms.BranchID]), ms.Reason
+		if staff != nil {
+			reason = fmt.Sprintf("%s; paired with %s", reason, pairedDoctor(softPairings[ms.BranchID], staff.ID))
+		} else {
+			for _, candidate := range eligibleStaff {
+				if !taken[candidate.ID] {
+					staff = candidate
+					break
+				}
+			}
+		}
+		if staff == nil {
+			continue // Every eligible staff member is held for a pairing or suggested elsewhere
+		}
+		taken[staff.ID] = true
 
 		// Convert criteria breakdown to JSON
 		criteriaJSON, _ := json.Marshal(ms.CriteriaBreakdown)
@@ -102,7 +146,7 @@ func (e *SuggestionEngine) generateSuggestionsForBranch(branchID uuid.UUID, date
 			PositionID:      ms.PositionID,
 			Status:          models.SuggestionStatusPending,
 			Confidence:      ms.PriorityScore,
-			Reason:          ms.Reason,
+			Reason:          reason,
 			CriteriaUsed:    string(criteriaJSON),
 		}
 
@@ -112,16 +156,40 @@ func (e *SuggestionEngine) generateSuggestionsForBranch(branchID uuid.UUID, date
 	return suggestions, nil
 }
 
+// pickPairedStaff returns the first eligible staff member reserved by one of
+// the soft pairings and marks that pairing met, or nil if none is eligible
+func pickPairedStaff(eligibleStaff []*models.Staff, pairings []*models.DoctorPairing) *models.Staff {
+	for _, staff := range eligibleStaff {
+		for _, p := range pairings {
+			if p.StaffID == staff.ID && p.Status == models.PairingReserved {
+				p.Status = models.PairingMet
+				return staff
+			}
+		}
+	}
+	return nil
+}
+
+// pairedDoctor names the doctor a staff member is paired with among pairings
+func pairedDoctor(pairings []*models.DoctorPairing, staffID uuid.UUID) string {
+	for _, p := range pairings {
+		if p.StaffID == staffID {
+			return p.DoctorName
+		}
+	}
+	return ""
+}
+
 // findEligibleRotationStaff finds rotation staff eligible for assignment to a branch for a specific position
-func (e *SuggestionEngine) findEligibleRotationStaff(branchID uuid.UUID, positionID uuid.UUID, date time.Time) ([]*models.Staff, error) {
+func (e *SuggestionEngine) findEligibleRotationStaff(ctx context.Context, branchID uuid.UUID, positionID uuid.UUID, date time.Time) ([]*models.Staff, error) {
 	// Get effective branches for this branch (rotation staff eligible for this branch)
-	effectiveBranches, err := e.repos.EffectiveBranch.GetByBranchID(branchID)
+	effectiveBranches, err := e.repos.EffectiveBranch.GetByBranchID(ctx, branchID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get effective branches: %w", err)
 	}
 
 	// Get all rotation staff
-	allRotationStaff, err := e.repos.Staff.GetRotationStaff()
+	allRotationStaff, err := e.repos.Staff.GetRotationStaff(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get rotation staff: %w", err)
 	}
@@ -144,7 +212,7 @@ func (e *SuggestionEngine) findEligibleRotationStaff(branchID uuid.UUID, positio
 		matchesPosition := staff.PositionID == positionID
 		if !matchesPosition {
 			// Check for staff-to-position mapping
-			mapping, err := e.repos.RotationStaffBranchPosition.GetByStaffAndPosition(staff.ID, positionID)
+			mapping, err := e.repos.RotationStaffBranchPosition.GetByStaffAndPosition(ctx, staff.ID, positionID)
 			if err == nil && mapping != nil && mapping.IsActive {
 				matchesPosition = true
 				// Note: substitution level can be used for priority adjustment later
@@ -155,7 +223,7 @@ func (e *SuggestionEngine) findEligibleRotationStaff(branchID uuid.UUID, positio
 		}
 
 		// Check if staff is available (not already assigned on this date)
-		isAvailable, err := e.isStaffAvailable(staff.ID, date)
+		isAvailable, err := e.isStaffAvailable(ctx, staff.ID, date)
 		if err != nil || !isAvailable {
 			continue
 		}
@@ -170,9 +238,9 @@ func (e *SuggestionEngine) findEligibleRotationStaff(branchID uuid.UUID, positio
 }
 
 // isStaffAvailable checks if rotation staff is available on a specific date
-func (e *SuggestionEngine) isStaffAvailable(staffID uuid.UUID, date time.Time) (bool, error) {
+func (e *SuggestionEngine) isStaffAvailable(ctx context.Context, staffID uuid.UUID, date time.Time) (bool, error) {
 	// Check if staff is already assigned on this date
-	assignments, err := e.repos.Rotation.GetByRotationStaffID(staffID, date, date)
+	assignments, err := e.repos.Rotation.GetByRotationStaffID(ctx, staffID, date, date)
 	if err != nil {
 		return false, err
 	}
@@ -189,9 +257,9 @@ func (e *SuggestionEngine) isStaffAvailable(staffID uuid.UUID, date time.Time) (
 }
 
 // getCriteriaPriorityOrder retrieves criteria priority order from settings
-func (e *SuggestionEngine) getCriteriaPriorityOrder() (CriteriaPriorityOrder, bool, error) {
+func (e *SuggestionEngine) getCriteriaPriorityOrder(ctx context.Context) (CriteriaPriorityOrder, bool, error) {
 	// Get priority order setting
-	priorityOrderSetting, err := e.repos.Settings.GetByKey("allocation_criteria_priority_order")
+	priorityOrderSetting, err := e.repos.Settings.GetByKey(ctx, "allocation_criteria_priority_order")
 	if err != nil || priorityOrderSetting == nil {
 		return CriteriaPriorityOrder{}, false, fmt.Errorf("priority order setting not found")
 	}
@@ -207,7 +275,7 @@ func (e *SuggestionEngine) getCriteriaPriorityOrder() (CriteriaPriorityOrder, bo
 	}
 
 	// Get doctor preferences setting
-	doctorPrefSetting, _ := e.repos.Settings.GetByKey("allocation_enable_doctor_preferences")
+	doctorPrefSetting, _ := e.repos.Settings.GetByKey(ctx, "allocation_enable_doctor_preferences")
 	enableDoctorPrefs := false
 	if doctorPrefSetting != nil && doctorPrefSetting.Value == "true" {
 		enableDoctorPrefs = true
@@ -216,51 +284,55 @@ func (e *SuggestionEngine) getCriteriaPriorityOrder() (CriteriaPriorityOrder, bo
 	return priorityOrder, enableDoctorPrefs, nil
 }
 
-// ApproveSuggestion approves a suggestion and creates a rotation assignment
-func (e *SuggestionEngine) ApproveSuggestion(suggestionID uuid.UUID, userID uuid.UUID) error {
-	suggestion, err := e.repos.AllocationSuggestion.GetByID(suggestionID)
-	if err != nil {
-		return fmt.Errorf("failed to get suggestion: %w", err)
-	}
-	if suggestion == nil {
-		return fmt.Errorf("suggestion not found")
-	}
+// ApproveSuggestion approves a suggestion and creates a rotation assignment.
+// Both writes happen in one transaction so a failed status update cannot leave
+// an assignment behind for a suggestion that still looks pending.
+func (e *SuggestionEngine) ApproveSuggestion(ctx context.Context, suggestionID uuid.UUID, userID uuid.UUID) error {
+	return e.repos.UnitOfWork.Do(ctx, func(tx *interfaces.Repositories) error {
+		suggestion, err := tx.AllocationSuggestion.GetByID(ctx, suggestionID)
+		if err != nil {
+			return fmt.Errorf("failed to get suggestion: %w", err)
+		}
+		if suggestion == nil {
+			return fmt.Errorf("suggestion not found")
+		}
 
-	if suggestion.Status != models.SuggestionStatusPending {
-		return fmt.Errorf("suggestion is not pending")
-	}
+		if suggestion.Status != models.SuggestionStatusPending {
+			return fmt.Errorf("suggestion is not pending")
+		}
 
-	// Create rotation assignment
-	assignment := &models.RotationAssignment{
-		ID:              uuid.New(),
-		RotationStaffID: suggestion.RotationStaffID,
-		BranchID:        suggestion.BranchID,
-		Date:            suggestion.Date,
-		AssignmentLevel: 1, // Default to Level 1, can be made configurable
-		IsAdhoc:         false,
-		AssignedBy:      userID,
-	}
+		// Create rotation assignment
+		assignment := &models.RotationAssignment{
+			ID:              uuid.New(),
+			RotationStaffID: suggestion.RotationStaffID,
+			BranchID:        suggestion.BranchID,
+			Date:            suggestion.Date,
+			AssignmentLevel: 1, // Default to Level 1, can be made configurable
+			IsAdhoc:         false,
+			AssignedBy:      userID,
+		}
 
-	if err := e.repos.Rotation.Create(assignment); err != nil {
-		return fmt.Errorf("failed to create rotation assignment: %w", err)
-	}
+		if err := tx.Rotation.Create(ctx, assignment); err != nil {
+			return fmt.Errorf("failed to create rotation assignment: %w", err)
+		}
 
-	// Update suggestion status
-	suggestion.Status = models.SuggestionStatusApproved
-	suggestion.ReviewedBy = &userID
-	now := time.Now()
-	suggestion.ReviewedAt = &now
+		// Update suggestion status
+		suggestion.Status = models.SuggestionStatusApproved
+		suggestion.ReviewedBy = &userID
+		now := time.Now()
+		suggestion.ReviewedAt = &now
 
-	if err := e.repos.AllocationSuggestion.Update(suggestion); err != nil {
-		return fmt.Errorf("failed to update suggestion: %w", err)
-	}
+		if err := tx.AllocationSuggestion.Update(ctx, suggestion); err != nil {
+			return fmt.Errorf("failed to update suggestion: %w", err)
+		}
 
-	return nil
+		return nil
+	})
 }
 
 // RejectSuggestion rejects a suggestion
-func (e *SuggestionEngine) RejectSuggestion(suggestionID uuid.UUID, userID uuid.UUID) error {
-	suggestion, err := e.repos.AllocationSuggestion.GetByID(suggestionID)
+func (e *SuggestionEngine) RejectSuggestion(ctx context.Context, suggestionID uuid.UUID, userID uuid.UUID) error {
+	suggestion, err := e.repos.AllocationSuggestion.GetByID(ctx, suggestionID)
 	if err != nil {
 		return fmt.Errorf("failed to get suggestion: %w", err)
 	}
@@ -277,7 +349,7 @@ func (e *SuggestionEngine) RejectSuggestion(suggestionID uuid.UUID, userID uuid.
 	now := time.Now()
 	suggestion.ReviewedAt = &now
 
-	if err := e.repos.AllocationSuggestion.Update(suggestion); err != nil {
+	if err := e.repos.AllocationSuggestion.Update(ctx, suggestion); err != nil {
 		return fmt.Errorf("failed to update suggestion: %w", err)
 	}
 
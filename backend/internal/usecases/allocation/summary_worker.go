@@ -0,0 +1,244 @@
+package allocation
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"vsq-oper-manpower/backend/internal/domain/events"
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+)
+
+// SummaryWorker keeps branch_quota_daily_summary current. Database triggers and
+// change events queue the affected branch and date in quota_summary_dirty; the
+// worker recomputes queued summaries with the QuotaCalculator once they have
+// been quiet for the debounce period, so a bulk import costs one recomputation
+// per branch and day rather than one per row written.
+type SummaryWorker struct {
+	repos      *RepositoriesWrapper
+	calculator *QuotaCalculator
+	interval   time.Duration // How often the queue is polled
+	debounce   time.Duration // How long a key must be unchanged before it is recomputed
+	maxWait    time.Duration // Recompute anyway once a key has been queued this long
+	batchSize  int           // Keys recomputed per poll
+	bus        *events.Bus   // Told about recomputed summaries; nil drops them
+
+	mu    sync.Mutex
+	stale map[staleKey]bool // Summaries events asked to queue, not yet marked
+	wake  chan struct{}     // Tells Run that stale has new keys
+}
+
+// staleKey is a branch and date to mark stale. A nil branch or zero date widens
+// it like the event it came from.
+type staleKey struct {
+	branchID uuid.UUID
+	date     time.Time
+}
+
+// NewSummaryWorker creates a summary worker with the default schedule
+func NewSummaryWorker(repos *RepositoriesWrapper, calculator *QuotaCalculator) *SummaryWorker {
+	return &SummaryWorker{
+		repos:      repos,
+		calculator: calculator,
+		interval:   2 * time.Second,
+		debounce:   2 * time.Second,
+		maxWait:    30 * time.Second,
+		batchSize:  500,
+		stale:      make(map[staleKey]bool),
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// WithSchedule sets how often the queue is polled, how long a key must be quiet
+// before it is recomputed and how long continuous changes may defer it
+func (w *SummaryWorker) WithSchedule(interval, debounce, maxWait time.Duration) *SummaryWorker {
+	w.interval = interval
+	w.debounce = debounce
+	w.maxWait = maxWait
+	return w
+}
+
+// WithBatchSize bounds how many summaries one poll recomputes
+func (w *SummaryWorker) WithBatchSize(batchSize int) *SummaryWorker {
+	w.batchSize = batchSize
+	return w
+}
+
+// WithEvents publishes a QuotaStatusChanged event for every summary the worker
+// saves, so clients can refresh without polling
+func (w *SummaryWorker) WithEvents(bus *events.Bus) *SummaryWorker {
+	w.bus = bus
+	return w
+}
+
+// HandleEvent queues the existing summaries affected by changes the database
+// triggers cannot see: doctor schedules, the doctor roster, branch day status
+// and staffing constraints. Schedule, rotation and quota writes are queued by
+// their triggers.
+func (w *SummaryWorker) HandleEvent(ctx context.Context, e events.Event) {
+	switch e.Kind {
+	case events.DoctorOverrideChanged, events.DoctorRosterChanged, events.BranchDayChanged, events.ConstraintsChanged:
+	default:
+		return
+	}
+	// Handlers must not block the publisher, so the key is only noted here and
+	// marked by the Run loop; repeated events for a key are marked once
+	key := staleKey{branchID: e.BranchID}
+	if !e.Date.IsZero() {
+		key.date = time.Date(e.Date.Year(), e.Date.Month(), e.Date.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	w.mu.Lock()
+	w.stale[key] = true
+	w.mu.Unlock()
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// markStale queues the summaries noted by HandleEvent. Keys that fail to be
+// marked are kept for the next attempt.
+func (w *SummaryWorker) markStale(ctx context.Context) error {
+	w.mu.Lock()
+	keys := w.stale
+	w.stale = make(map[staleKey]bool)
+	w.mu.Unlock()
+
+	var firstErr error
+	for key := range keys {
+		if err := w.repos.BranchQuotaSummary.MarkStale(ctx, key.branchID, key.date); err != nil {
+			w.mu.Lock()
+			w.stale[key] = true
+			w.mu.Unlock()
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// Run polls the queue until ctx is cancelled. Summaries events asked to queue
+// are marked before it returns, so stopping the worker does not lose them.
+func (w *SummaryWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	defer func() {
+		if err := w.markStale(context.WithoutCancel(ctx)); err != nil {
+			slog.ErrorContext(ctx, "Failed to queue quota summaries", "error", err)
+		}
+	}()
+	for {
+		// Drain full batches before waiting for the next tick
+		for {
+			n, err := w.RunOnce(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.ErrorContext(ctx, "Failed to recompute quota summaries", "error", err)
+				}
+				break
+			}
+			if n < w.batchSize {
+				break
+			}
+		}
+
+		// Events are marked as they arrive, so reads skip the stale summaries
+		// without waiting for the next poll
+		for waiting := true; waiting; {
+			select {
+			case <-ctx.Done():
+				return
+			case <-w.wake:
+				if err := w.markStale(ctx); err != nil && ctx.Err() == nil {
+					slog.ErrorContext(ctx, "Failed to queue quota summaries", "error", err)
+				}
+			case <-ticker.C:
+				waiting = false
+			}
+		}
+	}
+}
+
+// RunOnce recomputes one batch of queued summaries and returns how many it wrote
+func (w *SummaryWorker) RunOnce(ctx context.Context) (int, error) {
+	if err := w.markStale(ctx); err != nil {
+		return 0, fmt.Errorf("failed to queue quota summaries: %w", err)
+	}
+	keys, err := w.repos.BranchQuotaSummary.ListDirty(ctx, w.debounce, w.maxWait, w.batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list dirty quota summaries: %w", err)
+	}
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	// One context per date covers every queued branch on it
+	byDate := make(map[string][]*models.QuotaSummaryDirtyKey)
+	dates := []string{}
+	for _, key := range keys {
+		k := dateKey(key.Date)
+		if _, ok := byDate[k]; !ok {
+			dates = append(dates, k)
+		}
+		byDate[k] = append(byDate[k], key)
+	}
+	sort.Strings(dates)
+
+	summaries := make([]*models.BranchQuotaSummary, 0, len(keys))
+	for _, k := range dates {
+		dateKeys := byDate[k]
+		date := dateKeys[0].Date
+		branchIDs := make([]uuid.UUID, len(dateKeys))
+		for i, key := range dateKeys {
+			branchIDs[i] = key.BranchID
+		}
+		dc, err := LoadDayContext(ctx, w.repos, branchIDs, date, date)
+		if err != nil {
+			return 0, err
+		}
+		for _, branchID := range branchIDs {
+			// A branch deleted since it was queued takes its queue row with it
+			if dc.Branch(branchID) == nil {
+				continue
+			}
+			summary, err := w.calculator.SummaryFromContext(dc, branchID, date)
+			if err != nil {
+				return 0, fmt.Errorf("failed to calculate quota summary for branch %s: %w", branchID, err)
+			}
+			summaries = append(summaries, summary)
+		}
+	}
+
+	// Keys marked again while this batch was computed stay queued for the next one
+	save := func(repo interfaces.BranchQuotaSummaryRepository) error {
+		for _, summary := range summaries {
+			if err := repo.Upsert(ctx, summary); err != nil {
+				return fmt.Errorf("failed to save quota summary: %w", err)
+			}
+		}
+		return repo.ClearDirty(ctx, keys)
+	}
+	if w.repos.UnitOfWork == nil {
+		err = save(w.repos.BranchQuotaSummary)
+	} else {
+		err = w.repos.UnitOfWork.Do(ctx, func(tx *interfaces.Repositories) error {
+			return save(tx.BranchQuotaSummary)
+		})
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	changed := make([]events.Event, len(summaries))
+	for i, summary := range summaries {
+		changed[i] = events.Event{Kind: events.QuotaStatusChanged, BranchID: summary.BranchID, Date: summary.Date}
+	}
+	w.bus.Publish(ctx, changed...)
+	return len(summaries), nil
+}
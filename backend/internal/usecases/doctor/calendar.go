@@ -0,0 +1,148 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"vsq-oper-manpower/backend/internal/domain/events"
+	"vsq-oper-manpower/backend/internal/domain/models"
+	"vsq-oper-manpower/backend/pkg/lru"
+)
+
+// DefaultCalendarMonths is how many months of calculated assignments a
+// calendar keeps when no size is configured
+const DefaultCalendarMonths = 24
+
+// DoctorCalendar serves calculated doctor assignments a month at a time. The
+// first read of a month loads the default schedules, weekly off days,
+// overrides, leave and revenue once and indexes the month's assignments by
+// branch and date; later reads of the month only look them up. Subscribed to
+// the bus, it drops a month as soon as a doctor schedule write touches it.
+type DoctorCalendar struct {
+	calculator *DoctorScheduleCalculator
+	months     *lru.Cache[string, *calendarMonth] // keyed by "2006-01"
+	generation atomic.Uint64                      // bumped by every eviction, so a load racing a write is not cached
+}
+
+// calendarMonth is a month of calculated assignments, in calculator order:
+// by date, doctor and slot
+type calendarMonth struct {
+	byDate       map[string][]*CalculatedAssignment
+	byBranchDate map[uuid.UUID]map[string][]*CalculatedAssignment
+}
+
+// NewDoctorCalendar creates a calendar keeping at most months months, none
+// older than ttl (0 keeps them until evicted), calculated by calculator.
+// months <= 0 uses DefaultCalendarMonths.
+func NewDoctorCalendar(calculator *DoctorScheduleCalculator, months int, ttl time.Duration) *DoctorCalendar {
+	if months <= 0 {
+		months = DefaultCalendarMonths
+	}
+	return &DoctorCalendar{
+		calculator: calculator,
+		months:     lru.New[string, *calendarMonth](months, ttl),
+	}
+}
+
+// Assignments returns the assignments between startDate and endDate that
+// filter matches, as models of the doctors they belong to. Doctors are read
+// afresh, so renamed and removed doctors show at once.
+func (c *DoctorCalendar) Assignments(ctx context.Context, startDate, endDate time.Time, filter RosterFilter) ([]*models.DoctorAssignment, error) {
+	doctors, err := c.calculator.doctorRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get doctors: %w", err)
+	}
+	byID := make(map[uuid.UUID]*models.Doctor, len(doctors))
+	for _, doctor := range doctors {
+		byID[doctor.ID] = doctor
+	}
+
+	assignments := []*models.DoctorAssignment{}
+	err = c.each(ctx, startDate, endDate, filter.BranchID, func(assignment *CalculatedAssignment) {
+		doctor, ok := byID[assignment.DoctorID]
+		if ok && filter.Matches(assignment.DoctorID, assignment.BranchID) {
+			assignments = append(assignments, assignment.Model(doctor))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return assignments, nil
+}
+
+// each calls fn for every assignment between startDate and endDate, at
+// branchID only when it is set, in date order
+func (c *DoctorCalendar) each(ctx context.Context, startDate, endDate time.Time, branchID *uuid.UUID, fn func(*CalculatedAssignment)) error {
+	var month *calendarMonth
+	for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 1) {
+		if month == nil || date.Day() == 1 {
+			var err error
+			if month, err = c.month(ctx, date); err != nil {
+				return err
+			}
+		}
+		day := month.byDate
+		if branchID != nil {
+			day = month.byBranchDate[*branchID]
+		}
+		for _, assignment := range day[date.Format("2006-01-02")] {
+			fn(assignment)
+		}
+	}
+	return nil
+}
+
+// month returns the month date falls in, calculating it on a miss
+func (c *DoctorCalendar) month(ctx context.Context, date time.Time) (*calendarMonth, error) {
+	key := date.Format("2006-01")
+	if month, ok := c.months.Get(key); ok {
+		return month, nil
+	}
+
+	generation := c.generation.Load()
+	start := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	calculated, err := c.calculator.CalculateDetailedAssignmentsForDateRange(ctx, start, start.AddDate(0, 1, -1))
+	if err != nil {
+		return nil, err
+	}
+	month := &calendarMonth{
+		byDate:       make(map[string][]*CalculatedAssignment),
+		byBranchDate: make(map[uuid.UUID]map[string][]*CalculatedAssignment),
+	}
+	for _, assignment := range calculated {
+		day := assignment.Date.Format("2006-01-02")
+		month.byDate[day] = append(month.byDate[day], assignment)
+		if month.byBranchDate[assignment.BranchID] == nil {
+			month.byBranchDate[assignment.BranchID] = make(map[string][]*CalculatedAssignment)
+		}
+		month.byBranchDate[assignment.BranchID][day] = append(month.byBranchDate[assignment.BranchID][day], assignment)
+	}
+	// A write while the month was calculated may not be in it; use it for
+	// this read only
+	if c.generation.Load() == generation {
+		c.months.Put(key, month)
+	}
+	return month, nil
+}
+
+// HandleEvent drops the month a doctor schedule change falls in, or every
+// month when the change has no date, such as a weekly schedule
+func (c *DoctorCalendar) HandleEvent(ctx context.Context, e events.Event) {
+	if e.Kind != events.DoctorOverrideChanged {
+		return
+	}
+	c.generation.Add(1)
+	if e.Date.IsZero() {
+		c.months.Purge()
+		return
+	}
+	c.months.Remove(e.Date.Format("2006-01"))
+}
+
+// CacheStats returns the month cache counters
+func (c *DoctorCalendar) CacheStats() lru.Stats {
+	return c.months.Stats()
+}
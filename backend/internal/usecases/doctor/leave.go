@@ -0,0 +1,54 @@
+package doctor
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"vsq-oper-manpower/backend/internal/domain/models"
+)
+
+// LeaveImpact works out what leave takes the doctor away from: each branch
+// and slot the doctor was due to work on a date of the leave, with the number
+// of other doctors still working at the branch that day. The leave counts
+// whatever its status, so a pending leave shows what approving it would do.
+// Other doctors' approved leave and the substitutes already assigned are taken
+// into account, and dates a schedule override keeps the doctor working are
+// not affected.
+func (c *DoctorScheduleCalculator) LeaveImpact(ctx context.Context, leave *models.DoctorLeave) ([]*models.DoctorLeaveImpact, error) {
+	planned := *c
+	planned.ignoreLeave = leave.ID
+	planned.revenueProfileRepo, planned.revenueOverrideRepo = nil, nil
+	assignments, err := planned.CalculateDetailedAssignmentsForDateRange(ctx, leave.StartDate, leave.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	type branchDay struct {
+		branchID uuid.UUID
+		date     string
+	}
+	working := make(map[branchDay]map[uuid.UUID]bool)
+	for _, assignment := range assignments {
+		key := branchDay{assignment.BranchID, assignment.Date.Format("2006-01-02")}
+		if working[key] == nil {
+			working[key] = make(map[uuid.UUID]bool)
+		}
+		working[key][assignment.DoctorID] = true
+	}
+
+	impacts := []*models.DoctorLeaveImpact{}
+	for _, assignment := range assignments {
+		if assignment.DoctorID != leave.DoctorID || assignment.Source == "override" {
+			continue
+		}
+		others := len(working[branchDay{assignment.BranchID, assignment.Date.Format("2006-01-02")}]) - 1
+		impacts = append(impacts, &models.DoctorLeaveImpact{
+			Date:         assignment.Date,
+			BranchID:     assignment.BranchID,
+			Slot:         assignment.Slot,
+			OtherDoctors: others,
+			DoctorLess:   others == 0,
+		})
+	}
+	return impacts, nil
+}
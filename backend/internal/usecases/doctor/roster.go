@@ -0,0 +1,402 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"vsq-oper-manpower/backend/internal/domain/events"
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+	"vsq-oper-manpower/backend/pkg/timezone"
+)
+
+// DefaultRosterHorizon is how many days ahead, including today, the roster is
+// materialized when no horizon is configured
+const DefaultRosterHorizon = 90
+
+// RosterFilter narrows roster reads to one doctor and/or branch; nil fields match all
+type RosterFilter struct {
+	DoctorID *uuid.UUID
+	BranchID *uuid.UUID
+}
+
+// Matches reports whether an assignment of doctorID at branchID passes the filter
+func (f RosterFilter) Matches(doctorID, branchID uuid.UUID) bool {
+	if f.DoctorID != nil && *f.DoctorID != doctorID {
+		return false
+	}
+	return f.BranchID == nil || *f.BranchID == branchID
+}
+
+// CountDoctors counts the doctors in assignments; a doctor working both
+// halves of a day counts once
+func CountDoctors(assignments []*models.DoctorAssignment) int {
+	doctors := make(map[uuid.UUID]bool, len(assignments))
+	for _, assignment := range assignments {
+		doctors[assignment.DoctorID] = true
+	}
+	return len(doctors)
+}
+
+// RosterSegment is a run of consecutive dates that are either read from the
+// materialized roster or calculated from the doctors' schedules
+type RosterSegment struct {
+	StartDate    time.Time
+	EndDate      time.Time
+	Materialized bool
+}
+
+// SplitRosterRange splits startDate..endDate into the segments before, inside
+// and after window, in date order and omitting empty ones. With no window
+// every date is calculated.
+func SplitRosterRange(window *models.DoctorRosterWindow, startDate, endDate time.Time) []RosterSegment {
+	if endDate.Before(startDate) {
+		return nil
+	}
+	if window == nil || endDate.Before(window.StartDate) || startDate.After(window.EndDate) {
+		return []RosterSegment{{StartDate: startDate, EndDate: endDate}}
+	}
+	var segments []RosterSegment
+	inside := RosterSegment{StartDate: startDate, EndDate: endDate, Materialized: true}
+	if startDate.Before(window.StartDate) {
+		segments = append(segments, RosterSegment{StartDate: startDate, EndDate: window.StartDate.AddDate(0, 0, -1)})
+		inside.StartDate = window.StartDate
+	}
+	if endDate.After(window.EndDate) {
+		inside.EndDate = window.EndDate
+	}
+	segments = append(segments, inside)
+	if endDate.After(window.EndDate) {
+		segments = append(segments, RosterSegment{StartDate: window.EndDate.AddDate(0, 0, 1), EndDate: endDate})
+	}
+	return segments
+}
+
+// RosterMaterializer writes the assignments calculated from the doctors'
+// default schedules, weekly off days and overrides into the roster, so every
+// assignment keeps its ID between reads, and records what each run changed.
+// Runs are serialized.
+type RosterMaterializer struct {
+	uow     interfaces.UnitOfWork
+	horizon int         // Days materialized from today, including today
+	bus     *events.Bus // Told about changed branches and dates; nil drops them
+	mu      sync.Mutex  // Held for the length of a run
+
+	pendingMu  sync.Mutex
+	pending    *pendingRefresh // Changes seen since the last refresh started
+	refreshing bool
+}
+
+// pendingRefresh is the union of the dates changed by queued events
+type pendingRefresh struct {
+	all        bool // a weekly schedule or profile changed, affecting every date
+	start, end time.Time
+}
+
+// NewRosterMaterializer creates a materializer keeping horizonDays days from
+// today materialized; horizonDays <= 0 uses DefaultRosterHorizon
+func NewRosterMaterializer(uow interfaces.UnitOfWork, horizonDays int) *RosterMaterializer {
+	if horizonDays <= 0 {
+		horizonDays = DefaultRosterHorizon
+	}
+	return &RosterMaterializer{uow: uow, horizon: horizonDays}
+}
+
+// WithEvents publishes a DoctorRosterChanged event for every branch and date a
+// run changes
+func (m *RosterMaterializer) WithEvents(bus *events.Bus) *RosterMaterializer {
+	m.bus = bus
+	return m
+}
+
+// MaterializeHorizon materializes the horizon from today. If the last run
+// ended before yesterday the run starts the day after it, so the window has
+// no gap of unmaterialized dates.
+func (m *RosterMaterializer) MaterializeHorizon(ctx context.Context, trigger string) (*models.DoctorRosterRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	window, err := m.window(ctx)
+	if err != nil {
+		return nil, err
+	}
+	start := rosterToday()
+	end := start.AddDate(0, 0, m.horizon-1)
+	if window != nil && window.EndDate.Before(start.AddDate(0, 0, -1)) {
+		start = window.EndDate.AddDate(0, 0, 1)
+	}
+	return m.materialize(ctx, start, end, trigger)
+}
+
+// Materialize rewrites the roster between startDate and endDate and extends
+// the window to cover them
+func (m *RosterMaterializer) Materialize(ctx context.Context, startDate, endDate time.Time, trigger string) (*models.DoctorRosterRun, error) {
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("end date %s is before start date %s",
+			endDate.Format("2006-01-02"), startDate.Format("2006-01-02"))
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.materialize(ctx, startDate, endDate, trigger)
+}
+
+// Refresh rewrites the dates between startDate and endDate that are already
+// materialized, after the schedules behind them changed. It never extends the
+// window and returns a nil run when no date in the range is materialized or
+// nothing changed.
+func (m *RosterMaterializer) Refresh(ctx context.Context, startDate, endDate time.Time) (*models.DoctorRosterRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	window, err := m.window(ctx)
+	if err != nil || window == nil {
+		return nil, err
+	}
+	if startDate.Before(window.StartDate) {
+		startDate = window.StartDate
+	}
+	if endDate.After(window.EndDate) {
+		endDate = window.EndDate
+	}
+	if endDate.Before(startDate) {
+		return nil, nil
+	}
+	return m.materialize(ctx, startDate, endDate, models.RosterTriggerChange)
+}
+
+// HandleEvent refreshes the roster after a doctor's schedule, override or
+// revenue changed. Events arriving while a refresh runs are merged into the
+// next one. A change to a weekly schedule refreshes today onwards; dates
+// already past keep the roster they had.
+func (m *RosterMaterializer) HandleEvent(ctx context.Context, e events.Event) {
+	if e.Kind != events.DoctorOverrideChanged {
+		return
+	}
+	m.pendingMu.Lock()
+	if m.pending == nil {
+		m.pending = &pendingRefresh{start: e.Date, end: e.Date, all: e.Date.IsZero()}
+	} else if e.Date.IsZero() {
+		m.pending.all = true
+	} else {
+		if m.pending.start.IsZero() || e.Date.Before(m.pending.start) {
+			m.pending.start = e.Date
+		}
+		if e.Date.After(m.pending.end) {
+			m.pending.end = e.Date
+		}
+	}
+	if m.refreshing {
+		m.pendingMu.Unlock()
+		return
+	}
+	m.refreshing = true
+	m.pendingMu.Unlock()
+
+	// Handlers must not block the publisher; detached so the refresh outlives the request
+	go m.drain(context.WithoutCancel(ctx))
+}
+
+// drain refreshes queued changes until none are left
+func (m *RosterMaterializer) drain(ctx context.Context) {
+	for {
+		m.pendingMu.Lock()
+		pending := m.pending
+		m.pending = nil
+		if pending == nil {
+			m.refreshing = false
+		}
+		m.pendingMu.Unlock()
+		if pending == nil {
+			return
+		}
+
+		start, end := pending.start, pending.end
+		if pending.all {
+			today := rosterToday()
+			if start.IsZero() || start.After(today) {
+				start = today
+			}
+			end = today.AddDate(0, 0, m.horizon-1)
+		}
+		if _, err := m.Refresh(ctx, start, end); err != nil {
+			slog.ErrorContext(ctx, "Failed to refresh the doctor roster", "start", start.Format("2006-01-02"), "end", end.Format("2006-01-02"), "error", err)
+		}
+	}
+}
+
+func (m *RosterMaterializer) window(ctx context.Context) (*models.DoctorRosterWindow, error) {
+	var window *models.DoctorRosterWindow
+	err := m.uow.Do(ctx, func(tx *interfaces.Repositories) error {
+		var err error
+		window, err = tx.DoctorRoster.Window(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get roster window: %w", err)
+	}
+	return window, nil
+}
+
+// rosterKey identifies a doctor's assignment in a slot of a date
+type rosterKey struct {
+	doctorID uuid.UUID
+	date     string
+	slot     string
+}
+
+func keyOf(a *models.DoctorAssignment) rosterKey {
+	return rosterKey{doctorID: a.DoctorID, date: a.Date.Format("2006-01-02"), slot: models.DoctorSlotOrFullDay(a.Slot)}
+}
+
+// materialize diffs the calculated assignments against the stored roster in
+// one transaction. The caller holds m.mu.
+func (m *RosterMaterializer) materialize(ctx context.Context, startDate, endDate time.Time, trigger string) (*models.DoctorRosterRun, error) {
+	run := &models.DoctorRosterRun{StartDate: startDate, EndDate: endDate, Trigger: trigger}
+	var changed []events.Event
+
+	err := m.uow.Do(ctx, func(tx *interfaces.Repositories) error {
+		calculated, err := NewDoctorScheduleCalculator(
+			tx.DoctorDefaultSchedule,
+			tx.DoctorWeeklyOffDay,
+			tx.DoctorScheduleOverride,
+			tx.Doctor,
+		).WithRevenue(tx.DoctorRevenueProfile, tx.DoctorRevenueOverride).
+			WithLeaves(tx.DoctorLeave).
+			CalculateDetailedAssignmentsForDateRange(ctx, startDate, endDate)
+		if err != nil {
+			return err
+		}
+		stored, err := tx.DoctorAssignment.GetRosterByDateRange(ctx, startDate, endDate)
+		if err != nil {
+			return fmt.Errorf("failed to get roster: %w", err)
+		}
+		existing := make(map[rosterKey]*models.DoctorAssignment, len(stored))
+		for _, row := range stored {
+			existing[keyOf(row)] = row
+		}
+
+		var changes []*models.DoctorRosterChange
+		for _, assignment := range calculated {
+			next := assignment.Model(&models.Doctor{ID: assignment.DoctorID})
+			key := keyOf(next)
+			row, ok := existing[key]
+			delete(existing, key)
+
+			switch {
+			case !ok:
+				if err := tx.DoctorAssignment.Create(ctx, next); err != nil {
+					return fmt.Errorf("failed to add roster assignment: %w", err)
+				}
+				run.Added++
+				changes = append(changes, &models.DoctorRosterChange{
+					DoctorID: next.DoctorID, Date: next.Date, ChangeType: models.RosterChangeAdded,
+					ToBranchID: &next.BranchID,
+				})
+				changed = append(changed, rosterEvent(next.BranchID, next.Date))
+			case row.BranchID != next.BranchID:
+				next.ID = row.ID
+				if err := tx.DoctorAssignment.Update(ctx, next); err != nil {
+					return fmt.Errorf("failed to move roster assignment: %w", err)
+				}
+				run.Moved++
+				from := row.BranchID
+				changes = append(changes, &models.DoctorRosterChange{
+					DoctorID: next.DoctorID, Date: next.Date, ChangeType: models.RosterChangeMoved,
+					FromBranchID: &from, ToBranchID: &next.BranchID,
+				})
+				changed = append(changed, rosterEvent(from, next.Date), rosterEvent(next.BranchID, next.Date))
+			case !sameRosterDetails(row, next):
+				next.ID = row.ID
+				if err := tx.DoctorAssignment.Update(ctx, next); err != nil {
+					return fmt.Errorf("failed to update roster assignment: %w", err)
+				}
+				run.Updated++
+				changed = append(changed, rosterEvent(next.BranchID, next.Date))
+			}
+		}
+
+		// Stored assignments no longer calculated: the doctor is now off
+		for _, row := range stored {
+			if _, ok := existing[keyOf(row)]; !ok {
+				continue
+			}
+			if err := tx.DoctorAssignment.Delete(ctx, row.ID); err != nil {
+				return fmt.Errorf("failed to remove roster assignment: %w", err)
+			}
+			run.Removed++
+			from := row.BranchID
+			changes = append(changes, &models.DoctorRosterChange{
+				DoctorID: row.DoctorID, Date: row.Date, ChangeType: models.RosterChangeRemoved,
+				FromBranchID: &from,
+			})
+			changed = append(changed, rosterEvent(from, row.Date))
+		}
+
+		// Refreshes that change nothing would only clutter the history; they
+		// never extend the window, so there is nothing to record
+		if trigger == models.RosterTriggerChange && len(changed) == 0 {
+			run = nil
+			return nil
+		}
+		if err := tx.DoctorRoster.CreateRun(ctx, run); err != nil {
+			return fmt.Errorf("failed to record roster run: %w", err)
+		}
+		for _, change := range changes {
+			change.RunID = run.ID
+			if err := tx.DoctorRoster.CreateChange(ctx, change); err != nil {
+				return fmt.Errorf("failed to record roster change: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.bus.Publish(ctx, dedupeEvents(changed)...)
+	return run, nil
+}
+
+func rosterEvent(branchID uuid.UUID, date time.Time) events.Event {
+	return events.Event{Kind: events.DoctorRosterChanged, BranchID: branchID, Date: date}
+}
+
+func dedupeEvents(evs []events.Event) []events.Event {
+	type key struct {
+		branchID uuid.UUID
+		date     string
+	}
+	seen := make(map[key]bool, len(evs))
+	out := evs[:0]
+	for _, e := range evs {
+		k := key{branchID: e.BranchID, date: e.Date.Format("2006-01-02")}
+		if !seen[k] {
+			seen[k] = true
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// sameRosterDetails reports whether a stored assignment already has the
+// source and revenue of the freshly calculated one
+func sameRosterDetails(stored, next *models.DoctorAssignment) bool {
+	if stored.Source != next.Source || stored.RevenueSource != next.RevenueSource ||
+		stored.ExpectedRevenue != next.ExpectedRevenue {
+		return false
+	}
+	if stored.Revenue == nil || next.Revenue == nil {
+		return stored.Revenue == nil && next.Revenue == nil
+	}
+	return *stored.Revenue == *next.Revenue
+}
+
+// rosterToday is today's date in Thailand, as a UTC midnight like stored dates
+func rosterToday() time.Time {
+	now := timezone.GetThailandTime()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
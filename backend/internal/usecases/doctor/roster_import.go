@@ -0,0 +1,155 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"vsq-oper-manpower/backend/internal/domain/models"
+)
+
+// RosterDay is a doctor's day as an imported roster has it: off, or working
+// at BranchIDs, one per slot worked, morning first
+type RosterDay struct {
+	DoctorID  uuid.UUID
+	Date      time.Time
+	Off       bool
+	BranchIDs []uuid.UUID
+}
+
+// DiffRoster works out the schedule overrides that make the doctors' days
+// what the imported roster says, against the schedule calculated from default
+// schedules, weekly off days and leave alone. A day the schedule already has
+// needs no override, and any override left on it is cleared; any other day
+// gets a working or off override, unless the same override exists already.
+// An override covers the whole day, so a day split between branches that the
+// schedule does not already have cannot be imported and is returned in
+// unmatched instead.
+func (c *DoctorScheduleCalculator) DiffRoster(ctx context.Context, days []*RosterDay) (changes []*models.DoctorRosterImportChange, unmatched []*RosterDay, err error) {
+	changes = []*models.DoctorRosterImportChange{}
+	if len(days) == 0 {
+		return changes, nil, nil
+	}
+	startDate, endDate := days[0].Date, days[0].Date
+	for _, day := range days {
+		if day.Date.Before(startDate) {
+			startDate = day.Date
+		}
+		if day.Date.After(endDate) {
+			endDate = day.Date
+		}
+	}
+
+	branches, err := c.scheduledBranches(ctx, startDate, endDate)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	existing, err := c.overrideRepo.GetByDateRange(ctx, startDate, endDate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get overrides: %w", err)
+	}
+	overrides := make(map[doctorDay]*models.DoctorScheduleOverride, len(existing))
+	for _, override := range existing {
+		overrides[doctorDay{override.DoctorID, override.Date.Format("2006-01-02")}] = override
+	}
+
+	for _, day := range days {
+		key := doctorDay{day.DoctorID, day.Date.Format("2006-01-02")}
+		override := overrides[key]
+		if day.scheduled(branches[key]) {
+			if override != nil {
+				changes = append(changes, &models.DoctorRosterImportChange{
+					DoctorID:   day.DoctorID,
+					Date:       day.Date,
+					Action:     models.RosterImportClear,
+					OverrideID: &override.ID,
+				})
+			}
+			continue
+		}
+		if len(day.BranchIDs) > 1 {
+			unmatched = append(unmatched, day)
+			continue
+		}
+
+		change := &models.DoctorRosterImportChange{
+			DoctorID: day.DoctorID,
+			Date:     day.Date,
+			Action:   models.RosterImportSet,
+			Type:     "off",
+		}
+		if !day.Off {
+			branchID := day.BranchIDs[0]
+			change.Type, change.BranchID = "working", &branchID
+		}
+		if override != nil {
+			if override.Type == change.Type && sameBranch(override.BranchID, change.BranchID) {
+				continue
+			}
+			change.OverrideID = &override.ID
+		}
+		changes = append(changes, change)
+	}
+	return changes, unmatched, nil
+}
+
+// doctorDay keys a doctor's date
+type doctorDay struct {
+	doctorID uuid.UUID
+	date     string
+}
+
+// scheduledBranches calculates the branches each doctor is scheduled at on
+// each date between startDate and endDate, slot by slot, from default
+// schedules, weekly off days and leave alone, as if no override existed
+func (c *DoctorScheduleCalculator) scheduledBranches(ctx context.Context, startDate, endDate time.Time) (map[doctorDay][]uuid.UUID, error) {
+	scheduled := *c
+	scheduled.ignoreOverrides = true
+	scheduled.revenueProfileRepo, scheduled.revenueOverrideRepo = nil, nil
+	assignments, err := scheduled.CalculateDetailedAssignmentsForDateRange(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	branches := make(map[doctorDay][]uuid.UUID)
+	for _, assignment := range assignments {
+		key := doctorDay{assignment.DoctorID, assignment.Date.Format("2006-01-02")}
+		branches[key] = append(branches[key], assignment.BranchID)
+	}
+	return branches, nil
+}
+
+// scheduled reports whether the day is what the schedule already has: off
+// when nothing is scheduled, otherwise the scheduled branches slot for slot.
+// A single branch for a day scheduled at one branch in both halves matches.
+func (d *RosterDay) scheduled(branchIDs []uuid.UUID) bool {
+	if d.Off || len(branchIDs) == 0 {
+		return d.Off && len(branchIDs) == 0
+	}
+	if len(d.BranchIDs) == 1 {
+		for _, branchID := range branchIDs {
+			if branchID != d.BranchIDs[0] {
+				return false
+			}
+		}
+		return true
+	}
+	if len(d.BranchIDs) != len(branchIDs) {
+		return false
+	}
+	for i := range branchIDs {
+		if branchIDs[i] != d.BranchIDs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// sameBranch reports whether two optional branch IDs are equal
+func sameBranch(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
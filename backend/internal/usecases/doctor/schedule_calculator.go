@@ -1,6 +1,7 @@
 package doctor
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -15,6 +16,11 @@ type DoctorScheduleCalculator struct {
 	weeklyOffDayRepo    interfaces.DoctorWeeklyOffDayRepository
 	overrideRepo        interfaces.DoctorScheduleOverrideRepository
 	doctorRepo          interfaces.DoctorRepository
+	revenueProfileRepo  interfaces.DoctorRevenueProfileRepository
+	revenueOverrideRepo interfaces.DoctorRevenueOverrideRepository
+	leaveRepo           interfaces.DoctorLeaveRepository
+	ignoreLeave         uuid.UUID // a leave to calculate as if it did not exist
+	ignoreOverrides     bool      // calculate as if no schedule override existed
 }
 
 // NewDoctorScheduleCalculator creates a new schedule calculator
@@ -32,177 +38,412 @@ func NewDoctorScheduleCalculator(
 	}
 }
 
+// WithRevenue attaches the doctors' revenue profiles and overrides to the
+// calculated assignments; without it they carry no revenue
+func (c *DoctorScheduleCalculator) WithRevenue(
+	profileRepo interfaces.DoctorRevenueProfileRepository,
+	overrideRepo interfaces.DoctorRevenueOverrideRepository,
+) *DoctorScheduleCalculator {
+	c.revenueProfileRepo = profileRepo
+	c.revenueOverrideRepo = overrideRepo
+	return c
+}
+
+// WithLeaves takes the doctors off their schedules during approved leave;
+// without it leave is ignored
+func (c *DoctorScheduleCalculator) WithLeaves(leaveRepo interfaces.DoctorLeaveRepository) *DoctorScheduleCalculator {
+	c.leaveRepo = leaveRepo
+	return c
+}
+
 // CalculatedAssignment represents a calculated doctor assignment
 type CalculatedAssignment struct {
 	DoctorID        uuid.UUID
 	BranchID        uuid.UUID
 	Date            time.Time
-	Source          string // "override" or "default"
-	ExpectedRevenue float64 // Will be 0 for calculated assignments (can be set separately)
+	Slot            string                // models.DoctorSlotFullDay, DoctorSlotMorning or DoctorSlotAfternoon
+	Source          string                // "override" or "default"
+	ExpectedRevenue float64               // Revenue.Amount(), 0 when the doctor has no revenue for the day
+	Revenue         *models.DoctorRevenue // nil when the doctor has no revenue profile or override for the day
+	RevenueSource   string                // "override" or "profile"
+}
+
+// ID is stable for the doctor, date and slot, so the same calculated
+// assignment keeps its ID across requests
+func (a *CalculatedAssignment) ID() uuid.UUID {
+	name := a.Date.Format("2006-01-02")
+	if a.Slot != "" && a.Slot != models.DoctorSlotFullDay {
+		name += "/" + a.Slot
+	}
+	return uuid.NewSHA1(a.DoctorID, []byte(name))
+}
+
+// Model converts the calculated assignment for doctor to a DoctorAssignment.
+// The model has its own copy of the revenue, so a cached assignment is never
+// changed through it.
+func (a *CalculatedAssignment) Model(doctor *models.Doctor) *models.DoctorAssignment {
+	var revenue *models.DoctorRevenue
+	if a.Revenue != nil {
+		copied := *a.Revenue
+		revenue = &copied
+	}
+	return &models.DoctorAssignment{
+		ID:              a.ID(),
+		DoctorID:        a.DoctorID,
+		DoctorName:      doctor.Name,
+		DoctorCode:      doctor.Code,
+		BranchID:        a.BranchID,
+		Date:            a.Date,
+		Slot:            models.DoctorSlotOrFullDay(a.Slot),
+		ExpectedRevenue: a.ExpectedRevenue,
+		Source:          a.Source,
+		Revenue:         revenue,
+		RevenueSource:   a.RevenueSource,
+	}
+}
+
+// setRevenue attaches revenue from source to the assignment. Revenue is for
+// a full day, so a half-day assignment gets half of it.
+func (a *CalculatedAssignment) setRevenue(revenue *models.DoctorRevenue, source string) {
+	if revenue == nil {
+		return
+	}
+	if a.Slot == models.DoctorSlotMorning || a.Slot == models.DoctorSlotAfternoon {
+		half := revenue.Half(a.Slot == models.DoctorSlotMorning)
+		revenue = &half
+	}
+	a.Revenue = revenue
+	a.RevenueSource = source
+	a.ExpectedRevenue = revenue.Amount()
+}
+
+// revenueTable resolves a doctor's expected revenue on a date:
+// Date Override > Branch Weekday Profile > Any-Branch Weekday Profile
+type revenueTable struct {
+	profiles  map[uuid.UUID][]*models.DoctorRevenueProfile           // doctorID -> profiles
+	overrides map[uuid.UUID]map[string]*models.DoctorRevenueOverride // doctorID -> date -> override
+}
+
+func newRevenueTable(profiles []*models.DoctorRevenueProfile, overrides []*models.DoctorRevenueOverride) *revenueTable {
+	t := &revenueTable{
+		profiles:  make(map[uuid.UUID][]*models.DoctorRevenueProfile),
+		overrides: make(map[uuid.UUID]map[string]*models.DoctorRevenueOverride),
+	}
+	for _, profile := range profiles {
+		t.profiles[profile.DoctorID] = append(t.profiles[profile.DoctorID], profile)
+	}
+	for _, override := range overrides {
+		if t.overrides[override.DoctorID] == nil {
+			t.overrides[override.DoctorID] = make(map[string]*models.DoctorRevenueOverride)
+		}
+		t.overrides[override.DoctorID][override.Date.Format("2006-01-02")] = override
+	}
+	return t
 }
 
-// CalculateAssignmentForDate calculates doctor assignment for a specific doctor and date
-// Priority: Override > Weekly Off Day > Default Schedule
-func (c *DoctorScheduleCalculator) CalculateAssignmentForDate(doctorID uuid.UUID, date time.Time) (*CalculatedAssignment, error) {
-	// Get day of week (0=Sunday, 1=Monday, ..., 6=Saturday)
+func (t *revenueTable) resolve(doctorID, branchID uuid.UUID, date time.Time) (*models.DoctorRevenue, string) {
+	if override, ok := t.overrides[doctorID][date.Format("2006-01-02")]; ok {
+		revenue := override.DoctorRevenue
+		return &revenue, "override"
+	}
 	dayOfWeek := int(date.Weekday())
+	var anyBranch *models.DoctorRevenueProfile
+	for _, profile := range t.profiles[doctorID] {
+		if profile.DayOfWeek != dayOfWeek {
+			continue
+		}
+		if profile.BranchID == nil {
+			anyBranch = profile
+			continue
+		}
+		if *profile.BranchID == branchID {
+			revenue := profile.DoctorRevenue
+			return &revenue, "profile"
+		}
+	}
+	if anyBranch != nil {
+		revenue := anyBranch.DoctorRevenue
+		return &revenue, "profile"
+	}
+	return nil, ""
+}
 
-	// Priority 1: Check for override
-	override, err := c.overrideRepo.GetByDoctorAndDate(doctorID, date)
+// loadRevenue loads the revenue of doctorID, or every doctor when it is
+// uuid.Nil, between startDate and endDate. It returns nil without WithRevenue.
+func (c *DoctorScheduleCalculator) loadRevenue(ctx context.Context, doctorID uuid.UUID, startDate, endDate time.Time) (*revenueTable, error) {
+	if c.revenueProfileRepo == nil || c.revenueOverrideRepo == nil {
+		return nil, nil
+	}
+	var profiles []*models.DoctorRevenueProfile
+	var overrides []*models.DoctorRevenueOverride
+	var err error
+	if doctorID == uuid.Nil {
+		profiles, err = c.revenueProfileRepo.List(ctx)
+	} else {
+		profiles, err = c.revenueProfileRepo.GetByDoctorID(ctx, doctorID)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get override: %w", err)
+		return nil, fmt.Errorf("failed to get revenue profiles: %w", err)
+	}
+	if doctorID == uuid.Nil {
+		overrides, err = c.revenueOverrideRepo.GetByDateRange(ctx, startDate, endDate)
+	} else {
+		overrides, err = c.revenueOverrideRepo.GetByDoctorID(ctx, doctorID, startDate, endDate)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revenue overrides: %w", err)
 	}
+	return newRevenueTable(profiles, overrides), nil
+}
 
-	if override != nil {
+// loadLeaves loads the approved leave of doctorID, or every doctor when it is
+// uuid.Nil, overlapping startDate to endDate. It returns nil without WithLeaves.
+func (c *DoctorScheduleCalculator) loadLeaves(ctx context.Context, doctorID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorLeave, error) {
+	if c.leaveRepo == nil {
+		return nil, nil
+	}
+	filters := interfaces.DoctorLeaveFilters{Status: models.DoctorLeaveApproved, StartDate: &startDate, EndDate: &endDate}
+	if doctorID != uuid.Nil {
+		filters.DoctorID = &doctorID
+	}
+	leaves, err := c.leaveRepo.List(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get doctor leaves: %w", err)
+	}
+	kept := leaves[:0]
+	for _, leave := range leaves {
+		if leave.ID != c.ignoreLeave {
+			kept = append(kept, leave)
+		}
+	}
+	return kept, nil
+}
+
+// doctorRules are one doctor's default schedules, weekly off days, overrides
+// and approved leave, from which resolve works out the doctor's assignments
+// on a date
+type doctorRules struct {
+	schedules []*models.DoctorDefaultSchedule
+	offDays   []*models.DoctorWeeklyOffDay
+	overrides map[string]*models.DoctorScheduleOverride // date -> override
+	leaves    []*models.DoctorLeave
+}
+
+// resolve returns the doctor's assignments on date, one per slot worked.
+// Priority: Override > Leave > Weekly Off Day > Default Schedule. A restricted schedule
+// (see models.ScheduleRecurrence) replaces the every-week ones on the dates it
+// applies, and a full-day schedule beats half-day ones.
+func (r *doctorRules) resolve(doctorID uuid.UUID, date time.Time) []*CalculatedAssignment {
+	dayOfWeek := int(date.Weekday())
+
+	// Priority 1: Check override; it covers the whole day
+	if override, ok := r.overrides[date.Format("2006-01-02")]; ok {
 		if override.Type == "off" {
-			// Doctor is off - no assignment
-			return nil, nil
+			return nil
 		}
-		// Working override
-		if override.BranchID == nil {
-			return nil, fmt.Errorf("override type is 'working' but branch_id is null")
+		if override.BranchID != nil {
+			return []*CalculatedAssignment{{
+				DoctorID: doctorID,
+				BranchID: *override.BranchID,
+				Date:     date,
+				Slot:     models.DoctorSlotFullDay,
+				Source:   "override",
+			}}
 		}
-		return &CalculatedAssignment{
-			DoctorID:        doctorID,
-			BranchID:        *override.BranchID,
-			Date:            date,
-			Source:          "override",
-			ExpectedRevenue: 0, // Can be set separately if needed
-		}, nil
 	}
 
-	// Priority 2: Check for weekly off day
-	weeklyOffDay, err := c.weeklyOffDayRepo.GetByDoctorAndDayOfWeek(doctorID, dayOfWeek)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get weekly off day: %w", err)
+	// Priority 2: Check approved leave
+	for _, leave := range r.leaves {
+		if leave.Covers(date) {
+			return nil
+		}
 	}
 
-	if weeklyOffDay != nil {
-		// Doctor has weekly off day - no assignment
-		return nil, nil
+	// Priority 3: Check weekly off days
+	for _, offDay := range r.offDays {
+		if offDay.DayOfWeek == dayOfWeek && offDay.Occurs(date) {
+			return nil
+		}
 	}
 
-	// Priority 3: Check default schedule
-	defaultSchedule, err := c.defaultScheduleRepo.GetByDoctorAndDayOfWeek(doctorID, dayOfWeek)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get default schedule: %w", err)
+	// Priority 4: Check default schedules
+	var weekly, restricted []*models.DoctorDefaultSchedule
+	for _, schedule := range r.schedules {
+		if schedule.DayOfWeek != dayOfWeek || !schedule.Occurs(date) {
+			continue
+		}
+		if schedule.Restricted() {
+			restricted = append(restricted, schedule)
+		} else {
+			weekly = append(weekly, schedule)
+		}
+	}
+	schedules := weekly
+	if len(restricted) > 0 {
+		schedules = restricted
 	}
 
-	if defaultSchedule != nil {
-		return &CalculatedAssignment{
-			DoctorID:        doctorID,
-			BranchID:        defaultSchedule.BranchID,
-			Date:            date,
-			Source:          "default",
-			ExpectedRevenue: 0, // Can be set separately if needed
-		}, nil
+	bySlot := make(map[string]*models.DoctorDefaultSchedule)
+	for _, schedule := range schedules {
+		slot := models.DoctorSlotOrFullDay(schedule.Slot)
+		if bySlot[slot] == nil {
+			bySlot[slot] = schedule
+		}
 	}
+	slots := []string{models.DoctorSlotMorning, models.DoctorSlotAfternoon}
+	if bySlot[models.DoctorSlotFullDay] != nil {
+		slots = []string{models.DoctorSlotFullDay}
+	}
+	var assignments []*CalculatedAssignment
+	for _, slot := range slots {
+		if schedule := bySlot[slot]; schedule != nil {
+			assignments = append(assignments, &CalculatedAssignment{
+				DoctorID: doctorID,
+				BranchID: schedule.BranchID,
+				Date:     date,
+				Slot:     slot,
+				Source:   "default",
+			})
+		}
+	}
+	return assignments
+}
 
-	// No schedule - doctor is off
-	return nil, nil
+// CalculateAssignmentsForDate calculates a doctor's assignments on a date,
+// one per slot worked
+// Priority: Override > Leave > Weekly Off Day > Default Schedule
+func (c *DoctorScheduleCalculator) CalculateAssignmentsForDate(ctx context.Context, doctorID uuid.UUID, date time.Time) ([]*CalculatedAssignment, error) {
+	rules := &doctorRules{overrides: make(map[string]*models.DoctorScheduleOverride)}
+	override, err := c.overrideRepo.GetByDoctorAndDate(ctx, doctorID, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get override: %w", err)
+	}
+	if override != nil {
+		rules.overrides[date.Format("2006-01-02")] = override
+	}
+	if rules.offDays, err = c.weeklyOffDayRepo.GetByDoctorAndDayOfWeek(ctx, doctorID, int(date.Weekday())); err != nil {
+		return nil, fmt.Errorf("failed to get weekly off days: %w", err)
+	}
+	if rules.schedules, err = c.defaultScheduleRepo.GetByDoctorAndDayOfWeek(ctx, doctorID, int(date.Weekday())); err != nil {
+		return nil, fmt.Errorf("failed to get default schedules: %w", err)
+	}
+	if rules.leaves, err = c.loadLeaves(ctx, doctorID, date, date); err != nil {
+		return nil, err
+	}
+
+	assignments := rules.resolve(doctorID, date)
+	if len(assignments) == 0 {
+		return nil, nil
+	}
+	revenue, err := c.loadRevenue(ctx, doctorID, date, date)
+	if err != nil {
+		return nil, err
+	}
+	if revenue != nil {
+		for _, assignment := range assignments {
+			assignment.setRevenue(revenue.resolve(doctorID, assignment.BranchID, date))
+		}
+	}
+	return assignments, nil
 }
 
 // CalculateAssignmentsForDateRange calculates assignments for all doctors in a date range
 // Returns a map: branchID -> date -> []doctorID
-func (c *DoctorScheduleCalculator) CalculateAssignmentsForDateRange(startDate, endDate time.Time) (map[uuid.UUID]map[time.Time][]uuid.UUID, error) {
+func (c *DoctorScheduleCalculator) CalculateAssignmentsForDateRange(ctx context.Context, startDate, endDate time.Time) (map[uuid.UUID]map[time.Time][]uuid.UUID, error) {
+	assignments, err := c.CalculateDetailedAssignmentsForDateRange(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uuid.UUID]map[time.Time][]uuid.UUID)
+	for _, assignment := range assignments {
+		if result[assignment.BranchID] == nil {
+			result[assignment.BranchID] = make(map[time.Time][]uuid.UUID)
+		}
+		doctorIDs := result[assignment.BranchID][assignment.Date]
+		// A doctor working both halves of the day at the branch is listed once
+		if n := len(doctorIDs); n > 0 && doctorIDs[n-1] == assignment.DoctorID {
+			continue
+		}
+		result[assignment.BranchID][assignment.Date] = append(doctorIDs, assignment.DoctorID)
+	}
+	return result, nil
+}
+
+// CalculateDetailedAssignmentsForDateRange calculates the assignments of all
+// doctors in a date range, with their revenue, ordered by date, doctor and slot
+func (c *DoctorScheduleCalculator) CalculateDetailedAssignmentsForDateRange(ctx context.Context, startDate, endDate time.Time) ([]*CalculatedAssignment, error) {
 	// Get all doctors
-	doctors, err := c.doctorRepo.List()
+	doctors, err := c.doctorRepo.List(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get doctors: %w", err)
 	}
 
-	// Load all default schedules (cacheable)
-	allDefaultSchedules := make(map[uuid.UUID][]*models.DoctorDefaultSchedule)
+	// Load default schedules, weekly off days, overrides and leave in one query each
+	rules := make(map[uuid.UUID]*doctorRules, len(doctors))
 	for _, doctor := range doctors {
-		schedules, err := c.defaultScheduleRepo.GetByDoctorID(doctor.ID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get default schedules for doctor %s: %w", doctor.ID, err)
-		}
-		allDefaultSchedules[doctor.ID] = schedules
+		rules[doctor.ID] = &doctorRules{overrides: make(map[string]*models.DoctorScheduleOverride)}
 	}
 
-	// Load all weekly off days (cacheable)
-	allWeeklyOffDays := make(map[uuid.UUID]map[int]bool) // doctorID -> dayOfWeek -> true
-	for _, doctor := range doctors {
-		offDays, err := c.weeklyOffDayRepo.GetByDoctorID(doctor.ID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get weekly off days for doctor %s: %w", doctor.ID, err)
+	defaultSchedules, err := c.defaultScheduleRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get default schedules: %w", err)
+	}
+	for _, schedule := range defaultSchedules {
+		if r, ok := rules[schedule.DoctorID]; ok {
+			r.schedules = append(r.schedules, schedule)
 		}
-		offDayMap := make(map[int]bool)
-		for _, offDay := range offDays {
-			offDayMap[offDay.DayOfWeek] = true
+	}
+
+	offDays, err := c.weeklyOffDayRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weekly off days: %w", err)
+	}
+	for _, offDay := range offDays {
+		if r, ok := rules[offDay.DoctorID]; ok {
+			r.offDays = append(r.offDays, offDay)
 		}
-		allWeeklyOffDays[doctor.ID] = offDayMap
 	}
 
-	// Load all overrides for date range
-	allOverrides := make(map[uuid.UUID]map[time.Time]*models.DoctorScheduleOverride) // doctorID -> date -> override
-	for _, doctor := range doctors {
-		overrides, err := c.overrideRepo.GetByDoctorID(doctor.ID, startDate, endDate)
+	if !c.ignoreOverrides {
+		overrides, err := c.overrideRepo.GetByDateRange(ctx, startDate, endDate)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get overrides for doctor %s: %w", doctor.ID, err)
+			return nil, fmt.Errorf("failed to get overrides: %w", err)
 		}
-		overrideMap := make(map[time.Time]*models.DoctorScheduleOverride)
 		for _, override := range overrides {
-			overrideMap[override.Date] = override
+			if r, ok := rules[override.DoctorID]; ok {
+				r.overrides[override.Date.Format("2006-01-02")] = override
+			}
 		}
-		allOverrides[doctor.ID] = overrideMap
 	}
 
-	// Calculate assignments: branchID -> date -> []doctorID
-	result := make(map[uuid.UUID]map[time.Time][]uuid.UUID)
+	leaves, err := c.loadLeaves(ctx, uuid.Nil, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	for _, leave := range leaves {
+		if r, ok := rules[leave.DoctorID]; ok {
+			r.leaves = append(r.leaves, leave)
+		}
+	}
+
+	revenue, err := c.loadRevenue(ctx, uuid.Nil, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*CalculatedAssignment
 
 	// Iterate through all dates
 	currentDate := startDate
 	for !currentDate.After(endDate) {
-		dayOfWeek := int(currentDate.Weekday())
-
 		// Process each doctor
 		for _, doctor := range doctors {
-			var branchID *uuid.UUID
-
-			// Priority 1: Check override
-			if overrideMap, ok := allOverrides[doctor.ID]; ok {
-				if override, ok := overrideMap[currentDate]; ok {
-					if override.Type == "off" {
-						continue // Doctor is off
-					}
-					if override.BranchID != nil {
-						branchID = override.BranchID
-					}
+			for _, assignment := range rules[doctor.ID].resolve(doctor.ID, currentDate) {
+				if revenue != nil {
+					assignment.setRevenue(revenue.resolve(doctor.ID, assignment.BranchID, currentDate))
 				}
-			}
-
-			// Priority 2: Check weekly off day
-			if branchID == nil {
-				if offDayMap, ok := allWeeklyOffDays[doctor.ID]; ok {
-					if offDayMap[dayOfWeek] {
-						continue // Doctor has weekly off day
-					}
-				}
-			}
-
-			// Priority 3: Check default schedule
-			if branchID == nil {
-				if schedules, ok := allDefaultSchedules[doctor.ID]; ok {
-					for _, schedule := range schedules {
-						if schedule.DayOfWeek == dayOfWeek {
-							branchID = &schedule.BranchID
-							break
-						}
-					}
-				}
-			}
-
-			// Add assignment if branch is determined
-			if branchID != nil {
-				if result[*branchID] == nil {
-					result[*branchID] = make(map[time.Time][]uuid.UUID)
-				}
-				if result[*branchID][currentDate] == nil {
-					result[*branchID][currentDate] = []uuid.UUID{}
-				}
-				result[*branchID][currentDate] = append(result[*branchID][currentDate], doctor.ID)
+				result = append(result, assignment)
 			}
 		}
 
@@ -213,66 +454,61 @@ func (c *DoctorScheduleCalculator) CalculateAssignmentsForDateRange(startDate, e
 	return result, nil
 }
 
-// GetDoctorsByBranchAndDate returns doctor IDs assigned to a branch on a specific date
-func (c *DoctorScheduleCalculator) GetDoctorsByBranchAndDate(branchID uuid.UUID, date time.Time) ([]uuid.UUID, error) {
-	// Get all doctors
-	doctors, err := c.doctorRepo.List()
+// Assignments calculates the assignments between startDate and endDate that
+// filter matches, as models of the doctors they belong to
+func (c *DoctorScheduleCalculator) Assignments(ctx context.Context, startDate, endDate time.Time, filter RosterFilter) ([]*models.DoctorAssignment, error) {
+	calculated, err := c.CalculateDetailedAssignmentsForDateRange(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	doctors, err := c.doctorRepo.List(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get doctors: %w", err)
 	}
-
-	dayOfWeek := int(date.Weekday())
-	var doctorIDs []uuid.UUID
-
+	byID := make(map[uuid.UUID]*models.Doctor, len(doctors))
 	for _, doctor := range doctors {
-		var assignedBranchID *uuid.UUID
-
-		// Priority 1: Check override
-		override, err := c.overrideRepo.GetByDoctorAndDate(doctor.ID, date)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get override: %w", err)
-		}
-		if override != nil {
-			if override.Type == "off" {
-				continue // Doctor is off
-			}
-			assignedBranchID = override.BranchID
-		}
-
-		// Priority 2: Check weekly off day
-		if assignedBranchID == nil {
-			weeklyOffDay, err := c.weeklyOffDayRepo.GetByDoctorAndDayOfWeek(doctor.ID, dayOfWeek)
-			if err != nil {
-				return nil, fmt.Errorf("failed to get weekly off day: %w", err)
-			}
-			if weeklyOffDay != nil {
-				continue // Doctor has weekly off day
-			}
-		}
+		byID[doctor.ID] = doctor
+	}
 
-		// Priority 3: Check default schedule
-		if assignedBranchID == nil {
-			defaultSchedule, err := c.defaultScheduleRepo.GetByDoctorAndDayOfWeek(doctor.ID, dayOfWeek)
-			if err != nil {
-				return nil, fmt.Errorf("failed to get default schedule: %w", err)
-			}
-			if defaultSchedule != nil {
-				assignedBranchID = &defaultSchedule.BranchID
-			}
+	assignments := []*models.DoctorAssignment{}
+	for _, assignment := range calculated {
+		doctor, ok := byID[assignment.DoctorID]
+		if !ok || !filter.Matches(assignment.DoctorID, assignment.BranchID) {
+			continue
 		}
+		assignments = append(assignments, assignment.Model(doctor))
+	}
+	return assignments, nil
+}
 
-		// Check if assigned to target branch
-		if assignedBranchID != nil && *assignedBranchID == branchID {
-			doctorIDs = append(doctorIDs, doctor.ID)
+// GetAssignmentsByBranchAndDate returns the calculated assignments, with
+// revenue, of the doctors working at a branch on a specific date
+func (c *DoctorScheduleCalculator) GetAssignmentsByBranchAndDate(ctx context.Context, branchID uuid.UUID, date time.Time) ([]*CalculatedAssignment, error) {
+	assignments, err := c.CalculateDetailedAssignmentsForDateRange(ctx, date, date)
+	if err != nil {
+		return nil, err
+	}
+	var result []*CalculatedAssignment
+	for _, assignment := range assignments {
+		if assignment.BranchID == branchID {
+			result = append(result, assignment)
 		}
 	}
+	return result, nil
+}
 
-	return doctorIDs, nil
+// GetDoctorsByBranchAndDate returns doctor IDs assigned to a branch on a specific date
+func (c *DoctorScheduleCalculator) GetDoctorsByBranchAndDate(ctx context.Context, branchID uuid.UUID, date time.Time) ([]uuid.UUID, error) {
+	assignments, err := c.CalculateAssignmentsForDateRange(ctx, date, date)
+	if err != nil {
+		return nil, err
+	}
+	return assignments[branchID][date], nil
 }
 
 // GetDoctorCountByBranch returns the count of doctors assigned to a branch on a specific date
-func (c *DoctorScheduleCalculator) GetDoctorCountByBranch(branchID uuid.UUID, date time.Time) (int, error) {
-	doctorIDs, err := c.GetDoctorsByBranchAndDate(branchID, date)
+func (c *DoctorScheduleCalculator) GetDoctorCountByBranch(ctx context.Context, branchID uuid.UUID, date time.Time) (int, error) {
+	doctorIDs, err := c.GetDoctorsByBranchAndDate(ctx, branchID, date)
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,198 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+)
+
+// DefaultCoverageGapDays is how many open days in a row without a doctor make
+// a coverage gap: a whole week
+const DefaultCoverageGapDays = 7
+
+// ScheduleValidator checks the calculated doctor schedule before it is
+// published: branches left without a doctor, branches given more doctors than
+// they can take, and schedule overrides that conflict or change nothing
+type ScheduleValidator struct {
+	calculator   *DoctorScheduleCalculator
+	branchRepo   interfaces.BranchRepository
+	closureRepo  interfaces.BranchClosureRepository
+	onOffDayRepo interfaces.DoctorOnOffDayRepository
+}
+
+// NewScheduleValidator creates a validator over calculator's schedule
+func NewScheduleValidator(
+	calculator *DoctorScheduleCalculator,
+	branchRepo interfaces.BranchRepository,
+	closureRepo interfaces.BranchClosureRepository,
+	onOffDayRepo interfaces.DoctorOnOffDayRepository,
+) *ScheduleValidator {
+	return &ScheduleValidator{
+		calculator:   calculator,
+		branchRepo:   branchRepo,
+		closureRepo:  closureRepo,
+		onOffDayRepo: onOffDayRepo,
+	}
+}
+
+// Validate checks the doctor schedule from startDate to endDate. A coverage
+// gap is gapDays or more open days in a row a branch has no doctor on; closed
+// days and doctor-off designations neither count towards a gap nor end it.
+// Coverage gaps and redundant overrides are warnings, the rest are errors.
+func (v *ScheduleValidator) Validate(ctx context.Context, startDate, endDate time.Time, gapDays int) (*models.DoctorScheduleValidation, error) {
+	if gapDays <= 0 {
+		gapDays = DefaultCoverageGapDays
+	}
+	result := &models.DoctorScheduleValidation{StartDate: startDate, EndDate: endDate, Valid: true, Issues: []*models.DoctorScheduleIssue{}}
+
+	assignments, err := v.calculator.CalculateAssignmentsForDateRange(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	branches, err := v.branchRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branches: %w", err)
+	}
+	closures, err := v.closureRepo.List(ctx, interfaces.BranchClosureFilters{StartDate: &startDate, EndDate: &endDate})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branch closures: %w", err)
+	}
+	designations, err := v.onOffDayRepo.GetByDateRange(ctx, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get doctor on/off days: %w", err)
+	}
+	doctorOff := make(map[uuid.UUID]map[string]bool)
+	for _, d := range designations {
+		if d.IsDoctorOn {
+			continue
+		}
+		if doctorOff[d.BranchID] == nil {
+			doctorOff[d.BranchID] = make(map[string]bool)
+		}
+		doctorOff[d.BranchID][d.Date.Format("2006-01-02")] = true
+	}
+	closed := func(branchID uuid.UUID, date time.Time) bool {
+		for _, closure := range closures {
+			if closure.Closes(branchID, date) {
+				return true
+			}
+		}
+		return false
+	}
+
+	codes := make(map[uuid.UUID]string, len(branches))
+	for _, branch := range branches {
+		codes[branch.ID] = branch.Code
+		doctorsOn := make(map[string]int)
+		for date, doctorIDs := range assignments[branch.ID] {
+			doctorsOn[date.Format("2006-01-02")] = len(doctorIDs)
+		}
+
+		var gapStart, gapEnd time.Time
+		gap := 0
+		flush := func() {
+			if gap >= gapDays {
+				branchID, end := branch.ID, gapEnd
+				result.Add(&models.DoctorScheduleIssue{
+					Type:       models.ScheduleIssueCoverageGap,
+					Severity:   models.ScheduleIssueWarning,
+					BranchID:   &branchID,
+					BranchCode: branch.Code,
+					Date:       gapStart,
+					EndDate:    &end,
+					Days:       gap,
+					Message: fmt.Sprintf("%s has no doctor from %s to %s",
+						branch.Code, gapStart.Format("2006-01-02"), gapEnd.Format("2006-01-02")),
+				})
+			}
+			gap = 0
+		}
+		capacity := branch.DoctorCapacity()
+		for date := startDate; !date.After(endDate); date = date.AddDate(0, 0, 1) {
+			n := doctorsOn[date.Format("2006-01-02")]
+			if n > capacity {
+				branchID := branch.ID
+				result.Add(&models.DoctorScheduleIssue{
+					Type:       models.ScheduleIssueOverCapacity,
+					Severity:   models.ScheduleIssueError,
+					BranchID:   &branchID,
+					BranchCode: branch.Code,
+					Date:       date,
+					Doctors:    n,
+					Capacity:   capacity,
+					Message:    fmt.Sprintf("%s has %d doctors on %s; it can take %d", branch.Code, n, date.Format("2006-01-02"), capacity),
+				})
+			}
+			if closed(branch.ID, date) || doctorOff[branch.ID][date.Format("2006-01-02")] {
+				continue
+			}
+			if n > 0 {
+				flush()
+				continue
+			}
+			if gap == 0 {
+				gapStart = date
+			}
+			gap++
+			gapEnd = date
+		}
+		flush()
+	}
+
+	if err := v.checkOverrides(ctx, result, codes, closed); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(result.Issues, func(i, j int) bool {
+		return result.Issues[i].Date.Before(result.Issues[j].Date)
+	})
+	return result, nil
+}
+
+// checkOverrides reports working overrides at a closed branch and overrides
+// the default schedule makes redundant. A doctor has at most one override a
+// date; both stores refuse a second.
+func (v *ScheduleValidator) checkOverrides(ctx context.Context, result *models.DoctorScheduleValidation, codes map[uuid.UUID]string, closed func(uuid.UUID, time.Time) bool) error {
+	overrides, err := v.calculator.overrideRepo.GetByDateRange(ctx, result.StartDate, result.EndDate)
+	if err != nil {
+		return fmt.Errorf("failed to get overrides: %w", err)
+	}
+	scheduled, err := v.calculator.scheduledBranches(ctx, result.StartDate, result.EndDate)
+	if err != nil {
+		return err
+	}
+
+	for _, override := range overrides {
+		key := doctorDay{override.DoctorID, override.Date.Format("2006-01-02")}
+		doctorID := override.DoctorID
+		issue := &models.DoctorScheduleIssue{
+			DoctorID:  &doctorID,
+			BranchID:  override.BranchID,
+			Date:      override.Date,
+			Overrides: []uuid.UUID{override.ID},
+		}
+		if override.BranchID != nil {
+			issue.BranchCode = codes[*override.BranchID]
+		}
+		if override.Type == "working" && override.BranchID != nil && closed(*override.BranchID, override.Date) {
+			issue.Type, issue.Severity = models.ScheduleIssueClosedBranch, models.ScheduleIssueError
+			issue.Message = fmt.Sprintf("Doctor overridden to work at %s on %s, when it is closed", issue.BranchCode, key.date)
+			result.Add(issue)
+			continue
+		}
+		day := &RosterDay{Off: override.Type == "off"}
+		if override.BranchID != nil {
+			day.BranchIDs = []uuid.UUID{*override.BranchID}
+		}
+		if day.scheduled(scheduled[key]) {
+			issue.Type, issue.Severity = models.ScheduleIssueRedundantOverride, models.ScheduleIssueWarning
+			issue.Message = fmt.Sprintf("Override on %s is what the default schedule has already", key.date)
+			result.Add(issue)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+	"vsq-oper-manpower/backend/internal/usecases/allocation"
+	"vsq-oper-manpower/backend/pkg/excel"
+	"vsq-oper-manpower/backend/pkg/timezone"
+
+	"github.com/google/uuid"
+)
+
+// Job types backed by the allocation use cases
+const (
+	TypeGenerateSuggestions   = "suggestions.generate"
+	TypeRebuildQuotaSummaries = "quota_summaries.rebuild"
+	TypeWeeklyReport          = "reports.weekly"
+)
+
+// GenerateSuggestionsPayload selects the days and branches to generate suggestions for
+type GenerateSuggestionsPayload struct {
+	Days      int         `json:"days"`                 // Days from today, including today; defaults to 14
+	BranchIDs []uuid.UUID `json:"branch_ids,omitempty"` // Empty means every branch
+}
+
+// RebuildQuotaSummariesPayload selects the summaries to recompute
+type RebuildQuotaSummariesPayload struct {
+	StartDate string      `json:"start_date"` // YYYY-MM-DD
+	EndDate   string      `json:"end_date"`   // YYYY-MM-DD
+	BranchIDs []uuid.UUID `json:"branch_ids,omitempty"`
+}
+
+// AllocationJobs runs the suggestion, summary and report jobs
+type AllocationJobs struct {
+	repos           *allocation.RepositoriesWrapper
+	engine          *allocation.SuggestionEngine
+	quotaCalculator *allocation.QuotaCalculator
+}
+
+// NewAllocationJobs creates the allocation jobs
+func NewAllocationJobs(repos *allocation.RepositoriesWrapper, engine *allocation.SuggestionEngine, quotaCalculator *allocation.QuotaCalculator) *AllocationJobs {
+	return &AllocationJobs{
+		repos:           repos,
+		engine:          engine,
+		quotaCalculator: quotaCalculator,
+	}
+}
+
+// Register adds the allocation job types to the runner
+func (a *AllocationJobs) Register(runner *Runner) {
+	runner.Register(TypeGenerateSuggestions, a.GenerateSuggestions)
+	runner.Register(TypeRebuildQuotaSummaries, a.RebuildQuotaSummaries)
+	runner.Register(TypeWeeklyReport, a.WeeklyReport)
+}
+
+// ScheduleDefaults sets up nightly suggestion generation for the next 14 days
+// and the weekly staffing report every Monday morning
+func (a *AllocationJobs) ScheduleDefaults(ctx context.Context, runner *Runner) error {
+	if err := runner.Schedule(ctx, "nightly-suggestions", TypeGenerateSuggestions, "0 2 * * *",
+		GenerateSuggestionsPayload{Days: 14}); err != nil {
+		return err
+	}
+	return runner.Schedule(ctx, "weekly-staffing-report", TypeWeeklyReport, "0 6 * * 1", nil)
+}
+
+// GenerateSuggestions replaces the pending suggestions of the coming days with
+// freshly generated ones. Approved and rejected suggestions are kept.
+func (a *AllocationJobs) GenerateSuggestions(ctx context.Context, job *models.Job, progress *Progress) (*Result, error) {
+	var payload GenerateSuggestionsPayload
+	if err := DecodePayload(job, &payload); err != nil {
+		return nil, err
+	}
+	if payload.Days <= 0 {
+		payload.Days = 14
+	}
+	if payload.Days > 366 {
+		return nil, Permanent(errors.New("days must be at most 366"))
+	}
+
+	branchIDs, err := a.branchIDs(ctx, payload.BranchIDs)
+	if err != nil {
+		return nil, err
+	}
+	startDate := thailandToday()
+	endDate := startDate.AddDate(0, 0, payload.Days-1)
+
+	// Drop the pending suggestions being regenerated
+	progress.Report(0, "Removing pending suggestions")
+	pending := models.SuggestionStatusPending
+	existing, err := a.repos.AllocationSuggestion.List(ctx, interfaces.AllocationSuggestionFilters{
+		Status:    &pending,
+		StartDate: &startDate,
+		EndDate:   &endDate,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending suggestions: %w", err)
+	}
+	selected := make(map[uuid.UUID]bool, len(branchIDs))
+	for _, branchID := range branchIDs {
+		selected[branchID] = true
+	}
+	replaced := 0
+	for _, suggestion := range existing {
+		if !selected[suggestion.BranchID] {
+			continue
+		}
+		if err := a.repos.AllocationSuggestion.Delete(ctx, suggestion.ID); err != nil {
+			return nil, fmt.Errorf("failed to delete suggestion %s: %w", suggestion.ID, err)
+		}
+		replaced++
+	}
+
+	created := 0
+	for day := 0; day < payload.Days; day++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		date := startDate.AddDate(0, 0, day)
+		progress.Report(day*100/payload.Days, fmt.Sprintf("Generating suggestions for %s", date.Format("2006-01-02")))
+		suggestions, err := a.engine.GenerateSuggestions(ctx, branchIDs, date, date)
+		if err != nil {
+			return nil, err
+		}
+		for _, suggestion := range suggestions {
+			if err := a.repos.AllocationSuggestion.Create(ctx, suggestion); err != nil {
+				return nil, fmt.Errorf("failed to save suggestion: %w", err)
+			}
+			created++
+		}
+	}
+
+	return &Result{Data: map[string]interface{}{
+		"start_date": startDate.Format("2006-01-02"),
+		"end_date":   endDate.Format("2006-01-02"),
+		"branches":   len(branchIDs),
+		"created":    created,
+		"replaced":   replaced,
+	}}, nil
+}
+
+// RebuildQuotaSummaries queues the summaries of a date range for the summary
+// worker, which recomputes them in the background
+func (a *AllocationJobs) RebuildQuotaSummaries(ctx context.Context, job *models.Job, progress *Progress) (*Result, error) {
+	var payload RebuildQuotaSummariesPayload
+	if err := DecodePayload(job, &payload); err != nil {
+		return nil, err
+	}
+	startDate, err := time.Parse("2006-01-02", payload.StartDate)
+	if err != nil {
+		return nil, Permanent(errors.New("invalid start_date format. Use YYYY-MM-DD"))
+	}
+	endDate, err := time.Parse("2006-01-02", payload.EndDate)
+	if err != nil {
+		return nil, Permanent(errors.New("invalid end_date format. Use YYYY-MM-DD"))
+	}
+	if endDate.Before(startDate) {
+		return nil, Permanent(errors.New("end_date must be after start_date"))
+	}
+
+	branchIDs, err := a.branchIDs(ctx, payload.BranchIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i, branchID := range branchIDs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := a.repos.BranchQuotaSummary.MarkDirty(ctx, branchID, startDate, endDate); err != nil {
+			return nil, fmt.Errorf("failed to queue quota summaries for branch %s: %w", branchID, err)
+		}
+		progress.Report((i+1)*100/len(branchIDs), fmt.Sprintf("Queued %d of %d branches", i+1, len(branchIDs)))
+	}
+
+	return &Result{Data: map[string]interface{}{
+		"start_date": payload.StartDate,
+		"end_date":   payload.EndDate,
+		"branches":   len(branchIDs),
+	}}, nil
+}
+
+// WeeklyReport builds a workbook of the staff each branch still required on
+// each day of the previous Monday-to-Sunday week
+func (a *AllocationJobs) WeeklyReport(ctx context.Context, job *models.Job, progress *Progress) (*Result, error) {
+	today := thailandToday()
+	// Monday of this week, then back one week
+	weekStart := today.AddDate(0, 0, -((int(today.Weekday())+6)%7)-7)
+	dates := make([]time.Time, 7)
+	for i := range dates {
+		dates[i] = weekStart.AddDate(0, 0, i)
+	}
+
+	branches, err := a.repos.Branch.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branches: %w", err)
+	}
+	branchIDs := make([]uuid.UUID, len(branches))
+	for i, branch := range branches {
+		branchIDs[i] = branch.ID
+	}
+
+	progress.Report(10, "Calculating quota status")
+	matrix, err := a.quotaCalculator.CalculateMatrix(ctx, branchIDs, dates)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &excel.StaffingReport{
+		Title: fmt.Sprintf("Staff still required, %s to %s",
+			dates[0].Format("2 Jan 2006"), dates[len(dates)-1].Format("2 Jan 2006")),
+		Dates: dates,
+	}
+	totalRequired := 0
+	for i, branch := range branches {
+		row := excel.StaffingReportRow{BranchCode: branch.Code, BranchName: branch.Name, Required: make([]int, len(dates))}
+		for d, status := range matrix[i] {
+			row.Required[d] = status.TotalRequired
+			totalRequired += status.TotalRequired
+		}
+		report.Rows = append(report.Rows, row)
+	}
+
+	progress.Report(80, "Writing workbook")
+	data, err := excel.WriteStaffingReport(report)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{
+		Data: map[string]interface{}{
+			"week_start":     dates[0].Format("2006-01-02"),
+			"week_end":       dates[len(dates)-1].Format("2006-01-02"),
+			"branches":       len(branches),
+			"total_required": totalRequired,
+		},
+		File: &models.JobFile{
+			Name:        fmt.Sprintf("staffing-report-%s.xlsx", dates[0].Format("2006-01-02")),
+			ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			Data:        data,
+		},
+	}, nil
+}
+
+// branchIDs returns the requested branches, or every branch when none are given
+func (a *AllocationJobs) branchIDs(ctx context.Context, requested []uuid.UUID) ([]uuid.UUID, error) {
+	if len(requested) > 0 {
+		return requested, nil
+	}
+	branches, err := a.repos.Branch.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branches: %w", err)
+	}
+	branchIDs := make([]uuid.UUID, len(branches))
+	for i, branch := range branches {
+		branchIDs[i] = branch.ID
+	}
+	return branchIDs, nil
+}
+
+// thailandToday returns today's date in Thailand as a UTC date, the form
+// schedule dates are stored in
+func thailandToday() time.Time {
+	now := timezone.GetThailandTime()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
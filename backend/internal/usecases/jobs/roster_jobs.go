@@ -0,0 +1,93 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/models"
+	"vsq-oper-manpower/backend/internal/usecases/doctor"
+)
+
+// TypeMaterializeRoster writes the calculated doctor roster into the roster table
+const TypeMaterializeRoster = "doctor_roster.materialize"
+
+// MaterializeRosterPayload selects the dates to materialize. Without dates the
+// run covers the configured horizon from today.
+type MaterializeRosterPayload struct {
+	StartDate string `json:"start_date,omitempty"` // YYYY-MM-DD
+	EndDate   string `json:"end_date,omitempty"`   // YYYY-MM-DD
+}
+
+// RosterJobs runs the doctor roster materialization
+type RosterJobs struct {
+	materializer *doctor.RosterMaterializer
+}
+
+// NewRosterJobs creates the roster jobs
+func NewRosterJobs(materializer *doctor.RosterMaterializer) *RosterJobs {
+	return &RosterJobs{materializer: materializer}
+}
+
+// Register adds the roster job type to the runner
+func (r *RosterJobs) Register(runner *Runner) {
+	runner.Register(TypeMaterializeRoster, r.Materialize)
+}
+
+// ScheduleDefaults rolls the roster horizon forward every night and queues a
+// run now, so a new deployment or changed horizon takes effect at startup
+func (r *RosterJobs) ScheduleDefaults(ctx context.Context, runner *Runner) error {
+	if err := runner.Schedule(ctx, "nightly-doctor-roster", TypeMaterializeRoster, "30 1 * * *", nil); err != nil {
+		return err
+	}
+	_, err := runner.Enqueue(ctx, TypeMaterializeRoster, EnqueueOptions{})
+	return err
+}
+
+// Materialize rewrites the roster for the payload's dates or the horizon and
+// reports what changed
+func (r *RosterJobs) Materialize(ctx context.Context, job *models.Job, progress *Progress) (*Result, error) {
+	var payload MaterializeRosterPayload
+	if err := DecodePayload(job, &payload); err != nil {
+		return nil, err
+	}
+	// Jobs queued by a user are manual; schedules and startup have no creator
+	trigger := models.RosterTriggerScheduled
+	if job.CreatedBy != nil {
+		trigger = models.RosterTriggerManual
+	}
+
+	progress.Report(0, "Materializing doctor roster")
+	var run *models.DoctorRosterRun
+	if payload.StartDate == "" && payload.EndDate == "" {
+		var err error
+		if run, err = r.materializer.MaterializeHorizon(ctx, trigger); err != nil {
+			return nil, err
+		}
+	} else {
+		startDate, err := time.Parse("2006-01-02", payload.StartDate)
+		if err != nil {
+			return nil, Permanent(errors.New("invalid start_date"))
+		}
+		endDate, err := time.Parse("2006-01-02", payload.EndDate)
+		if err != nil {
+			return nil, Permanent(errors.New("invalid end_date"))
+		}
+		if endDate.Before(startDate) || endDate.Sub(startDate) > 366*24*time.Hour {
+			return nil, Permanent(errors.New("end_date must be on or after start_date and within a year of it"))
+		}
+		if run, err = r.materializer.Materialize(ctx, startDate, endDate, trigger); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Result{Data: map[string]interface{}{
+		"run_id":     run.ID,
+		"start_date": run.StartDate.Format("2006-01-02"),
+		"end_date":   run.EndDate.Format("2006-01-02"),
+		"added":      run.Added,
+		"removed":    run.Removed,
+		"moved":      run.Moved,
+		"updated":    run.Updated,
+	}}, nil
+}
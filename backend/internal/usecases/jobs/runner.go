@@ -0,0 +1,551 @@
+// Package jobs runs long operations (imports, bulk generation, reports) in the
+// background. Jobs are rows in the jobs table, so they survive restarts and any
+// server instance may run them; workers claim queued jobs, report progress
+// through heartbeats and record a JSON result or a file for download.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+	"vsq-oper-manpower/backend/pkg/cron"
+	"vsq-oper-manpower/backend/pkg/timezone"
+
+	"github.com/google/uuid"
+)
+
+// ErrUnknownJobType is returned when enqueuing a type with no registered handler
+var ErrUnknownJobType = errors.New("unknown job type")
+
+// Handler runs one job. It should return promptly once ctx is cancelled, which
+// happens when the job is cancelled or the server shuts down.
+type Handler func(ctx context.Context, job *models.Job, progress *Progress) (*Result, error)
+
+// Result is what a successful job leaves behind: JSON data, a file, or both
+type Result struct {
+	Data interface{}
+	File *models.JobFile
+}
+
+// Progress collects a running job's progress; the runner saves the latest
+// report with each heartbeat
+type Progress struct {
+	mu      sync.Mutex
+	percent int
+	message string
+}
+
+// Report records how far the job has got, as a percentage and a short message
+func (p *Progress) Report(percent int, message string) {
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 99 {
+		// 100 is reserved for finished jobs
+		percent = 99
+	}
+	p.mu.Lock()
+	p.percent, p.message = percent, message
+	p.mu.Unlock()
+}
+
+func (p *Progress) snapshot() (int, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.percent, p.message
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks an error that retrying cannot fix, such as invalid input, so
+// the job fails without using its remaining attempts
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// EnqueueOptions describes a job to queue
+type EnqueueOptions struct {
+	Payload     interface{}     // Marshalled to JSON; handlers decode it with DecodePayload
+	Input       *models.JobFile // Uploaded file the handler reads with Runner.Input
+	MaxAttempts int             // Defaults to 3
+	RunAt       time.Time       // Defaults to now
+	CreatedBy   *uuid.UUID
+	// ScheduleName records the schedule that queued the job
+	ScheduleName string
+}
+
+// DecodePayload unmarshals a job's payload into v
+func DecodePayload(job *models.Job, v interface{}) error {
+	if len(job.Payload) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(job.Payload, v); err != nil {
+		return Permanent(fmt.Errorf("invalid job payload: %w", err))
+	}
+	return nil
+}
+
+// Runner claims and runs queued jobs and queues jobs for due schedules
+type Runner struct {
+	jobs              interfaces.JobRepository
+	schedules         interfaces.JobScheduleRepository
+	handlers          map[string]Handler
+	workerID          string
+	workers           int
+	pollInterval      time.Duration
+	heartbeatInterval time.Duration // How often progress is saved and cancellation checked
+	staleAfter        time.Duration // Running jobs without a heartbeat this long are requeued
+	retention         time.Duration // Finished jobs are deleted after this long
+	shutdownGrace     time.Duration // Running jobs may finish this long after Run's ctx is cancelled
+	location          *time.Location
+
+	// Worker liveness for readiness checks
+	mu         sync.Mutex
+	running    bool
+	workerSeen []time.Time // When each worker last polled or sent a heartbeat
+}
+
+// NewRunner creates a runner with the default settings
+func NewRunner(jobs interfaces.JobRepository, schedules interfaces.JobScheduleRepository) *Runner {
+	hostname, _ := os.Hostname()
+	location, err := time.LoadLocation(timezone.ThailandTimeZone)
+	if err != nil {
+		location = time.Local
+	}
+	return &Runner{
+		jobs:              jobs,
+		schedules:         schedules,
+		handlers:          make(map[string]Handler),
+		workerID:          fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), uuid.NewString()[:8]),
+		workers:           2,
+		pollInterval:      time.Second,
+		heartbeatInterval: 2 * time.Second,
+		staleAfter:        2 * time.Minute,
+		retention:         7 * 24 * time.Hour,
+		location:          location,
+	}
+}
+
+// WithWorkers sets how many jobs run concurrently
+func (r *Runner) WithWorkers(workers int) *Runner {
+	if workers > 0 {
+		r.workers = workers
+	}
+	return r
+}
+
+// WithPollInterval sets how often idle workers look for queued jobs
+func (r *Runner) WithPollInterval(interval time.Duration) *Runner {
+	if interval > 0 {
+		r.pollInterval = interval
+	}
+	return r
+}
+
+// WithHeartbeat sets how often running jobs save progress and how long a job
+// may go without a heartbeat before it is considered abandoned
+func (r *Runner) WithHeartbeat(interval, staleAfter time.Duration) *Runner {
+	if interval > 0 {
+		r.heartbeatInterval = interval
+	}
+	if staleAfter > 0 {
+		r.staleAfter = staleAfter
+	}
+	return r
+}
+
+// WithRetention sets how long finished jobs are kept
+func (r *Runner) WithRetention(retention time.Duration) *Runner {
+	if retention > 0 {
+		r.retention = retention
+	}
+	return r
+}
+
+// WithShutdownGrace sets how long running jobs may finish once Run's context is
+// cancelled; jobs still running then are interrupted and queued again
+func (r *Runner) WithShutdownGrace(grace time.Duration) *Runner {
+	r.shutdownGrace = grace
+	return r
+}
+
+// Register sets the handler for a job type. Handlers are registered at
+// startup, before Run.
+func (r *Runner) Register(jobType string, handler Handler) {
+	r.handlers[jobType] = handler
+}
+
+// Types returns the registered job types
+func (r *Runner) Types() []string {
+	types := make([]string, 0, len(r.handlers))
+	for jobType := range r.handlers {
+		types = append(types, jobType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Enqueue queues a job and returns it
+func (r *Runner) Enqueue(ctx context.Context, jobType string, opts EnqueueOptions) (*models.Job, error) {
+	if _, ok := r.handlers[jobType]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownJobType, jobType)
+	}
+	job := &models.Job{
+		Type:         jobType,
+		Status:       models.JobStatusQueued,
+		MaxAttempts:  opts.MaxAttempts,
+		RunAt:        opts.RunAt,
+		CreatedBy:    opts.CreatedBy,
+		ScheduleName: opts.ScheduleName,
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = 3
+	}
+	if opts.Payload != nil {
+		payload, err := json.Marshal(opts.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode job payload: %w", err)
+		}
+		job.Payload = payload
+	}
+	if err := r.jobs.Create(ctx, job, opts.Input); err != nil {
+		return nil, fmt.Errorf("failed to queue job: %w", err)
+	}
+	return job, nil
+}
+
+// Input returns the file uploaded with a job
+func (r *Runner) Input(ctx context.Context, job *models.Job) (*models.JobFile, error) {
+	input, err := r.jobs.GetInput(ctx, job.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load job input: %w", err)
+	}
+	if input == nil {
+		return nil, Permanent(errors.New("job has no input file"))
+	}
+	return input, nil
+}
+
+// Cancel cancels a queued job at once and asks a running one to stop; the job
+// is nil if it does not exist
+func (r *Runner) Cancel(ctx context.Context, id uuid.UUID) (*models.Job, error) {
+	return r.jobs.RequestCancel(ctx, id)
+}
+
+// Schedule registers a recurring job. Cron expressions are evaluated in
+// Thailand time. Schedules that already exist keep their enabled flag, so an
+// administrator's decision to pause one survives restarts.
+func (r *Runner) Schedule(ctx context.Context, name, jobType, spec string, payload interface{}) error {
+	if _, ok := r.handlers[jobType]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownJobType, jobType)
+	}
+	sched, err := cron.Parse(spec)
+	if err != nil {
+		return err
+	}
+	schedule := &models.JobSchedule{
+		Name:      name,
+		JobType:   jobType,
+		Cron:      spec,
+		Enabled:   true,
+		NextRunAt: sched.Next(time.Now().In(r.location)),
+	}
+	if payload != nil {
+		if schedule.Payload, err = json.Marshal(payload); err != nil {
+			return fmt.Errorf("failed to encode schedule payload: %w", err)
+		}
+	}
+	if err := r.schedules.Ensure(ctx, schedule); err != nil {
+		return fmt.Errorf("failed to save schedule %s: %w", name, err)
+	}
+	return nil
+}
+
+// Run starts the workers and the scheduler and blocks until ctx is cancelled.
+// Jobs still running at shutdown are requeued.
+func (r *Runner) Run(ctx context.Context) {
+	r.mu.Lock()
+	r.running = true
+	r.workerSeen = make([]time.Time, r.workers)
+	for i := range r.workerSeen {
+		r.workerSeen[i] = time.Now()
+	}
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.running = false
+		r.mu.Unlock()
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < r.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.work(ctx, i)
+		}()
+	}
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		if _, err := r.RunDueSchedules(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Failed to queue scheduled jobs", "error", err)
+		}
+		r.maintain(ctx)
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// maintain requeues jobs whose worker died and deletes old finished jobs
+func (r *Runner) maintain(ctx context.Context) {
+	if n, err := r.jobs.RequeueStale(ctx, r.staleAfter); err != nil {
+		if ctx.Err() == nil {
+			slog.ErrorContext(ctx, "Failed to requeue stale jobs", "error", err)
+		}
+	} else if n > 0 {
+		slog.InfoContext(ctx, "Requeued jobs abandoned by their worker", "count", n)
+	}
+	if _, err := r.jobs.DeleteFinishedBefore(ctx, time.Now().Add(-r.retention)); err != nil && ctx.Err() == nil {
+		slog.ErrorContext(ctx, "Failed to delete old jobs", "error", err)
+	}
+}
+
+// Health returns an error unless Run is running and every worker has polled
+// for jobs or sent a heartbeat for its job recently
+func (r *Runner) Health() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.running {
+		return errors.New("job runner is not running")
+	}
+	for i, seen := range r.workerSeen {
+		if idle := time.Since(seen); idle > r.staleAfter {
+			return fmt.Errorf("job worker %d unresponsive for %s", i, idle.Round(time.Second))
+		}
+	}
+	return nil
+}
+
+// markSeen records that worker is alive; RunPending runs as worker -1
+func (r *Runner) markSeen(worker int) {
+	if worker < 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if worker < len(r.workerSeen) {
+		r.workerSeen[worker] = time.Now()
+	}
+}
+
+func (r *Runner) work(ctx context.Context, worker int) {
+	for {
+		r.markSeen(worker)
+		// Keep claiming while there is work before waiting for the next poll
+		for {
+			ran, err := r.runNext(ctx, worker)
+			if err != nil && ctx.Err() == nil {
+				slog.ErrorContext(ctx, "Failed to claim job", "worker", worker, "error", err)
+			}
+			if !ran || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.pollInterval):
+		}
+	}
+}
+
+// RunPending runs queued jobs that are due, one after another, until none are
+// left and returns how many it ran
+func (r *Runner) RunPending(ctx context.Context) (int, error) {
+	count := 0
+	for {
+		ran, err := r.runNext(ctx, -1)
+		if err != nil || !ran {
+			return count, err
+		}
+		count++
+	}
+}
+
+func (r *Runner) runNext(ctx context.Context, worker int) (bool, error) {
+	job, err := r.jobs.Claim(ctx, r.workerID, r.Types())
+	if err != nil || job == nil {
+		return false, err
+	}
+	r.execute(ctx, job, worker)
+	return true, nil
+}
+
+// execute runs a claimed job and records how it ended
+func (r *Runner) execute(ctx context.Context, job *models.Job, worker int) {
+	// Shutdown (ctx cancelled) leaves the job the grace period to finish
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+	stopGrace := context.AfterFunc(ctx, func() {
+		time.AfterFunc(r.shutdownGrace, cancel)
+	})
+	defer stopGrace()
+	progress := &Progress{}
+
+	// Heartbeats save progress and notice cancellation while the job runs;
+	// wasCancelled is read only after the heartbeat goroutine has exited
+	wasCancelled := false
+	done := make(chan struct{})
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		ticker := time.NewTicker(r.heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				r.markSeen(worker)
+				percent, message := progress.snapshot()
+				stop, err := r.jobs.Heartbeat(context.WithoutCancel(ctx), job.ID, percent, message)
+				if err != nil {
+					slog.ErrorContext(jobCtx, "Failed to record job heartbeat", "job_id", job.ID, "job_type", job.Type, "error", err)
+					continue
+				}
+				if stop {
+					wasCancelled = true
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	result, err := r.call(jobCtx, job, progress)
+	close(done)
+	<-heartbeatDone
+
+	// Record the outcome even when shutdown cancelled ctx
+	saveCtx := context.WithoutCancel(ctx)
+	switch {
+	case wasCancelled:
+		err = r.jobs.MarkCancelled(saveCtx, job.ID)
+	case err == nil:
+		err = r.complete(saveCtx, job, result)
+	case ctx.Err() != nil && jobCtx.Err() != nil:
+		// Interrupted by shutdown: another start picks it up again
+		now := time.Now()
+		err = r.jobs.Fail(saveCtx, job.ID, "interrupted by server shutdown", &now)
+	default:
+		slog.ErrorContext(jobCtx, "Job attempt failed",
+			"job_id", job.ID, "job_type", job.Type, "attempt", job.Attempts, "error", err)
+		var permanent *permanentError
+		var retryAt *time.Time
+		if !errors.As(err, &permanent) && job.Attempts < job.MaxAttempts {
+			next := time.Now().Add(retryDelay(job.Attempts))
+			retryAt = &next
+		}
+		err = r.jobs.Fail(saveCtx, job.ID, err.Error(), retryAt)
+	}
+	if err != nil {
+		slog.ErrorContext(saveCtx, "Failed to record job result", "job_id", job.ID, "job_type", job.Type, "error", err)
+	}
+}
+
+// call runs the handler, turning a panic into an error
+func (r *Runner) call(ctx context.Context, job *models.Job, progress *Progress) (result *Result, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job panicked: %v", p)
+		}
+	}()
+	return r.handlers[job.Type](ctx, job, progress)
+}
+
+func (r *Runner) complete(ctx context.Context, job *models.Job, result *Result) error {
+	var data []byte
+	var file *models.JobFile
+	if result != nil {
+		file = result.File
+		if result.Data != nil {
+			var err error
+			if data, err = json.Marshal(result.Data); err != nil {
+				return r.jobs.Fail(ctx, job.ID, fmt.Sprintf("failed to encode job result: %v", err), nil)
+			}
+		}
+	}
+	return r.jobs.Complete(ctx, job.ID, data, file)
+}
+
+// retryDelay backs off quadratically: 30s, 2m, 4.5m, ... up to 30 minutes
+func retryDelay(attempt int) time.Duration {
+	delay := time.Duration(attempt*attempt) * 30 * time.Second
+	if delay > 30*time.Minute {
+		delay = 30 * time.Minute
+	}
+	return delay
+}
+
+// RunDueSchedules queues a job for every enabled schedule due at now and
+// returns how many it queued. A run missed while the server was down is
+// queued once, not once per missed occurrence.
+func (r *Runner) RunDueSchedules(ctx context.Context, now time.Time) (int, error) {
+	schedules, err := r.schedules.List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list job schedules: %w", err)
+	}
+	queued := 0
+	for _, schedule := range schedules {
+		if !schedule.Enabled || schedule.NextRunAt.After(now) {
+			continue
+		}
+		sched, err := cron.Parse(schedule.Cron)
+		if err != nil {
+			slog.WarnContext(ctx, "Skipping job schedule with an invalid cron", "schedule", schedule.Name, "error", err)
+			continue
+		}
+		// Advancing first means only one instance queues each run
+		advanced, err := r.schedules.Advance(ctx, schedule.Name, schedule.NextRunAt, now, sched.Next(now.In(r.location)))
+		if err != nil {
+			return queued, fmt.Errorf("failed to advance job schedule %s: %w", schedule.Name, err)
+		}
+		if !advanced {
+			continue
+		}
+		opts := EnqueueOptions{ScheduleName: schedule.Name}
+		if len(schedule.Payload) > 0 {
+			opts.Payload = json.RawMessage(schedule.Payload)
+		}
+		if _, err := r.Enqueue(ctx, schedule.JobType, opts); err != nil {
+			return queued, fmt.Errorf("failed to queue job for schedule %s: %w", schedule.Name, err)
+		}
+		queued++
+	}
+	return queued, nil
+}
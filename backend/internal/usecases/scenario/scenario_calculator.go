@@ -1,6 +1,7 @@
 package scenario
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -33,6 +34,7 @@ func NewScenarioCalculator(repos *RepositoriesWrapper) *ScenarioCalculator {
 
 // CalculateStaffRequirements calculates staff requirements for a position based on scenarios
 func (c *ScenarioCalculator) CalculateStaffRequirements(
+	ctx context.Context,
 	branchID uuid.UUID,
 	date time.Time,
 	positionID uuid.UUID,
@@ -42,7 +44,7 @@ func (c *ScenarioCalculator) CalculateStaffRequirements(
 	dayOfWeek := int(date.Weekday())
 
 	// Get day-of-week baseline revenue
-	dayOfWeekRevenue, err := c.repos.BranchWeeklyRevenue.GetByBranchIDAndDayOfWeek(branchID, dayOfWeek)
+	dayOfWeekRevenue, err := c.repos.BranchWeeklyRevenue.GetByBranchIDAndDayOfWeek(ctx, branchID, dayOfWeek)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get day-of-week revenue: %w", err)
 	}
@@ -61,44 +63,14 @@ func (c *ScenarioCalculator) CalculateStaffRequirements(
 		}
 	}
 
-	// Get specific date revenue (if available)
-	specificDateRevenue, err := c.repos.Revenue.GetByBranchID(branchID, date, date)
+	// Get specific date revenue (if available) and the doctors working that day
+	specificDateRevenueValue, doctorCount, err := c.specificDateRevenue(ctx, branchID, date)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get specific date revenue: %w", err)
-	}
-	var specificDateRevenueValue *float64
-	if len(specificDateRevenue) > 0 {
-		revenue := specificDateRevenue[0]
-		// Prefer actual revenue if available, otherwise use calculated total from all types
-		if revenue.ActualRevenue != nil && *revenue.ActualRevenue > 0 {
-			value := *revenue.ActualRevenue
-			specificDateRevenueValue = &value
-		} else {
-			// Calculate total revenue from all 4 types
-			totalValue := c.calculateTotalRevenueValue(
-				revenue.SkinRevenue,
-				revenue.LSHMRevenue,
-				revenue.VitaminCases,
-				revenue.SlimPenCases,
-			)
-			// Fallback to ExpectedRevenue for backward compatibility if new fields are zero
-			if totalValue == 0 && revenue.ExpectedRevenue > 0 {
-				totalValue = revenue.ExpectedRevenue
-			}
-			if totalValue > 0 {
-				specificDateRevenueValue = &totalValue
-			}
-		}
-	}
-
-	// Get doctor count for the date
-	doctorCount, err := c.repos.DoctorAssignment.GetDoctorCountByBranch(branchID, date)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get doctor count: %w", err)
+		return nil, err
 	}
 
 	// Get position name
-	position, err := c.repos.Position.GetByID(positionID)
+	position, err := c.repos.Position.GetByID(ctx, positionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get position: %w", err)
 	}
@@ -107,7 +79,7 @@ func (c *ScenarioCalculator) CalculateStaffRequirements(
 	}
 
 	// Find matching scenarios (ordered by priority DESC)
-	scenarios, err := c.repos.StaffRequirementScenario.GetActiveOrderedByPriority()
+	scenarios, err := c.repos.StaffRequirementScenario.GetActiveOrderedByPriority(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get scenarios: %w", err)
 	}
@@ -116,9 +88,9 @@ func (c *ScenarioCalculator) CalculateStaffRequirements(
 	var factorsApplied []string
 
 	for _, scenario := range scenarios {
-		if c.matchesScenario(scenario, dayOfWeekRevenueValue, specificDateRevenueValue, doctorCount, dayOfWeek) {
+		if c.matchesScenario(ctx, scenario, dayOfWeekRevenueValue, specificDateRevenueValue, doctorCount, dayOfWeek) {
 			matchedScenario = scenario
-			factorsApplied = c.buildMatchReason(scenario, dayOfWeekRevenueValue, specificDateRevenueValue, doctorCount)
+			factorsApplied = c.buildMatchReason(ctx, scenario, dayOfWeekRevenueValue, specificDateRevenueValue, doctorCount)
 			break // Use highest priority matching scenario
 		}
 	}
@@ -129,7 +101,7 @@ func (c *ScenarioCalculator) CalculateStaffRequirements(
 
 	if matchedScenario != nil {
 		// Get position requirements for this scenario
-		requirement, err := c.repos.ScenarioPositionRequirement.GetByScenarioAndPosition(matchedScenario.ID, positionID)
+		requirement, err := c.repos.ScenarioPositionRequirement.GetByScenarioAndPosition(ctx, matchedScenario.ID, positionID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get scenario requirement: %w", err)
 		}
@@ -167,6 +139,7 @@ func (c *ScenarioCalculator) CalculateStaffRequirements(
 
 // matchesScenario checks if a scenario matches the given conditions
 func (c *ScenarioCalculator) matchesScenario(
+	ctx context.Context,
 	scenario *models.StaffRequirementScenario,
 	dayOfWeekRevenue float64,
 	specificDateRevenue *float64,
@@ -195,7 +168,7 @@ func (c *ScenarioCalculator) matchesScenario(
 
 	// Check revenue tier match
 	if scenario.RevenueLevelTierID != nil {
-		tier, err := c.repos.RevenueLevelTier.GetTierForRevenue(revenueToCheck)
+		tier, err := c.repos.RevenueLevelTier.GetTierForRevenue(ctx, revenueToCheck)
 		if err != nil || tier == nil || tier.ID != *scenario.RevenueLevelTierID {
 			return false
 		}
@@ -222,6 +195,7 @@ func (c *ScenarioCalculator) matchesScenario(
 
 // buildMatchReason builds a human-readable reason for why a scenario matched
 func (c *ScenarioCalculator) buildMatchReason(
+	ctx context.Context,
 	scenario *models.StaffRequirementScenario,
 	dayOfWeekRevenue float64,
 	specificDateRevenue *float64,
@@ -245,7 +219,7 @@ func (c *ScenarioCalculator) buildMatchReason(
 
 	// Add revenue tier reason
 	if scenario.RevenueLevelTierID != nil {
-		tier, err := c.repos.RevenueLevelTier.GetByID(*scenario.RevenueLevelTierID)
+		tier, err := c.repos.RevenueLevelTier.GetByID(ctx, *scenario.RevenueLevelTierID)
 		if err == nil && tier != nil {
 			reasons = append(reasons, fmt.Sprintf("Revenue Level %d (%s) from %s revenue", tier.LevelNumber, tier.LevelName, revenueSource))
 		}
@@ -300,15 +274,69 @@ func (c *ScenarioCalculator) calculateTotalRevenueValue(
 	return total
 }
 
+// specificDateRevenue returns the branch's revenue value for date, nil when
+// there is none, and the number of doctors working there. Branches without
+// entered revenue, or with doctor-sourced revenue, use the summed expected
+// revenue of their doctors that day.
+func (c *ScenarioCalculator) specificDateRevenue(ctx context.Context, branchID uuid.UUID, date time.Time) (*float64, int, error) {
+	records, err := c.repos.Revenue.GetByBranchID(ctx, branchID, date, date)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get specific date revenue: %w", err)
+	}
+	doctors, err := c.repos.DoctorAssignment.GetDoctorsByBranchAndDate(ctx, branchID, date)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get doctor count: %w", err)
+	}
+
+	if len(records) == 0 || records[0].RevenueSource == "doctor" {
+		var sum models.DoctorRevenue
+		for _, assignment := range doctors {
+			if assignment.Revenue != nil {
+				sum = sum.Add(*assignment.Revenue)
+			}
+		}
+		totalValue := c.calculateTotalRevenueValue(sum.SkinRevenue, sum.LSHMRevenue, sum.VitaminCases, sum.SlimPenCases)
+		if totalValue > 0 {
+			return &totalValue, len(doctors), nil
+		}
+		if len(records) == 0 {
+			return nil, len(doctors), nil
+		}
+	}
+
+	revenue := records[0]
+	// Prefer actual revenue if available, otherwise use calculated total from all types
+	if revenue.ActualRevenue != nil && *revenue.ActualRevenue > 0 {
+		value := *revenue.ActualRevenue
+		return &value, len(doctors), nil
+	}
+	// Calculate total revenue from all 4 types
+	totalValue := c.calculateTotalRevenueValue(
+		revenue.SkinRevenue,
+		revenue.LSHMRevenue,
+		revenue.VitaminCases,
+		revenue.SlimPenCases,
+	)
+	// Fallback to ExpectedRevenue for backward compatibility if new fields are zero
+	if totalValue == 0 && revenue.ExpectedRevenue > 0 {
+		totalValue = revenue.ExpectedRevenue
+	}
+	if totalValue > 0 {
+		return &totalValue, len(doctors), nil
+	}
+	return nil, len(doctors), nil
+}
+
 // GetMatchingScenarios returns all scenarios that match the given conditions
 func (c *ScenarioCalculator) GetMatchingScenarios(
+	ctx context.Context,
 	branchID uuid.UUID,
 	date time.Time,
 ) ([]*models.ScenarioMatch, error) {
 	dayOfWeek := int(date.Weekday())
 
 	// Get day-of-week baseline revenue
-	dayOfWeekRevenue, err := c.repos.BranchWeeklyRevenue.GetByBranchIDAndDayOfWeek(branchID, dayOfWeek)
+	dayOfWeekRevenue, err := c.repos.BranchWeeklyRevenue.GetByBranchIDAndDayOfWeek(ctx, branchID, dayOfWeek)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get day-of-week revenue: %w", err)
 	}
@@ -327,54 +355,24 @@ func (c *ScenarioCalculator) GetMatchingScenarios(
 		}
 	}
 
-	// Get specific date revenue (if available)
-	specificDateRevenue, err := c.repos.Revenue.GetByBranchID(branchID, date, date)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get specific date revenue: %w", err)
-	}
-	var specificDateRevenueValue *float64
-	if len(specificDateRevenue) > 0 {
-		revenue := specificDateRevenue[0]
-		// Prefer actual revenue if available, otherwise use calculated total from all types
-		if revenue.ActualRevenue != nil && *revenue.ActualRevenue > 0 {
-			value := *revenue.ActualRevenue
-			specificDateRevenueValue = &value
-		} else {
-			// Calculate total revenue from all 4 types
-			totalValue := c.calculateTotalRevenueValue(
-				revenue.SkinRevenue,
-				revenue.LSHMRevenue,
-				revenue.VitaminCases,
-				revenue.SlimPenCases,
-			)
-			// Fallback to ExpectedRevenue for backward compatibility if new fields are zero
-			if totalValue == 0 && revenue.ExpectedRevenue > 0 {
-				totalValue = revenue.ExpectedRevenue
-			}
-			if totalValue > 0 {
-				specificDateRevenueValue = &totalValue
-			}
-		}
-	}
-
-	// Get doctor count for the date
-	doctorCount, err := c.repos.DoctorAssignment.GetDoctorCountByBranch(branchID, date)
+	// Get specific date revenue (if available) and the doctors working that day
+	specificDateRevenueValue, doctorCount, err := c.specificDateRevenue(ctx, branchID, date)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get doctor count: %w", err)
+		return nil, err
 	}
 
 	// Get all active scenarios
-	scenarios, err := c.repos.StaffRequirementScenario.GetActiveOrderedByPriority()
+	scenarios, err := c.repos.StaffRequirementScenario.GetActiveOrderedByPriority(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get scenarios: %w", err)
 	}
 
 	matches := []*models.ScenarioMatch{}
 	for _, scenario := range scenarios {
-		matchesScenario := c.matchesScenario(scenario, dayOfWeekRevenueValue, specificDateRevenueValue, doctorCount, dayOfWeek)
+		matchesScenario := c.matchesScenario(ctx, scenario, dayOfWeekRevenueValue, specificDateRevenueValue, doctorCount, dayOfWeek)
 		matchReason := ""
 		if matchesScenario {
-			reasons := c.buildMatchReason(scenario, dayOfWeekRevenueValue, specificDateRevenueValue, doctorCount)
+			reasons := c.buildMatchReason(ctx, scenario, dayOfWeekRevenueValue, specificDateRevenueValue, doctorCount)
 			matchReason = fmt.Sprintf("%v", reasons)
 		}
 
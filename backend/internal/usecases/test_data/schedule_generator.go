@@ -1,6 +1,7 @@
 package test_data
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"time"
@@ -76,13 +77,13 @@ type scheduleEntry struct {
 }
 
 // GenerateSchedules generates schedules for all branch staff across all branches
-func (g *ScheduleGenerator) GenerateSchedules(req GenerateSchedulesRequest) (*GenerateSchedulesResult, error) {
+func (g *ScheduleGenerator) GenerateSchedules(ctx context.Context, req GenerateSchedulesRequest) (*GenerateSchedulesResult, error) {
 	result := &GenerateSchedulesResult{
 		Errors: []string{},
 	}
 
 	// Get all branches
-	allBranches, err := g.repos.Branch.List()
+	allBranches, err := g.repos.Branch.List(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get branches: %w", err)
 	}
@@ -110,7 +111,7 @@ func (g *ScheduleGenerator) GenerateSchedules(req GenerateSchedulesRequest) (*Ge
 	}
 
 	// Get all branch staff
-	allStaff, err := g.repos.Staff.List(interfaces.StaffFilters{
+	allStaff, err := g.repos.Staff.List(ctx, interfaces.StaffFilters{
 		StaffType: &[]models.StaffType{models.StaffTypeBranch}[0],
 	})
 	if err != nil {
@@ -162,7 +163,7 @@ func (g *ScheduleGenerator) GenerateSchedules(req GenerateSchedulesRequest) (*Ge
 
 		// Generate schedules for each staff member in this branch
 		for _, staff := range staffList {
-			schedules, err := g.generateSchedulesForStaff(staff, branch, req.StartDate, req.EndDate, branchRules, req.OverwriteExisting, req.CreatedBy)
+			schedules, err := g.generateSchedulesForStaff(ctx, staff, branch, req.StartDate, req.EndDate, branchRules, req.OverwriteExisting, req.CreatedBy)
 			if err != nil {
 				result.Errors = append(result.Errors, fmt.Sprintf("Error generating schedules for staff %s (%s): %v", staff.ID, staff.Name, err))
 				continue
@@ -182,7 +183,7 @@ func (g *ScheduleGenerator) GenerateSchedules(req GenerateSchedulesRequest) (*Ge
 
 	// Enforce minimum staff per group if enabled
 	if req.Rules.EnforceMinStaffPerGroup {
-		if err := g.enforceMinimumStaffPerGroup(allSchedules, branches, req.StartDate, req.EndDate); err != nil {
+		if err := g.enforceMinimumStaffPerGroup(ctx, allSchedules, branches, req.StartDate, req.EndDate); err != nil {
 			result.Errors = append(result.Errors, fmt.Sprintf("Error enforcing minimum staff per group: %v", err))
 		}
 	}
@@ -190,7 +191,7 @@ func (g *ScheduleGenerator) GenerateSchedules(req GenerateSchedulesRequest) (*Ge
 	// Commit all schedules to database
 	for _, dateSchedules := range allSchedules {
 		for _, entry := range dateSchedules {
-			if err := g.repos.Schedule.Create(entry.schedule); err != nil {
+			if err := g.repos.Schedule.Create(ctx, entry.schedule); err != nil {
 				result.Errors = append(result.Errors, fmt.Sprintf("Error creating schedule for staff %s on %s: %v", entry.staff.ID, entry.schedule.Date.Format("2006-01-02"), err))
 				continue
 			}
@@ -212,6 +213,7 @@ func (g *ScheduleGenerator) GenerateSchedules(req GenerateSchedulesRequest) (*Ge
 
 // generateSchedulesForStaff generates schedules for a single staff member
 func (g *ScheduleGenerator) generateSchedulesForStaff(
+	ctx context.Context,
 	staff *models.Staff,
 	branch *models.Branch,
 	startDate, endDate time.Time,
@@ -224,7 +226,7 @@ func (g *ScheduleGenerator) generateSchedulesForStaff(
 	// Get existing schedules if not overwriting
 	existingSchedules := make(map[string]*models.StaffSchedule)
 	if !overwriteExisting {
-		existing, err := g.repos.Schedule.GetByStaffID(staff.ID, startDate, endDate)
+		existing, err := g.repos.Schedule.GetByStaffID(ctx, staff.ID, startDate, endDate)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get existing schedules: %w", err)
 		}
@@ -395,12 +397,13 @@ func (g *ScheduleGenerator) generateSchedulesForStaff(
 
 // enforceMinimumStaffPerGroup ensures minimum staff per group requirements are met
 func (g *ScheduleGenerator) enforceMinimumStaffPerGroup(
+	ctx context.Context,
 	allSchedules map[string][]*scheduleEntry,
 	branches []*models.Branch,
 	startDate, endDate time.Time,
 ) error {
 	// Get all staff groups and their positions
-	staffGroups, err := g.repos.StaffGroup.List()
+	staffGroups, err := g.repos.StaffGroup.List(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get staff groups: %w", err)
 	}
@@ -411,7 +414,7 @@ func (g *ScheduleGenerator) enforceMinimumStaffPerGroup(
 		if !sg.IsActive {
 			continue
 		}
-		positions, err := g.repos.StaffGroupPosition.GetByStaffGroupID(sg.ID)
+		positions, err := g.repos.StaffGroupPosition.GetByStaffGroupID(ctx, sg.ID)
 		if err != nil {
 			continue
 		}
@@ -441,13 +444,13 @@ func (g *ScheduleGenerator) enforceMinimumStaffPerGroup(
 			}
 
 			// Get branch constraints for this day of week
-			constraints, err := g.repos.BranchConstraints.GetByBranchID(branch.ID)
+			constraints, err := g.repos.BranchConstraints.GetByBranchID(ctx, branch.ID)
 			if err != nil {
 				continue
 			}
 
 			// Load staff group requirements
-			if err := g.repos.BranchConstraints.LoadStaffGroupRequirements(constraints); err != nil {
+			if err := g.repos.BranchConstraints.LoadStaffGroupRequirements(ctx, constraints); err != nil {
 				continue
 			}
 
@@ -0,0 +1,113 @@
+package apitoken
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// TokenPrefix marks strings issued by this service so they are easy to spot in logs and secret scanners
+const TokenPrefix = "vsq"
+
+// ScopeAll grants every scope
+const ScopeAll = "*"
+
+// Generate creates a new random API token.
+// It returns the plaintext token (shown to the caller once), its lookup prefix and its SHA-256 hash.
+func Generate() (token string, prefix string, hash string, err error) {
+	prefixBytes := make([]byte, 4)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate token prefix: %w", err)
+	}
+	secretBytes := make([]byte, 32)
+	if _, err := rand.Read(secretBytes); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate token secret: %w", err)
+	}
+
+	prefix = hex.EncodeToString(prefixBytes)
+	token = fmt.Sprintf("%s_%s_%s", TokenPrefix, prefix, base64.RawURLEncoding.EncodeToString(secretBytes))
+	return token, prefix, Hash(token), nil
+}
+
+// Hash returns the hex-encoded SHA-256 hash of a plaintext token
+func Hash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParsePrefix extracts the lookup prefix from a plaintext token
+func ParsePrefix(token string) (string, bool) {
+	parts := strings.SplitN(token, "_", 3)
+	if len(parts) != 3 || parts[0] != TokenPrefix || parts[1] == "" || parts[2] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// FromAuthorizationHeader extracts a bearer token from an Authorization header value
+func FromAuthorizationHeader(header string) (string, bool) {
+	const bearer = "Bearer "
+	if len(header) <= len(bearer) || !strings.EqualFold(header[:len(bearer)], bearer) {
+		return "", false
+	}
+	token := strings.TrimSpace(header[len(bearer):])
+	return token, token != ""
+}
+
+// RequiredScope returns the scope needed for a request.
+// Scopes have the form "<resource>:<read|write>", where resource is the first path
+// segment after /api (e.g. "GET /api/branches/:id/revenue" needs "branches:read").
+func RequiredScope(method, path string) string {
+	trimmed := strings.TrimPrefix(path, "/api/")
+	resource := strings.SplitN(trimmed, "/", 2)[0]
+	if resource == "" {
+		resource = "api"
+	}
+
+	access := "write"
+	if method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions {
+		access = "read"
+	}
+	return resource + ":" + access
+}
+
+// Allows reports whether any of the granted scopes satisfies the required scope.
+// "*" grants everything, "<resource>:*" grants read and write, and write implies read.
+func Allows(granted []string, required string) bool {
+	resource, access, _ := strings.Cut(required, ":")
+	for _, scope := range granted {
+		scope = strings.TrimSpace(scope)
+		if scope == ScopeAll || scope == required {
+			return true
+		}
+		gResource, gAccess, ok := strings.Cut(scope, ":")
+		if !ok || gResource != resource {
+			continue
+		}
+		if gAccess == "*" || (access == "read" && gAccess == "write") {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateScope checks that a scope string is well formed
+func ValidateScope(scope string) error {
+	if scope == ScopeAll {
+		return nil
+	}
+	resource, access, ok := strings.Cut(scope, ":")
+	if !ok || resource == "" {
+		return fmt.Errorf("invalid scope %q: expected <resource>:<read|write|*>", scope)
+	}
+	switch access {
+	case "read", "write", "*":
+		return nil
+	default:
+		return fmt.Errorf("invalid scope %q: access must be read, write or *", scope)
+	}
+}
@@ -2,7 +2,9 @@ package excel
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -44,7 +46,7 @@ func NewExcelImporter(
 // - Column C: Position Name (string, required) - e.g., "Nurse", "Doctor"
 // - Column D: Branch Code (string, optional for branch staff) - e.g., "TMA", "CPN"
 // - Column E: Nickname (string, optional)
-func (e *ExcelImporter) ImportStaff(fileData []byte) ([]*models.Staff, error) {
+func (e *ExcelImporter) ImportStaff(ctx context.Context, fileData []byte) ([]*models.Staff, error) {
 	// Open Excel file from byte data
 	f, err := excelize.OpenReader(bytes.NewReader(fileData))
 	if err != nil {
@@ -127,7 +129,7 @@ func (e *ExcelImporter) ImportStaff(fileData []byte) ([]*models.Staff, error) {
 		// Column C: Position Name (lookup by name)
 		if len(row) > 2 && strings.TrimSpace(row[2]) != "" {
 			positionName := strings.TrimSpace(row[2])
-			position, err := e.findPositionByName(positionName)
+			position, err := e.findPositionByName(ctx, positionName)
 			if err != nil {
 				errors = append(errors, fmt.Sprintf("Row %d: position '%s' not found: %v", i+1, positionName, err))
 				continue
@@ -141,7 +143,7 @@ func (e *ExcelImporter) ImportStaff(fileData []byte) ([]*models.Staff, error) {
 		// Column D: Branch Code (optional, mainly for branch staff)
 		if len(row) > 3 && strings.TrimSpace(row[3]) != "" {
 			branchCode := strings.TrimSpace(row[3])
-			branch, err := e.findBranchByCode(branchCode)
+			branch, err := e.findBranchByCode(ctx, branchCode)
 			if err != nil {
 				errors = append(errors, fmt.Sprintf("Row %d: branch code '%s' not found: %v", i+1, branchCode, err))
 				continue
@@ -179,8 +181,8 @@ func (e *ExcelImporter) ImportStaff(fileData []byte) ([]*models.Staff, error) {
 }
 
 // findPositionByName looks up a position by name (case-insensitive)
-func (e *ExcelImporter) findPositionByName(name string) (*models.Position, error) {
-	positions, err := e.positionRepo.List()
+func (e *ExcelImporter) findPositionByName(ctx context.Context, name string) (*models.Position, error) {
+	positions, err := e.positionRepo.List(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load positions: %w", err)
 	}
@@ -195,8 +197,8 @@ func (e *ExcelImporter) findPositionByName(name string) (*models.Position, error
 }
 
 // findBranchByCode looks up a branch by code (case-insensitive)
-func (e *ExcelImporter) findBranchByCode(code string) (*models.Branch, error) {
-	branches, err := e.branchRepo.List()
+func (e *ExcelImporter) findBranchByCode(ctx context.Context, code string) (*models.Branch, error) {
+	branches, err := e.branchRepo.List(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load branches: %w", err)
 	}
@@ -239,7 +241,7 @@ func (e *ExcelImporter) ValidateStaffData(staff *models.Staff) error {
 // - Column A: Name (required)
 // - Column B: Code (optional) - doctor code/nickname
 // - Column C: Preferences (optional) - noted remark/preferences
-func (e *ExcelImporter) ImportDoctors(fileData []byte) ([]*models.Doctor, error) {
+func (e *ExcelImporter) ImportDoctors(ctx context.Context, fileData []byte) ([]*models.Doctor, error) {
 	// Open Excel file from byte data
 	f, err := excelize.OpenReader(bytes.NewReader(fileData))
 	if err != nil {
@@ -312,7 +314,7 @@ func (e *ExcelImporter) ImportDoctors(fileData []byte) ([]*models.Doctor, error)
 		if len(row) > 1 && strings.TrimSpace(row[1]) != "" {
 			doctor.Code = strings.TrimSpace(row[1])
 			// Check if code already exists
-			existing, err := e.doctorRepo.GetByCode(doctor.Code)
+			existing, err := e.doctorRepo.GetByCode(ctx, doctor.Code)
 			if err == nil && existing != nil {
 				errors = append(errors, fmt.Sprintf("Row %d: doctor code '%s' already exists", i+1, doctor.Code))
 				continue
@@ -375,7 +377,7 @@ type ImportDefaultSchedulesResult struct {
 // - Column C: Branch Code or Branch Name (optional - empty or "OFF"/"Off Day" means off day)
 // Note: If a doctor has duplicate branches on the same workday, the last imported entry will be used.
 // Empty branch or "OFF" means the doctor is off on that day (existing schedule will be deleted).
-func (e *ExcelImporter) ImportDefaultSchedules(fileData []byte) (*ImportDefaultSchedulesResult, error) {
+func (e *ExcelImporter) ImportDefaultSchedules(ctx context.Context, fileData []byte) (*ImportDefaultSchedulesResult, error) {
 	// Open Excel file from byte data
 	f, err := excelize.OpenReader(bytes.NewReader(fileData))
 	if err != nil {
@@ -453,7 +455,7 @@ func (e *ExcelImporter) ImportDefaultSchedules(fileData []byte) (*ImportDefaultS
 		}
 
 		// Find doctor by code
-		doctor, err := e.doctorRepo.GetByCode(doctorCode)
+		doctor, err := e.doctorRepo.GetByCode(ctx, doctorCode)
 		if err != nil || doctor == nil {
 			errors = append(errors, fmt.Sprintf("Row %d: doctor code '%s' not found", i+1, doctorCode))
 			continue
@@ -517,7 +519,7 @@ func (e *ExcelImporter) ImportDefaultSchedules(fileData []byte) (*ImportDefaultS
 		}
 
 		// Find branch by code or name
-		branch, err := e.findBranchByCodeOrName(branchIdentifier)
+		branch, err := e.findBranchByCodeOrName(ctx, branchIdentifier)
 		if err != nil {
 			errors = append(errors, fmt.Sprintf("Row %d: branch '%s' not found: %v", i+1, branchIdentifier, err))
 			continue
@@ -564,8 +566,8 @@ func (e *ExcelImporter) ImportDefaultSchedules(fileData []byte) (*ImportDefaultS
 
 // findBranchByCodeOrName looks up a branch by code or name (case-insensitive)
 // Tries code first, then name if code doesn't match
-func (e *ExcelImporter) findBranchByCodeOrName(identifier string) (*models.Branch, error) {
-	branches, err := e.branchRepo.List()
+func (e *ExcelImporter) findBranchByCodeOrName(ctx context.Context, identifier string) (*models.Branch, error) {
+	branches, err := e.branchRepo.List(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load branches: %w", err)
 	}
@@ -589,6 +591,226 @@ func (e *ExcelImporter) findBranchByCodeOrName(identifier string) (*models.Branc
 	return nil, fmt.Errorf("branch code or name '%s' not found", identifier)
 }
 
+// DoctorRosterCell is one doctor's day in an imported monthly roster
+type DoctorRosterCell struct {
+	Row        int
+	DoctorID   uuid.UUID
+	DoctorCode string
+	Date       time.Time
+	Off        bool
+	BranchIDs  []uuid.UUID // One per slot, morning first, when the cell names two branches
+}
+
+// ImportDoctorRosterResult contains the parsed roster cells and the dates the roster covers
+type ImportDoctorRosterResult struct {
+	StartDate time.Time
+	EndDate   time.Time
+	Cells     []*DoctorRosterCell
+}
+
+// rosterDateLayouts are the date header formats accepted in a roster grid:
+// the exported format, ISO dates, Thai day-first dates and Excel's default
+var rosterDateLayouts = []string{"Mon 2006-01-02", "2006-01-02", "2/1/2006", "01-02-06"}
+
+// ImportDoctorRoster parses a monthly doctor roster grid, doctors by dates
+// Expected format (the layout WriteDoctorRoster produces):
+// - Header row: the first row whose first cell mentions "doctor" or "code"; rows above it are skipped
+// - Column A: Doctor Code (required)
+// - Other columns: one per date, headed by the date (e.g. "Mon 2026-11-02"); others such as Doctor Name are ignored
+// - Cells: Branch Code or Branch Name, "MORNING/AFTERNOON" for a split day, or "OFF"/"Off Day"
+// An empty cell leaves the doctor's day as it is. If a doctor appears on more
+// than one row, the last entry for each date is used.
+func (e *ExcelImporter) ImportDoctorRoster(ctx context.Context, fileData []byte) (*ImportDoctorRosterResult, error) {
+	// Open Excel file from byte data
+	f, err := excelize.OpenReader(bytes.NewReader(fileData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Excel file: %w", err)
+	}
+	defer f.Close()
+
+	// Get the first sheet
+	sheetName := f.GetSheetName(0)
+	if sheetName == "" {
+		return nil, fmt.Errorf("Excel file has no sheets")
+	}
+
+	rows, err := f.GetRows(sheetName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rows: %w", err)
+	}
+
+	// Find the header row and its date columns: the first row headed Doctor
+	// Code that has dates in it, so a title such as "Doctor roster, May 2026"
+	// above it is skipped
+	headerRow := -1
+	titled := false
+	var dates map[int]time.Time
+	result := &ImportDoctorRosterResult{}
+	for i, row := range rows {
+		if len(row) == 0 {
+			continue
+		}
+		firstCell := strings.ToLower(strings.TrimSpace(row[0]))
+		if !strings.Contains(firstCell, "doctor") && !strings.Contains(firstCell, "code") {
+			continue
+		}
+		titled = true
+		dates = rosterDateColumns(row)
+		if len(dates) > 0 {
+			headerRow = i
+			break
+		}
+	}
+	if !titled {
+		return nil, fmt.Errorf("header row not found (first column must be Doctor Code)")
+	}
+	if headerRow < 0 {
+		return nil, fmt.Errorf("header row has no date columns")
+	}
+	for _, date := range dates {
+		if result.StartDate.IsZero() || date.Before(result.StartDate) {
+			result.StartDate = date
+		}
+		if date.After(result.EndDate) {
+			result.EndDate = date
+		}
+	}
+	if len(rows) <= headerRow+1 {
+		return nil, fmt.Errorf("Excel file must have at least one data row")
+	}
+
+	var errors []string
+	doctors := make(map[string]*models.Doctor)
+	branches := make(map[string]*models.Branch)
+	cellMap := make(map[string]*DoctorRosterCell) // doctorID-date -> last entry
+
+	// Process data rows after the header
+	for i := headerRow + 1; i < len(rows); i++ {
+		row := rows[i]
+		if len(row) == 0 {
+			continue
+		}
+
+		// Column A: Doctor Code (required)
+		doctorCode := strings.TrimSpace(row[0])
+		if doctorCode == "" {
+			errors = append(errors, fmt.Sprintf("Row %d: doctor code is required", i+1))
+			continue
+		}
+		doctor, ok := doctors[doctorCode]
+		if !ok {
+			doctor, err = e.doctorRepo.GetByCode(ctx, doctorCode)
+			if err != nil {
+				doctor = nil
+			}
+			doctors[doctorCode] = doctor
+		}
+		if doctor == nil {
+			errors = append(errors, fmt.Sprintf("Row %d: doctor code '%s' not found", i+1, doctorCode))
+			continue
+		}
+
+		for col, date := range dates {
+			if col >= len(row) {
+				continue
+			}
+			value := strings.TrimSpace(row[col])
+			if value == "" {
+				continue
+			}
+
+			cell := &DoctorRosterCell{
+				Row:        i + 1,
+				DoctorID:   doctor.ID,
+				DoctorCode: doctor.Code,
+				Date:       date,
+				Off:        isOffValue(value),
+			}
+			if !cell.Off {
+				identifiers := strings.Split(value, "/")
+				if len(identifiers) > 2 {
+					errors = append(errors, fmt.Sprintf("Row %d: %s: at most two branches per day, got '%s'", i+1, date.Format("2006-01-02"), value))
+					continue
+				}
+				for _, identifier := range identifiers {
+					identifier = strings.TrimSpace(identifier)
+					branch, ok := branches[strings.ToLower(identifier)]
+					if !ok {
+						branch, err = e.findBranchByCodeOrName(ctx, identifier)
+						if err != nil {
+							errors = append(errors, fmt.Sprintf("Row %d: %s: branch '%s' not found: %v", i+1, date.Format("2006-01-02"), identifier, err))
+							cell = nil
+							break
+						}
+						branches[strings.ToLower(identifier)] = branch
+					}
+					cell.BranchIDs = append(cell.BranchIDs, branch.ID)
+				}
+				if cell == nil {
+					continue
+				}
+			}
+			cellMap[fmt.Sprintf("%s-%s", doctor.ID, date.Format("2006-01-02"))] = cell
+		}
+	}
+
+	for _, cell := range cellMap {
+		result.Cells = append(result.Cells, cell)
+	}
+	sort.Slice(result.Cells, func(i, j int) bool {
+		if result.Cells[i].Row != result.Cells[j].Row {
+			return result.Cells[i].Row < result.Cells[j].Row
+		}
+		return result.Cells[i].Date.Before(result.Cells[j].Date)
+	})
+
+	if len(errors) > 0 && len(result.Cells) == 0 {
+		return nil, fmt.Errorf("failed to import any roster days: %s", strings.Join(errors, "; "))
+	}
+
+	if len(errors) > 0 {
+		// Return partial success with errors
+		return result, fmt.Errorf("import completed with errors: %s", strings.Join(errors, "; "))
+	}
+
+	return result, nil
+}
+
+// rosterDateColumns returns the date of each column of a roster header row
+// after the first that holds one
+func rosterDateColumns(row []string) map[int]time.Time {
+	dates := make(map[int]time.Time)
+	for col, value := range row {
+		if col == 0 {
+			continue
+		}
+		if date, ok := parseRosterDate(value); ok {
+			dates[col] = date
+		}
+	}
+	return dates
+}
+
+// parseRosterDate parses a roster grid date header
+func parseRosterDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range rosterDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// isOffValue reports whether a schedule cell marks an off day: "OFF", "Off Day", "OFF_DAY" or "offday"
+func isOffValue(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "off", "off day", "off_day", "offday":
+		return true
+	}
+	return false
+}
+
 // ImportPositionQuotasResult contains the import results
 type ImportPositionQuotasResult struct {
 	Created int      `json:"created"`
@@ -604,7 +826,7 @@ type ImportPositionQuotasResult struct {
 // - Column B: Position Code (required) - e.g., "BM", "ABM", "DA"
 // - Column C: Preferred No. (required) - designated_quota
 // - Column D: Minimum No. (required) - minimum_required
-func (e *ExcelImporter) ImportPositionQuotas(fileData []byte, createdBy uuid.UUID) (*ImportPositionQuotasResult, error) {
+func (e *ExcelImporter) ImportPositionQuotas(ctx context.Context, fileData []byte, createdBy uuid.UUID) (*ImportPositionQuotasResult, error) {
 	// Open Excel file from byte data
 	f, err := excelize.OpenReader(bytes.NewReader(fileData))
 	if err != nil {
@@ -656,7 +878,7 @@ func (e *ExcelImporter) ImportPositionQuotas(fileData []byte, createdBy uuid.UUI
 	}
 
 	// Load all positions and branches for lookup
-	positions, err := e.positionRepo.List()
+	positions, err := e.positionRepo.List(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load positions: %w", err)
 	}
@@ -667,7 +889,7 @@ func (e *ExcelImporter) ImportPositionQuotas(fileData []byte, createdBy uuid.UUI
 		}
 	}
 
-	branches, err := e.branchRepo.List()
+	branches, err := e.branchRepo.List(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load branches: %w", err)
 	}
@@ -747,7 +969,7 @@ func (e *ExcelImporter) ImportPositionQuotas(fileData []byte, createdBy uuid.UUI
 		}
 
 		// Check if quota already exists
-		existingQuota, err := e.positionQuotaRepo.GetByBranchAndPosition(branch.ID, position.ID)
+		existingQuota, err := e.positionQuotaRepo.GetByBranchAndPosition(ctx, branch.ID, position.ID)
 		if err != nil {
 			result.Errors = append(result.Errors, fmt.Sprintf("Row %d: failed to check existing quota: %v", i+1, err))
 			continue
@@ -758,7 +980,7 @@ func (e *ExcelImporter) ImportPositionQuotas(fileData []byte, createdBy uuid.UUI
 			existingQuota.DesignatedQuota = preferredNo
 			existingQuota.MinimumRequired = minimumNo
 			existingQuota.IsActive = true
-			if err := e.positionQuotaRepo.Update(existingQuota); err != nil {
+			if err := e.positionQuotaRepo.Update(ctx, existingQuota); err != nil {
 				result.Errors = append(result.Errors, fmt.Sprintf("Row %d: failed to update quota: %v", i+1, err))
 				continue
 			}
@@ -774,7 +996,7 @@ func (e *ExcelImporter) ImportPositionQuotas(fileData []byte, createdBy uuid.UUI
 				IsActive:         true,
 				CreatedBy:        createdBy,
 			}
-			if err := e.positionQuotaRepo.Create(quota); err != nil {
+			if err := e.positionQuotaRepo.Create(ctx, quota); err != nil {
 				result.Errors = append(result.Errors, fmt.Sprintf("Row %d: failed to create quota: %v", i+1, err))
 				continue
 			}
@@ -804,7 +1026,7 @@ type ImportBranchRevenueResult struct {
 // - Column F: Slim Pen Cases (required) - Integer (count)
 // The import overrides all existing revenue for each day of each branch.
 // Note: For backward compatibility, if only Column C is provided, it will be treated as Skin Revenue.
-func (e *ExcelImporter) ImportBranchRevenue(fileData []byte) ([]*models.RevenueData, []string, error) {
+func (e *ExcelImporter) ImportBranchRevenue(ctx context.Context, fileData []byte) ([]*models.RevenueData, []string, error) {
 	// Open Excel file from byte data
 	f, err := excelize.OpenReader(bytes.NewReader(fileData))
 	if err != nil {
@@ -854,7 +1076,7 @@ func (e *ExcelImporter) ImportBranchRevenue(fileData []byte) ([]*models.RevenueD
 	}
 
 	// Load all branches for lookup
-	branches, err := e.branchRepo.List()
+	branches, err := e.branchRepo.List(ctx)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to load branches: %w", err)
 	}
@@ -0,0 +1,221 @@
+package excel
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// StaffingReport is a branch-by-day grid of staff still required
+type StaffingReport struct {
+	Title string
+	Dates []time.Time
+	Rows  []StaffingReportRow
+}
+
+// StaffingReportRow holds one branch's still-required count for each report date
+type StaffingReportRow struct {
+	BranchCode string
+	BranchName string
+	Required   []int // One per report date
+}
+
+// WriteStaffingReport renders the report as an xlsx workbook
+// Layout:
+// - Row 1: Title
+// - Row 3: Header (Branch Code, Branch Name, one column per date, Total)
+// - Row 4+: One row per branch, then a totals row
+func WriteStaffingReport(report *StaffingReport) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := "Staffing"
+	if err := f.SetSheetName(f.GetSheetName(0), sheet); err != nil {
+		return nil, fmt.Errorf("failed to name sheet: %w", err)
+	}
+
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create style: %w", err)
+	}
+
+	set := func(col, row int, value interface{}) error {
+		cell, err := excelize.CoordinatesToCellName(col, row)
+		if err != nil {
+			return err
+		}
+		return f.SetCellValue(sheet, cell, value)
+	}
+
+	if err := set(1, 1, report.Title); err != nil {
+		return nil, err
+	}
+
+	// Header row
+	header := []interface{}{"Branch Code", "Branch Name"}
+	for _, date := range report.Dates {
+		header = append(header, date.Format("Mon 2006-01-02"))
+	}
+	header = append(header, "Total")
+	for i, value := range header {
+		if err := set(i+1, 3, value); err != nil {
+			return nil, err
+		}
+	}
+	lastCol, _ := excelize.ColumnNumberToName(len(header))
+	if err := f.SetCellStyle(sheet, "A3", lastCol+"3", bold); err != nil {
+		return nil, err
+	}
+
+	// Branch rows
+	dayTotals := make([]int, len(report.Dates))
+	row := 4
+	for _, r := range report.Rows {
+		if err := set(1, row, r.BranchCode); err != nil {
+			return nil, err
+		}
+		if err := set(2, row, r.BranchName); err != nil {
+			return nil, err
+		}
+		total := 0
+		for i := range report.Dates {
+			required := 0
+			if i < len(r.Required) {
+				required = r.Required[i]
+			}
+			if err := set(i+3, row, required); err != nil {
+				return nil, err
+			}
+			dayTotals[i] += required
+			total += required
+		}
+		if err := set(len(report.Dates)+3, row, total); err != nil {
+			return nil, err
+		}
+		row++
+	}
+
+	// Totals row
+	if err := set(1, row, "Total"); err != nil {
+		return nil, err
+	}
+	grandTotal := 0
+	for i, total := range dayTotals {
+		if err := set(i+3, row, total); err != nil {
+			return nil, err
+		}
+		grandTotal += total
+	}
+	if err := set(len(report.Dates)+3, row, grandTotal); err != nil {
+		return nil, err
+	}
+	totalRow := fmt.Sprintf("%d", row)
+	if err := f.SetCellStyle(sheet, "A"+totalRow, lastCol+totalRow, bold); err != nil {
+		return nil, err
+	}
+
+	if err := f.SetColWidth(sheet, "B", "B", 28); err != nil {
+		return nil, err
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write workbook: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// DoctorRoster is a doctor-by-day grid of where each doctor works
+type DoctorRoster struct {
+	Title string
+	Dates []time.Time
+	Rows  []DoctorRosterRow
+}
+
+// DoctorRosterRow holds one doctor's branch codes for each roster date
+type DoctorRosterRow struct {
+	DoctorCode string
+	DoctorName string
+	Branches   [][]string // One per roster date: branch codes by slot, morning first; empty when off
+}
+
+// WriteDoctorRoster renders the roster as an xlsx workbook that
+// ExcelImporter.ImportDoctorRoster reads back
+// Layout:
+// - Row 1: Title
+// - Row 3: Header (Doctor Code, Doctor Name, one column per date)
+// - Row 4+: One row per doctor; cells hold the branch code, "CODE/CODE" for a split day, or OFF
+func WriteDoctorRoster(roster *DoctorRoster) ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sheet := "Roster"
+	if err := f.SetSheetName(f.GetSheetName(0), sheet); err != nil {
+		return nil, fmt.Errorf("failed to name sheet: %w", err)
+	}
+
+	bold, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create style: %w", err)
+	}
+
+	set := func(col, row int, value interface{}) error {
+		cell, err := excelize.CoordinatesToCellName(col, row)
+		if err != nil {
+			return err
+		}
+		return f.SetCellValue(sheet, cell, value)
+	}
+
+	if err := set(1, 1, roster.Title); err != nil {
+		return nil, err
+	}
+
+	// Header row
+	header := []interface{}{"Doctor Code", "Doctor Name"}
+	for _, date := range roster.Dates {
+		header = append(header, date.Format("Mon 2006-01-02"))
+	}
+	for i, value := range header {
+		if err := set(i+1, 3, value); err != nil {
+			return nil, err
+		}
+	}
+	lastCol, _ := excelize.ColumnNumberToName(len(header))
+	if err := f.SetCellStyle(sheet, "A3", lastCol+"3", bold); err != nil {
+		return nil, err
+	}
+
+	// Doctor rows
+	row := 4
+	for _, r := range roster.Rows {
+		if err := set(1, row, r.DoctorCode); err != nil {
+			return nil, err
+		}
+		if err := set(2, row, r.DoctorName); err != nil {
+			return nil, err
+		}
+		for i := range roster.Dates {
+			value := "OFF"
+			if i < len(r.Branches) && len(r.Branches[i]) > 0 {
+				value = strings.Join(r.Branches[i], "/")
+			}
+			if err := set(i+3, row, value); err != nil {
+				return nil, err
+			}
+		}
+		row++
+	}
+
+	if err := f.SetColWidth(sheet, "B", "B", 28); err != nil {
+		return nil, err
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write workbook: %w", err)
+	}
+	return buf.Bytes(), nil
+}
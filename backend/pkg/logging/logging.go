@@ -0,0 +1,119 @@
+// Package logging sets up structured logging with log/slog. Request
+// middleware stores fields such as the request ID and user in the request
+// context, and every line logged with that context carries them.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// New creates a logger writing JSON lines, or human-readable text when format
+// is "text", at level and above ("debug", "info", "warn" or "error")
+func New(w io.Writer, format, level string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: ParseLevel(level)}
+	var handler slog.Handler
+	if format == "text" {
+		handler = slog.NewTextHandler(w, opts)
+	} else {
+		handler = slog.NewJSONHandler(w, opts)
+	}
+	return slog.New(contextHandler{handler})
+}
+
+// ParseLevel parses a level name, defaulting to info
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+type fieldsKey struct{}
+
+// fields are shared by every context derived from the one they were added to,
+// so values set by later middleware reach contexts derived earlier
+type fields struct {
+	mu    sync.Mutex
+	attrs []slog.Attr
+}
+
+// WithFields returns a context whose log lines carry the given key/value pairs.
+// Fields already present in ctx are updated in place.
+func WithFields(ctx context.Context, args ...any) context.Context {
+	f, ok := ctx.Value(fieldsKey{}).(*fields)
+	if !ok {
+		f = &fields{}
+		ctx = context.WithValue(ctx, fieldsKey{}, f)
+	}
+	f.set(argsToAttrs(args))
+	return ctx
+}
+
+// Fields returns the fields stored in ctx
+func Fields(ctx context.Context) []slog.Attr {
+	f, ok := ctx.Value(fieldsKey{}).(*fields)
+	if !ok {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]slog.Attr(nil), f.attrs...)
+}
+
+func (f *fields) set(attrs []slog.Attr) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+next:
+	for _, attr := range attrs {
+		for i := range f.attrs {
+			if f.attrs[i].Key == attr.Key {
+				f.attrs[i] = attr
+				continue next
+			}
+		}
+		f.attrs = append(f.attrs, attr)
+	}
+}
+
+func argsToAttrs(args []any) []slog.Attr {
+	var r slog.Record
+	r.Add(args...)
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+	return attrs
+}
+
+// contextHandler adds the context's fields to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if attrs := Fields(ctx); len(attrs) > 0 {
+			r = r.Clone()
+			r.AddAttrs(attrs...)
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,160 @@
+// Package lru is a size-bounded least-recently-used cache with optional expiry
+// and hit/miss counters
+package lru
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Stats is a snapshot of a cache's size and counters
+type Stats struct {
+	Size          int     `json:"size"`
+	Capacity      int     `json:"capacity"`
+	Hits          uint64  `json:"hits"`
+	Misses        uint64  `json:"misses"`
+	HitRate       float64 `json:"hit_rate"`
+	Evictions     uint64  `json:"evictions"`     // Dropped to stay within capacity
+	Expirations   uint64  `json:"expirations"`   // Dropped after their TTL
+	Invalidations uint64  `json:"invalidations"` // Removed by Remove, RemoveFunc or Purge
+}
+
+type entry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// Cache is safe for concurrent use
+type Cache[K comparable, V any] struct {
+	mu       sync.Mutex
+	capacity int
+	ttl      time.Duration
+	order    *list.List // Front is most recently used
+	items    map[K]*list.Element
+	stats    Stats
+}
+
+// New creates a cache holding at most capacity entries (minimum 1). Entries
+// older than ttl are treated as missing; a zero ttl keeps them until evicted.
+func New[K comparable, V any](capacity int, ttl time.Duration) *Cache[K, V] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &Cache[K, V]{
+		capacity: capacity,
+		ttl:      ttl,
+		order:    list.New(),
+		items:    make(map[K]*list.Element),
+	}
+}
+
+// Get returns the cached value and marks it most recently used
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	elem, ok := c.items[key]
+	if !ok {
+		c.stats.Misses++
+		return zero, false
+	}
+	e := elem.Value.(*entry[K, V])
+	if c.ttl > 0 && time.Now().After(e.expiresAt) {
+		c.removeElement(elem)
+		c.stats.Expirations++
+		c.stats.Misses++
+		return zero, false
+	}
+	c.order.MoveToFront(elem)
+	c.stats.Hits++
+	return e.value, true
+}
+
+// Put stores value under key, evicting the least recently used entry when full
+func (c *Cache[K, V]) Put(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(c.ttl)
+	if elem, ok := c.items[key]; ok {
+		e := elem.Value.(*entry[K, V])
+		e.value, e.expiresAt = value, expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.items[key] = c.order.PushFront(&entry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.capacity {
+		c.removeElement(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+// Remove deletes key, reporting whether it was cached
+func (c *Cache[K, V]) Remove(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if ok {
+		c.removeElement(elem)
+		c.stats.Invalidations++
+	}
+	return ok
+}
+
+// RemoveFunc deletes every entry for which match returns true and returns how many it removed
+func (c *Cache[K, V]) RemoveFunc(match func(key K, value V) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	removed := 0
+	for elem := c.order.Front(); elem != nil; {
+		next := elem.Next()
+		if e := elem.Value.(*entry[K, V]); match(e.key, e.value) {
+			c.removeElement(elem)
+			removed++
+		}
+		elem = next
+	}
+	c.stats.Invalidations += uint64(removed)
+	return removed
+}
+
+// Purge deletes every entry
+func (c *Cache[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats.Invalidations += uint64(c.order.Len())
+	c.order.Init()
+	c.items = make(map[K]*list.Element)
+}
+
+// Len returns the number of cached entries, including expired ones not yet dropped
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Stats returns the current size and counters
+func (c *Cache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Size = c.order.Len()
+	stats.Capacity = c.capacity
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(lookups)
+	}
+	return stats
+}
+
+func (c *Cache[K, V]) removeElement(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.items, elem.Value.(*entry[K, V]).key)
+}
@@ -42,7 +42,7 @@ func NewClient(cfg config.MCPConfig) *Client {
 		serverURL: cfg.ServerURL,
 		apiKey:    cfg.APIKey,
 		enabled:   cfg.Enabled,
-		client:    &http.Client{},
+		client:    &http.Client{Timeout: cfg.Timeout},
 	}
 }
 
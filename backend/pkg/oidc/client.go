@@ -0,0 +1,199 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+)
+
+// Client performs the OpenID Connect authorization code flow with PKCE.
+// Provider discovery happens lazily on first use so the server can start while the IdP is unreachable.
+type Client struct {
+	cfg Options
+
+	mu       sync.Mutex
+	provider *gooidc.Provider
+	verifier *gooidc.IDTokenVerifier
+	oauth    *oauth2.Config
+}
+
+// Options configures a Client
+type Options struct {
+	Enabled      bool
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string // Requested in addition to "openid"
+	// GroupsClaim and BranchClaim name the ID token claims holding IdP groups and the branch code
+	GroupsClaim string
+	BranchClaim string
+}
+
+// Identity is the subset of ID token claims used to find, provision and authorize a local user
+type Identity struct {
+	Issuer  string
+	Subject string
+	Email   string
+	// EmailVerified is the IdP's "email_verified" claim; an unverified email
+	// says nothing about who the caller is
+	EmailVerified bool
+	Username      string
+	Name          string
+	Groups        []string
+	BranchCode    string
+}
+
+func NewClient(opts Options) *Client {
+	return &Client{cfg: opts}
+}
+
+// Enabled reports whether OIDC login is configured
+func (c *Client) Enabled() bool {
+	return c.cfg.Enabled && c.cfg.IssuerURL != "" && c.cfg.ClientID != ""
+}
+
+func (c *Client) init(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.provider != nil {
+		return nil
+	}
+	if !c.Enabled() {
+		return fmt.Errorf("OIDC login is not enabled")
+	}
+
+	provider, err := gooidc.NewProvider(ctx, c.cfg.IssuerURL)
+	if err != nil {
+		return fmt.Errorf("failed to discover OIDC provider: %w", err)
+	}
+
+	scopes := []string{gooidc.ScopeOpenID}
+	for _, scope := range c.cfg.Scopes {
+		if scope != gooidc.ScopeOpenID {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	c.provider = provider
+	c.verifier = provider.Verifier(&gooidc.Config{ClientID: c.cfg.ClientID})
+	c.oauth = &oauth2.Config{
+		ClientID:     c.cfg.ClientID,
+		ClientSecret: c.cfg.ClientSecret,
+		RedirectURL:  c.cfg.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       scopes,
+	}
+	return nil
+}
+
+// NewState returns a random value suitable for the state and nonce parameters
+func NewState() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random state: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// NewVerifier returns a PKCE code verifier
+func NewVerifier() string {
+	return oauth2.GenerateVerifier()
+}
+
+// AuthCodeURL returns the IdP authorization URL for the given state, nonce and PKCE verifier
+func (c *Client) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	if err := c.init(ctx); err != nil {
+		return "", err
+	}
+	return c.oauth.AuthCodeURL(state, gooidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier)), nil
+}
+
+// Exchange redeems an authorization code, verifies the ID token (signature, issuer,
+// audience, expiry and nonce) and returns the caller's identity
+func (c *Client) Exchange(ctx context.Context, code, verifier, nonce string) (*Identity, error) {
+	if err := c.init(ctx); err != nil {
+		return nil, err
+	}
+
+	token, err := c.oauth.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, fmt.Errorf("token response did not contain an id_token")
+	}
+
+	idToken, err := c.verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify ID token: %w", err)
+	}
+	if idToken.Nonce != nonce {
+		return nil, fmt.Errorf("ID token nonce mismatch")
+	}
+
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to decode ID token claims: %w", err)
+	}
+
+	return &Identity{
+		Issuer:        idToken.Issuer,
+		Subject:       idToken.Subject,
+		Email:         stringClaim(claims, "email"),
+		EmailVerified: boolClaim(claims, "email_verified"),
+		Username:      stringClaim(claims, "preferred_username"),
+		Name:          stringClaim(claims, "name"),
+		Groups:        listClaim(claims, c.cfg.GroupsClaim),
+		BranchCode:    stringClaim(claims, c.cfg.BranchClaim),
+	}, nil
+}
+
+func stringClaim(claims map[string]interface{}, name string) string {
+	if name == "" {
+		return ""
+	}
+	if value, ok := claims[name].(string); ok {
+		return strings.TrimSpace(value)
+	}
+	return ""
+}
+
+// boolClaim reads a boolean claim, which some IdPs emit as the string "true"
+func boolClaim(claims map[string]interface{}, name string) bool {
+	switch v := claims[name].(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
+
+// listClaim reads a claim that IdPs emit either as a JSON array or as a space/comma separated string
+func listClaim(claims map[string]interface{}, name string) []string {
+	if name == "" {
+		return nil
+	}
+	var values []string
+	switch v := claims[name].(type) {
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+	case string:
+		values = strings.FieldsFunc(v, func(r rune) bool { return r == ' ' || r == ',' })
+	}
+	return values
+}
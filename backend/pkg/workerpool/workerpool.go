@@ -0,0 +1,112 @@
+// Package workerpool runs independent tasks on a bounded number of goroutines
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// TaskError is the failure of a single task
+type TaskError struct {
+	Index int
+	Err   error
+}
+
+func (e *TaskError) Error() string {
+	return fmt.Sprintf("task %d: %v", e.Index, e.Err)
+}
+
+func (e *TaskError) Unwrap() error {
+	return e.Err
+}
+
+// Errors aggregates the failed tasks of a run, ordered by task index
+type Errors []*TaskError
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Err.Error()
+	}
+	messages := make([]string, 0, len(e))
+	for _, taskErr := range e {
+		messages = append(messages, taskErr.Err.Error())
+	}
+	return fmt.Sprintf("%d tasks failed: %s", len(e), strings.Join(messages, "; "))
+}
+
+// Unwrap lets errors.Is and errors.As see every task error
+func (e Errors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, taskErr := range e {
+		errs = append(errs, taskErr)
+	}
+	return errs
+}
+
+// Workers returns n if positive, otherwise the number of usable CPUs
+func Workers(n int) int {
+	if n > 0 {
+		return n
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// Map calls fn for every index in [0, n) on at most workers goroutines
+// (GOMAXPROCS when workers <= 0) and returns the results in index order.
+// A failing task does not stop the others; all failures are returned
+// together as Errors and their result slots are left as zero values.
+// Once ctx is done no further tasks start and ctx.Err() is returned.
+func Map[T any](ctx context.Context, workers, n int, fn func(ctx context.Context, i int) (T, error)) ([]T, error) {
+	results := make([]T, n)
+	if n == 0 {
+		return results, ctx.Err()
+	}
+	workers = min(Workers(workers), n)
+
+	var (
+		mu     sync.Mutex
+		failed Errors
+		wg     sync.WaitGroup
+	)
+	tasks := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range tasks {
+				result, err := fn(ctx, i)
+				if err != nil {
+					mu.Lock()
+					failed = append(failed, &TaskError{Index: i, Err: err})
+					mu.Unlock()
+					continue
+				}
+				results[i] = result
+			}
+		}()
+	}
+
+dispatch:
+	for i := 0; i < n; i++ {
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case tasks <- i:
+		}
+	}
+	close(tasks)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(failed) > 0 {
+		sort.Slice(failed, func(a, b int) bool { return failed[a].Index < failed[b].Index })
+		return results, failed
+	}
+	return results, nil
+}
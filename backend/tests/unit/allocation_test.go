@@ -1,35 +1,1186 @@
 package unit
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
 	"testing"
-	"github.com/google/uuid"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/events"
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+	"vsq-oper-manpower/backend/internal/handlers"
+	"vsq-oper-manpower/backend/internal/repositories/memory"
+	"vsq-oper-manpower/backend/internal/repositories/postgres"
 	"vsq-oper-manpower/backend/internal/usecases/allocation"
+	"vsq-oper-manpower/backend/internal/usecases/doctor"
+	"vsq-oper-manpower/backend/internal/usecases/scenario"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
 )
 
-// Placeholder test file for allocation engine
-func TestAllocationEngine_CalculateRequiredStaff(t *testing.T) {
-	// TODO: Implement allocation calculation tests
-	// Example test structure:
-	// engine := allocation.NewAllocationEngine(repos)
-	// branchID := uuid.New()
-	// positionID := uuid.New()
-	// count, err := engine.CalculateRequiredStaff(branchID, "2024-01-01", positionID, 100000.0)
-	// if err != nil {
-	// 	t.Fatalf("Failed to calculate required staff: %v", err)
-	// }
-	// if count < 1 {
-	// 	t.Error("Required staff count should be at least 1")
-	// }
-	t.Skip("Test not yet implemented")
+// testDate is a Wednesday
+var testDate = time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)
+
+// allocationFixture is an in-memory store with one branch-type position and
+// helpers to add branches, staff and doctors to it
+type allocationFixture struct {
+	t        testing.TB
+	ctx      context.Context
+	repos    *memory.Repositories
+	position *models.Position
+}
+
+func newAllocationFixture(t testing.TB) *allocationFixture {
+	t.Helper()
+	f := &allocationFixture{
+		t:     t,
+		ctx:   context.Background(),
+		repos: memory.NewRepositories(),
+		position: &models.Position{
+			ID:           uuid.New(),
+			Name:         "Doctor Assistant",
+			PositionType: models.PositionTypeBranch,
+			ManpowerType: models.ManpowerTypeDoctor,
+		},
+	}
+	f.must(f.repos.Position.Create(f.ctx, f.position))
+	return f
+}
+
+func (f *allocationFixture) must(err error) {
+	f.t.Helper()
+	if err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+func (f *allocationFixture) wrapper() *allocation.RepositoriesWrapper {
+	r := f.repos
+	return &allocation.RepositoriesWrapper{
+		User:                        r.User,
+		Role:                        r.Role,
+		Staff:                       r.Staff,
+		Position:                    r.Position,
+		Branch:                      r.Branch,
+		EffectiveBranch:             r.EffectiveBranch,
+		Revenue:                     r.Revenue,
+		Schedule:                    r.Schedule,
+		Rotation:                    r.Rotation,
+		Settings:                    r.Settings,
+		AllocationRule:              r.AllocationRule,
+		AreaOfOperation:             r.AreaOfOperation,
+		AllocationCriteria:          r.AllocationCriteria,
+		PositionQuota:               r.PositionQuota,
+		Doctor:                      r.Doctor,
+		DoctorPreference:            r.DoctorPreference,
+		DoctorAssignment:            r.DoctorAssignment,
+		DoctorOnOffDay:              r.DoctorOnOffDay,
+		DoctorDefaultSchedule:       r.DoctorDefaultSchedule,
+		DoctorWeeklyOffDay:          r.DoctorWeeklyOffDay,
+		DoctorScheduleOverride:      r.DoctorScheduleOverride,
+		DoctorRevenueProfile:        r.DoctorRevenueProfile,
+		DoctorRevenueOverride:       r.DoctorRevenueOverride,
+		DoctorLeave:                 r.DoctorLeave,
+		BranchClosure:               r.BranchClosure,
+		BranchType:                  r.BranchType,
+		StaffGroup:                  r.StaffGroup,
+		StaffGroupPosition:          r.StaffGroupPosition,
+		BranchTypeRequirement:       r.BranchTypeRequirement,
+		BranchTypeConstraints:       r.BranchTypeConstraints,
+		BranchConstraints:           r.BranchConstraints,
+		RotationStaffSchedule:       r.RotationStaffSchedule,
+		SpecificPreference:          r.SpecificPreference,
+		RotationStaffBranchPosition: r.RotationStaffBranchPosition,
+		AllocationSuggestion:        r.AllocationSuggestion,
+		BranchQuotaSummary:          r.BranchQuotaSummary,
+		UnitOfWork:                  r.UnitOfWork,
+	}
+}
+
+// addBranch creates a branch with a quota for the fixture position and, if
+// open, a doctor working there on testDate's weekday
+func (f *allocationFixture) addBranch(code string, designated, minimum int, open bool) *models.Branch {
+	f.t.Helper()
+	branch := &models.Branch{ID: uuid.New(), Name: code, Code: code}
+	f.must(f.repos.Branch.Create(f.ctx, branch))
+	f.must(f.repos.PositionQuota.Create(f.ctx, &models.PositionQuota{
+		ID:              uuid.New(),
+		BranchID:        branch.ID,
+		PositionID:      f.position.ID,
+		DesignatedQuota: designated,
+		MinimumRequired: minimum,
+		IsActive:        true,
+	}))
+	if open {
+		f.addDoctor(branch)
+	}
+	return branch
+}
+
+func (f *allocationFixture) addDoctor(branch *models.Branch) *models.Doctor {
+	f.t.Helper()
+	d := &models.Doctor{Name: "Dr. " + branch.Code, Code: branch.Code}
+	f.must(f.repos.Doctor.Create(f.ctx, d))
+	f.must(f.repos.DoctorDefaultSchedule.Create(f.ctx, &models.DoctorDefaultSchedule{
+		DoctorID:  d.ID,
+		DayOfWeek: int(testDate.Weekday()),
+		BranchID:  branch.ID,
+	}))
+	return d
+}
+
+// addStaff creates one local staff member per status, scheduled on testDate;
+// an empty status leaves that staff member unscheduled
+func (f *allocationFixture) addStaff(branch *models.Branch, statuses ...models.ScheduleStatus) {
+	f.t.Helper()
+	for _, status := range statuses {
+		staff := &models.Staff{
+			ID:         uuid.New(),
+			Nickname:   branch.Code + "-staff",
+			StaffType:  models.StaffTypeBranch,
+			PositionID: f.position.ID,
+			BranchID:   &branch.ID,
+		}
+		f.must(f.repos.Staff.Create(f.ctx, staff))
+		if status == "" {
+			continue
+		}
+		f.must(f.repos.Schedule.Create(f.ctx, &models.StaffSchedule{
+			ID:             uuid.New(),
+			StaffID:        staff.ID,
+			BranchID:       branch.ID,
+			Date:           testDate,
+			ScheduleStatus: status,
+		}))
+	}
+}
+
+func TestQuotaCalculator_CalculateBranchQuotaStatus(t *testing.T) {
+	working, off, leave := models.ScheduleStatusWorking, models.ScheduleStatusOff, models.ScheduleStatusLeave
+
+	tests := []struct {
+		name          string
+		open          bool
+		statuses      []models.ScheduleStatus
+		wantPositions int
+		wantAvailable int
+		wantRequired  int
+	}{
+		{"fully staffed", true, []models.ScheduleStatus{working, working, working}, 1, 3, 0},
+		{"one on leave", true, []models.ScheduleStatus{working, working, leave}, 1, 2, 0},
+		{"short by one", true, []models.ScheduleStatus{working, off, off}, 1, 1, 1},
+		{"nobody scheduled", true, []models.ScheduleStatus{"", ""}, 1, 0, 2},
+		{"closed without doctor", false, []models.ScheduleStatus{working}, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAllocationFixture(t)
+			branch := f.addBranch("CPN", 3, 2, tt.open)
+			f.addStaff(branch, tt.statuses...)
+
+			// The summary fast path and the direct calculation must agree
+			withSummary := f.wrapper()
+			withoutSummary := f.wrapper()
+			withoutSummary.BranchQuotaSummary = nil
+			f.must(f.repos.BranchQuotaSummary.MarkDirty(f.ctx, branch.ID, testDate, testDate))
+			if _, err := newTestSummaryWorker(withSummary).RunOnce(f.ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			paths := map[string]*allocation.RepositoriesWrapper{"direct": withoutSummary, "summary": withSummary}
+			for path, w := range paths {
+				status, err := allocation.NewQuotaCalculator(w).CalculateBranchQuotaStatus(f.ctx, branch.ID, testDate)
+				if err != nil {
+					t.Fatalf("%s: %v", path, err)
+				}
+				if len(status.PositionStatuses) != tt.wantPositions {
+					t.Fatalf("%s: %d position statuses; want %d", path, len(status.PositionStatuses), tt.wantPositions)
+				}
+				if tt.wantPositions == 0 {
+					continue
+				}
+				ps := status.PositionStatuses[0]
+				if ps.AvailableLocal != tt.wantAvailable || ps.StillRequired != tt.wantRequired {
+					t.Errorf("%s: available %d, still required %d; want %d, %d",
+						path, ps.AvailableLocal, ps.StillRequired, tt.wantAvailable, tt.wantRequired)
+				}
+			}
+		})
+	}
+}
+
+func TestMultiCriteriaFilter_GenerateRankedSuggestions(t *testing.T) {
+	working, off := models.ScheduleStatusWorking, models.ScheduleStatusOff
+
+	f := newAllocationFixture(t)
+	short2 := f.addBranch("BBB", 3, 2, true)
+	f.addStaff(short2, off, off)
+	short1 := f.addBranch("CCC", 3, 2, true)
+	f.addStaff(short1, working, off)
+	preferredOnly := f.addBranch("AAA", 3, 2, true)
+	f.addStaff(preferredOnly, working, working)
+	full := f.addBranch("DDD", 2, 2, true)
+	f.addStaff(full, working, working)
+	closed := f.addBranch("EEE", 3, 2, false)
+	f.addStaff(closed, off, off)
+
+	branchIDs := []uuid.UUID{full.ID, closed.ID, preferredOnly.ID, short1.ID, short2.ID}
+	suggestions, err := allocation.NewMultiCriteriaFilter(f.wrapper()).
+		GenerateRankedSuggestions(f.ctx, branchIDs, testDate, allocation.DefaultCriteriaPriorityOrder(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		code        string
+		group2Score int
+	}{
+		{"BBB", -2},
+		{"CCC", -1},
+		{"AAA", 0},
+	}
+	if len(suggestions) != len(want) {
+		t.Fatalf("got %d suggestions; want %d (full and closed branches are skipped)", len(suggestions), len(want))
+	}
+	for i, w := range want {
+		s := suggestions[i]
+		if s.BranchCode != w.code || s.Group2Score != w.group2Score {
+			t.Errorf("suggestion %d = %s (group2 %d); want %s (group2 %d)", i, s.BranchCode, s.Group2Score, w.code, w.group2Score)
+		}
+	}
+}
+
+func TestScenarioCalculator_CalculateStaffRequirements(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	wednesday, thursday := int(time.Wednesday), int(time.Thursday)
+
+	tests := []struct {
+		name          string
+		scenarios     []*models.StaffRequirementScenario
+		requirements  []models.ScenarioPositionRequirement // matched to scenarios by index
+		wantPreferred int
+		wantMinimum   int
+		wantScenario  string
+	}{
+		{
+			name:          "no scenario keeps base",
+			wantPreferred: 3,
+			wantMinimum:   2,
+		},
+		{
+			name: "additive scenario",
+			scenarios: []*models.StaffRequirementScenario{
+				{ScenarioName: "busy", MinDoctorCount: intPtr(1)},
+			},
+			requirements:  []models.ScenarioPositionRequirement{{PreferredStaff: 2, MinimumStaff: 1}},
+			wantPreferred: 5,
+			wantMinimum:   3,
+			wantScenario:  "busy",
+		},
+		{
+			name: "higher priority override wins",
+			scenarios: []*models.StaffRequirementScenario{
+				{ScenarioName: "busy", MinDoctorCount: intPtr(1)},
+				{ScenarioName: "wednesday", DayOfWeek: &wednesday, Priority: 5},
+			},
+			requirements: []models.ScenarioPositionRequirement{
+				{PreferredStaff: 2, MinimumStaff: 1},
+				{PreferredStaff: 1, MinimumStaff: 1, OverrideBase: true},
+			},
+			wantPreferred: 1,
+			wantMinimum:   1,
+			wantScenario:  "wednesday",
+		},
+		{
+			name: "non-matching day falls through",
+			scenarios: []*models.StaffRequirementScenario{
+				{ScenarioName: "busy", MinDoctorCount: intPtr(1)},
+				{ScenarioName: "thursday", DayOfWeek: &thursday, Priority: 5},
+			},
+			requirements: []models.ScenarioPositionRequirement{
+				{PreferredStaff: 2, MinimumStaff: 1},
+				{PreferredStaff: 1, MinimumStaff: 1, OverrideBase: true},
+			},
+			wantPreferred: 5,
+			wantMinimum:   3,
+			wantScenario:  "busy",
+		},
+		{
+			name: "doctor count mismatch",
+			scenarios: []*models.StaffRequirementScenario{
+				{ScenarioName: "two doctors", MinDoctorCount: intPtr(2)},
+			},
+			requirements:  []models.ScenarioPositionRequirement{{PreferredStaff: 2, MinimumStaff: 1}},
+			wantPreferred: 3,
+			wantMinimum:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAllocationFixture(t)
+			branch := f.addBranch("CPN", 3, 2, true)
+			for i, s := range tt.scenarios {
+				s.ID = uuid.New()
+				s.IsActive = true
+				f.must(f.repos.StaffRequirementScenario.Create(f.ctx, s))
+				req := tt.requirements[i]
+				req.ID = uuid.New()
+				req.ScenarioID = s.ID
+				req.PositionID = f.position.ID
+				f.must(f.repos.ScenarioPositionRequirement.Create(f.ctx, &req))
+			}
+
+			calc := scenario.NewScenarioCalculator(&scenario.RepositoriesWrapper{
+				BranchWeeklyRevenue:         f.repos.BranchWeeklyRevenue,
+				Revenue:                     f.repos.Revenue,
+				DoctorAssignment:            f.repos.DoctorAssignment,
+				PositionQuota:               f.repos.PositionQuota,
+				RevenueLevelTier:            f.repos.RevenueLevelTier,
+				StaffRequirementScenario:    f.repos.StaffRequirementScenario,
+				ScenarioPositionRequirement: f.repos.ScenarioPositionRequirement,
+				Position:                    f.repos.Position,
+			})
+			got, err := calc.CalculateStaffRequirements(f.ctx, branch.ID, testDate, f.position.ID, 3, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.CalculatedPreferred != tt.wantPreferred || got.CalculatedMinimum != tt.wantMinimum {
+				t.Errorf("preferred %d, minimum %d; want %d, %d", got.CalculatedPreferred, got.CalculatedMinimum, tt.wantPreferred, tt.wantMinimum)
+			}
+			gotScenario := ""
+			if got.MatchedScenarioName != nil {
+				gotScenario = *got.MatchedScenarioName
+			}
+			if gotScenario != tt.wantScenario {
+				t.Errorf("matched scenario %q; want %q", gotScenario, tt.wantScenario)
+			}
+		})
+	}
+}
+
+func TestDoctorScheduleCalculator_CalculateAssignmentForDate(t *testing.T) {
+	tests := []struct {
+		name       string
+		weeklyOff  bool
+		override   string // "", "working" or "off"
+		wantSource string // "" means no assignment
+		wantOther  bool   // assigned to the override branch
+	}{
+		{name: "default schedule", wantSource: "default"},
+		{name: "weekly off day", weeklyOff: true},
+		{name: "working override beats weekly off", weeklyOff: true, override: "working", wantSource: "override", wantOther: true},
+		{name: "off override beats default", override: "off"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAllocationFixture(t)
+			home := f.addBranch("CPN", 1, 1, false)
+			other := f.addBranch("CTR", 1, 1, false)
+			d := f.addDoctor(home)
+
+			if tt.weeklyOff {
+				f.must(f.repos.DoctorWeeklyOffDay.Create(f.ctx, &models.DoctorWeeklyOffDay{
+					DoctorID:  d.ID,
+					DayOfWeek: int(testDate.Weekday()),
+				}))
+			}
+			if tt.override != "" {
+				o := &models.DoctorScheduleOverride{DoctorID: d.ID, Date: testDate, Type: tt.override}
+				if tt.override == "working" {
+					o.BranchID = &other.ID
+				}
+				f.must(f.repos.DoctorScheduleOverride.Create(f.ctx, o))
+			}
+
+			calc := doctor.NewDoctorScheduleCalculator(
+				f.repos.DoctorDefaultSchedule,
+				f.repos.DoctorWeeklyOffDay,
+				f.repos.DoctorScheduleOverride,
+				f.repos.Doctor,
+			)
+			assignments, err := calc.CalculateAssignmentsForDate(f.ctx, d.ID, testDate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantSource == "" {
+				if len(assignments) != 0 {
+					t.Fatalf("got assignment to %s from %s; want none", assignments[0].BranchID, assignments[0].Source)
+				}
+				return
+			}
+			if len(assignments) != 1 {
+				t.Fatalf("got %d assignments; want one from %s", len(assignments), tt.wantSource)
+			}
+			got := assignments[0]
+			wantBranch := home.ID
+			if tt.wantOther {
+				wantBranch = other.ID
+			}
+			if got.Source != tt.wantSource || got.BranchID != wantBranch {
+				t.Errorf("assignment %s from %s; want %s from %s", got.BranchID, got.Source, wantBranch, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestDoctorScheduleCalculator_RecurrenceAndSlots(t *testing.T) {
+	f := newAllocationFixture(t)
+	home := f.addBranch("CPN", 1, 1, false)
+	other := f.addBranch("CTR", 1, 1, false)
+	d := f.addDoctor(home) // every Wednesday at CPN
+	weekday := int(testDate.Weekday())
+	anchor, febFirst := testDate, time.Date(2030, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	// Every other Wednesday from testDate the doctor splits the day
+	for _, s := range []*models.DoctorDefaultSchedule{
+		{BranchID: home.ID, Slot: models.DoctorSlotMorning, ScheduleRecurrence: models.ScheduleRecurrence{IntervalWeeks: 2, AnchorDate: &anchor}},
+		{BranchID: other.ID, Slot: models.DoctorSlotAfternoon, ScheduleRecurrence: models.ScheduleRecurrence{IntervalWeeks: 2, AnchorDate: &anchor}},
+		// From February, the first Wednesday of the month is at CTR
+		{BranchID: other.ID, ScheduleRecurrence: models.ScheduleRecurrence{WeekOfMonth: 1, ValidFrom: &febFirst}},
+	} {
+		s.DoctorID, s.DayOfWeek = d.ID, weekday
+		f.must(f.repos.DoctorDefaultSchedule.Create(f.ctx, s))
+	}
+	// Off on the last Wednesday of the month
+	f.must(f.repos.DoctorWeeklyOffDay.Create(f.ctx, &models.DoctorWeeklyOffDay{
+		DoctorID: d.ID, DayOfWeek: weekday, ScheduleRecurrence: models.ScheduleRecurrence{WeekOfMonth: -1},
+	}))
+	f.must(f.repos.DoctorRevenueProfile.Upsert(f.ctx, &models.DoctorRevenueProfile{
+		DoctorID: d.ID, DayOfWeek: weekday, DoctorRevenue: models.DoctorRevenue{SkinRevenue: 10000, VitaminCases: 3},
+	}))
+
+	calc := doctor.NewDoctorScheduleCalculator(
+		f.repos.DoctorDefaultSchedule,
+		f.repos.DoctorWeeklyOffDay,
+		f.repos.DoctorScheduleOverride,
+		f.repos.Doctor,
+	).WithRevenue(f.repos.DoctorRevenueProfile, f.repos.DoctorRevenueOverride)
+
+	tests := []struct {
+		date string
+		want []string // slot@branch
+	}{
+		{date: "2030-01-02", want: []string{"morning@CPN", "afternoon@CTR"}},
+		{date: "2030-01-09", want: []string{"full_day@CPN"}},
+		{date: "2030-01-16", want: []string{"morning@CPN", "afternoon@CTR"}},
+		{date: "2030-01-30"}, // last Wednesday, and an alternate one
+		{date: "2030-02-06", want: []string{"full_day@CTR"}},
+	}
+	codes := map[uuid.UUID]string{home.ID: "CPN", other.ID: "CTR"}
+	for _, tt := range tests {
+		date, _ := time.Parse("2006-01-02", tt.date)
+		assignments, err := calc.CalculateAssignmentsForDate(f.ctx, d.ID, date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, a := range assignments {
+			got = append(got, a.Slot+"@"+codes[a.BranchID])
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: assignments %v, want %v", tt.date, got, tt.want)
+		}
+	}
+
+	// Each half carries half the day's revenue and its own stable ID
+	assignments, err := f.repos.DoctorAssignment.GetByDate(f.ctx, testDate)
+	if err != nil || len(assignments) != 2 {
+		t.Fatalf("assignments = %v, %v; want two halves", assignments, err)
+	}
+	morning, afternoon := assignments[0], assignments[1]
+	if morning.ID == afternoon.ID {
+		t.Errorf("both halves have ID %s", morning.ID)
+	}
+	if morning.Slot != models.DoctorSlotMorning || morning.ExpectedRevenue != 5000 || morning.Revenue.VitaminCases != 2 ||
+		afternoon.ExpectedRevenue != 5000 || afternoon.Revenue.VitaminCases != 1 {
+		t.Errorf("halves = %+v / %+v, want the day's revenue split between them", morning.Revenue, afternoon.Revenue)
+	}
+
+	// The roster stores one row per half
+	run, err := doctor.NewRosterMaterializer(f.repos.UnitOfWork, 0).Materialize(f.ctx, testDate, testDate, models.RosterTriggerManual)
+	if err != nil || run.Added != 2 {
+		t.Fatalf("roster run = %+v, %v; want both halves added", run, err)
+	}
+	stored, err := f.repos.DoctorAssignment.GetByDate(f.ctx, testDate)
+	if err != nil || len(stored) != 2 || stored[0].ID != morning.ID || stored[1].ID != afternoon.ID {
+		t.Errorf("stored = %v, %v; want the calculated halves", stored, err)
+	}
+}
+
+func TestDoctorLeave_TakesDoctorOffAndReportsImpact(t *testing.T) {
+	f := newAllocationFixture(t)
+	home := f.addBranch("CPN", 1, 1, false)
+	other := f.addBranch("CTR", 1, 1, false)
+	d := f.addDoctor(home) // every Wednesday at CPN, alone
+	substitute := f.addDoctor(other)
+	secondWeek := testDate.AddDate(0, 0, 7)
+
+	leave := &models.DoctorLeave{DoctorID: d.ID, StartDate: testDate.AddDate(0, 0, -1), EndDate: secondWeek, Reason: "Vacation"}
+	f.must(f.repos.DoctorLeave.Create(f.ctx, leave))
+	calc := doctor.NewDoctorScheduleCalculator(
+		f.repos.DoctorDefaultSchedule,
+		f.repos.DoctorWeeklyOffDay,
+		f.repos.DoctorScheduleOverride,
+		f.repos.Doctor,
+	).WithLeaves(f.repos.DoctorLeave)
+
+	// Pending leave previews its impact without changing the schedule
+	impact, err := calc.LeaveImpact(f.ctx, leave)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(impact) != 2 || !impact[0].DoctorLess || !impact[1].DoctorLess || impact[0].BranchID != home.ID {
+		t.Fatalf("impact = %+v; want CPN doctor-less on both Wednesdays", impact)
+	}
+	if count, _ := f.repos.DoctorAssignment.GetDoctorCountByBranch(f.ctx, home.ID, testDate); count != 1 {
+		t.Errorf("CPN has %d doctors with leave pending; want 1", count)
+	}
+
+	// Approved leave takes the doctor off, except where an override brings them in
+	leave.Status = models.DoctorLeaveApproved
+	f.must(f.repos.DoctorLeave.Update(f.ctx, leave))
+	f.must(f.repos.DoctorScheduleOverride.Create(f.ctx, &models.DoctorScheduleOverride{
+		DoctorID: d.ID, Date: secondWeek, Type: "working", BranchID: &home.ID,
+	}))
+	for date, want := range map[time.Time]int{testDate: 0, secondWeek: 1} {
+		if count, _ := f.repos.DoctorAssignment.GetDoctorCountByBranch(f.ctx, home.ID, date); count != want {
+			t.Errorf("CPN has %d doctors on %s; want %d", count, date.Format("2006-01-02"), want)
+		}
+	}
+
+	// A substitute's working override keeps the branch open
+	f.must(f.repos.DoctorScheduleOverride.Create(f.ctx, &models.DoctorScheduleOverride{
+		DoctorID: substitute.ID, Date: testDate, Type: "working", BranchID: &home.ID,
+	}))
+	impact, err = calc.LeaveImpact(f.ctx, leave)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(impact) != 1 || impact[0].DoctorLess || impact[0].OtherDoctors != 1 {
+		t.Errorf("impact = %+v; want CPN covered by the substitute on %s only", impact, testDate.Format("2006-01-02"))
+	}
 }
 
-func TestAllocationEngine_CheckAvailability(t *testing.T) {
-	// TODO: Implement availability checking tests
-	t.Skip("Test not yet implemented")
+func TestBranchDayStatus_HonorsDesignationAndClosures(t *testing.T) {
+	off := models.ScheduleStatusOff
+	f := newAllocationFixture(t)
+	rostered := f.addBranch("AAA", 3, 2, true)
+	designatedOff := f.addBranch("BBB", 3, 2, true)
+	designatedOn := f.addBranch("CCC", 3, 2, false)
+	closed := f.addBranch("DDD", 3, 2, true)
+	open := f.addBranch("EEE", 3, 2, false)
+	branchIDs := []uuid.UUID{rostered.ID, designatedOff.ID, designatedOn.ID, closed.ID, open.ID}
+	for _, branch := range []*models.Branch{rostered, designatedOff, designatedOn, closed, open} {
+		f.addStaff(branch, off, off)
+	}
+	for branch, on := range map[*models.Branch]bool{designatedOff: false, designatedOn: true} {
+		f.must(f.repos.DoctorOnOffDay.Create(f.ctx, &models.DoctorOnOffDay{ID: uuid.New(), BranchID: branch.ID, Date: testDate, IsDoctorOn: on}))
+	}
+	f.must(f.repos.BranchClosure.Create(f.ctx, &models.BranchClosure{BranchID: &closed.ID, StartDate: testDate, EndDate: testDate, Reason: "Renovation"}))
+	nextDay := testDate.AddDate(0, 0, 1)
+	f.must(f.repos.BranchClosure.Create(f.ctx, &models.BranchClosure{StartDate: nextDay, EndDate: nextDay, Reason: "Public holiday"}))
+
+	statuses, err := allocation.LoadBranchDayStatuses(f.ctx, f.wrapper(), branchIDs, testDate, nextDay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		state    string
+		mismatch bool
+	}{
+		{models.BranchDayDoctorOn, false},
+		{models.BranchDayDoctorOff, true},
+		{models.BranchDayDoctorOn, true},
+		{models.BranchDayClosed, true},
+		{models.BranchDayOpen, false},
+	}
+	if len(statuses) != 2*len(want) {
+		t.Fatalf("got %d statuses; want %d", len(statuses), 2*len(want))
+	}
+	for i, w := range want {
+		s := statuses[i]
+		if s.State != w.state || (s.Mismatch != "") != w.mismatch {
+			t.Errorf("%s = %s (mismatch %q); want %s (mismatch %v)", s.BranchCode, s.State, s.Mismatch, w.state, w.mismatch)
+		}
+	}
+	for _, s := range statuses[len(want):] {
+		if s.State != models.BranchDayClosed || !s.Holiday || s.Mismatch != "" {
+			t.Errorf("%s on the holiday = %+v; want closed without mismatch", s.BranchCode, s)
+		}
+	}
+
+	// Only doctor-on days are staffed, whatever the roster says
+	suggestions, err := allocation.NewMultiCriteriaFilter(f.wrapper()).
+		GenerateRankedSuggestions(f.ctx, branchIDs, testDate, allocation.DefaultCriteriaPriorityOrder(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	suggested := map[string]bool{}
+	for _, s := range suggestions {
+		suggested[s.BranchCode] = true
+	}
+	if len(suggested) != 2 || !suggested["AAA"] || !suggested["CCC"] {
+		t.Errorf("suggested %v; want AAA and CCC only", suggested)
+	}
 }
 
+func TestDoctorPreferenceRules_DecodeStrictlyAndReportFailures(t *testing.T) {
+	working := models.ScheduleStatusWorking
+	for _, config := range []map[string]interface{}{
+		{"position_id": uuid.New().String(), "min_count": 1, "min": 2},
+		{"position_id": uuid.New().String(), "min_count": "2"},
+		{"position_id": uuid.New().String(), "min_count": 1, "days_of_week": []int{7}},
+	} {
+		if _, err := models.DecodePreferenceRule(models.RuleMinPositionCount, config); err == nil {
+			t.Errorf("decoded %v; want an error", config)
+		}
+	}
+	if _, err := models.DecodePreferenceRule("unknown", nil); err == nil {
+		t.Error("decoded an unknown rule type; want an error")
+	}
+
+	f := newAllocationFixture(t)
+	unmet := f.addBranch("AAA", 3, 1, false)
+	met := f.addBranch("BBB", 3, 1, false)
+	f.addStaff(unmet, working)
+	f.addStaff(met, working)
+	addRule := func(doctor *models.Doctor, ruleType string, config map[string]interface{}) {
+		f.must(f.repos.DoctorPreference.Create(f.ctx, &models.DoctorPreference{DoctorID: doctor.ID, RuleType: ruleType, RuleConfig: config, IsActive: true}))
+	}
+	otherDay := int(testDate.AddDate(0, 0, 1).Weekday())
+	unmetDoctor := f.addDoctor(unmet)
+	addRule(unmetDoctor, models.RuleMinPositionCount, map[string]interface{}{"position_id": f.position.ID.String(), "min_count": 2})
+	addRule(unmetDoctor, models.RulePreferredAssistant, map[string]interface{}{"staff_ids": []string{uuid.New().String()}})
+	addRule(unmetDoctor, models.RuleMinPositionCount, map[string]interface{}{"position_id": f.position.ID.String(), "min_count": 5, "days_of_week": []int{otherDay}})
+	addRule(f.addDoctor(met), models.RuleMinPositionCount, map[string]interface{}{"position_id": f.position.ID.String(), "min_count": 1})
+
+	filter := allocation.NewMultiCriteriaFilter(f.wrapper())
+	checks, err := filter.CheckDoctorPreferences(f.ctx, []uuid.UUID{unmet.ID, met.ID}, testDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checks) != 2 || checks[0].Met || !checks[1].Met {
+		t.Fatalf("checks = %+v; want AAA unmet and BBB met", checks)
+	}
+	failed := map[string]models.PreferenceRuleFailure{}
+	for _, failure := range checks[0].Failures {
+		failed[failure.RuleType] = failure
+	}
+	if minimum, ok := failed[models.RuleMinPositionCount]; len(checks[0].Failures) != 2 || !ok || minimum.Required != 2 || minimum.Actual != 1 {
+		t.Errorf("failures = %+v; want the position minimum (2, has 1) and the preferred assistant", checks[0].Failures)
+	}
+	if _, ok := failed[models.RulePreferredAssistant]; !ok {
+		t.Errorf("failures = %+v; want the preferred assistant", checks[0].Failures)
+	}
+
+	// The zeroth criteria drops branches whose doctors' rules fail
+	suggestions, err := filter.GenerateRankedSuggestions(f.ctx, []uuid.UUID{unmet.ID, met.ID}, testDate, allocation.DefaultCriteriaPriorityOrder(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range suggestions {
+		if s.BranchCode != "BBB" {
+			t.Errorf("suggested %s; want BBB only", s.BranchCode)
+		}
+	}
+}
+
+func TestPairingResolver_FollowsRosterAndReservesStaff(t *testing.T) {
+	f := newAllocationFixture(t)
+	paired := f.addBranch("AAA", 3, 2, false)
+	other := f.addBranch("BBB", 3, 2, true)
+	doctor := f.addDoctor(paired)
+	rotation := func(name string, effective ...*models.Branch) *models.Staff {
+		staff := &models.Staff{ID: uuid.New(), Nickname: name, StaffType: models.StaffTypeRotation, PositionID: f.position.ID}
+		f.must(f.repos.Staff.Create(f.ctx, staff))
+		for _, branch := range effective {
+			f.must(f.repos.EffectiveBranch.Create(f.ctx, &models.EffectiveBranch{ID: uuid.New(), RotationStaffID: staff.ID, BranchID: branch.ID, Level: 1}))
+		}
+		return staff
+	}
+	free := rotation("free", paired, other)
+	outside := rotation("outside", other)
+	onLeave := rotation("on-leave", paired)
+	f.must(f.repos.RotationStaffSchedule.Create(f.ctx, &models.RotationStaffSchedule{ID: uuid.New(), RotationStaffID: onLeave.ID, Date: testDate, ScheduleStatus: models.ScheduleStatusLeave}))
+	for staff, hard := range map[*models.Staff]bool{free: true, outside: false, onLeave: true} {
+		f.must(f.repos.SpecificPreference.Create(f.ctx, &models.SpecificPreference{
+			DoctorID: &doctor.ID, PreferenceType: models.SpecificPreferenceTypeStaffName, StaffID: &staff.ID, IsHard: hard, IsActive: true,
+		}))
+	}
+
+	w := f.wrapper()
+	resolver := allocation.NewPairingResolver(w)
+	pairings, err := resolver.Resolve(f.ctx, nil, testDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[uuid.UUID]string{free.ID: models.PairingReserved, outside.ID: models.PairingReasonOutsideEffectiveBranches, onLeave.ID: models.PairingReasonStaffOnLeave}
+	if len(pairings) != len(want) {
+		t.Fatalf("got %d pairings; want %d", len(pairings), len(want))
+	}
+	for _, p := range pairings {
+		if p.BranchID != paired.ID {
+			t.Errorf("%s paired at %s; want AAA, where the doctor is rostered", p.StaffName, p.BranchCode)
+		}
+		outcome := p.Reason
+		if outcome == "" {
+			outcome = p.Status
+		}
+		if outcome != want[p.StaffID] {
+			t.Errorf("%s = %s; want %s", p.StaffName, outcome, want[p.StaffID])
+		}
+	}
+
+	// The hard-paired staff member is placed with the doctor and held from other branches
+	suggestions, err := allocation.NewSuggestionEngine(w, allocation.NewMultiCriteriaFilter(w), allocation.NewQuotaCalculator(w)).
+		GenerateSuggestions(f.ctx, []uuid.UUID{paired.ID, other.ID}, testDate, testDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range suggestions {
+		if s.RotationStaffID == free.ID && s.BranchID != paired.ID {
+			t.Errorf("hard-paired staff suggested at %s", s.BranchID)
+		}
+	}
+	if len(suggestions) == 0 || suggestions[0].RotationStaffID != free.ID || suggestions[0].BranchID != paired.ID {
+		t.Errorf("suggestions = %+v; want the hard pairing at AAA first", suggestions)
+	}
+	if conflict, err := resolver.PairingFor(f.ctx, free.ID, other.ID, testDate); err != nil || conflict == nil {
+		t.Errorf("PairingFor another branch = %v, %v; want the hard pairing", conflict, err)
+	}
+
+	overview, err := allocation.NewOverviewGenerator(w, allocation.NewQuotaCalculator(w)).GenerateDayOverview(f.ctx, testDate, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overview.UnmetPairings) != 2 {
+		t.Errorf("overview has %d unmet pairings; want 2", len(overview.UnmetPairings))
+	}
+}
 
+func TestDoctorRevenue_FeedsAssignmentsAndDayRevenue(t *testing.T) {
+	f := newAllocationFixture(t)
+	home := f.addBranch("CPN", 1, 1, false)
+	weekday := int(testDate.Weekday())
+	a := f.addDoctor(home)
+	b := &models.Doctor{Name: "Dr. B", Code: "CPN-B"}
+	f.must(f.repos.Doctor.Create(f.ctx, b))
+	f.must(f.repos.DoctorDefaultSchedule.Create(f.ctx, &models.DoctorDefaultSchedule{DoctorID: b.ID, DayOfWeek: weekday, BranchID: home.ID}))
 
+	// a: the branch profile beats the any-branch one; b: the date override beats its profile
+	for _, p := range []*models.DoctorRevenueProfile{
+		{DoctorID: a.ID, DayOfWeek: weekday, DoctorRevenue: models.DoctorRevenue{SkinRevenue: 10000}},
+		{DoctorID: a.ID, BranchID: &home.ID, DayOfWeek: weekday, DoctorRevenue: models.DoctorRevenue{SkinRevenue: 20000, LSHMRevenue: 5000}},
+		{DoctorID: b.ID, DayOfWeek: weekday, DoctorRevenue: models.DoctorRevenue{SkinRevenue: 8000}},
+	} {
+		f.must(f.repos.DoctorRevenueProfile.Upsert(f.ctx, p))
+	}
+	f.must(f.repos.DoctorRevenueOverride.Upsert(f.ctx, &models.DoctorRevenueOverride{
+		DoctorID: b.ID, Date: testDate, DoctorRevenue: models.DoctorRevenue{SkinRevenue: 3000, VitaminCases: 2},
+	}))
 
+	assignments, err := f.repos.DoctorAssignment.GetByDate(f.ctx, testDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[uuid.UUID]struct {
+		amount float64
+		source string
+	}{a.ID: {25000, "profile"}, b.ID: {3000, "override"}}
+	if len(assignments) != len(want) {
+		t.Fatalf("got %d assignments, want %d", len(assignments), len(want))
+	}
+	for _, got := range assignments {
+		w := want[got.DoctorID]
+		if got.ExpectedRevenue != w.amount || got.RevenueSource != w.source {
+			t.Errorf("doctor %s: revenue %v from %q, want %v from %q", got.DoctorName, got.ExpectedRevenue, got.RevenueSource, w.amount, w.source)
+		}
+	}
+	again, err := f.repos.DoctorAssignment.GetDoctorsByBranchAndDate(f.ctx, home.ID, testDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, got := range again {
+		for _, first := range assignments {
+			if got.DoctorID == first.DoctorID && got.ID != first.ID {
+				t.Errorf("calculated assignment ID changed between reads: %s, %s", first.ID, got.ID)
+			}
+		}
+	}
 
+	dc, err := allocation.LoadDayContext(f.ctx, f.wrapper(), []uuid.UUID{home.ID}, testDate, testDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rd := dc.Revenue(home.ID, testDate)
+	if rd == nil || rd.RevenueSource != "doctor" || rd.SkinRevenue != 23000 || rd.LSHMRevenue != 5000 ||
+		rd.VitaminCases != 2 || rd.ExpectedRevenue != 28000 {
+		t.Fatalf("day revenue = %+v, want the doctors' sum", rd)
+	}
+
+	// Entered branch revenue still takes precedence
+	f.must(f.repos.Revenue.Create(f.ctx, &models.RevenueData{
+		ID: uuid.New(), BranchID: home.ID, Date: testDate, ExpectedRevenue: 1000, SkinRevenue: 1000, RevenueSource: "branch",
+	}))
+	dc, err = allocation.LoadDayContext(f.ctx, f.wrapper(), []uuid.UUID{home.ID}, testDate, testDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rd := dc.Revenue(home.ID, testDate); rd == nil || rd.RevenueSource != "branch" || rd.ExpectedRevenue != 1000 {
+		t.Errorf("day revenue = %+v, want the branch's own", rd)
+	}
+}
+
+func TestRosterMaterializer_KeepsIDsAndRecordsChanges(t *testing.T) {
+	f := newAllocationFixture(t)
+	home := f.addBranch("CPN", 1, 1, false)
+	other := f.addBranch("CTR", 1, 1, false)
+	d := f.addDoctor(home)
+
+	bus := events.NewBus()
+	var published []events.Event
+	bus.Subscribe(func(_ context.Context, e events.Event) { published = append(published, e) })
+	m := doctor.NewRosterMaterializer(f.repos.UnitOfWork, 0).WithEvents(bus)
+
+	calculated, err := f.repos.DoctorAssignment.GetByDate(f.ctx, testDate)
+	if err != nil || len(calculated) != 1 {
+		t.Fatalf("calculated assignments = %v, %v; want one", calculated, err)
+	}
+	run, err := m.Materialize(f.ctx, testDate, testDate.AddDate(0, 0, 6), models.RosterTriggerManual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Added != 1 || run.Removed != 0 || run.Moved != 0 {
+		t.Fatalf("first run = %+v, want one added", run)
+	}
+	stored, err := f.repos.DoctorAssignment.GetByDate(f.ctx, testDate)
+	if err != nil || len(stored) != 1 || stored[0].ID != calculated[0].ID {
+		t.Fatalf("materialized assignments = %v, %v; want the calculated ID %s", stored, err, calculated[0].ID)
+	}
+
+	// Moving the doctor keeps the assignment and records where it moved from
+	override := &models.DoctorScheduleOverride{DoctorID: d.ID, Date: testDate, Type: "working", BranchID: &other.ID}
+	f.must(f.repos.DoctorScheduleOverride.Create(f.ctx, override))
+	published = nil
+	if run, err = m.Refresh(f.ctx, testDate, testDate); err != nil {
+		t.Fatal(err)
+	}
+	if run == nil || run.Moved != 1 {
+		t.Fatalf("refresh run = %+v, want one moved", run)
+	}
+	moved, err := f.repos.DoctorAssignment.GetByID(f.ctx, calculated[0].ID)
+	if err != nil || moved == nil || moved.BranchID != other.ID || moved.Source != "override" {
+		t.Fatalf("moved assignment = %+v, %v; want it at CTR from the override", moved, err)
+	}
+	changes, err := f.repos.DoctorRoster.ListChanges(f.ctx, interfaces.DoctorRosterChangeFilters{RunID: &run.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].ChangeType != models.RosterChangeMoved ||
+		changes[0].FromBranchCode != "CPN" || changes[0].ToBranchCode != "CTR" {
+		t.Fatalf("changes = %+v, want one move from CPN to CTR", changes)
+	}
+	if len(published) != 2 {
+		t.Errorf("published %d events, want one per affected branch", len(published))
+	}
+
+	// A refresh that changes nothing is not recorded
+	if run, err = m.Refresh(f.ctx, testDate, testDate); err != nil || run != nil {
+		t.Fatalf("idle refresh = %+v, %v; want no run", run, err)
+	}
+
+	// Taking the day off removes the assignment
+	override.Type, override.BranchID = "off", nil
+	f.must(f.repos.DoctorScheduleOverride.Update(f.ctx, override))
+	if run, err = m.Refresh(f.ctx, testDate, testDate); err != nil || run == nil || run.Removed != 1 {
+		t.Fatalf("off refresh = %+v, %v; want one removed", run, err)
+	}
+	if gone, err := f.repos.DoctorAssignment.GetByID(f.ctx, calculated[0].ID); err != nil || gone != nil {
+		t.Errorf("removed assignment = %+v, %v; want none", gone, err)
+	}
+
+	// Dates past the window are still calculated
+	later := testDate.AddDate(0, 0, 7)
+	if rest, err := f.repos.DoctorAssignment.GetByDate(f.ctx, later); err != nil || len(rest) != 1 {
+		t.Errorf("assignments after the window = %v, %v; want the calculated one", rest, err)
+	}
+}
+
+// newTestSummaryWorker recomputes every queued summary on each run
+func newTestSummaryWorker(w *allocation.RepositoriesWrapper) *allocation.SummaryWorker {
+	return allocation.NewSummaryWorker(w, allocation.NewQuotaCalculator(w)).WithSchedule(time.Millisecond, 0, 0)
+}
 
+func TestSummaryWorker_RunOnce(t *testing.T) {
+	f := newAllocationFixture(t)
+	branch := f.addBranch("CPN", 3, 2, true)
+	f.addStaff(branch, models.ScheduleStatusWorking, models.ScheduleStatusWorking)
+	w := f.wrapper()
+	direct := f.wrapper()
+	direct.BranchQuotaSummary = nil
+
+	// Schedule writes only queue the branch and date
+	summary, err := f.repos.BranchQuotaSummary.GetByBranchIDAndDate(f.ctx, branch.ID, testDate)
+	if err != nil || summary != nil {
+		t.Fatalf("schedule writes should not calculate the summary, got %v, %v", summary, err)
+	}
+	dirty, err := f.repos.BranchQuotaSummary.GetDirtyByBranchIDsAndDateRange(f.ctx, []uuid.UUID{branch.ID}, testDate, testDate)
+	if err != nil || len(dirty) != 1 {
+		t.Fatalf("got %d dirty keys, %v; want 1", len(dirty), err)
+	}
+
+	// Debounced keys wait for writes to settle
+	waiting := allocation.NewSummaryWorker(w, allocation.NewQuotaCalculator(w)).WithSchedule(time.Second, time.Hour, time.Hour)
+	if n, err := waiting.RunOnce(f.ctx); err != nil || n != 0 {
+		t.Fatalf("debounced run wrote %d summaries, %v; want 0", n, err)
+	}
+
+	worker := newTestSummaryWorker(w)
+	if _, err := worker.RunOnce(f.ctx); err != nil {
+		t.Fatal(err)
+	}
+	summary, err = f.repos.BranchQuotaSummary.GetByBranchIDAndDate(f.ctx, branch.ID, testDate)
+	if err != nil || summary == nil {
+		t.Fatalf("worker should store the summary, got %v, %v", summary, err)
+	}
+	dirty, _ = f.repos.BranchQuotaSummary.GetDirtyByBranchIDsAndDateRange(f.ctx, []uuid.UUID{branch.ID}, testDate, testDate)
+	if len(dirty) != 0 {
+		t.Errorf("%d dirty keys left after the run; want 0", len(dirty))
+	}
+
+	// The stored summary is the calculator's own result
+	want, err := allocation.NewQuotaCalculator(direct).CalculateBranchQuotaStatus(f.ctx, branch.ID, testDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.TotalAvailable != want.TotalAvailable || summary.TotalRequired != want.TotalRequired ||
+		summary.Group1Score != want.Group1Score || summary.Group2Score != want.Group2Score || summary.Group3Score != want.Group3Score {
+		t.Errorf("summary %+v disagrees with calculated status %+v", summary, want)
+	}
+
+	// A change queues the key again, and reads ignore the stale summary until it is recomputed
+	schedules, err := f.repos.Schedule.GetByBranchID(f.ctx, branch.ID, testDate, testDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schedules[0].ScheduleStatus = models.ScheduleStatusSickLeave
+	f.must(f.repos.Schedule.Update(f.ctx, schedules[0]))
+
+	status, err := allocation.NewQuotaCalculator(w).CalculateBranchQuotaStatus(f.ctx, branch.ID, testDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.TotalAvailable != 1 || status.TotalRequired != 1 || status.Group2Score != -1 {
+		t.Errorf("status before recompute: available %d, required %d, group2 %d; want 1, 1, -1",
+			status.TotalAvailable, status.TotalRequired, status.Group2Score)
+	}
+
+	if _, err := worker.RunOnce(f.ctx); err != nil {
+		t.Fatal(err)
+	}
+	recomputed, err := f.repos.BranchQuotaSummary.GetByBranchIDAndDate(f.ctx, branch.ID, testDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recomputed.ID != summary.ID {
+		t.Errorf("recomputation replaced summary %s with %s; want an upsert", summary.ID, recomputed.ID)
+	}
+	if recomputed.TotalAvailable != 1 || recomputed.TotalRequired != 1 || recomputed.Group2Score != -1 {
+		t.Errorf("available %d, required %d, group2 %d; want 1, 1, -1",
+			recomputed.TotalAvailable, recomputed.TotalRequired, recomputed.Group2Score)
+	}
+}
+
+func TestDoctorRoster_ImportsOnlyDeviations(t *testing.T) {
+	f := newAllocationFixture(t)
+	home := f.addBranch("CPN", 1, 1, false)
+	other := f.addBranch("CTR", 1, 1, false)
+	d := f.addDoctor(home) // every Wednesday at CPN
+	nextWeek := testDate.AddDate(0, 0, 7)
+	f.must(f.repos.DoctorScheduleOverride.Create(f.ctx, &models.DoctorScheduleOverride{DoctorID: d.ID, Date: nextWeek, Type: "off"}))
+
+	gin.SetMode(gin.TestMode)
+	h := handlers.NewDoctorHandler(
+		&postgres.Repositories{Repositories: f.repos.Repositories, UnitOfWork: f.repos.UnitOfWork},
+		doctor.NewRosterMaterializer(f.repos.UnitOfWork, 0),
+	)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("user_id", uuid.New().String()) })
+	r.GET("/doctors/roster/export", h.ExportRoster)
+	r.POST("/doctors/roster/import", h.ImportRoster)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/doctors/roster/export?year=2030&month=1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("export = %d %s", w.Code, w.Body.String())
+	}
+	exported := w.Body.Bytes()
+
+	// importRoster uploads the exported roster after edit changes it
+	importRoster := func(edit func(f *excelize.File)) (int, map[string]interface{}) {
+		t.Helper()
+		data := exported
+		if edit != nil {
+			wb, err := excelize.OpenReader(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			edit(wb)
+			buf, err := wb.WriteToBuffer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			data = buf.Bytes()
+		}
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, err := form.CreateFormFile("file", "doctor-roster-2030-01.xlsx")
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write(data)
+		form.Close()
+		req := httptest.NewRequest(http.MethodPost, "/doctors/roster/import", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var response map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatal(err)
+		}
+		return w.Code, response
+	}
+
+	// As exported: next Wednesday is already overridden off, so nothing changes
+	code, response := importRoster(nil)
+	if code != http.StatusOK || response["overrides_set"] != float64(0) || response["overrides_cleared"] != float64(0) {
+		t.Fatalf("unchanged roster = %d %v; want nothing to change", code, response)
+	}
+
+	// Thursday at CTR, Friday split, a bad branch on Saturday and next
+	// Wednesday back at CPN; column C is January 1st
+	code, response = importRoster(func(wb *excelize.File) {
+		f.must(wb.SetCellValue("Roster", "E4", "ctr"))
+		f.must(wb.SetCellValue("Roster", "F4", "CPN/CTR"))
+		f.must(wb.SetCellValue("Roster", "G4", "XYZ"))
+		f.must(wb.SetCellValue("Roster", "K4", "CPN"))
+	})
+	if code != http.StatusPartialContent || response["parse_warnings"] == nil || response["import_warnings"] == nil {
+		t.Errorf("edited roster = %d %v; want the bad branch and split day reported", code, response)
+	}
+	if response["overrides_set"] != float64(1) || response["overrides_cleared"] != float64(1) {
+		t.Errorf("edited roster set %v and cleared %v overrides; want one each", response["overrides_set"], response["overrides_cleared"])
+	}
+	overrides, err := f.repos.DoctorScheduleOverride.GetByDateRange(f.ctx, testDate, nextWeek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(overrides) != 1 || overrides[0].Date.Format("2006-01-02") != "2030-01-03" ||
+		overrides[0].Type != "working" || overrides[0].BranchID == nil || *overrides[0].BranchID != other.ID {
+		t.Errorf("overrides = %v; want only Thursday at CTR", overrides)
+	}
+}
+
+func TestScheduleValidator_ReportsGapsCapacityAndOverrides(t *testing.T) {
+	f := newAllocationFixture(t)
+	home := f.addBranch("CPN", 1, 1, false)
+	other := f.addBranch("CTR", 1, 1, false)
+	closed := f.addBranch("PNK", 1, 1, false)
+	capacity := 1
+	home.MaxDoctors = &capacity
+	f.must(f.repos.Branch.Update(f.ctx, home))
+
+	d1, d2, d3 := f.addDoctor(home), f.addDoctor(other), f.addDoctor(closed) // every Wednesday
+	endDate := testDate.AddDate(0, 0, 13)
+	day := func(n int) time.Time { return testDate.AddDate(0, 0, n) }
+	f.must(f.repos.BranchClosure.Create(f.ctx, &models.BranchClosure{BranchID: &closed.ID, StartDate: testDate, EndDate: endDate, Reason: "Renovation"}))
+	f.must(f.repos.BranchClosure.Create(f.ctx, &models.BranchClosure{BranchID: &other.ID, StartDate: day(3), EndDate: day(3), Reason: "Event"}))
+	for _, o := range []*models.DoctorScheduleOverride{
+		{DoctorID: d1.ID, Date: testDate, Type: "working", BranchID: &home.ID}, // what the default says
+		{DoctorID: d2.ID, Date: day(7), Type: "working", BranchID: &home.ID},   // CPN takes one doctor
+		{DoctorID: d3.ID, Date: day(1), Type: "working", BranchID: &closed.ID}, // PNK is closed
+	} {
+		f.must(f.repos.DoctorScheduleOverride.Create(f.ctx, o))
+	}
+
+	calc := doctor.NewDoctorScheduleCalculator(
+		f.repos.DoctorDefaultSchedule,
+		f.repos.DoctorWeeklyOffDay,
+		f.repos.DoctorScheduleOverride,
+		f.repos.Doctor,
+	)
+	validation, err := doctor.NewScheduleValidator(calc, f.repos.Branch, f.repos.BranchClosure, f.repos.DoctorOnOffDay).
+		Validate(f.ctx, testDate, endDate, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, issue := range validation.Issues {
+		got = append(got, fmt.Sprintf("%s %s %s", issue.Date.Format("01-02"), issue.Type, issue.BranchCode))
+		if issue.Type == models.ScheduleIssueCoverageGap && (issue.Days != 12 || !issue.EndDate.Equal(endDate)) {
+			t.Errorf("CTR gap of %d days to %s; want 12 open days to the end", issue.Days, issue.EndDate.Format("01-02"))
+		}
+	}
+	// CTR loses its only doctor's second Wednesday to CPN, and is closed one day
+	want := []string{
+		"01-02 redundant_override CPN",
+		"01-03 coverage_gap CTR",
+		"01-03 override_at_closed_branch PNK",
+		"01-09 over_capacity CPN",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("issues\n%v\nwant\n%v", got, want)
+	}
+	if validation.Valid || validation.Errors != 2 || validation.Warnings != 2 {
+		t.Errorf("valid %v with %d errors and %d warnings; want invalid with 2 and 2", validation.Valid, validation.Errors, validation.Warnings)
+	}
+}
+
+func TestScheduleOverride_RefusesClosedBranchAndSecondOverride(t *testing.T) {
+	f := newAllocationFixture(t)
+	home := f.addBranch("CPN", 1, 1, false)
+	closed := f.addBranch("PNK", 1, 1, false)
+	d := f.addDoctor(home)
+	f.must(f.repos.BranchClosure.Create(f.ctx, &models.BranchClosure{BranchID: &closed.ID, StartDate: testDate, EndDate: testDate, Reason: "Renovation"}))
+
+	gin.SetMode(gin.TestMode)
+	h := handlers.NewDoctorHandler(
+		&postgres.Repositories{Repositories: f.repos.Repositories, UnitOfWork: f.repos.UnitOfWork},
+		doctor.NewRosterMaterializer(f.repos.UnitOfWork, 0),
+	)
+	r := gin.New()
+	r.Use(func(c *gin.Context) { c.Set("user_id", uuid.New().String()) })
+	r.POST("/doctors/schedule-overrides", h.CreateScheduleOverride)
+	create := func(branch *models.Branch) int {
+		t.Helper()
+		body := fmt.Sprintf(`{"doctor_id":%q,"date":%q,"type":"working","branch_id":%q}`, d.ID, testDate.Format("2006-01-02"), branch.ID)
+		req := httptest.NewRequest(http.MethodPost, "/doctors/schedule-overrides", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := create(closed); code != http.StatusBadRequest {
+		t.Errorf("override at a closed branch = %d, want 400", code)
+	}
+	if code := create(home); code != http.StatusCreated {
+		t.Fatalf("override at an open branch = %d, want 201", code)
+	}
+	second := &models.DoctorScheduleOverride{DoctorID: d.ID, Date: testDate, Type: "off"}
+	if err := f.repos.DoctorScheduleOverride.Create(f.ctx, second); err == nil {
+		t.Error("a second override for the doctor and date was stored")
+	}
+}
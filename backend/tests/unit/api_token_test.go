@@ -0,0 +1,303 @@
+package unit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/models"
+	"vsq-oper-manpower/backend/internal/handlers"
+	"vsq-oper-manpower/backend/internal/middleware"
+	"vsq-oper-manpower/backend/internal/repositories/memory"
+	"vsq-oper-manpower/backend/internal/repositories/postgres"
+	"vsq-oper-manpower/backend/pkg/apitoken"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestAPIToken_GenerateAndHash(t *testing.T) {
+	token, prefix, hash, err := apitoken.Generate()
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	if !strings.HasPrefix(token, apitoken.TokenPrefix+"_"+prefix+"_") {
+		t.Errorf("Token %q does not start with its prefix %q", token, prefix)
+	}
+	if hash != apitoken.Hash(token) {
+		t.Error("Returned hash does not match Hash(token)")
+	}
+	if strings.Contains(hash, token) {
+		t.Error("Hash must not contain the plaintext token")
+	}
+	parsed, ok := apitoken.ParsePrefix(token)
+	if !ok || parsed != prefix {
+		t.Errorf("ParsePrefix() = %q, %v; want %q, true", parsed, ok, prefix)
+	}
+
+	other, _, _, err := apitoken.Generate()
+	if err != nil {
+		t.Fatalf("Failed to generate second token: %v", err)
+	}
+	if other == token {
+		t.Error("Two generated tokens must differ")
+	}
+}
+
+func TestAPIToken_FromAuthorizationHeader(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+		ok     bool
+	}{
+		{"Bearer vsq_abc_def", "vsq_abc_def", true},
+		{"bearer vsq_abc_def", "vsq_abc_def", true},
+		{"Basic dXNlcjpwYXNz", "", false},
+		{"Bearer ", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := apitoken.FromAuthorizationHeader(tt.header)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("FromAuthorizationHeader(%q) = %q, %v; want %q, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestAPIToken_Scopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		granted []string
+		method  string
+		path    string
+		allowed bool
+	}{
+		{"exact read", []string{"branches:read"}, "GET", "/api/branches/123/revenue", true},
+		{"read does not allow write", []string{"branches:read"}, "POST", "/api/branches/revenue/import", false},
+		{"write implies read", []string{"branches:write"}, "GET", "/api/branches", true},
+		{"resource wildcard", []string{"staff:*"}, "POST", "/api/staff/import", true},
+		{"other resource", []string{"staff:*"}, "GET", "/api/branches", false},
+		{"global wildcard", []string{"*"}, "DELETE", "/api/quotas/1", true},
+		{"no scopes", nil, "GET", "/api/branches", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			required := apitoken.RequiredScope(tt.method, tt.path)
+			if got := apitoken.Allows(tt.granted, required); got != tt.allowed {
+				t.Errorf("Allows(%v, %q) = %v; want %v", tt.granted, required, got, tt.allowed)
+			}
+		})
+	}
+
+	if err := apitoken.ValidateScope("revenue"); err == nil {
+		t.Error("ValidateScope should reject a scope without access level")
+	}
+	if err := apitoken.ValidateScope("revenue:admin"); err == nil {
+		t.Error("ValidateScope should reject an unknown access level")
+	}
+}
+
+func TestAPIToken_IsUsable(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	if !(&models.APIToken{}).IsUsable(now) {
+		t.Error("Token without expiry should be usable")
+	}
+	if !(&models.APIToken{ExpiresAt: &future}).IsUsable(now) {
+		t.Error("Token expiring in the future should be usable")
+	}
+	if (&models.APIToken{ExpiresAt: &past}).IsUsable(now) {
+		t.Error("Expired token should not be usable")
+	}
+	if (&models.APIToken{RevokedAt: &past}).IsUsable(now) {
+		t.Error("Revoked token should not be usable")
+	}
+}
+
+func TestAPIToken_WritesAsServiceAccountUser(t *testing.T) {
+	ctx := context.Background()
+	repos := memory.NewRepositories()
+	if err := memory.Seed(ctx, repos); err != nil {
+		t.Fatal(err)
+	}
+	role, err := repos.Role.GetByName(ctx, "admin")
+	if err != nil || role == nil {
+		t.Fatalf("admin role not seeded: %v", err)
+	}
+
+	account := &models.ServiceAccount{Name: "pos-feed", RoleID: role.ID, IsActive: true}
+	if err := repos.ServiceAccount.Create(ctx, account); err != nil {
+		t.Fatal(err)
+	}
+	raw, prefix, hash, err := apitoken.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := &models.APIToken{ServiceAccountID: account.ID, Name: "feed", TokenPrefix: prefix, TokenHash: hash, Scopes: []string{"quotas:write"}}
+	if err := repos.APIToken.Create(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+	branch := &models.Branch{ID: uuid.New(), Name: "API", Code: "API"}
+	if err := repos.Branch.Create(ctx, branch); err != nil {
+		t.Fatal(err)
+	}
+	positions, err := repos.Position.List(ctx)
+	if err != nil || len(positions) == 0 {
+		t.Fatalf("positions not seeded: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.APITokenAuth(repos.APIToken, repos.ServiceAccount))
+	h := handlers.NewQuotaHandler(&postgres.Repositories{Repositories: repos.Repositories, UnitOfWork: repos.UnitOfWork}, nil)
+	router.POST("/api/quotas", middleware.RequireRole("admin", "area_manager"), h.CreateQuota)
+
+	body := fmt.Sprintf(`{"branch_id":%q,"position_id":%q,"designated_quota":2,"minimum_required":1}`, branch.ID, positions[0].ID)
+	req := httptest.NewRequest(http.MethodPost, "/api/quotas", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+raw)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Token write = %d %s; want 201", w.Code, w.Body.String())
+	}
+
+	quotas, err := repos.PositionQuota.GetByBranchID(ctx, branch.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quotas) != 1 || quotas[0].CreatedBy != account.ID {
+		t.Errorf("Quota should be created by the service account %s, got %+v", account.ID, quotas)
+	}
+}
+
+func TestAPIToken_CannotManageTokens(t *testing.T) {
+	ctx := context.Background()
+	repos := memory.NewRepositories()
+	if err := memory.Seed(ctx, repos); err != nil {
+		t.Fatal(err)
+	}
+	role, err := repos.Role.GetByName(ctx, "admin")
+	if err != nil || role == nil {
+		t.Fatalf("admin role not seeded: %v", err)
+	}
+	account := &models.ServiceAccount{Name: "ops", RoleID: role.ID, IsActive: true}
+	if err := repos.ServiceAccount.Create(ctx, account); err != nil {
+		t.Fatal(err)
+	}
+	raw, prefix, hash, err := apitoken.Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := &models.APIToken{ServiceAccountID: account.ID, Name: "ops", TokenPrefix: prefix, TokenHash: hash, Scopes: []string{"*"}}
+	if err := repos.APIToken.Create(ctx, token); err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.APITokenAuth(repos.APIToken, repos.ServiceAccount))
+	h := handlers.NewAPITokenHandler(&postgres.Repositories{Repositories: repos.Repositories, UnitOfWork: repos.UnitOfWork})
+	tokens := router.Group("/api/admin/tokens", middleware.RequireRole("admin"), middleware.RequireSession())
+	tokens.POST("", h.Create)
+	tokens.POST("/service-accounts", h.CreateServiceAccount)
+
+	requests := map[string]string{
+		"/api/admin/tokens":                  fmt.Sprintf(`{"service_account_id":%q,"name":"wider","scopes":["*"]}`, account.ID),
+		"/api/admin/tokens/service-accounts": fmt.Sprintf(`{"name":"another-admin","role_id":%q}`, role.ID),
+	}
+	for path, body := range requests {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+raw)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("POST %s with a token = %d %s; want 403", path, w.Code, w.Body.String())
+		}
+	}
+
+	issued, err := repos.APIToken.List(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accounts, err := repos.ServiceAccount.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issued) != 1 || len(accounts) != 1 {
+		t.Errorf("token requests created %d tokens and %d accounts; want only the original 1 and 1", len(issued), len(accounts))
+	}
+}
+
+func TestServiceAccount_DeleteRemovesBackingUser(t *testing.T) {
+	ctx := context.Background()
+	repos := memory.NewRepositories()
+	if err := memory.Seed(ctx, repos); err != nil {
+		t.Fatal(err)
+	}
+	role, err := repos.Role.GetByName(ctx, "admin")
+	if err != nil || role == nil {
+		t.Fatalf("admin role not seeded: %v", err)
+	}
+	idle := &models.ServiceAccount{Name: "idle", RoleID: role.ID, IsActive: true}
+	writer := &models.ServiceAccount{Name: "writer", RoleID: role.ID, IsActive: true}
+	for _, account := range []*models.ServiceAccount{idle, writer} {
+		if err := repos.ServiceAccount.Create(ctx, account); err != nil {
+			t.Fatal(err)
+		}
+	}
+	branches, err := repos.Branch.List(ctx)
+	if err != nil || len(branches) == 0 {
+		t.Fatalf("branches not seeded: %v", err)
+	}
+	position := &models.Position{ID: uuid.New(), Name: "Integration Clerk", PositionType: models.PositionTypeBranch}
+	if err := repos.Position.Create(ctx, position); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.PositionQuota.Create(ctx, &models.PositionQuota{
+		ID: uuid.New(), BranchID: branches[0].ID, PositionID: position.ID, DesignatedQuota: 1, IsActive: true, CreatedBy: writer.ID,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	users, err := repos.User.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, user := range users {
+		if user.ID == idle.ID || user.ID == writer.ID {
+			t.Errorf("User list should not include the backing user of a service account, got %s", user.Username)
+		}
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	h := handlers.NewAPITokenHandler(&postgres.Repositories{Repositories: repos.Repositories, UnitOfWork: repos.UnitOfWork})
+	router.DELETE("/api/admin/tokens/service-accounts/:id", h.DeleteServiceAccount)
+	remove := func(id uuid.UUID) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/admin/tokens/service-accounts/"+id.String(), nil))
+		return w.Code
+	}
+
+	if code := remove(idle.ID); code != http.StatusOK {
+		t.Fatalf("Deleting an idle account = %d; want 200", code)
+	}
+	if user, err := repos.User.GetByID(ctx, idle.ID); err != nil || user != nil {
+		t.Errorf("Backing user of a deleted account = %v, %v; want it gone", user, err)
+	}
+
+	if code := remove(writer.ID); code != http.StatusConflict {
+		t.Fatalf("Deleting an account that wrote a quota = %d; want 409", code)
+	}
+	if account, err := repos.ServiceAccount.GetByID(ctx, writer.ID); err != nil || account == nil {
+		t.Errorf("Refused delete should keep the account, got %v, %v", account, err)
+	}
+}
@@ -2,8 +2,6 @@ package unit
 
 import (
 	"testing"
-	"vsq-oper-manpower/backend/internal/handlers"
-	"vsq-oper-manpower/backend/internal/repositories/postgres"
 )
 
 // Placeholder test file - actual tests will be implemented
@@ -0,0 +1,97 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/config"
+)
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConfigFileAndEnvOverrides(t *testing.T) {
+	path := writeConfigFile(t, `
+database:
+  host: db.internal
+  max_open_conns: 40
+cache:
+  ttl: 90s
+mcp:
+  timeout: 3s
+`)
+	t.Setenv("DB_MAX_OPEN_CONNS", "50")
+
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Database.Host != "db.internal" || cfg.Cache.TTL != 90*time.Second || cfg.MCP.Timeout != 3*time.Second {
+		t.Errorf("file settings not applied: %+v", cfg)
+	}
+	if cfg.Database.MaxOpenConns != 50 {
+		t.Errorf("max_open_conns = %d, want the environment's 50", cfg.Database.MaxOpenConns)
+	}
+	if cfg.Database.Port != "5432" || cfg.Jobs.Workers != 2 {
+		t.Errorf("settings missing from the file lost their defaults: %+v", cfg)
+	}
+
+	// Typos and unparsable values are errors rather than silent defaults
+	if _, err := config.Load(writeConfigFile(t, "cache:\n  tll: 1m\n")); err == nil {
+		t.Error("unknown field accepted")
+	}
+	t.Setenv("JOB_WORKERS", "two")
+	if _, err := config.Load(path); err == nil || !strings.Contains(err.Error(), "JOB_WORKERS") {
+		t.Errorf("Load with JOB_WORKERS=two = %v, want an error naming it", err)
+	}
+}
+
+func TestConfigRefusesInsecureProductionDefaults(t *testing.T) {
+	t.Setenv("ENVIRONMENT", "production")
+
+	cfg, err := config.Read("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Features.TestData {
+		t.Error("test data endpoints enabled by default in production")
+	}
+	err = cfg.Validate()
+	if err == nil {
+		t.Fatal("production accepted the development defaults")
+	}
+	for _, want := range []string{"session_secret", "database.password", "database.sslmode"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("validation error %q does not mention %s", err, want)
+		}
+	}
+
+	t.Setenv("SESSION_SECRET", strings.Repeat("s", 32))
+	t.Setenv("DB_PASSWORD", "hunter2-but-longer")
+	t.Setenv("DB_SSLMODE", "require")
+	cfg, err = config.Load("")
+	if err != nil {
+		t.Fatalf("secure production config rejected: %v", err)
+	}
+
+	// Printing never shows secrets
+	out, err := cfg.Redacted().YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), "hunter2") || strings.Contains(string(out), "ssssssss") {
+		t.Errorf("redacted config leaks secrets:\n%s", out)
+	}
+	if cfg.Database.Password != "hunter2-but-longer" {
+		t.Error("Redacted modified the original config")
+	}
+}
@@ -0,0 +1,330 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+	"vsq-oper-manpower/backend/internal/usecases/allocation"
+
+	"github.com/google/uuid"
+)
+
+// seedBranches adds n open branches, each with a mix of scheduled staff
+func seedBranches(f *allocationFixture, n int) []uuid.UUID {
+	working, off, leave := models.ScheduleStatusWorking, models.ScheduleStatusOff, models.ScheduleStatusLeave
+	ids := make([]uuid.UUID, 0, n)
+	for i := 0; i < n; i++ {
+		branch := f.addBranch(fmt.Sprintf("B%03d", i), 4, 2, true)
+		f.addStaff(branch, working, working, off, leave, "")
+		ids = append(ids, branch.ID)
+	}
+	return ids
+}
+
+func TestQuotaCalculator_CalculateBranchesQuotaStatusMatchesSingleBranch(t *testing.T) {
+	f := newAllocationFixture(t)
+	ids := seedBranches(f, 5)
+	closed := f.addBranch("CLOSED", 2, 1, false)
+	ids = append(ids, closed.ID)
+
+	w := f.wrapper()
+	w.BranchQuotaSummary = nil
+	calc := allocation.NewQuotaCalculator(w)
+
+	batch, err := calc.CalculateBranchesQuotaStatus(f.ctx, ids, testDate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batch) != len(ids) {
+		t.Fatalf("got %d statuses, want %d", len(batch), len(ids))
+	}
+	for _, got := range batch {
+		want, err := calc.CalculateBranchQuotaStatus(f.ctx, got.BranchID, testDate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.TotalAvailable != want.TotalAvailable || got.TotalRequired != want.TotalRequired ||
+			len(got.PositionStatuses) != len(want.PositionStatuses) {
+			t.Errorf("%s: batch status %d/%d, single status %d/%d", got.BranchCode,
+				got.TotalAvailable, got.TotalRequired, want.TotalAvailable, want.TotalRequired)
+		}
+	}
+}
+
+// BenchmarkOverviewGenerator_GenerateDayOverview measures a cold day overview
+// for every branch, bypassing both the overview cache and quota summaries
+func BenchmarkOverviewGenerator_GenerateDayOverview(b *testing.B) {
+	for _, n := range []int{50, 200} {
+		b.Run(fmt.Sprintf("branches=%d", n), func(b *testing.B) {
+			f := newAllocationFixture(b)
+			seedBranches(f, n)
+			w := f.wrapper()
+			w.BranchQuotaSummary = nil
+
+			b.ResetTimer()
+			start := time.Now()
+			for i := 0; i < b.N; i++ {
+				g := allocation.NewOverviewGenerator(w, allocation.NewQuotaCalculator(w))
+				overview, err := g.GenerateDayOverview(f.ctx, testDate, nil)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if overview.TotalBranches != n {
+					b.Fatalf("got %d branches, want %d", overview.TotalBranches, n)
+				}
+			}
+			perOp := time.Since(start) / time.Duration(b.N)
+			b.ReportMetric(float64(perOp.Microseconds())/1000, "ms/overview")
+		})
+	}
+}
+
+// repoCalls counts the reads that have per-branch or per-day variants, so a
+// loader that falls back to one query per branch or day shows up in the counts
+type repoCalls map[string]int
+
+type countingRevenue struct {
+	interfaces.RevenueRepository
+	calls repoCalls
+}
+
+func (r countingRevenue) GetByBranchID(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) ([]*models.RevenueData, error) {
+	r.calls["Revenue.GetByBranchID"]++
+	return r.RevenueRepository.GetByBranchID(ctx, branchID, startDate, endDate)
+}
+
+func (r countingRevenue) GetByDate(ctx context.Context, date time.Time) ([]*models.RevenueData, error) {
+	r.calls["Revenue.GetByDate"]++
+	return r.RevenueRepository.GetByDate(ctx, date)
+}
+
+func (r countingRevenue) GetByBranchIDsAndDateRange(ctx context.Context, branchIDs []uuid.UUID, startDate, endDate time.Time) ([]*models.RevenueData, error) {
+	r.calls["Revenue.GetByBranchIDsAndDateRange"]++
+	return r.RevenueRepository.GetByBranchIDsAndDateRange(ctx, branchIDs, startDate, endDate)
+}
+
+type countingSchedule struct {
+	interfaces.ScheduleRepository
+	calls repoCalls
+}
+
+func (r countingSchedule) GetByBranchID(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) ([]*models.StaffSchedule, error) {
+	r.calls["Schedule.GetByBranchID"]++
+	return r.ScheduleRepository.GetByBranchID(ctx, branchID, startDate, endDate)
+}
+
+func (r countingSchedule) GetByStaffID(ctx context.Context, staffID uuid.UUID, startDate, endDate time.Time) ([]*models.StaffSchedule, error) {
+	r.calls["Schedule.GetByStaffID"]++
+	return r.ScheduleRepository.GetByStaffID(ctx, staffID, startDate, endDate)
+}
+
+func (r countingSchedule) GetByStaffIDs(ctx context.Context, staffIDs []uuid.UUID, startDate, endDate time.Time) (map[uuid.UUID][]*models.StaffSchedule, error) {
+	r.calls["Schedule.GetByStaffIDs"]++
+	return r.ScheduleRepository.GetByStaffIDs(ctx, staffIDs, startDate, endDate)
+}
+
+type countingStaff struct {
+	interfaces.StaffRepository
+	calls repoCalls
+}
+
+func (r countingStaff) GetByID(ctx context.Context, id uuid.UUID) (*models.Staff, error) {
+	r.calls["Staff.GetByID"]++
+	return r.StaffRepository.GetByID(ctx, id)
+}
+
+func (r countingStaff) GetByBranchID(ctx context.Context, branchID uuid.UUID) ([]*models.Staff, error) {
+	r.calls["Staff.GetByBranchID"]++
+	return r.StaffRepository.GetByBranchID(ctx, branchID)
+}
+
+func (r countingStaff) GetByBranchIDs(ctx context.Context, branchIDs []uuid.UUID) (map[uuid.UUID][]*models.Staff, error) {
+	r.calls["Staff.GetByBranchIDs"]++
+	return r.StaffRepository.GetByBranchIDs(ctx, branchIDs)
+}
+
+func (r countingStaff) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.Staff, error) {
+	r.calls["Staff.GetByIDs"]++
+	return r.StaffRepository.GetByIDs(ctx, ids)
+}
+
+type countingQuota struct {
+	interfaces.PositionQuotaRepository
+	calls repoCalls
+}
+
+func (r countingQuota) GetByBranchID(ctx context.Context, branchID uuid.UUID) ([]*models.PositionQuota, error) {
+	r.calls["PositionQuota.GetByBranchID"]++
+	return r.PositionQuotaRepository.GetByBranchID(ctx, branchID)
+}
+
+func (r countingQuota) List(ctx context.Context, filters interfaces.PositionQuotaFilters) ([]*models.PositionQuota, error) {
+	r.calls["PositionQuota.List"]++
+	return r.PositionQuotaRepository.List(ctx, filters)
+}
+
+type countingRotation struct {
+	interfaces.RotationRepository
+	calls repoCalls
+}
+
+func (r countingRotation) GetByDate(ctx context.Context, date time.Time) ([]*models.RotationAssignment, error) {
+	r.calls["Rotation.GetByDate"]++
+	return r.RotationRepository.GetByDate(ctx, date)
+}
+
+func (r countingRotation) GetByBranchID(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) ([]*models.RotationAssignment, error) {
+	r.calls["Rotation.GetByBranchID"]++
+	return r.RotationRepository.GetByBranchID(ctx, branchID, startDate, endDate)
+}
+
+func (r countingRotation) GetAssignments(ctx context.Context, filters interfaces.RotationFilters) ([]*models.RotationAssignment, error) {
+	r.calls["Rotation.GetAssignments"]++
+	return r.RotationRepository.GetAssignments(ctx, filters)
+}
+
+type countingDoctorAssignment struct {
+	interfaces.DoctorAssignmentRepository
+	calls repoCalls
+}
+
+func (r countingDoctorAssignment) GetByDateRange(ctx context.Context, startDate, endDate time.Time) ([]*models.DoctorAssignment, error) {
+	r.calls["DoctorAssignment.GetByDateRange"]++
+	return r.DoctorAssignmentRepository.GetByDateRange(ctx, startDate, endDate)
+}
+
+func (r countingDoctorAssignment) GetByBranchID(ctx context.Context, branchID uuid.UUID, startDate, endDate time.Time) ([]*models.DoctorAssignment, error) {
+	r.calls["DoctorAssignment.GetByBranchID"]++
+	return r.DoctorAssignmentRepository.GetByBranchID(ctx, branchID, startDate, endDate)
+}
+
+func (r countingDoctorAssignment) GetByDate(ctx context.Context, date time.Time) ([]*models.DoctorAssignment, error) {
+	r.calls["DoctorAssignment.GetByDate"]++
+	return r.DoctorAssignmentRepository.GetByDate(ctx, date)
+}
+
+func (r countingDoctorAssignment) GetDoctorsByBranchAndDate(ctx context.Context, branchID uuid.UUID, date time.Time) ([]*models.DoctorAssignment, error) {
+	r.calls["DoctorAssignment.GetDoctorsByBranchAndDate"]++
+	return r.DoctorAssignmentRepository.GetDoctorsByBranchAndDate(ctx, branchID, date)
+}
+
+// countingWrapper returns the fixture's repositories with their reads counted into calls
+func countingWrapper(f *allocationFixture, calls repoCalls) *allocation.RepositoriesWrapper {
+	w := f.wrapper()
+	w.BranchQuotaSummary = nil
+	w.Revenue = countingRevenue{w.Revenue, calls}
+	w.Schedule = countingSchedule{w.Schedule, calls}
+	w.Staff = countingStaff{w.Staff, calls}
+	w.PositionQuota = countingQuota{w.PositionQuota, calls}
+	w.Rotation = countingRotation{w.Rotation, calls}
+	w.DoctorAssignment = countingDoctorAssignment{w.DoctorAssignment, calls}
+	return w
+}
+
+func TestLoadDayContext_RepositoryCallsDoNotGrowWithBranchesOrDays(t *testing.T) {
+	load := func(branches, days int) repoCalls {
+		f := newAllocationFixture(t)
+		ids := seedBranches(f, branches)
+		for _, id := range ids {
+			f.must(f.repos.Revenue.Create(f.ctx, &models.RevenueData{
+				ID: uuid.New(), BranchID: id, Date: testDate, ExpectedRevenue: 100000,
+			}))
+		}
+		calls := repoCalls{}
+		dc, err := allocation.LoadDayContext(f.ctx, countingWrapper(f, calls), ids, testDate, testDate.AddDate(0, 0, days-1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rd := dc.Revenue(ids[0], testDate); rd == nil || rd.ExpectedRevenue != 100000 {
+			t.Fatalf("revenue = %+v, want the entered branch revenue", rd)
+		}
+		return calls
+	}
+
+	small, large := load(2, 1), load(40, 31)
+	if len(small) == 0 {
+		t.Fatal("no repository calls were counted")
+	}
+	for method := range large {
+		if large[method] != small[method] {
+			t.Errorf("%s: %d calls for 40 branches over 31 days, %d for 2 branches over 1 day",
+				method, large[method], small[method])
+		}
+	}
+}
+
+func TestOverviewGenerator_GenerateDayOverviewRepositoryCallsDoNotGrowWithBranches(t *testing.T) {
+	generate := func(branches int) repoCalls {
+		f := newAllocationFixture(t)
+		seedBranches(f, branches)
+		calls := repoCalls{}
+		w := countingWrapper(f, calls)
+		overview, err := allocation.NewOverviewGenerator(w, allocation.NewQuotaCalculator(w)).GenerateDayOverview(f.ctx, testDate, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if overview.TotalBranches != branches {
+			t.Fatalf("got %d branches, want %d", overview.TotalBranches, branches)
+		}
+		return calls
+	}
+
+	small, large := generate(2), generate(50)
+	for method := range large {
+		if large[method] != small[method] {
+			t.Errorf("%s: %d calls for 50 branches, %d for 2", method, large[method], small[method])
+		}
+	}
+}
+
+func TestQuotaCalculator_CalculateMonthlyMatrixMatchesMonthlyStatus(t *testing.T) {
+	f := newAllocationFixture(t)
+	ids := seedBranches(f, 3)
+	w := f.wrapper()
+	w.BranchQuotaSummary = nil
+	calc := allocation.NewQuotaCalculator(w).WithWorkers(4)
+
+	year, month := testDate.Year(), int(testDate.Month())
+	matrix, err := calc.CalculateMonthlyMatrix(f.ctx, ids, year, month)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matrix) != len(ids) {
+		t.Fatalf("got %d rows, want %d", len(matrix), len(ids))
+	}
+	for row, branchID := range ids {
+		monthly, err := calc.CalculateMonthlyQuotaStatus(f.ctx, branchID, year, month)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(matrix[row]) != 31 || len(monthly) != 31 {
+			t.Fatalf("row %d has %d days, monthly has %d, want 31", row, len(matrix[row]), len(monthly))
+		}
+		for day, got := range matrix[row] {
+			want := monthly[day]
+			if got.BranchID != branchID || !got.Date.Equal(want.Date) ||
+				got.TotalAssigned != want.TotalAssigned || got.TotalRequired != want.TotalRequired {
+				t.Errorf("row %d day %d: got %s %s %d/%d, want %s %s %d/%d", row, day,
+					got.BranchCode, got.Date.Format("2006-01-02"), got.TotalAssigned, got.TotalRequired,
+					want.BranchCode, want.Date.Format("2006-01-02"), want.TotalAssigned, want.TotalRequired)
+			}
+		}
+	}
+}
+
+func TestQuotaCalculator_CalculateMonthlyMatrixHonoursCancellation(t *testing.T) {
+	f := newAllocationFixture(t)
+	ids := seedBranches(f, 2)
+	ctx, cancel := context.WithCancel(f.ctx)
+	cancel()
+
+	_, err := allocation.NewQuotaCalculator(f.wrapper()).CalculateMonthlyMatrix(ctx, ids, testDate.Year(), int(testDate.Month()))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
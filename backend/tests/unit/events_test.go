@@ -0,0 +1,373 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/events"
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+	"vsq-oper-manpower/backend/internal/repositories/publishing"
+	"vsq-oper-manpower/backend/internal/usecases/allocation"
+	"vsq-oper-manpower/backend/internal/usecases/doctor"
+	"vsq-oper-manpower/backend/pkg/lru"
+
+	"github.com/google/uuid"
+)
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	cache := lru.New[string, int](2, 0)
+	cache.Put("a", 1)
+	cache.Put("b", 2)
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("a should be cached")
+	}
+	cache.Put("c", 3) // evicts b, the least recently used
+
+	if _, ok := cache.Get("b"); ok {
+		t.Error("b should have been evicted")
+	}
+	if v, ok := cache.Get("a"); !ok || v != 1 {
+		t.Errorf("a = %d, %v; want 1, true", v, ok)
+	}
+
+	stats := cache.Stats()
+	if stats.Size != 2 || stats.Hits != 2 || stats.Misses != 1 || stats.Evictions != 1 {
+		t.Errorf("stats = %+v, want size 2, 2 hits, 1 miss, 1 eviction", stats)
+	}
+}
+
+func TestLRU_ExpiresAndInvalidates(t *testing.T) {
+	cache := lru.New[int, string](10, 20*time.Millisecond)
+	cache.Put(1, "one")
+	cache.Put(2, "two")
+	cache.Put(3, "three")
+
+	if n := cache.RemoveFunc(func(k int, _ string) bool { return k%2 == 1 }); n != 2 {
+		t.Errorf("RemoveFunc removed %d, want 2", n)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, ok := cache.Get(2); ok {
+		t.Error("2 should have expired")
+	}
+
+	stats := cache.Stats()
+	if stats.Size != 0 || stats.Invalidations != 2 || stats.Expirations != 1 {
+		t.Errorf("stats = %+v, want empty with 2 invalidations and 1 expiration", stats)
+	}
+}
+
+// eventRecorder collects the events published on a bus
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []events.Event
+}
+
+func (r *eventRecorder) record(_ context.Context, e events.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+func (r *eventRecorder) take() []events.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	evs := r.events
+	r.events = nil
+	return evs
+}
+
+func newPublishingFixture(t *testing.T) (*allocationFixture, interfaces.Repositories, interfaces.UnitOfWork, *events.Bus, *eventRecorder) {
+	f := newAllocationFixture(t)
+	bus := events.NewBus()
+	recorder := &eventRecorder{}
+	bus.Subscribe(recorder.record)
+	repos, uow := publishing.Wrap(f.repos.Repositories, f.repos.UnitOfWork, bus)
+	return f, repos, uow, bus, recorder
+}
+
+func TestPublishing_RotationWritesPublishBranchAndDate(t *testing.T) {
+	f, repos, _, _, recorder := newPublishingFixture(t)
+	branch := f.addBranch("CPN", 2, 1, true)
+	rotationStaff := &models.Staff{ID: uuid.New(), Nickname: "rot", StaffType: models.StaffTypeRotation, PositionID: f.position.ID}
+	f.must(f.repos.Staff.Create(f.ctx, rotationStaff))
+
+	assignment := &models.RotationAssignment{ID: uuid.New(), RotationStaffID: rotationStaff.ID, BranchID: branch.ID, Date: testDate, AssignmentLevel: 1}
+	f.must(repos.Rotation.Create(f.ctx, assignment))
+	f.must(repos.Rotation.Delete(f.ctx, assignment.ID))
+
+	got := recorder.take()
+	if len(got) != 2 {
+		t.Fatalf("got %d events, want 2", len(got))
+	}
+	for _, e := range got {
+		if e.Kind != events.RotationChanged || e.BranchID != branch.ID || !e.AffectsDate(testDate) || e.AffectsDate(testDate.AddDate(0, 0, 1)) {
+			t.Errorf("event = %+v, want rotation change for %s on %s", e, branch.Code, testDate.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestPublishing_UnitOfWorkPublishesOnlyOnCommit(t *testing.T) {
+	f, _, uow, _, recorder := newPublishingFixture(t)
+	branch := f.addBranch("CPN", 2, 1, true)
+	// Quotas are unique per branch and position, and positions must exist before
+	// the memory transaction locks the store
+	var positions []*models.Position
+	for i := 0; i < 2; i++ {
+		position := &models.Position{ID: uuid.New(), Name: uuid.NewString(), PositionType: models.PositionTypeBranch}
+		f.must(f.repos.Position.Create(f.ctx, position))
+		positions = append(positions, position)
+	}
+	quota := func(i int) *models.PositionQuota {
+		return &models.PositionQuota{ID: uuid.New(), BranchID: branch.ID, PositionID: positions[i].ID, DesignatedQuota: 1, IsActive: true}
+	}
+
+	errRollback := errors.New("rollback")
+	err := uow.Do(f.ctx, func(tx *interfaces.Repositories) error {
+		if err := tx.PositionQuota.Create(f.ctx, quota(0)); err != nil {
+			return err
+		}
+		if n := len(recorder.take()); n != 0 {
+			t.Errorf("%d events published before commit", n)
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("err = %v, want rollback", err)
+	}
+	if n := len(recorder.take()); n != 0 {
+		t.Errorf("%d events published after rollback, want 0", n)
+	}
+
+	tx, err := uow.Begin(f.ctx)
+	f.must(err)
+	f.must(tx.Repositories().PositionQuota.Create(f.ctx, quota(1)))
+	f.must(tx.Commit())
+	got := recorder.take()
+	if len(got) != 1 || got[0].Kind != events.QuotaChanged || got[0].BranchID != branch.ID || !got[0].Date.IsZero() {
+		t.Errorf("events after commit = %+v, want one quota change for every date of %s", got, branch.Code)
+	}
+}
+
+func TestOverviewGenerator_EvictsOnlyAffectedOverviews(t *testing.T) {
+	f, repos, _, bus, _ := newPublishingFixture(t)
+	cpn := f.addBranch("CPN", 2, 1, true)
+	f.addStaff(cpn, models.ScheduleStatusWorking)
+	rama := f.addBranch("RAMA", 2, 1, true)
+	f.addStaff(rama, models.ScheduleStatusWorking)
+
+	w := f.wrapper()
+	w.BranchQuotaSummary = nil
+	g := allocation.NewOverviewGenerator(w, allocation.NewQuotaCalculator(w))
+	bus.Subscribe(g.HandleEvent)
+
+	overview := func(branch *models.Branch) *allocation.BranchQuotaStatus {
+		t.Helper()
+		o, err := g.GenerateDayOverview(f.ctx, testDate, []uuid.UUID{branch.ID})
+		f.must(err)
+		return o.BranchStatuses[0]
+	}
+	if got := overview(cpn).TotalAssigned; got != 1 {
+		t.Fatalf("CPN assigned = %d, want 1", got)
+	}
+	overview(rama)
+
+	// Another CPN staff member starts working: only CPN's overview goes stale
+	staff := &models.Staff{ID: uuid.New(), Nickname: "new", StaffType: models.StaffTypeBranch, PositionID: f.position.ID, BranchID: &cpn.ID}
+	f.must(f.repos.Staff.Create(f.ctx, staff))
+	f.must(repos.Schedule.Create(f.ctx, &models.StaffSchedule{
+		ID: uuid.New(), StaffID: staff.ID, BranchID: cpn.ID, Date: testDate, ScheduleStatus: models.ScheduleStatusWorking,
+	}))
+
+	if got := overview(cpn).TotalAssigned; got != 2 {
+		t.Errorf("CPN assigned after schedule change = %d, want 2", got)
+	}
+	overview(rama)
+
+	stats := g.CacheStats()
+	if stats.Invalidations != 1 || stats.Hits != 1 || stats.Misses != 3 {
+		t.Errorf("stats = %+v, want 1 invalidation (CPN), 1 hit (RAMA) and 3 misses", stats)
+	}
+}
+
+func TestStream_ReplaysFromLastEventID(t *testing.T) {
+	ctx := context.Background()
+	stream := events.NewStream(3, 0)
+	branchID := uuid.New()
+	for i := 0; i < 2; i++ {
+		stream.Handle(ctx, events.Event{Kind: events.ScheduleChanged, BranchID: branchID, Date: testDate.AddDate(0, 0, i)})
+	}
+
+	first, _, ok := stream.Subscribe("")
+	if !ok || first.Since == "" {
+		t.Fatalf("Subscribe() = since %q, %v; want the latest ID", first.Since, ok)
+	}
+	stream.Unsubscribe(first)
+
+	// A client that saw the first event replays only the second
+	stream.Handle(ctx, events.Event{Kind: events.QuotaStatusChanged, BranchID: branchID, Date: testDate})
+	sub, backlog, ok := stream.Subscribe(first.Since)
+	if !ok || len(backlog) != 1 || backlog[0].Event.Kind != events.QuotaStatusChanged {
+		t.Fatalf("backlog = %+v, %v; want the quota status change", backlog, ok)
+	}
+	stream.Handle(ctx, events.Event{Kind: events.RotationChanged})
+	if env := <-sub.Events; env.Event.Kind != events.RotationChanged {
+		t.Errorf("live event = %+v, want the rotation change", env)
+	}
+	stream.Unsubscribe(sub)
+
+	// IDs pushed out of the log, from another process or malformed cannot be replayed
+	for _, id := range []string{backlog[0].ID[:len(backlog[0].ID)-1] + "0", "0-1", "bogus"} {
+		sub, backlog, ok := stream.Subscribe(id)
+		if ok || backlog != nil {
+			t.Errorf("Subscribe(%q) = %d events, %v; want no replay", id, len(backlog), ok)
+		}
+		stream.Unsubscribe(sub)
+	}
+}
+
+func TestStream_DropsSubscribersThatFallBehind(t *testing.T) {
+	ctx := context.Background()
+	stream := events.NewStream(10, 0).WithBuffer(1)
+	sub, _, _ := stream.Subscribe("")
+	stream.Handle(ctx, events.Event{Kind: events.ScheduleChanged})
+	stream.Handle(ctx, events.Event{Kind: events.ScheduleChanged})
+
+	<-sub.Events
+	if _, open := <-sub.Events; open {
+		t.Error("subscription still open after overflowing its buffer")
+	}
+	if n := stream.Subscribers(); n != 0 {
+		t.Errorf("%d subscribers, want the slow one dropped", n)
+	}
+	stream.Unsubscribe(sub)
+}
+
+func TestSummaryWorker_PublishesQuotaStatusChanges(t *testing.T) {
+	f := newAllocationFixture(t)
+	cpn := f.addBranch("CPN", 2, 1, true)
+	f.addStaff(cpn, models.ScheduleStatusWorking)
+
+	bus := events.NewBus()
+	recorder := &eventRecorder{}
+	bus.Subscribe(recorder.record)
+	w := f.wrapper()
+	worker := allocation.NewSummaryWorker(w, allocation.NewQuotaCalculator(w)).
+		WithSchedule(time.Second, 0, 0).
+		WithEvents(bus)
+	n, err := worker.RunOnce(f.ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// One event per recomputed summary, including the day CPN's schedule changed
+	got := recorder.take()
+	sawTestDate := false
+	for _, e := range got {
+		if e.Kind != events.QuotaStatusChanged || e.BranchID != cpn.ID {
+			t.Errorf("event = %+v, want a quota status change for CPN", e)
+		}
+		sawTestDate = sawTestDate || e.Affects(cpn.ID, testDate)
+	}
+	if len(got) != n || !sawTestDate {
+		t.Errorf("got %d events for %d summaries (test date included: %v)", len(got), n, sawTestDate)
+	}
+}
+
+func TestSummaryWorker_MarksEventsFromItsOwnLoop(t *testing.T) {
+	f := newAllocationFixture(t)
+	cpn := f.addBranch("CPN", 2, 1, true)
+	f.addStaff(cpn, models.ScheduleStatusWorking)
+	w := f.wrapper()
+	worker := newTestSummaryWorker(w)
+	if _, err := worker.RunOnce(f.ctx); err != nil {
+		t.Fatal(err)
+	}
+	dirty := func() int {
+		t.Helper()
+		keys, err := f.repos.BranchQuotaSummary.GetDirtyByBranchIDsAndDateRange(f.ctx, []uuid.UUID{cpn.ID}, testDate, testDate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(keys)
+	}
+
+	// Events only note the key; the worker marks it when it next runs
+	for i := 0; i < 1000; i++ {
+		worker.HandleEvent(f.ctx, events.Event{Kind: events.DoctorOverrideChanged, BranchID: cpn.ID, Date: testDate})
+	}
+	if n := dirty(); n != 0 {
+		t.Fatalf("%d dirty keys before the worker ran, want 0", n)
+	}
+	waiting := allocation.NewSummaryWorker(w, allocation.NewQuotaCalculator(w)).WithSchedule(time.Second, time.Hour, time.Hour)
+	waiting.HandleEvent(f.ctx, events.Event{Kind: events.DoctorOverrideChanged, BranchID: cpn.ID, Date: testDate})
+	if _, err := waiting.RunOnce(f.ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n := dirty(); n != 1 {
+		t.Fatalf("%d dirty keys after the run, want 1", n)
+	}
+
+	// Stopping Run marks the keys still noted
+	if _, err := worker.RunOnce(f.ctx); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(f.ctx)
+	cancel()
+	stopping := allocation.NewSummaryWorker(w, allocation.NewQuotaCalculator(w)).WithSchedule(time.Hour, time.Hour, time.Hour)
+	stopping.HandleEvent(f.ctx, events.Event{Kind: events.BranchDayChanged, BranchID: cpn.ID, Date: testDate})
+	stopping.Run(ctx)
+	if n := dirty(); n != 1 {
+		t.Errorf("%d dirty keys after stopping, want 1", n)
+	}
+}
+
+func TestDoctorCalendar_CachesMonthsUntilScheduleWrites(t *testing.T) {
+	f, repos, _, bus, _ := newPublishingFixture(t)
+	home := f.addBranch("CPN", 1, 1, false)
+	other := f.addBranch("CTR", 1, 1, false)
+	d := f.addDoctor(home)
+
+	calendar := doctor.NewDoctorCalendar(doctor.NewDoctorScheduleCalculator(
+		repos.DoctorDefaultSchedule, repos.DoctorWeeklyOffDay, repos.DoctorScheduleOverride, repos.Doctor,
+	), 0, 0)
+	bus.Subscribe(calendar.HandleEvent)
+	repos.DoctorAssignment.(interface{ UseCalendar(*doctor.DoctorCalendar) }).UseCalendar(calendar)
+
+	doctorsAt := func(branch *models.Branch, date time.Time) int {
+		t.Helper()
+		assignments, err := repos.DoctorAssignment.GetDoctorsByBranchAndDate(f.ctx, branch.ID, date)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(assignments)
+	}
+
+	// Every branch and day of the month is served from one calculation
+	if doctorsAt(home, testDate) != 1 || doctorsAt(other, testDate) != 0 || doctorsAt(home, testDate.AddDate(0, 0, 7)) != 1 {
+		t.Fatal("the doctor should work at CPN on their weekday")
+	}
+	if stats := calendar.CacheStats(); stats.Misses != 1 || stats.Hits != 2 {
+		t.Fatalf("cache stats = %+v, want one miss and two hits", stats)
+	}
+
+	override := &models.DoctorScheduleOverride{DoctorID: d.ID, Date: testDate, Type: "working", BranchID: &other.ID}
+	f.must(repos.DoctorScheduleOverride.Create(f.ctx, override))
+	if doctorsAt(home, testDate) != 0 || doctorsAt(other, testDate) != 1 {
+		t.Fatal("an override should show as soon as it is written")
+	}
+
+	// Writes to any doctor schedule evict, dated or not
+	override.Type, override.BranchID = "off", nil
+	f.must(repos.DoctorScheduleOverride.Update(f.ctx, override))
+	if doctorsAt(home, testDate) != 0 || doctorsAt(other, testDate) != 0 {
+		t.Fatal("the doctor should be off once the override says so")
+	}
+	f.must(repos.DoctorScheduleOverride.DeleteByDoctorID(f.ctx, d.ID))
+	if doctorsAt(home, testDate) != 1 {
+		t.Fatal("the doctor should be back at CPN once their overrides are deleted")
+	}
+}
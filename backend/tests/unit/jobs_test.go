@@ -0,0 +1,362 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/domain/interfaces"
+	"vsq-oper-manpower/backend/internal/domain/models"
+	"vsq-oper-manpower/backend/internal/handlers"
+	"vsq-oper-manpower/backend/internal/middleware"
+	"vsq-oper-manpower/backend/internal/repositories/memory"
+	"vsq-oper-manpower/backend/internal/repositories/postgres"
+	"vsq-oper-manpower/backend/internal/usecases/jobs"
+	"vsq-oper-manpower/backend/pkg/cron"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestCronNext(t *testing.T) {
+	bangkok := time.FixedZone("ICT", 7*60*60)
+	// Wednesday
+	from := time.Date(2030, 1, 2, 10, 30, 0, 0, bangkok)
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"0 2 * * *", time.Date(2030, 1, 3, 2, 0, 0, 0, bangkok)},
+		{"0 6 * * 1", time.Date(2030, 1, 7, 6, 0, 0, 0, bangkok)},
+		{"*/15 * * * *", time.Date(2030, 1, 2, 10, 45, 0, 0, bangkok)},
+		{"0 9-17/4 * * 1-5", time.Date(2030, 1, 2, 13, 0, 0, 0, bangkok)},
+		{"@monthly", time.Date(2030, 2, 1, 0, 0, 0, 0, bangkok)},
+		// Restricted day of month and day of week match either
+		{"0 0 15 * 5", time.Date(2030, 1, 4, 0, 0, 0, 0, bangkok)},
+	}
+	for _, tt := range tests {
+		schedule, err := cron.Parse(tt.spec)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.spec, err)
+		}
+		if got := schedule.Next(from); !got.Equal(tt.want) {
+			t.Errorf("Next(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "0 0 * * 8", "5-1 * * * *", "*/0 * * * *"} {
+		if _, err := cron.Parse(spec); err == nil {
+			t.Errorf("Parse(%q) succeeded, want error", spec)
+		}
+	}
+}
+
+func newTestRunner(repos *memory.Repositories) *jobs.Runner {
+	return jobs.NewRunner(repos.Job, repos.JobSchedule).WithHeartbeat(10*time.Millisecond, time.Minute)
+}
+
+func TestJobRunnerRecordsProgressAndResult(t *testing.T) {
+	ctx := context.Background()
+	repos := memory.NewRepositories()
+	runner := newTestRunner(repos)
+	runner.Register("echo", func(ctx context.Context, job *models.Job, progress *jobs.Progress) (*jobs.Result, error) {
+		var payload struct{ Name string }
+		if err := jobs.DecodePayload(job, &payload); err != nil {
+			return nil, err
+		}
+		input, err := runner.Input(ctx, job)
+		if err != nil {
+			return nil, err
+		}
+		progress.Report(50, "halfway")
+		return &jobs.Result{
+			Data: map[string]string{"hello": payload.Name},
+			File: &models.JobFile{Name: "out.txt", ContentType: "text/plain", Data: input.Data},
+		}, nil
+	})
+
+	if _, err := runner.Enqueue(ctx, "missing", jobs.EnqueueOptions{}); !errors.Is(err, jobs.ErrUnknownJobType) {
+		t.Fatalf("Enqueue of unregistered type: got %v, want ErrUnknownJobType", err)
+	}
+
+	job, err := runner.Enqueue(ctx, "echo", jobs.EnqueueOptions{
+		Payload: map[string]string{"Name": "world"},
+		Input:   &models.JobFile{Name: "in.txt", Data: []byte("file body")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status != models.JobStatusQueued || job.MaxAttempts != 3 {
+		t.Fatalf("queued job = %s with %d attempts, want queued with 3", job.Status, job.MaxAttempts)
+	}
+
+	if n, err := runner.RunPending(ctx); err != nil || n != 1 {
+		t.Fatalf("RunPending = %d, %v; want 1 job", n, err)
+	}
+	done, _ := repos.Job.GetByID(ctx, job.ID)
+	if done.Status != models.JobStatusSucceeded || done.Progress != 100 || done.Attempts != 1 {
+		t.Fatalf("job = %s, progress %d, attempts %d; want succeeded, 100, 1", done.Status, done.Progress, done.Attempts)
+	}
+	var result map[string]string
+	if err := json.Unmarshal(done.Result, &result); err != nil || result["hello"] != "world" {
+		t.Errorf("result = %s (%v), want hello=world", done.Result, err)
+	}
+	file, _ := repos.Job.GetResultFile(ctx, job.ID)
+	if file == nil || done.ResultFileName != "out.txt" || string(file.Data) != "file body" {
+		t.Errorf("result file = %+v, want out.txt with the input", file)
+	}
+}
+
+func TestJobRunnerRetriesThenFails(t *testing.T) {
+	ctx := context.Background()
+	repos := memory.NewRepositories()
+	runner := newTestRunner(repos)
+	runner.Register("flaky", func(ctx context.Context, job *models.Job, progress *jobs.Progress) (*jobs.Result, error) {
+		return nil, errors.New("database unavailable")
+	})
+	runner.Register("invalid", func(ctx context.Context, job *models.Job, progress *jobs.Progress) (*jobs.Result, error) {
+		return nil, jobs.Permanent(errors.New("bad input"))
+	})
+	runner.Register("panics", func(ctx context.Context, job *models.Job, progress *jobs.Progress) (*jobs.Result, error) {
+		panic("boom")
+	})
+
+	flaky, _ := runner.Enqueue(ctx, "flaky", jobs.EnqueueOptions{MaxAttempts: 2})
+	invalid, _ := runner.Enqueue(ctx, "invalid", jobs.EnqueueOptions{})
+	panics, _ := runner.Enqueue(ctx, "panics", jobs.EnqueueOptions{MaxAttempts: 1})
+	if n, err := runner.RunPending(ctx); err != nil || n != 3 {
+		t.Fatalf("RunPending = %d, %v; want 3 jobs", n, err)
+	}
+
+	// A transient failure is retried later with backoff
+	got, _ := repos.Job.GetByID(ctx, flaky.ID)
+	if got.Status != models.JobStatusQueued || !got.RunAt.After(time.Now()) || got.Error != "database unavailable" {
+		t.Errorf("flaky job = %s at %v (%q), want queued for a later retry", got.Status, got.RunAt, got.Error)
+	}
+	// A permanent failure uses no further attempts
+	got, _ = repos.Job.GetByID(ctx, invalid.ID)
+	if got.Status != models.JobStatusFailed || got.Attempts != 1 {
+		t.Errorf("invalid job = %s after %d attempts, want failed after 1", got.Status, got.Attempts)
+	}
+	got, _ = repos.Job.GetByID(ctx, panics.ID)
+	if got.Status != models.JobStatusFailed || got.Error != "job panicked: boom" {
+		t.Errorf("panicking job = %s (%q), want failed", got.Status, got.Error)
+	}
+}
+
+func TestJobRunnerCancel(t *testing.T) {
+	ctx := context.Background()
+	repos := memory.NewRepositories()
+	runner := newTestRunner(repos)
+	started := make(chan struct{})
+	runner.Register("slow", func(ctx context.Context, job *models.Job, progress *jobs.Progress) (*jobs.Result, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	runner.Register("never", func(ctx context.Context, job *models.Job, progress *jobs.Progress) (*jobs.Result, error) {
+		t.Error("cancelled queued job ran")
+		return nil, nil
+	})
+
+	// A queued job is cancelled at once and never runs
+	queued, _ := runner.Enqueue(ctx, "never", jobs.EnqueueOptions{RunAt: time.Now().Add(time.Hour)})
+	if job, err := runner.Cancel(ctx, queued.ID); err != nil || job.Status != models.JobStatusCancelled {
+		t.Fatalf("Cancel(queued) = %+v, %v; want cancelled", job, err)
+	}
+
+	// A running job stops at its next heartbeat
+	slow, _ := runner.Enqueue(ctx, "slow", jobs.EnqueueOptions{})
+	done := make(chan error, 1)
+	go func() {
+		_, err := runner.RunPending(ctx)
+		done <- err
+	}()
+	<-started
+	if job, err := runner.Cancel(ctx, slow.ID); err != nil || !job.CancelRequested {
+		t.Fatalf("Cancel(running) = %+v, %v; want cancel requested", job, err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("running job did not stop after cancel")
+	}
+	got, _ := repos.Job.GetByID(ctx, slow.ID)
+	if got.Status != models.JobStatusCancelled {
+		t.Errorf("cancelled running job = %s, want cancelled", got.Status)
+	}
+}
+
+func TestJobHandler_AdminSessionSeesSystemJobs(t *testing.T) {
+	ctx := context.Background()
+	repos := memory.NewRepositories()
+	runner := newTestRunner(repos)
+	runner.Register("nightly", func(ctx context.Context, job *models.Job, progress *jobs.Progress) (*jobs.Result, error) {
+		return nil, nil
+	})
+	// Scheduled jobs are queued by the system, with no created_by
+	system, err := runner.Enqueue(ctx, "nightly", jobs.EnqueueOptions{RunAt: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := handlers.NewJobHandler(&postgres.Repositories{Repositories: repos.Repositories, UnitOfWork: repos.UnitOfWork}, runner)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(sessions.Sessions("vsq_session", cookie.NewStore([]byte("test-secret"))))
+	router.POST("/login/:role", func(c *gin.Context) {
+		session := sessions.Default(c)
+		session.Set("user_id", uuid.New().String())
+		session.Set("role", c.Param("role"))
+		if err := session.Save(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	protected := router.Group("/api", middleware.RequireAuth())
+	protected.GET("/jobs", h.List)
+	protected.POST("/jobs/:id/cancel", h.Cancel)
+
+	// as signs in with role and sends a request with the session cookie
+	as := func(role, method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login/"+role, nil))
+		req := httptest.NewRequest(method, path, nil)
+		for _, c := range w.Result().Cookies() {
+			req.AddCookie(c)
+		}
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	listed := func(w *httptest.ResponseRecorder) int {
+		var body struct{ Jobs []*models.Job }
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("List = %d %s", w.Code, w.Body.String())
+		}
+		return len(body.Jobs)
+	}
+
+	if n := listed(as("branch_manager", http.MethodGet, "/api/jobs")); n != 0 {
+		t.Errorf("Branch manager listed %d jobs, want none", n)
+	}
+	if w := as("branch_manager", http.MethodPost, "/api/jobs/"+system.ID.String()+"/cancel"); w.Code != http.StatusNotFound {
+		t.Errorf("Branch manager cancelling a system job = %d, want 404", w.Code)
+	}
+	if n := listed(as("admin", http.MethodGet, "/api/jobs")); n != 1 {
+		t.Errorf("Admin listed %d jobs, want the system job", n)
+	}
+	if w := as("admin", http.MethodPost, "/api/jobs/"+system.ID.String()+"/cancel"); w.Code != http.StatusAccepted {
+		t.Fatalf("Admin cancelling a system job = %d %s, want 202", w.Code, w.Body.String())
+	}
+	if got, _ := repos.Job.GetByID(ctx, system.ID); got.Status != models.JobStatusCancelled {
+		t.Errorf("system job = %s, want cancelled", got.Status)
+	}
+}
+
+func TestJobRunnerQueuesDueSchedules(t *testing.T) {
+	ctx := context.Background()
+	repos := memory.NewRepositories()
+	runner := newTestRunner(repos)
+	runner.Register("nightly", func(ctx context.Context, job *models.Job, progress *jobs.Progress) (*jobs.Result, error) {
+		return nil, nil
+	})
+
+	if err := runner.Schedule(ctx, "nightly-run", "nightly", "0 2 * * *", map[string]int{"days": 14}); err != nil {
+		t.Fatal(err)
+	}
+	schedule, _ := repos.JobSchedule.GetByName(ctx, "nightly-run")
+	if schedule == nil || !schedule.Enabled || !schedule.NextRunAt.After(time.Now()) {
+		t.Fatalf("schedule = %+v, want enabled with a future run", schedule)
+	}
+
+	// Nothing is due yet
+	if n, err := runner.RunDueSchedules(ctx, time.Now()); err != nil || n != 0 {
+		t.Fatalf("RunDueSchedules before due = %d, %v; want 0", n, err)
+	}
+
+	// Once due it queues one job and moves to the next night
+	due := schedule.NextRunAt.Add(time.Minute)
+	if n, err := runner.RunDueSchedules(ctx, due); err != nil || n != 1 {
+		t.Fatalf("RunDueSchedules when due = %d, %v; want 1", n, err)
+	}
+	if n, _ := runner.RunDueSchedules(ctx, due); n != 0 {
+		t.Errorf("RunDueSchedules queued the same run twice")
+	}
+	advanced, _ := repos.JobSchedule.GetByName(ctx, "nightly-run")
+	if want := schedule.NextRunAt.Add(24 * time.Hour); !advanced.NextRunAt.Equal(want) || advanced.LastRunAt == nil {
+		t.Errorf("next run = %v, want %v", advanced.NextRunAt, want)
+	}
+	queued, _ := repos.Job.List(ctx, interfaces.JobFilters{Type: "nightly"})
+	if len(queued) != 1 || queued[0].ScheduleName != "nightly-run" || string(queued[0].Payload) != `{"days":14}` {
+		t.Fatalf("queued jobs = %+v, want one nightly job with the schedule payload", queued)
+	}
+
+	// A paused schedule queues nothing, and re-registering keeps it paused
+	if err := repos.JobSchedule.SetEnabled(ctx, "nightly-run", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := runner.Schedule(ctx, "nightly-run", "nightly", "0 2 * * *", map[string]int{"days": 14}); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := runner.RunDueSchedules(ctx, due.Add(48*time.Hour)); n != 0 {
+		t.Errorf("paused schedule queued %d jobs", n)
+	}
+}
+
+func TestJobRunnerShutdownGraceAndHealth(t *testing.T) {
+	repos := memory.NewRepositories()
+	runner := newTestRunner(repos).WithPollInterval(10 * time.Millisecond).WithShutdownGrace(time.Second)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	runner.Register("report", func(ctx context.Context, job *models.Job, progress *jobs.Progress) (*jobs.Result, error) {
+		close(started)
+		select {
+		case <-release:
+			return &jobs.Result{Data: "done"}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	})
+
+	if err := runner.Health(); err == nil {
+		t.Error("Health before Run = nil, want an error")
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		runner.Run(ctx)
+		close(stopped)
+	}()
+	job, _ := runner.Enqueue(context.Background(), "report", jobs.EnqueueOptions{})
+	<-started
+	if err := runner.Health(); err != nil {
+		t.Errorf("Health while running = %v, want nil", err)
+	}
+
+	// Shutdown waits for the running job when it finishes within the grace period
+	stop()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after shutdown")
+	}
+	got, _ := repos.Job.GetByID(context.Background(), job.ID)
+	if got.Status != models.JobStatusSucceeded {
+		t.Errorf("job finished during grace = %s, want succeeded", got.Status)
+	}
+	if err := runner.Health(); err == nil {
+		t.Error("Health after Run = nil, want an error")
+	}
+}
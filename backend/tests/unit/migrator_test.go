@@ -0,0 +1,152 @@
+package unit
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"vsq-oper-manpower/backend/internal/repositories/postgres"
+)
+
+func TestMigrations_EmbeddedSetIsContiguous(t *testing.T) {
+	migrator, err := postgres.NewMigrator(nil)
+	if err != nil {
+		t.Fatalf("Failed to load embedded migrations: %v", err)
+	}
+
+	migrations := migrator.Migrations()
+	if len(migrations) == 0 {
+		t.Fatal("Expected embedded migrations")
+	}
+	for i, m := range migrations {
+		if m.Version != int64(i+1) {
+			t.Fatalf("Migration %d has version %d; versions must be contiguous from 1", i, m.Version)
+		}
+		if m.Checksum == "" {
+			t.Errorf("Migration %d (%s) has no checksum", m.Version, m.Name)
+		}
+	}
+
+	if migrations[1].Reversible() {
+		t.Error("Baseline data migration should be irreversible")
+	}
+	if !migrations[len(migrations)-1].Reversible() {
+		t.Error("Latest migration should be reversible")
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0002_add_b.up.sql":   {Data: []byte("-- migrate:no-transaction\nCREATE INDEX CONCURRENTLY b_idx ON b(id);")},
+		"m/0002_add_b.down.sql": {Data: []byte("DROP INDEX b_idx;")},
+		"m/0001_add_a.up.sql":   {Data: []byte("CREATE TABLE a (id INT);")},
+		"m/0001_add_a.down.sql": {Data: []byte("-- nothing to undo\n")},
+		"m/README.md":           {Data: []byte("ignored")},
+	}
+	goMigs := []postgres.Migration{
+		{Version: 3, Name: "backfill", UpFunc: func(tx *sql.Tx) error { return nil }},
+	}
+
+	migrations, err := postgres.LoadMigrations(fsys, "m", goMigs)
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+	if len(migrations) != 3 {
+		t.Fatalf("Expected 3 migrations, got %d", len(migrations))
+	}
+
+	a, b, goMig := migrations[0], migrations[1], migrations[2]
+	if a.Name != "add_a" || b.Name != "add_b" || goMig.Name != "backfill" {
+		t.Errorf("Unexpected order: %s, %s, %s", a.Name, b.Name, goMig.Name)
+	}
+	if a.NoTransaction || !b.NoTransaction {
+		t.Error("Only add_b declares the no-transaction directive")
+	}
+	if a.Reversible() {
+		t.Error("A comment-only down migration should not count as reversible")
+	}
+	if !b.Reversible() {
+		t.Error("add_b has a down migration")
+	}
+	if goMig.Reversible() {
+		t.Error("Go migration without DownFunc should be irreversible")
+	}
+	if a.Checksum == b.Checksum || goMig.Checksum == "" {
+		t.Error("Each migration should have a distinct checksum")
+	}
+
+	// Editing an applied migration must change its checksum
+	fsys["m/0001_add_a.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE a (id BIGINT);")}
+	edited, err := postgres.LoadMigrations(fsys, "m", goMigs)
+	if err != nil {
+		t.Fatalf("LoadMigrations() error = %v", err)
+	}
+	if edited[0].Checksum == a.Checksum {
+		t.Error("Checksum should change when the up migration changes")
+	}
+}
+
+func TestLoadMigrations_RejectsConflicts(t *testing.T) {
+	tests := []struct {
+		name   string
+		fsys   fstest.MapFS
+		goMigs []postgres.Migration
+	}{
+		{
+			name: "duplicate version",
+			fsys: fstest.MapFS{
+				"m/0001_a.up.sql": {Data: []byte("SELECT 1;")},
+				"m/0001_b.up.sql": {Data: []byte("SELECT 1;")},
+			},
+		},
+		{
+			name: "down without up",
+			fsys: fstest.MapFS{
+				"m/0001_a.down.sql": {Data: []byte("SELECT 1;")},
+			},
+		},
+		{
+			name: "go migration reuses version",
+			fsys: fstest.MapFS{
+				"m/0001_a.up.sql": {Data: []byte("SELECT 1;")},
+			},
+			goMigs: []postgres.Migration{{Version: 1, Name: "b", UpFunc: func(tx *sql.Tx) error { return nil }}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := postgres.LoadMigrations(tt.fsys, "m", tt.goMigs); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestCreateMigrationFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "0042_existing.up.sql"), []byte("SELECT 1;"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	upPath, downPath, err := postgres.CreateMigrationFiles(dir, "Add Doctor Leave")
+	if err != nil {
+		t.Fatalf("CreateMigrationFiles() error = %v", err)
+	}
+	if filepath.Base(upPath) != "0043_add_doctor_leave.up.sql" {
+		t.Errorf("Unexpected up file %s", filepath.Base(upPath))
+	}
+	if !strings.HasSuffix(downPath, "0043_add_doctor_leave.down.sql") {
+		t.Errorf("Unexpected down file %s", downPath)
+	}
+	if _, err := os.Stat(downPath); err != nil {
+		t.Errorf("Down file not written: %v", err)
+	}
+
+	if _, _, err := postgres.CreateMigrationFiles(dir, "  "); err == nil {
+		t.Error("Expected an error for an empty name")
+	}
+}
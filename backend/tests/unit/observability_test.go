@@ -0,0 +1,113 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"vsq-oper-manpower/backend/internal/middleware"
+	"vsq-oper-manpower/backend/pkg/logging"
+	"vsq-oper-manpower/backend/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestMetricsAndLogs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&logs, "json", "info"))
+	defer slog.SetDefault(previous)
+
+	registry := metrics.NewRegistry()
+	r := gin.New()
+	r.Use(middleware.RequestIDMiddleware(), middleware.RequestLogger(), middleware.RequestMetrics(registry))
+	r.GET("/api/branches/:id", func(c *gin.Context) {
+		// Set by RequireAuth after the logger has started
+		c.Request = c.Request.WithContext(logging.WithFields(c.Request.Context(), "user_id", "u-1", "role", "admin"))
+		c.Status(http.StatusNoContent)
+	})
+	r.GET("/metrics", middleware.MetricsAuth("secret"), gin.WrapH(registry.Handler()))
+
+	for _, path := range []string{"/api/branches/a", "/api/branches/b", "/nowhere"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Request-ID", "req-"+path[len(path)-1:])
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// One line per request carrying the request fields
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d log lines, want 3:\n%s", len(lines), logs.String())
+	}
+	var first map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if first["request_id"] != "req-a" || first["user_id"] != "u-1" || first["role"] != "admin" ||
+		first["route"] != "/api/branches/:id" || first["status"] != float64(http.StatusNoContent) {
+		t.Errorf("log line = %v, want request, user and route fields", first)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("unauthenticated scrape = %d, want 401", w.Code)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	body := w.Body.String()
+	for _, want := range []string{
+		"# TYPE http_request_duration_seconds histogram",
+		`http_request_duration_seconds_count{method="GET",route="/api/branches/:id",status="204"} 2`,
+		`http_request_duration_seconds_bucket{method="GET",route="/api/branches/:id",status="204",le="+Inf"} 2`,
+		`http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+}
+
+func TestMetricsRegistryFormatsSamples(t *testing.T) {
+	registry := metrics.NewRegistry()
+	counter := registry.NewCounter("imports_total", "Imports by kind", "kind")
+	counter.Inc(`staff "v2"`)
+	counter.Add(2, `staff "v2"`)
+	histogram := registry.NewHistogram("ranking_seconds", "Ranking time", []float64{1, 0.1})
+	histogram.Observe(0.05)
+	histogram.Observe(0.5)
+	histogram.Observe(3)
+	registry.NewGaugeFunc("queue_depth", "Queued items", []string{"queue"}, func(_ context.Context, emit metrics.Emit) {
+		emit(4, "jobs")
+	})
+
+	var out bytes.Buffer
+	if err := registry.Write(context.Background(), &out); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP imports_total Imports by kind
+# TYPE imports_total counter
+imports_total{kind="staff \"v2\""} 3
+# HELP ranking_seconds Ranking time
+# TYPE ranking_seconds histogram
+ranking_seconds_bucket{le="0.1"} 1
+ranking_seconds_bucket{le="1"} 2
+ranking_seconds_bucket{le="+Inf"} 3
+ranking_seconds_sum 3.55
+ranking_seconds_count 3
+# HELP queue_depth Queued items
+# TYPE queue_depth gauge
+queue_depth{queue="jobs"} 4
+`
+	if out.String() != want {
+		t.Errorf("output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
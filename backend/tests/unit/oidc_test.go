@@ -0,0 +1,230 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"vsq-oper-manpower/backend/internal/config"
+	"vsq-oper-manpower/backend/internal/handlers"
+	"vsq-oper-manpower/backend/internal/repositories/memory"
+	"vsq-oper-manpower/backend/internal/repositories/postgres"
+	"vsq-oper-manpower/backend/pkg/oidc"
+	"vsq-oper-manpower/backend/pkg/oidc/oidctest"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-contrib/sessions/cookie"
+	"github.com/gin-gonic/gin"
+)
+
+// authorize follows the mock IdP's authorization endpoint and returns the code and state sent back
+func authorize(t *testing.T, authURL string) (code, state string) {
+	t.Helper()
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("Authorization request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("Authorization returned status %d", resp.StatusCode)
+	}
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("Invalid redirect: %v", err)
+	}
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func newMockOIDC(t *testing.T) (*oidctest.Provider, *oidc.Client) {
+	t.Helper()
+	provider, srv, err := oidctest.NewServer("vsq", "secret")
+	if err != nil {
+		t.Fatalf("Failed to start mock IdP: %v", err)
+	}
+	t.Cleanup(srv.Close)
+
+	client := oidc.NewClient(oidc.Options{
+		Enabled:      true,
+		IssuerURL:    srv.URL,
+		ClientID:     "vsq",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/api/auth/oidc/callback",
+		Scopes:       []string{"profile", "email"},
+		GroupsClaim:  "groups",
+		BranchClaim:  "branch_code",
+	})
+	return provider, client
+}
+
+func TestOIDC_AuthorizationCodeFlowWithPKCE(t *testing.T) {
+	provider, client := newMockOIDC(t)
+	provider.SetClaims(map[string]interface{}{
+		"sub":                "user-123",
+		"email":              "manager@example.com",
+		"preferred_username": "ctr-manager",
+		"groups":             []string{"vsq-branch-managers"},
+		"branch_code":        "CTR",
+	})
+
+	ctx := context.Background()
+	state, _ := oidc.NewState()
+	nonce, _ := oidc.NewState()
+	verifier := oidc.NewVerifier()
+
+	authURL, err := client.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL failed: %v", err)
+	}
+	code, returnedState := authorize(t, authURL)
+	if returnedState != state {
+		t.Fatalf("State = %q; want %q", returnedState, state)
+	}
+
+	identity, err := client.Exchange(ctx, code, verifier, nonce)
+	if err != nil {
+		t.Fatalf("Exchange failed: %v", err)
+	}
+	if identity.Subject != "user-123" || identity.Email != "manager@example.com" || identity.Username != "ctr-manager" {
+		t.Errorf("Unexpected identity: %+v", identity)
+	}
+	if len(identity.Groups) != 1 || identity.Groups[0] != "vsq-branch-managers" {
+		t.Errorf("Groups = %v", identity.Groups)
+	}
+	if identity.BranchCode != "CTR" {
+		t.Errorf("BranchCode = %q; want CTR", identity.BranchCode)
+	}
+}
+
+func TestOIDC_RejectsWrongVerifierAndNonce(t *testing.T) {
+	_, client := newMockOIDC(t)
+	ctx := context.Background()
+
+	verifier := oidc.NewVerifier()
+	authURL, err := client.AuthCodeURL(ctx, "state", "nonce", verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL failed: %v", err)
+	}
+	code, _ := authorize(t, authURL)
+	if _, err := client.Exchange(ctx, code, oidc.NewVerifier(), "nonce"); err == nil {
+		t.Error("Exchange should fail when the PKCE verifier does not match")
+	}
+
+	authURL, _ = client.AuthCodeURL(ctx, "state", "nonce", verifier)
+	code, _ = authorize(t, authURL)
+	if _, err := client.Exchange(ctx, code, verifier, "other-nonce"); err == nil {
+		t.Error("Exchange should fail when the nonce does not match")
+	}
+}
+
+func TestOIDC_RoleForGroups(t *testing.T) {
+	cfg := config.OIDCConfig{RoleMappings: map[string]string{
+		"vsq-admins":          "admin",
+		"vsq-area-managers":   "area_manager",
+		"vsq-branch-managers": "branch_manager",
+	}}
+
+	tests := []struct {
+		groups []string
+		want   string
+		ok     bool
+	}{
+		{[]string{"vsq-branch-managers"}, "branch_manager", true},
+		{[]string{"vsq-branch-managers", "vsq-admins"}, "admin", true},
+		{[]string{"unrelated"}, "", false},
+		{nil, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := cfg.RoleForGroups(tt.groups)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("RoleForGroups(%v) = %q, %v; want %q, %v", tt.groups, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestOIDC_LinksExistingUserOnlyWithVerifiedEmailAndApproval(t *testing.T) {
+	ctx := context.Background()
+	repos := memory.NewRepositories()
+	if err := memory.Seed(ctx, repos); err != nil {
+		t.Fatal(err)
+	}
+	admin, err := repos.User.GetByUsername(ctx, memory.DemoAdminUsername)
+	if err != nil || admin == nil {
+		t.Fatalf("admin not seeded: %v", err)
+	}
+
+	provider, srv, err := oidctest.NewServer("vsq", "secret")
+	if err != nil {
+		t.Fatalf("Failed to start mock IdP: %v", err)
+	}
+	t.Cleanup(srv.Close)
+	cfg := &config.Config{OIDC: config.OIDCConfig{
+		Enabled:      true,
+		IssuerURL:    srv.URL,
+		ClientID:     "vsq",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/api/auth/oidc/callback",
+		FrontendURL:  "http://frontend",
+	}}
+	h := handlers.NewOIDCHandler(&postgres.Repositories{Repositories: repos.Repositories, UnitOfWork: repos.UnitOfWork}, cfg)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(sessions.Sessions("vsq_session", cookie.NewStore([]byte("test-secret"))))
+	router.GET("/login", h.Login)
+	router.GET("/callback", h.Callback)
+	router.POST("/users/:id/sso-link", func(c *gin.Context) { c.Set("user_id", admin.ID.String()) }, h.ApproveLink)
+
+	// login signs in at the IdP with claims and returns where the callback sends the browser
+	login := func(claims map[string]interface{}) string {
+		t.Helper()
+		provider.SetClaims(claims)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/login", nil))
+		code, state := authorize(t, w.Header().Get("Location"))
+
+		req := httptest.NewRequest(http.MethodGet, "/callback?code="+url.QueryEscape(code)+"&state="+url.QueryEscape(state), nil)
+		for _, c := range w.Result().Cookies() {
+			req.AddCookie(c)
+		}
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Header().Get("Location")
+	}
+	claims := func(subject string, verified bool) map[string]interface{} {
+		return map[string]interface{}{
+			"sub":                subject,
+			"email":              admin.Email,
+			"email_verified":     verified,
+			"preferred_username": admin.Username,
+		}
+	}
+
+	if got := login(claims("unverified", false)); !strings.Contains(got, "sso_error") {
+		t.Errorf("Unverified email matching the admin logged in, redirected to %s", got)
+	}
+	if got := login(claims("unapproved", true)); !strings.Contains(got, "sso_error") {
+		t.Errorf("Verified email linked without an admin's approval, redirected to %s", got)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/"+admin.ID.String()+"/sso-link", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Approving the link = %d %s; want 200", w.Code, w.Body.String())
+	}
+	if got := login(claims("approved", true)); got != "http://frontend/" {
+		t.Errorf("Approved verified login redirected to %s; want the frontend", got)
+	}
+	identities, err := repos.UserIdentity.GetByUserID(ctx, admin.ID)
+	if err != nil || len(identities) != 1 || identities[0].Subject != "approved" {
+		t.Errorf("Admin identities = %+v, %v; want only the approved subject", identities, err)
+	}
+	if got := login(claims("second", true)); !strings.Contains(got, "sso_error") {
+		t.Errorf("An approval should link one identity only, redirected to %s", got)
+	}
+}
@@ -0,0 +1,61 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"vsq-oper-manpower/backend/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestTimeout(20*time.Millisecond, map[string]time.Duration{
+		"/long":      time.Hour,
+		"/unbounded": 0,
+	}))
+
+	deadlines := map[string]time.Duration{}
+	record := func(c *gin.Context) {
+		if deadline, ok := c.Request.Context().Deadline(); ok {
+			deadlines[c.FullPath()] = time.Until(deadline)
+		} else {
+			deadlines[c.FullPath()] = -1
+		}
+		c.Status(http.StatusNoContent)
+	}
+	r.GET("/short", record)
+	r.GET("/long", record)
+	r.GET("/unbounded", record)
+	r.GET("/slow", func(c *gin.Context) {
+		// Simulates a query that honours cancellation but the handler never responds
+		<-c.Request.Context().Done()
+	})
+
+	for _, path := range []string{"/short", "/long", "/unbounded"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusNoContent {
+			t.Errorf("%s: status = %d; want %d", path, w.Code, http.StatusNoContent)
+		}
+	}
+	if d := deadlines["/short"]; d <= 0 || d > 20*time.Millisecond {
+		t.Errorf("/short deadline in %v; want the default timeout", d)
+	}
+	if d := deadlines["/long"]; d < time.Minute {
+		t.Errorf("/long deadline in %v; want the route override", d)
+	}
+	if d := deadlines["/unbounded"]; d != -1 {
+		t.Errorf("/unbounded should have no deadline, got %v", d)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("/slow: status = %d; want %d", w.Code, http.StatusGatewayTimeout)
+	}
+}
@@ -0,0 +1,81 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"vsq-oper-manpower/backend/pkg/workerpool"
+)
+
+func TestWorkerpoolMap_OrdersResultsAndBoundsConcurrency(t *testing.T) {
+	var running, peak atomic.Int32
+	results, err := workerpool.Map(context.Background(), 3, 50, func(ctx context.Context, i int) (int, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		// Later tasks finish first, so ordering can't come from completion order
+		time.Sleep(time.Duration(50-i) * 20 * time.Microsecond)
+		running.Add(-1)
+		return i * i, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, got := range results {
+		if got != i*i {
+			t.Fatalf("results[%d] = %d, want %d", i, got, i*i)
+		}
+	}
+	if p := peak.Load(); p > 3 {
+		t.Errorf("peak concurrency %d, want at most 3", p)
+	}
+}
+
+func TestWorkerpoolMap_AggregatesTaskErrors(t *testing.T) {
+	errOdd := errors.New("odd")
+	results, err := workerpool.Map(context.Background(), 4, 6, func(ctx context.Context, i int) (string, error) {
+		if i%2 == 1 {
+			return "", fmt.Errorf("task %d: %w", i, errOdd)
+		}
+		return fmt.Sprint(i), nil
+	})
+
+	var taskErrs workerpool.Errors
+	if !errors.As(err, &taskErrs) {
+		t.Fatalf("err = %v, want workerpool.Errors", err)
+	}
+	if len(taskErrs) != 3 || taskErrs[0].Index != 1 || taskErrs[1].Index != 3 || taskErrs[2].Index != 5 {
+		t.Errorf("failed tasks = %v, want 1, 3 and 5 in order", taskErrs)
+	}
+	if !errors.Is(err, errOdd) {
+		t.Error("errors.Is should see the task errors")
+	}
+	if results[0] != "0" || results[2] != "2" || results[1] != "" {
+		t.Errorf("results = %q, want successful slots filled and failed ones empty", results)
+	}
+}
+
+func TestWorkerpoolMap_StopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var started atomic.Int32
+	_, err := workerpool.Map(ctx, 2, 1000, func(ctx context.Context, i int) (int, error) {
+		if started.Add(1) == 10 {
+			cancel()
+		}
+		return i, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if n := started.Load(); n >= 1000 {
+		t.Errorf("all %d tasks ran after cancellation", n)
+	}
+}
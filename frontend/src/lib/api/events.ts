@@ -0,0 +1,53 @@
+import apiClient from './client';
+
+export type ChangeEventKind =
+  | 'schedule.changed'
+  | 'rotation.changed'
+  | 'quota.changed'
+  | 'doctor_override.changed'
+  | 'constraints.changed'
+  | 'quota_status.changed';
+
+export interface ChangeEvent {
+  id: string;
+  kind: ChangeEventKind;
+  branch_id?: string; // absent when every branch is affected
+  date?: string; // YYYY-MM-DD; absent when every date is affected
+  at: string;
+}
+
+export interface ChangeEventHandlers {
+  onEvent: (event: ChangeEvent) => void;
+  // Called when missed events could not be replayed; refetch everything shown
+  onReset?: () => void;
+}
+
+const eventKinds: ChangeEventKind[] = [
+  'schedule.changed',
+  'rotation.changed',
+  'quota.changed',
+  'doctor_override.changed',
+  'constraints.changed',
+  'quota_status.changed',
+];
+
+export const eventsApi = {
+  // Subscribes to change events for the user's branches. The browser
+  // reconnects on its own and the server replays what was missed.
+  // Returns a function that closes the stream.
+  subscribe: (handlers: ChangeEventHandlers, branchIds?: string[]): (() => void) => {
+    const url = new URL(`${apiClient.defaults.baseURL}/events/stream`);
+    if (branchIds && branchIds.length > 0) {
+      url.searchParams.set('branch_ids', branchIds.join(','));
+    }
+    const source = new EventSource(url.toString(), { withCredentials: true });
+
+    const onMessage = (message: MessageEvent) => {
+      handlers.onEvent(JSON.parse(message.data) as ChangeEvent);
+    };
+    eventKinds.forEach((kind) => source.addEventListener(kind, onMessage));
+    source.addEventListener('reset', () => handlers.onReset?.());
+
+    return () => source.close();
+  },
+};
@@ -0,0 +1,100 @@
+import apiClient from './client';
+
+export type JobStatus = 'queued' | 'running' | 'succeeded' | 'failed' | 'cancelled';
+
+export interface Job {
+  id: string;
+  type: string;
+  status: JobStatus;
+  payload?: unknown;
+  progress: number; // 0-100
+  progress_message: string;
+  attempts: number;
+  max_attempts: number;
+  error?: string;
+  result?: unknown;
+  result_file_name?: string;
+  cancel_requested: boolean;
+  schedule_name?: string;
+  run_at: string;
+  started_at?: string;
+  finished_at?: string;
+  created_by?: string;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface JobSchedule {
+  name: string;
+  job_type: string;
+  cron: string;
+  payload?: unknown;
+  enabled: boolean;
+  last_run_at?: string;
+  next_run_at: string;
+}
+
+export interface JobFilters {
+  type?: string;
+  status?: JobStatus;
+  limit?: number;
+}
+
+export const isJobFinished = (job: Job): boolean =>
+  job.status === 'succeeded' || job.status === 'failed' || job.status === 'cancelled';
+
+export const jobsApi = {
+  list: async (filters?: JobFilters): Promise<Job[]> => {
+    const response = await apiClient.get('/jobs', { params: filters });
+    return (response.data.jobs || []) as Job[];
+  },
+
+  get: async (id: string): Promise<Job> => {
+    const response = await apiClient.get(`/jobs/${id}`);
+    return response.data.job as Job;
+  },
+
+  cancel: async (id: string): Promise<Job> => {
+    const response = await apiClient.post(`/jobs/${id}/cancel`);
+    return response.data.job as Job;
+  },
+
+  create: async (type: string, payload?: unknown): Promise<Job> => {
+    const response = await apiClient.post('/jobs', { type, payload });
+    return response.data.job as Job;
+  },
+
+  // Downloads the file a finished job produced
+  downloadResult: async (id: string): Promise<Blob> => {
+    const response = await apiClient.get(`/jobs/${id}/result`, {
+      responseType: 'blob',
+    });
+    return response.data as Blob;
+  },
+
+  // Polls a job until it finishes, reporting progress along the way
+  waitFor: async (
+    id: string,
+    onProgress?: (job: Job) => void,
+    intervalMs = 2000
+  ): Promise<Job> => {
+    for (;;) {
+      const job = await jobsApi.get(id);
+      onProgress?.(job);
+      if (isJobFinished(job)) {
+        return job;
+      }
+      await new Promise((resolve) => setTimeout(resolve, intervalMs));
+    }
+  },
+
+  listSchedules: async (): Promise<JobSchedule[]> => {
+    const response = await apiClient.get('/jobs/schedules');
+    return (response.data.schedules || []) as JobSchedule[];
+  },
+
+  setScheduleEnabled: async (name: string, enabled: boolean): Promise<JobSchedule> => {
+    const response = await apiClient.put(`/jobs/schedules/${encodeURIComponent(name)}`, { enabled });
+    return response.data.schedule as JobSchedule;
+  },
+};
@@ -45,6 +45,23 @@ export interface DayOverview {
   branches_with_shortage: number;
 }
 
+export interface MonthlyOverview {
+  branch_id: string;
+  branch_name: string;
+  branch_code: string;
+  year: number;
+  month: number;
+  day_statuses: BranchQuotaStatus[];
+  average_fulfillment: number;
+}
+
+export interface MonthlyMatrix {
+  year: number;
+  month: number;
+  dates: string[]; // Column headers (YYYY-MM-DD)
+  branches: MonthlyOverview[]; // One row per branch, one status per date
+}
+
 export const overviewApi = {
   getDayOverview: async (date: string, branchIds?: string[]) => {
     const params: { date: string; branch_ids?: string } = { date };
@@ -55,6 +72,15 @@ export const overviewApi = {
     return response.data.overview as DayOverview;
   },
   
+  getMonthlyMatrix: async (year: number, month: number, branchIds?: string[]) => {
+    const params: { year: number; month: number; branch_ids?: string } = { year, month };
+    if (branchIds && branchIds.length > 0) {
+      params.branch_ids = branchIds.join(',');
+    }
+    const response = await apiClient.get('/overview/matrix', { params });
+    return response.data.matrix as MonthlyMatrix;
+  },
+
   getBranchQuotaStatus: async (branchId: string, date: string) => {
     const overview = await overviewApi.getDayOverview(date, [branchId]);
     return overview.branch_statuses.find(bs => bs.branch_id === branchId);